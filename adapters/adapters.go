@@ -0,0 +1,88 @@
+// Package adapters mounts MCP HTTP endpoints into existing routers
+//
+// The framework-specific adapters (adapters/gin, adapters/echo, adapters/fiber)
+// are separate modules so the core framework doesn't depend on any web framework.
+// They are thin wrappers around Mount.
+package adapters
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SaherElMasry/go-mcp-framework/framework"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+)
+
+// RegisterFunc registers a handler for a method and path in a host router
+// A path ending in a slash is a subtree, as in ServeMux: the handler also
+// serves every path below it (see httpTransport.Route.Prefix).
+type RegisterFunc func(method, path string, handler http.Handler)
+
+// Endpoints holds the MCP handler and the routes it serves
+type Endpoints struct {
+	Handler http.Handler
+	Routes  []httpTransport.Route
+}
+
+// FromServer extracts the HTTP endpoints from an initialized server
+func FromServer(s *framework.Server) (*Endpoints, error) {
+	h, err := s.HTTPHandler()
+	if err != nil {
+		return nil, err
+	}
+	return &Endpoints{
+		Handler: h,
+		Routes:  s.HTTPRoutes(),
+	}, nil
+}
+
+// Mount registers every MCP route under prefix using register
+// Requests are dispatched to the MCP handler with prefix stripped, so the
+// handler sees the same paths (/rpc, /stream, /health) as when run standalone.
+// OPTIONS is registered alongside each route for CORS preflight.
+func (e *Endpoints) Mount(prefix string, register RegisterFunc) error {
+	if e == nil || e.Handler == nil {
+		return fmt.Errorf("no MCP handler to mount")
+	}
+
+	prefix = NormalizePrefix(prefix)
+	handler := e.Handler
+	if prefix != "" {
+		handler = http.StripPrefix(prefix, handler)
+	}
+
+	for _, route := range e.Routes {
+		path := prefix + route.Path
+		register(route.Method, path, handler)
+		register(http.MethodOptions, path, handler)
+	}
+
+	return nil
+}
+
+// MountServeMux mounts the endpoints on a standard library ServeMux
+func (e *Endpoints) MountServeMux(mux *http.ServeMux, prefix string) error {
+	registered := make(map[string]bool)
+	return e.Mount(prefix, func(method, path string, h http.Handler) {
+		// ServeMux routes by path; the MCP handler enforces methods itself
+		if registered[path] {
+			return
+		}
+		registered[path] = true
+		mux.Handle(path, h)
+	})
+}
+
+// NormalizePrefix returns prefix with a leading slash and no trailing slash
+// An empty or "/" prefix normalizes to ""
+func NormalizePrefix(prefix string) string {
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
@@ -0,0 +1,96 @@
+package adapters_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/adapters"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/framework"
+)
+
+func newInitializedServer(t *testing.T) *framework.Server {
+	t.Helper()
+
+	server := framework.NewServer(
+		framework.WithBackend(backend.NewBaseBackend("test")),
+		framework.WithTransport("http"),
+		framework.WithObservability(false),
+	)
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	return server
+}
+
+func TestNormalizePrefix(t *testing.T) {
+	tests := map[string]string{
+		"":      "",
+		"/":     "",
+		"mcp":   "/mcp",
+		"/mcp/": "/mcp",
+		"/a/b":  "/a/b",
+	}
+	for in, want := range tests {
+		if got := adapters.NormalizePrefix(in); got != want {
+			t.Errorf("NormalizePrefix(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMount_RegistersAllRoutes(t *testing.T) {
+	endpoints, err := adapters.FromServer(newInitializedServer(t))
+	if err != nil {
+		t.Fatalf("FromServer() error = %v", err)
+	}
+
+	var registered []string
+	err = endpoints.Mount("/mcp", func(method, path string, h http.Handler) {
+		registered = append(registered, method+" "+path)
+	})
+	if err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+
+	joined := strings.Join(registered, ",")
+	for _, want := range []string{"POST /mcp/rpc", "POST /mcp/stream", "GET /mcp/health", "OPTIONS /mcp/rpc"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing route %q in %v", want, registered)
+		}
+	}
+}
+
+func TestMountServeMux_StripsPrefix(t *testing.T) {
+	endpoints, err := adapters.FromServer(newInitializedServer(t))
+	if err != nil {
+		t.Fatalf("FromServer() error = %v", err)
+	}
+
+	mux := http.NewServeMux()
+	if err := endpoints.MountServeMux(mux, "/mcp"); err != nil {
+		t.Fatalf("MountServeMux() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/mcp/health", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /mcp/health status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	w = httptest.NewRecorder()
+	body := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp/rpc", body))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"tools"`) {
+		t.Errorf("POST /mcp/rpc = %d %s, want tools list", w.Code, w.Body.String())
+	}
+}
+
+func TestFromServer_RequiresHTTPTransport(t *testing.T) {
+	server := framework.NewServer(framework.WithTransport("stdio"))
+	if _, err := adapters.FromServer(server); err == nil {
+		t.Error("expected error for non-HTTP server")
+	}
+}
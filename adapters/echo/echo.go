@@ -0,0 +1,49 @@
+// Package mcpecho mounts MCP endpoints on an echo server
+//
+// Example:
+//
+//	server := framework.NewServer(framework.WithBackend(b), framework.WithTransport("http"))
+//	if err := server.Initialize(ctx); err != nil { ... }
+//	endpoints, _ := adapters.FromServer(server)
+//
+//	e := echo.New()
+//	mcpecho.Register(e, "/mcp", endpoints, middleware.Logger())
+package mcpecho
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/SaherElMasry/go-mcp-framework/adapters"
+	"github.com/labstack/echo/v4"
+)
+
+// Register mounts the MCP routes under prefix on an echo instance
+// The given middleware runs before the MCP handler. The created group
+// is returned so callers can attach further routes or middleware.
+func Register(e *echo.Echo, prefix string, endpoints *adapters.Endpoints, m ...echo.MiddlewareFunc) (*echo.Group, error) {
+	if endpoints == nil || endpoints.Handler == nil {
+		return nil, fmt.Errorf("no MCP handler to mount")
+	}
+
+	prefix = adapters.NormalizePrefix(prefix)
+	handler := endpoints.Handler
+	if prefix != "" {
+		handler = http.StripPrefix(prefix, handler)
+	}
+	wrapped := echo.WrapHandler(handler)
+
+	g := e.Group(prefix, m...)
+	// Routes share a path across methods; register each preflight once
+	preflight := make(map[string]bool)
+	for _, route := range endpoints.Routes {
+		path := route.Pattern("*")
+		g.Add(route.Method, path, wrapped)
+		if !preflight[path] {
+			preflight[path] = true
+			g.Add(http.MethodOptions, path, wrapped)
+		}
+	}
+
+	return g, nil
+}
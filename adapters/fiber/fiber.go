@@ -0,0 +1,51 @@
+// Package mcpfiber mounts MCP endpoints on a fiber app
+//
+// Fiber is built on fasthttp, so the net/http MCP handler is bridged with
+// fiber's adaptor middleware. The adaptor buffers responses: SSE events from
+// /stream are delivered when the stream ends rather than incrementally. Use
+// the gin/echo adapters or the standalone transport when incremental
+// streaming matters.
+//
+// Example:
+//
+//	app := fiber.New()
+//	mcpfiber.Register(app, "/mcp", endpoints, logger.New())
+package mcpfiber
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/SaherElMasry/go-mcp-framework/adapters"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// Register mounts the MCP routes under prefix on a fiber router
+// The given handlers run as middleware before the MCP handler.
+func Register(r fiber.Router, prefix string, endpoints *adapters.Endpoints, middleware ...fiber.Handler) (fiber.Router, error) {
+	if endpoints == nil || endpoints.Handler == nil {
+		return nil, fmt.Errorf("no MCP handler to mount")
+	}
+
+	prefix = adapters.NormalizePrefix(prefix)
+	handler := endpoints.Handler
+	if prefix != "" {
+		handler = http.StripPrefix(prefix, handler)
+	}
+	wrapped := adaptor.HTTPHandler(handler)
+
+	g := r.Group(prefix, middleware...)
+	// Routes share a path across methods; register each preflight once
+	preflight := make(map[string]bool)
+	for _, route := range endpoints.Routes {
+		path := route.Pattern("*")
+		g.Add(route.Method, path, wrapped)
+		if !preflight[path] {
+			preflight[path] = true
+			g.Add(http.MethodOptions, path, wrapped)
+		}
+	}
+
+	return g, nil
+}
@@ -0,0 +1,58 @@
+package mcpfiber_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/adapters"
+	mcpfiber "github.com/SaherElMasry/go-mcp-framework/adapters/fiber"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+	"github.com/gofiber/fiber/v2"
+)
+
+// pathEndpoints answers every request with the path the MCP handler sees
+func pathEndpoints() *adapters.Endpoints {
+	return &adapters.Endpoints{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.URL.Path))
+		}),
+		Routes: []httpTransport.Route{
+			{Method: http.MethodPost, Path: httpTransport.PathRPC},
+			{Method: http.MethodGet, Path: httpTransport.PathRPC},
+			{Method: http.MethodGet, Path: httpTransport.PathAdmin, Prefix: true},
+			{Method: http.MethodPost, Path: httpTransport.PathAdmin, Prefix: true},
+			{Method: http.MethodDelete, Path: httpTransport.PathAdmin, Prefix: true},
+			{Method: http.MethodGet, Path: httpTransport.PathRecordings},
+			{Method: http.MethodGet, Path: httpTransport.PathRecordings + "/", Prefix: true},
+		},
+	}
+}
+
+func TestRegister_Subtree(t *testing.T) {
+	app := fiber.New()
+	if _, err := mcpfiber.Register(app, "/mcp", pathEndpoints()); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tests := []struct{ method, path, want string }{
+		{http.MethodPost, "/mcp/rpc", "/rpc"},
+		{http.MethodGet, "/mcp/admin/tools", "/admin/tools"},
+		{http.MethodPost, "/mcp/admin/tools/echo/disable", "/admin/tools/echo/disable"},
+		{http.MethodDelete, "/mcp/admin/sessions/s1", "/admin/sessions/s1"},
+		{http.MethodGet, "/mcp/debug/recordings", "/debug/recordings"},
+		{http.MethodGet, "/mcp/debug/recordings/req-1", "/debug/recordings/req-1"},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.method, tt.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != tt.want {
+			t.Errorf("%s %s = %d %q, want %q", tt.method, tt.path, resp.StatusCode, body, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package mcpgin mounts MCP endpoints on a gin router
+//
+// Example:
+//
+//	server := framework.NewServer(framework.WithBackend(b), framework.WithTransport("http"))
+//	if err := server.Initialize(ctx); err != nil { ... }
+//	endpoints, _ := adapters.FromServer(server)
+//
+//	r := gin.Default()
+//	mcp := r.Group("/mcp", authMiddleware)
+//	mcpgin.Register(mcp, endpoints)
+package mcpgin
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/SaherElMasry/go-mcp-framework/adapters"
+	"github.com/gin-gonic/gin"
+)
+
+// Register mounts the MCP routes on a gin router group
+// Middleware attached to the group runs before the MCP handler.
+func Register(g *gin.RouterGroup, endpoints *adapters.Endpoints) error {
+	if endpoints == nil || endpoints.Handler == nil {
+		return fmt.Errorf("no MCP handler to mount")
+	}
+
+	// gin passes the full request path through, so strip the group base path
+	handler := endpoints.Handler
+	if base := adapters.NormalizePrefix(g.BasePath()); base != "" {
+		handler = http.StripPrefix(base, handler)
+	}
+	wrapped := gin.WrapH(handler)
+
+	// Routes share a path across methods, and gin panics on duplicates
+	preflight := make(map[string]bool)
+	for _, route := range endpoints.Routes {
+		path := route.Pattern("*path")
+		g.Handle(route.Method, path, wrapped)
+		if !preflight[path] {
+			preflight[path] = true
+			g.OPTIONS(path, wrapped)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package mcpgin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/adapters"
+	mcpgin "github.com/SaherElMasry/go-mcp-framework/adapters/gin"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+	"github.com/gin-gonic/gin"
+)
+
+// pathEndpoints answers every request with the path the MCP handler sees
+func pathEndpoints() *adapters.Endpoints {
+	return &adapters.Endpoints{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.URL.Path))
+		}),
+		Routes: []httpTransport.Route{
+			{Method: http.MethodPost, Path: httpTransport.PathRPC},
+			{Method: http.MethodGet, Path: httpTransport.PathRPC},
+			{Method: http.MethodGet, Path: httpTransport.PathAdmin, Prefix: true},
+			{Method: http.MethodPost, Path: httpTransport.PathAdmin, Prefix: true},
+			{Method: http.MethodDelete, Path: httpTransport.PathAdmin, Prefix: true},
+			{Method: http.MethodGet, Path: httpTransport.PathRecordings},
+			{Method: http.MethodGet, Path: httpTransport.PathRecordings + "/", Prefix: true},
+		},
+	}
+}
+
+func TestRegister_Subtree(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := mcpgin.Register(r.Group("/mcp"), pathEndpoints()); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	tests := []struct{ method, path, want string }{
+		{http.MethodPost, "/mcp/rpc", "/rpc"},
+		{http.MethodGet, "/mcp/admin/tools", "/admin/tools"},
+		{http.MethodPost, "/mcp/admin/tools/echo/disable", "/admin/tools/echo/disable"},
+		{http.MethodDelete, "/mcp/admin/sessions/s1", "/admin/sessions/s1"},
+		{http.MethodGet, "/mcp/debug/recordings", "/debug/recordings"},
+		{http.MethodGet, "/mcp/debug/recordings/req-1", "/debug/recordings/req-1"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != tt.want {
+			t.Errorf("%s %s = %d %q, want %q", tt.method, tt.path, w.Code, w.Body.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package audit records every tool call to an append-only log
+//
+// Each event holds the call's time, principal, transport, tool, redacted
+// arguments, outcome, duration and whether the cache answered it. Events
+// are written by a background goroutine to a Sink: a JSONL file, stdout
+// or a webhook. Arguments are redacted before they leave the call, by
+// name-based defaults (password, token, ...) and per-tool rules:
+//
+//	# config.yaml
+//	audit:
+//	  enabled: true
+//	  sink: file
+//	  path: /var/log/mcp/audit.jsonl
+//	  redact:
+//	    file_write: [content]
+//	    "*": [headers.cookie]
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sink kinds of Config.Sink
+const (
+	SinkStdout  = "stdout"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+)
+
+// Defaults for zero Config fields
+const (
+	DefaultBufferSize     = 1024
+	DefaultWebhookTimeout = 10 * time.Second
+)
+
+// Redacted replaces the values of redacted arguments
+const Redacted = "[REDACTED]"
+
+// DefaultRedactedFields are argument names redacted in every tool, at
+// any depth, compared case-insensitively
+var DefaultRedactedFields = []string{
+	"password", "passwd", "secret", "token", "api_key", "apikey",
+	"access_token", "refresh_token", "client_secret", "authorization",
+	"private_key",
+}
+
+// Config configures audit logging
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Sink is "stdout" (default), "file" or "webhook"
+	Sink string `yaml:"sink"`
+
+	// Path is the JSONL file of the file sink, appended to
+	Path string `yaml:"path"`
+
+	// URL receives each event as a JSON POST with the webhook sink
+	URL string `yaml:"url"`
+
+	// Headers are added to webhook requests, e.g. Authorization
+	Headers map[string]string `yaml:"headers"`
+
+	// Timeout bounds each webhook request (default: DefaultWebhookTimeout)
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Redact lists argument fields never logged, by tool; "*" applies to
+	// every tool. Fields are dotted paths into nested objects, e.g.
+	// "options.body", and apply to each element of arrays on the way.
+	Redact map[string][]string `yaml:"redact"`
+
+	// BufferSize bounds the events waiting for the sink; events beyond
+	// it are dropped and counted (default: DefaultBufferSize)
+	BufferSize int `yaml:"buffer_size"`
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Sink {
+	case "", SinkStdout:
+	case SinkFile:
+		if c.Path == "" {
+			return fmt.Errorf("file sink requires a path")
+		}
+	case SinkWebhook:
+		if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			return fmt.Errorf("webhook sink requires an http(s) url")
+		}
+	default:
+		return fmt.Errorf("unknown sink %q", c.Sink)
+	}
+	if c.BufferSize < 0 {
+		return fmt.Errorf("buffer size must not be negative")
+	}
+	return nil
+}
+
+// Event is one audited tool call
+type Event struct {
+	Time       time.Time              `json:"time"`
+	Principal  string                 `json:"principal,omitempty"`
+	AuthMethod string                 `json:"auth_method,omitempty"`
+	Transport  string                 `json:"transport"`
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+
+	// Status is "success" or "error"; ErrorCode names the failure
+	Status    string `json:"status"`
+	ErrorCode string `json:"error_code,omitempty"`
+
+	DurationMS float64 `json:"duration_ms"`
+	CacheHit   bool    `json:"cache_hit"`
+	TraceID    string  `json:"trace_id,omitempty"`
+	RequestID  string  `json:"request_id,omitempty"`
+}
+
+// Logger redacts events and hands them to a sink in the background
+type Logger struct {
+	sink   Sink
+	redact map[string][]string
+	logger *slog.Logger
+
+	events    chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a logger writing to the sink config describes
+func New(config *Config, logger *slog.Logger) (*Logger, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	sink, err := NewSink(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithSink(sink, config.Redact, config.BufferSize, logger), nil
+}
+
+// NewWithSink creates a logger writing to sink
+// redact holds the per-tool rules of Config.Redact; bufferSize <= 0
+// means DefaultBufferSize.
+func NewWithSink(sink Sink, redact map[string][]string, bufferSize int, logger *slog.Logger) *Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	l := &Logger{
+		sink:   sink,
+		redact: redact,
+		logger: logger,
+		events: make(chan Event, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Record fills in the caller's identity, trace and request ID from ctx,
+// redacts the event's arguments and queues it for the sink
+// It never blocks the call: when the queue is full the event is dropped.
+func (l *Logger) Record(ctx context.Context, e Event) {
+	if l == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && principal != nil {
+		e.Principal, e.AuthMethod = principal.ID, principal.Method
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		e.TraceID = sc.TraceID().String()
+	}
+	if id, ok := observability.RequestIDFromContext(ctx); ok {
+		e.RequestID = id
+	}
+	e.Arguments = l.Redact(e.Tool, e.Arguments)
+
+	select {
+	case l.events <- e:
+	default:
+		observability.RecordAuditDropped()
+		l.logger.Warn("audit queue full, event dropped", "tool", e.Tool)
+	}
+}
+
+// Redact returns a copy of a tool's arguments with the default and
+// configured fields replaced by Redacted
+func (l *Logger) Redact(tool string, args map[string]interface{}) map[string]interface{} {
+	if args == nil {
+		return nil
+	}
+	out := redactNames(args).(map[string]interface{})
+	for _, rules := range [][]string{l.redact["*"], l.redact[tool]} {
+		for _, path := range rules {
+			redactPath(out, strings.Split(path, "."))
+		}
+	}
+	return out
+}
+
+// Close writes the queued events and closes the sink
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.closeOnce.Do(func() { close(l.events) })
+	<-l.done
+	return l.sink.Close()
+}
+
+func (l *Logger) run() {
+	defer close(l.done)
+	for e := range l.events {
+		if err := l.sink.Write(e); err != nil {
+			observability.RecordAuditSinkError()
+			l.logger.Error("audit sink write failed", "tool", e.Tool, "error", err)
+		}
+	}
+}
+
+// ============================================================
+// Redaction
+// ============================================================
+
+// redactNames deep-copies v, redacting the fields DefaultRedactedFields names
+func redactNames(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			if isSecretName(k) {
+				out[k] = Redacted
+				continue
+			}
+			out[k] = redactNames(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactNames(item)
+		}
+		return out
+	}
+	return v
+}
+
+func isSecretName(name string) bool {
+	for _, field := range DefaultRedactedFields {
+		if strings.EqualFold(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactPath redacts the field at path in a copied argument tree
+func redactPath(v interface{}, path []string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		item, ok := v[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = Redacted
+			return
+		}
+		redactPath(item, path[1:])
+	case []interface{}:
+		for _, item := range v {
+			redactPath(item, path)
+		}
+	}
+}
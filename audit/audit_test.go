@@ -0,0 +1,186 @@
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/audit"
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+)
+
+// memorySink keeps events in memory
+type memorySink struct {
+	mu     sync.Mutex
+	events []audit.Event
+	block  chan struct{} // if set, Write waits for it
+}
+
+func (s *memorySink) Write(e audit.Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+func (s *memorySink) Close() error { return nil }
+
+func TestRedact(t *testing.T) {
+	l := audit.NewWithSink(&memorySink{}, map[string][]string{
+		"file_write": {"content", "options.mode"},
+		"*":          {"items.body"},
+	}, 0, nil)
+	defer l.Close()
+
+	args := map[string]interface{}{
+		"path":    "/tmp/a",
+		"content": "top secret",
+		"options": map[string]interface{}{"mode": "0600", "append": true},
+		"items":   []interface{}{map[string]interface{}{"body": "x", "id": 1}},
+		"auth":    map[string]interface{}{"API_KEY": "k", "user": "u"},
+	}
+	got := l.Redact("file_write", args)
+	want := map[string]interface{}{
+		"path":    "/tmp/a",
+		"content": audit.Redacted,
+		"options": map[string]interface{}{"mode": audit.Redacted, "append": true},
+		"items":   []interface{}{map[string]interface{}{"body": audit.Redacted, "id": 1}},
+		"auth":    map[string]interface{}{"API_KEY": audit.Redacted, "user": "u"},
+	}
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("redacted = %s, want %s", gotJSON, wantJSON)
+	}
+	if args["content"] != "top secret" {
+		t.Error("Redact modified the caller's arguments")
+	}
+
+	other := l.Redact("file_read", map[string]interface{}{"content": "kept"})
+	if other["content"] != "kept" {
+		t.Errorf("rules of file_write applied to file_read: %v", other)
+	}
+}
+
+func TestRecord_Principal(t *testing.T) {
+	sink := &memorySink{}
+	l := audit.NewWithSink(sink, nil, 0, nil)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "alice", Method: "api-key"})
+	l.Record(ctx, audit.Event{Tool: "search", Transport: "http", Status: "success"})
+	l.Close()
+
+	if len(sink.events) != 1 {
+		t.Fatalf("got %d events", len(sink.events))
+	}
+	e := sink.events[0]
+	if e.Principal != "alice" || e.AuthMethod != "api-key" || e.Time.IsZero() {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestRecord_DropsWhenFull(t *testing.T) {
+	sink := &memorySink{block: make(chan struct{})}
+	l := audit.NewWithSink(sink, nil, 1, nil)
+	for i := 0; i < 5; i++ {
+		l.Record(context.Background(), audit.Event{Tool: "t"})
+	}
+	close(sink.block)
+	l.Close()
+
+	// One event in the sink's hands, one queued; the rest are dropped
+	if n := len(sink.events); n < 1 || n > 2 {
+		t.Errorf("sink got %d events, want 1 or 2", n)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	l, err := audit.New(&audit.Config{Enabled: true, Sink: audit.SinkFile, Path: path}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Record(context.Background(), audit.Event{Tool: "a", Status: "success"})
+	l.Record(context.Background(), audit.Event{Tool: "b", Status: "error", ErrorCode: "not_found"})
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	f, _ := os.Open(path)
+	defer f.Close()
+	var tools []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e audit.Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		tools = append(tools, e.Tool)
+	}
+	if len(tools) != 2 || tools[0] != "a" || tools[1] != "b" {
+		t.Errorf("tools = %v", tools)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var got []audit.Event
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e audit.Event
+		json.NewDecoder(r.Body).Decode(&e)
+		mu.Lock()
+		got = append(got, e)
+		authHeader = r.Header.Get("Authorization")
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	l, err := audit.New(&audit.Config{
+		Enabled: true,
+		Sink:    audit.SinkWebhook,
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer s3cret"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Record(context.Background(), audit.Event{Tool: "deploy", Arguments: map[string]interface{}{"token": "t"}})
+	l.Close()
+
+	if len(got) != 1 || got[0].Tool != "deploy" || got[0].Arguments["token"] != audit.Redacted {
+		t.Errorf("webhook got %+v", got)
+	}
+	if authHeader != "Bearer s3cret" {
+		t.Errorf("Authorization = %q", authHeader)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, c := range []audit.Config{
+		{Enabled: true, Sink: audit.SinkFile},
+		{Enabled: true, Sink: audit.SinkWebhook, URL: "ftp://x"},
+		{Enabled: true, Sink: "syslog"},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want an error", c)
+		}
+	}
+	if err := (&audit.Config{Sink: "syslog"}).Validate(); err != nil {
+		t.Errorf("disabled config: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Sink writes audit events somewhere durable
+// Write is called from one goroutine at a time.
+type Sink interface {
+	Write(e Event) error
+	Close() error
+}
+
+// NewSink creates the sink config describes
+func NewSink(config *Config) (Sink, error) {
+	switch config.Sink {
+	case "", SinkStdout:
+		return NewWriterSink(os.Stdout), nil
+	case SinkFile:
+		return NewFileSink(config.Path)
+	case SinkWebhook:
+		return NewWebhookSink(config.URL, config.Headers, &http.Client{Timeout: config.timeout()}), nil
+	}
+	return nil, fmt.Errorf("unknown audit sink %q", config.Sink)
+}
+
+func (c *Config) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultWebhookTimeout
+}
+
+// WriterSink writes events as JSON lines
+type WriterSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewWriterSink writes events to w, one JSON object per line
+// Closing the sink does not close w.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// NewFileSink appends events to a JSONL file, creating it (and its
+// directory) readable by the owner only
+func NewFileSink(path string) (*WriterSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &WriterSink{w: f, closer: f}, nil
+}
+
+// Write implements Sink
+func (s *WriterSink) Write(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// Close implements Sink
+func (s *WriterSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// WebhookSink posts each event as JSON to a URL
+type WebhookSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookSink posts events to url with the given headers
+func NewWebhookSink(url string, headers map[string]string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultWebhookTimeout}
+	}
+	return &WebhookSink{url: url, headers: headers, client: client}
+}
+
+// Write implements Sink; any non-2xx response is an error
+func (s *WebhookSink) Write(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("audit webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Close implements Sink
+func (s *WebhookSink) Close() error {
+	return nil
+}
@@ -2,16 +2,28 @@
 package auth
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/observability"
 )
 
 // APIKeyProvider authenticates using API keys
+//
+// It holds one or more keys and sends the first usable one by priority.
+// A request rejected with 401 is retried with the next key, which then
+// stays in use; expired keys are skipped. When no key is left, keys are
+// reloaded with the APIKeyLoader, if one is set (see SetKeyLoader).
 type APIKeyProvider struct {
 	*BaseProvider
-	apiKey string
+	keys   *keyRing
 	header string // Header name (default: "X-API-Key")
 }
 
@@ -19,8 +31,34 @@ type APIKeyProvider struct {
 type APIKeyConfig struct {
 	APIKey string `yaml:"api_key" json:"api_key"`
 	Header string `yaml:"header" json:"header"` // Optional custom header name
+
+	// Keys are further keys, e.g. the next key during a rotation;
+	// APIKey, if set, is the key with ID "default" and priority 0
+	Keys []APIKey `yaml:"keys" json:"keys"`
+}
+
+// APIKey is one key of an APIKeyProvider
+type APIKey struct {
+	// ID names the key in metrics; the key itself is never exposed
+	// (default: "key-<position>")
+	ID  string `yaml:"id" json:"id"`
+	Key string `yaml:"key" json:"key"`
+
+	// Priority orders the keys: lower is tried first
+	Priority int `yaml:"priority" json:"priority"`
+
+	// ExpiresAt retires the key (zero: never)
+	ExpiresAt time.Time `yaml:"expires_at" json:"expires_at"`
+}
+
+// expired reports whether the key is past its expiry
+func (k APIKey) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
 }
 
+// APIKeyLoader loads the current keys, e.g. from a secret store
+type APIKeyLoader func(ctx context.Context) ([]APIKey, error)
+
 // NewAPIKeyProvider creates a new API key provider
 func NewAPIKeyProvider(name string, config APIKeyConfig) *APIKeyProvider {
 	header := config.Header
@@ -28,13 +66,43 @@ func NewAPIKeyProvider(name string, config APIKeyConfig) *APIKeyProvider {
 		header = "X-API-Key"
 	}
 
+	keys := config.Keys
+	if config.APIKey != "" {
+		keys = append([]APIKey{{ID: "default", Key: config.APIKey}}, keys...)
+	}
+
 	return &APIKeyProvider{
 		BaseProvider: NewBaseProvider(name),
-		apiKey:       config.APIKey,
+		keys:         newKeyRing(name, keys),
 		header:       header,
 	}
 }
 
+// SetKeyLoader sets the hook that reloads keys, on Refresh and whenever
+// every key is expired or rejected
+func (p *APIKeyProvider) SetKeyLoader(loader APIKeyLoader) {
+	p.keys.mu.Lock()
+	defer p.keys.mu.Unlock()
+	p.keys.loader = loader
+}
+
+// KeyInUse returns the ID of the key requests are sent with
+func (p *APIKeyProvider) KeyInUse() string {
+	key, ok := p.keys.pick(nil)
+	if !ok {
+		return ""
+	}
+	return key.ID
+}
+
+// Refresh reloads the keys with the key loader, if set
+func (p *APIKeyProvider) Refresh(ctx context.Context) error {
+	if err := p.keys.reload(ctx); err != nil {
+		return NewAuthError(p.Name(), "", "refresh", err)
+	}
+	return nil
+}
+
 // GetResource returns an authenticated HTTP client
 func (p *APIKeyProvider) GetResource(ctx context.Context, resourceID string) (Resource, error) {
 	// Get resource config
@@ -48,7 +116,7 @@ func (p *APIKeyProvider) GetResource(ctx context.Context, resourceID string) (Re
 		Timeout: 30 * time.Second,
 		Transport: &apiKeyTransport{
 			base:   http.DefaultTransport,
-			apiKey: p.apiKey,
+			keys:   p.keys,
 			header: p.header,
 		},
 	}
@@ -66,26 +134,196 @@ func (p *APIKeyProvider) GetResource(ctx context.Context, resourceID string) (Re
 	}, nil
 }
 
-// Validate checks if the API key is set
+// Validate checks that a usable key is set, loading keys if there is none
 func (p *APIKeyProvider) Validate(ctx context.Context) error {
-	if p.apiKey == "" {
-		return NewAuthError(p.Name(), "", "validate", ErrInvalidCredentials)
+	if _, ok := p.keys.pick(nil); !ok {
+		if err := p.keys.reload(ctx); err != nil {
+			return NewAuthError(p.Name(), "", "validate", err)
+		}
+		if _, ok := p.keys.pick(nil); !ok {
+			return NewAuthError(p.Name(), "", "validate", ErrInvalidCredentials)
+		}
 	}
 	return nil
 }
 
-// apiKeyTransport adds API key to all requests
+// apiKeyTransport adds API key to all requests, retrying requests
+// rejected with 401 with the next key
 type apiKeyTransport struct {
 	base   http.RoundTripper
-	apiKey string
+	keys   *keyRing
 	header string
 }
 
 func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Clone request to avoid modifying original
-	req = req.Clone(req.Context())
-	req.Header.Set(t.header, t.apiKey)
-	return t.base.RoundTrip(req)
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	tried := make(map[string]bool)
+	reloaded := false
+	var rejected *http.Response
+
+	for {
+		key, ok := t.keys.pick(tried)
+		if !ok && !reloaded && t.keys.hasLoader() {
+			// Every key is expired or rejected: the store may have new ones
+			reloaded = true
+			if err := t.keys.reload(req.Context()); err == nil {
+				key, ok = t.keys.pick(tried)
+			}
+		}
+		if !ok {
+			if rejected != nil {
+				return rejected, nil
+			}
+			return nil, fmt.Errorf("%w: no usable API key", ErrInvalidCredentials)
+		}
+
+		// Clone request to avoid modifying original
+		attempt := req.Clone(req.Context())
+		if rejected != nil {
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return rejected, nil
+				}
+				attempt.Body = body
+			}
+			io.Copy(io.Discard, rejected.Body)
+			rejected.Body.Close()
+		}
+		attempt.Header.Set(t.header, key.Key)
+
+		resp, err := t.base.RoundTrip(attempt)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			return resp, nil
+		}
+		tried[key.Key] = true
+		t.keys.reject(key)
+		if !replayable {
+			return resp, nil
+		}
+		rejected = resp
+	}
+}
+
+// ============================================================
+// Key Ring
+// ============================================================
+
+// keyRing holds a provider's keys and the one in use
+type keyRing struct {
+	provider string
+
+	mu     sync.Mutex
+	keys   []APIKey        // by priority
+	failed map[string]bool // keys rejected since they were loaded
+	inUse  APIKey
+	loader APIKeyLoader
+}
+
+func newKeyRing(provider string, keys []APIKey) *keyRing {
+	r := &keyRing{provider: provider}
+	r.set(keys)
+	return r
+}
+
+// set replaces the keys; called with mu held, or before use
+func (r *keyRing) set(keys []APIKey) {
+	sorted := make([]APIKey, 0, len(keys))
+	for i, key := range keys {
+		if key.Key == "" {
+			continue
+		}
+		if key.ID == "" {
+			key.ID = "key-" + strconv.Itoa(i+1)
+		}
+		sorted = append(sorted, key)
+	}
+	slices.SortStableFunc(sorted, func(a, b APIKey) int { return cmp.Compare(a.Priority, b.Priority) })
+	r.keys = sorted
+	r.failed = make(map[string]bool)
+}
+
+// pick returns the key to send: the first unexpired key by priority that
+// wasn't rejected, else the first unexpired one not yet tried
+func (r *keyRing) pick(tried map[string]bool) (APIKey, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var fallback *APIKey
+	for i := range r.keys {
+		key := r.keys[i]
+		if key.expired(now) || tried[key.Key] {
+			continue
+		}
+		if !r.failed[key.Key] {
+			r.use(key, now)
+			return key, true
+		}
+		if fallback == nil {
+			fallback = &r.keys[i]
+		}
+	}
+	if fallback != nil {
+		// All were rejected before: give them another chance
+		r.use(*fallback, now)
+		return *fallback, true
+	}
+	return APIKey{}, false
+}
+
+// use records a switch of keys; called with mu held
+func (r *keyRing) use(key APIKey, now time.Time) {
+	if key.Key == r.inUse.Key && key.ID == r.inUse.ID {
+		return
+	}
+	if previous := r.inUse; previous.Key != "" {
+		reason := "reloaded"
+		switch {
+		case r.failed[previous.Key]:
+			reason = "rejected"
+		case previous.expired(now):
+			reason = "expired"
+		}
+		observability.RecordAPIKeyRotation(r.provider, previous.ID, reason)
+	}
+	r.inUse = key
+	observability.RecordAPIKeyInUse(r.provider, key.ID)
+}
+
+// reject marks a key rejected by the API
+func (r *keyRing) reject(key APIKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failed[key.Key] = true
+}
+
+func (r *keyRing) hasLoader() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.loader != nil
+}
+
+// reload replaces the keys with the loader's, if there is a loader
+func (r *keyRing) reload(ctx context.Context) error {
+	r.mu.Lock()
+	loader := r.loader
+	r.mu.Unlock()
+	if loader == nil {
+		return nil
+	}
+
+	keys, err := loader(ctx)
+	if err != nil {
+		return fmt.Errorf("reload API keys: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.set(keys)
+	return nil
 }
 
 // APIKeyResource wraps an HTTP client
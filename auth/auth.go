@@ -58,6 +58,9 @@ type ResourceConfig struct {
 
 	// Resource-specific configuration
 	Config map[string]interface{} `yaml:"config" json:"config"`
+
+	// Scopes required when accessing the resource
+	Scopes []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
 }
 
 // Credentials holds authentication credentials
@@ -2,8 +2,18 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestManager(t *testing.T) {
@@ -92,6 +102,147 @@ func TestAPIKeyProvider(t *testing.T) {
 	}
 }
 
+func TestAPIKeyProvider_Rotation(t *testing.T) {
+	valid := "new-key"
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		sent = append(sent, key)
+		if key != valid {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewAPIKeyProvider("rotating", APIKeyConfig{
+		APIKey: "old-key",
+		Keys: []APIKey{
+			{ID: "retired", Key: "retired-key", Priority: -1, ExpiresAt: time.Now().Add(-time.Hour)},
+			{ID: "next", Key: "new-key", Priority: 1},
+		},
+	})
+	provider.RegisterResource(ResourceConfig{ID: "api", Config: map[string]interface{}{"base_url": server.URL}})
+	if provider.KeyInUse() != "default" {
+		t.Fatalf("key in use = %q, want default", provider.KeyInUse())
+	}
+
+	resource, err := provider.GetResource(context.Background(), "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := resource.(*APIKeyResource).Client()
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if fmt.Sprint(sent) != "[old-key new-key]" {
+		t.Errorf("keys sent = %v", sent)
+	}
+	if provider.KeyInUse() != "next" {
+		t.Errorf("key in use = %q, want next", provider.KeyInUse())
+	}
+
+	// The next key stays in use
+	sent = nil
+	resp, _ = client.Get(server.URL)
+	resp.Body.Close()
+	if fmt.Sprint(sent) != "[new-key]" {
+		t.Errorf("keys sent = %v", sent)
+	}
+
+	// Once every key is rejected, keys are reloaded
+	valid = "rotated-key"
+	provider.SetKeyLoader(func(ctx context.Context) ([]APIKey, error) {
+		return []APIKey{{ID: "rotated", Key: "rotated-key"}}, nil
+	})
+	sent = nil
+	resp, _ = client.Get(server.URL)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || provider.KeyInUse() != "rotated" {
+		t.Errorf("after reload: status %d, key in use %q, keys sent %v", resp.StatusCode, provider.KeyInUse(), sent)
+	}
+}
+
+func TestAPIKeyProvider_Validate(t *testing.T) {
+	expired := NewAPIKeyProvider("expired", APIKeyConfig{
+		Keys: []APIKey{{Key: "k", ExpiresAt: time.Now().Add(-time.Minute)}},
+	})
+	if err := expired.Validate(context.Background()); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expired keys: error = %v", err)
+	}
+
+	expired.SetKeyLoader(func(ctx context.Context) ([]APIKey, error) {
+		return []APIKey{{Key: "fresh"}}, nil
+	})
+	if err := expired.Validate(context.Background()); err != nil {
+		t.Errorf("after loading keys: %v", err)
+	}
+	if expired.KeyInUse() != "key-1" {
+		t.Errorf("key in use = %q", expired.KeyInUse())
+	}
+}
+
+func TestStaticHeaderProvider(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	provider := NewStaticHeaderProvider("static", StaticHeaderConfig{
+		Username: "user",
+		Password: "pass",
+		Headers:  map[string]string{"X-Tenant": "acme"},
+	})
+	provider.RegisterResource(ResourceConfig{
+		ID:   "api",
+		Type: "api",
+		Config: map[string]interface{}{
+			"base_url": server.URL,
+			"headers":  map[string]interface{}{"X-Tenant": "globex", "X-Extra": "1"},
+		},
+	})
+
+	ctx := context.Background()
+	if err := provider.Validate(ctx); err != nil {
+		t.Fatalf("validation failed: %v", err)
+	}
+
+	res, err := provider.GetResource(ctx, "api")
+	if err != nil {
+		t.Fatalf("failed to get resource: %v", err)
+	}
+	defer res.Close()
+
+	resp, err := res.(*StaticHeaderResource).Client().Get(server.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if auth := got.Get("Authorization"); auth != "Basic dXNlcjpwYXNz" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.Get("X-Tenant") != "globex" || got.Get("X-Extra") != "1" {
+		t.Errorf("resource headers not applied: %v", got)
+	}
+
+	bearer := NewStaticHeaderProvider("bearer", StaticHeaderConfig{BearerToken: "tok"})
+	if auth := bearer.headers.Get("Authorization"); auth != "Bearer tok" {
+		t.Errorf("Authorization = %q", auth)
+	}
+
+	empty := NewStaticHeaderProvider("empty", StaticHeaderConfig{})
+	if err := empty.Validate(ctx); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+}
+
 // Mock provider for testing
 type mockAuthProvider struct {
 	name string
@@ -111,3 +262,473 @@ type mockResource struct{}
 
 func (r *mockResource) Close() error { return nil }
 func (r *mockResource) Type() string { return "mock" }
+
+func TestManager_RegisterResources(t *testing.T) {
+	manager := NewManager()
+	provider := NewAPIKeyProvider("github", APIKeyConfig{APIKey: "k"})
+	if err := manager.Register("github", provider); err != nil {
+		t.Fatal(err)
+	}
+
+	decls := []ResourceDeclaration{{
+		Name:     "github-api",
+		Provider: "github",
+		BaseURL:  "https://api.github.com",
+		Scopes:   []string{"repo"},
+	}}
+	if err := manager.RegisterResources(decls); err != nil {
+		t.Fatalf("RegisterResources() error = %v", err)
+	}
+
+	res, err := provider.GetResource(context.Background(), "github-api")
+	if err != nil {
+		t.Fatalf("GetResource() error = %v", err)
+	}
+	if got := res.(*APIKeyResource).BaseURL(); got != "https://api.github.com" {
+		t.Errorf("BaseURL() = %q", got)
+	}
+
+	exported := manager.ExportResources()
+	if len(exported) != 1 {
+		t.Fatalf("ExportResources() = %+v", exported)
+	}
+	if e := exported[0]; e.Name != "github-api" || e.Provider != "github" || e.BaseURL != "https://api.github.com" || e.Type != "api" || len(e.Scopes) != 1 {
+		t.Errorf("exported = %+v", e)
+	}
+}
+
+func TestManager_RegisterResourcesErrors(t *testing.T) {
+	manager := NewManager()
+	manager.Register("api", NewAPIKeyProvider("api", APIKeyConfig{APIKey: "k"}))
+	manager.Register("mock", &mockAuthProvider{name: "mock"})
+
+	tests := []struct {
+		name  string
+		decls []ResourceDeclaration
+	}{
+		{"missing name", []ResourceDeclaration{{Provider: "api"}}},
+		{"missing provider", []ResourceDeclaration{{Name: "r"}}},
+		{"relative base_url", []ResourceDeclaration{{Name: "r", Provider: "api", BaseURL: "/v1"}}},
+		{"duplicate", []ResourceDeclaration{{Name: "r", Provider: "api"}, {Name: "r", Provider: "api"}}},
+		{"unknown provider", []ResourceDeclaration{{Name: "r", Provider: "nope"}}},
+		{"unsupported provider", []ResourceDeclaration{{Name: "r", Provider: "mock"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := manager.RegisterResources(tt.decls); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestRegisterOAuthEndpoint(t *testing.T) {
+	endpoint := KeycloakEndpoint("https://sso.example.com/", "eng")
+	if err := RegisterOAuthEndpoint("keycloak-test", endpoint); err != nil {
+		t.Fatalf("RegisterOAuthEndpoint() error = %v", err)
+	}
+
+	factory := NewProviderFactory(NewMemoryTokenStore())
+	provider, err := factory.Create("keycloak-test", "id", "secret", "http://localhost/cb", nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	want := "https://sso.example.com/realms/eng/protocol/openid-connect/token"
+	if provider.config.Endpoint.TokenURL != want {
+		t.Errorf("TokenURL = %q, want %q", provider.config.Endpoint.TokenURL, want)
+	}
+	if len(provider.config.Scopes) != 3 {
+		t.Errorf("Scopes = %v, want endpoint defaults", provider.config.Scopes)
+	}
+
+	if _, err := factory.Create("no-such-idp", "id", "secret", "", nil); err == nil {
+		t.Error("expected error for unregistered provider")
+	}
+	if err := RegisterOAuthEndpoint("bad", OAuthEndpoint{AuthURL: "not a url"}); err == nil {
+		t.Error("expected validation error")
+	}
+}
+
+func TestOAuth2Config_ResolveEndpoint(t *testing.T) {
+	cfg, err := OAuth2Config{Endpoint: "github", TokenURL: "https://ghe.example.com/token"}.ResolveEndpoint()
+	if err != nil {
+		t.Fatalf("ResolveEndpoint() error = %v", err)
+	}
+	if cfg.AuthURL != "https://github.com/login/oauth/authorize" {
+		t.Errorf("AuthURL = %q", cfg.AuthURL)
+	}
+	if cfg.TokenURL != "https://ghe.example.com/token" {
+		t.Errorf("TokenURL = %q, explicit value must win", cfg.TokenURL)
+	}
+
+	if _, err := (OAuth2Config{Endpoint: "missing"}).ResolveEndpoint(); err == nil {
+		t.Error("expected error for unknown endpoint")
+	}
+}
+
+func TestOAuth2Provider_PKCEAndState(t *testing.T) {
+	var gotVerifier string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		gotVerifier = r.Form.Get("code_verifier")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"tok","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	provider := NewOAuth2Provider("test", OAuth2Config{
+		ClientID:    "client",
+		RedirectURL: "http://localhost/callback",
+		AuthURL:     server.URL + "/authorize",
+		TokenURL:    server.URL + "/token",
+	}, nil)
+
+	ctx := context.Background()
+	authURL, state, err := provider.AuthCodeURL(ctx)
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if q.Get("state") != state {
+		t.Errorf("state = %q, want %q", q.Get("state"), state)
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("missing PKCE challenge in %s", authURL)
+	}
+
+	if err := provider.Exchange(ctx, "forged", "code"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState for unknown state, got %v", err)
+	}
+
+	if err := provider.Exchange(ctx, state, "code"); err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if gotVerifier == "" {
+		t.Error("token request did not carry code_verifier")
+	}
+
+	// States are single use
+	if err := provider.Exchange(ctx, state, "code"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState on replay, got %v", err)
+	}
+}
+
+func TestMemoryStateStore_Expiry(t *testing.T) {
+	store := NewMemoryStateStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	store.Save(ctx, "s", AuthRequest{Provider: "p", ExpiresAt: now.Add(time.Minute)})
+
+	now = now.Add(2 * time.Minute)
+	if _, err := store.Consume(ctx, "s"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected ErrInvalidState for expired state, got %v", err)
+	}
+}
+
+func TestOAuth2Provider_IntrospectAndRevoke(t *testing.T) {
+	revoked := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		token := r.Form.Get("token")
+		switch r.URL.Path {
+		case "/introspect":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"active":%t}`, !revoked[token])
+		case "/revoke":
+			revoked[token] = true
+		}
+	}))
+	defer server.Close()
+
+	store := &memoryTokenStore{tokens: map[string]*OAuth2Token{}}
+	provider := NewOAuth2Provider("test", OAuth2Config{
+		ClientID:         "client",
+		AuthURL:          server.URL + "/authorize",
+		TokenURL:         server.URL + "/token",
+		IntrospectionURL: server.URL + "/introspect",
+		RevocationURL:    server.URL + "/revoke",
+	}, store)
+
+	ctx := context.Background()
+	provider.SetToken(ctx, &OAuth2Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+
+	if err := provider.Validate(ctx); err != nil {
+		t.Fatalf("Validate failed for active token: %v", err)
+	}
+
+	manager := NewManager()
+	manager.Register("test", provider)
+	if err := manager.RevokeToken(ctx, "test"); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if !revoked["access"] || !revoked["refresh"] {
+		t.Errorf("revoked = %v, want access and refresh tokens", revoked)
+	}
+	if _, ok := store.tokens["test"]; ok {
+		t.Error("revoked token still in token store")
+	}
+	if err := provider.Validate(ctx); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Validate after revoke = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestManager_RevokeTokenUnsupported(t *testing.T) {
+	manager := NewManager()
+	manager.Register("keys", NewAPIKeyProvider("keys", APIKeyConfig{}))
+
+	if err := manager.RevokeToken(context.Background(), "keys"); !errors.Is(err, ErrRevocationUnsupported) {
+		t.Errorf("expected ErrRevocationUnsupported, got %v", err)
+	}
+}
+
+// memoryTokenStore is a TokenStore for tests
+type memoryTokenStore struct {
+	tokens map[string]*OAuth2Token
+}
+
+func (s *memoryTokenStore) Save(ctx context.Context, name string, token *OAuth2Token) error {
+	s.tokens[name] = token
+	return nil
+}
+
+func (s *memoryTokenStore) Load(ctx context.Context, name string) (*OAuth2Token, error) {
+	if token, ok := s.tokens[name]; ok {
+		return token, nil
+	}
+	return nil, ErrInvalidCredentials
+}
+
+func (s *memoryTokenStore) Delete(ctx context.Context, name string) error {
+	delete(s.tokens, name)
+	return nil
+}
+
+func (s *memoryTokenStore) Close() error { return nil }
+
+func TestOAuth2Provider_GetScopedResource(t *testing.T) {
+	var requests int
+	var gotScope string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests++
+		gotScope = r.Form.Get("scope")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"scoped","token_type":"bearer","expires_in":3600,"scope":%q}`, gotScope)
+	}))
+	defer server.Close()
+
+	provider := NewOAuth2Provider("test", OAuth2Config{
+		ClientID: "client",
+		Scopes:   []string{"repo", "read:org", "user"},
+		AuthURL:  server.URL + "/authorize",
+		TokenURL: server.URL + "/token",
+	}, nil)
+	provider.RegisterResource(ResourceConfig{ID: "api", Type: "api", Config: map[string]interface{}{"base_url": "https://api.example.com"}})
+
+	ctx := context.Background()
+	provider.SetToken(ctx, &OAuth2Token{AccessToken: "full", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour)})
+
+	if _, err := provider.GetScopedResource(ctx, "api", []string{"read:org"}); err != nil {
+		t.Fatalf("GetScopedResource failed: %v", err)
+	}
+	if gotScope != "read:org" {
+		t.Errorf("requested scope = %q, want read:org", gotScope)
+	}
+
+	// Cached per scope set
+	provider.GetScopedResource(ctx, "api", []string{"read:org"})
+	if requests != 1 {
+		t.Errorf("token requests = %d, want 1", requests)
+	}
+
+	if _, err := provider.GetScopedResource(ctx, "api", []string{"admin"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for ungranted scope, got %v", err)
+	}
+}
+
+func TestManager_GetScopedResourceUnsupported(t *testing.T) {
+	manager := NewManager()
+	manager.Register("keys", NewAPIKeyProvider("keys", APIKeyConfig{}))
+
+	_, err := manager.GetScopedResource(context.Background(), "keys", "api", []string{"read"})
+	if !errors.Is(err, ErrScopingUnsupported) {
+		t.Errorf("expected ErrScopingUnsupported, got %v", err)
+	}
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	secret := NewSecret("s3cr3t")
+	secret.Scheme = "Bearer"
+
+	var logged bytes.Buffer
+	slog.New(slog.NewTextHandler(&logged, nil)).Info("x", "secret", secret)
+	encoded, _ := json.Marshal(map[string]Secret{"secret": secret})
+
+	for _, out := range []string{
+		fmt.Sprint(secret), fmt.Sprintf("%v %+v %#v %s", secret, secret, secret, secret),
+		logged.String(), string(encoded),
+	} {
+		if strings.Contains(out, "s3cr3t") {
+			t.Errorf("secret leaked: %s", out)
+		}
+	}
+	if secret.Reveal() != "s3cr3t" || secret.HeaderValue() != "Bearer s3cr3t" {
+		t.Errorf("Reveal = %q, HeaderValue = %q", secret.Reveal(), secret.HeaderValue())
+	}
+}
+
+func TestCredentialSource(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager()
+	manager.Register("keys", NewAPIKeyProvider("keys", APIKeyConfig{
+		Header: "X-Key",
+		Keys:   []APIKey{{ID: "primary", Key: "k1"}},
+	}))
+	manager.Register("static", NewStaticHeaderProvider("static", StaticHeaderConfig{BearerToken: "tok"}))
+	manager.Register("mock", &mockAuthProvider{name: "mock"})
+
+	secret, err := manager.GetSecret(ctx, "keys", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Reveal() != "k1" || secret.Header != "X-Key" || secret.KeyID != "primary" {
+		t.Errorf("secret = %q in %s (%s)", secret.Reveal(), secret.Header, secret.KeyID)
+	}
+	if _, err := manager.GetSecret(ctx, "keys", "unregistered"); !errors.Is(err, ErrResourceNotFound) {
+		t.Errorf("unregistered resource: error = %v", err)
+	}
+	if _, err := manager.GetSecret(ctx, "static", ""); !errors.Is(err, ErrCredentialsUnsupported) {
+		t.Errorf("static provider: error = %v", err)
+	}
+	if _, err := manager.HTTPClient(ctx, "mock", ""); !errors.Is(err, ErrCredentialsUnsupported) {
+		t.Errorf("mock provider: error = %v", err)
+	}
+
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	for name, want := range map[string][2]string{
+		"keys":   {"X-Key", "k1"},
+		"static": {"Authorization", "Bearer tok"},
+	} {
+		client, err := manager.HTTPClient(ctx, name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got.Get(want[0]) != want[1] {
+			t.Errorf("%s: %s = %q, want %q", name, want[0], got.Get(want[0]), want[1])
+		}
+	}
+}
+
+func TestOAuth2Provider_DeviceLogin(t *testing.T) {
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/device":
+			w.Write([]byte(`{"device_code":"dev","user_code":"ABCD-EFGH","verification_uri":"https://example.com/device","expires_in":60,"interval":1}`))
+		case "/token":
+			if r.Form.Get("device_code") != "dev" {
+				t.Errorf("device_code = %q", r.Form.Get("device_code"))
+			}
+			polls++
+			if polls == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"device-token","token_type":"bearer","expires_in":3600}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOAuth2Provider("test", OAuth2Config{
+		ClientID:      "client",
+		TokenURL:      server.URL + "/token",
+		DeviceAuthURL: server.URL + "/device",
+	}, nil)
+
+	var prompted DeviceAuthorization
+	err := provider.DeviceLogin(context.Background(), func(da DeviceAuthorization) { prompted = da })
+	if err != nil {
+		t.Fatalf("DeviceLogin failed: %v", err)
+	}
+	if prompted.UserCode != "ABCD-EFGH" || prompted.VerificationURI != "https://example.com/device" {
+		t.Errorf("prompted with %+v", prompted)
+	}
+	if polls != 2 {
+		t.Errorf("polls = %d, want 2", polls)
+	}
+
+	secret, err := provider.GetSecret(context.Background(), "")
+	if err != nil || secret.Reveal() != "device-token" {
+		t.Errorf("GetSecret = %q, %v", secret.Reveal(), err)
+	}
+}
+
+func TestOAuth2Provider_DeviceAuthUnsupported(t *testing.T) {
+	provider := NewOAuth2Provider("test", OAuth2Config{
+		ClientID: "client",
+		AuthURL:  "https://example.com/authorize",
+		TokenURL: "https://example.com/token",
+	}, nil)
+	if _, err := provider.StartDeviceAuth(context.Background()); !errors.Is(err, ErrDeviceFlowUnsupported) {
+		t.Errorf("expected ErrDeviceFlowUnsupported, got %v", err)
+	}
+}
+
+func TestOAuthEndpoint_ValidateDeviceOnly(t *testing.T) {
+	endpoint := OAuthEndpoint{
+		TokenURL:      "https://example.com/token",
+		DeviceAuthURL: "https://example.com/device",
+	}
+	if err := endpoint.Validate(); err != nil {
+		t.Errorf("device-only endpoint rejected: %v", err)
+	}
+	endpoint.DeviceAuthURL = ""
+	if err := endpoint.Validate(); err == nil {
+		t.Error("endpoint without auth_url or device_auth_url accepted")
+	}
+}
+
+func TestResourceHTTPClient(t *testing.T) {
+	provider := NewStaticHeaderProvider("static", StaticHeaderConfig{BearerToken: "tok"})
+	provider.RegisterResource(ResourceConfig{
+		ID:     "api",
+		Type:   "api",
+		Config: map[string]interface{}{"base_url": "https://api.example.com"},
+	})
+	resource, err := provider.GetResource(context.Background(), "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resource.Close()
+
+	client, baseURL, err := ResourceHTTPClient(resource)
+	if err != nil || client == nil || baseURL != "https://api.example.com" {
+		t.Errorf("ResourceHTTPClient = %v, %q, %v", client, baseURL, err)
+	}
+	if _, _, err := ResourceHTTPClient(&mockResource{}); !errors.Is(err, ErrCredentialsUnsupported) {
+		t.Errorf("mock resource: error = %v, want ErrCredentialsUnsupported", err)
+	}
+}
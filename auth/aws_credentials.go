@@ -0,0 +1,407 @@
+// auth/aws_credentials.go
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoAWSCredentials indicates no source in the credential chain had credentials
+var ErrNoAWSCredentials = errors.New("no AWS credentials found")
+
+// AWSCredentials are AWS access keys, possibly temporary
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Expires is zero for long-lived credentials
+	Expires time.Time
+
+	// Source names the provider that produced the credentials
+	Source string
+}
+
+// expired reports whether the credentials expire within window
+func (c AWSCredentials) expired(window time.Duration) bool {
+	return !c.Expires.IsZero() && time.Until(c.Expires) < window
+}
+
+// AWSCredentialsProvider retrieves AWS credentials
+type AWSCredentialsProvider interface {
+	Retrieve(ctx context.Context) (AWSCredentials, error)
+}
+
+// ============================================================
+// Static and environment credentials
+// ============================================================
+
+// StaticAWSCredentials always returns the same credentials
+type StaticAWSCredentials AWSCredentials
+
+// Retrieve implements AWSCredentialsProvider
+func (s StaticAWSCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return AWSCredentials{}, ErrNoAWSCredentials
+	}
+	creds := AWSCredentials(s)
+	if creds.Source == "" {
+		creds.Source = "static"
+	}
+	return creds, nil
+}
+
+// EnvAWSCredentials reads AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+type EnvAWSCredentials struct{}
+
+// Retrieve implements AWSCredentialsProvider
+func (EnvAWSCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	id := os.Getenv("AWS_ACCESS_KEY_ID")
+	secret := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if id == "" || secret == "" {
+		return AWSCredentials{}, ErrNoAWSCredentials
+	}
+	return AWSCredentials{
+		AccessKeyID:     id,
+		SecretAccessKey: secret,
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Source:          "env",
+	}, nil
+}
+
+// ============================================================
+// Shared credentials file
+// ============================================================
+
+// SharedAWSCredentials reads a profile from the shared credentials file,
+// then from the shared config file
+type SharedAWSCredentials struct {
+	// Filename defaults to $AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials
+	Filename string
+
+	// ConfigFilename defaults to $AWS_CONFIG_FILE or ~/.aws/config
+	ConfigFilename string
+
+	// Profile defaults to $AWS_PROFILE or "default"
+	Profile string
+}
+
+// Retrieve implements AWSCredentialsProvider
+func (s SharedAWSCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	profile := awsProfile(s.Profile)
+
+	filename := firstNonEmpty(s.Filename, os.Getenv("AWS_SHARED_CREDENTIALS_FILE"))
+	if filename == "" {
+		filename = awsHomeFile("credentials")
+	}
+	sections, err := readAWSFile(filename)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	if creds, ok := sharedAWSCredentials(sections[profile], "shared:"+profile); ok {
+		return creds, nil
+	}
+
+	// Keys may also live in the config file, under "[profile NAME]"
+	sections, err = readAWSFile(sharedConfigFilename(s.ConfigFilename))
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	if creds, ok := sharedAWSCredentials(sharedConfigSection(sections, profile), "config:"+profile); ok {
+		return creds, nil
+	}
+	return AWSCredentials{}, ErrNoAWSCredentials
+}
+
+// sharedAWSCredentials reads the keys of a profile section
+func sharedAWSCredentials(section map[string]string, source string) (AWSCredentials, bool) {
+	if section["aws_access_key_id"] == "" || section["aws_secret_access_key"] == "" {
+		return AWSCredentials{}, false
+	}
+	return AWSCredentials{
+		AccessKeyID:     section["aws_access_key_id"],
+		SecretAccessKey: section["aws_secret_access_key"],
+		SessionToken:    section["aws_session_token"],
+		Source:          source,
+	}, true
+}
+
+// readAWSFile reads a shared file; a missing file has no sections
+func readAWSFile(filename string) (map[string]map[string]string, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	sections, err := readINI(filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", filename, err)
+	}
+	return sections, nil
+}
+
+// awsHomeFile returns ~/.aws/name, or "" without a home directory
+func awsHomeFile(name string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", name)
+}
+
+// sharedConfigFilename resolves the shared config file
+func sharedConfigFilename(filename string) string {
+	if filename = firstNonEmpty(filename, os.Getenv("AWS_CONFIG_FILE")); filename != "" {
+		return filename
+	}
+	return awsHomeFile("config")
+}
+
+// sharedConfigSection returns a profile of the config file, whose
+// sections are "[profile NAME]" except for "[default]"
+func sharedConfigSection(sections map[string]map[string]string, profile string) map[string]string {
+	if section, ok := sections["profile "+profile]; ok {
+		return section
+	}
+	return sections[profile]
+}
+
+// awsProfile resolves the profile name
+func awsProfile(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	if env := os.Getenv("AWS_PROFILE"); env != "" {
+		return env
+	}
+	return "default"
+}
+
+// sharedConfigRegion returns the region of a profile in ~/.aws/config
+func sharedConfigRegion(profile string) string {
+	sections, err := readAWSFile(sharedConfigFilename(""))
+	if err != nil {
+		return ""
+	}
+	return sharedConfigSection(sections, awsProfile(profile))["region"]
+}
+
+// readINI parses the subset of INI used by AWS shared files
+func readINI(filename string) (map[string]map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sections := make(map[string]map[string]string)
+	var current map[string]string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			current = make(map[string]string)
+			sections[name] = current
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current == nil {
+			continue
+		}
+		current[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return sections, scanner.Err()
+}
+
+// ============================================================
+// Web identity (IRSA)
+// ============================================================
+
+// WebIdentityAWSCredentials exchanges a web identity token for role credentials
+// This is how EKS IAM Roles for Service Accounts (IRSA) work: the pod gets
+// AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE, and calls STS
+// AssumeRoleWithWebIdentity, which needs no AWS credentials itself.
+type WebIdentityAWSCredentials struct {
+	// RoleARN defaults to $AWS_ROLE_ARN
+	RoleARN string
+
+	// TokenFile defaults to $AWS_WEB_IDENTITY_TOKEN_FILE
+	TokenFile string
+
+	// SessionName defaults to $AWS_ROLE_SESSION_NAME or "mcp-server"
+	SessionName string
+
+	// Region selects the regional STS endpoint
+	Region string
+
+	// Endpoint overrides the STS endpoint URL
+	Endpoint string
+
+	// Client is the HTTP client for STS (default: 10s timeout)
+	Client *http.Client
+}
+
+// Retrieve implements AWSCredentialsProvider
+func (w WebIdentityAWSCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	roleARN := firstNonEmpty(w.RoleARN, os.Getenv("AWS_ROLE_ARN"))
+	tokenFile := firstNonEmpty(w.TokenFile, os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"))
+	if roleARN == "" || tokenFile == "" {
+		return AWSCredentials{}, ErrNoAWSCredentials
+	}
+
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("read web identity token: %w", err)
+	}
+
+	endpoint := w.Endpoint
+	if endpoint == "" {
+		endpoint = "https://sts.amazonaws.com"
+		if w.Region != "" {
+			endpoint = "https://sts." + w.Region + ".amazonaws.com"
+		}
+	}
+
+	form := url.Values{}
+	form.Set("Action", "AssumeRoleWithWebIdentity")
+	form.Set("Version", "2011-06-15")
+	form.Set("RoleArn", roleARN)
+	form.Set("RoleSessionName", firstNonEmpty(w.SessionName, os.Getenv("AWS_ROLE_SESSION_NAME"), "mcp-server"))
+	form.Set("WebIdentityToken", strings.TrimSpace(string(token)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("create STS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return AWSCredentials{}, fmt.Errorf("STS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return AWSCredentials{}, fmt.Errorf("STS AssumeRoleWithWebIdentity returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return AWSCredentials{}, fmt.Errorf("decode STS response: %w", err)
+	}
+
+	return AWSCredentials{
+		AccessKeyID:     result.Credentials.AccessKeyID,
+		SecretAccessKey: result.Credentials.SecretAccessKey,
+		SessionToken:    result.Credentials.SessionToken,
+		Expires:         result.Credentials.Expiration,
+		Source:          "web_identity",
+	}, nil
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// ============================================================
+// Chain and cache
+// ============================================================
+
+// AWSCredentialsChain tries providers in order, returning the first credentials found
+type AWSCredentialsChain []AWSCredentialsProvider
+
+// Retrieve implements AWSCredentialsProvider
+// Providers reporting ErrNoAWSCredentials are skipped; other errors stop the chain.
+func (c AWSCredentialsChain) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	for _, p := range c {
+		creds, err := p.Retrieve(ctx)
+		if err == nil {
+			return creds, nil
+		}
+		if !errors.Is(err, ErrNoAWSCredentials) {
+			return AWSCredentials{}, err
+		}
+	}
+	return AWSCredentials{}, ErrNoAWSCredentials
+}
+
+// DefaultAWSCredentialsChain returns the standard chain: env, shared
+// credentials and config files, web identity
+func DefaultAWSCredentialsChain(profile, region string) AWSCredentialsChain {
+	return AWSCredentialsChain{
+		EnvAWSCredentials{},
+		SharedAWSCredentials{Profile: profile},
+		WebIdentityAWSCredentials{Region: region},
+	}
+}
+
+// awsCredentialsRenewBefore renews temporary credentials this long before they expire
+const awsCredentialsRenewBefore = 5 * time.Minute
+
+// cachedAWSCredentials caches credentials until shortly before they expire
+type cachedAWSCredentials struct {
+	provider AWSCredentialsProvider
+
+	mu    sync.Mutex
+	creds *AWSCredentials
+}
+
+// Retrieve implements AWSCredentialsProvider
+func (c *cachedAWSCredentials) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.creds != nil && !c.creds.expired(awsCredentialsRenewBefore) {
+		return *c.creds, nil
+	}
+
+	creds, err := c.provider.Retrieve(ctx)
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	c.creds = &creds
+	return creds, nil
+}
+
+// invalidate drops the cached credentials
+func (c *cachedAWSCredentials) invalidate() {
+	c.mu.Lock()
+	c.creds = nil
+	c.mu.Unlock()
+}
@@ -0,0 +1,419 @@
+// auth/aws_sigv4.go
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSSigV4Config holds AWS SigV4 provider configuration
+type AWSSigV4Config struct {
+	// Region defaults to $AWS_REGION, $AWS_DEFAULT_REGION or the profile's region
+	Region string `yaml:"region" json:"region"`
+
+	// Service is the signing name (e.g. "s3", "dynamodb", "bedrock")
+	// Resources may override it with a "service" config key.
+	Service string `yaml:"service" json:"service"`
+
+	// Profile selects a shared config profile (default: $AWS_PROFILE or "default")
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+
+	// AccessKeyID and SecretAccessKey set static credentials, bypassing the chain
+	AccessKeyID     string `yaml:"-" json:"-"`
+	SecretAccessKey string `yaml:"-" json:"-"`
+	SessionToken    string `yaml:"-" json:"-"`
+
+	// Credentials replaces the default credential chain
+	Credentials AWSCredentialsProvider `yaml:"-" json:"-"`
+}
+
+// AWSSigV4Provider signs requests to AWS APIs with Signature Version 4
+//
+// Credentials come from the standard chain — environment variables, the
+// shared credentials and config files, then web identity (IRSA) — and
+// temporary credentials are renewed before they expire. Backends use the
+// resource's client, HTTPClient, or Credentials for an SDK.
+//
+// Resource config keys:
+//
+//	base_url   API root (default: https://SERVICE.REGION.amazonaws.com)
+//	service    signing name, overriding the provider's
+//	region     signing region, overriding the provider's
+type AWSSigV4Provider struct {
+	*BaseProvider
+	region      string
+	service     string
+	credentials *cachedAWSCredentials
+	now         func() time.Time
+}
+
+// NewAWSSigV4Provider creates a new AWS SigV4 provider
+func NewAWSSigV4Provider(name string, config AWSSigV4Config) *AWSSigV4Provider {
+	region := firstNonEmpty(config.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	if region == "" {
+		region = sharedConfigRegion(config.Profile)
+	}
+
+	creds := config.Credentials
+	if creds == nil {
+		if config.AccessKeyID != "" {
+			creds = StaticAWSCredentials{
+				AccessKeyID:     config.AccessKeyID,
+				SecretAccessKey: config.SecretAccessKey,
+				SessionToken:    config.SessionToken,
+			}
+		} else {
+			creds = DefaultAWSCredentialsChain(config.Profile, region)
+		}
+	}
+
+	return &AWSSigV4Provider{
+		BaseProvider: NewBaseProvider(name),
+		region:       region,
+		service:      config.Service,
+		credentials:  &cachedAWSCredentials{provider: creds},
+		now:          time.Now,
+	}
+}
+
+// GetResource returns an HTTP client that signs every request
+func (p *AWSSigV4Provider) GetResource(ctx context.Context, resourceID string) (Resource, error) {
+	config, err := p.GetResourceConfig(resourceID)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+	}
+
+	service, region, err := p.signingScope(config)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+	}
+
+	baseURL, _ := config.Config["base_url"].(string)
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s.%s.amazonaws.com", service, region)
+	}
+
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: p.transport(http.DefaultTransport, region, service),
+	}
+
+	return &AWSResource{
+		client:     client,
+		baseURL:    baseURL,
+		resourceID: resourceID,
+		region:     region,
+		service:    service,
+	}, nil
+}
+
+// RoundTripper implements TransportSource
+// Requests are signed for the resource's service and region, the
+// provider's own for resourceID "".
+func (p *AWSSigV4Provider) RoundTripper(ctx context.Context, resourceID string, base http.RoundTripper) (http.RoundTripper, error) {
+	var config ResourceConfig
+	if resourceID != "" {
+		var err error
+		if config, err = p.GetResourceConfig(resourceID); err != nil {
+			return nil, NewAuthError(p.Name(), resourceID, "round_tripper", err)
+		}
+	}
+
+	service, region, err := p.signingScope(config)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "round_tripper", err)
+	}
+	return p.transport(base, region, service), nil
+}
+
+// Credentials returns the current credentials, for SDKs that sign
+// requests themselves
+// Fetch them per call: temporary credentials are renewed before they expire.
+func (p *AWSSigV4Provider) Credentials(ctx context.Context) (AWSCredentials, error) {
+	creds, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return AWSCredentials{}, NewAuthError(p.Name(), "", "credentials", err)
+	}
+	return creds, nil
+}
+
+// signingScope returns the service and region of a resource
+func (p *AWSSigV4Provider) signingScope(config ResourceConfig) (service, region string, err error) {
+	service, _ = config.Config["service"].(string)
+	service = firstNonEmpty(service, p.service)
+	region, _ = config.Config["region"].(string)
+	region = firstNonEmpty(region, p.region)
+
+	if service == "" || region == "" {
+		return "", "", fmt.Errorf("AWS service and region are required")
+	}
+	return service, region, nil
+}
+
+// transport returns a RoundTripper signing for region and service
+func (p *AWSSigV4Provider) transport(base http.RoundTripper, region, service string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &sigV4Transport{
+		base:    base,
+		signer:  p,
+		region:  region,
+		service: service,
+	}
+}
+
+// Validate checks that credentials can be retrieved
+func (p *AWSSigV4Provider) Validate(ctx context.Context) error {
+	if _, err := p.credentials.Retrieve(ctx); err != nil {
+		return NewAuthError(p.Name(), "", "validate", err)
+	}
+	return nil
+}
+
+// Refresh discards cached credentials so the next request re-resolves them
+func (p *AWSSigV4Provider) Refresh(ctx context.Context) error {
+	p.credentials.invalidate()
+	return p.Validate(ctx)
+}
+
+// Close releases provider resources
+func (p *AWSSigV4Provider) Close() error {
+	return nil
+}
+
+// SignRequest signs req in place for the given region and service
+// The body is read to compute its hash and then restored.
+func (p *AWSSigV4Provider) SignRequest(ctx context.Context, req *http.Request, region, service string) error {
+	creds, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return NewAuthError(p.Name(), "", "sign", err)
+	}
+
+	payloadHash, err := hashRequestBody(req)
+	if err != nil {
+		return NewAuthError(p.Name(), "", "sign", err)
+	}
+
+	signSigV4(req, creds, region, service, payloadHash, p.now().UTC())
+	return nil
+}
+
+// hashRequestBody returns the hex SHA-256 of the body, leaving the body readable
+func hashRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return sha256Hex(nil), nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return sha256Hex(body), nil
+}
+
+// signSigV4 adds the SigV4 Authorization header to req
+func signSigV4(req *http.Request, creds AWSCredentials, region, service, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := canonicalSigV4Headers(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalSigV4Path(req.URL, service),
+		canonicalSigV4Query(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalSigV4Headers returns the signed header list and canonical header block
+// Signs host, content-type and every x-amz-* header.
+func canonicalSigV4Headers(req *http.Request) (signed, canonical string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			trimmed := make([]string, len(values))
+			for i, v := range values {
+				trimmed[i] = strings.Join(strings.Fields(v), " ")
+			}
+			headers[lower] = strings.Join(trimmed, ",")
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(headers[name])
+		b.WriteByte('\n')
+	}
+	return strings.Join(names, ";"), b.String()
+}
+
+// canonicalSigV4Path returns the canonical URI
+// Services other than S3 expect each path segment encoded twice.
+func canonicalSigV4Path(u *url.URL, service string) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	if service == "s3" {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = sigV4Escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalSigV4Query returns the sorted, encoded query string
+func canonicalSigV4Query(u *url.URL) string {
+	query := u.Query()
+	if len(query) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, sigV4Escape(key)+"="+sigV4Escape(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// sigV4Escape percent-encodes everything but RFC 3986 unreserved characters
+func sigV4Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sigV4Transport signs requests before sending them
+type sigV4Transport struct {
+	base    http.RoundTripper
+	signer  *AWSSigV4Provider
+	region  string
+	service string
+}
+
+func (t *sigV4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Clone request to avoid modifying the original
+	req = req.Clone(req.Context())
+	if err := t.signer.SignRequest(req.Context(), req, t.region, t.service); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// AWSResource wraps a SigV4-signing HTTP client
+type AWSResource struct {
+	client     *http.Client
+	baseURL    string
+	resourceID string
+	region     string
+	service    string
+}
+
+func (r *AWSResource) Close() error {
+	r.client.CloseIdleConnections()
+	return nil
+}
+
+func (r *AWSResource) Type() string {
+	return "aws"
+}
+
+// Client returns the signing HTTP client
+func (r *AWSResource) Client() *http.Client {
+	return r.client
+}
+
+// BaseURL returns the API root
+func (r *AWSResource) BaseURL() string {
+	return r.baseURL
+}
+
+// Region returns the signing region
+func (r *AWSResource) Region() string {
+	return r.region
+}
+
+// Service returns the signing name
+func (r *AWSResource) Service() string {
+	return r.service
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// AWS SigV4 test suite "get-vanilla"
+func TestSignSigV4_GetVanilla(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	creds := AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	signSigV4(req, creds, "us-east-1", "service", sha256Hex(nil), now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAWSSigV4Provider_SignsRequests(t *testing.T) {
+	var gotAuth, gotToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotToken = r.Header.Get("X-Amz-Security-Token")
+	}))
+	defer server.Close()
+
+	provider := NewAWSSigV4Provider("aws", AWSSigV4Config{
+		Region:          "eu-west-1",
+		Service:         "dynamodb",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+	})
+	provider.RegisterResource(ResourceConfig{ID: "ddb", Type: "api", Config: map[string]interface{}{"base_url": server.URL}})
+
+	res, err := provider.GetResource(context.Background(), "ddb")
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	awsRes := res.(*AWSResource)
+
+	resp, err := awsRes.Client().Post(awsRes.BaseURL(), "application/x-amz-json-1.0", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(gotAuth, "/eu-west-1/dynamodb/aws4_request") ||
+		!strings.Contains(gotAuth, "x-amz-security-token") {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotToken != "session" {
+		t.Errorf("X-Amz-Security-Token = %q", gotToken)
+	}
+}
+
+func TestAWSSigV4Provider_RoundTripper(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	provider := NewAWSSigV4Provider("aws", AWSSigV4Config{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret"})
+	if _, err := provider.RoundTripper(context.Background(), "", nil); err == nil {
+		t.Error("RoundTripper without a service succeeded")
+	}
+	provider.RegisterResource(ResourceConfig{ID: "s3", Config: map[string]interface{}{"service": "s3", "region": "eu-west-2"}})
+
+	client, err := HTTPClient(context.Background(), provider, "s3")
+	if err != nil {
+		t.Fatalf("HTTPClient failed: %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !strings.Contains(gotAuth, "/eu-west-2/s3/aws4_request") {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+
+	creds, err := provider.Credentials(context.Background())
+	if err != nil || creds.AccessKeyID != "AKID" {
+		t.Errorf("Credentials = %+v, %v", creds, err)
+	}
+}
+
+func TestAWSCredentialsChain(t *testing.T) {
+	dir := t.TempDir()
+	credsFile := filepath.Join(dir, "credentials")
+	os.WriteFile(credsFile, []byte("[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = s1\n\n[ci]\naws_access_key_id=AKIDCI\naws_secret_access_key=s2\n"), 0600)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_ROLE_ARN", "")
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsFile)
+	configFile := filepath.Join(dir, "config")
+	os.WriteFile(configFile, []byte("[profile sso]\nregion = eu-central-1\naws_access_key_id = AKIDCONFIG\naws_secret_access_key = s4\n"), 0600)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	ctx := context.Background()
+	creds, err := DefaultAWSCredentialsChain("ci", "").Retrieve(ctx)
+	if err != nil || creds.AccessKeyID != "AKIDCI" || creds.Source != "shared:ci" {
+		t.Errorf("shared profile: %+v, %v", creds, err)
+	}
+	creds, err = DefaultAWSCredentialsChain("sso", "").Retrieve(ctx)
+	if err != nil || creds.AccessKeyID != "AKIDCONFIG" || creds.Source != "config:sso" {
+		t.Errorf("config file profile: %+v, %v", creds, err)
+	}
+	if _, err := DefaultAWSCredentialsChain("missing", "").Retrieve(ctx); !errors.Is(err, ErrNoAWSCredentials) {
+		t.Errorf("missing profile: %v", err)
+	}
+	if region := sharedConfigRegion("sso"); region != "eu-central-1" {
+		t.Errorf("profile region = %q", region)
+	}
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "s3")
+	creds, err = DefaultAWSCredentialsChain("ci", "").Retrieve(ctx)
+	if err != nil || creds.AccessKeyID != "AKIDENV" {
+		t.Errorf("env should take precedence: %+v, %v", creds, err)
+	}
+}
+
+func TestWebIdentityAWSCredentials(t *testing.T) {
+	sts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("Action") != "AssumeRoleWithWebIdentity" || r.Form.Get("WebIdentityToken") != "jwt" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`<AssumeRoleWithWebIdentityResponse><AssumeRoleWithWebIdentityResult><Credentials>
+<AccessKeyId>ASIA</AccessKeyId><SecretAccessKey>secret</SecretAccessKey><SessionToken>token</SessionToken>
+<Expiration>2030-01-01T00:00:00Z</Expiration></Credentials></AssumeRoleWithWebIdentityResult></AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer sts.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(tokenFile, []byte("jwt\n"), 0600)
+
+	creds, err := WebIdentityAWSCredentials{
+		RoleARN:   "arn:aws:iam::123456789012:role/mcp",
+		TokenFile: tokenFile,
+		Endpoint:  sts.URL,
+	}.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve failed: %v", err)
+	}
+	if creds.AccessKeyID != "ASIA" || creds.SessionToken != "token" || creds.Expires.Year() != 2030 {
+		t.Errorf("creds = %+v", creds)
+	}
+}
@@ -0,0 +1,267 @@
+// auth/credentials.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrCredentialsUnsupported indicates a provider does not hand out its
+// credentials in the requested form
+var ErrCredentialsUnsupported = errors.New("credentials not exposed by provider")
+
+// redacted replaces a Secret's value wherever it is printed
+const redacted = "[REDACTED]"
+
+// Secret is a credential handed to a backend
+//
+// The value is only returned by Reveal: printing, logging or marshaling a
+// Secret shows "[REDACTED]", so it cannot leak by accident.
+type Secret struct {
+	value string
+
+	// Header is the header the provider sends the credential in (e.g.
+	// "X-API-Key" or "Authorization")
+	Header string
+
+	// Scheme prefixes the value in the header, e.g. "Bearer"; empty for
+	// bare keys
+	Scheme string
+
+	// KeyID names the credential, e.g. the APIKey.ID in use
+	KeyID string
+
+	// ExpiresAt is when the credential stops working (zero: unknown)
+	ExpiresAt time.Time
+}
+
+// NewSecret wraps a credential value
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Reveal returns the credential
+func (s Secret) Reveal() string {
+	return s.value
+}
+
+// HeaderValue returns the header value: the credential after its scheme
+func (s Secret) HeaderValue() string {
+	if s.Scheme == "" {
+		return s.value
+	}
+	return s.Scheme + " " + s.value
+}
+
+// IsZero reports whether the secret is empty
+func (s Secret) IsZero() bool {
+	return s.value == ""
+}
+
+// String and GoString hide the value from fmt
+func (s Secret) String() string   { return redacted }
+func (s Secret) GoString() string { return redacted }
+
+// LogValue implements slog.LogValuer
+func (s Secret) LogValue() slog.Value { return slog.StringValue(redacted) }
+
+// MarshalJSON implements json.Marshaler
+func (s Secret) MarshalJSON() ([]byte, error) { return []byte(`"` + redacted + `"`), nil }
+
+// MarshalText implements encoding.TextMarshaler, used by YAML and other
+// encoders
+func (s Secret) MarshalText() ([]byte, error) { return []byte(redacted), nil }
+
+// CredentialSource is implemented by providers that hand their credential
+// to backends that must place it themselves: in a query parameter, an SDK
+// or a signature
+//
+// Prefer TransportSource where it fits: credentials handed out miss
+// rotation and refreshes, so fetch them per call rather than keeping them.
+type CredentialSource interface {
+	// GetSecret returns the credential for resourceID ("" for the
+	// provider's own)
+	GetSecret(ctx context.Context, resourceID string) (Secret, error)
+}
+
+// TransportSource is implemented by providers that authenticate requests
+// sent through an http.RoundTripper, so credentials never leave them
+type TransportSource interface {
+	// RoundTripper authenticates requests for resourceID ("" for the
+	// provider's own) and sends them with base
+	RoundTripper(ctx context.Context, resourceID string, base http.RoundTripper) (http.RoundTripper, error)
+}
+
+// HTTPResource is implemented by resources exposing an authenticated
+// HTTP client, such as *OAuth2Resource and *StaticHeaderResource
+type HTTPResource interface {
+	Client() *http.Client
+}
+
+// BaseURLResource is implemented by resources with an API base URL
+type BaseURLResource interface {
+	BaseURL() string
+}
+
+// ResourceHTTPClient returns the authenticated client of a resource and
+// its base URL ("" if it has none), for backends calling HTTP APIs with
+// the resource bound to a tool (see ResourceFromContext)
+// Fails with ErrCredentialsUnsupported if resource is not an HTTP
+// resource.
+func ResourceHTTPClient(resource Resource) (*http.Client, string, error) {
+	authenticated, ok := resource.(HTTPResource)
+	if !ok {
+		return nil, "", fmt.Errorf("%s resource is not an HTTP client: %w", resource.Type(), ErrCredentialsUnsupported)
+	}
+	var baseURL string
+	if r, ok := resource.(BaseURLResource); ok {
+		baseURL = r.BaseURL()
+	}
+	return authenticated.Client(), baseURL, nil
+}
+
+// GetSecret gets the credential of provider
+// Fails with ErrCredentialsUnsupported if the provider doesn't expose it.
+func GetSecret(ctx context.Context, provider AuthProvider, resourceID string) (Secret, error) {
+	source, ok := provider.(CredentialSource)
+	if !ok {
+		return Secret{}, NewAuthError(provider.Name(), resourceID, "get_secret", ErrCredentialsUnsupported)
+	}
+	return source.GetSecret(ctx, resourceID)
+}
+
+// HTTPClient returns an HTTP client whose requests provider authenticates
+// Unlike GetResource, it needs no base_url, and with resourceID "" no
+// registered resource.
+func HTTPClient(ctx context.Context, provider AuthProvider, resourceID string) (*http.Client, error) {
+	source, ok := provider.(TransportSource)
+	if !ok {
+		return nil, NewAuthError(provider.Name(), resourceID, "http_client", ErrCredentialsUnsupported)
+	}
+	transport, err := source.RoundTripper(ctx, resourceID, http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}, nil
+}
+
+// GetSecret gets the credential of a specific provider
+func (m *Manager) GetSecret(ctx context.Context, providerName, resourceID string) (Secret, error) {
+	provider, err := m.Get(providerName)
+	if err != nil {
+		return Secret{}, err
+	}
+	return GetSecret(ctx, provider, resourceID)
+}
+
+// HTTPClient returns an HTTP client authenticated by a specific provider
+func (m *Manager) HTTPClient(ctx context.Context, providerName, resourceID string) (*http.Client, error) {
+	provider, err := m.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	return HTTPClient(ctx, provider, resourceID)
+}
+
+// checkResource verifies a resource credentials are requested for is
+// registered; "" is the provider's own
+func (p *BaseProvider) checkResource(resourceID, op string) error {
+	if resourceID == "" {
+		return nil
+	}
+	if _, err := p.GetResourceConfig(resourceID); err != nil {
+		return NewAuthError(p.Name(), resourceID, op, err)
+	}
+	return nil
+}
+
+// ============================================================
+// Provider implementations
+// ============================================================
+
+// GetSecret returns the API key in use
+// With several keys, fetch it per call: it changes on rotation.
+func (p *APIKeyProvider) GetSecret(ctx context.Context, resourceID string) (Secret, error) {
+	if err := p.checkResource(resourceID, "get_secret"); err != nil {
+		return Secret{}, err
+	}
+	key, ok := p.keys.pick(nil)
+	if !ok {
+		if err := p.keys.reload(ctx); err == nil {
+			key, ok = p.keys.pick(nil)
+		}
+	}
+	if !ok {
+		return Secret{}, NewAuthError(p.Name(), resourceID, "get_secret", ErrInvalidCredentials)
+	}
+	return Secret{value: key.Key, Header: p.header, KeyID: key.ID, ExpiresAt: key.ExpiresAt}, nil
+}
+
+// RoundTripper sends the API key, rotating keys on 401
+func (p *APIKeyProvider) RoundTripper(ctx context.Context, resourceID string, base http.RoundTripper) (http.RoundTripper, error) {
+	if err := p.checkResource(resourceID, "round_tripper"); err != nil {
+		return nil, err
+	}
+	return &apiKeyTransport{base: base, keys: p.keys, header: p.header}, nil
+}
+
+// RoundTripper adds the configured headers, and the resource's
+func (p *StaticHeaderProvider) RoundTripper(ctx context.Context, resourceID string, base http.RoundTripper) (http.RoundTripper, error) {
+	headers := p.headers.Clone()
+	if resourceID != "" {
+		config, err := p.GetResourceConfig(resourceID)
+		if err != nil {
+			return nil, NewAuthError(p.Name(), resourceID, "round_tripper", err)
+		}
+		if extra, ok := config.Config["headers"]; ok {
+			if err := mergeHeaderConfig(headers, extra); err != nil {
+				return nil, NewAuthError(p.Name(), resourceID, "round_tripper", err)
+			}
+		}
+	}
+	return &staticHeaderTransport{base: base, headers: headers}, nil
+}
+
+// GetSecret returns the current access token, refreshed if it expired
+func (p *OAuth2Provider) GetSecret(ctx context.Context, resourceID string) (Secret, error) {
+	if err := p.checkResource(resourceID, "get_secret"); err != nil {
+		return Secret{}, err
+	}
+	if err := p.ensureValidToken(ctx); err != nil {
+		return Secret{}, NewAuthError(p.Name(), resourceID, "get_secret", err)
+	}
+	scheme := p.token.TokenType
+	if scheme == "" || strings.EqualFold(scheme, "bearer") {
+		scheme = "Bearer"
+	}
+	return Secret{
+		value:     p.token.AccessToken,
+		Header:    "Authorization",
+		Scheme:    scheme,
+		ExpiresAt: p.token.ExpiresAt,
+	}, nil
+}
+
+// RoundTripper sends the access token, refreshing it as it expires
+func (p *OAuth2Provider) RoundTripper(ctx context.Context, resourceID string, base http.RoundTripper) (http.RoundTripper, error) {
+	if err := p.checkResource(resourceID, "round_tripper"); err != nil {
+		return nil, err
+	}
+	if err := p.ensureValidToken(ctx); err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "round_tripper", err)
+	}
+	token := &oauth2.Token{
+		AccessToken:  p.token.AccessToken,
+		RefreshToken: p.token.RefreshToken,
+		TokenType:    p.token.TokenType,
+		Expiry:       p.token.ExpiresAt,
+	}
+	return &oauth2.Transport{Source: p.config.TokenSource(ctx, token), Base: base}, nil
+}
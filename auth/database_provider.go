@@ -5,90 +5,293 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// Default connection pool settings
+const (
+	DefaultDBMaxOpenConns    = 25
+	DefaultDBMaxIdleConns    = 5
+	DefaultDBConnMaxLifetime = 5 * time.Minute
 )
 
 // DatabaseProvider authenticates to databases
+//
+// Each resource is one database; its config holds the connection
+// settings (see DatabaseConfig), for example:
+//
+//	resources:
+//	  main-db:
+//	    type: database
+//	    config:
+//	      driver: postgres
+//	      host: db.internal
+//	      port: 5432
+//	      database: app
+//	      username: app
+//	      password: ${DB_PASSWORD}
+//	      ssl_mode: verify-full
+//	      tls:
+//	        ca_file: /etc/ssl/db-ca.pem
+//	  cache-db:
+//	    type: database
+//	    config:
+//	      driver: sqlite3
+//	      path: /var/lib/app/cache.db
+//
+// The database/sql drivers are not linked in; import the ones the
+// resources name:
+//
+//	import _ "github.com/lib/pq"              // driver: postgres
+//	import _ "github.com/jackc/pgx/v5/stdlib" // driver: pgx
+//	import _ "github.com/go-sql-driver/mysql" // driver: mysql
+//	import _ "github.com/mattn/go-sqlite3"    // driver: sqlite3
+//	import _ "modernc.org/sqlite"             // driver: sqlite
 type DatabaseProvider struct {
 	*BaseProvider
+
+	mu          sync.Mutex
+	configs     map[string]*DatabaseConfig
 	connections map[string]*sql.DB
 }
 
 // DatabaseConfig holds database provider configuration
 type DatabaseConfig struct {
-	Driver   string `yaml:"driver" json:"driver"` // postgres, mysql, sqlite
+	Driver string `yaml:"driver" json:"driver"` // postgres, pgx, mysql, sqlite3, sqlite
+
+	// DSN is a complete driver connection string; when set, the
+	// connection fields below are ignored
+	DSN string `yaml:"dsn" json:"dsn"`
+
 	Host     string `yaml:"host" json:"host"`
 	Port     int    `yaml:"port" json:"port"`
 	Database string `yaml:"database" json:"database"`
 	Username string `yaml:"username" json:"username"`
 	Password string `yaml:"password" json:"password"`
 
-	// Connection pool settings
+	// Path is the SQLite database file (or ":memory:")
+	Path string `yaml:"path" json:"path"`
+
+	// SSLMode is disable (the default), require, verify-ca or verify-full
+	SSLMode string            `yaml:"ssl_mode" json:"ssl_mode"`
+	TLS     DatabaseTLSConfig `yaml:"tls" json:"tls"`
+
+	// Params are extra driver parameters appended to the connection string
+	Params map[string]string `yaml:"params" json:"params"`
+
+	// Connection pool settings; zero selects the defaults
 	MaxOpenConns    int           `yaml:"max_open_conns" json:"max_open_conns"`
 	MaxIdleConns    int           `yaml:"max_idle_conns" json:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" json:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" json:"conn_max_idle_time"`
+}
+
+// DatabaseTLSConfig names the certificate files of a TLS connection
+// Only postgres reads them; a mysql TLS config must be registered with
+// the driver and named in params ("tls": "<name>").
+type DatabaseTLSConfig struct {
+	CAFile   string `yaml:"ca_file" json:"ca_file"`
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+}
+
+// IsSQLite reports whether the config names a SQLite driver
+func (c *DatabaseConfig) IsSQLite() bool {
+	return c.Driver == "sqlite3" || c.Driver == "sqlite"
+}
+
+// Validate checks the configuration
+func (c *DatabaseConfig) Validate() error {
+	switch c.Driver {
+	case "":
+		return fmt.Errorf("driver is required")
+	case "postgres", "pgx", "mysql", "sqlite3", "sqlite":
+	default:
+		return fmt.Errorf("unsupported driver: %s", c.Driver)
+	}
+
+	switch {
+	case c.DSN != "":
+	case c.IsSQLite():
+		if c.Path == "" && c.Database == "" {
+			return fmt.Errorf("%s: path is required", c.Driver)
+		}
+	default:
+		if c.Host == "" {
+			return fmt.Errorf("%s: host is required", c.Driver)
+		}
+		if c.Database == "" {
+			return fmt.Errorf("%s: database is required", c.Driver)
+		}
+	}
+
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("port %d out of range", c.Port)
+	}
+	switch c.SSLMode {
+	case "", "disable", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("unknown ssl_mode %q (want disable, require, verify-ca or verify-full)", c.SSLMode)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("tls: cert_file and key_file must be set together")
+	}
+	if c.TLS != (DatabaseTLSConfig{}) && c.Driver == "mysql" {
+		return fmt.Errorf("mysql: register a TLS config with the driver and name it in params.tls instead of tls files")
+	}
+
+	if c.MaxOpenConns < 0 || c.MaxIdleConns < 0 || c.ConnMaxLifetime < 0 || c.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("pool settings must not be negative")
+	}
+	if c.MaxOpenConns > 0 && c.MaxIdleConns > c.MaxOpenConns {
+		return fmt.Errorf("max_idle_conns (%d) exceeds max_open_conns (%d)", c.MaxIdleConns, c.MaxOpenConns)
+	}
+	return nil
+}
+
+// withDefaults fills in the pool settings left zero
+func (c DatabaseConfig) withDefaults() *DatabaseConfig {
+	if c.MaxOpenConns == 0 {
+		c.MaxOpenConns = DefaultDBMaxOpenConns
+	}
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = min(DefaultDBMaxIdleConns, c.MaxOpenConns)
+	}
+	if c.ConnMaxLifetime == 0 {
+		c.ConnMaxLifetime = DefaultDBConnMaxLifetime
+	}
+	return &c
 }
 
 // NewDatabaseProvider creates a new database provider
 func NewDatabaseProvider(name string) *DatabaseProvider {
 	return &DatabaseProvider{
 		BaseProvider: NewBaseProvider(name),
+		configs:      make(map[string]*DatabaseConfig),
 		connections:  make(map[string]*sql.DB),
 	}
 }
 
+// RegisterDatabase registers a database resource from a typed config
+// Resources registered with RegisterResource are parsed from their config map.
+func (p *DatabaseProvider) RegisterDatabase(resourceID string, config DatabaseConfig) error {
+	if err := config.Validate(); err != nil {
+		return NewAuthError(p.Name(), resourceID, "register", err)
+	}
+
+	p.mu.Lock()
+	p.configs[resourceID] = config.withDefaults()
+	p.mu.Unlock()
+
+	p.RegisterResource(ResourceConfig{ID: resourceID, Type: "database"})
+	return nil
+}
+
 // GetResource returns a database connection
 func (p *DatabaseProvider) GetResource(ctx context.Context, resourceID string) (Resource, error) {
-	// Check if we already have a connection
-	if db, exists := p.connections[resourceID]; exists {
+	// Check if we already have a connection; pinged unlocked, as a busy
+	// pool makes the ping wait for a connection
+	p.mu.Lock()
+	db, exists := p.connections[resourceID]
+	p.mu.Unlock()
+	if exists {
 		// Ping to verify connection is alive
 		if err := db.PingContext(ctx); err == nil {
 			return &DatabaseResource{db: db, resourceID: resourceID}, nil
 		}
 		// Connection is dead, close and remove it
-		db.Close()
-		delete(p.connections, resourceID)
+		p.mu.Lock()
+		if p.connections[resourceID] == db {
+			p.closeConnection(resourceID, db)
+		}
+		p.mu.Unlock()
 	}
 
-	// Get resource config
-	config, err := p.GetResourceConfig(resourceID)
+	db, err := p.connect(ctx, resourceID)
 	if err != nil {
 		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
 	}
+	return &DatabaseResource{db: db, resourceID: resourceID}, nil
+}
 
-	// Parse database config
-	dbConfig, err := parseDatabaseConfig(config.Config)
+// connect opens the connection pool of a resource
+func (p *DatabaseProvider) connect(ctx context.Context, resourceID string) (*sql.DB, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Another caller may have connected meanwhile
+	if db, exists := p.connections[resourceID]; exists {
+		return db, nil
+	}
+
+	dbConfig, err := p.databaseConfig(resourceID)
 	if err != nil {
-		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+		return nil, err
 	}
 
 	// Build connection string based on driver
 	connStr, err := buildConnectionString(dbConfig)
 	if err != nil {
-		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+		return nil, err
 	}
 
 	// Open database connection
 	db, err := sql.Open(dbConfig.Driver, connStr)
 	if err != nil {
-		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+		return nil, err
 	}
 
 	// Configure connection pool
 	db.SetMaxOpenConns(dbConfig.MaxOpenConns)
 	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
 	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
 
 	// Verify connection
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+		return nil, err
 	}
 
 	// Store connection for reuse
 	p.connections[resourceID] = db
+	observability.RegisterDBPool(p.Name(), resourceID, db.Stats)
+	return db, nil
+}
 
-	return &DatabaseResource{db: db, resourceID: resourceID}, nil
+// databaseConfig returns the parsed config of a resource
+// Must be called with p.mu held.
+func (p *DatabaseProvider) databaseConfig(resourceID string) (*DatabaseConfig, error) {
+	if config, ok := p.configs[resourceID]; ok {
+		return config, nil
+	}
+
+	resource, err := p.GetResourceConfig(resourceID)
+	if err != nil {
+		return nil, err
+	}
+	config, err := parseDatabaseConfig(resource.Config)
+	if err != nil {
+		return nil, err
+	}
+	p.configs[resourceID] = config
+	return config, nil
+}
+
+// closeConnection closes and forgets a connection
+// Must be called with p.mu held.
+func (p *DatabaseProvider) closeConnection(resourceID string, db *sql.DB) error {
+	delete(p.connections, resourceID)
+	observability.UnregisterDBPool(p.Name(), resourceID)
+	return db.Close()
 }
 
 // Validate checks if we can connect to the database
@@ -101,11 +304,65 @@ func (p *DatabaseProvider) Validate(ctx context.Context) error {
 	return nil
 }
 
+// RegisterHealthChecks implements health.Reporter
+// Every database gets a readiness check that connects and pings it; a
+// pool with all connections in use and callers waiting degrades it.
+func (p *DatabaseProvider) RegisterHealthChecks(r *health.Registry) {
+	for _, resourceID := range p.ListResources() {
+		resourceID := resourceID
+		r.RegisterReadiness("database:"+p.Name()+":"+resourceID, func(ctx context.Context) error {
+			return p.checkDatabase(ctx, resourceID)
+		})
+	}
+}
+
+// checkDatabase is the readiness check of one database
+// The pool is looked at before pinging: the ping of an exhausted pool
+// would wait for a connection.
+func (p *DatabaseProvider) checkDatabase(ctx context.Context, resourceID string) error {
+	p.mu.Lock()
+	db := p.connections[resourceID]
+	p.mu.Unlock()
+	if db != nil {
+		if err := poolExhausted(db.Stats()); err != nil {
+			return health.Degraded(err)
+		}
+	}
+
+	_, err := p.GetResource(ctx, resourceID)
+	return err
+}
+
+// poolExhausted reports a pool with every connection in use and callers
+// having waited for one
+func poolExhausted(stats sql.DBStats) error {
+	if stats.MaxOpenConnections == 0 || stats.InUse < stats.MaxOpenConnections || stats.WaitCount == 0 {
+		return nil
+	}
+	return fmt.Errorf("connection pool exhausted: %d/%d in use, %d waits totalling %s",
+		stats.InUse, stats.MaxOpenConnections, stats.WaitCount, stats.WaitDuration.Round(time.Millisecond))
+}
+
+// Stats returns the pool statistics of the open connections by resource
+func (p *DatabaseProvider) Stats() map[string]sql.DBStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make(map[string]sql.DBStats, len(p.connections))
+	for id, db := range p.connections {
+		stats[id] = db.Stats()
+	}
+	return stats
+}
+
 // Close closes all database connections
 func (p *DatabaseProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	var errs []error
 	for id, db := range p.connections {
-		if err := db.Close(); err != nil {
+		if err := p.closeConnection(id, db); err != nil {
 			errs = append(errs, fmt.Errorf("resource %q: %w", id, err))
 		}
 	}
@@ -139,25 +396,251 @@ func (r *DatabaseResource) DB() *sql.DB {
 
 // Helper functions
 
+// parseDatabaseConfig reads and validates a resource's config map
+// Numbers and durations may be given as strings, as environment
+// variable substitution produces; durations as plain numbers are seconds.
 func parseDatabaseConfig(config map[string]interface{}) (*DatabaseConfig, error) {
-	// TODO: Implement proper parsing with validation
-	// For now, return a basic config
-	return &DatabaseConfig{
-		MaxOpenConns:    25,
-		MaxIdleConns:    5,
-		ConnMaxLifetime: 5 * time.Minute,
-	}, nil
+	var c DatabaseConfig
+	var err error
+	str := func(key string, dst *string) {
+		if err == nil {
+			*dst, err = configString(config, key)
+		}
+	}
+	num := func(key string, dst *int) {
+		if err == nil {
+			*dst, err = configInt(config, key)
+		}
+	}
+	dur := func(key string, dst *time.Duration) {
+		if err == nil {
+			*dst, err = configDuration(config, key)
+		}
+	}
+
+	str("driver", &c.Driver)
+	str("dsn", &c.DSN)
+	str("host", &c.Host)
+	num("port", &c.Port)
+	str("database", &c.Database)
+	str("username", &c.Username)
+	if c.Username == "" {
+		str("user", &c.Username)
+	}
+	str("password", &c.Password)
+	str("path", &c.Path)
+	str("ssl_mode", &c.SSLMode)
+	num("max_open_conns", &c.MaxOpenConns)
+	num("max_idle_conns", &c.MaxIdleConns)
+	dur("conn_max_lifetime", &c.ConnMaxLifetime)
+	dur("conn_max_idle_time", &c.ConnMaxIdleTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if v, ok := config["tls"]; ok && v != nil {
+		tls, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("tls: want a map, got %T", v)
+		}
+		for key, dst := range map[string]*string{"ca_file": &c.TLS.CAFile, "cert_file": &c.TLS.CertFile, "key_file": &c.TLS.KeyFile} {
+			if *dst, err = configString(tls, key); err != nil {
+				return nil, fmt.Errorf("tls.%w", err)
+			}
+		}
+	}
+
+	if v, ok := config["params"]; ok && v != nil {
+		params, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("params: want a map, got %T", v)
+		}
+		c.Params = make(map[string]string, len(params))
+		for key, value := range params {
+			c.Params[key] = fmt.Sprint(value)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c.withDefaults(), nil
 }
 
+// configString reads an optional string
+func configString(config map[string]interface{}, key string) (string, error) {
+	switch v := config[key].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("%s: want a string, got %T", key, v)
+	}
+}
+
+// configInt reads an optional integer
+func configInt(config map[string]interface{}, key string) (int, error) {
+	switch v := config[key].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("%s: want an integer, got %v", key, v)
+		}
+		return int(v), nil
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("%s: want an integer, got %q", key, v)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%s: want an integer, got %T", key, v)
+	}
+}
+
+// configDuration reads an optional duration ("30s", "5m") or seconds
+func configDuration(config map[string]interface{}, key string) (time.Duration, error) {
+	switch v := config[key].(type) {
+	case nil:
+		return 0, nil
+	case time.Duration:
+		return v, nil
+	case string:
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", key, err)
+		}
+		return d, nil
+	default:
+		seconds, err := configInt(config, key)
+		if err != nil {
+			return 0, fmt.Errorf("%s: want a duration, got %T", key, v)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+}
+
+// buildConnectionString returns the driver connection string of config
 func buildConnectionString(config *DatabaseConfig) (string, error) {
+	if config.DSN != "" {
+		return config.DSN, nil
+	}
+
 	switch config.Driver {
-	case "postgres":
-		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-			config.Host, config.Port, config.Username, config.Password, config.Database), nil
+	case "postgres", "pgx":
+		return postgresConnectionString(config), nil
 	case "mysql":
-		return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s",
-			config.Username, config.Password, config.Host, config.Port, config.Database), nil
+		return mysqlConnectionString(config), nil
+	case "sqlite3", "sqlite":
+		return sqliteConnectionString(config), nil
 	default:
 		return "", fmt.Errorf("unsupported driver: %s", config.Driver)
 	}
 }
+
+// postgresConnectionString builds a libpq key=value string
+func postgresConnectionString(config *DatabaseConfig) string {
+	sslMode := config.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	port := config.Port
+	if port == 0 {
+		port = 5432
+	}
+
+	pairs := []string{
+		"host=" + quotePostgresValue(config.Host),
+		"port=" + strconv.Itoa(port),
+		"dbname=" + quotePostgresValue(config.Database),
+		"sslmode=" + sslMode,
+	}
+	if config.Username != "" {
+		pairs = append(pairs, "user="+quotePostgresValue(config.Username))
+	}
+	if config.Password != "" {
+		pairs = append(pairs, "password="+quotePostgresValue(config.Password))
+	}
+	tls := map[string]string{"sslrootcert": config.TLS.CAFile, "sslcert": config.TLS.CertFile, "sslkey": config.TLS.KeyFile}
+	for _, key := range []string{"sslrootcert", "sslcert", "sslkey"} {
+		if tls[key] != "" {
+			pairs = append(pairs, key+"="+quotePostgresValue(tls[key]))
+		}
+	}
+	for _, key := range sortedKeys(config.Params) {
+		pairs = append(pairs, key+"="+quotePostgresValue(config.Params[key]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// quotePostgresValue quotes a key=value string value when it needs it
+func quotePostgresValue(s string) string {
+	if s != "" && !strings.ContainsAny(s, ` '\`) {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+// mysqlConnectionString builds a go-sql-driver/mysql DSN
+func mysqlConnectionString(config *DatabaseConfig) string {
+	port := config.Port
+	if port == 0 {
+		port = 3306
+	}
+
+	params := url.Values{}
+	switch config.SSLMode {
+	case "require":
+		params.Set("tls", "skip-verify")
+	case "verify-ca", "verify-full":
+		params.Set("tls", "true")
+	}
+	for key, value := range config.Params {
+		params.Set(key, value)
+	}
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s",
+		config.Username, config.Password, config.Host, port, config.Database)
+	if len(params) > 0 {
+		dsn += "?" + params.Encode()
+	}
+	return dsn
+}
+
+// sqliteConnectionString builds a SQLite file name, a file: URI when
+// there are parameters
+func sqliteConnectionString(config *DatabaseConfig) string {
+	path := config.Path
+	if path == "" {
+		path = config.Database
+	}
+	if len(config.Params) == 0 {
+		return path
+	}
+
+	params := url.Values{}
+	for key, value := range config.Params {
+		params.Set(key, value)
+	}
+	return "file:" + strings.TrimPrefix(path, "file:") + "?" + params.Encode()
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestParseDatabaseConfig(t *testing.T) {
+	config, err := parseDatabaseConfig(map[string]interface{}{
+		"driver":             "postgres",
+		"host":               "db.internal",
+		"port":               "5433", // from ${DB_PORT}
+		"database":           "app",
+		"user":               "svc",
+		"password":           "p@ss word",
+		"ssl_mode":           "verify-full",
+		"tls":                map[string]interface{}{"ca_file": "/etc/ssl/ca.pem"},
+		"params":             map[string]interface{}{"application_name": "mcp", "connect_timeout": 5},
+		"max_open_conns":     10,
+		"conn_max_lifetime":  "1h",
+		"conn_max_idle_time": 30,
+	})
+	if err != nil {
+		t.Fatalf("parseDatabaseConfig failed: %v", err)
+	}
+	if config.Port != 5433 || config.Username != "svc" || config.TLS.CAFile != "/etc/ssl/ca.pem" || config.Params["connect_timeout"] != "5" {
+		t.Errorf("parsed %+v", config)
+	}
+	if config.MaxOpenConns != 10 || config.MaxIdleConns != DefaultDBMaxIdleConns ||
+		config.ConnMaxLifetime != time.Hour || config.ConnMaxIdleTime != 30*time.Second {
+		t.Errorf("pool settings %+v", config)
+	}
+
+	dsn, _ := buildConnectionString(config)
+	want := "host=db.internal port=5433 dbname=app sslmode=verify-full user=svc password='p@ss word' sslrootcert=/etc/ssl/ca.pem application_name=mcp connect_timeout=5"
+	if dsn != want {
+		t.Errorf("dsn = %q\nwant  %q", dsn, want)
+	}
+}
+
+func TestParseDatabaseConfig_Invalid(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"no driver":       {"host": "db", "database": "app"},
+		"unknown driver":  {"driver": "oracle", "host": "db", "database": "app"},
+		"no host":         {"driver": "mysql", "database": "app"},
+		"sqlite no path":  {"driver": "sqlite3"},
+		"bad port":        {"driver": "postgres", "host": "db", "database": "app", "port": "fivefour"},
+		"port range":      {"driver": "postgres", "host": "db", "database": "app", "port": 70000},
+		"wrong type":      {"driver": "postgres", "host": 42, "database": "app"},
+		"ssl mode":        {"driver": "postgres", "host": "db", "database": "app", "ssl_mode": "maybe"},
+		"cert no key":     {"driver": "postgres", "host": "db", "database": "app", "tls": map[string]interface{}{"cert_file": "c.pem"}},
+		"mysql tls files": {"driver": "mysql", "host": "db", "database": "app", "tls": map[string]interface{}{"ca_file": "ca.pem"}},
+		"idle over open":  {"driver": "postgres", "host": "db", "database": "app", "max_open_conns": 2, "max_idle_conns": 5},
+		"bad duration":    {"driver": "postgres", "host": "db", "database": "app", "conn_max_lifetime": "soon"},
+	}
+	for name, config := range tests {
+		if _, err := parseDatabaseConfig(config); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestBuildConnectionString(t *testing.T) {
+	tests := []struct {
+		config DatabaseConfig
+		want   string
+	}{
+		{DatabaseConfig{Driver: "pgx", DSN: "postgres://u:p@db/app"}, "postgres://u:p@db/app"},
+		{DatabaseConfig{Driver: "postgres", Host: "db", Database: "app"}, "host=db port=5432 dbname=app sslmode=disable"},
+		{DatabaseConfig{Driver: "mysql", Host: "db", Database: "app", Username: "u", Password: "p", SSLMode: "require"}, "u:p@tcp(db:3306)/app?tls=skip-verify"},
+		{DatabaseConfig{Driver: "sqlite3", Path: "/data/app.db"}, "/data/app.db"},
+		{DatabaseConfig{Driver: "sqlite", Path: "app.db", Params: map[string]string{"mode": "ro"}}, "file:app.db?mode=ro"},
+	}
+	for _, tt := range tests {
+		got, err := buildConnectionString(&tt.config)
+		if err != nil || got != tt.want {
+			t.Errorf("buildConnectionString(%+v) = %q, %v; want %q", tt.config, got, err, tt.want)
+		}
+	}
+}
+
+func TestDatabaseProvider_SQLite(t *testing.T) {
+	ctx := context.Background()
+	p := NewDatabaseProvider("db")
+	defer p.Close()
+	p.RegisterResource(ResourceConfig{ID: "main", Type: "database", Config: map[string]interface{}{
+		"driver":         "sqlite3",
+		"path":           filepath.Join(t.TempDir(), "app.db"),
+		"max_open_conns": 1,
+	}})
+
+	resource, err := p.GetResource(ctx, "main")
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	db := resource.(*DatabaseResource).DB()
+	if _, err := db.ExecContext(ctx, "CREATE TABLE t (v INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := p.GetResource(ctx, "main"); again.(*DatabaseResource).DB() != db {
+		t.Error("connection not reused")
+	}
+	if stats := p.Stats()["main"]; stats.MaxOpenConnections != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+
+	registry := health.NewRegistry()
+	p.RegisterHealthChecks(registry)
+	if report := registry.Run(ctx, health.Readiness); len(report.Checks) != 1 ||
+		report.Checks[0].Name != "database:db:main" || report.Checks[0].Error != "" {
+		t.Errorf("readiness report %+v", report)
+	}
+
+	// Hold the only connection and make a caller wait for it
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	db.PingContext(waitCtx)
+	cancel()
+	checkCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := p.checkDatabase(checkCtx, "main"); !health.IsDegraded(err) || !strings.Contains(err.Error(), "exhausted") {
+		t.Errorf("exhausted pool: %v", err)
+	}
+	conn.Close()
+}
+
+func TestDatabaseProvider_RegisterDatabase(t *testing.T) {
+	p := NewDatabaseProvider("db")
+	defer p.Close()
+	if err := p.RegisterDatabase("bad", DatabaseConfig{Driver: "postgres"}); err == nil {
+		t.Error("invalid config accepted")
+	}
+	if err := p.RegisterDatabase("mem", DatabaseConfig{Driver: "sqlite3", Path: ":memory:"}); err != nil {
+		t.Fatal(err)
+	}
+	resource, err := p.GetResource(context.Background(), "mem")
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	var one int
+	if err := resource.(*DatabaseResource).DB().QueryRow("SELECT 1").Scan(&one); err != nil || one != 1 {
+		t.Errorf("query: %d, %v", one, err)
+	}
+
+	if _, err := p.GetResource(context.Background(), "missing"); err == nil {
+		t.Error("unknown resource accepted")
+	}
+	var authErr *AuthError
+	if _, err := p.GetResource(context.Background(), "bad"); !errors.As(err, &authErr) {
+		t.Errorf("error %T is not an AuthError", err)
+	}
+}
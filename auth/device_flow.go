@@ -0,0 +1,97 @@
+// auth/device_flow.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrDeviceFlowUnsupported indicates the provider has no device
+// authorization endpoint
+var ErrDeviceFlowUnsupported = errors.New("device authorization not supported: no device_auth_url")
+
+// DeviceAuthorization is a pending device authorization (RFC 8628)
+// Show the user VerificationURI and UserCode (or VerificationURIComplete,
+// which embeds the code), then wait for them with PollDeviceToken.
+type DeviceAuthorization struct {
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	ExpiresAt               time.Time
+
+	// Interval is how often the token endpoint is polled
+	Interval time.Duration
+
+	response *oauth2.DeviceAuthResponse
+}
+
+// StartDeviceAuth starts a device authorization grant
+// Headless servers and CLIs use it instead of AuthCodeURL: the user
+// approves the request on another device, so no redirect URL is needed,
+// and public clients need no client secret.
+func (p *OAuth2Provider) StartDeviceAuth(ctx context.Context) (*DeviceAuthorization, error) {
+	if p.config.Endpoint.DeviceAuthURL == "" {
+		return nil, NewAuthError(p.Name(), "", "device_auth", ErrDeviceFlowUnsupported)
+	}
+
+	resp, err := p.config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), "", "device_auth", err)
+	}
+
+	interval := time.Duration(resp.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second // RFC 8628 section 3.2
+	}
+	return &DeviceAuthorization{
+		UserCode:                resp.UserCode,
+		VerificationURI:         resp.VerificationURI,
+		VerificationURIComplete: resp.VerificationURIComplete,
+		ExpiresAt:               resp.Expiry,
+		Interval:                interval,
+		response:                resp,
+	}, nil
+}
+
+// PollDeviceToken waits until the user approves the device authorization,
+// then stores the token
+// It polls at the server's interval, slowing down when asked to, and
+// fails when the user denies the request, the code expires or ctx ends.
+func (p *OAuth2Provider) PollDeviceToken(ctx context.Context, da *DeviceAuthorization) error {
+	if da == nil || da.response == nil {
+		return NewAuthError(p.Name(), "", "device_token", fmt.Errorf("no device authorization started"))
+	}
+
+	token, err := p.config.DeviceAccessToken(ctx, da.response)
+	if err != nil {
+		return NewAuthError(p.Name(), "", "device_token", err)
+	}
+
+	return p.SetToken(ctx, &OAuth2Token{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenType:    token.TokenType,
+		ExpiresAt:    token.Expiry,
+	})
+}
+
+// DeviceLogin runs a device authorization grant: it starts it, hands the
+// user code to prompt and waits for the user to approve it
+//
+// Example:
+//
+//	err := provider.DeviceLogin(ctx, func(da auth.DeviceAuthorization) {
+//	    fmt.Fprintf(os.Stderr, "Visit %s and enter %s\n", da.VerificationURI, da.UserCode)
+//	})
+func (p *OAuth2Provider) DeviceLogin(ctx context.Context, prompt func(DeviceAuthorization)) error {
+	da, err := p.StartDeviceAuth(ctx)
+	if err != nil {
+		return err
+	}
+	prompt(*da)
+	return p.PollDeviceToken(ctx, da)
+}
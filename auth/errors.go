@@ -22,8 +22,23 @@ var (
 	// ErrRefreshFailed indicates credential refresh failed
 	ErrRefreshFailed = errors.New("credential refresh failed")
 
+	// ErrTokenRevoked indicates the authorization server reports the token as inactive
+	ErrTokenRevoked = errors.New("token revoked or inactive")
+
+	// ErrRevocationUnsupported indicates a provider has no revocation endpoint
+	ErrRevocationUnsupported = errors.New("token revocation not supported")
+
 	// ErrValidationFailed indicates credential validation failed
 	ErrValidationFailed = errors.New("validation failed")
+
+	// ErrNoCredentials indicates an inbound request carried no credentials
+	ErrNoCredentials = errors.New("no credentials provided")
+
+	// ErrUnauthenticated indicates an inbound request could not be authenticated
+	ErrUnauthenticated = errors.New("authentication required")
+
+	// ErrForbidden indicates an authenticated principal is not allowed to perform an action
+	ErrForbidden = errors.New("access denied")
 )
 
 // AuthError wraps errors with additional context
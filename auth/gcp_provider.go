@@ -0,0 +1,262 @@
+// auth/gcp_provider.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// DefaultGCPScope is the scope GCP providers request by default
+const DefaultGCPScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// gcpCredentialTypes are the credentials files a GCPProvider accepts
+var gcpCredentialTypes = []google.CredentialsType{
+	google.ServiceAccount,
+	google.AuthorizedUser,
+	google.ImpersonatedServiceAccount,
+	google.ExternalAccount,
+}
+
+// GCPConfig holds Google Cloud provider configuration
+type GCPConfig struct {
+	// CredentialsFile is a service account key or other credentials file;
+	// empty uses Application Default Credentials
+	CredentialsFile string `yaml:"credentials_file,omitempty" json:"credentials_file,omitempty"`
+
+	// Scopes requested (default: cloud-platform)
+	Scopes []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+
+	// ProjectID overrides the project of the credentials
+	ProjectID string `yaml:"project_id,omitempty" json:"project_id,omitempty"`
+
+	// CredentialsJSON sets the credentials file contents, bypassing the lookup
+	CredentialsJSON []byte `yaml:"-" json:"-"`
+
+	// TokenSource replaces the credentials lookup
+	TokenSource oauth2.TokenSource `yaml:"-" json:"-"`
+}
+
+// GCPProvider authenticates to Google Cloud APIs with OAuth2 access tokens
+//
+// Credentials come from Application Default Credentials: the file named by
+// $GOOGLE_APPLICATION_CREDENTIALS, gcloud's application default file, then
+// the metadata server on GCE, GKE (workload identity) and Cloud Run. Tokens
+// are cached and renewed before they expire.
+//
+// Resource config keys:
+//
+//	base_url   API root (default: https://SERVICE.googleapis.com)
+//	service    API name, e.g. "storage" or "bigquery"
+type GCPProvider struct {
+	*BaseProvider
+	config GCPConfig
+
+	mu          sync.Mutex
+	credentials *google.Credentials
+}
+
+// NewGCPProvider creates a new Google Cloud provider
+// Credentials are looked up on first use.
+func NewGCPProvider(name string, config GCPConfig) *GCPProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{DefaultGCPScope}
+	}
+	return &GCPProvider{
+		BaseProvider: NewBaseProvider(name),
+		config:       config,
+	}
+}
+
+// findCredentials returns the provider's credentials, looking them up once
+func (p *GCPProvider) findCredentials(ctx context.Context) (*google.Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.credentials != nil {
+		return p.credentials, nil
+	}
+
+	// The token source outlives the call that created it
+	ctx = context.WithoutCancel(ctx)
+
+	var creds *google.Credentials
+	var err error
+	switch {
+	case p.config.TokenSource != nil:
+		creds = &google.Credentials{TokenSource: oauth2.ReuseTokenSource(nil, p.config.TokenSource)}
+	case p.config.CredentialsJSON != nil:
+		creds, err = gcpCredentialsFromJSON(ctx, p.config.CredentialsJSON, p.config.Scopes)
+	case p.config.CredentialsFile != "":
+		var data []byte
+		if data, err = os.ReadFile(p.config.CredentialsFile); err == nil {
+			creds, err = gcpCredentialsFromJSON(ctx, data, p.config.Scopes)
+		}
+	default:
+		creds, err = google.FindDefaultCredentials(ctx, p.config.Scopes...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if p.config.ProjectID != "" {
+		creds.ProjectID = p.config.ProjectID
+	}
+	p.credentials = creds
+	return creds, nil
+}
+
+// gcpCredentialsFromJSON parses a credentials file of an accepted type
+func gcpCredentialsFromJSON(ctx context.Context, data []byte, scopes []string) (*google.Credentials, error) {
+	var f struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse credentials file: %w", err)
+	}
+	for _, t := range gcpCredentialTypes {
+		if google.CredentialsType(f.Type) == t {
+			return google.CredentialsFromJSONWithType(ctx, data, t, scopes...)
+		}
+	}
+	return nil, fmt.Errorf("unsupported credentials type %q", f.Type)
+}
+
+// token returns a current access token
+func (p *GCPProvider) token(ctx context.Context) (*oauth2.Token, error) {
+	creds, err := p.findCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return creds.TokenSource.Token()
+}
+
+// GetResource returns an HTTP client that authenticates every request
+func (p *GCPProvider) GetResource(ctx context.Context, resourceID string) (Resource, error) {
+	config, err := p.GetResourceConfig(resourceID)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+	}
+
+	baseURL, _ := config.Config["base_url"].(string)
+	if baseURL == "" {
+		service, _ := config.Config["service"].(string)
+		if service == "" {
+			return nil, NewAuthError(p.Name(), resourceID, "get_resource",
+				fmt.Errorf("base_url or service is required"))
+		}
+		baseURL = "https://" + service + ".googleapis.com"
+	}
+
+	creds, err := p.findCredentials(ctx)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+	}
+
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &oauth2.Transport{Source: creds.TokenSource, Base: http.DefaultTransport},
+	}
+
+	return &GCPResource{
+		client:     client,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		resourceID: resourceID,
+		projectID:  creds.ProjectID,
+	}, nil
+}
+
+// GetSecret implements CredentialSource with the current access token
+func (p *GCPProvider) GetSecret(ctx context.Context, resourceID string) (Secret, error) {
+	token, err := p.token(ctx)
+	if err != nil {
+		return Secret{}, NewAuthError(p.Name(), resourceID, "get_secret", err)
+	}
+	secret := NewSecret(token.AccessToken)
+	secret.Header = "Authorization"
+	secret.Scheme = "Bearer"
+	secret.ExpiresAt = token.Expiry
+	return secret, nil
+}
+
+// RoundTripper implements TransportSource
+func (p *GCPProvider) RoundTripper(ctx context.Context, resourceID string, base http.RoundTripper) (http.RoundTripper, error) {
+	creds, err := p.findCredentials(ctx)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "round_tripper", err)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &oauth2.Transport{Source: creds.TokenSource, Base: base}, nil
+}
+
+// ProjectID returns the project of the credentials, "" if they name none
+func (p *GCPProvider) ProjectID(ctx context.Context) (string, error) {
+	creds, err := p.findCredentials(ctx)
+	if err != nil {
+		return "", NewAuthError(p.Name(), "", "project_id", err)
+	}
+	return creds.ProjectID, nil
+}
+
+// Validate checks that an access token can be obtained
+func (p *GCPProvider) Validate(ctx context.Context) error {
+	if _, err := p.token(ctx); err != nil {
+		return NewAuthError(p.Name(), "", "validate", err)
+	}
+	return nil
+}
+
+// Refresh discards the credentials so the next request looks them up again
+func (p *GCPProvider) Refresh(ctx context.Context) error {
+	p.mu.Lock()
+	p.credentials = nil
+	p.mu.Unlock()
+	return p.Validate(ctx)
+}
+
+// Close releases provider resources
+func (p *GCPProvider) Close() error {
+	return nil
+}
+
+// GCPResource wraps an HTTP client authenticated to Google Cloud
+type GCPResource struct {
+	client     *http.Client
+	baseURL    string
+	resourceID string
+	projectID  string
+}
+
+func (r *GCPResource) Close() error {
+	r.client.CloseIdleConnections()
+	return nil
+}
+
+func (r *GCPResource) Type() string {
+	return "gcp"
+}
+
+// Client returns the authenticated HTTP client
+func (r *GCPResource) Client() *http.Client {
+	return r.client
+}
+
+// BaseURL returns the API root
+func (r *GCPResource) BaseURL() string {
+	return r.baseURL
+}
+
+// ProjectID returns the project of the credentials
+func (r *GCPResource) ProjectID() string {
+	return r.projectID
+}
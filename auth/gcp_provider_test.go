@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// serviceAccountKey writes a service account key file whose token_uri
+// points at a test token endpoint
+func serviceAccountKey(t *testing.T, tokenURL string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	data, _ := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "demo-project",
+		"private_key_id": "k1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "mcp@demo-project.iam.gserviceaccount.com",
+		"token_uri":      tokenURL,
+	})
+	path := filepath.Join(t.TempDir(), "key.json")
+	os.WriteFile(path, data, 0600)
+	return path
+}
+
+func TestGCPProvider_ApplicationDefaultCredentials(t *testing.T) {
+	var tokenRequests atomic.Int32
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.Form.Get("assertion") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		tokenRequests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"ya29.token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokens.Close()
+
+	var gotAuth string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer api.Close()
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", serviceAccountKey(t, tokens.URL))
+
+	provider := NewGCPProvider("gcp", GCPConfig{})
+	provider.RegisterResource(ResourceConfig{ID: "storage", Config: map[string]interface{}{"base_url": api.URL}})
+	ctx := context.Background()
+
+	res, err := provider.GetResource(ctx, "storage")
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	gcp := res.(*GCPResource)
+	if gcp.ProjectID() != "demo-project" {
+		t.Errorf("ProjectID = %q", gcp.ProjectID())
+	}
+	for i := 0; i < 2; i++ {
+		resp, err := gcp.Client().Get(gcp.BaseURL() + "/b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if gotAuth != "Bearer ya29.token" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if n := tokenRequests.Load(); n != 1 {
+		t.Errorf("%d token requests, want 1 (cached)", n)
+	}
+
+	secret, err := provider.GetSecret(ctx, "storage")
+	if err != nil || secret.HeaderValue() != "Bearer ya29.token" || secret.ExpiresAt.IsZero() {
+		t.Errorf("GetSecret = %+v, %v", secret, err)
+	}
+}
+
+func TestGCPProvider_Config(t *testing.T) {
+	provider := NewGCPProvider("gcp", GCPConfig{
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "static"}),
+		ProjectID:   "override",
+	})
+	provider.RegisterResource(ResourceConfig{ID: "bq", Config: map[string]interface{}{"service": "bigquery"}})
+	provider.RegisterResource(ResourceConfig{ID: "bare"})
+	ctx := context.Background()
+
+	res, err := provider.GetResource(ctx, "bq")
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	if gcp := res.(*GCPResource); gcp.BaseURL() != "https://bigquery.googleapis.com" || gcp.ProjectID() != "override" {
+		t.Errorf("resource %q in %q", gcp.BaseURL(), gcp.ProjectID())
+	}
+	if _, err := provider.GetResource(ctx, "bare"); err == nil {
+		t.Error("resource without base_url or service accepted")
+	}
+	if err := provider.Validate(ctx); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+
+	bad := NewGCPProvider("gcp", GCPConfig{CredentialsJSON: []byte(`{"type":"gdch_service_account"}`)})
+	if err := bad.Validate(ctx); err == nil {
+		t.Error("unsupported credentials type accepted")
+	}
+}
@@ -0,0 +1,312 @@
+// auth/github_app.go
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// GitHubAppConfig holds GitHub App provider configuration
+type GitHubAppConfig struct {
+	// AppID is the numeric GitHub App ID
+	AppID int64 `yaml:"app_id" json:"app_id"`
+
+	// InstallationID is the installation whose tokens are minted
+	InstallationID int64 `yaml:"installation_id" json:"installation_id"`
+
+	// PrivateKeyPath is the app's PEM private key file
+	PrivateKeyPath string `yaml:"private_key_path" json:"private_key_path"`
+
+	// PrivateKey is the PEM key itself; takes precedence over PrivateKeyPath
+	PrivateKey string `yaml:"-" json:"-"`
+
+	// BaseURL is the API root (default: https://api.github.com)
+	// Set it to https://HOST/api/v3 for GitHub Enterprise Server.
+	BaseURL string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
+}
+
+// githubAppRenewBefore renews installation tokens this long before they expire
+const githubAppRenewBefore = 5 * time.Minute
+
+// GitHubAppProvider authenticates as a GitHub App installation
+//
+// It signs short-lived app JWTs with the app's private key and exchanges
+// them for installation tokens, which are cached and renewed automatically.
+// Installation tokens have per-installation rate limits and survive the
+// departure of the user who set the integration up.
+type GitHubAppProvider struct {
+	*BaseProvider
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	baseURL        string
+	client         *http.Client
+	now            func() time.Time
+
+	// Installation token source with the app's full permissions
+	sourceMu sync.Mutex
+	source   oauth2.TokenSource
+}
+
+// NewGitHubAppProvider creates a new GitHub App provider
+func NewGitHubAppProvider(name string, config GitHubAppConfig) (*GitHubAppProvider, error) {
+	if config.AppID == 0 || config.InstallationID == 0 {
+		return nil, fmt.Errorf("github app: app_id and installation_id are required")
+	}
+
+	pemData := []byte(config.PrivateKey)
+	if len(pemData) == 0 {
+		if config.PrivateKeyPath == "" {
+			return nil, fmt.Errorf("github app: private key is required")
+		}
+		data, err := os.ReadFile(config.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("github app: read private key: %w", err)
+		}
+		pemData = data
+	}
+
+	key, err := parseRSAPrivateKey(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("github app: %w", err)
+	}
+
+	baseURL := strings.TrimSuffix(config.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+
+	return &GitHubAppProvider{
+		BaseProvider:   NewBaseProvider(name),
+		appID:          config.AppID,
+		installationID: config.InstallationID,
+		key:            key,
+		baseURL:        baseURL,
+		client:         &http.Client{Timeout: 30 * time.Second},
+		now:            time.Now,
+	}, nil
+}
+
+// parseRSAPrivateKey decodes a PKCS#1 or PKCS#8 PEM RSA key
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// GetResource returns an HTTP client authenticated as the installation
+// Resources without a base_url use the GitHub API root.
+func (p *GitHubAppProvider) GetResource(ctx context.Context, resourceID string) (Resource, error) {
+	config, err := p.GetResourceConfig(resourceID)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+	}
+
+	return p.newResource(resourceID, config, p.tokenSource()), nil
+}
+
+// GetScopedResource returns a client whose installation token carries only scopes
+// Scopes are GitHub App permissions written as "name:level", e.g.
+// "contents:read" or "issues:write".
+func (p *GitHubAppProvider) GetScopedResource(ctx context.Context, resourceID string, scopes []string) (Resource, error) {
+	config, err := p.GetResourceConfig(resourceID)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_scoped_resource", err)
+	}
+
+	permissions := make(map[string]string, len(scopes))
+	for _, scope := range scopes {
+		name, level, ok := strings.Cut(scope, ":")
+		if !ok || name == "" || level == "" {
+			return nil, NewAuthError(p.Name(), resourceID, "get_scoped_resource",
+				fmt.Errorf("invalid GitHub permission %q (want name:level)", scope))
+		}
+		permissions[name] = level
+	}
+
+	source := oauth2.ReuseTokenSourceWithExpiry(nil, &githubInstallationSource{
+		provider:    p,
+		permissions: permissions,
+	}, githubAppRenewBefore)
+
+	return p.newResource(resourceID, config, source), nil
+}
+
+// newResource wraps a token source in an OAuth2Resource
+func (p *GitHubAppProvider) newResource(resourceID string, config ResourceConfig, source oauth2.TokenSource) *OAuth2Resource {
+	baseURL, _ := config.Config["base_url"].(string)
+	if baseURL == "" {
+		baseURL = p.baseURL
+	}
+
+	client := oauth2.NewClient(context.Background(), source)
+	client.Timeout = 30 * time.Second
+
+	return &OAuth2Resource{
+		client:     client,
+		baseURL:    baseURL,
+		resourceID: resourceID,
+	}
+}
+
+// tokenSource returns the shared, auto-renewing installation token source
+func (p *GitHubAppProvider) tokenSource() oauth2.TokenSource {
+	p.sourceMu.Lock()
+	defer p.sourceMu.Unlock()
+
+	if p.source == nil {
+		p.source = oauth2.ReuseTokenSourceWithExpiry(nil, &githubInstallationSource{provider: p}, githubAppRenewBefore)
+	}
+	return p.source
+}
+
+// Validate mints (or reuses) an installation token
+func (p *GitHubAppProvider) Validate(ctx context.Context) error {
+	if _, err := p.tokenSource().Token(); err != nil {
+		return NewAuthError(p.Name(), "", "validate", err)
+	}
+	return nil
+}
+
+// Refresh discards the cached installation token
+func (p *GitHubAppProvider) Refresh(ctx context.Context) error {
+	p.sourceMu.Lock()
+	p.source = nil
+	p.sourceMu.Unlock()
+	return nil
+}
+
+// Close releases provider resources
+func (p *GitHubAppProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// appJWT returns a JWT authenticating as the app itself (valid ~10 minutes)
+func (p *GitHubAppProvider) appJWT() (string, error) {
+	now := p.now()
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		// Backdated to tolerate clock drift, as GitHub recommends
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(p.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign app JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// mintInstallationToken requests a new installation access token
+func (p *GitHubAppProvider) mintInstallationToken(ctx context.Context, permissions map[string]string) (*oauth2.Token, error) {
+	jwt, err := p.appJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if len(permissions) > 0 {
+		data, err := json.Marshal(map[string]interface{}{"permissions": permissions})
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", p.baseURL, p.installationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("create installation token request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("installation token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("installation token request returned status %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode installation token: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: result.Token,
+		TokenType:   "Bearer",
+		Expiry:      result.ExpiresAt,
+	}, nil
+}
+
+// githubInstallationSource mints installation tokens on demand
+type githubInstallationSource struct {
+	provider    *GitHubAppProvider
+	permissions map[string]string
+}
+
+// Token implements oauth2.TokenSource
+func (s *githubInstallationSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	token, err := s.provider.mintInstallationToken(ctx, s.permissions)
+	if err != nil {
+		return nil, NewAuthError(s.provider.Name(), "", "mint_installation_token", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubAppProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var mints int
+	var gotPermissions map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app/installations/42/access_tokens":
+			jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if err := verifyRS256(jwt, &key.PublicKey); err != nil {
+				t.Errorf("invalid app JWT: %v", err)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			mints++
+			var body struct {
+				Permissions map[string]string `json:"permissions"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			gotPermissions = body.Permissions
+
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token":"ghs_%d","expires_at":%q}`, mints, time.Now().Add(time.Hour).Format(time.RFC3339))
+		case "/repos/acme/app":
+			if r.Header.Get("Authorization") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewGitHubAppProvider("gh", GitHubAppConfig{
+		AppID:          1,
+		InstallationID: 42,
+		PrivateKey:     string(keyPEM),
+		BaseURL:        server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewGitHubAppProvider failed: %v", err)
+	}
+	provider.RegisterResource(ResourceConfig{ID: "github", Type: "api"})
+
+	ctx := context.Background()
+	res, err := provider.GetResource(ctx, "github")
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	oauthRes := res.(*OAuth2Resource)
+	if oauthRes.BaseURL() != server.URL {
+		t.Errorf("BaseURL = %q, want API root", oauthRes.BaseURL())
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := oauthRes.Client().Get(server.URL + "/repos/acme/app")
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("request failed: %v %v", resp, err)
+		}
+		resp.Body.Close()
+	}
+	if mints != 1 {
+		t.Errorf("installation tokens minted = %d, want 1 (cached)", mints)
+	}
+
+	scoped, err := provider.GetScopedResource(ctx, "github", []string{"contents:read"})
+	if err != nil {
+		t.Fatalf("GetScopedResource failed: %v", err)
+	}
+	resp, err := scoped.(*OAuth2Resource).Client().Get(server.URL + "/repos/acme/app")
+	if err != nil {
+		t.Fatalf("scoped request failed: %v", err)
+	}
+	resp.Body.Close()
+	if gotPermissions["contents"] != "read" {
+		t.Errorf("permissions = %v, want contents:read", gotPermissions)
+	}
+}
+
+// verifyRS256 checks a compact JWS signature
+func verifyRS256(jwt string, pub *rsa.PublicKey) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWT")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig)
+}
@@ -0,0 +1,368 @@
+// auth/inbound.go
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ============================================================
+// Principal
+// ============================================================
+
+// Principal identifies an authenticated inbound caller
+type Principal struct {
+	// ID is the stable identifier of the caller (key ID, token subject, ...)
+	ID string `json:"id"`
+
+	// Method is the authentication method used ("api-key", "bearer", "introspection", "jwt")
+	Method string `json:"method"`
+
+	// Scopes granted to the caller
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Claims holds additional identity attributes (e.g. from token introspection)
+	Claims map[string]interface{} `json:"claims,omitempty"`
+}
+
+// HasScope reports whether the principal was granted scope
+func (p *Principal) HasScope(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the authenticated principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated principal, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// ============================================================
+// Authenticators
+// ============================================================
+
+// Authenticator authenticates inbound HTTP requests
+// Implementations return ErrNoCredentials when the request carries none of
+// the credentials they understand, so authenticators can be chained.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// InboundConfig configures authentication of inbound requests
+type InboundConfig struct {
+	// Enabled requires every /rpc and /stream request to authenticate
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// APIKeys accepts static API keys from a header or query parameter
+	APIKeys InboundAPIKeyConfig `yaml:"api_keys" json:"api_keys"`
+
+	// Bearer accepts static bearer tokens from the Authorization header
+	Bearer InboundBearerConfig `yaml:"bearer" json:"bearer"`
+
+	// Introspection validates bearer tokens against an RFC 7662 endpoint
+	Introspection IntrospectionConfig `yaml:"introspection" json:"introspection"`
+
+	// JWT validates bearer JWTs against the JWKS of trusted issuers
+	JWT JWTConfig `yaml:"jwt" json:"jwt"`
+
+	// Tools restricts individual tools to specific principals
+	Tools map[string]ToolAccessPolicy `yaml:"tools" json:"tools"`
+}
+
+// InboundCredential is a static credential mapped to a principal
+type InboundCredential struct {
+	ID     string   `yaml:"id" json:"id"`
+	Secret string   `yaml:"secret" json:"secret"`
+	Scopes []string `yaml:"scopes" json:"scopes"`
+}
+
+// InboundAPIKeyConfig configures API key authentication
+type InboundAPIKeyConfig struct {
+	// Header carrying the key (default: "X-API-Key")
+	Header string `yaml:"header" json:"header"`
+
+	// QueryParam optionally accepts the key as a query parameter (e.g. "api_key")
+	// Query parameters end up in access logs; prefer the header
+	QueryParam string `yaml:"query_param" json:"query_param"`
+
+	Keys []InboundCredential `yaml:"keys" json:"keys"`
+}
+
+// InboundBearerConfig configures static bearer token authentication
+type InboundBearerConfig struct {
+	Tokens []InboundCredential `yaml:"tokens" json:"tokens"`
+}
+
+// Validate validates the inbound configuration
+func (c *InboundConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if len(c.APIKeys.Keys) == 0 && len(c.Bearer.Tokens) == 0 && c.Introspection.Endpoint == "" && len(c.JWT.Issuers) == 0 {
+		return fmt.Errorf("inbound auth enabled but no api keys, bearer tokens, introspection endpoint or jwt issuers configured")
+	}
+
+	check := func(kind string, creds []InboundCredential) error {
+		seen := make(map[string]bool)
+		for i, cred := range creds {
+			if cred.ID == "" {
+				return fmt.Errorf("%s #%d: id is required", kind, i)
+			}
+			if cred.Secret == "" {
+				return fmt.Errorf("%s %q: secret is required", kind, cred.ID)
+			}
+			if seen[cred.ID] {
+				return fmt.Errorf("%s %q: duplicate id", kind, cred.ID)
+			}
+			seen[cred.ID] = true
+		}
+		return nil
+	}
+
+	if err := check("api key", c.APIKeys.Keys); err != nil {
+		return err
+	}
+	if err := check("bearer token", c.Bearer.Tokens); err != nil {
+		return err
+	}
+	if len(c.JWT.Issuers) > 0 {
+		if err := c.JWT.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewInboundAuthenticator builds an authenticator chain from configuration
+func NewInboundAuthenticator(config InboundConfig) (Authenticator, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var chain ChainAuthenticator
+
+	if len(config.APIKeys.Keys) > 0 {
+		chain = append(chain, NewAPIKeyAuthenticator(config.APIKeys))
+	}
+	if len(config.Bearer.Tokens) > 0 {
+		chain = append(chain, NewBearerAuthenticator(config.Bearer))
+	}
+	// JWTs before introspection: they validate locally
+	if len(config.JWT.Issuers) > 0 {
+		jwt, err := NewJWTProvider("inbound-jwt", config.JWT)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, jwt)
+	}
+	if config.Introspection.Endpoint != "" {
+		chain = append(chain, NewIntrospectionAuthenticator(NewIntrospector(config.Introspection)))
+	}
+
+	return chain, nil
+}
+
+// ChainAuthenticator tries each authenticator in order
+// The first success wins. If none succeed, the first error other than
+// ErrNoCredentials is returned (or ErrNoCredentials if nothing applied).
+type ChainAuthenticator []Authenticator
+
+// Authenticate implements Authenticator
+func (c ChainAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	var firstErr error
+	for _, a := range c {
+		p, err := a.Authenticate(r)
+		if err == nil {
+			return p, nil
+		}
+		if firstErr == nil && !errors.Is(err, ErrNoCredentials) {
+			firstErr = err
+		}
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, ErrNoCredentials
+}
+
+// APIKeyAuthenticator authenticates static API keys
+type APIKeyAuthenticator struct {
+	header     string
+	queryParam string
+	keys       []InboundCredential
+}
+
+// NewAPIKeyAuthenticator creates an API key authenticator
+func NewAPIKeyAuthenticator(config InboundAPIKeyConfig) *APIKeyAuthenticator {
+	header := config.Header
+	if header == "" {
+		header = "X-API-Key"
+	}
+	return &APIKeyAuthenticator{
+		header:     header,
+		queryParam: config.QueryParam,
+		keys:       config.Keys,
+	}
+}
+
+// Authenticate implements Authenticator
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(a.header)
+	if key == "" && a.queryParam != "" {
+		key = r.URL.Query().Get(a.queryParam)
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	if cred, ok := matchCredential(a.keys, key); ok {
+		return &Principal{ID: cred.ID, Method: "api-key", Scopes: cred.Scopes}, nil
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// BearerAuthenticator authenticates static bearer tokens
+type BearerAuthenticator struct {
+	tokens []InboundCredential
+}
+
+// NewBearerAuthenticator creates a static bearer token authenticator
+func NewBearerAuthenticator(config InboundBearerConfig) *BearerAuthenticator {
+	return &BearerAuthenticator{tokens: config.Tokens}
+}
+
+// Authenticate implements Authenticator
+func (a *BearerAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := BearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	if cred, ok := matchCredential(a.tokens, token); ok {
+		return &Principal{ID: cred.ID, Method: "bearer", Scopes: cred.Scopes}, nil
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// IntrospectionAuthenticator validates bearer tokens with an introspection endpoint
+type IntrospectionAuthenticator struct {
+	introspector *Introspector
+}
+
+// NewIntrospectionAuthenticator creates an introspection-backed authenticator
+func NewIntrospectionAuthenticator(introspector *Introspector) *IntrospectionAuthenticator {
+	return &IntrospectionAuthenticator{introspector: introspector}
+}
+
+// Authenticate implements Authenticator
+func (a *IntrospectionAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := BearerToken(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	result, err := a.introspector.Introspect(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Active {
+		return nil, ErrInvalidCredentials
+	}
+
+	return result.Principal(), nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer" header
+func BearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	const prefix = "bearer "
+	if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(h[len(prefix):])
+	return token, token != ""
+}
+
+// matchCredential finds the credential whose secret equals presented
+// Every credential is compared in constant time
+func matchCredential(creds []InboundCredential, presented string) (InboundCredential, bool) {
+	var match InboundCredential
+	found := false
+	for _, cred := range creds {
+		if subtle.ConstantTimeCompare([]byte(cred.Secret), []byte(presented)) == 1 {
+			match = cred
+			found = true
+		}
+	}
+	return match, found
+}
+
+// ============================================================
+// Tool access policies
+// ============================================================
+
+// ToolAccessPolicy restricts who may call a tool
+type ToolAccessPolicy struct {
+	// AllowedPrincipals lists principal IDs allowed to call the tool
+	// An empty list allows every authenticated principal
+	AllowedPrincipals []string `yaml:"allowed_principals" json:"allowed_principals"`
+}
+
+// AccessPolicy evaluates per-tool access policies
+type AccessPolicy struct {
+	tools map[string]ToolAccessPolicy
+}
+
+// NewAccessPolicy creates an access policy from per-tool rules
+func NewAccessPolicy(tools map[string]ToolAccessPolicy) *AccessPolicy {
+	if tools == nil {
+		tools = make(map[string]ToolAccessPolicy)
+	}
+	return &AccessPolicy{tools: tools}
+}
+
+// Authorize checks whether principal may call tool
+// Tools without a policy are open to any caller
+func (p *AccessPolicy) Authorize(tool string, principal *Principal) error {
+	if p == nil {
+		return nil
+	}
+
+	policy, ok := p.tools[tool]
+	if !ok || len(policy.AllowedPrincipals) == 0 {
+		return nil
+	}
+
+	if principal == nil {
+		return fmt.Errorf("%w: tool %q requires an authenticated principal", ErrUnauthenticated, tool)
+	}
+
+	for _, id := range policy.AllowedPrincipals {
+		if id == principal.ID {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: principal %q may not call tool %q", ErrForbidden, principal.ID, tool)
+}
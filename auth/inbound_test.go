@@ -0,0 +1,202 @@
+// framework/auth/inbound_test.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestInboundAuthenticator_APIKey(t *testing.T) {
+	authn, err := NewInboundAuthenticator(InboundConfig{
+		Enabled: true,
+		APIKeys: InboundAPIKeyConfig{
+			QueryParam: "api_key",
+			Keys:       []InboundCredential{{ID: "ci", Secret: "secret-1", Scopes: []string{"read"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewInboundAuthenticator() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(r *http.Request)
+		wantID  string
+		wantErr error
+	}{
+		{"header", func(r *http.Request) { r.Header.Set("X-API-Key", "secret-1") }, "ci", nil},
+		{"query", func(r *http.Request) { r.URL.RawQuery = "api_key=secret-1" }, "ci", nil},
+		{"wrong key", func(r *http.Request) { r.Header.Set("X-API-Key", "nope") }, "", ErrInvalidCredentials},
+		{"missing", func(r *http.Request) {}, "", ErrNoCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+			tt.setup(r)
+
+			p, err := authn.Authenticate(r)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if p.ID != tt.wantID || !p.HasScope("read") {
+				t.Errorf("principal = %+v, want id %q with scope read", p, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestInboundAuthenticator_ChainPrefersRealError(t *testing.T) {
+	authn, err := NewInboundAuthenticator(InboundConfig{
+		Enabled: true,
+		APIKeys: InboundAPIKeyConfig{Keys: []InboundCredential{{ID: "k", Secret: "key"}}},
+		Bearer:  InboundBearerConfig{Tokens: []InboundCredential{{ID: "t", Secret: "token"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewInboundAuthenticator() error = %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	if p, err := authn.Authenticate(r); err != nil || p.ID != "t" {
+		t.Fatalf("bearer Authenticate() = %+v, %v", p, err)
+	}
+
+	r.Header.Set("Authorization", "Bearer wrong")
+	if _, err := authn.Authenticate(r); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() error = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestIntrospector(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if user, pass, ok := r.BasicAuth(); !ok || user != "rs" || pass != "rs-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("token") == "good" {
+			w.Write([]byte(`{"active":true,"sub":"alice","scope":"tools:read tools:write"}`))
+			return
+		}
+		w.Write([]byte(`{"active":false}`))
+	}))
+	defer srv.Close()
+
+	authn := NewIntrospectionAuthenticator(NewIntrospector(IntrospectionConfig{
+		Endpoint:     srv.URL,
+		ClientID:     "rs",
+		ClientSecret: "rs-secret",
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+	r.Header.Set("Authorization", "Bearer good")
+	for i := 0; i < 2; i++ {
+		p, err := authn.Authenticate(r)
+		if err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		if p.ID != "alice" || !p.HasScope("tools:write") {
+			t.Errorf("principal = %+v", p)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("introspection calls = %d, want 1 (cached)", n)
+	}
+
+	r.Header.Set("Authorization", "Bearer bad")
+	if _, err := authn.Authenticate(r); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("inactive token error = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestAccessPolicy(t *testing.T) {
+	policy := NewAccessPolicy(map[string]ToolAccessPolicy{
+		"delete_file": {AllowedPrincipals: []string{"admin"}},
+	})
+
+	if err := policy.Authorize("read_file", nil); err != nil {
+		t.Errorf("unrestricted tool: %v", err)
+	}
+	if err := policy.Authorize("delete_file", &Principal{ID: "admin"}); err != nil {
+		t.Errorf("allowed principal: %v", err)
+	}
+	if err := policy.Authorize("delete_file", &Principal{ID: "guest"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("other principal error = %v, want ErrForbidden", err)
+	}
+	if err := policy.Authorize("delete_file", nil); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("anonymous error = %v, want ErrUnauthenticated", err)
+	}
+}
+
+func TestPrincipalContext(t *testing.T) {
+	if _, ok := PrincipalFromContext(context.Background()); ok {
+		t.Error("empty context should carry no principal")
+	}
+
+	ctx := WithPrincipal(context.Background(), &Principal{ID: "alice"})
+	if p, ok := PrincipalFromContext(ctx); !ok || p.ID != "alice" {
+		t.Errorf("PrincipalFromContext() = %+v, %v", p, ok)
+	}
+}
+
+func TestInboundConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  InboundConfig
+		wantErr bool
+	}{
+		{"disabled", InboundConfig{}, false},
+		{"enabled without credentials", InboundConfig{Enabled: true}, true},
+		{"missing secret", InboundConfig{Enabled: true, APIKeys: InboundAPIKeyConfig{Keys: []InboundCredential{{ID: "a"}}}}, true},
+		{"duplicate id", InboundConfig{Enabled: true, Bearer: InboundBearerConfig{Tokens: []InboundCredential{{ID: "a", Secret: "1"}, {ID: "a", Secret: "2"}}}}, true},
+		{"introspection only", InboundConfig{Enabled: true, Introspection: IntrospectionConfig{Endpoint: "https://idp/introspect"}}, false},
+		{"jwt only", InboundConfig{Enabled: true, JWT: JWTConfig{Issuers: []JWTIssuerConfig{{Issuer: "https://idp", JWKSURL: "https://idp/jwks", Audiences: []string{"mcp"}}}}}, false},
+		{"jwt without audience", InboundConfig{Enabled: true, JWT: JWTConfig{Issuers: []JWTIssuerConfig{{Issuer: "https://idp", JWKSURL: "https://idp/jwks"}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequireScopes(t *testing.T) {
+	p := &Principal{ID: "alice", Scopes: []string{"fs:read"}}
+
+	if err := RequireScopes(p); err != nil {
+		t.Errorf("no scopes required: %v", err)
+	}
+	if err := RequireScopes(p, "fs:read"); err != nil {
+		t.Errorf("granted scope: %v", err)
+	}
+
+	err := RequireScopes(p, "fs:read", "fs:write")
+	var scopeErr *ScopeError
+	if !errors.As(err, &scopeErr) || !errors.Is(err, ErrForbidden) {
+		t.Fatalf("error = %v, want *ScopeError wrapping ErrForbidden", err)
+	}
+	if len(scopeErr.Missing) != 1 || scopeErr.Missing[0] != "fs:write" {
+		t.Errorf("Missing = %v, want [fs:write]", scopeErr.Missing)
+	}
+
+	if err := RequireScopes(nil, "fs:read"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("anonymous error = %v, want ErrUnauthenticated", err)
+	}
+}
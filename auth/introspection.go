@@ -0,0 +1,201 @@
+// auth/introspection.go
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IntrospectionConfig configures OAuth2 token introspection (RFC 7662)
+type IntrospectionConfig struct {
+	// Endpoint is the introspection URL of the authorization server
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+
+	// ClientID and ClientSecret authenticate this server to the endpoint
+	ClientID     string `yaml:"client_id" json:"client_id"`
+	ClientSecret string `yaml:"client_secret" json:"client_secret"`
+
+	// CacheTTL bounds how long an introspection result is reused (default: 30s)
+	// Results are never cached past the token's expiry
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"`
+
+	// Timeout for introspection requests (default: 10s)
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+}
+
+// IntrospectionResult is the response of an introspection endpoint
+type IntrospectionResult struct {
+	Active    bool   `json:"active"`
+	Subject   string `json:"sub,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	Audience  any    `json:"aud,omitempty"`
+}
+
+// Scopes returns the space-separated scope claim as a slice
+func (r *IntrospectionResult) Scopes() []string {
+	return strings.Fields(r.Scope)
+}
+
+// Principal converts an active introspection result into a principal
+// The subject is preferred as ID, falling back to username and client ID
+func (r *IntrospectionResult) Principal() *Principal {
+	id := r.Subject
+	if id == "" {
+		id = r.Username
+	}
+	if id == "" {
+		id = r.ClientID
+	}
+
+	claims := map[string]interface{}{}
+	if r.ClientID != "" {
+		claims["client_id"] = r.ClientID
+	}
+	if r.Username != "" {
+		claims["username"] = r.Username
+	}
+	if r.Issuer != "" {
+		claims["iss"] = r.Issuer
+	}
+	if r.ExpiresAt != 0 {
+		claims["exp"] = r.ExpiresAt
+	}
+
+	return &Principal{
+		ID:     id,
+		Method: "introspection",
+		Scopes: r.Scopes(),
+		Claims: claims,
+	}
+}
+
+// Introspector validates tokens against an introspection endpoint
+// Results are cached by token hash. Thread-safe.
+type Introspector struct {
+	config IntrospectionConfig
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]introspectionEntry
+}
+
+type introspectionEntry struct {
+	result    *IntrospectionResult
+	expiresAt time.Time
+}
+
+// maxIntrospectionCache bounds the number of cached introspection results
+const maxIntrospectionCache = 10000
+
+// NewIntrospector creates a new introspection client
+func NewIntrospector(config IntrospectionConfig) *Introspector {
+	if config.CacheTTL == 0 {
+		config.CacheTTL = 30 * time.Second
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &Introspector{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		cache:  make(map[string]introspectionEntry),
+	}
+}
+
+// Introspect returns the introspection result for token
+func (i *Introspector) Introspect(ctx context.Context, token string) (*IntrospectionResult, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	now := i.now()
+
+	i.mu.Lock()
+	if entry, ok := i.cache[key]; ok {
+		if now.Before(entry.expiresAt) {
+			i.mu.Unlock()
+			return entry.result, nil
+		}
+		delete(i.cache, key)
+	}
+	i.mu.Unlock()
+
+	result, err := i.fetch(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(i.config.CacheTTL)
+	if result.ExpiresAt > 0 {
+		if exp := time.Unix(result.ExpiresAt, 0); exp.Before(expiresAt) {
+			expiresAt = exp
+		}
+	}
+
+	i.mu.Lock()
+	if len(i.cache) >= maxIntrospectionCache {
+		for k, e := range i.cache {
+			if !now.Before(e.expiresAt) {
+				delete(i.cache, k)
+			}
+		}
+	}
+	if len(i.cache) < maxIntrospectionCache {
+		i.cache[key] = introspectionEntry{result: result, expiresAt: expiresAt}
+	}
+	i.mu.Unlock()
+
+	return result, nil
+}
+
+// fetch performs the introspection request
+func (i *Introspector) fetch(ctx context.Context, token string) (*IntrospectionResult, error) {
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", "access_token")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.config.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create introspection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if i.config.ClientID != "" {
+		req.SetBasicAuth(url.QueryEscape(i.config.ClientID), url.QueryEscape(i.config.ClientSecret))
+	}
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("introspection request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("introspection endpoint returned status %d", resp.StatusCode)
+	}
+
+	var result IntrospectionResult
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode introspection response: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,360 @@
+// auth/jwks.go
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ============================================================
+// JSON Web Keys (RFC 7517)
+// ============================================================
+
+// jwk is a public JSON Web Key
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// EC and OKP
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// jwkSet is a JWKS document
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// jwksKey is a parsed verification key
+type jwksKey struct {
+	alg string // empty: any algorithm matching the key type
+	key crypto.PublicKey
+}
+
+// publicKey decodes the key material
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	decode := func(field, s string) ([]byte, error) {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(b) == 0 {
+			return nil, fmt.Errorf("jwk %q: invalid %s", k.Kid, field)
+		}
+		return b, nil
+	}
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("jwk %q: exponent too large", k.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := decode("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("jwk %q: point not on curve", k.Kid)
+		}
+		return key, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", k.Kid, k.Crv)
+		}
+		x, err := decode("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk %q: invalid Ed25519 key size", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("jwk %q: unsupported key type %q", k.Kid, k.Kty)
+}
+
+// publicJWK encodes a public key as a JWK
+func publicJWK(kid, alg string, key crypto.PublicKey) (jwk, error) {
+	enc := base64.RawURLEncoding.EncodeToString
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return jwk{Kty: "RSA", Kid: kid, Use: "sig", Alg: alg,
+			N: enc(k.N.Bytes()), E: enc(big.NewInt(int64(k.E)).Bytes())}, nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return jwk{Kty: "EC", Kid: kid, Use: "sig", Alg: alg, Crv: k.Curve.Params().Name,
+			X: enc(k.X.FillBytes(make([]byte, size))), Y: enc(k.Y.FillBytes(make([]byte, size)))}, nil
+	case ed25519.PublicKey:
+		return jwk{Kty: "OKP", Kid: kid, Use: "sig", Alg: alg, Crv: "Ed25519", X: enc(k)}, nil
+	}
+	return jwk{}, fmt.Errorf("unsupported public key type %T", key)
+}
+
+// ============================================================
+// JWKS cache
+// ============================================================
+
+// jwksMinRefresh limits refetches triggered by unknown key IDs
+const jwksMinRefresh = 30 * time.Second
+
+// jwksCache fetches and caches the keys published at a JWKS URL
+// Keys are refetched after ttl, or early when a token names an unknown
+// key ID (at most every jwksMinRefresh), so key rotation needs no restart.
+// A failed refetch keeps the previous keys. Thread-safe.
+type jwksCache struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]jwksKey
+	fetchedAt time.Time
+}
+
+// newJWKSCache creates a cache for url
+func newJWKSCache(url string, ttl time.Duration, client *http.Client, now func() time.Time) *jwksCache {
+	return &jwksCache{url: url, ttl: ttl, client: client, now: now}
+}
+
+// key returns the key named kid ("" when the token names none and the
+// set holds a single key)
+func (c *jwksCache) key(ctx context.Context, kid string) (jwksKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	age := now.Sub(c.fetchedAt)
+	_, known := c.lookup(kid)
+	if (c.keys != nil && age >= c.ttl) || (!known && age >= jwksMinRefresh) {
+		if err := c.fetch(ctx); err != nil && c.keys == nil {
+			return jwksKey{}, err
+		}
+	}
+	if c.keys == nil {
+		return jwksKey{}, fmt.Errorf("jwks at %s unavailable", c.url)
+	}
+
+	key, ok := c.lookup(kid)
+	if !ok {
+		return jwksKey{}, fmt.Errorf("%w: unknown signing key %q", ErrInvalidCredentials, kid)
+	}
+	return key, nil
+}
+
+// lookup finds kid among the cached keys; caller holds mu
+func (c *jwksCache) lookup(kid string) (jwksKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// invalidate forces the next lookup to refetch
+func (c *jwksCache) invalidate() {
+	c.mu.Lock()
+	c.fetchedAt = time.Time{}
+	c.keys = nil
+	c.mu.Unlock()
+}
+
+// fetch downloads the key set; caller holds mu
+// Keys that fail to parse or are not meant for signatures are skipped.
+func (c *jwksCache) fetch(ctx context.Context) error {
+	// Record the attempt first, so a failing endpoint is not hammered
+	c.fetchedAt = c.now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("create jwks request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwks request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("jwks endpoint returned status %d", resp.StatusCode)
+	}
+
+	var set jwkSet
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return fmt.Errorf("decode jwks: %w", err)
+	}
+
+	keys := make(map[string]jwksKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = jwksKey{alg: k.Alg, key: pub}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("jwks at %s holds no usable signing keys", c.url)
+	}
+
+	c.keys = keys
+	return nil
+}
+
+// ============================================================
+// JWS signatures (RFC 7518)
+// ============================================================
+
+// jwsAlgorithms lists the accepted signature algorithms
+// Symmetric algorithms and "none" are never accepted: keys come from JWKS.
+var jwsAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// jwsCurves maps ECDSA algorithms to their curves
+var jwsCurves = map[string]string{"ES256": "P-256", "ES384": "P-384", "ES512": "P-521"}
+
+// jwsHash returns the digest of signingInput for alg
+func jwsHash(alg, signingInput string) (crypto.Hash, []byte) {
+	switch alg[len(alg)-3:] {
+	case "384":
+		sum := sha512.Sum384([]byte(signingInput))
+		return crypto.SHA384, sum[:]
+	case "512":
+		sum := sha512.Sum512([]byte(signingInput))
+		return crypto.SHA512, sum[:]
+	}
+	sum := sha256.Sum256([]byte(signingInput))
+	return crypto.SHA256, sum[:]
+}
+
+// verifyJWS checks the signature of a compact JWS
+func verifyJWS(alg string, key crypto.PublicKey, signingInput string, sig []byte) error {
+	if alg == "EdDSA" {
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(pub, []byte(signingInput), sig) {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidCredentials)
+		}
+		return nil
+	}
+
+	hash, digest := jwsHash(alg, signingInput)
+	var valid bool
+	switch alg[:2] {
+	case "RS":
+		if pub, ok := key.(*rsa.PublicKey); ok {
+			valid = rsa.VerifyPKCS1v15(pub, hash, digest, sig) == nil
+		}
+	case "PS":
+		if pub, ok := key.(*rsa.PublicKey); ok {
+			valid = rsa.VerifyPSS(pub, hash, digest, sig, nil) == nil
+		}
+	case "ES":
+		if pub, ok := key.(*ecdsa.PublicKey); ok && pub.Curve.Params().Name == jwsCurves[alg] {
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			if len(sig) == 2*size {
+				r := new(big.Int).SetBytes(sig[:size])
+				s := new(big.Int).SetBytes(sig[size:])
+				valid = ecdsa.Verify(pub, digest, r, s)
+			}
+		}
+	}
+	if !valid {
+		return fmt.Errorf("%w: invalid signature", ErrInvalidCredentials)
+	}
+	return nil
+}
+
+// jwsAlgorithmFor picks the signature algorithm for a private key
+func jwsAlgorithmFor(key crypto.Signer) (string, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return "RS256", nil
+	case *ecdsa.PrivateKey:
+		for alg, curve := range jwsCurves {
+			if k.Curve.Params().Name == curve {
+				return alg, nil
+			}
+		}
+		return "", fmt.Errorf("unsupported curve %s", k.Curve.Params().Name)
+	case ed25519.PrivateKey:
+		return "EdDSA", nil
+	}
+	return "", fmt.Errorf("unsupported signing key type %T", key)
+}
+
+// signJWS signs signingInput with key
+func signJWS(alg string, key crypto.Signer, signingInput string) ([]byte, error) {
+	if alg == "EdDSA" {
+		return key.Sign(rand.Reader, []byte(signingInput), crypto.Hash(0))
+	}
+
+	hash, digest := jwsHash(alg, signingInput)
+	if k, ok := key.(*ecdsa.PrivateKey); ok {
+		// JWS wants the fixed-size r||s form, not ASN.1
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	}
+	return key.Sign(rand.Reader, digest, hash)
+}
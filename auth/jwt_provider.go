@@ -0,0 +1,616 @@
+// auth/jwt_provider.go
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrJWTSigningUnconfigured indicates a JWT provider has no signing key
+// to mint outbound tokens with
+var ErrJWTSigningUnconfigured = errors.New("jwt signing key not configured")
+
+// JWTConfig holds JWT provider configuration
+// Issuers validates inbound bearer tokens; Signing mints outbound ones.
+// Either may be left empty.
+type JWTConfig struct {
+	// Issuers whose tokens are accepted
+	Issuers []JWTIssuerConfig `yaml:"issuers" json:"issuers"`
+
+	// Leeway tolerates clock skew in exp, nbf and iat (default: 60s)
+	Leeway time.Duration `yaml:"leeway" json:"leeway"`
+
+	// JWKSCacheTTL is how long fetched keys are used before refetching
+	// (default: 1h); unknown key IDs trigger an early refetch
+	JWKSCacheTTL time.Duration `yaml:"jwks_cache_ttl" json:"jwks_cache_ttl"`
+
+	// Signing mints service-to-service tokens for outbound calls
+	Signing JWTSigningConfig `yaml:"signing" json:"signing"`
+}
+
+// JWTIssuerConfig describes a trusted token issuer
+type JWTIssuerConfig struct {
+	// Issuer must equal the token's iss claim
+	Issuer string `yaml:"issuer" json:"issuer"`
+
+	// JWKSURL publishes the issuer's signing keys
+	JWKSURL string `yaml:"jwks_url" json:"jwks_url"`
+
+	// Audiences accepted in the aud claim; at least one is required
+	Audiences []string `yaml:"audiences" json:"audiences"`
+
+	// Algorithms accepted (default: RS*, PS*, ES* and EdDSA)
+	Algorithms []string `yaml:"algorithms,omitempty" json:"algorithms,omitempty"`
+
+	// SubjectClaim names the principal ID claim (default: "sub")
+	SubjectClaim string `yaml:"subject_claim,omitempty" json:"subject_claim,omitempty"`
+}
+
+// JWTSigningConfig configures minting of outbound tokens
+type JWTSigningConfig struct {
+	// PrivateKeyPath is a PEM RSA, ECDSA or Ed25519 private key file
+	PrivateKeyPath string `yaml:"private_key_path" json:"private_key_path"`
+
+	// PrivateKey is the PEM key itself; takes precedence over PrivateKeyPath
+	PrivateKey string `yaml:"-" json:"-"`
+
+	// KeyID is sent as the kid header, so peers can pick the key from
+	// PublicJWKS
+	KeyID string `yaml:"key_id" json:"key_id"`
+
+	// Issuer and Subject of minted tokens
+	Issuer  string `yaml:"issuer" json:"issuer"`
+	Subject string `yaml:"subject" json:"subject"`
+
+	// Audience of minted tokens; resources override it with an
+	// "audience" config key
+	Audience string `yaml:"audience" json:"audience"`
+
+	// TTL of minted tokens (default: 5m)
+	TTL time.Duration `yaml:"ttl" json:"ttl"`
+}
+
+// enabled reports whether a signing key is configured
+func (c JWTSigningConfig) enabled() bool {
+	return c.PrivateKey != "" || c.PrivateKeyPath != ""
+}
+
+// Validate validates the JWT configuration
+func (c *JWTConfig) Validate() error {
+	if len(c.Issuers) == 0 && !c.Signing.enabled() {
+		return fmt.Errorf("jwt: no issuers or signing key configured")
+	}
+
+	seen := make(map[string]bool)
+	for i, issuer := range c.Issuers {
+		if issuer.Issuer == "" {
+			return fmt.Errorf("jwt issuer #%d: issuer is required", i)
+		}
+		if seen[issuer.Issuer] {
+			return fmt.Errorf("jwt issuer %q: duplicate issuer", issuer.Issuer)
+		}
+		seen[issuer.Issuer] = true
+
+		u, err := url.Parse(issuer.JWKSURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("jwt issuer %q: jwks_url must be an absolute http(s) URL, got %q", issuer.Issuer, issuer.JWKSURL)
+		}
+		if len(issuer.Audiences) == 0 {
+			return fmt.Errorf("jwt issuer %q: at least one audience is required", issuer.Issuer)
+		}
+		for _, alg := range issuer.Algorithms {
+			if !slices.Contains(jwsAlgorithms, alg) {
+				return fmt.Errorf("jwt issuer %q: unsupported algorithm %q (supported: %s)",
+					issuer.Issuer, alg, strings.Join(jwsAlgorithms, ", "))
+			}
+		}
+	}
+
+	if c.Signing.enabled() && c.Signing.Issuer == "" {
+		return fmt.Errorf("jwt signing: issuer is required")
+	}
+	if c.Leeway < 0 || c.JWKSCacheTTL < 0 || c.Signing.TTL < 0 {
+		return fmt.Errorf("jwt: durations must not be negative")
+	}
+	return nil
+}
+
+// jwtIssuer is a trusted issuer with its key cache
+type jwtIssuer struct {
+	config JWTIssuerConfig
+	keys   *jwksCache
+}
+
+// JWTProvider validates and mints JSON Web Tokens
+//
+// Inbound, it is an Authenticator: bearer tokens signed by a configured
+// issuer (keys fetched from its JWKS) with an accepted audience become a
+// Principal with the token's claims. Register it with
+// framework.WithAuthenticator, or configure transport.http.auth.jwt.
+//
+// Outbound, it is an AuthProvider whose resources send short-lived tokens
+// signed with the configured key, for service-to-service calls. Peers
+// validate them with the keys from PublicJWKS.
+//
+// Resource config keys:
+//
+//	base_url   API root
+//	audience   aud of tokens sent to this resource (default: signing.audience)
+type JWTProvider struct {
+	*BaseProvider
+	config  JWTConfig
+	issuers map[string]*jwtIssuer
+	client  *http.Client
+	now     func() time.Time
+
+	// Outbound signing
+	signer  crypto.Signer
+	alg     string
+	sources sync.Map // audience -> oauth2.TokenSource
+}
+
+// NewJWTProvider creates a new JWT provider
+func NewJWTProvider(name string, config JWTConfig) (*JWTProvider, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Leeway == 0 {
+		config.Leeway = 60 * time.Second
+	}
+	if config.JWKSCacheTTL == 0 {
+		config.JWKSCacheTTL = time.Hour
+	}
+	if config.Signing.TTL == 0 {
+		config.Signing.TTL = 5 * time.Minute
+	}
+
+	p := &JWTProvider{
+		BaseProvider: NewBaseProvider(name),
+		config:       config,
+		issuers:      make(map[string]*jwtIssuer, len(config.Issuers)),
+		client:       &http.Client{Timeout: 10 * time.Second},
+		now:          time.Now,
+	}
+
+	for _, issuer := range config.Issuers {
+		if len(issuer.Algorithms) == 0 {
+			issuer.Algorithms = jwsAlgorithms
+		}
+		if issuer.SubjectClaim == "" {
+			issuer.SubjectClaim = "sub"
+		}
+		p.issuers[issuer.Issuer] = &jwtIssuer{
+			config: issuer,
+			keys:   newJWKSCache(issuer.JWKSURL, config.JWKSCacheTTL, p.client, func() time.Time { return p.now() }),
+		}
+	}
+
+	if config.Signing.enabled() {
+		pemData := []byte(config.Signing.PrivateKey)
+		if len(pemData) == 0 {
+			data, err := os.ReadFile(config.Signing.PrivateKeyPath)
+			if err != nil {
+				return nil, fmt.Errorf("jwt signing: read private key: %w", err)
+			}
+			pemData = data
+		}
+		signer, err := parseSigningKey(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("jwt signing: %w", err)
+		}
+		alg, err := jwsAlgorithmFor(signer)
+		if err != nil {
+			return nil, fmt.Errorf("jwt signing: %w", err)
+		}
+		p.signer = signer
+		p.alg = alg
+	}
+
+	return p, nil
+}
+
+// parseSigningKey decodes a PEM RSA, ECDSA or Ed25519 private key
+func parseSigningKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: unsupported format")
+	}
+	return key, nil
+}
+
+// ============================================================
+// Inbound validation
+// ============================================================
+
+// Authenticate implements Authenticator
+// Requests without a bearer token, or with one that is not a JWT, get
+// ErrNoCredentials so other authenticators can try them.
+func (p *JWTProvider) Authenticate(r *http.Request) (*Principal, error) {
+	token, ok := BearerToken(r)
+	if !ok || len(p.issuers) == 0 || strings.Count(token, ".") != 2 {
+		return nil, ErrNoCredentials
+	}
+	return p.ValidateToken(r.Context(), token)
+}
+
+// ValidateToken verifies a compact JWT and returns its principal
+// The signature, issuer, audience, exp, nbf and iat are all checked.
+func (p *JWTProvider) ValidateToken(ctx context.Context, token string) (*Principal, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: jwt: %s", ErrInvalidCredentials, fmt.Sprintf(format, args...))
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, invalid("header: %v", err)
+	}
+	claims := make(map[string]interface{})
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, invalid("claims: %v", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("signature encoding")
+	}
+
+	iss, _ := claims["iss"].(string)
+	issuer, ok := p.issuers[iss]
+	if !ok {
+		return nil, invalid("untrusted issuer %q", iss)
+	}
+	if !slices.Contains(issuer.config.Algorithms, header.Alg) {
+		return nil, invalid("algorithm %q not accepted", header.Alg)
+	}
+
+	key, err := issuer.keys.key(ctx, header.Kid)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+		return nil, NewAuthError(p.Name(), "", "fetch_jwks", err)
+	}
+	if key.alg != "" && key.alg != header.Alg {
+		return nil, invalid("key %q is for %s, token uses %s", header.Kid, key.alg, header.Alg)
+	}
+	if err := verifyJWS(header.Alg, key.key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	if err := p.checkTimes(claims); err != nil {
+		return nil, invalid("%v", err)
+	}
+	if !audienceMatches(claims["aud"], issuer.config.Audiences) {
+		return nil, invalid("audience not accepted")
+	}
+
+	return jwtPrincipal(claims, issuer.config.SubjectClaim), nil
+}
+
+// checkTimes checks the exp (required), nbf and iat claims
+func (p *JWTProvider) checkTimes(claims map[string]interface{}) error {
+	now := p.now()
+	leeway := p.config.Leeway
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("exp claim is required")
+	}
+	if now.After(exp.Add(leeway)) {
+		return ErrTokenExpired
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(leeway).Before(nbf) {
+		return fmt.Errorf("token not valid yet")
+	}
+	if iat, ok := numericDate(claims["iat"]); ok && now.Add(leeway).Before(iat) {
+		return fmt.Errorf("token issued in the future")
+	}
+	return nil
+}
+
+// decodeJWTPart decodes a base64url JSON segment
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// numericDate reads a NumericDate claim
+func numericDate(v interface{}) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// audienceMatches reports whether the aud claim (a string or an array)
+// names an accepted audience
+func audienceMatches(aud interface{}, accepted []string) bool {
+	switch a := aud.(type) {
+	case string:
+		return slices.Contains(accepted, a)
+	case []interface{}:
+		for _, v := range a {
+			if s, ok := v.(string); ok && slices.Contains(accepted, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jwtPrincipal converts validated claims into a principal
+// Scopes come from "scope" (space-separated) or "scp" (string or array).
+func jwtPrincipal(claims map[string]interface{}, subjectClaim string) *Principal {
+	id, _ := claims[subjectClaim].(string)
+	if id == "" {
+		id, _ = claims["client_id"].(string)
+	}
+
+	var scopes []string
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = strings.Fields(scope)
+	} else {
+		switch scp := claims["scp"].(type) {
+		case string:
+			scopes = strings.Fields(scp)
+		case []interface{}:
+			for _, v := range scp {
+				if s, ok := v.(string); ok {
+					scopes = append(scopes, s)
+				}
+			}
+		}
+	}
+
+	return &Principal{
+		ID:     id,
+		Method: "jwt",
+		Scopes: scopes,
+		Claims: claims,
+	}
+}
+
+// ============================================================
+// Outbound minting
+// ============================================================
+
+// MintToken signs a token for audience ("" for signing.audience)
+// extra claims are added, but cannot override iss, sub, aud, iat, nbf,
+// exp or jti.
+func (p *JWTProvider) MintToken(audience string, extra map[string]interface{}) (string, time.Time, error) {
+	if p.signer == nil {
+		return "", time.Time{}, NewAuthError(p.Name(), "", "mint", ErrJWTSigningUnconfigured)
+	}
+	if audience == "" {
+		audience = p.config.Signing.Audience
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", time.Time{}, NewAuthError(p.Name(), "", "mint", err)
+	}
+
+	now := p.now()
+	expiresAt := now.Add(p.config.Signing.TTL)
+	claims := make(map[string]interface{}, len(extra)+7)
+	for k, v := range extra {
+		claims[k] = v
+	}
+	claims["iss"] = p.config.Signing.Issuer
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+	claims["exp"] = expiresAt.Unix()
+	claims["jti"] = hex.EncodeToString(jti)
+	if p.config.Signing.Subject != "" {
+		claims["sub"] = p.config.Signing.Subject
+	}
+	if audience != "" {
+		claims["aud"] = audience
+	}
+
+	header := map[string]string{"alg": p.alg, "typ": "JWT"}
+	if p.config.Signing.KeyID != "" {
+		header["kid"] = p.config.Signing.KeyID
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", time.Time{}, NewAuthError(p.Name(), "", "mint", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, NewAuthError(p.Name(), "", "mint", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	sig, err := signJWS(p.alg, p.signer, signingInput)
+	if err != nil {
+		return "", time.Time{}, NewAuthError(p.Name(), "", "mint", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), expiresAt, nil
+}
+
+// PublicJWKS returns the JWKS document holding the signing key, for peers
+// to validate minted tokens (serve it at e.g. /.well-known/jwks.json)
+func (p *JWTProvider) PublicJWKS() ([]byte, error) {
+	if p.signer == nil {
+		return nil, NewAuthError(p.Name(), "", "public_jwks", ErrJWTSigningUnconfigured)
+	}
+	key, err := publicJWK(p.config.Signing.KeyID, p.alg, p.signer.Public())
+	if err != nil {
+		return nil, NewAuthError(p.Name(), "", "public_jwks", err)
+	}
+	return json.Marshal(jwkSet{Keys: []jwk{key}})
+}
+
+// audienceFor returns the audience of tokens sent to resourceID
+func (p *JWTProvider) audienceFor(resourceID string) (string, error) {
+	if resourceID == "" {
+		return p.config.Signing.Audience, nil
+	}
+	config, err := p.GetResourceConfig(resourceID)
+	if err != nil {
+		return "", err
+	}
+	if audience, ok := config.Config["audience"].(string); ok && audience != "" {
+		return audience, nil
+	}
+	return p.config.Signing.Audience, nil
+}
+
+// tokenSource returns the cached, auto-renewing token source for audience
+func (p *JWTProvider) tokenSource(audience string) oauth2.TokenSource {
+	if source, ok := p.sources.Load(audience); ok {
+		return source.(oauth2.TokenSource)
+	}
+	// Renew once a fifth of the lifetime is left
+	source := oauth2.ReuseTokenSourceWithExpiry(nil, &jwtMintSource{provider: p, audience: audience}, p.config.Signing.TTL/5)
+	actual, _ := p.sources.LoadOrStore(audience, source)
+	return actual.(oauth2.TokenSource)
+}
+
+// GetResource returns an HTTP client that sends minted tokens
+func (p *JWTProvider) GetResource(ctx context.Context, resourceID string) (Resource, error) {
+	config, err := p.GetResourceConfig(resourceID)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+	}
+	if p.signer == nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_resource", ErrJWTSigningUnconfigured)
+	}
+	audience, err := p.audienceFor(resourceID)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_resource", err)
+	}
+
+	baseURL, _ := config.Config["base_url"].(string)
+	client := oauth2.NewClient(context.Background(), p.tokenSource(audience))
+	client.Timeout = 30 * time.Second
+
+	return &OAuth2Resource{
+		client:     client,
+		baseURL:    baseURL,
+		resourceID: resourceID,
+	}, nil
+}
+
+// GetSecret returns a minted token for the resource's audience
+func (p *JWTProvider) GetSecret(ctx context.Context, resourceID string) (Secret, error) {
+	audience, err := p.audienceFor(resourceID)
+	if err != nil {
+		return Secret{}, NewAuthError(p.Name(), resourceID, "get_secret", err)
+	}
+	if p.signer == nil {
+		return Secret{}, NewAuthError(p.Name(), resourceID, "get_secret", ErrJWTSigningUnconfigured)
+	}
+	token, err := p.tokenSource(audience).Token()
+	if err != nil {
+		return Secret{}, NewAuthError(p.Name(), resourceID, "get_secret", err)
+	}
+	return Secret{
+		value:     token.AccessToken,
+		Header:    "Authorization",
+		Scheme:    "Bearer",
+		KeyID:     p.config.Signing.KeyID,
+		ExpiresAt: token.Expiry,
+	}, nil
+}
+
+// RoundTripper sends minted tokens for the resource's audience
+func (p *JWTProvider) RoundTripper(ctx context.Context, resourceID string, base http.RoundTripper) (http.RoundTripper, error) {
+	audience, err := p.audienceFor(resourceID)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "round_tripper", err)
+	}
+	if p.signer == nil {
+		return nil, NewAuthError(p.Name(), resourceID, "round_tripper", ErrJWTSigningUnconfigured)
+	}
+	return &oauth2.Transport{Source: p.tokenSource(audience), Base: base}, nil
+}
+
+// Validate fetches every issuer's keys and mints a test token
+func (p *JWTProvider) Validate(ctx context.Context) error {
+	for name, issuer := range p.issuers {
+		if _, err := issuer.keys.key(ctx, ""); err != nil && !errors.Is(err, ErrInvalidCredentials) {
+			return NewAuthError(p.Name(), "", "validate", fmt.Errorf("issuer %q: %w", name, err))
+		}
+	}
+	if p.signer != nil {
+		if _, _, err := p.MintToken("", nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Refresh refetches issuer keys and discards cached outbound tokens
+func (p *JWTProvider) Refresh(ctx context.Context) error {
+	for _, issuer := range p.issuers {
+		issuer.keys.invalidate()
+	}
+	p.sources.Clear()
+	return nil
+}
+
+// Close releases provider resources
+func (p *JWTProvider) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// jwtMintSource mints tokens on demand
+type jwtMintSource struct {
+	provider *JWTProvider
+	audience string
+}
+
+// Token implements oauth2.TokenSource
+func (s *jwtMintSource) Token() (*oauth2.Token, error) {
+	token, expiresAt, err := s.provider.MintToken(s.audience, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Token{AccessToken: token, TokenType: "Bearer", Expiry: expiresAt}, nil
+}
@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pkcs8PEM encodes a private key as PKCS#8 PEM
+func pkcs8PEM(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// newJWTMinter creates a provider that signs tokens as https://svc
+func newJWTMinter(t *testing.T, key crypto.Signer, kid string) *JWTProvider {
+	t.Helper()
+	p, err := NewJWTProvider("minter", JWTConfig{Signing: JWTSigningConfig{
+		PrivateKey: pkcs8PEM(t, key),
+		KeyID:      kid,
+		Issuer:     "https://svc",
+		Subject:    "svc-a",
+		Audience:   "api",
+	}})
+	if err != nil {
+		t.Fatalf("NewJWTProvider failed: %v", err)
+	}
+	return p
+}
+
+// jwksServer serves the JWKS of the current minter
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	minter  *JWTProvider
+	fetches int
+}
+
+func newJWKSServer(t *testing.T, minter *JWTProvider) *jwksServer {
+	s := &jwksServer{minter: minter}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+		data, err := s.minter.PublicJWKS()
+		if err != nil {
+			t.Errorf("PublicJWKS failed: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) rotate(minter *JWTProvider) {
+	s.mu.Lock()
+	s.minter = minter
+	s.mu.Unlock()
+}
+
+func newJWTValidator(t *testing.T, jwksURL string) *JWTProvider {
+	t.Helper()
+	p, err := NewJWTProvider("validator", JWTConfig{Issuers: []JWTIssuerConfig{{
+		Issuer:    "https://svc",
+		JWKSURL:   jwksURL,
+		Audiences: []string{"api", "other"},
+	}}})
+	if err != nil {
+		t.Fatalf("NewJWTProvider failed: %v", err)
+	}
+	return p
+}
+
+func bearerRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestJWTProvider_Authenticate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minter := newJWTMinter(t, key, "k1")
+	server := newJWKSServer(t, minter)
+	validator := newJWTValidator(t, server.URL)
+
+	token, _, err := minter.MintToken("", map[string]interface{}{"scope": "tools:read admin", "iss": "forged"})
+	if err != nil {
+		t.Fatalf("MintToken failed: %v", err)
+	}
+
+	principal, err := validator.Authenticate(bearerRequest(token))
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if principal.ID != "svc-a" || principal.Method != "jwt" || !principal.HasScope("admin") {
+		t.Errorf("principal = %+v", principal)
+	}
+	if principal.Claims["iss"] != "https://svc" {
+		t.Errorf("extra claims overrode iss: %v", principal.Claims["iss"])
+	}
+
+	// Keys are cached
+	validator.Authenticate(bearerRequest(token))
+	if server.fetches != 1 {
+		t.Errorf("jwks fetched %d times, want 1", server.fetches)
+	}
+
+	if _, err := validator.Authenticate(bearerRequest("opaque-token")); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("opaque token: expected ErrNoCredentials, got %v", err)
+	}
+	if _, err := validator.Authenticate(httptest.NewRequest(http.MethodPost, "/rpc", nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("no token: expected ErrNoCredentials, got %v", err)
+	}
+}
+
+func TestJWTProvider_Rejects(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	minter := newJWTMinter(t, key, "k1")
+	server := newJWKSServer(t, minter)
+	validator := newJWTValidator(t, server.URL)
+
+	mint := func(audience string) string {
+		token, _, err := minter.MintToken(audience, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := mint("")
+	parts := strings.Split(valid, ".")
+	enc := base64.RawURLEncoding.EncodeToString
+
+	minter.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	expired := mint("")
+	minter.now = time.Now
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	forged, _, _ := newJWTMinter(t, otherKey, "k1").MintToken("", nil)
+
+	tests := map[string]string{
+		"wrong audience":   mint("elsewhere"),
+		"expired":          expired,
+		"tampered claims":  parts[0] + "." + enc([]byte(`{"iss":"https://svc","aud":"api","sub":"root","exp":9999999999}`)) + "." + parts[2],
+		"alg none":         enc([]byte(`{"alg":"none","kid":"k1"}`)) + "." + parts[1] + ".",
+		"alg mismatch":     enc([]byte(`{"alg":"RS256","kid":"k1"}`)) + "." + parts[1] + "." + parts[2],
+		"foreign key":      forged,
+		"untrusted issuer": parts[0] + "." + enc([]byte(`{"iss":"https://evil","aud":"api","exp":9999999999}`)) + "." + parts[2],
+	}
+	for name, token := range tests {
+		if _, err := validator.Authenticate(bearerRequest(token)); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: expected ErrInvalidCredentials, got %v", name, err)
+		}
+	}
+
+	if _, err := validator.Authenticate(bearerRequest(mint("other"))); err != nil {
+		t.Errorf("second audience rejected: %v", err)
+	}
+}
+
+func TestJWTProvider_KeyRotation(t *testing.T) {
+	key1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key2, _ := rsa.GenerateKey(rand.Reader, 2048)
+	minter1 := newJWTMinter(t, key1, "k1")
+	minter2 := newJWTMinter(t, key2, "k2")
+
+	server := newJWKSServer(t, minter1)
+	validator := newJWTValidator(t, server.URL)
+	now := time.Now()
+	validator.now = func() time.Time { return now }
+
+	token1, _, _ := minter1.MintToken("", nil)
+	if _, err := validator.Authenticate(bearerRequest(token1)); err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+
+	server.rotate(minter2)
+	token2, _, _ := minter2.MintToken("", nil)
+
+	// Unknown key IDs refetch, but not more than every jwksMinRefresh
+	if _, err := validator.Authenticate(bearerRequest(token2)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials before refetch, got %v", err)
+	}
+	now = now.Add(jwksMinRefresh)
+	if _, err := validator.Authenticate(bearerRequest(token2)); err != nil {
+		t.Errorf("rotated key rejected: %v", err)
+	}
+	if server.fetches != 2 {
+		t.Errorf("jwks fetched %d times, want 2", server.fetches)
+	}
+}
+
+func TestJWTProvider_KeyTypes(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := map[string]crypto.Signer{"RS256": rsaKey, "ES384": ecKey, "EdDSA": edKey}
+	for alg, key := range tests {
+		minter := newJWTMinter(t, key, alg)
+		if minter.alg != alg {
+			t.Errorf("%s key signs with %s", alg, minter.alg)
+		}
+		server := newJWKSServer(t, minter)
+		token, _, err := minter.MintToken("", nil)
+		if err != nil {
+			t.Fatalf("%s: MintToken failed: %v", alg, err)
+		}
+		if _, err := newJWTValidator(t, server.URL).ValidateToken(context.Background(), token); err != nil {
+			t.Errorf("%s: ValidateToken failed: %v", alg, err)
+		}
+	}
+}
+
+func TestJWTProvider_OutboundResource(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	minter := newJWTMinter(t, key, "k1")
+	jwks := newJWKSServer(t, minter)
+
+	validator, err := NewJWTProvider("validator", JWTConfig{Issuers: []JWTIssuerConfig{{
+		Issuer:    "https://svc",
+		JWKSURL:   jwks.URL,
+		Audiences: []string{"billing"},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := validator.Authenticate(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer api.Close()
+
+	minter.RegisterResource(ResourceConfig{ID: "billing", Type: "api", Config: map[string]interface{}{
+		"base_url": api.URL,
+		"audience": "billing",
+	}})
+
+	resource, err := minter.GetResource(context.Background(), "billing")
+	if err != nil {
+		t.Fatalf("GetResource failed: %v", err)
+	}
+	resp, err := resource.(*OAuth2Resource).Client().Get(api.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+
+	secret, err := minter.GetSecret(context.Background(), "billing")
+	if err != nil || secret.KeyID != "k1" || secret.Scheme != "Bearer" {
+		t.Errorf("GetSecret = %+v, %v", secret, err)
+	}
+}
+
+func TestJWTConfig_Validate(t *testing.T) {
+	tests := map[string]JWTConfig{
+		"empty":          {},
+		"no jwks url":    {Issuers: []JWTIssuerConfig{{Issuer: "i", Audiences: []string{"a"}}}},
+		"no audience":    {Issuers: []JWTIssuerConfig{{Issuer: "i", JWKSURL: "https://i/jwks"}}},
+		"hmac":           {Issuers: []JWTIssuerConfig{{Issuer: "i", JWKSURL: "https://i/jwks", Audiences: []string{"a"}, Algorithms: []string{"HS256"}}}},
+		"signing issuer": {Signing: JWTSigningConfig{PrivateKeyPath: "key.pem"}},
+	}
+	for name, config := range tests {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
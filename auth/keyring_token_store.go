@@ -0,0 +1,76 @@
+// auth/keyring_token_store.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// DefaultKeyringService is the keyring service tokens are stored under
+const DefaultKeyringService = "go-mcp-framework"
+
+// KeyringTokenStore stores tokens in the OS keyring: the macOS Keychain,
+// the Windows Credential Manager or the Secret Service (GNOME Keyring,
+// KWallet) on Linux
+//
+// The keyring encrypts tokens with the user's login credentials, so no
+// encryption key needs managing. It suits CLIs and desktop deployments;
+// headless servers usually have no keyring daemon.
+type KeyringTokenStore struct {
+	service string
+}
+
+// NewKeyringTokenStore creates a keyring token store ("" service for
+// DefaultKeyringService)
+func NewKeyringTokenStore(service string) *KeyringTokenStore {
+	if service == "" {
+		service = DefaultKeyringService
+	}
+	return &KeyringTokenStore{service: service}
+}
+
+// Save saves a token to the keyring
+func (s *KeyringTokenStore) Save(ctx context.Context, providerName string, token *OAuth2Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal token: %w", err)
+	}
+	if err := keyring.Set(s.service, providerName, string(data)); err != nil {
+		return fmt.Errorf("failed to save token to keyring: %w", err)
+	}
+	return nil
+}
+
+// Load loads a token from the keyring
+func (s *KeyringTokenStore) Load(ctx context.Context, providerName string) (*OAuth2Token, error) {
+	data, err := keyring.Get(s.service, providerName)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to read token from keyring: %w", err)
+	}
+
+	var token OAuth2Token
+	if err := json.Unmarshal([]byte(data), &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	return &token, nil
+}
+
+// Delete deletes a token from the keyring
+func (s *KeyringTokenStore) Delete(ctx context.Context, providerName string) error {
+	if err := keyring.Delete(s.service, providerName); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete token from keyring: %w", err)
+	}
+	return nil
+}
+
+// Close closes the token store
+func (s *KeyringTokenStore) Close() error {
+	return nil
+}
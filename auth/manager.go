@@ -11,6 +11,9 @@ import (
 type Manager struct {
 	providers map[string]AuthProvider
 	mu        sync.RWMutex
+
+	// tools binds tools to the resources their calls use
+	tools map[string]ToolResource
 }
 
 // NewManager creates a new auth manager
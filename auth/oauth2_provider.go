@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -16,6 +17,21 @@ type OAuth2Provider struct {
 	config     *oauth2.Config
 	tokenStore TokenStore
 	token      *OAuth2Token
+	stateStore StateStore
+	stateTTL   time.Duration
+	pkce       bool
+
+	// Token lifecycle endpoints (optional)
+	introspector  *Introspector
+	revocationURL string
+	revokeOnClose bool
+	clientID      string
+	clientSecret  string
+	httpClient    *http.Client
+
+	// Down-scoped tokens by scope set (see GetScopedResource)
+	scopedMu sync.Mutex
+	scoped   map[string]*OAuth2Token
 }
 
 // OAuth2Token represents an OAuth2 token
@@ -34,6 +50,61 @@ type OAuth2Config struct {
 	Scopes       []string `yaml:"scopes" json:"scopes"`
 	AuthURL      string   `yaml:"auth_url" json:"auth_url"`
 	TokenURL     string   `yaml:"token_url" json:"token_url"`
+
+	// DeviceAuthURL is the device authorization endpoint (RFC 8628), if any
+	DeviceAuthURL string `yaml:"device_auth_url,omitempty" json:"device_auth_url,omitempty"`
+
+	// Endpoint names a registered OAuth endpoint (see RegisterOAuthEndpoint)
+	// Explicit AuthURL/TokenURL values take precedence over it.
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+
+	// DisablePKCE turns off PKCE for authorization servers that reject it
+	// PKCE (RFC 7636, S256) is sent by default.
+	DisablePKCE bool `yaml:"disable_pkce,omitempty" json:"disable_pkce,omitempty"`
+
+	// IntrospectionURL enables RFC 7662 checks of the access token in Validate
+	IntrospectionURL string `yaml:"introspection_url,omitempty" json:"introspection_url,omitempty"`
+
+	// RevocationURL is the RFC 7009 endpoint used by RevokeToken
+	RevocationURL string `yaml:"revocation_url,omitempty" json:"revocation_url,omitempty"`
+
+	// RevokeOnClose revokes the stored token when the provider is closed
+	// (i.e. on server shutdown), so it cannot outlive the process.
+	RevokeOnClose bool `yaml:"revoke_on_close,omitempty" json:"revoke_on_close,omitempty"`
+}
+
+// ResolveEndpoint fills the URLs (and default scopes) from the named endpoint
+func (c OAuth2Config) ResolveEndpoint() (OAuth2Config, error) {
+	if c.Endpoint != "" {
+		endpoint, ok := LookupOAuthEndpoint(c.Endpoint)
+		if !ok {
+			return c, fmt.Errorf("unknown oauth endpoint %q", c.Endpoint)
+		}
+		if c.AuthURL == "" {
+			c.AuthURL = endpoint.AuthURL
+		}
+		if c.TokenURL == "" {
+			c.TokenURL = endpoint.TokenURL
+		}
+		if c.DeviceAuthURL == "" {
+			c.DeviceAuthURL = endpoint.DeviceAuthURL
+		}
+		if c.Scopes == nil {
+			c.Scopes = endpoint.Scopes
+		}
+	}
+
+	endpoint := OAuthEndpoint{
+		AuthURL:          c.AuthURL,
+		TokenURL:         c.TokenURL,
+		DeviceAuthURL:    c.DeviceAuthURL,
+		IntrospectionURL: c.IntrospectionURL,
+		RevocationURL:    c.RevocationURL,
+	}
+	if err := endpoint.Validate(); err != nil {
+		return c, err
+	}
+	return c, nil
 }
 
 // NewOAuth2Provider creates a new OAuth2 provider
@@ -44,16 +115,46 @@ func NewOAuth2Provider(name string, config OAuth2Config, tokenStore TokenStore)
 		RedirectURL:  config.RedirectURL,
 		Scopes:       config.Scopes,
 		Endpoint: oauth2.Endpoint{
-			AuthURL:  config.AuthURL,
-			TokenURL: config.TokenURL,
+			AuthURL:       config.AuthURL,
+			TokenURL:      config.TokenURL,
+			DeviceAuthURL: config.DeviceAuthURL,
 		},
 	}
 
-	return &OAuth2Provider{
-		BaseProvider: NewBaseProvider(name),
-		config:       oauth2Config,
-		tokenStore:   tokenStore,
+	p := &OAuth2Provider{
+		BaseProvider:  NewBaseProvider(name),
+		config:        oauth2Config,
+		tokenStore:    tokenStore,
+		stateStore:    NewMemoryStateStore(),
+		stateTTL:      DefaultStateTTL,
+		pkce:          !config.DisablePKCE,
+		revocationURL: config.RevocationURL,
+		revokeOnClose: config.RevokeOnClose,
+		clientID:      config.ClientID,
+		clientSecret:  config.ClientSecret,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if config.IntrospectionURL != "" {
+		p.introspector = NewIntrospector(IntrospectionConfig{
+			Endpoint:     config.IntrospectionURL,
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+		})
 	}
+
+	return p
+}
+
+// SetStateStore replaces the store tracking pending authorization requests
+// The default in-memory store only works when the callback reaches the same process.
+func (p *OAuth2Provider) SetStateStore(store StateStore) {
+	p.stateStore = store
+}
+
+// SetStateTTL sets how long an authorization request may stay pending
+func (p *OAuth2Provider) SetStateTTL(ttl time.Duration) {
+	p.stateTTL = ttl
 }
 
 // GetResource returns an authenticated HTTP client
@@ -101,7 +202,20 @@ func (p *OAuth2Provider) Validate(ctx context.Context) error {
 	// Check if token is expired
 	if time.Now().After(p.token.ExpiresAt) {
 		// Try to refresh
-		return p.Refresh(ctx)
+		if err := p.Refresh(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Ask the IdP whether the token is still active (e.g. not revoked)
+	if p.introspector != nil {
+		result, err := p.Introspect(ctx)
+		if err != nil {
+			return err
+		}
+		if !result.Active {
+			return NewAuthError(p.Name(), "", "validate", ErrTokenRevoked)
+		}
 	}
 
 	return nil
@@ -155,14 +269,52 @@ func (p *OAuth2Provider) SetToken(ctx context.Context, token *OAuth2Token) error
 	return nil
 }
 
-// GetAuthURL returns the OAuth2 authorization URL
-func (p *OAuth2Provider) GetAuthURL(state string) string {
-	return p.config.AuthCodeURL(state)
+// AuthCodeURL starts an authorization code flow
+// It generates a random state (and PKCE verifier), records them in the state
+// store and returns the URL to send the user to together with the state.
+func (p *OAuth2Provider) AuthCodeURL(ctx context.Context) (authURL, state string, err error) {
+	state, err = GenerateState()
+	if err != nil {
+		return "", "", NewAuthError(p.Name(), "", "auth_url", err)
+	}
+
+	req := AuthRequest{
+		Provider:  p.Name(),
+		ExpiresAt: time.Now().Add(p.stateTTL),
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if p.pkce {
+		req.CodeVerifier = oauth2.GenerateVerifier()
+		opts = append(opts, oauth2.S256ChallengeOption(req.CodeVerifier))
+	}
+
+	if err := p.stateStore.Save(ctx, state, req); err != nil {
+		return "", "", NewAuthError(p.Name(), "", "auth_url", err)
+	}
+
+	return p.config.AuthCodeURL(state, opts...), state, nil
 }
 
 // Exchange exchanges an authorization code for a token
-func (p *OAuth2Provider) Exchange(ctx context.Context, code string) error {
-	token, err := p.config.Exchange(ctx, code)
+// state must be the value returned by AuthCodeURL; it is consumed, so a
+// callback cannot be replayed. Returns ErrInvalidState for unknown,
+// expired or already used states.
+func (p *OAuth2Provider) Exchange(ctx context.Context, state, code string) error {
+	req, err := p.stateStore.Consume(ctx, state)
+	if err != nil {
+		return NewAuthError(p.Name(), "", "exchange", err)
+	}
+	if req.Provider != p.Name() {
+		return NewAuthError(p.Name(), "", "exchange", ErrInvalidState)
+	}
+
+	var opts []oauth2.AuthCodeOption
+	if req.CodeVerifier != "" {
+		opts = append(opts, oauth2.VerifierOption(req.CodeVerifier))
+	}
+
+	token, err := p.config.Exchange(ctx, code, opts...)
 	if err != nil {
 		return NewAuthError(p.Name(), "", "exchange", err)
 	}
@@ -209,7 +361,17 @@ func (p *OAuth2Provider) ensureValidToken(ctx context.Context) error {
 }
 
 // Close closes the provider
+// With RevokeOnClose set, the current token is revoked first.
 func (p *OAuth2Provider) Close() error {
+	if p.revokeOnClose && p.token != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := p.RevokeToken(ctx)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
 	if p.tokenStore != nil {
 		return p.tokenStore.Close()
 	}
@@ -2,6 +2,10 @@ package auth
 
 import (
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/facebook"
@@ -11,7 +15,170 @@ import (
 	"golang.org/x/oauth2/slack"
 )
 
-// ProviderFactory creates OAuth2 providers for popular services
+// OAuthEndpoint describes an OAuth2 authorization server
+type OAuthEndpoint struct {
+	AuthURL       string `yaml:"auth_url" json:"auth_url"`
+	TokenURL      string `yaml:"token_url" json:"token_url"`
+	DeviceAuthURL string `yaml:"device_auth_url,omitempty" json:"device_auth_url,omitempty"`
+
+	// Token lifecycle endpoints (RFC 7662, RFC 7009), if the server offers them
+	IntrospectionURL string `yaml:"introspection_url,omitempty" json:"introspection_url,omitempty"`
+	RevocationURL    string `yaml:"revocation_url,omitempty" json:"revocation_url,omitempty"`
+
+	// Scopes requested when the caller passes nil scopes
+	Scopes []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+}
+
+// Validate checks that the endpoint URLs are absolute http(s) URLs
+// auth_url may be omitted when device_auth_url is set.
+func (e OAuthEndpoint) Validate() error {
+	check := func(field, raw string, required bool) error {
+		if raw == "" {
+			if required {
+				return fmt.Errorf("%s is required", field)
+			}
+			return nil
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%s must be an absolute http(s) URL, got %q", field, raw)
+		}
+		return nil
+	}
+
+	// Device-only servers (RFC 8628) need no authorization endpoint
+	if err := check("auth_url", e.AuthURL, e.DeviceAuthURL == ""); err != nil {
+		return err
+	}
+	if err := check("token_url", e.TokenURL, true); err != nil {
+		return err
+	}
+	if err := check("device_auth_url", e.DeviceAuthURL, false); err != nil {
+		return err
+	}
+	if err := check("introspection_url", e.IntrospectionURL, false); err != nil {
+		return err
+	}
+	return check("revocation_url", e.RevocationURL, false)
+}
+
+// endpointFrom converts an x/oauth2 endpoint
+func endpointFrom(e oauth2.Endpoint, scopes ...string) OAuthEndpoint {
+	return OAuthEndpoint{
+		AuthURL:       e.AuthURL,
+		TokenURL:      e.TokenURL,
+		DeviceAuthURL: e.DeviceAuthURL,
+		Scopes:        scopes,
+	}
+}
+
+// withRevocation sets the revocation URL of an endpoint
+func withRevocation(e OAuthEndpoint, revocationURL string) OAuthEndpoint {
+	e.RevocationURL = revocationURL
+	return e
+}
+
+// OAuth endpoint registry, seeded with the built-in providers
+var (
+	oauthEndpoints = map[string]OAuthEndpoint{
+		"github": endpointFrom(github.Endpoint, "repo", "user"),
+		"google": withRevocation(endpointFrom(google.Endpoint,
+			"https://www.googleapis.com/auth/userinfo.email",
+			"https://www.googleapis.com/auth/userinfo.profile",
+		), "https://oauth2.googleapis.com/revoke"),
+		"facebook":  endpointFrom(facebook.Endpoint, "public_profile", "email"),
+		"microsoft": endpointFrom(microsoft.AzureADEndpoint(""), "https://graph.microsoft.com/User.Read"),
+		"slack":     endpointFrom(slack.Endpoint, "channels:read", "chat:write"),
+	}
+	oauthEndpointsMu sync.RWMutex
+)
+
+// RegisterOAuthEndpoint makes an authorization server available by name
+// to ProviderFactory.Create (and framework.WithOAuth). Registering an
+// existing name replaces it, so built-in endpoints can be overridden.
+//
+// Example:
+//
+//	auth.RegisterOAuthEndpoint("okta", auth.OktaEndpoint("acme.okta.com", "default"))
+func RegisterOAuthEndpoint(name string, endpoint OAuthEndpoint) error {
+	if name == "" {
+		return fmt.Errorf("oauth endpoint name is required")
+	}
+	if err := endpoint.Validate(); err != nil {
+		return fmt.Errorf("oauth endpoint %q: %w", name, err)
+	}
+
+	oauthEndpointsMu.Lock()
+	defer oauthEndpointsMu.Unlock()
+	oauthEndpoints[name] = endpoint
+	return nil
+}
+
+// LookupOAuthEndpoint returns the endpoint registered under name
+func LookupOAuthEndpoint(name string) (OAuthEndpoint, bool) {
+	oauthEndpointsMu.RLock()
+	defer oauthEndpointsMu.RUnlock()
+	e, ok := oauthEndpoints[name]
+	return e, ok
+}
+
+// OAuthEndpointNames returns the registered endpoint names, sorted
+func OAuthEndpointNames() []string {
+	oauthEndpointsMu.RLock()
+	defer oauthEndpointsMu.RUnlock()
+
+	names := make([]string, 0, len(oauthEndpoints))
+	for name := range oauthEndpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ============================================================
+// Enterprise identity provider endpoints
+// ============================================================
+
+// OktaEndpoint returns the endpoint of an Okta authorization server
+// authServer is usually "default"; empty uses the org authorization server.
+func OktaEndpoint(domain, authServer string) OAuthEndpoint {
+	base := "https://" + strings.TrimSuffix(domain, "/") + "/oauth2"
+	if authServer != "" {
+		base += "/" + authServer
+	}
+	return OAuthEndpoint{
+		AuthURL:          base + "/v1/authorize",
+		TokenURL:         base + "/v1/token",
+		DeviceAuthURL:    base + "/v1/device/authorize",
+		IntrospectionURL: base + "/v1/introspect",
+		RevocationURL:    base + "/v1/revoke",
+		Scopes:           []string{"openid", "profile", "email"},
+	}
+}
+
+// KeycloakEndpoint returns the endpoint of a Keycloak realm
+func KeycloakEndpoint(baseURL, realm string) OAuthEndpoint {
+	base := strings.TrimSuffix(baseURL, "/") + "/realms/" + realm + "/protocol/openid-connect"
+	return OAuthEndpoint{
+		AuthURL:          base + "/auth",
+		TokenURL:         base + "/token",
+		DeviceAuthURL:    base + "/auth/device",
+		IntrospectionURL: base + "/token/introspect",
+		RevocationURL:    base + "/revoke",
+		Scopes:           []string{"openid", "profile", "email"},
+	}
+}
+
+// AzureADEndpoint returns the endpoint of a specific Azure AD tenant
+func AzureADEndpoint(tenant string) OAuthEndpoint {
+	return endpointFrom(microsoft.AzureADEndpoint(tenant), "https://graph.microsoft.com/User.Read")
+}
+
+// ============================================================
+// Provider factory
+// ============================================================
+
+// ProviderFactory creates OAuth2 providers for registered endpoints
 type ProviderFactory struct {
 	tokenStore TokenStore
 }
@@ -23,84 +190,43 @@ func NewProviderFactory(tokenStore TokenStore) *ProviderFactory {
 	}
 }
 
-// Create creates an OAuth2 provider for a service
+// Create creates an OAuth2 provider for a registered endpoint
+// Nil scopes select the endpoint's default scopes.
 func (f *ProviderFactory) Create(providerName, clientID, clientSecret, redirectURL string, scopes []string) (*OAuth2Provider, error) {
-	var endpoint oauth2.Endpoint
-	var defaultScopes []string
-
-	switch providerName {
-	case "github":
-		endpoint = github.Endpoint
-		if scopes == nil {
-			defaultScopes = []string{"repo", "user"}
-		}
-
-	case "google":
-		endpoint = google.Endpoint
-		if scopes == nil {
-			defaultScopes = []string{
-				"https://www.googleapis.com/auth/userinfo.email",
-				"https://www.googleapis.com/auth/userinfo.profile",
-			}
-		}
-
-	case "facebook":
-		endpoint = facebook.Endpoint
-		if scopes == nil {
-			defaultScopes = []string{"public_profile", "email"}
-		}
-
-	case "microsoft":
-		endpoint = microsoft.AzureADEndpoint("")
-		if scopes == nil {
-			defaultScopes = []string{
-				"https://graph.microsoft.com/User.Read",
-			}
-		}
-
-	case "slack":
-		endpoint = slack.Endpoint
-		if scopes == nil {
-			defaultScopes = []string{"channels:read", "chat:write"}
-		}
-
-	default:
-		return nil, fmt.Errorf("unsupported OAuth provider: %s", providerName)
+	endpoint, ok := LookupOAuthEndpoint(providerName)
+	if !ok {
+		return nil, fmt.Errorf("unsupported OAuth provider: %s (registered: %s)",
+			providerName, strings.Join(OAuthEndpointNames(), ", "))
 	}
+	return f.CreateWithEndpoint(providerName, endpoint, clientID, clientSecret, redirectURL, scopes), nil
+}
 
+// CreateWithEndpoint creates an OAuth2 provider for an explicit endpoint
+func (f *ProviderFactory) CreateWithEndpoint(name string, endpoint OAuthEndpoint, clientID, clientSecret, redirectURL string, scopes []string) *OAuth2Provider {
 	if scopes == nil {
-		scopes = defaultScopes
+		scopes = endpoint.Scopes
 	}
 
 	config := OAuth2Config{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		RedirectURL:  redirectURL,
-		Scopes:       scopes,
-		AuthURL:      endpoint.AuthURL,
-		TokenURL:     endpoint.TokenURL,
+		ClientID:         clientID,
+		ClientSecret:     clientSecret,
+		RedirectURL:      redirectURL,
+		Scopes:           scopes,
+		AuthURL:          endpoint.AuthURL,
+		TokenURL:         endpoint.TokenURL,
+		DeviceAuthURL:    endpoint.DeviceAuthURL,
+		IntrospectionURL: endpoint.IntrospectionURL,
+		RevocationURL:    endpoint.RevocationURL,
 	}
 
-	return NewOAuth2Provider(providerName, config, f.tokenStore), nil
+	return NewOAuth2Provider(name, config, f.tokenStore)
 }
 
 // GetDefaultScopes returns default scopes for a provider
 func GetDefaultScopes(providerName string) []string {
-	switch providerName {
-	case "github":
-		return []string{"repo", "user"}
-	case "google":
-		return []string{
-			"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile",
-		}
-	case "facebook":
-		return []string{"public_profile", "email"}
-	case "microsoft":
-		return []string{"https://graph.microsoft.com/User.Read"}
-	case "slack":
-		return []string{"channels:read", "chat:write"}
-	default:
+	endpoint, ok := LookupOAuthEndpoint(providerName)
+	if !ok {
 		return nil
 	}
+	return endpoint.Scopes
 }
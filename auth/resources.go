@@ -0,0 +1,190 @@
+// auth/resources.go
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+)
+
+// ResourceDeclaration declares an auth resource in configuration
+//
+// Example (YAML):
+//
+//	auth:
+//	  resources:
+//	    - name: github-api
+//	      provider: default
+//	      base_url: https://api.github.com
+//	      scopes: [repo]
+type ResourceDeclaration struct {
+	// Name is the resource ID passed to GetResource
+	Name string `yaml:"name" json:"name"`
+
+	// Provider is the registered auth provider serving the resource
+	Provider string `yaml:"provider" json:"provider"`
+
+	// Type is the resource type (default: "api")
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+
+	// BaseURL is the API root for HTTP resources
+	BaseURL string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
+
+	// Scopes required when accessing the resource
+	Scopes []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+
+	// Config holds additional provider-specific settings
+	Config map[string]interface{} `yaml:"config,omitempty" json:"config,omitempty"`
+}
+
+// ResourceRegistrar is implemented by providers that accept resource registrations
+// Every provider embedding *BaseProvider implements it.
+type ResourceRegistrar interface {
+	RegisterResource(config ResourceConfig)
+}
+
+// ResourceLister is implemented by providers that expose their registered resources
+type ResourceLister interface {
+	ListResources() []string
+	GetResourceConfig(resourceID string) (ResourceConfig, error)
+}
+
+// Validate checks a declaration without consulting any provider
+func (d ResourceDeclaration) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("resource name is required")
+	}
+	if d.Provider == "" {
+		return fmt.Errorf("resource %q: provider is required", d.Name)
+	}
+	if d.BaseURL != "" {
+		u, err := url.Parse(d.BaseURL)
+		if err != nil {
+			return fmt.Errorf("resource %q: invalid base_url: %w", d.Name, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("resource %q: base_url must be an absolute http(s) URL, got %q", d.Name, d.BaseURL)
+		}
+	}
+	return nil
+}
+
+// ResourceConfig converts the declaration into a provider resource configuration
+// base_url is stored in Config, where HTTP providers read it.
+func (d ResourceDeclaration) ResourceConfig() ResourceConfig {
+	config := make(map[string]interface{}, len(d.Config)+1)
+	for k, v := range d.Config {
+		config[k] = v
+	}
+	if d.BaseURL != "" {
+		config["base_url"] = d.BaseURL
+	}
+
+	typ := d.Type
+	if typ == "" {
+		typ = "api"
+	}
+
+	return ResourceConfig{
+		ID:     d.Name,
+		Type:   typ,
+		Config: config,
+		Scopes: d.Scopes,
+	}
+}
+
+// ValidateResourceDeclarations checks declarations for errors and duplicate names
+func ValidateResourceDeclarations(decls []ResourceDeclaration) error {
+	seen := make(map[string]bool, len(decls))
+	for _, d := range decls {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		key := d.Provider + "/" + d.Name
+		if seen[key] {
+			return fmt.Errorf("resource %q: declared twice for provider %q", d.Name, d.Provider)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// RegisterResources registers declared resources with their providers
+// All declarations are validated before any is registered.
+func (m *Manager) RegisterResources(decls []ResourceDeclaration) error {
+	if err := ValidateResourceDeclarations(decls); err != nil {
+		return err
+	}
+
+	registrars := make([]ResourceRegistrar, len(decls))
+	for i, d := range decls {
+		provider, err := m.Get(d.Provider)
+		if err != nil {
+			return fmt.Errorf("resource %q: %w", d.Name, err)
+		}
+		registrar, ok := provider.(ResourceRegistrar)
+		if !ok {
+			return fmt.Errorf("resource %q: provider %q does not support resource registration", d.Name, d.Provider)
+		}
+		registrars[i] = registrar
+	}
+
+	for i, d := range decls {
+		registrars[i].RegisterResource(d.ResourceConfig())
+	}
+	return nil
+}
+
+// ExportResources returns the resources registered with every provider
+// The result can be written back to configuration; it is sorted by provider and name.
+func (m *Manager) ExportResources() []ResourceDeclaration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var decls []ResourceDeclaration
+	for name, provider := range m.providers {
+		lister, ok := provider.(ResourceLister)
+		if !ok {
+			continue
+		}
+		for _, id := range lister.ListResources() {
+			config, err := lister.GetResourceConfig(id)
+			if err != nil {
+				continue
+			}
+			decls = append(decls, declarationFromConfig(name, config))
+		}
+	}
+
+	sort.Slice(decls, func(i, j int) bool {
+		if decls[i].Provider != decls[j].Provider {
+			return decls[i].Provider < decls[j].Provider
+		}
+		return decls[i].Name < decls[j].Name
+	})
+	return decls
+}
+
+// declarationFromConfig is the inverse of ResourceDeclaration.ResourceConfig
+func declarationFromConfig(provider string, config ResourceConfig) ResourceDeclaration {
+	d := ResourceDeclaration{
+		Name:     config.ID,
+		Provider: provider,
+		Type:     config.Type,
+		Scopes:   config.Scopes,
+	}
+
+	for k, v := range config.Config {
+		if k == "base_url" {
+			if s, ok := v.(string); ok {
+				d.BaseURL = s
+				continue
+			}
+		}
+		if d.Config == nil {
+			d.Config = make(map[string]interface{})
+		}
+		d.Config[k] = v
+	}
+	return d
+}
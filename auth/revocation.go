@@ -0,0 +1,136 @@
+// auth/revocation.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TokenRevoker is implemented by providers that can revoke their credentials
+type TokenRevoker interface {
+	// RevokeToken invalidates the provider's credentials at the issuer
+	// and forgets them locally
+	RevokeToken(ctx context.Context) error
+}
+
+// Introspect checks the current access token against the introspection endpoint
+func (p *OAuth2Provider) Introspect(ctx context.Context) (*IntrospectionResult, error) {
+	if p.introspector == nil {
+		return nil, NewAuthError(p.Name(), "", "introspect",
+			fmt.Errorf("no introspection_url configured"))
+	}
+	if p.token == nil {
+		return nil, NewAuthError(p.Name(), "", "introspect", ErrInvalidCredentials)
+	}
+
+	result, err := p.introspector.Introspect(ctx, p.token.AccessToken)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), "", "introspect", err)
+	}
+	return result, nil
+}
+
+// RevokeToken revokes the current token (RFC 7009) and deletes it from the token store
+// The refresh token is revoked first, which servers also apply to its access tokens.
+func (p *OAuth2Provider) RevokeToken(ctx context.Context) error {
+	if p.revocationURL == "" {
+		return NewAuthError(p.Name(), "", "revoke", ErrRevocationUnsupported)
+	}
+
+	if p.token == nil && p.tokenStore != nil {
+		if token, err := p.tokenStore.Load(ctx, p.Name()); err == nil {
+			p.token = token
+		}
+	}
+	if p.token == nil {
+		return nil
+	}
+
+	if p.token.RefreshToken != "" {
+		if err := p.revoke(ctx, p.token.RefreshToken, "refresh_token"); err != nil {
+			return NewAuthError(p.Name(), "", "revoke", err)
+		}
+	}
+	if err := p.revoke(ctx, p.token.AccessToken, "access_token"); err != nil {
+		return NewAuthError(p.Name(), "", "revoke", err)
+	}
+
+	p.token = nil
+	if p.tokenStore != nil {
+		if err := p.tokenStore.Delete(ctx, p.Name()); err != nil {
+			return NewAuthError(p.Name(), "", "revoke", err)
+		}
+	}
+
+	return nil
+}
+
+// revoke posts a single token to the revocation endpoint
+func (p *OAuth2Provider) revoke(ctx context.Context, token, hint string) error {
+	form := url.Values{}
+	form.Set("token", token)
+	form.Set("token_type_hint", hint)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.revocationURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("create revocation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p.clientID != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("revocation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	// Per RFC 7009, unknown or already invalid tokens also yield 200
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revocation endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RevokeToken revokes the credentials of a registered provider
+func (m *Manager) RevokeToken(ctx context.Context, providerName string) error {
+	provider, err := m.Get(providerName)
+	if err != nil {
+		return err
+	}
+
+	revoker, ok := provider.(TokenRevoker)
+	if !ok {
+		return NewAuthError(providerName, "", "revoke", ErrRevocationUnsupported)
+	}
+	return revoker.RevokeToken(ctx)
+}
+
+// RevokeAll revokes the credentials of every provider that supports it
+func (m *Manager) RevokeAll(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var errs []error
+	for name, provider := range m.providers {
+		revoker, ok := provider.(TokenRevoker)
+		if !ok {
+			continue
+		}
+		if err := revoker.RevokeToken(ctx); err != nil && !errors.Is(err, ErrRevocationUnsupported) {
+			errs = append(errs, fmt.Errorf("provider %q: %w", name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("errors revoking tokens: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+// auth/scoped.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrScopingUnsupported indicates a provider cannot issue narrowed credentials
+var ErrScopingUnsupported = errors.New("scoped credentials not supported")
+
+// ScopedResourceProvider is implemented by providers that can issue
+// credentials restricted to a subset of their scopes
+//
+// Tools use it to limit the blast radius of a single call: a tool that only
+// reads repositories asks for a read-only token even when the server holds
+// a broader grant.
+type ScopedResourceProvider interface {
+	// GetScopedResource returns a resource authenticated with at most scopes
+	GetScopedResource(ctx context.Context, resourceID string, scopes []string) (Resource, error)
+}
+
+// GetScopedResource gets a down-scoped resource from a specific provider
+// Fails with ErrScopingUnsupported rather than handing out full credentials.
+func (m *Manager) GetScopedResource(ctx context.Context, providerName, resourceID string, scopes []string) (Resource, error) {
+	provider, err := m.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+	return GetScopedResource(ctx, provider, resourceID, scopes)
+}
+
+// GetScopedResource gets a down-scoped resource from provider
+func GetScopedResource(ctx context.Context, provider AuthProvider, resourceID string, scopes []string) (Resource, error) {
+	scoped, ok := provider.(ScopedResourceProvider)
+	if !ok {
+		return nil, NewAuthError(provider.Name(), resourceID, "get_scoped_resource", ErrScopingUnsupported)
+	}
+	return scoped.GetScopedResource(ctx, resourceID, scopes)
+}
+
+// ============================================================
+// OAuth2 down-scoping
+// ============================================================
+
+// GetScopedResource returns an HTTP client whose token carries only scopes
+//
+// The token is obtained with a refresh_token grant requesting the narrower
+// scope (RFC 6749 section 6), which Google and most OIDC servers honour.
+// Scoped tokens are cached until they expire and are never refreshed into
+// broader ones. The requested scopes must be a subset of the provider's.
+func (p *OAuth2Provider) GetScopedResource(ctx context.Context, resourceID string, scopes []string) (Resource, error) {
+	config, err := p.GetResourceConfig(resourceID)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_scoped_resource", err)
+	}
+
+	baseURL, ok := config.Config["base_url"].(string)
+	if !ok {
+		return nil, NewAuthError(p.Name(), resourceID, "get_scoped_resource",
+			fmt.Errorf("missing base_url in resource config"))
+	}
+
+	if missing := missingScopes(p.config.Scopes, scopes); len(missing) > 0 {
+		return nil, NewAuthError(p.Name(), resourceID, "get_scoped_resource",
+			fmt.Errorf("%w: scopes not granted to provider: %s", ErrForbidden, strings.Join(missing, ", ")))
+	}
+
+	token, err := p.scopedToken(ctx, scopes)
+	if err != nil {
+		return nil, NewAuthError(p.Name(), resourceID, "get_scoped_resource", err)
+	}
+
+	// No refresh token: the client must not be able to widen its grant
+	source := oauth2.StaticTokenSource(&oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Expiry:      token.ExpiresAt,
+	})
+
+	return &OAuth2Resource{
+		client:     oauth2.NewClient(ctx, source),
+		baseURL:    baseURL,
+		resourceID: resourceID,
+	}, nil
+}
+
+// scopedToken returns a cached or freshly issued token for scopes
+func (p *OAuth2Provider) scopedToken(ctx context.Context, scopes []string) (*OAuth2Token, error) {
+	key := scopeKey(scopes)
+
+	p.scopedMu.Lock()
+	defer p.scopedMu.Unlock()
+
+	// Reuse tokens with at least a minute left
+	if token, ok := p.scoped[key]; ok && time.Until(token.ExpiresAt) > time.Minute {
+		return token, nil
+	}
+
+	if err := p.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+	if p.token.RefreshToken == "" {
+		return nil, fmt.Errorf("%w: no refresh token to request scoped tokens with", ErrScopingUnsupported)
+	}
+
+	token, err := p.requestScopedToken(ctx, strings.Join(scopes, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	if p.scoped == nil {
+		p.scoped = make(map[string]*OAuth2Token)
+	}
+	p.scoped[key] = token
+	return token, nil
+}
+
+// requestScopedToken performs a refresh_token grant for a narrower scope
+func (p *OAuth2Provider) requestScopedToken(ctx context.Context, scope string) (*OAuth2Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", p.token.RefreshToken)
+	form.Set("scope", scope)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.Endpoint.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Scope       string `json:"scope"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode token response: %w", err)
+	}
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	// A server that ignored the request must not hand out a broader token
+	if body.Scope != "" {
+		if extra := missingScopes(strings.Fields(scope), strings.Fields(body.Scope)); len(extra) > 0 {
+			return nil, fmt.Errorf("%w: server granted unrequested scopes: %s",
+				ErrScopingUnsupported, strings.Join(extra, ", "))
+		}
+	}
+
+	token := &OAuth2Token{
+		AccessToken: body.AccessToken,
+		TokenType:   body.TokenType,
+	}
+	if body.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// missingScopes returns the scopes in requested that granted lacks
+func missingScopes(granted, requested []string) []string {
+	have := make(map[string]bool, len(granted))
+	for _, s := range granted {
+		have[s] = true
+	}
+
+	var missing []string
+	for _, s := range requested {
+		if !have[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// scopeKey returns an order-independent key for a scope set
+func scopeKey(scopes []string) string {
+	sorted := append([]string(nil), scopes...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, " ")
+}
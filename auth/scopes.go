@@ -0,0 +1,45 @@
+// auth/scopes.go
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeError reports scopes a principal lacks
+type ScopeError struct {
+	Principal string
+	Missing   []string
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("principal %q lacks required scopes: %s", e.Principal, strings.Join(e.Missing, ", "))
+}
+
+// Unwrap allows errors.Is(err, ErrForbidden)
+func (e *ScopeError) Unwrap() error {
+	return ErrForbidden
+}
+
+// RequireScopes checks that principal holds every scope in required
+// Returns ErrUnauthenticated when there is no principal and a *ScopeError
+// (which unwraps to ErrForbidden) listing the missing scopes otherwise.
+func RequireScopes(principal *Principal, required ...string) error {
+	if len(required) == 0 {
+		return nil
+	}
+	if principal == nil {
+		return fmt.Errorf("%w: scopes %s required", ErrUnauthenticated, strings.Join(required, ", "))
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !principal.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return &ScopeError{Principal: principal.ID, Missing: missing}
+	}
+	return nil
+}
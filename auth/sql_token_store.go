@@ -0,0 +1,239 @@
+// auth/sql_token_store.go
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTokenTable is the table SQL token stores use
+const DefaultTokenTable = "oauth_tokens"
+
+// validTokenTable restricts table names, which can't be bound as parameters
+var validTokenTable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLTokenStore stores encrypted tokens in a SQL database
+//
+// The database/sql driver is not linked in; import one and name it in
+// the config:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"      // driver: pgx
+//	import _ "github.com/go-sql-driver/mysql"      // driver: mysql
+//	import _ "github.com/mattn/go-sqlite3"         // driver: sqlite3
+//
+// The table is created if it doesn't exist.
+type SQLTokenStore struct {
+	db     *sql.DB
+	ownsDB bool
+	table  string
+
+	// postgres wants $1 placeholders, everything else ?
+	dollarParams bool
+
+	closeOnce sync.Once
+
+	keyMu         sync.RWMutex
+	encryptionKey string
+	keys          tokenKeys
+}
+
+// NewSQLTokenStore opens dsn with driver and creates the token table
+func NewSQLTokenStore(ctx context.Context, driver, dsn, table, encryptionKey string) (*SQLTokenStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql token store: %w", err)
+	}
+	s, err := NewSQLTokenStoreWithDB(ctx, db, driver, table, encryptionKey)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	s.ownsDB = true
+	return s, nil
+}
+
+// NewSQLTokenStoreWithDB creates a store on an open database
+// driver picks the placeholder style; the store does not close db.
+func NewSQLTokenStoreWithDB(ctx context.Context, db *sql.DB, driver, table, encryptionKey string) (*SQLTokenStore, error) {
+	if table == "" {
+		table = DefaultTokenTable
+	}
+	if !validTokenTable.MatchString(table) {
+		return nil, fmt.Errorf("sql token store: invalid table name %q", table)
+	}
+
+	s := &SQLTokenStore{
+		db:            db,
+		table:         table,
+		encryptionKey: encryptionKey,
+		dollarParams:  driver == "postgres" || driver == "pgx",
+	}
+
+	query := `CREATE TABLE IF NOT EXISTS ` + table + ` (
+		provider VARCHAR(255) NOT NULL PRIMARY KEY,
+		token TEXT NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	)`
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return nil, fmt.Errorf("sql token store: create table: %w", err)
+	}
+	return s, nil
+}
+
+// query rewrites ? placeholders for the driver
+func (s *SQLTokenStore) query(q string) string {
+	if !s.dollarParams {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Save saves a token (encrypted)
+// Delete and insert run in one transaction: upsert syntax differs
+// between databases.
+func (s *SQLTokenStore) Save(ctx context.Context, providerName string, token *OAuth2Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to marshal token: %w", err)
+	}
+	// Held until written, so RotateKey can't miss the token
+	s.keyMu.RLock()
+	defer s.keyMu.RUnlock()
+
+	encrypted, err := s.keys.encrypt(s.encryptionKey, data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt token: %w", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM `+s.table+` WHERE provider = ?`), providerName); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, s.query(`INSERT INTO `+s.table+` (provider, token, updated_at) VALUES (?, ?, ?)`),
+		providerName, string(encrypted), time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// Load loads a token (decrypted)
+func (s *SQLTokenStore) Load(ctx context.Context, providerName string) (*OAuth2Token, error) {
+	s.keyMu.RLock()
+	defer s.keyMu.RUnlock()
+
+	var encrypted string
+	err := s.db.QueryRowContext(ctx, s.query(`SELECT token FROM `+s.table+` WHERE provider = ?`), providerName).Scan(&encrypted)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	data, err := s.keys.decrypt(s.encryptionKey, []byte(encrypted))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token: %w", err)
+	}
+
+	var token OAuth2Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	return &token, nil
+}
+
+// Delete deletes a token
+func (s *SQLTokenStore) Delete(ctx context.Context, providerName string) error {
+	if _, err := s.db.ExecContext(ctx, s.query(`DELETE FROM `+s.table+` WHERE provider = ?`), providerName); err != nil {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	return nil
+}
+
+// RotateKey re-encrypts every token in the table with newKey, in one
+// transaction
+func (s *SQLTokenStore) RotateKey(ctx context.Context, oldKey, newKey string) error {
+	if newKey == "" {
+		return fmt.Errorf("new encryption key is empty")
+	}
+
+	s.keyMu.Lock()
+	defer s.keyMu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to rotate key: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT provider, token FROM `+s.table)
+	if err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+	rotated := make(map[string]string)
+	for rows.Next() {
+		var provider, encrypted string
+		if err := rows.Scan(&provider, &encrypted); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to list tokens: %w", err)
+		}
+		data, err := s.keys.reencrypt(oldKey, newKey, []byte(encrypted))
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to re-encrypt token %q: %w", provider, err)
+		}
+		rotated[provider] = string(data)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for provider, encrypted := range rotated {
+		if _, err := tx.ExecContext(ctx, s.query(`UPDATE `+s.table+` SET token = ?, updated_at = ? WHERE provider = ?`),
+			encrypted, now, provider); err != nil {
+			return fmt.Errorf("failed to write token %q: %w", provider, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to rotate key: %w", err)
+	}
+
+	s.encryptionKey = newKey
+	return nil
+}
+
+// Close closes the database if the store opened it
+func (s *SQLTokenStore) Close() error {
+	var err error
+	if s.ownsDB {
+		s.closeOnce.Do(func() { err = s.db.Close() })
+	}
+	return err
+}
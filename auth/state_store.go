@@ -0,0 +1,99 @@
+// auth/state_store.go
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrInvalidState indicates an OAuth2 callback carried an unknown, reused or expired state
+var ErrInvalidState = errors.New("invalid or expired oauth state")
+
+// DefaultStateTTL bounds how long an authorization request may stay pending
+const DefaultStateTTL = 10 * time.Minute
+
+// AuthRequest is a pending authorization code request
+type AuthRequest struct {
+	// Provider is the name of the provider that started the flow
+	Provider string `json:"provider"`
+
+	// CodeVerifier is the PKCE verifier sent with the token exchange
+	CodeVerifier string `json:"code_verifier,omitempty"`
+
+	// ExpiresAt is when the request stops being accepted
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// StateStore tracks pending authorization requests by state parameter
+// Implementations must make Consume one-shot: a state can be used once.
+// Use a shared store (e.g. Redis) when callbacks may reach another replica.
+type StateStore interface {
+	// Save records a pending request under state
+	Save(ctx context.Context, state string, req AuthRequest) error
+
+	// Consume removes and returns the request for state
+	// Returns ErrInvalidState if the state is unknown or expired.
+	Consume(ctx context.Context, state string) (AuthRequest, error)
+}
+
+// MemoryStateStore keeps pending requests in memory
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	pending map[string]AuthRequest
+	now     func() time.Time
+}
+
+// NewMemoryStateStore creates an in-memory state store
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		pending: make(map[string]AuthRequest),
+		now:     time.Now,
+	}
+}
+
+// Save implements StateStore
+func (s *MemoryStateStore) Save(ctx context.Context, state string, req AuthRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop abandoned requests so the map cannot grow without bound
+	now := s.now()
+	for k, r := range s.pending {
+		if now.After(r.ExpiresAt) {
+			delete(s.pending, k)
+		}
+	}
+
+	s.pending[state] = req
+	return nil
+}
+
+// Consume implements StateStore
+func (s *MemoryStateStore) Consume(ctx context.Context, state string) (AuthRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, ok := s.pending[state]
+	if !ok {
+		return AuthRequest{}, ErrInvalidState
+	}
+	delete(s.pending, state)
+
+	if s.now().After(req.ExpiresAt) {
+		return AuthRequest{}, ErrInvalidState
+	}
+	return req, nil
+}
+
+// GenerateState returns a random, URL-safe state parameter
+func GenerateState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,190 @@
+// auth/token_crypto.go
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Encrypted token format
+//
+// Tokens are stored as "mcp-token-v2:" followed by the base64 of
+// salt | nonce | AES-256-GCM ciphertext, the key derived from the
+// passphrase and salt with argon2id. A store derives a key once per salt
+// (see tokenKeys) and seals the tokens it saves under one salt, each with
+// its own random nonce. Values without the header are the
+// legacy format (key = passphrase padded or cut to 32 bytes), still read
+// so existing stores keep working; they are rewritten on their next Save
+// or RotateKey.
+const tokenFormatV2 = "mcp-token-v2:"
+
+// argon2id parameters (RFC 9106, second recommended option)
+const (
+	tokenKDFTime    = 1
+	tokenKDFMemory  = 64 * 1024 // KiB
+	tokenKDFThreads = 4
+	tokenSaltSize   = 16
+)
+
+// maxTokenKeys bounds a store's cached keys, one per passphrase and salt
+// seen; the cache starts over when it is full
+const maxTokenKeys = 64
+
+// deriveTokenKey derives the AES key for salt from the passphrase
+func deriveTokenKey(encryptionKey string, salt []byte) []byte {
+	return argon2.IDKey([]byte(encryptionKey), salt, tokenKDFTime, tokenKDFMemory, tokenKDFThreads, 32)
+}
+
+// tokenKeys caches the argon2id keys of a store, so that only the first
+// Save under a passphrase and the first Load of each salt pay for the
+// derivation (tens of milliseconds and 64 MiB)
+// Shared by the stores that keep tokens outside an OS keyring; the zero
+// value is ready to use.
+type tokenKeys struct {
+	mu    sync.Mutex
+	salts map[string][]byte     // passphrase → salt of the tokens it saves
+	keys  map[tokenKeyID][]byte // derived keys
+}
+
+type tokenKeyID struct {
+	encryptionKey string
+	salt          string
+}
+
+// key returns the key of encryptionKey and salt, deriving it on first use
+// Derivations run one at a time, so concurrent Loads don't each hold
+// 64 MiB.
+func (k *tokenKeys) key(encryptionKey string, salt []byte) []byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	id := tokenKeyID{encryptionKey: encryptionKey, salt: string(salt)}
+	if key, ok := k.keys[id]; ok {
+		return key
+	}
+	if k.keys == nil || len(k.keys) >= maxTokenKeys {
+		k.keys = make(map[tokenKeyID][]byte)
+	}
+	key := deriveTokenKey(encryptionKey, salt)
+	k.keys[id] = key
+	return key
+}
+
+// salt returns the salt of the tokens encryptionKey saves, picking one
+// on first use
+func (k *tokenKeys) salt(encryptionKey string) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if salt, ok := k.salts[encryptionKey]; ok {
+		return salt, nil
+	}
+	salt := make([]byte, tokenSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	if k.salts == nil || len(k.salts) >= maxTokenKeys {
+		k.salts = make(map[string][]byte)
+	}
+	k.salts[encryptionKey] = salt
+	return salt, nil
+}
+
+// encrypt encrypts data in the current format
+func (k *tokenKeys) encrypt(encryptionKey string, data []byte) ([]byte, error) {
+	if encryptionKey == "" {
+		return nil, fmt.Errorf("encryption key is empty")
+	}
+
+	salt, err := k.salt(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newTokenGCM(k.key(encryptionKey, salt))
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// The header is authenticated too, so it can't be swapped
+	out := append(append([]byte(nil), salt...), nonce...)
+	out = gcm.Seal(out, nonce, data, []byte(tokenFormatV2))
+	return []byte(tokenFormatV2 + base64.StdEncoding.EncodeToString(out)), nil
+}
+
+// decrypt decrypts data in the current or the legacy format
+func (k *tokenKeys) decrypt(encryptionKey string, data []byte) ([]byte, error) {
+	encoded, current := strings.CutPrefix(string(data), tokenFormatV2)
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if !current {
+		return decryptLegacyToken(encryptionKey, raw)
+	}
+
+	if len(raw) < tokenSaltSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	salt, raw := raw[:tokenSaltSize], raw[tokenSaltSize:]
+	gcm, err := newTokenGCM(k.key(encryptionKey, salt))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := raw[:gcm.NonceSize()], raw[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, []byte(tokenFormatV2))
+}
+
+// decryptLegacyToken decrypts the headerless format of earlier releases
+func decryptLegacyToken(encryptionKey string, ciphertext []byte) ([]byte, error) {
+	gcm, err := newTokenGCM(legacyTokenKey(encryptionKey))
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// legacyTokenKey pads or cuts the passphrase to 32 bytes, as earlier
+// releases did
+func legacyTokenKey(encryptionKey string) []byte {
+	key := make([]byte, 32)
+	copy(key, encryptionKey)
+	return key
+}
+
+// newTokenGCM creates the AES-GCM cipher for key
+func newTokenGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// reencrypt decrypts data with oldKey and encrypts it with newKey
+func (k *tokenKeys) reencrypt(oldKey, newKey string, data []byte) ([]byte, error) {
+	plaintext, err := k.decrypt(oldKey, data)
+	if err != nil {
+		return nil, err
+	}
+	return k.encrypt(newKey, plaintext)
+}
@@ -3,15 +3,14 @@ package auth
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -23,10 +22,20 @@ type TokenStore interface {
 	Close() error
 }
 
+// KeyRotator is implemented by token stores that encrypt tokens with a
+// passphrase
+type KeyRotator interface {
+	// RotateKey re-encrypts every stored token with newKey, and uses
+	// newKey from then on. Tokens are all decrypted with oldKey before
+	// any is rewritten, so a wrong oldKey changes nothing.
+	RotateKey(ctx context.Context, oldKey, newKey string) error
+}
+
 // FileTokenStore stores tokens in encrypted files
 type FileTokenStore struct {
 	baseDir       string
 	encryptionKey string
+	keys          tokenKeys
 	mu            sync.RWMutex
 }
 
@@ -55,7 +64,7 @@ func (s *FileTokenStore) Save(ctx context.Context, providerName string, token *O
 	}
 
 	// Encrypt data
-	encrypted, err := s.encrypt(data)
+	encrypted, err := s.keys.encrypt(s.encryptionKey, data)
 	if err != nil {
 		return fmt.Errorf("failed to encrypt token: %w", err)
 	}
@@ -85,7 +94,7 @@ func (s *FileTokenStore) Load(ctx context.Context, providerName string) (*OAuth2
 	}
 
 	// Decrypt data
-	data, err := s.decrypt(encrypted)
+	data, err := s.keys.decrypt(s.encryptionKey, encrypted)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt token: %w", err)
 	}
@@ -112,83 +121,88 @@ func (s *FileTokenStore) Delete(ctx context.Context, providerName string) error
 	return nil
 }
 
-// Close closes the token store
-func (s *FileTokenStore) Close() error {
-	return nil
+// List returns the names of the providers with a stored token
+func (s *FileTokenStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.list()
 }
 
-// encrypt encrypts data using AES-GCM
-func (s *FileTokenStore) encrypt(data []byte) ([]byte, error) {
-	// Derive key from encryption key
-	key := []byte(s.encryptionKey)
-	if len(key) < 32 {
-		// Pad key to 32 bytes
-		padded := make([]byte, 32)
-		copy(padded, key)
-		key = padded
-	} else if len(key) > 32 {
-		key = key[:32]
-	}
-
-	block, err := aes.NewCipher(key)
+// list lists the token files; caller holds mu
+func (s *FileTokenStore) list() ([]string, error) {
+	entries, err := os.ReadDir(s.baseDir)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to list token directory: %w", err)
 	}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".token"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
 	}
+	return names, nil
+}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return nil, err
+// RotateKey re-encrypts every token file with newKey
+// Files in the legacy format are upgraded on the way. Each file is
+// replaced atomically.
+func (s *FileTokenStore) RotateKey(ctx context.Context, oldKey, newKey string) error {
+	if newKey == "" {
+		return fmt.Errorf("new encryption key is empty")
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
-	return []byte(base64.StdEncoding.EncodeToString(ciphertext)), nil
-}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-// decrypt decrypts data using AES-GCM
-func (s *FileTokenStore) decrypt(data []byte) ([]byte, error) {
-	// Decode base64
-	ciphertext, err := base64.StdEncoding.DecodeString(string(data))
+	names, err := s.list()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Derive key
-	key := []byte(s.encryptionKey)
-	if len(key) < 32 {
-		padded := make([]byte, 32)
-		copy(padded, key)
-		key = padded
-	} else if len(key) > 32 {
-		key = key[:32]
+	rotated := make(map[string][]byte, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(s.baseDir, name+".token"))
+		if err != nil {
+			return fmt.Errorf("failed to read token file %q: %w", name, err)
+		}
+		if rotated[name], err = s.keys.reencrypt(oldKey, newKey, data); err != nil {
+			return fmt.Errorf("failed to re-encrypt token %q: %w", name, err)
+		}
 	}
 
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
+	for name, data := range rotated {
+		if err := writeFileAtomic(filepath.Join(s.baseDir, name+".token"), data); err != nil {
+			return fmt.Errorf("failed to write token file %q: %w", name, err)
+		}
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	s.encryptionKey = newKey
+	return nil
+}
+
+// writeFileAtomic replaces filename with data, readable by the owner only
+func writeFileAtomic(filename string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".token-*")
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer os.Remove(tmp.Name())
 
-	nonceSize := gcm.NonceSize()
-	if len(ciphertext) < nonceSize {
-		return nil, fmt.Errorf("ciphertext too short")
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
 	}
-
-	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return nil, err
+	if err := tmp.Close(); err != nil {
+		return err
 	}
+	// CreateTemp already uses 0600
+	return os.Rename(tmp.Name(), filename)
+}
 
-	return plaintext, nil
+// Close closes the token store
+func (s *FileTokenStore) Close() error {
+	return nil
 }
 
 // GenerateKey generates a random encryption key
@@ -240,3 +254,137 @@ func (s *MemoryTokenStore) Delete(ctx context.Context, providerName string) erro
 func (s *MemoryTokenStore) Close() error {
 	return nil
 }
+
+// ============================================================
+// Token store selection
+// ============================================================
+
+// Token store types
+const (
+	TokenStoreFile    = "file"
+	TokenStoreMemory  = "memory"
+	TokenStoreKeyring = "keyring"
+	TokenStoreRedis   = "redis"
+	TokenStoreSQL     = "sql"
+)
+
+// TokenStoreConfig selects where OAuth2 tokens are kept
+type TokenStoreConfig struct {
+	// Type is file (default), memory, keyring, redis or sql
+	Type string `yaml:"type" json:"type"`
+
+	// EncryptionKey encrypts tokens in file, redis and sql stores
+	EncryptionKey string `yaml:"encryption_key" json:"-"`
+
+	// Directory holds the file store's tokens
+	Directory string `yaml:"directory" json:"directory"`
+
+	// Service names the keyring entries (default: DefaultKeyringService)
+	Service string `yaml:"service" json:"service"`
+
+	// URL and Prefix locate the redis store
+	URL    string `yaml:"url" json:"-"`
+	Prefix string `yaml:"prefix" json:"prefix"`
+
+	// Driver, DSN and Table locate the sql store; the driver must be
+	// linked in (see SQLTokenStore)
+	Driver string `yaml:"driver" json:"driver"`
+	DSN    string `yaml:"dsn" json:"-"`
+	Table  string `yaml:"table" json:"table"`
+}
+
+// Encrypted reports whether the store needs EncryptionKey
+func (c TokenStoreConfig) Encrypted() bool {
+	switch c.Type {
+	case "", TokenStoreFile, TokenStoreRedis, TokenStoreSQL:
+		return true
+	}
+	return false
+}
+
+// Validate validates the token store configuration
+func (c TokenStoreConfig) Validate() error {
+	switch c.Type {
+	case "", TokenStoreFile, TokenStoreMemory, TokenStoreKeyring:
+	case TokenStoreRedis:
+		if c.URL == "" {
+			return fmt.Errorf("redis token store: url is required")
+		}
+	case TokenStoreSQL:
+		if c.Driver == "" || c.DSN == "" {
+			return fmt.Errorf("sql token store: driver and dsn are required")
+		}
+		if c.Table != "" && !validTokenTable.MatchString(c.Table) {
+			return fmt.Errorf("sql token store: invalid table name %q", c.Table)
+		}
+	default:
+		return fmt.Errorf("unknown token store type %q (want file, memory, keyring, redis or sql)", c.Type)
+	}
+	return nil
+}
+
+// NewTokenStore creates the token store config selects
+func NewTokenStore(ctx context.Context, config TokenStoreConfig) (TokenStore, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Encrypted() && config.EncryptionKey == "" {
+		return nil, fmt.Errorf("%s token store: encryption key is required", config.Type)
+	}
+
+	switch config.Type {
+	case TokenStoreMemory:
+		return NewMemoryTokenStore(), nil
+	case TokenStoreKeyring:
+		return NewKeyringTokenStore(config.Service), nil
+	case TokenStoreRedis:
+		return NewRedisTokenStore(ctx, config.URL, config.Prefix, config.EncryptionKey)
+	case TokenStoreSQL:
+		return NewSQLTokenStore(ctx, config.Driver, config.DSN, config.Table, config.EncryptionKey)
+	}
+
+	if config.Directory == "" {
+		return nil, fmt.Errorf("file token store: directory is required")
+	}
+	return NewFileTokenStore(config.Directory, config.EncryptionKey)
+}
+
+// MigrateFileTokens moves the tokens of the file store in dir to store
+// Tokens store already holds are left alone; migrated files are deleted,
+// so the migration runs once. A missing dir migrates nothing.
+func MigrateFileTokens(ctx context.Context, dir, encryptionKey string, store TokenStore) (int, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	files, err := NewFileTokenStore(dir, encryptionKey)
+	if err != nil {
+		return 0, err
+	}
+	names, err := files.List()
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	var errs []error
+	for _, name := range names {
+		if _, err := store.Load(ctx, name); err == nil {
+			continue
+		}
+		token, err := files.Load(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("token %q: %w", name, err))
+			continue
+		}
+		if err := store.Save(ctx, name, token); err != nil {
+			errs = append(errs, fmt.Errorf("token %q: %w", name, err))
+			continue
+		}
+		if err := files.Delete(ctx, name); err != nil {
+			errs = append(errs, fmt.Errorf("token %q: %w", name, err))
+		}
+		migrated++
+	}
+	return migrated, errors.Join(errs...)
+}
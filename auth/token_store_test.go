@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/zalando/go-keyring"
+)
+
+// testTokenStore saves, loads and deletes a token
+func testTokenStore(t *testing.T, store TokenStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "github"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Load of missing token: expected ErrInvalidCredentials, got %v", err)
+	}
+
+	token := &OAuth2Token{AccessToken: "access", RefreshToken: "refresh", TokenType: "bearer", ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second)}
+	if err := store.Save(ctx, "github", token); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	token.AccessToken = "rotated"
+	if err := store.Save(ctx, "github", token); err != nil {
+		t.Fatalf("Save over existing token failed: %v", err)
+	}
+
+	loaded, err := store.Load(ctx, "github")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.AccessToken != "rotated" || loaded.RefreshToken != "refresh" || !loaded.ExpiresAt.Equal(token.ExpiresAt) {
+		t.Errorf("loaded %+v", loaded)
+	}
+
+	if err := store.Delete(ctx, "github"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load(ctx, "github"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Load after Delete: expected ErrInvalidCredentials, got %v", err)
+	}
+	if err := store.Delete(ctx, "github"); err != nil {
+		t.Errorf("Delete of missing token failed: %v", err)
+	}
+}
+
+func TestFileTokenStore(t *testing.T) {
+	store, err := NewFileTokenStore(t.TempDir(), "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTokenStore(t, store)
+}
+
+func TestKeyringTokenStore(t *testing.T) {
+	keyring.MockInit()
+	testTokenStore(t, NewKeyringTokenStore("test"))
+}
+
+func TestRedisTokenStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	store, err := NewRedisTokenStore(context.Background(), "redis://"+mr.Addr(), "", "key")
+	if err != nil {
+		t.Fatalf("NewRedisTokenStore failed: %v", err)
+	}
+	defer store.Close()
+	testTokenStore(t, store)
+
+	store.Save(context.Background(), "slack", &OAuth2Token{AccessToken: "xoxb-secret"})
+	stored, err := mr.Get(DefaultRedisTokenPrefix + "slack")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stored, "xoxb-secret") {
+		t.Error("token stored in plaintext")
+	}
+
+	// Shared by several providers, the store is closed more than once
+	if err := store.Close(); err != nil {
+		t.Errorf("second Close failed: %v", err)
+	}
+}
+
+func TestSQLTokenStore_Placeholders(t *testing.T) {
+	s := &SQLTokenStore{dollarParams: true}
+	if got := s.query("INSERT INTO t (a, b) VALUES (?, ?)"); got != "INSERT INTO t (a, b) VALUES ($1, $2)" {
+		t.Errorf("query = %q", got)
+	}
+	s.dollarParams = false
+	if got := s.query("DELETE FROM t WHERE a = ?"); got != "DELETE FROM t WHERE a = ?" {
+		t.Errorf("query = %q", got)
+	}
+}
+
+func TestMigrateFileTokens(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	files, err := NewFileTokenStore(dir, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files.Save(ctx, "github", &OAuth2Token{AccessToken: "gh"})
+	files.Save(ctx, "google", &OAuth2Token{AccessToken: "stale"})
+
+	store := NewMemoryTokenStore()
+	store.Save(ctx, "google", &OAuth2Token{AccessToken: "current"})
+
+	n, err := MigrateFileTokens(ctx, dir, "key", store)
+	if err != nil || n != 1 {
+		t.Fatalf("MigrateFileTokens = %d, %v; want 1, nil", n, err)
+	}
+	if token, _ := store.Load(ctx, "github"); token == nil || token.AccessToken != "gh" {
+		t.Errorf("github token not migrated: %+v", token)
+	}
+	if token, _ := store.Load(ctx, "google"); token.AccessToken != "current" {
+		t.Errorf("existing token overwritten: %+v", token)
+	}
+	if names, _ := files.List(); len(names) != 1 || names[0] != "google" {
+		t.Errorf("file store after migration holds %v", names)
+	}
+
+	if n, err := MigrateFileTokens(ctx, dir+"/missing", "key", store); n != 0 || err != nil {
+		t.Errorf("missing directory: %d, %v", n, err)
+	}
+}
+
+func TestTokenStoreConfig_Validate(t *testing.T) {
+	tests := []struct {
+		config  TokenStoreConfig
+		wantErr bool
+	}{
+		{TokenStoreConfig{}, false},
+		{TokenStoreConfig{Type: TokenStoreKeyring}, false},
+		{TokenStoreConfig{Type: TokenStoreRedis}, true},
+		{TokenStoreConfig{Type: TokenStoreSQL, Driver: "pgx"}, true},
+		{TokenStoreConfig{Type: TokenStoreSQL, Driver: "pgx", DSN: "postgres://", Table: "tokens; DROP"}, true},
+		{TokenStoreConfig{Type: "vault"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.config, err, tt.wantErr)
+		}
+	}
+
+	if _, err := NewTokenStore(context.Background(), TokenStoreConfig{Type: TokenStoreFile, Directory: t.TempDir()}); err == nil {
+		t.Error("file store without encryption key accepted")
+	}
+}
+
+func TestTokenCrypto_LegacyFormat(t *testing.T) {
+	// Written the way earlier releases did: passphrase padded to 32 bytes
+	gcm, err := newTokenGCM(legacyTokenKey("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	legacy := []byte(base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(`{"access_token":"old"}`), nil)))
+
+	var keys tokenKeys
+	data, err := keys.decrypt("passphrase", legacy)
+	if err != nil || string(data) != `{"access_token":"old"}` {
+		t.Fatalf("legacy token: %q, %v", data, err)
+	}
+
+	encrypted, err := keys.encrypt("passphrase", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(encrypted), tokenFormatV2) {
+		t.Errorf("new tokens lack the version header: %q", encrypted)
+	}
+	if _, err := keys.decrypt("wrong", encrypted); err == nil {
+		t.Error("decrypted with the wrong key")
+	}
+
+	// Long passphrases no longer collide on their first 32 bytes
+	long := strings.Repeat("k", 32)
+	encrypted, _ = keys.encrypt(long+"a", data)
+	if _, err := keys.decrypt(long+"b", encrypted); err == nil {
+		t.Error("passphrases sharing a 32-byte prefix are interchangeable")
+	}
+}
+
+func TestTokenKeys_DeriveOncePerSalt(t *testing.T) {
+	var keys tokenKeys
+	a, err := keys.encrypt("passphrase", []byte("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := keys.encrypt("passphrase", []byte("a"))
+	if _, err := keys.decrypt("passphrase", a); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.keys) != 1 {
+		t.Errorf("derived %d keys for one passphrase, want 1", len(keys.keys))
+	}
+	if string(a) == string(b) {
+		t.Error("tokens under one salt share a nonce")
+	}
+
+	// Another store (or a restart) derives the key of a salt it reads
+	var other tokenKeys
+	if data, err := other.decrypt("passphrase", b); err != nil || string(data) != "a" {
+		t.Errorf("token of another store: %q, %v", data, err)
+	}
+	if len(other.keys) != 1 {
+		t.Errorf("other store derived %d keys, want 1", len(other.keys))
+	}
+}
+
+func TestFileTokenStore_RotateKey(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileTokenStore(dir, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Save(ctx, "github", &OAuth2Token{AccessToken: "gh"})
+	store.Save(ctx, "google", &OAuth2Token{AccessToken: "g"})
+
+	if err := store.RotateKey(ctx, "wrong", "new"); err == nil {
+		t.Fatal("rotation with the wrong old key succeeded")
+	}
+	if token, err := store.Load(ctx, "github"); err != nil || token.AccessToken != "gh" {
+		t.Fatalf("failed rotation changed the store: %v", err)
+	}
+
+	if err := store.RotateKey(ctx, "old", "new"); err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+	if token, err := store.Load(ctx, "google"); err != nil || token.AccessToken != "g" {
+		t.Errorf("Load after rotation: %+v, %v", token, err)
+	}
+
+	reopened, _ := NewFileTokenStore(dir, "new")
+	if _, err := reopened.Load(ctx, "github"); err != nil {
+		t.Errorf("store reopened with the new key: %v", err)
+	}
+	reopened, _ = NewFileTokenStore(dir, "old")
+	if _, err := reopened.Load(ctx, "github"); err == nil {
+		t.Error("old key still decrypts rotated tokens")
+	}
+}
+
+func TestRedisTokenStore_RotateKey(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	store, err := NewRedisTokenStore(ctx, "redis://"+mr.Addr(), "", "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	store.Save(ctx, "github", &OAuth2Token{AccessToken: "gh"})
+
+	if err := store.RotateKey(ctx, "old", "new"); err != nil {
+		t.Fatalf("RotateKey failed: %v", err)
+	}
+	reopened, _ := NewRedisTokenStore(ctx, "redis://"+mr.Addr(), "", "new")
+	defer reopened.Close()
+	if token, err := reopened.Load(ctx, "github"); err != nil || token.AccessToken != "gh" {
+		t.Errorf("Load with the new key: %+v, %v", token, err)
+	}
+}
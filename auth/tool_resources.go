@@ -0,0 +1,123 @@
+// auth/tool_resources.go
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// DefaultProviderName is the provider a binding without one uses
+const DefaultProviderName = "default"
+
+// ToolResource binds a tool to the auth resource its calls use
+//
+// The resource is resolved before each call and handed to the handler
+// through the context (see ResourceFromContext), so tools doing different
+// things can hold different credentials:
+//
+//	auth:
+//	  tools:
+//	    search_repos: {provider: github, resource: github-readonly}
+//	    create_repo:  {provider: github, resource: github-write}
+type ToolResource struct {
+	// Provider is the registered provider (default: the backend's
+	// primary provider, or the one named "default")
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Resource is the resource ID passed to GetResource
+	Resource string `yaml:"resource" json:"resource"`
+
+	// Scopes narrow the credentials (see ScopedResourceProvider); the
+	// call fails rather than getting broader ones
+	Scopes []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+}
+
+// Validate checks the binding without consulting any provider
+func (t ToolResource) Validate() error {
+	if t.Resource == "" {
+		return fmt.Errorf("resource is required")
+	}
+	return nil
+}
+
+// Get resolves the binding with provider
+func (t ToolResource) Get(ctx context.Context, provider AuthProvider) (Resource, error) {
+	if len(t.Scopes) > 0 {
+		return GetScopedResource(ctx, provider, t.Resource, t.Scopes)
+	}
+	return provider.GetResource(ctx, t.Resource)
+}
+
+// BindTool binds a tool to a resource, replacing the tool's own binding
+func (m *Manager) BindTool(tool string, resource ToolResource) error {
+	if err := resource.Validate(); err != nil {
+		return fmt.Errorf("tool %q: %w", tool, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.tools == nil {
+		m.tools = make(map[string]ToolResource)
+	}
+	m.tools[tool] = resource
+	return nil
+}
+
+// BindTools binds several tools, e.g. from configuration
+func (m *Manager) BindTools(tools map[string]ToolResource) error {
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := m.BindTool(name, tools[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToolResource returns the resource bound to a tool, if any
+func (m *Manager) ToolResource(tool string) (ToolResource, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	resource, ok := m.tools[tool]
+	return resource, ok
+}
+
+// ============================================================
+// Context
+// ============================================================
+
+type toolResourceKey struct{}
+
+// boundResource is a resolved binding
+type boundResource struct {
+	binding  ToolResource
+	resource Resource
+}
+
+// WithResource attaches a tool's resolved resource to its call's context
+func WithResource(ctx context.Context, binding ToolResource, resource Resource) context.Context {
+	return context.WithValue(ctx, toolResourceKey{}, boundResource{binding: binding, resource: resource})
+}
+
+// ResourceFromContext returns the resource bound to the running tool
+//
+// Example usage in a tool:
+//
+//	res, ok := auth.ResourceFromContext(ctx)
+//	client := res.(*auth.OAuth2Resource).Client()
+func ResourceFromContext(ctx context.Context) (Resource, bool) {
+	bound, ok := ctx.Value(toolResourceKey{}).(boundResource)
+	return bound.resource, ok
+}
+
+// ToolResourceFromContext returns the binding the running tool's
+// resource was resolved from
+func ToolResourceFromContext(ctx context.Context) (ToolResource, bool) {
+	bound, ok := ctx.Value(toolResourceKey{}).(boundResource)
+	return bound.binding, ok
+}
@@ -42,6 +42,22 @@ type ServerBackend interface {
 	GetAuthManager() *auth.Manager
 }
 
+// ToolChangeNotifier is implemented by backends whose tools can change
+// while the server runs (BaseBackend and CompositeBackend do)
+type ToolChangeNotifier interface {
+	OnToolsChanged(fn func())
+}
+
+// ResourceWatcher is implemented by backends whose resources clients can
+// subscribe to (resources/subscribe)
+type ResourceWatcher interface {
+	// WatchResource calls updated with the URI of each change to the
+	// resource at uri, or to one below it, until ctx is done
+	// It returns once the watch is set up, with an error when uri cannot
+	// be watched.
+	WatchResource(ctx context.Context, uri string, updated func(uri string)) error
+}
+
 // StreamingEmitter is defined here to avoid circular imports
 // The actual engine.Emitter will implement this
 type StreamingEmitter interface {
@@ -2,10 +2,21 @@ package backend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sort"
 	"sync"
 
 	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/resilience"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // BaseBackend provides common functionality for backends
@@ -21,6 +32,20 @@ type BaseBackend struct {
 	authProvider auth.AuthProvider
 	authManager  *auth.Manager
 	mu           sync.RWMutex // Protects auth fields
+
+	// Circuit breakers: per tool, and the backend-wide fallback
+	toolBreakers map[string]*resilience.Breaker
+	breaker      *resilience.Breaker
+
+	// disabled tools are hidden and cannot be called
+	disabled map[string]bool
+
+	// toolsMu protects tools, handlers and streamingHandlers, which may
+	// change while the server runs
+	toolsMu sync.RWMutex
+
+	// onToolsChanged are called after the tool set changes
+	onToolsChanged []func()
 }
 
 // StreamingHandler is the function signature for streaming tools
@@ -35,6 +60,7 @@ func NewBaseBackend(name string) *BaseBackend {
 		streamingHandlers: make(map[string]StreamingHandler), // NEW
 		resources:         []Resource{},                      //v3
 		prompts:           []Prompt{},                        //v3
+		toolBreakers:      make(map[string]*resilience.Breaker),
 	}
 }
 
@@ -69,41 +95,187 @@ func (b *BaseBackend) Close() error {
 	return nil
 }
 
-// RegisterTool registers a regular tool
+// RegisterTool registers a regular tool, replacing any tool of the same name
 func (b *BaseBackend) RegisterTool(tool ToolDefinition, handler ToolHandler) {
 	tool.Streaming = false
-	b.tools[tool.Name] = tool
-	b.handlers[tool.Name] = handler
+	b.putTool(tool, handler, nil)
 }
 
 // RegisterStreamingTool registers a streaming tool (NEW)
 func (b *BaseBackend) RegisterStreamingTool(tool ToolDefinition, handler StreamingHandler) {
 	tool.Streaming = true
+	b.putTool(tool, nil, handler)
+}
+
+// AddTool registers a regular tool while the server runs
+// It fails if a tool of the same name exists; clients are notified of
+// the change (see OnToolsChanged).
+func (b *BaseBackend) AddTool(tool ToolDefinition, handler ToolHandler) error {
+	if _, exists := b.lookupTool(tool.Name); exists {
+		return fmt.Errorf("tool already registered: %s", tool.Name)
+	}
+	b.RegisterTool(tool, handler)
+	return nil
+}
+
+// AddStreamingTool registers a streaming tool while the server runs
+// It fails if a tool of the same name exists.
+func (b *BaseBackend) AddStreamingTool(tool ToolDefinition, handler StreamingHandler) error {
+	if _, exists := b.lookupTool(tool.Name); exists {
+		return fmt.Errorf("tool already registered: %s", tool.Name)
+	}
+	b.RegisterStreamingTool(tool, handler)
+	return nil
+}
+
+// ReplaceTool swaps the definition and handler of a registered tool
+// Calls already running finish with the old handler.
+func (b *BaseBackend) ReplaceTool(tool ToolDefinition, handler ToolHandler) error {
+	if _, exists := b.lookupTool(tool.Name); !exists {
+		return fmt.Errorf("tool not found: %s", tool.Name)
+	}
+	b.RegisterTool(tool, handler)
+	return nil
+}
+
+// RemoveTool unregisters a tool, reporting whether it existed
+func (b *BaseBackend) RemoveTool(name string) bool {
+	b.toolsMu.Lock()
+	_, exists := b.tools[name]
+	delete(b.tools, name)
+	delete(b.handlers, name)
+	delete(b.streamingHandlers, name)
+	b.toolsMu.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	b.mu.Lock()
+	delete(b.toolBreakers, name)
+	b.mu.Unlock()
+
+	b.toolsChanged()
+	return true
+}
+
+// OnToolsChanged registers fn to be called after tools are registered,
+// replaced, removed, enabled or disabled
+// The server uses it to send notifications/tools/list_changed.
+func (b *BaseBackend) OnToolsChanged(fn func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onToolsChanged = append(b.onToolsChanged, fn)
+}
+
+// putTool stores a tool with its handler
+func (b *BaseBackend) putTool(tool ToolDefinition, handler ToolHandler, streaming StreamingHandler) {
+	b.toolsMu.Lock()
 	b.tools[tool.Name] = tool
-	b.streamingHandlers[tool.Name] = handler
+	delete(b.handlers, tool.Name)
+	delete(b.streamingHandlers, tool.Name)
+	if streaming != nil {
+		b.streamingHandlers[tool.Name] = streaming
+	} else {
+		b.handlers[tool.Name] = handler
+	}
+	b.toolsMu.Unlock()
+
+	b.registerBreaker(tool)
+
+	b.toolsChanged()
+}
+
+// lookupTool returns a registered tool, disabled or not
+func (b *BaseBackend) lookupTool(name string) (ToolDefinition, bool) {
+	b.toolsMu.RLock()
+	defer b.toolsMu.RUnlock()
+	tool, ok := b.tools[name]
+	return tool, ok
+}
+
+// toolsChanged calls the OnToolsChanged listeners
+func (b *BaseBackend) toolsChanged() {
+	b.mu.RLock()
+	listeners := b.onToolsChanged
+	b.mu.RUnlock()
+
+	for _, fn := range listeners {
+		fn()
+	}
 }
 
-// ListTools returns all registered tools
+// ListTools returns all registered tools, except disabled ones
 func (b *BaseBackend) ListTools() []ToolDefinition {
+	b.toolsMu.RLock()
+	defer b.toolsMu.RUnlock()
+
 	tools := make([]ToolDefinition, 0, len(b.tools))
 	for _, tool := range b.tools {
+		if b.isDisabled(tool.Name) {
+			continue
+		}
 		tools = append(tools, tool)
 	}
 	return tools
 }
 
 // GetTool retrieves a tool definition
+// Disabled tools are reported as not found.
 func (b *BaseBackend) GetTool(name string) (ToolDefinition, bool) {
-	tool, ok := b.tools[name]
-	return tool, ok
+	tool, ok := b.lookupTool(name)
+	if !ok || b.isDisabled(name) {
+		return ToolDefinition{}, false
+	}
+	return tool, true
 }
 
-// CallTool executes a regular tool
-func (b *BaseBackend) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+// SetDisabledTools replaces the set of disabled tools
+// Disabled tools stay registered but are hidden from ListTools and cannot
+// be called, so they can be switched back on at runtime.
+func (b *BaseBackend) SetDisabledTools(names []string) {
+	disabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		disabled[name] = true
+	}
+
+	b.mu.Lock()
+	changed := !maps.Equal(b.disabled, disabled)
+	b.disabled = disabled
+	b.mu.Unlock()
+
+	if changed {
+		b.toolsChanged()
+	}
+}
+
+// isDisabled reports whether a tool is disabled
+func (b *BaseBackend) isDisabled(name string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.disabled[name]
+}
+
+// CallTool executes a tool
+// A streaming tool runs to completion and returns the result it emitted
+// with EmitResult, or the array of its data chunks if it emitted none, so
+// the same tool serves clients that cannot stream.
+func (b *BaseBackend) CallTool(ctx context.Context, name string, args map[string]interface{}) (result interface{}, err error) {
+	ctx, span := b.startToolSpan(ctx, name, false)
+	defer func() { observability.EndSpan(span, err) }()
+
+	b.toolsMu.RLock()
 	handler, ok := b.handlers[name]
+	if streaming, isStreaming := b.streamingHandlers[name]; !ok && isStreaming {
+		handler, ok = collect(streaming), true
+	}
+	b.toolsMu.RUnlock()
 	if !ok {
 		return nil, fmt.Errorf("tool not found: %s", name)
 	}
+	if b.isDisabled(name) {
+		return nil, fmt.Errorf("tool is disabled: %s", name)
+	}
 
 	// === NEW: Optional auth validation before tool execution ===
 	// Tools can choose to use auth or not
@@ -118,24 +290,193 @@ func (b *BaseBackend) CallTool(ctx context.Context, name string, args map[string
 			// Logger would go here if available
 		}
 	}
-	return handler(ctx, args)
+
+	if ctx, err = b.bindToolResource(ctx, name); err != nil {
+		return nil, err
+	}
+
+	breaker := b.breakerFor(name)
+	if breaker == nil {
+		return callHandler(ctx, name, handler, args)
+	}
+
+	err = breaker.Execute(func() error {
+		var err error
+		result, err = callHandler(ctx, name, handler, args)
+		return err
+	})
+	return result, err
 }
 
 // IsStreamingTool checks if a tool supports streaming (NEW)
 func (b *BaseBackend) IsStreamingTool(name string) bool {
+	b.toolsMu.RLock()
+	defer b.toolsMu.RUnlock()
 	_, ok := b.streamingHandlers[name]
 	return ok
 }
 
 // CallStreamingTool executes a streaming tool (NEW)
-func (b *BaseBackend) CallStreamingTool(ctx context.Context, name string, args map[string]interface{}, emit StreamingEmitter) error {
+func (b *BaseBackend) CallStreamingTool(ctx context.Context, name string, args map[string]interface{}, emit StreamingEmitter) (err error) {
+	ctx, span := b.startToolSpan(ctx, name, true)
+	defer func() { observability.EndSpan(span, err) }()
+
+	b.toolsMu.RLock()
 	handler, ok := b.streamingHandlers[name]
+	b.toolsMu.RUnlock()
 	if !ok {
 		return fmt.Errorf("streaming tool not found: %s", name)
 	}
+	if b.isDisabled(name) {
+		return fmt.Errorf("tool is disabled: %s", name)
+	}
+	if ctx, err = b.bindToolResource(ctx, name); err != nil {
+		return err
+	}
+
+	if breaker := b.breakerFor(name); breaker != nil {
+		return breaker.Execute(func() error { return callStreamingHandler(ctx, name, handler, args, emit) })
+	}
+	return callStreamingHandler(ctx, name, handler, args, emit)
+}
+
+// callHandler calls a tool's handler, failing the call with an internal
+// error if it panics
+// Panics of goroutines the handler starts are not recovered.
+func callHandler(ctx context.Context, name string, handler ToolHandler, args map[string]interface{}) (result interface{}, err error) {
+	defer recoverPanic(ctx, name, &err)
+	return handler(ctx, args)
+}
+
+// callStreamingHandler is callHandler for streaming tools
+func callStreamingHandler(ctx context.Context, name string, handler StreamingHandler, args map[string]interface{}, emit StreamingEmitter) (err error) {
+	defer recoverPanic(ctx, name, &err)
 	return handler(ctx, args, emit)
 }
 
+// recoverPanic, deferred, turns a panic into err, logging its stack at
+// debug level
+func recoverPanic(ctx context.Context, tool string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	recovered := mcperr.Recovered(r, "tool %s panicked", tool)
+	*err = recovered
+
+	observability.RecordToolPanic(tool)
+	slog.ErrorContext(ctx, "tool handler panicked", "tool", tool, "panic", r)
+	slog.DebugContext(ctx, "tool handler panic stack", "tool", tool, "stack", string(recovered.Err.(*mcperr.PanicError).Stack))
+}
+
+// startToolSpan starts the span of a tool execution
+func (b *BaseBackend) startToolSpan(ctx context.Context, name string, streaming bool) (context.Context, trace.Span) {
+	return observability.StartSpan(ctx, "tool "+name, trace.WithAttributes(
+		attribute.String("mcp.backend", b.name),
+		attribute.String("mcp.tool.name", name),
+		attribute.Bool("mcp.tool.streaming", streaming),
+	))
+}
+
+// HTTPClient returns base with its requests traced: each gets a client
+// span and carries the caller's trace context to the upstream server
+// A nil base means a client with http.DefaultTransport. Use it for
+// upstream API calls so they show up under the tool call's span.
+func (b *BaseBackend) HTTPClient(base *http.Client) *http.Client {
+	client := &http.Client{}
+	if base != nil {
+		*client = *base
+	}
+	client.Transport = observability.TraceTransport(client.Transport)
+	return client
+}
+
+// ============================================================
+// Circuit Breakers
+// ============================================================
+
+// UseCircuitBreaker guards every tool without its own breaker with one
+// shared breaker named after the backend
+// Use it when all tools call the same upstream API.
+func (b *BaseBackend) UseCircuitBreaker(config resilience.Config) {
+	breaker := resilience.NewBreaker(b.name, breakerConfig(config))
+
+	b.mu.Lock()
+	b.breaker = breaker
+	b.mu.Unlock()
+}
+
+// CircuitBreakers returns the backend's breakers, sorted by name
+func (b *BaseBackend) CircuitBreakers() []*resilience.Breaker {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	breakers := make([]*resilience.Breaker, 0, len(b.toolBreakers)+1)
+	if b.breaker != nil {
+		breakers = append(breakers, b.breaker)
+	}
+	for _, breaker := range b.toolBreakers {
+		breakers = append(breakers, breaker)
+	}
+	sort.Slice(breakers, func(i, j int) bool { return breakers[i].Name() < breakers[j].Name() })
+	return breakers
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Open circuit breakers degrade readiness. Backends embedding BaseBackend
+// can override it to add their own checks (call this one too):
+//
+//	func (b *WeatherBackend) RegisterHealthChecks(r *health.Registry) {
+//	    b.BaseBackend.RegisterHealthChecks(r)
+//	    r.RegisterReadiness("weather_api", b.pingAPI)
+//	}
+func (b *BaseBackend) RegisterHealthChecks(r *health.Registry) {
+	r.RegisterReadiness("circuit_breakers", resilience.HealthCheck(b.CircuitBreakers))
+}
+
+// registerBreaker creates the breaker of a tool declaring one
+func (b *BaseBackend) registerBreaker(tool ToolDefinition) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if tool.CircuitBreaker == nil {
+		delete(b.toolBreakers, tool.Name)
+		return
+	}
+	if b.toolBreakers == nil {
+		b.toolBreakers = make(map[string]*resilience.Breaker)
+	}
+	b.toolBreakers[tool.Name] = resilience.NewBreaker(tool.Name, breakerConfig(*tool.CircuitBreaker))
+}
+
+// breakerFor returns the breaker guarding a tool, or nil
+func (b *BaseBackend) breakerFor(name string) *resilience.Breaker {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if breaker, ok := b.toolBreakers[name]; ok {
+		return breaker
+	}
+	return b.breaker
+}
+
+// breakerConfig makes invalid arguments and tool error results not count
+// as upstream failures unless the config decides otherwise
+func breakerConfig(config resilience.Config) resilience.Config {
+	if config.IsFailure == nil {
+		config.IsFailure = func(err error) bool {
+			if errors.Is(err, ErrInvalidArguments) {
+				return false
+			}
+			if _, ok := AsToolError(err); ok {
+				return false
+			}
+			return resilience.DefaultIsFailure(err)
+		}
+	}
+	return config
+}
+
 // ============================================================
 // Resource Management
 // ============================================================
@@ -248,6 +589,109 @@ func (b *BaseBackend) GetAuthenticatedResource(ctx context.Context, providerName
 	return manager.GetResource(ctx, providerName, resourceID)
 }
 
+// GetAuthenticatedResourceWithScopes gets a resource whose credentials carry only scopes
+// Uses the primary auth provider, or the manager's "default" provider.
+// Fails (wrapping auth.ErrScopingUnsupported) instead of falling back to
+// full-privilege credentials when the provider cannot narrow them.
+//
+// Example usage in a tool:
+//
+//	res, err := b.GetAuthenticatedResourceWithScopes(ctx, "google-drive",
+//	    []string{"https://www.googleapis.com/auth/drive.readonly"})
+func (b *BaseBackend) GetAuthenticatedResourceWithScopes(ctx context.Context, resourceID string, scopes []string) (auth.Resource, error) {
+	provider, err := b.authProviderOrDefault()
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.GetScopedResource(ctx, provider, resourceID, scopes)
+}
+
+// authProviderOrDefault returns the primary auth provider, or the
+// manager's "default" provider
+func (b *BaseBackend) authProviderOrDefault() (auth.AuthProvider, error) {
+	b.mu.RLock()
+	provider := b.authProvider
+	manager := b.authManager
+	b.mu.RUnlock()
+
+	if provider == nil && manager != nil {
+		provider, _ = manager.Get("default")
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("no auth configured")
+	}
+	return provider, nil
+}
+
+// GetAuthSecret returns the auth provider's credential, for APIs that
+// take it in a query parameter, an SDK or a signature
+// Fetch it per call rather than keeping it: keys rotate and tokens expire.
+// The secret prints as "[REDACTED]"; Reveal returns the value.
+//
+// Example usage in a tool:
+//
+//	secret, err := b.GetAuthSecret(ctx, "")
+//	q.Set("key", secret.Reveal())
+func (b *BaseBackend) GetAuthSecret(ctx context.Context, resourceID string) (auth.Secret, error) {
+	provider, err := b.authProviderOrDefault()
+	if err != nil {
+		return auth.Secret{}, err
+	}
+	return auth.GetSecret(ctx, provider, resourceID)
+}
+
+// GetAuthenticatedHTTPClient returns an HTTP client whose requests the
+// auth provider authenticates, without exposing the credential
+// resourceID "" uses the provider's own credential.
+func (b *BaseBackend) GetAuthenticatedHTTPClient(ctx context.Context, resourceID string) (*http.Client, error) {
+	provider, err := b.authProviderOrDefault()
+	if err != nil {
+		return nil, err
+	}
+	return auth.HTTPClient(ctx, provider, resourceID)
+}
+
+// bindToolResource resolves the auth resource bound to a tool and attaches
+// it to the call's context (see auth.ResourceFromContext)
+// A binding in the auth manager overrides the tool definition's.
+func (b *BaseBackend) bindToolResource(ctx context.Context, name string) (context.Context, error) {
+	tool, _ := b.lookupTool(name)
+	binding := tool.Auth
+
+	b.mu.RLock()
+	manager := b.authManager
+	b.mu.RUnlock()
+	if manager != nil {
+		if bound, ok := manager.ToolResource(name); ok {
+			binding = &bound
+		}
+	}
+	if binding == nil {
+		return ctx, nil
+	}
+
+	var provider auth.AuthProvider
+	var err error
+	switch {
+	case binding.Provider == "":
+		provider, err = b.authProviderOrDefault()
+	case manager != nil:
+		provider, err = manager.Get(binding.Provider)
+	default:
+		err = fmt.Errorf("%w: %s", auth.ErrProviderNotFound, binding.Provider)
+	}
+	if err != nil {
+		return ctx, fmt.Errorf("tool %s: %w", name, err)
+	}
+
+	resource, err := binding.Get(ctx, provider)
+	if err != nil {
+		return ctx, fmt.Errorf("tool %s: failed to get auth resource %q: %w", name, binding.Resource, err)
+	}
+	return auth.WithResource(ctx, *binding, resource), nil
+}
+
 // ValidateAuth validates the current auth configuration
 // Tools can call this at the start of execution
 func (b *BaseBackend) ValidateAuth(ctx context.Context) error {
@@ -0,0 +1,200 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/resilience"
+)
+
+func TestBaseBackend_CircuitBreaker(t *testing.T) {
+	b := backend.NewBaseBackend("weather")
+	b.UseCircuitBreaker(resilience.Config{FailureThreshold: 2})
+
+	calls := 0
+	upstreamDown := errors.New("connection refused")
+	b.RegisterTool(backend.NewTool("forecast").Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		calls++
+		return nil, upstreamDown
+	})
+	b.RegisterTool(backend.NewTool("lookup").
+		CircuitBreaker(resilience.Config{FailureThreshold: 1}).
+		Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, backend.NewToolError("city not found")
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		b.CallTool(ctx, "forecast", nil)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2 before the breaker opened", calls)
+	}
+	if _, err := b.CallTool(ctx, "forecast", nil); !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Errorf("error = %v, want circuit open", err)
+	}
+
+	// Tool error results are domain failures, not upstream failures
+	b.CallTool(ctx, "lookup", nil)
+	b.CallTool(ctx, "lookup", nil)
+
+	breakers := b.CircuitBreakers()
+	if len(breakers) != 2 || breakers[0].Name() != "lookup" || breakers[1].Name() != "weather" {
+		t.Fatalf("breakers = %v", breakers)
+	}
+	if breakers[0].State() != resilience.StateClosed {
+		t.Errorf("lookup breaker = %v, want closed", breakers[0].State())
+	}
+	if breakers[1].State() != resilience.StateOpen {
+		t.Errorf("backend breaker = %v, want open", breakers[1].State())
+	}
+}
+
+func TestBaseBackend_RecoversPanics(t *testing.T) {
+	b := backend.NewBaseBackend("fragile")
+	b.UseCircuitBreaker(resilience.Config{FailureThreshold: 5})
+	b.RegisterTool(backend.NewTool("divide").Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		var m map[string]int
+		m["x"] = 1
+		return nil, nil
+	})
+	b.RegisterStreamingTool(backend.NewTool("stream").Build(), func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		panic("stream broke")
+	})
+
+	_, err := b.CallTool(context.Background(), "divide", nil)
+	categorized, ok := mcperr.As(err)
+	if !ok || categorized.Code != mcperr.CodeInternal || categorized.Details["panic"] != true {
+		t.Fatalf("error = %v", err)
+	}
+	if !strings.Contains(err.Error(), "tool divide panicked") {
+		t.Errorf("message = %q", err.Error())
+	}
+
+	err = b.CallStreamingTool(context.Background(), "stream", nil, nil)
+	var panicErr *mcperr.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "stream broke" {
+		t.Errorf("streaming error = %v", err)
+	}
+
+	// Panics count as breaker failures
+	if breakers := b.CircuitBreakers(); breakers[0].Stats().ConsecutiveFailures != 2 {
+		t.Errorf("breaker stats = %+v", breakers[0].Stats())
+	}
+}
+
+func TestBaseBackend_DynamicTools(t *testing.T) {
+	b := backend.NewBaseBackend("dynamic")
+	changes := 0
+	b.OnToolsChanged(func() { changes++ })
+
+	echo := func(reply string) backend.ToolHandler {
+		return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return reply, nil
+		}
+	}
+
+	if err := b.AddTool(backend.NewTool("greet").Build(), echo("hello")); err != nil {
+		t.Fatalf("AddTool: %v", err)
+	}
+	if err := b.AddTool(backend.NewTool("greet").Build(), echo("again")); err == nil {
+		t.Error("duplicate AddTool succeeded")
+	}
+	if len(b.ListTools()) != 1 {
+		t.Errorf("tools = %v", b.ListTools())
+	}
+
+	if err := b.ReplaceTool(backend.NewTool("greet").Description("v2").Build(), echo("hi")); err != nil {
+		t.Fatalf("ReplaceTool: %v", err)
+	}
+	if result, _ := b.CallTool(context.Background(), "greet", nil); result != "hi" {
+		t.Errorf("result = %v, want the replacement's", result)
+	}
+	if tool, _ := b.GetTool("greet"); tool.Description != "v2" {
+		t.Errorf("description = %q", tool.Description)
+	}
+	if err := b.ReplaceTool(backend.NewTool("missing").Build(), echo("")); err == nil {
+		t.Error("ReplaceTool of a missing tool succeeded")
+	}
+
+	if !b.RemoveTool("greet") || b.RemoveTool("greet") {
+		t.Error("RemoveTool should succeed once")
+	}
+	if _, err := b.CallTool(context.Background(), "greet", nil); err == nil {
+		t.Error("removed tool is still callable")
+	}
+
+	b.SetDisabledTools(nil) // no change, no notification
+	if changes != 3 {
+		t.Errorf("changes = %d, want 3 (add, replace, remove)", changes)
+	}
+}
+
+func TestBaseBackend_ConcurrentToolChanges(t *testing.T) {
+	b := backend.NewBaseBackend("dynamic")
+	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) { return nil, nil }
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			name := fmt.Sprintf("tool_%d", i%10)
+			b.AddTool(backend.NewTool(name).Build(), handler)
+			b.RemoveTool(name)
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		b.ListTools()
+		b.CallTool(context.Background(), "tool_1", nil)
+	}
+	<-done
+}
+
+func TestBaseBackend_ToolAuthResource(t *testing.T) {
+	github := auth.NewStaticHeaderProvider("github", auth.StaticHeaderConfig{BearerToken: "t"})
+	github.RegisterResource(auth.ResourceConfig{ID: "github-readonly", Config: map[string]interface{}{"base_url": "https://read.example"}})
+	github.RegisterResource(auth.ResourceConfig{ID: "github-write", Config: map[string]interface{}{"base_url": "https://write.example"}})
+	manager := auth.NewManager()
+	manager.Register("github", github)
+
+	b := backend.NewBaseBackend("github")
+	b.SetAuthManager(manager)
+	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		res, ok := auth.ResourceFromContext(ctx)
+		if !ok {
+			return "none", nil
+		}
+		return res.(*auth.StaticHeaderResource).BaseURL(), nil
+	}
+	b.RegisterTool(backend.NewTool("search_repos").AuthResource("github", "github-readonly").Build(), handler)
+	b.RegisterTool(backend.NewTool("create_repo").AuthResource("github", "github-readonly").Build(), handler)
+	b.RegisterTool(backend.NewTool("whoami").Build(), handler)
+	b.RegisterTool(backend.NewTool("broken").AuthResource("gitlab", "api").Build(), handler)
+
+	// Configuration overrides the builder's binding
+	if err := manager.BindTool("create_repo", auth.ToolResource{Provider: "github", Resource: "github-write"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for tool, want := range map[string]string{"search_repos": "https://read.example", "create_repo": "https://write.example", "whoami": "none"} {
+		if got, err := b.CallTool(ctx, tool, nil); err != nil || got != want {
+			t.Errorf("%s: got %v, %v; want %s", tool, got, err, want)
+		}
+	}
+	if _, err := b.CallTool(ctx, "broken", nil); !errors.Is(err, auth.ErrProviderNotFound) {
+		t.Errorf("unknown provider: %v", err)
+	}
+
+	// Scoped bindings never fall back to full credentials
+	manager.BindTool("whoami", auth.ToolResource{Provider: "github", Resource: "github-readonly", Scopes: []string{"read:user"}})
+	if _, err := b.CallTool(ctx, "whoami", nil); !errors.Is(err, auth.ErrScopingUnsupported) {
+		t.Errorf("scoped binding: %v", err)
+	}
+}
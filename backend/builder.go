@@ -1,6 +1,11 @@
 package backend
 
-import "time"
+import (
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/resilience"
+)
 
 // ToolBuilder provides fluent API for building tool definitions
 type ToolBuilder struct {
@@ -9,6 +14,17 @@ type ToolBuilder struct {
 	parameters  []Parameter
 	streaming   bool            // Existing
 	cache       ToolCacheConfig // NEW
+	scopes      []string
+	output      map[string]interface{}
+	timeout     time.Duration
+	maxResult   int
+	overflow    string
+	overflowTTL time.Duration
+	batchSize   int
+	flushEvery  time.Duration
+	breaker     *resilience.Config
+	version     string
+	auth        *auth.ToolResource
 }
 
 // NewTool creates a new tool builder
@@ -82,12 +98,217 @@ func (b *ToolBuilder) EnumParam(name, description string, required bool, values
 	return b
 }
 
+// DateTimeParam adds an RFC 3339 date-time parameter
+// Values must carry a UTC offset ("2025-03-30T09:00:00+02:00"), so they
+// name one instant; bind them into a time.Time.
+func (b *ToolBuilder) DateTimeParam(name, description string, required bool) *ToolBuilder {
+	b.parameters = append(b.parameters, Parameter{
+		Name:        name,
+		Description: description,
+		Type:        "string",
+		Required:    required,
+		Schema:      map[string]interface{}{"type": "string", "format": "date-time", "description": description},
+	})
+	return b
+}
+
+// DateParam adds a YYYY-MM-DD date parameter; bind it into a Date
+func (b *ToolBuilder) DateParam(name, description string, required bool) *ToolBuilder {
+	b.parameters = append(b.parameters, Parameter{
+		Name:        name,
+		Description: description,
+		Type:        "string",
+		Required:    required,
+		Schema:      map[string]interface{}{"type": "string", "format": "date", "description": description},
+	})
+	return b
+}
+
+// TimeZoneParam adds an IANA time zone parameter; bind it into a TimeZone
+func (b *ToolBuilder) TimeZoneParam(name, description string, required bool) *ToolBuilder {
+	if description == "" {
+		description = timeZoneDescription
+	}
+	b.parameters = append(b.parameters, Parameter{
+		Name:        name,
+		Description: description,
+		Type:        "string",
+		Required:    required,
+	})
+	return b
+}
+
+// Paginated adds the cursor and limit parameters of list-style tools
+// Bind them by embedding PageRequest in the tool's arguments, and return
+// a Page (see Paginate) carrying the next cursor.
+//
+// Example:
+//
+//	NewTool("list_orders").
+//	    Paginated().
+//	    Build()
+func (b *ToolBuilder) Paginated() *ToolBuilder {
+	minLimit, maxLimit := 1, MaxPageSize
+	b.parameters = append(b.parameters,
+		Parameter{
+			Name:        "cursor",
+			Description: "The nextCursor of the previous page; omit for the first page",
+			Type:        "string",
+		},
+		Parameter{
+			Name:        "limit",
+			Description: "Maximum number of items to return",
+			Type:        "integer",
+			Default:     DefaultPageSize,
+			Minimum:     &minLimit,
+			Maximum:     &maxLimit,
+		})
+	return b
+}
+
 // Streaming marks the tool as supporting streaming (Existing)
 func (b *ToolBuilder) Streaming(enabled bool) *ToolBuilder {
 	b.streaming = enabled
 	return b
 }
 
+// RequireScope requires callers to hold the given scopes (or roles)
+// Calls from principals lacking any of them are rejected by the protocol handler
+//
+// Example:
+//
+//	NewTool("write_file").
+//	    RequireScope("fs:write").
+//	    Build()
+func (b *ToolBuilder) RequireScope(scopes ...string) *ToolBuilder {
+	b.scopes = append(b.scopes, scopes...)
+	return b
+}
+
+// OutputSchema declares the JSON Schema of the tool's result
+// The schema must describe an object; results are then also returned as
+// structuredContent.
+func (b *ToolBuilder) OutputSchema(schema map[string]interface{}) *ToolBuilder {
+	b.output = schema
+	return b
+}
+
+// OutputFromStruct declares the output schema from a Go struct
+// See SchemaFromStruct for the supported tags.
+//
+// Example:
+//
+//	NewTool("get_weather").
+//	    OutputFromStruct(Forecast{}).
+//	    Build()
+func (b *ToolBuilder) OutputFromStruct(v any) *ToolBuilder {
+	schema, err := SchemaFromStruct(v)
+	if err != nil {
+		panic(err)
+	}
+	b.output = schema
+	return b
+}
+
+// Timeout bounds each call of the tool, replacing the server-wide default
+// Clients may ask for a shorter deadline (X-Timeout, _meta.timeout) but not
+// a longer one.
+//
+// Example:
+//
+//	NewTool("generate_report").
+//	    Timeout(2 * time.Minute).
+//	    Build()
+func (b *ToolBuilder) Timeout(d time.Duration) *ToolBuilder {
+	b.timeout = d
+	return b
+}
+
+// MaxResultSize bounds the encoded size of the tool's tools/call results
+// in bytes, replacing the server-wide limit; results over it are handled
+// by the server's truncation policy. Configured per-tool limits override
+// it.
+//
+// Example:
+//
+//	NewTool("export_table").
+//	    MaxResultSize(4 << 20).
+//	    Build()
+func (b *ToolBuilder) MaxResultSize(bytes int) *ToolBuilder {
+	b.maxResult = bytes
+	return b
+}
+
+// EventOverflow sets what the streaming tool's emits do when the event
+// buffer is full because the client reads slower than the tool emits:
+// "block" (for up to timeout), "drop_oldest", "drop_newest" or
+// "coalesce" (keep the latest progress event). It replaces the server's
+// policy; a zero timeout keeps the server's.
+//
+// Example:
+//
+//	NewTool("tail_logs").
+//	    Streaming(true).
+//	    EventOverflow("drop_oldest", 0).
+//	    Build()
+func (b *ToolBuilder) EventOverflow(policy string, timeout time.Duration) *ToolBuilder {
+	b.overflow = policy
+	b.overflowTTL = timeout
+	return b
+}
+
+// BatchEvents groups the streaming tool's data events into array events
+// of up to size chunks, each sent at the latest flushInterval after its
+// first chunk (0: 100ms), for tools that emit one event per match or row
+//
+// Example:
+//
+//	NewTool("grep").
+//	    Streaming(true).
+//	    BatchEvents(100, 50*time.Millisecond).
+//	    Build()
+func (b *ToolBuilder) BatchEvents(size int, flushInterval time.Duration) *ToolBuilder {
+	b.batchSize = size
+	b.flushEvery = flushInterval
+	return b
+}
+
+// CircuitBreaker guards the tool with its own circuit breaker
+// Without it the tool shares the backend's breaker, if any
+// (see BaseBackend.UseCircuitBreaker).
+//
+// Example:
+//
+//	NewTool("get_forecast").
+//	    CircuitBreaker(resilience.Config{FailureThreshold: 3, OpenDuration: time.Minute}).
+//	    Build()
+func (b *ToolBuilder) CircuitBreaker(config resilience.Config) *ToolBuilder {
+	b.breaker = &config
+	return b
+}
+
+// AuthResource binds the tool to an auth resource, resolved before each
+// call and read by the handler with auth.ResourceFromContext
+// provider "" is the backend's primary provider. With scopes the
+// credentials are narrowed to them, or the call fails. Bindings in the
+// auth configuration (auth.tools) override this one.
+//
+// Example:
+//
+//	NewTool("search_repos").
+//	    AuthResource("github", "github-readonly").
+//	    Build()
+func (b *ToolBuilder) AuthResource(provider, resourceID string, scopes ...string) *ToolBuilder {
+	b.auth = &auth.ToolResource{Provider: provider, Resource: resourceID, Scopes: scopes}
+	return b
+}
+
+// Version sets the tool's version, reported in result provenance
+func (b *ToolBuilder) Version(version string) *ToolBuilder {
+	b.version = version
+	return b
+}
+
 // ============================================================
 // NEW: Cache Configuration Methods
 // ============================================================
@@ -100,9 +321,8 @@ func (b *ToolBuilder) Streaming(enabled bool) *ToolBuilder {
 //	    WithCache(true, 5*time.Minute).  // Cache for 5 minutes
 //	    Build()
 func (b *ToolBuilder) WithCache(cacheable bool, ttl time.Duration) *ToolBuilder {
-	b.cache = ToolCacheConfig{
-		Cacheable: cacheable,
-	}
+	b.cache.Cacheable = cacheable
+	b.cache.TTL = nil
 	if cacheable && ttl > 0 {
 		b.cache.TTL = &ttl
 	}
@@ -111,17 +331,15 @@ func (b *ToolBuilder) WithCache(cacheable bool, ttl time.Duration) *ToolBuilder
 
 // Cacheable marks the tool as cacheable with default TTL
 func (b *ToolBuilder) Cacheable() *ToolBuilder {
-	b.cache = ToolCacheConfig{
-		Cacheable: true,
-	}
+	b.cache.Cacheable = true
+	b.cache.TTL = nil
 	return b
 }
 
 // NonCacheable explicitly marks the tool as non-cacheable
 func (b *ToolBuilder) NonCacheable() *ToolBuilder {
-	b.cache = ToolCacheConfig{
-		Cacheable: false,
-	}
+	b.cache.Cacheable = false
+	b.cache.TTL = nil
 	return b
 }
 
@@ -131,6 +349,20 @@ func (b *ToolBuilder) CacheTags(tags ...string) *ToolBuilder {
 	return b
 }
 
+// CacheVersion sets the schema version of the cached results; bump it
+// when the tool's output format changes
+func (b *ToolBuilder) CacheVersion(version string) *ToolBuilder {
+	b.cache.Version = version
+	return b
+}
+
+// CachePerPrincipal caches results per authenticated caller, so they are
+// never shared across users
+func (b *ToolBuilder) CachePerPrincipal() *ToolBuilder {
+	b.cache.PerPrincipal = true
+	return b
+}
+
 // Build creates the tool definition
 func (b *ToolBuilder) Build() ToolDefinition {
 	return ToolDefinition{
@@ -139,5 +371,18 @@ func (b *ToolBuilder) Build() ToolDefinition {
 		Parameters:  b.parameters,
 		Streaming:   b.streaming,
 		Cache:       b.cache, // NEW
+
+		RequiredScopes: b.scopes,
+		OutputSchema:   b.output,
+		Timeout:        b.timeout,
+		MaxResultSize:  b.maxResult,
+
+		EventOverflow:        b.overflow,
+		EventOverflowTimeout: b.overflowTTL,
+		EventBatchSize:       b.batchSize,
+		EventFlushInterval:   b.flushEvery,
+		CircuitBreaker:       b.breaker,
+		Version:              b.version,
+		Auth:                 b.auth,
 	}
 }
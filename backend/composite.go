@@ -0,0 +1,352 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+)
+
+// PrefixSeparator joins a mount prefix and a tool name ("fs.file_read")
+const PrefixSeparator = "."
+
+// Mount places a backend in a CompositeBackend
+type Mount struct {
+	Backend ServerBackend
+
+	// Prefix namespaces the backend's tools and prompts ("fs" serves
+	// file_read as fs.file_read). Empty serves them under their own names.
+	Prefix string
+
+	// Config is passed to the backend's Initialize
+	// If nil, the composite's config entry keyed by the mount's key (the
+	// prefix, or the backend name) is used.
+	Config map[string]interface{}
+}
+
+// key identifies the mount in config and health checks
+func (m Mount) key() string {
+	if m.Prefix != "" {
+		return m.Prefix
+	}
+	return m.Backend.Name()
+}
+
+// qualify returns the name a mount serves name under
+func (m Mount) qualify(name string) string {
+	if m.Prefix == "" {
+		return name
+	}
+	return m.Prefix + PrefixSeparator + name
+}
+
+// CompositeBackend serves several backends from one server
+// tools/list merges the mounted backends' tools, tools/call is routed by
+// name, and each backend is initialized and closed on its own. When two
+// mounts serve the same name the first one wins.
+//
+// Example:
+//
+//	composite := backend.NewCompositeBackend("workspace",
+//	    backend.Mount{Backend: fs, Prefix: "fs"},
+//	    backend.Mount{Backend: gh, Prefix: "gh"},
+//	)
+//
+//	# config.yaml: backend.config entries are handed out per prefix
+//	backend:
+//	  type: workspace
+//	  config:
+//	    fs: {root: /data}
+//	    gh: {org: acme}
+type CompositeBackend struct {
+	name   string
+	mounts []Mount
+
+	authProvider auth.AuthProvider
+	authManager  *auth.Manager
+	mu           sync.RWMutex
+}
+
+// NewCompositeBackend creates a backend serving mounts
+func NewCompositeBackend(name string, mounts ...Mount) *CompositeBackend {
+	return &CompositeBackend{name: name, mounts: mounts}
+}
+
+// Mount adds a backend
+// Mount before the server initializes the composite.
+func (c *CompositeBackend) Mount(b ServerBackend, prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mounts = append(c.mounts, Mount{Backend: b, Prefix: prefix})
+}
+
+// Mounts returns the mounted backends
+func (c *CompositeBackend) Mounts() []Mount {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Mount(nil), c.mounts...)
+}
+
+// Name returns the backend name
+func (c *CompositeBackend) Name() string {
+	return c.name
+}
+
+// Initialize initializes every mounted backend
+// If one fails, or is still initializing when ctx is done, those already
+// initialized are closed again and the error names the mount.
+func (c *CompositeBackend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	mounts := c.Mounts()
+	for i, m := range mounts {
+		mountConfig := m.Config
+		if mountConfig == nil {
+			mountConfig, _ = config[m.key()].(map[string]interface{})
+		}
+
+		if err := initialize(ctx, m.Backend, m.key(), mountConfig); err != nil {
+			for _, initialized := range mounts[:i] {
+				initialized.Backend.Close()
+			}
+			return fmt.Errorf("backend %s: %w", m.key(), err)
+		}
+	}
+	return nil
+}
+
+// Close closes every mounted backend, returning their joined errors
+func (c *CompositeBackend) Close() error {
+	var errs []error
+	for _, m := range c.Mounts() {
+		if err := m.Backend.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("backend %s: %w", m.key(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ============================================================
+// Tool routing
+// ============================================================
+
+// route finds the mount serving a tool and the backend's own tool name
+func (c *CompositeBackend) route(name string) (Mount, string, bool) {
+	for _, m := range c.Mounts() {
+		local := name
+		if m.Prefix != "" {
+			var ok bool
+			if local, ok = strings.CutPrefix(name, m.Prefix+PrefixSeparator); !ok {
+				continue
+			}
+		}
+		if _, ok := m.Backend.GetTool(local); ok {
+			return m, local, true
+		}
+	}
+	return Mount{}, "", false
+}
+
+// ListTools merges the mounted backends' tools under their served names
+func (c *CompositeBackend) ListTools() []ToolDefinition {
+	var tools []ToolDefinition
+	seen := make(map[string]bool)
+	for _, m := range c.Mounts() {
+		for _, tool := range m.Backend.ListTools() {
+			tool.Name = m.qualify(tool.Name)
+			if seen[tool.Name] {
+				continue
+			}
+			seen[tool.Name] = true
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// GetTool retrieves a tool definition by its served name
+func (c *CompositeBackend) GetTool(name string) (ToolDefinition, bool) {
+	m, local, ok := c.route(name)
+	if !ok {
+		return ToolDefinition{}, false
+	}
+	tool, _ := m.Backend.GetTool(local)
+	tool.Name = name
+	return tool, true
+}
+
+// CallTool routes a call to the backend serving the tool
+func (c *CompositeBackend) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	m, local, ok := c.route(name)
+	if !ok {
+		return nil, fmt.Errorf("tool not found: %s", name)
+	}
+	return m.Backend.CallTool(ctx, local, args)
+}
+
+// IsStreamingTool checks if a tool supports streaming
+func (c *CompositeBackend) IsStreamingTool(name string) bool {
+	m, local, ok := c.route(name)
+	return ok && m.Backend.IsStreamingTool(local)
+}
+
+// CallStreamingTool routes a streaming call to the backend serving the tool
+func (c *CompositeBackend) CallStreamingTool(ctx context.Context, name string, args map[string]interface{}, emit StreamingEmitter) error {
+	m, local, ok := c.route(name)
+	if !ok {
+		return fmt.Errorf("streaming tool not found: %s", name)
+	}
+	return m.Backend.CallStreamingTool(ctx, local, args, emit)
+}
+
+// SetDisabledTools disables tools by their served names on the backends
+// that support it
+func (c *CompositeBackend) SetDisabledTools(names []string) {
+	for _, m := range c.Mounts() {
+		switcher, ok := m.Backend.(interface{ SetDisabledTools([]string) })
+		if !ok {
+			continue
+		}
+
+		var local []string
+		for _, name := range names {
+			if m.Prefix == "" {
+				local = append(local, name)
+			} else if trimmed, ok := strings.CutPrefix(name, m.Prefix+PrefixSeparator); ok {
+				local = append(local, trimmed)
+			}
+		}
+		switcher.SetDisabledTools(local)
+	}
+}
+
+// OnToolsChanged registers fn with every mounted backend whose tools can
+// change
+func (c *CompositeBackend) OnToolsChanged(fn func()) {
+	for _, m := range c.Mounts() {
+		if notifier, ok := m.Backend.(ToolChangeNotifier); ok {
+			notifier.OnToolsChanged(fn)
+		}
+	}
+}
+
+// ============================================================
+// Resources and prompts
+// ============================================================
+
+// WatchResource watches uri with the first mounted backend that accepts it
+func (c *CompositeBackend) WatchResource(ctx context.Context, uri string, updated func(uri string)) error {
+	err := fmt.Errorf("no backend watches resource %s", uri)
+	for _, m := range c.Mounts() {
+		watcher, ok := m.Backend.(ResourceWatcher)
+		if !ok {
+			continue
+		}
+		if err = watcher.WatchResource(ctx, uri, updated); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// ListResources merges the mounted backends' resources
+func (c *CompositeBackend) ListResources() []Resource {
+	var resources []Resource
+	for _, m := range c.Mounts() {
+		resources = append(resources, m.Backend.ListResources()...)
+	}
+	return resources
+}
+
+// ListPrompts merges the mounted backends' prompts under prefixed names
+func (c *CompositeBackend) ListPrompts() []Prompt {
+	var prompts []Prompt
+	for _, m := range c.Mounts() {
+		for _, prompt := range m.Backend.ListPrompts() {
+			prompt.Name = m.qualify(prompt.Name)
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts
+}
+
+// ============================================================
+// Auth and health
+// ============================================================
+
+// SetAuthProvider sets the auth provider of every mounted backend
+func (c *CompositeBackend) SetAuthProvider(provider auth.AuthProvider) {
+	c.mu.Lock()
+	c.authProvider = provider
+	c.mu.Unlock()
+
+	for _, m := range c.Mounts() {
+		m.Backend.SetAuthProvider(provider)
+	}
+}
+
+// GetAuthProvider returns the auth provider
+func (c *CompositeBackend) GetAuthProvider() auth.AuthProvider {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.authProvider
+}
+
+// SetAuthManager sets the auth manager of every mounted backend
+func (c *CompositeBackend) SetAuthManager(manager *auth.Manager) {
+	c.mu.Lock()
+	c.authManager = manager
+	c.mu.Unlock()
+
+	for _, m := range c.Mounts() {
+		m.Backend.SetAuthManager(manager)
+	}
+}
+
+// GetAuthManager returns the auth manager
+func (c *CompositeBackend) GetAuthManager() *auth.Manager {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.authManager
+}
+
+// RegisterHealthChecks registers one liveness and one readiness check,
+// "backend:<key>", per mounted backend reporting health checks
+func (c *CompositeBackend) RegisterHealthChecks(r *health.Registry) {
+	for _, m := range c.Mounts() {
+		reporter, ok := m.Backend.(health.Reporter)
+		if !ok {
+			continue
+		}
+
+		checks := health.NewRegistry()
+		reporter.RegisterHealthChecks(checks)
+		for _, kind := range []health.Kind{health.Liveness, health.Readiness} {
+			r.Register(kind, "backend:"+m.key(), mountCheck(checks, kind))
+		}
+	}
+}
+
+// mountCheck summarizes a mounted backend's checks of kind as one check
+func mountCheck(checks *health.Registry, kind health.Kind) health.Check {
+	return func(ctx context.Context) error {
+		report := checks.Run(ctx, kind)
+		if report.Status == health.StatusOK {
+			return nil
+		}
+
+		var problems []string
+		for _, result := range report.Checks {
+			if result.Status != health.StatusOK {
+				problems = append(problems, result.Name+": "+result.Error)
+			}
+		}
+		err := errors.New(strings.Join(problems, "; "))
+		if report.Status == health.StatusDegraded {
+			return health.Degraded(err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,154 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+)
+
+// recordingBackend records its lifecycle calls
+type recordingBackend struct {
+	*backend.BaseBackend
+	config  map[string]interface{}
+	initErr error
+	closed  bool
+}
+
+func (b *recordingBackend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	b.config = config
+	return b.initErr
+}
+
+func (b *recordingBackend) Close() error {
+	b.closed = true
+	return nil
+}
+
+func newRecordingBackend(name string, tools ...string) *recordingBackend {
+	b := &recordingBackend{BaseBackend: backend.NewBaseBackend(name)}
+	for _, tool := range tools {
+		tool := tool
+		b.RegisterTool(backend.NewTool(tool).Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return name + "/" + tool, nil
+		})
+	}
+	return b
+}
+
+func TestCompositeBackend_Routing(t *testing.T) {
+	fs := newRecordingBackend("filesystem", "file_read", "list")
+	gh := newRecordingBackend("github", "list_repos", "list")
+	util := newRecordingBackend("util", "echo")
+
+	c := backend.NewCompositeBackend("workspace",
+		backend.Mount{Backend: fs, Prefix: "fs"},
+		backend.Mount{Backend: gh, Prefix: "gh"},
+		backend.Mount{Backend: util},
+	)
+
+	var names []string
+	for _, tool := range c.ListTools() {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	want := "echo,fs.file_read,fs.list,gh.list,gh.list_repos"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("tools = %s, want %s", got, want)
+	}
+
+	for name, want := range map[string]string{
+		"fs.list":         "filesystem/list",
+		"gh.list":         "github/list",
+		"gh.list_repos":   "github/list_repos",
+		"echo":            "util/echo",
+		"fs.file_read":    "filesystem/file_read",
+		"gh.file_read":    "",
+		"file_read":       "",
+		"missing.example": "",
+	} {
+		result, err := c.CallTool(context.Background(), name, nil)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%s: routed to %v, want not found", name, result)
+			}
+			continue
+		}
+		if err != nil || result != want {
+			t.Errorf("%s: result = %v, err = %v, want %s", name, result, err, want)
+		}
+	}
+
+	if tool, ok := c.GetTool("gh.list_repos"); !ok || tool.Name != "gh.list_repos" {
+		t.Errorf("GetTool = %+v, %v", tool, ok)
+	}
+
+	c.SetDisabledTools([]string{"fs.list", "echo"})
+	if _, ok := c.GetTool("fs.list"); ok {
+		t.Error("fs.list is still enabled")
+	}
+	if _, ok := c.GetTool("gh.list"); !ok {
+		t.Error("gh.list was disabled")
+	}
+	if _, ok := c.GetTool("echo"); ok {
+		t.Error("echo is still enabled")
+	}
+}
+
+func TestCompositeBackend_Lifecycle(t *testing.T) {
+	fs := newRecordingBackend("filesystem")
+	gh := newRecordingBackend("github")
+	c := backend.NewCompositeBackend("workspace",
+		backend.Mount{Backend: fs, Prefix: "fs"},
+		backend.Mount{Backend: gh, Config: map[string]interface{}{"org": "explicit"}},
+	)
+
+	err := c.Initialize(context.Background(), map[string]interface{}{
+		"fs":     map[string]interface{}{"root": "/data"},
+		"github": map[string]interface{}{"org": "ignored"},
+	})
+	if err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	if fs.config["root"] != "/data" {
+		t.Errorf("fs config = %v", fs.config)
+	}
+	if gh.config["org"] != "explicit" {
+		t.Errorf("github config = %v, want the mount's own", gh.config)
+	}
+
+	// A failing backend closes the ones already initialized
+	fs, gh = newRecordingBackend("filesystem"), newRecordingBackend("github")
+	gh.initErr = errors.New("bad token")
+	c = backend.NewCompositeBackend("workspace", backend.Mount{Backend: fs}, backend.Mount{Backend: gh})
+	err = c.Initialize(context.Background(), nil)
+	if err == nil || !strings.Contains(err.Error(), "backend github: bad token") {
+		t.Errorf("Initialize error = %v", err)
+	}
+	if !fs.closed {
+		t.Error("initialized backend was not closed")
+	}
+
+	if err := c.Close(); err != nil || !gh.closed {
+		t.Errorf("Close: %v, github closed = %v", err, gh.closed)
+	}
+}
+
+func TestCompositeBackend_Health(t *testing.T) {
+	fs := newRecordingBackend("filesystem")
+	c := backend.NewCompositeBackend("workspace",
+		backend.Mount{Backend: fs, Prefix: "fs"},
+		backend.Mount{Backend: newRecordingBackend("github"), Prefix: "gh"},
+	)
+
+	r := health.NewRegistry()
+	c.RegisterHealthChecks(r)
+	report := r.Run(context.Background(), health.Readiness)
+	if report.Status != health.StatusOK || len(report.Checks) != 2 || report.Checks[0].Name != "backend:fs" {
+		t.Errorf("report = %+v", report)
+	}
+}
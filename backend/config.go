@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================
+// Config helpers
+// ============================================================
+
+// StringConfig reads a string entry of a backend's Initialize config,
+// def if it is missing or blank
+func StringConfig(config map[string]interface{}, key, def string) string {
+	if v, ok := config[key].(string); ok && strings.TrimSpace(v) != "" {
+		return v
+	}
+	return def
+}
+
+// IntConfig reads an integer entry; YAML yields int, JSON float64
+func IntConfig(config map[string]interface{}, key string, def int) int {
+	switch v := config[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return def
+}
+
+// StringsConfig reads a list entry, skipping empty items
+func StringsConfig(config map[string]interface{}, key string) []string {
+	var values []string
+	switch list := config[key].(type) {
+	case []string:
+		for _, v := range list {
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+	case []interface{}:
+		for _, v := range list {
+			if s := fmt.Sprint(v); v != nil && s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
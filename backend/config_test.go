@@ -0,0 +1,38 @@
+package backend_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+func TestConfigHelpers(t *testing.T) {
+	config := map[string]interface{}{
+		"name":    "db",
+		"blank":   "  ",
+		"yaml":    5,
+		"json":    float64(7),
+		"list":    []interface{}{"a", "", "b"},
+		"strings": []string{"c"},
+	}
+
+	if got := backend.StringConfig(config, "name", "x"); got != "db" {
+		t.Errorf("StringConfig(name) = %q", got)
+	}
+	if got := backend.StringConfig(config, "blank", "x"); got != "x" {
+		t.Errorf("StringConfig(blank) = %q, want the default", got)
+	}
+	if got := backend.IntConfig(config, "yaml", 0) + backend.IntConfig(config, "json", 0) + backend.IntConfig(config, "missing", 1); got != 13 {
+		t.Errorf("IntConfig sum = %d, want 13", got)
+	}
+	if got := backend.StringsConfig(config, "list"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("StringsConfig(list) = %v", got)
+	}
+	if got := backend.StringsConfig(config, "strings"); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("StringsConfig(strings) = %v", got)
+	}
+	if got := backend.StringsConfig(config, "missing"); got != nil {
+		t.Errorf("StringsConfig(missing) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,163 @@
+package backend
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// Content is a single item of a tool result's content list
+//
+// Handlers return a *ToolResult (or a Content, or a []Content) to control
+// the content sent to the client. Any other result is sent as JSON text.
+type Content interface {
+	// ContentType is the MCP content type ("text", "image", ...)
+	ContentType() string
+}
+
+// TextContent is plain text
+type TextContent struct {
+	Text        string                 `json:"text"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// ImageContent is an image, base64-encoded
+type ImageContent struct {
+	Data        string                 `json:"data"`
+	MimeType    string                 `json:"mimeType"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// AudioContent is audio, base64-encoded
+type AudioContent struct {
+	Data        string                 `json:"data"`
+	MimeType    string                 `json:"mimeType"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// ResourceContents is the body of an embedded resource
+// Exactly one of Text or Blob (base64) should be set.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// EmbeddedResource embeds the contents of a resource in the result
+type EmbeddedResource struct {
+	Resource    ResourceContents       `json:"resource"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// ResourceLink points to a resource the client can fetch separately
+type ResourceLink struct {
+	URI         string                 `json:"uri"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	MimeType    string                 `json:"mimeType,omitempty"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+func (TextContent) ContentType() string      { return "text" }
+func (ImageContent) ContentType() string     { return "image" }
+func (AudioContent) ContentType() string     { return "audio" }
+func (EmbeddedResource) ContentType() string { return "resource" }
+func (ResourceLink) ContentType() string     { return "resource_link" }
+
+// MarshalContent encodes a content item with its "type" field
+func MarshalContent(c Content) ([]byte, error) {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], _ = json.Marshal(c.ContentType())
+	return json.Marshal(fields)
+}
+
+// ============================================================
+// Tool results
+// ============================================================
+
+// ToolResult is a tool result made of explicit content items
+type ToolResult struct {
+	Content []Content
+
+	// Structured is sent as structuredContent when the tool declares an output schema
+	Structured map[string]interface{}
+}
+
+// NewResult creates a result from content items
+func NewResult(content ...Content) *ToolResult {
+	return &ToolResult{Content: content}
+}
+
+// Add appends content items to the result
+func (r *ToolResult) Add(content ...Content) *ToolResult {
+	r.Content = append(r.Content, content...)
+	return r
+}
+
+// NewTextResult creates a result with a single text item
+func NewTextResult(text string) *ToolResult {
+	return NewResult(TextContent{Text: text})
+}
+
+// NewImageResult creates a result with a single image
+func NewImageResult(data []byte, mimeType string) *ToolResult {
+	return NewResult(NewImageContent(data, mimeType))
+}
+
+// NewAudioResult creates a result with a single audio clip
+func NewAudioResult(data []byte, mimeType string) *ToolResult {
+	return NewResult(NewAudioContent(data, mimeType))
+}
+
+// NewResourceLinkResult creates a result with a single resource link
+func NewResourceLinkResult(uri, name string) *ToolResult {
+	return NewResult(ResourceLink{URI: uri, Name: name})
+}
+
+// NewImageContent base64-encodes image data
+func NewImageContent(data []byte, mimeType string) ImageContent {
+	return ImageContent{Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// NewAudioContent base64-encodes audio data
+func NewAudioContent(data []byte, mimeType string) AudioContent {
+	return AudioContent{Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// NewTextResource embeds a text resource
+func NewTextResource(uri, mimeType, text string) EmbeddedResource {
+	return EmbeddedResource{Resource: ResourceContents{URI: uri, MimeType: mimeType, Text: text}}
+}
+
+// NewBlobResource embeds a binary resource, base64-encoded
+func NewBlobResource(uri, mimeType string, data []byte) EmbeddedResource {
+	return EmbeddedResource{Resource: ResourceContents{
+		URI:      uri,
+		MimeType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}}
+}
+
+// AsToolResult reports whether a handler result carries explicit content
+func AsToolResult(result interface{}) (*ToolResult, bool) {
+	switch r := result.(type) {
+	case *ToolResult:
+		return r, r != nil
+	case ToolResult:
+		return &r, true
+	case Content:
+		return NewResult(r), true
+	case []Content:
+		return NewResult(r...), true
+	default:
+		return nil, false
+	}
+}
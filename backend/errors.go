@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotInitialized is returned by the tools of a backend called before
+// its Initialize succeeded
+var ErrNotInitialized = errors.New("backend not initialized")
+
+// ToolError reports that a tool ran but failed, as opposed to a protocol failure
+//
+// The protocol handler returns it as a successful tools/call response with
+// isError set, so the model sees the failure and can react (retry, pick
+// another tool) instead of the client treating it as fatal. Any other error
+// returned by a handler is reported as a JSON-RPC internal error.
+//
+// Example:
+//
+//	if resp.StatusCode == http.StatusNotFound {
+//	    return nil, backend.NewToolError("repository %s not found", repo)
+//	}
+type ToolError struct {
+	// Message is shown to the client when Content is empty
+	Message string
+
+	// Content optionally replaces the default text content
+	Content []Content
+
+	// Err is the underlying cause, if any (not sent to the client)
+	Err error
+}
+
+// NewToolError creates a tool error with a formatted message
+func NewToolError(format string, args ...interface{}) *ToolError {
+	return &ToolError{Message: fmt.Sprintf(format, args...)}
+}
+
+// WrapToolError turns err into a tool error with the given message
+func WrapToolError(err error, message string) *ToolError {
+	return &ToolError{Message: message, Err: err}
+}
+
+func (e *ToolError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// ResultContent returns the content sent to the client
+func (e *ToolError) ResultContent() []Content {
+	if len(e.Content) > 0 {
+		return e.Content
+	}
+	return []Content{TextContent{Text: e.Message}}
+}
+
+// AsToolError reports whether err is (or wraps) a *ToolError
+func AsToolError(err error) (*ToolError, bool) {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,433 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// Limits of file reads
+const (
+	// DefaultMaxReadSize is the most bytes one file_read returns unless
+	// the config's max_read_size says otherwise; file_read_stream reads
+	// larger files
+	DefaultMaxReadSize = 1024 * 1024
+
+	// DefaultChunkSize is file_read_stream's chunk size without chunk_size
+	DefaultChunkSize = 64 * 1024
+)
+
+// Content encodings
+const (
+	EncodingAuto   = "auto"
+	EncodingUTF8   = "utf-8"
+	EncodingBase64 = "base64"
+)
+
+// Write modes
+const (
+	ModeOverwrite = "overwrite"
+	ModeAppend    = "append"
+	ModePatch     = "patch"
+)
+
+type readArgs struct {
+	Path     string `json:"path" description:"Path to the file"`
+	Offset   int64  `json:"offset,omitempty" jsonschema:"minimum=0" description:"Byte offset to start reading at (default 0)"`
+	Length   int64  `json:"length,omitempty" jsonschema:"minimum=1" description:"Most bytes to read (default: to the end of the file, within the read limit)"`
+	Encoding string `json:"encoding,omitempty" jsonschema:"enum=auto|utf-8|base64" description:"utf-8 text, base64 or auto: text unless the bytes read are binary (default)"`
+}
+
+type streamArgs struct {
+	Path      string `json:"path" description:"Path to the file"`
+	Offset    int64  `json:"offset,omitempty" jsonschema:"minimum=0" description:"Byte offset to start reading at (default 0)"`
+	Length    int64  `json:"length,omitempty" jsonschema:"minimum=1" description:"Most bytes to read (default: to the end of the file)"`
+	ChunkSize int    `json:"chunk_size,omitempty" jsonschema:"minimum=1,maximum=1048576" description:"Bytes per chunk (default 65536)"`
+	Encoding  string `json:"encoding,omitempty" jsonschema:"enum=auto|utf-8|base64" description:"utf-8 text, base64 or auto: base64 when the file starts with binary content (default)"`
+}
+
+type writeArgs struct {
+	Path           string `json:"path" description:"Path to the file"`
+	Content        string `json:"content" description:"Content to write, encoded as encoding says"`
+	Encoding       string `json:"encoding,omitempty" jsonschema:"enum=utf-8|base64" description:"Encoding of content (default utf-8)"`
+	Mode           string `json:"mode,omitempty" jsonschema:"enum=overwrite|append|patch" description:"overwrite the file (default), append to it, or patch: write over its bytes from offset on"`
+	Offset         int64  `json:"offset,omitempty" jsonschema:"minimum=0" description:"Byte offset patch mode writes at, at most the file's size"`
+	ExpectedSHA256 string `json:"expected_sha256,omitempty" description:"SHA-256 (hex) the file must still have, as returned by file_read: the write fails if it changed since"`
+	DryRun         bool   `json:"dry_run,omitempty" description:"Report what would change without writing"`
+}
+
+// Chunk is a file_read_stream result
+type Chunk struct {
+	Offset   int64  `json:"offset"`
+	Data     string `json:"data"`
+	Encoding string `json:"encoding"`
+}
+
+// handleFileRead reads a file or a byte range of it
+func (b *Backend) handleFileRead(ctx context.Context, in readArgs) (map[string]interface{}, error) {
+	fullPath, info, err := b.openable(ctx, in.Path)
+	if err != nil {
+		return nil, err
+	}
+	if in.Offset > info.Size() {
+		return nil, backend.NewArgumentError("file_read", "offset", fmt.Sprintf("offset %d is past the end of the file (%d bytes)", in.Offset, info.Size()))
+	}
+
+	length := info.Size() - in.Offset
+	if in.Length > 0 && in.Length < length {
+		length = in.Length
+	}
+	truncated := false
+	if length > b.maxReadSize {
+		length, truncated = b.maxReadSize, true
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+	data := make([]byte, length)
+	n, err := f.ReadAt(data, in.Offset)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	data = data[:n]
+
+	encoding, content, err := encode("file_read", data, in.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := fileSHA256(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	relPath, _ := b.security.GetRelativePath(fullPath)
+
+	result := map[string]interface{}{
+		"path":        relPath,
+		"content":     content,
+		"encoding":    encoding,
+		"offset":      in.Offset,
+		"length":      n,
+		"size":        info.Size(),
+		"eof":         in.Offset+int64(n) >= info.Size(),
+		"sha256":      sum,
+		"modified":    info.ModTime().Format(time.RFC3339),
+		"permissions": info.Mode().String(),
+	}
+	if truncated {
+		result["truncated"] = true
+		result["next_offset"] = in.Offset + int64(n)
+	}
+	return result, nil
+}
+
+// handleFileReadStream streams a file in chunks
+func (b *Backend) handleFileReadStream(ctx context.Context, in streamArgs, emit backend.StreamingEmitter) error {
+	fullPath, info, err := b.openable(ctx, in.Path)
+	if err != nil {
+		return err
+	}
+	if in.Offset > info.Size() {
+		return backend.NewArgumentError("file_read_stream", "offset", fmt.Sprintf("offset %d is past the end of the file (%d bytes)", in.Offset, info.Size()))
+	}
+	end := info.Size()
+	if in.Length > 0 && in.Offset+in.Length < end {
+		end = in.Offset + in.Length
+	}
+	chunkSize := in.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	encoding := in.Encoding
+	if encoding == "" || encoding == EncodingAuto {
+		head := make([]byte, 512)
+		n, _ := f.ReadAt(head, in.Offset)
+		encoding = EncodingUTF8
+		if bytes.IndexByte(head[:n], 0) >= 0 {
+			encoding = EncodingBase64
+		}
+	}
+
+	r := io.NewSectionReader(f, in.Offset, end-in.Offset)
+	buf := make([]byte, chunkSize)
+	var carry []byte // an incomplete UTF-8 sequence ending the last chunk
+	offset, chunks := in.Offset, 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, readErr := io.ReadFull(r, buf[len(carry):])
+		data := append(carry, buf[len(carry):len(carry)+n]...)
+		carry = nil
+		last := readErr != nil
+
+		if encoding == EncodingUTF8 && !last {
+			// Hold back a rune split by the chunk boundary
+			cut := len(data)
+			for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+				if utf8.RuneStart(data[len(data)-i]) {
+					if !utf8.FullRune(data[len(data)-i:]) {
+						cut = len(data) - i
+					}
+					break
+				}
+			}
+			carry = append([]byte(nil), data[cut:]...)
+			data = data[:cut]
+		}
+
+		if len(data) > 0 {
+			_, content, err := encode("file_read_stream", data, encoding)
+			if err != nil {
+				return err
+			}
+			if err := emit.EmitData(Chunk{Offset: offset, Data: content, Encoding: encoding}); err != nil {
+				return err
+			}
+			offset += int64(len(data))
+			chunks++
+			if err := emit.EmitProgress(offset-in.Offset, end-in.Offset, ""); err != nil {
+				return err
+			}
+		}
+		if last {
+			if readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+				return fmt.Errorf("failed to read file: %w", readErr)
+			}
+			return emit.EmitProgress(offset-in.Offset, end-in.Offset,
+				fmt.Sprintf("%d bytes in %d chunks", offset-in.Offset, chunks))
+		}
+		copy(buf, carry)
+	}
+}
+
+// handleFileWrite overwrites, appends to or patches a file
+// Overwrites and patches replace the file atomically: readers see the old
+// content or the new, never a partial write.
+func (b *Backend) handleFileWrite(ctx context.Context, in writeArgs) (map[string]interface{}, error) {
+	if in.DryRun {
+		ctx = security.DryRun(ctx)
+	}
+	fullPath, err := b.security.Authorize(ctx, security.OpWrite, in.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte(in.Content)
+	if in.Encoding == EncodingBase64 {
+		if data, err = base64.StdEncoding.DecodeString(in.Content); err != nil {
+			return nil, backend.NewArgumentError("file_write", "content", "invalid base64: "+err.Error())
+		}
+	}
+	mode := in.Mode
+	if mode == "" {
+		mode = ModeOverwrite
+	}
+
+	// Writes are serialized, so that nothing changes the file between the
+	// hash check and the write
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+
+	var size int64
+	info, err := os.Stat(fullPath)
+	switch {
+	case err == nil && info.IsDir():
+		return nil, backend.NewArgumentError("file_write", "path", "path is a directory: "+in.Path)
+	case err == nil:
+		size = info.Size()
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	case mode == ModePatch || in.ExpectedSHA256 != "":
+		return nil, mcperr.NotFound("file not found: %s", in.Path)
+	}
+
+	if in.ExpectedSHA256 != "" {
+		sum, err := fileSHA256(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write file: %w", err)
+		}
+		if !strings.EqualFold(sum, in.ExpectedSHA256) {
+			return nil, backend.NewToolError("%s changed since it was read (sha256 %s, expected %s); read it again before writing", in.Path, sum, in.ExpectedSHA256)
+		}
+	}
+
+	final := int64(len(data))
+	switch mode {
+	case ModeAppend:
+		final += size
+	case ModePatch:
+		if in.Offset > size {
+			return nil, backend.NewArgumentError("file_write", "offset", fmt.Sprintf("offset %d is past the end of the file (%d bytes)", in.Offset, size))
+		}
+		final = max(size, in.Offset+int64(len(data)))
+	}
+	if err := b.security.Check(ctx, security.Request{Op: security.OpWrite, Path: in.Path, Size: final}); err != nil {
+		return nil, err
+	}
+	relPath, _ := b.security.GetRelativePath(fullPath)
+
+	if in.DryRun {
+		return map[string]interface{}{
+			"success":  true,
+			"dry_run":  true,
+			"path":     relPath,
+			"mode":     mode,
+			"exists":   info != nil,
+			"old_size": size,
+			"size":     final,
+			"message":  fmt.Sprintf("Would write %d bytes to %s (%s)", len(data), relPath, mode),
+		}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create parent directories: %w", err)
+	}
+	if err := writeFile(fullPath, mode, in.Offset, data); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	sum, err := fileSHA256(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	return map[string]interface{}{
+		"success": true,
+		"path":    relPath,
+		"mode":    mode,
+		"written": len(data),
+		"size":    final,
+		"sha256":  sum,
+		"message": fmt.Sprintf("File written: %s", relPath),
+	}, nil
+}
+
+// openable checks that path names a readable file, returning its
+// resolved path and info
+func (b *Backend) openable(ctx context.Context, path string) (string, os.FileInfo, error) {
+	if b.security == nil {
+		return "", nil, errors.New("filesystem: backend not initialized")
+	}
+	fullPath, err := b.security.Authorize(ctx, security.OpRead, path)
+	if err != nil {
+		return "", nil, err
+	}
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return "", nil, mcperr.NotFound("file not found: %s", path)
+	}
+	if info.IsDir() {
+		return "", nil, backend.NewArgumentError("file_read", "path", "path is a directory, not a file: "+path)
+	}
+	return fullPath, info, nil
+}
+
+// writeFile writes data to name as mode says
+func writeFile(name, mode string, offset int64, data []byte) error {
+	switch mode {
+	case ModeAppend:
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return errors.Join(err, f.Close())
+	case ModePatch:
+		// Files are within max_file_size, so patching a copy in memory is fine
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if end := offset + int64(len(data)); end > int64(len(content)) {
+			content = append(content, make([]byte, end-int64(len(content)))...)
+		}
+		copy(content[offset:], data)
+		return atomicWrite(name, content)
+	default:
+		return atomicWrite(name, data)
+	}
+}
+
+// atomicWrite replaces name with data through a temporary file in the
+// same directory, keeping the permissions of the file it replaces
+func atomicWrite(name string, data []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(name); err == nil {
+		perm = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err = errors.Join(err, tmp.Close()); err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// encode returns data as text or base64, as encoding asks; auto picks
+// text for valid UTF-8 without NUL bytes
+func encode(tool string, data []byte, encoding string) (string, string, error) {
+	text := utf8.Valid(data) && bytes.IndexByte(data, 0) < 0
+	switch encoding {
+	case EncodingBase64:
+		return EncodingBase64, base64.StdEncoding.EncodeToString(data), nil
+	case EncodingUTF8:
+		if !text {
+			return "", "", backend.NewToolError("the content is binary or not valid UTF-8; call %s with encoding base64", tool)
+		}
+		return EncodingUTF8, string(data), nil
+	default:
+		if text {
+			return EncodingUTF8, string(data), nil
+		}
+		return EncodingBase64, base64.StdEncoding.EncodeToString(data), nil
+	}
+}
+
+// fileSHA256 returns the hex SHA-256 of a file's content
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
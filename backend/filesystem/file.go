@@ -0,0 +1,284 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// handleFileCreate creates a new file
+func (b *Backend) handleFileCreate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path := args["path"].(string)
+	content := ""
+	if c, ok := args["content"].(string); ok {
+		content = c
+	}
+
+	fullPath, err := b.security.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.security.ValidateFileOperation(path, security.OpWrite); err != nil {
+		return nil, err
+	}
+
+	if err := b.security.Check(ctx, security.Request{Op: security.OpWrite, Path: path, Size: int64(len(content)), Files: 1}); err != nil {
+		return nil, err
+	}
+
+	// Check if file already exists
+	if _, err := os.Stat(fullPath); err == nil {
+		return nil, fmt.Errorf("file already exists: %s", path)
+	}
+
+	// Create parent directories
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create parent directories: %w", err)
+	}
+
+	// Write file
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	relPath, _ := b.security.GetRelativePath(fullPath)
+
+	return map[string]interface{}{
+		"success": true,
+		"path":    relPath,
+		"size":    len(content),
+		"message": fmt.Sprintf("File created: %s", relPath),
+	}, nil
+}
+
+// handleFileUpdate appends content to file
+func (b *Backend) handleFileUpdate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path := args["path"].(string)
+	content := args["content"].(string)
+
+	fullPath, err := b.security.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.security.ValidateFileOperation(path, security.OpWrite); err != nil {
+		return nil, err
+	}
+
+	// Read existing content
+	existing, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing file: %w", err)
+	}
+
+	newContent := string(existing) + content
+
+	if err := b.security.Check(ctx, security.Request{Op: security.OpWrite, Path: path, Size: int64(len(newContent))}); err != nil {
+		return nil, err
+	}
+
+	if err := atomicWrite(fullPath, []byte(newContent)); err != nil {
+		return nil, fmt.Errorf("failed to update file: %w", err)
+	}
+
+	relPath, _ := b.security.GetRelativePath(fullPath)
+
+	return map[string]interface{}{
+		"success":  true,
+		"path":     relPath,
+		"size":     len(newContent),
+		"appended": len(content),
+		"message":  fmt.Sprintf("File updated: %s", relPath),
+	}, nil
+}
+
+// handleFileDelete deletes a file, moving it to the trash when the trash
+// is enabled
+func (b *Backend) handleFileDelete(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path := args["path"].(string)
+	dryRun, _ := args["dry_run"].(bool)
+	if dryRun {
+		ctx = security.DryRun(ctx)
+	}
+
+	fullPath, err := b.security.Authorize(ctx, security.OpDelete, path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+
+	if info.IsDir() {
+		return nil, fmt.Errorf("path is a directory, use folder_delete: %s", path)
+	}
+
+	relPath, _ := b.security.GetRelativePath(fullPath)
+
+	if dryRun {
+		return map[string]interface{}{
+			"success": true,
+			"dry_run": true,
+			"path":    relPath,
+			"size":    info.Size(),
+			"trash":   b.trash != nil,
+			"message": fmt.Sprintf("Would delete file: %s", relPath),
+		}, nil
+	}
+
+	if b.trash != nil {
+		entry, err := b.moveToTrash(fullPath, info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move file to trash: %w", err)
+		}
+		return map[string]interface{}{
+			"success":  true,
+			"path":     relPath,
+			"trash_id": entry.ID,
+			"message":  fmt.Sprintf("File moved to trash: %s (restore with file_restore id %s)", relPath, entry.ID),
+		}, nil
+	}
+
+	if err := os.Remove(fullPath); err != nil {
+		return nil, fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"path":    relPath,
+		"message": fmt.Sprintf("File deleted: %s", relPath),
+	}, nil
+}
+
+// handleFileCopy copies a file
+func (b *Backend) handleFileCopy(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	srcPath := args["source"].(string)
+	dstPath := args["destination"].(string)
+
+	srcFull, err := b.security.ValidatePath(srcPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source path: %w", err)
+	}
+
+	dstFull, err := b.security.ValidatePath(dstPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination path: %w", err)
+	}
+
+	if err := b.security.ValidateFileOperation(srcPath, security.OpRead); err != nil {
+		return nil, err
+	}
+
+	if err := b.security.ValidateFileOperation(dstPath, security.OpWrite); err != nil {
+		return nil, err
+	}
+
+	// Open source
+	src, err := os.Open(srcFull)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source: %w", err)
+	}
+	defer src.Close()
+
+	if info, err := src.Stat(); err == nil {
+		if err := b.security.Check(ctx, security.Request{Op: security.OpWrite, Path: dstPath, Size: info.Size(), Files: 1}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create destination
+	if err := os.MkdirAll(filepath.Dir(dstFull), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	dst, err := os.Create(dstFull)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination: %w", err)
+	}
+	defer dst.Close()
+
+	// Copy
+	written, err := io.Copy(dst, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	srcRel, _ := b.security.GetRelativePath(srcFull)
+	dstRel, _ := b.security.GetRelativePath(dstFull)
+
+	return map[string]interface{}{
+		"success":     true,
+		"source":      srcRel,
+		"destination": dstRel,
+		"size":        written,
+		"message":     fmt.Sprintf("File copied: %s → %s", srcRel, dstRel),
+	}, nil
+}
+
+// handleFileShowContent shows file content with metadata
+func (b *Backend) handleFileShowContent(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path := args["path"].(string)
+
+	fullPath, err := b.security.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.security.ValidateFileOperation(path, security.OpRead); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("file not found: %s", path)
+	}
+
+	if info.IsDir() {
+		return nil, fmt.Errorf("path is a directory: %s", path)
+	}
+
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	relPath, _ := b.security.GetRelativePath(fullPath)
+
+	// Count lines
+	lines := strings.Count(string(content), "\n") + 1
+
+	// Detect if binary
+	isBinary := false
+	for _, b := range content[:min(512, len(content))] {
+		if b == 0 {
+			isBinary = true
+			break
+		}
+	}
+
+	result := map[string]interface{}{
+		"path":        relPath,
+		"size":        len(content),
+		"lines":       lines,
+		"modified":    info.ModTime().Format(time.RFC3339),
+		"permissions": info.Mode().String(),
+		"is_binary":   isBinary,
+	}
+
+	if !isBinary {
+		result["content"] = string(content)
+	} else {
+		result["message"] = "Binary file (content not displayed)"
+	}
+
+	return result, nil
+}
@@ -0,0 +1,399 @@
+// Package filesystem is a sandboxed file and folder backend
+//
+// Tools:
+//
+//	file_create, file_read, file_write, file_update, file_delete,
+//	file_copy, file_show_content      single-file operations
+//	file_read_stream                  a large file in chunks, streamed
+//	file_restore, file_trash_list     deleted entries in the trash
+//	workspace_stats                   usage and remaining quota
+//	file_search                       text or regex search, matches streamed,
+//	                                  then a summary
+//	file_watch                        changes below a path, streamed
+//	folder_create, folder_delete, folder_rename, folder_copy,
+//	folder_move                       directory operations
+//	folder_list                       directory entries, streamed
+//
+// Every path is relative to the workspace root and goes through a
+// security.Manager: traversal and symlink escapes are rejected, and the
+// read-only mode, size limits and extension and path rules of the config
+// apply. file_search and folder_list walk the tree incrementally, emitting
+// each match or entry as it is found along with progress, and take glob,
+// regex and max_depth filters. file_watch streams the changes below a path
+// for a while; clients that would rather be told as long as they are
+// connected subscribe to the file:// URI of a file or directory with
+// resources/subscribe. file_write replaces files atomically; with the
+// trash enabled, deletes move entries to a hidden trash directory that
+// file_restore brings them back from. Destructive operations take dry_run,
+// which runs every check and reports what would change. Quotas bound the
+// workspace's bytes, files and operations per minute; denials carry the
+// current usage, which workspace_stats also reports. Importing the
+// package registers the backend as "filesystem" (the framework does so):
+//
+//	# config.yaml
+//	backend:
+//	  type: filesystem
+//	  config:
+//	    workspace_root: ./workspace
+//	    max_file_size: 10485760
+//	    max_read_size: 1048576
+//	    read_only: false
+//	    blocked_extensions: [".exe", ".sh"]
+//	    deny_paths: [".git", "secrets/*"]
+//	    trash: true
+//	    max_total_bytes: 104857600
+//	    max_ops_per_minute: 600
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+	"github.com/SaherElMasry/go-mcp-framework/txn"
+)
+
+// DefaultRoot is the workspace root of configs without workspace_root
+const DefaultRoot = "./workspace"
+
+func init() {
+	backend.Register("filesystem", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves the files below one workspace root
+type Backend struct {
+	*backend.BaseBackend
+
+	logger      *slog.Logger
+	security    *security.Manager
+	maxReadSize int64
+
+	// trash receives deleted entries; nil deletes for good
+	trash *trash
+
+	// writeMu serializes file_write, so that expected_sha256 checks hold
+	// until the write
+	writeMu sync.Mutex
+
+	// closed ends the watches on Close
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates a filesystem backend; Initialize sets its root
+func New() *Backend {
+	b := &Backend{
+		BaseBackend: backend.NewBaseBackend("filesystem"),
+		logger:      slog.Default(),
+		closed:      make(chan struct{}),
+	}
+	b.registerTools()
+	return b
+}
+
+// Initialize creates the security manager and the workspace root
+//
+// Config entries:
+//
+//	workspace_root      directory tools are confined to (default "./workspace")
+//	max_file_size       largest file written, in bytes (default 10MB)
+//	max_read_size       most bytes one file_read returns (default 1MB)
+//	read_only           reject every operation but reads
+//	allowed_extensions  the only extensions permitted, e.g. [".md", ".txt"]
+//	blocked_extensions  extensions refused
+//	deny_paths          glob patterns that may not be touched
+//	allow_symlinks      follow symlinks that stay inside the root
+//	max_total_bytes     quota on the bytes below the root
+//	max_files           quota on the files below the root
+//	max_ops_per_minute  operations budget: paths authorized per minute
+//	trash               move deleted files and directories to the trash,
+//	                    for file_restore to bring back
+//	trash_dir           trash directory below the root, hidden from every
+//	                    tool (default ".trash")
+//	trash_retention_hours  how long deleted entries are kept (default 168)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	root := backend.StringConfig(config, "workspace_root", DefaultRoot)
+	if strings.HasPrefix(root, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("filesystem: %w", err)
+		}
+		root = filepath.Join(home, root[2:])
+	}
+
+	secConfig := security.Config{
+		Root:              root,
+		MaxFileSize:       int64(backend.IntConfig(config, "max_file_size", 0)),
+		AllowedExtensions: backend.StringsConfig(config, "allowed_extensions"),
+		BlockedExtensions: backend.StringsConfig(config, "blocked_extensions"),
+		DenyPaths:         backend.StringsConfig(config, "deny_paths"),
+		MaxTotalBytes:     int64(backend.IntConfig(config, "max_total_bytes", 0)),
+		MaxFiles:          backend.IntConfig(config, "max_files", 0),
+		MaxOpsPerMinute:   backend.IntConfig(config, "max_ops_per_minute", 0),
+	}
+	secConfig.ReadOnly, _ = config["read_only"].(bool)
+	secConfig.AllowSymlinks, _ = config["allow_symlinks"].(bool)
+
+	manager, err := security.NewManager(secConfig)
+	if err != nil {
+		return err
+	}
+	if err := manager.EnsureRoot(); err != nil {
+		return fmt.Errorf("filesystem: failed to create workspace: %w", err)
+	}
+	b.security = manager
+	b.maxReadSize = int64(backend.IntConfig(config, "max_read_size", DefaultMaxReadSize))
+	if b.maxReadSize <= 0 {
+		b.maxReadSize = DefaultMaxReadSize
+	}
+	if err := b.configureTrash(config); err != nil {
+		return err
+	}
+
+	b.logger.Info("filesystem backend initialized",
+		"root", manager.Root(),
+		"read_only", secConfig.ReadOnly)
+	return nil
+}
+
+// Close stops the backend's watches
+func (b *Backend) Close() error {
+	b.closeOnce.Do(func() { close(b.closed) })
+	return b.BaseBackend.Close()
+}
+
+// Security returns the manager checking the backend's paths, to add
+// policies to; nil before Initialize
+func (b *Backend) Security() *security.Manager {
+	return b.security
+}
+
+// registerTools registers all filesystem tools
+func (b *Backend) registerTools() {
+	// File operations
+	b.RegisterTool(
+		backend.NewTool("file_create").
+			Description("Create a new file with optional content").
+			StringParam("path", "Path to the new file", true).
+			StringParam("content", "Initial file content (optional)", false).
+			Build(),
+		b.handleFileCreate,
+	)
+
+	backend.RegisterTypedTool(b, backend.NewTool("file_read").
+		Description("Read a file, or a byte range of it with offset and length. Binary content comes back base64-encoded; "+
+			"reads stop at the read limit, with next_offset saying where to continue").
+		ParamsFromStruct(readArgs{}).
+		Build(), b.handleFileRead)
+
+	readStream := backend.NewTool("file_read_stream").
+		Description("Read a large file in chunks, streaming each chunk as it is read").
+		ParamsFromStruct(streamArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(readStream, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[streamArgs](readStream, args)
+		if err != nil {
+			return err
+		}
+		return b.handleFileReadStream(ctx, in, emit)
+	})
+
+	backend.RegisterTypedTool(b, backend.NewTool("file_write").
+		Description("Write a file: overwrite it, append to it, or patch its bytes from an offset on. "+
+			"With expected_sha256 the write fails if the file changed since it was read").
+		ParamsFromStruct(writeArgs{}).
+		NonCacheable().
+		Build(), b.handleFileWrite)
+
+	b.RegisterTool(
+		backend.NewTool("file_update").
+			Description("Append content to an existing file").
+			StringParam("path", "Path to the file", true).
+			StringParam("content", "Content to append", true).
+			Build(),
+		b.handleFileUpdate,
+	)
+
+	b.RegisterTool(
+		backend.NewTool("file_delete").
+			Description("Delete a file; with the trash enabled it can be brought back with file_restore").
+			StringParam("path", "Path to the file", true).
+			BoolParam("dry_run", "Report what would be deleted without deleting it", false, boolPtr(false)).
+			NonCacheable().
+			Build(),
+		b.handleFileDelete,
+	)
+
+	backend.RegisterTypedTool(b, backend.NewTool("file_restore").
+		Description("Restore a deleted file or directory from the trash, by the trash ID its delete returned").
+		ParamsFromStruct(restoreArgs{}).
+		NonCacheable().
+		Build(), b.handleFileRestore)
+
+	backend.RegisterTypedTool(b, backend.NewTool("workspace_stats").
+		Description("Show the workspace's bytes, files and operations in the last minute against its quotas, "+
+			"to check the remaining budget before large operations").
+		ParamsFromStruct(noArgs{}).
+		NonCacheable().
+		Build(), b.handleWorkspaceStats)
+
+	backend.RegisterTypedTool(b, backend.NewTool("file_trash_list").
+		Description("List the deleted files and directories in the trash, newest first").
+		ParamsFromStruct(trashListArgs{}).
+		NonCacheable().
+		Build(), b.handleTrashList)
+
+	b.RegisterTool(
+		backend.NewTool("file_copy").
+			Description("Copy a file to a new location").
+			StringParam("source", "Source file path", true).
+			StringParam("destination", "Destination file path", true).
+			Build(),
+		b.handleFileCopy,
+	)
+
+	search := backend.NewTool("file_search").
+		Description("Search files for text or a regular expression, streaming each matching line as it is found; "+
+			"the result counts the matches and searched files").
+		ParamsFromStruct(searchArgs{}).
+		Streaming(true).
+		BatchEvents(searchBatchSize, 0).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(search, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[searchArgs](search, args)
+		if err != nil {
+			return err
+		}
+		return b.handleFileSearch(ctx, in, emit)
+	})
+
+	watch := backend.NewTool("file_watch").
+		Description("Watch a file or directory and stream its changes (create, write, remove, rename, chmod) as they happen, " +
+			"for a number of seconds or until max_events changes").
+		ParamsFromStruct(watchArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(watch, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[watchArgs](watch, args)
+		if err != nil {
+			return err
+		}
+		return b.handleFileWatch(ctx, in, emit)
+	})
+
+	b.RegisterTool(
+		backend.NewTool("file_show_content").
+			Description("Show file content with metadata").
+			StringParam("path", "Path to the file", true).
+			Build(),
+		b.handleFileShowContent,
+	)
+
+	// Folder operations
+	b.RegisterTool(
+		backend.NewTool("folder_create").
+			Description("Create a new directory").
+			StringParam("path", "Path to the new directory", true).
+			Build(),
+		b.handleFolderCreate,
+	)
+
+	b.RegisterTool(
+		backend.NewTool("folder_delete").
+			Description("Delete a directory; with the trash enabled it can be brought back with file_restore").
+			StringParam("path", "Path to the directory", true).
+			BoolParam("recursive", "Delete recursively", false, boolPtr(false)).
+			BoolParam("dry_run", "Report what would be deleted without deleting it", false, boolPtr(false)).
+			NonCacheable().
+			Build(),
+		b.handleFolderDelete,
+	)
+
+	b.RegisterTool(
+		backend.NewTool("folder_rename").
+			Description("Rename a directory").
+			StringParam("old_path", "Current directory path", true).
+			StringParam("new_path", "New directory path", true).
+			Build(),
+		b.handleFolderRename,
+	)
+
+	b.RegisterTool(
+		backend.NewTool("folder_copy").
+			Description("Copy a directory recursively").
+			StringParam("source", "Source directory path", true).
+			StringParam("destination", "Destination directory path", true).
+			Build(),
+		txn.Wrap(b.handleFolderCopy),
+	)
+
+	b.RegisterTool(
+		backend.NewTool("folder_move").
+			Description("Move a directory").
+			StringParam("source", "Source directory path", true).
+			StringParam("destination", "Destination directory path", true).
+			Build(),
+		b.handleFolderMove,
+	)
+
+	list := backend.NewTool("folder_list").
+		Description("List the entries of a directory, streaming them as they are read").
+		ParamsFromStruct(listArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(list, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[listArgs](list, args)
+		if err != nil {
+			return err
+		}
+		return b.handleFolderList(ctx, in, emit)
+	})
+}
+
+// noArgs is the arguments of tools without parameters
+type noArgs struct{}
+
+// handleWorkspaceStats reports the workspace's usage and what remains of
+// its quotas
+func (b *Backend) handleWorkspaceStats(ctx context.Context, _ noArgs) (map[string]interface{}, error) {
+	if b.security == nil {
+		return nil, errors.New("filesystem: backend not initialized")
+	}
+	usage := b.security.Usage()
+	result := map[string]interface{}{
+		"bytes":           usage.Bytes,
+		"files":           usage.Files,
+		"ops_last_minute": usage.Ops,
+	}
+	if usage.MaxBytes > 0 {
+		result["max_total_bytes"] = usage.MaxBytes
+		result["remaining_bytes"] = max(usage.MaxBytes-usage.Bytes, 0)
+	}
+	if usage.MaxFiles > 0 {
+		result["max_files"] = usage.MaxFiles
+		result["remaining_files"] = max(usage.MaxFiles-usage.Files, 0)
+	}
+	if usage.MaxOpsPerMinute > 0 {
+		result["max_ops_per_minute"] = usage.MaxOpsPerMinute
+		result["remaining_ops"] = max(usage.MaxOpsPerMinute-usage.Ops, 0)
+	}
+	return result, nil
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
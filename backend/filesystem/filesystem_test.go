@@ -0,0 +1,583 @@
+package filesystem_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backend/filesystem"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+type captureEmitter struct {
+	ctx      context.Context
+	data     []interface{}
+	progress []string
+	logs     []string
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.data = append(e.data, data)
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error {
+	e.progress = append(e.progress, message)
+	return nil
+}
+func (e *captureEmitter) EmitLog(level, message string, fields map[string]interface{}) error {
+	e.logs = append(e.logs, level+": "+message)
+	return nil
+}
+func (e *captureEmitter) Context() context.Context { return e.ctx }
+
+// workspace creates a backend over a small tree
+func workspace(t *testing.T, config map[string]interface{}) *filesystem.Backend {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"README.md":           "# Demo\nTODO: write docs\n",
+		"src/main.go":         "package main\n\n// TODO fix\nfunc main() {}\n",
+		"src/util/strings.go": "package util\n// todo: more\n",
+		"src/util/blob.bin":   "TODO\x00binary",
+		"secrets/key.txt":     "TODO rotate\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	config["workspace_root"] = root
+	config["deny_paths"] = []interface{}{"secrets"}
+	b := filesystem.New()
+	if err := b.Initialize(context.Background(), config); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return b
+}
+
+func stream(b *filesystem.Backend, tool string, args map[string]interface{}) (*captureEmitter, error) {
+	emit := &captureEmitter{ctx: context.Background()}
+	return emit, b.CallStreamingTool(emit.ctx, tool, args, emit)
+}
+
+func TestBackend_FileSearch(t *testing.T) {
+	b := workspace(t, nil)
+
+	emit, err := stream(b, "file_search", map[string]interface{}{"path": ".", "query": "todo"})
+	if err != nil {
+		t.Fatalf("file_search failed: %v", err)
+	}
+	var found []string
+	for _, d := range emit.data {
+		m := d.(filesystem.Match)
+		found = append(found, m.Path)
+	}
+	// Binary files and denied paths are skipped
+	if strings.Join(found, ",") != "README.md,src/main.go,src/util/strings.go" {
+		t.Errorf("matches %v", found)
+	}
+	if last := emit.progress[len(emit.progress)-1]; last != "3 matches in 3 of 4 files" {
+		t.Errorf("progress %q", last)
+	}
+
+	emit, err = stream(b, "file_search", map[string]interface{}{
+		"path": "src", "query": `^// (TODO|todo)`, "regex": true, "case_sensitive": true, "glob": "*.go", "max_depth": 1,
+	})
+	if err != nil || len(emit.data) != 1 {
+		t.Fatalf("regex search: %v, %v", emit.data, err)
+	}
+	if m := emit.data[0].(filesystem.Match); m.Path != "src/main.go" || m.Line != 3 || m.Text != "// TODO fix" {
+		t.Errorf("match %+v", m)
+	}
+
+	emit, err = stream(b, "file_search", map[string]interface{}{"path": ".", "query": "todo", "max_results": 2})
+	if err != nil || len(emit.data) != 2 || !strings.Contains(emit.progress[len(emit.progress)-1], "truncated at 2") {
+		t.Errorf("max_results: %v, %v, %v", emit.data, emit.progress, err)
+	}
+
+	var argErr *backend.ArgumentError
+	if _, err := stream(b, "file_search", map[string]interface{}{"path": ".", "query": "(", "regex": true}); !errors.As(err, &argErr) {
+		t.Errorf("bad regex: %v", err)
+	}
+	if _, err := stream(b, "file_search", map[string]interface{}{"path": "../", "query": "x"}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("traversal: %v", err)
+	}
+	if _, err := stream(b, "file_search", map[string]interface{}{"path": "nope", "query": "x"}); mcperr.CodeOf(err) != mcperr.CodeNotFound {
+		t.Errorf("missing path: %v", err)
+	}
+}
+
+func TestBackend_FileSearchSummary(t *testing.T) {
+	b := workspace(t, nil)
+
+	// Called with tools/call, file_search runs to completion and returns
+	// its summary
+	result, err := b.CallTool(context.Background(), "file_search", map[string]interface{}{"path": ".", "query": "todo", "max_results": 2})
+	if err != nil {
+		t.Fatalf("file_search failed: %v", err)
+	}
+	if summary := result.(filesystem.SearchSummary); summary.Matches != 2 || !summary.Truncated {
+		t.Errorf("summary %+v", summary)
+	}
+}
+
+func TestBackend_FileSearchWarnings(t *testing.T) {
+	b := workspace(t, map[string]interface{}{"allow_symlinks": true})
+	// A link to a directory passes the walk as a file but cannot be read
+	if err := os.Symlink("src", filepath.Join(b.Security().Root(), "src.txt")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	// Unreadable files are skipped with a warning instead of failing the search
+	emit, err := stream(b, "file_search", map[string]interface{}{"path": ".", "query": "todo"})
+	if err != nil {
+		t.Fatalf("file_search failed: %v", err)
+	}
+	if len(emit.data) != 3 || len(emit.logs) != 1 || emit.logs[0] != "warning: skipped unreadable file" {
+		t.Errorf("data %v, logs %v", emit.data, emit.logs)
+	}
+}
+
+func TestBackend_FolderList(t *testing.T) {
+	b := workspace(t, nil)
+
+	list := func(args map[string]interface{}) []string {
+		t.Helper()
+		emit, err := stream(b, "folder_list", args)
+		if err != nil {
+			t.Fatalf("folder_list %v failed: %v", args, err)
+		}
+		var paths []string
+		for _, d := range emit.data {
+			paths = append(paths, d.(filesystem.Entry).Path)
+		}
+		return paths
+	}
+
+	if got := strings.Join(list(map[string]interface{}{"path": "."}), ","); got != "README.md,src" {
+		t.Errorf("top level %s", got)
+	}
+	if got := strings.Join(list(map[string]interface{}{"path": "src", "recursive": true}), ","); got != "src/main.go,src/util,src/util/blob.bin,src/util/strings.go" {
+		t.Errorf("recursive %s", got)
+	}
+	if got := strings.Join(list(map[string]interface{}{"path": ".", "recursive": true, "max_depth": 2, "glob": "*.go"}), ","); got != "src/main.go" {
+		t.Errorf("max_depth and glob %s", got)
+	}
+	if got := strings.Join(list(map[string]interface{}{"path": ".", "recursive": true, "regex": `^src/.*\.(go|bin)$`, "glob": "src/util/*"}), ","); got != "src/util/blob.bin,src/util/strings.go" {
+		t.Errorf("regex %s", got)
+	}
+
+	var argErr *backend.ArgumentError
+	if _, err := stream(b, "folder_list", map[string]interface{}{"path": "README.md"}); !errors.As(err, &argErr) {
+		t.Errorf("listing a file: %v", err)
+	}
+	if _, err := stream(b, "folder_list", map[string]interface{}{"path": "secrets"}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("denied path: %v", err)
+	}
+}
+
+func TestBackend_ReadOnly(t *testing.T) {
+	b := workspace(t, map[string]interface{}{"read_only": true})
+	ctx := context.Background()
+
+	if _, err := b.CallTool(ctx, "file_write", map[string]interface{}{"path": "new.txt", "content": "x"}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("write in read-only mode: %v", err)
+	}
+	result, err := b.CallTool(ctx, "file_read", map[string]interface{}{"path": "README.md"})
+	if err != nil || !strings.HasPrefix(result.(map[string]interface{})["content"].(string), "# Demo") {
+		t.Errorf("read: %v, %v", result, err)
+	}
+}
+
+// asyncEmitter collects a running tool's events
+type asyncEmitter struct {
+	ctx      context.Context
+	mu       sync.Mutex
+	data     []interface{}
+	progress chan string
+}
+
+func (e *asyncEmitter) EmitData(data interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.data = append(e.data, data)
+	return nil
+}
+func (e *asyncEmitter) EmitProgress(current, total int64, message string) error {
+	e.progress <- message
+	return nil
+}
+func (e *asyncEmitter) Context() context.Context { return e.ctx }
+
+func TestBackend_FileWatch(t *testing.T) {
+	b := workspace(t, nil)
+	defer b.Close()
+	root := b.Security().Root()
+
+	emit := &asyncEmitter{ctx: context.Background(), progress: make(chan string, 10)}
+	done := make(chan error, 1)
+	go func() {
+		done <- b.CallStreamingTool(emit.ctx, "file_watch", map[string]interface{}{
+			"path": "src", "recursive": true, "glob": "*.txt", "seconds": 10, "max_events": 2,
+		}, emit)
+	}()
+	if msg := <-emit.progress; !strings.HasPrefix(msg, "watching src") {
+		t.Fatalf("first progress %q", msg)
+	}
+
+	os.WriteFile(filepath.Join(root, "src/ignored.md"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(root, "src/util/notes.txt"), []byte("x"), 0644)
+	os.Remove(filepath.Join(root, "src/util/notes.txt"))
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("file_watch failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("file_watch did not stop after max_events")
+	}
+	first, last := emit.data[0].(filesystem.Event), emit.data[1].(filesystem.Event)
+	if first.Path != "src/util/notes.txt" || first.Op != "create" || last.Path != "src/util/notes.txt" {
+		t.Errorf("events %+v", emit.data)
+	}
+	if msg := <-emit.progress; msg != "stopped after 2 changes" {
+		t.Errorf("last progress %q", msg)
+	}
+
+	if _, err := stream(b, "file_watch", map[string]interface{}{"path": "secrets"}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("denied path: %v", err)
+	}
+}
+
+func TestBackend_WatchResource(t *testing.T) {
+	b := workspace(t, nil)
+	defer b.Close()
+	root := b.Security().Root()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := make(chan string, 10)
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(root, "src"))}).String()
+	if err := b.WatchResource(ctx, uri, func(uri string) { updates <- uri }); err != nil {
+		t.Fatalf("WatchResource failed: %v", err)
+	}
+
+	// A burst of writes is one update
+	name := filepath.Join(root, "src", "main.go")
+	for i := 0; i < 3; i++ {
+		os.WriteFile(name, []byte("package main\n"), 0644)
+	}
+	select {
+	case got := <-updates:
+		if want := (&url.URL{Scheme: "file", Path: filepath.ToSlash(name)}).String(); got != want {
+			t.Errorf("updated %s, want %s", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if len(updates) != 0 {
+		t.Errorf("%d more updates for one burst", len(updates))
+	}
+
+	for _, uri := range []string{"https://example.com/x", "file:///etc/passwd", "file://" + filepath.ToSlash(root) + "/secrets"} {
+		if err := b.WatchResource(ctx, uri, func(string) {}); err == nil {
+			t.Errorf("%s: watched", uri)
+		}
+	}
+}
+
+func TestBackend_FileReadRanges(t *testing.T) {
+	b := workspace(t, map[string]interface{}{"max_read_size": 8})
+	ctx := context.Background()
+	read := func(args map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result, err := b.CallTool(ctx, "file_read", args)
+		if err != nil {
+			t.Fatalf("file_read %v failed: %v", args, err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	r := read(map[string]interface{}{"path": "README.md", "offset": 2, "length": 4})
+	if r["content"] != "Demo" || r["encoding"] != "utf-8" || r["eof"] != false || r["truncated"] != nil {
+		t.Errorf("range %v", r)
+	}
+	// The read limit truncates, saying where to go on
+	r = read(map[string]interface{}{"path": "README.md"})
+	if r["content"] != "# Demo\nT" || r["truncated"] != true || r["next_offset"] != int64(8) {
+		t.Errorf("truncated %v", r)
+	}
+	r = read(map[string]interface{}{"path": "src/util/blob.bin", "offset": 3})
+	if data, _ := base64.StdEncoding.DecodeString(r["content"].(string)); r["encoding"] != "base64" || string(data) != "O\x00binary" || r["eof"] != true {
+		t.Errorf("binary %v", r)
+	}
+
+	var toolErr *backend.ToolError
+	if _, err := b.CallTool(ctx, "file_read", map[string]interface{}{"path": "src/util/blob.bin", "encoding": "utf-8"}); !errors.As(err, &toolErr) {
+		t.Errorf("binary as text: %v", err)
+	}
+	var argErr *backend.ArgumentError
+	if _, err := b.CallTool(ctx, "file_read", map[string]interface{}{"path": "README.md", "offset": 100}); !errors.As(err, &argErr) {
+		t.Errorf("offset past the end: %v", err)
+	}
+}
+
+func TestBackend_FileReadStream(t *testing.T) {
+	b := workspace(t, nil)
+	ctx := context.Background()
+
+	// Multi-byte runes straddle the chunk boundaries
+	text := strings.Repeat("héllo wörld ", 1000)
+	if _, err := b.CallTool(ctx, "file_write", map[string]interface{}{"path": "big.txt", "content": text}); err != nil {
+		t.Fatal(err)
+	}
+	emit, err := stream(b, "file_read_stream", map[string]interface{}{"path": "big.txt", "chunk_size": 1000})
+	if err != nil {
+		t.Fatalf("file_read_stream failed: %v", err)
+	}
+	var got strings.Builder
+	for _, d := range emit.data {
+		c := d.(filesystem.Chunk)
+		if c.Encoding != "utf-8" || !utf8.ValidString(c.Data) || int(c.Offset) != got.Len() {
+			t.Fatalf("chunk at %d: %+v", got.Len(), c)
+		}
+		got.WriteString(c.Data)
+	}
+	if got.String() != text || len(emit.data) < 14 {
+		t.Errorf("read %d bytes in %d chunks", got.Len(), len(emit.data))
+	}
+
+	emit, err = stream(b, "file_read_stream", map[string]interface{}{"path": "src/util/blob.bin", "offset": 2, "length": 5, "chunk_size": 2})
+	if err != nil {
+		t.Fatalf("binary stream failed: %v", err)
+	}
+	var data []byte
+	for _, d := range emit.data {
+		c := d.(filesystem.Chunk)
+		part, _ := base64.StdEncoding.DecodeString(c.Data)
+		data = append(data, part...)
+	}
+	if string(data) != "DO\x00bi" || emit.progress[len(emit.progress)-1] != "5 bytes in 3 chunks" {
+		t.Errorf("binary stream %q, %v", data, emit.progress)
+	}
+}
+
+func TestBackend_FileWriteModes(t *testing.T) {
+	b := workspace(t, nil)
+	ctx := context.Background()
+	root := b.Security().Root()
+	write := func(args map[string]interface{}) (map[string]interface{}, error) {
+		result, err := b.CallTool(ctx, "file_write", args)
+		if err != nil {
+			return nil, err
+		}
+		return result.(map[string]interface{}), nil
+	}
+
+	blob := []byte{0xff, 0x00, 0x10}
+	r, err := write(map[string]interface{}{"path": "data.bin", "content": base64.StdEncoding.EncodeToString(blob), "encoding": "base64"})
+	if err != nil {
+		t.Fatalf("base64 write: %v", err)
+	}
+	if _, err := write(map[string]interface{}{"path": "data.bin", "content": "ab", "mode": "append", "expected_sha256": r["sha256"]}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if _, err := write(map[string]interface{}{"path": "data.bin", "content": "XYZ", "mode": "patch", "offset": 3}); err != nil {
+		t.Fatalf("patch: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "data.bin")); string(data) != "\xff\x00\x10XYZ" {
+		t.Errorf("content %q", data)
+	}
+
+	// The file changed since r's hash
+	var toolErr *backend.ToolError
+	if _, err := write(map[string]interface{}{"path": "data.bin", "content": "x", "expected_sha256": r["sha256"]}); !errors.As(err, &toolErr) {
+		t.Errorf("stale hash: %v", err)
+	}
+	var argErr *backend.ArgumentError
+	if _, err := write(map[string]interface{}{"path": "data.bin", "content": "x", "mode": "patch", "offset": 10}); !errors.As(err, &argErr) {
+		t.Errorf("patch past the end: %v", err)
+	}
+	if _, err := write(map[string]interface{}{"path": "none.txt", "content": "x", "mode": "patch"}); mcperr.CodeOf(err) != mcperr.CodeNotFound {
+		t.Errorf("patching a missing file: %v", err)
+	}
+	if _, err := write(map[string]interface{}{"path": "x.bin", "content": "!!", "encoding": "base64"}); !errors.As(err, &argErr) {
+		t.Errorf("bad base64: %v", err)
+	}
+}
+
+func TestBackend_Trash(t *testing.T) {
+	b := workspace(t, map[string]interface{}{"trash": true})
+	ctx := context.Background()
+	root := b.Security().Root()
+	call := func(tool string, args map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result, err := b.CallTool(ctx, tool, args)
+		if err != nil {
+			t.Fatalf("%s %v failed: %v", tool, args, err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	// A dry run changes nothing
+	r := call("folder_delete", map[string]interface{}{"path": "src", "recursive": true, "dry_run": true})
+	if r["files"] != 3 || r["trash"] != true {
+		t.Errorf("dry run %v", r)
+	}
+	if _, err := os.Stat(filepath.Join(root, "src/main.go")); err != nil {
+		t.Fatalf("dry run deleted: %v", err)
+	}
+
+	fileID := call("file_delete", map[string]interface{}{"path": "README.md"})["trash_id"].(string)
+	dirID := call("folder_delete", map[string]interface{}{"path": "src/util", "recursive": true})["trash_id"].(string)
+	if _, err := os.Stat(filepath.Join(root, "src/util")); !os.IsNotExist(err) {
+		t.Fatalf("src/util still there: %v", err)
+	}
+
+	listed, err := b.CallTool(ctx, "file_trash_list", map[string]interface{}{"path": "src"})
+	if entries := listed.([]filesystem.TrashEntry); err != nil || len(entries) != 1 || entries[0].ID != dirID || !entries[0].Dir {
+		t.Errorf("trash list %v, %v", listed, err)
+	}
+
+	// The trash is out of reach of the other tools
+	for _, path := range []string{".trash", ".trash/" + fileID + "/item"} {
+		if _, err := b.CallTool(ctx, "file_read", map[string]interface{}{"path": path}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+			t.Errorf("reading %s: %v", path, err)
+		}
+	}
+	emit, _ := stream(b, "folder_list", map[string]interface{}{"path": ".", "recursive": true})
+	for _, d := range emit.data {
+		if strings.HasPrefix(d.(filesystem.Entry).Path, ".trash") {
+			t.Errorf("listed %s", d.(filesystem.Entry).Path)
+		}
+	}
+
+	call("file_restore", map[string]interface{}{"id": dirID})
+	if data, err := os.ReadFile(filepath.Join(root, "src/util/strings.go")); err != nil || !strings.HasPrefix(string(data), "package util") {
+		t.Errorf("restored directory: %q, %v", data, err)
+	}
+	os.WriteFile(filepath.Join(root, "README.md"), []byte("new"), 0644)
+	var toolErr *backend.ToolError
+	if _, err := b.CallTool(ctx, "file_restore", map[string]interface{}{"id": fileID}); !errors.As(err, &toolErr) {
+		t.Errorf("restoring over a file: %v", err)
+	}
+	r = call("file_restore", map[string]interface{}{"id": fileID, "path": "docs/README.old.md"})
+	if data, _ := os.ReadFile(filepath.Join(root, "docs/README.old.md")); r["path"] != "docs/README.old.md" || !strings.HasPrefix(string(data), "# Demo") {
+		t.Errorf("restored elsewhere %v: %q", r, data)
+	}
+	if _, err := b.CallTool(ctx, "file_restore", map[string]interface{}{"id": fileID}); mcperr.CodeOf(err) != mcperr.CodeNotFound {
+		t.Errorf("restoring twice: %v", err)
+	}
+	if _, err := b.CallTool(ctx, "file_restore", map[string]interface{}{"id": "../secrets"}); mcperr.CodeOf(err) != mcperr.CodeNotFound {
+		t.Errorf("restoring outside the trash: %v", err)
+	}
+}
+
+func TestBackend_WriteSafety(t *testing.T) {
+	b := workspace(t, map[string]interface{}{"max_file_size": 64})
+	ctx := context.Background()
+	root := b.Security().Root()
+	name := filepath.Join(root, "src/main.go")
+	os.Chmod(name, 0600)
+
+	r, err := b.CallTool(ctx, "file_write", map[string]interface{}{"path": "src/main.go", "content": "package x\n", "dry_run": true})
+	if err != nil || r.(map[string]interface{})["old_size"] != int64(41) {
+		t.Errorf("dry run %v, %v", r, err)
+	}
+	if data, _ := os.ReadFile(name); !strings.HasPrefix(string(data), "package main") {
+		t.Errorf("dry run wrote %q", data)
+	}
+	// Dry runs are checked like writes
+	big := strings.Repeat("x", 100)
+	if _, err := b.CallTool(ctx, "file_write", map[string]interface{}{"path": "src/main.go", "content": big, "dry_run": true}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("dry run over the size limit: %v", err)
+	}
+
+	if _, err := b.CallTool(ctx, "file_write", map[string]interface{}{"path": "src/main.go", "content": "package x\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.CallTool(ctx, "file_write", map[string]interface{}{"path": "src/main.go", "content": "y", "mode": "patch", "offset": 8}); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(name)
+	data, _ := os.ReadFile(name)
+	if string(data) != "package y\n" || info.Mode().Perm() != 0600 {
+		t.Errorf("after writes: %q, %v", data, info.Mode())
+	}
+	entries, _ := os.ReadDir(filepath.Join(root, "src"))
+	if len(entries) != 2 {
+		t.Errorf("temporary files left: %v", entries)
+	}
+
+	// Without the trash deletes are final
+	if _, err := b.CallTool(ctx, "file_delete", map[string]interface{}{"path": "src/main.go"}); err != nil {
+		t.Fatal(err)
+	}
+	var toolErr *backend.ToolError
+	if _, err := b.CallTool(ctx, "file_restore", map[string]interface{}{"id": "x"}); !errors.As(err, &toolErr) {
+		t.Errorf("restore without trash: %v", err)
+	}
+}
+
+func TestBackend_Quotas(t *testing.T) {
+	b := workspace(t, map[string]interface{}{"max_total_bytes": 150, "max_files": 6})
+	ctx := context.Background()
+
+	stats, err := b.CallTool(ctx, "workspace_stats", map[string]interface{}{})
+	s := stats.(map[string]interface{})
+	if err != nil || s["bytes"] != int64(115) || s["files"] != 5 || s["remaining_bytes"] != int64(35) || s["remaining_files"] != 1 {
+		t.Fatalf("stats %v, %v", stats, err)
+	}
+	if _, ok := s["remaining_ops"]; ok {
+		t.Errorf("no operations budget, but %v", s)
+	}
+
+	_, err = b.CallTool(ctx, "file_write", map[string]interface{}{"path": "big.txt", "content": strings.Repeat("x", 40)})
+	if e, _ := mcperr.As(err); e == nil || e.Details["used_bytes"] != int64(115) || e.Details["max_total_bytes"] != int64(150) {
+		t.Errorf("over the quota: %v", err)
+	}
+	if _, err := b.CallTool(ctx, "file_create", map[string]interface{}{"path": "one.txt"}); err != nil {
+		t.Fatalf("last file: %v", err)
+	}
+	if _, err := b.CallTool(ctx, "file_copy", map[string]interface{}{"source": "one.txt", "destination": "two.txt"}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("over the file limit: %v", err)
+	}
+	if _, err := b.CallTool(ctx, "folder_copy", map[string]interface{}{"source": "src", "destination": "src2"}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("copying a tree over the quotas: %v", err)
+	}
+}
+
+func TestBackend_OperationBudget(t *testing.T) {
+	b := workspace(t, map[string]interface{}{"max_ops_per_minute": 3})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := b.CallTool(ctx, "file_read", map[string]interface{}{"path": "README.md"}); err != nil {
+			t.Fatalf("read %d: %v", i, err)
+		}
+	}
+	if _, err := b.CallTool(ctx, "file_read", map[string]interface{}{"path": "README.md"}); mcperr.CodeOf(err) != mcperr.CodeRateLimited {
+		t.Errorf("over budget: %v", err)
+	}
+	stats, _ := b.CallTool(ctx, "workspace_stats", map[string]interface{}{})
+	if s := stats.(map[string]interface{}); s["ops_last_minute"] != 3 || s["remaining_ops"] != 0 {
+		t.Errorf("stats %v", s)
+	}
+}
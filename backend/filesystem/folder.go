@@ -1,4 +1,4 @@
-package backend
+package filesystem
 
 import (
 	"context"
@@ -6,11 +6,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/security"
+	"github.com/SaherElMasry/go-mcp-framework/txn"
 )
 
 // handleFolderCreate creates a new directory
-func (b *FilesystemBackend) handleFolderCreate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+func (b *Backend) handleFolderCreate(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path := args["path"].(string)
 
 	fullPath, err := b.security.ValidatePath(path)
@@ -18,7 +20,7 @@ func (b *FilesystemBackend) handleFolderCreate(ctx context.Context, args map[str
 		return nil, err
 	}
 
-	if err := b.security.ValidateFileOperation(path, "write"); err != nil {
+	if err := b.security.ValidateFileOperation(path, security.OpWrite); err != nil {
 		return nil, err
 	}
 
@@ -35,21 +37,22 @@ func (b *FilesystemBackend) handleFolderCreate(ctx context.Context, args map[str
 	}, nil
 }
 
-// handleFolderDelete deletes a directory
-func (b *FilesystemBackend) handleFolderDelete(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+// handleFolderDelete deletes a directory, moving it to the trash when the
+// trash is enabled
+func (b *Backend) handleFolderDelete(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path := args["path"].(string)
 
 	recursive := false
 	if r, ok := args["recursive"].(bool); ok {
 		recursive = r
 	}
-
-	fullPath, err := b.security.ValidatePath(path)
-	if err != nil {
-		return nil, err
+	dryRun, _ := args["dry_run"].(bool)
+	if dryRun {
+		ctx = security.DryRun(ctx)
 	}
 
-	if err := b.security.ValidateFileOperation(path, "delete"); err != nil {
+	fullPath, err := b.security.Authorize(ctx, security.OpDelete, path)
+	if err != nil {
 		return nil, err
 	}
 
@@ -62,6 +65,46 @@ func (b *FilesystemBackend) handleFolderDelete(ctx context.Context, args map[str
 		return nil, fmt.Errorf("path is not a directory: %s", path)
 	}
 
+	relPath, _ := b.security.GetRelativePath(fullPath)
+
+	if !recursive && (dryRun || b.trash != nil) {
+		entries, err := os.ReadDir(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+		if len(entries) > 0 {
+			return nil, fmt.Errorf("directory not empty, set recursive to delete it: %s", path)
+		}
+	}
+
+	if dryRun {
+		size, files := treeSize(fullPath)
+		return map[string]interface{}{
+			"success":   true,
+			"dry_run":   true,
+			"path":      relPath,
+			"recursive": recursive,
+			"files":     files,
+			"size":      size,
+			"trash":     b.trash != nil,
+			"message":   fmt.Sprintf("Would delete directory: %s (%d files, %d bytes)", relPath, files, size),
+		}, nil
+	}
+
+	if b.trash != nil {
+		entry, err := b.moveToTrash(fullPath, info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to move directory to trash: %w", err)
+		}
+		return map[string]interface{}{
+			"success":   true,
+			"path":      relPath,
+			"recursive": recursive,
+			"trash_id":  entry.ID,
+			"message":   fmt.Sprintf("Directory moved to trash: %s (restore with file_restore id %s)", relPath, entry.ID),
+		}, nil
+	}
+
 	if recursive {
 		err = os.RemoveAll(fullPath)
 	} else {
@@ -72,8 +115,6 @@ func (b *FilesystemBackend) handleFolderDelete(ctx context.Context, args map[str
 		return nil, fmt.Errorf("failed to delete directory: %w", err)
 	}
 
-	relPath, _ := b.security.GetRelativePath(fullPath)
-
 	return map[string]interface{}{
 		"success":   true,
 		"path":      relPath,
@@ -83,7 +124,7 @@ func (b *FilesystemBackend) handleFolderDelete(ctx context.Context, args map[str
 }
 
 // handleFolderRename renames a directory
-func (b *FilesystemBackend) handleFolderRename(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+func (b *Backend) handleFolderRename(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	oldPath := args["old_path"].(string)
 	newPath := args["new_path"].(string)
 
@@ -97,7 +138,7 @@ func (b *FilesystemBackend) handleFolderRename(ctx context.Context, args map[str
 		return nil, fmt.Errorf("invalid new path: %w", err)
 	}
 
-	if err := b.security.ValidateFileOperation(oldPath, "write"); err != nil {
+	if err := b.security.ValidateFileOperation(oldPath, security.OpWrite); err != nil {
 		return nil, err
 	}
 
@@ -117,7 +158,7 @@ func (b *FilesystemBackend) handleFolderRename(ctx context.Context, args map[str
 }
 
 // handleFolderCopy copies a directory recursively
-func (b *FilesystemBackend) handleFolderCopy(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+func (b *Backend) handleFolderCopy(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	srcPath := args["source"].(string)
 	dstPath := args["destination"].(string)
 
@@ -131,14 +172,26 @@ func (b *FilesystemBackend) handleFolderCopy(ctx context.Context, args map[strin
 		return nil, fmt.Errorf("invalid destination path: %w", err)
 	}
 
-	if err := b.security.ValidateFileOperation(srcPath, "read"); err != nil {
+	if err := b.security.ValidateFileOperation(srcPath, security.OpRead); err != nil {
+		return nil, err
+	}
+
+	if err := b.security.ValidateFileOperation(dstPath, security.OpWrite); err != nil {
 		return nil, err
 	}
 
-	if err := b.security.ValidateFileOperation(dstPath, "write"); err != nil {
+	size, files := treeSize(srcFull)
+	if err := b.security.Check(ctx, security.Request{Op: security.OpWrite, Path: dstPath, Size: size, Files: files}); err != nil {
 		return nil, err
 	}
 
+	// Remove a partial copy if the copy fails or is cancelled
+	if _, err := os.Stat(dstFull); os.IsNotExist(err) {
+		txn.OnRollback(ctx, func(ctx context.Context) error {
+			return os.RemoveAll(dstFull)
+		})
+	}
+
 	filesCopied := 0
 	bytesCopied := int64(0)
 
@@ -146,6 +199,9 @@ func (b *FilesystemBackend) handleFolderCopy(ctx context.Context, args map[strin
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		// Calculate relative path
 		relPath, err := filepath.Rel(srcFull, path)
@@ -201,7 +257,7 @@ func (b *FilesystemBackend) handleFolderCopy(ctx context.Context, args map[strin
 }
 
 // handleFolderMove moves a directory
-func (b *FilesystemBackend) handleFolderMove(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+func (b *Backend) handleFolderMove(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	srcPath := args["source"].(string)
 	dstPath := args["destination"].(string)
 
@@ -215,7 +271,7 @@ func (b *FilesystemBackend) handleFolderMove(ctx context.Context, args map[strin
 		return nil, fmt.Errorf("invalid destination path: %w", err)
 	}
 
-	if err := b.security.ValidateFileOperation(srcPath, "write"); err != nil {
+	if err := b.security.ValidateFileOperation(srcPath, security.OpWrite); err != nil {
 		return nil, err
 	}
 
@@ -233,81 +289,3 @@ func (b *FilesystemBackend) handleFolderMove(ctx context.Context, args map[strin
 		"message":     fmt.Sprintf("Directory moved: %s → %s", srcRel, dstRel),
 	}, nil
 }
-
-// handleFolderList lists directory contents
-func (b *FilesystemBackend) handleFolderList(ctx context.Context, args map[string]interface{}) (interface{}, error) {
-	path := args["path"].(string)
-
-	recursive := false
-	if r, ok := args["recursive"].(bool); ok {
-		recursive = r
-	}
-
-	fullPath, err := b.security.ValidatePath(path)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := b.security.ValidateFileOperation(path, "read"); err != nil {
-		return nil, err
-	}
-
-	var entries []map[string]interface{}
-
-	if recursive {
-		err = filepath.Walk(fullPath, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-
-			if path == fullPath {
-				return nil
-			}
-
-			relPath, _ := b.security.GetRelativePath(path)
-
-			entry := map[string]interface{}{
-				"name":        filepath.Base(path),
-				"path":        relPath,
-				"is_dir":      info.IsDir(),
-				"size":        info.Size(),
-				"modified":    info.ModTime().Format(time.RFC3339),
-				"permissions": info.Mode().String(),
-			}
-
-			entries = append(entries, entry)
-			return nil
-		})
-	} else {
-		dirEntries, err := os.ReadDir(fullPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read directory: %w", err)
-		}
-
-		for _, entry := range dirEntries {
-			info, _ := entry.Info()
-			entryPath := filepath.Join(fullPath, entry.Name())
-			relPath, _ := b.security.GetRelativePath(entryPath)
-
-			e := map[string]interface{}{
-				"name":        entry.Name(),
-				"path":        relPath,
-				"is_dir":      entry.IsDir(),
-				"size":        info.Size(),
-				"modified":    info.ModTime().Format(time.RFC3339),
-				"permissions": info.Mode().String(),
-			}
-
-			entries = append(entries, e)
-		}
-	}
-
-	relPath, _ := b.security.GetRelativePath(fullPath)
-
-	return map[string]interface{}{
-		"path":      relPath,
-		"entries":   entries,
-		"count":     len(entries),
-		"recursive": recursive,
-	}, nil
-}
@@ -0,0 +1,351 @@
+package filesystem
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// Limits of the streaming tools
+const (
+	// DefaultMaxResults is the most matching lines file_search returns
+	// unless max_results says otherwise
+	DefaultMaxResults = 1000
+
+	// progressEvery is the number of files or entries between progress
+	// updates
+	progressEvery = 100
+
+	// maxLineLength truncates long matching lines in results
+	maxLineLength = 500
+
+	// searchBatchSize is the most matches sent in one stream event
+	searchBatchSize = 50
+)
+
+type searchArgs struct {
+	Path          string `json:"path" description:"File or directory to search, relative to the workspace root"`
+	Query         string `json:"query" description:"Text to search for, or a regular expression with regex"`
+	Regex         bool   `json:"regex,omitempty" description:"Treat query as a regular expression (RE2 syntax)"`
+	CaseSensitive bool   `json:"case_sensitive,omitempty" description:"Match case (default: ignore it)"`
+	Glob          string `json:"glob,omitempty" description:"Only search files matching this pattern: \"*.go\" matches file names, \"docs/*.md\" paths below path"`
+	MaxDepth      int    `json:"max_depth,omitempty" jsonschema:"minimum=0" description:"Deepest level searched, 1 being the files directly in path (default: unlimited)"`
+	MaxResults    int    `json:"max_results,omitempty" jsonschema:"minimum=1" description:"Most matching lines to return (default 1000)"`
+}
+
+type listArgs struct {
+	Path      string `json:"path" description:"Directory to list, relative to the workspace root"`
+	Recursive bool   `json:"recursive,omitempty" description:"List subdirectories' entries as well"`
+	Glob      string `json:"glob,omitempty" description:"Only list entries matching this pattern: \"*.go\" matches names, \"docs/*.md\" paths below path"`
+	Regex     string `json:"regex,omitempty" description:"Only list entries whose path below path matches this regular expression"`
+	MaxDepth  int    `json:"max_depth,omitempty" jsonschema:"minimum=0" description:"Deepest level listed when recursive, 1 being the directory's own entries (default: unlimited)"`
+}
+
+// Match is a file_search result: one matching line
+type Match struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// SearchSummary is the result of a file_search call, emitted after its
+// matches
+type SearchSummary struct {
+	Matches       int  `json:"matches"`
+	FilesMatched  int  `json:"files_matched"`
+	FilesSearched int  `json:"files_searched"`
+	Truncated     bool `json:"truncated,omitempty"`
+}
+
+// Entry is a folder_list result
+type Entry struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	IsDir       bool   `json:"is_dir"`
+	Size        int64  `json:"size"`
+	Modified    string `json:"modified"`
+	Permissions string `json:"permissions"`
+}
+
+// filter selects entries by their slash-separated path below the walked
+// directory
+type filter struct {
+	glob string
+	re   *regexp.Regexp
+}
+
+// newFilter compiles a tool's glob and regex arguments
+func newFilter(tool, glob, regex string) (filter, error) {
+	f := filter{glob: glob}
+	if _, err := path.Match(glob, ""); err != nil {
+		return f, backend.NewArgumentError(tool, "glob", err.Error())
+	}
+	if regex != "" {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			return f, backend.NewArgumentError(tool, "regex", err.Error())
+		}
+		f.re = re
+	}
+	return f, nil
+}
+
+// match reports whether the entry at rel passes the filter
+// Globs without a slash match the entry's name, others its path.
+func (f filter) match(rel string) bool {
+	if f.glob != "" {
+		subject := rel
+		if !strings.Contains(f.glob, "/") {
+			subject = path.Base(rel)
+		}
+		if ok, _ := path.Match(f.glob, subject); !ok {
+			return false
+		}
+	}
+	return f.re == nil || f.re.MatchString(rel)
+}
+
+// walk calls visit for the entries below dir, a root-relative path, that
+// the policies let be read, up to maxDepth levels (0: unlimited)
+// visit receives the entry's path relative to dir (slash-separated) and
+// to the root. Unreadable entries are skipped; denied directories are
+// not descended into. A file as dir is visited alone when allowFile is
+// set and is an argument error otherwise.
+func (b *Backend) walk(ctx context.Context, tool, dir string, allowFile bool, maxDepth int, visit func(rel, rootRel string, d fs.DirEntry) error) error {
+	if b.security == nil {
+		return errors.New("filesystem: backend not initialized")
+	}
+	start, err := b.security.Authorize(ctx, security.OpRead, dir)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(start)
+	if os.IsNotExist(err) {
+		return mcperr.NotFound("path not found: %s", dir)
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() && !allowFile {
+		return backend.NewArgumentError(tool, "path", "not a directory: "+dir)
+	}
+	base, err := b.security.GetRelativePath(start)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if p == start {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return visit(d.Name(), base, d)
+			}
+			return nil
+		}
+		if err != nil {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(start, p)
+		rootRel := filepath.Join(base, rel)
+		// Policies (deny_paths, extensions) and symlinks leaving the
+		// root exclude entries silently
+		if d.Type()&fs.ModeSymlink != 0 {
+			if _, err := b.security.ResolvePath(rootRel); err != nil {
+				return nil
+			}
+		}
+		if err := b.security.Check(ctx, security.Request{Op: security.OpRead, Path: rootRel}); err != nil {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel = filepath.ToSlash(rel)
+		if err := visit(rel, rootRel, d); err != nil {
+			return err
+		}
+		if d.IsDir() && maxDepth > 0 && strings.Count(rel, "/")+1 >= maxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+// errEnough stops a walk once enough results were emitted
+var errEnough = errors.New("enough results")
+
+// unreadableError reports a file searchFile could not read
+type unreadableError struct {
+	err error
+}
+
+func (e *unreadableError) Error() string { return "unreadable file: " + e.err.Error() }
+func (e *unreadableError) Unwrap() error { return e.err }
+
+// handleFileSearch streams the lines of the files below a path that match
+// a query, then a summary as the call's result
+func (b *Backend) handleFileSearch(ctx context.Context, in searchArgs, emit backend.StreamingEmitter) error {
+	pattern := in.Query
+	if !in.Regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !in.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return backend.NewArgumentError("file_search", "query", err.Error())
+	}
+	files, err := newFilter("file_search", in.Glob, "")
+	if err != nil {
+		return err
+	}
+	maxResults := in.MaxResults
+	if maxResults <= 0 {
+		maxResults = DefaultMaxResults
+	}
+
+	searched, matched, results := 0, 0, 0
+	err = b.walk(ctx, "file_search", in.Path, true, in.MaxDepth, func(rel, rootRel string, d fs.DirEntry) error {
+		if !d.Type().IsRegular() && d.Type()&fs.ModeSymlink == 0 || !files.match(rel) {
+			return nil
+		}
+		searched++
+		if searched%progressEvery == 0 {
+			if err := emit.EmitProgress(int64(searched), 0, fmt.Sprintf("%d files searched, %d matches", searched, results)); err != nil {
+				return err
+			}
+		}
+
+		found, err := searchFile(filepath.Join(b.security.Root(), rootRel), re, func(line int, text string) error {
+			if results == maxResults {
+				return errEnough
+			}
+			results++
+			return emit.EmitData(Match{Path: filepath.ToSlash(rootRel), Line: line, Text: text})
+		})
+		if found {
+			matched++
+		}
+		var unreadable *unreadableError
+		if errors.As(err, &unreadable) {
+			return backend.EmitStreamLog(emit, backend.LogWarning, "skipped unreadable file", map[string]interface{}{
+				"path":  filepath.ToSlash(rootRel),
+				"error": unreadable.err.Error(),
+			})
+		}
+		return err
+	})
+	summary := SearchSummary{Matches: results, FilesMatched: matched, FilesSearched: searched}
+	message := fmt.Sprintf("%d matches in %d of %d files", results, matched, searched)
+	if errors.Is(err, errEnough) {
+		summary.Truncated = true
+		message = fmt.Sprintf("truncated at %d matches; narrow the path or glob, or raise max_results", results)
+	} else if err != nil {
+		return err
+	}
+	if err := emit.EmitProgress(int64(searched), int64(searched), message); err != nil {
+		return err
+	}
+	return backend.EmitResult(emit, summary)
+}
+
+// searchFile calls found for each line of a text file that re matches,
+// reporting whether there was any; binary files are skipped, and read
+// errors fail with an *unreadableError
+func searchFile(name string, re *regexp.Regexp, found func(line int, text string) error) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, &unreadableError{err}
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if head, _ := r.Peek(512); bytes.IndexByte(head, 0) >= 0 {
+		return false, nil
+	}
+
+	matched := false
+	for line := 1; ; line++ {
+		text, err := r.ReadString('\n')
+		if text != "" && re.MatchString(text) {
+			matched = true
+			text = strings.TrimRight(text, "\r\n")
+			if len(text) > maxLineLength {
+				text = text[:maxLineLength] + "..."
+			}
+			if err := found(line, text); err != nil {
+				return matched, err
+			}
+		}
+		if err == io.EOF {
+			return matched, nil
+		}
+		if err != nil {
+			return matched, &unreadableError{err}
+		}
+	}
+}
+
+// handleFolderList streams the entries of a directory
+func (b *Backend) handleFolderList(ctx context.Context, in listArgs, emit backend.StreamingEmitter) error {
+	entries, err := newFilter("folder_list", in.Glob, in.Regex)
+	if err != nil {
+		return err
+	}
+	maxDepth := in.MaxDepth
+	if !in.Recursive {
+		maxDepth = 1
+	}
+
+	seen, listed := 0, 0
+	err = b.walk(ctx, "folder_list", in.Path, false, maxDepth, func(rel, rootRel string, d fs.DirEntry) error {
+		seen++
+		if seen%progressEvery == 0 {
+			if err := emit.EmitProgress(int64(seen), 0, fmt.Sprintf("%d entries read", seen)); err != nil {
+				return err
+			}
+		}
+		if !entries.match(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		listed++
+		return emit.EmitData(Entry{
+			Name:        d.Name(),
+			Path:        filepath.ToSlash(rootRel),
+			IsDir:       d.IsDir(),
+			Size:        info.Size(),
+			Modified:    info.ModTime().Format(time.RFC3339),
+			Permissions: info.Mode().String(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return emit.EmitProgress(int64(seen), int64(seen), fmt.Sprintf("%d entries", listed))
+}
@@ -0,0 +1,266 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// Trash defaults
+const (
+	// DefaultTrashDir is the trash directory, relative to the workspace root
+	DefaultTrashDir = ".trash"
+
+	// DefaultTrashRetention is how long deleted entries are kept
+	DefaultTrashRetention = 7 * 24 * time.Hour
+)
+
+// Files of a trash entry's directory
+const (
+	trashItem = "item"
+	trashMeta = "entry.json"
+)
+
+// TrashEntry is a deleted file or directory file_restore can bring back
+type TrashEntry struct {
+	ID string `json:"id"`
+
+	// Path is where the entry was deleted from, relative to the root
+	Path    string `json:"path"`
+	Dir     bool   `json:"dir"`
+	Size    int64  `json:"size"`
+	Deleted string `json:"deleted"`
+}
+
+type restoreArgs struct {
+	ID     string `json:"id" description:"Trash ID returned by file_delete or folder_delete"`
+	Path   string `json:"path,omitempty" description:"Where to restore to (default: where it was deleted from)"`
+	DryRun bool   `json:"dry_run,omitempty" description:"Report what would be restored without restoring it"`
+}
+
+type trashListArgs struct {
+	Path string `json:"path,omitempty" description:"Only list entries deleted from this path or below it"`
+}
+
+// trash configures deletes to move entries to a trash directory
+type trash struct {
+	// dir is the resolved trash directory
+	dir       string
+	retention time.Duration
+}
+
+// configureTrash enables the trash below the root, hiding it from every
+// tool through the security manager
+func (b *Backend) configureTrash(config map[string]interface{}) error {
+	if enabled, _ := config["trash"].(bool); !enabled {
+		return nil
+	}
+	rel := filepath.Clean(backend.StringConfig(config, "trash_dir", DefaultTrashDir))
+	dir, err := b.security.ResolvePath(rel)
+	if err != nil {
+		return fmt.Errorf("filesystem: trash_dir: %w", err)
+	}
+	if dir == b.security.Root() {
+		return errors.New("filesystem: trash_dir cannot be the workspace root")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("filesystem: failed to create trash: %w", err)
+	}
+	b.security.Use(security.DenyPaths(filepath.ToSlash(rel)))
+
+	retention := DefaultTrashRetention
+	if hours := backend.IntConfig(config, "trash_retention_hours", 0); hours > 0 {
+		retention = time.Duration(hours) * time.Hour
+	}
+	b.trash = &trash{dir: dir, retention: retention}
+	return nil
+}
+
+// moveToTrash moves a resolved path to the trash, returning its entry
+func (b *Backend) moveToTrash(fullPath string, info os.FileInfo) (TrashEntry, error) {
+	b.purgeTrash()
+
+	relPath, _ := b.security.GetRelativePath(fullPath)
+	entry := TrashEntry{
+		ID:      newTrashID(),
+		Path:    filepath.ToSlash(relPath),
+		Dir:     info.IsDir(),
+		Size:    info.Size(),
+		Deleted: time.Now().UTC().Format(time.RFC3339),
+	}
+	if entry.Dir {
+		entry.Size, _ = treeSize(fullPath)
+	}
+
+	dir := filepath.Join(b.trash.dir, entry.ID)
+	if err := os.Mkdir(dir, 0700); err != nil {
+		return TrashEntry{}, err
+	}
+	meta, _ := json.Marshal(entry)
+	if err := os.WriteFile(filepath.Join(dir, trashMeta), meta, 0600); err != nil {
+		os.RemoveAll(dir)
+		return TrashEntry{}, err
+	}
+	if err := os.Rename(fullPath, filepath.Join(dir, trashItem)); err != nil {
+		os.RemoveAll(dir)
+		return TrashEntry{}, err
+	}
+	return entry, nil
+}
+
+// trashEntries reads the entries in the trash, newest first
+func (b *Backend) trashEntries() ([]TrashEntry, error) {
+	dirs, err := os.ReadDir(b.trash.dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]TrashEntry, 0, len(dirs))
+	for _, d := range dirs {
+		if e, err := b.trashEntry(d.Name()); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+	return entries, nil
+}
+
+// trashEntry reads one entry
+func (b *Backend) trashEntry(id string) (TrashEntry, error) {
+	var entry TrashEntry
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return entry, mcperr.NotFound("no trash entry %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(b.trash.dir, id, trashMeta))
+	if err != nil {
+		return entry, mcperr.NotFound("no trash entry %q", id)
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, fmt.Errorf("trash entry %s: %w", id, err)
+	}
+	return entry, nil
+}
+
+// purgeTrash removes the entries older than the retention
+func (b *Backend) purgeTrash() {
+	entries, err := b.trashEntries()
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-b.trash.retention)
+	for _, e := range entries {
+		if deleted, err := time.Parse(time.RFC3339, e.Deleted); err == nil && deleted.Before(cutoff) {
+			if err := os.RemoveAll(filepath.Join(b.trash.dir, e.ID)); err != nil {
+				b.logger.Warn("failed to purge trash entry", "id", e.ID, "error", err)
+			}
+		}
+	}
+}
+
+// handleFileRestore moves a trash entry back into the workspace
+func (b *Backend) handleFileRestore(ctx context.Context, in restoreArgs) (map[string]interface{}, error) {
+	if b.trash == nil {
+		return nil, backend.NewToolError("the trash is disabled; nothing can be restored")
+	}
+	if in.DryRun {
+		ctx = security.DryRun(ctx)
+	}
+	entry, err := b.trashEntry(in.ID)
+	if err != nil {
+		return nil, err
+	}
+	target := in.Path
+	if target == "" {
+		target = entry.Path
+	}
+	fullPath, err := b.security.Authorize(ctx, security.OpWrite, target)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(fullPath); err == nil {
+		return nil, backend.NewToolError("%s already exists; restore entry %s to another path", target, entry.ID)
+	}
+
+	relPath, _ := b.security.GetRelativePath(fullPath)
+	result := map[string]interface{}{
+		"success": true,
+		"id":      entry.ID,
+		"path":    relPath,
+		"dir":     entry.Dir,
+		"size":    entry.Size,
+	}
+	if in.DryRun {
+		result["dry_run"] = true
+		result["message"] = fmt.Sprintf("Would restore %s to %s", entry.Path, relPath)
+		return result, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create parent directories: %w", err)
+	}
+	dir := filepath.Join(b.trash.dir, entry.ID)
+	if err := os.Rename(filepath.Join(dir, trashItem), fullPath); err != nil {
+		return nil, fmt.Errorf("failed to restore: %w", err)
+	}
+	os.RemoveAll(dir)
+	result["message"] = fmt.Sprintf("Restored %s to %s", entry.Path, relPath)
+	return result, nil
+}
+
+// handleTrashList lists the trash entries
+func (b *Backend) handleTrashList(ctx context.Context, in trashListArgs) ([]TrashEntry, error) {
+	if b.trash == nil {
+		return nil, backend.NewToolError("the trash is disabled")
+	}
+	entries, err := b.trashEntries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+	if in.Path == "" || in.Path == "." {
+		return entries, nil
+	}
+	prefix := filepath.ToSlash(filepath.Clean(in.Path))
+	kept := entries[:0]
+	for _, e := range entries {
+		if e.Path == prefix || strings.HasPrefix(e.Path, prefix+"/") {
+			kept = append(kept, e)
+		}
+	}
+	return kept, nil
+}
+
+// newTrashID returns a unique ID that sorts by deletion time
+func newTrashID() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return time.Now().UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix)
+}
+
+// treeSize returns the number of files below dir and their total size
+func treeSize(dir string) (int64, int) {
+	var size int64
+	files := 0
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+			files++
+		}
+		return nil
+	})
+	return size, files
+}
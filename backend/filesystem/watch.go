@@ -0,0 +1,299 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// Limits of file_watch
+const (
+	// DefaultWatchSeconds is how long file_watch watches without seconds
+	DefaultWatchSeconds = 60
+
+	// DefaultMaxEvents is the most changes file_watch reports unless
+	// max_events says otherwise
+	DefaultMaxEvents = 100
+)
+
+// resourceDebounce coalesces bursts of changes to one file (an editor's
+// truncate and write) into one notifications/resources/updated
+const resourceDebounce = 100 * time.Millisecond
+
+type watchArgs struct {
+	Path      string `json:"path" description:"File or directory to watch, relative to the workspace root"`
+	Recursive bool   `json:"recursive,omitempty" description:"Watch subdirectories as well"`
+	Glob      string `json:"glob,omitempty" description:"Only report changes to entries matching this pattern: \"*.go\" matches names, \"docs/*.md\" paths below path"`
+	Seconds   int    `json:"seconds,omitempty" jsonschema:"minimum=1,maximum=3600" description:"How long to watch (default 60)"`
+	MaxEvents int    `json:"max_events,omitempty" jsonschema:"minimum=1" description:"Stop after this many changes (default 100)"`
+}
+
+// Event is a file_watch result: one change
+type Event struct {
+	// Path is relative to the workspace root
+	Path string `json:"path"`
+
+	// Op is create, write, remove, rename or chmod
+	Op   string `json:"op"`
+	Time string `json:"time"`
+}
+
+// eventOp names the change of an fsnotify event
+func eventOp(op fsnotify.Op) string {
+	switch {
+	case op.Has(fsnotify.Create):
+		return "create"
+	case op.Has(fsnotify.Write):
+		return "write"
+	case op.Has(fsnotify.Remove):
+		return "remove"
+	case op.Has(fsnotify.Rename):
+		return "rename"
+	default:
+		return "chmod"
+	}
+}
+
+// watch reports the changes to a root-relative file or directory (and its
+// subdirectories when recursive) until ctx is done or the backend closes
+// It returns once the watch is set up. changed receives each event and
+// its path relative to the watched one (slash-separated); changes the
+// policies would not let be read are left out.
+func (b *Backend) watch(ctx context.Context, target string, recursive bool, changed func(rel string, e Event)) error {
+	if b.security == nil {
+		return errors.New("filesystem: backend not initialized")
+	}
+	start, err := b.security.Authorize(ctx, security.OpRead, target)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(start)
+	if os.IsNotExist(err) {
+		return mcperr.NotFound("path not found: %s", target)
+	}
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("filesystem: %w", err)
+	}
+	// A file is watched through its directory, which survives editors
+	// replacing the file
+	dir := start
+	if !info.IsDir() {
+		dir = filepath.Dir(start)
+	}
+	if err := b.addWatches(watcher, dir, info.IsDir() && recursive); err != nil {
+		watcher.Close()
+		return fmt.Errorf("filesystem: watch %s: %w", target, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.closed:
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				b.logger.Warn("file watch error", "path", target, "error", err)
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !info.IsDir() && ev.Name != start {
+					continue
+				}
+				rel, err := filepath.Rel(start, ev.Name)
+				if err != nil {
+					continue
+				}
+				if !info.IsDir() {
+					rel = filepath.Base(start)
+				}
+				rootRel, err := b.security.GetRelativePath(ev.Name)
+				if err != nil {
+					continue
+				}
+				if b.security.Check(ctx, security.Request{Op: security.OpRead, Path: rootRel}) != nil {
+					continue
+				}
+				if recursive && info.IsDir() && ev.Has(fsnotify.Create) {
+					if fi, err := os.Lstat(ev.Name); err == nil && fi.IsDir() {
+						b.addWatches(watcher, ev.Name, true)
+					}
+				}
+				changed(filepath.ToSlash(rel), Event{
+					Path: filepath.ToSlash(rootRel),
+					Op:   eventOp(ev.Op),
+					Time: time.Now().UTC().Format(time.RFC3339Nano),
+				})
+			}
+		}
+	}()
+	return nil
+}
+
+// addWatches watches dir and, when recursive, the subdirectories the
+// policies let be read
+func (b *Backend) addWatches(watcher *fsnotify.Watcher, dir string, recursive bool) error {
+	if !recursive {
+		return watcher.Add(dir)
+	}
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if p != dir {
+			rootRel, err := b.security.GetRelativePath(p)
+			if err != nil || b.security.Check(context.Background(), security.Request{Op: security.OpRead, Path: rootRel}) != nil {
+				return filepath.SkipDir
+			}
+		}
+		if err := watcher.Add(p); err != nil && p == dir {
+			return err
+		}
+		return nil
+	})
+}
+
+// handleFileWatch streams the changes below a path for a while
+func (b *Backend) handleFileWatch(ctx context.Context, in watchArgs, emit backend.StreamingEmitter) error {
+	entries, err := newFilter("file_watch", in.Glob, "")
+	if err != nil {
+		return err
+	}
+	seconds := in.Seconds
+	if seconds <= 0 {
+		seconds = DefaultWatchSeconds
+	}
+	maxEvents := in.MaxEvents
+	if maxEvents <= 0 {
+		maxEvents = DefaultMaxEvents
+	}
+
+	watchCtx, cancel := context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+	defer cancel()
+	events := make(chan Event, 64)
+	err = b.watch(watchCtx, in.Path, in.Recursive, func(rel string, e Event) {
+		if !entries.match(rel) {
+			return
+		}
+		select {
+		case events <- e:
+		case <-watchCtx.Done():
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if err := emit.EmitProgress(0, int64(maxEvents), fmt.Sprintf("watching %s for %ds", in.Path, seconds)); err != nil {
+		return err
+	}
+
+	count := 0
+	for {
+		select {
+		case <-watchCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return emit.EmitProgress(int64(count), int64(count), fmt.Sprintf("%d changes in %ds", count, seconds))
+		case e := <-events:
+			if err := emit.EmitData(e); err != nil {
+				return err
+			}
+			count++
+			if count == maxEvents {
+				return emit.EmitProgress(int64(count), int64(count), fmt.Sprintf("stopped after %d changes", count))
+			}
+		}
+	}
+}
+
+// ============================================================
+// Resource subscriptions
+// ============================================================
+
+// WatchResource implements backend.ResourceWatcher for file:// URIs of
+// files and directories inside the workspace; a directory's subscribers
+// hear of every change below it, under the changed entry's URI
+func (b *Backend) WatchResource(ctx context.Context, uri string, updated func(uri string)) error {
+	if b.security == nil {
+		return errors.New("filesystem: backend not initialized")
+	}
+	target, err := b.uriPath(uri)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	pending := make(map[string]bool)
+	return b.watch(ctx, target, true, func(_ string, e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if pending[e.Path] {
+			return
+		}
+		pending[e.Path] = true
+		time.AfterFunc(resourceDebounce, func() {
+			mu.Lock()
+			delete(pending, e.Path)
+			mu.Unlock()
+			if ctx.Err() == nil {
+				updated(b.fileURI(e.Path))
+			}
+		})
+	})
+}
+
+// uriPath returns the root-relative path of a file:// URI inside the
+// workspace
+func (b *Backend) uriPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return "", mcperr.NotFound("not a file resource: %s", uri)
+	}
+	name := filepath.FromSlash(u.Path)
+	for _, root := range b.roots() {
+		if rel, err := filepath.Rel(root, name); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return rel, nil
+		}
+	}
+	return "", mcperr.NotFound("resource outside the workspace: %s", uri)
+}
+
+// roots returns the workspace root as configured and with symlinks
+// resolved
+func (b *Backend) roots() []string {
+	root := b.security.Root()
+	if real, err := filepath.EvalSymlinks(root); err == nil && real != root {
+		return []string{root, real}
+	}
+	return []string{root}
+}
+
+// fileURI returns the file:// URI of a root-relative path
+func (b *Backend) fileURI(rel string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(b.security.Root(), rel))}
+	return u.String()
+}
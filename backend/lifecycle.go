@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// Defaults for lifecycle timeouts
+const (
+	DefaultInitializeTimeout = 30 * time.Second
+	DefaultCloseTimeout      = 10 * time.Second
+)
+
+// lifecycleGrace is how long a backend may take to return its own error
+// after its context is done, before the timeout error is returned instead
+const lifecycleGrace = 100 * time.Millisecond
+
+// InitializeWithTimeout initializes b, giving up after timeout (0: only
+// when ctx is done)
+// Initialize's context is cancelled at the deadline; a backend ignoring
+// it is left running in the background and reported as timed out, so a
+// hung upstream check cannot block startup. Errors name the backend, and
+// the duration is recorded as mcp_backend_initialize_duration_seconds.
+func InitializeWithTimeout(ctx context.Context, b ServerBackend, config map[string]interface{}, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := initialize(ctx, b, b.Name(), config); err != nil {
+		return fmt.Errorf("backend %s: %w", b.Name(), err)
+	}
+	return nil
+}
+
+// initialize initializes b until ctx is done, recording the duration
+// under name
+func initialize(ctx context.Context, b ServerBackend, name string, config map[string]interface{}) error {
+	start := time.Now()
+	_, composite := b.(*CompositeBackend)
+
+	var err error
+	if composite {
+		// A composite bounds each mount itself, so the hung one is named
+		err = b.Initialize(ctx, config)
+	} else {
+		err = runWithContext(ctx, func() error { return b.Initialize(ctx, config) })
+	}
+
+	status := "success"
+	switch {
+	case errors.Is(err, errLifecycleTimeout):
+		status = "timeout"
+		waited := time.Since(start)
+		if deadline, ok := ctx.Deadline(); ok {
+			waited = deadline.Sub(start)
+		}
+		err = fmt.Errorf("initialize did not finish within %s: %w", waited.Round(time.Millisecond), ctx.Err())
+	case err != nil && ctx.Err() != nil:
+		status = "timeout"
+		if !composite {
+			err = fmt.Errorf("initialize interrupted after %s: %w", time.Since(start).Round(time.Millisecond), err)
+		}
+	case err != nil:
+		status = "error"
+	}
+	observability.RecordBackendInitialize(name, status, time.Since(start))
+	return err
+}
+
+// CloseWithTimeout closes b, giving up after timeout (0: wait)
+// A backend still closing at the deadline is left to finish in the
+// background, so shutdown proceeds.
+func CloseWithTimeout(b ServerBackend, timeout time.Duration) error {
+	if timeout <= 0 {
+		return b.Close()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := runWithContext(ctx, b.Close)
+	if errors.Is(err, errLifecycleTimeout) {
+		return fmt.Errorf("backend %s: close did not finish within %s: %w", b.Name(), timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// errLifecycleTimeout reports a call still running when its context ended
+var errLifecycleTimeout = errors.New("lifecycle call did not return")
+
+// runWithContext runs fn, returning errLifecycleTimeout if it hasn't
+// returned shortly after ctx is done
+func runWithContext(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+	}
+	// Prefer the backend's own error if it reacts to the cancellation
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(lifecycleGrace):
+		return errLifecycleTimeout
+	}
+}
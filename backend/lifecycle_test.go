@@ -0,0 +1,107 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// hangingBackend blocks in Initialize and Close until released, or until
+// its context is done if it honors it
+type hangingBackend struct {
+	*backend.BaseBackend
+	release    chan struct{}
+	honorsCtx  bool
+	hangsClose bool
+}
+
+func newHangingBackend(name string) *hangingBackend {
+	return &hangingBackend{BaseBackend: backend.NewBaseBackend(name), release: make(chan struct{})}
+}
+
+func (b *hangingBackend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if b.honorsCtx {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.release:
+			return nil
+		}
+	}
+	<-b.release
+	return nil
+}
+
+func (b *hangingBackend) Close() error {
+	if b.hangsClose {
+		<-b.release
+	}
+	return nil
+}
+
+func TestInitializeWithTimeout(t *testing.T) {
+	// A backend ignoring its context is abandoned at the deadline
+	b := newHangingBackend("github")
+	defer close(b.release)
+
+	start := time.Now()
+	err := backend.InitializeWithTimeout(context.Background(), b, nil, 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("InitializeWithTimeout returned after %s", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.HasPrefix(err.Error(), "backend github: initialize did not finish within") {
+		t.Errorf("error = %v", err)
+	}
+
+	// A backend honoring it returns its own error
+	b = newHangingBackend("github")
+	b.honorsCtx = true
+	err = backend.InitializeWithTimeout(context.Background(), b, nil, 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.HasPrefix(err.Error(), "backend github: initialize interrupted after") {
+		t.Errorf("error = %v", err)
+	}
+
+	// Errors from a prompt backend pass through, naming it
+	r := newRecordingBackend("github")
+	r.initErr = errors.New("bad token")
+	err = backend.InitializeWithTimeout(context.Background(), r, nil, time.Second)
+	if err == nil || err.Error() != "backend github: bad token" {
+		t.Errorf("error = %v", err)
+	}
+	if err := backend.InitializeWithTimeout(context.Background(), newRecordingBackend("github"), nil, 0); err != nil {
+		t.Errorf("InitializeWithTimeout: %v", err)
+	}
+}
+
+func TestInitializeWithTimeout_CompositeNamesMount(t *testing.T) {
+	fs := newRecordingBackend("filesystem")
+	gh := newHangingBackend("github")
+	defer close(gh.release)
+	c := backend.NewCompositeBackend("workspace", backend.Mount{Backend: fs}, backend.Mount{Backend: gh, Prefix: "gh"})
+
+	err := backend.InitializeWithTimeout(context.Background(), c, nil, 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "backend gh: initialize did not finish") {
+		t.Errorf("error = %v", err)
+	}
+	if !fs.closed {
+		t.Error("initialized backend was not closed")
+	}
+}
+
+func TestCloseWithTimeout(t *testing.T) {
+	b := newHangingBackend("github")
+	b.hangsClose = true
+	defer close(b.release)
+
+	err := backend.CloseWithTimeout(b, 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "backend github: close did not finish within 50ms") {
+		t.Errorf("error = %v", err)
+	}
+	if err := backend.CloseWithTimeout(newRecordingBackend("github"), time.Second); err != nil {
+		t.Errorf("CloseWithTimeout: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package backend
+
+import "context"
+
+// Log levels of EmitLog, the MCP logging levels
+const (
+	LogDebug     = "debug"
+	LogInfo      = "info"
+	LogNotice    = "notice"
+	LogWarning   = "warning"
+	LogError     = "error"
+	LogCritical  = "critical"
+	LogAlert     = "alert"
+	LogEmergency = "emergency"
+)
+
+// LogReporter delivers the log messages of a tool call to the client
+type LogReporter func(level, message string, fields map[string]interface{}) error
+
+type logKey struct{}
+
+// WithLogReporter attaches a log reporter to a tool call's context
+// The protocol handler installs one that sends notifications/message
+// when the transport can notify.
+func WithLogReporter(ctx context.Context, r LogReporter) context.Context {
+	return context.WithValue(ctx, logKey{}, r)
+}
+
+// EmitLog reports a non-fatal warning or other message from a regular
+// (non-streaming) tool handler; streaming handlers use EmitStreamLog
+// It is a no-op when the client cannot be notified.
+//
+// Example:
+//
+//	if err != nil {
+//	    backend.EmitLog(ctx, backend.LogWarning, "skipped unreadable file", map[string]interface{}{"path": path})
+//	    continue
+//	}
+func EmitLog(ctx context.Context, level, message string, fields map[string]interface{}) error {
+	r, ok := ctx.Value(logKey{}).(LogReporter)
+	if !ok || r == nil {
+		return nil
+	}
+	return r(level, message, fields)
+}
+
+// LogEmitter is implemented by emitters that can send a streaming tool's
+// log messages (the engine's emitter does)
+type LogEmitter interface {
+	EmitLog(level, message string, fields map[string]interface{}) error
+}
+
+// EmitStreamLog reports a non-fatal warning or other message from a
+// streaming tool handler, e.g. "skipped unreadable file"; level is one
+// of LogDebug through LogEmergency, and logs below the call's log level
+// are dropped
+// Emitters that do not implement LogEmitter ignore it.
+//
+// Example:
+//
+//	if err != nil {
+//	    backend.EmitStreamLog(emit, backend.LogWarning, "skipped unreadable file", map[string]interface{}{"path": path})
+//	    continue
+//	}
+func EmitStreamLog(emit StreamingEmitter, level, message string, fields map[string]interface{}) error {
+	l, ok := emit.(LogEmitter)
+	if !ok {
+		return nil
+	}
+	return l.EmitLog(level, message, fields)
+}
@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Page sizes of paginated tools (see ToolBuilder.Paginated)
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 1000
+)
+
+// cursorPrefix marks the offset cursors built by Paginate
+const cursorPrefix = "offset:"
+
+// PageRequest is the cursor and limit of a paginated call
+// Embed it in the arguments of a tool built with Paginated:
+//
+//	type listArgs struct {
+//	    backend.PageRequest
+//	    Dir string `json:"dir"`
+//	}
+type PageRequest struct {
+	// Cursor is the nextCursor of the previous page ("" = first page)
+	Cursor string `json:"cursor,omitempty"`
+
+	// Limit is the most items returned (0 = DefaultPageSize)
+	Limit int `json:"limit,omitempty"`
+}
+
+// Page is one page of a list result
+// NextCursor is empty on the last page; clients pass it back as the
+// cursor argument to get the next one.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Paginate returns the page of items req asks for
+// Cursors are opaque offsets into items, so the list must keep its
+// order between calls; tools paging through a remote API can return
+// that API's cursors in their own Page instead. A cursor Paginate did
+// not issue fails with ErrInvalidArguments.
+func Paginate[T any](items []T, req PageRequest) (Page[T], error) {
+	offset, err := DecodeCursor(req.Cursor)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+
+	page := Page[T]{Items: []T{}}
+	if offset >= len(items) {
+		return page, nil
+	}
+	end := min(offset+limit, len(items))
+	page.Items = items[offset:end]
+	if end < len(items) {
+		page.NextCursor = EncodeCursor(end)
+	}
+	return page, nil
+}
+
+// EncodeCursor returns the opaque cursor of an offset
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset of a cursor built by EncodeCursor; the
+// empty cursor is offset 0
+func DecodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(data), cursorPrefix) {
+		offset, err := strconv.Atoi(strings.TrimPrefix(string(data), cursorPrefix))
+		if err == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: invalid cursor %q", ErrInvalidArguments, cursor)
+}
@@ -0,0 +1,82 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4, 5, 6}
+
+	var got []int
+	req := backend.PageRequest{Limit: 3}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination does not end")
+		}
+		page, err := backend.Paginate(items, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+	if len(got) != len(items) || got[6] != 6 {
+		t.Errorf("pages = %v", got)
+	}
+
+	page, _ := backend.Paginate(items, backend.PageRequest{})
+	if len(page.Items) != 7 || page.NextCursor != "" {
+		t.Errorf("default limit page = %+v", page)
+	}
+	page, _ = backend.Paginate(items, backend.PageRequest{Cursor: backend.EncodeCursor(10)})
+	if page.Items == nil || len(page.Items) != 0 {
+		t.Errorf("page past the end = %+v", page)
+	}
+
+	for _, cursor := range []string{"not base64!", "b2Zmc2V0Oi0x", "eHl6"} {
+		if _, err := backend.Paginate(items, backend.PageRequest{Cursor: cursor}); !errors.Is(err, backend.ErrInvalidArguments) {
+			t.Errorf("cursor %q: err = %v", cursor, err)
+		}
+	}
+}
+
+type listArgs struct {
+	backend.PageRequest
+	Prefix string `json:"prefix"`
+}
+
+func TestToolBuilder_Paginated(t *testing.T) {
+	tool := backend.NewTool("list_items").StringParam("prefix", "Prefix", false).Paginated().Build()
+	if len(tool.Parameters) != 3 || tool.Parameters[2].Name != "limit" || *tool.Parameters[2].Maximum != backend.MaxPageSize {
+		t.Fatalf("parameters = %+v", tool.Parameters)
+	}
+
+	b := backend.NewBaseBackend("test")
+	names := []string{"a", "b", "c"}
+	backend.RegisterTypedTool(b, tool, func(ctx context.Context, in listArgs) (backend.Page[string], error) {
+		return backend.Paginate(names, in.PageRequest)
+	})
+
+	result, err := b.CallTool(context.Background(), "list_items", map[string]interface{}{"limit": float64(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := result.(backend.Page[string])
+	if len(page.Items) != 2 || page.NextCursor == "" {
+		t.Fatalf("first page = %+v", page)
+	}
+	result, err = b.CallTool(context.Background(), "list_items", map[string]interface{}{"cursor": page.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := result.(backend.Page[string]); len(page.Items) != 1 || page.Items[0] != "c" || page.NextCursor != "" {
+		t.Errorf("last page = %+v", page)
+	}
+}
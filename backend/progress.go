@@ -0,0 +1,32 @@
+package backend
+
+import "context"
+
+// ProgressReporter delivers progress updates of a tool call to the client
+type ProgressReporter func(current, total int64, message string) error
+
+type progressKey struct{}
+
+// WithProgressReporter attaches a progress reporter to a tool call's context
+// The protocol handler installs one when the client sent a progressToken.
+func WithProgressReporter(ctx context.Context, r ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressKey{}, r)
+}
+
+// EmitProgress reports progress from a regular (non-streaming) tool handler
+// It is a no-op when the client did not ask for progress. total <= 0 means
+// the total is unknown.
+//
+// Example:
+//
+//	for i, file := range files {
+//	    backend.EmitProgress(ctx, int64(i+1), int64(len(files)), "indexing "+file)
+//	    ...
+//	}
+func EmitProgress(ctx context.Context, current, total int64, message string) error {
+	r, ok := ctx.Value(progressKey{}).(ProgressReporter)
+	if !ok || r == nil {
+		return nil
+	}
+	return r(current, total, message)
+}
@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"context"
+	"sync"
+)
+
+// sourceRecorder collects the upstream sources a tool call reports
+type sourceRecorder struct {
+	mu      sync.Mutex
+	sources []string
+}
+
+type sourcesKey struct{}
+
+// WithSourceRecorder attaches a recorder of upstream sources to a tool
+// call's context and returns a function listing the sources recorded
+// The protocol handler installs one when provenance metadata is enabled.
+func WithSourceRecorder(ctx context.Context) (context.Context, func() []string) {
+	r := &sourceRecorder{}
+	list := func() []string {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return append([]string(nil), r.sources...)
+	}
+	return context.WithValue(ctx, sourcesKey{}, r), list
+}
+
+// RecordSource reports where a tool call's data came from (an API URL, a
+// database, a file), for the result's provenance metadata
+// It is a no-op when provenance is disabled; duplicates are recorded once.
+//
+// Example:
+//
+//	resp, err := client.Get(url)
+//	backend.RecordSource(ctx, url)
+func RecordSource(ctx context.Context, source string) {
+	r, ok := ctx.Value(sourcesKey{}).(*sourceRecorder)
+	if !ok || source == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sources {
+		if s == source {
+			return
+		}
+	}
+	r.sources = append(r.sources, source)
+}
@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/client"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// ============================================================
+// Tools
+// ============================================================
+
+// FetchTools lists a server's tools as local definitions
+func FetchTools(ctx context.Context, c *client.Client) ([]backend.ToolDefinition, error) {
+	list, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+
+	tools := make([]backend.ToolDefinition, len(list))
+	for i, info := range list {
+		tools[i] = backend.ToolDefinition{
+			Name:         info.Name,
+			Description:  info.Description,
+			Parameters:   parametersFromSchema(info.InputSchema),
+			OutputSchema: info.OutputSchema,
+		}
+	}
+	return tools, nil
+}
+
+// CallTool calls a server's tool with the caller's progress forwarded,
+// returning its content as a *backend.ToolResult or, when the server
+// flags it as an error, a *backend.ToolError
+func CallTool(ctx context.Context, c *client.Client, name string, args map[string]interface{}) (interface{}, error) {
+	result, err := c.CallToolWithProgress(ctx, name, args, func(current, total int64, message string) {
+		backend.EmitProgress(ctx, current, total, message)
+	})
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+	return toolResult(*result)
+}
+
+// upstreamError categorizes an error the server did not, so it is not
+// mistaken for a local failure
+func upstreamError(err error) error {
+	if _, ok := mcperr.As(err); ok || errors.Is(err, backend.ErrInvalidArguments) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	return mcperr.Upstream(err, "upstream request failed")
+}
+
+// parametersFromSchema turns an input schema's properties into parameters
+// that advertise the server's schema unchanged
+func parametersFromSchema(schema map[string]interface{}) []backend.Parameter {
+	properties, _ := schema["properties"].(map[string]interface{})
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]backend.Parameter, 0, len(names))
+	for _, name := range names {
+		prop, _ := properties[name].(map[string]interface{})
+		param := backend.Parameter{
+			Name:     name,
+			Required: required[name],
+			Schema:   prop,
+		}
+		param.Type, _ = prop["type"].(string)
+		param.Description, _ = prop["description"].(string)
+		params = append(params, param)
+	}
+	return params
+}
+
+// toolResult converts a tools/call result back into content, so the
+// local server re-encodes it unchanged
+func toolResult(result protocol.ToolCallResult) (interface{}, error) {
+	content := make([]backend.Content, 0, len(result.Content))
+	for _, item := range result.Content {
+		switch item.Type {
+		case "image":
+			data, _ := item.Data.(string)
+			content = append(content, backend.ImageContent{Data: data, MimeType: item.MimeType, Annotations: item.Annotations})
+		case "audio":
+			data, _ := item.Data.(string)
+			content = append(content, backend.AudioContent{Data: data, MimeType: item.MimeType, Annotations: item.Annotations})
+		case "resource":
+			if item.Resource != nil {
+				content = append(content, backend.EmbeddedResource{Resource: *item.Resource, Annotations: item.Annotations})
+			}
+		case "resource_link":
+			content = append(content, backend.ResourceLink{URI: item.URI, Name: item.Name, Description: item.Description, MimeType: item.MimeType, Annotations: item.Annotations})
+		default:
+			content = append(content, backend.TextContent{Text: item.Text, Annotations: item.Annotations})
+		}
+	}
+
+	if result.IsError {
+		return nil, &backend.ToolError{Message: "remote tool failed", Content: content}
+	}
+	return &backend.ToolResult{Content: content, Structured: result.StructuredContent}, nil
+}
+
+// ============================================================
+// Resources and prompts
+// ============================================================
+
+// FetchResources lists a server's resources; servers without resources
+// (resources/list not found) have none
+func FetchResources(ctx context.Context, c *client.Client) ([]backend.Resource, error) {
+	resources, err := c.ListResources(ctx)
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+	return resources, nil
+}
+
+// FetchPrompts lists a server's prompts; servers without prompts
+// (prompts/list not found) have none
+func FetchPrompts(ctx context.Context, c *client.Client) ([]backend.Prompt, error) {
+	prompts, err := c.ListPrompts(ctx)
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+	return prompts, nil
+}
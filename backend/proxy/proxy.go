@@ -0,0 +1,487 @@
+// Package proxy federates downstream MCP servers behind one server
+//
+// The proxy backend connects to each upstream with the client package,
+// over stdio (a child process), HTTP or WebSocket, and serves the upstream's tools and
+// prompts under its prefix ("gh.create_issue") and its resources as they
+// are. tools/call is forwarded with the caller's progress, categorized
+// errors and tool errors preserved. Tool lists follow the upstreams:
+// notifications/tools/list_changed re-imports that upstream's tools, and
+// a stdio upstream that exits is restarted on the next call.
+//
+// Importing the package registers the backend as "proxy" (the framework
+// does so):
+//
+//	# config.yaml
+//	backend:
+//	  type: proxy
+//	  config:
+//	    upstreams:
+//	      - name: github
+//	        prefix: gh
+//	        command: ./github-server
+//	        env: {GITHUB_TOKEN: "${secret:env:GITHUB_TOKEN}"}
+//	      - name: search
+//	        url: http://search.internal:8080/rpc
+//	        headers: {Authorization: "Bearer ${secret:env:SEARCH_TOKEN}"}
+//	      - name: notes
+//	        url: ws://notes.internal:9000/mcp
+//
+// The prefix defaults to the upstream's name; an empty prefix imports
+// tools under their own names.
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/client"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// connectTimeout bounds connecting to an upstream and listing its tools
+const connectTimeout = 10 * time.Second
+
+func init() {
+	backend.Register("proxy", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Upstream is a downstream MCP server
+// Exactly one of Stdio, HTTP and WebSocket is set.
+type Upstream struct {
+	// Name identifies the upstream in logs and health checks
+	Name string
+
+	// Prefix namespaces the upstream's tools and prompts; empty imports
+	// them under their own names
+	Prefix string
+
+	Stdio     *client.StdioConfig
+	HTTP      *client.HTTPConfig
+	WebSocket *client.WebSocketConfig
+}
+
+// qualify returns the local name of an upstream tool or prompt
+func (u Upstream) qualify(name string) string {
+	if u.Prefix == "" {
+		return name
+	}
+	return u.Prefix + backend.PrefixSeparator + name
+}
+
+// parseUpstreams reads the upstreams entry of a backend config map
+func parseUpstreams(config map[string]interface{}) ([]Upstream, error) {
+	list, _ := config["upstreams"].([]interface{})
+	if len(list) == 0 {
+		return nil, errors.New("proxy: at least one upstream is required")
+	}
+
+	upstreams := make([]Upstream, 0, len(list))
+	for i, entry := range list {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("proxy: upstream %d is not a map", i)
+		}
+
+		var u Upstream
+		u.Name, _ = m["name"].(string)
+		if u.Name == "" {
+			return nil, fmt.Errorf("proxy: upstream %d has no name", i)
+		}
+		u.Prefix = u.Name
+		if prefix, ok := m["prefix"].(string); ok {
+			u.Prefix = prefix
+		}
+
+		command, _ := m["command"].(string)
+		url, _ := m["url"].(string)
+		switch {
+		case command != "" && url != "":
+			return nil, fmt.Errorf("proxy: upstream %s has both command and url", u.Name)
+		case command != "":
+			u.Stdio = &client.StdioConfig{Command: command, Env: stringMap(m["env"])}
+			if args, ok := m["args"].([]interface{}); ok {
+				for _, arg := range args {
+					u.Stdio.Args = append(u.Stdio.Args, fmt.Sprint(arg))
+				}
+			}
+		case strings.HasPrefix(url, "ws://") || strings.HasPrefix(url, "wss://"):
+			u.WebSocket = &client.WebSocketConfig{URL: url, Headers: stringMap(m["headers"])}
+		case url != "":
+			u.HTTP = &client.HTTPConfig{URL: url, Headers: stringMap(m["headers"])}
+		default:
+			return nil, fmt.Errorf("proxy: upstream %s needs a command or a url", u.Name)
+		}
+		upstreams = append(upstreams, u)
+	}
+	return upstreams, nil
+}
+
+// countSet counts the true values
+func countSet(values ...bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
+
+// stringMap converts a config map's values to strings
+func stringMap(v interface{}) map[string]string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
+
+// Backend serves the tools of its upstreams
+type Backend struct {
+	*backend.BaseBackend
+
+	logger    *slog.Logger
+	upstreams []*upstream
+
+	// syncMu serializes tool imports across upstreams
+	syncMu sync.Mutex
+
+	closed atomic.Bool
+}
+
+// upstream is a connected Upstream
+type upstream struct {
+	Upstream
+
+	// connectMu serializes (re)connecting
+	connectMu sync.Mutex
+
+	mu        sync.RWMutex
+	client    *client.Client
+	served    map[string]backend.ToolDefinition
+	resources []backend.Resource
+	prompts   []backend.Prompt
+}
+
+// New creates an unconfigured proxy backend; Initialize reads its
+// upstreams from the config map
+func New() *Backend {
+	return &Backend{
+		BaseBackend: backend.NewBaseBackend("proxy"),
+		logger:      slog.Default(),
+	}
+}
+
+// NewWithUpstreams creates a proxy backend of the given upstreams
+func NewWithUpstreams(upstreams ...Upstream) *Backend {
+	b := New()
+	for _, u := range upstreams {
+		b.upstreams = append(b.upstreams, &upstream{Upstream: u})
+	}
+	return b
+}
+
+// Initialize connects to the upstreams and imports their tools, resources
+// and prompts
+// The config's upstreams entry is ignored when the backend was created
+// with NewWithUpstreams.
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if len(b.upstreams) == 0 {
+		upstreams, err := parseUpstreams(config)
+		if err != nil {
+			return err
+		}
+		for _, u := range upstreams {
+			b.upstreams = append(b.upstreams, &upstream{Upstream: u})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, u := range b.upstreams {
+		if seen[u.Name] {
+			return fmt.Errorf("proxy: duplicate upstream %s", u.Name)
+		}
+		seen[u.Name] = true
+		if n := countSet(u.Stdio != nil, u.HTTP != nil, u.WebSocket != nil); n != 1 {
+			return fmt.Errorf("proxy: upstream %s needs exactly one of stdio, http and websocket", u.Name)
+		}
+	}
+
+	for _, u := range b.upstreams {
+		if _, err := b.connect(ctx, u); err != nil {
+			b.Close()
+			return fmt.Errorf("proxy: upstream %s: %w", u.Name, err)
+		}
+	}
+	return nil
+}
+
+// Close disconnects from the upstreams
+func (b *Backend) Close() error {
+	b.closed.Store(true)
+	var errs []error
+	for _, u := range b.upstreams {
+		u.mu.Lock()
+		c := u.client
+		u.client = nil
+		u.mu.Unlock()
+		if c != nil {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("upstream %s: %w", u.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ============================================================
+// Connections
+// ============================================================
+
+// conn returns the upstream's client, reconnecting if it is gone
+func (b *Backend) conn(ctx context.Context, u *upstream) (*client.Client, error) {
+	u.mu.RLock()
+	c := u.client
+	u.mu.RUnlock()
+	if c != nil && c.Alive() {
+		return c, nil
+	}
+	return b.connect(ctx, u)
+}
+
+// connect opens a client to the upstream and imports its tools,
+// resources and prompts
+func (b *Backend) connect(ctx context.Context, u *upstream) (*client.Client, error) {
+	u.connectMu.Lock()
+	defer u.connectMu.Unlock()
+	if b.closed.Load() {
+		return nil, client.ErrClosed
+	}
+
+	// Another caller may have reconnected meanwhile
+	u.mu.RLock()
+	previous := u.client
+	u.mu.RUnlock()
+	if previous != nil && previous.Alive() {
+		return previous, nil
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	logger := b.logger.With("upstream", u.Name)
+	var c *client.Client
+	var err error
+	switch {
+	case u.Stdio != nil:
+		c, err = client.NewStdio(*u.Stdio, logger)
+	case u.WebSocket != nil:
+		c, err = client.NewWebSocket(connectCtx, *u.WebSocket, logger)
+	default:
+		c = client.NewHTTP(*u.HTTP, logger)
+	}
+	if err != nil {
+		return nil, mcperr.Upstream(err, "connect to upstream %s", u.Name)
+	}
+	c.OnNotification(func(method string, params map[string]interface{}) {
+		b.notify(u, method)
+	})
+
+	if _, err := c.Initialize(connectCtx); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+	if err := b.refresh(connectCtx, u, c); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	u.mu.Lock()
+	u.client = c
+	tools := len(u.served)
+	u.mu.Unlock()
+	if previous != nil {
+		go previous.Close()
+	}
+	if b.closed.Load() {
+		// Close ran while connecting
+		c.Close()
+		return nil, client.ErrClosed
+	}
+	logger.Info("upstream connected", "tools", tools)
+	return c, nil
+}
+
+// notify handles an upstream's list_changed notifications
+func (b *Backend) notify(u *upstream, method string) {
+	switch method {
+	case protocol.NotificationToolsListChanged,
+		"notifications/resources/list_changed",
+		"notifications/prompts/list_changed":
+	default:
+		return
+	}
+
+	u.mu.RLock()
+	c := u.client
+	u.mu.RUnlock()
+	if c == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err := b.refresh(ctx, u, c); err != nil {
+		b.logger.Warn("upstream refresh failed", "upstream", u.Name, "error", err)
+	}
+}
+
+// refresh re-imports an upstream's tools, resources and prompts
+func (b *Backend) refresh(ctx context.Context, u *upstream, c *client.Client) error {
+	tools, err := FetchTools(ctx, c)
+	if err != nil {
+		return fmt.Errorf("tools/list: %w", err)
+	}
+	resources, err := FetchResources(ctx, c)
+	if err != nil {
+		return fmt.Errorf("resources/list: %w", err)
+	}
+	prompts, err := FetchPrompts(ctx, c)
+	if err != nil {
+		return fmt.Errorf("prompts/list: %w", err)
+	}
+	for i := range prompts {
+		prompts[i].Name = u.qualify(prompts[i].Name)
+	}
+
+	b.syncTools(u, tools)
+
+	u.mu.Lock()
+	u.resources = resources
+	u.prompts = prompts
+	u.mu.Unlock()
+	return nil
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+// syncTools registers an upstream's tools under its prefix, replacing
+// changed ones and removing those it no longer serves
+func (b *Backend) syncTools(u *upstream, tools []backend.ToolDefinition) {
+	b.syncMu.Lock()
+	defer b.syncMu.Unlock()
+
+	u.mu.RLock()
+	previous := u.served
+	u.mu.RUnlock()
+
+	served := make(map[string]backend.ToolDefinition, len(tools))
+	for _, tool := range tools {
+		remote := tool.Name
+		tool.Name = u.qualify(remote)
+		handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return b.call(ctx, u, remote, args)
+		}
+
+		existing, ok := previous[tool.Name]
+		switch {
+		case !ok:
+			if err := b.AddTool(tool, handler); err != nil {
+				b.logger.Warn("upstream tool not imported", "upstream", u.Name, "tool", tool.Name, "error", err)
+				continue
+			}
+		case !sameTool(existing, tool):
+			b.ReplaceTool(tool, handler)
+		}
+		served[tool.Name] = tool
+	}
+
+	for name := range previous {
+		if _, ok := served[name]; !ok {
+			b.RemoveTool(name)
+		}
+	}
+
+	u.mu.Lock()
+	u.served = served
+	u.mu.Unlock()
+}
+
+// sameTool reports whether two definitions advertise the same tool
+func sameTool(a, b backend.ToolDefinition) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+// call forwards a tool call to an upstream
+func (b *Backend) call(ctx context.Context, u *upstream, name string, args map[string]interface{}) (interface{}, error) {
+	c, err := b.conn(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	return CallTool(ctx, c, name, args)
+}
+
+// ============================================================
+// Resources, prompts and health
+// ============================================================
+
+// ListResources merges the upstreams' resources
+func (b *Backend) ListResources() []backend.Resource {
+	var resources []backend.Resource
+	for _, u := range b.upstreams {
+		u.mu.RLock()
+		resources = append(resources, u.resources...)
+		u.mu.RUnlock()
+	}
+	return resources
+}
+
+// ListPrompts merges the upstreams' prompts under prefixed names
+func (b *Backend) ListPrompts() []backend.Prompt {
+	var prompts []backend.Prompt
+	for _, u := range b.upstreams {
+		u.mu.RLock()
+		prompts = append(prompts, u.prompts...)
+		u.mu.RUnlock()
+	}
+	sort.Slice(prompts, func(i, j int) bool { return prompts[i].Name < prompts[j].Name })
+	return prompts
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Each upstream gets a readiness check that pings it; servers without
+// ping count as reachable once they answer.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	for _, u := range b.upstreams {
+		u := u
+		r.RegisterReadiness("upstream:"+u.Name, func(ctx context.Context) error {
+			c, err := b.conn(ctx, u)
+			if err != nil {
+				return err
+			}
+			return c.Ping(ctx)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backend/proxy"
+	"github.com/SaherElMasry/go-mcp-framework/client"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+	"github.com/SaherElMasry/go-mcp-framework/transport/stdio"
+)
+
+// The test binary doubles as a stdio upstream when PROXY_TEST_SERVER is set
+func TestMain(m *testing.M) {
+	if os.Getenv("PROXY_TEST_SERVER") != "" {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		stdio.NewStdioTransport(protocol.NewHandler(upstreamBackend(), logger), logger).Run(context.Background())
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// upstreamBackend serves echo, whose text argument selects a failure
+func upstreamBackend() *backend.BaseBackend {
+	b := backend.NewBaseBackend("upstream")
+	tool := backend.NewTool("echo").
+		Description("Echo text").
+		StringParam("text", "Text to echo", true).
+		Build()
+	b.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		switch args["text"] {
+		case "crash":
+			os.Exit(1)
+		case "missing":
+			return nil, mcperr.NotFound("no such thing")
+		case "fail":
+			return nil, backend.NewToolError("tool failed")
+		}
+		backend.EmitProgress(ctx, 1, 2, "halfway")
+		return backend.NewTextResult("echo: " + args["text"].(string)), nil
+	})
+	return b
+}
+
+func toolNames(b backend.ServerBackend) string {
+	var names []string
+	for _, tool := range b.ListTools() {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// callEcho calls a proxied echo tool, returning its text and progress
+func callEcho(t *testing.T, b backend.ServerBackend, tool, text string) (string, []string, error) {
+	t.Helper()
+	var progress []string
+	ctx := backend.WithProgressReporter(context.Background(), func(current, total int64, message string) error {
+		progress = append(progress, message)
+		return nil
+	})
+	result, err := b.CallTool(ctx, tool, map[string]interface{}{"text": text})
+	if err != nil {
+		return "", progress, err
+	}
+	typed, ok := backend.AsToolResult(result)
+	if !ok || len(typed.Content) != 1 {
+		t.Fatalf("result = %#v", result)
+	}
+	return typed.Content[0].(backend.TextContent).Text, progress, nil
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestProxy_Stdio(t *testing.T) {
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := proxy.NewWithUpstreams(proxy.Upstream{
+		Name:   "local",
+		Prefix: "loc",
+		Stdio:  &client.StdioConfig{Command: self, Env: map[string]string{"PROXY_TEST_SERVER": "1"}},
+	})
+	if err := b.Initialize(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	if got := toolNames(b); got != "loc.echo" {
+		t.Fatalf("tools = %s", got)
+	}
+	tool, _ := b.GetTool("loc.echo")
+	if len(tool.Parameters) != 1 || tool.Parameters[0].Name != "text" || !tool.Parameters[0].Required {
+		t.Errorf("parameters = %+v", tool.Parameters)
+	}
+
+	text, progress, err := callEcho(t, b, "loc.echo", "hi")
+	if err != nil || text != "echo: hi" {
+		t.Fatalf("call = %q, %v", text, err)
+	}
+	if len(progress) != 1 || progress[0] != "halfway" {
+		t.Errorf("progress = %v", progress)
+	}
+
+	if _, _, err := callEcho(t, b, "loc.echo", "missing"); !errors.Is(err, mcperr.ErrNotFound) {
+		t.Errorf("categorized error = %v", err)
+	}
+	if _, _, err := callEcho(t, b, "loc.echo", "fail"); err == nil {
+		t.Error("tool error not returned")
+	} else if toolErr, ok := backend.AsToolError(err); !ok || toolErr.ResultContent()[0].(backend.TextContent).Text != "tool failed" {
+		t.Errorf("tool error = %v", err)
+	}
+
+	// A crashed upstream is restarted on the next call
+	if _, _, err := callEcho(t, b, "loc.echo", "crash"); err == nil {
+		t.Fatal("crash not reported")
+	}
+	waitFor(t, func() bool {
+		text, _, err := callEcho(t, b, "loc.echo", "again")
+		return err == nil && text == "echo: again"
+	})
+}
+
+func TestProxy_HTTP(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	remote := upstreamBackend()
+	broadcaster := transport.NewBroadcaster()
+	remote.OnToolsChanged(func() {
+		broadcaster.Broadcast([]byte(`{"jsonrpc":"2.0","method":"` + protocol.NotificationToolsListChanged + `"}`))
+	})
+	tr := httpTransport.NewHTTPTransport(protocol.NewHandler(remote, logger), httpTransport.HTTPConfig{MaxRequestSize: 1 << 20}, logger, remote, nil)
+	tr.SetBroadcaster(broadcaster)
+	server := httptest.NewServer(tr.Handler())
+	t.Cleanup(server.Close)
+
+	b := proxy.NewWithUpstreams(proxy.Upstream{
+		Name: "remote",
+		HTTP: &client.HTTPConfig{URL: server.URL + httpTransport.PathRPC},
+	})
+	if err := b.Initialize(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	if got := toolNames(b); got != "echo" {
+		t.Fatalf("tools = %s", got)
+	}
+	text, progress, err := callEcho(t, b, "echo", "hi")
+	if err != nil || text != "echo: hi" {
+		t.Fatalf("call = %q, %v", text, err)
+	}
+	if len(progress) != 1 || progress[0] != "halfway" {
+		t.Errorf("progress = %v", progress)
+	}
+	if _, _, err := callEcho(t, b, "echo", "missing"); !errors.Is(err, mcperr.ErrNotFound) {
+		t.Errorf("categorized error = %v", err)
+	}
+
+	// Tools added upstream are imported once it announces them
+	waitFor(t, func() bool { return broadcaster.Subscribers() == 1 })
+	remote.AddTool(backend.NewTool("added").Description("Added later").Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return backend.NewTextResult("added"), nil
+	})
+	waitFor(t, func() bool { return toolNames(b) == "added,echo" })
+}
+
+func TestProxy_Config(t *testing.T) {
+	for name, config := range map[string]map[string]interface{}{
+		"no upstreams": {},
+		"no name":      {"upstreams": []interface{}{map[string]interface{}{"url": "http://x"}}},
+		"no endpoint":  {"upstreams": []interface{}{map[string]interface{}{"name": "x"}}},
+		"both":         {"upstreams": []interface{}{map[string]interface{}{"name": "x", "url": "http://x", "command": "x"}}},
+		"unreachable":  {"upstreams": []interface{}{map[string]interface{}{"name": "x", "command": "/nonexistent/server"}}},
+	} {
+		if err := proxy.New().Initialize(context.Background(), config); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
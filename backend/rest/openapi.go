@@ -0,0 +1,418 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// openAPIMethods are the operations of a path item, in the order tools
+// are generated
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// invalidNameChars are the characters tool and parameter names may not hold
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// maxSchemaDepth bounds how deep referenced schemas are inlined
+const maxSchemaDepth = 32
+
+// openAPI converts an OpenAPI 3 document
+type openAPI struct {
+	doc map[string]interface{}
+}
+
+// parseOpenAPI generates an operation per endpoint of an OpenAPI 3 document
+//
+// Tools are named after operationId (else method and path), described by
+// summary and description, and take the path, query and header
+// parameters plus the properties of a JSON request body. Schemas are
+// inlined with their $refs resolved. The x-mcp-pagination extension of an
+// operation sets its pagination, which is otherwise recognized from its
+// query parameters. Deprecated operations and those whose request body
+// is not JSON are skipped.
+func parseOpenAPI(doc map[string]interface{}) (*Spec, error) {
+	version := fmt.Sprint(doc["openapi"])
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %s", version)
+	}
+	o := &openAPI{doc: doc}
+	spec := &Spec{BaseURL: o.serverURL(), Skipped: make(map[string]string)}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		item, _ := paths[path].(map[string]interface{})
+		for _, method := range openAPIMethods {
+			raw, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op, err := o.operation(path, method, item, raw)
+			if err != nil {
+				spec.Skipped[op.Name] = err.Error()
+				continue
+			}
+			spec.Operations = append(spec.Operations, op)
+		}
+	}
+	return spec, nil
+}
+
+// serverURL returns the first server's URL with its variables' defaults
+func (o *openAPI) serverURL() string {
+	servers, _ := o.doc["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]interface{})
+	u, _ := server["url"].(string)
+	variables, _ := server["variables"].(map[string]interface{})
+	for name, v := range variables {
+		variable, _ := v.(map[string]interface{})
+		if def, ok := variable["default"]; ok {
+			u = strings.ReplaceAll(u, "{"+name+"}", fmt.Sprint(def))
+		}
+	}
+	return strings.TrimSuffix(u, "/")
+}
+
+// operation converts one operation of a path item
+func (o *openAPI) operation(path, method string, item, raw map[string]interface{}) (Operation, error) {
+	op := Operation{
+		Name:   toolName(raw, method, path),
+		Method: strings.ToUpper(method),
+		Path:   path,
+	}
+	if deprecated, _ := raw["deprecated"].(bool); deprecated {
+		return op, errors.New("deprecated")
+	}
+
+	summary, _ := raw["summary"].(string)
+	description, _ := raw["description"].(string)
+	switch {
+	case summary == "" || strings.HasPrefix(description, summary):
+		op.Description = description
+	case description == "":
+		op.Description = summary
+	default:
+		op.Description = summary + "\n\n" + description
+	}
+
+	params, err := o.parameters(item, raw)
+	if err != nil {
+		return op, err
+	}
+	op.Params = params
+
+	body, err := o.requestBody(raw)
+	if err != nil {
+		return op, err
+	}
+	names := make(map[string]bool, len(op.Params))
+	for _, p := range op.Params {
+		names[p.Name] = true
+	}
+	for _, p := range body {
+		if names[p.Name] {
+			p.Name = "body_" + p.Name
+		}
+		op.Params = append(op.Params, p)
+	}
+
+	if ext, ok := raw["x-mcp-pagination"].(map[string]interface{}); ok {
+		op.Pagination = parsePagination(ext)
+	} else {
+		op.Pagination = detectPagination(op.Params)
+	}
+	return op, op.Validate()
+}
+
+// toolName names an operation's tool after its operationId, else its
+// method and path: "get_repos_owner_repo"
+func toolName(raw map[string]interface{}, method, path string) string {
+	name, _ := raw["operationId"].(string)
+	if name == "" {
+		name = method + "_" + strings.NewReplacer("{", "", "}", "").Replace(path)
+		name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ReplaceAll(name, "/", "_"), "_"), "_")
+	}
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// parameters converts the path item's and operation's parameters, the
+// operation's overriding those of the same name and location
+// Cookie parameters are not supported and left out.
+func (o *openAPI) parameters(item, raw map[string]interface{}) ([]Param, error) {
+	var params []Param
+	index := make(map[string]int)
+	for _, list := range []interface{}{item["parameters"], raw["parameters"]} {
+		entries, _ := list.([]interface{})
+		for _, entry := range entries {
+			p, err := o.resolve(entry)
+			if err != nil {
+				return nil, err
+			}
+			name, _ := p["name"].(string)
+			in, _ := p["in"].(string)
+			if in == "cookie" {
+				continue
+			}
+			if in != InPath && in != InQuery && in != InHeader {
+				return nil, fmt.Errorf("parameter %s: unknown location %q", name, in)
+			}
+
+			param := Param{Name: invalidNameChars.ReplaceAllString(name, "_"), In: in}
+			if param.Name != name {
+				param.Key = name
+			}
+			required, _ := p["required"].(bool)
+			param.Required = required || in == InPath
+
+			schema, _ := p["schema"].(map[string]interface{})
+			if schema == nil {
+				schema = mediaSchema(p["content"])
+			}
+			if param.Schema, err = o.schema(schema); err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", name, err)
+			}
+			if description, ok := p["description"].(string); ok && param.Schema["description"] == nil {
+				param.Schema["description"] = description
+			}
+
+			if i, ok := index[in+":"+name]; ok {
+				params[i] = param
+				continue
+			}
+			index[in+":"+name] = len(params)
+			params = append(params, param)
+		}
+	}
+	return params, nil
+}
+
+// requestBody converts a JSON request body: an object's properties
+// become body parameters, any other schema a payload parameter "body"
+func (o *openAPI) requestBody(raw map[string]interface{}) ([]Param, error) {
+	if raw["requestBody"] == nil {
+		return nil, nil
+	}
+	body, err := o.resolve(raw["requestBody"])
+	if err != nil {
+		return nil, err
+	}
+	content, _ := body["content"].(map[string]interface{})
+	if len(content) == 0 {
+		return nil, nil
+	}
+	media, _ := content["application/json"].(map[string]interface{})
+	if media == nil {
+		for _, mediaType := range sortedKeys(content) {
+			if strings.HasSuffix(strings.SplitN(mediaType, ";", 2)[0], "json") {
+				media, _ = content[mediaType].(map[string]interface{})
+				break
+			}
+		}
+	}
+	if media == nil {
+		return nil, fmt.Errorf("request body is not JSON (%s)", strings.Join(sortedKeys(content), ", "))
+	}
+
+	schema, _ := media["schema"].(map[string]interface{})
+	schema, err = o.schema(schema)
+	if err != nil {
+		return nil, fmt.Errorf("request body: %w", err)
+	}
+	bodyRequired, _ := body["required"].(bool)
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	if properties == nil || schema["type"] != "object" {
+		if description, ok := body["description"].(string); ok && schema["description"] == nil {
+			schema["description"] = description
+		}
+		return []Param{{Name: "body", In: InPayload, Required: bodyRequired, Schema: schema}}, nil
+	}
+
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			required[fmt.Sprint(name)] = true
+		}
+	}
+	params := make([]Param, 0, len(properties))
+	for _, name := range sortedKeys(properties) {
+		prop, _ := properties[name].(map[string]interface{})
+		param := Param{
+			Name:     invalidNameChars.ReplaceAllString(name, "_"),
+			In:       InBody,
+			Required: bodyRequired && required[name],
+			Schema:   prop,
+		}
+		if param.Name != name {
+			param.Key = name
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// mediaSchema returns the schema of a parameter's single content entry
+func mediaSchema(v interface{}) map[string]interface{} {
+	content, _ := v.(map[string]interface{})
+	for _, mediaType := range sortedKeys(content) {
+		media, _ := content[mediaType].(map[string]interface{})
+		schema, _ := media["schema"].(map[string]interface{})
+		return schema
+	}
+	return nil
+}
+
+// ============================================================
+// Schemas
+// ============================================================
+
+// resolve follows a local $ref ("#/components/parameters/owner")
+func (o *openAPI) resolve(v interface{}) (map[string]interface{}, error) {
+	m, _ := v.(map[string]interface{})
+	for i := 0; m != nil && m["$ref"] != nil; i++ {
+		ref, _ := m["$ref"].(string)
+		if i == maxSchemaDepth {
+			return nil, fmt.Errorf("$ref %s: too many indirections", ref)
+		}
+		target, err := o.pointer(ref)
+		if err != nil {
+			return nil, err
+		}
+		m = target
+	}
+	if m == nil {
+		return nil, errors.New("not an object")
+	}
+	return m, nil
+}
+
+// pointer returns the object a local JSON pointer names
+func (o *openAPI) pointer(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("$ref %s: only references within the document are supported", ref)
+	}
+	var v interface{} = o.doc
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref %s: not found", ref)
+		}
+		v = m[token]
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("$ref %s: not found", ref)
+	}
+	return m, nil
+}
+
+// schema converts an OpenAPI schema to a self-contained JSON Schema
+func (o *openAPI) schema(s map[string]interface{}) (map[string]interface{}, error) {
+	if s == nil {
+		return map[string]interface{}{}, nil
+	}
+	return o.convert(s, make(map[string]bool), 0)
+}
+
+// convert inlines references and drops the OpenAPI-only keywords
+// Recursive references end in an unconstrained object, and properties
+// only the server sets (readOnly) are left out of request schemas.
+func (o *openAPI) convert(s map[string]interface{}, seen map[string]bool, depth int) (map[string]interface{}, error) {
+	if ref, ok := s["$ref"].(string); ok {
+		if seen[ref] || depth >= maxSchemaDepth {
+			return map[string]interface{}{"type": "object"}, nil
+		}
+		target, err := o.pointer(ref)
+		if err != nil {
+			return nil, err
+		}
+		seen[ref] = true
+		defer delete(seen, ref)
+		return o.convert(target, seen, depth+1)
+	}
+
+	out := make(map[string]interface{}, len(s))
+	for k, v := range s {
+		switch k {
+		case "nullable", "discriminator", "xml", "externalDocs", "readOnly", "writeOnly":
+			continue
+		case "example":
+			out["examples"] = []interface{}{v}
+			continue
+		}
+
+		var err error
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if k == "properties" {
+				out[k], err = o.properties(v, seen, depth)
+			} else if k == "items" || k == "additionalProperties" || k == "not" {
+				out[k], err = o.convert(v, seen, depth+1)
+			} else {
+				out[k] = v
+			}
+		case []interface{}:
+			if k == "allOf" || k == "anyOf" || k == "oneOf" {
+				list := make([]interface{}, 0, len(v))
+				for _, e := range v {
+					m, _ := e.(map[string]interface{})
+					var c map[string]interface{}
+					if c, err = o.convert(m, seen, depth+1); err != nil {
+						break
+					}
+					list = append(list, c)
+				}
+				out[k] = list
+			} else {
+				out[k] = v
+			}
+		default:
+			out[k] = v
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if required, ok := out["required"].([]interface{}); ok {
+		props, _ := out["properties"].(map[string]interface{})
+		kept := make([]interface{}, 0, len(required))
+		for _, name := range required {
+			if _, ok := props[fmt.Sprint(name)]; ok || props == nil {
+				kept = append(kept, name)
+			}
+		}
+		out["required"] = kept
+	}
+	return out, nil
+}
+
+// properties converts an object's properties, leaving out readOnly ones
+func (o *openAPI) properties(props map[string]interface{}, seen map[string]bool, depth int) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(props))
+	for name, v := range props {
+		prop, _ := v.(map[string]interface{})
+		if prop == nil {
+			continue
+		}
+		if resolved, err := o.resolve(prop); err == nil {
+			if readOnly, _ := resolved["readOnly"].(bool); readOnly {
+				continue
+			}
+		}
+		c, err := o.convert(prop, seen, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = c
+	}
+	return out, nil
+}
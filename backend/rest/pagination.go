@@ -0,0 +1,293 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Pagination styles
+const (
+	// PageCursor endpoints return an opaque token selecting the next page
+	PageCursor = "cursor"
+
+	// PageNumber endpoints number their pages from 1
+	PageNumber = "page"
+
+	// PageOffset endpoints skip a number of items
+	PageOffset = "offset"
+
+	// PageLink endpoints name the next page in a Link header (RFC 8288)
+	PageLink = "link"
+)
+
+// Parameter names that select a page, by style
+var (
+	cursorParams = []string{"cursor", "page_token", "pageToken", "next_token", "nextToken",
+		"continuation_token", "continuationToken", "starting_after", "after", "marker"}
+	limitParams = []string{"per_page", "page_size", "pageSize", "limit", "size", "count"}
+)
+
+// Where responses commonly keep the next cursor and the page's items
+var (
+	cursorPaths = []string{"next_cursor", "nextCursor", "next_page_token", "nextPageToken",
+		"next_token", "nextToken", "meta.next_cursor", "pagination.next_cursor",
+		"response_metadata.next_cursor", "links.next", "next"}
+	itemPaths = []string{"items", "data", "results", "values", "records", "entries"}
+)
+
+// Pagination describes how an endpoint pages its results
+//
+// Each response of a paginated tool carries, in next, the arguments of
+// the call returning the following page, so the model need not know the
+// API's scheme.
+type Pagination struct {
+	// Style is PageCursor, PageNumber, PageOffset or PageLink
+	Style string
+
+	// Param is the tool parameter selecting the page; PageLink may leave
+	// it empty to take every query parameter of the next link
+	Param string
+
+	// Next is the dotted path of the next cursor in the response body
+	// (PageCursor; default: a common location such as "next_cursor")
+	// A next page URL is also accepted, its Param query value is used.
+	Next string
+
+	// Items is the dotted path of the page's items (PageNumber and
+	// PageOffset; default: the body when it is an array, else a common
+	// location such as "items" or "data")
+	Items string
+
+	// Limit is the page size parameter, so a short page ends the results
+	Limit string
+}
+
+// parsePagination reads a pagination mapping:
+// {style: cursor, param: cursor, next: meta.next_cursor}
+func parsePagination(m map[string]interface{}) *Pagination {
+	p := &Pagination{}
+	p.Style, _ = m["style"].(string)
+	p.Param, _ = m["param"].(string)
+	p.Next, _ = m["next"].(string)
+	p.Items, _ = m["items"].(string)
+	p.Limit, _ = m["limit"].(string)
+	return p
+}
+
+// detectPagination recognizes pagination from the query parameters of an
+// operation: a cursor parameter, page or offset
+func detectPagination(params []Param) *Pagination {
+	query := make(map[string]string)
+	for _, p := range params {
+		if p.In == InQuery {
+			query[p.key()] = p.Name
+		}
+	}
+	first := func(keys []string) string {
+		for _, k := range keys {
+			if name, ok := query[k]; ok {
+				return name
+			}
+		}
+		return ""
+	}
+
+	if name := first(cursorParams); name != "" {
+		return &Pagination{Style: PageCursor, Param: name}
+	}
+	if name := first([]string{"page"}); name != "" {
+		return &Pagination{Style: PageNumber, Param: name, Limit: first(limitParams)}
+	}
+	if name := first([]string{"offset", "skip", "start"}); name != "" {
+		return &Pagination{Style: PageOffset, Param: name, Limit: first(limitParams)}
+	}
+	return nil
+}
+
+// validate checks the style and that the parameters exist
+func (p *Pagination) validate(op *Operation) error {
+	switch p.Style {
+	case PageCursor, PageNumber, PageOffset:
+		if p.Param == "" {
+			return errors.New("param is required")
+		}
+	case PageLink:
+	default:
+		return fmt.Errorf("unknown style %q", p.Style)
+	}
+	for _, name := range []string{p.Param, p.Limit} {
+		if _, ok := op.param(name); name != "" && !ok {
+			return fmt.Errorf("no parameter %s", name)
+		}
+	}
+	return nil
+}
+
+// paginationHint is appended to the description of paginated tools
+const paginationHint = "Results are paginated: when the result has \"next\", call the tool again with those arguments for the following page."
+
+// nextPage returns the arguments of the call for the page after the one
+// args returned, nil on the last page
+func (op *Operation) nextPage(args map[string]interface{}, header http.Header, body interface{}) map[string]interface{} {
+	p := op.Pagination
+	var values map[string]interface{}
+
+	switch p.Style {
+	case PageCursor:
+		var cursor interface{}
+		switch c := lookup(body, p.Next, cursorPaths).(type) {
+		case string:
+			param, _ := op.param(p.Param)
+			cursor = cursorFromURL(c, param.key())
+		case float64:
+			cursor = c
+		}
+		if cursor == nil || cursor == "" {
+			return nil
+		}
+		values = map[string]interface{}{p.Param: cursor}
+
+	case PageNumber, PageOffset:
+		items, ok := lookup(body, p.Items, itemPaths).([]interface{})
+		if !ok || len(items) == 0 {
+			return nil
+		}
+		if limit := intArg(args[p.Limit], 0); limit > 0 && len(items) < limit {
+			return nil
+		}
+		if p.Style == PageNumber {
+			values = map[string]interface{}{p.Param: intArg(args[p.Param], 1) + 1}
+		} else {
+			values = map[string]interface{}{p.Param: intArg(args[p.Param], 0) + len(items)}
+		}
+
+	case PageLink:
+		link := nextLink(header)
+		if link == nil {
+			return nil
+		}
+		values = make(map[string]interface{})
+		for _, param := range op.Params {
+			if param.In != InQuery || (p.Param != "" && param.Name != p.Param) {
+				continue
+			}
+			if v := link.Query().Get(param.key()); v != "" {
+				values[param.Name] = queryValue(param, v)
+			}
+		}
+		if len(values) == 0 {
+			return nil
+		}
+	}
+
+	next := make(map[string]interface{}, len(args)+len(values))
+	for k, v := range args {
+		next[k] = v
+	}
+	for k, v := range values {
+		next[k] = v
+	}
+	return next
+}
+
+// lookup returns the value at a dotted path of body; without a path, the
+// body itself when it is an array, else the first of the defaults found
+func lookup(body interface{}, path string, defaults []string) interface{} {
+	if path != "" {
+		return at(body, path)
+	}
+	if list, ok := body.([]interface{}); ok {
+		return list
+	}
+	for _, d := range defaults {
+		if v := at(body, d); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+// at walks a dotted path of nested objects
+func at(v interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// cursorFromURL returns the key query value when the cursor is a next
+// page URL, else the cursor itself
+func cursorFromURL(cursor, key string) interface{} {
+	if !strings.HasPrefix(cursor, "http://") && !strings.HasPrefix(cursor, "https://") && !strings.HasPrefix(cursor, "/") {
+		return cursor
+	}
+	u, err := url.Parse(cursor)
+	if err != nil {
+		return cursor
+	}
+	if v := u.Query().Get(key); v != "" {
+		return v
+	}
+	return nil
+}
+
+// nextLink returns the rel="next" target of the Link headers
+func nextLink(header http.Header) *url.URL {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					if strings.EqualFold(rel, "next") {
+						u, err := url.Parse(target[1 : len(target)-1])
+						if err == nil {
+							return u
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// queryValue converts a query string value back to the parameter's type
+func queryValue(p Param, v string) interface{} {
+	switch p.Schema["type"] {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+// intArg reads an integer argument, def when unset
+func intArg(v interface{}, def int) int {
+	switch v := v.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
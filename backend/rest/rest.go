@@ -0,0 +1,487 @@
+// Package rest serves an existing HTTP API as tools, without Go handlers
+//
+// Tools are generated from an OpenAPI 3 document or from a YAML mapping
+// of tool names to endpoints (see LoadSpec). Each tool takes the
+// endpoint's path, query, header and JSON body parameters, with their
+// schemas, and returns the response's status and decoded body. Paginated
+// endpoints add the arguments of the next page's call to their results.
+// Requests are sent with the HTTP client of the tool's auth resource, so
+// operations can hold different credentials; errors keep their meaning:
+// 401 and 403 fail as permission denied, 404 as not found, 429 as rate
+// limited and 5xx as upstream failures, while other client errors are
+// tool errors the model can correct.
+//
+// Importing the package registers the backend as "rest" (the framework
+// does so):
+//
+//	# config.yaml
+//	backend:
+//	  type: rest
+//	  config:
+//	    spec: ./petstore.yaml
+//	    include: ["listPets", "showPetById"]
+//	    auth_resource: petstore
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultTimeout         = 30 * time.Second
+	DefaultMaxResponseSize = 10 << 20
+)
+
+func init() {
+	backend.Register("rest", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves the operations of one HTTP API as tools
+type Backend struct {
+	*backend.BaseBackend
+
+	logger *slog.Logger
+	spec   *Spec
+
+	baseURL         string
+	headers         map[string]string
+	client          *http.Client
+	timeout         time.Duration
+	maxResponseSize int64
+}
+
+// New creates a rest backend; Initialize loads its spec
+func New() *Backend {
+	return &Backend{
+		BaseBackend:     backend.NewBaseBackend("rest"),
+		logger:          slog.Default(),
+		client:          &http.Client{},
+		timeout:         DefaultTimeout,
+		maxResponseSize: DefaultMaxResponseSize,
+	}
+}
+
+// NewWithSpec creates a rest backend serving spec; the config's spec and
+// tools entries are then ignored
+func NewWithSpec(spec *Spec) *Backend {
+	b := New()
+	b.spec = spec
+	return b
+}
+
+// Initialize loads the spec and registers a tool per operation
+//
+// Config entries:
+//
+//	spec               OpenAPI 3 document or YAML mapping file
+//	tools              inline YAML mapping of tools, instead of spec
+//	base_url           API root (default: the spec's, else the auth resource's)
+//	auth_provider      auth provider of auth_resource (default: the backend's)
+//	auth_resource      auth resource of the operations without their own
+//	headers            headers sent with every request
+//	include            tool name patterns to serve (default: all), e.g. ["list*"]
+//	exclude            tool name patterns not to serve
+//	timeout            longest request (default "30s")
+//	max_response_size  largest response body in bytes (default 10MB)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if b.spec == nil {
+		spec, err := loadConfigSpec(config)
+		if err != nil {
+			return fmt.Errorf("rest: %w", err)
+		}
+		b.spec = spec
+	}
+	for name, reason := range b.spec.Skipped {
+		b.logger.Warn("operation not served", "tool", name, "reason", reason)
+	}
+
+	if s := backend.StringConfig(config, "timeout", ""); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("rest: invalid timeout %q", s)
+		}
+		b.timeout = d
+	}
+	if n := backend.IntConfig(config, "max_response_size", DefaultMaxResponseSize); n > 0 {
+		b.maxResponseSize = int64(n)
+	} else {
+		return errors.New("rest: max_response_size must be positive")
+	}
+	b.baseURL = strings.TrimSuffix(backend.StringConfig(config, "base_url", b.spec.BaseURL), "/")
+	b.headers = stringMap(config["headers"])
+
+	operations, err := filterOperations(b.spec.Operations, stringList(config["include"]), stringList(config["exclude"]))
+	if err != nil {
+		return fmt.Errorf("rest: %w", err)
+	}
+	if resource := backend.StringConfig(config, "auth_resource", ""); resource != "" {
+		for i := range operations {
+			if operations[i].Auth == nil {
+				operations[i].Auth = &auth.ToolResource{Provider: backend.StringConfig(config, "auth_provider", ""), Resource: resource}
+			}
+		}
+	}
+
+	spec := &Spec{BaseURL: b.baseURL, Operations: operations}
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("rest: %w", err)
+	}
+	for i := range operations {
+		op := &operations[i]
+		handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return b.call(ctx, op, args)
+		}
+		if err := b.AddTool(op.tool(), handler); err != nil {
+			return fmt.Errorf("rest: %w", err)
+		}
+	}
+
+	b.logger.Info("rest backend initialized",
+		"base_url", b.baseURL,
+		"tools", len(operations))
+	return nil
+}
+
+// loadConfigSpec reads the spec named by the config, or its inline tools
+func loadConfigSpec(config map[string]interface{}) (*Spec, error) {
+	file := backend.StringConfig(config, "spec", "")
+	tools, _ := config["tools"].(map[string]interface{})
+	switch {
+	case file != "" && tools != nil:
+		return nil, errors.New("spec and tools are exclusive")
+	case file != "":
+		return LoadSpecFile(file)
+	case tools != nil:
+		return parseMapping(map[string]interface{}{"tools": tools})
+	}
+	return nil, errors.New("spec or tools is required")
+}
+
+// filterOperations keeps the operations whose tool names match an
+// include pattern, if there are any, and no exclude pattern
+func filterOperations(ops []Operation, include, exclude []string) ([]Operation, error) {
+	matches := func(patterns []string, name string) (bool, error) {
+		for _, pattern := range patterns {
+			ok, err := path.Match(pattern, name)
+			if err != nil {
+				return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	kept := make([]Operation, 0, len(ops))
+	for _, op := range ops {
+		if len(include) > 0 {
+			ok, err := matches(include, op.Name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		excluded, err := matches(exclude, op.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !excluded {
+			kept = append(kept, op)
+		}
+	}
+	return kept, nil
+}
+
+// tool returns the operation's tool definition
+func (op *Operation) tool() backend.ToolDefinition {
+	description := op.Description
+	if description == "" {
+		description = op.Method + " " + op.Path
+	}
+	if op.Pagination != nil {
+		description += "\n\n" + paginationHint
+	}
+
+	params := make([]backend.Parameter, len(op.Params))
+	for i, p := range op.Params {
+		params[i] = backend.Parameter{
+			Name:     p.Name,
+			Required: p.Required,
+			Schema:   p.Schema,
+		}
+		params[i].Type, _ = p.Schema["type"].(string)
+		params[i].Description, _ = p.Schema["description"].(string)
+		params[i].Default = p.Schema["default"]
+		if enum, ok := p.Schema["enum"].([]interface{}); ok && params[i].Type == "string" {
+			for _, v := range enum {
+				params[i].Enum = append(params[i].Enum, fmt.Sprint(v))
+			}
+		}
+	}
+
+	return backend.ToolDefinition{
+		Name:        op.Name,
+		Description: description,
+		Parameters:  params,
+		Auth:        op.Auth,
+	}
+}
+
+// ============================================================
+// Calls
+// ============================================================
+
+// Response is the result of an operation's tool
+type Response struct {
+	Status int `json:"status"`
+
+	// Body is the decoded JSON body, else the body as text
+	Body interface{} `json:"body,omitempty"`
+
+	// Next holds the arguments of the call returning the next page, on
+	// paginated endpoints that have one
+	Next map[string]interface{} `json:"next,omitempty"`
+}
+
+// call sends an operation's request and decodes its response
+func (b *Backend) call(ctx context.Context, op *Operation, args map[string]interface{}) (interface{}, error) {
+	client := b.client
+	baseURL := b.baseURL
+	if resource, ok := auth.ResourceFromContext(ctx); ok {
+		authenticated, resourceURL, err := auth.ResourceHTTPClient(resource)
+		if err != nil {
+			return nil, fmt.Errorf("rest: auth resource of %s: %w", op.Name, err)
+		}
+		client = authenticated
+		if resourceURL != "" && !isAbsolute(baseURL) {
+			baseURL = strings.TrimSuffix(resourceURL, "/") + baseURL
+		}
+	}
+	if !isAbsolute(baseURL) {
+		return nil, fmt.Errorf("rest: %s: no base_url", op.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	req, err := b.newRequest(ctx, op, baseURL, args)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.HTTPClient(client).Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, mcperr.Timeout("%s %s: no response within %s", op.Method, op.Path, b.timeout)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, mcperr.Upstream(err, "%s %s", op.Method, req.URL.Redacted())
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, b.maxResponseSize+1))
+	if err != nil {
+		return nil, mcperr.Upstream(err, "%s %s: read response", op.Method, op.Path)
+	}
+	if int64(len(data)) > b.maxResponseSize {
+		return nil, mcperr.New(mcperr.CodeUpstream, "%s %s: response larger than %d bytes", op.Method, op.Path, b.maxResponseSize)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, statusError(op, resp, data)
+	}
+
+	result := &Response{Status: resp.StatusCode, Body: decodeBody(resp.Header.Get("Content-Type"), data)}
+	if op.Pagination != nil {
+		result.Next = op.nextPage(args, resp.Header, result.Body)
+	}
+	return result, nil
+}
+
+// newRequest builds an operation's request from the tool arguments
+func (b *Backend) newRequest(ctx context.Context, op *Operation, baseURL string, args map[string]interface{}) (*http.Request, error) {
+	reqPath := op.Path
+	query := url.Values{}
+	header := http.Header{}
+	var body interface{}
+	fields := make(map[string]interface{})
+
+	for _, p := range op.Params {
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			if p.Required && p.In == InPath {
+				return nil, fmt.Errorf("%w: %s is required", backend.ErrInvalidArguments, p.Name)
+			}
+			continue
+		}
+		switch p.In {
+		case InPath:
+			reqPath = strings.ReplaceAll(reqPath, "{"+p.key()+"}", url.PathEscape(formatValue(v)))
+		case InQuery:
+			if list, ok := v.([]interface{}); ok {
+				for _, e := range list {
+					query.Add(p.key(), formatValue(e))
+				}
+			} else {
+				query.Set(p.key(), formatValue(v))
+			}
+		case InHeader:
+			header.Set(p.key(), formatValue(v))
+		case InBody:
+			fields[p.key()] = v
+		case InPayload:
+			body = v
+		}
+	}
+	if len(fields) > 0 {
+		body = fields
+	}
+
+	target := baseURL + reqPath
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, op.Method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range b.headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// statusError categorizes an error response
+func statusError(op *Operation, resp *http.Response, data []byte) error {
+	detail := strings.TrimSpace(string(data))
+	if len(detail) > 1024 {
+		detail = detail[:1024] + "..."
+	}
+	msg := fmt.Sprintf("%s %s: HTTP %d: %s", op.Method, op.Path, resp.StatusCode, detail)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return mcperr.NotFound("%s", msg)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return mcperr.PermissionDenied("%s", msg)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return mcperr.RateLimited(time.Duration(seconds)*time.Second, "%s", msg)
+	case resp.StatusCode >= 500:
+		return mcperr.Upstream(errors.New(msg), "request failed")
+	}
+	// The request was wrong: let the model read why and fix it
+	return backend.NewToolError("HTTP %d: %s", resp.StatusCode, detail)
+}
+
+// decodeBody decodes a JSON body; other bodies are returned as text, or
+// described when they are binary
+func decodeBody(contentType string, data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if strings.HasSuffix(mediaType, "json") {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err == nil {
+			return v
+		}
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return fmt.Sprintf("(%d bytes of %s)", len(data), mediaType)
+}
+
+// formatValue renders an argument for a path, query or header
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// isAbsolute reports whether a base URL has a scheme and host
+func isAbsolute(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// ============================================================
+// Config helpers
+// ============================================================
+
+// stringMap reads a map of strings
+func stringMap(v interface{}) map[string]string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
+
+// stringList reads a list of strings
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	out := make([]string, 0, len(list))
+	for _, e := range list {
+		out = append(out, fmt.Sprint(e))
+	}
+	return out
+}
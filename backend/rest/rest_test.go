@@ -0,0 +1,350 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backend/rest"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// request is what the test API received
+type request struct {
+	Method, Path, Query, Auth, Trace string
+	Body                             map[string]interface{}
+}
+
+// fakeAPI serves a small issue tracker
+func fakeAPI(requests *[]request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.RawQuery,
+			Auth:   r.Header.Get("Authorization"),
+			Trace:  r.Header.Get("X-Trace-ID"),
+		}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			json.Unmarshal(data, &req.Body)
+		}
+		*requests = append(*requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/repos/acme/app/issues" && r.Method == "GET":
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `[{"number":3}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"number":1},{"number":2}]`)
+		case r.URL.Path == "/repos/acme/app/issues" && r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"number":4}`)
+		case r.URL.Path == "/events":
+			if r.URL.Query().Get("cursor") == "" {
+				fmt.Fprint(w, `{"data":[{"id":"e1"}],"meta":{"next_cursor":"c2"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":[{"id":"e2"}],"meta":{"next_cursor":null}}`)
+		case r.URL.Path == "/invalid":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"title is too long"}`)
+		case r.URL.Path == "/private":
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/busy":
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestBackend_Mapping(t *testing.T) {
+	var requests []request
+	api := fakeAPI(&requests)
+	defer api.Close()
+
+	provider := auth.NewStaticHeaderProvider("default", auth.StaticHeaderConfig{BearerToken: "write-token"})
+	provider.RegisterResource(auth.ResourceConfig{ID: "writer", Config: map[string]interface{}{"base_url": api.URL}})
+
+	b := rest.New()
+	b.SetAuthProvider(provider)
+	ctx := context.Background()
+	err := b.Initialize(ctx, map[string]interface{}{
+		"base_url": api.URL,
+		"tools": map[string]interface{}{
+			"list_issues": map[string]interface{}{
+				"description": "List a repository's issues",
+				"path":        "/repos/{owner}/{repo}/issues",
+				"query": map[string]interface{}{
+					"state":    map[string]interface{}{"type": "string", "enum": []interface{}{"open", "closed"}},
+					"page":     "integer",
+					"per_page": "integer",
+				},
+			},
+			"create_issue": map[string]interface{}{
+				"method": "post",
+				"path":   "/repos/{owner}/{repo}/issues",
+				"body": map[string]interface{}{
+					"title":  map[string]interface{}{"type": "string", "required": true},
+					"labels": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				},
+				"auth_resource": "writer",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	list, ok := b.GetTool("list_issues")
+	if !ok || !strings.Contains(list.Description, "paginated") || len(list.Parameters) != 5 {
+		t.Fatalf("list_issues = %+v", list)
+	}
+	for _, p := range list.Parameters {
+		if p.Name == "state" && (p.Type != "string" || len(p.Enum) != 2) {
+			t.Errorf("state parameter %+v", p)
+		}
+		if p.Name == "owner" && !p.Required {
+			t.Errorf("path parameter owner is optional")
+		}
+	}
+
+	result, err := b.CallTool(ctx, "list_issues", map[string]interface{}{
+		"owner": "acme", "repo": "app", "state": "open", "per_page": float64(2),
+	})
+	if err != nil {
+		t.Fatalf("list_issues failed: %v", err)
+	}
+	resp := result.(*rest.Response)
+	if resp.Status != 200 || len(resp.Body.([]interface{})) != 2 {
+		t.Errorf("response %+v", resp)
+	}
+	if resp.Next["page"] != 2 || resp.Next["state"] != "open" {
+		t.Fatalf("next %v", resp.Next)
+	}
+	if requests[0].Query != "per_page=2&state=open" || requests[0].Auth != "" {
+		t.Errorf("request %+v", requests[0])
+	}
+
+	result, err = b.CallTool(ctx, "list_issues", resp.Next)
+	if err != nil {
+		t.Fatalf("second page failed: %v", err)
+	}
+	if next := result.(*rest.Response).Next; next != nil {
+		t.Errorf("short page has next %v", next)
+	}
+
+	result, err = b.CallTool(ctx, "create_issue", map[string]interface{}{
+		"owner": "acme", "repo": "app", "title": "Crash", "labels": []interface{}{"bug"},
+	})
+	if err != nil {
+		t.Fatalf("create_issue failed: %v", err)
+	}
+	created := requests[len(requests)-1]
+	if result.(*rest.Response).Status != 201 || created.Auth != "Bearer write-token" ||
+		created.Body["title"] != "Crash" || created.Body["owner"] != nil {
+		t.Errorf("create request %+v", created)
+	}
+
+	create, _ := b.GetTool("create_issue")
+	if err := backend.ValidateArguments(create, map[string]interface{}{"owner": "acme", "repo": "app"}); !errors.Is(err, backend.ErrInvalidArguments) {
+		t.Errorf("missing title: %v", err)
+	}
+}
+
+const openAPISpec = `
+openapi: 3.0.3
+servers:
+  - url: "{scheme}://localhost/api"
+    variables:
+      scheme: {default: https}
+paths:
+  /events:
+    get:
+      operationId: listEvents
+      summary: List events
+      parameters:
+        - $ref: '#/components/parameters/Cursor'
+        - name: X-Trace-ID
+          in: header
+          schema: {type: string}
+  /notes:
+    post:
+      operationId: createNote
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Note'}
+  /uploads:
+    post:
+      operationId: upload
+      requestBody:
+        content:
+          application/octet-stream:
+            schema: {type: string, format: binary}
+  /legacy:
+    get:
+      operationId: legacy
+      deprecated: true
+components:
+  parameters:
+    Cursor:
+      name: cursor
+      in: query
+      description: Page token
+      schema: {type: string}
+  schemas:
+    Note:
+      type: object
+      required: [id, text]
+      properties:
+        id: {type: string, readOnly: true}
+        text: {type: string, example: hello}
+        parent: {$ref: '#/components/schemas/Note'}
+        tags:
+          type: array
+          nullable: true
+          items: {type: string}
+`
+
+func TestBackend_OpenAPI(t *testing.T) {
+	var requests []request
+	api := fakeAPI(&requests)
+	defer api.Close()
+
+	spec, err := rest.LoadSpec([]byte(openAPISpec))
+	if err != nil {
+		t.Fatalf("LoadSpec failed: %v", err)
+	}
+	if spec.BaseURL != "https://localhost/api" || len(spec.Operations) != 2 ||
+		spec.Skipped["upload"] == "" || spec.Skipped["legacy"] != "deprecated" {
+		t.Fatalf("spec %+v", spec)
+	}
+
+	note := spec.Operations[1]
+	if note.Name != "createNote" || len(note.Params) != 3 {
+		t.Fatalf("createNote %+v", note)
+	}
+	for _, p := range note.Params {
+		switch p.Name {
+		case "text":
+			if !p.Required || p.Schema["examples"] == nil {
+				t.Errorf("text %+v", p)
+			}
+		case "parent":
+			if p.Schema["type"] != "object" || p.Schema["properties"] != nil {
+				t.Errorf("recursive parent schema %v", p.Schema)
+			}
+		case "tags":
+			if p.Schema["nullable"] != nil {
+				t.Errorf("tags schema %v", p.Schema)
+			}
+		default:
+			t.Errorf("unexpected parameter %s", p.Name)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	os.WriteFile(path, []byte(openAPISpec), 0600)
+	b := rest.New()
+	ctx := context.Background()
+	if err := b.Initialize(ctx, map[string]interface{}{
+		"spec":     path,
+		"base_url": api.URL,
+		"include":  []interface{}{"list*"},
+		"headers":  map[string]interface{}{"User-Agent": "mcp"},
+	}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if tools := b.ListTools(); len(tools) != 1 || tools[0].Name != "listEvents" {
+		t.Fatalf("tools %+v", tools)
+	}
+
+	result, err := b.CallTool(ctx, "listEvents", map[string]interface{}{"X-Trace-ID": "t1"})
+	if err != nil {
+		t.Fatalf("listEvents failed: %v", err)
+	}
+	next := result.(*rest.Response).Next
+	if next["cursor"] != "c2" || requests[0].Trace != "t1" {
+		t.Fatalf("next %v, request %+v", next, requests[0])
+	}
+	result, err = b.CallTool(ctx, "listEvents", next)
+	if err != nil || result.(*rest.Response).Next != nil || requests[1].Query != "cursor=c2" {
+		t.Errorf("last page: %+v, %v", result, err)
+	}
+}
+
+func TestBackend_Errors(t *testing.T) {
+	var requests []request
+	api := fakeAPI(&requests)
+	defer api.Close()
+
+	tools := map[string]interface{}{}
+	for _, name := range []string{"invalid", "private", "busy", "broken", "missing"} {
+		tools[name] = map[string]interface{}{"path": "/" + name}
+	}
+	b := rest.New()
+	ctx := context.Background()
+	if err := b.Initialize(ctx, map[string]interface{}{"base_url": api.URL, "tools": tools}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	_, err := b.CallTool(ctx, "invalid", nil)
+	var toolErr *backend.ToolError
+	if !errors.As(err, &toolErr) || !strings.Contains(toolErr.Message, "title is too long") {
+		t.Errorf("422: %v", err)
+	}
+	for name, code := range map[string]mcperr.Code{
+		"private": mcperr.CodePermissionDenied,
+		"busy":    mcperr.CodeRateLimited,
+		"broken":  mcperr.CodeUpstream,
+		"missing": mcperr.CodeNotFound,
+	} {
+		if _, err := b.CallTool(ctx, name, nil); mcperr.CodeOf(err) != code {
+			t.Errorf("%s: %v, want %s", name, err, code)
+		}
+	}
+	_, err = b.CallTool(ctx, "busy", nil)
+	if d, ok := mcperr.RetryAfter(err); !ok || d.Seconds() != 7 {
+		t.Errorf("retry after %s", d)
+	}
+}
+
+func TestSpec_Validate(t *testing.T) {
+	tests := map[string]string{
+		"unused path parameter": "tools: {get: {path: /a, path_params: {id: string}}}",
+		"unknown pagination":    "tools: {get: {path: /a, pagination: {style: magic}}}",
+		"body on GET":           "tools: {get: {path: /a, body: {x: string}}}",
+		"bad method":            "tools: {get: {method: FETCH, path: /a}}",
+		"relative path":         "tools: {get: {path: a}}",
+	}
+	for name, doc := range tests {
+		spec, err := rest.LoadSpec([]byte(doc))
+		if err == nil {
+			err = spec.Validate()
+		}
+		if err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	if _, err := rest.LoadSpec([]byte("swagger: '2.0'")); err == nil {
+		t.Error("swagger 2.0 accepted")
+	}
+}
@@ -0,0 +1,425 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+)
+
+// Parameter locations
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+
+	// InBody is a property of the JSON request body
+	InBody = "body"
+
+	// InPayload is the whole JSON request body
+	InPayload = "payload"
+)
+
+// methods are the HTTP methods an operation may use
+var methods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true,
+	"PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+// placeholder matches a path parameter placeholder, "{owner}"
+var placeholder = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// Spec describes the HTTP API a rest backend serves as tools
+type Spec struct {
+	// BaseURL is the API root operation paths are relative to
+	BaseURL string
+
+	// Operations become one tool each
+	Operations []Operation
+
+	// Skipped names the operations of an OpenAPI document that could not
+	// be served, with the reason
+	Skipped map[string]string
+}
+
+// Operation is an HTTP endpoint served as a tool
+type Operation struct {
+	// Name is the tool name
+	Name        string
+	Description string
+	Method      string
+
+	// Path is relative to the base URL, with a {name} placeholder per
+	// path parameter: "/repos/{owner}/{repo}/issues"
+	Path string
+
+	Params []Param
+
+	// Auth binds the tool to the auth resource whose HTTP client sends
+	// its requests (see auth.ToolResource)
+	Auth *auth.ToolResource
+
+	// Pagination describes how the endpoint pages its results, if it does
+	Pagination *Pagination
+}
+
+// Param is an operation parameter, exposed as a tool parameter
+type Param struct {
+	// Name is the tool parameter name
+	Name string
+
+	// Key is the name sent upstream (default: Name), for names that are
+	// not valid tool parameters, such as "X-Request-ID"
+	Key string
+
+	// In is where the value goes: InPath, InQuery, InHeader, InBody or
+	// InPayload
+	In string
+
+	Required bool
+
+	// Schema is the parameter's JSON Schema, with its description
+	Schema map[string]interface{}
+}
+
+// key returns the name the parameter is sent under
+func (p Param) key() string {
+	if p.Key != "" {
+		return p.Key
+	}
+	return p.Name
+}
+
+// param returns the operation's parameter named name
+func (op *Operation) param(name string) (Param, bool) {
+	for _, p := range op.Params {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// Validate checks the operation's method, path, parameters and pagination
+func (op *Operation) Validate() error {
+	if op.Name == "" {
+		return errors.New("operation without a name")
+	}
+	if !methods[op.Method] {
+		return fmt.Errorf("%s: unsupported method %q", op.Name, op.Method)
+	}
+	if !strings.HasPrefix(op.Path, "/") {
+		return fmt.Errorf("%s: path %q must start with /", op.Name, op.Path)
+	}
+
+	seen := make(map[string]bool, len(op.Params))
+	pathParams := make(map[string]bool)
+	var body, payload bool
+	for _, p := range op.Params {
+		if p.Name == "" {
+			return fmt.Errorf("%s: parameter without a name", op.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("%s: duplicate parameter %s", op.Name, p.Name)
+		}
+		seen[p.Name] = true
+
+		switch p.In {
+		case InPath:
+			pathParams[p.key()] = true
+		case InQuery, InHeader:
+		case InBody:
+			body = true
+		case InPayload:
+			if payload {
+				return fmt.Errorf("%s: more than one payload parameter", op.Name)
+			}
+			payload = true
+		default:
+			return fmt.Errorf("%s: parameter %s has unknown location %q", op.Name, p.Name, p.In)
+		}
+	}
+	if body && payload {
+		return fmt.Errorf("%s: body and payload parameters are exclusive", op.Name)
+	}
+	if (body || payload) && (op.Method == "GET" || op.Method == "HEAD") {
+		return fmt.Errorf("%s: %s request with a body", op.Name, op.Method)
+	}
+
+	for _, m := range placeholder.FindAllStringSubmatch(op.Path, -1) {
+		if !pathParams[m[1]] {
+			return fmt.Errorf("%s: no parameter for {%s} in %s", op.Name, m[1], op.Path)
+		}
+		delete(pathParams, m[1])
+	}
+	for key := range pathParams {
+		return fmt.Errorf("%s: path parameter %s is not in %s", op.Name, key, op.Path)
+	}
+
+	if op.Auth != nil {
+		if err := op.Auth.Validate(); err != nil {
+			return fmt.Errorf("%s: auth: %w", op.Name, err)
+		}
+	}
+	if op.Pagination != nil {
+		if err := op.Pagination.validate(op); err != nil {
+			return fmt.Errorf("%s: pagination: %w", op.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks every operation and that tool names are unique
+func (s *Spec) Validate() error {
+	if len(s.Operations) == 0 {
+		return errors.New("spec has no operations")
+	}
+	names := make(map[string]bool, len(s.Operations))
+	for i := range s.Operations {
+		op := &s.Operations[i]
+		if err := op.Validate(); err != nil {
+			return err
+		}
+		if names[op.Name] {
+			return fmt.Errorf("duplicate tool %s", op.Name)
+		}
+		names[op.Name] = true
+	}
+	return nil
+}
+
+// ============================================================
+// Loading
+// ============================================================
+
+// LoadSpecFile reads an OpenAPI 3 document or a YAML mapping
+func LoadSpecFile(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := LoadSpec(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// LoadSpec parses an OpenAPI 3 document, in JSON or YAML, or a YAML
+// mapping of tools to endpoints
+//
+// A mapping lists the base URL and, per tool, the method (default GET),
+// path and parameters grouped by location: path_params, query, headers
+// and body. Parameters are JSON Schemas, or just a type, plus required
+// and key entries; undeclared path parameters are required strings:
+//
+//	base_url: https://api.github.com
+//	tools:
+//	  list_issues:
+//	    description: List a repository's issues
+//	    method: GET
+//	    path: /repos/{owner}/{repo}/issues
+//	    query:
+//	      state: {type: string, enum: [open, closed, all]}
+//	      page: integer
+//	      per_page: {type: integer, maximum: 100}
+//	    auth_resource: github-readonly
+//	  create_issue:
+//	    method: POST
+//	    path: /repos/{owner}/{repo}/issues
+//	    body:
+//	      title: {type: string, required: true}
+//	      labels: {type: array, items: {type: string}}
+//	    auth_resource: github-write
+func LoadSpec(data []byte) (*Spec, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	m, ok := normalize(doc).(map[string]interface{})
+	if !ok {
+		return nil, errors.New("spec is not a map")
+	}
+
+	switch {
+	case m["openapi"] != nil:
+		return parseOpenAPI(m)
+	case m["swagger"] != nil:
+		return nil, errors.New("swagger 2.0 documents are not supported, convert them to OpenAPI 3")
+	default:
+		return parseMapping(m)
+	}
+}
+
+// normalize turns the map[interface{}]interface{} YAML yields for
+// non-string keys (such as response codes) into map[string]interface{}
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalize(e)
+		}
+		return v
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalize(e)
+		}
+		return v
+	}
+	return v
+}
+
+// parseMapping reads a YAML mapping (see LoadSpec)
+func parseMapping(m map[string]interface{}) (*Spec, error) {
+	spec := &Spec{}
+	spec.BaseURL, _ = m["base_url"].(string)
+
+	tools, ok := m["tools"].(map[string]interface{})
+	if !ok || len(tools) == 0 {
+		return nil, errors.New("tools is required")
+	}
+	for _, name := range sortedKeys(tools) {
+		t, ok := tools[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("tool %s is not a map", name)
+		}
+		op, err := parseMappedOperation(name, t)
+		if err != nil {
+			return nil, fmt.Errorf("tool %s: %w", name, err)
+		}
+		spec.Operations = append(spec.Operations, op)
+	}
+	return spec, nil
+}
+
+// parseMappedOperation reads one tool of a mapping
+func parseMappedOperation(name string, t map[string]interface{}) (Operation, error) {
+	op := Operation{Name: name}
+	op.Description, _ = t["description"].(string)
+	method, _ := t["method"].(string)
+	op.Method = strings.ToUpper(method)
+	if op.Method == "" {
+		op.Method = "GET"
+	}
+	op.Path, _ = t["path"].(string)
+
+	for _, group := range []struct{ key, in string }{
+		{"path_params", InPath},
+		{"query", InQuery},
+		{"headers", InHeader},
+		{"body", InBody},
+	} {
+		params, ok := t[group.key].(map[string]interface{})
+		if !ok {
+			if t[group.key] != nil {
+				return op, fmt.Errorf("%s is not a map", group.key)
+			}
+			continue
+		}
+		for _, pname := range sortedKeys(params) {
+			p, err := parseMappedParam(pname, group.in, params[pname])
+			if err != nil {
+				return op, err
+			}
+			op.Params = append(op.Params, p)
+		}
+	}
+
+	// Placeholders without a declared parameter are required strings
+	for _, m := range placeholder.FindAllStringSubmatch(op.Path, -1) {
+		declared := false
+		for _, p := range op.Params {
+			declared = declared || (p.In == InPath && p.key() == m[1])
+		}
+		if !declared {
+			op.Params = append(op.Params, Param{
+				Name:     m[1],
+				In:       InPath,
+				Required: true,
+				Schema:   map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+
+	resource, _ := t["auth_resource"].(string)
+	if resource != "" {
+		op.Auth = &auth.ToolResource{Resource: resource}
+		op.Auth.Provider, _ = t["auth_provider"].(string)
+		if scopes, ok := t["auth_scopes"].([]interface{}); ok {
+			for _, s := range scopes {
+				op.Auth.Scopes = append(op.Auth.Scopes, fmt.Sprint(s))
+			}
+		}
+	}
+
+	switch p := t["pagination"].(type) {
+	case nil:
+		op.Pagination = detectPagination(op.Params)
+	case map[string]interface{}:
+		op.Pagination = parsePagination(p)
+	case string:
+		op.Pagination = &Pagination{Style: p}
+		if detected := detectPagination(op.Params); detected != nil && detected.Style == p {
+			op.Pagination = detected
+		}
+	default:
+		return op, errors.New("pagination is not a map")
+	}
+	return op, nil
+}
+
+// parseMappedParam reads a parameter: a type name or a JSON Schema with
+// optional required and key entries
+func parseMappedParam(name, in string, v interface{}) (Param, error) {
+	p := Param{Name: name, In: in, Required: in == InPath}
+	switch v := v.(type) {
+	case nil:
+		p.Schema = map[string]interface{}{"type": "string"}
+	case string:
+		p.Schema = map[string]interface{}{"type": v}
+	case map[string]interface{}:
+		p.Schema = make(map[string]interface{}, len(v))
+		for k, e := range v {
+			switch k {
+			case "required":
+				required, ok := e.(bool)
+				if !ok {
+					return p, fmt.Errorf("parameter %s: required is not a boolean", name)
+				}
+				p.Required = required
+			case "key":
+				p.Key, _ = e.(string)
+			default:
+				p.Schema[k] = e
+			}
+		}
+		if p.Schema["type"] == nil && p.Schema["enum"] == nil {
+			p.Schema["type"] = "string"
+		}
+	default:
+		return p, fmt.Errorf("parameter %s is not a type or a schema", name)
+	}
+	return p, nil
+}
+
+// sortedKeys returns a map's keys in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,104 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ResultEmitter is implemented by emitters that can send a streaming
+// tool's aggregate result (the engine's emitter does)
+type ResultEmitter interface {
+	EmitResult(result interface{}) error
+}
+
+// EmitResult sends the aggregate result of a streaming tool call, e.g.
+// the total number of matches and a summary, once its data is emitted
+// Streaming clients receive it as a result event before the end event;
+// tools/call callers receive it as the call's result (see CallTool).
+// Emitters that do not implement ResultEmitter ignore it.
+//
+// Example:
+//
+//	for _, m := range matches {
+//	    emit.EmitData(m)
+//	}
+//	return backend.EmitResult(emit, SearchSummary{Total: len(matches)})
+func EmitResult(emit StreamingEmitter, result interface{}) error {
+	r, ok := emit.(ResultEmitter)
+	if !ok {
+		return nil
+	}
+	return r.EmitResult(result)
+}
+
+// collectingEmitter runs a streaming tool for a tools/call caller,
+// keeping its data chunks and result and reporting its progress and logs
+// through the call's reporters
+type collectingEmitter struct {
+	ctx context.Context
+
+	mu        sync.Mutex
+	chunks    []interface{}
+	result    interface{}
+	hasResult bool
+}
+
+// EmitData keeps a data chunk
+func (e *collectingEmitter) EmitData(data interface{}) error {
+	if err := e.ctx.Err(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.chunks = append(e.chunks, data)
+	return nil
+}
+
+// EmitProgress reports progress to the client, if it asked for it
+func (e *collectingEmitter) EmitProgress(current, total int64, message string) error {
+	return EmitProgress(e.ctx, current, total, message)
+}
+
+// EmitLog reports a log message to the client, if it can be notified
+func (e *collectingEmitter) EmitLog(level, message string, fields map[string]interface{}) error {
+	return EmitLog(e.ctx, level, message, fields)
+}
+
+// EmitResult keeps the aggregate result
+func (e *collectingEmitter) EmitResult(result interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.hasResult {
+		return fmt.Errorf("result already emitted")
+	}
+	e.result, e.hasResult = result, true
+	return nil
+}
+
+// Context returns the call's context
+func (e *collectingEmitter) Context() context.Context {
+	return e.ctx
+}
+
+// collect adapts a streaming handler to a ToolHandler that runs it to
+// completion and returns its result, or the array of its data chunks if
+// it emitted none
+func collect(handler StreamingHandler) ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		emit := &collectingEmitter{ctx: ctx}
+		if err := handler(ctx, args, emit); err != nil {
+			return nil, err
+		}
+
+		emit.mu.Lock()
+		defer emit.mu.Unlock()
+		if emit.hasResult {
+			return emit.result, nil
+		}
+		if emit.chunks == nil {
+			return []interface{}{}, nil
+		}
+		return emit.chunks, nil
+	}
+}
@@ -0,0 +1,57 @@
+package backend_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+type searchArgs struct {
+	Pattern string `json:"pattern"`
+}
+
+type searchSummary struct {
+	Total int `json:"total"`
+}
+
+func TestBaseBackend_CallStreamingToolAsRegular(t *testing.T) {
+	b := backend.NewBaseBackend("search")
+	backend.RegisterTypedStreamingTool(b, backend.NewTool("search").StringParam("pattern", "Pattern", true).Build(),
+		func(ctx context.Context, in searchArgs, emit backend.StreamingEmitter) (searchSummary, error) {
+			for _, m := range []string{in.Pattern + "1", in.Pattern + "2"} {
+				if err := emit.EmitData(m); err != nil {
+					return searchSummary{}, err
+				}
+			}
+			return searchSummary{Total: 2}, nil
+		})
+	b.RegisterStreamingTool(backend.NewTool("tail").Build(), func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		emit.EmitData("line 1")
+		return emit.EmitData("line 2")
+	})
+
+	// tools/call runs the tool to completion and returns its result
+	result, err := b.CallTool(context.Background(), "search", map[string]interface{}{"pattern": "x"})
+	if err != nil {
+		t.Fatalf("CallTool(search): %v", err)
+	}
+	if result != (searchSummary{Total: 2}) {
+		t.Errorf("search result = %#v", result)
+	}
+
+	// Without a result, the data chunks are the result
+	result, err = b.CallTool(context.Background(), "tail", nil)
+	if err != nil {
+		t.Fatalf("CallTool(tail): %v", err)
+	}
+	if !reflect.DeepEqual(result, []interface{}{"line 1", "line 2"}) {
+		t.Errorf("tail result = %#v", result)
+	}
+
+	// Arguments are still bound and checked
+	if _, err := b.CallTool(context.Background(), "search", nil); err == nil {
+		t.Error("missing pattern accepted")
+	}
+}
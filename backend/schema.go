@@ -0,0 +1,348 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SchemaFromStruct derives a JSON Schema from a Go struct (or pointer to one)
+//
+// Property names follow the `json` tag. Other tags refine the schema:
+//
+//	description:"City name"                  property description
+//	jsonschema:"required"                     force required
+//	jsonschema:"optional"                     force optional
+//	jsonschema:"enum=metric|imperial"         allowed values
+//	jsonschema:"minimum=1,maximum=10"         numeric bounds
+//	jsonschema:"default=metric"               default value
+//
+// Fields are required unless they are pointers or tagged omitempty.
+// Nested structs, slices, arrays and maps are described recursively.
+//
+// Example:
+//
+//	type SearchArgs struct {
+//	    Query string `json:"query" description:"Search terms"`
+//	    Limit int    `json:"limit,omitempty" jsonschema:"minimum=1,maximum=100"`
+//	}
+//
+//	schema, err := backend.SchemaFromStruct(SearchArgs{})
+func SchemaFromStruct(v any) (map[string]interface{}, error) {
+	t, err := structType(v)
+	if err != nil {
+		return nil, err
+	}
+
+	g := schemaGenerator{seen: make(map[reflect.Type]bool)}
+	schema, _, err := g.structSchema(t)
+	return schema, err
+}
+
+// structType returns the struct type of v, dereferencing pointers
+func structType(v any) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("schema: expected struct, got %T", v)
+	}
+	return t, nil
+}
+
+// ParamsFromStruct adds one parameter per field of a struct
+// See SchemaFromStruct for the supported tags. Parameters with nested
+// object or array types carry their full schema in Parameter.Schema.
+// Panics if v is not a struct, like other builder misuse.
+func (b *ToolBuilder) ParamsFromStruct(v any) *ToolBuilder {
+	params, err := ParametersFromStruct(v)
+	if err != nil {
+		panic(err)
+	}
+	b.parameters = append(b.parameters, params...)
+	return b
+}
+
+// ParametersFromStruct converts the fields of a struct into tool parameters
+func ParametersFromStruct(v any) ([]Parameter, error) {
+	t, err := structType(v)
+	if err != nil {
+		return nil, err
+	}
+
+	g := schemaGenerator{seen: make(map[reflect.Type]bool)}
+	schema, order, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+
+	required := make(map[string]bool)
+	if req, ok := schema["required"].([]string); ok {
+		for _, name := range req {
+			required[name] = true
+		}
+	}
+
+	params := make([]Parameter, 0, len(order))
+	for _, name := range order {
+		prop, _ := props[name].(map[string]interface{})
+		params = append(params, parameterFromSchema(name, prop, required[name]))
+	}
+	return params, nil
+}
+
+// parameterFromSchema maps a property schema onto a Parameter
+func parameterFromSchema(name string, prop map[string]interface{}, required bool) Parameter {
+	p := Parameter{Name: name, Required: required}
+	p.Type, _ = prop["type"].(string)
+	p.Description, _ = prop["description"].(string)
+	p.Default = prop["default"]
+
+	if enum, ok := prop["enum"].([]string); ok {
+		p.Enum = enum
+	}
+	if n, ok := prop["minimum"].(int); ok {
+		p.Minimum = &n
+	}
+	if n, ok := prop["maximum"].(int); ok {
+		p.Maximum = &n
+	}
+
+	// Keep the full schema where Parameter's flat fields cannot express it
+	if p.Type == "object" || p.Type == "array" || p.Type == "" || prop["format"] != nil {
+		p.Schema = prop
+	}
+	return p
+}
+
+// schemaGenerator tracks visited types to break recursive definitions
+type schemaGenerator struct {
+	seen map[reflect.Type]bool
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	durationType   = reflect.TypeOf(time.Duration(0))
+	timeZoneType   = reflect.TypeOf(TimeZone{})
+	dateType       = reflect.TypeOf(Date{})
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schemaFor returns the schema for a Go type
+func (g *schemaGenerator) schemaFor(t reflect.Type) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case timeZoneType:
+		return map[string]interface{}{"type": "string", "description": timeZoneDescription}, nil
+	case dateType:
+		return map[string]interface{}{"type": "string", "format": "date"}, nil
+	case durationType:
+		return map[string]interface{}{"type": "string", "description": "duration, e.g. \"1m30s\""}, nil
+	case rawMessageType:
+		return map[string]interface{}{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes []byte as base64
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := g.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("schema: unsupported map key type %s", t.Key())
+		}
+		values, err := g.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+
+	case reflect.Struct:
+		schema, _, err := g.structSchema(t)
+		return schema, err
+
+	default:
+		return nil, fmt.Errorf("schema: unsupported type %s", t)
+	}
+}
+
+// structSchema describes a struct as an object schema
+// Also returns the property names in field order.
+func (g *schemaGenerator) structSchema(t reflect.Type) (map[string]interface{}, []string, error) {
+	if g.seen[t] {
+		// Recursive type: stop descending
+		return map[string]interface{}{"type": "object"}, nil, nil
+	}
+	g.seen[t] = true
+	defer delete(g.seen, t)
+
+	properties := make(map[string]interface{})
+	var required, order []string
+
+	if err := g.collectFields(t, properties, &required, &order); err != nil {
+		return nil, nil, err
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, order, nil
+}
+
+// collectFields adds the fields of t (flattening embedded structs) to properties
+func (g *schemaGenerator) collectFields(t reflect.Type, properties map[string]interface{}, required, order *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, omitempty, skip := jsonFieldName(f)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened, as encoding/json does
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := g.collectFields(ft, properties, required, order); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		prop, err := g.schemaFor(f.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if desc := f.Tag.Get("description"); desc != "" {
+			prop["description"] = desc
+		}
+
+		isRequired := !omitempty && f.Type.Kind() != reflect.Pointer
+		if err := applySchemaTag(f, prop, &isRequired); err != nil {
+			return err
+		}
+
+		if _, exists := properties[name]; !exists {
+			*order = append(*order, name)
+		}
+		properties[name] = prop
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// jsonFieldName returns the JSON property name for a struct field
+func jsonFieldName(f reflect.StructField) (name string, omitempty, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// applySchemaTag applies the `jsonschema` tag options to prop
+func applySchemaTag(f reflect.StructField, prop map[string]interface{}, required *bool) error {
+	tag := f.Tag.Get("jsonschema")
+	if tag == "" {
+		return nil
+	}
+
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "":
+		case "required":
+			*required = true
+		case "optional":
+			*required = false
+		case "enum":
+			prop["enum"] = strings.Split(value, "|")
+		case "minimum", "maximum":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("field %s: invalid %s %q", f.Name, key, value)
+			}
+			prop[key] = n
+		case "default":
+			prop["default"] = parseDefault(prop["type"], value)
+		case "format":
+			prop["format"] = value
+		default:
+			return fmt.Errorf("field %s: unknown jsonschema option %q", f.Name, key)
+		}
+	}
+	return nil
+}
+
+// parseDefault converts a tag default to the property's JSON type
+func parseDefault(typ interface{}, value string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
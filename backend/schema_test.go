@@ -0,0 +1,96 @@
+package backend_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+type issueFilter struct {
+	Labels []string  `json:"labels,omitempty" description:"Label names"`
+	Since  time.Time `json:"since,omitempty"`
+}
+
+type listIssuesArgs struct {
+	Owner  string         `json:"owner" description:"Repository owner"`
+	State  string         `json:"state,omitempty" jsonschema:"enum=open|closed|all,default=open"`
+	Limit  int            `json:"limit,omitempty" jsonschema:"minimum=1,maximum=100"`
+	Filter *issueFilter   `json:"filter"`
+	Extra  map[string]int `json:"extra,omitempty"`
+	Secret string         `json:"-"`
+	hidden string
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	schema, err := backend.SchemaFromStruct(&listIssuesArgs{})
+	if err != nil {
+		t.Fatalf("SchemaFromStruct() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(schema["required"], []string{"owner"}) {
+		t.Errorf("required = %v, want [owner]", schema["required"])
+	}
+
+	props := schema["properties"].(map[string]interface{})
+	if len(props) != 5 {
+		t.Errorf("got %d properties, want 5 (json:\"-\" and unexported skipped)", len(props))
+	}
+
+	owner := props["owner"].(map[string]interface{})
+	if owner["type"] != "string" || owner["description"] != "Repository owner" {
+		t.Errorf("owner = %v", owner)
+	}
+
+	state := props["state"].(map[string]interface{})
+	if !reflect.DeepEqual(state["enum"], []string{"open", "closed", "all"}) || state["default"] != "open" {
+		t.Errorf("state = %v", state)
+	}
+
+	filter := props["filter"].(map[string]interface{})
+	fprops := filter["properties"].(map[string]interface{})
+	labels := fprops["labels"].(map[string]interface{})
+	if labels["type"] != "array" || labels["items"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("filter.labels = %v", labels)
+	}
+	if since := fprops["since"].(map[string]interface{}); since["format"] != "date-time" {
+		t.Errorf("filter.since = %v", since)
+	}
+
+	extra := props["extra"].(map[string]interface{})
+	if extra["type"] != "object" || extra["additionalProperties"].(map[string]interface{})["type"] != "integer" {
+		t.Errorf("extra = %v", extra)
+	}
+}
+
+func TestSchemaFromStruct_NotStruct(t *testing.T) {
+	if _, err := backend.SchemaFromStruct("nope"); err == nil {
+		t.Error("expected error for non-struct")
+	}
+}
+
+func TestToolBuilder_ParamsFromStruct(t *testing.T) {
+	tool := backend.NewTool("list_issues").ParamsFromStruct(listIssuesArgs{}).Build()
+
+	names := make([]string, len(tool.Parameters))
+	for i, p := range tool.Parameters {
+		names[i] = p.Name
+	}
+	if !reflect.DeepEqual(names, []string{"owner", "state", "limit", "filter", "extra"}) {
+		t.Fatalf("parameters = %v, want field order", names)
+	}
+
+	limit := tool.Parameters[2]
+	if limit.Type != "integer" || limit.Required || *limit.Minimum != 1 || *limit.Maximum != 100 {
+		t.Errorf("limit = %+v", limit)
+	}
+	if filter := tool.Parameters[3]; filter.Schema == nil || filter.Type != "object" {
+		t.Errorf("filter = %+v, want nested schema", filter)
+	}
+
+	// Generated parameters are enforced like hand-written ones
+	if err := backend.ValidateArguments(tool, map[string]interface{}{"owner": "x", "limit": float64(500)}); err == nil {
+		t.Error("expected maximum violation")
+	}
+}
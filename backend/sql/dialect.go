@@ -0,0 +1,217 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// dialect is a database's SQL flavor
+type dialect string
+
+const (
+	dialectPostgres dialect = "postgres"
+	dialectMySQL    dialect = "mysql"
+	dialectSQLite   dialect = "sqlite"
+)
+
+// parseDialect reads a dialect or driver name
+func parseDialect(name string) (dialect, error) {
+	switch strings.ToLower(name) {
+	case "postgres", "postgresql", "pgx", "pq":
+		return dialectPostgres, nil
+	case "mysql", "mariadb":
+		return dialectMySQL, nil
+	case "sqlite", "sqlite3":
+		return dialectSQLite, nil
+	}
+	return "", fmt.Errorf("unknown dialect %q", name)
+}
+
+// detectDialect guesses the dialect from the pool's driver type,
+// "" when it is not a known driver
+func detectDialect(db *sql.DB) dialect {
+	name := strings.ToLower(fmt.Sprintf("%T", db.Driver()))
+	switch {
+	case strings.Contains(name, "sqlite"):
+		return dialectSQLite
+	case strings.Contains(name, "mysql"):
+		return dialectMySQL
+	case strings.Contains(name, "pq.") || strings.Contains(name, "pgx") || strings.Contains(name, "stdlib."):
+		return dialectPostgres
+	}
+	return ""
+}
+
+// placeholders describes the dialect's parameter markers to the model
+func (d dialect) placeholders() string {
+	if d == dialectPostgres {
+		return "$1, $2..."
+	}
+	return "?"
+}
+
+// param returns the marker of the i-th statement parameter
+func (d dialect) param(i int) string {
+	if d == dialectPostgres {
+		return fmt.Sprintf("$%d", i)
+	}
+	return "?"
+}
+
+// currentSchema is the expression of the default schema
+func (d dialect) currentSchema() string {
+	if d == dialectPostgres {
+		return "current_schema()"
+	}
+	return "DATABASE()"
+}
+
+// Table is a table or view of list_tables
+type Table struct {
+	// Name is qualified by its schema outside the default one
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Column is a column of describe_table
+type Column struct {
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Nullable   bool    `json:"nullable"`
+	Default    *string `json:"default,omitempty"`
+	PrimaryKey bool    `json:"primary_key,omitempty"`
+}
+
+// listTables lists the tables and views of a schema, the default one
+// when schema is empty
+func (d dialect) listTables(ctx context.Context, db *sql.DB, schema string) ([]Table, error) {
+	var query string
+	var args []interface{}
+	switch d {
+	case dialectSQLite:
+		from := "sqlite_master"
+		if schema != "" {
+			from = quoteIdent(schema) + ".sqlite_master"
+		}
+		query = "SELECT name, type FROM " + from + " WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	default:
+		query = `SELECT table_name, CASE table_type WHEN 'VIEW' THEN 'view' ELSE 'table' END
+			FROM information_schema.tables
+			WHERE table_schema = COALESCE(NULLIF(` + d.param(1) + `, ''), ` + d.currentSchema() + `)
+			ORDER BY table_name`
+		args = []interface{}{schema}
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []Table
+	for rows.Next() {
+		var t Table
+		if err := rows.Scan(&t.Name, &t.Type); err != nil {
+			return nil, err
+		}
+		if schema != "" {
+			t.Name = schema + "." + t.Name
+		}
+		tables = append(tables, t)
+	}
+	return tables, rows.Err()
+}
+
+// describeTable lists a table's columns in order
+func (d dialect) describeTable(ctx context.Context, db *sql.DB, schema, table string) ([]Column, error) {
+	if d == dialectSQLite {
+		return d.describeSQLite(ctx, db, schema, table)
+	}
+
+	inSchema := fmt.Sprintf("COALESCE(NULLIF(%s, ''), %s)", d.param(1), d.currentSchema())
+	columns := `SELECT column_name, data_type, is_nullable = 'YES', column_default
+		FROM information_schema.columns
+		WHERE table_schema = ` + inSchema + ` AND table_name = ` + d.param(2) + `
+		ORDER BY ordinal_position`
+	primary := `SELECT k.column_name
+		FROM information_schema.table_constraints t
+		JOIN information_schema.key_column_usage k
+		  ON k.constraint_name = t.constraint_name AND k.table_schema = t.table_schema AND k.table_name = t.table_name
+		WHERE t.constraint_type = 'PRIMARY KEY'
+		  AND t.table_schema = ` + inSchema + ` AND t.table_name = ` + d.param(2)
+
+	rows, err := db.QueryContext(ctx, columns, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []Column
+	for rows.Next() {
+		var c Column
+		var def sql.NullString
+		if err := rows.Scan(&c.Name, &c.Type, &c.Nullable, &def); err != nil {
+			return nil, err
+		}
+		if def.Valid {
+			c.Default = &def.String
+		}
+		result = append(result, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	keys, err := db.QueryContext(ctx, primary, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer keys.Close()
+	for keys.Next() {
+		var name string
+		if err := keys.Scan(&name); err != nil {
+			return nil, err
+		}
+		for i := range result {
+			if result[i].Name == name {
+				result[i].PrimaryKey = true
+			}
+		}
+	}
+	return result, keys.Err()
+}
+
+// describeSQLite lists a SQLite table's columns with pragma_table_info
+func (d dialect) describeSQLite(ctx context.Context, db *sql.DB, schema, table string) ([]Column, error) {
+	if schema == "" {
+		schema = "main"
+	}
+	rows, err := db.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?, ?)`, table, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []Column
+	for rows.Next() {
+		var c Column
+		var notNull, pk int
+		var def sql.NullString
+		if err := rows.Scan(&c.Name, &c.Type, &notNull, &def, &pk); err != nil {
+			return nil, err
+		}
+		c.Nullable = notNull == 0
+		c.PrimaryKey = pk > 0
+		if def.Valid {
+			c.Default = &def.String
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
+
+// quoteIdent quotes an identifier with double quotes
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,551 @@
+// Package sql is a read-only SQL query backend
+//
+// Tools:
+//
+//	query           a read-only statement with bound parameters, rows streamed
+//	list_tables     the tables and views of a schema
+//	describe_table  a table's columns, types and primary key
+//
+// Statements run one at a time in a read-only transaction, with values
+// bound as parameters: string literals are refused unless allow_literals
+// is set. Results stop at max_rows rows, and the tables tools may read can
+// be restricted to a list of patterns. Connections come from a
+// DatabaseProvider resource, sharing its pool, metrics and health checks
+// with the rest of the server, or from the backend's own database config.
+// PostgreSQL, MySQL and SQLite are supported; link in the driver. Importing
+// the package registers the backend as "sql" (the framework does so):
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//
+//	# config.yaml
+//	backend:
+//	  type: sql
+//	  config:
+//	    database:
+//	      driver: pgx
+//	      host: db.internal
+//	      database: shop
+//	      user: readonly
+//	      password: ${secret:env:DB_PASSWORD}
+//	    tables: ["orders", "customers", "reporting.*"]
+//	    max_rows: 500
+//
+// The table list is checked against each statement's FROM and JOIN
+// references; grant the database user only those tables for a hard
+// guarantee.
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultMaxRows = 1000
+	DefaultTimeout = 30 * time.Second
+)
+
+// ownResource is the resource of the backend's own database config
+const ownResource = "default"
+
+func init() {
+	backend.Register("sql", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend queries one database
+type Backend struct {
+	*backend.BaseBackend
+
+	logger *slog.Logger
+
+	// The connection: a pool, the backend's own provider or an auth resource
+	db           *sql.DB
+	provider     *auth.DatabaseProvider
+	authProvider string
+	authResource string
+
+	mu      sync.Mutex
+	dialect dialect
+
+	tables        allowlist
+	maxRows       int
+	timeout       time.Duration
+	allowLiterals bool
+}
+
+// New creates a sql backend; Initialize connects it
+func New() *Backend {
+	return &Backend{
+		BaseBackend: backend.NewBaseBackend("sql"),
+		logger:      slog.Default(),
+		maxRows:     DefaultMaxRows,
+		timeout:     DefaultTimeout,
+	}
+}
+
+// NewWithDB creates a sql backend over an existing pool; the config's
+// database and auth entries are then ignored
+func NewWithDB(db *sql.DB) *Backend {
+	b := New()
+	b.db = db
+	return b
+}
+
+// Initialize reads the config and registers the tools
+//
+// Config entries:
+//
+//	database        connection of the backend's own pool, as a database
+//	                auth resource's config (driver, host, dsn, path...)
+//	auth_provider   database provider of auth_resource (default: the backend's)
+//	auth_resource   database resource to query, instead of database
+//	dialect         postgres, mysql or sqlite (default: from the driver)
+//	tables          table patterns tools may read (default: all), e.g. ["sales.*"]
+//	max_rows        most rows a query returns (default 1000)
+//	timeout         longest statement (default "30s")
+//	allow_literals  accept string literals in statements (default false)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if b.maxRows = backend.IntConfig(config, "max_rows", b.maxRows); b.maxRows <= 0 {
+		return errors.New("sql: max_rows must be positive")
+	}
+	if s := backend.StringConfig(config, "timeout", ""); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("sql: invalid timeout %q", s)
+		}
+		b.timeout = d
+	}
+	b.allowLiterals, _ = config["allow_literals"].(bool)
+	if list, ok := config["tables"].([]interface{}); ok {
+		for _, pattern := range list {
+			b.tables = append(b.tables, fmt.Sprint(pattern))
+		}
+	}
+
+	database, _ := config["database"].(map[string]interface{})
+	b.authProvider = backend.StringConfig(config, "auth_provider", "")
+	b.authResource = backend.StringConfig(config, "auth_resource", "")
+	switch {
+	case b.db != nil:
+	case database != nil && b.authResource != "":
+		return errors.New("sql: database and auth_resource are exclusive")
+	case database != nil:
+		b.provider = auth.NewDatabaseProvider("sql")
+		b.provider.RegisterResource(auth.ResourceConfig{ID: ownResource, Type: "database", Config: database})
+		if driver, _ := database["driver"].(string); driver != "" {
+			b.dialect, _ = parseDialect(driver)
+		}
+	case b.authResource == "":
+		return errors.New("sql: database or auth_resource is required")
+	}
+
+	if name := backend.StringConfig(config, "dialect", ""); name != "" {
+		d, err := parseDialect(name)
+		if err != nil {
+			return fmt.Errorf("sql: %w", err)
+		}
+		b.dialect = d
+	}
+
+	b.registerTools()
+	b.logger.Info("sql backend initialized",
+		"dialect", b.dialect,
+		"tables", len(b.tables),
+		"max_rows", b.maxRows)
+	return nil
+}
+
+// Close closes the backend's own pool
+func (b *Backend) Close() error {
+	if b.provider != nil {
+		b.provider.Close()
+	}
+	return b.BaseBackend.Close()
+}
+
+// dbResource is an auth resource holding a pool, such as
+// *auth.DatabaseResource
+type dbResource interface {
+	DB() *sql.DB
+}
+
+// conn returns the pool to query and its dialect
+// A database resource bound to the tool (see auth.ToolResource) comes
+// first.
+func (b *Backend) conn(ctx context.Context) (*sql.DB, dialect, error) {
+	db := b.db
+	if db == nil {
+		var resource auth.Resource
+		var err error
+		if bound, ok := auth.ResourceFromContext(ctx); ok {
+			resource = bound
+		} else {
+			switch {
+			case b.provider != nil:
+				resource, err = b.provider.GetResource(ctx, ownResource)
+			case b.authResource != "":
+				resource, err = b.resource(ctx, b.authProvider, b.authResource)
+			default:
+				return nil, "", backend.ErrNotInitialized
+			}
+		}
+		if err != nil {
+			return nil, "", mcperr.Upstream(err, "database unavailable")
+		}
+		pool, ok := resource.(dbResource)
+		if !ok {
+			return nil, "", fmt.Errorf("sql: auth resource is a %s resource, not a database", resource.Type())
+		}
+		db = pool.DB()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dialect == "" {
+		if b.dialect = detectDialect(db); b.dialect == "" {
+			return nil, "", fmt.Errorf("sql: unknown driver %T, set the dialect", db.Driver())
+		}
+	}
+	return db, b.dialect, nil
+}
+
+// resource gets an auth resource
+func (b *Backend) resource(ctx context.Context, provider, name string) (auth.Resource, error) {
+	if provider == "" {
+		if p := b.GetAuthProvider(); p != nil {
+			return p.GetResource(ctx, name)
+		}
+		provider = auth.DefaultProviderName
+	}
+	return b.GetAuthenticatedResource(ctx, provider, name)
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Readiness pings the database; the backend's own pool is checked as a
+// provider's, degraded when exhausted.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	if b.provider != nil {
+		b.provider.RegisterHealthChecks(r)
+		return
+	}
+	r.RegisterReadiness("sql:database", func(ctx context.Context) error {
+		db, _, err := b.conn(ctx)
+		if err != nil {
+			return err
+		}
+		return db.PingContext(ctx)
+	})
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type queryArgs struct {
+	SQL    string        `json:"sql" description:"One read-only statement (SELECT, WITH, EXPLAIN...). Values go in params, referenced by placeholders"`
+	Params []interface{} `json:"params,omitempty" description:"Values bound to the statement's placeholders, in order"`
+	Limit  int           `json:"limit,omitempty" jsonschema:"minimum=1" description:"Most rows to return (default and cap: the server's row limit)"`
+}
+
+type listArgs struct {
+	Schema string `json:"schema,omitempty" description:"Schema (or attached SQLite database) to list (default: the current one)"`
+}
+
+type describeArgs struct {
+	Table string `json:"table" description:"Table or view name, qualified by its schema outside the current one: \"sales.orders\""`
+}
+
+// TableInfo is the result of describe_table
+type TableInfo struct {
+	Name    string   `json:"name"`
+	Columns []Column `json:"columns"`
+}
+
+type listResult struct {
+	Tables []Table `json:"tables"`
+}
+
+func (b *Backend) registerTools() {
+	placeholders := "$1, $2... for PostgreSQL, ? for MySQL and SQLite"
+	if b.dialect != "" {
+		placeholders = b.dialect.placeholders()
+	}
+	query := backend.NewTool("query").
+		Description(fmt.Sprintf("Run one read-only SQL statement and stream its rows, at most %d. "+
+			"Pass values in params and reference them with placeholders (%s) rather than writing them into the SQL.", b.maxRows, placeholders)).
+		ParamsFromStruct(queryArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(query, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[queryArgs](query, args)
+		if err != nil {
+			return err
+		}
+		return b.query(ctx, in, emit)
+	})
+
+	backend.RegisterTypedTool(b, backend.NewTool("list_tables").
+		Description("List the tables and views the query tool can read.").
+		ParamsFromStruct(listArgs{}).
+		WithCache(true, time.Minute).
+		Build(), b.listTables)
+
+	backend.RegisterTypedTool(b, backend.NewTool("describe_table").
+		Description("Describe a table's columns: name, type, nullability, default and primary key.").
+		ParamsFromStruct(describeArgs{}).
+		WithCache(true, time.Minute).
+		Build(), b.describeTable)
+}
+
+// checkTable refuses tables outside the allowlist
+func (b *Backend) checkTable(table string) error {
+	if !b.tables.allows(table) {
+		return mcperr.PermissionDenied("table %s is not accessible", table)
+	}
+	return nil
+}
+
+// query handles query
+func (b *Backend) query(ctx context.Context, in queryArgs, emit backend.StreamingEmitter) error {
+	db, d, err := b.conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := parseStatement(in.SQL, d, b.allowLiterals)
+	if err != nil {
+		return backend.NewArgumentError("query", "sql", err.Error())
+	}
+	if len(b.tables) > 0 {
+		refs, err := stmt.tables()
+		if err != nil {
+			return backend.NewArgumentError("query", "sql", err.Error())
+		}
+		for _, table := range refs {
+			if err := b.checkTable(table); err != nil {
+				return err
+			}
+		}
+	}
+	limit := b.maxRows
+	if in.Limit > 0 && in.Limit < limit {
+		limit = in.Limit
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return b.dbError(ctx, err)
+	}
+	defer conn.Close()
+
+	if d == dialectSQLite {
+		// SQLite ignores read-only transactions; query_only holds the
+		// connection to reads until reset
+		if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			return b.dbError(ctx, err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.WithoutCancel(ctx), "PRAGMA query_only = OFF"); err != nil {
+				// Keep the connection out of the pool rather than read-only
+				conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			}
+		}()
+	}
+
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return b.dbError(ctx, err)
+	}
+	defer tx.Rollback()
+
+	params := make([]interface{}, len(in.Params))
+	for i, p := range in.Params {
+		params[i] = paramValue(p)
+	}
+	rows, err := tx.QueryContext(ctx, in.SQL, params...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return b.dbError(ctx, err)
+		}
+		// The database rejected the statement: let the model fix it
+		return backend.NewArgumentError("query", "sql", err.Error())
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return b.dbError(ctx, err)
+	}
+	names := columnNames(types)
+	described := make([]string, len(types))
+	for i, t := range types {
+		described[i] = names[i]
+		if t.DatabaseTypeName() != "" {
+			described[i] += " " + t.DatabaseTypeName()
+		}
+	}
+	if err := emit.EmitProgress(0, int64(limit), "columns: "+strings.Join(described, ", ")); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(types))
+	pointers := make([]interface{}, len(types))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	count := 0
+	for rows.Next() {
+		if count == limit {
+			return emit.EmitProgress(int64(count), int64(count), fmt.Sprintf("truncated at %d rows; narrow the query or page with LIMIT and OFFSET", count))
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return b.dbError(ctx, err)
+		}
+		row := make(map[string]interface{}, len(values))
+		for i, v := range values {
+			row[names[i]] = resultValue(v)
+		}
+		if err := emit.EmitData(row); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return b.dbError(ctx, err)
+	}
+	return emit.EmitProgress(int64(count), int64(count), fmt.Sprintf("%d rows", count))
+}
+
+// dbError categorizes a failure talking to the database
+func (b *Backend) dbError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return mcperr.Timeout("statement did not complete within %s", b.timeout)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return mcperr.Upstream(err, "database error")
+}
+
+// listTables handles list_tables
+func (b *Backend) listTables(ctx context.Context, in listArgs) (*listResult, error) {
+	db, d, err := b.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	tables, err := d.listTables(ctx, db, in.Schema)
+	if err != nil {
+		return nil, b.dbError(ctx, err)
+	}
+	result := &listResult{Tables: []Table{}}
+	for _, t := range tables {
+		if b.tables.allows(t.Name) {
+			result.Tables = append(result.Tables, t)
+		}
+	}
+	return result, nil
+}
+
+// describeTable handles describe_table
+func (b *Backend) describeTable(ctx context.Context, in describeArgs) (*TableInfo, error) {
+	if in.Table == "" {
+		return nil, backend.NewArgumentError("describe_table", "table", "is required")
+	}
+	if err := b.checkTable(in.Table); err != nil {
+		return nil, err
+	}
+	db, d, err := b.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	schema, table := "", in.Table
+	if i := strings.LastIndexByte(in.Table, '.'); i >= 0 {
+		schema, table = in.Table[:i], in.Table[i+1:]
+	}
+	columns, err := d.describeTable(ctx, db, schema, table)
+	if err != nil {
+		return nil, b.dbError(ctx, err)
+	}
+	if len(columns) == 0 {
+		return nil, mcperr.NotFound("table %s not found", in.Table)
+	}
+	return &TableInfo{Name: in.Table, Columns: columns}, nil
+}
+
+// ============================================================
+// Values
+// ============================================================
+
+// columnNames returns the result's column names, numbering repeats
+// ("id", "id_2") so rows keep every column
+func columnNames(types []*sql.ColumnType) []string {
+	names := make([]string, len(types))
+	seen := make(map[string]int, len(types))
+	for i, t := range types {
+		name := t.Name()
+		if seen[name]++; seen[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, seen[name])
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// paramValue converts a decoded JSON argument for binding: whole numbers
+// bind as integers, arrays and objects as JSON text
+func paramValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case []interface{}, map[string]interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return v
+}
+
+// resultValue converts a scanned value for JSON: text as strings, binary
+// data base64-encoded, times in RFC 3339
+func resultValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}
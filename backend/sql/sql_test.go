@@ -0,0 +1,202 @@
+package sql_test
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backend/sql"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+type captureEmitter struct {
+	ctx      context.Context
+	mu       sync.Mutex
+	rows     []map[string]interface{}
+	progress []string
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rows = append(e.rows, data.(map[string]interface{}))
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error {
+	e.progress = append(e.progress, message)
+	return nil
+}
+func (e *captureEmitter) Context() context.Context { return e.ctx }
+
+// shop creates a SQLite database with orders, customers and secrets
+func shop(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "shop.db")
+	db, err := dbsql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE customers (name TEXT PRIMARY KEY, city TEXT)`,
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY, customer TEXT NOT NULL, total REAL DEFAULT 0)`,
+		`CREATE TABLE secrets (value TEXT)`,
+		`INSERT INTO customers VALUES ('alice', 'Cairo'), ('bob', 'Giza')`,
+		`INSERT INTO orders VALUES (1, 'alice', 10), (2, 'alice', 20), (3, 'alice', 30), (4, 'bob', 5)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func toJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func query(b *sql.Backend, statement string, params ...interface{}) (*captureEmitter, error) {
+	emit := &captureEmitter{ctx: context.Background()}
+	args := map[string]interface{}{"sql": statement, "params": params}
+	return emit, b.CallStreamingTool(emit.ctx, "query", args, emit)
+}
+
+func TestBackend_Query(t *testing.T) {
+	b := sql.New()
+	defer b.Close()
+	ctx := context.Background()
+	if err := b.Initialize(ctx, map[string]interface{}{
+		"database": map[string]interface{}{"driver": "sqlite3", "path": shop(t)},
+		"tables":   []interface{}{"orders", "customers"},
+		"max_rows": 2,
+	}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	emit, err := query(b, `SELECT o.id, c.city FROM orders o JOIN customers AS c ON c.name = o.customer WHERE o.customer = ? ORDER BY o.id`, "alice")
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if len(emit.rows) != 2 || emit.rows[0]["id"] != int64(1) || emit.rows[1]["city"] != "Cairo" {
+		t.Errorf("rows %v", emit.rows)
+	}
+	if last := emit.progress[len(emit.progress)-1]; !strings.Contains(last, "truncated at 2 rows") {
+		t.Errorf("progress %q", emit.progress)
+	}
+	if !strings.HasPrefix(emit.progress[0], "columns: id INTEGER, city TEXT") {
+		t.Errorf("columns %q", emit.progress[0])
+	}
+
+	emit, err = query(b, `WITH big AS (SELECT * FROM orders WHERE total > ?) SELECT count(*) AS n FROM big`, 15)
+	if err != nil || len(emit.rows) != 1 || emit.rows[0]["n"] != int64(2) {
+		t.Errorf("CTE query: %v, %v", emit.rows, err)
+	}
+
+	var argErr *backend.ArgumentError
+	for _, statement := range []string{
+		`DELETE FROM orders`,
+		`SELECT 1; DELETE FROM orders`,
+		`SELECT * FROM orders WHERE customer = 'alice'`,
+		`SELECT * FROM orders WHERE nope = ?`,
+	} {
+		if _, err := query(b, statement, 1); !errors.As(err, &argErr) {
+			t.Errorf("%s: %v", statement, err)
+		}
+	}
+	for _, statement := range []string{
+		`SELECT * FROM secrets`,
+		`SELECT * FROM orders WHERE id IN (SELECT rowid FROM [secrets])`,
+		`SELECT * FROM orders WHERE id IN ((SELECT 1) UNION SELECT rowid FROM secrets)`,
+		`SELECT * FROM pragma_table_info(?)`,
+	} {
+		if _, err := query(b, statement, "secrets"); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+			t.Errorf("%s: %v", statement, err)
+		}
+	}
+
+	tables, err := b.CallTool(ctx, "list_tables", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("list_tables failed: %v", err)
+	}
+	if got := toJSON(t, tables); got != `{"tables":[{"name":"customers","type":"table"},{"name":"orders","type":"table"}]}` {
+		t.Errorf("list_tables = %s", got)
+	}
+
+	info, err := b.CallTool(ctx, "describe_table", map[string]interface{}{"table": "orders"})
+	if err != nil {
+		t.Fatalf("describe_table failed: %v", err)
+	}
+	columns := info.(*sql.TableInfo).Columns
+	if len(columns) != 3 || !columns[0].PrimaryKey || columns[1].Nullable || *columns[2].Default != "0" {
+		t.Errorf("columns %+v", columns)
+	}
+	if _, err := b.CallTool(ctx, "describe_table", map[string]interface{}{"table": "secrets"}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("describe secrets: %v", err)
+	}
+
+	registry := health.NewRegistry()
+	b.RegisterHealthChecks(registry)
+	report := registry.Run(ctx, health.Readiness)
+	for _, check := range report.Checks {
+		if check.Error != "" {
+			t.Errorf("check %s: %s", check.Name, check.Error)
+		}
+	}
+}
+
+func TestBackend_ReadOnly(t *testing.T) {
+	db, err := dbsql.Open("sqlite3", shop(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	b := sql.NewWithDB(db)
+	ctx := context.Background()
+	if err := b.Initialize(ctx, map[string]interface{}{"allow_literals": true}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	emit, err := query(b, `SELECT name FROM customers WHERE city = 'Giza'`)
+	if err != nil || len(emit.rows) != 1 || emit.rows[0]["name"] != "bob" {
+		t.Fatalf("literal query: %v, %v", emit.rows, err)
+	}
+
+	// The pool's only connection is writable again after the query
+	if _, err := db.Exec(`INSERT INTO secrets VALUES ('x')`); err != nil {
+		t.Errorf("connection left read-only: %v", err)
+	}
+
+	if _, err := b.CallTool(ctx, "describe_table", map[string]interface{}{"table": "missing"}); mcperr.CodeOf(err) != mcperr.CodeNotFound {
+		t.Errorf("describe missing: %v", err)
+	}
+}
+
+func TestBackend_Config(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"no database": {},
+		"both":        {"database": map[string]interface{}{"driver": "sqlite3"}, "auth_resource": "db"},
+		"dialect":     {"auth_resource": "db", "dialect": "oracle"},
+		"max rows":    {"auth_resource": "db", "max_rows": 0},
+	}
+	for name, config := range tests {
+		if err := sql.New().Initialize(context.Background(), config); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// readOnlyKeywords are the statements the query tool runs
+var readOnlyKeywords = map[string]bool{
+	"select": true, "with": true, "values": true, "table": true,
+	"explain": true, "show": true, "describe": true, "desc": true,
+}
+
+// clauseKeywords end a FROM list or cannot be table aliases
+var clauseKeywords = map[string]bool{
+	"where": true, "group": true, "order": true, "limit": true, "offset": true,
+	"having": true, "join": true, "inner": true, "left": true, "right": true,
+	"full": true, "outer": true, "cross": true, "natural": true, "on": true,
+	"using": true, "union": true, "intersect": true, "except": true,
+	"window": true, "fetch": true, "for": true, "as": true, "select": true,
+	"from": true, "lateral": true, "only": true, "straight_join": true,
+	"tablesample": true, "returning": true, "table": true,
+}
+
+// token is a lexical unit of a statement
+type token struct {
+	// kind is 'w' for words, 'q' for quoted identifiers, 's' for string
+	// literals, 'n' for numbers and the character itself for punctuation
+	kind byte
+	text string
+}
+
+// word reports whether the token is the unquoted keyword kw
+func (t token) word(kw string) bool {
+	return t.kind == 'w' && strings.EqualFold(t.text, kw)
+}
+
+// ident reports whether the token can name a table
+func (t token) ident() bool {
+	return t.kind == 'q' || (t.kind == 'w' && !clauseKeywords[strings.ToLower(t.text)])
+}
+
+// name returns an identifier as compared against the allowlist:
+// unquoted names lowercased, quoted ones verbatim
+func (t token) name() string {
+	if t.kind == 'q' {
+		return t.text
+	}
+	return strings.ToLower(t.text)
+}
+
+// tokenize splits a statement into tokens, dropping comments and
+// whitespace
+// Lexing follows the dialect, so that what the database runs is what is
+// checked: MySQL's # comments, backslash escapes and "strings", and
+// PostgreSQL's nested comments, E'strings' and dollar quoting, SQLite's
+// [identifiers].
+func tokenize(stmt string, d dialect) ([]token, error) {
+	mysql := d == dialectMySQL
+	var tokens []token
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case isSpace(c):
+			i++
+
+		case strings.HasPrefix(stmt[i:], "--") && (!mysql || i+2 == len(stmt) || isSpace(stmt[i+2])),
+			c == '#' && mysql:
+			end := strings.IndexByte(stmt[i:], '\n')
+			if end < 0 {
+				return tokens, nil
+			}
+			i += end + 1
+
+		case strings.HasPrefix(stmt[i:], "/*"):
+			if mysql && strings.HasPrefix(stmt[i:], "/*!") {
+				return nil, errors.New("executable comments are not allowed")
+			}
+			end, err := commentEnd(stmt, i, d == dialectPostgres)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+
+		case c == '\'' || c == '"' || c == '`':
+			escapes := mysql && c != '`'
+			if d == dialectPostgres && c == '\'' && len(tokens) > 0 && i > 0 && !isSpace(stmt[i-1]) && tokens[len(tokens)-1].word("e") {
+				// E'...' escape string
+				tokens = tokens[:len(tokens)-1]
+				escapes = true
+			}
+			end, text, err := quoted(stmt, i, escapes)
+			if err != nil {
+				return nil, err
+			}
+			kind := byte('q')
+			if c == '\'' || (c == '"' && mysql) {
+				kind = 's'
+			}
+			tokens = append(tokens, token{kind: kind, text: text})
+			i = end
+
+		case c == '[' && d == dialectSQLite:
+			end := strings.IndexByte(stmt[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated [ quote")
+			}
+			tokens = append(tokens, token{kind: 'q', text: stmt[i+1 : i+end]})
+			i += end + 1
+
+		case c == '$' && d == dialectPostgres && i+1 < len(stmt) && (stmt[i+1] == '$' || isWordStart(stmt[i+1])):
+			// Dollar quoting, $tag$...$tag$
+			end := strings.IndexByte(stmt[i+1:], '$')
+			tag := ""
+			if end >= 0 {
+				tag = stmt[i : i+end+2]
+			}
+			if end < 0 || !isTag(tag) {
+				tokens = append(tokens, token{kind: '$', text: "$"})
+				i++
+				continue
+			}
+			close := strings.Index(stmt[i+len(tag):], tag)
+			if close < 0 {
+				return nil, errors.New("unterminated dollar-quoted string")
+			}
+			tokens = append(tokens, token{kind: 's', text: stmt[i+len(tag) : i+len(tag)+close]})
+			i += len(tag) + close + len(tag)
+
+		case isWordStart(c):
+			j := i + 1
+			for j < len(stmt) && (isWordStart(stmt[j]) || isDigit(stmt[j]) || stmt[j] == '$') {
+				j++
+			}
+			tokens = append(tokens, token{kind: 'w', text: stmt[i:j]})
+			i = j
+
+		case isDigit(c):
+			j := i + 1
+			for j < len(stmt) && (isDigit(stmt[j]) || stmt[j] == '.' || stmt[j] == 'e' || stmt[j] == 'E') {
+				j++
+			}
+			tokens = append(tokens, token{kind: 'n', text: stmt[i:j]})
+			i = j
+
+		default:
+			tokens = append(tokens, token{kind: c, text: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// quoted reads the quoted string or identifier starting at i, returning
+// the index after it and its unescaped text
+// Quotes are escaped by doubling them, or with a backslash when escapes
+// is set.
+func quoted(stmt string, i int, escapes bool) (int, string, error) {
+	q := stmt[i]
+	var text strings.Builder
+	for j := i + 1; j < len(stmt); j++ {
+		switch c := stmt[j]; {
+		case c == '\\' && escapes && j+1 < len(stmt):
+			j++
+			text.WriteByte(stmt[j])
+		case c == q && j+1 < len(stmt) && stmt[j+1] == q:
+			j++
+			text.WriteByte(q)
+		case c == q:
+			return j + 1, text.String(), nil
+		default:
+			text.WriteByte(c)
+		}
+	}
+	return 0, "", fmt.Errorf("unterminated %c quote", q)
+}
+
+// commentEnd returns the index after the block comment starting at i
+func commentEnd(stmt string, i int, nested bool) (int, error) {
+	depth := 0
+	for j := i; j+1 < len(stmt); j++ {
+		switch stmt[j : j+2] {
+		case "/*":
+			if depth == 0 || nested {
+				depth++
+			}
+			j++
+		case "*/":
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1, nil
+			}
+		}
+	}
+	return 0, errors.New("unterminated comment")
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isTag reports whether s is a dollar quote tag, "$$" or "$name$"
+func isTag(s string) bool {
+	for i := 1; i < len(s)-1; i++ {
+		if !isWordStart(s[i]) && !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ============================================================
+// Checks
+// ============================================================
+
+// statement is a tokenized statement the query tool may run
+type statement struct {
+	tokens []token
+}
+
+// parseStatement accepts a single read-only statement
+// String literals are refused unless allowLiterals is set, so values
+// arrive as parameters instead of being spliced into the SQL.
+func parseStatement(sql string, d dialect, allowLiterals bool) (*statement, error) {
+	tokens, err := tokenize(sql, d)
+	if err != nil {
+		return nil, err
+	}
+	for len(tokens) > 0 && tokens[len(tokens)-1].kind == ';' {
+		tokens = tokens[:len(tokens)-1]
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty statement")
+	}
+
+	first := tokens[0]
+	if first.kind == '(' {
+		// A parenthesized query: (SELECT ...) UNION (SELECT ...)
+		for _, t := range tokens {
+			if t.kind != '(' {
+				first = t
+				break
+			}
+		}
+	}
+	if first.kind != 'w' || !readOnlyKeywords[strings.ToLower(first.text)] {
+		return nil, fmt.Errorf("only read-only statements (SELECT, WITH, EXPLAIN...) are allowed, not %s", first.text)
+	}
+
+	for _, t := range tokens {
+		switch {
+		case t.kind == ';':
+			return nil, errors.New("only one statement is allowed")
+		case t.kind == 's' && !allowLiterals:
+			return nil, errors.New("string literals are not allowed; pass values in params and reference them with placeholders")
+		}
+	}
+	return &statement{tokens: tokens}, nil
+}
+
+// tables returns the tables the statement reads: the references of its
+// FROM lists and joins, CTEs excepted
+// Functions in FROM (generate_series(...)) are returned with "()".
+// Statements whose tables cannot be told, such as SHOW, fail.
+func (s *statement) tables() ([]string, error) {
+	tokens := s.tokens
+	switch first := strings.ToLower(tokens[0].text); {
+	case first == "describe" || first == "desc" || (first == "explain" && !s.hasQuery()):
+		// MySQL's DESCRIBE t, also spelled EXPLAIN t
+		refs, _, err := tableList(tokens, 1, false)
+		return refs, err
+	case first == "show":
+		return nil, errors.New("the tables of SHOW statements cannot be checked")
+	}
+
+	ctes := make(map[string]bool)
+	for i := 0; i+2 < len(tokens); i++ {
+		// name AS (  or  name (columns) AS (
+		j := i + 1
+		if tokens[j].kind == '(' {
+			j = closing(tokens, j) + 1
+		}
+		if tokens[i].ident() && j+1 < len(tokens) && tokens[j].word("as") && tokens[j+1].kind == '(' {
+			ctes[tokens[i].name()] = true
+		}
+	}
+
+	// Each open parenthesis: whether it follows a name, as a function
+	// call's does, and whether a query started inside it
+	type paren struct{ call, query bool }
+	var refs []string
+	var parens []paren
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.kind == '(':
+			parens = append(parens, paren{call: i > 0 && tokens[i-1].ident()})
+		case t.kind == ')':
+			if len(parens) > 0 {
+				parens = parens[:len(parens)-1]
+			}
+		case t.word("select") || t.word("values"):
+			if len(parens) > 0 {
+				parens[len(parens)-1].query = true
+			}
+		case t.word("from") || t.word("join") || t.word("table"):
+			// EXTRACT(year FROM d) and SUBSTRING(s FROM 2) read no table,
+			// but x IN (SELECT ... FROM t) does
+			if top := len(parens) - 1; t.word("from") && top >= 0 && parens[top].call && !parens[top].query {
+				continue
+			}
+			list, last, err := tableList(tokens, i+1, t.word("from"))
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range list {
+				if !ctes[ref] {
+					refs = append(refs, ref)
+				}
+			}
+			i = last
+		}
+	}
+	return refs, nil
+}
+
+// hasQuery reports whether the statement holds a query, as opposed to
+// EXPLAIN t
+func (s *statement) hasQuery() bool {
+	for _, t := range s.tokens[1:] {
+		if t.word("select") || t.word("with") || t.word("values") || t.word("table") {
+			return true
+		}
+	}
+	return false
+}
+
+// tableList reads the references following FROM or JOIN, returning the
+// index of their last token
+func tableList(tokens []token, i int, list bool) ([]string, int, error) {
+	var refs []string
+	for i < len(tokens) {
+		for i < len(tokens) && (tokens[i].word("only") || tokens[i].word("lateral")) {
+			i++
+		}
+		if i >= len(tokens) || tokens[i].kind == '(' {
+			// A subquery, whose own FROM is read separately
+			return refs, i - 1, nil
+		}
+		if !tokens[i].ident() {
+			return nil, i, fmt.Errorf("unexpected %q where a table belongs", tokens[i].text)
+		}
+
+		name := tokens[i].name()
+		for i+2 < len(tokens) && tokens[i+1].kind == '.' && tokens[i+2].ident() {
+			name += "." + tokens[i+2].name()
+			i += 2
+		}
+		if i+1 < len(tokens) && tokens[i+1].kind == '(' {
+			name += "()"
+			i = closing(tokens, i+1)
+		}
+		refs = append(refs, name)
+		i++
+
+		// Alias
+		if i < len(tokens) && tokens[i].word("as") {
+			i += 2
+		} else if i < len(tokens) && tokens[i].ident() {
+			i++
+		}
+		if i < len(tokens) && tokens[i].kind == '(' {
+			// Column aliases: AS t (a, b)
+			i = closing(tokens, i) + 1
+		}
+
+		if !list || i >= len(tokens) || tokens[i].kind != ',' {
+			return refs, i - 1, nil
+		}
+		i++
+	}
+	return refs, i - 1, nil
+}
+
+// closing returns the index of the parenthesis closing the one at i
+func closing(tokens []token, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i].kind {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// ============================================================
+// Allowlist
+// ============================================================
+
+// allowlist restricts the tables tools may read
+// Patterns are matched with path.Match against references as written:
+// "orders" allows the unqualified name, "sales.*" every table of the
+// sales schema. No patterns allow every table.
+type allowlist []string
+
+// allows reports whether a table reference is allowed
+func (a allowlist) allows(table string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	for _, pattern := range a {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(table)); ok {
+			return true
+		}
+	}
+	return false
+}
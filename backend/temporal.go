@@ -0,0 +1,172 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateFormat is the layout of Date arguments (RFC 3339 full-date)
+const DateFormat = "2006-01-02"
+
+// timeZoneDescription describes time zone parameters without their own
+const timeZoneDescription = `IANA time zone, e.g. "Europe/Berlin"`
+
+// ============================================================
+// Time zones
+// ============================================================
+
+// TimeZone is an IANA time zone argument such as "Europe/Berlin"
+// It decodes through time.LoadLocation, so an unknown zone is an invalid
+// argument rather than a silent fall back to UTC. The zero value means
+// the caller gave none.
+//
+// Example:
+//
+//	type Args struct {
+//	    Start    time.Time        `json:"start"`
+//	    TimeZone backend.TimeZone `json:"time_zone,omitempty"`
+//	}
+//
+//	loc := in.TimeZone.Or(time.UTC)
+type TimeZone struct {
+	loc *time.Location
+}
+
+// NewTimeZone returns the TimeZone of loc
+func NewTimeZone(loc *time.Location) TimeZone {
+	return TimeZone{loc: loc}
+}
+
+// Location returns the zone, or nil when unset
+func (z TimeZone) Location() *time.Location {
+	return z.loc
+}
+
+// Or returns the zone, or def when unset
+func (z TimeZone) Or(def *time.Location) *time.Location {
+	if z.loc == nil {
+		return def
+	}
+	return z.loc
+}
+
+// IsZero reports whether the zone is unset
+func (z TimeZone) IsZero() bool {
+	return z.loc == nil
+}
+
+// String returns the zone's IANA name, or "" when unset
+func (z TimeZone) String() string {
+	if z.loc == nil {
+		return ""
+	}
+	return z.loc.String()
+}
+
+// MarshalJSON encodes the zone's name
+func (z TimeZone) MarshalJSON() ([]byte, error) {
+	return json.Marshal(z.String())
+}
+
+// UnmarshalJSON loads the named zone; "" leaves it unset
+func (z *TimeZone) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("time zone must be a string")
+	}
+	if name == "" {
+		z.loc = nil
+		return nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("unknown time zone %q", name)
+	}
+	z.loc = loc
+	return nil
+}
+
+// ============================================================
+// Dates
+// ============================================================
+
+// Date is a calendar date argument ("2025-03-30") without a time zone
+// A date becomes an instant only in a zone: Date.In returns its midnight
+// there, which is correct across daylight saving changes.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in t's location
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// In returns the start of the date in loc
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// AddDays returns the date n days later
+func (d Date) AddDays(n int) Date {
+	return DateOf(time.Date(d.Year, d.Month, d.Day+n, 0, 0, 0, 0, time.UTC))
+}
+
+// IsZero reports whether the date is unset
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// String formats the date as YYYY-MM-DD
+func (d Date) String() string {
+	return d.In(time.UTC).Format(DateFormat)
+}
+
+// MarshalJSON encodes the date as YYYY-MM-DD
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte(`""`), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON parses YYYY-MM-DD; "" leaves the date unset
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("date must be a string")
+	}
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+	t, err := time.Parse(DateFormat, s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	*d = DateOf(t)
+	return nil
+}
+
+// ============================================================
+// Validation
+// ============================================================
+
+// validateFormat checks string values of the date-time and date formats
+func validateFormat(format string, s string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "expected an RFC 3339 date-time with a UTC offset, e.g. 2025-03-30T09:00:00+02:00"
+		}
+	case "date":
+		if _, err := time.Parse(DateFormat, s); err != nil {
+			return "expected a date as YYYY-MM-DD"
+		}
+	}
+	return ""
+}
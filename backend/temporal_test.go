@@ -0,0 +1,86 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+type meetingArgs struct {
+	Start    time.Time        `json:"start"`
+	Day      backend.Date     `json:"day,omitempty"`
+	TimeZone backend.TimeZone `json:"time_zone,omitempty"`
+}
+
+func TestTemporalParams(t *testing.T) {
+	tool := backend.NewTool("schedule").ParamsFromStruct(meetingArgs{}).Build()
+
+	for name, tt := range map[string]struct {
+		args  map[string]interface{}
+		valid bool
+	}{
+		"offset":       {map[string]interface{}{"start": "2025-03-30T09:00:00+02:00"}, true},
+		"utc":          {map[string]interface{}{"start": "2025-03-30T07:00:00Z", "day": "2025-03-30"}, true},
+		"no offset":    {map[string]interface{}{"start": "2025-03-30T09:00:00"}, false},
+		"bad date":     {map[string]interface{}{"start": "2025-03-30T07:00:00Z", "day": "30/03/2025"}, false},
+		"not a string": {map[string]interface{}{"start": 1743318000.0}, false},
+	} {
+		err := backend.ValidateArguments(tool, tt.args)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateArguments() = %v", name, err)
+		}
+	}
+
+	var got meetingArgs
+	handler := backend.TypedHandler(tool, func(ctx context.Context, in meetingArgs) (string, error) {
+		got = in
+		return "", nil
+	})
+	if _, err := handler(context.Background(), map[string]interface{}{
+		"start":     "2025-03-30T09:00:00+02:00",
+		"day":       "2025-03-30",
+		"time_zone": "Europe/Berlin",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	berlin := got.TimeZone.Or(time.UTC)
+	if berlin.String() != "Europe/Berlin" || !got.Start.Equal(time.Date(2025, 3, 30, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("bound = %+v", got)
+	}
+	// Midnight of the day daylight saving starts is still +01:00
+	if _, offset := got.Day.In(berlin).Zone(); offset != 3600 {
+		t.Errorf("offset = %d", offset)
+	}
+	if got.Day.AddDays(2).String() != "2025-04-01" {
+		t.Errorf("AddDays = %s", got.Day.AddDays(2))
+	}
+
+	_, err := handler(context.Background(), map[string]interface{}{
+		"start":     "2025-03-30T09:00:00+02:00",
+		"time_zone": "Mars/Olympus",
+	})
+	if !errors.Is(err, backend.ErrInvalidArguments) || !strings.Contains(err.Error(), "Mars/Olympus") {
+		t.Errorf("unknown zone = %v", err)
+	}
+}
+
+func TestToolBuilder_TemporalParams(t *testing.T) {
+	tool := backend.NewTool("range").
+		DateTimeParam("from", "Range start", true).
+		DateParam("day", "Day", false).
+		TimeZoneParam("tz", "", false).
+		Build()
+
+	if err := backend.ValidateArguments(tool, map[string]interface{}{"from": "tomorrow", "day": "2025-02-30"}); err == nil {
+		t.Error("invalid date-time and date accepted")
+	} else if fields := err.(*backend.ArgumentError).Fields; len(fields) != 2 {
+		t.Errorf("fields = %+v", fields)
+	}
+	if tz := tool.Parameters[2]; tz.Type != "string" || !strings.Contains(tz.Description, "IANA") {
+		t.Errorf("tz = %+v", tz)
+	}
+}
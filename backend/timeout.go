@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type timeoutKey struct{}
+
+// WithRequestTimeout records a client-requested timeout on a tool call's context
+// Transports set it from the X-Timeout header and the protocol handler from
+// _meta.timeout. When both are present the shorter one wins.
+func WithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	if existing, ok := RequestTimeout(ctx); ok && existing <= timeout {
+		return ctx
+	}
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// RequestTimeout returns the client-requested timeout, if any
+func RequestTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(timeoutKey{}).(time.Duration)
+	return timeout, ok
+}
+
+// ResolveTimeout returns the deadline to enforce for a call to tool
+// The tool's declared timeout replaces fallback (the server-wide default);
+// a client-requested timeout may shorten the result but never extend it.
+// Zero means no deadline.
+func ResolveTimeout(ctx context.Context, tool ToolDefinition, fallback time.Duration) time.Duration {
+	timeout := fallback
+	if tool.Timeout > 0 {
+		timeout = tool.Timeout
+	}
+	if requested, ok := RequestTimeout(ctx); ok && (timeout <= 0 || requested < timeout) {
+		timeout = requested
+	}
+	return timeout
+}
+
+// ParseTimeout parses a client-supplied timeout
+// Accepts Go durations ("1m30s") and plain seconds ("90", "2.5").
+func ParseTimeout(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return secondsToTimeout(seconds)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", s)
+	}
+	return d, nil
+}
+
+// TimeoutFromValue converts a decoded JSON value (seconds or duration string)
+func TimeoutFromValue(v interface{}) (time.Duration, error) {
+	switch t := v.(type) {
+	case float64:
+		return secondsToTimeout(t)
+	case string:
+		return ParseTimeout(t)
+	default:
+		return 0, fmt.Errorf("invalid timeout %v: want seconds or a duration string", v)
+	}
+}
+
+func secondsToTimeout(seconds float64) (time.Duration, error) {
+	if seconds <= 0 || seconds > float64(1<<63-1)/float64(time.Second) {
+		return 0, fmt.Errorf("invalid timeout %v: must be a positive number of seconds", seconds)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
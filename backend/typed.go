@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidArguments indicates tool arguments failed binding or validation
+// The protocol handler reports these as JSON-RPC InvalidParams errors
+var ErrInvalidArguments = errors.New("invalid arguments")
+
+// FieldError describes a single invalid argument
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ArgumentError reports invalid tool arguments
+type ArgumentError struct {
+	Tool   string
+	Fields []FieldError
+}
+
+func (e *ArgumentError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return fmt.Sprintf("invalid arguments for tool %s: %s", e.Tool, strings.Join(parts, "; "))
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidArguments)
+func (e *ArgumentError) Unwrap() error {
+	return ErrInvalidArguments
+}
+
+// NewArgumentError reports one invalid argument of a tool
+func NewArgumentError(tool, field, message string) *ArgumentError {
+	return &ArgumentError{Tool: tool, Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// ToolRegistrar is implemented by backends that accept regular tools
+type ToolRegistrar interface {
+	RegisterTool(tool ToolDefinition, handler ToolHandler)
+}
+
+// TypedToolHandler handles a tool call with arguments bound into In
+type TypedToolHandler[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// RegisterTypedTool registers a tool whose arguments are bound into In
+//
+// Arguments are decoded using In's JSON tags after checking the tool's
+// required parameters, so handlers never type-assert raw maps. Missing or
+// mistyped arguments produce an *ArgumentError instead of a panic.
+//
+// Example:
+//
+//	type WeatherArgs struct {
+//	    Location string `json:"location"`
+//	    Units    string `json:"units"`
+//	}
+//
+//	backend.RegisterTypedTool(b, tool, func(ctx context.Context, in WeatherArgs) (*Forecast, error) {
+//	    return lookup(ctx, in.Location, in.Units)
+//	})
+func RegisterTypedTool[In, Out any](b ToolRegistrar, tool ToolDefinition, fn TypedToolHandler[In, Out]) {
+	b.RegisterTool(tool, TypedHandler(tool, fn))
+}
+
+// TypedHandler adapts a typed handler to a ToolHandler for tool
+func TypedHandler[In, Out any](tool ToolDefinition, fn TypedToolHandler[In, Out]) ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		in, err := BindArguments[In](tool, args)
+		if err != nil {
+			return nil, err
+		}
+		return fn(ctx, in)
+	}
+}
+
+// StreamingToolRegistrar is implemented by backends that accept
+// streaming tools
+type StreamingToolRegistrar interface {
+	RegisterStreamingTool(tool ToolDefinition, handler StreamingHandler)
+}
+
+// TypedStreamingToolHandler handles a streaming tool call with arguments
+// bound into In, emitting data as it goes and returning the aggregate
+type TypedStreamingToolHandler[In, Out any] func(ctx context.Context, in In, emit StreamingEmitter) (Out, error)
+
+// RegisterTypedStreamingTool registers a streaming tool whose arguments
+// are bound into In and whose returned Out is emitted as its result
+//
+// Streaming clients receive the data events followed by a result event
+// carrying Out; tools/call callers receive Out as the call's result.
+//
+// Example:
+//
+//	backend.RegisterTypedStreamingTool(b, tool, func(ctx context.Context, in SearchArgs, emit backend.StreamingEmitter) (*SearchSummary, error) {
+//	    total := 0
+//	    for m := range search(ctx, in.Pattern) {
+//	        emit.EmitData(m)
+//	        total++
+//	    }
+//	    return &SearchSummary{Total: total}, nil
+//	})
+func RegisterTypedStreamingTool[In, Out any](b StreamingToolRegistrar, tool ToolDefinition, fn TypedStreamingToolHandler[In, Out]) {
+	b.RegisterStreamingTool(tool, TypedStreamingHandler(tool, fn))
+}
+
+// TypedStreamingHandler adapts a typed streaming handler to a
+// StreamingHandler for tool
+func TypedStreamingHandler[In, Out any](tool ToolDefinition, fn TypedStreamingToolHandler[In, Out]) StreamingHandler {
+	return func(ctx context.Context, args map[string]interface{}, emit StreamingEmitter) error {
+		in, err := BindArguments[In](tool, args)
+		if err != nil {
+			return err
+		}
+		out, err := fn(ctx, in, emit)
+		if err != nil {
+			return err
+		}
+		return EmitResult(emit, out)
+	}
+}
+
+// BindArguments decodes args into a value of type T
+// Parameter defaults from the tool definition are applied first.
+func BindArguments[T any](tool ToolDefinition, args map[string]interface{}) (T, error) {
+	var out T
+
+	merged := make(map[string]interface{}, len(args))
+	for _, p := range tool.Parameters {
+		if p.Default != nil {
+			merged[p.Name] = p.Default
+		}
+	}
+	for k, v := range args {
+		merged[k] = v
+	}
+
+	var missing []FieldError
+	for _, p := range tool.Parameters {
+		if v, ok := merged[p.Name]; p.Required && (!ok || v == nil) {
+			missing = append(missing, FieldError{Field: p.Name, Message: "required"})
+		}
+	}
+	if len(missing) > 0 {
+		return out, &ArgumentError{Tool: tool.Name, Fields: missing}
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return out, &ArgumentError{Tool: tool.Name, Fields: []FieldError{{Field: "arguments", Message: err.Error()}}}
+	}
+
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, &ArgumentError{Tool: tool.Name, Fields: []FieldError{decodeFieldError(err)}}
+	}
+
+	return out, nil
+}
+
+// decodeFieldError converts a JSON decoding error into a field error
+func decodeFieldError(err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := typeErr.Field
+		if field == "" {
+			field = "arguments"
+		}
+		return FieldError{
+			Field:   field,
+			Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+		}
+	}
+	return FieldError{Field: "arguments", Message: err.Error()}
+}
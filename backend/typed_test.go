@@ -0,0 +1,81 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+type weatherArgs struct {
+	Location string `json:"location"`
+	Days     int    `json:"days"`
+	Units    string `json:"units"`
+}
+
+type weatherResult struct {
+	Summary string `json:"summary"`
+}
+
+func newWeatherTool() backend.ToolDefinition {
+	units := "metric"
+	return backend.NewTool("get_weather").
+		StringParam("location", "City", true).
+		IntParam("days", "Forecast days", false, nil, nil).
+		EnumParam("units", "Units", false, []string{"metric", "imperial"}, &units).
+		Build()
+}
+
+func TestRegisterTypedTool(t *testing.T) {
+	b := backend.NewBaseBackend("test")
+	tool := newWeatherTool()
+
+	var got weatherArgs
+	backend.RegisterTypedTool(b, tool, func(ctx context.Context, in weatherArgs) (weatherResult, error) {
+		got = in
+		return weatherResult{Summary: "sunny in " + in.Location}, nil
+	})
+
+	result, err := b.CallTool(context.Background(), "get_weather", map[string]interface{}{
+		"location": "Cairo",
+		"days":     float64(3), // JSON numbers arrive as float64
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	if got.Location != "Cairo" || got.Days != 3 || got.Units != "metric" {
+		t.Errorf("bound args = %+v, want Cairo/3/metric (default)", got)
+	}
+	if r, ok := result.(weatherResult); !ok || r.Summary != "sunny in Cairo" {
+		t.Errorf("result = %#v", result)
+	}
+}
+
+func TestBindArguments_Errors(t *testing.T) {
+	tool := newWeatherTool()
+
+	tests := []struct {
+		name      string
+		args      map[string]interface{}
+		wantField string
+	}{
+		{"missing required", map[string]interface{}{}, "location"},
+		{"wrong type", map[string]interface{}{"location": "Cairo", "days": "three"}, "days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := backend.BindArguments[weatherArgs](tool, tt.args)
+
+			var argErr *backend.ArgumentError
+			if !errors.As(err, &argErr) || !errors.Is(err, backend.ErrInvalidArguments) {
+				t.Fatalf("error = %v, want *ArgumentError", err)
+			}
+			if len(argErr.Fields) != 1 || argErr.Fields[0].Field != tt.wantField {
+				t.Errorf("Fields = %+v, want field %q", argErr.Fields, tt.wantField)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package backend
 import (
 	"context"
 	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/resilience"
 )
 
 // ToolDefinition describes a tool's interface
@@ -14,6 +17,43 @@ type ToolDefinition struct {
 
 	// NEW: Cache configuration
 	Cache ToolCacheConfig `json:"cache,omitempty"`
+
+	// RequiredScopes must all be granted to the caller's principal
+	// before tools/call is allowed (see auth.RequireScopes)
+	RequiredScopes []string `json:"requiredScopes,omitempty"`
+
+	// OutputSchema is the JSON Schema of the tool's result, if declared
+	// Results of such tools are also returned as structuredContent.
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+
+	// Timeout bounds each call, replacing the server-wide default (0 = default)
+	Timeout time.Duration `json:"-"`
+
+	// MaxResultSize bounds the encoded tools/call result in bytes,
+	// replacing the server-wide limit (0 = default)
+	MaxResultSize int `json:"-"`
+
+	// EventOverflow is what the tool's emits do when the client falls
+	// behind: "block", "drop_oldest", "drop_newest" or "coalesce"
+	// ("" = the server's policy); EventOverflowTimeout bounds blocking
+	EventOverflow        string        `json:"-"`
+	EventOverflowTimeout time.Duration `json:"-"`
+
+	// EventBatchSize groups up to this many of the tool's data events
+	// into one array event, sent at the latest EventFlushInterval after
+	// its first (0 = the server's batching)
+	EventBatchSize     int           `json:"-"`
+	EventFlushInterval time.Duration `json:"-"`
+
+	// CircuitBreaker guards the tool with its own breaker, if set
+	CircuitBreaker *resilience.Config `json:"-"`
+
+	// Version identifies the tool's implementation in result provenance
+	Version string `json:"-"`
+
+	// Auth is the auth resource the tool's calls use, handed to the
+	// handler through the context (see auth.ResourceFromContext)
+	Auth *auth.ToolResource `json:"-"`
 }
 
 // Parameter describes a tool parameter
@@ -26,6 +66,11 @@ type Parameter struct {
 	Default     interface{} `json:"default,omitempty"`
 	Minimum     *int        `json:"minimum,omitempty"`
 	Maximum     *int        `json:"maximum,omitempty"`
+
+	// Schema is the parameter's full JSON Schema, for types the fields
+	// above cannot express (nested objects, arrays, formats)
+	// When set, it is advertised instead of the generated property schema.
+	Schema map[string]interface{} `json:"schema,omitempty"`
 }
 
 // ToolHandler is the function signature for regular tools
@@ -59,6 +104,16 @@ type ToolCacheConfig struct {
 
 	// Tags for cache categorization (optional, future use)
 	Tags []string `json:"tags,omitempty"`
+
+	// Version is the schema version of the cached results
+	// Bump it when the tool's output format changes, so results cached
+	// in the old format (e.g. in a shared store) are no longer served.
+	Version string `json:"version,omitempty"`
+
+	// PerPrincipal caches results per authenticated caller, for tools
+	// whose results depend on who calls them (their permissions, tenant,
+	// ...) and must not be shared across users
+	PerPrincipal bool `json:"per_principal,omitempty"`
 }
 
 // IsCacheable returns whether this tool can be cached
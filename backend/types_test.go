@@ -1,6 +1,7 @@
 package backend_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -303,3 +304,47 @@ func TestToolDefinition_RealWorldScenarios(t *testing.T) {
 		}
 	})
 }
+
+func TestResolveTimeout(t *testing.T) {
+	plain := backend.NewTool("plain").Build()
+	bounded := backend.NewTool("bounded").Timeout(time.Minute).Build()
+	ctx := context.Background()
+
+	if got := backend.ResolveTimeout(ctx, plain, 5*time.Minute); got != 5*time.Minute {
+		t.Errorf("default: got %v", got)
+	}
+	if got := backend.ResolveTimeout(ctx, bounded, 5*time.Minute); got != time.Minute {
+		t.Errorf("tool timeout: got %v", got)
+	}
+
+	short := backend.WithRequestTimeout(ctx, 10*time.Second)
+	if got := backend.ResolveTimeout(short, bounded, 5*time.Minute); got != 10*time.Second {
+		t.Errorf("request override: got %v", got)
+	}
+
+	long := backend.WithRequestTimeout(ctx, time.Hour)
+	if got := backend.ResolveTimeout(long, bounded, 0); got != time.Minute {
+		t.Errorf("request cannot extend: got %v", got)
+	}
+	if got := backend.ResolveTimeout(long, plain, 0); got != time.Hour {
+		t.Errorf("no server limit: got %v", got)
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30":    30 * time.Second,
+		"2.5":   2500 * time.Millisecond,
+		"1m30s": 90 * time.Second,
+	}
+	for in, want := range tests {
+		if got, err := backend.ParseTimeout(in); err != nil || got != want {
+			t.Errorf("ParseTimeout(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "0", "-5", "soon", "-1s"} {
+		if _, err := backend.ParseTimeout(in); err == nil {
+			t.Errorf("ParseTimeout(%q) succeeded", in)
+		}
+	}
+}
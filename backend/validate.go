@@ -0,0 +1,147 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ValidateArguments checks args against the tool's parameter definitions
+//
+// Enforces required parameters, JSON types, date and date-time formats,
+// enums and integer bounds, the same constraints advertised in the tool's
+// inputSchema. Every violation is reported, ordered by field name, in a
+// single *ArgumentError.
+func ValidateArguments(tool ToolDefinition, args map[string]interface{}) error {
+	var fields []FieldError
+
+	for _, p := range tool.Parameters {
+		v, ok := args[p.Name]
+		if !ok || v == nil {
+			if p.Required && p.Default == nil {
+				fields = append(fields, FieldError{Field: p.Name, Message: "required"})
+			}
+			continue
+		}
+
+		if msg := validateValue(p, v); msg != "" {
+			fields = append(fields, FieldError{Field: p.Name, Message: msg})
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return &ArgumentError{Tool: tool.Name, Fields: fields}
+}
+
+// validateValue returns a description of why v violates p, or ""
+func validateValue(p Parameter, v interface{}) string {
+	if p.Type != "" && !matchesType(p.Type, v) {
+		return fmt.Sprintf("expected %s, got %s", p.Type, jsonType(v))
+	}
+
+	if format, ok := p.Schema["format"].(string); ok {
+		if str, ok := v.(string); ok {
+			if msg := validateFormat(format, str); msg != "" {
+				return msg
+			}
+		}
+	}
+
+	if len(p.Enum) > 0 {
+		s, _ := v.(string)
+		found := false
+		for _, allowed := range p.Enum {
+			if s == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("must be one of [%s]", strings.Join(p.Enum, ", "))
+		}
+	}
+
+	if n, ok := toFloat(v); ok {
+		if p.Minimum != nil && n < float64(*p.Minimum) {
+			return fmt.Sprintf("must be >= %d", *p.Minimum)
+		}
+		if p.Maximum != nil && n > float64(*p.Maximum) {
+			return fmt.Sprintf("must be <= %d", *p.Maximum)
+		}
+	}
+
+	return ""
+}
+
+// matchesType reports whether v is a valid JSON value of the schema type
+func matchesType(schemaType string, v interface{}) bool {
+	switch schemaType {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "integer":
+		n, ok := toFloat(v)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := toFloat(v)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	default:
+		// Unknown types are not enforced
+		return true
+	}
+}
+
+// toFloat converts decoded JSON numbers (and Go numeric types) to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		if _, ok := toFloat(v); ok {
+			return "number"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,59 @@
+package backend_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+func TestValidateArguments(t *testing.T) {
+	min, max := 1, 10
+	tool := backend.NewTool("search").
+		StringParam("query", "Query", true).
+		IntParam("limit", "Max results", false, &min, &max).
+		EnumParam("sort", "Order", false, []string{"asc", "desc"}, nil).
+		BoolParam("exact", "Exact match", false, nil).
+		Build()
+
+	tests := []struct {
+		name       string
+		args       map[string]interface{}
+		wantFields []string
+	}{
+		{"valid", map[string]interface{}{"query": "go", "limit": float64(5), "sort": "asc", "exact": true}, nil},
+		{"optional omitted", map[string]interface{}{"query": "go"}, nil},
+		{"missing required", map[string]interface{}{}, []string{"query"}},
+		{"wrong type", map[string]interface{}{"query": 42.0}, []string{"query"}},
+		{"not an integer", map[string]interface{}{"query": "go", "limit": 2.5}, []string{"limit"}},
+		{"below minimum", map[string]interface{}{"query": "go", "limit": float64(0)}, []string{"limit"}},
+		{"above maximum", map[string]interface{}{"query": "go", "limit": float64(11)}, []string{"limit"}},
+		{"not in enum", map[string]interface{}{"query": "go", "sort": "random"}, []string{"sort"}},
+		{"multiple", map[string]interface{}{"exact": "yes", "limit": float64(99)}, []string{"exact", "limit", "query"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := backend.ValidateArguments(tool, tt.args)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("ValidateArguments() error = %v", err)
+				}
+				return
+			}
+
+			var argErr *backend.ArgumentError
+			if !errors.As(err, &argErr) {
+				t.Fatalf("error = %v, want *ArgumentError", err)
+			}
+			if len(argErr.Fields) != len(tt.wantFields) {
+				t.Fatalf("Fields = %+v, want %v", argErr.Fields, tt.wantFields)
+			}
+			for i, f := range argErr.Fields {
+				if f.Field != tt.wantFields[i] {
+					t.Errorf("Fields[%d] = %q, want %q", i, f.Field, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}
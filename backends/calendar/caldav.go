@@ -0,0 +1,147 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// CalDAVProvider is a CalDAV (RFC 4791) server
+// Calendars are collections below BaseURL, named by their path relative
+// to it ("work" is BaseURL/work/) or by an absolute URL; the empty name
+// is BaseURL itself.
+type CalDAVProvider struct {
+	Client *http.Client
+
+	// BaseURL is the calendar home, e.g.
+	// https://dav.example.com/calendars/alice/
+	BaseURL string
+}
+
+// collection returns the URL of a calendar collection
+func (p *CalDAVProvider) collection(calendar string) string {
+	if strings.HasPrefix(calendar, "http://") || strings.HasPrefix(calendar, "https://") {
+		return strings.TrimSuffix(calendar, "/") + "/"
+	}
+	base := strings.TrimSuffix(p.BaseURL, "/") + "/"
+	if calendar = strings.Trim(calendar, "/"); calendar == "" {
+		return base
+	}
+	return base + calendar + "/"
+}
+
+// calendarQuery asks for the events overlapping a time range, with
+// recurring events expanded by the server (RFC 4791 section 9.6.5)
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <C:calendar-data><C:expand start="%[1]s" end="%[2]s"/></C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT"><C:time-range start="%[1]s" end="%[2]s"/></C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+// multistatus is a WebDAV REPORT response
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				CalendarData string `xml:"calendar-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// ListEvents implements Provider
+func (p *CalDAVProvider) ListEvents(ctx context.Context, calendar string, r TimeRange, fn func(Event) error) error {
+	start, end := r.Start.UTC().Format(icsUTC), r.End.UTC().Format(icsUTC)
+	data, _, err := do(ctx, p.Client, request{
+		method: "REPORT",
+		url:    p.collection(calendar),
+		headers: map[string]string{
+			"Content-Type": "application/xml; charset=utf-8",
+			"Depth":        "1",
+		},
+		body: []byte(fmt.Sprintf(calendarQuery, start, end)),
+	})
+	if err != nil {
+		return err
+	}
+
+	var ms multistatus
+	if err := xml.Unmarshal(data, &ms); err != nil {
+		return mcperr.Upstream(err, "decode calendar-query response")
+	}
+
+	var events []Event
+	for _, resp := range ms.Responses {
+		for _, ps := range resp.Propstat {
+			if ps.Prop.CalendarData == "" || (ps.Status != "" && !strings.Contains(ps.Status, " 200 ")) {
+				continue
+			}
+			parsed, err := parseICS(ps.Prop.CalendarData)
+			if err != nil {
+				return mcperr.Upstream(err, "parse %s", resp.Href)
+			}
+			for _, e := range parsed {
+				// Servers without expand return whole series; keep what overlaps
+				if e.Start.Before(r.End) && e.End.After(r.Start) {
+					e.Calendar = calendar
+					events = append(events, e)
+				}
+			}
+		}
+	}
+
+	sortEvents(events)
+	for _, e := range events {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateEvent implements Provider, storing the event as a new resource
+func (p *CalDAVProvider) CreateEvent(ctx context.Context, calendar string, e Event) (Event, error) {
+	e.ID = newUID()
+	e.Calendar = calendar
+	_, _, err := do(ctx, p.Client, request{
+		method: http.MethodPut,
+		url:    p.collection(calendar) + e.ID + ".ics",
+		headers: map[string]string{
+			"Content-Type":  "text/calendar; charset=utf-8",
+			"If-None-Match": "*",
+		},
+		body: []byte(encodeICS(e, time.Now())),
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	e.Status = "confirmed"
+	return e, nil
+}
+
+// Busy implements Provider from the calendars' events
+func (p *CalDAVProvider) Busy(ctx context.Context, calendars []string, r TimeRange) ([]TimeRange, error) {
+	return busyFromEvents(ctx, p, calendars, r)
+}
+
+// newUID returns a random event UID
+func newUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,486 @@
+// Package calendar is a calendar backend for Google Calendar and CalDAV
+//
+// Three tools are served:
+//
+//	list_events      events of a date range, streamed in start order
+//	create_event     a timed or all-day event
+//	find_free_slots  gaps between busy times, optionally within working hours
+//
+// Times are RFC 3339 with a UTC offset, so every argument names one
+// instant; time_zone arguments (IANA names) choose the zone results are
+// shown in and working hours are measured in. Requests are authenticated
+// by an auth resource of the server's auth provider, typically OAuth2
+// with a calendar scope; CalDAV servers may use basic auth instead.
+// Importing the package registers the backend as "calendar":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/calendar"
+//
+//	# config.yaml
+//	backend:
+//	  type: calendar
+//	  config:
+//	    provider: google
+//	    auth_resource: google-calendar
+//	    time_zone: Europe/Berlin
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultGoogleCalendar = "primary"
+	DefaultMaxRangeDays   = 366
+	DefaultMaxSlots       = 20
+
+	// MaxSlots bounds the slots of one find_free_slots call
+	MaxSlots = 100
+)
+
+func init() {
+	backend.Register("calendar", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves a calendar account's events
+type Backend struct {
+	*backend.BaseBackend
+
+	logger *slog.Logger
+
+	// provider is set by NewWithProvider; otherwise one is built per call
+	// from kind and the authenticated client
+	provider Provider
+	kind     string
+	url      string
+
+	authProvider string
+	authResource string
+	username     string
+	password     string
+
+	calendar string
+	loc      *time.Location
+	maxRange time.Duration
+}
+
+// New creates a calendar backend; Initialize configures its provider
+func New() *Backend {
+	b := &Backend{
+		BaseBackend: backend.NewBaseBackend("calendar"),
+		logger:      slog.Default(),
+		loc:         time.UTC,
+		maxRange:    DefaultMaxRangeDays * 24 * time.Hour,
+	}
+	b.registerTools()
+	return b
+}
+
+// NewWithProvider creates a calendar backend over an existing provider;
+// the config's provider, url and auth entries are then ignored
+func NewWithProvider(provider Provider) *Backend {
+	b := New()
+	b.provider = provider
+	return b
+}
+
+// Initialize reads the config
+//
+// Config entries:
+//
+//	provider        google or caldav (required unless NewWithProvider)
+//	url             API endpoint (google: DefaultGoogleURL; caldav: the
+//	                calendar home, default the auth resource's base_url)
+//	calendar        calendar of calls naming none (google: "primary")
+//	auth_provider   auth provider of auth_resource (default: the backend's)
+//	auth_resource   auth resource whose HTTP client makes the requests
+//	username        CalDAV basic auth, when there is no auth_resource
+//	password
+//	time_zone       zone of results by default (default UTC)
+//	max_range_days  longest range of one call (default 366)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	b.calendar = backend.StringConfig(config, "calendar", "")
+	if name := backend.StringConfig(config, "time_zone", ""); name != "" {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return fmt.Errorf("calendar: invalid time_zone: %w", err)
+		}
+		b.loc = loc
+	}
+	days := backend.IntConfig(config, "max_range_days", DefaultMaxRangeDays)
+	if days <= 0 {
+		return errors.New("calendar: max_range_days must be positive")
+	}
+	b.maxRange = time.Duration(days) * 24 * time.Hour
+
+	if b.provider != nil {
+		return nil
+	}
+
+	b.kind = backend.StringConfig(config, "provider", "")
+	b.url = backend.StringConfig(config, "url", "")
+	b.authProvider = backend.StringConfig(config, "auth_provider", "")
+	b.authResource = backend.StringConfig(config, "auth_resource", "")
+	b.username = backend.StringConfig(config, "username", "")
+	b.password = backend.StringConfig(config, "password", "")
+
+	switch b.kind {
+	case "google":
+		if b.calendar == "" {
+			b.calendar = DefaultGoogleCalendar
+		}
+		if b.authResource == "" {
+			return errors.New("calendar: google needs an auth_resource")
+		}
+	case "caldav":
+		if b.authResource == "" && b.username == "" {
+			return errors.New("calendar: caldav needs an auth_resource or a username")
+		}
+		if b.authResource == "" && b.url == "" {
+			return errors.New("calendar: caldav needs a url")
+		}
+	case "":
+		return errors.New("calendar: provider is required (google or caldav)")
+	default:
+		return fmt.Errorf("calendar: unknown provider %q (google or caldav)", b.kind)
+	}
+	return nil
+}
+
+// connect returns the provider of a call and a function releasing it
+func (b *Backend) connect(ctx context.Context) (Provider, func(), error) {
+	if b.provider != nil {
+		return b.provider, func() {}, nil
+	}
+
+	var client *http.Client
+	baseURL := b.url
+	release := func() {}
+	switch {
+	case b.authResource != "":
+		resource, err := b.resource(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("calendar: auth resource %s: %w", b.authResource, err)
+		}
+		authenticated, resourceURL, err := auth.ResourceHTTPClient(resource)
+		if err != nil {
+			resource.Close()
+			return nil, nil, fmt.Errorf("calendar: auth resource %s: %w", b.authResource, err)
+		}
+		client = b.HTTPClient(authenticated)
+		if baseURL == "" && b.kind == "caldav" {
+			baseURL = resourceURL
+		}
+		release = func() { resource.Close() }
+	case b.username != "":
+		client = b.HTTPClient(&http.Client{Transport: basicAuth{username: b.username, password: b.password}})
+	default:
+		return nil, nil, backend.ErrNotInitialized
+	}
+
+	switch b.kind {
+	case "google":
+		return &GoogleProvider{Client: client, BaseURL: baseURL}, release, nil
+	case "caldav":
+		return &CalDAVProvider{Client: client, BaseURL: baseURL}, release, nil
+	}
+	release()
+	return nil, nil, backend.ErrNotInitialized
+}
+
+// resource gets the configured auth resource
+func (b *Backend) resource(ctx context.Context) (auth.Resource, error) {
+	if b.authProvider == "" {
+		if provider := b.GetAuthProvider(); provider != nil {
+			return provider.GetResource(ctx, b.authResource)
+		}
+		return b.GetAuthenticatedResource(ctx, "default", b.authResource)
+	}
+	return b.GetAuthenticatedResource(ctx, b.authProvider, b.authResource)
+}
+
+// basicAuth adds HTTP basic credentials to requests
+type basicAuth struct {
+	username, password string
+}
+
+func (t basicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type listArgs struct {
+	Calendar string           `json:"calendar,omitempty" description:"Calendar ID (default: the configured calendar)"`
+	Start    time.Time        `json:"start" description:"Range start, RFC 3339 with offset"`
+	End      time.Time        `json:"end" description:"Range end (exclusive), RFC 3339 with offset"`
+	TimeZone backend.TimeZone `json:"time_zone,omitempty" description:"IANA zone of returned times (default: the configured zone)"`
+}
+
+type createArgs struct {
+	Calendar    string           `json:"calendar,omitempty" description:"Calendar ID (default: the configured calendar)"`
+	Summary     string           `json:"summary" description:"Event title"`
+	Start       time.Time        `json:"start,omitempty" description:"Start of a timed event, RFC 3339 with offset"`
+	End         time.Time        `json:"end,omitempty" description:"End of a timed event, RFC 3339 with offset"`
+	Date        backend.Date     `json:"date,omitempty" description:"Day of an all-day event (YYYY-MM-DD), instead of start and end"`
+	Days        int              `json:"days,omitempty" jsonschema:"minimum=1,maximum=366" description:"Length of an all-day event in days (default 1)"`
+	TimeZone    backend.TimeZone `json:"time_zone,omitempty" description:"IANA zone the event is scheduled in (default: the configured zone)"`
+	Description string           `json:"description,omitempty" description:"Event details"`
+	Location    string           `json:"location,omitempty" description:"Where the event takes place"`
+	Attendees   []string         `json:"attendees,omitempty" description:"Email addresses to invite"`
+}
+
+type freeSlotsArgs struct {
+	Calendars       []string         `json:"calendars,omitempty" description:"Calendars whose events block time (default: the configured calendar)"`
+	Start           time.Time        `json:"start" description:"Range start, RFC 3339 with offset"`
+	End             time.Time        `json:"end" description:"Range end (exclusive), RFC 3339 with offset"`
+	DurationMinutes int              `json:"duration_minutes" jsonschema:"minimum=1" description:"Shortest useful slot in minutes"`
+	TimeZone        backend.TimeZone `json:"time_zone,omitempty" description:"IANA zone of working hours and returned times (default: the configured zone)"`
+	DayStart        string           `json:"day_start,omitempty" description:"Start of working hours as HH:MM (default: no working hours)"`
+	DayEnd          string           `json:"day_end,omitempty" description:"End of working hours as HH:MM"`
+	WeekdaysOnly    bool             `json:"weekdays_only,omitempty" description:"Skip Saturdays and Sundays"`
+	MaxSlots        int              `json:"max_slots,omitempty" jsonschema:"minimum=1,default=20" description:"Number of slots to return"`
+}
+
+type freeSlotsResult struct {
+	TimeZone string      `json:"time_zone"`
+	Slots    []TimeRange `json:"slots"`
+}
+
+func (b *Backend) registerTools() {
+	list := backend.NewTool("list_events").
+		Description("List the events of a date range in start order, recurring events expanded. Events stream one by one.").
+		ParamsFromStruct(listArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(list, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[listArgs](list, args)
+		if err != nil {
+			return err
+		}
+		return b.listEvents(ctx, in, emit)
+	})
+
+	create := backend.NewTool("create_event").
+		Description("Create a calendar event: a timed event from start to end, or an all-day event on date.").
+		ParamsFromStruct(createArgs{}).
+		NonCacheable().
+		Build()
+	backend.RegisterTypedTool(b, create, b.createEvent)
+
+	free := backend.NewTool("find_free_slots").
+		Description("Find free time of at least a given length across calendars, optionally within daily working hours.").
+		ParamsFromStruct(freeSlotsArgs{}).
+		NonCacheable().
+		Build()
+	backend.RegisterTypedTool(b, free, b.findFreeSlots)
+}
+
+// calendarOf returns the calendar a call names, or the default
+func (b *Backend) calendarOf(name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return b.calendar
+}
+
+// checkRange validates a call's start and end
+func (b *Backend) checkRange(tool string, start, end time.Time) error {
+	var msg string
+	switch {
+	case !end.After(start):
+		msg = "must be after start"
+	case end.Sub(start) > b.maxRange:
+		msg = fmt.Sprintf("range may span at most %d days", int(b.maxRange.Hours()/24))
+	default:
+		return nil
+	}
+	return &backend.ArgumentError{Tool: tool, Fields: []backend.FieldError{{Field: "end", Message: msg}}}
+}
+
+// localize shows an event in loc, keeping all-day events on their dates
+func localize(e Event, loc *time.Location) Event {
+	if e.AllDay {
+		e.Start = backend.DateOf(e.Start).In(loc)
+		e.End = backend.DateOf(e.End).In(loc)
+	} else {
+		e.Start = e.Start.In(loc)
+		e.End = e.End.In(loc)
+	}
+	return e
+}
+
+// listEvents handles list_events, emitting one Event per event
+func (b *Backend) listEvents(ctx context.Context, in listArgs, emit backend.StreamingEmitter) error {
+	if err := b.checkRange("list_events", in.Start, in.End); err != nil {
+		return err
+	}
+	provider, release, err := b.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	loc := in.TimeZone.Or(b.loc)
+	calendar := b.calendarOf(in.Calendar)
+	count := 0
+	err = provider.ListEvents(ctx, calendar, TimeRange{Start: in.Start, End: in.End}, func(e Event) error {
+		if e.Status == "cancelled" {
+			return nil
+		}
+		count++
+		return emit.EmitData(localize(e, loc))
+	})
+	if err != nil {
+		return err
+	}
+	b.logger.Debug("events listed", "calendar", calendar, "count", count)
+	return nil
+}
+
+// createEvent handles create_event
+func (b *Backend) createEvent(ctx context.Context, in createArgs) (*Event, error) {
+	fail := func(field, msg string) (*Event, error) {
+		return nil, &backend.ArgumentError{Tool: "create_event", Fields: []backend.FieldError{{Field: field, Message: msg}}}
+	}
+	if strings.TrimSpace(in.Summary) == "" {
+		return fail("summary", "must not be empty")
+	}
+
+	loc := in.TimeZone.Or(b.loc)
+	e := Event{
+		Summary:     in.Summary,
+		Description: in.Description,
+		Location:    in.Location,
+		Attendees:   in.Attendees,
+	}
+	timed := !in.Start.IsZero() || !in.End.IsZero()
+	switch {
+	case timed && !in.Date.IsZero():
+		return fail("date", "give either date or start and end")
+	case timed:
+		if in.Start.IsZero() {
+			return fail("start", "required with end")
+		}
+		if !in.End.After(in.Start) {
+			return fail("end", "must be after start")
+		}
+		e.Start, e.End = in.Start.In(loc), in.End.In(loc)
+	case !in.Date.IsZero():
+		days := max(in.Days, 1)
+		e.AllDay = true
+		e.Start, e.End = in.Date.In(loc), in.Date.AddDays(days).In(loc)
+	default:
+		return fail("start", "give start and end, or date")
+	}
+
+	provider, release, err := b.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	created, err := provider.CreateEvent(ctx, b.calendarOf(in.Calendar), e)
+	if err != nil {
+		return nil, err
+	}
+	created = localize(created, loc)
+	b.logger.Info("event created", "calendar", created.Calendar, "id", created.ID)
+	return &created, nil
+}
+
+// findFreeSlots handles find_free_slots
+func (b *Backend) findFreeSlots(ctx context.Context, in freeSlotsArgs) (*freeSlotsResult, error) {
+	fail := func(field, msg string) (*freeSlotsResult, error) {
+		return nil, &backend.ArgumentError{Tool: "find_free_slots", Fields: []backend.FieldError{{Field: field, Message: msg}}}
+	}
+	if err := b.checkRange("find_free_slots", in.Start, in.End); err != nil {
+		return nil, err
+	}
+	if in.DurationMinutes <= 0 {
+		return fail("duration_minutes", "must be positive")
+	}
+	maxSlots := in.MaxSlots
+	if maxSlots <= 0 {
+		maxSlots = DefaultMaxSlots
+	}
+	maxSlots = min(maxSlots, MaxSlots)
+
+	loc := in.TimeZone.Or(b.loc)
+	r := TimeRange{Start: in.Start, End: in.End}
+	windows := []TimeRange{r}
+	if in.DayStart != "" || in.DayEnd != "" || in.WeekdaysOnly {
+		hours := workingHours{start: 0, end: 24 * time.Hour, weekdaysOnly: in.WeekdaysOnly, loc: loc}
+		if in.DayStart != "" || in.DayEnd != "" {
+			start, err := clockTime(in.DayStart)
+			if err != nil {
+				return fail("day_start", err.Error())
+			}
+			end, err := clockTime(in.DayEnd)
+			if err != nil {
+				return fail("day_end", err.Error())
+			}
+			if end <= start {
+				return fail("day_end", "must be after day_start")
+			}
+			hours.start, hours.end = start, end
+		}
+		windows = hours.windows(r)
+	}
+
+	calendars := in.Calendars
+	if len(calendars) == 0 {
+		calendars = []string{b.calendar}
+	}
+
+	provider, release, err := b.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	busy, err := provider.Busy(ctx, calendars, r)
+	if err != nil {
+		return nil, err
+	}
+
+	slots := freeSlots(windows, busy, time.Duration(in.DurationMinutes)*time.Minute, maxSlots)
+	for i := range slots {
+		slots[i] = TimeRange{Start: slots[i].Start.In(loc), End: slots[i].End.In(loc)}
+	}
+	if slots == nil {
+		slots = []TimeRange{}
+	}
+	return &freeSlotsResult{TimeZone: loc.String(), Slots: slots}, nil
+}
+
+// clockTime parses HH:MM (24:00 allowed) into an offset from midnight
+func clockTime(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("required with working hours")
+	}
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
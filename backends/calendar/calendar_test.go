@@ -0,0 +1,468 @@
+package calendar_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backends/calendar"
+)
+
+type captureEmitter struct {
+	ctx    context.Context
+	events []calendar.Event
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.events = append(e.events, data.(calendar.Event))
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error { return nil }
+func (e *captureEmitter) Context() context.Context                                { return e.ctx }
+
+func newBackend(t *testing.T, provider calendar.Provider, config map[string]interface{}) *calendar.Backend {
+	t.Helper()
+	b := calendar.NewWithProvider(provider)
+	if err := b.Initialize(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func listEvents(t *testing.T, b *calendar.Backend, args map[string]interface{}) []calendar.Event {
+	t.Helper()
+	emit := &captureEmitter{ctx: context.Background()}
+	if err := b.CallStreamingTool(context.Background(), "list_events", args, emit); err != nil {
+		t.Fatal(err)
+	}
+	return emit.events
+}
+
+// fakeGoogle serves two pages of events, event creation and free/busy
+type fakeGoogle struct {
+	mu      sync.Mutex
+	created map[string]interface{}
+}
+
+func (f *fakeGoogle) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/calendars/primary/events":
+		if r.URL.Query().Get("singleEvents") != "true" {
+			http.Error(w, "expected singleEvents", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("pageToken") == "" {
+			io.WriteString(w, `{"items":[
+				{"id":"a","summary":"Standup","start":{"dateTime":"2026-03-02T09:00:00Z"},"end":{"dateTime":"2026-03-02T09:15:00Z"}},
+				{"id":"gone","status":"cancelled","start":{"dateTime":"2026-03-02T10:00:00Z"},"end":{"dateTime":"2026-03-02T11:00:00Z"}}
+			],"nextPageToken":"p2"}`)
+			return
+		}
+		io.WriteString(w, `{"items":[
+			{"id":"b","summary":"Holiday","start":{"date":"2026-03-03"},"end":{"date":"2026-03-04"}}
+		]}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/calendars/primary/events":
+		var in map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&in)
+		f.mu.Lock()
+		f.created = in
+		f.mu.Unlock()
+		in["id"] = "new"
+		in["status"] = "confirmed"
+		json.NewEncoder(w).Encode(in)
+	case r.Method == http.MethodPost && r.URL.Path == "/freeBusy":
+		io.WriteString(w, `{"calendars":{
+			"primary":{"busy":[{"start":"2026-03-02T08:00:00Z","end":"2026-03-02T09:00:00Z"}]},
+			"team":{"busy":[{"start":"2026-03-02T08:30:00Z","end":"2026-03-02T10:00:00Z"}]},
+			"missing":{"errors":[{"reason":"notFound"}]}
+		}}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newGoogle(t *testing.T) (*calendar.Backend, *fakeGoogle) {
+	t.Helper()
+	fake := &fakeGoogle{}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	provider := &calendar.GoogleProvider{Client: srv.Client(), BaseURL: srv.URL}
+	return newBackend(t, provider, map[string]interface{}{
+		"calendar":  "primary",
+		"time_zone": "Europe/Berlin",
+	}), fake
+}
+
+func TestGoogle_ListEvents(t *testing.T) {
+	b, _ := newGoogle(t)
+	events := listEvents(t, b, map[string]interface{}{
+		"start": "2026-03-01T00:00:00Z",
+		"end":   "2026-03-08T00:00:00Z",
+	})
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want standup and holiday", events)
+	}
+
+	standup := events[0]
+	if standup.ID != "a" || standup.Start.Format(time.RFC3339) != "2026-03-02T10:00:00+01:00" {
+		t.Errorf("standup = %s %s, want 10:00 Berlin time", standup.ID, standup.Start.Format(time.RFC3339))
+	}
+	holiday := events[1]
+	if !holiday.AllDay || holiday.Start.Format(time.RFC3339) != "2026-03-03T00:00:00+01:00" ||
+		holiday.End.Format(time.RFC3339) != "2026-03-04T00:00:00+01:00" {
+		t.Errorf("holiday = %+v, want local midnight of its dates", holiday)
+	}
+
+	// A per-call zone overrides the configured one
+	events = listEvents(t, b, map[string]interface{}{
+		"start":     "2026-03-01T00:00:00Z",
+		"end":       "2026-03-08T00:00:00Z",
+		"time_zone": "America/New_York",
+	})
+	if got := events[0].Start.Format(time.RFC3339); got != "2026-03-02T04:00:00-05:00" {
+		t.Errorf("standup in New York = %s", got)
+	}
+}
+
+func TestGoogle_CreateEvent(t *testing.T) {
+	b, fake := newGoogle(t)
+
+	result, err := b.CallTool(context.Background(), "create_event", map[string]interface{}{
+		"summary":   "Review",
+		"start":     "2026-03-02T14:00:00+01:00",
+		"end":       "2026-03-02T15:00:00+01:00",
+		"attendees": []interface{}{"bob@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := result.(*calendar.Event)
+	if e.ID != "new" || e.Start.Format(time.RFC3339) != "2026-03-02T14:00:00+01:00" {
+		t.Errorf("created = %+v", e)
+	}
+	start := fake.created["start"].(map[string]interface{})
+	if start["timeZone"] != "Europe/Berlin" {
+		t.Errorf("start = %v, want the configured zone", start)
+	}
+	if attendees := fake.created["attendees"].([]interface{}); len(attendees) != 1 {
+		t.Errorf("attendees = %v", attendees)
+	}
+
+	if _, err := b.CallTool(context.Background(), "create_event", map[string]interface{}{
+		"summary": "Offsite",
+		"date":    "2026-03-10",
+		"days":    2,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	start = fake.created["start"].(map[string]interface{})
+	end := fake.created["end"].(map[string]interface{})
+	if start["date"] != "2026-03-10" || end["date"] != "2026-03-12" {
+		t.Errorf("all-day event = %v to %v", start, end)
+	}
+}
+
+func TestGoogle_FindFreeSlots(t *testing.T) {
+	b, _ := newGoogle(t)
+	result, err := b.CallTool(context.Background(), "find_free_slots", map[string]interface{}{
+		"calendars":        []interface{}{"primary", "team"},
+		"start":            "2026-03-02T00:00:00+01:00",
+		"end":              "2026-03-03T00:00:00+01:00",
+		"duration_minutes": 30,
+		"day_start":        "09:00",
+		"day_end":          "17:00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result)
+	var out struct {
+		TimeZone string               `json:"time_zone"`
+		Slots    []calendar.TimeRange `json:"slots"`
+	}
+	json.Unmarshal(data, &out)
+	// Busy 09:00-11:00 Berlin time (merged across calendars)
+	if out.TimeZone != "Europe/Berlin" || len(out.Slots) != 1 ||
+		out.Slots[0].Start.Format("15:04") != "11:00" || out.Slots[0].End.Format("15:04") != "17:00" {
+		t.Errorf("result = %s", data)
+	}
+
+	_, err = b.CallTool(context.Background(), "find_free_slots", map[string]interface{}{
+		"calendars":        []interface{}{"missing"},
+		"start":            "2026-03-02T00:00:00Z",
+		"end":              "2026-03-03T00:00:00Z",
+		"duration_minutes": 30,
+	})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("unknown calendar: err = %v", err)
+	}
+}
+
+// stubProvider reports fixed busy times
+type stubProvider struct {
+	busy []calendar.TimeRange
+}
+
+func (p *stubProvider) ListEvents(ctx context.Context, cal string, r calendar.TimeRange, fn func(calendar.Event) error) error {
+	return nil
+}
+func (p *stubProvider) CreateEvent(ctx context.Context, cal string, e calendar.Event) (calendar.Event, error) {
+	return e, nil
+}
+func (p *stubProvider) Busy(ctx context.Context, cals []string, r calendar.TimeRange) ([]calendar.TimeRange, error) {
+	return p.busy, nil
+}
+
+func TestFindFreeSlots_DaylightSaving(t *testing.T) {
+	// Berlin switches to summer time on 2026-03-29, a Sunday
+	b := newBackend(t, &stubProvider{}, map[string]interface{}{"time_zone": "Europe/Berlin"})
+	result, err := b.CallTool(context.Background(), "find_free_slots", map[string]interface{}{
+		"start":            "2026-03-27T00:00:00+01:00",
+		"end":              "2026-03-31T00:00:00+02:00",
+		"duration_minutes": 60,
+		"day_start":        "09:00",
+		"day_end":          "17:00",
+		"weekdays_only":    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result)
+	var out struct {
+		Slots []calendar.TimeRange `json:"slots"`
+	}
+	json.Unmarshal(data, &out)
+
+	want := []string{
+		"2026-03-27T09:00:00+01:00/2026-03-27T17:00:00+01:00",
+		"2026-03-30T09:00:00+02:00/2026-03-30T17:00:00+02:00",
+	}
+	if len(out.Slots) != len(want) {
+		t.Fatalf("slots = %s", data)
+	}
+	for i, slot := range out.Slots {
+		if got := slot.Start.Format(time.RFC3339) + "/" + slot.End.Format(time.RFC3339); got != want[i] {
+			t.Errorf("slot %d = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+// fakeCalDAV answers calendar-query reports and stores PUT events
+type fakeCalDAV struct {
+	mu  sync.Mutex
+	put map[string]string
+}
+
+const multistatusBody = `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:response>
+    <D:href>/cal/work/1.ics</D:href>
+    <D:propstat>
+      <D:prop><C:calendar-data>BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:1
+DTSTART;TZID=America/New_York:20260302T090000
+DTEND;TZID=America/New_York:20260302T100000
+SUMMARY:Planning\, Q2 and
+  beyond
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Reminder
+END:VALARM
+ATTENDEE;CN=Bob:mailto:bob@example.com
+END:VEVENT
+END:VCALENDAR
+</C:calendar-data></D:prop>
+      <D:status>HTTP/1.1 200 OK</D:status>
+    </D:propstat>
+  </D:response>
+  <D:response>
+    <D:href>/cal/work/2.ics</D:href>
+    <D:propstat>
+      <D:prop><C:calendar-data>BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:2
+DTSTART;VALUE=DATE:20260301
+SUMMARY:Conference
+END:VEVENT
+END:VCALENDAR
+</C:calendar-data></D:prop>
+      <D:status>HTTP/1.1 200 OK</D:status>
+    </D:propstat>
+  </D:response>
+</D:multistatus>`
+
+func (f *fakeCalDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, _, ok := r.BasicAuth(); !ok || user != "alice" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch r.Method {
+	case "REPORT":
+		if r.URL.Path != "/cal/work/" || r.Header.Get("Depth") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, multistatusBody)
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		f.put[r.URL.Path] = string(body)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newCalDAV(t *testing.T, username string) (*calendar.Backend, *fakeCalDAV) {
+	t.Helper()
+	fake := &fakeCalDAV{put: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	b := calendar.New()
+	if err := b.Initialize(context.Background(), map[string]interface{}{
+		"provider": "caldav",
+		"url":      srv.URL + "/cal/",
+		"calendar": "work",
+		"username": username,
+		"password": "secret",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return b, fake
+}
+
+func TestCalDAV_ListEvents(t *testing.T) {
+	b, _ := newCalDAV(t, "alice")
+	events := listEvents(t, b, map[string]interface{}{
+		"start": "2026-03-01T00:00:00Z",
+		"end":   "2026-03-08T00:00:00Z",
+	})
+	if len(events) != 2 {
+		t.Fatalf("events = %+v", events)
+	}
+
+	if conf := events[0]; conf.ID != "2" || !conf.AllDay || conf.End.Format(backend.DateFormat) != "2026-03-02" {
+		t.Errorf("all-day event = %+v, want one day on 2026-03-01", conf)
+	}
+	planning := events[1]
+	if planning.Summary != "Planning, Q2 and beyond" {
+		t.Errorf("summary = %q", planning.Summary)
+	}
+	if got := planning.Start.UTC().Format(time.RFC3339); got != "2026-03-02T14:00:00Z" {
+		t.Errorf("start = %s, want 9:00 New York time", got)
+	}
+	if len(planning.Attendees) != 1 || planning.Attendees[0] != "bob@example.com" {
+		t.Errorf("attendees = %v", planning.Attendees)
+	}
+}
+
+func TestCalDAV_CreateEvent(t *testing.T) {
+	b, fake := newCalDAV(t, "alice")
+	result, err := b.CallTool(context.Background(), "create_event", map[string]interface{}{
+		"summary":   "Lunch; with team",
+		"start":     "2026-03-02T12:00:00+01:00",
+		"end":       "2026-03-02T13:00:00+01:00",
+		"time_zone": "Europe/Berlin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := result.(*calendar.Event)
+
+	fake.mu.Lock()
+	body := fake.put["/cal/work/"+e.ID+".ics"]
+	fake.mu.Unlock()
+	for _, want := range []string{"DTSTART:20260302T110000Z", "SUMMARY:Lunch\\; with team", "UID:" + e.ID} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stored event lacks %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestCalDAV_Unauthorized(t *testing.T) {
+	b, _ := newCalDAV(t, "mallory")
+	emit := &captureEmitter{ctx: context.Background()}
+	err := b.CallStreamingTool(context.Background(), "list_events", map[string]interface{}{
+		"start": "2026-03-01T00:00:00Z",
+		"end":   "2026-03-08T00:00:00Z",
+	}, emit)
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("err = %v, want permission denied", err)
+	}
+}
+
+func TestArgumentErrors(t *testing.T) {
+	b := newBackend(t, &stubProvider{}, nil)
+	cases := []struct {
+		tool  string
+		args  map[string]interface{}
+		field string
+	}{
+		{"find_free_slots", map[string]interface{}{
+			"start": "2026-03-02T00:00:00Z", "end": "2026-03-01T00:00:00Z", "duration_minutes": 30,
+		}, "end"},
+		{"find_free_slots", map[string]interface{}{
+			"start": "2026-01-01T00:00:00Z", "end": "2028-01-01T00:00:00Z", "duration_minutes": 30,
+		}, "end"},
+		{"find_free_slots", map[string]interface{}{
+			"start": "2026-03-01T00:00:00Z", "end": "2026-03-02T00:00:00Z", "duration_minutes": 30, "day_start": "9am", "day_end": "17:00",
+		}, "day_start"},
+		{"find_free_slots", map[string]interface{}{
+			"start": "2026-03-01T00:00:00Z", "end": "2026-03-02T00:00:00Z", "duration_minutes": 30, "time_zone": "Mars/Olympus",
+		}, "arguments"},
+		{"create_event", map[string]interface{}{
+			"summary": "x", "date": "2026-03-01", "start": "2026-03-01T10:00:00Z", "end": "2026-03-01T11:00:00Z",
+		}, "date"},
+		{"create_event", map[string]interface{}{"summary": " ", "date": "2026-03-01"}, "summary"},
+		{"create_event", map[string]interface{}{"summary": "x", "date": "01.03.2026"}, "date"},
+		{"create_event", map[string]interface{}{"summary": "x", "start": "2026-03-01 10:00"}, "start"},
+	}
+	for _, tc := range cases {
+		// Validate first, as the protocol handler does
+		tool, _ := b.GetTool(tc.tool)
+		err := backend.ValidateArguments(tool, tc.args)
+		if err == nil {
+			_, err = b.CallTool(context.Background(), tc.tool, tc.args)
+		}
+		var argErr *backend.ArgumentError
+		if !errors.As(err, &argErr) {
+			t.Errorf("%s %v: err = %v, want an argument error", tc.tool, tc.args, err)
+			continue
+		}
+		found := false
+		for _, f := range argErr.Fields {
+			found = found || f.Field == tc.field
+		}
+		if !found {
+			t.Errorf("%s %v: fields = %+v, want %s", tc.tool, tc.args, argErr.Fields, tc.field)
+		}
+	}
+}
+
+func TestInitialize_Errors(t *testing.T) {
+	for _, config := range []map[string]interface{}{
+		{},
+		{"provider": "outlook"},
+		{"provider": "google"},
+		{"provider": "caldav", "username": "alice"},
+		{"provider": "google", "auth_resource": "cal", "time_zone": "Nowhere/City"},
+		{"provider": "google", "auth_resource": "cal", "max_range_days": 0},
+	} {
+		if err := calendar.New().Initialize(context.Background(), config); err == nil {
+			t.Errorf("Initialize(%v) succeeded", config)
+		}
+	}
+}
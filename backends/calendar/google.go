@@ -0,0 +1,219 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// DefaultGoogleURL is the Google Calendar API v3 endpoint
+const DefaultGoogleURL = "https://www.googleapis.com/calendar/v3"
+
+// GoogleProvider is a Google Calendar account
+// Client must authenticate its requests, e.g. the client of an
+// OAuth2 resource with a calendar scope.
+type GoogleProvider struct {
+	Client *http.Client
+
+	// BaseURL defaults to DefaultGoogleURL
+	BaseURL string
+}
+
+// googleTime is the start or end of a Google event: dateTime for timed
+// events, date for all-day ones
+type googleTime struct {
+	DateTime string `json:"dateTime,omitempty"`
+	Date     string `json:"date,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type googleEvent struct {
+	ID           string     `json:"id,omitempty"`
+	Status       string     `json:"status,omitempty"`
+	Summary      string     `json:"summary,omitempty"`
+	Description  string     `json:"description,omitempty"`
+	Location     string     `json:"location,omitempty"`
+	HTMLLink     string     `json:"htmlLink,omitempty"`
+	Transparency string     `json:"transparency,omitempty"`
+	Start        googleTime `json:"start"`
+	End          googleTime `json:"end"`
+	Attendees    []struct {
+		Email string `json:"email"`
+	} `json:"attendees,omitempty"`
+}
+
+func (p *GoogleProvider) url(path string) string {
+	base := p.BaseURL
+	if base == "" {
+		base = DefaultGoogleURL
+	}
+	return strings.TrimSuffix(base, "/") + path
+}
+
+// ListEvents implements Provider, following result pages
+func (p *GoogleProvider) ListEvents(ctx context.Context, calendar string, r TimeRange, fn func(Event) error) error {
+	query := url.Values{
+		"timeMin":      {r.Start.Format(time.RFC3339)},
+		"timeMax":      {r.End.Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"orderBy":      {"startTime"},
+		"maxResults":   {"250"},
+	}
+	for {
+		data, _, err := do(ctx, p.Client, request{
+			method: http.MethodGet,
+			url:    p.url("/calendars/" + url.PathEscape(calendar) + "/events?" + query.Encode()),
+		})
+		if err != nil {
+			return err
+		}
+
+		var page struct {
+			Items         []googleEvent `json:"items"`
+			NextPageToken string        `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return mcperr.Upstream(err, "decode events")
+		}
+		for _, item := range page.Items {
+			e, err := item.event(calendar)
+			if err != nil {
+				return err
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+
+		if page.NextPageToken == "" {
+			return nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// CreateEvent implements Provider
+func (p *GoogleProvider) CreateEvent(ctx context.Context, calendar string, e Event) (Event, error) {
+	in := googleEvent{
+		Summary:     e.Summary,
+		Description: e.Description,
+		Location:    e.Location,
+	}
+	if e.AllDay {
+		in.Start.Date = e.Start.Format(backend.DateFormat)
+		in.End.Date = e.End.Format(backend.DateFormat)
+	} else {
+		in.Start = googleTime{DateTime: e.Start.Format(time.RFC3339), TimeZone: zoneName(e.Start.Location())}
+		in.End = googleTime{DateTime: e.End.Format(time.RFC3339), TimeZone: zoneName(e.End.Location())}
+	}
+	for _, email := range e.Attendees {
+		in.Attendees = append(in.Attendees, struct {
+			Email string `json:"email"`
+		}{email})
+	}
+
+	data, _, err := do(ctx, p.Client, request{
+		method: http.MethodPost,
+		url:    p.url("/calendars/" + url.PathEscape(calendar) + "/events"),
+		json:   in,
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	var out googleEvent
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Event{}, mcperr.Upstream(err, "decode created event")
+	}
+	return out.event(calendar)
+}
+
+// Busy implements Provider with the freeBusy query
+func (p *GoogleProvider) Busy(ctx context.Context, calendars []string, r TimeRange) ([]TimeRange, error) {
+	items := make([]map[string]string, len(calendars))
+	for i, calendar := range calendars {
+		items[i] = map[string]string{"id": calendar}
+	}
+	data, _, err := do(ctx, p.Client, request{
+		method: http.MethodPost,
+		url:    p.url("/freeBusy"),
+		json: map[string]interface{}{
+			"timeMin": r.Start.Format(time.RFC3339),
+			"timeMax": r.End.Format(time.RFC3339),
+			"items":   items,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		Calendars map[string]struct {
+			Busy   []TimeRange `json:"busy"`
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"calendars"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, mcperr.Upstream(err, "decode free/busy")
+	}
+
+	var busy []TimeRange
+	for _, calendar := range calendars {
+		result := out.Calendars[calendar]
+		if len(result.Errors) > 0 {
+			if result.Errors[0].Reason == "notFound" {
+				return nil, mcperr.NotFound("calendar %s not found", calendar)
+			}
+			return nil, mcperr.New(mcperr.CodeUpstream, "free/busy of %s: %s", calendar, result.Errors[0].Reason)
+		}
+		busy = append(busy, result.Busy...)
+	}
+	return busy, nil
+}
+
+// event converts a Google event
+func (g googleEvent) event(calendar string) (Event, error) {
+	e := Event{
+		ID:          g.ID,
+		Calendar:    calendar,
+		Summary:     g.Summary,
+		Description: g.Description,
+		Location:    g.Location,
+		Status:      g.Status,
+		Transparent: g.Transparency == "transparent",
+		URL:         g.HTMLLink,
+	}
+	for _, a := range g.Attendees {
+		e.Attendees = append(e.Attendees, a.Email)
+	}
+
+	var err error
+	if g.Start.Date != "" {
+		e.AllDay = true
+		if e.Start, err = time.Parse(backend.DateFormat, g.Start.Date); err == nil {
+			e.End, err = time.Parse(backend.DateFormat, g.End.Date)
+		}
+	} else if e.Start, err = time.Parse(time.RFC3339, g.Start.DateTime); err == nil {
+		e.End, err = time.Parse(time.RFC3339, g.End.DateTime)
+	}
+	if err != nil {
+		return Event{}, mcperr.Upstream(err, "event %s has an invalid time", g.ID)
+	}
+	return e, nil
+}
+
+// zoneName returns the IANA name of loc, or "" for zones Google does not
+// know by name
+func zoneName(loc *time.Location) string {
+	if name := loc.String(); name != "Local" {
+		return name
+	}
+	return ""
+}
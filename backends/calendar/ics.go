@@ -0,0 +1,265 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// iCalendar (RFC 5545) layouts
+const (
+	icsDate     = "20060102"
+	icsDateTime = "20060102T150405"
+	icsUTC      = "20060102T150405Z"
+)
+
+// icsLine is a content line: NAME;PARAM=VALUE:value
+type icsLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICS returns the VEVENTs of an iCalendar object
+// Times with an unknown TZID, and floating times, are read in UTC.
+func parseICS(data string) ([]Event, error) {
+	var events []Event
+	var current []icsLine
+	inEvent := false
+	nested := 0 // depth of components (VALARM) inside the event
+
+	for _, line := range unfold(data) {
+		l, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case !inEvent:
+			if l.name == "BEGIN" && strings.EqualFold(l.value, "VEVENT") {
+				inEvent = true
+				current = nil
+			}
+		case l.name == "BEGIN":
+			nested++
+		case l.name == "END" && nested > 0:
+			nested--
+		case nested > 0:
+		case l.name == "END":
+			inEvent = false
+			e, err := eventFromLines(current)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, e)
+		default:
+			current = append(current, l)
+		}
+	}
+	return events, nil
+}
+
+// unfold joins continuation lines (RFC 5545 section 3.1)
+func unfold(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseLine splits a content line into name, parameters and value
+func parseLine(line string) (icsLine, bool) {
+	// The value starts at the first colon outside a quoted parameter
+	colon := -1
+	quoted := false
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icsLine{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	l := icsLine{name: strings.ToUpper(parts[0]), value: line[colon+1:], params: map[string]string{}}
+	for _, param := range parts[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		l.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return l, true
+}
+
+// eventFromLines builds an event from the properties of a VEVENT
+func eventFromLines(lines []icsLine) (Event, error) {
+	var e Event
+	var recurrenceID string
+	var duration time.Duration
+	hasEnd := false
+
+	for _, l := range lines {
+		var err error
+		switch l.name {
+		case "UID":
+			e.ID = l.value
+		case "RECURRENCE-ID":
+			recurrenceID = l.value
+		case "SUMMARY":
+			e.Summary = unescapeText(l.value)
+		case "DESCRIPTION":
+			e.Description = unescapeText(l.value)
+		case "LOCATION":
+			e.Location = unescapeText(l.value)
+		case "URL":
+			e.URL = l.value
+		case "STATUS":
+			e.Status = strings.ToLower(l.value)
+		case "TRANSP":
+			e.Transparent = strings.EqualFold(l.value, "TRANSPARENT")
+		case "ATTENDEE":
+			if email, ok := strings.CutPrefix(strings.ToLower(l.value), "mailto:"); ok {
+				e.Attendees = append(e.Attendees, email)
+			}
+		case "DTSTART":
+			e.Start, e.AllDay, err = parseICSTime(l)
+		case "DTEND":
+			e.End, _, err = parseICSTime(l)
+			hasEnd = true
+		case "DURATION":
+			duration, err = parseICSDuration(l.value)
+		}
+		if err != nil {
+			return Event{}, fmt.Errorf("event %s: %s: %w", e.ID, l.name, err)
+		}
+	}
+
+	if e.Start.IsZero() {
+		return Event{}, fmt.Errorf("event %s has no DTSTART", e.ID)
+	}
+	switch {
+	case hasEnd:
+	case duration > 0:
+		e.End = e.Start.Add(duration)
+	case e.AllDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	default:
+		e.End = e.Start
+	}
+	if recurrenceID != "" {
+		e.ID += "/" + recurrenceID
+	}
+	return e, nil
+}
+
+// parseICSTime parses a DATE or DATE-TIME value, reporting whether it is
+// a date; dates are returned at UTC midnight
+func parseICSTime(l icsLine) (time.Time, bool, error) {
+	value := l.value
+	if l.params["VALUE"] == "DATE" || len(value) == len(icsDate) {
+		t, err := time.Parse(icsDate, value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsUTC, value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzid := l.params["TZID"]; tzid != "" {
+		if zone, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			loc = zone
+		}
+	}
+	t, err := time.ParseInLocation(icsDateTime, value, loc)
+	return t, false, err
+}
+
+var icsDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICSDuration parses a DURATION value such as PT1H30M or P1D
+func parseICSDuration(value string) (time.Duration, error) {
+	m := icsDurationPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// unescapeText decodes a TEXT value
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// escapeText encodes a TEXT value
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`).Replace(s)
+}
+
+// encodeICS returns an iCalendar object holding one event
+// Timed events are written in UTC; all-day events as the dates of Start
+// and End in their location.
+func encodeICS(e Event, stamp time.Time) string {
+	var b strings.Builder
+	write := func(line string) {
+		// Fold at 75 octets without splitting UTF-8 sequences
+		for len(line) > 75 {
+			cut := 75
+			for cut > 0 && line[cut]&0xC0 == 0x80 {
+				cut--
+			}
+			b.WriteString(line[:cut] + "\r\n")
+			line = " " + line[cut:]
+		}
+		b.WriteString(line + "\r\n")
+	}
+
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:-//go-mcp-framework//calendar//EN")
+	write("BEGIN:VEVENT")
+	write("UID:" + e.ID)
+	write("DTSTAMP:" + stamp.UTC().Format(icsUTC))
+	if e.AllDay {
+		write("DTSTART;VALUE=DATE:" + backend.DateOf(e.Start).In(time.UTC).Format(icsDate))
+		write("DTEND;VALUE=DATE:" + backend.DateOf(e.End).In(time.UTC).Format(icsDate))
+	} else {
+		write("DTSTART:" + e.Start.UTC().Format(icsUTC))
+		write("DTEND:" + e.End.UTC().Format(icsUTC))
+	}
+	write("SUMMARY:" + escapeText(e.Summary))
+	if e.Description != "" {
+		write("DESCRIPTION:" + escapeText(e.Description))
+	}
+	if e.Location != "" {
+		write("LOCATION:" + escapeText(e.Location))
+	}
+	for _, email := range e.Attendees {
+		write("ATTENDEE;RSVP=TRUE:mailto:" + email)
+	}
+	write("END:VEVENT")
+	write("END:VCALENDAR")
+	return b.String()
+}
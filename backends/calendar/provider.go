@@ -0,0 +1,174 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// ErrReadOnly is returned by providers that cannot create events
+var ErrReadOnly = errors.New("calendar is read-only")
+
+// Event is a calendar event
+// Start and End are instants; all-day events start and end at midnight
+// of their dates in the zone the caller asked for, with End exclusive.
+type Event struct {
+	ID          string    `json:"id"`
+	Calendar    string    `json:"calendar,omitempty"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	AllDay      bool      `json:"all_day,omitempty"`
+	Attendees   []string  `json:"attendees,omitempty"`
+
+	// Status is confirmed, tentative or cancelled
+	Status string `json:"status,omitempty"`
+
+	// Transparent events (e.g. reminders) don't make their time busy
+	Transparent bool `json:"transparent,omitempty"`
+
+	URL string `json:"url,omitempty"`
+}
+
+// TimeRange is the half-open interval [Start, End)
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Provider is a calendar service
+//
+// Providers return all-day events with Start and End at UTC midnight of
+// their dates; the backend moves them into the caller's zone.
+type Provider interface {
+	// ListEvents calls fn with the events overlapping r in start order,
+	// recurring events expanded into their occurrences
+	ListEvents(ctx context.Context, calendar string, r TimeRange, fn func(Event) error) error
+
+	// CreateEvent creates an event and returns it as stored
+	// Timed events keep the zone of their Start; all-day events use the
+	// dates of Start and End in their location.
+	CreateEvent(ctx context.Context, calendar string, e Event) (Event, error)
+
+	// Busy returns the busy intervals of the calendars within r
+	Busy(ctx context.Context, calendars []string, r TimeRange) ([]TimeRange, error)
+}
+
+// busyFromEvents derives busy intervals from the events of calendars,
+// for providers without a free/busy query
+func busyFromEvents(ctx context.Context, p Provider, calendars []string, r TimeRange) ([]TimeRange, error) {
+	var busy []TimeRange
+	for _, calendar := range calendars {
+		err := p.ListEvents(ctx, calendar, r, func(e Event) error {
+			if e.Status != "cancelled" && !e.Transparent {
+				busy = append(busy, TimeRange{Start: e.Start, End: e.End})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return busy, nil
+}
+
+// sortEvents orders events by start, then end
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].Start.Equal(events[j].Start) {
+			return events[i].Start.Before(events[j].Start)
+		}
+		return events[i].End.Before(events[j].End)
+	})
+}
+
+// ============================================================
+// HTTP
+// ============================================================
+
+// request is an HTTP request to a calendar service
+type request struct {
+	method  string
+	url     string
+	headers map[string]string
+
+	// body is sent as is; json is marshaled when body is nil
+	body []byte
+	json interface{}
+}
+
+// do sends a request and returns the response body, categorizing
+// failures
+func do(ctx context.Context, client *http.Client, r request) ([]byte, http.Header, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	body := r.body
+	if body == nil && r.json != nil {
+		data, err := json.Marshal(r.json)
+		if err != nil {
+			return nil, nil, err
+		}
+		body = data
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.json != nil && r.body == nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range r.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, mcperr.Upstream(err, "%s %s", r.method, req.URL.Redacted())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		msg := fmt.Sprintf("%s %s: HTTP %d: %s", r.method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(detail)))
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return nil, nil, mcperr.NotFound("%s", msg)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return nil, nil, mcperr.PermissionDenied("%s", msg)
+		case http.StatusTooManyRequests:
+			seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			return nil, nil, mcperr.RateLimited(time.Duration(seconds)*time.Second, "%s", msg)
+		}
+		return nil, nil, mcperr.Upstream(errors.New(msg), "request failed")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, nil, mcperr.Upstream(err, "read %s response", req.URL.Path)
+	}
+	return data, resp.Header, nil
+}
+
+// maxResponseSize bounds a calendar service's response
+const maxResponseSize = 32 * 1024 * 1024
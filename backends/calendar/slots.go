@@ -0,0 +1,100 @@
+package calendar
+
+import (
+	"sort"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// workingHours limits free slots to a daily window in a time zone
+type workingHours struct {
+	// start and end are offsets from midnight, e.g. 9h and 17h
+	start, end   time.Duration
+	weekdaysOnly bool
+	loc          *time.Location
+}
+
+// windows returns the working windows of each day overlapping r
+// Each day's window is built from its wall clock in loc, so it stays
+// 9:00-17:00 local on days daylight saving starts or ends.
+func (h workingHours) windows(r TimeRange) []TimeRange {
+	var windows []TimeRange
+	last := backend.DateOf(r.End.In(h.loc))
+	for day := backend.DateOf(r.Start.In(h.loc)); !day.In(time.UTC).After(last.In(time.UTC)); day = day.AddDays(1) {
+		midnight := day.In(h.loc)
+		if weekday := midnight.Weekday(); h.weekdaysOnly && (weekday == time.Saturday || weekday == time.Sunday) {
+			continue
+		}
+		window := TimeRange{
+			Start: wallClock(day, h.start, h.loc),
+			End:   wallClock(day, h.end, h.loc),
+		}
+		if window.Start.Before(r.Start) {
+			window.Start = r.Start
+		}
+		if window.End.After(r.End) {
+			window.End = r.End
+		}
+		if window.Start.Before(window.End) {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+// wallClock returns the instant offset (e.g. 9h30m) shows on day in loc
+func wallClock(day backend.Date, offset time.Duration, loc *time.Location) time.Time {
+	minutes := int(offset / time.Minute)
+	return time.Date(day.Year, day.Month, day.Day, minutes/60, minutes%60, 0, 0, loc)
+}
+
+// mergeBusy sorts intervals and merges overlapping or touching ones
+func mergeBusy(busy []TimeRange) []TimeRange {
+	sorted := append([]TimeRange(nil), busy...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var merged []TimeRange
+	for _, b := range sorted {
+		if !b.Start.Before(b.End) {
+			continue
+		}
+		if n := len(merged); n > 0 && !b.Start.After(merged[n-1].End) {
+			if b.End.After(merged[n-1].End) {
+				merged[n-1].End = b.End
+			}
+			continue
+		}
+		merged = append(merged, b)
+	}
+	return merged
+}
+
+// freeSlots returns the gaps of at least d between busy intervals within
+// windows, at most max of them
+func freeSlots(windows, busy []TimeRange, d time.Duration, max int) []TimeRange {
+	busy = mergeBusy(busy)
+	var slots []TimeRange
+	for _, w := range windows {
+		cursor := w.Start
+		for _, b := range busy {
+			if !b.End.After(cursor) {
+				continue
+			}
+			if !b.Start.Before(w.End) {
+				break
+			}
+			if b.Start.Sub(cursor) >= d {
+				slots = append(slots, TimeRange{Start: cursor, End: b.Start})
+			}
+			cursor = b.End
+		}
+		if w.End.Sub(cursor) >= d {
+			slots = append(slots, TimeRange{Start: cursor, End: w.End})
+		}
+		if len(slots) >= max {
+			return slots[:max]
+		}
+	}
+	return slots
+}
@@ -0,0 +1,516 @@
+// Package codesearch is a backend for code assistants working over a
+// repository
+//
+// It indexes the definitions in a source tree (Go through go/parser;
+// Python, JavaScript/TypeScript, Rust, Java and Ruby through per-language
+// patterns) and serves three tools:
+//
+//	find_symbol      definitions by name, best matches first
+//	find_references  occurrences of a name, streamed file by file
+//	open_file_range  numbered lines of a file
+//
+// The index is rescanned periodically and only files whose size or
+// modification time changed are reparsed. Importing the package registers
+// the backend as "codesearch":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/codesearch"
+//
+//	# config.yaml
+//	backend:
+//	  type: codesearch
+//	  config:
+//	    root: ./src
+//	    reindex_interval: 10s
+//	    exclude: [.git, node_modules, vendor]
+package codesearch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultReindexInterval = 10 * time.Second
+	DefaultMaxFileSize     = 1024 * 1024 // 1MB
+	DefaultMaxResults      = 100
+	DefaultMaxReferences   = 1000
+
+	// MaxRangeLines bounds the lines open_file_range returns
+	MaxRangeLines = 500
+)
+
+// DefaultExclude are the directories skipped when exclude isn't configured
+var DefaultExclude = []string{".git", ".hg", ".svn", "node_modules", "vendor", "target", "dist", "__pycache__"}
+
+func init() {
+	backend.Register("codesearch", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves symbol search over a source tree
+type Backend struct {
+	*backend.BaseBackend
+
+	index    *Index
+	security *security.Manager
+	logger   *slog.Logger
+
+	reindexInterval time.Duration
+	maxResults      int
+	maxReferences   int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a codesearch backend
+func New() *Backend {
+	b := &Backend{
+		BaseBackend: backend.NewBaseBackend("codesearch"),
+		logger:      slog.Default(),
+	}
+	b.registerTools()
+	return b
+}
+
+// Initialize indexes the tree and starts reindexing
+//
+// Config entries:
+//
+//	root              directory to index (default ".")
+//	exclude           directory names skipped (default DefaultExclude)
+//	max_file_size     larger files are not indexed (default 1MB)
+//	reindex_interval  rescan period, "0" disables (default 10s)
+//	max_results       find_symbol result cap (default 100)
+//	max_references    find_references result cap (default 1000)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	root := "."
+	if r, ok := config["root"].(string); ok && r != "" {
+		root = r
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("codesearch: resolve root: %w", err)
+	}
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+
+	exclude := DefaultExclude
+	if list, ok := config["exclude"].([]interface{}); ok {
+		exclude = make([]string, 0, len(list))
+		for _, dir := range list {
+			if s, ok := dir.(string); ok {
+				exclude = append(exclude, s)
+			}
+		}
+	}
+
+	maxFileSize := int64(backend.IntConfig(config, "max_file_size", DefaultMaxFileSize))
+	b.maxResults = backend.IntConfig(config, "max_results", DefaultMaxResults)
+	b.maxReferences = backend.IntConfig(config, "max_references", DefaultMaxReferences)
+
+	b.reindexInterval = DefaultReindexInterval
+	if s, ok := config["reindex_interval"].(string); ok && s != "" {
+		if b.reindexInterval, err = time.ParseDuration(s); err != nil {
+			return fmt.Errorf("codesearch: invalid reindex_interval: %w", err)
+		}
+	}
+
+	b.index = NewIndex(root, exclude, maxFileSize)
+
+	b.security, err = security.NewManager(security.Config{
+		Root:        root,
+		ReadOnly:    true,
+		MaxFileSize: maxFileSize,
+	})
+	if err != nil {
+		return err
+	}
+	b.security.Use(b.index.excludePolicy())
+	stats, err := b.index.Update(ctx)
+	if err != nil {
+		return fmt.Errorf("codesearch: index %s: %w", root, err)
+	}
+	b.logger.Info("codesearch index built",
+		"root", root,
+		"files", stats.Files,
+		"symbols", stats.Symbols,
+		"duration", stats.Duration)
+
+	if b.reindexInterval > 0 {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		b.cancel = cancel
+		b.wg.Add(1)
+		go b.watch(watchCtx)
+	}
+	return nil
+}
+
+// Close stops reindexing
+func (b *Backend) Close() error {
+	if b.cancel != nil {
+		b.cancel()
+		b.wg.Wait()
+	}
+	return nil
+}
+
+// Index returns the symbol index (nil before Initialize)
+func (b *Backend) Index() *Index {
+	return b.index
+}
+
+// watch rescans the tree every reindex interval
+func (b *Backend) watch(ctx context.Context) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.reindexInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats, err := b.index.Update(ctx)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				b.logger.Warn("codesearch reindex failed", "error", err)
+			}
+			continue
+		}
+		if stats.Changed() {
+			b.logger.Debug("codesearch index updated",
+				"added", stats.Added,
+				"updated", stats.Updated,
+				"removed", stats.Removed,
+				"duration", stats.Duration)
+		}
+	}
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Readiness degrades while rescans fail; the index keeps serving the last
+// successful scan.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	r.RegisterReadiness("codesearch:index", func(ctx context.Context) error {
+		if b.index == nil {
+			return errors.New("index not built")
+		}
+		if updated, err := b.index.Status(); err != nil {
+			return health.Degraded(fmt.Errorf("reindex failing since %s: %w", updated.Format(time.RFC3339), err))
+		}
+		return nil
+	})
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type findSymbolArgs struct {
+	Query string `json:"query"`
+	Kind  string `json:"kind"`
+	Limit int    `json:"limit"`
+}
+
+type findSymbolResult struct {
+	Symbols   []Symbol `json:"symbols"`
+	Total     int      `json:"total"`
+	Truncated bool     `json:"truncated"`
+}
+
+type referencesArgs struct {
+	Symbol     string `json:"symbol"`
+	PathPrefix string `json:"path_prefix"`
+	Limit      int    `json:"limit"`
+}
+
+// Reference is an occurrence of a name
+type Reference struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Text   string `json:"text"`
+
+	// Definition marks occurrences on a line defining the name
+	Definition bool `json:"definition"`
+}
+
+type openRangeArgs struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+type openRangeResult struct {
+	Path       string `json:"path"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	TotalLines int    `json:"total_lines"`
+	Content    string `json:"content"`
+}
+
+func (b *Backend) registerTools() {
+	one, maxLimit := 1, 10000
+	kinds := []string{KindFunc, KindMethod, KindType, KindStruct, KindInterface, KindClass, KindModule, KindEnum, KindConst, KindVar}
+
+	findSymbol := backend.NewTool("find_symbol").
+		Description("Find definitions (functions, methods, types, classes, ...) by name. Exact matches rank first, then prefixes and substrings; 'Type.method' restricts to a container.").
+		StringParam("query", "Symbol name or part of it", true).
+		EnumParam("kind", "Only return symbols of this kind", false, kinds, nil).
+		IntParam("limit", "Maximum number of results", false, &one, &maxLimit).
+		NonCacheable().
+		Build()
+	backend.RegisterTypedTool(b, findSymbol, b.findSymbol)
+
+	references := backend.NewTool("find_references").
+		Description("Stream every whole-word occurrence of a name across the indexed files, with the line's text. Definitions are marked.").
+		StringParam("symbol", "Name to find", true).
+		StringParam("path_prefix", "Only search files under this directory", false).
+		IntParam("limit", "Maximum number of references", false, &one, &maxLimit).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(references, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[referencesArgs](references, args)
+		if err != nil {
+			return err
+		}
+		return b.findReferences(ctx, in, emit)
+	})
+
+	maxLine := 1 << 30
+	openRange := backend.NewTool("open_file_range").
+		Description(fmt.Sprintf("Read numbered lines of a file in the indexed tree (at most %d lines).", MaxRangeLines)).
+		StringParam("path", "File path relative to the indexed root", true).
+		IntParam("start_line", "First line (1-based)", true, &one, &maxLine).
+		IntParam("end_line", fmt.Sprintf("Last line, inclusive (default: start_line + %d)", MaxRangeLines/10), false, &one, &maxLine).
+		NonCacheable().
+		Build()
+	backend.RegisterTypedTool(b, openRange, b.openFileRange)
+}
+
+// findSymbol handles find_symbol
+func (b *Backend) findSymbol(ctx context.Context, in findSymbolArgs) (*findSymbolResult, error) {
+	if b.index == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	limit := in.Limit
+	if limit <= 0 || limit > b.maxResults {
+		limit = b.maxResults
+	}
+
+	symbols, total := b.index.Find(strings.TrimSpace(in.Query), in.Kind, limit)
+	if symbols == nil {
+		symbols = []Symbol{}
+	}
+	return &findSymbolResult{
+		Symbols:   symbols,
+		Total:     total,
+		Truncated: total > len(symbols),
+	}, nil
+}
+
+// findReferences handles find_references, emitting one Reference per
+// occurrence and progress after each file
+func (b *Backend) findReferences(ctx context.Context, in referencesArgs, emit backend.StreamingEmitter) error {
+	if b.index == nil {
+		return backend.ErrNotInitialized
+	}
+	name := strings.TrimSpace(in.Symbol)
+	if name == "" {
+		return &backend.ArgumentError{Tool: "find_references", Fields: []backend.FieldError{{Field: "symbol", Message: "must not be empty"}}}
+	}
+	limit := in.Limit
+	if limit <= 0 || limit > b.maxReferences {
+		limit = b.maxReferences
+	}
+	prefix := strings.Trim(filepath.ToSlash(in.PathPrefix), "/")
+
+	var files []string
+	for _, file := range b.index.Files() {
+		if prefix == "" || file == prefix || strings.HasPrefix(file, prefix+"/") {
+			files = append(files, file)
+		}
+	}
+	defs := b.index.definitions(name)
+
+	found := 0
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		refs, err := b.scanFile(file, name, limit-found)
+		if err != nil {
+			continue // deleted since the last scan
+		}
+		for _, ref := range refs {
+			ref.Definition = defs[position(ref.File, ref.Line)]
+			if err := emit.EmitData(ref); err != nil {
+				return err
+			}
+		}
+		found += len(refs)
+
+		if found >= limit {
+			return emit.EmitProgress(int64(i+1), int64(len(files)),
+				fmt.Sprintf("Stopped at %d references (limit)", found))
+		}
+		emit.EmitProgress(int64(i+1), int64(len(files)),
+			fmt.Sprintf("Searched %d/%d files, %d references", i+1, len(files), found))
+	}
+	return nil
+}
+
+// scanFile returns up to max whole-word occurrences of name in file
+func (b *Backend) scanFile(file, name string, max int) ([]Reference, error) {
+	f, err := os.Open(filepath.Join(b.security.Root(), filepath.FromSlash(file)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var refs []Reference
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, col := range wordIndexes(text, name) {
+			refs = append(refs, Reference{
+				File:   file,
+				Line:   line,
+				Column: col + 1,
+				Text:   truncate(strings.TrimSpace(text), 200),
+			})
+			if len(refs) >= max {
+				return refs, nil
+			}
+		}
+	}
+	return refs, scanner.Err()
+}
+
+// openFileRange handles open_file_range
+func (b *Backend) openFileRange(ctx context.Context, in openRangeArgs) (*openRangeResult, error) {
+	if b.security == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	path, err := b.security.Authorize(ctx, security.OpRead, in.Path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, mcperr.NotFound("file not found: %s", in.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &backend.ArgumentError{Tool: "open_file_range", Fields: []backend.FieldError{{Field: "path", Message: "is a directory"}}}
+	}
+	if err := b.security.Check(ctx, security.Request{Op: security.OpRead, Size: info.Size()}); err != nil {
+		return nil, err
+	}
+
+	start := max(in.StartLine, 1)
+	end := in.EndLine
+	if end <= 0 {
+		end = start + MaxRangeLines/10
+	}
+	if end < start {
+		return nil, &backend.ArgumentError{Tool: "open_file_range", Fields: []backend.FieldError{{Field: "end_line", Message: "must not be before start_line"}}}
+	}
+	end = min(end, start+MaxRangeLines-1)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var content strings.Builder
+	total := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		total++
+		if total >= start && total <= end {
+			content.WriteString(strconv.Itoa(total))
+			content.WriteString("\t")
+			content.WriteString(scanner.Text())
+			content.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &openRangeResult{
+		Path:       filepath.ToSlash(filepath.Clean(in.Path)),
+		StartLine:  start,
+		EndLine:    min(end, total),
+		TotalLines: total,
+		Content:    content.String(),
+	}, nil
+}
+
+// ============================================================
+// Helpers
+// ============================================================
+
+// wordIndexes returns the byte offsets of whole-word occurrences of word
+func wordIndexes(s, word string) []int {
+	var idx []int
+	for offset := 0; ; {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return idx
+		}
+		i += offset
+		end := i + len(word)
+		if (i == 0 || !isIdent(s[i-1])) && (end == len(s) || !isIdent(s[end])) {
+			idx = append(idx, i)
+		}
+		offset = i + 1
+	}
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// position keys a file line
+func position(file string, line int) string {
+	return file + ":" + strconv.Itoa(line)
+}
+
+// truncate shortens s to n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
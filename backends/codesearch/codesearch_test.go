@@ -0,0 +1,207 @@
+package codesearch_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backends/codesearch"
+)
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTree(t *testing.T) (string, *codesearch.Backend) {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, root, "server/server.go", `package server
+
+type Server struct{ name string }
+
+type Runner interface {
+	Run() error
+}
+
+const DefaultName = "mcp"
+
+func NewServer() *Server { return &Server{name: DefaultName} }
+
+func (s *Server) Run() error { return nil }
+`)
+	writeFile(t, root, "main.go", `package main
+
+func main() {
+	s := server.NewServer()
+	s.Run()
+}
+`)
+	writeFile(t, root, "tools/weather.py", `class WeatherClient:
+    def fetch(self, city):
+        return city
+
+def fetch_all():
+    pass
+`)
+	writeFile(t, root, "web/app.ts", `export interface Options {}
+export const NewServer = async (opts: Options) => {}
+`)
+	writeFile(t, root, "node_modules/dep/index.js", "function NewServer() {}\n")
+
+	b := codesearch.New()
+	err := b.Initialize(context.Background(), map[string]interface{}{
+		"root":             root,
+		"reindex_interval": "0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return root, b
+}
+
+type symbolsResult struct {
+	Symbols []codesearch.Symbol `json:"symbols"`
+	Total   int                 `json:"total"`
+}
+
+func findSymbol(t *testing.T, b *codesearch.Backend, args map[string]interface{}) symbolsResult {
+	t.Helper()
+	result, err := b.CallTool(context.Background(), "find_symbol", args)
+	if err != nil {
+		t.Fatalf("find_symbol: %v", err)
+	}
+	data, _ := json.Marshal(result)
+	var out symbolsResult
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func TestFindSymbol(t *testing.T) {
+	_, b := newTree(t)
+
+	got := findSymbol(t, b, map[string]interface{}{"query": "NewServer"})
+	if got.Total != 2 {
+		t.Fatalf("NewServer matches = %+v (node_modules must be excluded)", got.Symbols)
+	}
+	if s := got.Symbols[0]; s.File != "server/server.go" && s.File != "web/app.ts" || s.Line == 0 {
+		t.Errorf("symbol = %+v", s)
+	}
+
+	got = findSymbol(t, b, map[string]interface{}{"query": "Server.Run"})
+	if got.Total != 1 || got.Symbols[0].Kind != codesearch.KindMethod || got.Symbols[0].Line != 13 {
+		t.Errorf("Server.Run = %+v", got.Symbols)
+	}
+
+	got = findSymbol(t, b, map[string]interface{}{"query": "run", "kind": "method"})
+	if got.Total != 2 {
+		t.Errorf("run methods = %+v", got.Symbols)
+	}
+
+	got = findSymbol(t, b, map[string]interface{}{"query": "WeatherClient.fetch"})
+	if got.Total != 1 || got.Symbols[0].Kind != codesearch.KindMethod {
+		t.Errorf("python method = %+v", got.Symbols)
+	}
+	got = findSymbol(t, b, map[string]interface{}{"query": "fetch_all"})
+	if got.Total != 1 || got.Symbols[0].Container != "" {
+		t.Errorf("python function = %+v", got.Symbols)
+	}
+
+	got = findSymbol(t, b, map[string]interface{}{"query": "Server", "limit": 1})
+	if len(got.Symbols) != 1 || got.Symbols[0].Name != "Server" || got.Total < 3 {
+		t.Errorf("ranking = %+v (total %d)", got.Symbols, got.Total)
+	}
+}
+
+type captureEmitter struct {
+	ctx  context.Context
+	data []interface{}
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.data = append(e.data, data)
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error { return nil }
+func (e *captureEmitter) Context() context.Context                                { return e.ctx }
+
+func TestFindReferences(t *testing.T) {
+	_, b := newTree(t)
+
+	emit := &captureEmitter{ctx: context.Background()}
+	err := b.CallStreamingTool(context.Background(), "find_references",
+		map[string]interface{}{"symbol": "NewServer", "path_prefix": "server"}, emit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emit.data) != 1 {
+		t.Fatalf("references under server/ = %+v", emit.data)
+	}
+	if ref := emit.data[0].(codesearch.Reference); !ref.Definition || ref.Line != 11 {
+		t.Errorf("reference = %+v", ref)
+	}
+
+	emit = &captureEmitter{ctx: context.Background()}
+	b.CallStreamingTool(context.Background(), "find_references", map[string]interface{}{"symbol": "Run"}, emit)
+	var lines []string
+	for _, d := range emit.data {
+		ref := d.(codesearch.Reference)
+		lines = append(lines, ref.Text)
+	}
+	if len(lines) != 3 {
+		t.Errorf("Run references = %q", lines)
+	}
+}
+
+func TestOpenFileRange(t *testing.T) {
+	_, b := newTree(t)
+
+	result, err := b.CallTool(context.Background(), "open_file_range",
+		map[string]interface{}{"path": "server/server.go", "start_line": 3, "end_line": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result)
+	if !strings.Contains(string(data), `"content":"3\ttype Server struct{ name string }\n"`) ||
+		!strings.Contains(string(data), `"total_lines":13`) {
+		t.Errorf("result = %s", data)
+	}
+
+	for _, path := range []string{"../etc/passwd", "node_modules/dep/index.js", "missing.go"} {
+		if _, err := b.CallTool(context.Background(), "open_file_range",
+			map[string]interface{}{"path": path, "start_line": 1}); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
+
+func TestIncrementalReindex(t *testing.T) {
+	root, b := newTree(t)
+
+	writeFile(t, root, "server/extra.go", "package server\n\nfunc Shutdown() {}\n")
+	os.Remove(filepath.Join(root, "main.go"))
+
+	stats, err := b.Index().Update(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Added != 1 || stats.Removed != 1 || stats.Updated != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if got := findSymbol(t, b, map[string]interface{}{"query": "Shutdown"}); got.Total != 1 {
+		t.Errorf("new symbol not indexed: %+v", got)
+	}
+	if got := findSymbol(t, b, map[string]interface{}{"query": "main"}); got.Total != 0 {
+		t.Errorf("removed file still indexed: %+v", got)
+	}
+}
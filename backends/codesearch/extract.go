@@ -0,0 +1,306 @@
+package codesearch
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Symbol kinds
+const (
+	KindFunc      = "func"
+	KindMethod    = "method"
+	KindType      = "type"
+	KindStruct    = "struct"
+	KindInterface = "interface"
+	KindClass     = "class"
+	KindModule    = "module"
+	KindEnum      = "enum"
+	KindConst     = "const"
+	KindVar       = "var"
+)
+
+// Symbol is a definition found in a source file
+type Symbol struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+
+	// Container is the receiver type of a Go method, the interface
+	// declaring a method, or the class enclosing a Python method
+	Container string `json:"container,omitempty"`
+
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+
+	// Signature is the defining source line, trimmed
+	Signature string `json:"signature"`
+}
+
+// qualifiedName returns Container.Name, or Name without a container
+func (s Symbol) qualifiedName() string {
+	if s.Container == "" {
+		return s.Name
+	}
+	return s.Container + "." + s.Name
+}
+
+// extractor finds the symbols of one file
+type extractor func(file string, src []byte) []Symbol
+
+// extractorFor returns the extractor for a file, or nil for unsupported
+// languages
+func extractorFor(path string) extractor {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".go" {
+		return extractGo
+	}
+	if lang, ok := languages[ext]; ok {
+		return lang.extract
+	}
+	return nil
+}
+
+// ============================================================
+// Go
+// ============================================================
+
+// extractGo parses Go source; files with syntax errors yield the
+// declarations parsed before the error
+func extractGo(file string, src []byte) []Symbol {
+	fset := token.NewFileSet()
+	f, _ := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+	if f == nil {
+		return nil
+	}
+
+	lines := strings.Split(string(src), "\n")
+	var symbols []Symbol
+	add := func(name *ast.Ident, kind, container string) {
+		if name == nil || name.Name == "_" {
+			return
+		}
+		pos := fset.Position(name.Pos())
+		symbols = append(symbols, Symbol{
+			Name:      name.Name,
+			Kind:      kind,
+			Container: container,
+			File:      file,
+			Line:      pos.Line,
+			Column:    pos.Column,
+			Signature: sourceLine(lines, pos.Line),
+		})
+	}
+
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				add(d.Name, KindMethod, receiverName(d.Recv.List[0].Type))
+			} else {
+				add(d.Name, KindFunc, "")
+			}
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					switch t := s.Type.(type) {
+					case *ast.StructType:
+						add(s.Name, KindStruct, "")
+					case *ast.InterfaceType:
+						add(s.Name, KindInterface, "")
+						for _, m := range t.Methods.List {
+							if _, ok := m.Type.(*ast.FuncType); ok {
+								for _, name := range m.Names {
+									add(name, KindMethod, s.Name.Name)
+								}
+							}
+						}
+					default:
+						add(s.Name, KindType, "")
+					}
+				case *ast.ValueSpec:
+					kind := KindVar
+					if d.Tok == token.CONST {
+						kind = KindConst
+					}
+					for _, name := range s.Names {
+						add(name, kind, "")
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverName returns the type name of a method receiver (T for *T, T[K])
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// ============================================================
+// Other languages
+// ============================================================
+
+// pattern matches a definition; the "name" group is the symbol name and
+// the optional "indent" group its indentation
+type pattern struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// language extracts symbols line by line
+type language struct {
+	patterns []pattern
+
+	// indentScoped languages (Python) nest methods in the enclosing class
+	// by indentation
+	indentScoped bool
+}
+
+func p(kind, expr string) pattern {
+	return pattern{kind: kind, re: regexp.MustCompile(expr)}
+}
+
+var (
+	python = language{
+		indentScoped: true,
+		patterns: []pattern{
+			p(KindClass, `^(?P<indent>\s*)class\s+(?P<name>[A-Za-z_]\w*)`),
+			p(KindFunc, `^(?P<indent>\s*)(?:async\s+)?def\s+(?P<name>[A-Za-z_]\w*)`),
+		},
+	}
+
+	javascript = language{
+		patterns: []pattern{
+			p(KindFunc, `^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+(?P<name>[A-Za-z_$][\w$]*)`),
+			p(KindClass, `^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(?P<name>[A-Za-z_$][\w$]*)`),
+			p(KindInterface, `^\s*(?:export\s+)?interface\s+(?P<name>[A-Za-z_$][\w$]*)`),
+			p(KindEnum, `^\s*(?:export\s+)?(?:const\s+)?enum\s+(?P<name>[A-Za-z_$][\w$]*)`),
+			p(KindType, `^\s*(?:export\s+)?type\s+(?P<name>[A-Za-z_$][\w$]*)\s*(?:<[^=]*>)?\s*=`),
+			p(KindFunc, `^\s*(?:export\s+)?(?:const|let|var)\s+(?P<name>[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function|\([^)]*\)\s*(?::[^=]+)?=>|[A-Za-z_$][\w$]*\s*=>)`),
+		},
+	}
+
+	rust = language{
+		patterns: []pattern{
+			p(KindFunc, `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+(?P<name>\w+)`),
+			p(KindStruct, `^\s*(?:pub(?:\([^)]*\))?\s+)?struct\s+(?P<name>\w+)`),
+			p(KindEnum, `^\s*(?:pub(?:\([^)]*\))?\s+)?enum\s+(?P<name>\w+)`),
+			p(KindInterface, `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:unsafe\s+)?trait\s+(?P<name>\w+)`),
+			p(KindType, `^\s*(?:pub(?:\([^)]*\))?\s+)?type\s+(?P<name>\w+)`),
+			p(KindConst, `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const|static)\s+(?:mut\s+)?(?P<name>\w+)\s*:`),
+			p(KindModule, `^\s*(?:pub(?:\([^)]*\))?\s+)?mod\s+(?P<name>\w+)`),
+		},
+	}
+
+	java = language{
+		patterns: []pattern{
+			p(KindClass, `^\s*(?:(?:public|protected|private|static|final|abstract|sealed)\s+)*(?:class|record)\s+(?P<name>\w+)`),
+			p(KindInterface, `^\s*(?:(?:public|protected|private|static|sealed)\s+)*(?:interface|@interface)\s+(?P<name>\w+)`),
+			p(KindEnum, `^\s*(?:(?:public|protected|private|static)\s+)*enum\s+(?P<name>\w+)`),
+			p(KindMethod, `^\s*(?:(?:public|protected|private|static|final|abstract|synchronized|native|default)\s+)+(?:<[^>]+>\s+)?[\w<>\[\],.?\s]+?\s+(?P<name>\w+)\s*\(`),
+		},
+	}
+
+	ruby = language{
+		patterns: []pattern{
+			p(KindClass, `^\s*class\s+(?P<name>[A-Z]\w*)`),
+			p(KindModule, `^\s*module\s+(?P<name>[A-Z]\w*)`),
+			p(KindFunc, `^\s*def\s+(?:self\.)?(?P<name>[A-Za-z_]\w*[?!=]?)`),
+		},
+	}
+)
+
+// languages maps file extensions to their extraction rules
+var languages = map[string]language{
+	".py":   python,
+	".js":   javascript,
+	".jsx":  javascript,
+	".mjs":  javascript,
+	".cjs":  javascript,
+	".ts":   javascript,
+	".tsx":  javascript,
+	".rs":   rust,
+	".java": java,
+	".rb":   ruby,
+}
+
+// scope is an enclosing class of an indent-scoped language
+type scope struct {
+	name   string
+	indent int
+}
+
+// extract finds symbols with the first matching pattern on each line
+func (l language) extract(file string, src []byte) []Symbol {
+	lines := strings.Split(string(src), "\n")
+	var symbols []Symbol
+	var scopes []scope
+
+	for i, line := range lines {
+		for _, pat := range l.patterns {
+			m := pat.re.FindStringSubmatchIndex(line)
+			if m == nil {
+				continue
+			}
+			nameIdx := pat.re.SubexpIndex("name")
+			name := line[m[2*nameIdx]:m[2*nameIdx+1]]
+
+			sym := Symbol{
+				Name:      name,
+				Kind:      pat.kind,
+				File:      file,
+				Line:      i + 1,
+				Column:    m[2*nameIdx] + 1,
+				Signature: strings.TrimSpace(line),
+			}
+
+			if l.indentScoped {
+				indent := 0
+				if idx := pat.re.SubexpIndex("indent"); idx >= 0 {
+					indent = len(line[m[2*idx]:m[2*idx+1]])
+				}
+				for len(scopes) > 0 && scopes[len(scopes)-1].indent >= indent {
+					scopes = scopes[:len(scopes)-1]
+				}
+				if len(scopes) > 0 {
+					sym.Container = scopes[len(scopes)-1].name
+					if sym.Kind == KindFunc {
+						sym.Kind = KindMethod
+					}
+				}
+				if sym.Kind == KindClass {
+					scopes = append(scopes, scope{name: name, indent: indent})
+				}
+			}
+
+			symbols = append(symbols, sym)
+			break
+		}
+	}
+	return symbols
+}
+
+// sourceLine returns line n (1-based), trimmed
+func sourceLine(lines []string, n int) string {
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[n-1])
+}
@@ -0,0 +1,290 @@
+package codesearch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// Index holds the symbols of a source tree
+// Update rescans the tree and reparses only files whose size or
+// modification time changed, so periodic updates are cheap.
+type Index struct {
+	root        string
+	exclude     map[string]bool
+	maxFileSize int64
+
+	// updateMu serializes updates
+	updateMu sync.Mutex
+
+	mu         sync.RWMutex
+	files      map[string]*fileEntry
+	lastUpdate time.Time
+	lastErr    error
+}
+
+// fileEntry is an indexed file
+type fileEntry struct {
+	modTime time.Time
+	size    int64
+	symbols []Symbol
+}
+
+// UpdateStats reports the changes an Update applied
+type UpdateStats struct {
+	Added    int           `json:"added"`
+	Updated  int           `json:"updated"`
+	Removed  int           `json:"removed"`
+	Files    int           `json:"files"`
+	Symbols  int           `json:"symbols"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Changed reports whether the update changed the index
+func (s UpdateStats) Changed() bool {
+	return s.Added+s.Updated+s.Removed > 0
+}
+
+// NewIndex creates an empty index of root, skipping directories named in
+// exclude and files larger than maxFileSize bytes
+func NewIndex(root string, exclude []string, maxFileSize int64) *Index {
+	ix := &Index{
+		root:        root,
+		exclude:     make(map[string]bool, len(exclude)),
+		maxFileSize: maxFileSize,
+		files:       make(map[string]*fileEntry),
+	}
+	for _, dir := range exclude {
+		ix.exclude[dir] = true
+	}
+	return ix
+}
+
+// Update rescans the tree
+func (ix *Index) Update(ctx context.Context) (UpdateStats, error) {
+	ix.updateMu.Lock()
+	defer ix.updateMu.Unlock()
+
+	start := time.Now()
+	var stats UpdateStats
+
+	ix.mu.RLock()
+	previous := make(map[string]*fileEntry, len(ix.files))
+	for path, entry := range ix.files {
+		previous[path] = entry
+	}
+	ix.mu.RUnlock()
+
+	current := make(map[string]*fileEntry, len(previous))
+	err := filepath.WalkDir(ix.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable entries are skipped; a missing root fails the update
+			if path == ix.root {
+				return err
+			}
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if d.IsDir() {
+			if path != ix.root && ix.exclude[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		extract := extractorFor(path)
+		if extract == nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || (ix.maxFileSize > 0 && info.Size() > ix.maxFileSize) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(ix.root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if entry, ok := previous[rel]; ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+			current[rel] = entry
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if _, ok := previous[rel]; ok {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+		current[rel] = &fileEntry{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+			symbols: extract(rel, src),
+		}
+		return nil
+	})
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.lastErr = err
+	if err != nil {
+		return stats, err
+	}
+
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			stats.Removed++
+		}
+	}
+	ix.files = current
+	ix.lastUpdate = time.Now()
+
+	stats.Files = len(current)
+	for _, entry := range current {
+		stats.Symbols += len(entry.symbols)
+	}
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// Find returns the symbols matching query, best matches first
+//
+// Exact names rank above case-insensitive ones, then prefixes, then
+// substrings. A qualified query ("Server.Run") matches the container as
+// well. kind, if set, restricts the symbol kind. It also returns the total
+// number of matches before limit is applied.
+func (ix *Index) Find(query, kind string, limit int) ([]Symbol, int) {
+	container := ""
+	if i := strings.LastIndex(query, "."); i > 0 && i < len(query)-1 {
+		container, query = query[:i], query[i+1:]
+	}
+	lowerQuery := strings.ToLower(query)
+
+	type match struct {
+		symbol Symbol
+		rank   int
+	}
+	var matches []match
+
+	ix.mu.RLock()
+	for _, entry := range ix.files {
+		for _, sym := range entry.symbols {
+			if kind != "" && sym.Kind != kind {
+				continue
+			}
+			if container != "" && !strings.EqualFold(sym.Container, container) {
+				continue
+			}
+
+			rank := -1
+			lowerName := strings.ToLower(sym.Name)
+			switch {
+			case sym.Name == query:
+				rank = 0
+			case lowerName == lowerQuery:
+				rank = 1
+			case strings.HasPrefix(lowerName, lowerQuery):
+				rank = 2
+			case strings.Contains(lowerName, lowerQuery):
+				rank = 3
+			}
+			if rank >= 0 {
+				matches = append(matches, match{sym, rank})
+			}
+		}
+	}
+	ix.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.symbol.Name != b.symbol.Name {
+			return a.symbol.Name < b.symbol.Name
+		}
+		if a.symbol.File != b.symbol.File {
+			return a.symbol.File < b.symbol.File
+		}
+		return a.symbol.Line < b.symbol.Line
+	})
+
+	total := len(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	symbols := make([]Symbol, len(matches))
+	for i, m := range matches {
+		symbols[i] = m.symbol
+	}
+	return symbols, total
+}
+
+// Files returns the indexed files, root-relative with forward slashes,
+// sorted
+func (ix *Index) Files() []string {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	files := make([]string, 0, len(ix.files))
+	for path := range ix.files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// definitions returns the positions ("file:line") where name is defined
+func (ix *Index) definitions(name string) map[string]bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	defs := make(map[string]bool)
+	for _, entry := range ix.files {
+		for _, sym := range entry.symbols {
+			if sym.Name == name {
+				defs[position(sym.File, sym.Line)] = true
+			}
+		}
+	}
+	return defs
+}
+
+// excludePolicy denies paths inside excluded directories at any depth
+func (ix *Index) excludePolicy() security.Policy {
+	return security.PolicyFunc(func(ctx context.Context, req security.Request) error {
+		for _, part := range strings.Split(filepath.ToSlash(filepath.Clean(req.Path)), "/") {
+			if ix.exclude[part] {
+				return mcperr.PermissionDenied("%s is excluded from the index", req.Path).WithDetail("policy", "exclude")
+			}
+		}
+		return nil
+	})
+}
+
+// Status returns the time of the last successful update and the error of
+// the last attempt
+func (ix *Index) Status() (time.Time, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.lastUpdate, ix.lastErr
+}
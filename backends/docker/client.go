@@ -0,0 +1,218 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// DefaultHost is the daemon's socket on Linux
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// maxResponseSize bounds non-streaming API responses
+const maxResponseSize = 64 << 20
+
+// Client makes requests to the Docker Engine API
+type Client struct {
+	http *http.Client
+	base string // URL prefix of every request
+	host string
+}
+
+// NewClient creates a client for a daemon address: a unix:// socket, a
+// plain socket path, or tcp://host:port (unencrypted, e.g. a socket proxy)
+// An empty host means $DOCKER_HOST, then DefaultHost.
+func NewClient(host string) (*Client, error) {
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	if host == "" {
+		host = DefaultHost
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	c := &Client{
+		// No client timeout: log streams last as long as their context
+		http: &http.Client{Transport: transport},
+		host: host,
+	}
+	switch {
+	case strings.HasPrefix(host, "tcp://"), strings.HasPrefix(host, "http://"):
+		u, err := url.Parse(host)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("docker: invalid host %q", host)
+		}
+		c.base = "http://" + u.Host
+	case strings.HasPrefix(host, "unix://"), strings.HasPrefix(host, "/"):
+		socket := strings.TrimPrefix(host, "unix://")
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		// The host part is ignored when dialing a socket
+		c.base = "http://docker"
+	default:
+		return nil, fmt.Errorf("docker: unsupported host %q; use unix:// or tcp://", host)
+	}
+	return c, nil
+}
+
+// Host returns the daemon address the client talks to
+func (c *Client) Host() string {
+	return c.host
+}
+
+// CheckSocket reports whether the daemon socket can be used, with an
+// error saying how to fix it when not
+// A missing socket is not_found and an inaccessible one
+// permission_denied. TCP hosts are not checked.
+func (c *Client) CheckSocket() error {
+	socket, ok := c.socketPath()
+	if !ok {
+		return nil
+	}
+	info, err := os.Stat(socket)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return mcperr.NotFound("docker: no socket at %s; is the daemon running?", socket)
+	case err != nil:
+		return socketError(socket, err)
+	case info.Mode()&fs.ModeSocket == 0:
+		return fmt.Errorf("docker: %s is not a socket", socket)
+	}
+	conn, err := net.DialTimeout("unix", socket, 2*time.Second)
+	if err != nil {
+		return socketError(socket, err)
+	}
+	return conn.Close()
+}
+
+// socketPath returns the path of a unix socket host
+func (c *Client) socketPath() (string, bool) {
+	if strings.HasPrefix(c.host, "unix://") {
+		return strings.TrimPrefix(c.host, "unix://"), true
+	}
+	return c.host, strings.HasPrefix(c.host, "/")
+}
+
+// socketError explains a failure to reach the socket
+func socketError(socket string, err error) error {
+	if errors.Is(err, fs.ErrPermission) {
+		return mcperr.PermissionDenied("docker: no access to %s; add the server's user to the docker group", socket)
+	}
+	return mcperr.Upstream(err, "docker: connect to %s", socket)
+}
+
+// request sends a request and returns the response if its status is 2xx
+// The caller closes the body.
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	u := c.base + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if socket, ok := c.socketPath(); ok {
+			return nil, socketError(socket, err)
+		}
+		return nil, mcperr.Upstream(err, "docker API %s", path)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, statusError(resp, data)
+	}
+	return resp, nil
+}
+
+// get decodes the JSON response of a GET into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// do sends a request and decodes its JSON response into out, if non-nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	resp, err := c.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return mcperr.Upstream(err, "docker API %s", path)
+	}
+	if len(data) > maxResponseSize {
+		return mcperr.New(mcperr.CodeUpstream, "docker API %s: response exceeds %d bytes", path, maxResponseSize)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return mcperr.Upstream(err, "decode %s", path)
+	}
+	return nil
+}
+
+// stream sends a request and returns its body, for reading as it arrives
+func (c *Client) stream(ctx context.Context, method, path string, query url.Values, body interface{}) (io.ReadCloser, error) {
+	resp, err := c.request(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// statusError maps a failed response to a categorized error
+func statusError(resp *http.Response, data []byte) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &body) != nil || body.Message == "" {
+		body.Message = strings.TrimSpace(string(data))
+		if body.Message == "" {
+			body.Message = resp.Status
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// Authorization plugins deny with 403
+		return mcperr.PermissionDenied("docker: %s", body.Message)
+	case http.StatusNotFound:
+		return mcperr.NotFound("docker: %s", body.Message)
+	case http.StatusBadRequest, http.StatusConflict:
+		return mcperr.New(mcperr.CodeUpstream, "docker: %s", body.Message)
+	}
+	return mcperr.Upstream(fmt.Errorf("%s: %s", resp.Status, body.Message), "docker API request failed")
+}
@@ -0,0 +1,229 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLogLine bounds one log line; a longer line ends the stream with an error
+const maxLogLine = 1 << 20
+
+// ============================================================
+// Containers
+// ============================================================
+
+// apiContainer is an entry of GET /containers/json
+type apiContainer struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	Command string            `json:"Command"`
+	Created int64             `json:"Created"`
+	State   string            `json:"State"`
+	Status  string            `json:"Status"`
+	Labels  map[string]string `json:"Labels"`
+	Ports   []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+}
+
+// ContainerSummary is one row of list_containers, like docker ps
+type ContainerSummary struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Image   string            `json:"image"`
+	Command string            `json:"command"`
+	Created time.Time         `json:"created"`
+	State   string            `json:"state"`
+	Status  string            `json:"status"`
+	Ports   []string          `json:"ports,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// summarize converts a list entry
+func (c apiContainer) summarize() ContainerSummary {
+	s := ContainerSummary{
+		ID:      shortID(c.ID),
+		Image:   c.Image,
+		Command: c.Command,
+		Created: time.Unix(c.Created, 0).UTC(),
+		State:   c.State,
+		Status:  c.Status,
+		Labels:  c.Labels,
+	}
+	if len(c.Names) > 0 {
+		s.Name = strings.TrimPrefix(c.Names[0], "/")
+	}
+	for _, p := range c.Ports {
+		port := strconv.Itoa(p.PrivatePort) + "/" + p.Type
+		if p.PublicPort != 0 {
+			port = p.IP + ":" + strconv.Itoa(p.PublicPort) + "->" + port
+		}
+		s.Ports = append(s.Ports, port)
+	}
+	sort.Strings(s.Ports)
+	return s
+}
+
+// shortID abbreviates a container ID the way docker ps does
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// containerInfo is the part of GET /containers/{id}/json this package reads
+type containerInfo struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Tty bool `json:"Tty"`
+	} `json:"Config"`
+	State struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+}
+
+// name returns the container's name without the leading slash
+func (c containerInfo) name() string {
+	return strings.TrimPrefix(c.Name, "/")
+}
+
+// redactEnv replaces the values of an inspected container's environment,
+// which commonly holds credentials, keeping the variable names
+func redactEnv(object map[string]interface{}) {
+	config, ok := object["Config"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	env, ok := config["Env"].([]interface{})
+	if !ok {
+		return
+	}
+	for i, v := range env {
+		s, _ := v.(string)
+		if name, _, ok := strings.Cut(s, "="); ok {
+			env[i] = name + "=<redacted>"
+		}
+	}
+}
+
+// ============================================================
+// Streams
+// ============================================================
+
+// Stream names of multiplexed output
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// readFrames reads the daemon's multiplexed output of a container without
+// a TTY, calling fn with each frame
+// Each frame has an 8-byte header: the stream (0 stdin, 1 stdout,
+// 2 stderr), three zero bytes and the payload size, big-endian.
+func readFrames(r io.Reader, fn func(stream string, data []byte) error) error {
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		stream := StreamStdout
+		switch header[0] {
+		case 0, 1:
+		case 2:
+			stream = StreamStderr
+		default:
+			return fmt.Errorf("invalid stream type %d in output", header[0])
+		}
+		size := binary.BigEndian.Uint32(header[4:])
+		if size > maxLogLine {
+			return fmt.Errorf("output frame of %d bytes exceeds %d", size, maxLogLine)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return err
+		}
+		if err := fn(stream, data); err != nil {
+			return err
+		}
+	}
+}
+
+// readLines reads a container's output line by line, calling fn with
+// each line and the stream it came from
+// With a TTY, the output is raw and all of it is stdout.
+func readLines(r io.Reader, tty bool, fn func(stream, line string) error) error {
+	if tty {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64<<10), maxLogLine)
+		for scanner.Scan() {
+			if err := fn(StreamStdout, strings.TrimSuffix(scanner.Text(), "\r")); err != nil {
+				return err
+			}
+		}
+		return scanner.Err()
+	}
+
+	// Frames follow the container's writes, so lines may span frames
+	partial := map[string][]byte{}
+	err := readFrames(r, func(stream string, data []byte) error {
+		buf := append(partial[stream], data...)
+		for {
+			i := bytes.IndexByte(buf, '\n')
+			if i < 0 {
+				break
+			}
+			if err := fn(stream, string(bytes.TrimSuffix(buf[:i], []byte("\r")))); err != nil {
+				return err
+			}
+			buf = buf[i+1:]
+		}
+		if len(buf) > maxLogLine {
+			return fmt.Errorf("log line exceeds %d bytes", maxLogLine)
+		}
+		partial[stream] = append([]byte(nil), buf...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, stream := range []string{StreamStdout, StreamStderr} {
+		if len(partial[stream]) > 0 {
+			if err := fn(stream, string(partial[stream])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first max bytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); len(p) > room {
+		b.truncated = true
+		b.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
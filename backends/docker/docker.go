@@ -0,0 +1,561 @@
+// Package docker is a container runtime backend for development agents
+//
+// Read tools are always served:
+//
+//	list_containers  containers with state, image and ports, like docker ps
+//	container_logs   a container's output, streamed, optionally followed
+//	inspect          a container's full configuration and state
+//
+// The backend is read-only unless read_only is false, which adds exec
+// and restart. Every call of those runs through the backend's policies
+// first: exec is limited to allowed_commands (and refused without
+// them), both to containers matching allowed_containers, and Use adds
+// further checks such as an approval step. Environment values are
+// redacted from inspect unless reveal_env is set.
+//
+// The daemon is reached over its local socket, which is checked at
+// Initialize so that a missing daemon or a user outside the docker group
+// fails with an explanation. Importing the package registers the
+// backend as "docker":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/docker"
+//
+//	# config.yaml
+//	backend:
+//	  type: docker
+//	  config:
+//	    read_only: false
+//	    allowed_commands: [ls, cat, env, ps]
+//	    allowed_containers: ["dev-*"]
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultFollowTimeout = 10 * time.Minute
+	DefaultExecTimeout   = time.Minute
+	DefaultMaxOutput     = 1 << 20
+	DefaultMaxContainers = 500
+	DefaultTailLines     = 500
+)
+
+func init() {
+	backend.Register("docker", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves the containers of one Docker daemon
+type Backend struct {
+	*backend.BaseBackend
+
+	logger   *slog.Logger
+	client   *Client
+	policies []security.Policy
+
+	readOnly      bool
+	revealEnv     bool
+	followTimeout time.Duration
+	execTimeout   time.Duration
+	maxOutput     int
+	maxContainers int
+}
+
+// New creates a docker backend; Initialize connects it
+func New() *Backend {
+	b := &Backend{
+		BaseBackend:   backend.NewBaseBackend("docker"),
+		logger:        slog.Default(),
+		readOnly:      true,
+		followTimeout: DefaultFollowTimeout,
+		execTimeout:   DefaultExecTimeout,
+		maxOutput:     DefaultMaxOutput,
+		maxContainers: DefaultMaxContainers,
+	}
+	b.registerTools()
+	return b
+}
+
+// NewWithClient creates a docker backend over an existing client;
+// the config's host entry is then ignored
+func NewWithClient(client *Client) *Backend {
+	b := New()
+	b.client = client
+	return b
+}
+
+// Use adds policies that exec and restart calls must pass
+// Requests carry the operation (security.OpExecute or OpWrite), the
+// container's name as Resource and, for exec, the command line.
+func (b *Backend) Use(policies ...security.Policy) {
+	b.policies = append(b.policies, policies...)
+}
+
+// Initialize connects to the daemon
+//
+// Config entries:
+//
+//	host                daemon address (default $DOCKER_HOST or
+//	                    unix:///var/run/docker.sock)
+//	read_only           serve only read tools (default true)
+//	reveal_env          show environment values in inspect (default false)
+//	follow_timeout      longest followed log stream (default "10m")
+//	exec_timeout        longest exec call (default "1m")
+//	max_output          bytes of each exec output stream kept (default 1MB)
+//	max_containers      most containers one list returns (default 500)
+//	allowed_commands    programs exec may run; none disables exec
+//	allowed_containers  name patterns exec and restart may touch
+//	                    (default: any)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if v, ok := config["read_only"].(bool); ok {
+		b.readOnly = v
+	}
+	if v, ok := config["reveal_env"].(bool); ok {
+		b.revealEnv = v
+	}
+	for key, target := range map[string]*time.Duration{
+		"follow_timeout": &b.followTimeout,
+		"exec_timeout":   &b.execTimeout,
+	} {
+		if s := backend.StringConfig(config, key, ""); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("docker: invalid %s %q", key, s)
+			}
+			*target = d
+		}
+	}
+	if b.maxOutput = backend.IntConfig(config, "max_output", DefaultMaxOutput); b.maxOutput <= 0 {
+		return errors.New("docker: max_output must be positive")
+	}
+	if b.maxContainers = backend.IntConfig(config, "max_containers", DefaultMaxContainers); b.maxContainers <= 0 {
+		return errors.New("docker: max_containers must be positive")
+	}
+
+	patterns := backend.StringsConfig(config, "allowed_containers")
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("docker: invalid allowed_containers pattern %q", p)
+		}
+	}
+
+	if b.client == nil {
+		client, err := NewClient(backend.StringConfig(config, "host", ""))
+		if err != nil {
+			return err
+		}
+		if err := client.CheckSocket(); err != nil {
+			return err
+		}
+		b.client = client
+	}
+	b.client.http = b.HTTPClient(b.client.http)
+
+	if !b.readOnly {
+		b.Use(security.Commands(backend.StringsConfig(config, "allowed_commands")...))
+		if len(patterns) > 0 {
+			b.Use(allowedContainers(patterns))
+		}
+		b.registerWriteTools()
+	}
+	b.logger.Info("docker backend initialized",
+		"host", b.client.Host(),
+		"read_only", b.readOnly)
+	return nil
+}
+
+// allowedContainers denies requests on containers matching no pattern
+func allowedContainers(patterns []string) security.Policy {
+	return security.PolicyFunc(func(ctx context.Context, req security.Request) error {
+		for _, p := range patterns {
+			if ok, _ := path.Match(p, req.Resource); ok {
+				return nil
+			}
+		}
+		return mcperr.PermissionDenied("container %s is not in allowed_containers", req.Resource).
+			WithDetail("policy", "allowed_containers")
+	})
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Readiness pings the daemon.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	r.RegisterReadiness("docker:daemon", func(ctx context.Context) error {
+		if b.client == nil {
+			return backend.ErrNotInitialized
+		}
+		return b.client.get(ctx, "/_ping", nil, nil)
+	})
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type listArgs struct {
+	All    bool     `json:"all,omitempty" description:"Include stopped containers"`
+	Name   string   `json:"name,omitempty" description:"Only containers whose name contains this"`
+	Labels []string `json:"labels,omitempty" description:"Only containers with these labels, as key or key=value"`
+	Status string   `json:"status,omitempty" jsonschema:"enum=created|restarting|running|removing|paused|exited|dead" description:"Only containers in this state"`
+	Limit  int      `json:"limit,omitempty" jsonschema:"minimum=1,default=100" description:"Most containers to return, newest first"`
+}
+
+type listResult struct {
+	Containers []ContainerSummary `json:"containers"`
+	Truncated  bool               `json:"truncated,omitempty"`
+}
+
+type logsArgs struct {
+	Container  string `json:"container" description:"Container name or ID"`
+	Follow     bool   `json:"follow,omitempty" description:"Keep streaming new lines until cancelled or the follow timeout"`
+	TailLines  int    `json:"tail_lines,omitempty" jsonschema:"minimum=1,default=500" description:"Lines from the end of the log to start with"`
+	Since      int    `json:"since_seconds,omitempty" jsonschema:"minimum=1" description:"Only lines newer than this many seconds"`
+	Stream     string `json:"stream,omitempty" jsonschema:"enum=all|stdout|stderr,default=all" description:"Output stream to read"`
+	Timestamps bool   `json:"timestamps,omitempty" description:"Include each line's timestamp"`
+}
+
+// LogLine is one line of container_logs
+type LogLine struct {
+	Stream string     `json:"stream"`
+	Time   *time.Time `json:"time,omitempty"`
+	Line   string     `json:"line"`
+}
+
+type inspectArgs struct {
+	Container string `json:"container" description:"Container name or ID"`
+}
+
+func (b *Backend) registerTools() {
+	backend.RegisterTypedTool(b, backend.NewTool("list_containers").
+		Description("List containers with state, image and published ports, like docker ps.").
+		ParamsFromStruct(listArgs{}).
+		NonCacheable().
+		Build(), b.listContainers)
+
+	logs := backend.NewTool("container_logs").
+		Description("Stream a container's output lines. With follow, new lines keep streaming until the call is cancelled.").
+		ParamsFromStruct(logsArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(logs, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[logsArgs](logs, args)
+		if err != nil {
+			return err
+		}
+		return b.containerLogs(ctx, in, emit)
+	})
+
+	backend.RegisterTypedTool(b, backend.NewTool("inspect").
+		Description("Get a container's configuration, mounts, networks and state. Environment values are redacted.").
+		ParamsFromStruct(inspectArgs{}).
+		NonCacheable().
+		Build(), b.inspect)
+}
+
+// containerPath returns the API path of a container
+func containerPath(container string) string {
+	return "/containers/" + url.PathEscape(container)
+}
+
+// lookup inspects the container a call names
+func (b *Backend) lookup(ctx context.Context, container string) (containerInfo, error) {
+	var info containerInfo
+	err := b.client.get(ctx, containerPath(container)+"/json", nil, &info)
+	return info, err
+}
+
+// listContainers handles list_containers
+func (b *Backend) listContainers(ctx context.Context, in listArgs) (*listResult, error) {
+	if b.client == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	limit = min(limit, b.maxContainers)
+
+	filters := map[string][]string{}
+	if in.Name != "" {
+		filters["name"] = []string{in.Name}
+	}
+	if len(in.Labels) > 0 {
+		filters["label"] = in.Labels
+	}
+	if in.Status != "" {
+		filters["status"] = []string{in.Status}
+	}
+	query := url.Values{
+		"all":   {strconv.FormatBool(in.All)},
+		"limit": {strconv.Itoa(limit + 1)},
+	}
+	if len(filters) > 0 {
+		data, _ := json.Marshal(filters)
+		query.Set("filters", string(data))
+	}
+
+	var containers []apiContainer
+	if err := b.client.get(ctx, "/containers/json", query, &containers); err != nil {
+		return nil, err
+	}
+	result := &listResult{Containers: []ContainerSummary{}}
+	for _, c := range containers {
+		if len(result.Containers) == limit {
+			result.Truncated = true
+			break
+		}
+		result.Containers = append(result.Containers, c.summarize())
+	}
+	return result, nil
+}
+
+// containerLogs handles container_logs, emitting one LogLine per line
+func (b *Backend) containerLogs(ctx context.Context, in logsArgs, emit backend.StreamingEmitter) error {
+	if b.client == nil {
+		return backend.ErrNotInitialized
+	}
+	// The stream format depends on whether the container has a TTY
+	info, err := b.lookup(ctx, in.Container)
+	if err != nil {
+		return err
+	}
+
+	tail := in.TailLines
+	if tail <= 0 {
+		tail = DefaultTailLines
+	}
+	query := url.Values{
+		"stdout":     {strconv.FormatBool(in.Stream != StreamStderr)},
+		"stderr":     {strconv.FormatBool(in.Stream != StreamStdout)},
+		"tail":       {strconv.Itoa(tail)},
+		"timestamps": {strconv.FormatBool(in.Timestamps)},
+	}
+	if in.Since > 0 {
+		query.Set("since", strconv.FormatInt(time.Now().Add(-time.Duration(in.Since)*time.Second).Unix(), 10))
+	}
+	if in.Follow {
+		query.Set("follow", "true")
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.followTimeout)
+		defer cancel()
+	}
+
+	body, err := b.client.stream(ctx, http.MethodGet, containerPath(info.ID)+"/logs", query, nil)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	lines := 0
+	var emitErr error
+	err = readLines(body, info.Config.Tty, func(stream, text string) error {
+		line := LogLine{Stream: stream, Line: text}
+		if in.Timestamps {
+			if stamp, rest, ok := strings.Cut(text, " "); ok {
+				if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+					line.Time, line.Line = &t, rest
+				}
+			}
+		}
+		lines++
+		emitErr = emit.EmitData(line)
+		return emitErr
+	})
+	// A followed stream ends when its time is up; that is not a failure
+	if in.Follow && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		b.logger.Debug("log follow timed out", "container", info.name(), "lines", lines)
+		return emit.EmitProgress(int64(lines), 0, fmt.Sprintf("follow stopped after %s", b.followTimeout))
+	}
+	if emitErr != nil {
+		return emitErr
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return mcperr.Upstream(err, "read logs of %s", info.name())
+	}
+	return nil
+}
+
+// inspect handles inspect
+func (b *Backend) inspect(ctx context.Context, in inspectArgs) (map[string]interface{}, error) {
+	if b.client == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	var object map[string]interface{}
+	if err := b.client.get(ctx, containerPath(in.Container)+"/json", nil, &object); err != nil {
+		return nil, err
+	}
+	if !b.revealEnv {
+		redactEnv(object)
+	}
+	return object, nil
+}
+
+// ============================================================
+// Mutating tools (read_only: false)
+// ============================================================
+
+type execArgs struct {
+	Container string `json:"container" description:"Container name or ID"`
+	Command   string `json:"command" description:"Command line, run without a shell, e.g. ls -la /app"`
+	Workdir   string `json:"workdir,omitempty" description:"Working directory (default: the container's)"`
+}
+
+// ExecResult is the outcome of exec
+type ExecResult struct {
+	Container string `json:"container"`
+	ExitCode  int    `json:"exit_code"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+type restartArgs struct {
+	Container string `json:"container" description:"Container name or ID"`
+	Timeout   *int   `json:"timeout_seconds,omitempty" jsonschema:"minimum=0" description:"Seconds to wait for a stop before killing (default: the container's)"`
+}
+
+type restartResult struct {
+	ID        string `json:"id"`
+	Container string `json:"container"`
+	Restarted bool   `json:"restarted"`
+}
+
+func (b *Backend) registerWriteTools() {
+	backend.RegisterTypedTool(b, backend.NewTool("exec").
+		Description("Run a command in a running container and return its exit code and output. Only allowed programs may run.").
+		ParamsFromStruct(execArgs{}).
+		NonCacheable().
+		Build(), b.exec)
+
+	backend.RegisterTypedTool(b, backend.NewTool("restart").
+		Description("Restart a container.").
+		ParamsFromStruct(restartArgs{}).
+		NonCacheable().
+		Build(), b.restart)
+}
+
+// approve runs a mutating request through the policies
+func (b *Backend) approve(ctx context.Context, req security.Request) error {
+	for _, p := range b.policies {
+		if err := p.Check(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exec handles exec
+func (b *Backend) exec(ctx context.Context, in execArgs) (*ExecResult, error) {
+	info, err := b.lookup(ctx, in.Container)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.approve(ctx, security.Request{Op: security.OpExecute, Command: in.Command, Resource: info.name()}); err != nil {
+		return nil, err
+	}
+	if !info.State.Running {
+		return nil, mcperr.New(mcperr.CodeUpstream, "docker: container %s is not running", info.name())
+	}
+
+	// The Commands policy rules out shell syntax, so fields are the argv
+	var created struct {
+		ID string `json:"Id"`
+	}
+	config := map[string]interface{}{
+		"Cmd":          strings.Fields(in.Command),
+		"AttachStdout": true,
+		"AttachStderr": true,
+		"WorkingDir":   in.Workdir,
+	}
+	if err := b.client.do(ctx, http.MethodPost, containerPath(info.ID)+"/exec", nil, config, &created); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, b.execTimeout)
+	defer cancel()
+	body, err := b.client.stream(runCtx, http.MethodPost, "/exec/"+url.PathEscape(created.ID)+"/start", nil,
+		map[string]interface{}{"Detach": false, "Tty": false})
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	stdout := &limitedBuffer{max: b.maxOutput}
+	stderr := &limitedBuffer{max: b.maxOutput}
+	err = readFrames(body, func(stream string, data []byte) error {
+		if stream == StreamStderr {
+			stderr.Write(data)
+		} else {
+			stdout.Write(data)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			// Docker cannot stop an exec'd process; it may still be running
+			return nil, mcperr.Timeout("docker: %q in %s did not finish within %s", in.Command, info.name(), b.execTimeout)
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, mcperr.Upstream(err, "read output of exec in %s", info.name())
+	}
+
+	var state struct {
+		ExitCode int `json:"ExitCode"`
+	}
+	if err := b.client.get(ctx, "/exec/"+url.PathEscape(created.ID)+"/json", nil, &state); err != nil {
+		return nil, err
+	}
+	b.logger.Info("command executed", "container", info.name(), "command", in.Command, "exit_code", state.ExitCode)
+	return &ExecResult{
+		Container: info.name(),
+		ExitCode:  state.ExitCode,
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.truncated || stderr.truncated,
+	}, nil
+}
+
+// restart handles restart
+func (b *Backend) restart(ctx context.Context, in restartArgs) (*restartResult, error) {
+	info, err := b.lookup(ctx, in.Container)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.approve(ctx, security.Request{Op: security.OpWrite, Resource: info.name()}); err != nil {
+		return nil, err
+	}
+	var query url.Values
+	if in.Timeout != nil {
+		query = url.Values{"t": {strconv.Itoa(*in.Timeout)}}
+	}
+	if err := b.client.do(ctx, http.MethodPost, containerPath(info.ID)+"/restart", query, nil, nil); err != nil {
+		return nil, err
+	}
+	b.logger.Info("container restarted", "container", info.name())
+	return &restartResult{ID: shortID(info.ID), Container: info.name(), Restarted: true}, nil
+}
@@ -0,0 +1,367 @@
+package docker_test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backends/docker"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+type captureEmitter struct {
+	ctx      context.Context
+	lines    []docker.LogLine
+	progress []string
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.lines = append(e.lines, data.(docker.LogLine))
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error {
+	e.progress = append(e.progress, message)
+	return nil
+}
+func (e *captureEmitter) Context() context.Context { return e.ctx }
+
+// frame encodes output the way the daemon multiplexes it
+func frame(stream byte, data string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+	return append(header, data...)
+}
+
+// fakeDaemon serves containers "web" (running), "tty" (running, with a
+// TTY) and "old" (exited)
+type fakeDaemon struct {
+	listQuery string
+	execCmd   []string
+	restarted string
+	follow    chan struct{} // closed to end a followed log
+}
+
+var containers = map[string]map[string]interface{}{
+	"web": {"Id": "aaaaaaaaaaaa1111", "Name": "/web", "Config": map[string]interface{}{
+		"Tty": false, "Env": []interface{}{"PATH=/bin", "DB_PASSWORD=hunter2"},
+	}, "State": map[string]interface{}{"Running": true}},
+	"tty": {"Id": "bbbbbbbbbbbb2222", "Name": "/tty", "Config": map[string]interface{}{"Tty": true},
+		"State": map[string]interface{}{"Running": true}},
+	"old": {"Id": "cccccccccccc3333", "Name": "/old", "Config": map[string]interface{}{"Tty": false},
+		"State": map[string]interface{}{"Running": false}},
+}
+
+func containerByRef(ref string) (string, map[string]interface{}) {
+	for name, c := range containers {
+		if ref == name || ref == c["Id"] {
+			return name, c
+		}
+	}
+	return "", nil
+}
+
+func (f *fakeDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/_ping":
+		w.Write([]byte("OK"))
+	case r.URL.Path == "/containers/json":
+		f.listQuery = r.URL.RawQuery
+		json.NewEncoder(w).Encode([]interface{}{
+			map[string]interface{}{
+				"Id": "aaaaaaaaaaaa1111", "Names": []string{"/web"}, "Image": "nginx:1", "State": "running",
+				"Status": "Up 2 hours", "Created": 1700000000,
+				"Ports": []interface{}{map[string]interface{}{"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"}},
+			},
+			map[string]interface{}{"Id": "bbbbbbbbbbbb2222", "Names": []string{"/tty"}, "State": "running"},
+		})
+	case parts[0] == "containers" && len(parts) == 3:
+		name, c := containerByRef(parts[1])
+		if c == nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No such container: ` + parts[1] + `"}`))
+			return
+		}
+		switch parts[2] {
+		case "json":
+			json.NewEncoder(w).Encode(c)
+		case "logs":
+			if name == "tty" {
+				w.Write([]byte("raw line\r\nsecond\r\n"))
+				return
+			}
+			w.Write(frame(1, "2024-01-02T03:04:05.000000006Z started\n2024-01-02T03:04:06Z lis"))
+			w.Write(frame(2, "2024-01-02T03:04:07Z warning: slow\n"))
+			w.Write(frame(1, "tening\n"))
+			if r.URL.Query().Get("follow") == "true" {
+				w.(http.Flusher).Flush()
+				select {
+				case <-r.Context().Done():
+				case <-f.follow:
+				}
+			}
+		case "exec":
+			var config struct{ Cmd []string }
+			json.NewDecoder(r.Body).Decode(&config)
+			f.execCmd = config.Cmd
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"Id":"exec1"}`))
+		case "restart":
+			f.restarted = name + "?" + r.URL.RawQuery
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case r.URL.Path == "/exec/exec1/start":
+		w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
+		w.Write(frame(1, "index.html\n"))
+		w.Write(frame(2, "ls: cannot access 'x'\n"))
+	case r.URL.Path == "/exec/exec1/json":
+		w.Write([]byte(`{"ExitCode":2,"Running":false}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newBackend starts a fake daemon on a unix socket and connects a
+// backend to it
+func newBackend(t *testing.T, config map[string]interface{}) (*docker.Backend, *fakeDaemon) {
+	t.Helper()
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	daemon := &fakeDaemon{follow: make(chan struct{})}
+	server := httptest.NewUnstartedServer(daemon)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	config["host"] = "unix://" + socket
+	b := docker.New()
+	if err := b.Initialize(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	return b, daemon
+}
+
+func call(t *testing.T, b *docker.Backend, tool string, args map[string]interface{}) (map[string]interface{}, error) {
+	t.Helper()
+	result, err := b.CallTool(context.Background(), tool, args)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(result)
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	return out, nil
+}
+
+func TestListContainers(t *testing.T) {
+	b, daemon := newBackend(t, nil)
+	out, err := call(t, b, "list_containers", map[string]interface{}{"all": true, "status": "running", "limit": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := out["containers"].([]interface{})
+	if len(list) != 1 || out["truncated"] != true {
+		t.Fatalf("result = %v, want one container and truncated", out)
+	}
+	web := list[0].(map[string]interface{})
+	if web["id"] != "aaaaaaaaaaaa" || web["name"] != "web" {
+		t.Errorf("container = %v", web)
+	}
+	if ports := web["ports"].([]interface{}); ports[0] != "0.0.0.0:8080->80/tcp" {
+		t.Errorf("ports = %v", ports)
+	}
+	if !strings.Contains(daemon.listQuery, "all=true") || !strings.Contains(daemon.listQuery, "running") {
+		t.Errorf("query = %s", daemon.listQuery)
+	}
+}
+
+func TestContainerLogs(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	emit := &captureEmitter{ctx: context.Background()}
+	err := b.CallStreamingTool(context.Background(), "container_logs",
+		map[string]interface{}{"container": "web", "timestamps": true}, emit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A line split across frames is joined; stderr keeps its own lines
+	want := []string{"stdout:started", "stderr:warning: slow", "stdout:listening"}
+	if len(emit.lines) != len(want) {
+		t.Fatalf("lines = %+v", emit.lines)
+	}
+	for i, line := range emit.lines {
+		if got := line.Stream + ":" + line.Line; got != want[i] || line.Time == nil {
+			t.Errorf("line %d = %s (time %v), want %s", i, got, line.Time, want[i])
+		}
+	}
+
+	// With a TTY the output is raw
+	emit = &captureEmitter{ctx: context.Background()}
+	if err := b.CallStreamingTool(context.Background(), "container_logs", map[string]interface{}{"container": "tty"}, emit); err != nil {
+		t.Fatal(err)
+	}
+	if len(emit.lines) != 2 || emit.lines[0].Line != "raw line" {
+		t.Errorf("tty lines = %+v", emit.lines)
+	}
+}
+
+func TestContainerLogs_Follow(t *testing.T) {
+	b, _ := newBackend(t, map[string]interface{}{"follow_timeout": "200ms"})
+	emit := &captureEmitter{ctx: context.Background()}
+	start := time.Now()
+	err := b.CallStreamingTool(context.Background(), "container_logs",
+		map[string]interface{}{"container": "web", "follow": true}, emit)
+	if err != nil {
+		t.Fatalf("followed stream ended with %v, want a normal end", err)
+	}
+	if time.Since(start) < 200*time.Millisecond || len(emit.lines) != 3 {
+		t.Errorf("lines = %d after %s", len(emit.lines), time.Since(start))
+	}
+	if len(emit.progress) != 1 || !strings.Contains(emit.progress[0], "follow stopped") {
+		t.Errorf("progress = %v", emit.progress)
+	}
+}
+
+func TestInspect_RedactsEnv(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	out, err := call(t, b, "inspect", map[string]interface{}{"container": "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := out["Config"].(map[string]interface{})["Env"].([]interface{})
+	if env[1] != "DB_PASSWORD=<redacted>" {
+		t.Errorf("env = %v", env)
+	}
+
+	_, err = call(t, b, "inspect", map[string]interface{}{"container": "missing"})
+	if mcperr.CodeOf(err) != mcperr.CodeNotFound || !strings.Contains(err.Error(), "No such container") {
+		t.Errorf("err = %v, want not_found from the daemon", err)
+	}
+}
+
+func TestReadOnlyDefault(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	for _, tool := range []string{"exec", "restart"} {
+		if _, ok := b.GetTool(tool); ok {
+			t.Errorf("%s registered in read-only mode", tool)
+		}
+	}
+}
+
+func TestExec(t *testing.T) {
+	b, daemon := newBackend(t, map[string]interface{}{
+		"read_only":          false,
+		"allowed_commands":   []interface{}{"ls"},
+		"allowed_containers": []interface{}{"web", "old"},
+	})
+	out, err := call(t, b, "exec", map[string]interface{}{"container": "web", "command": "ls -la /srv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["exit_code"] != 2.0 || out["stdout"] != "index.html\n" || !strings.Contains(out["stderr"].(string), "cannot access") {
+		t.Errorf("result = %v", out)
+	}
+	if strings.Join(daemon.execCmd, " ") != "ls -la /srv" {
+		t.Errorf("cmd = %q", daemon.execCmd)
+	}
+
+	for name, args := range map[string]map[string]interface{}{
+		"command":     {"container": "web", "command": "rm -rf /"},
+		"shell":       {"container": "web", "command": "ls; rm x"},
+		"container":   {"container": "tty", "command": "ls"},
+		"not running": {"container": "old", "command": "ls"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := call(t, b, "exec", args)
+			want := mcperr.CodePermissionDenied
+			if name == "not running" {
+				want = mcperr.CodeUpstream
+			}
+			if mcperr.CodeOf(err) != want {
+				t.Errorf("err = %v, want %s", err, want)
+			}
+		})
+	}
+}
+
+func TestRestart_Approval(t *testing.T) {
+	b, daemon := newBackend(t, map[string]interface{}{"read_only": false})
+	var asked []security.Request
+	b.Use(security.PolicyFunc(func(ctx context.Context, req security.Request) error {
+		asked = append(asked, req)
+		if req.Resource == "tty" {
+			return mcperr.PermissionDenied("restart of %s declined", req.Resource)
+		}
+		return nil
+	}))
+
+	if _, err := call(t, b, "restart", map[string]interface{}{"container": "aaaaaaaaaaaa1111", "timeout_seconds": 5}); err != nil {
+		t.Fatal(err)
+	}
+	if daemon.restarted != "web?t=5" {
+		t.Errorf("restarted = %q", daemon.restarted)
+	}
+	if _, err := call(t, b, "restart", map[string]interface{}{"container": "tty"}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("err = %v, want the policy's denial", err)
+	}
+	if len(asked) != 2 || asked[0].Op != security.OpWrite || asked[0].Resource != "web" {
+		t.Errorf("policy saw %+v", asked)
+	}
+}
+
+func TestArgumentErrors(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	tool, _ := b.GetTool("container_logs")
+	err := backend.ValidateArguments(tool, map[string]interface{}{"container": "web", "stream": "stdin"})
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) || argErr.Fields[0].Field != "stream" {
+		t.Errorf("err = %v, want an error on stream", err)
+	}
+}
+
+func TestSocketChecks(t *testing.T) {
+	b := docker.New()
+	missing := filepath.Join(t.TempDir(), "docker.sock")
+	err := b.Initialize(context.Background(), map[string]interface{}{"host": "unix://" + missing})
+	if mcperr.CodeOf(err) != mcperr.CodeNotFound || !strings.Contains(err.Error(), "daemon running") {
+		t.Errorf("err = %v, want not_found", err)
+	}
+
+	plain := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(plain, nil, 0o600)
+	if err := docker.New().Initialize(context.Background(), map[string]interface{}{"host": plain}); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Errorf("err = %v, want not a socket", err)
+	}
+
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores socket permissions")
+	}
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	defer listener.Close()
+	os.Chmod(socket, 0)
+	err = docker.New().Initialize(context.Background(), map[string]interface{}{"host": "unix://" + socket})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied || !strings.Contains(err.Error(), "docker group") {
+		t.Errorf("err = %v, want permission_denied", err)
+	}
+}
@@ -0,0 +1,217 @@
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// maxResponseSize bounds non-streaming API responses
+const maxResponseSize = 64 << 20
+
+// Client makes authenticated requests to an API server
+type Client struct {
+	config *Config
+	http   *http.Client
+	server string
+}
+
+// NewClient creates a client from a config
+func NewClient(config *Config) (*Client, error) {
+	if config == nil || config.Server == "" {
+		return nil, errors.New("kubernetes: config has no server")
+	}
+	server, err := url.Parse(config.Server)
+	if err != nil || server.Host == "" {
+		return nil, fmt.Errorf("kubernetes: invalid server %q", config.Server)
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.Insecure,
+		ServerName:         config.TLSServerName,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if len(config.CAData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CAData) {
+			return nil, errors.New("kubernetes: no certificates in certificate authority data")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(config.ClientCertData) > 0 {
+		cert, err := tls.X509KeyPair(config.ClientCertData, config.ClientKeyData)
+		if err != nil {
+			return nil, fmt.Errorf("kubernetes: client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &Client{
+		config: config,
+		// No client timeout: log streams last as long as their context
+		http:   &http.Client{Transport: transport},
+		server: strings.TrimSuffix(server.String(), "/"),
+	}, nil
+}
+
+// Namespace returns the config's default namespace
+func (c *Client) Namespace() string {
+	return c.config.Namespace
+}
+
+// request sends a request and returns the response if its status is 2xx
+// The caller closes the body.
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, body interface{}, contentType string) (*http.Response, error) {
+	u := c.server + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if err := c.authenticate(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, mcperr.Upstream(err, "kubernetes API %s", path)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, statusError(resp, data)
+	}
+	return resp, nil
+}
+
+// authenticate adds the config's credentials to a request
+func (c *Client) authenticate(req *http.Request) error {
+	token := c.config.Token
+	if c.config.TokenFile != "" {
+		data, err := os.ReadFile(c.config.TokenFile)
+		if err != nil {
+			return fmt.Errorf("kubernetes: read token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case c.config.Username != "":
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+	return nil
+}
+
+// get decodes the JSON response of a GET into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, "", out)
+}
+
+// do sends a request and decodes its JSON response into out, if non-nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}, contentType string, out interface{}) error {
+	resp, err := c.request(ctx, method, path, query, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return mcperr.Upstream(err, "kubernetes API %s", path)
+	}
+	if len(data) > maxResponseSize {
+		return mcperr.New(mcperr.CodeUpstream, "kubernetes API %s: response exceeds %d bytes", path, maxResponseSize)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return mcperr.Upstream(err, "decode %s", path)
+	}
+	return nil
+}
+
+// stream returns the body of a GET, for reading as it arrives
+func (c *Client) stream(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	resp, err := c.request(ctx, http.MethodGet, path, query, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// apiStatus is the Status object of a failed request
+type apiStatus struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+	Code    int    `json:"code"`
+	Details struct {
+		RetryAfterSeconds int `json:"retryAfterSeconds"`
+	} `json:"details"`
+}
+
+// statusError maps a failed response to a categorized error
+// 403 keeps the server's message, which names the user, verb and
+// resource the RBAC rules deny, so the fix is clear from the error alone.
+func statusError(resp *http.Response, data []byte) error {
+	var status apiStatus
+	if json.Unmarshal(data, &status) != nil || status.Message == "" {
+		status.Message = strings.TrimSpace(string(data))
+		if status.Message == "" {
+			status.Message = resp.Status
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return mcperr.PermissionDenied("kubernetes: credentials rejected: %s", status.Message)
+	case http.StatusForbidden:
+		return mcperr.PermissionDenied("kubernetes RBAC: %s", status.Message)
+	case http.StatusNotFound:
+		return mcperr.NotFound("kubernetes: %s", status.Message)
+	case http.StatusTooManyRequests:
+		retry := time.Duration(status.Details.RetryAfterSeconds) * time.Second
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retry = time.Duration(s) * time.Second
+		}
+		return mcperr.RateLimited(retry, "kubernetes: %s", status.Message)
+	case http.StatusGatewayTimeout:
+		return mcperr.Timeout("kubernetes: %s", status.Message)
+	case http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity:
+		return mcperr.New(mcperr.CodeUpstream, "kubernetes: %s (%s)", status.Message, status.Reason)
+	}
+	return mcperr.Upstream(fmt.Errorf("%s: %s", resp.Status, status.Message), "kubernetes API request failed")
+}
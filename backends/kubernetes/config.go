@@ -0,0 +1,216 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Service account files mounted into pods
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// ErrNotInCluster is returned by InClusterConfig outside a pod
+var ErrNotInCluster = errors.New("kubernetes: not running in a cluster (KUBERNETES_SERVICE_HOST unset)")
+
+// Config is how to reach and authenticate to an API server
+type Config struct {
+	// Server is the API server URL, e.g. https://10.0.0.1:6443
+	Server string
+
+	// Namespace is the default namespace of namespaced calls
+	Namespace string
+
+	// Bearer token; TokenFile is re-read on every request so rotated
+	// service account tokens keep working
+	Token     string
+	TokenFile string
+
+	// Basic auth, for clusters that still allow it
+	Username string
+	Password string
+
+	// PEM client certificate and key
+	ClientCertData []byte
+	ClientKeyData  []byte
+
+	// CAData is the PEM bundle trusted for the server; empty means the
+	// system roots
+	CAData   []byte
+	Insecure bool
+
+	// TLSServerName overrides the name verified in the server certificate
+	TLSServerName string
+}
+
+// kubeconfig is the subset of a kubeconfig file this package reads
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+			TLSServerName            string `yaml:"tls-server-name"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string    `yaml:"token"`
+			TokenFile             string    `yaml:"tokenFile"`
+			Username              string    `yaml:"username"`
+			Password              string    `yaml:"password"`
+			ClientCertificate     string    `yaml:"client-certificate"`
+			ClientCertificateData string    `yaml:"client-certificate-data"`
+			ClientKey             string    `yaml:"client-key"`
+			ClientKeyData         string    `yaml:"client-key-data"`
+			Exec                  yaml.Node `yaml:"exec"`
+			AuthProvider          yaml.Node `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// DefaultKubeconfigPath returns $KUBECONFIG (its first entry) or
+// ~/.kube/config
+func DefaultKubeconfigPath() string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+// LoadKubeconfig reads the cluster and credentials of a kubeconfig
+// context; the empty context is the file's current-context
+// Relative file references resolve against the kubeconfig's directory.
+// Exec and auth-provider credential plugins are not supported.
+func LoadKubeconfig(path, context string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: read kubeconfig: %w", err)
+	}
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("kubernetes: parse kubeconfig %s: %w", path, err)
+	}
+
+	if context == "" {
+		context = kc.CurrentContext
+	}
+	if context == "" {
+		return nil, fmt.Errorf("kubernetes: kubeconfig %s has no current-context", path)
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(file string) string {
+		if file == "" || filepath.IsAbs(file) {
+			return file
+		}
+		return filepath.Join(dir, file)
+	}
+
+	for _, c := range kc.Contexts {
+		if c.Name != context {
+			continue
+		}
+		config := &Config{Namespace: c.Context.Namespace}
+
+		found := false
+		for _, cl := range kc.Clusters {
+			if cl.Name != c.Context.Cluster {
+				continue
+			}
+			found = true
+			config.Server = cl.Cluster.Server
+			config.Insecure = cl.Cluster.InsecureSkipTLSVerify
+			config.TLSServerName = cl.Cluster.TLSServerName
+			if config.CAData, err = fileOrData(resolve(cl.Cluster.CertificateAuthority), cl.Cluster.CertificateAuthorityData); err != nil {
+				return nil, fmt.Errorf("kubernetes: cluster %s: certificate authority: %w", cl.Name, err)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("kubernetes: context %s names unknown cluster %q", context, c.Context.Cluster)
+		}
+		if config.Server == "" {
+			return nil, fmt.Errorf("kubernetes: cluster %s has no server", c.Context.Cluster)
+		}
+
+		for _, u := range kc.Users {
+			if u.Name != c.Context.User {
+				continue
+			}
+			user := u.User
+			if !user.Exec.IsZero() || !user.AuthProvider.IsZero() {
+				if user.Token == "" && user.TokenFile == "" && user.ClientCertificateData == "" && user.ClientCertificate == "" {
+					return nil, fmt.Errorf("kubernetes: user %s uses a credential plugin, which is not supported; use a token or client certificate", u.Name)
+				}
+			}
+			config.Token = user.Token
+			config.TokenFile = resolve(user.TokenFile)
+			config.Username = user.Username
+			config.Password = user.Password
+			if config.ClientCertData, err = fileOrData(resolve(user.ClientCertificate), user.ClientCertificateData); err != nil {
+				return nil, fmt.Errorf("kubernetes: user %s: client certificate: %w", u.Name, err)
+			}
+			if config.ClientKeyData, err = fileOrData(resolve(user.ClientKey), user.ClientKeyData); err != nil {
+				return nil, fmt.Errorf("kubernetes: user %s: client key: %w", u.Name, err)
+			}
+		}
+		return config, nil
+	}
+	return nil, fmt.Errorf("kubernetes: kubeconfig %s has no context %q", path, context)
+}
+
+// fileOrData returns base64 data, or else the contents of file
+func fileOrData(file, data string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	}
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	return nil, nil
+}
+
+// InClusterConfig returns the config of the pod's service account
+func InClusterConfig() (*Config, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, ErrNotInCluster
+	}
+	tokenFile := filepath.Join(serviceAccountDir, "token")
+	if _, err := os.Stat(tokenFile); err != nil {
+		return nil, fmt.Errorf("kubernetes: service account token: %w", err)
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: service account CA: %w", err)
+	}
+	namespace, _ := os.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+
+	return &Config{
+		Server:    "https://" + net.JoinHostPort(host, port),
+		Namespace: strings.TrimSpace(string(namespace)),
+		TokenFile: tokenFile,
+		CAData:    ca,
+	}, nil
+}
@@ -0,0 +1,663 @@
+// Package kubernetes is a cluster introspection backend for SREs
+//
+// Read tools are always served:
+//
+//	list_pods          pods with status, readiness and restarts
+//	get_logs           container logs, streamed, optionally followed
+//	describe_resource  an object with its recent events
+//	top_nodes          node CPU and memory usage from metrics-server
+//
+// The backend is read-only unless read_only is false, which adds
+// delete_pod and scale. What the cluster allows is still up to RBAC:
+// a forbidden request fails with a permission_denied error quoting the
+// API server, which names the user, verb and resource denied.
+// Credentials come from a kubeconfig or, in a pod, the service account.
+// Importing the package registers the backend as "kubernetes":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/kubernetes"
+//
+//	# config.yaml
+//	backend:
+//	  type: kubernetes
+//	  config:
+//	    context: prod-eu
+//	    namespace: payments
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultNamespace     = "default"
+	DefaultFollowTimeout = 10 * time.Minute
+	DefaultMaxPods       = 500
+	DefaultTailLines     = 500
+	DefaultMaxEvents     = 20
+)
+
+// maxLogLine bounds one log line; a longer line ends the stream with an error
+const maxLogLine = 1 << 20
+
+func init() {
+	backend.Register("kubernetes", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves introspection of one cluster
+type Backend struct {
+	*backend.BaseBackend
+
+	logger *slog.Logger
+	client *Client
+
+	namespace     string
+	readOnly      bool
+	revealSecrets bool
+	followTimeout time.Duration
+	maxPods       int
+}
+
+// New creates a kubernetes backend; Initialize connects it
+func New() *Backend {
+	b := &Backend{
+		BaseBackend:   backend.NewBaseBackend("kubernetes"),
+		logger:        slog.Default(),
+		readOnly:      true,
+		followTimeout: DefaultFollowTimeout,
+		maxPods:       DefaultMaxPods,
+	}
+	b.registerTools()
+	return b
+}
+
+// NewWithClient creates a kubernetes backend over an existing client;
+// the config's kubeconfig, context and in_cluster entries are then ignored
+func NewWithClient(client *Client) *Backend {
+	b := New()
+	b.client = client
+	return b
+}
+
+// Initialize connects to the cluster
+//
+// Config entries:
+//
+//	kubeconfig      kubeconfig path (default $KUBECONFIG or ~/.kube/config)
+//	context         kubeconfig context (default its current-context)
+//	in_cluster      use the pod's service account (default: when running
+//	                in a pod and no kubeconfig is set)
+//	namespace       namespace of calls naming none (default: the
+//	                context's, the pod's, or "default")
+//	read_only       serve only read tools (default true)
+//	reveal_secrets  show Secret values in describe_resource (default false)
+//	follow_timeout  longest followed log stream (default "10m")
+//	max_pods        most pods one list_pods call returns (default 500)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if v, ok := config["read_only"].(bool); ok {
+		b.readOnly = v
+	}
+	if v, ok := config["reveal_secrets"].(bool); ok {
+		b.revealSecrets = v
+	}
+	if s := backend.StringConfig(config, "follow_timeout", ""); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("kubernetes: invalid follow_timeout %q", s)
+		}
+		b.followTimeout = d
+	}
+	if b.maxPods = backend.IntConfig(config, "max_pods", DefaultMaxPods); b.maxPods <= 0 {
+		return errors.New("kubernetes: max_pods must be positive")
+	}
+
+	if b.client == nil {
+		clusterConfig, err := loadConfig(config)
+		if err != nil {
+			return err
+		}
+		if b.client, err = NewClient(clusterConfig); err != nil {
+			return err
+		}
+	}
+	b.client.http = b.HTTPClient(b.client.http)
+
+	b.namespace = backend.StringConfig(config, "namespace", b.client.Namespace())
+	if b.namespace == "" {
+		b.namespace = DefaultNamespace
+	}
+
+	if !b.readOnly {
+		b.registerWriteTools()
+	}
+	b.logger.Info("kubernetes backend initialized",
+		"server", b.client.server,
+		"namespace", b.namespace,
+		"read_only", b.readOnly)
+	return nil
+}
+
+// loadConfig finds the cluster config the backend config asks for
+func loadConfig(config map[string]interface{}) (*Config, error) {
+	path := backend.StringConfig(config, "kubeconfig", "")
+	inCluster, set := config["in_cluster"].(bool)
+	if !set {
+		inCluster = path == "" && os.Getenv("KUBERNETES_SERVICE_HOST") != ""
+	}
+	if inCluster {
+		return InClusterConfig()
+	}
+	if path == "" {
+		path = DefaultKubeconfigPath()
+	}
+	return LoadKubeconfig(path, backend.StringConfig(config, "context", ""))
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Readiness asks the API server for its version.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	r.RegisterReadiness("kubernetes:api", func(ctx context.Context) error {
+		if b.client == nil {
+			return backend.ErrNotInitialized
+		}
+		return b.client.get(ctx, "/version", nil, nil)
+	})
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type listPodsArgs struct {
+	Namespace     string `json:"namespace,omitempty" description:"Namespace (default: the configured namespace)"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty" description:"List pods of every namespace"`
+	LabelSelector string `json:"label_selector,omitempty" description:"Label selector, e.g. app=web,tier!=cache"`
+	FieldSelector string `json:"field_selector,omitempty" description:"Field selector, e.g. status.phase=Running or spec.nodeName=node-1"`
+	Limit         int    `json:"limit,omitempty" jsonschema:"minimum=1,default=100" description:"Most pods to return"`
+}
+
+type listPodsResult struct {
+	Pods      []PodSummary `json:"pods"`
+	Truncated bool         `json:"truncated,omitempty"`
+}
+
+type logsArgs struct {
+	Namespace    string `json:"namespace,omitempty" description:"Namespace (default: the configured namespace)"`
+	Pod          string `json:"pod" description:"Pod name"`
+	Container    string `json:"container,omitempty" description:"Container; required for pods with several"`
+	Follow       bool   `json:"follow,omitempty" description:"Keep streaming new lines until cancelled or the follow timeout"`
+	TailLines    int    `json:"tail_lines,omitempty" jsonschema:"minimum=1,default=500" description:"Lines from the end of the log to start with"`
+	SinceSeconds int    `json:"since_seconds,omitempty" jsonschema:"minimum=1" description:"Only lines newer than this many seconds"`
+	Previous     bool   `json:"previous,omitempty" description:"Logs of the previous, crashed container instance"`
+	Timestamps   bool   `json:"timestamps,omitempty" description:"Include each line's timestamp"`
+}
+
+// LogLine is one line of get_logs
+type LogLine struct {
+	Container string     `json:"container"`
+	Time      *time.Time `json:"time,omitempty"`
+	Line      string     `json:"line"`
+}
+
+type describeArgs struct {
+	Kind      string `json:"kind" description:"Resource kind or short name: Pod, Deployment, svc, ..."`
+	Name      string `json:"name" description:"Object name"`
+	Namespace string `json:"namespace,omitempty" description:"Namespace of namespaced kinds (default: the configured namespace)"`
+}
+
+type describeResult struct {
+	Kind   string                 `json:"kind"`
+	Object map[string]interface{} `json:"object"`
+	Events []Event                `json:"events"`
+}
+
+type topNodesArgs struct {
+	SortBy        string `json:"sort_by,omitempty" jsonschema:"enum=cpu|memory|name,default=cpu" description:"Order of the nodes, highest usage first"`
+	LabelSelector string `json:"label_selector,omitempty" description:"Label selector of the nodes"`
+}
+
+// NodeUsage is one row of top_nodes
+type NodeUsage struct {
+	Name          string  `json:"name"`
+	CPUMillicores int64   `json:"cpu_millicores"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryBytes   int64   `json:"memory_bytes"`
+	MemoryPercent float64 `json:"memory_percent"`
+	Ready         bool    `json:"ready"`
+}
+
+type topNodesResult struct {
+	Nodes []NodeUsage `json:"nodes"`
+}
+
+func (b *Backend) registerTools() {
+	backend.RegisterTypedTool(b, backend.NewTool("list_pods").
+		Description("List pods with status, readiness, restarts and node, like kubectl get pods -o wide.").
+		ParamsFromStruct(listPodsArgs{}).
+		NonCacheable().
+		Build(), b.listPods)
+
+	logs := backend.NewTool("get_logs").
+		Description("Stream a container's log lines. With follow, new lines keep streaming until the call is cancelled.").
+		ParamsFromStruct(logsArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(logs, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[logsArgs](logs, args)
+		if err != nil {
+			return err
+		}
+		return b.getLogs(ctx, in, emit)
+	})
+
+	backend.RegisterTypedTool(b, backend.NewTool("describe_resource").
+		Description("Get an object and its recent events. Known kinds: "+strings.Join(kindNames(), ", ")+". Secret values are redacted.").
+		ParamsFromStruct(describeArgs{}).
+		NonCacheable().
+		Build(), b.describeResource)
+
+	backend.RegisterTypedTool(b, backend.NewTool("top_nodes").
+		Description("Show node CPU and memory usage against allocatable capacity. Needs metrics-server.").
+		ParamsFromStruct(topNodesArgs{}).
+		NonCacheable().
+		Build(), b.topNodes)
+}
+
+// namespaceOf returns the namespace a call names, or the default
+func (b *Backend) namespaceOf(name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return b.namespace
+}
+
+// listPods handles list_pods, following result pages up to the limit
+func (b *Backend) listPods(ctx context.Context, in listPodsArgs) (*listPodsResult, error) {
+	if b.client == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	limit = min(limit, b.maxPods)
+
+	path := "/api/v1/namespaces/" + url.PathEscape(b.namespaceOf(in.Namespace)) + "/pods"
+	if in.AllNamespaces {
+		path = "/api/v1/pods"
+	}
+	query := url.Values{}
+	if in.LabelSelector != "" {
+		query.Set("labelSelector", in.LabelSelector)
+	}
+	if in.FieldSelector != "" {
+		query.Set("fieldSelector", in.FieldSelector)
+	}
+
+	now := time.Now()
+	result := &listPodsResult{Pods: []PodSummary{}}
+	for {
+		query.Set("limit", strconv.Itoa(limit-len(result.Pods)))
+		var page struct {
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+			Items []pod `json:"items"`
+		}
+		if err := b.client.get(ctx, path, query, &page); err != nil {
+			return nil, err
+		}
+		for _, p := range page.Items {
+			result.Pods = append(result.Pods, p.summarize(now))
+		}
+		if page.Metadata.Continue == "" {
+			return result, nil
+		}
+		if len(result.Pods) >= limit {
+			result.Truncated = true
+			return result, nil
+		}
+		query.Set("continue", page.Metadata.Continue)
+	}
+}
+
+// getLogs handles get_logs, emitting one LogLine per line
+func (b *Backend) getLogs(ctx context.Context, in logsArgs, emit backend.StreamingEmitter) error {
+	if b.client == nil {
+		return backend.ErrNotInitialized
+	}
+	namespace := b.namespaceOf(in.Namespace)
+	podPath := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods/" + url.PathEscape(in.Pod)
+
+	// Resolve the container first: the API's own error for an ambiguous
+	// pod does not list the choices
+	container := in.Container
+	if container == "" {
+		var p pod
+		if err := b.client.get(ctx, podPath, nil, &p); err != nil {
+			return err
+		}
+		switch len(p.Spec.Containers) {
+		case 0:
+			return mcperr.NotFound("pod %s/%s has no containers", namespace, in.Pod)
+		case 1:
+			container = p.Spec.Containers[0].Name
+		default:
+			names := make([]string, len(p.Spec.Containers))
+			for i, c := range p.Spec.Containers {
+				names[i] = c.Name
+			}
+			return &backend.ArgumentError{Tool: "get_logs", Fields: []backend.FieldError{{
+				Field:   "container",
+				Message: "pod has several containers, choose one of: " + strings.Join(names, ", "),
+			}}}
+		}
+	}
+
+	tail := in.TailLines
+	if tail <= 0 {
+		tail = DefaultTailLines
+	}
+	query := url.Values{
+		"container":  {container},
+		"tailLines":  {strconv.Itoa(tail)},
+		"timestamps": {strconv.FormatBool(in.Timestamps)},
+	}
+	if in.SinceSeconds > 0 {
+		query.Set("sinceSeconds", strconv.Itoa(in.SinceSeconds))
+	}
+	if in.Previous {
+		query.Set("previous", "true")
+	}
+	if in.Follow {
+		query.Set("follow", "true")
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.followTimeout)
+		defer cancel()
+	}
+
+	body, err := b.client.stream(ctx, podPath+"/log", query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64<<10), maxLogLine)
+	lines := 0
+	for scanner.Scan() {
+		line := LogLine{Container: container, Line: scanner.Text()}
+		if in.Timestamps {
+			if stamp, rest, ok := strings.Cut(line.Line, " "); ok {
+				if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+					line.Time, line.Line = &t, rest
+				}
+			}
+		}
+		if err := emit.EmitData(line); err != nil {
+			return err
+		}
+		lines++
+	}
+	err = scanner.Err()
+	// A followed stream ends when its time is up; that is not a failure
+	if in.Follow && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		b.logger.Debug("log follow timed out", "pod", in.Pod, "lines", lines)
+		return emit.EmitProgress(int64(lines), 0, fmt.Sprintf("follow stopped after %s", b.followTimeout))
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return mcperr.Upstream(err, "read logs of %s/%s", namespace, in.Pod)
+	}
+	return nil
+}
+
+// describeResource handles describe_resource
+func (b *Backend) describeResource(ctx context.Context, in describeArgs) (*describeResult, error) {
+	if b.client == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	kind, ok := lookupKind(in.Kind)
+	if !ok {
+		return nil, &backend.ArgumentError{Tool: "describe_resource", Fields: []backend.FieldError{{
+			Field:   "kind",
+			Message: fmt.Sprintf("unknown kind %q; known kinds: %s", in.Kind, strings.Join(kindNames(), ", ")),
+		}}}
+	}
+	namespace := ""
+	if kind.namespaced {
+		namespace = b.namespaceOf(in.Namespace)
+	}
+
+	var object map[string]interface{}
+	if err := b.client.get(ctx, kind.path(url.PathEscape(namespace), url.PathEscape(in.Name)), nil, &object); err != nil {
+		return nil, err
+	}
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+	}
+	if kind.Kind == "Secret" && !b.revealSecrets {
+		redactSecret(object)
+	}
+
+	return &describeResult{
+		Kind:   kind.Kind,
+		Object: object,
+		Events: b.events(ctx, kind.Kind, namespace, in.Name),
+	}, nil
+}
+
+// events returns the newest events about an object
+// Events are best effort: RBAC often allows an object but not events.
+func (b *Backend) events(ctx context.Context, kind, namespace, name string) []Event {
+	path := "/api/v1/events"
+	if namespace != "" {
+		path = "/api/v1/namespaces/" + url.PathEscape(namespace) + "/events"
+	}
+	query := url.Values{"fieldSelector": {"involvedObject.kind=" + kind + ",involvedObject.name=" + name}}
+
+	var list struct {
+		Items []apiEvent `json:"items"`
+	}
+	events := []Event{}
+	if err := b.client.get(ctx, path, query, &list); err != nil {
+		b.logger.Debug("events unavailable", "kind", kind, "name", name, "error", err)
+		return events
+	}
+	for _, e := range list.Items {
+		events = append(events, e.event())
+	}
+	sortEvents(events)
+	if len(events) > DefaultMaxEvents {
+		events = events[:DefaultMaxEvents]
+	}
+	return events
+}
+
+// topNodes handles top_nodes
+func (b *Backend) topNodes(ctx context.Context, in topNodesArgs) (*topNodesResult, error) {
+	if b.client == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	query := url.Values{}
+	if in.LabelSelector != "" {
+		query.Set("labelSelector", in.LabelSelector)
+	}
+
+	var metrics struct {
+		Items []struct {
+			Metadata objectMeta        `json:"metadata"`
+			Usage    map[string]string `json:"usage"`
+		} `json:"items"`
+	}
+	if err := b.client.get(ctx, "/apis/metrics.k8s.io/v1beta1/nodes", query, &metrics); err != nil {
+		if mcperr.CodeOf(err) == mcperr.CodeNotFound {
+			return nil, mcperr.NotFound("metrics API unavailable; is metrics-server installed?")
+		}
+		return nil, err
+	}
+
+	var nodes struct {
+		Items []struct {
+			Metadata objectMeta `json:"metadata"`
+			Status   struct {
+				Allocatable map[string]string `json:"allocatable"`
+				Conditions  []struct {
+					Type   string `json:"type"`
+					Status string `json:"status"`
+				} `json:"conditions"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := b.client.get(ctx, "/api/v1/nodes", query, &nodes); err != nil {
+		return nil, err
+	}
+
+	type capacity struct {
+		cpu, memory float64
+		ready       bool
+	}
+	allocatable := make(map[string]capacity, len(nodes.Items))
+	for _, n := range nodes.Items {
+		var c capacity
+		c.cpu, _ = parseQuantity(n.Status.Allocatable["cpu"])
+		c.memory, _ = parseQuantity(n.Status.Allocatable["memory"])
+		for _, cond := range n.Status.Conditions {
+			if cond.Type == "Ready" {
+				c.ready = cond.Status == "True"
+			}
+		}
+		allocatable[n.Metadata.Name] = c
+	}
+
+	result := &topNodesResult{Nodes: []NodeUsage{}}
+	for _, m := range metrics.Items {
+		cpu, err := parseQuantity(m.Usage["cpu"])
+		if err != nil {
+			return nil, mcperr.Upstream(err, "node %s cpu usage", m.Metadata.Name)
+		}
+		memory, err := parseQuantity(m.Usage["memory"])
+		if err != nil {
+			return nil, mcperr.Upstream(err, "node %s memory usage", m.Metadata.Name)
+		}
+		c := allocatable[m.Metadata.Name]
+		result.Nodes = append(result.Nodes, NodeUsage{
+			Name:          m.Metadata.Name,
+			CPUMillicores: int64(cpu * 1000),
+			CPUPercent:    percent(cpu, c.cpu),
+			MemoryBytes:   int64(memory),
+			MemoryPercent: percent(memory, c.memory),
+			Ready:         c.ready,
+		})
+	}
+
+	sort.SliceStable(result.Nodes, func(i, j int) bool {
+		x, y := result.Nodes[i], result.Nodes[j]
+		switch in.SortBy {
+		case "name":
+			return x.Name < y.Name
+		case "memory":
+			return x.MemoryBytes > y.MemoryBytes
+		}
+		return x.CPUMillicores > y.CPUMillicores
+	})
+	return result, nil
+}
+
+// ============================================================
+// Write tools (read_only: false)
+// ============================================================
+
+type deletePodArgs struct {
+	Namespace          string `json:"namespace,omitempty" description:"Namespace (default: the configured namespace)"`
+	Pod                string `json:"pod" description:"Pod name"`
+	GracePeriodSeconds *int   `json:"grace_period_seconds,omitempty" jsonschema:"minimum=0" description:"Seconds to shut down gracefully (default: the pod's)"`
+}
+
+type scaleArgs struct {
+	Kind      string `json:"kind" jsonschema:"enum=Deployment|StatefulSet|ReplicaSet" description:"Kind of the workload"`
+	Name      string `json:"name" description:"Workload name"`
+	Namespace string `json:"namespace,omitempty" description:"Namespace (default: the configured namespace)"`
+	Replicas  int    `json:"replicas" jsonschema:"minimum=0" description:"Desired replica count"`
+}
+
+type writeResult struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Replicas  *int   `json:"replicas,omitempty"`
+}
+
+func (b *Backend) registerWriteTools() {
+	backend.RegisterTypedTool(b, backend.NewTool("delete_pod").
+		Description("Delete a pod, e.g. to have its controller restart it.").
+		ParamsFromStruct(deletePodArgs{}).
+		NonCacheable().
+		Build(), b.deletePod)
+
+	backend.RegisterTypedTool(b, backend.NewTool("scale").
+		Description("Set the replica count of a Deployment, StatefulSet or ReplicaSet.").
+		ParamsFromStruct(scaleArgs{}).
+		NonCacheable().
+		Build(), b.scale)
+}
+
+// deletePod handles delete_pod
+func (b *Backend) deletePod(ctx context.Context, in deletePodArgs) (*writeResult, error) {
+	namespace := b.namespaceOf(in.Namespace)
+	var options map[string]interface{}
+	if in.GracePeriodSeconds != nil {
+		options = map[string]interface{}{"gracePeriodSeconds": *in.GracePeriodSeconds}
+	}
+	path := "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods/" + url.PathEscape(in.Pod)
+	if err := b.client.do(ctx, http.MethodDelete, path, nil, options, "application/json", nil); err != nil {
+		return nil, err
+	}
+	b.logger.Info("pod deleted", "namespace", namespace, "pod", in.Pod)
+	return &writeResult{Kind: "Pod", Name: in.Pod, Namespace: namespace}, nil
+}
+
+// scale handles scale through the workload's scale subresource
+func (b *Backend) scale(ctx context.Context, in scaleArgs) (*writeResult, error) {
+	kind, ok := lookupKind(in.Kind)
+	if !ok || kind.group != "apps" || kind.Kind == "DaemonSet" {
+		return nil, &backend.ArgumentError{Tool: "scale", Fields: []backend.FieldError{{
+			Field: "kind", Message: "must be Deployment, StatefulSet or ReplicaSet",
+		}}}
+	}
+	namespace := b.namespaceOf(in.Namespace)
+	patch := map[string]interface{}{"spec": map[string]interface{}{"replicas": in.Replicas}}
+
+	var out struct {
+		Spec struct {
+			Replicas int `json:"replicas"`
+		} `json:"spec"`
+	}
+	path := kind.path(url.PathEscape(namespace), url.PathEscape(in.Name)) + "/scale"
+	if err := b.client.do(ctx, http.MethodPatch, path, nil, patch, "application/merge-patch+json", &out); err != nil {
+		return nil, err
+	}
+	b.logger.Info("workload scaled", "kind", kind.Kind, "namespace", namespace, "name", in.Name, "replicas", out.Spec.Replicas)
+	return &writeResult{Kind: kind.Kind, Name: in.Name, Namespace: namespace, Replicas: &out.Spec.Replicas}, nil
+}
@@ -0,0 +1,446 @@
+package kubernetes_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backends/kubernetes"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+type captureEmitter struct {
+	ctx      context.Context
+	lines    []kubernetes.LogLine
+	progress []string
+	after    func(n int) // called after each line
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.lines = append(e.lines, data.(kubernetes.LogLine))
+	if e.after != nil {
+		e.after(len(e.lines))
+	}
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error {
+	e.progress = append(e.progress, message)
+	return nil
+}
+func (e *captureEmitter) Context() context.Context { return e.ctx }
+
+const token = "sre-token"
+
+// fakeAPI is an API server with a few objects in namespace "team"
+type fakeAPI struct {
+	patched string
+	deleted string
+}
+
+func writeStatus(w http.ResponseWriter, code int, reason, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"kind": "Status", "status": "Failure", "reason": reason, "message": message, "code": code,
+	})
+}
+
+func podJSON(name string, containers ...string) map[string]interface{} {
+	var specs, statuses []interface{}
+	for _, c := range containers {
+		specs = append(specs, map[string]interface{}{"name": c, "image": c + ":1"})
+		statuses = append(statuses, map[string]interface{}{
+			"name": c, "ready": true, "restartCount": 1,
+			"state": map[string]interface{}{"running": map[string]interface{}{}},
+		})
+	}
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": name, "namespace": "team",
+			"creationTimestamp": time.Now().Add(-3 * time.Hour).Format(time.RFC3339),
+		},
+		"spec":   map[string]interface{}{"nodeName": "node-1", "containers": specs},
+		"status": map[string]interface{}{"phase": "Running", "podIP": "10.0.0.7", "containerStatuses": statuses},
+	}
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+token {
+		writeStatus(w, http.StatusUnauthorized, "Unauthorized", "Unauthorized")
+		return
+	}
+	enc := json.NewEncoder(w)
+	switch r.Method + " " + r.URL.Path {
+	case "GET /version":
+		enc.Encode(map[string]string{"gitVersion": "v1.31.0"})
+	case "GET /api/v1/namespaces/team/pods":
+		if r.URL.Query().Get("continue") == "" {
+			crashing := podJSON("worker", "worker")
+			crashing["status"].(map[string]interface{})["containerStatuses"] = []interface{}{map[string]interface{}{
+				"name": "worker", "ready": false, "restartCount": 7,
+				"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}},
+			}}
+			enc.Encode(map[string]interface{}{
+				"metadata": map[string]interface{}{"continue": "page2"},
+				"items":    []interface{}{podJSON("web", "web", "proxy"), crashing},
+			})
+			return
+		}
+		enc.Encode(map[string]interface{}{"items": []interface{}{podJSON("api", "api")}})
+	case "GET /api/v1/pods":
+		writeStatus(w, http.StatusForbidden, "Forbidden",
+			`pods is forbidden: User "system:serviceaccount:team:mcp" cannot list resource "pods" in API group "" at the cluster scope`)
+	case "GET /api/v1/namespaces/team/pods/web":
+		enc.Encode(podJSON("web", "web", "proxy"))
+	case "GET /api/v1/namespaces/team/pods/api":
+		enc.Encode(podJSON("api", "api"))
+	case "GET /api/v1/namespaces/team/pods/api/log":
+		q := r.URL.Query()
+		if q.Get("container") != "api" {
+			writeStatus(w, http.StatusBadRequest, "BadRequest", "container not found")
+			return
+		}
+		prefix := ""
+		if q.Get("timestamps") == "true" {
+			prefix = "2026-05-01T10:00:00.123456789Z "
+		}
+		fmt.Fprintf(w, "%sstarting\n%slistening on :8080\n", prefix, prefix)
+		w.(http.Flusher).Flush()
+		if q.Get("follow") == "true" {
+			<-r.Context().Done()
+		}
+	case "GET /api/v1/namespaces/team/secrets/db":
+		enc.Encode(map[string]interface{}{
+			"kind": "Secret",
+			"metadata": map[string]interface{}{
+				"name": "db", "namespace": "team",
+				"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+				"annotations": map[string]interface{}{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"data":{"password":"aHVudGVyMg=="}}`,
+				},
+			},
+			"data": map[string]interface{}{"password": "aHVudGVyMg=="},
+		})
+	case "GET /api/v1/namespaces/team/events":
+		if got := r.URL.Query().Get("fieldSelector"); got != "involvedObject.kind=Secret,involvedObject.name=db" {
+			writeStatus(w, http.StatusBadRequest, "BadRequest", "unexpected selector "+got)
+			return
+		}
+		enc.Encode(map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"type": "Normal", "reason": "Old", "lastTimestamp": "2026-05-01T09:00:00Z"},
+			map[string]interface{}{"type": "Warning", "reason": "New", "lastTimestamp": "2026-05-01T10:00:00Z"},
+		}})
+	case "GET /apis/metrics.k8s.io/v1beta1/nodes":
+		enc.Encode(map[string]interface{}{"items": []interface{}{
+			map[string]interface{}{"metadata": map[string]interface{}{"name": "node-1"}, "usage": map[string]string{"cpu": "250000000n", "memory": "1Gi"}},
+			map[string]interface{}{"metadata": map[string]interface{}{"name": "node-2"}, "usage": map[string]string{"cpu": "1500m", "memory": "512Mi"}},
+		}})
+	case "GET /api/v1/nodes":
+		node := func(name string) map[string]interface{} {
+			return map[string]interface{}{
+				"metadata": map[string]interface{}{"name": name},
+				"status": map[string]interface{}{
+					"allocatable": map[string]string{"cpu": "2", "memory": "4Gi"},
+					"conditions":  []interface{}{map[string]string{"type": "Ready", "status": "True"}},
+				},
+			}
+		}
+		enc.Encode(map[string]interface{}{"items": []interface{}{node("node-1"), node("node-2")}})
+	case "DELETE /api/v1/namespaces/team/pods/api":
+		f.deleted = "api"
+		enc.Encode(podJSON("api", "api"))
+	case "PATCH /apis/apps/v1/namespaces/team/deployments/web/scale":
+		body, _ := io.ReadAll(r.Body)
+		f.patched = r.Header.Get("Content-Type") + " " + string(body)
+		enc.Encode(map[string]interface{}{"spec": map[string]interface{}{"replicas": 3}})
+	default:
+		writeStatus(w, http.StatusNotFound, "NotFound", r.URL.Path+" not found")
+	}
+}
+
+// newBackend starts a fake API server and a backend connected to it via
+// a kubeconfig trusting its certificate
+func newBackend(t *testing.T, config map[string]interface{}) (*kubernetes.Backend, *fakeAPI) {
+	t.Helper()
+	fake := &fakeAPI{}
+	srv := httptest.NewTLSServer(fake)
+	t.Cleanup(srv.Close)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: test
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: sre
+  user:
+    token: %s
+contexts:
+- name: dev
+  context:
+    cluster: test
+    user: sre
+    namespace: team
+`, srv.URL, base64.StdEncoding.EncodeToString(ca), token)), 0o600)
+
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	config["kubeconfig"] = kubeconfig
+	b := kubernetes.New()
+	if err := b.Initialize(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	return b, fake
+}
+
+func call(t *testing.T, b *kubernetes.Backend, tool string, args map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	result, err := b.CallTool(context.Background(), tool, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result)
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func TestListPods(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	out := call(t, b, "list_pods", map[string]interface{}{})
+	pods := out["pods"].([]interface{})
+	if len(pods) != 3 {
+		t.Fatalf("pods = %v, want both pages", pods)
+	}
+	web := pods[0].(map[string]interface{})
+	if web["ready"] != "2/2" || web["restarts"] != float64(2) || web["age"] != "3h" || web["node"] != "node-1" {
+		t.Errorf("web = %v", web)
+	}
+	if worker := pods[1].(map[string]interface{}); worker["status"] != "CrashLoopBackOff" || worker["ready"] != "0/1" {
+		t.Errorf("worker = %v, want its waiting reason", worker)
+	}
+
+	out = call(t, b, "list_pods", map[string]interface{}{"limit": 2})
+	if len(out["pods"].([]interface{})) != 2 || out["truncated"] != true {
+		t.Errorf("limited = %v", out)
+	}
+}
+
+func TestRBACDenied(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	_, err := b.CallTool(context.Background(), "list_pods", map[string]interface{}{"all_namespaces": true})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Fatalf("err = %v, want permission denied", err)
+	}
+	if !strings.Contains(err.Error(), `cannot list resource "pods"`) {
+		t.Errorf("err = %v, want the API server's reason", err)
+	}
+
+	_, err = b.CallTool(context.Background(), "describe_resource", map[string]interface{}{"kind": "deploy", "name": "missing"})
+	if mcperr.CodeOf(err) != mcperr.CodeNotFound {
+		t.Errorf("missing object: err = %v, want not found", err)
+	}
+}
+
+func TestGetLogs(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	emit := &captureEmitter{ctx: context.Background()}
+	err := b.CallStreamingTool(context.Background(), "get_logs", map[string]interface{}{
+		"pod": "api", "timestamps": true,
+	}, emit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emit.lines) != 2 || emit.lines[1].Line != "listening on :8080" || emit.lines[1].Container != "api" {
+		t.Fatalf("lines = %+v", emit.lines)
+	}
+	if emit.lines[0].Time == nil || emit.lines[0].Time.Nanosecond() != 123456789 {
+		t.Errorf("time = %v", emit.lines[0].Time)
+	}
+
+	// Several containers need a choice
+	err = b.CallStreamingTool(context.Background(), "get_logs", map[string]interface{}{"pod": "web"}, emit)
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) || !strings.Contains(argErr.Error(), "web, proxy") {
+		t.Errorf("err = %v, want the containers listed", err)
+	}
+}
+
+func TestGetLogs_Follow(t *testing.T) {
+	b, _ := newBackend(t, map[string]interface{}{"follow_timeout": "200ms"})
+
+	// Timing out ends a followed stream normally
+	emit := &captureEmitter{ctx: context.Background()}
+	start := time.Now()
+	err := b.CallStreamingTool(context.Background(), "get_logs", map[string]interface{}{"pod": "api", "follow": true}, emit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emit.lines) != 2 || len(emit.progress) != 1 || time.Since(start) > 5*time.Second {
+		t.Errorf("lines = %d, progress = %v", len(emit.lines), emit.progress)
+	}
+
+	// Cancelling does not
+	ctx, cancel := context.WithCancel(context.Background())
+	emit = &captureEmitter{ctx: ctx, after: func(n int) {
+		if n == 2 {
+			cancel()
+		}
+	}}
+	err = b.CallStreamingTool(ctx, "get_logs", map[string]interface{}{"pod": "api", "follow": true}, emit)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestDescribeResource_Secret(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	out := call(t, b, "describe_resource", map[string]interface{}{"kind": "secret", "name": "db"})
+	data, _ := json.Marshal(out)
+	if strings.Contains(string(data), "aHVudGVyMg==") || strings.Contains(string(data), "managedFields") {
+		t.Errorf("secret leaked: %s", data)
+	}
+	object := out["object"].(map[string]interface{})
+	if got := object["data"].(map[string]interface{})["password"]; got != "<redacted, 12 bytes>" {
+		t.Errorf("password = %v", got)
+	}
+	events := out["events"].([]interface{})
+	if len(events) != 2 || events[0].(map[string]interface{})["reason"] != "New" {
+		t.Errorf("events = %v, want newest first", events)
+	}
+
+	b, _ = newBackend(t, map[string]interface{}{"reveal_secrets": true})
+	out = call(t, b, "describe_resource", map[string]interface{}{"kind": "Secret", "name": "db"})
+	if got := out["object"].(map[string]interface{})["data"].(map[string]interface{})["password"]; got != "aHVudGVyMg==" {
+		t.Errorf("revealed password = %v", got)
+	}
+
+	_, err := b.CallTool(context.Background(), "describe_resource", map[string]interface{}{"kind": "Widget", "name": "x"})
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) {
+		t.Errorf("unknown kind: err = %v", err)
+	}
+}
+
+func TestTopNodes(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	out := call(t, b, "top_nodes", map[string]interface{}{})
+	nodes := out["nodes"].([]interface{})
+	if len(nodes) != 2 {
+		t.Fatalf("nodes = %v", nodes)
+	}
+	first := nodes[0].(map[string]interface{})
+	if first["name"] != "node-2" || first["cpu_millicores"] != float64(1500) || first["cpu_percent"] != float64(75) {
+		t.Errorf("first = %v, want node-2 at 75%% CPU", first)
+	}
+	second := nodes[1].(map[string]interface{})
+	if second["cpu_millicores"] != float64(250) || second["memory_percent"] != float64(25) || second["ready"] != true {
+		t.Errorf("second = %v", second)
+	}
+
+	out = call(t, b, "top_nodes", map[string]interface{}{"sort_by": "memory"})
+	if out["nodes"].([]interface{})[0].(map[string]interface{})["name"] != "node-1" {
+		t.Errorf("by memory = %v", out["nodes"])
+	}
+}
+
+func TestReadOnlyDefault(t *testing.T) {
+	b, _ := newBackend(t, nil)
+	for _, name := range []string{"delete_pod", "scale"} {
+		if _, ok := b.GetTool(name); ok {
+			t.Errorf("%s served in read-only mode", name)
+		}
+	}
+
+	b, fake := newBackend(t, map[string]interface{}{"read_only": false})
+	call(t, b, "delete_pod", map[string]interface{}{"pod": "api"})
+	if fake.deleted != "api" {
+		t.Errorf("deleted = %q", fake.deleted)
+	}
+	out := call(t, b, "scale", map[string]interface{}{"kind": "Deployment", "name": "web", "replicas": 3})
+	if out["replicas"] != float64(3) || fake.patched != `application/merge-patch+json {"spec":{"replicas":3}}` {
+		t.Errorf("scale = %v, patch = %q", out, fake.patched)
+	}
+}
+
+func TestLoadKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "ca.pem"), []byte("ca"), 0o600)
+	os.WriteFile(filepath.Join(dir, "token"), []byte("file-token\n"), 0o600)
+	path := filepath.Join(dir, "config")
+	os.WriteFile(path, []byte(`current-context: a
+clusters:
+- name: one
+  cluster:
+    server: https://one.example.com
+    certificate-authority: ca.pem
+- name: two
+  cluster:
+    server: https://two.example.com
+users:
+- name: file
+  user:
+    tokenFile: token
+- name: plugin
+  user:
+    exec:
+      command: aws
+contexts:
+- name: a
+  context: {cluster: one, user: file}
+- name: b
+  context: {cluster: two, user: plugin, namespace: ops}
+- name: c
+  context: {cluster: three, user: file}
+`), 0o600)
+
+	config, err := kubernetes.LoadKubeconfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Server != "https://one.example.com" || string(config.CAData) != "ca" || config.TokenFile != filepath.Join(dir, "token") {
+		t.Errorf("config = %+v", config)
+	}
+
+	for _, context := range []string{"b", "c", "missing"} {
+		if _, err := kubernetes.LoadKubeconfig(path, context); err == nil {
+			t.Errorf("context %s: want an error", context)
+		}
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	fake := &fakeAPI{}
+	srv := httptest.NewTLSServer(fake)
+	defer srv.Close()
+	client, err := kubernetes.NewClient(&kubernetes.Config{Server: srv.URL, Token: "wrong", Insecure: true, Namespace: "team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := kubernetes.NewWithClient(client)
+	if err := b.Initialize(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err = b.CallTool(context.Background(), "list_pods", map[string]interface{}{})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied || !strings.Contains(err.Error(), "credentials rejected") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,286 @@
+package kubernetes
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resourceKind is an API resource describe_resource can fetch
+type resourceKind struct {
+	Kind       string
+	group      string // "" for the core group
+	version    string
+	plural     string
+	namespaced bool
+	aliases    []string
+}
+
+// resourceKinds are the kinds describe_resource knows
+var resourceKinds = []resourceKind{
+	{"Pod", "", "v1", "pods", true, []string{"pod", "po"}},
+	{"Service", "", "v1", "services", true, []string{"service", "svc"}},
+	{"ConfigMap", "", "v1", "configmaps", true, []string{"configmap", "cm"}},
+	{"Secret", "", "v1", "secrets", true, []string{"secret"}},
+	{"ServiceAccount", "", "v1", "serviceaccounts", true, []string{"serviceaccount", "sa"}},
+	{"PersistentVolumeClaim", "", "v1", "persistentvolumeclaims", true, []string{"persistentvolumeclaim", "pvc"}},
+	{"Endpoints", "", "v1", "endpoints", true, []string{"ep"}},
+	{"Node", "", "v1", "nodes", false, []string{"node", "no"}},
+	{"Namespace", "", "v1", "namespaces", false, []string{"namespace", "ns"}},
+	{"PersistentVolume", "", "v1", "persistentvolumes", false, []string{"persistentvolume", "pv"}},
+	{"Deployment", "apps", "v1", "deployments", true, []string{"deployment", "deploy"}},
+	{"StatefulSet", "apps", "v1", "statefulsets", true, []string{"statefulset", "sts"}},
+	{"DaemonSet", "apps", "v1", "daemonsets", true, []string{"daemonset", "ds"}},
+	{"ReplicaSet", "apps", "v1", "replicasets", true, []string{"replicaset", "rs"}},
+	{"Job", "batch", "v1", "jobs", true, []string{"job"}},
+	{"CronJob", "batch", "v1", "cronjobs", true, []string{"cronjob", "cj"}},
+	{"Ingress", "networking.k8s.io", "v1", "ingresses", true, []string{"ingress", "ing"}},
+	{"HorizontalPodAutoscaler", "autoscaling", "v2", "horizontalpodautoscalers", true, []string{"horizontalpodautoscaler", "hpa"}},
+}
+
+// kindNames lists the canonical kinds, for schemas and errors
+func kindNames() []string {
+	names := make([]string, len(resourceKinds))
+	for i, k := range resourceKinds {
+		names[i] = k.Kind
+	}
+	return names
+}
+
+// lookupKind finds a kind by name, plural or short name, ignoring case
+func lookupKind(name string) (resourceKind, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, k := range resourceKinds {
+		if name == strings.ToLower(k.Kind) || name == k.plural {
+			return k, true
+		}
+		for _, alias := range k.aliases {
+			if name == alias {
+				return k, true
+			}
+		}
+	}
+	return resourceKind{}, false
+}
+
+// path returns the URL path of a resource collection, or of one object
+// when name is set
+func (k resourceKind) path(namespace, name string) string {
+	var p string
+	if k.group == "" {
+		p = "/api/" + k.version
+	} else {
+		p = "/apis/" + k.group + "/" + k.version
+	}
+	if k.namespaced && namespace != "" {
+		p += "/namespaces/" + namespace
+	}
+	p += "/" + k.plural
+	if name != "" {
+		p += "/" + name
+	}
+	return p
+}
+
+// ============================================================
+// Objects
+// ============================================================
+
+// objectMeta is the metadata this package reads
+type objectMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	CreationTimestamp time.Time         `json:"creationTimestamp"`
+	DeletionTimestamp *time.Time        `json:"deletionTimestamp,omitempty"`
+}
+
+type pod struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		NodeName   string `json:"nodeName"`
+		Containers []struct {
+			Name  string `json:"name"`
+			Image string `json:"image"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase             string            `json:"phase"`
+		Reason            string            `json:"reason"`
+		PodIP             string            `json:"podIP"`
+		ContainerStatuses []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerStatus struct {
+	Name         string `json:"name"`
+	Ready        bool   `json:"ready"`
+	RestartCount int    `json:"restartCount"`
+	State        map[string]struct {
+		Reason string `json:"reason"`
+	} `json:"state"`
+}
+
+// PodSummary is one row of list_pods, like kubectl get pods -o wide
+type PodSummary struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	Status     string            `json:"status"`
+	Ready      string            `json:"ready"`
+	Restarts   int               `json:"restarts"`
+	Age        string            `json:"age"`
+	Node       string            `json:"node,omitempty"`
+	IP         string            `json:"ip,omitempty"`
+	Containers []string          `json:"containers"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// summarize builds a pod's summary as of now
+func (p pod) summarize(now time.Time) PodSummary {
+	s := PodSummary{
+		Name:      p.Metadata.Name,
+		Namespace: p.Metadata.Namespace,
+		Status:    p.Status.Phase,
+		Age:       age(now.Sub(p.Metadata.CreationTimestamp)),
+		Node:      p.Spec.NodeName,
+		IP:        p.Status.PodIP,
+		Labels:    p.Metadata.Labels,
+	}
+	if p.Status.Reason != "" {
+		s.Status = p.Status.Reason
+	}
+	ready := 0
+	for _, c := range p.Status.ContainerStatuses {
+		if c.Ready {
+			ready++
+		}
+		s.Restarts += c.RestartCount
+		// A waiting or terminated container explains the pod better than
+		// its phase (CrashLoopBackOff, ImagePullBackOff, OOMKilled)
+		for state, detail := range c.State {
+			if state != "running" && detail.Reason != "" && detail.Reason != "Completed" {
+				s.Status = detail.Reason
+			}
+		}
+	}
+	if p.Metadata.DeletionTimestamp != nil {
+		s.Status = "Terminating"
+	}
+	for _, c := range p.Spec.Containers {
+		s.Containers = append(s.Containers, c.Name)
+	}
+	s.Ready = fmt.Sprintf("%d/%d", ready, len(p.Spec.Containers))
+	return s
+}
+
+// age formats a duration the way kubectl does: 45s, 12m, 5h, 3d
+func age(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "0s"
+	case d < 2*time.Minute:
+		return strconv.Itoa(int(d.Seconds())) + "s"
+	case d < 2*time.Hour:
+		return strconv.Itoa(int(d.Minutes())) + "m"
+	case d < 48*time.Hour:
+		return strconv.Itoa(int(d.Hours())) + "h"
+	}
+	return strconv.Itoa(int(d.Hours()/24)) + "d"
+}
+
+// Event is a cluster event about an object
+type Event struct {
+	Type    string    `json:"type"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+	Count   int       `json:"count,omitempty"`
+	Last    time.Time `json:"last_seen"`
+}
+
+type apiEvent struct {
+	Type           string    `json:"type"`
+	Reason         string    `json:"reason"`
+	Message        string    `json:"message"`
+	Count          int       `json:"count"`
+	LastTimestamp  time.Time `json:"lastTimestamp"`
+	EventTime      time.Time `json:"eventTime"`
+	FirstTimestamp time.Time `json:"firstTimestamp"`
+}
+
+// event converts an API event, taking the latest of its timestamps
+func (e apiEvent) event() Event {
+	last := e.LastTimestamp
+	for _, t := range []time.Time{e.EventTime, e.FirstTimestamp} {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return Event{Type: e.Type, Reason: e.Reason, Message: e.Message, Count: e.Count, Last: last}
+}
+
+// sortEvents orders events newest first
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Last.After(events[j].Last) })
+}
+
+// redactSecret replaces a Secret's values with their sizes
+func redactSecret(object map[string]interface{}) {
+	for _, key := range []string{"data", "stringData"} {
+		values, ok := object[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for k, v := range values {
+			s, _ := v.(string)
+			values[k] = fmt.Sprintf("<redacted, %d bytes>", len(s))
+		}
+	}
+	// kubectl apply keeps the applied manifest, values included
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		}
+	}
+}
+
+// ============================================================
+// Quantities
+// ============================================================
+
+// quantitySuffixes are the multipliers of resource quantity suffixes
+var quantitySuffixes = map[string]float64{
+	"n": 1e-9, "u": 1e-6, "m": 1e-3, "": 1,
+	"k": 1e3, "M": 1e6, "G": 1e9, "T": 1e12, "P": 1e15, "E": 1e18,
+	"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50, "Ei": 1 << 60,
+}
+
+// parseQuantity parses a resource quantity such as 250m, 1.5, 512Mi or
+// 1e3 into its value
+func parseQuantity(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	end := len(s)
+	for end > 0 && (s[end-1] < '0' || s[end-1] > '9') && s[end-1] != '.' {
+		end--
+	}
+	number, suffix := s[:end], s[end:]
+	multiplier, ok := quantitySuffixes[suffix]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	return v * multiplier, nil
+}
+
+// percent returns used as a rounded share of total, or 0 without a total
+func percent(used, total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(used/total*1000) / 10
+}
@@ -0,0 +1,94 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoOffsets is returned by brokers that cannot start a consumer at an
+// offset, such as core NATS without JetStream
+var ErrNoOffsets = errors.New("broker does not keep offsets")
+
+// ErrNoHeaders is returned by brokers without message headers, such as
+// the Kafka REST proxy
+var ErrNoHeaders = errors.New("broker does not support message headers")
+
+// errStop ends a consumer's loop without an error
+var errStop = errors.New("stop consuming")
+
+// Message is a message published to or consumed from a topic
+type Message struct {
+	Topic   string            `json:"topic"`
+	Key     string            `json:"key,omitempty"`
+	Value   string            `json:"value"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Partition and Offset locate a consumed message: the Kafka
+	// partition and offset, or the JetStream stream sequence
+	Partition *int       `json:"partition,omitempty"`
+	Offset    *int64     `json:"offset,omitempty"`
+	Time      *time.Time `json:"time,omitempty"`
+}
+
+// PublishResult is where a published message was stored
+// Brokers without storage (core NATS) leave the location empty.
+type PublishResult struct {
+	Topic     string `json:"topic"`
+	Partition *int   `json:"partition,omitempty"`
+	Offset    *int64 `json:"offset,omitempty"`
+	Stream    string `json:"stream,omitempty"`
+}
+
+// Positions consume_topic starts from
+const (
+	FromLatest   = "latest"
+	FromEarliest = "earliest"
+	FromOffset   = "offset"
+)
+
+// Acknowledgement modes of consume_topic
+const (
+	// AckAuto acknowledges (commits) each message once it is emitted
+	AckAuto = "auto"
+
+	// AckNone leaves messages unacknowledged, so a consumer group sees
+	// them again
+	AckNone = "none"
+)
+
+// ConsumeOptions configures a consumer
+type ConsumeOptions struct {
+	Topic string
+
+	// Group is the Kafka consumer group or JetStream durable consumer;
+	// "" consumes without one (core NATS: a queue group)
+	Group string
+
+	// From is FromLatest, FromEarliest or FromOffset
+	From string
+
+	// Offset is the first offset (FromOffset) of Partition (Kafka)
+	Offset    int64
+	Partition int
+
+	// Ack is AckAuto or AckNone
+	Ack string
+}
+
+// Broker is a message system
+type Broker interface {
+	// Publish stores or delivers a message
+	Publish(ctx context.Context, msg Message) (*PublishResult, error)
+
+	// Consume calls fn with each message of the topic until ctx ends or
+	// fn returns an error. A message is acknowledged after fn returns
+	// nil, if opts.Ack asks for it.
+	Consume(ctx context.Context, opts ConsumeOptions, fn func(Message) error) error
+
+	// Ping checks that the broker is reachable
+	Ping(ctx context.Context) error
+
+	// Close releases the broker's resources
+	Close() error
+}
@@ -0,0 +1,267 @@
+package mq
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Content types of the Kafka REST Proxy v2 API with binary payloads
+const (
+	kafkaBinary = "application/vnd.kafka.binary.v2+json"
+	kafkaJSON   = "application/vnd.kafka.v2+json"
+)
+
+// kafkaPollTimeout is how long one records request waits for messages
+const kafkaPollTimeout = time.Second
+
+// KafkaBroker reaches Kafka through a REST proxy speaking the Confluent
+// REST Proxy v2 API, so no broker protocol client is needed
+// Each consume call creates a consumer instance and deletes it when done.
+type KafkaBroker struct {
+	// BaseURL is the proxy's address, e.g. http://localhost:8082
+	BaseURL string
+
+	// Client makes the requests, authenticated if the proxy needs it
+	Client *http.Client
+}
+
+// Publish implements Broker
+// The REST proxy v2 API has no message headers.
+func (b *KafkaBroker) Publish(ctx context.Context, msg Message) (*PublishResult, error) {
+	if len(msg.Headers) > 0 {
+		return nil, ErrNoHeaders
+	}
+	record := map[string]interface{}{"value": base64.StdEncoding.EncodeToString([]byte(msg.Value))}
+	if msg.Key != "" {
+		record["key"] = base64.StdEncoding.EncodeToString([]byte(msg.Key))
+	}
+	if msg.Partition != nil {
+		record["partition"] = *msg.Partition
+	}
+	var out struct {
+		Offsets []struct {
+			Partition *int   `json:"partition"`
+			Offset    *int64 `json:"offset"`
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+	}
+	body := map[string]interface{}{"records": []interface{}{record}}
+	if err := b.do(ctx, http.MethodPost, "/topics/"+url.PathEscape(msg.Topic), kafkaBinary, body, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Offsets) == 0 {
+		return nil, mcperr.New(mcperr.CodeUpstream, "mq: publish to %s: no offset in reply", msg.Topic)
+	}
+	o := out.Offsets[0]
+	if o.Error != "" || o.ErrorCode != nil {
+		return nil, mcperr.New(mcperr.CodeUpstream, "mq: publish to %s: %s", msg.Topic, o.Error)
+	}
+	return &PublishResult{Topic: msg.Topic, Partition: o.Partition, Offset: o.Offset}, nil
+}
+
+// Consume implements Broker
+// Offsets are committed per message with AckAuto; the consumer never
+// auto-commits, so AckNone leaves the group's position unchanged.
+func (b *KafkaBroker) Consume(ctx context.Context, opts ConsumeOptions, fn func(Message) error) error {
+	group := opts.Group
+	if group == "" {
+		group = "mcp-" + randomToken()
+	}
+	reset := "latest"
+	if opts.From == FromEarliest {
+		reset = "earliest"
+	}
+	name := "mcp-" + randomToken()
+	create := map[string]interface{}{
+		"name":               name,
+		"format":             "binary",
+		"auto.offset.reset":  reset,
+		"auto.commit.enable": "false",
+	}
+	groupPath := "/consumers/" + url.PathEscape(group)
+	if err := b.do(ctx, http.MethodPost, groupPath, kafkaJSON, create, nil); err != nil {
+		return err
+	}
+	instance := groupPath + "/instances/" + url.PathEscape(name)
+	defer func() {
+		// Delete the instance even when ctx was cancelled
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		b.do(ctx, http.MethodDelete, instance, kafkaJSON, nil, nil)
+	}()
+
+	if opts.From == FromOffset {
+		partition := map[string]interface{}{"topic": opts.Topic, "partition": opts.Partition}
+		if err := b.do(ctx, http.MethodPost, instance+"/assignments", kafkaJSON,
+			map[string]interface{}{"partitions": []interface{}{partition}}, nil); err != nil {
+			return err
+		}
+		position := map[string]interface{}{"topic": opts.Topic, "partition": opts.Partition, "offset": opts.Offset}
+		if err := b.do(ctx, http.MethodPost, instance+"/positions", kafkaJSON,
+			map[string]interface{}{"offsets": []interface{}{position}}, nil); err != nil {
+			return err
+		}
+	} else if err := b.do(ctx, http.MethodPost, instance+"/subscription", kafkaJSON,
+		map[string]interface{}{"topics": []string{opts.Topic}}, nil); err != nil {
+		return err
+	}
+
+	records := instance + "/records?timeout=" + fmt.Sprint(kafkaPollTimeout.Milliseconds())
+	for {
+		var batch []kafkaRecord
+		if err := b.do(ctx, http.MethodGet, records, "", nil, &batch); err != nil {
+			return err
+		}
+		for _, r := range batch {
+			msg, err := r.message()
+			if err != nil {
+				return err
+			}
+			if err := fn(msg); err != nil {
+				return err
+			}
+			if opts.Ack == AckNone {
+				continue
+			}
+			commit := map[string]interface{}{"topic": r.Topic, "partition": r.Partition, "offset": r.Offset + 1}
+			if err := b.do(ctx, http.MethodPost, instance+"/offsets", kafkaJSON,
+				map[string]interface{}{"offsets": []interface{}{commit}}, nil); err != nil {
+				return err
+			}
+		}
+		if len(batch) == 0 {
+			// The proxy returns at once while the consumer joins its group
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+}
+
+// Ping implements Broker
+func (b *KafkaBroker) Ping(ctx context.Context) error {
+	return b.do(ctx, http.MethodGet, "/topics", "", nil, nil)
+}
+
+// Close implements Broker
+func (b *KafkaBroker) Close() error {
+	if b.Client != nil {
+		b.Client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// kafkaRecord is a record of a binary consumer
+type kafkaRecord struct {
+	Topic     string  `json:"topic"`
+	Key       *string `json:"key"`
+	Value     *string `json:"value"`
+	Partition int     `json:"partition"`
+	Offset    int64   `json:"offset"`
+}
+
+func (r kafkaRecord) message() (Message, error) {
+	msg := Message{Topic: r.Topic, Partition: &r.Partition, Offset: &r.Offset}
+	if r.Key != nil {
+		key, err := base64.StdEncoding.DecodeString(*r.Key)
+		if err != nil {
+			return msg, mcperr.Upstream(err, "mq: invalid key at %s/%d/%d", r.Topic, r.Partition, r.Offset)
+		}
+		msg.Key = string(key)
+	}
+	if r.Value != nil {
+		value, err := base64.StdEncoding.DecodeString(*r.Value)
+		if err != nil {
+			return msg, mcperr.Upstream(err, "mq: invalid value at %s/%d/%d", r.Topic, r.Partition, r.Offset)
+		}
+		msg.Value = string(value)
+	}
+	return msg, nil
+}
+
+// do sends a request to the proxy and decodes its JSON reply into out
+func (b *KafkaBroker) do(ctx context.Context, method, path, contentType string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(b.BaseURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", kafkaBinary+", "+kafkaJSON+", application/json")
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return mcperr.Upstream(err, "mq: kafka proxy %s %s", method, path)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return mcperr.Upstream(err, "mq: kafka proxy %s %s", method, path)
+	}
+	if resp.StatusCode >= 300 {
+		return kafkaError(resp.StatusCode, data, path)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return mcperr.Upstream(err, "mq: invalid kafka proxy reply to %s", path)
+	}
+	return nil
+}
+
+// kafkaError maps a proxy error reply
+func kafkaError(status int, data []byte, path string) error {
+	var reply struct {
+		ErrorCode int    `json:"error_code"`
+		Message   string `json:"message"`
+	}
+	json.Unmarshal(data, &reply)
+	message := reply.Message
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	switch status {
+	case http.StatusNotFound:
+		return mcperr.NotFound("mq: %s: %s", path, message)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return mcperr.PermissionDenied("mq: %s: %s", path, message)
+	case http.StatusTooManyRequests:
+		return mcperr.RateLimited(0, "mq: %s: %s", path, message)
+	}
+	return mcperr.Upstream(errors.New(message), "mq: kafka proxy returned %d", status)
+}
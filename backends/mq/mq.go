@@ -0,0 +1,402 @@
+// Package mq is a message queue backend for Kafka and NATS
+//
+// Two tools are served:
+//
+//	publish_message  a message to a topic (NATS: a subject)
+//	consume_topic    a topic's messages, streamed until cancelled, the
+//	                 stream's message cap or the consume timeout
+//
+// Kafka is reached through a REST proxy (Confluent REST Proxy v2 API);
+// NATS directly, with JetStream for offsets and acknowledgements when
+// jetstream is set. consume_topic starts at the latest, earliest or a
+// given offset, and with ack "auto" commits (Kafka) or acks (JetStream)
+// each message once it has been emitted.
+//
+// Credentials come from the config, where they are usually secret
+// references resolved at load, or for Kafka from an auth resource of
+// the server's auth provider. Topics can be limited to allowed_topics,
+// and read_only drops publish_message. Importing the package registers
+// the backend as "mq":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/mq"
+//
+//	# config.yaml
+//	backend:
+//	  type: mq
+//	  config:
+//	    broker: nats
+//	    url: nats://nats.internal:4222
+//	    token: ${vault:secret/data/nats#token}
+//	    jetstream: true
+//	    allowed_topics: ["orders.*"]
+//	    max_messages: 500
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultMaxMessages     = 1000
+	DefaultConsumeTimeout  = 10 * time.Minute
+	DefaultMaxMessageBytes = 1 << 20
+)
+
+func init() {
+	backend.Register("mq", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend publishes to and consumes from one broker
+type Backend struct {
+	*backend.BaseBackend
+
+	logger *slog.Logger
+	broker Broker
+	kind   string
+
+	group           string
+	readOnly        bool
+	allowedTopics   []string
+	maxMessages     int
+	consumeTimeout  time.Duration
+	maxMessageBytes int
+}
+
+// New creates an mq backend; Initialize connects its broker
+func New() *Backend {
+	b := &Backend{
+		BaseBackend:     backend.NewBaseBackend("mq"),
+		logger:          slog.Default(),
+		maxMessages:     DefaultMaxMessages,
+		consumeTimeout:  DefaultConsumeTimeout,
+		maxMessageBytes: DefaultMaxMessageBytes,
+	}
+	b.registerTools()
+	return b
+}
+
+// NewWithBroker creates an mq backend over an existing broker;
+// the config's broker, url and credential entries are then ignored
+func NewWithBroker(broker Broker) *Backend {
+	b := New()
+	b.broker = broker
+	return b
+}
+
+// Initialize reads the config and creates the broker
+//
+// Config entries:
+//
+//	broker             kafka or nats (required unless NewWithBroker)
+//	url                kafka: the REST proxy (default the auth resource's
+//	                   base_url); nats: nats:// or tls:// address
+//	                   (default nats://127.0.0.1:4222)
+//	username           kafka: proxy basic auth; nats: user credentials
+//	password
+//	token              nats: authentication token
+//	auth_provider      auth provider of auth_resource (default: the backend's)
+//	auth_resource      kafka: auth resource whose HTTP client makes the
+//	                   requests, instead of username and password
+//	jetstream          nats: use JetStream streams (default false)
+//	group              consumer group of calls naming none (default: none)
+//	read_only          serve only consume_topic (default false)
+//	allowed_topics     topic patterns calls may use (default: any)
+//	max_messages       most messages of one consume_topic call (default 1000)
+//	consume_timeout    longest consume_topic call (default "10m")
+//	max_message_bytes  largest published value (default 1MB)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if v, ok := config["read_only"].(bool); ok {
+		b.readOnly = v
+	}
+	b.group = backend.StringConfig(config, "group", "")
+	if b.maxMessages = backend.IntConfig(config, "max_messages", DefaultMaxMessages); b.maxMessages <= 0 {
+		return errors.New("mq: max_messages must be positive")
+	}
+	if b.maxMessageBytes = backend.IntConfig(config, "max_message_bytes", DefaultMaxMessageBytes); b.maxMessageBytes <= 0 {
+		return errors.New("mq: max_message_bytes must be positive")
+	}
+	if s := backend.StringConfig(config, "consume_timeout", ""); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("mq: invalid consume_timeout %q", s)
+		}
+		b.consumeTimeout = d
+	}
+	b.allowedTopics = backend.StringsConfig(config, "allowed_topics")
+	for _, p := range b.allowedTopics {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("mq: invalid allowed_topics pattern %q", p)
+		}
+	}
+
+	if b.broker == nil {
+		broker, err := b.newBroker(ctx, config)
+		if err != nil {
+			return err
+		}
+		b.broker = broker
+	}
+	if !b.readOnly {
+		b.registerPublish()
+	}
+	b.logger.Info("mq backend initialized",
+		"broker", b.kind,
+		"read_only", b.readOnly,
+		"max_messages", b.maxMessages)
+	return nil
+}
+
+// newBroker creates the configured broker
+func (b *Backend) newBroker(ctx context.Context, config map[string]interface{}) (Broker, error) {
+	b.kind = backend.StringConfig(config, "broker", "")
+	url := backend.StringConfig(config, "url", "")
+	username := backend.StringConfig(config, "username", "")
+	password := backend.StringConfig(config, "password", "")
+
+	switch b.kind {
+	case "kafka":
+		broker := &KafkaBroker{BaseURL: url}
+		if resourceName := backend.StringConfig(config, "auth_resource", ""); resourceName != "" {
+			resource, err := b.resource(ctx, backend.StringConfig(config, "auth_provider", ""), resourceName)
+			if err != nil {
+				return nil, fmt.Errorf("mq: auth resource %s: %w", resourceName, err)
+			}
+			authenticated, baseURL, err := auth.ResourceHTTPClient(resource)
+			if err != nil {
+				resource.Close()
+				return nil, fmt.Errorf("mq: auth resource %s: %w", resourceName, err)
+			}
+			broker.Client = b.HTTPClient(authenticated)
+			if broker.BaseURL == "" {
+				broker.BaseURL = baseURL
+			}
+		} else if username != "" {
+			broker.Client = b.HTTPClient(&http.Client{Transport: basicAuth{username: username, password: password}})
+		} else {
+			broker.Client = b.HTTPClient(&http.Client{})
+		}
+		if broker.BaseURL == "" {
+			return nil, errors.New("mq: kafka needs the url of a REST proxy")
+		}
+		return broker, nil
+	case "nats":
+		jetstream, _ := config["jetstream"].(bool)
+		return NewNATSBroker(NATSConfig{
+			URL:       url,
+			Username:  username,
+			Password:  password,
+			Token:     backend.StringConfig(config, "token", ""),
+			JetStream: jetstream,
+			Name:      "mcp-mq",
+		})
+	case "":
+		return nil, errors.New("mq: broker is required (kafka or nats)")
+	}
+	return nil, fmt.Errorf("mq: unknown broker %q (kafka or nats)", b.kind)
+}
+
+// resource gets an auth resource
+func (b *Backend) resource(ctx context.Context, provider, name string) (auth.Resource, error) {
+	if provider == "" {
+		if p := b.GetAuthProvider(); p != nil {
+			return p.GetResource(ctx, name)
+		}
+		provider = "default"
+	}
+	return b.GetAuthenticatedResource(ctx, provider, name)
+}
+
+// basicAuth adds HTTP basic credentials to requests
+type basicAuth struct {
+	username, password string
+}
+
+func (t basicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Readiness pings the broker.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	r.RegisterReadiness("mq:broker", func(ctx context.Context) error {
+		if b.broker == nil {
+			return backend.ErrNotInitialized
+		}
+		return b.broker.Ping(ctx)
+	})
+}
+
+// Close releases the broker
+func (b *Backend) Close() error {
+	if b.broker != nil {
+		b.broker.Close()
+	}
+	return b.BaseBackend.Close()
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type publishArgs struct {
+	Topic     string            `json:"topic" description:"Topic (Kafka) or subject (NATS) to publish to"`
+	Value     string            `json:"value" description:"Message payload"`
+	Key       string            `json:"key,omitempty" description:"Kafka message key, which chooses the partition"`
+	Headers   map[string]string `json:"headers,omitempty" description:"Message headers (NATS only)"`
+	Partition *int              `json:"partition,omitempty" jsonschema:"minimum=0" description:"Kafka partition, instead of the key's"`
+}
+
+type consumeArgs struct {
+	Topic       string `json:"topic" description:"Topic (Kafka) or subject (NATS, wildcards allowed) to consume"`
+	Group       string `json:"group,omitempty" description:"Consumer group (Kafka) or durable consumer (JetStream); default: the configured group"`
+	From        string `json:"from,omitempty" jsonschema:"enum=latest|earliest|offset,default=latest" description:"Where to start: new messages, the oldest kept, or offset"`
+	Offset      int64  `json:"offset,omitempty" jsonschema:"minimum=0" description:"First offset (Kafka) or stream sequence (JetStream) when from is offset"`
+	Partition   int    `json:"partition,omitempty" jsonschema:"minimum=0" description:"Kafka partition of offset"`
+	Ack         string `json:"ack,omitempty" jsonschema:"enum=auto|none,default=auto" description:"auto acknowledges each message once emitted; none leaves them for the group to see again"`
+	MaxMessages int    `json:"max_messages,omitempty" jsonschema:"minimum=1" description:"Stop after this many messages (default and cap: the configured max_messages)"`
+}
+
+func (b *Backend) registerTools() {
+	consume := backend.NewTool("consume_topic").
+		Description("Stream a topic's messages until cancelled, max_messages is reached or the consume timeout ends. Messages are emitted one by one.").
+		ParamsFromStruct(consumeArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(consume, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[consumeArgs](consume, args)
+		if err != nil {
+			return err
+		}
+		return b.consume(ctx, in, emit)
+	})
+}
+
+func (b *Backend) registerPublish() {
+	backend.RegisterTypedTool(b, backend.NewTool("publish_message").
+		Description("Publish a message to a topic. Returns where it was stored, if the broker keeps messages.").
+		ParamsFromStruct(publishArgs{}).
+		NonCacheable().
+		Build(), b.publish)
+}
+
+// checkTopic denies topics matching none of allowed_topics
+func (b *Backend) checkTopic(tool, topic string) error {
+	if strings.TrimSpace(topic) == "" {
+		return &backend.ArgumentError{Tool: tool, Fields: []backend.FieldError{{Field: "topic", Message: "is required"}}}
+	}
+	if len(b.allowedTopics) == 0 {
+		return nil
+	}
+	for _, p := range b.allowedTopics {
+		if ok, _ := path.Match(p, topic); ok {
+			return nil
+		}
+	}
+	return mcperr.PermissionDenied("topic %s is not in allowed_topics", topic).
+		WithDetail("policy", "allowed_topics")
+}
+
+// publish handles publish_message
+func (b *Backend) publish(ctx context.Context, in publishArgs) (*PublishResult, error) {
+	if b.broker == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	if err := b.checkTopic("publish_message", in.Topic); err != nil {
+		return nil, err
+	}
+	if len(in.Value) > b.maxMessageBytes {
+		return nil, &backend.ArgumentError{Tool: "publish_message", Fields: []backend.FieldError{{
+			Field: "value", Message: fmt.Sprintf("is %d bytes, more than max_message_bytes %d", len(in.Value), b.maxMessageBytes),
+		}}}
+	}
+	result, err := b.broker.Publish(ctx, Message{Topic: in.Topic, Key: in.Key, Value: in.Value, Headers: in.Headers, Partition: in.Partition})
+	if errors.Is(err, ErrNoHeaders) {
+		return nil, &backend.ArgumentError{Tool: "publish_message", Fields: []backend.FieldError{{Field: "headers", Message: err.Error()}}}
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.logger.Debug("message published", "topic", in.Topic, "bytes", len(in.Value))
+	return result, nil
+}
+
+// consume handles consume_topic, emitting one Message per message
+func (b *Backend) consume(ctx context.Context, in consumeArgs, emit backend.StreamingEmitter) error {
+	if b.broker == nil {
+		return backend.ErrNotInitialized
+	}
+	if err := b.checkTopic("consume_topic", in.Topic); err != nil {
+		return err
+	}
+	max := b.maxMessages
+	if in.MaxMessages > 0 && in.MaxMessages < max {
+		max = in.MaxMessages
+	}
+	opts := ConsumeOptions{
+		Topic:     in.Topic,
+		Group:     in.Group,
+		From:      in.From,
+		Offset:    in.Offset,
+		Partition: in.Partition,
+		Ack:       in.Ack,
+	}
+	if opts.Group == "" {
+		opts.Group = b.group
+	}
+	if opts.From == "" {
+		opts.From = FromLatest
+	}
+	if opts.Ack == "" {
+		opts.Ack = AckAuto
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.consumeTimeout)
+	defer cancel()
+
+	count := 0
+	var emitErr error
+	err := b.broker.Consume(ctx, opts, func(msg Message) error {
+		if emitErr = emit.EmitData(msg); emitErr != nil {
+			return emitErr
+		}
+		count++
+		if count >= max {
+			return errStop
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errStop):
+		return emit.EmitProgress(int64(count), int64(max), fmt.Sprintf("stopped after %d messages", count))
+	case emitErr != nil:
+		return emitErr
+	case errors.Is(err, ErrNoOffsets):
+		return &backend.ArgumentError{Tool: "consume_topic", Fields: []backend.FieldError{{
+			Field: "from", Message: fmt.Sprintf("must be latest: %v (enable jetstream)", err),
+		}}}
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		// The consume timeout ends a stream; that is not a failure
+		b.logger.Debug("consume timed out", "topic", in.Topic, "messages", count)
+		return emit.EmitProgress(int64(count), int64(max), fmt.Sprintf("consume stopped after %s", b.consumeTimeout))
+	}
+	return err
+}
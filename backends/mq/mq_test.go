@@ -0,0 +1,693 @@
+package mq_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backends/mq"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+type captureEmitter struct {
+	ctx      context.Context
+	mu       sync.Mutex
+	messages []mq.Message
+	progress []string
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.messages = append(e.messages, data.(mq.Message))
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error {
+	e.progress = append(e.progress, message)
+	return nil
+}
+func (e *captureEmitter) Context() context.Context { return e.ctx }
+
+// ============================================================
+// Fake NATS server
+// ============================================================
+
+// fakeNATS speaks enough of the NATS protocol for the backend: core
+// publish and subscribe, and a JetStream stream "ORDERS" on orders.>
+// with pull consumers
+type fakeNATS struct {
+	ln        net.Listener
+	token     string
+	jetstream bool
+
+	mu        sync.Mutex
+	conns     []*natsClient
+	stored    []natsStored
+	consumers map[string]*natsConsumer
+	pulls     []*natsPull
+	acks      []int
+}
+
+type natsClient struct {
+	conn net.Conn
+	mu   sync.Mutex
+	subs map[int]string
+}
+
+type natsStored struct {
+	subject string
+	data    []byte
+}
+
+type natsConsumer struct {
+	next     int // index into stored
+	ackNone  bool
+	filter   string
+	received int
+}
+
+type natsPull struct {
+	client   *natsClient
+	reply    string
+	consumer string
+	left     int
+}
+
+func newFakeNATS(t *testing.T, jetstream bool) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeNATS{ln: ln, token: "s3cret", jetstream: jetstream, consumers: map[string]*natsConsumer{}}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return f
+}
+
+func (f *fakeNATS) url() string { return "nats://" + f.ln.Addr().String() }
+
+// subscribers returns the number of subscriptions to subject
+func (f *fakeNATS) subscribers(subject string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.conns {
+		c.mu.Lock()
+		for _, s := range c.subs {
+			if s == subject {
+				n++
+			}
+		}
+		c.mu.Unlock()
+	}
+	return n
+}
+
+func (f *fakeNATS) ackedSeqs() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int(nil), f.acks...)
+}
+
+func (c *natsClient) send(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.conn, format, args...)
+}
+
+// deliver sends a message to the client's subscription matching subject
+func (c *natsClient) deliver(subject, match, reply, headers string, data []byte) {
+	c.mu.Lock()
+	sid := -1
+	for id, s := range c.subs {
+		if subjectMatch(s, match) {
+			sid = id
+		}
+	}
+	c.mu.Unlock()
+	if sid < 0 {
+		return
+	}
+	if reply != "" {
+		reply += " "
+	}
+	if headers != "" {
+		c.send("HMSG %s %d %s%d %d\r\n%s%s\r\n", subject, sid, reply, len(headers), len(headers)+len(data), headers, data)
+		return
+	}
+	c.send("MSG %s %d %s%d\r\n%s\r\n", subject, sid, reply, len(data), data)
+}
+
+func subjectMatch(pattern, subject string) bool {
+	p, s := strings.Split(pattern, "."), strings.Split(subject, ".")
+	for i, token := range p {
+		if token == ">" {
+			return len(s) > i
+		}
+		if i >= len(s) || (token != "*" && token != s[i]) {
+			return false
+		}
+	}
+	return len(p) == len(s)
+}
+
+func (f *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	c := &natsClient{conn: conn, subs: map[int]string{}}
+	c.send("INFO {\"headers\":true,\"max_payload\":1048576,\"auth_required\":true}\r\n")
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch op {
+		case "CONNECT":
+			var opts struct {
+				Token string `json:"auth_token"`
+			}
+			json.Unmarshal([]byte(args), &opts)
+			if opts.Token != f.token {
+				c.send("-ERR 'Authorization Violation'\r\n")
+				return
+			}
+			f.mu.Lock()
+			f.conns = append(f.conns, c)
+			f.mu.Unlock()
+		case "PING":
+			c.send("PONG\r\n")
+		case "SUB":
+			fields := strings.Fields(args)
+			sid, _ := strconv.Atoi(fields[len(fields)-1])
+			c.mu.Lock()
+			c.subs[sid] = fields[0]
+			c.mu.Unlock()
+		case "PUB", "HPUB":
+			fields := strings.Fields(args)
+			reply, headerSize := "", 0
+			sizes := 1
+			if op == "HPUB" {
+				sizes = 2
+			}
+			if len(fields) == 1+sizes+1 {
+				reply = fields[1]
+			}
+			if op == "HPUB" {
+				headerSize, _ = strconv.Atoi(fields[len(fields)-2])
+			}
+			total, _ := strconv.Atoi(fields[len(fields)-1])
+			buf := make([]byte, total+2)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			f.publish(c, fields[0], reply, string(buf[:headerSize]), buf[headerSize:total])
+		}
+	}
+}
+
+// publish routes a client's message
+func (f *fakeNATS) publish(from *natsClient, subject, reply, headers string, data []byte) {
+	switch {
+	case strings.HasPrefix(subject, "$JS.API."):
+		// Pull requests are answered by the messages they get
+		if answer := f.api(from, subject, reply, data); answer != nil {
+			from.deliver(reply, reply, "", "", answer)
+		}
+		return
+	case strings.HasPrefix(subject, "$JS.ACK."):
+		seq, _ := strconv.Atoi(strings.Split(subject, ".")[5])
+		f.mu.Lock()
+		f.acks = append(f.acks, seq)
+		f.mu.Unlock()
+		return
+	}
+
+	f.mu.Lock()
+	conns := append([]*natsClient(nil), f.conns...)
+	stream := f.jetstream && subjectMatch("orders.>", subject)
+	if stream {
+		f.stored = append(f.stored, natsStored{subject: subject, data: data})
+	}
+	seq := len(f.stored)
+	f.mu.Unlock()
+
+	for _, c := range conns {
+		c.deliver(subject, subject, "", headers, data)
+	}
+	if reply != "" && !stream {
+		from.deliver(reply, reply, "", "NATS/1.0 503\r\n\r\n", nil) // no responders
+	}
+	if stream {
+		if reply != "" {
+			from.deliver(reply, reply, "", "", []byte(fmt.Sprintf(`{"stream":"ORDERS","seq":%d}`, seq)))
+		}
+		f.servePulls()
+	}
+}
+
+// api answers a JetStream API request
+func (f *fakeNATS) api(from *natsClient, subject, reply string, data []byte) []byte {
+	if !f.jetstream {
+		return []byte(`{"error":{"code":503,"description":"JetStream not enabled"}}`)
+	}
+	tokens := strings.Split(subject, ".")
+	switch {
+	case subject == "$JS.API.STREAM.NAMES":
+		var req struct {
+			Subject string `json:"subject"`
+		}
+		json.Unmarshal(data, &req)
+		if subjectMatch("orders.>", req.Subject) || req.Subject == "orders.>" {
+			return []byte(`{"streams":["ORDERS"]}`)
+		}
+		return []byte(`{"streams":null}`)
+	case strings.HasPrefix(subject, "$JS.API.CONSUMER.CREATE.") || strings.HasPrefix(subject, "$JS.API.CONSUMER.DURABLE.CREATE."):
+		var req struct {
+			Config struct {
+				Durable  string `json:"durable_name"`
+				Deliver  string `json:"deliver_policy"`
+				StartSeq int    `json:"opt_start_seq"`
+				Ack      string `json:"ack_policy"`
+				Filter   string `json:"filter_subject"`
+			} `json:"config"`
+		}
+		json.Unmarshal(data, &req)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		name := req.Config.Durable
+		if name == "" {
+			name = fmt.Sprintf("eph%d", len(f.consumers))
+		}
+		consumer := &natsConsumer{ackNone: req.Config.Ack == "none", filter: req.Config.Filter}
+		switch req.Config.Deliver {
+		case "all":
+		case "by_start_sequence":
+			consumer.next = req.Config.StartSeq - 1
+		default:
+			consumer.next = len(f.stored)
+		}
+		f.consumers[name] = consumer
+		return []byte(fmt.Sprintf(`{"name":%q}`, name))
+	case strings.HasPrefix(subject, "$JS.API.CONSUMER.MSG.NEXT."):
+		var req struct {
+			Batch int `json:"batch"`
+		}
+		json.Unmarshal(data, &req)
+		f.mu.Lock()
+		f.pulls = append(f.pulls, &natsPull{client: from, reply: reply, consumer: tokens[len(tokens)-1], left: req.Batch})
+		f.mu.Unlock()
+		go f.servePulls()
+		return nil
+	}
+	return []byte(`{"error":{"code":400,"description":"unsupported"}}`)
+}
+
+// servePulls hands stored messages to waiting pull requests
+func (f *fakeNATS) servePulls() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.pulls {
+		consumer := f.consumers[p.consumer]
+		for p.left > 0 && consumer.next < len(f.stored) {
+			m := f.stored[consumer.next]
+			consumer.next++
+			if !subjectMatch(consumer.filter, m.subject) && consumer.filter != m.subject {
+				continue
+			}
+			consumer.received++
+			p.left--
+			ack := fmt.Sprintf("$JS.ACK.ORDERS.%s.1.%d.%d.%d.0", p.consumer, consumer.next, consumer.received, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).UnixNano())
+			p.client.deliver(m.subject, p.reply, ack, "", m.data)
+		}
+	}
+}
+
+// ============================================================
+// Fake Kafka REST proxy
+// ============================================================
+
+// fakeProxy serves one partition per topic
+type fakeProxy struct {
+	mu        sync.Mutex
+	topics    map[string][]kafkaStored
+	positions map[string]int // consumer instance -> next offset
+	commits   []int64
+	deleted   []string
+}
+
+type kafkaStored struct {
+	key, value string
+}
+
+func newFakeProxy(t *testing.T) (*fakeProxy, string) {
+	f := &fakeProxy{topics: map[string][]kafkaStored{}, positions: map[string]int{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv.URL
+}
+
+func (f *fakeProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, ok := r.BasicAuth(); !ok || user != "svc" || pass != "pw" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error_code":40101,"message":"Unauthorized"}`))
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	switch {
+	case r.URL.Path == "/topics":
+		json.NewEncoder(w).Encode([]string{"orders"})
+	case parts[0] == "topics" && len(parts) == 2:
+		if r.Header.Get("Content-Type") != "application/vnd.kafka.binary.v2+json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		record := body["records"].([]interface{})[0].(map[string]interface{})
+		decode := func(v interface{}) string {
+			s, _ := v.(string)
+			data, _ := base64.StdEncoding.DecodeString(s)
+			return string(data)
+		}
+		f.topics[parts[1]] = append(f.topics[parts[1]], kafkaStored{key: decode(record["key"]), value: decode(record["value"])})
+		fmt.Fprintf(w, `{"offsets":[{"partition":0,"offset":%d}]}`, len(f.topics[parts[1]])-1)
+	case parts[0] == "consumers" && len(parts) == 2:
+		instance := parts[1] + "/" + body["name"].(string)
+		f.positions[instance] = 0
+		if body["auto.offset.reset"] == "latest" {
+			f.positions[instance] = -1 // set at subscription
+		}
+		fmt.Fprintf(w, `{"instance_id":%q}`, body["name"])
+	case parts[0] == "consumers" && len(parts) >= 4:
+		instance := parts[1] + "/" + parts[3]
+		action := ""
+		if len(parts) == 5 {
+			action = parts[4]
+		}
+		switch {
+		case r.Method == http.MethodDelete:
+			f.deleted = append(f.deleted, instance)
+			w.WriteHeader(http.StatusNoContent)
+		case action == "subscription":
+			if f.positions[instance] < 0 {
+				f.positions[instance] = len(f.topics["orders"])
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case action == "assignments":
+			w.WriteHeader(http.StatusNoContent)
+		case action == "positions":
+			offset := body["offsets"].([]interface{})[0].(map[string]interface{})["offset"].(float64)
+			f.positions[instance] = int(offset)
+			w.WriteHeader(http.StatusNoContent)
+		case action == "offsets":
+			offset := body["offsets"].([]interface{})[0].(map[string]interface{})["offset"].(float64)
+			f.commits = append(f.commits, int64(offset))
+			w.WriteHeader(http.StatusNoContent)
+		case action == "records":
+			var records []map[string]interface{}
+			stored := f.topics["orders"]
+			for i := f.positions[instance]; i < len(stored); i++ {
+				records = append(records, map[string]interface{}{
+					"topic": "orders", "partition": 0, "offset": i,
+					"key":   base64.StdEncoding.EncodeToString([]byte(stored[i].key)),
+					"value": base64.StdEncoding.EncodeToString([]byte(stored[i].value)),
+				})
+			}
+			if f.positions[instance] < len(stored) {
+				f.positions[instance] = len(stored)
+			}
+			w.Header().Set("Content-Type", "application/vnd.kafka.binary.v2+json")
+			json.NewEncoder(w).Encode(records)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// ============================================================
+// Tests
+// ============================================================
+
+func newBackend(t *testing.T, config map[string]interface{}) *mq.Backend {
+	t.Helper()
+	b := mq.New()
+	if err := b.Initialize(context.Background(), config); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func publish(t *testing.T, b *mq.Backend, args map[string]interface{}) *mq.PublishResult {
+	t.Helper()
+	result, err := b.CallTool(context.Background(), "publish_message", args)
+	if err != nil {
+		t.Fatalf("publish_message error = %v", err)
+	}
+	return result.(*mq.PublishResult)
+}
+
+func TestNATS_PublishAndConsume(t *testing.T) {
+	f := newFakeNATS(t, false)
+	b := newBackend(t, map[string]interface{}{"broker": "nats", "url": f.url(), "token": "s3cret"})
+
+	emit := &captureEmitter{ctx: context.Background()}
+	done := make(chan error, 1)
+	go func() {
+		done <- b.CallStreamingTool(context.Background(), "consume_topic",
+			map[string]interface{}{"topic": "orders.*", "max_messages": 2}, emit)
+	}()
+	for deadline := time.Now().Add(2 * time.Second); f.subscribers("orders.*") == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("consumer did not subscribe")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for i := 1; i <= 3; i++ {
+		publish(t, b, map[string]interface{}{
+			"topic": "orders.new", "value": fmt.Sprintf("order %d", i), "headers": map[string]interface{}{"Source": "test"},
+		})
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("consume_topic error = %v", err)
+	}
+	if len(emit.messages) != 2 {
+		t.Fatalf("got %d messages, want the cap of 2", len(emit.messages))
+	}
+	if m := emit.messages[0]; m.Topic != "orders.new" || m.Value != "order 1" || m.Headers["Source"] != "test" {
+		t.Errorf("first message = %+v", m)
+	}
+	if len(emit.progress) != 1 || emit.progress[0] != "stopped after 2 messages" {
+		t.Errorf("progress = %v", emit.progress)
+	}
+}
+
+func TestNATS_CoreHasNoOffsets(t *testing.T) {
+	f := newFakeNATS(t, false)
+	b := newBackend(t, map[string]interface{}{"broker": "nats", "url": f.url(), "token": "s3cret"})
+
+	err := b.CallStreamingTool(context.Background(), "consume_topic",
+		map[string]interface{}{"topic": "orders.new", "from": "earliest"}, &captureEmitter{ctx: context.Background()})
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) || argErr.Fields[0].Field != "from" {
+		t.Errorf("error = %v, want an argument error on from", err)
+	}
+}
+
+func TestNATS_AuthorizationViolation(t *testing.T) {
+	f := newFakeNATS(t, false)
+	b := newBackend(t, map[string]interface{}{"broker": "nats", "url": f.url(), "token": "wrong"})
+
+	_, err := b.CallTool(context.Background(), "publish_message", map[string]interface{}{"topic": "orders.new", "value": "x"})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("error = %v, want permission denied", err)
+	}
+}
+
+func TestNATS_JetStream(t *testing.T) {
+	f := newFakeNATS(t, true)
+	b := newBackend(t, map[string]interface{}{
+		"broker": "nats", "url": "nats://s3cret@" + f.ln.Addr().String(), "jetstream": true,
+	})
+
+	for i := 1; i <= 3; i++ {
+		result := publish(t, b, map[string]interface{}{"topic": "orders.new", "value": fmt.Sprintf("order %d", i)})
+		if result.Stream != "ORDERS" || result.Offset == nil || *result.Offset != int64(i) {
+			t.Fatalf("publish result = %+v", result)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	emit := &captureEmitter{ctx: ctx}
+	err := b.CallStreamingTool(ctx, "consume_topic", map[string]interface{}{
+		"topic": "orders.new", "from": "offset", "offset": 2, "max_messages": 2,
+	}, emit)
+	if err != nil {
+		t.Fatalf("consume_topic error = %v", err)
+	}
+	if len(emit.messages) != 2 || emit.messages[0].Value != "order 2" || *emit.messages[1].Offset != 3 {
+		t.Fatalf("messages = %+v", emit.messages)
+	}
+	if m := emit.messages[0]; m.Topic != "orders.new" || m.Time == nil || m.Time.Year() != 2024 {
+		t.Errorf("message metadata = %+v", m)
+	}
+	if acks := f.ackedSeqs(); len(acks) != 1 || acks[0] != 2 {
+		// The cap stops the stream before the last message is acked
+		t.Errorf("acked sequences = %v, want [2]", acks)
+	}
+
+	err = b.CallStreamingTool(ctx, "consume_topic", map[string]interface{}{
+		"topic": "orders.new", "from": "earliest", "ack": "none", "max_messages": 3,
+	}, &captureEmitter{ctx: ctx})
+	if err != nil {
+		t.Fatalf("consume_topic error = %v", err)
+	}
+	if acks := f.ackedSeqs(); len(acks) != 1 {
+		t.Errorf("ack none acked %v", acks[1:])
+	}
+
+	_, err = b.CallTool(ctx, "publish_message", map[string]interface{}{"topic": "payments", "value": "x"})
+	if mcperr.CodeOf(err) != mcperr.CodeNotFound {
+		t.Errorf("publish outside any stream: error = %v, want not found", err)
+	}
+}
+
+func TestKafka_PublishAndConsume(t *testing.T) {
+	f, url := newFakeProxy(t)
+	b := newBackend(t, map[string]interface{}{
+		"broker": "kafka", "url": url, "username": "svc", "password": "pw", "group": "agents",
+	})
+
+	for i := 0; i < 3; i++ {
+		result := publish(t, b, map[string]interface{}{"topic": "orders", "key": "k", "value": fmt.Sprintf("order %d", i)})
+		if result.Offset == nil || *result.Offset != int64(i) || result.Partition == nil {
+			t.Fatalf("publish result = %+v", result)
+		}
+	}
+
+	emit := &captureEmitter{ctx: context.Background()}
+	err := b.CallStreamingTool(context.Background(), "consume_topic",
+		map[string]interface{}{"topic": "orders", "from": "earliest", "max_messages": 2}, emit)
+	if err != nil {
+		t.Fatalf("consume_topic error = %v", err)
+	}
+	if len(emit.messages) != 2 || emit.messages[1].Value != "order 1" || emit.messages[1].Key != "k" {
+		t.Fatalf("messages = %+v", emit.messages)
+	}
+	if *emit.messages[1].Offset != 1 || *emit.messages[1].Partition != 0 {
+		t.Errorf("location = %d/%d", *emit.messages[1].Partition, *emit.messages[1].Offset)
+	}
+	f.mu.Lock()
+	commits, deleted := f.commits, f.deleted
+	f.mu.Unlock()
+	if len(commits) != 1 || commits[0] != 1 {
+		t.Errorf("commits = %v, want [1] (the next offset after the first)", commits)
+	}
+	if len(deleted) != 1 || !strings.HasPrefix(deleted[0], "agents/") {
+		t.Errorf("deleted instances = %v", deleted)
+	}
+
+	_, err = b.CallTool(context.Background(), "publish_message", map[string]interface{}{
+		"topic": "orders", "value": "x", "headers": map[string]interface{}{"a": "b"},
+	})
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) || argErr.Fields[0].Field != "headers" {
+		t.Errorf("headers error = %v, want an argument error", err)
+	}
+}
+
+func TestKafka_Unauthorized(t *testing.T) {
+	_, url := newFakeProxy(t)
+	b := newBackend(t, map[string]interface{}{"broker": "kafka", "url": url, "username": "svc", "password": "nope"})
+
+	_, err := b.CallTool(context.Background(), "publish_message", map[string]interface{}{"topic": "orders", "value": "x"})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("error = %v, want permission denied", err)
+	}
+}
+
+func TestConsumeTimeout(t *testing.T) {
+	f := newFakeNATS(t, false)
+	b := newBackend(t, map[string]interface{}{
+		"broker": "nats", "url": f.url(), "token": "s3cret", "consume_timeout": "100ms",
+	})
+
+	emit := &captureEmitter{ctx: context.Background()}
+	if err := b.CallStreamingTool(context.Background(), "consume_topic", map[string]interface{}{"topic": "idle"}, emit); err != nil {
+		t.Fatalf("consume_topic error = %v, want a normal end", err)
+	}
+	if len(emit.progress) != 1 || !strings.Contains(emit.progress[0], "consume stopped after 100ms") {
+		t.Errorf("progress = %v", emit.progress)
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	f := newFakeNATS(t, false)
+	b := newBackend(t, map[string]interface{}{
+		"broker": "nats", "url": f.url(), "token": "s3cret",
+		"allowed_topics": []interface{}{"orders.*"}, "max_message_bytes": 4,
+	})
+
+	_, err := b.CallTool(context.Background(), "publish_message", map[string]interface{}{"topic": "payments.new", "value": "x"})
+	if e, ok := mcperr.As(err); !ok || e.Code != mcperr.CodePermissionDenied || e.Details["policy"] != "allowed_topics" {
+		t.Errorf("error = %v, want an allowed_topics denial", err)
+	}
+	err = b.CallStreamingTool(context.Background(), "consume_topic",
+		map[string]interface{}{"topic": ">"}, &captureEmitter{ctx: context.Background()})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("consume error = %v, want permission denied", err)
+	}
+	_, err = b.CallTool(context.Background(), "publish_message", map[string]interface{}{"topic": "orders.new", "value": "too long"})
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) || argErr.Fields[0].Field != "value" {
+		t.Errorf("error = %v, want an argument error on value", err)
+	}
+
+	readOnly := newBackend(t, map[string]interface{}{"broker": "nats", "url": f.url(), "read_only": true})
+	if _, err := readOnly.CallTool(context.Background(), "publish_message", map[string]interface{}{"topic": "orders.new", "value": "x"}); err == nil {
+		t.Error("read_only backend served publish_message")
+	}
+}
+
+func TestInitialize_Errors(t *testing.T) {
+	for name, config := range map[string]map[string]interface{}{
+		"no broker":      {},
+		"unknown broker": {"broker": "rabbitmq"},
+		"kafka no url":   {"broker": "kafka"},
+		"bad nats url":   {"broker": "nats", "url": "http://localhost"},
+		"bad pattern":    {"broker": "nats", "allowed_topics": []interface{}{"["}},
+		"bad timeout":    {"broker": "nats", "consume_timeout": "soon"},
+	} {
+		if err := mq.New().Initialize(context.Background(), config); err == nil {
+			t.Errorf("%s: Initialize() succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	goplugin "plugin"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// GoPluginSymbol is the constructor a Go plugin exports
+const GoPluginSymbol = "NewBackend"
+
+// LoadGoPlugin opens a Go plugin and creates its backend
+// The plugin must be built with the same Go version and framework version
+// as the server (go build -buildmode=plugin).
+func LoadGoPlugin(path string) (backend.ServerBackend, error) {
+	p, err := goplugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin: open %s: %w", path, err)
+	}
+	sym, err := p.Lookup(GoPluginSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin: %s: %w", path, err)
+	}
+
+	switch newBackend := sym.(type) {
+	case func() backend.ServerBackend:
+		return newBackend(), nil
+	case *func() backend.ServerBackend:
+		return (*newBackend)(), nil
+	}
+	return nil, fmt.Errorf("plugin: %s: %s is %T, want func() backend.ServerBackend", path, GoPluginSymbol, sym)
+}
+
+// loadGoPlugin initializes a Go plugin's backend and serves its tools
+func (b *Backend) loadGoPlugin(ctx context.Context) error {
+	inner, err := LoadGoPlugin(b.config.Path)
+	if err != nil {
+		return err
+	}
+	return b.mount(ctx, inner)
+}
+
+// mount initializes inner and serves its tools through the backend
+func (b *Backend) mount(ctx context.Context, inner backend.ServerBackend) error {
+	if err := inner.Initialize(ctx, b.config.Config); err != nil {
+		return fmt.Errorf("plugin %s: %w", inner.Name(), err)
+	}
+	b.inner = inner
+
+	for _, tool := range inner.ListTools() {
+		name := tool.Name
+		if inner.IsStreamingTool(name) {
+			b.AddStreamingTool(tool, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+				return inner.CallStreamingTool(ctx, name, args, emit)
+			})
+			continue
+		}
+		b.AddTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return inner.CallTool(ctx, name, args)
+		})
+	}
+
+	b.logger.Info("plugin loaded", "path", b.config.Path, "backend", inner.Name(), "tools", len(inner.ListTools()))
+	return nil
+}
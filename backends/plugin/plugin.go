@@ -0,0 +1,423 @@
+// Package plugin loads backends shipped separately from the server binary
+//
+// A plugin is either an executable speaking MCP over stdio (any server
+// built with this framework and the stdio transport qualifies) or a Go
+// plugin (.so built with -buildmode=plugin) exporting
+//
+//	func NewBackend() backend.ServerBackend
+//
+// The plugin's tools are served as the backend's own. Executable plugins
+// are restarted when they exit and hot-swapped when the binary changes:
+// the new process is started and its tools listed before the old one is
+// stopped, and clients are told to re-fetch tools/list. Go plugins can't
+// be unloaded, so they are loaded once.
+//
+// Importing the package registers the backend as "plugin":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/plugin"
+//
+//	# config.yaml
+//	backend:
+//	  type: plugin
+//	  config:
+//	    path: ./plugins/weather-server
+//	    args: [--config, weather.yaml]
+//	    env: {WEATHER_API_KEY: "${secret:env:WEATHER_API_KEY}"}
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backend/proxy"
+	"github.com/SaherElMasry/go-mcp-framework/client"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// Plugin kinds
+const (
+	KindExec = "exec"
+	KindGo   = "go"
+)
+
+// DefaultWatchInterval is how often an executable plugin is checked for
+// changes and restarted after exiting
+const DefaultWatchInterval = 2 * time.Second
+
+// ErrPluginExited is returned by calls to a plugin process that has exited
+var ErrPluginExited = errors.New("plugin process exited")
+
+// startTimeout bounds a starting plugin's answer to tools/list
+const startTimeout = 10 * time.Second
+
+func init() {
+	backend.Register("plugin", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Config configures a plugin
+type Config struct {
+	// Path is the plugin executable or .so file
+	Path string
+
+	// Kind is KindExec or KindGo (default: KindGo for .so files)
+	Kind string
+
+	// Args and Env are passed to an executable plugin
+	Args []string
+	Env  map[string]string
+
+	// Config is passed to a Go plugin backend's Initialize
+	Config map[string]interface{}
+
+	// WatchInterval is the restart and change detection period of an
+	// executable plugin; negative disables both (default: DefaultWatchInterval)
+	WatchInterval time.Duration
+}
+
+// setDefaults fills in the kind and watch interval
+func (c *Config) setDefaults() {
+	if c.Kind == "" {
+		c.Kind = KindExec
+		if filepath.Ext(c.Path) == ".so" {
+			c.Kind = KindGo
+		}
+	}
+	if c.WatchInterval == 0 {
+		c.WatchInterval = DefaultWatchInterval
+	}
+}
+
+// parseConfig reads a Config from a backend config map
+func parseConfig(m map[string]interface{}) (Config, error) {
+	var config Config
+	config.Path, _ = m["path"].(string)
+	if config.Path == "" {
+		return config, errors.New("plugin: path is required")
+	}
+
+	config.Kind, _ = m["kind"].(string)
+	if config.Kind != "" && config.Kind != KindExec && config.Kind != KindGo {
+		return config, fmt.Errorf("plugin: unknown kind %q (use %q or %q)", config.Kind, KindExec, KindGo)
+	}
+
+	if args, ok := m["args"].([]interface{}); ok {
+		for _, arg := range args {
+			config.Args = append(config.Args, fmt.Sprint(arg))
+		}
+	}
+	if env, ok := m["env"].(map[string]interface{}); ok {
+		config.Env = make(map[string]string, len(env))
+		for k, v := range env {
+			config.Env[k] = fmt.Sprint(v)
+		}
+	}
+	config.Config, _ = m["config"].(map[string]interface{})
+
+	if s, ok := m["watch_interval"].(string); ok && s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return config, fmt.Errorf("plugin: invalid watch_interval: %w", err)
+		}
+		config.WatchInterval = d
+	}
+	return config, nil
+}
+
+// Backend serves the tools of a plugin
+type Backend struct {
+	*backend.BaseBackend
+
+	config Config
+	logger *slog.Logger
+
+	// Executable plugins
+	mu      sync.RWMutex
+	client  *client.Client
+	modTime time.Time
+	reload  chan struct{}
+
+	// Go plugins
+	inner backend.ServerBackend
+
+	// served are the plugin's tools as last registered
+	syncMu sync.Mutex
+	served map[string]backend.ToolDefinition
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates an unconfigured plugin backend; Initialize loads the plugin
+func New() *Backend {
+	return &Backend{
+		BaseBackend: backend.NewBaseBackend("plugin"),
+		logger:      slog.Default(),
+		reload:      make(chan struct{}, 1),
+	}
+}
+
+// NewWithConfig creates a plugin backend from a Config
+func NewWithConfig(config Config) *Backend {
+	b := New()
+	b.config = config
+	return b
+}
+
+// Initialize loads the plugin and registers its tools
+// config entries: path, kind, args, env, config (Go plugins) and
+// watch_interval; they are ignored when the backend was created with
+// NewWithConfig.
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	if b.config.Path == "" {
+		parsed, err := parseConfig(config)
+		if err != nil {
+			return err
+		}
+		b.config = parsed
+	}
+	b.config.setDefaults()
+
+	if b.config.Kind == KindGo {
+		return b.loadGoPlugin(ctx)
+	}
+
+	if err := b.swap(ctx); err != nil {
+		return err
+	}
+	if b.config.WatchInterval > 0 {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		b.cancel = cancel
+		b.wg.Add(1)
+		go b.watch(watchCtx)
+	}
+	return nil
+}
+
+// Close stops the plugin
+func (b *Backend) Close() error {
+	if b.cancel != nil {
+		b.cancel()
+		b.wg.Wait()
+	}
+	if b.inner != nil {
+		return b.inner.Close()
+	}
+
+	b.mu.Lock()
+	c := b.client
+	b.client = nil
+	b.mu.Unlock()
+	if c != nil {
+		return c.Close()
+	}
+	return nil
+}
+
+// ============================================================
+// Executable plugins
+// ============================================================
+
+// swap starts the plugin executable, syncs its tools and stops the
+// previous process
+func (b *Backend) swap(ctx context.Context) error {
+	info, err := os.Stat(b.config.Path)
+	if err != nil {
+		return fmt.Errorf("plugin: %w", err)
+	}
+
+	next, err := client.NewStdio(client.StdioConfig{
+		Command: b.config.Path,
+		Args:    b.config.Args,
+		Env:     b.config.Env,
+	}, b.logger)
+	if err != nil {
+		return fmt.Errorf("start plugin %s: %w", b.config.Path, err)
+	}
+	next.OnNotification(func(method string, params map[string]interface{}) {
+		if method == protocol.NotificationToolsListChanged {
+			b.requestReload()
+		}
+	})
+	listCtx, cancel := context.WithTimeout(ctx, startTimeout)
+	defer cancel()
+	if _, err := next.Initialize(listCtx); err != nil {
+		next.Close()
+		return fmt.Errorf("plugin %s: initialize: %w", b.config.Path, err)
+	}
+	tools, err := proxy.FetchTools(listCtx, next)
+	if err != nil {
+		next.Close()
+		return fmt.Errorf("plugin %s: tools/list: %w", b.config.Path, err)
+	}
+
+	b.mu.Lock()
+	previous := b.client
+	b.client = next
+	b.modTime = info.ModTime()
+	b.mu.Unlock()
+
+	b.syncTools(tools, b.callExec)
+
+	if previous != nil {
+		go previous.Close()
+	}
+	b.logger.Info("plugin loaded", "path", b.config.Path, "tools", len(tools))
+	return nil
+}
+
+// refresh re-lists the tools of the running plugin
+func (b *Backend) refresh(ctx context.Context) error {
+	b.mu.RLock()
+	c := b.client
+	b.mu.RUnlock()
+	if c == nil {
+		return ErrPluginExited
+	}
+
+	tools, err := proxy.FetchTools(ctx, c)
+	if err != nil {
+		return err
+	}
+	b.syncTools(tools, b.callExec)
+	return nil
+}
+
+// requestReload schedules a tools/list refresh (the plugin announced a
+// change)
+func (b *Backend) requestReload() {
+	select {
+	case b.reload <- struct{}{}:
+	default:
+	}
+}
+
+// watch restarts the plugin when it exits or its executable changes
+func (b *Backend) watch(ctx context.Context) {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.config.WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.reload:
+			if err := b.refresh(ctx); err != nil {
+				b.logger.Warn("plugin tools refresh failed", "path", b.config.Path, "error", err)
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		b.mu.RLock()
+		c, modTime := b.client, b.modTime
+		b.mu.RUnlock()
+
+		reason := ""
+		if info, err := os.Stat(b.config.Path); err == nil && !info.ModTime().Equal(modTime) {
+			reason = "executable changed"
+		} else if c == nil || !c.Alive() {
+			reason = "process exited"
+		}
+		if reason == "" {
+			continue
+		}
+
+		b.logger.Info("reloading plugin", "path", b.config.Path, "reason", reason)
+		if err := b.swap(ctx); err != nil {
+			b.logger.Warn("plugin reload failed", "path", b.config.Path, "error", err)
+		}
+	}
+}
+
+// callExec calls a tool of the executable plugin
+func (b *Backend) callExec(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	b.mu.RLock()
+	c := b.client
+	b.mu.RUnlock()
+	if c == nil || !c.Alive() {
+		return nil, mcperr.Upstream(ErrPluginExited, "plugin %s unavailable", b.config.Path)
+	}
+	return proxy.CallTool(ctx, c, name, args)
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+// callFunc calls a plugin tool by name
+type callFunc func(ctx context.Context, name string, args map[string]interface{}) (interface{}, error)
+
+// syncTools registers tools, replacing changed ones and removing those the
+// plugin no longer serves
+func (b *Backend) syncTools(tools []backend.ToolDefinition, call callFunc) {
+	b.syncMu.Lock()
+	defer b.syncMu.Unlock()
+
+	served := make(map[string]backend.ToolDefinition, len(tools))
+	for _, tool := range tools {
+		served[tool.Name] = tool
+		name := tool.Name
+		handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return call(ctx, name, args)
+		}
+
+		existing, ok := b.served[tool.Name]
+		switch {
+		case !ok:
+			b.AddTool(tool, handler)
+		case !sameTool(existing, tool):
+			b.ReplaceTool(tool, handler)
+		}
+	}
+
+	for name := range b.served {
+		if _, ok := served[name]; !ok {
+			b.RemoveTool(name)
+		}
+	}
+	b.served = served
+}
+
+// sameTool reports whether two definitions advertise the same tool
+func sameTool(a, b backend.ToolDefinition) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Readiness fails while an executable plugin isn't running.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	if reporter, ok := b.inner.(health.Reporter); ok {
+		reporter.RegisterHealthChecks(r)
+		return
+	}
+	if b.config.Kind != KindExec {
+		return
+	}
+	r.RegisterReadiness("plugin:"+strings.TrimSuffix(filepath.Base(b.config.Path), filepath.Ext(b.config.Path)), func(ctx context.Context) error {
+		b.mu.RLock()
+		c := b.client
+		b.mu.RUnlock()
+		if c == nil || !c.Alive() {
+			return ErrPluginExited
+		}
+		return nil
+	})
+}
@@ -0,0 +1,203 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backends/plugin"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/transport/stdio"
+)
+
+// The test binary doubles as the plugin: with PLUGIN_TEST_TOOLS set it
+// serves the comma-separated tools named in that file over stdio
+func TestMain(m *testing.M) {
+	if file := os.Getenv("PLUGIN_TEST_TOOLS"); file != "" {
+		servePlugin(file)
+		return
+	}
+	os.Exit(m.Run())
+}
+
+func servePlugin(file string) {
+	names, _ := os.ReadFile(file)
+	b := backend.NewBaseBackend("test-plugin")
+	for _, name := range strings.Split(strings.TrimSpace(string(names)), ",") {
+		name := name
+		tool := backend.NewTool(name).
+			Description("test tool "+name).
+			StringParam("text", "Text to echo", true).
+			Build()
+		b.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			switch args["text"] {
+			case "crash":
+				os.Exit(1)
+			case "missing":
+				return nil, mcperr.NotFound("no such thing")
+			case "fail":
+				return nil, backend.NewToolError("tool failed")
+			}
+			backend.EmitProgress(ctx, 1, 2, "halfway")
+			return backend.NewTextResult(name + ": " + args["text"].(string)), nil
+		})
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	stdio.NewStdioTransport(protocol.NewHandler(b, logger), logger).Run(context.Background())
+}
+
+// newPlugin copies the test binary so its modification time can change
+func newPlugin(t *testing.T, tools string) (*plugin.Backend, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(self)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "plugin")
+	if err := os.WriteFile(path, data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	toolsFile := filepath.Join(dir, "tools")
+	os.WriteFile(toolsFile, []byte(tools), 0o644)
+
+	b := plugin.NewWithConfig(plugin.Config{
+		Path:          path,
+		Env:           map[string]string{"PLUGIN_TEST_TOOLS": toolsFile},
+		WatchInterval: 20 * time.Millisecond,
+	})
+	if err := b.Initialize(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b, path, toolsFile
+}
+
+func toolNames(b backend.ServerBackend) string {
+	var names []string
+	for _, tool := range b.ListTools() {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func callText(t *testing.T, b backend.ServerBackend, tool, text string) (string, error) {
+	t.Helper()
+	result, err := b.CallTool(context.Background(), tool, map[string]interface{}{"text": text})
+	if err != nil {
+		return "", err
+	}
+	typed, ok := backend.AsToolResult(result)
+	if !ok || len(typed.Content) != 1 {
+		t.Fatalf("result = %#v", result)
+	}
+	return typed.Content[0].(backend.TextContent).Text, nil
+}
+
+func TestPlugin_CallsTools(t *testing.T) {
+	b, _, _ := newPlugin(t, "echo,shout")
+
+	if got := toolNames(b); got != "echo,shout" {
+		t.Fatalf("tools = %s", got)
+	}
+	tool, _ := b.GetTool("echo")
+	if len(tool.Parameters) != 1 || tool.Parameters[0].Name != "text" || !tool.Parameters[0].Required {
+		t.Errorf("parameters = %+v", tool.Parameters)
+	}
+
+	var progress []string
+	ctx := backend.WithProgressReporter(context.Background(), func(current, total int64, message string) error {
+		progress = append(progress, message)
+		return nil
+	})
+	result, err := b.CallTool(ctx, "echo", map[string]interface{}{"text": "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typed, _ := backend.AsToolResult(result); typed.Content[0].(backend.TextContent).Text != "echo: hi" {
+		t.Errorf("result = %+v", typed)
+	}
+	if len(progress) != 1 || progress[0] != "halfway" {
+		t.Errorf("progress = %v", progress)
+	}
+
+	if _, err := callText(t, b, "echo", "missing"); !errors.Is(err, mcperr.ErrNotFound) {
+		t.Errorf("categorized error = %v", err)
+	}
+	if _, err := callText(t, b, "echo", "fail"); err == nil {
+		t.Error("tool error not returned")
+	} else if toolErr, ok := backend.AsToolError(err); !ok || toolErr.ResultContent()[0].(backend.TextContent).Text != "tool failed" {
+		t.Errorf("tool error = %v", err)
+	}
+}
+
+func TestPlugin_RestartsAndHotSwaps(t *testing.T) {
+	b, path, toolsFile := newPlugin(t, "echo")
+
+	changed := make(chan struct{}, 10)
+	b.OnToolsChanged(func() { changed <- struct{}{} })
+
+	// A crashed plugin is restarted
+	if _, err := callText(t, b, "echo", "crash"); err == nil {
+		t.Fatal("crash not reported")
+	}
+	waitFor(t, func() bool {
+		text, err := callText(t, b, "echo", "again")
+		return err == nil && text == "echo: again"
+	})
+
+	// A changed executable is swapped in with its new tools
+	os.WriteFile(toolsFile, []byte("echo2,shout"), 0o644)
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(path, future, future)
+	waitFor(t, func() bool { return toolNames(b) == "echo2,shout" })
+
+	select {
+	case <-changed:
+	default:
+		t.Error("tool change not notified")
+	}
+	if text, err := callText(t, b, "shout", "x"); err != nil || text != "shout: x" {
+		t.Errorf("swapped plugin call = %q, %v", text, err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestPlugin_Config(t *testing.T) {
+	b := plugin.New()
+	if err := b.Initialize(context.Background(), map[string]interface{}{}); err == nil {
+		t.Error("missing path accepted")
+	}
+	if err := b.Initialize(context.Background(), map[string]interface{}{"path": "x", "kind": "wasm"}); err == nil {
+		t.Error("unknown kind accepted")
+	}
+	if err := plugin.New().Initialize(context.Background(), map[string]interface{}{"path": "missing.so"}); err == nil {
+		t.Error("missing Go plugin accepted")
+	}
+}
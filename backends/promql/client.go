@@ -0,0 +1,266 @@
+package promql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Client calls the HTTP API of Prometheus or a compatible server
+// (Thanos, Mimir, VictoriaMetrics, ...)
+type Client struct {
+	// BaseURL is the server's address, e.g. http://prometheus:9090;
+	// API paths are appended to it
+	BaseURL string
+
+	// HTTP makes the requests, authenticated if the server needs it
+	HTTP *http.Client
+
+	// MaxResponseSize bounds a response body; larger answers fail
+	// instead of filling memory (0: no limit)
+	MaxResponseSize int64
+}
+
+// apiResponse is the envelope of every API response
+type apiResponse struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+	Warnings  []string        `json:"warnings"`
+	Infos     []string        `json:"infos"`
+}
+
+// QueryData is the data of a query or query_range response
+type QueryData struct {
+	// ResultType is "matrix", "vector", "scalar" or "string"
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// rawSeries is a series of a vector (Value) or matrix (Values) result
+type rawSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value,omitempty"`
+	Values [][]interface{}   `json:"values,omitempty"`
+}
+
+// APIError is an error the server reported for a request
+type APIError struct {
+	Status  int
+	Type    string // e.g. "bad_data", "timeout", "execution"
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("prometheus %s: %s", e.Type, e.Message)
+}
+
+// Query evaluates an instant query
+// A zero at means the server's current time.
+func (c *Client) Query(ctx context.Context, query string, at time.Time, timeout time.Duration) (*QueryData, []string, error) {
+	form := url.Values{"query": {query}}
+	if !at.IsZero() {
+		form.Set("time", formatTime(at))
+	}
+	if timeout > 0 {
+		form.Set("timeout", timeout.String())
+	}
+	var data QueryData
+	warnings, err := c.call(ctx, "/api/v1/query", form, &data)
+	if err != nil {
+		return nil, warnings, err
+	}
+	return &data, warnings, nil
+}
+
+// QueryRange evaluates a query over a range at a step
+func (c *Client) QueryRange(ctx context.Context, query string, start, end time.Time, step, timeout time.Duration) (*QueryData, []string, error) {
+	form := url.Values{
+		"query": {query},
+		"start": {formatTime(start)},
+		"end":   {formatTime(end)},
+		"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+	}
+	if timeout > 0 {
+		form.Set("timeout", timeout.String())
+	}
+	var data QueryData
+	warnings, err := c.call(ctx, "/api/v1/query_range", form, &data)
+	if err != nil {
+		return nil, warnings, err
+	}
+	return &data, warnings, nil
+}
+
+// LabelValues returns the values of a label, e.g. "__name__" for metric
+// names, of series matching the selectors (none: all series)
+func (c *Client) LabelValues(ctx context.Context, label string, match []string) ([]string, error) {
+	form := url.Values{}
+	for _, m := range match {
+		form.Add("match[]", m)
+	}
+	var values []string
+	_, err := c.get(ctx, "/api/v1/label/"+url.PathEscape(label)+"/values", form, &values)
+	return values, err
+}
+
+// MetricMetadata describes a metric
+type MetricMetadata struct {
+	Type string `json:"type"`
+	Help string `json:"help"`
+	Unit string `json:"unit,omitempty"`
+}
+
+// Metadata returns the metadata of metrics scraped by the server, by name
+// Servers without the endpoint yield an empty map.
+func (c *Client) Metadata(ctx context.Context) (map[string][]MetricMetadata, error) {
+	var metadata map[string][]MetricMetadata
+	_, err := c.get(ctx, "/api/v1/metadata", nil, &metadata)
+	if apiErr := (*APIError)(nil); errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return map[string][]MetricMetadata{}, nil
+	}
+	return metadata, err
+}
+
+// Ready checks the server's readiness endpoint
+func (c *Client) Ready(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url("/-/ready"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return mcperr.Upstream(err, "prometheus unreachable")
+	}
+	resp.Body.Close()
+	// Servers without /-/ready (e.g. some proxies) still answer the API
+	if resp.StatusCode == http.StatusNotFound {
+		_, err = c.get(ctx, "/api/v1/status/buildinfo", nil, nil)
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return mcperr.New(mcperr.CodeUpstream, "prometheus not ready: %s", resp.Status)
+	}
+	return nil
+}
+
+// call POSTs a form, so long queries fit
+func (c *Client) call(ctx context.Context, path string, form url.Values, out interface{}) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url(path), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req, out)
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) ([]string, error) {
+	u := c.url(path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req, out)
+}
+
+// do sends a request and decodes the data of the response into out
+func (c *Client) do(req *http.Request, out interface{}) ([]string, error) {
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client().Do(req)
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, mcperr.Upstream(err, "prometheus request %s", req.URL.Path)
+	}
+	defer resp.Body.Close()
+
+	body := io.Reader(resp.Body)
+	if c.MaxResponseSize > 0 {
+		body = io.LimitReader(resp.Body, c.MaxResponseSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, mcperr.Upstream(err, "read prometheus response")
+	}
+	if c.MaxResponseSize > 0 && int64(len(data)) > c.MaxResponseSize {
+		return nil, &SizeError{Limit: c.MaxResponseSize}
+	}
+
+	var envelope apiResponse
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		if resp.StatusCode >= 300 {
+			return nil, statusError(resp.StatusCode, strings.TrimSpace(string(data)))
+		}
+		return nil, mcperr.Upstream(err, "invalid prometheus response")
+	}
+	if envelope.Status != "success" {
+		if envelope.ErrorType == "" {
+			return nil, statusError(resp.StatusCode, envelope.Error)
+		}
+		return envelope.Warnings, &APIError{Status: resp.StatusCode, Type: envelope.ErrorType, Message: envelope.Error}
+	}
+	if out != nil {
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			return nil, mcperr.Upstream(err, "invalid prometheus response data")
+		}
+	}
+	return append(envelope.Warnings, envelope.Infos...), nil
+}
+
+// SizeError is returned for responses larger than MaxResponseSize
+type SizeError struct {
+	Limit int64
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("prometheus response exceeds %d bytes", e.Limit)
+}
+
+// statusError maps a failed response without an API error
+func statusError(status int, message string) error {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return mcperr.PermissionDenied("prometheus: %s", message)
+	case http.StatusNotFound:
+		return &APIError{Status: status, Type: "not_found", Message: message}
+	case http.StatusTooManyRequests:
+		return mcperr.RateLimited(0, "prometheus: %s", message)
+	}
+	return mcperr.New(mcperr.CodeUpstream, "prometheus returned %d: %s", status, message)
+}
+
+func (c *Client) url(path string) string {
+	return strings.TrimSuffix(c.BaseURL, "/") + path
+}
+
+func (c *Client) client() *http.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return http.DefaultClient
+}
+
+// formatTime formats a time as Unix seconds, as the API accepts
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', 3, 64)
+}
@@ -0,0 +1,651 @@
+// Package promql is a metrics query backend for Prometheus
+//
+// Tools:
+//
+//	instant_query  a PromQL expression evaluated at one instant
+//	range_query    an expression over a time range, streamed series by series
+//	list_metrics   metric names, with type and help where known
+//
+// Any server speaking the Prometheus HTTP API works: Prometheus, Thanos,
+// Mimir, VictoriaMetrics. Queries are bounded so a careless expression
+// cannot swamp the model or the server: range queries get a step no finer
+// than min_step and at most max_points points per series, results are
+// cut off after max_series series and max_samples samples, and responses
+// larger than max_response_size fail. Requests are authenticated by an
+// auth resource of the server's auth provider, basic auth or a bearer
+// token. Importing the package registers the backend as "promql":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/promql"
+//
+//	# config.yaml
+//	backend:
+//	  type: promql
+//	  config:
+//	    url: http://prometheus:9090
+//	    token: ${env:PROMETHEUS_TOKEN}
+//	    max_series: 200
+package promql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultTimeout         = 30 * time.Second
+	DefaultMaxSeries       = 500
+	DefaultMaxPoints       = 11000
+	DefaultMaxSamples      = 100000
+	DefaultMaxMetrics      = 2000
+	DefaultMinStep         = time.Second
+	DefaultMaxRange        = 31 * 24 * time.Hour
+	DefaultMaxResponseSize = 32 << 20
+
+	// DefaultPoints is the points per series of a range query without a
+	// step, so the chosen step follows the range
+	DefaultPoints = 250
+)
+
+func init() {
+	backend.Register("promql", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend queries one Prometheus-compatible server
+type Backend struct {
+	*backend.BaseBackend
+
+	logger *slog.Logger
+	client *Client
+
+	timeout    time.Duration
+	maxSeries  int
+	maxPoints  int
+	maxSamples int
+	maxMetrics int
+	minStep    time.Duration
+	maxRange   time.Duration
+}
+
+// New creates a promql backend; Initialize connects it
+func New() *Backend {
+	b := &Backend{
+		BaseBackend: backend.NewBaseBackend("promql"),
+		logger:      slog.Default(),
+		timeout:     DefaultTimeout,
+		maxSeries:   DefaultMaxSeries,
+		maxPoints:   DefaultMaxPoints,
+		maxSamples:  DefaultMaxSamples,
+		maxMetrics:  DefaultMaxMetrics,
+		minStep:     DefaultMinStep,
+		maxRange:    DefaultMaxRange,
+	}
+	b.registerTools()
+	return b
+}
+
+// NewWithClient creates a promql backend over an existing client;
+// the config's url and auth entries are then ignored
+func NewWithClient(client *Client) *Backend {
+	b := New()
+	b.client = client
+	return b
+}
+
+// Initialize reads the config
+//
+// Config entries:
+//
+//	url                server address (default: the auth resource's base_url)
+//	auth_provider      auth provider of auth_resource (default: the backend's)
+//	auth_resource      auth resource whose HTTP client makes the requests
+//	username           basic auth, when there is no auth_resource
+//	password
+//	token              bearer token, when there is no auth_resource
+//	timeout            longest query (default "30s"), also sent to the server
+//	max_series         series of one result kept (default 500)
+//	max_points         points per series of a range query (default 11000)
+//	max_samples        samples of one range query kept (default 100000)
+//	max_metrics        names list_metrics returns at most (default 2000)
+//	min_step           finest range query step (default "1s")
+//	max_range          longest range query range (default "744h")
+//	max_response_size  largest response body in bytes (default 32MB)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	for key, target := range map[string]*int{
+		"max_series":  &b.maxSeries,
+		"max_points":  &b.maxPoints,
+		"max_samples": &b.maxSamples,
+		"max_metrics": &b.maxMetrics,
+	} {
+		if *target = backend.IntConfig(config, key, *target); *target <= 0 {
+			return fmt.Errorf("promql: %s must be positive", key)
+		}
+	}
+	for key, target := range map[string]*time.Duration{
+		"timeout":   &b.timeout,
+		"min_step":  &b.minStep,
+		"max_range": &b.maxRange,
+	} {
+		if s := backend.StringConfig(config, key, ""); s != "" {
+			d, err := parseDuration(s)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("promql: invalid %s %q", key, s)
+			}
+			*target = d
+		}
+	}
+	maxResponse := backend.IntConfig(config, "max_response_size", DefaultMaxResponseSize)
+	if maxResponse <= 0 {
+		return errors.New("promql: max_response_size must be positive")
+	}
+
+	if b.client == nil {
+		client, err := b.newClient(ctx, config)
+		if err != nil {
+			return err
+		}
+		b.client = client
+	}
+	b.client.MaxResponseSize = int64(maxResponse)
+	b.client.HTTP = b.HTTPClient(b.client.HTTP)
+	b.logger.Info("promql backend initialized",
+		"url", b.client.BaseURL,
+		"max_series", b.maxSeries,
+		"min_step", b.minStep)
+	return nil
+}
+
+// newClient creates the client of the configured server and credentials
+func (b *Backend) newClient(ctx context.Context, config map[string]interface{}) (*Client, error) {
+	client := &Client{BaseURL: backend.StringConfig(config, "url", "")}
+	username := backend.StringConfig(config, "username", "")
+	token := backend.StringConfig(config, "token", "")
+
+	switch resourceName := backend.StringConfig(config, "auth_resource", ""); {
+	case resourceName != "":
+		resource, err := b.resource(ctx, backend.StringConfig(config, "auth_provider", ""), resourceName)
+		if err != nil {
+			return nil, fmt.Errorf("promql: auth resource %s: %w", resourceName, err)
+		}
+		authenticated, baseURL, err := auth.ResourceHTTPClient(resource)
+		if err != nil {
+			resource.Close()
+			return nil, fmt.Errorf("promql: auth resource %s: %w", resourceName, err)
+		}
+		client.HTTP = authenticated
+		if client.BaseURL == "" {
+			client.BaseURL = baseURL
+		}
+	case username != "":
+		client.HTTP = &http.Client{Transport: basicAuth{username: username, password: backend.StringConfig(config, "password", "")}}
+	case token != "":
+		client.HTTP = &http.Client{Transport: bearerAuth{token: token}}
+	default:
+		client.HTTP = &http.Client{}
+	}
+	if client.BaseURL == "" {
+		return nil, errors.New("promql: url is required")
+	}
+	return client, nil
+}
+
+// resource gets an auth resource
+func (b *Backend) resource(ctx context.Context, provider, name string) (auth.Resource, error) {
+	if provider == "" {
+		if p := b.GetAuthProvider(); p != nil {
+			return p.GetResource(ctx, name)
+		}
+		provider = "default"
+	}
+	return b.GetAuthenticatedResource(ctx, provider, name)
+}
+
+// basicAuth adds HTTP basic credentials to requests
+type basicAuth struct {
+	username, password string
+}
+
+func (t basicAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.SetBasicAuth(t.username, t.password)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// bearerAuth adds a bearer token to requests
+type bearerAuth struct {
+	token string
+}
+
+func (t bearerAuth) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Readiness checks the server's readiness endpoint.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	r.RegisterReadiness("promql:server", func(ctx context.Context) error {
+		if b.client == nil {
+			return backend.ErrNotInitialized
+		}
+		return b.client.Ready(ctx)
+	})
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type instantArgs struct {
+	Query string    `json:"query" description:"PromQL expression, e.g. sum by (job) (rate(http_requests_total[5m]))"`
+	Time  time.Time `json:"time,omitempty" description:"Evaluation instant, RFC 3339 (default: now)"`
+}
+
+type rangeArgs struct {
+	Query string    `json:"query" description:"PromQL expression"`
+	Start time.Time `json:"start" description:"Range start, RFC 3339"`
+	End   time.Time `json:"end,omitempty" description:"Range end, RFC 3339 (default: now)"`
+	Step  string    `json:"step,omitempty" description:"Resolution as a duration (\"30s\", \"5m\") or seconds (default: about 250 points over the range)"`
+}
+
+type listArgs struct {
+	Match  string `json:"match,omitempty" description:"Regular expression metric names must contain, e.g. ^http_ (default: all)"`
+	Job    string `json:"job,omitempty" description:"Only metrics of series with this job label"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"minimum=1,default=200" description:"Most names to return"`
+	NoHelp bool   `json:"no_help,omitempty" description:"Skip type and help text, which need another request"`
+}
+
+// Sample is a value at an instant
+// Values stay strings as Prometheus sends them, since NaN and ±Inf have
+// no JSON number.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value string    `json:"value"`
+}
+
+// Series is a labelled series of a result: Value for instant vectors,
+// Values for range results
+type Series struct {
+	Metric map[string]string `json:"metric"`
+	Value  *Sample           `json:"value,omitempty"`
+	Values []Sample          `json:"values,omitempty"`
+}
+
+// InstantResult is the result of instant_query
+type InstantResult struct {
+	// ResultType is "vector", "matrix", "scalar" or "string"
+	ResultType string   `json:"result_type"`
+	Series     []Series `json:"series,omitempty"`
+	Scalar     *Sample  `json:"scalar,omitempty"`
+
+	// TotalSeries is the number of series before max_series applied
+	TotalSeries int      `json:"total_series"`
+	Truncated   bool     `json:"truncated,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// Metric is a metric of list_metrics
+type Metric struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+	Help string `json:"help,omitempty"`
+	Unit string `json:"unit,omitempty"`
+}
+
+type listResult struct {
+	Metrics   []Metric `json:"metrics"`
+	Total     int      `json:"total"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+func (b *Backend) registerTools() {
+	backend.RegisterTypedTool(b, backend.NewTool("instant_query").
+		Description("Evaluate a PromQL expression at one instant (default now). Returns the series with their current values, a scalar or a string.").
+		ParamsFromStruct(instantArgs{}).
+		NonCacheable().
+		Build(), b.instantQuery)
+
+	rangeQuery := backend.NewTool("range_query").
+		Description("Evaluate a PromQL expression over a time range at a step. Series stream one by one with their values.").
+		ParamsFromStruct(rangeArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(rangeQuery, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[rangeArgs](rangeQuery, args)
+		if err != nil {
+			return err
+		}
+		return b.rangeQuery(ctx, in, emit)
+	})
+
+	backend.RegisterTypedTool(b, backend.NewTool("list_metrics").
+		Description("List metric names known to the server, optionally filtered, with their type and help text.").
+		ParamsFromStruct(listArgs{}).
+		WithCache(true, time.Minute).
+		Build(), b.listMetrics)
+}
+
+// queryError maps a failed query: mistakes in the expression are
+// argument errors the model can correct
+func queryError(tool string, err error) error {
+	var apiErr *APIError
+	var sizeErr *SizeError
+	switch {
+	case errors.As(err, &sizeErr):
+		return backend.NewArgumentError(tool, "query", fmt.Sprintf("result exceeds %d bytes; select fewer series or use a coarser step", sizeErr.Limit))
+	case !errors.As(err, &apiErr):
+		return err
+	case apiErr.Type == "bad_data":
+		return backend.NewArgumentError(tool, "query", apiErr.Message)
+	case apiErr.Type == "timeout" || apiErr.Type == "canceled":
+		return mcperr.Timeout("query timed out: %s", apiErr.Message)
+	case apiErr.Status == http.StatusUnprocessableEntity:
+		// The expression is valid but cannot run, e.g. too many samples
+		return backend.NewArgumentError(tool, "query", apiErr.Message)
+	case apiErr.Type == "not_found":
+		return mcperr.New(mcperr.CodeUpstream, "the server has no Prometheus API at this url: %s", apiErr.Message)
+	}
+	return mcperr.Upstream(apiErr, "query failed")
+}
+
+// instantQuery handles instant_query
+func (b *Backend) instantQuery(ctx context.Context, in instantArgs) (*InstantResult, error) {
+	if b.client == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	if strings.TrimSpace(in.Query) == "" {
+		return nil, backend.NewArgumentError("instant_query", "query", "is required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	data, warnings, err := b.client.Query(ctx, in.Query, in.Time, b.timeout)
+	if err != nil {
+		return nil, queryError("instant_query", err)
+	}
+	result := &InstantResult{ResultType: data.ResultType, Warnings: warnings}
+	switch data.ResultType {
+	case "scalar", "string":
+		var raw []interface{}
+		if err := json.Unmarshal(data.Result, &raw); err != nil {
+			return nil, mcperr.Upstream(err, "invalid %s result", data.ResultType)
+		}
+		sample, err := parseSample(raw)
+		if err != nil {
+			return nil, err
+		}
+		result.Scalar = &sample
+		return result, nil
+	}
+
+	series, err := parseSeries(data)
+	if err != nil {
+		return nil, err
+	}
+	result.TotalSeries = len(series)
+	if len(series) > b.maxSeries {
+		series, result.Truncated = series[:b.maxSeries], true
+	}
+	samples := 0
+	for i, s := range series {
+		if samples+len(s.Values) > b.maxSamples {
+			series[i].Values = s.Values[:b.maxSamples-samples]
+			series, result.Truncated = series[:i+1], true
+			break
+		}
+		samples += len(s.Values)
+	}
+	result.Series = series
+	return result, nil
+}
+
+// rangeQuery handles range_query, emitting one Series per series
+func (b *Backend) rangeQuery(ctx context.Context, in rangeArgs, emit backend.StreamingEmitter) error {
+	if b.client == nil {
+		return backend.ErrNotInitialized
+	}
+	if strings.TrimSpace(in.Query) == "" {
+		return backend.NewArgumentError("range_query", "query", "is required")
+	}
+	end := in.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	span := end.Sub(in.Start)
+	switch {
+	case span <= 0:
+		return backend.NewArgumentError("range_query", "end", "must be after start")
+	case span > b.maxRange:
+		return backend.NewArgumentError("range_query", "start", fmt.Sprintf("range may span at most %s", b.maxRange))
+	}
+	step, err := b.step(in.Step, span)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	data, warnings, err := b.client.QueryRange(ctx, in.Query, in.Start, end, step, b.timeout)
+	if err != nil {
+		return queryError("range_query", err)
+	}
+	series, err := parseSeries(data)
+	if err != nil {
+		return err
+	}
+
+	if err := emit.EmitProgress(0, int64(len(series)), fmt.Sprintf("%d series at step %s", len(series), step)); err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		if err := emit.EmitProgress(0, int64(len(series)), "warning: "+w); err != nil {
+			return err
+		}
+	}
+
+	samples := 0
+	for i, s := range series {
+		if i == b.maxSeries {
+			return emit.EmitProgress(int64(i), int64(len(series)), fmt.Sprintf("truncated to %d of %d series", i, len(series)))
+		}
+		if samples+len(s.Values) > b.maxSamples {
+			s.Values = s.Values[:b.maxSamples-samples]
+			if err := emit.EmitData(s); err != nil {
+				return err
+			}
+			return emit.EmitProgress(int64(i+1), int64(len(series)), fmt.Sprintf("truncated after %d samples in %d of %d series", b.maxSamples, i+1, len(series)))
+		}
+		samples += len(s.Values)
+		if err := emit.EmitData(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// step returns the step of a range query, checked against min_step and
+// max_points
+func (b *Backend) step(arg string, span time.Duration) (time.Duration, error) {
+	if arg == "" {
+		step := (span / DefaultPoints).Truncate(time.Second)
+		if step < b.minStep {
+			step = b.minStep
+		}
+		return step, nil
+	}
+	step, err := parseDuration(arg)
+	if err != nil || step <= 0 {
+		return 0, backend.NewArgumentError("range_query", "step", fmt.Sprintf("invalid step %q", arg))
+	}
+	if step < b.minStep {
+		return 0, backend.NewArgumentError("range_query", "step", fmt.Sprintf("must be at least %s", b.minStep))
+	}
+	if points := int64(span/step) + 1; points > int64(b.maxPoints) {
+		return 0, backend.NewArgumentError("range_query", "step", fmt.Sprintf(
+			"gives %d points per series, more than %d; use a step of at least %s", points, b.maxPoints, minStepFor(span, b.maxPoints)))
+	}
+	return step, nil
+}
+
+// minStepFor returns the finest whole-second step keeping a span within
+// maxPoints points
+func minStepFor(span time.Duration, maxPoints int) time.Duration {
+	seconds := math.Ceil(span.Seconds() / float64(maxPoints-1))
+	return time.Duration(math.Max(seconds, 1)) * time.Second
+}
+
+// listMetrics handles list_metrics
+func (b *Backend) listMetrics(ctx context.Context, in listArgs) (*listResult, error) {
+	if b.client == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	var pattern *regexp.Regexp
+	if in.Match != "" {
+		re, err := regexp.Compile(in.Match)
+		if err != nil {
+			return nil, backend.NewArgumentError("list_metrics", "match", err.Error())
+		}
+		pattern = re
+	}
+	limit := in.Limit
+	if limit <= 0 || limit > b.maxMetrics {
+		limit = b.maxMetrics
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	var selectors []string
+	if in.Job != "" {
+		selectors = []string{fmt.Sprintf("{job=%q}", in.Job)}
+	}
+	names, err := b.client.LabelValues(ctx, "__name__", selectors)
+	if err != nil {
+		return nil, queryError("list_metrics", err)
+	}
+	sort.Strings(names)
+
+	result := &listResult{Metrics: []Metric{}}
+	for _, name := range names {
+		if pattern != nil && !pattern.MatchString(name) {
+			continue
+		}
+		result.Total++
+		if len(result.Metrics) < limit {
+			result.Metrics = append(result.Metrics, Metric{Name: name})
+		}
+	}
+	result.Truncated = result.Total > len(result.Metrics)
+	if in.NoHelp || len(result.Metrics) == 0 {
+		return result, nil
+	}
+
+	metadata, err := b.client.Metadata(ctx)
+	if err != nil {
+		// Names are still useful without help texts
+		b.logger.Debug("metric metadata unavailable", "error", err)
+		return result, nil
+	}
+	for i, m := range result.Metrics {
+		if meta := metadata[m.Name]; len(meta) > 0 {
+			result.Metrics[i].Type, result.Metrics[i].Help, result.Metrics[i].Unit = meta[0].Type, meta[0].Help, meta[0].Unit
+		}
+	}
+	return result, nil
+}
+
+// ============================================================
+// Results
+// ============================================================
+
+// parseSeries decodes a vector or matrix result
+func parseSeries(data *QueryData) ([]Series, error) {
+	if data.ResultType != "vector" && data.ResultType != "matrix" {
+		return nil, mcperr.New(mcperr.CodeUpstream, "unexpected result type %q", data.ResultType)
+	}
+	var raw []rawSeries
+	if err := json.Unmarshal(data.Result, &raw); err != nil {
+		return nil, mcperr.Upstream(err, "invalid %s result", data.ResultType)
+	}
+	series := make([]Series, len(raw))
+	for i, r := range raw {
+		series[i].Metric = r.Metric
+		if series[i].Metric == nil {
+			series[i].Metric = map[string]string{}
+		}
+		if r.Value != nil {
+			sample, err := parseSample(r.Value)
+			if err != nil {
+				return nil, err
+			}
+			series[i].Value = &sample
+		}
+		if len(r.Values) > 0 {
+			series[i].Values = make([]Sample, len(r.Values))
+			for j, v := range r.Values {
+				sample, err := parseSample(v)
+				if err != nil {
+					return nil, err
+				}
+				series[i].Values[j] = sample
+			}
+		}
+	}
+	return series, nil
+}
+
+// parseSample decodes a [unix seconds, "value"] pair
+func parseSample(raw []interface{}) (Sample, error) {
+	if len(raw) != 2 {
+		return Sample{}, mcperr.New(mcperr.CodeUpstream, "invalid sample %v", raw)
+	}
+	ts, ok := raw[0].(float64)
+	value, ok2 := raw[1].(string)
+	if !ok || !ok2 {
+		return Sample{}, mcperr.New(mcperr.CodeUpstream, "invalid sample %v", raw)
+	}
+	sec, frac := math.Modf(ts)
+	return Sample{Time: time.Unix(int64(sec), int64(math.Round(frac*1e3))*1e6).UTC(), Value: value}, nil
+}
+
+// parseDuration parses a Go duration, a Prometheus duration with days,
+// weeks or years ("7d", "1w"), or a number of seconds
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		if seconds < 0 || math.IsNaN(seconds) || seconds > math.MaxInt64/1e9 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour, 'y': 365 * 24 * time.Hour}
+	if n := len(s); n > 1 {
+		if unit, ok := units[s[n-1]]; ok {
+			count, err := strconv.Atoi(s[:n-1])
+			if err != nil || count < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
@@ -0,0 +1,383 @@
+package promql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backends/promql"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+type captureEmitter struct {
+	ctx      context.Context
+	mu       sync.Mutex
+	series   []promql.Series
+	progress []string
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.series = append(e.series, data.(promql.Series))
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error {
+	e.progress = append(e.progress, message)
+	return nil
+}
+func (e *captureEmitter) Context() context.Context { return e.ctx }
+
+// ============================================================
+// Fake Prometheus
+// ============================================================
+
+// fakePrometheus answers queries by name: "up" has 3 series, "many" 20,
+// "bad(" is a syntax error and "slow" times out
+type fakePrometheus struct {
+	token string
+
+	mu    sync.Mutex
+	forms []map[string]string
+}
+
+func (f *fakePrometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.token != "" && r.Header.Get("Authorization") != "Bearer "+f.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	r.ParseForm()
+	form := map[string]string{}
+	for k := range r.Form {
+		form[k] = r.Form.Get(k)
+	}
+	f.mu.Lock()
+	f.forms = append(f.forms, form)
+	f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/-/ready":
+		fmt.Fprint(w, "Prometheus Server is Ready.")
+	case "/api/v1/query":
+		f.query(w, r, false)
+	case "/api/v1/query_range":
+		f.query(w, r, true)
+	case "/api/v1/label/__name__/values":
+		names := []string{"up", "http_requests_total", "http_request_duration_seconds", "process_cpu_seconds_total"}
+		if r.Form.Get("match[]") == `{job="api"}` {
+			names = names[:3]
+		}
+		success(w, names)
+	case "/api/v1/metadata":
+		success(w, map[string]interface{}{
+			"http_requests_total": []map[string]string{{"type": "counter", "help": "Requests served."}},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakePrometheus) query(w http.ResponseWriter, r *http.Request, matrix bool) {
+	query := r.Form.Get("query")
+	count := map[string]int{"up": 3, "many": 20}[query]
+	switch query {
+	case "bad(":
+		fail(w, http.StatusBadRequest, "bad_data", `parse error: unexpected end of input`)
+		return
+	case "slow":
+		fail(w, http.StatusServiceUnavailable, "timeout", "query timed out in expression evaluation")
+		return
+	case "1+1":
+		success(w, map[string]interface{}{"resultType": "scalar", "result": []interface{}{1700000000.5, "2"}})
+		return
+	}
+
+	series := make([]map[string]interface{}, count)
+	for i := range series {
+		s := map[string]interface{}{"metric": map[string]string{"__name__": query, "instance": fmt.Sprintf("host-%d", i)}}
+		if matrix {
+			start, _ := strconv.ParseFloat(r.Form.Get("start"), 64)
+			end, _ := strconv.ParseFloat(r.Form.Get("end"), 64)
+			step, _ := strconv.ParseFloat(r.Form.Get("step"), 64)
+			var values [][]interface{}
+			for t := start; t <= end; t += step {
+				values = append(values, []interface{}{t, "1"})
+			}
+			s["values"] = values
+		} else {
+			s["value"] = []interface{}{1700000000, "NaN"}
+		}
+		series[i] = s
+	}
+	resultType := "vector"
+	if matrix {
+		resultType = "matrix"
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"data":     map[string]interface{}{"resultType": resultType, "result": series},
+		"warnings": []string{"partial response"},
+	})
+}
+
+func success(w http.ResponseWriter, data interface{}) {
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "data": data})
+}
+
+func fail(w http.ResponseWriter, status int, errorType, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "error", "errorType": errorType, "error": message})
+}
+
+func (f *fakePrometheus) lastForm() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.forms[len(f.forms)-1]
+}
+
+func newBackend(t *testing.T, config map[string]interface{}) (*promql.Backend, *fakePrometheus) {
+	t.Helper()
+	f := &fakePrometheus{token: "s3cret"}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	full := map[string]interface{}{"url": server.URL, "token": "s3cret"}
+	for k, v := range config {
+		full[k] = v
+	}
+	b := promql.New()
+	if err := b.Initialize(context.Background(), full); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b, f
+}
+
+// ============================================================
+// Tests
+// ============================================================
+
+func TestInstantQuery(t *testing.T) {
+	b, f := newBackend(t, map[string]interface{}{"max_series": 2})
+
+	result, err := b.CallTool(context.Background(), "instant_query", map[string]interface{}{
+		"query": "up", "time": "2023-11-14T22:13:20Z",
+	})
+	if err != nil {
+		t.Fatalf("instant_query error = %v", err)
+	}
+	r := result.(*promql.InstantResult)
+	if r.ResultType != "vector" || len(r.Series) != 2 || r.TotalSeries != 3 || !r.Truncated {
+		t.Fatalf("result = %+v, want 2 of 3 series", r)
+	}
+	if v := r.Series[0].Value; v == nil || v.Value != "NaN" || !v.Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("value = %+v", v)
+	}
+	if len(r.Warnings) != 1 {
+		t.Errorf("warnings = %v", r.Warnings)
+	}
+	if form := f.lastForm(); form["time"] != "1700000000.000" || form["timeout"] != "30s" {
+		t.Errorf("request = %v", form)
+	}
+
+	result, err = b.CallTool(context.Background(), "instant_query", map[string]interface{}{"query": "1+1"})
+	if err != nil {
+		t.Fatalf("scalar query error = %v", err)
+	}
+	if s := result.(*promql.InstantResult).Scalar; s == nil || s.Value != "2" || s.Time.Nanosecond() != 5e8 {
+		t.Errorf("scalar = %+v", s)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	b, _ := newBackend(t, nil)
+
+	_, err := b.CallTool(context.Background(), "instant_query", map[string]interface{}{"query": "bad("})
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) || argErr.Fields[0].Field != "query" || !strings.Contains(argErr.Fields[0].Message, "parse error") {
+		t.Errorf("syntax error = %v, want an argument error on query", err)
+	}
+	_, err = b.CallTool(context.Background(), "instant_query", map[string]interface{}{"query": "slow"})
+	if mcperr.CodeOf(err) != mcperr.CodeTimeout {
+		t.Errorf("timeout error = %v, want timeout", err)
+	}
+
+	unauthorized, _ := newBackend(t, map[string]interface{}{"token": "wrong"})
+	_, err = unauthorized.CallTool(context.Background(), "instant_query", map[string]interface{}{"query": "up"})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("unauthorized error = %v, want permission denied", err)
+	}
+
+	small, _ := newBackend(t, map[string]interface{}{"max_response_size": 100})
+	_, err = small.CallTool(context.Background(), "instant_query", map[string]interface{}{"query": "many"})
+	if !errors.As(err, &argErr) || !strings.Contains(argErr.Fields[0].Message, "exceeds 100 bytes") {
+		t.Errorf("large response error = %v, want a size error", err)
+	}
+}
+
+func TestRangeQuery(t *testing.T) {
+	b, f := newBackend(t, nil)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	emit := &captureEmitter{ctx: context.Background()}
+	err := b.CallStreamingTool(context.Background(), "range_query", map[string]interface{}{
+		"query": "up", "start": start.Format(time.RFC3339), "end": start.Add(time.Hour).Format(time.RFC3339), "step": "5m",
+	}, emit)
+	if err != nil {
+		t.Fatalf("range_query error = %v", err)
+	}
+	if len(emit.series) != 3 || len(emit.series[0].Values) != 13 {
+		t.Fatalf("got %d series, want 3 with 13 points", len(emit.series))
+	}
+	if emit.series[2].Metric["instance"] != "host-2" || !emit.series[0].Values[1].Time.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("series = %+v", emit.series[2])
+	}
+	if len(emit.progress) != 2 || emit.progress[0] != "3 series at step 5m0s" || emit.progress[1] != "warning: partial response" {
+		t.Errorf("progress = %v", emit.progress)
+	}
+	if f.lastForm()["step"] != "300" {
+		t.Errorf("step sent = %q", f.lastForm()["step"])
+	}
+
+	// Without a step, about DefaultPoints points cover the range
+	err = b.CallStreamingTool(context.Background(), "range_query", map[string]interface{}{
+		"query": "up", "start": start.Format(time.RFC3339), "end": start.Add(7 * 24 * time.Hour).Format(time.RFC3339),
+	}, &captureEmitter{ctx: context.Background()})
+	if err != nil {
+		t.Fatalf("range_query without step error = %v", err)
+	}
+	if f.lastForm()["step"] != "2419" {
+		t.Errorf("auto step = %q, want 2419", f.lastForm()["step"])
+	}
+}
+
+func TestRangeQuery_Caps(t *testing.T) {
+	b, _ := newBackend(t, map[string]interface{}{"max_series": 5, "max_samples": 30})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	args := func(query string) map[string]interface{} {
+		return map[string]interface{}{
+			"query": query, "start": start.Format(time.RFC3339), "end": start.Add(time.Hour).Format(time.RFC3339), "step": "10m",
+		}
+	}
+
+	emit := &captureEmitter{ctx: context.Background()}
+	if err := b.CallStreamingTool(context.Background(), "range_query", args("many"), emit); err != nil {
+		t.Fatalf("range_query error = %v", err)
+	}
+	// 7 points per series: 4 full series, then 2 of the 5th reach 30 samples
+	if len(emit.series) != 5 || len(emit.series[4].Values) != 2 {
+		t.Fatalf("got %d series, last with %d points", len(emit.series), len(emit.series[len(emit.series)-1].Values))
+	}
+	if last := emit.progress[len(emit.progress)-1]; last != "truncated after 30 samples in 5 of 20 series" {
+		t.Errorf("last progress = %q", last)
+	}
+
+	b, _ = newBackend(t, map[string]interface{}{"max_series": 5})
+	emit = &captureEmitter{ctx: context.Background()}
+	if err := b.CallStreamingTool(context.Background(), "range_query", args("many"), emit); err != nil {
+		t.Fatalf("range_query error = %v", err)
+	}
+	if len(emit.series) != 5 || emit.progress[len(emit.progress)-1] != "truncated to 5 of 20 series" {
+		t.Errorf("got %d series, progress %v", len(emit.series), emit.progress)
+	}
+}
+
+func TestRangeQuery_StepLimits(t *testing.T) {
+	b, _ := newBackend(t, map[string]interface{}{"min_step": "15s", "max_points": 100, "max_range": "2d"})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		end   time.Time
+		step  string
+		field string
+		want  string
+	}{
+		"below min_step":  {start.Add(time.Hour), "5s", "step", "at least 15s"},
+		"too many points": {start.Add(time.Hour), "30s", "step", "use a step of at least 37s"},
+		"invalid step":    {start.Add(time.Hour), "often", "step", "invalid step"},
+		"range too long":  {start.Add(3 * 24 * time.Hour), "1h", "start", "at most 48h0m0s"},
+		"end before":      {start.Add(-time.Hour), "1m", "end", "after start"},
+	} {
+		err := b.CallStreamingTool(context.Background(), "range_query", map[string]interface{}{
+			"query": "up", "start": start.Format(time.RFC3339), "end": tc.end.Format(time.RFC3339), "step": tc.step,
+		}, &captureEmitter{ctx: context.Background()})
+		var argErr *backend.ArgumentError
+		if !errors.As(err, &argErr) || argErr.Fields[0].Field != tc.field || !strings.Contains(argErr.Fields[0].Message, tc.want) {
+			t.Errorf("%s: error = %v, want %q on %s", name, err, tc.want, tc.field)
+		}
+	}
+
+	// Prometheus durations and plain seconds are steps too
+	for _, step := range []string{"1d", "3600", "1.5h"} {
+		err := b.CallStreamingTool(context.Background(), "range_query", map[string]interface{}{
+			"query": "up", "start": start.Format(time.RFC3339), "end": start.Add(48 * time.Hour).Format(time.RFC3339), "step": step,
+		}, &captureEmitter{ctx: context.Background()})
+		if err != nil {
+			t.Errorf("step %q: error = %v", step, err)
+		}
+	}
+}
+
+func TestListMetrics(t *testing.T) {
+	b, f := newBackend(t, nil)
+
+	result, err := b.CallTool(context.Background(), "list_metrics", map[string]interface{}{"match": "^http_", "limit": 1})
+	if err != nil {
+		t.Fatalf("list_metrics error = %v", err)
+	}
+	data, _ := json.Marshal(result)
+	var r struct {
+		Metrics   []promql.Metric `json:"metrics"`
+		Total     int             `json:"total"`
+		Truncated bool            `json:"truncated"`
+	}
+	json.Unmarshal(data, &r)
+	if r.Total != 2 || !r.Truncated || len(r.Metrics) != 1 {
+		t.Fatalf("result = %+v, want 1 of 2 http_ metrics", r)
+	}
+	// Sorted, so the duration histogram comes first and has no metadata
+	if m := r.Metrics[0]; m.Name != "http_request_duration_seconds" || m.Type != "" {
+		t.Errorf("metric = %+v", m)
+	}
+
+	result, err = b.CallTool(context.Background(), "list_metrics", map[string]interface{}{"match": "requests_total", "job": "api"})
+	if err != nil {
+		t.Fatalf("list_metrics error = %v", err)
+	}
+	data, _ = json.Marshal(result)
+	json.Unmarshal(data, &r)
+	if len(r.Metrics) != 1 || r.Metrics[0].Type != "counter" || r.Metrics[0].Help != "Requests served." {
+		t.Errorf("metrics = %+v", r.Metrics)
+	}
+	if f.forms[len(f.forms)-2]["match[]"] != `{job="api"}` {
+		t.Errorf("label request = %v", f.forms[len(f.forms)-2])
+	}
+
+	_, err = b.CallTool(context.Background(), "list_metrics", map[string]interface{}{"match": "("})
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) || argErr.Fields[0].Field != "match" {
+		t.Errorf("error = %v, want an argument error on match", err)
+	}
+}
+
+func TestInitialize_Errors(t *testing.T) {
+	for name, config := range map[string]map[string]interface{}{
+		"no url":        {},
+		"bad timeout":   {"url": "http://localhost:9090", "timeout": "soon"},
+		"zero series":   {"url": "http://localhost:9090", "max_series": 0},
+		"negative step": {"url": "http://localhost:9090", "min_step": "-1s"},
+	} {
+		if err := promql.New().Initialize(context.Background(), config); err == nil {
+			t.Errorf("%s: Initialize() succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// ============================================================
+// Embedders
+// ============================================================
+
+// Embedder turns texts into vectors, one per text in order
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderFactory creates an embedder from its config entry
+type EmbedderFactory func(config map[string]interface{}) (Embedder, error)
+
+var (
+	embeddersMu sync.RWMutex
+	embedders   = map[string]EmbedderFactory{
+		"openai": newOpenAIFromConfig,
+		"ollama": newOllamaFromConfig,
+		"hash": func(config map[string]interface{}) (Embedder, error) {
+			return NewHashEmbedder(backend.IntConfig(config, "dimensions", DefaultHashDimensions)), nil
+		},
+	}
+)
+
+// RegisterEmbedder makes an embedding provider available to the
+// embedder.type config entry
+func RegisterEmbedder(name string, factory EmbedderFactory) {
+	embeddersMu.Lock()
+	defer embeddersMu.Unlock()
+	embedders[name] = factory
+}
+
+// newEmbedder creates an embedder of a registered type
+func newEmbedder(name string, config map[string]interface{}) (Embedder, error) {
+	embeddersMu.RLock()
+	factory, ok := embedders[name]
+	embeddersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("vectorstore: unknown embedder type %q", name)
+	}
+	return factory(config)
+}
+
+// ============================================================
+// OpenAI-compatible APIs
+// ============================================================
+
+// DefaultOpenAIURL is the OpenAI API base URL
+const DefaultOpenAIURL = "https://api.openai.com/v1"
+
+// OpenAIEmbedder calls an OpenAI-compatible /embeddings endpoint (OpenAI,
+// Azure OpenAI, vLLM, LocalAI, ...)
+type OpenAIEmbedder struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+
+	// Dimensions shortens vectors on models that support it (0 = model default)
+	Dimensions int
+
+	Client *http.Client
+}
+
+func newOpenAIFromConfig(config map[string]interface{}) (Embedder, error) {
+	e := &OpenAIEmbedder{
+		BaseURL:    backend.StringConfig(config, "base_url", DefaultOpenAIURL),
+		APIKey:     backend.StringConfig(config, "api_key", ""),
+		Model:      backend.StringConfig(config, "model", ""),
+		Dimensions: backend.IntConfig(config, "dimensions", 0),
+	}
+	if e.Model == "" {
+		return nil, errors.New("vectorstore: openai embedder needs a model")
+	}
+	return e, nil
+}
+
+// Embed implements Embedder
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	req := map[string]interface{}{"model": e.Model, "input": texts}
+	if e.Dimensions > 0 {
+		req["dimensions"] = e.Dimensions
+	}
+	headers := map[string]string{}
+	if e.APIKey != "" {
+		headers["Authorization"] = "Bearer " + e.APIKey
+	}
+
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := doJSON(ctx, e.Client, http.MethodPost, strings.TrimSuffix(e.BaseURL, "/")+"/embeddings", headers, req, &resp); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index >= 0 && d.Index < len(vectors) {
+			vectors[d.Index] = d.Embedding
+		}
+	}
+	return vectors, checkVectors(vectors)
+}
+
+// ============================================================
+// Ollama
+// ============================================================
+
+// DefaultOllamaURL is a local Ollama server
+const DefaultOllamaURL = "http://localhost:11434"
+
+// OllamaEmbedder calls Ollama's /api/embed endpoint
+type OllamaEmbedder struct {
+	BaseURL string
+	Model   string
+	Client  *http.Client
+}
+
+func newOllamaFromConfig(config map[string]interface{}) (Embedder, error) {
+	e := &OllamaEmbedder{
+		BaseURL: backend.StringConfig(config, "base_url", DefaultOllamaURL),
+		Model:   backend.StringConfig(config, "model", ""),
+	}
+	if e.Model == "" {
+		return nil, errors.New("vectorstore: ollama embedder needs a model")
+	}
+	return e, nil
+}
+
+// Embed implements Embedder
+func (e *OllamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	req := map[string]interface{}{"model": e.Model, "input": texts}
+	if err := doJSON(ctx, e.Client, http.MethodPost, strings.TrimSuffix(e.BaseURL, "/")+"/api/embed", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, mcperr.Upstream(fmt.Errorf("got %d embeddings for %d texts", len(resp.Embeddings), len(texts)), "ollama embed")
+	}
+	return resp.Embeddings, checkVectors(resp.Embeddings)
+}
+
+// ============================================================
+// Hash embedder
+// ============================================================
+
+// DefaultHashDimensions is the HashEmbedder's default vector length
+const DefaultHashDimensions = 256
+
+// HashEmbedder embeds texts locally by hashing their words into a
+// fixed number of buckets
+// It finds documents sharing words with the query, not meaning; use it
+// for development and tests, where no model is available.
+type HashEmbedder struct {
+	dimensions int
+}
+
+// NewHashEmbedder creates a hash embedder producing vectors of the given
+// length
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+	if dimensions <= 0 {
+		dimensions = DefaultHashDimensions
+	}
+	return &HashEmbedder{dimensions: dimensions}
+}
+
+// Embed implements Embedder
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, e.dimensions)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			h := fnv.New32a()
+			h.Write([]byte(word))
+			sum := h.Sum32()
+			sign := float32(1)
+			if sum&1 == 1 {
+				sign = -1
+			}
+			v[(sum>>1)%uint32(e.dimensions)] += sign
+		}
+		normalize(v)
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+// normalize scales v to unit length
+func normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+}
+
+// ============================================================
+// Helpers
+// ============================================================
+
+// checkVectors fails if a provider left a text without a vector
+func checkVectors(vectors [][]float32) error {
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return mcperr.Upstream(fmt.Errorf("no embedding for input %d", i), "embed")
+		}
+	}
+	return nil
+}
+
+// doJSON sends in as JSON and decodes the response into out
+// HTTP errors are categorized: 404 as not found, 401/403 as permission
+// denied, 429 as rate limited (honoring Retry-After), others as upstream.
+func doJSON(ctx context.Context, client *http.Client, method, url string, headers map[string]string, in, out interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return mcperr.Upstream(err, "%s %s", method, req.URL.Redacted())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		msg := fmt.Sprintf("%s %s: HTTP %d: %s", method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(detail)))
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return mcperr.NotFound("%s", msg)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return mcperr.PermissionDenied("%s", msg)
+		case http.StatusTooManyRequests:
+			seconds, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			return mcperr.RateLimited(time.Duration(seconds)*time.Second, "%s", msg)
+		}
+		return mcperr.Upstream(errors.New(msg), "request failed")
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return mcperr.Upstream(err, "decode %s response", req.URL.Path)
+	}
+	return nil
+}
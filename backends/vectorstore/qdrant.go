@@ -0,0 +1,201 @@
+package vectorstore
+
+import (
+	"context"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Payload fields of Qdrant points
+const (
+	qdrantNamespace = "namespace"
+	qdrantID        = "doc_id"
+	qdrantText      = "text"
+	qdrantMetadata  = "metadata"
+)
+
+// QdrantStore keeps documents in a Qdrant collection through its REST API
+// All namespaces share the collection, told apart by a payload field;
+// the collection is created with cosine distance on the first upsert.
+type QdrantStore struct {
+	URL        string
+	Collection string
+	APIKey     string
+	Client     *http.Client
+
+	mu      sync.Mutex
+	created bool
+}
+
+func newQdrantFromConfig(config map[string]interface{}) (Store, error) {
+	s := &QdrantStore{
+		URL:        backend.StringConfig(config, "url", "http://localhost:6333"),
+		Collection: backend.StringConfig(config, "collection", ""),
+		APIKey:     backend.StringConfig(config, "api_key", ""),
+	}
+	if s.Collection == "" {
+		return nil, errors.New("vectorstore: qdrant store needs a collection")
+	}
+	return s, nil
+}
+
+// do calls the collection's API at path
+func (s *QdrantStore) do(ctx context.Context, method, path string, in, out interface{}) error {
+	headers := map[string]string{}
+	if s.APIKey != "" {
+		headers["api-key"] = s.APIKey
+	}
+	endpoint := strings.TrimSuffix(s.URL, "/") + "/collections/" + url.PathEscape(s.Collection) + path
+	return doJSON(ctx, s.Client, method, endpoint, headers, in, out)
+}
+
+// ensureCollection creates the collection unless it exists
+func (s *QdrantStore) ensureCollection(ctx context.Context, dims int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.created {
+		return nil
+	}
+
+	err := s.do(ctx, http.MethodGet, "", nil, nil)
+	if errors.Is(err, mcperr.ErrNotFound) {
+		err = s.do(ctx, http.MethodPut, "", map[string]interface{}{
+			"vectors": map[string]interface{}{"size": dims, "distance": "Cosine"},
+		}, nil)
+	}
+	if err != nil {
+		return err
+	}
+	s.created = true
+	return nil
+}
+
+// Upsert implements Store
+func (s *QdrantStore) Upsert(ctx context.Context, namespace string, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if err := s.ensureCollection(ctx, len(records[0].Vector)); err != nil {
+		return err
+	}
+
+	points := make([]map[string]interface{}, len(records))
+	for i, r := range records {
+		points[i] = map[string]interface{}{
+			"id":     pointID(namespace, r.ID),
+			"vector": r.Vector,
+			"payload": map[string]interface{}{
+				qdrantNamespace: namespace,
+				qdrantID:        r.ID,
+				qdrantText:      r.Text,
+				qdrantMetadata:  r.Metadata,
+			},
+		}
+	}
+	return s.do(ctx, http.MethodPut, "/points?wait=true", map[string]interface{}{"points": points}, nil)
+}
+
+// Search implements Store
+func (s *QdrantStore) Search(ctx context.Context, namespace string, q Query) ([]Match, error) {
+	req := map[string]interface{}{
+		"vector":       q.Vector,
+		"limit":        q.TopK,
+		"with_payload": true,
+		"filter":       namespaceFilter(namespace, q.Filter),
+	}
+	if q.MinScore > -1 {
+		req["score_threshold"] = q.MinScore
+	}
+
+	var resp struct {
+		Result []struct {
+			Score   float64                `json:"score"`
+			Payload map[string]interface{} `json:"payload"`
+		} `json:"result"`
+	}
+	err := s.do(ctx, http.MethodPost, "/points/search", req, &resp)
+	if errors.Is(err, mcperr.ErrNotFound) {
+		return nil, nil // nothing upserted yet
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]Match, 0, len(resp.Result))
+	for _, r := range resp.Result {
+		m := Match{Score: r.Score}
+		m.ID, _ = r.Payload[qdrantID].(string)
+		m.Text, _ = r.Payload[qdrantText].(string)
+		m.Metadata, _ = r.Payload[qdrantMetadata].(map[string]interface{})
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
+
+// DeleteNamespace implements Store
+func (s *QdrantStore) DeleteNamespace(ctx context.Context, namespace string) (int, error) {
+	filter := namespaceFilter(namespace, nil)
+
+	var count struct {
+		Result struct {
+			Count int `json:"count"`
+		} `json:"result"`
+	}
+	err := s.do(ctx, http.MethodPost, "/points/count", map[string]interface{}{"filter": filter, "exact": true}, &count)
+	if errors.Is(err, mcperr.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := s.do(ctx, http.MethodPost, "/points/delete?wait=true", map[string]interface{}{"filter": filter}, nil); err != nil {
+		return 0, err
+	}
+	return count.Result.Count, nil
+}
+
+// Ping implements Pinger
+func (s *QdrantStore) Ping(ctx context.Context) error {
+	err := s.do(ctx, http.MethodGet, "", nil, nil)
+	if errors.Is(err, mcperr.ErrNotFound) {
+		return nil // created on the first upsert
+	}
+	return err
+}
+
+// Close implements Store
+func (s *QdrantStore) Close() error {
+	return nil
+}
+
+// namespaceFilter matches a namespace's points whose metadata has the
+// filter values
+func namespaceFilter(namespace string, filter map[string]interface{}) map[string]interface{} {
+	must := []interface{}{
+		map[string]interface{}{"key": qdrantNamespace, "match": map[string]interface{}{"value": namespace}},
+	}
+	for k, v := range filter {
+		must = append(must, map[string]interface{}{
+			"key":   qdrantMetadata + "." + k,
+			"match": map[string]interface{}{"value": v},
+		})
+	}
+	return map[string]interface{}{"must": must}
+}
+
+// pointID derives a UUID from a namespace and document ID, as Qdrant
+// point IDs must be integers or UUIDs
+func pointID(namespace, id string) string {
+	sum := sha1.Sum([]byte(namespace + "\x00" + id))
+	sum[6] = sum[6]&0x0f | 0x50 // version 5
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
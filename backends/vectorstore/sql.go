@@ -0,0 +1,322 @@
+package vectorstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// Dialect is a SQL vector extension
+type Dialect string
+
+const (
+	// DialectPgvector stores documents in PostgreSQL with pgvector
+	DialectPgvector Dialect = "pgvector"
+
+	// DialectSQLiteVec stores documents in SQLite with sqlite-vec
+	DialectSQLiteVec Dialect = "sqlite-vec"
+)
+
+// sqliteOverfetch multiplies sqlite-vec's k so namespace and metadata
+// filtering, applied after the KNN query, still fill TopK
+const sqliteOverfetch = 4
+
+// validTable restricts table names, which can't be bound as parameters
+var validTable = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLStore keeps documents in a SQL database with a vector extension
+//
+// The database/sql driver is not linked in; import one and name it in
+// the config:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"      // driver: pgx
+//	import _ "github.com/mattn/go-sqlite3"         // driver: sqlite3 (load sqlite-vec)
+//
+// Tables are created on the first upsert, sized to its vectors.
+type SQLStore struct {
+	db      *sql.DB
+	dialect Dialect
+	table   string
+
+	mu      sync.Mutex
+	created bool
+}
+
+// NewSQLStore creates a store over db
+func NewSQLStore(db *sql.DB, dialect Dialect, table string) (*SQLStore, error) {
+	if dialect != DialectPgvector && dialect != DialectSQLiteVec {
+		return nil, fmt.Errorf("vectorstore: unknown SQL dialect %q", dialect)
+	}
+	if table == "" {
+		table = "documents"
+	}
+	if !validTable.MatchString(table) {
+		return nil, fmt.Errorf("vectorstore: invalid table name %q", table)
+	}
+	return &SQLStore{db: db, dialect: dialect, table: table}, nil
+}
+
+func newSQLFromConfig(dialect Dialect, config map[string]interface{}) (Store, error) {
+	driver := backend.StringConfig(config, "driver", "")
+	dsn := backend.StringConfig(config, "dsn", "")
+	if driver == "" || dsn == "" {
+		return nil, fmt.Errorf("vectorstore: %s store needs a driver and a dsn", dialect)
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("vectorstore: open %s: %w", driver, err)
+	}
+	s, err := NewSQLStore(db, dialect, backend.StringConfig(config, "table", ""))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// arg returns the n-th (1-based) placeholder
+func (s *SQLStore) arg(n int) string {
+	if s.dialect == DialectPgvector {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// ensureTables creates the store's tables unless they exist
+func (s *SQLStore) ensureTables(ctx context.Context, dims int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.created {
+		return nil
+	}
+
+	var stmts []string
+	switch s.dialect {
+	case DialectPgvector:
+		stmts = []string{
+			"CREATE EXTENSION IF NOT EXISTS vector",
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				namespace TEXT NOT NULL,
+				id TEXT NOT NULL,
+				content TEXT NOT NULL,
+				metadata JSONB NOT NULL DEFAULT '{}',
+				embedding vector(%d) NOT NULL,
+				PRIMARY KEY (namespace, id))`, s.table, dims),
+		}
+	case DialectSQLiteVec:
+		stmts = []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				rowid INTEGER PRIMARY KEY,
+				namespace TEXT NOT NULL,
+				id TEXT NOT NULL,
+				content TEXT NOT NULL,
+				metadata TEXT NOT NULL DEFAULT '{}',
+				UNIQUE (namespace, id))`, s.table),
+			fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s_vec USING vec0(embedding float[%d] distance_metric=cosine)", s.table, dims),
+		}
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("vectorstore: create tables: %w", err)
+		}
+	}
+	s.created = true
+	return nil
+}
+
+// Upsert implements Store
+func (s *SQLStore) Upsert(ctx context.Context, namespace string, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if err := s.ensureTables(ctx, len(records[0].Vector)); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, r := range records {
+		metadata, err := json.Marshal(nonNil(r.Metadata))
+		if err != nil {
+			return err
+		}
+		if err := s.upsertRecord(ctx, tx, namespace, r, string(metadata)); err != nil {
+			return fmt.Errorf("vectorstore: upsert %q: %w", r.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// upsertRecord writes one record within tx
+func (s *SQLStore) upsertRecord(ctx context.Context, tx *sql.Tx, namespace string, r Record, metadata string) error {
+	vector := vectorLiteral(r.Vector)
+
+	if s.dialect == DialectPgvector {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (namespace, id, content, metadata, embedding)
+			VALUES ($1, $2, $3, $4::jsonb, $5::vector)
+			ON CONFLICT (namespace, id) DO UPDATE
+			SET content = EXCLUDED.content, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding`, s.table),
+			namespace, r.ID, r.Text, metadata, vector)
+		return err
+	}
+
+	// vec0 tables don't support upserts: replace both rows
+	if err := s.deleteRows(ctx, tx, "namespace = ? AND id = ?", namespace, r.ID); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (namespace, id, content, metadata) VALUES (?, ?, ?, ?)", s.table),
+		namespace, r.ID, r.Text, metadata)
+	if err != nil {
+		return err
+	}
+	rowid, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s_vec (rowid, embedding) VALUES (?, ?)", s.table), rowid, vector)
+	return err
+}
+
+// deleteRows removes the sqlite-vec documents matching where
+func (s *SQLStore) deleteRows(ctx context.Context, tx *sql.Tx, where string, args ...interface{}) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s_vec WHERE rowid IN (SELECT rowid FROM %s WHERE %s)", s.table, s.table, where), args...); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", s.table, where), args...)
+	return err
+}
+
+// Search implements Store
+func (s *SQLStore) Search(ctx context.Context, namespace string, q Query) ([]Match, error) {
+	vector := vectorLiteral(q.Vector)
+
+	var query string
+	var args []interface{}
+	if s.dialect == DialectPgvector {
+		query = fmt.Sprintf(`SELECT id, content, metadata, 1 - (embedding <=> $1::vector) AS score
+			FROM %s WHERE namespace = $2`, s.table)
+		args = []interface{}{vector, namespace}
+		if len(q.Filter) > 0 {
+			filter, err := json.Marshal(q.Filter)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, string(filter))
+			query += " AND metadata @> " + s.arg(len(args)) + "::jsonb"
+		}
+		args = append(args, q.TopK)
+		query += " ORDER BY embedding <=> $1::vector LIMIT " + s.arg(len(args))
+	} else {
+		query = fmt.Sprintf(`SELECT d.id, d.content, d.metadata, 1 - v.distance AS score
+			FROM %s_vec v JOIN %s d ON d.rowid = v.rowid
+			WHERE v.embedding MATCH ? AND k = ? AND d.namespace = ?
+			ORDER BY v.distance`, s.table, s.table)
+		args = []interface{}{vector, q.TopK * sqliteOverfetch, namespace}
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		if isMissingTable(err) {
+			return nil, nil // nothing upserted yet
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []Match
+	for rows.Next() {
+		var m Match
+		var metadata string
+		if err := rows.Scan(&m.ID, &m.Text, &metadata, &m.Score); err != nil {
+			return nil, err
+		}
+		if metadata != "" {
+			json.Unmarshal([]byte(metadata), &m.Metadata)
+		}
+		if m.Score < q.MinScore || !matchesFilter(m.Metadata, q.Filter) {
+			continue
+		}
+		matches = append(matches, m)
+		if len(matches) == q.TopK {
+			break
+		}
+	}
+	return matches, rows.Err()
+}
+
+// DeleteNamespace implements Store
+func (s *SQLStore) DeleteNamespace(ctx context.Context, namespace string) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE namespace = %s", s.table, s.arg(1)), namespace).Scan(&count)
+	if err != nil {
+		if isMissingTable(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if s.dialect == DialectPgvector {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE namespace = $1", s.table), namespace)
+	} else {
+		err = s.deleteRows(ctx, tx, "namespace = ?", namespace)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+// Ping implements Pinger
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Close closes the database
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// vectorLiteral formats a vector as both extensions accept it: [1,2,3]
+func vectorLiteral(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// isMissingTable reports whether err is about a table not created yet
+func isMissingTable(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "no such table") || strings.Contains(msg, "does not exist")
+}
+
+// nonNil returns m, or an empty map for nil
+func nonNil(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
@@ -0,0 +1,231 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+// ErrDimensions is returned when a vector's length differs from the
+// namespace's (e.g. after switching embedding models)
+var ErrDimensions = errors.New("vectorstore: vector dimensions mismatch")
+
+// ============================================================
+// Stores
+// ============================================================
+
+// Record is a document with its embedding
+type Record struct {
+	ID       string
+	Text     string
+	Metadata map[string]interface{}
+	Vector   []float32
+}
+
+// Query is a similarity search
+type Query struct {
+	Vector []float32
+
+	// TopK caps the number of matches
+	TopK int
+
+	// MinScore drops matches scoring lower (cosine similarity, -1 to 1;
+	// -1 keeps all)
+	MinScore float64
+
+	// Filter keeps documents whose metadata has all these values
+	Filter map[string]interface{}
+}
+
+// Match is a search result
+type Match struct {
+	ID       string                 `json:"id"`
+	Score    float64                `json:"score"`
+	Text     string                 `json:"text"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Store keeps embedded documents in namespaces
+// Upsert replaces documents with the same ID in the namespace; Search
+// returns matches best first.
+type Store interface {
+	Upsert(ctx context.Context, namespace string, records []Record) error
+	Search(ctx context.Context, namespace string, q Query) ([]Match, error)
+
+	// DeleteNamespace removes a namespace's documents, returning how many
+	// were removed (-1 if the store can't tell)
+	DeleteNamespace(ctx context.Context, namespace string) (int, error)
+
+	Close() error
+}
+
+// Pinger is implemented by stores that can check their connection; the
+// backend's readiness check uses it
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// StoreFactory creates a store from its config entry
+type StoreFactory func(config map[string]interface{}) (Store, error)
+
+var (
+	storesMu sync.RWMutex
+	stores   = map[string]StoreFactory{
+		"memory": func(map[string]interface{}) (Store, error) { return NewMemoryStore(), nil },
+		"qdrant": newQdrantFromConfig,
+		"pgvector": func(config map[string]interface{}) (Store, error) {
+			return newSQLFromConfig(DialectPgvector, config)
+		},
+		"sqlite-vec": func(config map[string]interface{}) (Store, error) {
+			return newSQLFromConfig(DialectSQLiteVec, config)
+		},
+	}
+)
+
+// RegisterStore makes a store type available to the store.type config
+// entry
+func RegisterStore(name string, factory StoreFactory) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	stores[name] = factory
+}
+
+// newStore creates a store of a registered type
+func newStore(name string, config map[string]interface{}) (Store, error) {
+	storesMu.RLock()
+	factory, ok := stores[name]
+	storesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("vectorstore: unknown store type %q", name)
+	}
+	return factory(config)
+}
+
+// ============================================================
+// Memory store
+// ============================================================
+
+// MemoryStore keeps documents in memory, searching them exhaustively
+// It suits development, tests and small corpora.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	namespaces map[string]map[string]Record
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{namespaces: make(map[string]map[string]Record)}
+}
+
+// Upsert implements Store
+func (s *MemoryStore) Upsert(ctx context.Context, namespace string, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	docs := s.namespaces[namespace]
+	if docs == nil {
+		docs = make(map[string]Record)
+		s.namespaces[namespace] = docs
+	}
+	dims := 0
+	for _, r := range docs {
+		dims = len(r.Vector)
+		break
+	}
+	for _, r := range records {
+		if dims == 0 {
+			dims = len(r.Vector)
+		}
+		if len(r.Vector) != dims {
+			return fmt.Errorf("%w: %q has %d, namespace %q has %d", ErrDimensions, r.ID, len(r.Vector), namespace, dims)
+		}
+	}
+	for _, r := range records {
+		docs[r.ID] = r
+	}
+	return nil
+}
+
+// Search implements Store
+func (s *MemoryStore) Search(ctx context.Context, namespace string, q Query) ([]Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matches []Match
+	for _, r := range s.namespaces[namespace] {
+		if !matchesFilter(r.Metadata, q.Filter) {
+			continue
+		}
+		score := cosine(q.Vector, r.Vector)
+		if score < q.MinScore {
+			continue
+		}
+		matches = append(matches, Match{ID: r.ID, Score: score, Text: r.Text, Metadata: r.Metadata})
+	}
+	sortMatches(matches)
+	if q.TopK > 0 && len(matches) > q.TopK {
+		matches = matches[:q.TopK]
+	}
+	return matches, nil
+}
+
+// DeleteNamespace implements Store
+func (s *MemoryStore) DeleteNamespace(ctx context.Context, namespace string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.namespaces[namespace])
+	delete(s.namespaces, namespace)
+	return n, nil
+}
+
+// Close implements Store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// ============================================================
+// Helpers
+// ============================================================
+
+// cosine returns the cosine similarity of two vectors (0 when their
+// lengths differ or either is zero)
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// matchesFilter reports whether metadata holds every filter value
+// Values compare by their string form, so 2 matches 2.0 from JSON.
+func matchesFilter(metadata, filter map[string]interface{}) bool {
+	for k, want := range filter {
+		got, ok := metadata[k]
+		if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortMatches orders matches best first, ties by ID
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].ID < matches[j].ID
+	})
+}
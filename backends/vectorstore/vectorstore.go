@@ -0,0 +1,350 @@
+// Package vectorstore is a retrieval backend for RAG servers
+//
+// Documents are embedded by a pluggable provider and kept in namespaces
+// of a pluggable store. Three tools are served:
+//
+//	upsert_documents  embed and store documents, replacing same IDs
+//	semantic_search   documents closest to a query, streamed with scores
+//	delete_namespace  remove a namespace's documents
+//
+// Stores: memory (default), qdrant, pgvector and sqlite-vec; embedders:
+// openai (any OpenAI-compatible API), ollama and hash (local, word
+// overlap only, for development). More can be added with RegisterStore
+// and RegisterEmbedder. Importing the package registers the backend as
+// "vectorstore":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/vectorstore"
+//
+//	# config.yaml
+//	backend:
+//	  type: vectorstore
+//	  config:
+//	    store:
+//	      type: qdrant
+//	      url: http://localhost:6333
+//	      collection: docs
+//	    embedder:
+//	      type: openai
+//	      model: text-embedding-3-small
+//	      api_key: "${secret:env:OPENAI_API_KEY}"
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultNamespace  = "default"
+	DefaultTopK       = 5
+	DefaultMaxResults = 100
+	DefaultBatchSize  = 64
+
+	// MaxDocuments bounds the documents of one upsert_documents call
+	MaxDocuments = 1000
+)
+
+func init() {
+	backend.Register("vectorstore", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves document upserts and semantic search
+type Backend struct {
+	*backend.BaseBackend
+
+	store    Store
+	embedder Embedder
+	logger   *slog.Logger
+
+	defaultNamespace string
+	maxResults       int
+	batchSize        int
+}
+
+// New creates a vectorstore backend; Initialize creates its store and
+// embedder from the config
+func New() *Backend {
+	b := &Backend{
+		BaseBackend:      backend.NewBaseBackend("vectorstore"),
+		logger:           slog.Default(),
+		defaultNamespace: DefaultNamespace,
+		maxResults:       DefaultMaxResults,
+		batchSize:        DefaultBatchSize,
+	}
+	b.registerTools()
+	return b
+}
+
+// NewWithStore creates a vectorstore backend over an existing store and
+// embedder; the config's store and embedder entries are then ignored
+func NewWithStore(store Store, embedder Embedder) *Backend {
+	b := New()
+	b.store = store
+	b.embedder = embedder
+	return b
+}
+
+// Initialize creates the store and embedder
+//
+// Config entries:
+//
+//	store              {type: memory|qdrant|pgvector|sqlite-vec, ...} (default memory)
+//	embedder           {type: openai|ollama|hash, ...} (required unless hash)
+//	default_namespace  namespace of calls naming none (default "default")
+//	max_results        semantic_search top_k cap (default 100)
+//	batch_size         documents embedded per provider request (default 64)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	b.defaultNamespace = backend.StringConfig(config, "default_namespace", DefaultNamespace)
+	b.maxResults = backend.IntConfig(config, "max_results", DefaultMaxResults)
+	b.batchSize = backend.IntConfig(config, "batch_size", DefaultBatchSize)
+	if b.maxResults <= 0 || b.batchSize <= 0 {
+		return errors.New("vectorstore: max_results and batch_size must be positive")
+	}
+
+	if b.store == nil {
+		storeConfig, _ := config["store"].(map[string]interface{})
+		store, err := newStore(backend.StringConfig(storeConfig, "type", "memory"), storeConfig)
+		if err != nil {
+			return err
+		}
+		b.store = store
+	}
+
+	if b.embedder == nil {
+		embedderConfig, _ := config["embedder"].(map[string]interface{})
+		kind := backend.StringConfig(embedderConfig, "type", "")
+		if kind == "" {
+			b.store.Close()
+			return errors.New("vectorstore: embedder.type is required (openai, ollama or hash)")
+		}
+		embedder, err := newEmbedder(kind, embedderConfig)
+		if err != nil {
+			b.store.Close()
+			return err
+		}
+		b.embedder = embedder
+	}
+	return nil
+}
+
+// Close closes the store
+func (b *Backend) Close() error {
+	if b.store != nil {
+		return b.store.Close()
+	}
+	return nil
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Readiness pings stores that support it.
+func (b *Backend) RegisterHealthChecks(r *health.Registry) {
+	b.BaseBackend.RegisterHealthChecks(r)
+	r.RegisterReadiness("vectorstore:store", func(ctx context.Context) error {
+		if b.store == nil {
+			return backend.ErrNotInitialized
+		}
+		if pinger, ok := b.store.(Pinger); ok {
+			return pinger.Ping(ctx)
+		}
+		return nil
+	})
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+// Document is a document to upsert
+type Document struct {
+	ID       string                 `json:"id" description:"Unique ID within the namespace; upserting it again replaces the document"`
+	Text     string                 `json:"text" description:"Text to embed and return from searches"`
+	Metadata map[string]interface{} `json:"metadata,omitempty" description:"Values returned with matches and usable as search filters"`
+}
+
+type upsertArgs struct {
+	Namespace string     `json:"namespace,omitempty" description:"Namespace to store into (default: the configured default)"`
+	Documents []Document `json:"documents" description:"Documents to embed and store"`
+}
+
+type upsertResult struct {
+	Namespace string `json:"namespace"`
+	Upserted  int    `json:"upserted"`
+}
+
+type searchArgs struct {
+	Query     string                 `json:"query" description:"Text to find similar documents to"`
+	Namespace string                 `json:"namespace,omitempty" description:"Namespace to search (default: the configured default)"`
+	TopK      int                    `json:"top_k,omitempty" jsonschema:"minimum=1,default=5" description:"Number of matches"`
+	MinScore  *float64               `json:"min_score,omitempty" description:"Drop matches with a lower cosine similarity (-1 to 1)"`
+	Filter    map[string]interface{} `json:"filter,omitempty" description:"Only match documents whose metadata has these values"`
+}
+
+type deleteArgs struct {
+	Namespace string `json:"namespace" description:"Namespace to delete"`
+}
+
+type deleteResult struct {
+	Namespace string `json:"namespace"`
+
+	// Deleted is -1 when the store can't count
+	Deleted int `json:"deleted"`
+}
+
+func (b *Backend) registerTools() {
+	upsert := backend.NewTool("upsert_documents").
+		Description(fmt.Sprintf("Embed documents and store them in a namespace, replacing documents with the same ID (at most %d per call).", MaxDocuments)).
+		ParamsFromStruct(upsertArgs{}).
+		NonCacheable().
+		Build()
+	backend.RegisterTypedTool(b, upsert, b.upsertDocuments)
+
+	search := backend.NewTool("semantic_search").
+		Description("Find the documents most similar in meaning to a query. Matches stream best first with their cosine similarity score, text and metadata.").
+		ParamsFromStruct(searchArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(search, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[searchArgs](search, args)
+		if err != nil {
+			return err
+		}
+		return b.semanticSearch(ctx, in, emit)
+	})
+
+	remove := backend.NewTool("delete_namespace").
+		Description("Delete every document of a namespace. This cannot be undone.").
+		ParamsFromStruct(deleteArgs{}).
+		NonCacheable().
+		Build()
+	backend.RegisterTypedTool(b, remove, b.deleteNamespace)
+}
+
+// namespace returns the namespace a call names, or the default
+func (b *Backend) namespace(name string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return b.defaultNamespace
+}
+
+// upsertDocuments handles upsert_documents, embedding in batches and
+// reporting progress after each
+func (b *Backend) upsertDocuments(ctx context.Context, in upsertArgs) (*upsertResult, error) {
+	if b.store == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	if len(in.Documents) > MaxDocuments {
+		return nil, &backend.ArgumentError{Tool: "upsert_documents", Fields: []backend.FieldError{{Field: "documents", Message: fmt.Sprintf("at most %d per call", MaxDocuments)}}}
+	}
+	seen := make(map[string]bool, len(in.Documents))
+	for i, doc := range in.Documents {
+		field := fmt.Sprintf("documents[%d]", i)
+		switch {
+		case doc.ID == "":
+			return nil, &backend.ArgumentError{Tool: "upsert_documents", Fields: []backend.FieldError{{Field: field + ".id", Message: "required"}}}
+		case strings.TrimSpace(doc.Text) == "":
+			return nil, &backend.ArgumentError{Tool: "upsert_documents", Fields: []backend.FieldError{{Field: field + ".text", Message: "must not be empty"}}}
+		case seen[doc.ID]:
+			return nil, &backend.ArgumentError{Tool: "upsert_documents", Fields: []backend.FieldError{{Field: field + ".id", Message: "duplicate " + doc.ID}}}
+		}
+		seen[doc.ID] = true
+	}
+
+	namespace := b.namespace(in.Namespace)
+	total := int64(len(in.Documents))
+	for start := 0; start < len(in.Documents); start += b.batchSize {
+		batch := in.Documents[start:min(start+b.batchSize, len(in.Documents))]
+
+		texts := make([]string, len(batch))
+		for i, doc := range batch {
+			texts[i] = doc.Text
+		}
+		vectors, err := b.embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("embed documents: %w", err)
+		}
+
+		records := make([]Record, len(batch))
+		for i, doc := range batch {
+			records[i] = Record{ID: doc.ID, Text: doc.Text, Metadata: doc.Metadata, Vector: vectors[i]}
+		}
+		if err := b.store.Upsert(ctx, namespace, records); err != nil {
+			return nil, err
+		}
+
+		done := int64(start + len(batch))
+		backend.EmitProgress(ctx, done, total, fmt.Sprintf("Upserted %d/%d documents", done, total))
+	}
+
+	b.logger.Debug("documents upserted", "namespace", namespace, "count", len(in.Documents))
+	return &upsertResult{Namespace: namespace, Upserted: len(in.Documents)}, nil
+}
+
+// semanticSearch handles semantic_search, emitting one Match per result
+func (b *Backend) semanticSearch(ctx context.Context, in searchArgs, emit backend.StreamingEmitter) error {
+	if b.store == nil {
+		return backend.ErrNotInitialized
+	}
+	query := strings.TrimSpace(in.Query)
+	if query == "" {
+		return &backend.ArgumentError{Tool: "semantic_search", Fields: []backend.FieldError{{Field: "query", Message: "must not be empty"}}}
+	}
+	topK := in.TopK
+	if topK <= 0 {
+		topK = DefaultTopK
+	}
+	topK = min(topK, b.maxResults)
+	minScore := -1.0
+	if in.MinScore != nil {
+		minScore = *in.MinScore
+	}
+
+	vectors, err := b.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return fmt.Errorf("embed query: %w", err)
+	}
+
+	matches, err := b.store.Search(ctx, b.namespace(in.Namespace), Query{
+		Vector:   vectors[0],
+		TopK:     topK,
+		MinScore: minScore,
+		Filter:   in.Filter,
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if err := emit.EmitData(m); err != nil {
+			return err
+		}
+	}
+	return emit.EmitProgress(int64(len(matches)), int64(len(matches)), fmt.Sprintf("%d matches", len(matches)))
+}
+
+// deleteNamespace handles delete_namespace
+func (b *Backend) deleteNamespace(ctx context.Context, in deleteArgs) (*deleteResult, error) {
+	if b.store == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	namespace := strings.TrimSpace(in.Namespace)
+	if namespace == "" {
+		return nil, &backend.ArgumentError{Tool: "delete_namespace", Fields: []backend.FieldError{{Field: "namespace", Message: "must not be empty"}}}
+	}
+
+	deleted, err := b.store.DeleteNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	b.logger.Info("namespace deleted", "namespace", namespace, "documents", deleted)
+	return &deleteResult{Namespace: namespace, Deleted: deleted}, nil
+}
@@ -0,0 +1,304 @@
+package vectorstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backends/vectorstore"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+type captureEmitter struct {
+	ctx     context.Context
+	matches []vectorstore.Match
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	e.matches = append(e.matches, data.(vectorstore.Match))
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error { return nil }
+func (e *captureEmitter) Context() context.Context                                { return e.ctx }
+
+func newBackend(t *testing.T) *vectorstore.Backend {
+	t.Helper()
+	b := vectorstore.New()
+	if err := b.Initialize(context.Background(), map[string]interface{}{
+		"embedder": map[string]interface{}{"type": "hash"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func upsert(t *testing.T, b backend.ServerBackend, namespace string, docs ...map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	list := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		list[i] = doc
+	}
+	result, err := b.CallTool(context.Background(), "upsert_documents", map[string]interface{}{
+		"namespace": namespace,
+		"documents": list,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result)
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func search(t *testing.T, b backend.ServerBackend, args map[string]interface{}) []vectorstore.Match {
+	t.Helper()
+	emit := &captureEmitter{ctx: context.Background()}
+	if err := b.CallStreamingTool(context.Background(), "semantic_search", args, emit); err != nil {
+		t.Fatal(err)
+	}
+	return emit.matches
+}
+
+func doc(id, text string, metadata map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"id": id, "text": text, "metadata": metadata}
+}
+
+func TestVectorstore_UpsertSearchDelete(t *testing.T) {
+	b := newBackend(t)
+
+	out := upsert(t, b, "kb",
+		doc("go", "Goroutines and channels make concurrency simple in Go", map[string]interface{}{"lang": "go"}),
+		doc("py", "Python decorators wrap functions", map[string]interface{}{"lang": "python"}),
+		doc("rs", "Rust ownership prevents data races", map[string]interface{}{"lang": "rust"}),
+	)
+	if out["upserted"] != float64(3) || out["namespace"] != "kb" {
+		t.Fatalf("upsert = %v", out)
+	}
+
+	matches := search(t, b, map[string]interface{}{"query": "go channels concurrency", "namespace": "kb", "top_k": 2})
+	if len(matches) != 2 || matches[0].ID != "go" || matches[0].Score <= matches[1].Score {
+		t.Fatalf("matches = %+v", matches)
+	}
+	if matches[0].Metadata["lang"] != "go" || !strings.HasPrefix(matches[0].Text, "Goroutines") {
+		t.Errorf("match = %+v", matches[0])
+	}
+
+	// Filters, score thresholds and namespaces narrow results
+	matches = search(t, b, map[string]interface{}{"query": "go channels", "namespace": "kb", "filter": map[string]interface{}{"lang": "python"}})
+	if len(matches) != 1 || matches[0].ID != "py" {
+		t.Errorf("filtered = %+v", matches)
+	}
+	if matches := search(t, b, map[string]interface{}{"query": "go channels", "namespace": "kb", "min_score": 0.99}); len(matches) != 0 {
+		t.Errorf("thresholded = %+v", matches)
+	}
+	if matches := search(t, b, map[string]interface{}{"query": "go channels"}); len(matches) != 0 {
+		t.Errorf("default namespace = %+v", matches)
+	}
+
+	// Upserting an ID replaces the document
+	upsert(t, b, "kb", doc("go", "Python generators yield values", nil))
+	matches = search(t, b, map[string]interface{}{"query": "goroutines channels", "namespace": "kb"})
+	if matches[0].ID == "go" && strings.HasPrefix(matches[0].Text, "Goroutines") {
+		t.Errorf("document not replaced: %+v", matches[0])
+	}
+
+	result, err := b.CallTool(context.Background(), "delete_namespace", map[string]interface{}{"namespace": "kb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(result); !strings.Contains(string(data), `"deleted":3`) {
+		t.Errorf("delete = %s", data)
+	}
+	if matches := search(t, b, map[string]interface{}{"query": "go", "namespace": "kb"}); len(matches) != 0 {
+		t.Errorf("after delete = %+v", matches)
+	}
+}
+
+func TestVectorstore_Validation(t *testing.T) {
+	b := newBackend(t)
+
+	for name, docs := range map[string][]interface{}{
+		"missing id": {map[string]interface{}{"text": "x"}},
+		"empty text": {map[string]interface{}{"id": "a", "text": " "}},
+		"duplicate":  {map[string]interface{}{"id": "a", "text": "x"}, map[string]interface{}{"id": "a", "text": "y"}},
+	} {
+		_, err := b.CallTool(context.Background(), "upsert_documents", map[string]interface{}{"documents": docs})
+		if !errors.Is(err, backend.ErrInvalidArguments) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+
+	if err := vectorstore.New().Initialize(context.Background(), map[string]interface{}{}); err == nil {
+		t.Error("missing embedder accepted")
+	}
+	if err := vectorstore.New().Initialize(context.Background(), map[string]interface{}{
+		"store":    map[string]interface{}{"type": "faiss"},
+		"embedder": map[string]interface{}{"type": "hash"},
+	}); err == nil {
+		t.Error("unknown store accepted")
+	}
+
+	store := vectorstore.NewMemoryStore()
+	store.Upsert(context.Background(), "ns", []vectorstore.Record{{ID: "a", Vector: []float32{1, 0}}})
+	err := store.Upsert(context.Background(), "ns", []vectorstore.Record{{ID: "b", Vector: []float32{1, 0, 0}}})
+	if !errors.Is(err, vectorstore.ErrDimensions) {
+		t.Errorf("dimension mismatch = %v", err)
+	}
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("request = %s %v", r.URL.Path, r.Header)
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "small" || len(req.Input) != 2 {
+			t.Errorf("body = %+v", req)
+		}
+		// Out of order, as the API allows
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	e := &vectorstore.OpenAIEmbedder{BaseURL: server.URL + "/v1", APIKey: "key", Model: "small"}
+	_, err := e.Embed(context.Background(), []string{"a", "b"})
+	if retryAfter, ok := mcperr.RetryAfter(err); !errors.Is(err, mcperr.ErrRateLimited) || !ok || retryAfter != 7*time.Second {
+		t.Errorf("rate limit = %v", err)
+	}
+
+	vectors, err := e.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("vectors = %v", vectors)
+	}
+}
+
+// fakeQdrant serves the subset of the Qdrant REST API the store uses,
+// scoring every point of the requested namespace 0.5
+type fakeQdrant struct {
+	mu      sync.Mutex
+	created bool
+	points  map[string]map[string]interface{}
+}
+
+func (q *fakeQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	namespace := func() string {
+		must := body["filter"].(map[string]interface{})["must"].([]interface{})
+		return must[0].(map[string]interface{})["match"].(map[string]interface{})["value"].(string)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/collections/docs":
+		if !q.created {
+			http.Error(w, `{"status":{"error":"Not found"}}`, http.StatusNotFound)
+			return
+		}
+	case r.Method == http.MethodPut && r.URL.Path == "/collections/docs":
+		q.created = true
+	case r.Method == http.MethodPut && r.URL.Path == "/collections/docs/points":
+		for _, p := range body["points"].([]interface{}) {
+			point := p.(map[string]interface{})
+			q.points[point["id"].(string)] = point["payload"].(map[string]interface{})
+		}
+	case r.URL.Path == "/collections/docs/points/search":
+		var result []interface{}
+		for _, payload := range q.points {
+			if payload["namespace"] == namespace() {
+				result = append(result, map[string]interface{}{"score": 0.5, "payload": payload})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+		return
+	case r.URL.Path == "/collections/docs/points/count":
+		n := 0
+		for _, payload := range q.points {
+			if payload["namespace"] == namespace() {
+				n++
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": map[string]interface{}{"count": n}})
+		return
+	case r.URL.Path == "/collections/docs/points/delete":
+		ns := namespace()
+		for id, payload := range q.points {
+			if payload["namespace"] == ns {
+				delete(q.points, id)
+			}
+		}
+	default:
+		http.Error(w, "unexpected "+r.Method+" "+r.URL.Path, http.StatusBadRequest)
+		return
+	}
+	w.Write([]byte(`{"result":true}`))
+}
+
+func TestQdrantStore(t *testing.T) {
+	fake := &fakeQdrant{points: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	b := vectorstore.New()
+	if err := b.Initialize(context.Background(), map[string]interface{}{
+		"store":    map[string]interface{}{"type": "qdrant", "url": server.URL, "collection": "docs"},
+		"embedder": map[string]interface{}{"type": "hash", "dimensions": 16},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	upsert(t, b, "a", doc("one", "first document", map[string]interface{}{"tag": "x"}), doc("two", "second document", nil))
+	upsert(t, b, "b", doc("one", "same id, other namespace", nil))
+	if len(fake.points) != 3 || !fake.created {
+		t.Fatalf("points = %d, created = %v", len(fake.points), fake.created)
+	}
+	for id := range fake.points {
+		if len(id) != 36 || id[14] != '5' {
+			t.Errorf("point id %q is not a v5 UUID", id)
+		}
+	}
+
+	matches := search(t, b, map[string]interface{}{"query": "document", "namespace": "a"})
+	if len(matches) != 2 || matches[0].Score != 0.5 {
+		t.Fatalf("matches = %+v", matches)
+	}
+	for _, m := range matches {
+		if m.ID == "one" && m.Metadata["tag"] != "x" {
+			t.Errorf("match = %+v", m)
+		}
+	}
+
+	result, err := b.CallTool(context.Background(), "delete_namespace", map[string]interface{}{"namespace": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(result); !strings.Contains(string(data), `"deleted":2`) || len(fake.points) != 1 {
+		t.Errorf("delete = %s, %d points left", data, len(fake.points))
+	}
+}
@@ -0,0 +1,284 @@
+package webfetch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"golang.org/x/net/html/charset"
+)
+
+// maxRedirects bounds the redirects one fetch follows
+const maxRedirects = 10
+
+// errPrivateAddress is returned when a host resolves to an address the
+// backend may not connect to
+var errPrivateAddress = errors.New("private address")
+
+// newClient creates the HTTP client of the backend
+// Unless allowPrivate, connections to loopback, private and link-local
+// addresses are refused at dial time, after DNS resolution, so neither
+// a hostname nor a redirect can reach internal services. Proxies are
+// not used, since the check would then see only the proxy.
+func newClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivate(ip) {
+				return fmt.Errorf("%w %s", errPrivateAddress, host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
+
+// isPrivate reports whether ip is not a public unicast address
+func isPrivate(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
+
+// ============================================================
+// Domain policy
+// ============================================================
+
+// domainPolicy restricts the hosts the backend fetches from
+// A pattern "example.com" matches the domain and its subdomains;
+// "*.example.com" only the subdomains.
+type domainPolicy struct {
+	allowed []string
+	denied  []string
+}
+
+func matchDomain(host, pattern string) bool {
+	pattern = strings.ToLower(strings.TrimSuffix(pattern, "."))
+	if sub, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+sub)
+	}
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
+
+// check returns a permission_denied error if host may not be fetched
+func (p domainPolicy) check(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range p.denied {
+		if matchDomain(host, pattern) {
+			return mcperr.PermissionDenied("webfetch: %s is in denied_domains", host).
+				WithDetail("policy", "denied_domains")
+		}
+	}
+	if len(p.allowed) == 0 {
+		return nil
+	}
+	for _, pattern := range p.allowed {
+		if matchDomain(host, pattern) {
+			return nil
+		}
+	}
+	return mcperr.PermissionDenied("webfetch: %s is not in allowed_domains", host).
+		WithDetail("policy", "allowed_domains")
+}
+
+// ============================================================
+// Requests
+// ============================================================
+
+// parseURL parses an absolute http(s) URL from a tool argument
+func parseURL(tool, raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, backend.NewArgumentError(tool, "url", "must be an absolute http or https URL")
+	}
+	u.Fragment = ""
+	return u, nil
+}
+
+// permit checks a URL against the domain policy and robots.txt
+func (b *Backend) permit(ctx context.Context, u *url.URL) error {
+	if err := b.domains.check(u.Hostname()); err != nil {
+		return err
+	}
+	// robots.txt itself is always fetchable; checking it would recurse
+	if !b.respectRobots || u.Path == "/robots.txt" {
+		return nil
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	rules, err := b.robots.rules(ctx, b.client, b.userAgent, u)
+	if err != nil {
+		return requestError(ctx, u, err)
+	}
+	if !rules.allows(path) {
+		return mcperr.PermissionDenied("webfetch: robots.txt of %s disallows %s", u.Host, path).
+			WithDetail("policy", "robots.txt")
+	}
+	return nil
+}
+
+// checkRedirect applies the fetch policy to every redirect target
+func (b *Backend) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return mcperr.New(mcperr.CodeUpstream, "webfetch: stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return mcperr.New(mcperr.CodeUpstream, "webfetch: redirect to unsupported scheme %s", req.URL.Scheme)
+	}
+	return b.permit(req.Context(), req.URL)
+}
+
+// open fetches a URL the policy permits, returning a 2xx response
+// The caller closes the body.
+func (b *Backend) open(ctx context.Context, u *url.URL, accept string) (*http.Response, error) {
+	if err := b.permit(ctx, u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", b.userAgent)
+	req.Header.Set("Accept", accept)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, requestError(ctx, u, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		return nil, statusError(resp)
+	}
+	return resp, nil
+}
+
+// requestError maps a failed request to a categorized error
+func requestError(ctx context.Context, u *url.URL, err error) error {
+	if categorized, ok := mcperr.As(err); ok && !errors.Is(err, context.DeadlineExceeded) {
+		return categorized // from checkRedirect
+	}
+	switch {
+	case errors.Is(err, errPrivateAddress):
+		return mcperr.PermissionDenied("webfetch: %s resolves to a private address", u.Hostname()).
+			WithDetail("policy", "allow_private")
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return mcperr.Timeout("webfetch: %s timed out", u.Redacted())
+	case ctx.Err() != nil:
+		return ctx.Err()
+	}
+	return mcperr.Upstream(err, "webfetch: GET %s", u.Redacted())
+}
+
+// statusError maps a non-2xx response to a categorized error
+func statusError(resp *http.Response) error {
+	u := resp.Request.URL.Redacted()
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return mcperr.NotFound("webfetch: %s: %s", u, resp.Status)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return mcperr.PermissionDenied("webfetch: %s: %s", u, resp.Status)
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		var retry time.Duration
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retry = time.Duration(s) * time.Second
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || retry > 0 {
+			return mcperr.RateLimited(retry, "webfetch: %s: %s", u, resp.Status)
+		}
+	}
+	return mcperr.Upstream(fmt.Errorf("%s", resp.Status), "webfetch: GET %s", u)
+}
+
+// ============================================================
+// Bodies
+// ============================================================
+
+// mediaType returns the media type of a response, "" if it has none
+func mediaType(resp *http.Response) string {
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return strings.ToLower(mt)
+}
+
+// isText reports whether a media type is text the tools can return
+func isText(mt string) bool {
+	switch {
+	case mt == "", strings.HasPrefix(mt, "text/"),
+		strings.HasSuffix(mt, "+xml"), strings.HasSuffix(mt, "+json"):
+		return true
+	}
+	switch mt {
+	case "application/json", "application/xml", "application/javascript",
+		"application/x-javascript", "application/ecmascript", "application/xhtml+xml":
+		return true
+	}
+	return false
+}
+
+// isHTML reports whether a media type is HTML; untyped bodies are taken as HTML
+func isHTML(mt string) bool {
+	return mt == "" || mt == "text/html" || mt == "application/xhtml+xml"
+}
+
+// decode returns a reader of body as UTF-8 and the name of its charset,
+// detected from the Content-Type header, a BOM or a <meta> element
+func decode(body io.Reader, contentType string) (io.Reader, string, error) {
+	br := bufio.NewReaderSize(body, 1024)
+	peek, _ := br.Peek(1024)
+	_, name, _ := charset.DetermineEncoding(peek, contentType)
+	r, err := charset.NewReaderLabel(name, br)
+	if err != nil {
+		return nil, "", mcperr.Upstream(err, "webfetch: charset %s", name)
+	}
+	return r, name, nil
+}
+
+// readPage reads at most limit bytes of a response body as UTF-8
+// truncated reports whether the body was longer.
+func readPage(resp *http.Response, limit int64) (text string, truncated bool, err error) {
+	r, _, err := decode(io.LimitReader(resp.Body, limit), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return "", false, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", false, mcperr.Upstream(err, "webfetch: read %s", resp.Request.URL.Redacted())
+	}
+	var probe [1]byte
+	n, _ := io.ReadFull(resp.Body, probe[:])
+	return string(data), n > 0, nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
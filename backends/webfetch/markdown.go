@@ -0,0 +1,463 @@
+package webfetch
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skipped are elements whose content is never page text
+var skipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true,
+	atom.Template: true, atom.Svg: true, atom.Iframe: true, atom.Object: true,
+	atom.Canvas: true, atom.Button: true, atom.Select: true, atom.Textarea: true,
+}
+
+// blocks are elements that start and end a paragraph
+var blocks = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Nav: true, atom.Figure: true, atom.Figcaption: true, atom.Address: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Details: true,
+	atom.Summary: true, atom.Form: true, atom.Fieldset: true,
+}
+
+// ============================================================
+// Documents
+// ============================================================
+
+// document is a parsed page
+type document struct {
+	root *html.Node
+	base *url.URL // for resolving relative links
+}
+
+// parseDocument parses a page fetched from pageURL, which may be nil
+func parseDocument(text string, pageURL *url.URL) (*document, error) {
+	root, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	doc := &document{root: root, base: pageURL}
+	if n := find(root, atom.Base); n != nil && doc.base != nil {
+		if href, err := doc.base.Parse(attr(n, "href")); err == nil {
+			doc.base = href
+		}
+	}
+	return doc, nil
+}
+
+// resolve returns ref as an absolute URL, or ref itself without a base
+func (d *document) resolve(ref string) string {
+	ref = strings.TrimSpace(ref)
+	if d.base == nil {
+		return ref
+	}
+	u, err := d.base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// title returns the text of the page's <title>
+func (d *document) title() string {
+	if n := find(d.root, atom.Title); n != nil {
+		return collapse(textOf(n))
+	}
+	return ""
+}
+
+// content returns the element holding the page's main content: its
+// <main>, else its only <article>, else its <body>
+func (d *document) content() *html.Node {
+	if n := find(d.root, atom.Main); n != nil {
+		return n
+	}
+	var articles []*html.Node
+	walk(d.root, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Article {
+			articles = append(articles, n)
+			return false
+		}
+		return true
+	})
+	if len(articles) == 1 {
+		return articles[0]
+	}
+	if n := find(d.root, atom.Body); n != nil {
+		return n
+	}
+	return d.root
+}
+
+// Link is a hyperlink of a page
+type Link struct {
+	URL      string `json:"url"`
+	Text     string `json:"text,omitempty"`
+	Rel      string `json:"rel,omitempty"`
+	External bool   `json:"external"`
+}
+
+// links returns the page's http(s) links, resolved and without
+// fragments, each URL once
+func (d *document) links() []Link {
+	seen := map[string]bool{}
+	links := []Link{}
+	walk(d.root, func(n *html.Node) bool {
+		if n.Type != html.ElementNode || (n.DataAtom != atom.A && n.DataAtom != atom.Area) {
+			return true
+		}
+		href := attr(n, "href")
+		if href == "" || d.base == nil {
+			return true
+		}
+		u, err := d.base.Parse(strings.TrimSpace(href))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return true
+		}
+		u.Fragment = ""
+		if seen[u.String()] {
+			return true
+		}
+		seen[u.String()] = true
+		text := collapse(textOf(n))
+		if text == "" {
+			text = attr(n, "title")
+		}
+		links = append(links, Link{
+			URL:      u.String(),
+			Text:     text,
+			Rel:      attr(n, "rel"),
+			External: !strings.EqualFold(u.Hostname(), d.base.Hostname()),
+		})
+		return false
+	})
+	return links
+}
+
+// ============================================================
+// Markdown
+// ============================================================
+
+// markdown converts an element to Markdown
+func (d *document) markdown(n *html.Node) string {
+	c := &converter{doc: d}
+	c.children(n)
+	c.flush()
+	return strings.TrimSpace(c.out.String()) + "\n"
+}
+
+// converter writes Markdown blocks
+// Inline content collects in a paragraph until a block element ends it;
+// prefix holds the quote markers and list indentation of the block.
+type converter struct {
+	doc    *document
+	out    strings.Builder
+	para   strings.Builder
+	space  bool   // whitespace is pending before the next word
+	prefix string // of every line of a block
+	marker string // replaces the prefix's end on a list item's first line
+	tight  bool   // the next block follows without a blank line
+	lists  []int  // the next number of each open list; -1 if unordered
+}
+
+func (c *converter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		c.node(child)
+	}
+}
+
+func (c *converter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		c.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		c.children(n)
+		return
+	}
+	if skipped[n.DataAtom] || attr(n, "hidden") != "" || strings.EqualFold(attr(n, "aria-hidden"), "true") {
+		return
+	}
+
+	switch a := n.DataAtom; {
+	case a >= atom.H1 && a <= atom.H6 && len(n.Data) == 2:
+		level := int(n.Data[1] - '0')
+		c.flush()
+		if text := c.inline(n); text != "" {
+			c.block(strings.Repeat("#", level) + " " + text)
+		}
+	case blocks[a]:
+		c.flush()
+		c.children(n)
+		c.flush()
+	case a == atom.Br:
+		c.para.WriteString("  \n")
+		c.space = false
+	case a == atom.Hr:
+		c.flush()
+		c.block("---")
+	case a == atom.Pre:
+		c.flush()
+		fence := "```"
+		text := strings.TrimRight(textOf(n), "\n")
+		for strings.Contains(text, fence) {
+			fence += "`"
+		}
+		c.block(fence + "\n" + text + "\n" + fence)
+	case a == atom.Code || a == atom.Kbd || a == atom.Samp:
+		if text := collapse(textOf(n)); text != "" {
+			tick := "`"
+			if strings.Contains(text, "`") {
+				tick = "``"
+			}
+			c.write(tick + text + tick)
+		}
+	case a == atom.Strong || a == atom.B:
+		c.wrap(n, "**")
+	case a == atom.Em || a == atom.I:
+		c.wrap(n, "_")
+	case a == atom.Del || a == atom.S:
+		c.wrap(n, "~~")
+	case a == atom.A:
+		text := c.inline(n)
+		href := attr(n, "href")
+		if href == "" || strings.HasPrefix(strings.ToLower(strings.TrimSpace(href)), "javascript:") {
+			c.write(text)
+		} else if text != "" {
+			c.write("[" + text + "](" + c.doc.resolve(href) + ")")
+		}
+	case a == atom.Img:
+		if src := attr(n, "src"); src != "" {
+			c.write("![" + collapse(attr(n, "alt")) + "](" + c.doc.resolve(src) + ")")
+		}
+	case a == atom.Ul || a == atom.Ol:
+		c.flush()
+		next := -1
+		if a == atom.Ol {
+			next = 1
+			if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+				next = start
+			}
+		}
+		c.lists = append(c.lists, next)
+		c.children(n)
+		c.flush()
+		c.lists = c.lists[:len(c.lists)-1]
+	case a == atom.Li:
+		c.item(n)
+	case a == atom.Blockquote:
+		c.flush()
+		prefix := c.prefix
+		c.prefix += "> "
+		c.children(n)
+		c.flush()
+		c.prefix = prefix
+	case a == atom.Table:
+		c.flush()
+		c.table(n)
+	default:
+		c.children(n)
+	}
+}
+
+// item writes a list item, its first line marked
+func (c *converter) item(n *html.Node) {
+	c.flush()
+	marker := "- "
+	if depth := len(c.lists); depth > 0 && c.lists[depth-1] >= 0 {
+		marker = strconv.Itoa(c.lists[depth-1]) + ". "
+		c.lists[depth-1]++
+	}
+	// Items after the first follow without a blank line
+	c.tight = n.PrevSibling != nil && hasPreviousItem(n)
+	prefix := c.prefix
+	c.prefix += strings.Repeat(" ", len(marker))
+	c.marker = marker
+	c.children(n)
+	c.flush()
+	c.prefix, c.marker = prefix, ""
+}
+
+func hasPreviousItem(n *html.Node) bool {
+	for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode && s.DataAtom == atom.Li {
+			return true
+		}
+	}
+	return false
+}
+
+// table writes a table as a pipe table, its first row the header
+func (c *converter) table(n *html.Node) {
+	var rows [][]string
+	walk(n, func(row *html.Node) bool {
+		if row.Type != html.ElementNode || row.DataAtom != atom.Tr {
+			return true
+		}
+		var cells []string
+		for cell := row.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+				text := strings.ReplaceAll(c.inline(cell), "|", `\|`)
+				cells = append(cells, strings.ReplaceAll(text, "  \n", " "))
+			}
+		}
+		rows = append(rows, cells)
+		return false
+	})
+	if len(rows) == 0 {
+		return
+	}
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row))
+	}
+	var lines []string
+	for i, row := range rows {
+		for len(row) < width {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", width))
+		}
+	}
+	c.block(strings.Join(lines, "\n"))
+}
+
+// text adds a text node to the paragraph, collapsing whitespace
+func (c *converter) text(s string) {
+	if s != "" && isSpace(s[0]) {
+		c.space = true
+	}
+	for i, word := range strings.Fields(s) {
+		c.space = c.space || i > 0
+		c.write(word)
+	}
+	if s != "" && isSpace(s[len(s)-1]) {
+		c.space = true
+	}
+}
+
+// write adds Markdown to the paragraph, after a space if whitespace
+// came before it
+func (c *converter) write(s string) {
+	if s == "" {
+		return
+	}
+	if c.space && c.para.Len() > 0 && !strings.HasSuffix(c.para.String(), "\n") {
+		c.para.WriteByte(' ')
+	}
+	c.para.WriteString(s)
+	c.space = false
+}
+
+// wrap writes an element's inline content between delimiters
+func (c *converter) wrap(n *html.Node, delimiter string) {
+	if text := c.inline(n); text != "" {
+		c.write(delimiter + text + delimiter)
+	}
+}
+
+// inline returns the Markdown of an element's content as one paragraph
+func (c *converter) inline(n *html.Node) string {
+	para, space := c.para, c.space
+	c.para, c.space = strings.Builder{}, false
+	c.children(n)
+	text := strings.TrimSpace(c.para.String())
+	c.para, c.space = para, space
+	return text
+}
+
+// flush ends the paragraph
+func (c *converter) flush() {
+	text := strings.TrimSpace(c.para.String())
+	c.para.Reset()
+	c.space = false
+	if text != "" {
+		c.block(text)
+	}
+}
+
+// block writes a block, separated from the previous one by a blank line
+func (c *converter) block(text string) {
+	if c.out.Len() > 0 && !c.tight {
+		c.out.WriteString(strings.TrimRight(c.prefix[:len(c.prefix)-len(c.marker)], " ") + "\n")
+	}
+	c.tight = false
+	for i, line := range strings.Split(text, "\n") {
+		prefix := c.prefix
+		if i == 0 && c.marker != "" {
+			prefix = prefix[:len(prefix)-len(c.marker)] + c.marker
+		}
+		c.out.WriteString(strings.TrimRight(prefix+line, " ") + "\n")
+	}
+	c.marker = ""
+}
+
+// ============================================================
+// Node helpers
+// ============================================================
+
+// walk calls fn on n and its descendants, depth first, skipping the
+// descendants of nodes for which fn returns false
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+// find returns the first element of a kind
+func find(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(n *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if n.Type == html.ElementNode && n.DataAtom == a {
+			found = n
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// attr returns an attribute of an element
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textOf returns the text of a node and its descendants, as is
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(n *html.Node) bool {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		return true
+	})
+	return b.String()
+}
+
+// collapse joins the words of s with single spaces
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
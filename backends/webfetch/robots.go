@@ -0,0 +1,195 @@
+package webfetch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robots.txt files are kept this long; failed fetches are retried sooner
+const (
+	robotsTTL      = time.Hour
+	robotsErrorTTL = time.Minute
+	maxRobotsSize  = 512 << 10 // RFC 9309 asks crawlers to read at least 500 KiB
+)
+
+// robotsRules are the rules of a robots.txt that apply to one agent
+type robotsRules struct {
+	rules []robotsRule
+
+	// disallowAll is set when robots.txt could not be fetched for a
+	// server-side reason, which RFC 9309 treats as a full disallow
+	disallowAll bool
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int // of the pattern; the longest match decides
+	pattern *regexp.Regexp
+}
+
+// parseRobots parses a robots.txt for the agent with the given product token
+// The groups naming the token apply if there are any, else those for "*".
+func parseRobots(data []byte, token string) *robotsRules {
+	token = strings.ToLower(token)
+	var specific, wildcard []robotsRule
+	var forToken, forAll, inRules, sawToken bool
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				forToken, forAll, inRules = false, false, false
+			}
+			switch agent := strings.ToLower(value); agent {
+			case "*":
+				forAll = true
+			case token:
+				forToken, sawToken = true, true
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue // an empty disallow allows everything
+			}
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)}
+			if forToken {
+				specific = append(specific, rule)
+			}
+			if forAll {
+				wildcard = append(wildcard, rule)
+			}
+		}
+	}
+
+	if sawToken {
+		return &robotsRules{rules: specific}
+	}
+	return &robotsRules{rules: wildcard}
+}
+
+// robotsPattern compiles a path pattern, where * matches any characters
+// and a trailing $ anchors the end
+func robotsPattern(p string) *regexp.Regexp {
+	anchored := strings.HasSuffix(p, "$")
+	p = strings.TrimSuffix(p, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allows reports whether a path (with its query) may be fetched
+// The longest matching rule decides; on a tie, allow wins.
+func (r *robotsRules) allows(path string) bool {
+	if r.disallowAll {
+		return false
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	allowed, best := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > best || (rule.length == best && rule.allow) {
+			allowed, best = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
+
+// robotsCache fetches and keeps the robots.txt of each origin
+type robotsCache struct {
+	mu      sync.Mutex
+	entries map[string]robotsEntry
+}
+
+type robotsEntry struct {
+	rules   *robotsRules
+	expires time.Time
+}
+
+// rules returns the robots rules for u's origin, fetching them if needed
+// An error means the origin may not be reached at all.
+func (c *robotsCache) rules(ctx context.Context, client *http.Client, userAgent string, u *url.URL) (*robotsRules, error) {
+	origin := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	entry, ok := c.entries[origin]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.rules, nil
+	}
+
+	rules, ttl, err := fetchRobots(ctx, client, userAgent, origin)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]robotsEntry)
+	}
+	c.entries[origin] = robotsEntry{rules: rules, expires: time.Now().Add(ttl)}
+	c.mu.Unlock()
+	return rules, nil
+}
+
+// fetchRobots gets an origin's robots.txt
+// Following RFC 9309, a missing file (4xx) allows everything, while a
+// server error or an unreachable server disallows everything. Reaching
+// a private address is returned as an error instead, since the page
+// itself is off limits too.
+func fetchRobots(ctx context.Context, client *http.Client, userAgent, origin string) (*robotsRules, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return &robotsRules{disallowAll: true}, robotsErrorTTL, nil
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if errors.Is(err, errPrivateAddress) {
+		return nil, 0, err
+	}
+	if err != nil {
+		return &robotsRules{disallowAll: true}, robotsErrorTTL, nil
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+		if err != nil {
+			return &robotsRules{disallowAll: true}, robotsErrorTTL, nil
+		}
+		return parseRobots(data, productToken(userAgent)), robotsTTL, nil
+	case resp.StatusCode/100 == 4:
+		return &robotsRules{}, robotsTTL, nil
+	}
+	return &robotsRules{disallowAll: true}, robotsErrorTTL, nil
+}
+
+// productToken returns the name robots.txt groups use for a user agent:
+// its first word, without the version
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), " ")
+	token, _, _ = strings.Cut(token, "/")
+	return token
+}
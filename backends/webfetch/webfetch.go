@@ -0,0 +1,417 @@
+// Package webfetch is a web retrieval backend with crawler manners
+//
+// Tools:
+//
+//	fetch_url         a page's text, streamed in chunks as it downloads
+//	extract_links     the links of an HTML page, resolved to absolute URLs
+//	html_to_markdown  a page, or given HTML, as Markdown
+//
+// Every request, redirects and robots.txt included, is checked against
+// allowed_domains and denied_domains, and pages are only fetched where
+// the site's robots.txt lets the backend's user agent go. Connections to
+// loopback and private addresses are refused unless allow_private is
+// set, so the tools cannot be pointed at internal services. Bodies are
+// decoded to UTF-8 from their declared or sniffed charset and cut off at
+// max_size.
+//
+// Importing the package registers the backend as "webfetch":
+//
+//	import _ "github.com/SaherElMasry/go-mcp-framework/backends/webfetch"
+//
+//	# config.yaml
+//	backend:
+//	  type: webfetch
+//	  config:
+//	    user_agent: "docs-bot/1.0 (+https://example.com/bot)"
+//	    allowed_domains: [go.dev, pkg.go.dev]
+//	    max_size: 2097152
+package webfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"golang.org/x/net/html/atom"
+)
+
+// Defaults for unset config entries
+const (
+	DefaultUserAgent = "go-mcp-framework-webfetch/1.0"
+	DefaultMaxSize   = 5 << 20
+	DefaultTimeout   = 30 * time.Second
+	DefaultChunkSize = 32 << 10
+	DefaultMaxLinks  = 200
+)
+
+// Accept headers of the tools
+const (
+	acceptText = "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.5"
+	acceptHTML = "text/html,application/xhtml+xml;q=0.9"
+)
+
+func init() {
+	backend.Register("webfetch", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend fetches web pages on behalf of a model
+type Backend struct {
+	*backend.BaseBackend
+
+	logger  *slog.Logger
+	client  *http.Client
+	robots  robotsCache
+	domains domainPolicy
+
+	userAgent     string
+	respectRobots bool
+	maxSize       int64
+	timeout       time.Duration
+	chunkSize     int
+}
+
+// New creates a webfetch backend; Initialize configures it
+func New() *Backend {
+	b := &Backend{
+		BaseBackend:   backend.NewBaseBackend("webfetch"),
+		logger:        slog.Default(),
+		userAgent:     DefaultUserAgent,
+		respectRobots: true,
+		maxSize:       DefaultMaxSize,
+		timeout:       DefaultTimeout,
+		chunkSize:     DefaultChunkSize,
+	}
+	b.registerTools()
+	return b
+}
+
+// Initialize applies the config
+//
+// Config entries:
+//
+//	user_agent       User-Agent header; its first word is the robots.txt
+//	                 token (default "go-mcp-framework-webfetch/1.0")
+//	allowed_domains  domains that may be fetched, subdomains included
+//	                 (default: any)
+//	denied_domains   domains that may never be fetched
+//	respect_robots   obey robots.txt (default true)
+//	allow_private    allow loopback and private addresses (default false)
+//	max_size         bytes of a body read at most (default 5MB)
+//	timeout          longest fetch (default "30s")
+//	chunk_size       bytes of text per fetch_url chunk (default 32KB)
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	b.userAgent = backend.StringConfig(config, "user_agent", DefaultUserAgent)
+	b.domains = domainPolicy{
+		allowed: backend.StringsConfig(config, "allowed_domains"),
+		denied:  backend.StringsConfig(config, "denied_domains"),
+	}
+	if v, ok := config["respect_robots"].(bool); ok {
+		b.respectRobots = v
+	}
+	allowPrivate, _ := config["allow_private"].(bool)
+
+	if b.maxSize = int64(backend.IntConfig(config, "max_size", DefaultMaxSize)); b.maxSize <= 0 {
+		return errors.New("webfetch: max_size must be positive")
+	}
+	if b.chunkSize = backend.IntConfig(config, "chunk_size", DefaultChunkSize); b.chunkSize < utf8.UTFMax {
+		return fmt.Errorf("webfetch: chunk_size must be at least %d", utf8.UTFMax)
+	}
+	if s := backend.StringConfig(config, "timeout", ""); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("webfetch: invalid timeout %q", s)
+		}
+		b.timeout = d
+	}
+
+	client := newClient(allowPrivate)
+	client.CheckRedirect = b.checkRedirect
+	b.client = b.HTTPClient(client)
+
+	b.logger.Info("webfetch backend initialized",
+		"user_agent", b.userAgent,
+		"allowed_domains", len(b.domains.allowed),
+		"respect_robots", b.respectRobots,
+		"allow_private", allowPrivate)
+	return nil
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type fetchArgs struct {
+	URL      string `json:"url" description:"Absolute http or https URL"`
+	MaxBytes int    `json:"max_bytes,omitempty" jsonschema:"minimum=1" description:"Bytes of the body to read at most (default and cap: the backend's max_size)"`
+}
+
+// Page describes a fetched page; fetch_url emits it before the chunks
+type Page struct {
+	URL         string `json:"url"` // after redirects
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Charset     string `json:"charset"`
+	Length      int64  `json:"length,omitempty"` // declared, in bytes
+}
+
+// Chunk is a piece of a page's text, in order
+type Chunk struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+}
+
+type linksArgs struct {
+	URL          string `json:"url" description:"Absolute http or https URL of an HTML page"`
+	SameHostOnly bool   `json:"same_host_only,omitempty" description:"Only links to the page's own host"`
+	Limit        int    `json:"limit,omitempty" jsonschema:"minimum=1,default=200" description:"Most links to return, in page order"`
+}
+
+type linksResult struct {
+	URL       string `json:"url"`
+	Links     []Link `json:"links"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+type markdownArgs struct {
+	URL     string `json:"url,omitempty" description:"Page to fetch and convert"`
+	HTML    string `json:"html,omitempty" description:"HTML to convert instead of fetching a page"`
+	BaseURL string `json:"base_url,omitempty" description:"URL that relative links in html resolve against"`
+	Full    bool   `json:"full,omitempty" description:"Convert the whole body rather than its main content"`
+}
+
+type markdownResult struct {
+	URL       string `json:"url,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Markdown  string `json:"markdown"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+func (b *Backend) registerTools() {
+	fetch := backend.NewTool("fetch_url").
+		Description("Fetch a web page or text document and stream its text in chunks, decoded to UTF-8. The first item describes the response.").
+		ParamsFromStruct(fetchArgs{}).
+		Streaming(true).
+		NonCacheable().
+		Build()
+	b.RegisterStreamingTool(fetch, func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		in, err := backend.BindArguments[fetchArgs](fetch, args)
+		if err != nil {
+			return err
+		}
+		return b.fetchURL(ctx, in, emit)
+	})
+
+	backend.RegisterTypedTool(b, backend.NewTool("extract_links").
+		Description("List the links of an HTML page as absolute URLs with their text.").
+		ParamsFromStruct(linksArgs{}).
+		NonCacheable().
+		Build(), b.extractLinks)
+
+	backend.RegisterTypedTool(b, backend.NewTool("html_to_markdown").
+		Description("Convert a web page, or given HTML, to Markdown. Navigation and scripts are left out; by default only the main content is kept.").
+		ParamsFromStruct(markdownArgs{}).
+		NonCacheable().
+		Build(), b.htmlToMarkdown)
+}
+
+// fetchURL handles fetch_url, emitting a Page and then Chunks
+func (b *Backend) fetchURL(ctx context.Context, in fetchArgs, emit backend.StreamingEmitter) error {
+	if b.client == nil {
+		return backend.ErrNotInitialized
+	}
+	u, err := parseURL("fetch_url", in.URL)
+	if err != nil {
+		return err
+	}
+	limit := b.maxSize
+	if in.MaxBytes > 0 {
+		limit = min(limit, int64(in.MaxBytes))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	resp, err := b.open(ctx, u, acceptText)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	mt := mediaType(resp)
+	if !isText(mt) {
+		return mcperr.New(mcperr.CodeUpstream, "webfetch: %s is %s, not text", resp.Request.URL.Redacted(), mt)
+	}
+	counter := &countingReader{r: io.LimitReader(resp.Body, limit)}
+	r, name, err := decode(counter, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	if err := emit.EmitData(Page{
+		URL:         resp.Request.URL.String(),
+		Status:      resp.StatusCode,
+		ContentType: mt,
+		Charset:     name,
+		Length:      max(resp.ContentLength, 0),
+	}); err != nil {
+		return err
+	}
+
+	// Chunks end on rune boundaries; a split rune starts the next chunk
+	buf := make([]byte, b.chunkSize)
+	carry, index := 0, 0
+	for {
+		n, readErr := io.ReadFull(r, buf[carry:])
+		n += carry
+		end := n
+		if start := lastRuneStart(buf[:n]); readErr == nil && !utf8.FullRune(buf[start:n]) {
+			end = start
+		}
+		if end > 0 {
+			if err := emit.EmitData(Chunk{Index: index, Text: string(buf[:end])}); err != nil {
+				return err
+			}
+			index++
+			if err := emit.EmitProgress(counter.n, max(resp.ContentLength, 0), ""); err != nil {
+				return err
+			}
+		}
+		carry = copy(buf, buf[end:n])
+
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return requestError(ctx, u, ctxErr)
+			}
+			return mcperr.Upstream(readErr, "webfetch: read %s", resp.Request.URL.Redacted())
+		}
+	}
+
+	var probe [1]byte
+	if n, _ := io.ReadFull(resp.Body, probe[:]); n > 0 {
+		return emit.EmitProgress(counter.n, max(resp.ContentLength, 0),
+			fmt.Sprintf("truncated after %d bytes", limit))
+	}
+	return nil
+}
+
+// lastRuneStart returns the index of the start of the last rune in p
+func lastRuneStart(p []byte) int {
+	i := len(p) - 1
+	for i > 0 && !utf8.RuneStart(p[i]) {
+		i--
+	}
+	return max(i, 0)
+}
+
+// fetchHTML fetches and parses an HTML page
+func (b *Backend) fetchHTML(ctx context.Context, tool, raw string) (*document, bool, error) {
+	u, err := parseURL(tool, raw)
+	if err != nil {
+		return nil, false, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+	resp, err := b.open(ctx, u, acceptHTML)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if mt := mediaType(resp); !isHTML(mt) {
+		return nil, false, mcperr.New(mcperr.CodeUpstream, "webfetch: %s is %s, not HTML", resp.Request.URL.Redacted(), mt)
+	}
+	text, truncated, err := readPage(resp, b.maxSize)
+	if err != nil {
+		return nil, false, err
+	}
+	doc, err := parseDocument(text, resp.Request.URL)
+	if err != nil {
+		return nil, false, mcperr.Upstream(err, "webfetch: parse %s", resp.Request.URL.Redacted())
+	}
+	return doc, truncated, nil
+}
+
+// extractLinks handles extract_links
+func (b *Backend) extractLinks(ctx context.Context, in linksArgs) (*linksResult, error) {
+	if b.client == nil {
+		return nil, backend.ErrNotInitialized
+	}
+	doc, _, err := b.fetchHTML(ctx, "extract_links", in.URL)
+	if err != nil {
+		return nil, err
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = DefaultMaxLinks
+	}
+
+	result := &linksResult{URL: doc.base.String(), Links: []Link{}}
+	for _, link := range doc.links() {
+		if in.SameHostOnly && link.External {
+			continue
+		}
+		if len(result.Links) == limit {
+			result.Truncated = true
+			break
+		}
+		result.Links = append(result.Links, link)
+	}
+	return result, nil
+}
+
+// htmlToMarkdown handles html_to_markdown
+func (b *Backend) htmlToMarkdown(ctx context.Context, in markdownArgs) (*markdownResult, error) {
+	if (in.URL == "") == (in.HTML == "") {
+		return nil, backend.NewArgumentError("html_to_markdown", "url", "exactly one of url and html is required")
+	}
+
+	var doc *document
+	result := &markdownResult{}
+	if in.URL != "" {
+		if b.client == nil {
+			return nil, backend.ErrNotInitialized
+		}
+		var err error
+		if doc, result.Truncated, err = b.fetchHTML(ctx, "html_to_markdown", in.URL); err != nil {
+			return nil, err
+		}
+		result.URL = doc.base.String()
+	} else {
+		if int64(len(in.HTML)) > b.maxSize {
+			return nil, backend.NewArgumentError("html_to_markdown", "html", fmt.Sprintf("must be at most %d bytes", b.maxSize))
+		}
+		var base *url.URL
+		if in.BaseURL != "" {
+			u, err := parseURL("html_to_markdown", in.BaseURL)
+			if err != nil {
+				return nil, backend.NewArgumentError("html_to_markdown", "base_url", "must be an absolute http or https URL")
+			}
+			base = u
+		}
+		var err error
+		if doc, err = parseDocument(in.HTML, base); err != nil {
+			return nil, backend.NewArgumentError("html_to_markdown", "html", err.Error())
+		}
+	}
+
+	root := doc.content()
+	if in.Full {
+		if body := find(doc.root, atom.Body); body != nil {
+			root = body
+		}
+	}
+	result.Title = doc.title()
+	result.Markdown = doc.markdown(root)
+	return result, nil
+}
@@ -0,0 +1,303 @@
+package webfetch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/backends/webfetch"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+type captureEmitter struct {
+	ctx      context.Context
+	page     *webfetch.Page
+	chunks   []webfetch.Chunk
+	progress []string
+}
+
+func (e *captureEmitter) EmitData(data interface{}) error {
+	switch v := data.(type) {
+	case webfetch.Page:
+		e.page = &v
+	case webfetch.Chunk:
+		e.chunks = append(e.chunks, v)
+	}
+	return nil
+}
+func (e *captureEmitter) EmitProgress(current, total int64, message string) error {
+	e.progress = append(e.progress, message)
+	return nil
+}
+func (e *captureEmitter) Context() context.Context { return e.ctx }
+
+func (e *captureEmitter) text() string {
+	var b strings.Builder
+	for _, c := range e.chunks {
+		b.WriteString(c.Text)
+	}
+	return b.String()
+}
+
+const article = `<!DOCTYPE html>
+<html><head><title> Release notes </title><script>var x = 1;</script></head>
+<body>
+<nav><a href="/">Home</a></nav>
+<main>
+<h1>Version 2</h1>
+<p>The <strong>new</strong> parser is <a href="/docs/parser#top">documented</a>.</p>
+<ul><li>faster</li><li>smaller</li></ul>
+<pre>go get example.com/x</pre>
+<table><tr><th>Name</th><th>Size</th></tr><tr><td>x</td><td>1</td></tr></table>
+<p><a href="https://other.example/page">elsewhere</a> <a href="/docs/parser">again</a></p>
+</main>
+</body></html>`
+
+// newSite serves a small site with a robots.txt
+func newSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /private\nAllow: /private/open\n"))
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(article))
+	})
+	mux.HandleFunc("/latin", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+		w.Write([]byte("caf\xe9 cr\xe8me"))
+	})
+	mux.HandleFunc("/runes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(strings.Repeat("é€", 20)))
+	})
+	mux.HandleFunc("/big", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("a", 4096)))
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/private/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		u, _ := url.Parse(server.URL)
+		http.Redirect(w, r, "http://localhost:"+u.Port()+"/article", http.StatusFound)
+	})
+	return server
+}
+
+func newBackend(t *testing.T, config map[string]interface{}) *webfetch.Backend {
+	t.Helper()
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	if _, ok := config["allow_private"]; !ok {
+		config["allow_private"] = true
+	}
+	b := webfetch.New()
+	if err := b.Initialize(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func call(t *testing.T, b *webfetch.Backend, tool string, args map[string]interface{}) (map[string]interface{}, error) {
+	t.Helper()
+	result, err := b.CallTool(context.Background(), tool, args)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := json.Marshal(result)
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	return out, nil
+}
+
+func fetch(b *webfetch.Backend, args map[string]interface{}) (*captureEmitter, error) {
+	emit := &captureEmitter{ctx: context.Background()}
+	err := b.CallStreamingTool(context.Background(), "fetch_url", args, emit)
+	return emit, err
+}
+
+func TestFetchURL_Charset(t *testing.T) {
+	site := newSite(t)
+	emit, err := fetch(newBackend(t, nil), map[string]interface{}{"url": site.URL + "/latin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emit.page == nil || emit.page.Status != 200 || emit.page.Charset != "windows-1252" {
+		t.Errorf("page = %+v", emit.page)
+	}
+	if got := emit.text(); got != "café crème" {
+		t.Errorf("text = %q", got)
+	}
+}
+
+func TestFetchURL_Chunks(t *testing.T) {
+	site := newSite(t)
+	emit, err := fetch(newBackend(t, map[string]interface{}{"chunk_size": 8}), map[string]interface{}{"url": site.URL + "/runes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emit.chunks) < 2 {
+		t.Fatalf("got %d chunks", len(emit.chunks))
+	}
+	for i, c := range emit.chunks {
+		if c.Index != i || !utf8.ValidString(c.Text) {
+			t.Errorf("chunk %d = %+v", i, c)
+		}
+	}
+	if emit.text() != strings.Repeat("é€", 20) {
+		t.Errorf("text = %q", emit.text())
+	}
+}
+
+func TestFetchURL_MaxSize(t *testing.T) {
+	site := newSite(t)
+	emit, err := fetch(newBackend(t, map[string]interface{}{"max_size": 1000}), map[string]interface{}{"url": site.URL + "/big"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emit.text()) != 1000 {
+		t.Errorf("read %d bytes, want 1000", len(emit.text()))
+	}
+	if last := emit.progress[len(emit.progress)-1]; !strings.Contains(last, "truncated") {
+		t.Errorf("progress = %v, want a truncation note", emit.progress)
+	}
+
+	emit, _ = fetch(newBackend(t, nil), map[string]interface{}{"url": site.URL + "/big", "max_bytes": 10})
+	if emit.text() != strings.Repeat("a", 10) {
+		t.Errorf("text = %q, want max_bytes honored", emit.text())
+	}
+}
+
+func TestFetchURL_NotText(t *testing.T) {
+	site := newSite(t)
+	_, err := fetch(newBackend(t, nil), map[string]interface{}{"url": site.URL + "/image"})
+	if mcperr.CodeOf(err) != mcperr.CodeUpstream || !strings.Contains(err.Error(), "image/png") {
+		t.Errorf("err = %v, want upstream error naming the type", err)
+	}
+}
+
+func TestRobots(t *testing.T) {
+	site := newSite(t)
+	b := newBackend(t, nil)
+	_, err := fetch(b, map[string]interface{}{"url": site.URL + "/private/x"})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied || !strings.Contains(err.Error(), "robots.txt") {
+		t.Errorf("err = %v, want robots.txt denial", err)
+	}
+	if _, err := fetch(b, map[string]interface{}{"url": site.URL + "/private/open"}); err != nil {
+		t.Errorf("allowed path: %v", err)
+	}
+
+	b = newBackend(t, map[string]interface{}{"respect_robots": false})
+	if _, err := fetch(b, map[string]interface{}{"url": site.URL + "/private/x"}); err != nil {
+		t.Errorf("respect_robots false: %v", err)
+	}
+}
+
+func TestDomainPolicy(t *testing.T) {
+	site := newSite(t)
+	b := newBackend(t, map[string]interface{}{"denied_domains": []interface{}{"localhost"}})
+	_, err := fetch(b, map[string]interface{}{"url": site.URL + "/redirect"})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied || !strings.Contains(err.Error(), "denied_domains") {
+		t.Errorf("err = %v, want the redirect denied", err)
+	}
+
+	b = newBackend(t, map[string]interface{}{"allowed_domains": []interface{}{"example.com"}})
+	_, err = fetch(b, map[string]interface{}{"url": site.URL + "/article"})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied || !strings.Contains(err.Error(), "allowed_domains") {
+		t.Errorf("err = %v, want allowed_domains denial", err)
+	}
+}
+
+func TestPrivateAddresses(t *testing.T) {
+	site := newSite(t)
+	b := newBackend(t, map[string]interface{}{"allow_private": false})
+	_, err := fetch(b, map[string]interface{}{"url": site.URL + "/article"})
+	if mcperr.CodeOf(err) != mcperr.CodePermissionDenied || !strings.Contains(err.Error(), "private address") {
+		t.Errorf("err = %v, want private address denial", err)
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	site := newSite(t)
+	b := newBackend(t, nil)
+	out, err := call(t, b, "extract_links", map[string]interface{}{"url": site.URL + "/article"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, l := range out["links"].([]interface{}) {
+		urls = append(urls, l.(map[string]interface{})["url"].(string))
+	}
+	want := []string{site.URL + "/", site.URL + "/docs/parser", "https://other.example/page"}
+	if strings.Join(urls, " ") != strings.Join(want, " ") {
+		t.Errorf("links = %v, want %v", urls, want)
+	}
+
+	out, _ = call(t, b, "extract_links", map[string]interface{}{"url": site.URL + "/article", "same_host_only": true, "limit": 1})
+	if links := out["links"].([]interface{}); len(links) != 1 || out["truncated"] != true {
+		t.Errorf("result = %v, want one link and truncated", out)
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	site := newSite(t)
+	b := newBackend(t, nil)
+	out, err := call(t, b, "html_to_markdown", map[string]interface{}{"url": site.URL + "/article"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out["title"] != "Release notes" {
+		t.Errorf("title = %v", out["title"])
+	}
+	want := "# Version 2\n\n" +
+		"The **new** parser is [documented](" + site.URL + "/docs/parser#top).\n\n" +
+		"- faster\n- smaller\n\n" +
+		"```\ngo get example.com/x\n```\n\n" +
+		"| Name | Size |\n| --- | --- |\n| x | 1 |\n\n" +
+		"[elsewhere](https://other.example/page) [again](" + site.URL + "/docs/parser)\n"
+	if out["markdown"] != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", out["markdown"], want)
+	}
+
+	out, err = call(t, b, "html_to_markdown", map[string]interface{}{
+		"html":     `<blockquote><p>Quoted <em>text</em></p></blockquote><ol start="3"><li>three</li><li>four</li></ol><img src="a.png" alt="A">`,
+		"base_url": "https://example.com/docs/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "> Quoted _text_\n\n3. three\n4. four\n\n![A](https://example.com/docs/a.png)\n"
+	if out["markdown"] != want {
+		t.Errorf("markdown =\n%s\nwant\n%s", out["markdown"], want)
+	}
+}
+
+func TestArgumentErrors(t *testing.T) {
+	b := newBackend(t, nil)
+	_, err := call(t, b, "html_to_markdown", map[string]interface{}{})
+	var argErr *backend.ArgumentError
+	if !errors.As(err, &argErr) || argErr.Fields[0].Field != "url" {
+		t.Errorf("err = %v, want an error on url", err)
+	}
+
+	_, err = fetch(b, map[string]interface{}{"url": "file:///etc/passwd"})
+	if !errors.As(err, &argErr) || argErr.Fields[0].Field != "url" {
+		t.Errorf("err = %v, want an error on url", err)
+	}
+}
@@ -40,6 +40,10 @@ type Entry struct {
 	ExpiresAt time.Time       `json:"expires_at"` // Expiration timestamp
 	CreatedAt time.Time       `json:"created_at"` // Creation timestamp
 	Hits      int64           `json:"hits"`       // Number of cache hits
+
+	// Compressed marks a Value stored compressed (see Compression); Get
+	// returns values decompressed
+	Compressed bool `json:"compressed,omitempty"`
 }
 
 // CacheStats holds cache statistics
@@ -52,6 +56,16 @@ type CacheStats struct {
 	Size      int     `json:"size"`      // Current number of entries
 	MaxSize   int     `json:"max_size"`  // Maximum capacity
 	HitRate   float64 `json:"hit_rate"`  // Hit rate (hits / (hits + misses))
+
+	Bytes    int64 `json:"bytes"`               // Approximate memory of the entries
+	MaxBytes int64 `json:"max_bytes,omitempty"` // Maximum memory (0 = no limit)
+
+	CompressedEntries int     `json:"compressed_entries,omitempty"` // Entries stored compressed
+	CompressionRatio  float64 `json:"compression_ratio,omitempty"`  // Original / stored size of their values
+
+	Sweeps       int64     `json:"sweeps,omitempty"`        // Background cleanups run
+	SweptEntries int64     `json:"swept_entries,omitempty"` // Expired entries they removed
+	LastSweep    time.Time `json:"last_sweep,omitzero"`     // When the latest one ran
 }
 
 // IsExpired checks if the entry has expired
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DefaultCompressionThreshold is the size from which values are
+// compressed when Compression.Threshold is zero
+const DefaultCompressionThreshold = 4096
+
+// Compression configures the compression of large cached values
+// Values of Threshold bytes or more are compressed when stored and
+// decompressed on Get, transparently to callers; values that do not
+// shrink are stored as they are.
+type Compression struct {
+	// Algorithm is "gzip", another registered with RegisterCompressor
+	// (e.g. a zstd one), or "" to store values uncompressed
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+
+	// Threshold is the smallest value compressed, in bytes (0 =
+	// DefaultCompressionThreshold)
+	Threshold int `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+}
+
+// Validate validates the compression settings
+func (c Compression) Validate() error {
+	if c.Threshold < 0 {
+		return fmt.Errorf("compression threshold must not be negative, got %d", c.Threshold)
+	}
+	if c.Algorithm == "" {
+		return nil
+	}
+	if _, ok := lookupCompressor(c.Algorithm); !ok {
+		return fmt.Errorf("unknown compression algorithm %q (register it with cache.RegisterCompressor)", c.Algorithm)
+	}
+	return nil
+}
+
+// threshold returns the smallest value compressed
+func (c Compression) threshold() int {
+	if c.Threshold <= 0 {
+		return DefaultCompressionThreshold
+	}
+	return c.Threshold
+}
+
+// Compressor compresses cached values
+// Implementations must be safe for concurrent use.
+type Compressor interface {
+	// Name is the algorithm name of Compression.Algorithm
+	Name() string
+
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	compressors   = map[string]Compressor{"gzip": gzipCompressor{}}
+	compressorsMu sync.RWMutex
+)
+
+// RegisterCompressor makes a compression algorithm available to
+// Compression.Algorithm, replacing any of the same name
+func RegisterCompressor(c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[c.Name()] = c
+}
+
+// lookupCompressor returns the compressor of an algorithm
+func lookupCompressor(name string) (Compressor, bool) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	c, ok := compressors[name]
+	return c, ok
+}
+
+// gzipCompressor is the built-in "gzip" algorithm
+type gzipCompressor struct{}
+
+var gzipWriters = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+
+// Name implements Compressor
+func (gzipCompressor) Name() string { return "gzip" }
+
+// Compress implements Compressor
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(w)
+	w.Reset(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress implements Compressor
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
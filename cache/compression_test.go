@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+)
+
+// Test: Compression of Large Values
+func TestMemoryCache_Compression(t *testing.T) {
+	mc := cache.NewMemoryCache(10, time.Minute)
+	if err := mc.SetCompression(cache.Compression{Algorithm: "gzip", Threshold: 1024}); err != nil {
+		t.Fatalf("SetCompression() error = %v", err)
+	}
+	ctx := context.Background()
+
+	large := json.RawMessage(`{"content":"` + strings.Repeat("all work and no play ", 500) + `"}`)
+	small := json.RawMessage(`{"id":1}`)
+	mc.Set(ctx, "large", large, 0)
+	mc.Set(ctx, "small", small, 0)
+
+	// Values come back as they were stored
+	entry, err := mc.Get(ctx, "large")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(entry.Value) != string(large) || entry.Compressed {
+		t.Errorf("Get() = %d bytes, compressed %v", len(entry.Value), entry.Compressed)
+	}
+	if entry, _ := mc.Get(ctx, "small"); string(entry.Value) != string(small) {
+		t.Errorf("Get(small) = %s", entry.Value)
+	}
+
+	// Only the large value is compressed, and it takes less memory
+	stats := mc.Stats()
+	if stats.CompressedEntries != 1 || stats.CompressionRatio < 10 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.Bytes > int64(len(large)) {
+		t.Errorf("Bytes = %d, want less than the %d bytes of the large value", stats.Bytes, len(large))
+	}
+
+	mc.Delete(ctx, "large")
+	if stats := mc.Stats(); stats.CompressedEntries != 0 || stats.CompressionRatio != 0 {
+		t.Errorf("stats after Delete = %+v", stats)
+	}
+}
+
+// Test: Compression Config Validation
+func TestCompression_Validate(t *testing.T) {
+	if err := (cache.Compression{Algorithm: "zstd"}).Validate(); err == nil {
+		t.Error("unregistered algorithm accepted")
+	}
+	if err := (cache.Compression{Algorithm: "gzip", Threshold: -1}).Validate(); err == nil {
+		t.Error("negative threshold accepted")
+	}
+	if err := (cache.Compression{}).Validate(); err != nil {
+		t.Errorf("no compression: %v", err)
+	}
+}
@@ -2,7 +2,11 @@ package cache
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/paths"
 )
 
 // Type represents the cache type
@@ -30,10 +34,32 @@ type Config struct {
 	// Ignored for file-based cache
 	MaxSize int `json:"max_size" yaml:"max_size"`
 
-	// Directory for file-based cache
+	// MaxBytes bounds the approximate memory of the entries (for memory
+	// cache; 0 = no limit), so a few large results cannot crowd out
+	// memory the way MaxSize alone allows
+	// Ignored for file-based cache
+	MaxBytes int64 `json:"max_bytes,omitempty" yaml:"max_bytes,omitempty"`
+
+	// CleanupInterval is how often the memory cache created by New
+	// removes expired entries in the background, until its Close (0 or
+	// negative = never: expired entries are then only removed when looked
+	// up or evicted; DefaultConfig sets DefaultCleanupInterval)
+	// Ignored for file-based cache
+	CleanupInterval time.Duration `json:"cleanup_interval,omitempty" yaml:"cleanup_interval,omitempty"`
+
+	// Compression compresses large values of the memory cache
+	// (default: off)
+	// Ignored for file-based cache
+	Compression Compression `json:"compression,omitempty" yaml:"compression,omitempty"`
+
+	// Directory for file-based cache (default: DefaultDirectory())
 	// Ignored for memory cache
 	Directory string `json:"directory" yaml:"directory"`
 
+	// KeyPrefix namespaces the cache keys as "<prefix>:<hash>", e.g. per
+	// server or environment when several share a store (default: none)
+	KeyPrefix string `json:"key_prefix,omitempty" yaml:"key_prefix,omitempty"`
+
 	// Enabled enables/disables caching globally
 	// Default: false (SAFE DEFAULT - must opt-in)
 	// This is intentional for safety - caching is disabled by default
@@ -49,15 +75,27 @@ type Config struct {
 // Tools must explicitly opt-in to caching
 func DefaultConfig() *Config {
 	return &Config{
-		Type:      TypeShort,
-		TTL:       60,   // 60 seconds
-		MaxSize:   1000, // 1000 entries
-		Directory: ".mcp-cache",
-		Enabled:   false, // ⚠️ DISABLED BY DEFAULT (safe default)
-		ToolTTL:   make(map[string]time.Duration),
+		Type:            TypeShort,
+		TTL:             60,   // 60 seconds
+		MaxSize:         1000, // 1000 entries
+		CleanupInterval: DefaultCleanupInterval,
+		Directory:       DefaultDirectory(),
+		Enabled:         false, // ⚠️ DISABLED BY DEFAULT (safe default)
+		ToolTTL:         make(map[string]time.Duration),
 	}
 }
 
+// DefaultDirectory returns the default file cache directory
+// Resolves to the per-user cache directory (see the paths package),
+// falling back to ".mcp-cache" when it cannot be determined.
+func DefaultDirectory() string {
+	dir, err := paths.CacheDir("")
+	if err != nil {
+		return ".mcp-cache"
+	}
+	return filepath.Join(dir, "responses")
+}
+
 // Validate validates the cache configuration
 // If cache is disabled, no validation is performed
 func (c *Config) Validate() error {
@@ -80,6 +118,16 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("max_size must be positive for memory cache, got %d", c.MaxSize)
 	}
 
+	if c.MaxBytes < 0 {
+		return fmt.Errorf("max_bytes must not be negative, got %d", c.MaxBytes)
+	}
+	if err := c.Compression.Validate(); err != nil {
+		return err
+	}
+	if strings.ContainsAny(c.KeyPrefix, " \t\r\n") {
+		return fmt.Errorf("key_prefix must not contain whitespace, got %q", c.KeyPrefix)
+	}
+
 	// Validate Directory for file cache
 	if c.Type == TypeLong && c.Directory == "" {
 		return fmt.Errorf("directory is required for file cache")
@@ -29,8 +29,8 @@ func TestDefaultConfig(t *testing.T) {
 		t.Errorf("MaxSize = %v, want 1000", cfg.MaxSize)
 	}
 
-	if cfg.Directory != ".mcp-cache" {
-		t.Errorf("Directory = %v, want .mcp-cache", cfg.Directory)
+	if cfg.Directory != cache.DefaultDirectory() {
+		t.Errorf("Directory = %v, want %v", cfg.Directory, cache.DefaultDirectory())
 	}
 
 	if cfg.ToolTTL == nil {
@@ -133,6 +133,30 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "max_size must be positive",
 		},
+		{
+			name: "negative max bytes",
+			config: &cache.Config{
+				Type:     cache.TypeShort,
+				TTL:      60,
+				MaxSize:  100,
+				MaxBytes: -1,
+				Enabled:  true,
+			},
+			wantErr: true,
+			errMsg:  "max_bytes must not be negative",
+		},
+		{
+			name: "key prefix with whitespace",
+			config: &cache.Config{
+				Type:      cache.TypeShort,
+				TTL:       60,
+				MaxSize:   100,
+				KeyPrefix: "my app",
+				Enabled:   true,
+			},
+			wantErr: true,
+			errMsg:  "key_prefix must not contain whitespace",
+		},
 		{
 			name: "missing directory for long cache",
 			config: &cache.Config{
@@ -22,7 +22,15 @@ func New(config *Config) (Cache, error) {
 	case TypeShort:
 		// Memory cache with TTL in seconds
 		ttl := config.GetTTLDuration()
-		return NewMemoryCache(config.MaxSize, ttl), nil
+		c := NewMemoryCache(config.MaxSize, ttl)
+		c.SetMaxBytes(config.MaxBytes)
+		if err := c.SetCompression(config.Compression); err != nil {
+			return nil, fmt.Errorf("invalid cache config: %w", err)
+		}
+		if config.CleanupInterval > 0 {
+			c.StartCleanup(config.CleanupInterval)
+		}
+		return c, nil
 
 	case TypeLong:
 		// File cache with TTL in minutes (to be implemented in Week 3)
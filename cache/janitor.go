@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"time"
+)
+
+// DefaultCleanupInterval is DefaultConfig's CleanupInterval, and used when
+// StartCleanup's interval is zero
+const DefaultCleanupInterval = 5 * time.Minute
+
+// StartCleanup removes expired entries every interval (0 =
+// DefaultCleanupInterval) until Close, so entries nobody asks for again
+// do not hold memory until they are evicted
+// New starts it when Config.CleanupInterval is positive; calling it again
+// replaces the interval. The janitor is a goroutine: Close the cache to
+// stop it.
+func (c *MemoryCache) StartCleanup(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCleanupInterval
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	if c.stopCleanup != nil {
+		close(c.stopCleanup)
+	}
+	stop := make(chan struct{})
+	c.stopCleanup = stop
+	go c.janitor(interval, stop)
+}
+
+// janitor sweeps the cache every interval until stop is closed
+func (c *MemoryCache) janitor(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.sweep()
+		}
+	}
+}
+
+// sweep removes the expired entries, recording the sweep in the stats
+func (c *MemoryCache) sweep() {
+	removed := c.CleanExpired()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Sweeps++
+	c.stats.SweptEntries += int64(removed)
+	c.stats.LastSweep = time.Now()
+}
+
+// stopJanitor stops the janitor, if running; callers hold mu
+func (c *MemoryCache) stopJanitor() {
+	if c.stopCleanup != nil {
+		close(c.stopCleanup)
+		c.stopCleanup = nil
+	}
+}
@@ -0,0 +1,98 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+)
+
+// Test: Background Cleanup
+func TestMemoryCache_StartCleanup(t *testing.T) {
+	mc := cache.NewMemoryCache(10, time.Minute)
+	defer mc.Close()
+	ctx := context.Background()
+
+	mc.Set(ctx, "short", json.RawMessage(`1`), 5*time.Millisecond)
+	mc.Set(ctx, "long", json.RawMessage(`2`), time.Minute)
+	mc.StartCleanup(10 * time.Millisecond)
+
+	// Expired entries go without anybody looking them up
+	deadline := time.Now().Add(2 * time.Second)
+	for mc.Len() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Len() = %d, expired entry not cleaned up", mc.Len())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stats := mc.Stats()
+	if stats.Sweeps == 0 || stats.SweptEntries != 1 || stats.Misses != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if since := time.Since(stats.LastSweep); since < 0 || since > 2*time.Second {
+		t.Errorf("LastSweep = %v, want the time of the latest sweep", stats.LastSweep)
+	}
+}
+
+// Test: Close Stops the Cleanup
+func TestMemoryCache_CloseStopsCleanup(t *testing.T) {
+	mc := cache.NewMemoryCache(10, time.Minute)
+	mc.StartCleanup(time.Millisecond)
+	if err := mc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	sweeps := mc.Stats().Sweeps
+	time.Sleep(20 * time.Millisecond)
+	if mc.Stats().Sweeps > sweeps+1 {
+		t.Errorf("sweeps went on after Close: %d -> %d", sweeps, mc.Stats().Sweeps)
+	}
+
+	// Closing twice and restarting a closed cache are harmless
+	mc.StartCleanup(time.Millisecond)
+	if err := mc.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+// Test: New Starts the Cleanup
+func TestNew_CleanupInterval(t *testing.T) {
+	config := cache.DefaultConfig()
+	config.Enabled = true
+	config.CleanupInterval = 10 * time.Millisecond
+	c, err := cache.New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer c.Close()
+
+	c.Set(context.Background(), "key", json.RawMessage(`1`), time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Stats().SweptEntries != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v, expired entry not swept", c.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// Test: New Starts No Janitor Without an Interval
+func TestNew_NoCleanupInterval(t *testing.T) {
+	config := cache.DefaultConfig()
+	config.Enabled = true
+	config.CleanupInterval = 0
+
+	before := runtime.NumGoroutine()
+	c, err := cache.New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer c.Close()
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines = %d, want %d (no janitor)", after, before)
+	}
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -11,11 +12,53 @@ import (
 // KeyGenerator generates deterministic cache keys
 // CRITICAL: Keys must be deterministic - same logical input = same key
 // This prevents cache misses due to Go's random map iteration order
-type KeyGenerator struct{}
+type KeyGenerator struct {
+	prefix   string
+	identity IdentityFunc
+}
+
+// IdentityFunc returns the identity of the caller of ctx (e.g. its
+// principal or tenant), "" for anonymous callers
+type IdentityFunc func(ctx context.Context) string
+
+// KeyOption configures a KeyGenerator
+type KeyOption func(*KeyGenerator)
+
+// WithKeyPrefix namespaces the keys as "<prefix>:<hash>", so servers
+// sharing a store (e.g. one Redis) do not read each other's results
+func WithKeyPrefix(prefix string) KeyOption {
+	return func(kg *KeyGenerator) {
+		kg.prefix = prefix
+	}
+}
+
+// WithKeyIdentity sets how the caller's identity is read for tools whose
+// results are cached per caller (see KeyScope.PerIdentity)
+func WithKeyIdentity(identity IdentityFunc) KeyOption {
+	return func(kg *KeyGenerator) {
+		kg.identity = identity
+	}
+}
 
 // NewKeyGenerator creates a new key generator
-func NewKeyGenerator() *KeyGenerator {
-	return &KeyGenerator{}
+func NewKeyGenerator(opts ...KeyOption) *KeyGenerator {
+	kg := &KeyGenerator{}
+	for _, opt := range opts {
+		opt(kg)
+	}
+	return kg
+}
+
+// KeyScope narrows which calls share a cache key beyond tool and
+// arguments
+type KeyScope struct {
+	// Version is the tool's cache schema version; bumping it when the
+	// tool's output format changes keeps old results from being served
+	Version string
+
+	// PerIdentity keys the results by the caller's identity, so callers
+	// never see each other's results
+	PerIdentity bool
 }
 
 // Generate generates a cache key from tool name and arguments
@@ -30,16 +73,42 @@ func NewKeyGenerator() *KeyGenerator {
 //	args2 := {"a": 1, "b": 2}  // Different order
 //	Generate("tool", args1) == Generate("tool", args2)  // ✅ Same key!
 func (kg *KeyGenerator) Generate(toolName string, args map[string]interface{}) (string, error) {
+	return kg.GenerateScoped(context.Background(), toolName, args, KeyScope{})
+}
+
+// GenerateScoped generates a cache key like Generate, salted with the
+// scope's version and, if it is per identity, the identity of ctx's caller
+// Keys of an empty scope are those of Generate.
+//
+// Example:
+//
+//	key, err := kg.GenerateScoped(ctx, "get_profile", args, cache.KeyScope{
+//	    Version:     "2",
+//	    PerIdentity: true,
+//	})
+func (kg *KeyGenerator) GenerateScoped(ctx context.Context, toolName string, args map[string]interface{}, scope KeyScope) (string, error) {
 	// CRITICAL: Normalize arguments for deterministic hashing
 	normalized := kg.normalize(args)
 
 	// Create a deterministic representation
 	data := struct {
-		Tool string      `json:"tool"`
-		Args interface{} `json:"args"`
+		Tool     string      `json:"tool"`
+		Args     interface{} `json:"args"`
+		Version  string      `json:"version,omitempty"`
+		Identity *string     `json:"identity,omitempty"`
 	}{
-		Tool: toolName,
-		Args: normalized,
+		Tool:    toolName,
+		Args:    normalized,
+		Version: scope.Version,
+	}
+	if scope.PerIdentity {
+		// Anonymous callers share results with each other, not with
+		// identified ones
+		identity := ""
+		if kg.identity != nil {
+			identity = kg.identity(ctx)
+		}
+		data.Identity = &identity
 	}
 
 	// Serialize to JSON (now deterministic due to normalization)
@@ -51,6 +120,9 @@ func (kg *KeyGenerator) Generate(toolName string, args map[string]interface{}) (
 	// Hash the JSON using SHA-256
 	hash := sha256.Sum256(jsonData)
 	key := hex.EncodeToString(hash[:])
+	if kg.prefix != "" {
+		key = kg.prefix + ":" + key
+	}
 
 	return key, nil
 }
@@ -106,5 +178,8 @@ func (kg *KeyGenerator) normalize(v interface{}) interface{} {
 // GenerateSimple generates a simple cache key without arguments
 // Useful for testing or tools with no parameters
 func (kg *KeyGenerator) GenerateSimple(toolName string) string {
+	if kg.prefix != "" {
+		return fmt.Sprintf("%s:tool:%s", kg.prefix, toolName)
+	}
 	return fmt.Sprintf("tool:%s", toolName)
 }
@@ -1,6 +1,7 @@
 package cache_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -548,6 +549,59 @@ func BenchmarkKeyGenerator_Generate(b *testing.B) {
 	}
 }
 
+// Test: Prefixes, versions and identities
+func TestKeyGenerator_GenerateScoped(t *testing.T) {
+	args := map[string]interface{}{"path": "/tmp/test.txt"}
+	identity := func(ctx context.Context) string {
+		user, _ := ctx.Value(userKey{}).(string)
+		return user
+	}
+	kg := cache.NewKeyGenerator(cache.WithKeyIdentity(identity))
+	alice := context.WithValue(context.Background(), userKey{}, "alice")
+	bob := context.WithValue(context.Background(), userKey{}, "bob")
+
+	t.Run("empty scope matches Generate", func(t *testing.T) {
+		plain, _ := kg.Generate("read_file", args)
+		scoped, _ := kg.GenerateScoped(alice, "read_file", args, cache.KeyScope{})
+		if plain != scoped {
+			t.Errorf("GenerateScoped() = %q, want %q", scoped, plain)
+		}
+	})
+
+	t.Run("prefix namespaces keys", func(t *testing.T) {
+		plain, _ := kg.Generate("read_file", args)
+		prefixed, _ := cache.NewKeyGenerator(cache.WithKeyPrefix("staging")).Generate("read_file", args)
+		if prefixed != "staging:"+plain {
+			t.Errorf("prefixed key = %q, want %q", prefixed, "staging:"+plain)
+		}
+	})
+
+	t.Run("version changes keys", func(t *testing.T) {
+		v1, _ := kg.GenerateScoped(alice, "read_file", args, cache.KeyScope{Version: "1"})
+		v2, _ := kg.GenerateScoped(alice, "read_file", args, cache.KeyScope{Version: "2"})
+		if v1 == v2 {
+			t.Error("different versions produced the same key")
+		}
+	})
+
+	t.Run("per identity keys differ by caller", func(t *testing.T) {
+		scope := cache.KeyScope{PerIdentity: true}
+		a1, _ := kg.GenerateScoped(alice, "read_file", args, scope)
+		a2, _ := kg.GenerateScoped(alice, "read_file", args, scope)
+		b, _ := kg.GenerateScoped(bob, "read_file", args, scope)
+		shared, _ := kg.GenerateScoped(alice, "read_file", args, cache.KeyScope{})
+		anonymous, _ := kg.GenerateScoped(context.Background(), "read_file", args, scope)
+		if a1 != a2 {
+			t.Error("same caller produced different keys")
+		}
+		if a1 == b || a1 == shared || anonymous == shared {
+			t.Error("per-identity keys collide with other callers' or shared keys")
+		}
+	})
+}
+
+type userKey struct{}
+
 func BenchmarkKeyGenerator_GenerateComplex(b *testing.B) {
 	kg := cache.NewKeyGenerator()
 
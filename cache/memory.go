@@ -20,14 +20,28 @@ import (
 //
 // On Get:  Move to front (most recently used)
 // On Set:  Add to front, evict from back if full
+//
+// Full means more than maxSize entries or, with SetMaxBytes, more than
+// maxBytes of approximate memory (see entrySize).
 type MemoryCache struct {
-	maxSize int           // Maximum number of entries
-	ttl     time.Duration // Default TTL for entries
+	maxSize  int           // Maximum number of entries
+	maxBytes int64         // Maximum approximate memory (0 = no limit)
+	ttl      time.Duration // Default TTL for entries
 
 	mu      sync.RWMutex             // Protects all fields below
 	entries map[string]*list.Element // Key → list element
 	lru     *list.List               // LRU eviction list
 
+	compression Compression // Compression of large values
+	compressor  Compressor  // Compressor of compression.Algorithm (nil = off)
+
+	// original and stored are the value sizes of the compressed entries,
+	// before and after compression
+	original, stored int64
+
+	stopCleanup chan struct{} // Closed to stop the janitor (see StartCleanup)
+	closed      bool          // Whether Close was called
+
 	stats CacheStats // Cache statistics
 }
 
@@ -35,6 +49,21 @@ type MemoryCache struct {
 type cacheItem struct {
 	key   string // Cache key
 	entry *Entry // Cached entry
+	size  int64  // Approximate memory (see entrySize)
+
+	// compressor decompresses a compressed entry's value, original
+	// bytes long
+	compressor Compressor
+	original   int64
+}
+
+// entryOverhead approximates the memory of an entry besides its key and
+// value: the Entry, the list element and the map slot
+const entryOverhead = 200
+
+// entrySize returns the approximate memory of an entry
+func entrySize(key string, value json.RawMessage) int64 {
+	return int64(len(key)+len(value)) + entryOverhead
 }
 
 // NewMemoryCache creates a new in-memory cache
@@ -50,9 +79,57 @@ func NewMemoryCache(maxSize int, ttl time.Duration) *MemoryCache {
 	}
 }
 
+// SetMaxBytes bounds the approximate memory of the entries (0 = no
+// limit), evicting least recently used entries until they fit
+func (c *MemoryCache) SetMaxBytes(maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.maxBytes = maxBytes
+	c.stats.MaxBytes = maxBytes
+	c.evict()
+	c.stats.Size = len(c.entries)
+}
+
+// SetCompression compresses the values stored from now on as compression
+// says; entries already stored are left as they are
+func (c *MemoryCache) SetCompression(compression Compression) error {
+	if err := compression.Validate(); err != nil {
+		return err
+	}
+	var compressor Compressor
+	if compression.Algorithm != "" {
+		compressor, _ = lookupCompressor(compression.Algorithm)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.compression = compression
+	c.compressor = compressor
+	return nil
+}
+
 // Get retrieves a cached entry
 // Returns error if key not found or entry expired
 func (c *MemoryCache) Get(ctx context.Context, key string) (*Entry, error) {
+	entry, compressor, err := c.get(key)
+	if err != nil || compressor == nil {
+		return entry, err
+	}
+
+	// Decompress outside the lock; entry is a copy
+	value, err := compressor.Decompress(entry.Value)
+	if err != nil {
+		return nil, fmt.Errorf("cache entry %s: decompress: %w", key, err)
+	}
+	entry.Value = value
+	entry.Compressed = false
+	return entry, nil
+}
+
+// get looks up an entry, returning a copy of it and its compressor if it
+// is compressed
+func (c *MemoryCache) get(key string) (*Entry, Compressor, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -61,7 +138,7 @@ func (c *MemoryCache) Get(ctx context.Context, key string) (*Entry, error) {
 	if !exists {
 		c.stats.Misses++
 		c.updateHitRate()
-		return nil, fmt.Errorf("cache miss: key not found")
+		return nil, nil, fmt.Errorf("cache miss: key not found")
 	}
 
 	item := element.Value.(*cacheItem)
@@ -73,7 +150,7 @@ func (c *MemoryCache) Get(ctx context.Context, key string) (*Entry, error) {
 		c.stats.Misses++
 		c.stats.Evictions++
 		c.updateHitRate()
-		return nil, fmt.Errorf("cache miss: entry expired")
+		return nil, nil, fmt.Errorf("cache miss: entry expired")
 	}
 
 	// Move to front (most recently used)
@@ -84,11 +161,31 @@ func (c *MemoryCache) Get(ctx context.Context, key string) (*Entry, error) {
 	c.stats.Hits++
 	c.updateHitRate()
 
-	return item.entry, nil
+	if item.compressor != nil {
+		entry := *item.entry
+		return &entry, item.compressor, nil
+	}
+	return item.entry, nil, nil
 }
 
 // Set stores an entry in the cache
 func (c *MemoryCache) Set(ctx context.Context, key string, value json.RawMessage, ttl time.Duration) error {
+	// Compress outside the lock
+	c.mu.RLock()
+	compression, compressor := c.compression, c.compressor
+	c.mu.RUnlock()
+	original := int64(len(value))
+	if compressor != nil && len(value) >= compression.threshold() {
+		compressed, err := compressor.Compress(value)
+		if err == nil && len(compressed) < len(value) {
+			value = compressed
+		} else {
+			compressor = nil
+		}
+	} else {
+		compressor = nil
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -97,6 +194,18 @@ func (c *MemoryCache) Set(ctx context.Context, key string, value json.RawMessage
 		ttl = c.ttl
 	}
 
+	// An entry larger than the whole cache would evict everything and
+	// still not fit; the key's previous value goes, so it is not served
+	// in place of the one the caller meant to store
+	size := entrySize(key, value)
+	if c.maxBytes > 0 && size > c.maxBytes {
+		if element, exists := c.entries[key]; exists {
+			c.removeElement(element)
+			c.stats.Size = len(c.entries)
+		}
+		return fmt.Errorf("entry of %d bytes exceeds the cache's %d bytes", size, c.maxBytes)
+	}
+
 	// Create entry
 	entry := &Entry{
 		Key:       key,
@@ -104,29 +213,38 @@ func (c *MemoryCache) Set(ctx context.Context, key string, value json.RawMessage
 		ExpiresAt: time.Now().Add(ttl),
 		CreatedAt: time.Now(),
 		Hits:      0,
+
+		Compressed: compressor != nil,
 	}
 
 	// Check if entry already exists
 	if element, exists := c.entries[key]; exists {
 		// Update existing entry
 		item := element.Value.(*cacheItem)
+		c.uncount(item)
 		item.entry = entry
+		item.size = size
+		item.compressor = compressor
+		item.original = original
+		c.count(item)
 		c.lru.MoveToFront(element)
 	} else {
 		// Add new entry
 		item := &cacheItem{
-			key:   key,
-			entry: entry,
+			key:        key,
+			entry:      entry,
+			size:       size,
+			compressor: compressor,
+			original:   original,
 		}
 		element := c.lru.PushFront(item)
 		c.entries[key] = element
-
-		// Evict oldest if needed
-		if c.lru.Len() > c.maxSize {
-			c.evictOldest()
-		}
+		c.count(item)
 	}
 
+	// Evict oldest if needed
+	c.evict()
+
 	c.stats.Sets++
 	c.stats.Size = len(c.entries)
 
@@ -158,6 +276,10 @@ func (c *MemoryCache) Clear(ctx context.Context) error {
 	c.entries = make(map[string]*list.Element)
 	c.lru.Init()
 	c.stats.Size = 0
+	c.stats.Bytes = 0
+	c.stats.CompressedEntries = 0
+	c.original, c.stored = 0, 0
+	c.updateCompressionRatio()
 
 	return nil
 }
@@ -171,11 +293,24 @@ func (c *MemoryCache) Stats() CacheStats {
 	return c.stats
 }
 
-// Close closes the cache (clears all entries)
+// Close closes the cache (clears all entries and stops the cleanup)
 func (c *MemoryCache) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.stopJanitor()
+	c.mu.Unlock()
+
 	return c.Clear(context.Background())
 }
 
+// evict removes least recently used entries while there are more than
+// maxSize or they take more than maxBytes
+func (c *MemoryCache) evict() {
+	for c.lru.Len() > 0 && (c.lru.Len() > c.maxSize || c.maxBytes > 0 && c.stats.Bytes > c.maxBytes) {
+		c.evictOldest()
+	}
+}
+
 // evictOldest removes the least recently used entry
 func (c *MemoryCache) evictOldest() {
 	element := c.lru.Back()
@@ -190,6 +325,37 @@ func (c *MemoryCache) removeElement(element *list.Element) {
 	item := element.Value.(*cacheItem)
 	delete(c.entries, item.key)
 	c.lru.Remove(element)
+	c.uncount(item)
+}
+
+// count adds an item to the byte and compression statistics
+func (c *MemoryCache) count(item *cacheItem) {
+	c.stats.Bytes += item.size
+	if item.compressor != nil {
+		c.stats.CompressedEntries++
+		c.original += item.original
+		c.stored += int64(len(item.entry.Value))
+		c.updateCompressionRatio()
+	}
+}
+
+// uncount removes an item from the byte and compression statistics
+func (c *MemoryCache) uncount(item *cacheItem) {
+	c.stats.Bytes -= item.size
+	if item.compressor != nil {
+		c.stats.CompressedEntries--
+		c.original -= item.original
+		c.stored -= int64(len(item.entry.Value))
+		c.updateCompressionRatio()
+	}
+}
+
+// updateCompressionRatio calculates the compression ratio
+func (c *MemoryCache) updateCompressionRatio() {
+	c.stats.CompressionRatio = 0
+	if c.stored > 0 {
+		c.stats.CompressionRatio = float64(c.original) / float64(c.stored)
+	}
 }
 
 // updateHitRate calculates the cache hit rate
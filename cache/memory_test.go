@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -240,6 +241,56 @@ func TestMemoryCache_LRUEviction(t *testing.T) {
 	}
 }
 
+// Test: Byte-Bounded Eviction
+func TestMemoryCache_MaxBytesEviction(t *testing.T) {
+	mc := cache.NewMemoryCache(100, time.Minute)
+	ctx := context.Background()
+
+	// Each entry takes its key and value plus about 200 bytes of overhead
+	big := json.RawMessage(`"` + strings.Repeat("x", 1000) + `"`)
+	mc.SetMaxBytes(3000)
+	mc.Set(ctx, "key1", big, 0)
+	mc.Set(ctx, "key2", big, 0)
+	if stats := mc.Stats(); stats.Bytes < 2000 || stats.Bytes > 3000 || stats.MaxBytes != 3000 {
+		t.Fatalf("stats = %+v", stats)
+	}
+
+	// A third large entry pushes out the least recently used one, though
+	// the entry count is far from MaxSize
+	mc.Get(ctx, "key1")
+	mc.Set(ctx, "key3", big, 0)
+	if _, err := mc.Get(ctx, "key2"); err == nil {
+		t.Error("key2 should have been evicted")
+	}
+	if mc.Len() != 2 || mc.Stats().Bytes > 3000 {
+		t.Errorf("Len() = %d, stats = %+v", mc.Len(), mc.Stats())
+	}
+
+	// Entries larger than the whole cache are refused
+	huge := json.RawMessage(`"` + strings.Repeat("x", 5000) + `"`)
+	if err := mc.Set(ctx, "huge", huge, 0); err == nil {
+		t.Error("Set() of an entry larger than MaxBytes should fail")
+	}
+
+	// ...and overwriting a key with one drops its previous value
+	if err := mc.Set(ctx, "key3", huge, 0); err == nil {
+		t.Error("Set() of an entry larger than MaxBytes should fail")
+	}
+	if _, err := mc.Get(ctx, "key3"); err == nil {
+		t.Error("Get() after a refused overwrite returned the stale value")
+	}
+
+	// Deleting and clearing release the bytes
+	mc.Delete(ctx, "key1")
+	if mc.Stats().Bytes >= 2000 {
+		t.Errorf("Bytes after Delete = %d", mc.Stats().Bytes)
+	}
+	mc.Clear(ctx)
+	if mc.Stats().Bytes != 0 {
+		t.Errorf("Bytes after Clear = %d", mc.Stats().Bytes)
+	}
+}
+
 // Test: LRU Ordering (Access Updates Order)
 func TestMemoryCache_LRUOrdering(t *testing.T) {
 	mc := cache.NewMemoryCache(3, time.Minute)
@@ -0,0 +1,356 @@
+// Package canary calls tools periodically with fixed, safe arguments
+//
+// Each canary's outcome and latency are recorded as metrics
+// (mcp_canary_runs_total, mcp_canary_duration_seconds, mcp_canary_up) and
+// as a readiness check, so upstream API breakage shows up on dashboards
+// and probes before users hit it.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithCanary(canary.Check{
+//	        Tool:     "get_current_weather",
+//	        Args:     map[string]interface{}{"city": "London"},
+//	        Interval: time.Minute,
+//	    }),
+//	)
+//
+// Canaries run at a fixed interval or on a cron schedule (see ParseCron),
+// configured in config.yaml as:
+//
+//	canaries:
+//	  - tool: get_current_weather
+//	    args: {city: London}
+//	    interval: 1m
+//	  - name: nightly-report
+//	    tool: build_report
+//	    cron: "0 3 * * *"
+//
+// The scheduler's Handler serves the schedules and their next runs.
+package canary
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// Defaults for zero Check fields
+const (
+	DefaultInterval         = time.Minute
+	DefaultTimeout          = 10 * time.Second
+	DefaultFailureThreshold = 3
+)
+
+// Check is a tool called periodically with fixed arguments
+type Check struct {
+	// Name identifies the canary in metrics and health checks (default: Tool)
+	Name string `yaml:"name"`
+
+	// Tool is the tool called; Args must be safe to send repeatedly
+	Tool string                 `yaml:"tool"`
+	Args map[string]interface{} `yaml:"args"`
+
+	// Interval between calls (default: DefaultInterval)
+	Interval time.Duration `yaml:"interval"`
+
+	// Cron schedules calls by a cron expression instead of Interval,
+	// e.g. "*/5 * * * *"; see ParseCron
+	Cron string `yaml:"cron"`
+
+	// Timeout bounds each call (default: DefaultTimeout)
+	Timeout time.Duration `yaml:"timeout"`
+
+	// FailureThreshold is the number of consecutive failures after which
+	// readiness fails; fewer only degrade it (default: DefaultFailureThreshold)
+	FailureThreshold int `yaml:"failure_threshold"`
+
+	// Validate, if set, checks the tool's result
+	Validate func(result interface{}) error `yaml:"-"`
+
+	// cron is the parsed Cron, set by Scheduler.Add
+	cron *Cron
+}
+
+// withDefaults fills in zero fields
+func (c Check) withDefaults() Check {
+	if c.Name == "" {
+		c.Name = c.Tool
+	}
+	if c.Interval <= 0 && c.Cron == "" {
+		c.Interval = DefaultInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = DefaultFailureThreshold
+	}
+	return c
+}
+
+// Status is the recent history of a canary
+type Status struct {
+	Name                string        `json:"name"`
+	Tool                string        `json:"tool"`
+	LastRun             time.Time     `json:"last_run,omitempty"`
+	LastLatency         time.Duration `json:"-"`
+	LastError           string        `json:"last_error,omitempty"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	Successes           int64         `json:"successes"`
+	Failures            int64         `json:"failures"`
+}
+
+type canaryKey struct{}
+
+// IsCanary reports whether a tool call is a canary
+// Tools can use it to skip side effects such as usage accounting.
+func IsCanary(ctx context.Context) bool {
+	canary, _ := ctx.Value(canaryKey{}).(bool)
+	return canary
+}
+
+// Scheduler runs canaries against a backend
+type Scheduler struct {
+	backend backend.ServerBackend
+	logger  *slog.Logger
+
+	mu     sync.RWMutex
+	checks []Check
+	status map[string]*Status
+}
+
+// NewScheduler creates a scheduler calling tools on b
+func NewScheduler(b backend.ServerBackend, logger *slog.Logger) *Scheduler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Scheduler{
+		backend: b,
+		logger:  logger,
+		status:  make(map[string]*Status),
+	}
+}
+
+// Add adds a canary; add canaries before Run
+func (s *Scheduler) Add(check Check) error {
+	check = check.withDefaults()
+	if check.Tool == "" {
+		return fmt.Errorf("canary %q: tool is required", check.Name)
+	}
+	if check.Cron != "" {
+		if check.Interval > 0 {
+			return fmt.Errorf("canary %q: set interval or cron, not both", check.Name)
+		}
+		cron, err := ParseCron(check.Cron)
+		if err != nil {
+			return fmt.Errorf("canary %q: %w", check.Name, err)
+		}
+		check.cron = cron
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.status[check.Name]; exists {
+		return fmt.Errorf("canary %q already registered", check.Name)
+	}
+	s.checks = append(s.checks, check)
+	s.status[check.Name] = &Status{Name: check.Name, Tool: check.Tool}
+	return nil
+}
+
+// Run calls every interval canary immediately and then at its interval,
+// and every cron canary at its scheduled times, until ctx is done
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.RLock()
+	checks := append([]Check(nil), s.checks...)
+	s.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			s.loop(ctx, check)
+		}(check)
+	}
+	wg.Wait()
+}
+
+// loop runs one canary until ctx is done
+func (s *Scheduler) loop(ctx context.Context, check Check) {
+	if check.cron != nil {
+		s.cronLoop(ctx, check)
+		return
+	}
+
+	ticker := time.NewTicker(check.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.run(ctx, check)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cronLoop runs a cron canary at its scheduled times until ctx is done
+func (s *Scheduler) cronLoop(ctx context.Context, check Check) {
+	for {
+		next := check.cron.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warn("canary schedule has no further runs", "canary", check.Name, "cron", check.Cron)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.run(ctx, check)
+	}
+}
+
+// RunOnce calls every canary once and returns the first error
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	s.mu.RLock()
+	checks := append([]Check(nil), s.checks...)
+	s.mu.RUnlock()
+
+	var firstErr error
+	for _, check := range checks {
+		if err := s.run(ctx, check); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("canary %s: %w", check.Name, err)
+		}
+	}
+	return firstErr
+}
+
+// run calls a canary's tool and records the outcome
+func (s *Scheduler) run(ctx context.Context, check Check) error {
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, canaryKey{}, true), check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := s.call(ctx, check)
+	latency := time.Since(start)
+
+	if ctx.Err() != nil && err == nil {
+		err = ctx.Err()
+	}
+
+	observability.RecordCanary(check.Name, check.Tool, err, latency)
+
+	s.mu.Lock()
+	status := s.status[check.Name]
+	status.LastRun = start
+	status.LastLatency = latency
+	if err != nil {
+		status.LastError = err.Error()
+		status.ConsecutiveFailures++
+		status.Failures++
+	} else {
+		status.LastError = ""
+		status.ConsecutiveFailures = 0
+		status.Successes++
+	}
+	failures := status.ConsecutiveFailures
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Warn("canary failed",
+			"canary", check.Name,
+			"tool", check.Tool,
+			"consecutive_failures", failures,
+			"latency", latency,
+			"error", err)
+	} else {
+		s.logger.Debug("canary succeeded",
+			"canary", check.Name,
+			"tool", check.Tool,
+			"latency", latency)
+	}
+	return err
+}
+
+// call invokes the tool, discarding streamed events
+func (s *Scheduler) call(ctx context.Context, check Check) error {
+	args := make(map[string]interface{}, len(check.Args))
+	for k, v := range check.Args {
+		args[k] = v
+	}
+
+	if s.backend.IsStreamingTool(check.Tool) {
+		return s.backend.CallStreamingTool(ctx, check.Tool, args, discardEmitter{ctx})
+	}
+
+	result, err := s.backend.CallTool(ctx, check.Tool, args)
+	if err != nil {
+		return err
+	}
+	if check.Validate != nil {
+		return check.Validate(result)
+	}
+	return nil
+}
+
+// Status returns the canaries' recent history, sorted by name
+func (s *Scheduler) Status() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(s.status))
+	for _, status := range s.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// RegisterHealthChecks implements health.Reporter
+// Each canary adds a readiness check, "canary:<name>", degraded after a
+// failure and failing once FailureThreshold calls in a row have failed.
+// A canary that hasn't run yet is healthy.
+func (s *Scheduler) RegisterHealthChecks(r *health.Registry) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, check := range s.checks {
+		check := check
+		r.RegisterReadiness("canary:"+check.Name, func(ctx context.Context) error {
+			s.mu.RLock()
+			status := *s.status[check.Name]
+			s.mu.RUnlock()
+
+			if status.ConsecutiveFailures == 0 {
+				return nil
+			}
+			err := fmt.Errorf("%d consecutive failures, last: %s", status.ConsecutiveFailures, status.LastError)
+			if status.ConsecutiveFailures < check.FailureThreshold {
+				return health.Degraded(err)
+			}
+			return err
+		})
+	}
+}
+
+// discardEmitter drops the events of streaming canaries
+type discardEmitter struct {
+	ctx context.Context
+}
+
+func (e discardEmitter) EmitData(data interface{}) error                         { return e.ctx.Err() }
+func (e discardEmitter) EmitProgress(current, total int64, message string) error { return e.ctx.Err() }
+func (e discardEmitter) Context() context.Context                                { return e.ctx }
@@ -0,0 +1,201 @@
+package canary_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/canary"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+)
+
+func newWeatherBackend(fail *atomic.Bool, calls *atomic.Int32) *backend.BaseBackend {
+	b := backend.NewBaseBackend("weather")
+	b.RegisterTool(backend.NewTool("get_current_weather").Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		calls.Add(1)
+		if !canary.IsCanary(ctx) {
+			return nil, errors.New("not marked as canary")
+		}
+		if args["city"] != "London" {
+			return nil, errors.New("unexpected city")
+		}
+		if fail.Load() {
+			return nil, errors.New("upstream returned 500")
+		}
+		return map[string]interface{}{"temp": 12.5}, nil
+	})
+	return b
+}
+
+func TestScheduler_HealthAndStatus(t *testing.T) {
+	var fail atomic.Bool
+	var calls atomic.Int32
+	s := canary.NewScheduler(newWeatherBackend(&fail, &calls), nil)
+	if err := s.Add(canary.Check{
+		Tool:             "get_current_weather",
+		Args:             map[string]interface{}{"city": "London"},
+		FailureThreshold: 2,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(canary.Check{Tool: "get_current_weather"}); err == nil {
+		t.Error("duplicate canary accepted")
+	}
+
+	registry := health.NewRegistry()
+	s.RegisterHealthChecks(registry)
+	ready := func() health.Status {
+		return registry.Run(context.Background(), health.Readiness).Status
+	}
+
+	if status := ready(); status != health.StatusOK {
+		t.Errorf("before first run: %s", status)
+	}
+
+	if err := s.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if status := ready(); status != health.StatusOK {
+		t.Errorf("after success: %s", status)
+	}
+
+	fail.Store(true)
+	s.RunOnce(context.Background())
+	if status := ready(); status != health.StatusDegraded {
+		t.Errorf("after one failure: %s", status)
+	}
+	if err := s.RunOnce(context.Background()); err == nil {
+		t.Error("RunOnce didn't report the failure")
+	}
+	if status := ready(); status != health.StatusFailing {
+		t.Errorf("at threshold: %s", status)
+	}
+
+	fail.Store(false)
+	s.RunOnce(context.Background())
+	if status := ready(); status != health.StatusOK {
+		t.Errorf("after recovery: %s", status)
+	}
+
+	status := s.Status()[0]
+	if status.Name != "get_current_weather" || status.Successes != 2 || status.Failures != 2 || status.ConsecutiveFailures != 0 {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestScheduler_Validate(t *testing.T) {
+	var fail atomic.Bool
+	var calls atomic.Int32
+	s := canary.NewScheduler(newWeatherBackend(&fail, &calls), nil)
+	s.Add(canary.Check{
+		Name: "weather-shape",
+		Tool: "get_current_weather",
+		Args: map[string]interface{}{"city": "London"},
+		Validate: func(result interface{}) error {
+			if _, ok := result.(map[string]interface{})["humidity"]; !ok {
+				return errors.New("missing humidity")
+			}
+			return nil
+		},
+	})
+
+	if err := s.RunOnce(context.Background()); err == nil {
+		t.Error("invalid result accepted")
+	}
+	if got := s.Status()[0].LastError; got != "missing humidity" {
+		t.Errorf("LastError = %q", got)
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	var fail atomic.Bool
+	var calls atomic.Int32
+	s := canary.NewScheduler(newWeatherBackend(&fail, &calls), nil)
+	s.Add(canary.Check{
+		Tool:     "get_current_weather",
+		Args:     map[string]interface{}{"city": "London"},
+		Interval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	if n := calls.Load(); n < 3 {
+		t.Errorf("calls = %d, want periodic calls", n)
+	}
+}
+
+func TestScheduler_Schedules(t *testing.T) {
+	var fail atomic.Bool
+	var calls atomic.Int32
+	s := canary.NewScheduler(newWeatherBackend(&fail, &calls), nil)
+	if err := s.Add(canary.Check{Name: "both", Tool: "get_current_weather", Interval: time.Minute, Cron: "* * * * *"}); err == nil {
+		t.Error("canary with interval and cron accepted")
+	}
+	if err := s.Add(canary.Check{Name: "bad", Tool: "get_current_weather", Cron: "* * *"}); err == nil {
+		t.Error("invalid cron accepted")
+	}
+	s.Add(canary.Check{Name: "quarterly", Tool: "get_current_weather", Cron: "0 */15 * * * *"})
+	s.Add(canary.Check{Name: "every-minute", Tool: "get_current_weather"})
+
+	now := time.Date(2024, 1, 10, 10, 7, 30, 0, time.UTC)
+	schedules := s.Schedules(now, 2)
+	if len(schedules) != 2 || schedules[0].Name != "every-minute" || schedules[1].Name != "quarterly" {
+		t.Fatalf("schedules = %+v", schedules)
+	}
+	if sc := schedules[0]; sc.Interval != "1m0s" || !sc.Next[0].Equal(now) || !sc.Next[1].Equal(now.Add(time.Minute)) {
+		t.Errorf("interval schedule = %+v", sc)
+	}
+	if sc := schedules[1]; sc.Cron != "0 */15 * * * *" || !sc.Next[0].Equal(time.Date(2024, 1, 10, 10, 15, 0, 0, time.UTC)) {
+		t.Errorf("cron schedule = %+v", sc)
+	}
+
+	handler := s.Handler()
+	for target, status := range map[string]int{
+		"/debug/schedules":                 http.StatusOK,
+		"/debug/schedules?n=3&cron=@daily": http.StatusOK,
+		"/debug/schedules?cron=0+0+30+2+*": http.StatusBadRequest,
+		"/debug/schedules?n=1000":          http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != status {
+			t.Errorf("GET %s = %d, want %d: %s", target, rec.Code, status, rec.Body)
+		}
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/schedules?n=3&cron=@daily", nil))
+	var preview struct {
+		Next []time.Time `json:"next"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&preview); err != nil || len(preview.Next) != 3 {
+		t.Errorf("preview = %+v, %v", preview, err)
+	}
+}
+
+func TestScheduler_RunCron(t *testing.T) {
+	var fail atomic.Bool
+	var calls atomic.Int32
+	s := canary.NewScheduler(newWeatherBackend(&fail, &calls), nil)
+	s.Add(canary.Check{
+		Tool: "get_current_weather",
+		Args: map[string]interface{}{"city": "London"},
+		Cron: "* * * * * *",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1100*time.Millisecond)
+	defer cancel()
+	s.Run(ctx)
+
+	// Cron canaries wait for their first scheduled second
+	if n := calls.Load(); n < 1 || n > 2 {
+		t.Errorf("calls = %d, want one per second", n)
+	}
+}
@@ -0,0 +1,271 @@
+package canary
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================
+// Cron expressions
+// ============================================================
+
+// Cron is a parsed cron expression
+//
+// Five fields are the standard minute, hour, day of month, month and day
+// of week; a sixth, leading field adds seconds:
+//
+//	*/5 * * * *        every five minutes
+//	30 */10 * * * *    every ten minutes, at 30 seconds past
+//	0 9 * * MON-FRI    weekdays at 09:00
+//
+// Fields take *, ?, lists (1,15), ranges (1-5), steps (*/10, 5-30/5) and
+// names (JAN-DEC, SUN-SAT; 7 is also Sunday). As in Vixie cron, when both
+// day of month and day of week are restricted a day matching either runs.
+// The descriptors @yearly (@annually), @monthly, @weekly, @daily
+// (@midnight) and @hourly stand for their usual expressions, and a
+// "CRON_TZ=<zone> " prefix evaluates the expression in an IANA zone
+// instead of the time passed to Next.
+type Cron struct {
+	spec string
+	loc  *time.Location
+
+	second, minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny are set for unrestricted day fields
+	domAny, dowAny bool
+}
+
+// cronSearchYears bounds the search for the next occurrence, so
+// expressions such as "0 0 30 2 *" end instead of looping
+const cronSearchYears = 5
+
+// cronField is the range and names of a cron field
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names of min, min+1, ...
+}
+
+var (
+	secondField = cronField{name: "second", min: 0, max: 59}
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12,
+		names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	dowField = cronField{name: "day of week", min: 0, max: 7,
+		names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseCron parses a cron expression
+// Expressions that can never match, such as February 30th, are errors.
+func ParseCron(spec string) (*Cron, error) {
+	c := &Cron{spec: spec}
+	expr := strings.TrimSpace(spec)
+
+	if rest, ok := cutTZ(expr); ok {
+		zone, fields, _ := strings.Cut(rest, " ")
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: unknown time zone %q", spec, zone)
+		}
+		c.loc = loc
+		expr = strings.TrimSpace(fields)
+	}
+	if strings.HasPrefix(expr, "@") {
+		descriptor, ok := cronDescriptors[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("cron %q: unknown descriptor %s", spec, expr)
+		}
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron %q: want 5 fields, or 6 with seconds, got %d", spec, len(fields))
+	}
+
+	var err error
+	targets := []*uint64{&c.second, &c.minute, &c.hour, &c.dom, &c.month, &c.dow}
+	for i, field := range []cronField{secondField, minuteField, hourField, domField, monthField, dowField} {
+		if *targets[i], err = field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("cron %q: %w", spec, err)
+		}
+	}
+	// 7 is another name for Sunday
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domAny = strings.HasPrefix(fields[3], "*") || fields[3] == "?"
+	c.dowAny = strings.HasPrefix(fields[5], "*") || fields[5] == "?"
+
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron %q: never matches", spec)
+	}
+	return c, nil
+}
+
+// cutTZ strips a CRON_TZ= or TZ= prefix
+func cutTZ(expr string) (string, bool) {
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if rest, ok := strings.CutPrefix(expr, prefix); ok {
+			return rest, true
+		}
+	}
+	return expr, false
+}
+
+// parse parses a field into a bitset of its values
+func (f cronField) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		lo, hi, step := f.min, f.max, 1
+
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s field: invalid step %q", f.name, stepExpr)
+			}
+			step = n
+		}
+		switch {
+		case rangeExpr == "*" || rangeExpr == "?":
+		case strings.Contains(rangeExpr, "-"):
+			from, to, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if lo, err = f.value(from); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(to); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s field: range %s is backwards", f.name, rangeExpr)
+			}
+		default:
+			v, err := f.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+			// "5/15" is "5-max/15"; a bare value is just itself
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a number or name of the field
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s field: invalid value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s field: %d is outside %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression as given to ParseCron
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// Next returns the first time after t the expression matches, in t's
+// location, or the zero time if it doesn't match within five years
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	if c.loc != nil {
+		t = t.In(c.loc)
+	}
+	// Occurrences fall on whole seconds strictly after t
+	t = t.Truncate(time.Second).Add(time.Second)
+	limit := t.Year() + cronSearchYears
+
+search:
+	for t.Year() <= limit {
+		for c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			if t.Month() == time.January {
+				continue search
+			}
+		}
+		for !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			if t.Day() == 1 {
+				continue search
+			}
+		}
+		for c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location()).Add(time.Hour)
+			if t.Hour() == 0 {
+				continue search
+			}
+		}
+		for c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			if t.Minute() == 0 {
+				continue search
+			}
+		}
+		for c.second&(1<<uint(t.Second())) == 0 {
+			t = t.Add(time.Second)
+			if t.Second() == 0 {
+				continue search
+			}
+		}
+		return t.In(loc)
+	}
+	return time.Time{}
+}
+
+// NextN returns the next n occurrences after t, fewer if the expression
+// stops matching
+func (c *Cron) NextN(t time.Time, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+	for len(times) < n {
+		if t = c.Next(t); t.IsZero() {
+			break
+		}
+		times = append(times, t)
+	}
+	return times
+}
+
+// dayMatches checks the day of month and day of week fields
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
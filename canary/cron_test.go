@@ -0,0 +1,62 @@
+package canary_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/canary"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	// Wednesday, 2024-01-10 10:07:30 UTC
+	from := time.Date(2024, 1, 10, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"*/5 * * * *", []string{"2024-01-10T10:10:00Z", "2024-01-10T10:15:00Z"}},
+		{"30 */10 * * * *", []string{"2024-01-10T10:10:30Z", "2024-01-10T10:20:30Z"}},
+		{"0 9 * * MON-FRI", []string{"2024-01-11T09:00:00Z", "2024-01-12T09:00:00Z", "2024-01-15T09:00:00Z"}},
+		{"0 0 1,15 * *", []string{"2024-01-15T00:00:00Z", "2024-02-01T00:00:00Z"}},
+		{"0 0 13 * 5", []string{"2024-01-12T00:00:00Z", "2024-01-13T00:00:00Z", "2024-01-19T00:00:00Z"}},
+		{"0 12 29 feb ?", []string{"2024-02-29T12:00:00Z", "2028-02-29T12:00:00Z"}},
+		{"0 0 * * 7", []string{"2024-01-14T00:00:00Z"}},
+		{"@monthly", []string{"2024-02-01T00:00:00Z", "2024-03-01T00:00:00Z"}},
+		{"@hourly", []string{"2024-01-10T11:00:00Z"}},
+		{"CRON_TZ=America/New_York 0 9 * * *", []string{"2024-01-10T14:00:00Z", "2024-01-11T14:00:00Z"}},
+	}
+	for _, tt := range tests {
+		c, err := canary.ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q) error = %v", tt.spec, err)
+			continue
+		}
+		got := c.NextN(from, len(tt.want))
+		for i, want := range tt.want {
+			if i >= len(got) || got[i].Format(time.RFC3339) != want {
+				t.Errorf("%q: next = %v, want %v", tt.spec, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	for spec, want := range map[string]string{
+		"* * * *":                "want 5 fields",
+		"61 * * * *":             "minute field: 61 is outside 0-59",
+		"* * * FOO *":            `month field: invalid value "FOO"`,
+		"*/0 * * * *":            "invalid step",
+		"5-1 * * * *":            "backwards",
+		"0 0 30 2 *":             "never matches",
+		"@fortnightly":           "unknown descriptor",
+		"TZ=Mars/Base * * * * *": "unknown time zone",
+	} {
+		_, err := canary.ParseCron(spec)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseCron(%q) error = %v, want %q", spec, err, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package canary
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// PathSchedules is where the framework serves Handler, on the metrics
+// server
+const PathSchedules = "/debug/schedules"
+
+// Preview lengths of Handler's ?n= parameter
+const (
+	DefaultPreview = 5
+	MaxPreview     = 100
+)
+
+// Schedule is when a canary runs
+type Schedule struct {
+	Name     string `json:"name"`
+	Tool     string `json:"tool"`
+	Cron     string `json:"cron,omitempty"`
+	Interval string `json:"interval,omitempty"`
+
+	// Next are the upcoming runs; for interval canaries they count from
+	// the last run (or now, before the first), so they are estimates
+	Next []time.Time `json:"next"`
+
+	LastRun   time.Time `json:"last_run,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Schedules returns each canary's schedule with its next n runs after
+// now, sorted by name
+func (s *Scheduler) Schedules(now time.Time, n int) []Schedule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schedules := make([]Schedule, 0, len(s.checks))
+	for _, check := range s.checks {
+		status := s.status[check.Name]
+		schedule := Schedule{
+			Name:      check.Name,
+			Tool:      check.Tool,
+			LastRun:   status.LastRun,
+			LastError: status.LastError,
+		}
+		if check.cron != nil {
+			schedule.Cron = check.Cron
+			schedule.Next = check.cron.NextN(now, n)
+		} else {
+			schedule.Interval = check.Interval.String()
+			schedule.Next = intervalRuns(status.LastRun, check.Interval, now, n)
+		}
+		schedules = append(schedules, schedule)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Name < schedules[j].Name })
+	return schedules
+}
+
+// intervalRuns returns the next n runs of an interval canary
+func intervalRuns(lastRun time.Time, interval time.Duration, now time.Time, n int) []time.Time {
+	next := now
+	if !lastRun.IsZero() {
+		next = lastRun.Add(interval)
+		if next.Before(now) {
+			next = now
+		}
+	}
+	runs := make([]time.Time, n)
+	for i := range runs {
+		runs[i] = next.Add(time.Duration(i) * interval)
+	}
+	return runs
+}
+
+// Handler serves the canaries' schedules and previews cron expressions
+//
+//	GET /debug/schedules                 schedules with their next 5 runs
+//	GET /debug/schedules?n=20            ... with their next 20 runs
+//	GET /debug/schedules?cron=0+9+*+*+1  validate an expression and list
+//	                                     its next runs
+//
+// An invalid expression is a 400 with the parse error, so the endpoint
+// doubles as a validator before a schedule goes into config.
+func (s *Scheduler) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		n := DefaultPreview
+		if v := r.URL.Query().Get("n"); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed <= 0 || parsed > MaxPreview {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "n must be between 1 and " + strconv.Itoa(MaxPreview)})
+				return
+			}
+			n = parsed
+		}
+
+		now := time.Now()
+		if spec := r.URL.Query().Get("cron"); spec != "" {
+			cron, err := ParseCron(spec)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"cron": spec, "error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"cron": spec, "next": cron.NextN(now, n)})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"schedules": s.Schedules(now, n)})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
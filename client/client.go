@@ -0,0 +1,384 @@
+// Package client implements an MCP client
+//
+// A Client speaks to an MCP server over a Transport: a child process
+// (stdio), HTTP with SSE-streamed responses, or WebSocket. It lists and
+// calls tools, reports a call's progress and decodes tool results into Go
+// types:
+//
+//	c := client.NewHTTP(client.HTTPConfig{URL: "http://localhost:8080/rpc"}, nil)
+//	defer c.Close()
+//
+//	if _, err := c.Initialize(ctx); err != nil {
+//		return err
+//	}
+//	weather, err := client.CallTyped[Weather](ctx, c, "get_weather", map[string]interface{}{
+//		"city": "Cairo",
+//	})
+//
+// Server errors are categorized as on the server: errors.Is(err,
+// mcperr.ErrNotFound) and errors.Is(err, backend.ErrInvalidArguments)
+// hold for errors the server reported that way.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// ProtocolVersion is the MCP version the client requests
+const ProtocolVersion = "2025-06-18"
+
+// ClientName and ClientVersion identify the client to servers
+const (
+	ClientName    = "go-mcp-framework"
+	ClientVersion = "1.0.0"
+)
+
+// ErrStreamingUnsupported is returned by Stream on transports without a
+// streaming endpoint
+var ErrStreamingUnsupported = errors.New("transport does not support streaming")
+
+// Client is an MCP client; it is safe for concurrent use
+type Client struct {
+	transport Transport
+
+	mu   sync.Mutex
+	info *InitializeResult
+}
+
+// New creates a client over a transport
+func New(transport Transport) *Client {
+	return &Client{transport: transport}
+}
+
+// NewStdio starts a server process and returns a client for it
+func NewStdio(config StdioConfig, logger *slog.Logger) (*Client, error) {
+	transport, err := NewStdioTransport(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	return New(transport), nil
+}
+
+// NewHTTP returns a client for a server reached over HTTP
+func NewHTTP(config HTTPConfig, logger *slog.Logger) *Client {
+	return New(NewHTTPTransport(config, logger))
+}
+
+// NewWebSocket connects to a server over WebSocket
+func NewWebSocket(ctx context.Context, config WebSocketConfig, logger *slog.Logger) (*Client, error) {
+	transport, err := NewWebSocketTransport(ctx, config, logger)
+	if err != nil {
+		return nil, err
+	}
+	return New(transport), nil
+}
+
+// Transport returns the client's transport
+func (c *Client) Transport() Transport {
+	return c.transport
+}
+
+// ============================================================
+// Lifecycle
+// ============================================================
+
+// ServerInfo identifies a server
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// InitializeResult is a server's answer to initialize
+type InitializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	ServerInfo      ServerInfo             `json:"serverInfo"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+// Initialize performs the MCP handshake
+// Servers that answer initialize with MethodNotFound (tools-only servers
+// such as this framework's) are accepted with an empty result.
+func (c *Client) Initialize(ctx context.Context) (*InitializeResult, error) {
+	raw, err := c.transport.Call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    ClientName,
+			"version": ClientVersion,
+		},
+	})
+
+	result := &InitializeResult{}
+	switch {
+	case errors.Is(err, ErrMethodNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(raw, result); err != nil {
+			return nil, mcperr.Upstream(err, "invalid initialize result")
+		}
+		if err := c.transport.Notify(ctx, "notifications/initialized", nil); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	c.info = result
+	c.mu.Unlock()
+	return result, nil
+}
+
+// ServerInfo returns the result of Initialize, or nil before it
+func (c *Client) ServerInfo() *InitializeResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.info
+}
+
+// Ping checks that the server answers
+// Servers without ping (MethodNotFound) answered, so they pass.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.transport.Call(ctx, "ping", nil)
+	if errors.Is(err, ErrMethodNotFound) {
+		return nil
+	}
+	return err
+}
+
+// OnNotification registers fn for the server's notifications (e.g.
+// notifications/tools/list_changed)
+func (c *Client) OnNotification(fn NotificationHandler) {
+	c.transport.OnNotification(fn)
+}
+
+// Alive reports whether the server can be reached without reconnecting
+func (c *Client) Alive() bool {
+	return c.transport.Alive()
+}
+
+// Close closes the transport
+func (c *Client) Close() error {
+	return c.transport.Close()
+}
+
+// Call sends a raw request, for methods the client has no helper for
+func (c *Client) Call(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error) {
+	return c.transport.Call(ctx, method, params)
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+// ListTools lists the server's tools, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]protocol.ToolInfo, error) {
+	var tools []protocol.ToolInfo
+	var cursor string
+	for {
+		var params map[string]interface{}
+		if cursor != "" {
+			params = map[string]interface{}{"cursor": cursor}
+		}
+		raw, err := c.transport.Call(ctx, "tools/list", params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Tools      []protocol.ToolInfo `json:"tools"`
+			NextCursor string              `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, mcperr.Upstream(err, "invalid tools/list result")
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// CallTool calls a tool
+// A result the server flags with isError is returned as is, without an
+// error; see DecodeResult for turning it into one.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]interface{}) (*protocol.ToolCallResult, error) {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	raw, err := c.transport.Call(ctx, "tools/call", map[string]interface{}{
+		"name":      name,
+		"arguments": args,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result protocol.ToolCallResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, mcperr.Upstream(err, "invalid tools/call result")
+	}
+	return &result, nil
+}
+
+// CallToolWithProgress calls a tool, passing its progress notifications
+// to fn as they arrive
+func (c *Client) CallToolWithProgress(ctx context.Context, name string, args map[string]interface{}, fn ProgressFunc) (*protocol.ToolCallResult, error) {
+	return c.CallTool(WithProgress(ctx, fn), name, args)
+}
+
+// Streamer is implemented by transports that can run a streaming tool
+// event by event (the HTTP transport's /stream endpoint)
+type Streamer interface {
+	Stream(ctx context.Context, tool string, args map[string]interface{}, fn func(StreamEvent) error) error
+}
+
+// Stream runs a streaming tool, calling fn with each of its events
+// It returns ErrStreamingUnsupported when the transport is not a
+// Streamer; CallToolWithProgress works on every transport.
+func (c *Client) Stream(ctx context.Context, tool string, args map[string]interface{}, fn func(StreamEvent) error) error {
+	streamer, ok := c.transport.(Streamer)
+	if !ok {
+		return ErrStreamingUnsupported
+	}
+	return streamer.Stream(ctx, tool, args, fn)
+}
+
+// ============================================================
+// Resources and prompts
+// ============================================================
+
+// ListResources lists the server's resources, following nextCursor;
+// servers without resources (resources/list not found) have none
+func (c *Client) ListResources(ctx context.Context) ([]backend.Resource, error) {
+	var resources []backend.Resource
+	err := c.list(ctx, "resources/list", func(raw json.RawMessage) error {
+		var page struct {
+			Resources []backend.Resource `json:"resources"`
+		}
+		err := json.Unmarshal(raw, &page)
+		resources = append(resources, page.Resources...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// ListPrompts lists the server's prompts, following nextCursor; servers
+// without prompts (prompts/list not found) have none
+func (c *Client) ListPrompts(ctx context.Context) ([]backend.Prompt, error) {
+	var prompts []backend.Prompt
+	err := c.list(ctx, "prompts/list", func(raw json.RawMessage) error {
+		var page struct {
+			Prompts []backend.Prompt `json:"prompts"`
+		}
+		err := json.Unmarshal(raw, &page)
+		prompts = append(prompts, page.Prompts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prompts, nil
+}
+
+// list hands each page of an optional list method to decode
+func (c *Client) list(ctx context.Context, method string, decode func(raw json.RawMessage) error) error {
+	var cursor string
+	for {
+		var params map[string]interface{}
+		if cursor != "" {
+			params = map[string]interface{}{"cursor": cursor}
+		}
+		raw, err := c.transport.Call(ctx, method, params)
+		if errors.Is(err, ErrMethodNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var page struct {
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return mcperr.Upstream(err, "invalid %s result", method)
+		}
+		if err := decode(raw); err != nil {
+			return mcperr.Upstream(err, "invalid %s result", method)
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// ============================================================
+// Typed results
+// ============================================================
+
+// ToolError is a tool result the server flagged with isError
+type ToolError struct {
+	Result *protocol.ToolCallResult
+}
+
+func (e *ToolError) Error() string {
+	for _, item := range e.Result.Content {
+		if item.Type == "text" && item.Text != "" {
+			return item.Text
+		}
+	}
+	return "tool failed"
+}
+
+// DecodeResult decodes a tool result into T: its structured content if
+// any, otherwise its first text item as JSON
+// A result flagged with isError is returned as a *ToolError.
+func DecodeResult[T any](result *protocol.ToolCallResult) (T, error) {
+	var v T
+	if result.IsError {
+		return v, &ToolError{Result: result}
+	}
+
+	var data []byte
+	if result.StructuredContent != nil {
+		data, _ = json.Marshal(result.StructuredContent)
+	} else {
+		for _, item := range result.Content {
+			if item.Type == "text" {
+				data = []byte(item.Text)
+				break
+			}
+		}
+	}
+	if data == nil {
+		return v, fmt.Errorf("tool result has no structured or text content")
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, fmt.Errorf("decode tool result: %w", err)
+	}
+	return v, nil
+}
+
+// CallTyped calls a tool and decodes its result into T
+func CallTyped[T any](ctx context.Context, c *Client, name string, args map[string]interface{}) (T, error) {
+	result, err := c.CallTool(ctx, name, args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return DecodeResult[T](result)
+}
@@ -0,0 +1,287 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+	"github.com/SaherElMasry/go-mcp-framework/transport/stdio"
+)
+
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// The test binary doubles as a stdio server when CLIENT_TEST_SERVER is set
+func TestMain(m *testing.M) {
+	if os.Getenv("CLIENT_TEST_SERVER") != "" {
+		stdio.NewStdioTransport(protocol.NewHandler(testBackend(), discard), discard).Run(context.Background())
+		return
+	}
+	os.Exit(m.Run())
+}
+
+type weather struct {
+	City    string  `json:"city"`
+	Celsius float64 `json:"celsius"`
+}
+
+// testBackend serves echo (whose text selects a failure), weather and the
+// streaming count
+func testBackend() *backend.BaseBackend {
+	b := backend.NewBaseBackend("test")
+	b.RegisterTool(backend.NewTool("echo").
+		Description("Echo text").
+		StringParam("text", "Text to echo", true).
+		Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		switch args["text"] {
+		case "missing":
+			return nil, mcperr.NotFound("no such thing")
+		case "fail":
+			return nil, backend.NewToolError("tool failed")
+		}
+		backend.EmitProgress(ctx, 1, 2, "halfway")
+		return backend.NewTextResult("echo: " + args["text"].(string)), nil
+	})
+	b.RegisterTool(backend.NewTool("weather").
+		Description("Current weather").
+		StringParam("city", "City", true).
+		Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return weather{City: args["city"].(string), Celsius: 31.5}, nil
+	})
+	b.RegisterStreamingTool(backend.NewTool("count").
+		Description("Count to three").
+		Streaming(true).
+		Build(), func(ctx context.Context, args map[string]interface{}, emit backend.StreamingEmitter) error {
+		for i := 1; i <= 3; i++ {
+			if err := emit.EmitData(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return b
+}
+
+// exercise runs the checks every transport must pass
+func exercise(t *testing.T, c *Client) {
+	t.Helper()
+	ctx := context.Background()
+
+	// This framework's server has no initialize; the client accepts that
+	if _, err := c.Initialize(ctx); err != nil {
+		t.Fatalf("initialize = %v", err)
+	}
+	if err := c.Ping(ctx); err != nil {
+		t.Errorf("ping = %v", err)
+	}
+
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, ",") != "count,echo,weather" {
+		t.Errorf("tools = %v", names)
+	}
+
+	var mu sync.Mutex
+	var progress []string
+	result, err := c.CallToolWithProgress(ctx, "echo", map[string]interface{}{"text": "hi"}, func(current, total int64, message string) {
+		mu.Lock()
+		progress = append(progress, message)
+		mu.Unlock()
+	})
+	if err != nil || result.IsError || result.Content[0].Text != "echo: hi" {
+		t.Fatalf("call = %+v, %v", result, err)
+	}
+	mu.Lock()
+	if len(progress) != 1 || progress[0] != "halfway" {
+		t.Errorf("progress = %v", progress)
+	}
+	mu.Unlock()
+
+	w, err := CallTyped[weather](ctx, c, "weather", map[string]interface{}{"city": "Cairo"})
+	if err != nil || w.City != "Cairo" || w.Celsius != 31.5 {
+		t.Errorf("typed = %+v, %v", w, err)
+	}
+
+	if _, err := c.CallTool(ctx, "echo", map[string]interface{}{"text": "missing"}); !errors.Is(err, mcperr.ErrNotFound) {
+		t.Errorf("categorized error = %v", err)
+	}
+	if _, err := c.CallTool(ctx, "echo", nil); !errors.Is(err, backend.ErrInvalidArguments) {
+		t.Errorf("invalid arguments = %v", err)
+	}
+	_, err = CallTyped[string](ctx, c, "echo", map[string]interface{}{"text": "fail"})
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Error() != "tool failed" {
+		t.Errorf("tool error = %v", err)
+	}
+
+	if resources, err := c.ListResources(ctx); err != nil || len(resources) != 0 {
+		t.Errorf("resources = %v, %v", resources, err)
+	}
+}
+
+func TestClient_Stdio(t *testing.T) {
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewStdio(StdioConfig{Command: self, Env: map[string]string{"CLIENT_TEST_SERVER": "1"}}, discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise(t, c)
+
+	if err := c.Stream(context.Background(), "count", nil, nil); !errors.Is(err, ErrStreamingUnsupported) {
+		t.Errorf("stream = %v", err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Errorf("close = %v", err)
+	}
+	if c.Alive() {
+		t.Error("alive after close")
+	}
+	if _, err := c.ListTools(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("call after close = %v", err)
+	}
+}
+
+func TestClient_HTTP(t *testing.T) {
+	b := testBackend()
+	broadcaster := transport.NewBroadcaster()
+	tr := httpTransport.NewHTTPTransport(protocol.NewHandler(b, discard), httpTransport.HTTPConfig{MaxRequestSize: 1 << 20}, discard, b,
+		engine.NewExecutor(engine.DefaultExecutorConfig(), discard))
+	tr.SetBroadcaster(broadcaster)
+	server := httptest.NewServer(tr.Handler())
+	t.Cleanup(server.Close)
+
+	c := NewHTTP(HTTPConfig{URL: server.URL + httpTransport.PathRPC}, discard)
+	t.Cleanup(func() { c.Close() })
+	exercise(t, c)
+
+	// Streaming tools run event by event through /stream
+	var types []string
+	var chunks []int
+	err := c.Stream(context.Background(), "count", nil, func(event StreamEvent) error {
+		types = append(types, event.Type)
+		if event.Type == "data" {
+			var data struct {
+				Chunk int `json:"chunk"`
+			}
+			if err := event.Decode(&data); err != nil {
+				return err
+			}
+			chunks = append(chunks, data.Chunk)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types[0] != "start" || types[len(types)-1] != "end" || len(chunks) != 3 || chunks[2] != 3 {
+		t.Errorf("events = %v, chunks = %v", types, chunks)
+	}
+	if err := c.Stream(context.Background(), "echo", nil, func(StreamEvent) error { return nil }); err == nil {
+		t.Error("streaming a regular tool succeeded")
+	}
+
+	// Server notifications arrive once a handler is registered
+	changed := make(chan string, 1)
+	c.OnNotification(func(method string, params map[string]interface{}) {
+		changed <- method
+	})
+	for broadcaster.Subscribers() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	broadcaster.Broadcast([]byte(`{"jsonrpc":"2.0","method":"` + protocol.NotificationToolsListChanged + `"}`))
+	if method := <-changed; method != protocol.NotificationToolsListChanged {
+		t.Errorf("notification = %s", method)
+	}
+}
+
+func TestClient_WebSocket(t *testing.T) {
+	handler := protocol.NewHandler(testBackend(), discard)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWebSocket(t, w, r, handler)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewWebSocket(context.Background(), WebSocketConfig{URL: "ws" + strings.TrimPrefix(server.URL, "http") + "/mcp"}, discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise(t, c)
+	if err := c.Close(); err != nil {
+		t.Errorf("close = %v", err)
+	}
+	if c.Alive() {
+		t.Error("alive after close")
+	}
+}
+
+// serveWebSocket is a minimal WebSocket MCP server: each text message is
+// a request, answered (with its progress) in unmasked frames
+func serveWebSocket(t *testing.T, w http.ResponseWriter, r *http.Request, handler transport.Handler) {
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		http.Error(w, "bad handshake", http.StatusBadRequest)
+		return
+	}
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+	rw.Flush()
+
+	var writeMu sync.Mutex
+	send := func(op byte, data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return writeFrame(conn, op, data, false)
+	}
+	ctx := transport.WithNotifier(r.Context(), transport.NotifierFunc(func(message []byte) error {
+		return send(opText, message)
+	}))
+
+	reader := bufio.NewReader(rw)
+	for {
+		op, _, payload, err := readFrame(reader)
+		if err != nil || op == opClose {
+			return
+		}
+		if op != opText {
+			continue
+		}
+		go func() {
+			resp, err := handler.Handle(ctx, payload, "websocket")
+			if err == nil && resp != nil {
+				send(opText, resp)
+			}
+		}()
+	}
+}
@@ -0,0 +1,416 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// maxResponseSize bounds a server's response
+const maxResponseSize = 32 * 1024 * 1024
+
+// HTTPConfig describes an MCP server reached over HTTP
+type HTTPConfig struct {
+	// URL is the server's JSON-RPC endpoint (e.g. http://host:8080/rpc)
+	URL string
+
+	// StreamURL is the server's SSE streaming endpoint (default: URL with
+	// /rpc replaced by /stream)
+	StreamURL string
+
+	// Headers are sent with every request (e.g. Authorization)
+	Headers map[string]string
+
+	// Client defaults to an http.Client without a timeout; calls are
+	// bounded by their context
+	Client *http.Client
+}
+
+// HTTPTransport sends JSON-RPC requests over HTTP
+// Responses streamed as text/event-stream carry progress notifications
+// before the result; server-initiated notifications are read from a GET
+// event stream once OnNotification is set.
+type HTTPTransport struct {
+	config HTTPConfig
+	client *http.Client
+	logger *slog.Logger
+
+	nextID atomic.Int64
+
+	mu       sync.Mutex
+	onNotify NotificationHandler
+	cancel   context.CancelFunc
+	closed   bool
+}
+
+// NewHTTPTransport creates a transport to the server at config.URL
+func NewHTTPTransport(config HTTPConfig, logger *slog.Logger) *HTTPTransport {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	if config.StreamURL == "" {
+		config.StreamURL = strings.TrimSuffix(config.URL, "/rpc") + "/stream"
+	}
+	return &HTTPTransport{
+		config: config,
+		client: client,
+		logger: logger.With("url", config.URL),
+	}
+}
+
+// newRequest creates a request with the configured headers
+func (t *HTTPTransport) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range t.config.Headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// post sends a JSON-RPC message, returning the response for the caller
+// to read
+func (t *HTTPTransport) post(ctx context.Context, data []byte) (*http.Response, error) {
+	t.mu.Lock()
+	closed := t.closed
+	t.mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	req, err := t.newRequest(ctx, http.MethodPost, t.config.URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, mcperr.Upstream(err, "request to %s", t.config.URL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, statusError(resp, t.config.URL)
+	}
+	return resp, nil
+}
+
+// Call implements Transport
+func (t *HTTPTransport) Call(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error) {
+	id := t.nextID.Add(1)
+	data, err := encodeRequest(ctx, id, method, params)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.post(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	progress := progressFrom(ctx)
+	var result *message
+	handle := func(msg message) {
+		switch {
+		case msg.ID != nil && *msg.ID == id:
+			result = &msg
+		case msg.ID == nil && msg.Method == "" && msg.Error != nil:
+			// Errors of unparseable requests have a null id
+			result = &msg
+		case msg.Method == "notifications/progress" && progress != nil:
+			if token, current, total, text, ok := progressParams(msg.Params); ok && token == id {
+				progress(current, total, text)
+			}
+		}
+	}
+
+	if isEventStream(resp) {
+		err = readEvents(io.LimitReader(resp.Body, maxResponseSize), func(_ string, data []byte) bool {
+			var msg message
+			if json.Unmarshal(data, &msg) == nil {
+				handle(msg)
+			}
+			return result == nil
+		})
+	} else {
+		var msg message
+		if err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&msg); err == nil {
+			handle(msg)
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, mcperr.Upstream(err, "read response from %s", t.config.URL)
+	}
+	if result == nil {
+		return nil, mcperr.Upstream(fmt.Errorf("no response to request %d", id), "request to %s", t.config.URL)
+	}
+	return result.response()
+}
+
+// Notify implements Transport; the server's reply, if any, is discarded
+func (t *HTTPTransport) Notify(ctx context.Context, method string, params map[string]interface{}) error {
+	data, err := encodeNotification(method, params)
+	if err != nil {
+		return err
+	}
+	resp, err := t.post(ctx, data)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseSize))
+	return resp.Body.Close()
+}
+
+// OnNotification implements Transport, opening the server's notification
+// stream (GET on the endpoint) in the background
+// Servers without one are not retried.
+func (t *HTTPTransport) OnNotification(fn NotificationHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onNotify = fn
+	if t.cancel != nil || t.closed {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	go t.listen(ctx)
+}
+
+// listen reads the notification stream, reconnecting with backoff
+func (t *HTTPTransport) listen(ctx context.Context) {
+	backoff := time.Second
+	for {
+		supported, err := t.readNotifications(ctx)
+		if ctx.Err() != nil || !supported {
+			return
+		}
+		if err != nil {
+			t.logger.Debug("notification stream failed", "error", err, "retry_in", backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// readNotifications reads one notification stream until it ends; it
+// reports whether the server serves one at all
+func (t *HTTPTransport) readNotifications(ctx context.Context) (bool, error) {
+	req, err := t.newRequest(ctx, http.MethodGet, t.config.URL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || !isEventStream(resp) {
+		return false, nil
+	}
+
+	return true, readEvents(resp.Body, func(_ string, data []byte) bool {
+		var msg message
+		if json.Unmarshal(data, &msg) != nil || msg.Method == "" {
+			return true
+		}
+		t.mu.Lock()
+		onNotify := t.onNotify
+		t.mu.Unlock()
+		if onNotify != nil {
+			onNotify(msg.Method, msg.Params)
+		}
+		return true
+	})
+}
+
+// Alive implements Transport; HTTP servers are assumed reachable
+func (t *HTTPTransport) Alive() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.closed
+}
+
+// Close stops the notification stream
+func (t *HTTPTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	if t.cancel != nil {
+		t.cancel()
+	}
+	return nil
+}
+
+// ============================================================
+// Streaming endpoint
+// ============================================================
+
+// StreamEvent is an event of the streaming endpoint: start, data,
+// progress, retry, log, result, end or error
+type StreamEvent struct {
+	Type string
+	Data json.RawMessage
+}
+
+// Decode unmarshals the event's payload into v
+func (e StreamEvent) Decode(v interface{}) error {
+	return json.Unmarshal(e.Data, v)
+}
+
+// ErrStreamFailed wraps the message of a stream's error event
+var ErrStreamFailed = errors.New("stream failed")
+
+// Stream runs a streaming tool through the server's SSE endpoint, calling
+// fn with each event until the end event
+// An error event ends the stream with an error wrapping ErrStreamFailed;
+// fn returning an error stops reading and returns it.
+func (t *HTTPTransport) Stream(ctx context.Context, tool string, args map[string]interface{}, fn func(StreamEvent) error) error {
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	body, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	endpoint := t.config.StreamURL + "?tool=" + url.QueryEscape(tool)
+	req, err := t.newRequest(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return mcperr.Upstream(err, "stream from %s", t.config.StreamURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp, t.config.StreamURL)
+	}
+
+	var streamErr error
+	err = readEvents(resp.Body, func(event string, data []byte) bool {
+		evt := StreamEvent{Type: event, Data: append(json.RawMessage(nil), data...)}
+		if evt.Type == "error" {
+			var payload struct {
+				Message string `json:"message"`
+			}
+			json.Unmarshal(data, &payload)
+			streamErr = fmt.Errorf("%w: %s", ErrStreamFailed, payload.Message)
+			return false
+		}
+		if streamErr = fn(evt); streamErr != nil {
+			return false
+		}
+		return evt.Type != "end"
+	})
+	if streamErr != nil {
+		return streamErr
+	}
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// ============================================================
+// Helpers
+// ============================================================
+
+// isEventStream reports whether a response is an SSE stream
+func isEventStream(resp *http.Response) bool {
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+}
+
+// statusError categorizes a non-200 response
+func statusError(resp *http.Response, endpoint string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	msg := fmt.Sprintf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return mcperr.PermissionDenied("%s: %s", endpoint, msg)
+	case http.StatusTooManyRequests:
+		var retryAfter time.Duration
+		if seconds, err := time.ParseDuration(resp.Header.Get("Retry-After") + "s"); err == nil {
+			retryAfter = seconds
+		}
+		return mcperr.RateLimited(retryAfter, "%s: %s", endpoint, msg)
+	}
+	return mcperr.Upstream(errors.New(msg), "request to %s", endpoint)
+}
+
+// readEvents calls fn with the event name and data of each SSE event
+// until it returns false or the stream ends
+func readEvents(r io.Reader, fn func(event string, data []byte) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxResponseSize)
+
+	var event string
+	var data bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		switch {
+		case len(line) == 0:
+			if data.Len() > 0 {
+				if !fn(event, data.Bytes()) {
+					return nil
+				}
+			}
+			event = ""
+			data.Reset()
+		case bytes.HasPrefix(line, []byte("event:")):
+			event = string(bytes.TrimSpace(bytes.TrimPrefix(line, []byte("event:"))))
+		case bytes.HasPrefix(line, []byte("data:")):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.Write(bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" ")))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if data.Len() > 0 {
+		fn(event, data.Bytes())
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// session multiplexes requests over a connection carrying one JSON-RPC
+// message per write (stdio lines, WebSocket frames)
+// The transport feeds it incoming messages and ends it when the
+// connection closes.
+type session struct {
+	write  func(data []byte) error
+	logger *slog.Logger
+
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	nextID   int64
+	pending  map[int64]*pendingCall
+	onNotify NotificationHandler
+	done     chan struct{}
+	err      error
+}
+
+// pendingCall is a request awaiting its response
+type pendingCall struct {
+	response chan message
+	progress ProgressFunc
+}
+
+func newSession(write func([]byte) error, logger *slog.Logger) *session {
+	return &session{
+		write:   write,
+		logger:  logger,
+		pending: make(map[int64]*pendingCall),
+		done:    make(chan struct{}),
+	}
+}
+
+// dispatch handles one message from the server
+func (s *session) dispatch(data []byte) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		s.logger.Warn("invalid message from server", "error", err)
+		return
+	}
+
+	switch {
+	case msg.ID != nil:
+		s.mu.Lock()
+		call, ok := s.pending[*msg.ID]
+		delete(s.pending, *msg.ID)
+		s.mu.Unlock()
+		if ok {
+			call.response <- msg
+		}
+
+	case msg.Method == "notifications/progress":
+		token, current, total, text, ok := progressParams(msg.Params)
+		if !ok {
+			return
+		}
+		s.mu.Lock()
+		call, ok := s.pending[token]
+		s.mu.Unlock()
+		if ok && call.progress != nil {
+			call.progress(current, total, text)
+		}
+
+	case msg.Method != "":
+		s.mu.Lock()
+		onNotify := s.onNotify
+		s.mu.Unlock()
+		if onNotify != nil {
+			go onNotify(msg.Method, msg.Params)
+		}
+	}
+}
+
+// end fails pending calls once the connection is gone
+func (s *session) end(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	s.err = err
+	for id, call := range s.pending {
+		close(call.response)
+		delete(s.pending, id)
+	}
+	close(s.done)
+}
+
+// alive reports whether the connection is open
+func (s *session) alive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// send writes one message
+func (s *session) send(data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.write(data)
+}
+
+// call sends a request and waits for its response
+func (s *session) call(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error) {
+	call := &pendingCall{
+		response: make(chan message, 1),
+		progress: progressFrom(ctx),
+	}
+
+	s.mu.Lock()
+	if !s.alive() {
+		s.mu.Unlock()
+		return nil, mcperr.Upstream(ErrClosed, "connection closed")
+	}
+	s.nextID++
+	id := s.nextID
+	s.pending[id] = call
+	s.mu.Unlock()
+
+	data, err := encodeRequest(ctx, id, method, params)
+	if err != nil {
+		s.forget(id)
+		return nil, err
+	}
+	if err := s.send(data); err != nil {
+		s.forget(id)
+		return nil, mcperr.Upstream(err, "send %s", method)
+	}
+
+	select {
+	case <-ctx.Done():
+		s.forget(id)
+		return nil, ctx.Err()
+	case msg, ok := <-call.response:
+		if !ok {
+			return nil, mcperr.Upstream(ErrClosed, "connection closed during %s", method)
+		}
+		return msg.response()
+	}
+}
+
+// notify sends a notification
+func (s *session) notify(method string, params map[string]interface{}) error {
+	if !s.alive() {
+		return ErrClosed
+	}
+	data, err := encodeNotification(method, params)
+	if err != nil {
+		return err
+	}
+	return s.send(data)
+}
+
+// forget drops a pending call
+func (s *session) forget(id int64) {
+	s.mu.Lock()
+	delete(s.pending, id)
+	s.mu.Unlock()
+}
+
+// setNotificationHandler registers the handler of server notifications
+func (s *session) setNotificationHandler(fn NotificationHandler) {
+	s.mu.Lock()
+	s.onNotify = fn
+	s.mu.Unlock()
+}
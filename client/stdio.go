@@ -0,0 +1,155 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// closeTimeout is how long a server may take to exit after its stdin closes
+const closeTimeout = 5 * time.Second
+
+// StdioConfig describes an MCP server run as a child process
+type StdioConfig struct {
+	Command string
+	Args    []string
+
+	// Env is added to the server's environment
+	Env map[string]string
+
+	// Dir is the server's working directory (default: the current one)
+	Dir string
+}
+
+// StdioTransport speaks newline-delimited JSON-RPC to a child process
+type StdioTransport struct {
+	*session
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	exited chan struct{}
+}
+
+// NewStdioTransport starts the server process
+func NewStdioTransport(config StdioConfig, logger *slog.Logger) (*StdioTransport, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	cmd := exec.Command(config.Command, config.Args...)
+	cmd.Dir = config.Dir
+	cmd.Env = os.Environ()
+	for k, v := range config.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", config.Command, err)
+	}
+
+	logger = logger.With("command", config.Command, "pid", cmd.Process.Pid)
+	t := &StdioTransport{
+		cmd:    cmd,
+		stdin:  stdin,
+		exited: make(chan struct{}),
+	}
+	t.session = newSession(func(data []byte) error {
+		_, err := stdin.Write(append(data, '\n'))
+		return err
+	}, logger)
+
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		reader := bufio.NewReader(stdout)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 1 {
+				t.dispatch(line)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		defer readers.Done()
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.Debug("server stderr", "line", scanner.Text())
+		}
+	}()
+	go func() {
+		readers.Wait()
+		t.end(cmd.Wait())
+		close(t.exited)
+	}()
+
+	return t, nil
+}
+
+// Call implements Transport
+func (t *StdioTransport) Call(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error) {
+	return t.call(ctx, method, params)
+}
+
+// Notify implements Transport
+func (t *StdioTransport) Notify(ctx context.Context, method string, params map[string]interface{}) error {
+	return t.notify(method, params)
+}
+
+// OnNotification implements Transport
+func (t *StdioTransport) OnNotification(fn NotificationHandler) {
+	t.setNotificationHandler(fn)
+}
+
+// Alive implements Transport: whether the process is still running
+func (t *StdioTransport) Alive() bool {
+	return t.alive()
+}
+
+// Done is closed when the process exits
+func (t *StdioTransport) Done() <-chan struct{} {
+	return t.exited
+}
+
+// Close closes the server's stdin and waits for it to exit, killing it
+// after closeTimeout
+func (t *StdioTransport) Close() error {
+	t.stdin.Close()
+	select {
+	case <-t.exited:
+	case <-time.After(closeTimeout):
+		t.cmd.Process.Kill()
+		<-t.exited
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var exitErr *exec.ExitError
+	if errors.As(t.err, &exitErr) && !exitErr.Exited() {
+		return nil // killed
+	}
+	return t.err
+}
@@ -0,0 +1,197 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// ErrClosed is returned by calls on a closed transport or an exited
+// server process
+var ErrClosed = errors.New("mcp client closed")
+
+// ErrMethodNotFound matches servers' MethodNotFound errors (e.g.
+// resources/list on a tools-only server)
+var ErrMethodNotFound = errors.New("method not found")
+
+// Transport carries JSON-RPC messages to an MCP server
+type Transport interface {
+	// Call sends a request and returns its result
+	// Progress notifications of the request go to the ProgressFunc of ctx
+	// (see WithProgress).
+	Call(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error)
+
+	// Notify sends a notification
+	Notify(ctx context.Context, method string, params map[string]interface{}) error
+
+	// OnNotification registers fn for server-initiated notifications
+	// (progress excluded); register before the first call
+	OnNotification(fn NotificationHandler)
+
+	// Alive reports whether the server can be reached without reconnecting
+	Alive() bool
+
+	Close() error
+}
+
+// NotificationHandler handles a notification from a server
+type NotificationHandler func(method string, params map[string]interface{})
+
+// ============================================================
+// Progress
+// ============================================================
+
+// ProgressFunc receives a request's progress notifications
+type ProgressFunc func(current, total int64, message string)
+
+type progressKey struct{}
+
+// WithProgress asks for progress of the requests made with the returned
+// context; tools/call requests then carry a progress token
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progressFrom returns the ProgressFunc of ctx
+func progressFrom(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// ============================================================
+// Errors
+// ============================================================
+
+// Error is a JSON-RPC error returned by a server
+// It unwraps to backend.ErrInvalidArguments for invalid params, to
+// ErrMethodNotFound, or to the *mcperr.Error its data describes, so
+// errors.Is(err, mcperr.ErrNotFound) works on categorized errors.
+type Error struct {
+	Code    int
+	Message string
+	Data    interface{}
+
+	// detail is the message with the data's details
+	detail string
+	cause  error
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil && e.cause != ErrMethodNotFound {
+		return e.cause.Error()
+	}
+	return fmt.Sprintf("%s (%d)", e.detail, e.Code)
+}
+
+// Unwrap returns the error's category
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// newError converts a JSON-RPC error
+func newError(e *protocol.Error) *Error {
+	err := &Error{Code: e.Code, Message: e.Message, Data: e.Data}
+
+	msg := e.Message
+	data, _ := e.Data.(map[string]interface{})
+	if detail, ok := data["message"].(string); ok {
+		msg = detail
+	} else if detail, ok := e.Data.(string); ok {
+		msg = msg + ": " + detail
+	}
+
+	err.detail = msg
+
+	switch {
+	case e.Code == protocol.InvalidParams:
+		err.cause = fmt.Errorf("%w: %s", backend.ErrInvalidArguments, msg)
+	case e.Code == protocol.MethodNotFound:
+		err.cause = ErrMethodNotFound
+	default:
+		if code, ok := data["code"].(string); ok && code != "" {
+			categorized := mcperr.New(mcperr.Code(code), "%s", msg)
+			if retryAfter, ok := data["retry_after"].(float64); ok {
+				categorized.RetryAfter = time.Duration(retryAfter * float64(time.Second))
+			}
+			err.cause = categorized
+		}
+	}
+	return err
+}
+
+// ============================================================
+// Messages
+// ============================================================
+
+// message is any JSON-RPC message from a server: a response, or a
+// notification when ID is absent
+type message struct {
+	ID     *int64                 `json:"id"`
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+	Result json.RawMessage        `json:"result"`
+	Error  *protocol.Error        `json:"error"`
+}
+
+// response returns the result or error of a response
+func (m message) response() (json.RawMessage, error) {
+	if m.Error != nil {
+		return nil, newError(m.Error)
+	}
+	return m.Result, nil
+}
+
+// progressParams returns the progress token, progress, total and message
+// of a notifications/progress message
+func progressParams(params map[string]interface{}) (int64, int64, int64, string, bool) {
+	token, ok := params["progressToken"].(float64)
+	if !ok {
+		return 0, 0, 0, "", false
+	}
+	current, _ := params["progress"].(float64)
+	total, _ := params["total"].(float64)
+	msg, _ := params["message"].(string)
+	return int64(token), int64(current), int64(total), msg, true
+}
+
+// encodeRequest marshals a request, adding a progress token (the request
+// ID) to tools/call requests whose context wants progress
+func encodeRequest(ctx context.Context, id int64, method string, params map[string]interface{}) ([]byte, error) {
+	if method == "tools/call" && progressFrom(ctx) != nil {
+		with := make(map[string]interface{}, len(params)+1)
+		for k, v := range params {
+			with[k] = v
+		}
+		meta := map[string]interface{}{}
+		if existing, ok := params["_meta"].(map[string]interface{}); ok {
+			for k, v := range existing {
+				meta[k] = v
+			}
+		}
+		meta["progressToken"] = id
+		with["_meta"] = meta
+		params = with
+	}
+
+	return json.Marshal(protocol.Request{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  method,
+		Params:  params,
+	})
+}
+
+// encodeNotification marshals a notification
+func encodeNotification(method string, params map[string]interface{}) ([]byte, error) {
+	return json.Marshal(protocol.Notification{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	})
+}
@@ -0,0 +1,339 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is appended to the handshake key (RFC 6455 §1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxFrameSize bounds a single WebSocket message
+const maxFrameSize = maxResponseSize
+
+// WebSocket opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// WebSocketConfig describes an MCP server reached over WebSocket
+type WebSocketConfig struct {
+	// URL is the server's endpoint (ws:// or wss://)
+	URL string
+
+	// Headers are sent with the handshake (e.g. Authorization)
+	Headers map[string]string
+
+	// TLSConfig is used for wss:// URLs
+	TLSConfig *tls.Config
+}
+
+// WebSocketTransport speaks JSON-RPC in WebSocket text messages, one
+// message per frame sequence
+type WebSocketTransport struct {
+	*session
+
+	conn      net.Conn
+	closeOnce sync.Once
+}
+
+// NewWebSocketTransport connects to the server and completes the
+// WebSocket handshake
+func NewWebSocketTransport(ctx context.Context, config WebSocketConfig, logger *slog.Logger) (*WebSocketTransport, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket url: %w", err)
+	}
+	host := u.Host
+	switch u.Scheme {
+	case "ws":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	case "wss":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		}
+	default:
+		return nil, fmt.Errorf("invalid websocket url scheme %q", u.Scheme)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", host, err)
+	}
+	if u.Scheme == "wss" {
+		tlsConfig := config.TLSConfig.Clone()
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = u.Hostname()
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("tls handshake with %s: %w", host, err)
+		}
+		conn = tlsConn
+	}
+
+	reader, err := handshake(ctx, conn, u, config.Headers)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	t := &WebSocketTransport{conn: conn}
+	t.session = newSession(func(data []byte) error {
+		return writeFrame(conn, opText, data, true)
+	}, logger.With("url", config.URL))
+
+	go func() {
+		t.end(t.read(reader))
+	}()
+	return t, nil
+}
+
+// handshake performs the opening handshake, returning a reader of the
+// frames that follow
+func handshake(ctx context.Context, conn net.Conn, u *url.URL, headers map[string]string) (*bufio.Reader, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: u.Path, RawQuery: u.RawQuery},
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       u.Host,
+	}
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Protocol", "mcp")
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("websocket handshake: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, fmt.Errorf("websocket handshake: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, statusError(resp, u.String())
+	}
+	if !strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != AcceptKey(key) {
+		return nil, errors.New("websocket handshake: invalid upgrade response")
+	}
+	return reader, nil
+}
+
+// AcceptKey computes the Sec-WebSocket-Accept value for a handshake key,
+// for servers implementing the other side of the handshake
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// read dispatches messages until the connection closes
+func (t *WebSocketTransport) read(r io.Reader) error {
+	var msg []byte
+	for {
+		op, fin, payload, err := readFrame(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		switch op {
+		case opPing:
+			t.writeMu.Lock()
+			err := writeFrame(t.conn, opPong, payload, true)
+			t.writeMu.Unlock()
+			if err != nil {
+				return err
+			}
+		case opPong:
+		case opClose:
+			t.writeMu.Lock()
+			writeFrame(t.conn, opClose, payload, true)
+			t.writeMu.Unlock()
+			return nil
+		case opText, opBinary, opContinuation:
+			if len(msg)+len(payload) > maxFrameSize {
+				return errors.New("websocket message too large")
+			}
+			msg = append(msg, payload...)
+			if fin {
+				t.dispatch(msg)
+				msg = nil
+			}
+		}
+	}
+}
+
+// Call implements Transport
+func (t *WebSocketTransport) Call(ctx context.Context, method string, params map[string]interface{}) (json.RawMessage, error) {
+	return t.call(ctx, method, params)
+}
+
+// Notify implements Transport
+func (t *WebSocketTransport) Notify(ctx context.Context, method string, params map[string]interface{}) error {
+	return t.notify(method, params)
+}
+
+// OnNotification implements Transport
+func (t *WebSocketTransport) OnNotification(fn NotificationHandler) {
+	t.setNotificationHandler(fn)
+}
+
+// Alive implements Transport: whether the connection is open
+func (t *WebSocketTransport) Alive() bool {
+	return t.alive()
+}
+
+// Close sends a close frame and closes the connection
+func (t *WebSocketTransport) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		t.writeMu.Lock()
+		writeFrame(t.conn, opClose, []byte{0x03, 0xE8}, true) // 1000: normal closure
+		t.writeMu.Unlock()
+		err = t.conn.Close()
+		t.end(nil)
+	})
+	return err
+}
+
+// ============================================================
+// Frames
+// ============================================================
+
+// writeFrame writes a single-frame message; clients mask their frames
+func writeFrame(w io.Writer, op byte, payload []byte, mask bool) error {
+	header := make([]byte, 2, 14)
+	header[0] = 0x80 | op // FIN
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	if mask {
+		header[1] |= 0x80
+		key := make([]byte, 4)
+		rand.Read(key)
+		header = append(header, key...)
+		masked := make([]byte, len(payload))
+		for i, b := range payload {
+			masked[i] = b ^ key[i%4]
+		}
+		payload = masked
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame reads one frame, unmasking it if needed
+func readFrame(r io.Reader) (op byte, fin bool, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	fin = header[0]&0x80 != 0
+	op = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	n := uint64(header[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxFrameSize {
+		err = errors.New("websocket frame too large")
+		return
+	}
+
+	var key [4]byte
+	if masked {
+		if _, err = io.ReadFull(r, key[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= key[i%4]
+		}
+	}
+	return
+}
@@ -12,14 +12,19 @@ import (
 // ColoredHandler is a slog.Handler that outputs colored logs
 type ColoredHandler struct {
 	opts  *ColoredHandlerOptions
-	level slog.Level
+	level slog.Leveler
 	attrs []slog.Attr
 	group string
 }
 
 // ColoredHandlerOptions configures the ColoredHandler
 type ColoredHandlerOptions struct {
-	Level      slog.Level
+	Level slog.Level
+
+	// Leveler overrides Level, e.g. a *slog.LevelVar to change the
+	// level at runtime
+	Leveler slog.Leveler
+
 	AddSource  bool
 	TimeFormat string
 	Writer     io.Writer
@@ -42,15 +47,20 @@ func NewColoredHandler(w io.Writer, opts *ColoredHandlerOptions) *ColoredHandler
 		opts.TimeFormat = "15:04:05"
 	}
 
+	var level slog.Leveler = opts.Level
+	if opts.Leveler != nil {
+		level = opts.Leveler
+	}
+
 	return &ColoredHandler{
 		opts:  opts,
-		level: opts.Level,
+		level: level,
 	}
 }
 
 // Enabled implements slog.Handler
 func (h *ColoredHandler) Enabled(_ context.Context, level slog.Level) bool {
-	return level >= h.level
+	return level >= h.level.Level()
 }
 
 // Handle implements slog.Handler
@@ -164,6 +174,9 @@ func (h *ColoredHandler) formatAttr(a slog.Attr) string {
 	// Fix: Don't use a.Key as format string
 	key := Cyan("%s", a.Key)
 	value := h.formatValue(a.Value)
+	if a.Key == "status" && a.Value.Kind() == slog.KindInt64 {
+		value = colorizeStatus(a.Value.Int64())
+	}
 
 	if h.group != "" {
 		return fmt.Sprintf("%s.%s=%s", h.group, key, value)
@@ -171,6 +184,20 @@ func (h *ColoredHandler) formatAttr(a slog.Attr) string {
 	return fmt.Sprintf("%s=%s", key, value)
 }
 
+// colorizeStatus colors an HTTP status by class, as in access logs
+func colorizeStatus(status int64) string {
+	switch {
+	case status >= 500:
+		return Colorize(fmt.Sprint(status), ColorBrightRed, Bold)
+	case status >= 400:
+		return Colorize(fmt.Sprint(status), ColorBrightYellow, Bold)
+	case status >= 300:
+		return Cyan("%d", status)
+	default:
+		return BrightGreen("%d", status)
+	}
+}
+
 // formatValue formats a slog.Value with appropriate color
 func (h *ColoredHandler) formatValue(v slog.Value) string {
 	if h.opts.NoColor {
@@ -0,0 +1,218 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ============================================================
+// Backpressure
+// ============================================================
+
+// OverflowPolicy decides what an emit does when the event buffer is full,
+// because the client reads slower than the tool emits
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for room, up to Overflow.Timeout (default)
+	OverflowBlock OverflowPolicy = "block"
+
+	// OverflowDropOldest drops the oldest buffered event to make room
+	// It may be any event, the start event included.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+
+	// OverflowDropNewest drops the event being emitted
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+
+	// OverflowCoalesce keeps only the latest progress event while the
+	// buffer is full, sending it once there is room; other events block
+	// as with OverflowBlock
+	OverflowCoalesce OverflowPolicy = "coalesce"
+)
+
+// DefaultOverflowTimeout is used when Overflow.Timeout is zero
+const DefaultOverflowTimeout = 30 * time.Second
+
+// ErrEmitTimeout indicates an emit waited longer than Overflow.Timeout for
+// room in the event buffer; the event is dropped
+var ErrEmitTimeout = errors.New("timed out waiting for room in the event buffer")
+
+// Overflow configures the backpressure of a call's emits
+// Dropped events are counted in the end event (events_dropped); dropped
+// data events also leave gaps in the sequence numbers.
+type Overflow struct {
+	Policy OverflowPolicy
+
+	// Timeout bounds how long a blocked emit waits (0 = 30s, negative =
+	// until the call ends)
+	Timeout time.Duration
+}
+
+// Validate validates the overflow settings
+func (o Overflow) Validate() error {
+	switch o.Policy {
+	case "", OverflowBlock, OverflowDropOldest, OverflowDropNewest, OverflowCoalesce:
+		return nil
+	}
+	return fmt.Errorf("unknown overflow policy %q (want block, drop_oldest, drop_newest or coalesce)", o.Policy)
+}
+
+// merge returns o with the fields set in override replaced
+func (o Overflow) merge(override Overflow) Overflow {
+	if override.Policy != "" {
+		o.Policy = override.Policy
+	}
+	if override.Timeout != 0 {
+		o.Timeout = override.Timeout
+	}
+	return o
+}
+
+// timeout returns how long a blocked emit waits (0 = no limit)
+func (o Overflow) timeout() time.Duration {
+	switch {
+	case o.Timeout == 0:
+		return DefaultOverflowTimeout
+	case o.Timeout < 0:
+		return 0
+	}
+	return o.Timeout
+}
+
+type overflowKey struct{}
+
+// WithOverflow overrides ExecutorConfig.Overflow for the call made with
+// ctx; zero fields keep the executor's
+func WithOverflow(ctx context.Context, overflow Overflow) context.Context {
+	return context.WithValue(ctx, overflowKey{}, overflow)
+}
+
+// send sends an event under the emitter's overflow policy
+func (e *emitterImpl) send(event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event channel closed")
+		}
+	}()
+	if err := e.ctx.Err(); err != nil {
+		return err
+	}
+
+	switch e.overflow.Policy {
+	case OverflowDropNewest:
+		select {
+		case e.events <- event:
+		default:
+			e.dropped.Add(1)
+		}
+		return nil
+
+	case OverflowDropOldest:
+		for {
+			select {
+			case e.events <- event:
+				return nil
+			default:
+			}
+			select {
+			case <-e.events:
+				e.dropped.Add(1)
+			default:
+			}
+		}
+
+	case OverflowCoalesce:
+		if event.Type == EventProgress {
+			e.coalesce(event)
+			return nil
+		}
+		if err := e.flushProgress(); err != nil {
+			return err
+		}
+	}
+	return e.sendBlocking(event, e.overflow.timeout())
+}
+
+// sendBlocking sends an event, waiting up to timeout (0 = no limit) for
+// room in the buffer
+func (e *emitterImpl) sendBlocking(event Event, timeout time.Duration) error {
+	select {
+	case e.events <- event:
+		return nil
+	default:
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case e.events <- event:
+		return nil
+	case <-e.ctx.Done():
+		return e.ctx.Err()
+	case <-expired:
+		e.dropped.Add(1)
+		return ErrEmitTimeout
+	}
+}
+
+// coalesce sends a progress event if there is room, or keeps it in place
+// of the progress event still waiting
+func (e *emitterImpl) coalesce(event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.pending != nil {
+		select {
+		case e.events <- *e.pending:
+			e.pending = nil
+		default:
+			e.dropped.Add(1)
+			e.pending = &event
+			return
+		}
+	}
+	select {
+	case e.events <- event:
+	default:
+		e.pending = &event
+	}
+}
+
+// flushProgress sends the waiting progress event, if any, before the next
+// event so the stream keeps its order
+func (e *emitterImpl) flushProgress() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.pending == nil {
+		return nil
+	}
+	err := e.sendBlocking(*e.pending, e.overflow.timeout())
+	e.pending = nil
+	return err
+}
+
+// finish sends the waiting batch, and the waiting progress event if
+// there is room, counting it as dropped otherwise, and returns the number
+// of dropped events
+func (e *emitterImpl) finish() int64 {
+	e.flushBatch()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.pending != nil {
+		select {
+		case e.events <- *e.pending:
+		default:
+			e.dropped.Add(1)
+		}
+		e.pending = nil
+	}
+	return e.dropped.Load()
+}
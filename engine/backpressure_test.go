@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func fullEmitter(t *testing.T, policy OverflowPolicy, timeout time.Duration) (*emitterImpl, chan Event) {
+	t.Helper()
+	events := make(chan Event, 2)
+	e := newEmitter(context.Background(), events, Overflow{Policy: policy, Timeout: timeout}, Batching{})
+	e.EmitData(1)
+	e.EmitData(2)
+	return e, events
+}
+
+func drain(events chan Event) []Event {
+	var out []Event
+	for {
+		select {
+		case evt := <-events:
+			out = append(out, evt)
+		default:
+			return out
+		}
+	}
+}
+
+func TestEmitter_Overflow(t *testing.T) {
+	t.Run("block", func(t *testing.T) {
+		e, events := fullEmitter(t, OverflowBlock, 20*time.Millisecond)
+		if err := e.EmitData(3); !errors.Is(err, ErrEmitTimeout) {
+			t.Errorf("err = %v, want timeout", err)
+		}
+
+		go func() { <-events }()
+		if err := e.EmitData(4); err != nil {
+			t.Errorf("emit after room was made: %v", err)
+		}
+		if e.finish() != 1 {
+			t.Errorf("dropped = %d", e.dropped.Load())
+		}
+	})
+
+	t.Run("drop_newest", func(t *testing.T) {
+		e, events := fullEmitter(t, OverflowDropNewest, 0)
+		if err := e.EmitData(3); err != nil {
+			t.Fatal(err)
+		}
+		got := drain(events)
+		if len(got) != 2 || got[1].Data.(DataPayload).Chunk != 2 || e.finish() != 1 {
+			t.Errorf("events = %v, dropped %d", got, e.dropped.Load())
+		}
+	})
+
+	t.Run("drop_oldest", func(t *testing.T) {
+		e, events := fullEmitter(t, OverflowDropOldest, 0)
+		e.EmitData(3)
+		e.EmitData(4)
+		got := drain(events)
+		if len(got) != 2 || got[0].Data.(DataPayload).Chunk != 3 || got[1].Data.(DataPayload).Chunk != 4 || e.finish() != 2 {
+			t.Errorf("events = %v, dropped %d", got, e.dropped.Load())
+		}
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		e, events := fullEmitter(t, OverflowCoalesce, 20*time.Millisecond)
+		for i := int64(1); i <= 5; i++ {
+			if err := e.EmitProgress(i, 5, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		drain(events)
+
+		// The latest progress goes out before the next data event
+		e.EmitData(3)
+		got := drain(events)
+		if len(got) != 2 || got[0].Data.(ProgressPayload).Current != 5 || got[1].Type != EventData {
+			t.Errorf("events = %v", got)
+		}
+		if e.finish() != 4 {
+			t.Errorf("dropped = %d, want 4 superseded progress events", e.dropped.Load())
+		}
+	})
+}
+
+func TestExecutor_OverflowSummary(t *testing.T) {
+	config := DefaultExecutorConfig()
+	config.BufferSize = 4
+	executor := NewExecutor(config, nil)
+
+	ctx := WithOverflow(context.Background(), Overflow{Policy: OverflowDropNewest})
+	release := make(chan struct{})
+	events := executor.Execute(ctx, "chatty", "req-1", nil, func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+		for i := 0; i < 10; i++ {
+			emit.EmitData(i)
+		}
+		close(release)
+		return nil
+	})
+
+	<-release
+	var end EndPayload
+	for evt := range events {
+		if evt.Type == EventEnd {
+			end = evt.Data.(EndPayload)
+		}
+	}
+	// The start event and three data events fill the buffer; the end
+	// event waits for room
+	if end.EventsDropped != 7 || end.EventCount != 10 {
+		t.Errorf("end = %+v", end)
+	}
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================
+// Batching
+// ============================================================
+
+// DefaultFlushInterval is used when Batching.FlushInterval is zero
+const DefaultFlushInterval = 100 * time.Millisecond
+
+// Batching groups the data events of chatty tools (one event per match,
+// per row) into one event carrying an array of chunks
+// A batch is sent when it holds Size chunks, FlushInterval after its
+// first chunk, or before any other event, so the stream keeps its order.
+// Its DataPayload has the chunks as Chunk, the first chunk's sequence
+// number as Sequence, and their number as Count.
+type Batching struct {
+	// Size is the most chunks per batch (0 or 1 disables batching)
+	Size int
+
+	// FlushInterval bounds how long a chunk waits for its batch to fill
+	// (0 = 100ms)
+	FlushInterval time.Duration
+}
+
+// Validate validates the batching settings
+func (b Batching) Validate() error {
+	if b.Size < 0 || b.FlushInterval < 0 {
+		return fmt.Errorf("batch size and flush interval must not be negative")
+	}
+	return nil
+}
+
+// enabled reports whether data events are batched
+func (b Batching) enabled() bool {
+	return b.Size > 1
+}
+
+// merge returns b with the fields set in override replaced
+func (b Batching) merge(override Batching) Batching {
+	if override.Size != 0 {
+		b.Size = override.Size
+	}
+	if override.FlushInterval != 0 {
+		b.FlushInterval = override.FlushInterval
+	}
+	return b
+}
+
+type batchingKey struct{}
+
+// WithBatching overrides ExecutorConfig.Batching for the call made with
+// ctx; zero fields keep the executor's
+func WithBatching(ctx context.Context, batching Batching) context.Context {
+	return context.WithValue(ctx, batchingKey{}, batching)
+}
+
+// batch is the data chunks waiting to be sent together
+type batch struct {
+	chunks []interface{}
+	first  int64 // sequence number of the first chunk
+	timer  *time.Timer
+}
+
+// addToBatch queues a data chunk, sending the batch once it is full
+func (e *emitterImpl) addToBatch(chunk interface{}) error {
+	e.batchMu.Lock()
+	defer e.batchMu.Unlock()
+
+	sequence := atomic.AddInt64(&e.sequence, 1)
+	if len(e.batch.chunks) == 0 {
+		e.batch.first = sequence
+		interval := e.batching.FlushInterval
+		if interval <= 0 {
+			interval = DefaultFlushInterval
+		}
+		e.batch.timer = time.AfterFunc(interval, func() {
+			e.batchMu.Lock()
+			defer e.batchMu.Unlock()
+			// The batch may have been sent, and another started, meanwhile
+			if e.batch.first == sequence {
+				e.sendBatch()
+			}
+		})
+	}
+	e.batch.chunks = append(e.batch.chunks, chunk)
+	if len(e.batch.chunks) < e.batching.Size {
+		return nil
+	}
+	return e.sendBatch()
+}
+
+// flushBatch sends the waiting batch, if any, ahead of another event
+func (e *emitterImpl) flushBatch() error {
+	if !e.batching.enabled() {
+		return nil
+	}
+	e.batchMu.Lock()
+	defer e.batchMu.Unlock()
+	return e.sendBatch()
+}
+
+// sendBatch sends the waiting batch; callers hold batchMu
+func (e *emitterImpl) sendBatch() error {
+	if len(e.batch.chunks) == 0 {
+		return nil
+	}
+	e.batch.timer.Stop()
+	event := Event{
+		Type:      EventData,
+		Timestamp: time.Now(),
+		Data: DataPayload{
+			Chunk:    e.batch.chunks,
+			Sequence: e.batch.first,
+			Count:    len(e.batch.chunks),
+		},
+	}
+	e.batch = batch{}
+	return e.send(event)
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEmitter_Batching(t *testing.T) {
+	events := make(chan Event, 10)
+	e := newEmitter(context.Background(), events, Overflow{}, Batching{Size: 3, FlushInterval: time.Hour})
+
+	for i := 1; i <= 4; i++ {
+		e.EmitData(i)
+	}
+	// A full batch goes out at once; the fourth chunk waits
+	got := drain(events)
+	if len(got) != 1 {
+		t.Fatalf("events = %v", got)
+	}
+	if p := got[0].Data.(DataPayload); p.Count != 3 || p.Sequence != 1 || len(p.Chunk.([]interface{})) != 3 {
+		t.Errorf("batch = %+v", p)
+	}
+
+	// Other events flush the batch first, keeping the order
+	e.EmitProgress(1, 2, "")
+	got = drain(events)
+	if len(got) != 2 || got[0].Data.(DataPayload).Sequence != 4 || got[0].Data.(DataPayload).Count != 1 || got[1].Type != EventProgress {
+		t.Errorf("events = %v", got)
+	}
+
+	e.EmitData(5)
+	e.finish()
+	if got = drain(events); len(got) != 1 || got[0].Data.(DataPayload).Sequence != 5 {
+		t.Errorf("finish sent %v", got)
+	}
+}
+
+func TestEmitter_BatchFlushInterval(t *testing.T) {
+	events := make(chan Event, 10)
+	e := newEmitter(context.Background(), events, Overflow{}, Batching{Size: 100, FlushInterval: 10 * time.Millisecond})
+
+	e.EmitData("a")
+	e.EmitData("b")
+	select {
+	case evt := <-events:
+		if p := evt.Data.(DataPayload); p.Count != 2 {
+			t.Errorf("batch = %+v", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("batch not flushed")
+	}
+}
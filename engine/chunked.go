@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultChunkSize is the size of the pieces EmitChunked sends
+const DefaultChunkSize = 64 * 1024
+
+// ContentChunkPayload is one piece of a chunked result
+// Clients join the Data of chunks 0..Chunks-1 of the content_complete
+// event, in Index order, and decode the result as JSON.
+type ContentChunkPayload struct {
+	Index  int    `json:"index"`
+	Offset int64  `json:"offset"`
+	Data   string `json:"data"`
+}
+
+// ContentCompletePayload marks the end of a chunked result
+type ContentCompletePayload struct {
+	Chunks int   `json:"chunks"`
+	Size   int64 `json:"size"`
+
+	// SHA256 is the hex digest of the joined chunks, to check assembly
+	SHA256 string `json:"sha256"`
+}
+
+// NewContentChunkEvent creates a content chunk event
+func NewContentChunkEvent(index int, offset int64, data string) Event {
+	return Event{
+		Type:      EventContentChunk,
+		Timestamp: time.Now(),
+		Data:      ContentChunkPayload{Index: index, Offset: offset, Data: data},
+	}
+}
+
+// NewContentCompleteEvent creates the assembly marker of a chunked result
+func NewContentCompleteEvent(chunks int, size int64, sha256 string) Event {
+	return Event{
+		Type:      EventContentComplete,
+		Timestamp: time.Now(),
+		Data:      ContentCompletePayload{Chunks: chunks, Size: size, SHA256: sha256},
+	}
+}
+
+// EmitChunked sends a serialized result as content_chunk events of at
+// most chunkSize bytes (default DefaultChunkSize), cut at UTF-8 rune
+// boundaries, followed by a content_complete event
+// Unlike EmitData, it waits for room in the event buffer instead of
+// dropping events, since a missing chunk corrupts the whole result.
+func EmitChunked(emit Emitter, data []byte, chunkSize int) error {
+	e, ok := emit.(*emitterImpl)
+	if !ok {
+		return fmt.Errorf("emitter %T does not support chunked results", emit)
+	}
+	if e.closed.Load() {
+		return fmt.Errorf("emitter is closed")
+	}
+	if err := e.flushBatch(); err != nil {
+		return err
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	chunkSize = max(chunkSize, utf8.UTFMax)
+
+	chunks := 0
+	for offset := 0; offset < len(data); chunks++ {
+		end := min(offset+chunkSize, len(data))
+		for end < len(data) && end > offset+1 && !utf8.RuneStart(data[end]) {
+			end--
+		}
+		if err := e.sendEventBlocking(NewContentChunkEvent(chunks, int64(offset), string(data[offset:end]))); err != nil {
+			return err
+		}
+		atomic.AddInt64(&e.sequence, 1)
+		offset = end
+	}
+
+	sum := sha256.Sum256(data)
+	return e.sendEventBlocking(NewContentCompleteEvent(chunks, int64(len(data)), hex.EncodeToString(sum[:])))
+}
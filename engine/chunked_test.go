@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmitChunked(t *testing.T) {
+	// More chunks than the event buffer holds: none may be dropped
+	config := ExecutorConfig{BufferSize: 4, Timeout: 5 * time.Second, MaxConcurrent: 1}
+	executor := NewExecutor(config, nil)
+
+	data := []byte(`"` + strings.Repeat("héllo wörld ", 100) + `"`)
+	handler := func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+		return EmitChunked(emit, data, 64)
+	}
+
+	var joined strings.Builder
+	var chunks int
+	var complete *ContentCompletePayload
+	for evt := range executor.Execute(context.Background(), "big", "req-1", nil, handler) {
+		switch payload := evt.Data.(type) {
+		case ContentChunkPayload:
+			if payload.Index != chunks || payload.Offset != int64(joined.Len()) {
+				t.Fatalf("chunk %d at %d, want %d at %d", payload.Index, payload.Offset, chunks, joined.Len())
+			}
+			if len(payload.Data) > 64 || !strings.HasPrefix(string(data[payload.Offset:]), payload.Data) {
+				t.Fatalf("chunk %d = %q", payload.Index, payload.Data)
+			}
+			joined.WriteString(payload.Data)
+			chunks++
+			time.Sleep(time.Millisecond) // a slow client
+		case ContentCompletePayload:
+			complete = &payload
+		}
+	}
+
+	if joined.String() != string(data) {
+		t.Fatalf("joined chunks differ from the result")
+	}
+	sum := sha256.Sum256(data)
+	if complete == nil || complete.Chunks != chunks || complete.Size != int64(len(data)) || complete.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("content_complete = %+v, chunks %d", complete, chunks)
+	}
+}
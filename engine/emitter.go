@@ -3,7 +3,10 @@ package engine
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
+
+	"github.com/SaherElMasry/go-mcp-framework/resilience"
 )
 
 // Emitter is the interface provided to streaming tools for emitting events
@@ -21,16 +24,32 @@ type Emitter interface {
 // emitterImpl is the internal implementation of Emitter
 type emitterImpl struct {
 	ctx      context.Context
-	events   chan<- Event
+	events   chan Event
+	overflow Overflow
 	sequence int64
 	closed   atomic.Bool
+	dropped  atomic.Int64
+	result   atomic.Bool // whether EmitResult was called
+
+	// pending is the progress event held back by OverflowCoalesce
+	mu      sync.Mutex
+	pending *Event
+
+	batching Batching
+	batchMu  sync.Mutex
+	batch    batch
+
+	// logLevel is the least severe level of the logs sent
+	logLevel string
 }
 
 // newEmitter creates a new emitter instance
-func newEmitter(ctx context.Context, events chan<- Event) *emitterImpl {
+func newEmitter(ctx context.Context, events chan Event, overflow Overflow, batching Batching) *emitterImpl {
 	return &emitterImpl{
 		ctx:      ctx,
 		events:   events,
+		overflow: overflow,
+		batching: batching,
 		sequence: 0,
 	}
 }
@@ -41,8 +60,10 @@ func (e *emitterImpl) EmitData(data interface{}) error {
 		return fmt.Errorf("emitter is closed")
 	}
 
-	// Safely send event
-	return e.sendEventSafe(NewDataEvent(data, atomic.AddInt64(&e.sequence, 1)))
+	if e.batching.enabled() {
+		return e.addToBatch(data)
+	}
+	return e.send(NewDataEvent(data, atomic.AddInt64(&e.sequence, 1)))
 }
 
 // EmitProgress sends a progress event
@@ -51,8 +72,40 @@ func (e *emitterImpl) EmitProgress(current, total int64, message string) error {
 		return fmt.Errorf("emitter is closed")
 	}
 
-	// Safely send event
-	return e.sendEventSafe(NewProgressEvent(current, total, message))
+	if err := e.flushBatch(); err != nil {
+		return err
+	}
+	return e.send(NewProgressEvent(current, total, message))
+}
+
+// EmitResult sends the aggregate result of the call (e.g. total matches
+// and a summary) as a result event, after all data and progress events
+// It may be called once per call. Like the end event, it waits for room
+// in the event buffer instead of being dropped.
+func (e *emitterImpl) EmitResult(result interface{}) error {
+	if e.closed.Load() {
+		return fmt.Errorf("emitter is closed")
+	}
+	if !e.result.CompareAndSwap(false, true) {
+		return fmt.Errorf("result already emitted")
+	}
+
+	if err := e.flushBatch(); err != nil {
+		return err
+	}
+	if err := e.flushProgress(); err != nil {
+		return err
+	}
+	return e.sendEventBlocking(NewResultEvent(result))
+}
+
+// emitRetry sends a retry event for a retry of the resilience layer
+func (e *emitterImpl) emitRetry(r resilience.RetryEvent) {
+	if e.closed.Load() {
+		return
+	}
+	e.flushBatch()
+	e.send(NewRetryEvent(r.Attempt, r.MaxAttempts, r.Delay, r.Reason))
 }
 
 // Context returns the execution context
@@ -65,21 +118,14 @@ func (e *emitterImpl) close() {
 	e.closed.Store(true)
 }
 
-// sendEventSafe safely sends an event without panicking
-func (e *emitterImpl) sendEventSafe(event Event) error {
+// sendEventBlocking sends an event, waiting for room in the buffer until
+// the execution ends
+func (e *emitterImpl) sendEventBlocking(event Event) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			// Recovered from panic (channel closed)
+			err = fmt.Errorf("event channel closed")
 		}
 	}()
 
-	select {
-	case <-e.ctx.Done():
-		return e.ctx.Err()
-	case e.events <- event:
-		return nil
-	default:
-		// Channel full or closed
-		return fmt.Errorf("event channel unavailable")
-	}
+	return e.sendBlocking(event, 0)
 }
@@ -2,10 +2,17 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/resilience"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ExecutorState represents the execution state
@@ -19,12 +26,71 @@ const (
 	StateCanceled ExecutorState = "canceled"
 )
 
+// RejectPolicy decides what happens to a call when the work queue is full
+type RejectPolicy string
+
+const (
+	// RejectWhenFull fails the call immediately with ErrQueueFull (default)
+	RejectWhenFull RejectPolicy = "reject"
+
+	// BlockWhenFull waits for room in the queue, up to QueueTimeout
+	BlockWhenFull RejectPolicy = "block"
+)
+
+// DefaultQueueSize is the queue depth used when ExecutorConfig.QueueSize is zero
+const DefaultQueueSize = 64
+
+var (
+	// ErrQueueFull indicates the executor rejected a call because its queue was full
+	ErrQueueFull = errors.New("executor queue full")
+
+	// ErrQueueTimeout indicates a call waited longer than QueueTimeout for a worker
+	ErrQueueTimeout = errors.New("timed out waiting for an executor worker")
+
+	// ErrExecutorClosed indicates the executor was closed before the call ran
+	ErrExecutorClosed = errors.New("executor closed")
+)
+
 // ExecutorConfig configures the executor
 type ExecutorConfig struct {
 	BufferSize    int
 	Timeout       time.Duration
 	MaxEvents     int64
-	MaxConcurrent int // v2 feature: semaphore-based concurrency control
+	MaxConcurrent int // Number of workers
+
+	// QueueSize bounds the calls waiting for a worker
+	// Zero uses DefaultQueueSize; negative disables queueing.
+	QueueSize int
+
+	// QueueTimeout bounds how long a call may wait for a worker (0 = no limit)
+	QueueTimeout time.Duration
+
+	// RejectPolicy applies when the queue is full (default: RejectWhenFull)
+	RejectPolicy RejectPolicy
+
+	// ResumeBuffer keeps the last ResumeBuffer events of every stream so
+	// clients that lose their connection can resume it (see Resume); 0
+	// disables resuming. Calls then run ahead of slow clients instead of
+	// waiting for them, so the buffer must cover how far a client lags.
+	ResumeBuffer int
+
+	// ResumeWindow is how long a call keeps running without a client, and
+	// how long a finished stream stays resumable (default
+	// DefaultResumeWindow)
+	ResumeWindow time.Duration
+
+	// Overflow is what emits do when a call's event buffer is full
+	// (default: block for up to DefaultOverflowTimeout); WithOverflow
+	// overrides it per call
+	Overflow Overflow
+
+	// Batching groups data events into array events (default: off);
+	// WithBatching overrides it per call
+	Batching Batching
+
+	// LogLevel is the least severe level of the log events sent (default
+	// DefaultLogLevel); WithLogLevel overrides it per call
+	LogLevel string
 }
 
 // DefaultExecutorConfig returns default configuration
@@ -34,20 +100,122 @@ func DefaultExecutorConfig() ExecutorConfig {
 		Timeout:       5 * time.Minute,
 		MaxEvents:     10000,
 		MaxConcurrent: 16,
+		QueueSize:     DefaultQueueSize,
+		QueueTimeout:  30 * time.Second,
+		RejectPolicy:  RejectWhenFull,
+	}
+}
+
+// ExecutorStats is a snapshot of the executor's worker pool
+type ExecutorStats struct {
+	Workers       int `json:"workers"`
+	QueueCapacity int `json:"queue_capacity"`
+
+	// Active is the number of calls running on a worker
+	Active int64 `json:"active"`
+
+	// Queued is the number of accepted calls waiting for a worker
+	Queued int64 `json:"queued"`
+
+	Submitted int64 `json:"submitted"`
+	Completed int64 `json:"completed"`
+
+	// Rejected counts calls refused because the queue was full or the executor closed
+	Rejected int64 `json:"rejected"`
+
+	// Abandoned counts calls that timed out or were canceled while queued
+	Abandoned int64 `json:"abandoned"`
+
+	// TotalQueueWait and MaxQueueWait cover calls that reached a worker
+	TotalQueueWait time.Duration `json:"total_queue_wait"`
+	MaxQueueWait   time.Duration `json:"max_queue_wait"`
+}
+
+// AverageQueueWait returns the mean time calls waited for a worker
+func (s ExecutorStats) AverageQueueWait() time.Duration {
+	started := s.Completed + s.Active
+	if started == 0 {
+		return 0
 	}
+	return s.TotalQueueWait / time.Duration(started)
+}
+
+type timeoutKey struct{}
+
+// WithTimeout overrides ExecutorConfig.Timeout for the call made with ctx
+// Zero disables the deadline.
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
 }
 
 // StreamingToolHandler is the function signature for streaming tools
 type StreamingToolHandler func(ctx context.Context, args map[string]interface{}, emit Emitter) error
 
-// Executor manages streaming tool execution
+// Executor runs streaming tools on a fixed pool of workers
+//
+// Calls wait in a bounded queue for a free worker. When the queue is full
+// they are rejected (or wait, with BlockWhenFull); calls that wait longer
+// than QueueTimeout are abandoned. Both outcomes are reported to the caller
+// as a retryable error event.
 type Executor struct {
 	config    ExecutorConfig
 	logger    *slog.Logger
 	state     atomic.Value // ExecutorState
 	mu        sync.RWMutex
-	sem       chan struct{} // v2 semaphore for concurrency control
-	closeOnce sync.Once     // Ensure channels closed only once
+	queue     chan *job
+	done      chan struct{}
+	startOnce sync.Once
+	closeOnce sync.Once // Ensure channels closed only once
+
+	// Stats
+	active    atomic.Int64
+	queued    atomic.Int64
+	submitted atomic.Int64
+	completed atomic.Int64
+	rejected  atomic.Int64
+	abandoned atomic.Int64
+	totalWait atomic.Int64 // nanoseconds
+	maxWait   atomic.Int64 // nanoseconds
+
+	// recorder records the event streams of selected executions
+	recorder *Recorder
+
+	// streams are the resumable streams by owner and request ID
+	streamsMu sync.Mutex
+	streams   map[streamKey]*replayStream
+}
+
+// Job states
+const (
+	jobQueued int32 = iota
+	jobRunning
+	jobAbandoned
+)
+
+// job is a call waiting for, or running on, a worker
+type job struct {
+	ctx       context.Context
+	toolName  string
+	requestID string
+	args      map[string]interface{}
+	handler   StreamingToolHandler
+	events    chan Event
+	enqueued  time.Time
+	state     atomic.Int32
+
+	// stop disarms the queue timeout and cancellation watchers
+	stopMu sync.Mutex
+	stop   []func() bool
+}
+
+// disarm stops the job's watchers
+func (j *job) disarm() {
+	j.stopMu.Lock()
+	defer j.stopMu.Unlock()
+	for _, stop := range j.stop {
+		stop()
+	}
+	j.stop = nil
 }
 
 // NewExecutor creates a new executor
@@ -55,14 +223,24 @@ func NewExecutor(config ExecutorConfig, logger *slog.Logger) *Executor {
 	if logger == nil {
 		logger = slog.Default()
 	}
-
-	// Create semaphore for concurrency control
-	sem := make(chan struct{}, config.MaxConcurrent)
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = DefaultExecutorConfig().MaxConcurrent
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.QueueSize < 0 {
+		config.QueueSize = 0
+	}
+	if config.RejectPolicy == "" {
+		config.RejectPolicy = RejectWhenFull
+	}
 
 	e := &Executor{
 		config: config,
 		logger: logger,
-		sem:    sem,
+		queue:  make(chan *job, config.QueueSize),
+		done:   make(chan struct{}),
 	}
 
 	e.state.Store(StateInit)
@@ -70,7 +248,25 @@ func NewExecutor(config ExecutorConfig, logger *slog.Logger) *Executor {
 	return e
 }
 
-// Execute runs a streaming tool and returns an event channel
+// SetRecorder records the event streams of the executions r selects
+// Set it before the first Execute.
+func (e *Executor) SetRecorder(r *Recorder) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.recorder = r
+}
+
+// Recorder returns the executor's recorder, if any
+func (e *Executor) Recorder() *Recorder {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.recorder
+}
+
+// Execute queues a streaming tool call and returns its event channel
+// The channel is closed when the call finishes or is rejected. With
+// ResumeBuffer set, the call outlives ctx for ResumeWindow, and ctx's end
+// only closes the channel early.
 func (e *Executor) Execute(
 	ctx context.Context,
 	toolName string,
@@ -78,26 +274,306 @@ func (e *Executor) Execute(
 	args map[string]interface{},
 	handler StreamingToolHandler,
 ) <-chan Event {
-	// Create output channel
-	events := make(chan Event, e.config.BufferSize)
+	if e.Resumable() && requestID != "" {
+		return e.executeResumable(ctx, toolName, requestID, args, handler)
+	}
+	return e.executeRecorded(ctx, toolName, requestID, args, handler)
+}
+
+// executeRecorded queues a call, recording it if the recorder selects it
+func (e *Executor) executeRecorded(
+	ctx context.Context,
+	toolName string,
+	requestID string,
+	args map[string]interface{},
+	handler StreamingToolHandler,
+) <-chan Event {
+	events := e.execute(ctx, toolName, requestID, args, handler)
+	if r := e.Recorder(); r != nil && r.selects(ctx, toolName) {
+		return e.record(ctx, r, toolName, requestID, events)
+	}
+	return events
+}
 
-	// Run in goroutine
+// record forwards events, saving them once the stream ends
+// Events keep being recorded after ctx is done, when nobody reads them.
+func (e *Executor) record(ctx context.Context, r *Recorder, toolName, requestID string, events <-chan Event) <-chan Event {
+	out := make(chan Event, e.config.BufferSize)
 	go func() {
-		defer close(events) // Always close on exit
+		rec := &Recording{RequestID: requestID, ToolName: toolName, Started: time.Now()}
+		forward := true
+		for event := range events {
+			rec.Events = append(rec.Events, recordEvent(event))
+			if !forward {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				forward = false
+			}
+		}
+		// Save before closing, so the recording is available once the
+		// stream ends
+		if err := r.store.Save(rec); err != nil {
+			e.logger.Warn("failed to save event recording",
+				"tool", toolName,
+				"request_id", requestID,
+				"error", err)
+		}
+		close(out)
+	}()
+	return out
+}
+
+// execute queues a call
+func (e *Executor) execute(
+	ctx context.Context,
+	toolName string,
+	requestID string,
+	args map[string]interface{},
+	handler StreamingToolHandler,
+) <-chan Event {
+	// The backend's handler and log lines see the call's request ID
+	if _, ok := observability.RequestIDFromContext(ctx); !ok && requestID != "" {
+		ctx = observability.WithRequestID(ctx, requestID)
+	}
+	j := &job{
+		ctx:       ctx,
+		toolName:  toolName,
+		requestID: requestID,
+		args:      args,
+		handler:   handler,
+		events:    make(chan Event, e.config.BufferSize),
+		enqueued:  time.Now(),
+	}
+
+	e.submitted.Add(1)
+	e.startOnce.Do(e.startWorkers)
+
+	select {
+	case <-e.done:
+		e.reject(j, ErrExecutorClosed)
+		return j.events
+	default:
+	}
 
-		// Acquire semaphore (v2 concurrency control)
+	e.queued.Add(1)
+	observability.SetExecutorQueueLength(e.queued.Load())
+
+	select {
+	case e.queue <- j:
+		e.watch(j, e.config.QueueTimeout)
+		e.abandonIfClosed(j)
+		return j.events
+	default:
+	}
+
+	if e.config.RejectPolicy != BlockWhenFull {
+		e.queued.Add(-1)
+		observability.SetExecutorQueueLength(e.queued.Load())
+		e.reject(j, ErrQueueFull)
+		return j.events
+	}
+
+	go e.enqueueBlocking(j)
+	return j.events
+}
+
+// enqueueBlocking waits for room in the queue (BlockWhenFull)
+func (e *Executor) enqueueBlocking(j *job) {
+	var timeout <-chan time.Time
+	if e.config.QueueTimeout > 0 {
+		timer := time.NewTimer(e.config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case e.queue <- j:
+		remaining := time.Duration(0)
+		if e.config.QueueTimeout > 0 {
+			remaining = max(e.config.QueueTimeout-time.Since(j.enqueued), time.Nanosecond)
+		}
+		e.watch(j, remaining)
+		e.abandonIfClosed(j)
+	case <-timeout:
+		e.abandon(j, ErrQueueTimeout)
+	case <-j.ctx.Done():
+		e.abandon(j, j.ctx.Err())
+	case <-e.done:
+		e.abandon(j, ErrExecutorClosed)
+	}
+}
+
+// watch abandons a queued job when it times out or its context ends
+func (e *Executor) watch(j *job, timeout time.Duration) {
+	j.stopMu.Lock()
+	defer j.stopMu.Unlock()
+
+	if j.state.Load() != jobQueued {
+		return
+	}
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() { e.abandon(j, ErrQueueTimeout) })
+		j.stop = append(j.stop, timer.Stop)
+	}
+	j.stop = append(j.stop, context.AfterFunc(j.ctx, func() { e.abandon(j, j.ctx.Err()) }))
+}
+
+// abandonIfClosed fails a job queued after Close drained the queue
+func (e *Executor) abandonIfClosed(j *job) {
+	select {
+	case <-e.done:
+		e.abandon(j, ErrExecutorClosed)
+	default:
+	}
+}
+
+// abandon ends a job that never reached a worker
+func (e *Executor) abandon(j *job, err error) {
+	if !j.state.CompareAndSwap(jobQueued, jobAbandoned) {
+		return
+	}
+	j.disarm()
+
+	e.queued.Add(-1)
+	e.abandoned.Add(1)
+	observability.SetExecutorQueueLength(e.queued.Load())
+	observability.RecordExecutorRejection(abandonReason(err))
+	trace.SpanFromContext(j.ctx).AddEvent("executor.abandoned",
+		trace.WithAttributes(attribute.String("reason", abandonReason(err))))
+
+	e.logger.Warn("tool call abandoned while queued",
+		"tool", j.toolName,
+		"request_id", j.requestID,
+		"waited", time.Since(j.enqueued),
+		"error", err)
+
+	e.emitEventSafe(j.events, NewErrorEvent(err, "", err == ErrQueueTimeout))
+	close(j.events)
+}
+
+// reject refuses a job without queueing it
+func (e *Executor) reject(j *job, err error) {
+	e.rejected.Add(1)
+	observability.RecordExecutorRejection(abandonReason(err))
+	trace.SpanFromContext(j.ctx).AddEvent("executor.rejected",
+		trace.WithAttributes(attribute.String("reason", abandonReason(err))))
+
+	e.logger.Warn("tool call rejected",
+		"tool", j.toolName,
+		"request_id", j.requestID,
+		"queued", e.queued.Load(),
+		"error", err)
+
+	e.emitEventSafe(j.events, NewErrorEvent(err, "", err == ErrQueueFull))
+	close(j.events)
+}
+
+// abandonReason maps a queueing error to a metric label
+func abandonReason(err error) string {
+	switch err {
+	case ErrQueueFull:
+		return "queue_full"
+	case ErrQueueTimeout:
+		return "queue_timeout"
+	case ErrExecutorClosed:
+		return "closed"
+	default:
+		return "canceled"
+	}
+}
+
+// startWorkers launches the worker pool
+func (e *Executor) startWorkers() {
+	observability.SetExecutorWorkers(e.config.MaxConcurrent)
+	for i := 0; i < e.config.MaxConcurrent; i++ {
+		go e.worker()
+	}
+}
+
+// worker runs queued jobs until the executor is closed
+func (e *Executor) worker() {
+	for {
 		select {
-		case e.sem <- struct{}{}:
-			defer func() { <-e.sem }() // Release semaphore when done
-		case <-ctx.Done():
-			e.emitEventSafe(events, NewErrorEvent(ctx.Err(), "", false))
+		case <-e.done:
 			return
+		case j := <-e.queue:
+			e.runJob(j)
+		}
+	}
+}
+
+// runJob runs a job unless it was abandoned while queued
+func (e *Executor) runJob(j *job) {
+	if !j.state.CompareAndSwap(jobQueued, jobRunning) {
+		return
+	}
+	j.disarm()
+
+	wait := time.Since(j.enqueued)
+	e.queued.Add(-1)
+	e.active.Add(1)
+	e.totalWait.Add(int64(wait))
+	for {
+		cur := e.maxWait.Load()
+		if int64(wait) <= cur || e.maxWait.CompareAndSwap(cur, int64(wait)) {
+			break
 		}
+	}
+	observability.SetExecutorQueueLength(e.queued.Load())
+	observability.RecordExecutorQueueWait(wait)
+	observability.IncConcurrentExecutions()
+
+	ctx, span := observability.StartSpan(j.ctx, "executor.run", trace.WithAttributes(
+		attribute.String("mcp.tool.name", j.toolName),
+		attribute.String("mcp.request_id", j.requestID),
+		attribute.Int64("mcp.executor.queue_wait_ms", wait.Milliseconds()),
+	))
 
-		e.run(ctx, toolName, requestID, args, handler, events)
+	defer func() {
+		close(j.events)
+		e.active.Add(-1)
+		e.completed.Add(1)
+		observability.DecConcurrentExecutions()
 	}()
 
-	return events
+	err := e.run(ctx, j.toolName, j.requestID, j.args, j.handler, j.events)
+	observability.EndSpan(span, err)
+}
+
+// Stats returns a snapshot of the worker pool
+func (e *Executor) Stats() ExecutorStats {
+	return ExecutorStats{
+		Workers:        e.config.MaxConcurrent,
+		QueueCapacity:  e.config.QueueSize,
+		Active:         e.active.Load(),
+		Queued:         e.queued.Load(),
+		Submitted:      e.submitted.Load(),
+		Completed:      e.completed.Load(),
+		Rejected:       e.rejected.Load(),
+		Abandoned:      e.abandoned.Load(),
+		TotalQueueWait: time.Duration(e.totalWait.Load()),
+		MaxQueueWait:   time.Duration(e.maxWait.Load()),
+	}
+}
+
+// Close stops the workers and fails calls still waiting in the queue
+// Running calls are not interrupted; cancel their contexts to stop them.
+func (e *Executor) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.done)
+		for {
+			select {
+			case j := <-e.queue:
+				e.abandon(j, ErrExecutorClosed)
+			default:
+				return
+			}
+		}
+	})
+	return nil
 }
 
 // run executes the tool
@@ -107,38 +583,75 @@ func (e *Executor) run(
 	requestID string,
 	args map[string]interface{},
 	handler StreamingToolHandler,
-	events chan<- Event,
-) {
+	events chan Event,
+) error {
 	// Set state to running
 	e.state.Store(StateRunning)
 	startTime := time.Now()
 
 	// Create context with timeout
-	execCtx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	timeout := e.config.Timeout
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		timeout = d
+	}
+	var (
+		execCtx context.Context
+		cancel  context.CancelFunc
+	)
+	if timeout > 0 {
+		execCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		execCtx, cancel = context.WithCancel(ctx)
+	}
 	defer cancel()
 
 	// Emit start event
 	e.emitEventSafe(events, NewStartEvent(toolName, requestID, args))
 
-	// Create emitter
-	emitter := newEmitter(execCtx, events)
+	// Create emitter; retries made with resilience.Retry are reported as
+	// retry events so clients see why the stream stalls
+	var emitter *emitterImpl
+	execCtx = resilience.WithRetryObserver(execCtx, func(r resilience.RetryEvent) {
+		emitter.emitRetry(r)
+	})
+	overflow := e.config.Overflow
+	if o, ok := ctx.Value(overflowKey{}).(Overflow); ok {
+		overflow = overflow.merge(o)
+	}
+	batching := e.config.Batching
+	if b, ok := ctx.Value(batchingKey{}).(Batching); ok {
+		batching = batching.merge(b)
+	}
+	emitter = newEmitter(execCtx, events, overflow, batching)
+	emitter.logLevel = e.config.LogLevel
+	if level, ok := ctx.Value(logLevelKey{}).(string); ok && level != "" {
+		emitter.logLevel = level
+	}
 	defer emitter.close()
 
 	// Event counter
 	var eventCount int64
 
 	// Execute handler
-	err := handler(execCtx, args, emitter)
+	err := e.callHandler(execCtx, toolName, handler, args, emitter)
 
 	// Get event count
 	atomic.AddInt64(&eventCount, emitter.sequence)
+	dropped := emitter.finish()
 
 	duration := time.Since(startTime)
+	if dropped > 0 {
+		e.logger.Warn("events dropped on a full event buffer",
+			"tool", toolName,
+			"request_id", requestID,
+			"dropped", dropped,
+			"policy", overflow.Policy)
+	}
 
 	// Emit result
 	if err != nil {
 		e.state.Store(StateError)
-		e.emitEventSafe(events, NewErrorEvent(err, "", false))
+		e.emitFinal(ctx, events, NewErrorEvent(err, "", false), overflow.timeout())
 
 		e.logger.Error("tool execution failed",
 			"tool", toolName,
@@ -148,7 +661,11 @@ func (e *Executor) run(
 		)
 	} else {
 		e.state.Store(StateDone)
-		e.emitEventSafe(events, NewEndEvent(duration, eventCount, ""))
+		e.emitFinal(ctx, events, Event{
+			Type:      EventEnd,
+			Timestamp: time.Now(),
+			Data:      EndPayload{Duration: duration, EventCount: eventCount, EventsDropped: dropped},
+		}, overflow.timeout())
 
 		e.logger.Info("tool execution completed",
 			"tool", toolName,
@@ -157,6 +674,51 @@ func (e *Executor) run(
 			"events", eventCount,
 		)
 	}
+	return err
+}
+
+// callHandler runs a handler, turning a panic into an internal error so
+// the stream ends with an error event instead of taking the process down
+func (e *Executor) callHandler(ctx context.Context, toolName string, handler StreamingToolHandler, args map[string]interface{}, emit Emitter) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		recovered := mcperr.Recovered(r, "tool %s panicked", toolName)
+		err = recovered
+
+		observability.RecordToolPanic(toolName)
+		e.logger.ErrorContext(ctx, "tool handler panicked", "tool", toolName, "panic", r)
+		e.logger.DebugContext(ctx, "tool handler panic stack", "tool", toolName, "stack", string(recovered.Err.(*mcperr.PanicError).Stack))
+	}()
+	return handler(ctx, args, emit)
+}
+
+// emitFinal sends a call's end or error event, waiting up to timeout (0 =
+// no limit) for room while the caller is there: unlike other events it
+// is never dropped to keep up, as clients need it to know how the call
+// ended
+func (e *Executor) emitFinal(ctx context.Context, events chan<- Event, event Event, timeout time.Duration) {
+	select {
+	case events <- event:
+		return
+	default:
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case events <- event:
+	case <-ctx.Done():
+		e.logger.Debug("final event not sent: caller gone", "event_type", event.Type)
+	case <-expired:
+		e.logger.Debug("final event not sent: event channel full", "event_type", event.Type)
+	}
 }
 
 // emitEventSafe safely emits an event without panicking on closed channel
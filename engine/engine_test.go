@@ -5,6 +5,9 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/resilience"
 )
 
 func TestExecutor_Execute_Success(t *testing.T) {
@@ -79,6 +82,40 @@ func TestExecutor_Execute_Error(t *testing.T) {
 	}
 }
 
+func TestExecutor_RecoversPanics(t *testing.T) {
+	executor := NewExecutor(DefaultExecutorConfig(), nil)
+
+	handler := func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+		emit.EmitData("first")
+		var rows []string
+		_ = rows[3]
+		return nil
+	}
+
+	var last Event
+	for evt := range executor.Execute(context.Background(), "broken_tool", "req-789", nil, handler) {
+		last = evt
+	}
+
+	payload, ok := last.Data.(ErrorPayload)
+	if last.Type != EventError || !ok {
+		t.Fatalf("last event = %+v, want error", last)
+	}
+	var panicErr *mcperr.PanicError
+	if mcperr.CodeOf(payload.Error) != mcperr.CodeInternal || !errors.As(payload.Error, &panicErr) || len(panicErr.Stack) == 0 {
+		t.Errorf("error = %v", payload.Error)
+	}
+
+	// The worker survives
+	events := executor.Execute(context.Background(), "ok_tool", "req-790", nil,
+		func(ctx context.Context, args map[string]interface{}, emit Emitter) error { return nil })
+	for range events {
+	}
+	if executor.State() != StateDone {
+		t.Errorf("state after panic = %s", executor.State())
+	}
+}
+
 func TestExecutor_Concurrency(t *testing.T) {
 	config := ExecutorConfig{
 		BufferSize:    10,
@@ -125,3 +162,165 @@ func TestExecutor_Concurrency(t *testing.T) {
 		t.Logf("Duration: %v (expected ~200ms)", duration)
 	}
 }
+
+// lastError drains events and returns the final error payload, if any
+func lastError(events <-chan Event) *ErrorPayload {
+	var payload *ErrorPayload
+	for event := range events {
+		if event.Type == EventError {
+			p := event.Data.(ErrorPayload)
+			payload = &p
+		}
+	}
+	return payload
+}
+
+func TestExecutor_RejectsWhenQueueFull(t *testing.T) {
+	executor := NewExecutor(ExecutorConfig{
+		BufferSize:    10,
+		Timeout:       5 * time.Second,
+		MaxConcurrent: 1,
+		QueueSize:     1,
+	}, nil)
+	defer executor.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	handler := func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	running := executor.Execute(context.Background(), "slow", "1", nil, handler)
+	<-started
+	queued := executor.Execute(context.Background(), "slow", "2", nil, handler)
+	rejected := executor.Execute(context.Background(), "slow", "3", nil, handler)
+
+	payload := lastError(rejected)
+	if payload == nil || !errors.Is(payload.Error, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %+v", payload)
+	}
+	if !payload.Retryable {
+		t.Error("queue full rejection should be retryable")
+	}
+
+	stats := executor.Stats()
+	if stats.Active != 1 || stats.Queued != 1 || stats.Rejected != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	close(release)
+	if p := lastError(running); p != nil {
+		t.Errorf("running call failed: %v", p.Error)
+	}
+	if p := lastError(queued); p != nil {
+		t.Errorf("queued call failed: %v", p.Error)
+	}
+
+	stats = executor.Stats()
+	if stats.Completed != 2 || stats.Submitted != 3 || stats.Queued != 0 {
+		t.Errorf("unexpected stats after drain: %+v", stats)
+	}
+}
+
+func TestExecutor_QueueTimeout(t *testing.T) {
+	executor := NewExecutor(ExecutorConfig{
+		BufferSize:    10,
+		Timeout:       5 * time.Second,
+		MaxConcurrent: 1,
+		QueueSize:     1,
+		QueueTimeout:  20 * time.Millisecond,
+	}, nil)
+	defer executor.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{}, 1)
+	handler := func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	executor.Execute(context.Background(), "slow", "1", nil, handler)
+	<-started
+
+	payload := lastError(executor.Execute(context.Background(), "slow", "2", nil, handler))
+	if payload == nil || !errors.Is(payload.Error, ErrQueueTimeout) {
+		t.Fatalf("expected ErrQueueTimeout, got %+v", payload)
+	}
+	if stats := executor.Stats(); stats.Abandoned != 1 || stats.Queued != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestExecutor_BlockPolicyWaitsForRoom(t *testing.T) {
+	executor := NewExecutor(ExecutorConfig{
+		BufferSize:    10,
+		Timeout:       5 * time.Second,
+		MaxConcurrent: 1,
+		QueueSize:     -1,
+		QueueTimeout:  time.Second,
+		RejectPolicy:  BlockWhenFull,
+	}, nil)
+	defer executor.Close()
+
+	handler := func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}
+
+	first := executor.Execute(context.Background(), "tool", "1", nil, handler)
+	second := executor.Execute(context.Background(), "tool", "2", nil, handler)
+
+	if p := lastError(first); p != nil {
+		t.Errorf("first call failed: %v", p.Error)
+	}
+	if p := lastError(second); p != nil {
+		t.Errorf("second call failed: %v", p.Error)
+	}
+	if stats := executor.Stats(); stats.Completed != 2 || stats.Rejected != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestExecutor_Close(t *testing.T) {
+	executor := NewExecutor(DefaultExecutorConfig(), nil)
+	executor.Close()
+
+	payload := lastError(executor.Execute(context.Background(), "tool", "1", nil,
+		func(ctx context.Context, args map[string]interface{}, emit Emitter) error { return nil }))
+	if payload == nil || !errors.Is(payload.Error, ErrExecutorClosed) {
+		t.Fatalf("expected ErrExecutorClosed, got %+v", payload)
+	}
+}
+
+func TestExecutor_RetryEvents(t *testing.T) {
+	executor := NewExecutor(DefaultExecutorConfig(), nil)
+
+	handler := func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+		calls := 0
+		return resilience.Retry(ctx, resilience.RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond}, func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				return mcperr.Timeout("upstream timed out")
+			}
+			return emit.EmitData("ok")
+		})
+	}
+
+	var retries []RetryPayload
+	for evt := range executor.Execute(context.Background(), "fetch", "req-1", nil, handler) {
+		if evt.Type == EventRetry {
+			retries = append(retries, evt.Data.(RetryPayload))
+		}
+	}
+
+	if len(retries) != 1 {
+		t.Fatalf("retry events = %d, want 1", len(retries))
+	}
+	if r := retries[0]; r.Attempt != 2 || r.MaxAttempts != 3 || r.Reason != "timeout: upstream timed out" {
+		t.Errorf("retry event = %+v", r)
+	}
+}
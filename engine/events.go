@@ -20,6 +20,22 @@ const (
 
 	// EventError indicates an error occurred
 	EventError
+
+	// EventRetry indicates an upstream call failed and will be retried
+	EventRetry
+
+	// EventContentChunk carries a piece of a large serialized result
+	EventContentChunk
+
+	// EventContentComplete marks the end of a chunked result
+	EventContentComplete
+
+	// EventResult carries a streaming tool's aggregate result, sent once
+	// before the end event
+	EventResult
+
+	// EventLog carries a non-fatal warning or other log message of a tool
+	EventLog
 )
 
 // String returns the string representation of EventType
@@ -35,6 +51,16 @@ func (t EventType) String() string {
 		return "end"
 	case EventError:
 		return "error"
+	case EventRetry:
+		return "retry"
+	case EventContentChunk:
+		return "content_chunk"
+	case EventContentComplete:
+		return "content_complete"
+	case EventResult:
+		return "result"
+	case EventLog:
+		return "log"
 	default:
 		return "unknown"
 	}
@@ -59,6 +85,10 @@ type DataPayload struct {
 	Chunk    interface{} `json:"chunk"`
 	Sequence int64       `json:"sequence"`
 	Total    *int64      `json:"total,omitempty"`
+
+	// Count is the number of chunks of a batch (see Batching), whose
+	// Chunk is their array
+	Count int `json:"count,omitempty"`
 }
 
 // ProgressPayload contains progress event data
@@ -74,6 +104,14 @@ type EndPayload struct {
 	Duration   time.Duration `json:"duration_ms"`
 	EventCount int64         `json:"event_count,omitempty"`
 	Summary    string        `json:"summary,omitempty"`
+
+	// EventsDropped counts events the overflow policy dropped
+	EventsDropped int64 `json:"events_dropped,omitempty"`
+}
+
+// ResultPayload contains a streaming tool's aggregate result
+type ResultPayload struct {
+	Result interface{} `json:"result"`
 }
 
 // ErrorPayload contains error event data
@@ -83,6 +121,14 @@ type ErrorPayload struct {
 	Retryable bool   `json:"retryable"`
 }
 
+// RetryPayload contains retry event data
+type RetryPayload struct {
+	Attempt     int    `json:"attempt"`
+	MaxAttempts int    `json:"max_attempts"`
+	DelayMS     int64  `json:"delay_ms"`
+	Reason      string `json:"reason"`
+}
+
 // Event constructors
 
 // NewStartEvent creates a start event
@@ -142,6 +188,33 @@ func NewEndEvent(duration time.Duration, eventCount int64, summary string) Event
 	}
 }
 
+// NewResultEvent creates a result event
+func NewResultEvent(result interface{}) Event {
+	return Event{
+		Type:      EventResult,
+		Timestamp: time.Now(),
+		Data:      ResultPayload{Result: result},
+	}
+}
+
+// NewRetryEvent creates a retry event
+func NewRetryEvent(attempt, maxAttempts int, delay time.Duration, reason error) Event {
+	payload := RetryPayload{
+		Attempt:     attempt,
+		MaxAttempts: maxAttempts,
+		DelayMS:     delay.Milliseconds(),
+	}
+	if reason != nil {
+		payload.Reason = reason.Error()
+	}
+
+	return Event{
+		Type:      EventRetry,
+		Timestamp: time.Now(),
+		Data:      payload,
+	}
+}
+
 // NewErrorEvent creates an error event
 func NewErrorEvent(err error, message string, retryable bool) Event {
 	if message == "" && err != nil {
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// ============================================================
+// Fan-out
+// ============================================================
+
+// DefaultFanOutConcurrency is used when FanOut's concurrency is zero
+const DefaultFanOutConcurrency = 4
+
+// FanOutResult is the data event FanOut emits for each item, in the
+// order items finish; Index is the item's position in the input
+type FanOutResult struct {
+	Index int         `json:"index"`
+	Value interface{} `json:"value,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// ItemError is the error of one FanOut item
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+// Error implements error
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the item's error
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// FanOut runs fn for each item on up to concurrency workers (0 =
+// DefaultFanOutConcurrency), emitting a FanOutResult as each one finishes
+// and progress counting the finished items
+//
+// A failed item does not stop the others: its error is emitted with its
+// index, and FanOut returns the errors of all failed items joined, as
+// *ItemError in index order. An emit failing (e.g. the client went away)
+// or ctx ending stops handing out items and returns that error. Emits are
+// serialized, so emit need not be safe for concurrent use; panics of fn
+// fail its item with an internal error.
+//
+// Example:
+//
+//	return engine.FanOut(ctx, locations, 5, func(ctx context.Context, location string) (interface{}, error) {
+//	    return b.currentWeather(ctx, location)
+//	}, emit)
+func FanOut[T any](ctx context.Context, items []T, concurrency int, fn func(ctx context.Context, item T) (interface{}, error), emit Emitter) error {
+	if concurrency <= 0 {
+		concurrency = DefaultFanOutConcurrency
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		mu       sync.Mutex // serializes emits and guards the counters
+		done     int
+		failures []*ItemError
+	)
+	finished := func(index int, value interface{}, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+
+		result := FanOutResult{Index: index, Value: value}
+		if err != nil {
+			result.Error = err.Error()
+			failures = append(failures, &ItemError{Index: index, Err: err})
+		}
+		done++
+		if err := emit.EmitData(result); err != nil {
+			cancel(err)
+			return
+		}
+		message := fmt.Sprintf("%d/%d done", done, len(items))
+		if len(failures) > 0 {
+			message += fmt.Sprintf(", %d failed", len(failures))
+		}
+		if err := emit.EmitProgress(int64(done), int64(len(items)), message); err != nil {
+			cancel(err)
+		}
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				value, err := callItem(ctx, fn, items[i])
+				finished(i, value, err)
+			}
+		}()
+	}
+
+dispatch:
+	for i := range items {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Index < failures[j].Index })
+	errs := make([]error, len(failures))
+	for i, failure := range failures {
+		errs[i] = failure
+	}
+	return errors.Join(errs...)
+}
+
+// callItem runs fn for one item, turning a panic into an internal error
+func callItem[T any](ctx context.Context, fn func(ctx context.Context, item T) (interface{}, error), item T) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = mcperr.Recovered(r, "fan-out item panicked")
+		}
+	}()
+	return fn(ctx, item)
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+func TestFanOut(t *testing.T) {
+	events := make(chan Event, 100)
+	emit := newEmitter(context.Background(), events, Overflow{}, Batching{})
+
+	var running, peak atomic.Int32
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	err := FanOut(context.Background(), items, 3, func(ctx context.Context, n int) (interface{}, error) {
+		if r := running.Add(1); r > peak.Load() {
+			peak.Store(r)
+		}
+		defer running.Add(-1)
+		time.Sleep(10 * time.Millisecond)
+
+		switch n {
+		case 4:
+			return nil, errors.New("unreachable")
+		case 7:
+			panic("boom")
+		}
+		return n * 10, nil
+	}, emit)
+
+	if peak.Load() > 3 {
+		t.Errorf("%d items ran at once, want at most 3", peak.Load())
+	}
+
+	// Failed items are reported, in index order, without stopping the others
+	var first *ItemError
+	if !errors.As(err, &first) || first.Index != 4 {
+		t.Fatalf("error = %v", err)
+	}
+	if failed := err.(interface{ Unwrap() []error }).Unwrap(); len(failed) != 2 || mcperr.CodeOf(failed[1]) != mcperr.CodeInternal {
+		t.Errorf("failures = %v", failed)
+	}
+
+	seen := map[int]FanOutResult{}
+	var last ProgressPayload
+	for _, evt := range drain(events) {
+		switch p := evt.Data.(type) {
+		case DataPayload:
+			r := p.Chunk.(FanOutResult)
+			seen[r.Index] = r
+		case ProgressPayload:
+			last = p
+		}
+	}
+	if len(seen) != len(items) || seen[9].Value != 90 || seen[4].Error != "unreachable" {
+		t.Errorf("results = %v", seen)
+	}
+	if last.Current != 10 || last.Total != 10 || last.Message != "10/10 done, 2 failed" {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+func TestFanOut_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan Event, 100)
+	emit := newEmitter(ctx, events, Overflow{}, Batching{})
+
+	var calls atomic.Int32
+	err := FanOut(ctx, make([]int, 100), 2, func(ctx context.Context, n int) (interface{}, error) {
+		if calls.Add(1) == 3 {
+			cancel()
+		}
+		return n, nil
+	}, emit)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v", err)
+	}
+	if calls.Load() > 10 {
+		t.Errorf("%d items ran after cancellation", calls.Load())
+	}
+}
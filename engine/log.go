@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ============================================================
+// Log events
+// ============================================================
+
+// DefaultLogLevel is the least severe level of the log events sent when
+// none is configured
+const DefaultLogLevel = "info"
+
+// logLevels are the MCP logging levels (those of syslog), least severe
+// first
+var logLevels = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// ValidateLogLevel checks level is an MCP logging level
+func ValidateLogLevel(level string) error {
+	if !slices.Contains(logLevels, level) {
+		return fmt.Errorf("unknown log level %q (want debug, info, notice, warning, error, critical, alert or emergency)", level)
+	}
+	return nil
+}
+
+// LogLevelEnabled reports whether a log of level passes the least severe
+// level minLevel ("" = DefaultLogLevel)
+func LogLevelEnabled(level, minLevel string) bool {
+	if minLevel == "" {
+		minLevel = DefaultLogLevel
+	}
+	return slices.Index(logLevels, level) >= slices.Index(logLevels, minLevel)
+}
+
+type logLevelKey struct{}
+
+// WithLogLevel overrides ExecutorConfig.LogLevel for the call made with
+// ctx
+func WithLogLevel(ctx context.Context, level string) context.Context {
+	return context.WithValue(ctx, logLevelKey{}, level)
+}
+
+// LogPayload contains log event data
+type LogPayload struct {
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// NewLogEvent creates a log event
+func NewLogEvent(level, message string, fields map[string]interface{}) Event {
+	return Event{
+		Type:      EventLog,
+		Timestamp: time.Now(),
+		Data:      LogPayload{Level: level, Message: message, Fields: fields},
+	}
+}
+
+// EmitLog sends a log event, unless level is below the call's log level
+func (e *emitterImpl) EmitLog(level, message string, fields map[string]interface{}) error {
+	if e.closed.Load() {
+		return fmt.Errorf("emitter is closed")
+	}
+	if err := ValidateLogLevel(level); err != nil {
+		return err
+	}
+	if !LogLevelEnabled(level, e.logLevel) {
+		return nil
+	}
+
+	if err := e.flushBatch(); err != nil {
+		return err
+	}
+	return e.send(NewLogEvent(level, message, fields))
+}
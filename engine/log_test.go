@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEmitter_EmitLog(t *testing.T) {
+	events := make(chan Event, 10)
+	e := newEmitter(context.Background(), events, Overflow{}, Batching{})
+	e.logLevel = "warning"
+
+	e.EmitLog("info", "cache miss", nil)
+	if err := e.EmitLog("warning", "skipped unreadable file", map[string]interface{}{"path": "a.txt"}); err != nil {
+		t.Fatalf("EmitLog: %v", err)
+	}
+	if err := e.EmitLog("loud", "?", nil); err == nil {
+		t.Error("unknown level accepted")
+	}
+
+	// Logs below the call's level are dropped
+	got := drain(events)
+	if len(got) != 1 || got[0].Type != EventLog {
+		t.Fatalf("events = %v", got)
+	}
+	if p := got[0].Data.(LogPayload); p.Level != "warning" || p.Fields["path"] != "a.txt" {
+		t.Errorf("log = %+v", p)
+	}
+}
+
+func TestLogLevelEnabled(t *testing.T) {
+	if LogLevelEnabled("debug", "") || !LogLevelEnabled("info", "") {
+		t.Error("default level is not info")
+	}
+	if !LogLevelEnabled("emergency", "error") || LogLevelEnabled("notice", "warning") {
+		t.Error("levels out of order")
+	}
+}
@@ -0,0 +1,314 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================
+// Recordings
+// ============================================================
+
+// ErrRecordingNotFound is returned for unknown recordings
+var ErrRecordingNotFound = errors.New("recording not found")
+
+// RecordedEvent is an event as it was produced, its payload serialized
+type RecordedEvent struct {
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Event returns the recorded event; its Data is the serialized payload,
+// which SSE mapping renders unchanged
+func (r RecordedEvent) Event() Event {
+	return Event{
+		Type:      ParseEventType(r.Type),
+		Timestamp: r.Timestamp,
+		Data:      r.Data,
+	}
+}
+
+// Recording is the full event stream of one execution
+type Recording struct {
+	RequestID string          `json:"request_id"`
+	ToolName  string          `json:"tool_name"`
+	Started   time.Time       `json:"started"`
+	Events    []RecordedEvent `json:"events"`
+}
+
+// RecordingInfo summarizes a recording
+type RecordingInfo struct {
+	RequestID string    `json:"request_id"`
+	ToolName  string    `json:"tool_name"`
+	Started   time.Time `json:"started"`
+	Events    int       `json:"events"`
+}
+
+// Info summarizes the recording
+func (r *Recording) Info() RecordingInfo {
+	return RecordingInfo{
+		RequestID: r.RequestID,
+		ToolName:  r.ToolName,
+		Started:   r.Started,
+		Events:    len(r.Events),
+	}
+}
+
+// ParseEventType returns the event type named s (see EventType.String)
+func ParseEventType(s string) EventType {
+	for t := EventStart; t <= EventLog; t++ {
+		if t.String() == s {
+			return t
+		}
+	}
+	return EventType(-1)
+}
+
+// recordEvent serializes an event
+func recordEvent(event Event) RecordedEvent {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"error": "failed to serialize event data"})
+	}
+	return RecordedEvent{
+		Type:      event.Type.String(),
+		Timestamp: event.Timestamp,
+		Data:      data,
+	}
+}
+
+// ============================================================
+// Stores
+// ============================================================
+
+// RecordingStore persists recordings
+type RecordingStore interface {
+	Save(rec *Recording) error
+	Load(requestID string) (*Recording, error)
+
+	// List summarizes the stored recordings, newest first
+	List() ([]RecordingInfo, error)
+}
+
+// MemoryRecordingStore keeps the most recent recordings in a ring buffer
+type MemoryRecordingStore struct {
+	mu         sync.RWMutex
+	recordings []*Recording
+	next       int
+}
+
+// NewMemoryRecordingStore creates a store keeping up to capacity
+// recordings (default: 100)
+func NewMemoryRecordingStore(capacity int) *MemoryRecordingStore {
+	if capacity <= 0 {
+		capacity = 100
+	}
+	return &MemoryRecordingStore{recordings: make([]*Recording, capacity)}
+}
+
+// Save implements RecordingStore, evicting the oldest recording when full
+func (s *MemoryRecordingStore) Save(rec *Recording) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordings[s.next] = rec
+	s.next = (s.next + 1) % len(s.recordings)
+	return nil
+}
+
+// Load implements RecordingStore
+func (s *MemoryRecordingStore) Load(requestID string) (*Recording, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rec := range s.recordings {
+		if rec != nil && rec.RequestID == requestID {
+			return rec, nil
+		}
+	}
+	return nil, ErrRecordingNotFound
+}
+
+// List implements RecordingStore
+func (s *MemoryRecordingStore) List() ([]RecordingInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	infos := make([]RecordingInfo, 0, len(s.recordings))
+	for i := 1; i <= len(s.recordings); i++ {
+		rec := s.recordings[(s.next-i+len(s.recordings))%len(s.recordings)]
+		if rec != nil {
+			infos = append(infos, rec.Info())
+		}
+	}
+	return infos, nil
+}
+
+// DiskRecordingStore writes recordings as JSON files to a directory
+type DiskRecordingStore struct {
+	dir      string
+	maxFiles int
+	mu       sync.Mutex
+}
+
+// NewDiskRecordingStore creates a store in dir keeping up to maxFiles
+// recordings (0 = unlimited)
+func NewDiskRecordingStore(dir string, maxFiles int) (*DiskRecordingStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create recordings directory: %w", err)
+	}
+	return &DiskRecordingStore{dir: dir, maxFiles: maxFiles}, nil
+}
+
+// path returns the file of a recording
+func (s *DiskRecordingStore) path(requestID string) (string, error) {
+	if requestID == "" || strings.ContainsAny(requestID, `/\`) || strings.Contains(requestID, "..") {
+		return "", fmt.Errorf("invalid request ID %q", requestID)
+	}
+	return filepath.Join(s.dir, requestID+".json"), nil
+}
+
+// Save implements RecordingStore, pruning the oldest files beyond maxFiles
+func (s *DiskRecordingStore) Save(rec *Recording) error {
+	path, err := s.path(rec.RequestID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// prune removes the oldest recordings beyond maxFiles
+func (s *DiskRecordingStore) prune() error {
+	if s.maxFiles <= 0 {
+		return nil
+	}
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+	for i := s.maxFiles; i < len(files); i++ {
+		os.Remove(files[i].path)
+	}
+	return nil
+}
+
+type recordingFile struct {
+	path    string
+	modTime time.Time
+}
+
+// files returns the recording files, newest first
+func (s *DiskRecordingStore) files() ([]recordingFile, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []recordingFile
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, recordingFile{filepath.Join(s.dir, entry.Name()), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	return files, nil
+}
+
+// Load implements RecordingStore
+func (s *DiskRecordingStore) Load(requestID string) (*Recording, error) {
+	path, err := s.path(requestID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrRecordingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("invalid recording %s: %w", requestID, err)
+	}
+	return &rec, nil
+}
+
+// List implements RecordingStore
+func (s *DiskRecordingStore) List() ([]RecordingInfo, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]RecordingInfo, 0, len(files))
+	for _, f := range files {
+		rec, err := s.Load(strings.TrimSuffix(filepath.Base(f.path), ".json"))
+		if err != nil {
+			continue
+		}
+		infos = append(infos, rec.Info())
+	}
+	return infos, nil
+}
+
+// ============================================================
+// Recorder
+// ============================================================
+
+// Recorder selects executions whose event streams are recorded
+type Recorder struct {
+	store RecordingStore
+	tools map[string]bool
+}
+
+// NewRecorder records executions of tools into store; with no tools,
+// every execution is recorded
+func NewRecorder(store RecordingStore, tools ...string) *Recorder {
+	r := &Recorder{store: store, tools: make(map[string]bool, len(tools))}
+	for _, tool := range tools {
+		r.tools[tool] = true
+	}
+	return r
+}
+
+// Store returns the recorder's store
+func (r *Recorder) Store() RecordingStore {
+	return r.store
+}
+
+type recordKey struct{}
+
+// WithRecording marks a call's context for recording, even if its tool is
+// not selected
+func WithRecording(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recordKey{}, true)
+}
+
+// selects reports whether an execution is recorded
+func (r *Recorder) selects(ctx context.Context, toolName string) bool {
+	if forced, _ := ctx.Value(recordKey{}).(bool); forced {
+		return true
+	}
+	return len(r.tools) == 0 || r.tools[toolName]
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func runRecorded(t *testing.T, executor *Executor, ctx context.Context, tool, requestID string) {
+	t.Helper()
+	events := executor.Execute(ctx, tool, requestID, map[string]interface{}{"path": "/tmp"},
+		func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+			emit.EmitProgress(1, 2, "half")
+			emit.EmitData(map[string]string{"line": "hello"})
+			return nil
+		})
+	for range events {
+	}
+}
+
+func TestExecutor_Recording(t *testing.T) {
+	store := NewMemoryRecordingStore(2)
+	executor := NewExecutor(ExecutorConfig{BufferSize: 10, MaxConcurrent: 1}, nil)
+	executor.SetRecorder(NewRecorder(store, "tail"))
+
+	runRecorded(t, executor, context.Background(), "tail", "req-1")
+	runRecorded(t, executor, context.Background(), "other", "req-2")
+	runRecorded(t, executor, WithRecording(context.Background()), "other", "req-3")
+
+	rec, err := store.Load("req-1")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var types []string
+	for _, e := range rec.Events {
+		types = append(types, e.Type)
+	}
+	if got := len(types); got != 4 || types[0] != "start" || types[2] != "data" || types[3] != "end" {
+		t.Errorf("recorded events = %v", types)
+	}
+	if string(rec.Events[2].Data) != `{"chunk":{"line":"hello"},"sequence":1}` {
+		t.Errorf("data payload = %s", rec.Events[2].Data)
+	}
+
+	if _, err := store.Load("req-2"); !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("unselected tool was recorded: %v", err)
+	}
+	if _, err := store.Load("req-3"); err != nil {
+		t.Errorf("forced recording missing: %v", err)
+	}
+
+	// The ring buffer evicts the oldest recording
+	runRecorded(t, executor, context.Background(), "tail", "req-4")
+	infos, _ := store.List()
+	if len(infos) != 2 || infos[0].RequestID != "req-4" || infos[1].RequestID != "req-3" {
+		t.Errorf("recordings = %+v", infos)
+	}
+}
+
+func TestDiskRecordingStore(t *testing.T) {
+	store, err := NewDiskRecordingStore(t.TempDir(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, id := range []string{"req-a", "req-b", "req-c"} {
+		rec := &Recording{
+			RequestID: id,
+			ToolName:  "tail",
+			Started:   time.Now(),
+			Events:    []RecordedEvent{recordEvent(NewProgressEvent(int64(i), 3, id))},
+		}
+		if err := store.Save(rec); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond) // distinct modification times
+	}
+
+	infos, err := store.List()
+	if err != nil || len(infos) != 2 || infos[0].RequestID != "req-c" {
+		t.Fatalf("List = %+v, %v", infos, err)
+	}
+	if _, err := store.Load("req-a"); !errors.Is(err, ErrRecordingNotFound) {
+		t.Errorf("oldest recording was not pruned: %v", err)
+	}
+
+	rec, err := store.Load("req-c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := rec.Events[0].Event()
+	if event.Type != EventProgress {
+		t.Errorf("event type = %v", event.Type)
+	}
+	var payload ProgressPayload
+	if err := json.Unmarshal(event.Data.(json.RawMessage), &payload); err != nil || payload.Message != "req-c" {
+		t.Errorf("payload = %+v, %v", payload, err)
+	}
+
+	if _, err := store.Load("../escape"); err == nil {
+		t.Error("path traversal accepted")
+	}
+}
@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEmitter_EmitResult(t *testing.T) {
+	events := make(chan Event, 10)
+	e := newEmitter(context.Background(), events, Overflow{}, Batching{Size: 10, FlushInterval: time.Hour})
+
+	e.EmitData("a")
+	if err := e.EmitResult(map[string]int{"total": 1}); err != nil {
+		t.Fatalf("EmitResult: %v", err)
+	}
+	if err := e.EmitResult("again"); err == nil {
+		t.Error("second EmitResult succeeded")
+	}
+
+	// The waiting batch goes out ahead of the result
+	got := drain(events)
+	if len(got) != 2 || got[0].Type != EventData || got[1].Type != EventResult {
+		t.Fatalf("events = %v", got)
+	}
+	if p := got[1].Data.(ResultPayload); p.Result.(map[string]int)["total"] != 1 {
+		t.Errorf("result = %+v", p)
+	}
+	if ParseEventType("result") != EventResult {
+		t.Error("result event type not parsed")
+	}
+}
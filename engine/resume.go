@@ -0,0 +1,255 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ============================================================
+// Resumable streams
+// ============================================================
+
+// DefaultResumeWindow is used when ExecutorConfig.ResumeWindow is zero
+const DefaultResumeWindow = time.Minute
+
+var (
+	// ErrStreamNotFound indicates no resumable stream has the request ID,
+	// or it belongs to another owner
+	ErrStreamNotFound = errors.New("stream not found")
+
+	// ErrStreamGap indicates the events after the client's last one are
+	// no longer buffered
+	ErrStreamGap = errors.New("events to resume from are no longer buffered")
+)
+
+type ownerKey struct{}
+
+// WithOwner records who makes a call; only the same owner can resume its
+// stream
+func WithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// streamKey identifies a resumable stream
+// Request IDs come from clients, so they are scoped by owner: a caller
+// reusing another's request ID registers a stream of its own rather than
+// replacing theirs.
+type streamKey struct {
+	owner     string
+	requestID string
+}
+
+// ResumedStream is a stream picked up again by Resume
+type ResumedStream struct {
+	ToolName string
+
+	// Events are the stream's events from index From on, live until the
+	// call ends
+	From   int
+	Events <-chan Event
+}
+
+// Resumable reports whether the executor buffers streams for Resume
+func (e *Executor) Resumable() bool {
+	return e.config.ResumeBuffer > 0
+}
+
+// Resume returns the events of a stream after index after (its events
+// count from 0; -1 resumes from the first), for a client that lost its
+// connection
+// The call keeps running while its clients are away, for up to
+// ResumeWindow, and a finished stream stays resumable for as long.
+func (e *Executor) Resume(ctx context.Context, requestID, owner string, after int) (*ResumedStream, error) {
+	e.streamsMu.Lock()
+	s, ok := e.streams[streamKey{owner: owner, requestID: requestID}]
+	e.streamsMu.Unlock()
+	if !ok {
+		return nil, ErrStreamNotFound
+	}
+	events, err := s.subscribe(ctx, after+1, e.config.BufferSize, e.resumeWindow())
+	if err != nil {
+		return nil, err
+	}
+	return &ResumedStream{ToolName: s.toolName, From: after + 1, Events: events}, nil
+}
+
+// executeResumable runs a call detached from ctx, buffering its events
+// for Resume; ctx's end only unsubscribes the caller
+func (e *Executor) executeResumable(
+	ctx context.Context,
+	toolName string,
+	requestID string,
+	args map[string]interface{},
+	handler StreamingToolHandler,
+) <-chan Event {
+	owner, _ := ctx.Value(ownerKey{}).(string)
+	execCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	s := &replayStream{
+		requestID: requestID,
+		toolName:  toolName,
+		owner:     owner,
+		cancel:    cancel,
+		changed:   make(chan struct{}),
+	}
+
+	// A request ID reused by the same owner replaces its older stream
+	e.streamsMu.Lock()
+	if e.streams == nil {
+		e.streams = make(map[streamKey]*replayStream)
+	}
+	e.streams[s.key()] = s
+	e.streamsMu.Unlock()
+
+	go e.buffer(s, e.executeRecorded(execCtx, toolName, requestID, args, handler))
+
+	events, _ := s.subscribe(ctx, 0, e.config.BufferSize, e.resumeWindow())
+	return events
+}
+
+// buffer keeps a stream's events until ResumeWindow after it ends
+func (e *Executor) buffer(s *replayStream, events <-chan Event) {
+	for event := range events {
+		s.append(event, e.config.ResumeBuffer)
+	}
+	s.finish()
+
+	time.AfterFunc(e.resumeWindow(), func() {
+		e.streamsMu.Lock()
+		defer e.streamsMu.Unlock()
+		if e.streams[s.key()] == s {
+			delete(e.streams, s.key())
+		}
+	})
+}
+
+func (e *Executor) resumeWindow() time.Duration {
+	if e.config.ResumeWindow > 0 {
+		return e.config.ResumeWindow
+	}
+	return DefaultResumeWindow
+}
+
+// replayStream buffers the recent events of one call for its subscribers
+type replayStream struct {
+	requestID string
+	toolName  string
+	owner     string
+
+	// cancel stops the call
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	events []Event // events base, base+1, ...
+	base   int
+	done   bool
+
+	// changed is closed and replaced whenever events are added
+	changed chan struct{}
+
+	subscribers int
+	orphaned    *time.Timer
+}
+
+// key returns the stream's key in Executor.streams
+func (s *replayStream) key() streamKey {
+	return streamKey{owner: s.owner, requestID: s.requestID}
+}
+
+// append adds an event, dropping the oldest beyond limit
+func (s *replayStream) append(event Event, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	if len(s.events) > limit {
+		s.events = s.events[1:]
+		s.base++
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// finish marks the stream ended
+func (s *replayStream) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	if s.orphaned != nil {
+		s.orphaned.Stop()
+	}
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.cancel()
+}
+
+// subscribe streams the events from index from on until the stream ends
+// or ctx is done; when the last subscriber leaves a running stream, it
+// is cancelled unless someone resumes it within window
+func (s *replayStream) subscribe(ctx context.Context, from, bufferSize int, window time.Duration) (<-chan Event, error) {
+	s.mu.Lock()
+	if from < s.base || from > s.base+len(s.events) {
+		s.mu.Unlock()
+		return nil, ErrStreamGap
+	}
+	s.subscribers++
+	if s.orphaned != nil {
+		s.orphaned.Stop()
+		s.orphaned = nil
+	}
+	s.mu.Unlock()
+
+	out := make(chan Event, bufferSize)
+	go func() {
+		defer close(out)
+		defer s.unsubscribe(window)
+
+		next := from
+		for {
+			s.mu.Lock()
+			if next < s.base {
+				// This subscriber fell further behind than the buffer holds
+				s.mu.Unlock()
+				select {
+				case out <- NewErrorEvent(ErrStreamGap, "", false):
+				case <-ctx.Done():
+				}
+				return
+			}
+			pending := append([]Event(nil), s.events[next-s.base:]...)
+			done, changed := s.done, s.changed
+			s.mu.Unlock()
+
+			for _, event := range pending {
+				select {
+				case out <- event:
+					next++
+				case <-ctx.Done():
+					return
+				}
+			}
+			if len(pending) > 0 {
+				continue
+			}
+			if done {
+				return
+			}
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// unsubscribe removes a subscriber, arming the orphan timer after the last
+func (s *replayStream) unsubscribe(window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers--
+	if s.subscribers == 0 && !s.done {
+		s.orphaned = time.AfterFunc(window, s.cancel)
+	}
+}
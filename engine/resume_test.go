@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExecutor_Resume(t *testing.T) {
+	executor := NewExecutor(ExecutorConfig{BufferSize: 10, MaxConcurrent: 1, ResumeBuffer: 100, ResumeWindow: time.Second}, nil)
+	defer executor.Close()
+
+	step := make(chan struct{})
+	handler := func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+		for i := 1; i <= 4; i++ {
+			select {
+			case <-step:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			emit.EmitData(i)
+		}
+		return nil
+	}
+
+	// The first client reads the start event and one chunk, then leaves
+	ctx, disconnect := context.WithCancel(WithOwner(context.Background(), "alice"))
+	events := executor.Execute(ctx, "count", "req-1", nil, handler)
+	if evt := <-events; evt.Type != EventStart {
+		t.Fatalf("event 0 = %s", evt.Type)
+	}
+	step <- struct{}{}
+	if evt := <-events; evt.Data.(DataPayload).Chunk != 1 {
+		t.Fatalf("event 1 = %v", evt.Data)
+	}
+	disconnect()
+
+	// The call goes on without it
+	step <- struct{}{}
+	step <- struct{}{}
+
+	if _, err := executor.Resume(context.Background(), "req-1", "bob", 1); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("Resume by another owner: %v", err)
+	}
+	if _, err := executor.Resume(context.Background(), "req-2", "alice", 1); !errors.Is(err, ErrStreamNotFound) {
+		t.Errorf("Resume of an unknown stream: %v", err)
+	}
+
+	stream, err := executor.Resume(context.Background(), "req-1", "alice", 1)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if stream.ToolName != "count" || stream.From != 2 {
+		t.Errorf("stream = %+v", stream)
+	}
+	step <- struct{}{}
+
+	var chunks []interface{}
+	var last EventType
+	for evt := range stream.Events {
+		if evt.Type == EventData {
+			chunks = append(chunks, evt.Data.(DataPayload).Chunk)
+		}
+		last = evt.Type
+	}
+	if len(chunks) != 3 || chunks[0] != 2 || chunks[2] != 4 || last != EventEnd {
+		t.Errorf("resumed chunks = %v, last event %s", chunks, last)
+	}
+
+	// A finished stream can be replayed from its start
+	replay, err := executor.Resume(context.Background(), "req-1", "alice", -1)
+	if err != nil {
+		t.Fatalf("Resume finished stream: %v", err)
+	}
+	n := 0
+	for range replay.Events {
+		n++
+	}
+	if n != 6 {
+		t.Errorf("replayed %d events, want 6", n)
+	}
+}
+
+func TestExecutor_ResumeGap(t *testing.T) {
+	executor := NewExecutor(ExecutorConfig{BufferSize: 10, MaxConcurrent: 1, ResumeBuffer: 2}, nil)
+	defer executor.Close()
+
+	events := executor.Execute(context.Background(), "burst", "req-1", nil,
+		func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+			for i := 0; i < 5; i++ {
+				emit.EmitData(i)
+			}
+			return nil
+		})
+	for range events {
+	}
+
+	if _, err := executor.Resume(context.Background(), "req-1", "", 0); !errors.Is(err, ErrStreamGap) {
+		t.Errorf("Resume past the buffer: %v", err)
+	}
+	stream, err := executor.Resume(context.Background(), "req-1", "", 5)
+	if err != nil {
+		t.Fatalf("Resume within the buffer: %v", err)
+	}
+	if evt := <-stream.Events; evt.Type != EventEnd {
+		t.Errorf("event 6 = %s, want end", evt.Type)
+	}
+}
+
+func TestExecutor_ResumeWindow(t *testing.T) {
+	executor := NewExecutor(ExecutorConfig{BufferSize: 10, MaxConcurrent: 1, ResumeBuffer: 10, ResumeWindow: 50 * time.Millisecond}, nil)
+	defer executor.Close()
+
+	canceled := make(chan struct{})
+	ctx, disconnect := context.WithCancel(context.Background())
+	events := executor.Execute(ctx, "wait", "req-1", nil,
+		func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		})
+	<-events
+	disconnect()
+
+	// Nobody resumes, so the call is cancelled after the window
+	select {
+	case <-canceled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("abandoned call still running")
+	}
+}
+
+func TestExecutor_ResumeRequestIDOfAnotherOwner(t *testing.T) {
+	executor := NewExecutor(ExecutorConfig{BufferSize: 10, MaxConcurrent: 2, ResumeBuffer: 100, ResumeWindow: time.Second}, nil)
+	defer executor.Close()
+
+	handler := func(chunk string) StreamingToolHandler {
+		return func(ctx context.Context, args map[string]interface{}, emit Emitter) error {
+			return emit.EmitData(chunk)
+		}
+	}
+	drain := func(events <-chan Event) {
+		for range events {
+		}
+	}
+	drain(executor.Execute(WithOwner(context.Background(), "alice"), "echo", "req-1", nil, handler("alice's")))
+
+	// bob reusing alice's request ID must not replace her stream
+	drain(executor.Execute(WithOwner(context.Background(), "bob"), "echo", "req-1", nil, handler("bob's")))
+
+	for owner, want := range map[string]string{"alice": "alice's", "bob": "bob's"} {
+		stream, err := executor.Resume(context.Background(), "req-1", owner, -1)
+		if err != nil {
+			t.Fatalf("Resume by %s: %v", owner, err)
+		}
+		var got interface{}
+		for evt := range stream.Events {
+			if evt.Type == EventData {
+				got = evt.Data.(DataPayload).Chunk
+			}
+		}
+		if got != want {
+			t.Errorf("%s resumed %v, want %q", owner, got, want)
+		}
+	}
+}
@@ -44,6 +44,8 @@ func NewGrepBackend() *GrepBackend {
 
 func (gb *GrepBackend) registerTools() {
 	// Tool 1: Grep HTML
+	// This scans local files only; for pages on the web, with robots.txt,
+	// domain lists and size limits, use the webfetch backend.
 	htmlTool := backend.NewTool("grep_html").
 		Description("Search HTML file for patterns (e.g., href= to find URLs). Streams matches line-by-line.").
 		StringParam("file_path", "Path to HTML file (relative or absolute)", true).
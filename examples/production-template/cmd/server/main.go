@@ -0,0 +1,88 @@
+// Command server is the production template: one MCP server mounting a
+// read-only file tree, web retrieval and diagnostics, behind JWT and API
+// key authentication, with a shared Redis cache, tracing, rate limits and
+// the admin API
+//
+// Everything but secrets is in config/config.yaml; secrets come from the
+// environment (see README.md):
+//
+//	CONFIG_FILE          config file (default config/config.yaml)
+//	REDIS_URL            shared cache, e.g. redis://redis:6379/0 (default:
+//	                     the in-memory cache of the config)
+//	JWT_SECRET           HS256 secret of user tokens, or
+//	JWT_PUBLIC_KEY_FILE  PEM public key of RS256 user tokens
+//	JWT_ISSUER           required "iss" of user tokens
+//	JWT_AUDIENCE         required "aud" of user tokens
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/framework"
+
+	_ "github.com/SaherElMasry/go-mcp-framework/backends/webfetch"
+
+	_ "github.com/SaherElMasry/go-mcp-framework/examples/production-template/internal/diagnostic"
+	_ "github.com/SaherElMasry/go-mcp-framework/examples/production-template/internal/files"
+	"github.com/SaherElMasry/go-mcp-framework/examples/production-template/internal/jwtauth"
+	"github.com/SaherElMasry/go-mcp-framework/examples/production-template/internal/rediscache"
+)
+
+func main() {
+	// Distroless images have no curl; the binary probes itself
+	framework.HandleHealthcheckFlag()
+
+	configFile := os.Getenv("CONFIG_FILE")
+	if configFile == "" {
+		configFile = "config/config.yaml"
+	}
+	options := []framework.Option{framework.WithConfigFile(configFile)}
+
+	jwt, err := jwtAuthenticator()
+	if err != nil {
+		log.Fatalf("JWT auth: %v", err)
+	}
+	if jwt != nil {
+		options = append(options, framework.WithAuthenticator(jwt))
+	}
+
+	if url := os.Getenv("REDIS_URL"); url != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		store, err := rediscache.New(ctx, url, os.Getenv("REDIS_PREFIX"))
+		cancel()
+		if err != nil {
+			log.Fatalf("Redis cache: %v", err)
+		}
+		options = append(options, framework.WithCacheStore(store))
+	}
+
+	server := framework.NewServer(options...)
+	if err := server.Run(context.Background()); err != nil {
+		log.Fatalf("server: %v", err)
+	}
+}
+
+// jwtAuthenticator builds the user token authenticator from the
+// environment; nil when neither a secret nor a key is set
+func jwtAuthenticator() (*jwtauth.Authenticator, error) {
+	config := jwtauth.Config{
+		Issuer:   os.Getenv("JWT_ISSUER"),
+		Audience: os.Getenv("JWT_AUDIENCE"),
+		Secret:   []byte(os.Getenv("JWT_SECRET")),
+	}
+	if path := os.Getenv("JWT_PUBLIC_KEY_FILE"); path != "" {
+		key, err := jwtauth.LoadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		config.PublicKey = key
+	}
+	if len(config.Secret) == 0 && config.PublicKey == nil {
+		log.Print("JWT_SECRET and JWT_PUBLIC_KEY_FILE unset: only API keys are accepted")
+		return nil, nil
+	}
+	return jwtauth.New(config)
+}
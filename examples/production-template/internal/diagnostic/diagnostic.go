@@ -0,0 +1,146 @@
+// Package diagnostic answers questions about the server itself
+//
+// Tools:
+//
+//	ping         round trip check, echoing a message
+//	whoami       the principal the request authenticated as
+//	server_info  version, host and runtime figures of this replica
+//
+// They are cheap and touch nothing upstream, which makes them the tools
+// to point canaries and smoke tests at. Importing the package registers
+// the backend as "diagnostic".
+package diagnostic
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+)
+
+// Version is reported by server_info; set it at build time with
+// -ldflags "-X .../internal/diagnostic.Version=1.2.3"
+var Version = "dev"
+
+func init() {
+	backend.Register("diagnostic", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves the diagnostic tools
+type Backend struct {
+	*backend.BaseBackend
+
+	started time.Time
+}
+
+// New creates a diagnostic backend
+func New() *Backend {
+	b := &Backend{
+		BaseBackend: backend.NewBaseBackend("diagnostic"),
+		started:     time.Now(),
+	}
+	b.registerTools()
+	return b
+}
+
+// Initialize implements backend.ServerBackend; there is nothing to configure
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	return nil
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type pingArgs struct {
+	Message string `json:"message,omitempty" description:"Text to echo back"`
+}
+
+type pingResult struct {
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+}
+
+type noArgs struct{}
+
+type whoamiResult struct {
+	Authenticated bool     `json:"authenticated"`
+	ID            string   `json:"id,omitempty"`
+	Method        string   `json:"method,omitempty"`
+	Scopes        []string `json:"scopes,omitempty"`
+	Session       string   `json:"session,omitempty"`
+}
+
+type serverInfo struct {
+	Version    string `json:"version"`
+	Host       string `json:"host"`
+	GoVersion  string `json:"go_version"`
+	Uptime     string `json:"uptime"`
+	Goroutines int    `json:"goroutines"`
+	HeapBytes  uint64 `json:"heap_bytes"`
+}
+
+func (b *Backend) registerTools() {
+	backend.RegisterTypedTool(b, backend.NewTool("ping").
+		Description("Check the server answers; echoes message with the server's time.").
+		ParamsFromStruct(pingArgs{}).
+		NonCacheable().
+		Build(), b.ping)
+
+	backend.RegisterTypedTool(b, backend.NewTool("whoami").
+		Description("Show the identity, scopes and MCP session of the caller as the server sees them.").
+		ParamsFromStruct(noArgs{}).
+		NonCacheable().
+		Build(), b.whoami)
+
+	backend.RegisterTypedTool(b, backend.NewTool("server_info").
+		Description("Show the version, host, uptime and runtime figures of the replica answering.").
+		ParamsFromStruct(noArgs{}).
+		NonCacheable().
+		Build(), b.serverInfo)
+}
+
+// ping handles ping
+func (b *Backend) ping(ctx context.Context, in pingArgs) (pingResult, error) {
+	message := in.Message
+	if message == "" {
+		message = "pong"
+	}
+	return pingResult{Message: message, Time: time.Now().UTC()}, nil
+}
+
+// whoami handles whoami
+func (b *Backend) whoami(ctx context.Context, _ noArgs) (whoamiResult, error) {
+	var result whoamiResult
+	if p, ok := auth.PrincipalFromContext(ctx); ok {
+		result.Authenticated = true
+		result.ID = p.ID
+		result.Method = p.Method
+		result.Scopes = p.Scopes
+	}
+	if s, ok := session.FromContext(ctx); ok {
+		result.Session = s.ID()
+	}
+	return result, nil
+}
+
+// serverInfo handles server_info
+func (b *Backend) serverInfo(ctx context.Context, _ noArgs) (serverInfo, error) {
+	host, _ := os.Hostname()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return serverInfo{
+		Version:    Version,
+		Host:       host,
+		GoVersion:  runtime.Version(),
+		Uptime:     time.Since(b.started).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		HeapBytes:  mem.HeapAlloc,
+	}, nil
+}
@@ -0,0 +1,261 @@
+// Package files is a read-only view of a directory tree
+//
+// Tools:
+//
+//	read_file       a text file's content, up to max_file_size
+//	list_directory  the entries of a directory
+//	file_info       size, mode and modification time of a path
+//
+// Every path is resolved through a security.Manager in read-only mode, so
+// the tools cannot leave root, follow symlinks out of it, or touch the
+// denied paths.
+//
+// Importing the package registers the backend as "files":
+//
+//	# config.yaml
+//	backend:
+//	  mounts:
+//	    - type: files
+//	      prefix: fs
+//	      config:
+//	        root: /data
+//	        deny_paths: [".git", "*.key"]
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/security"
+)
+
+// DefaultMaxFileSize is read_file's limit when max_file_size is unset
+const DefaultMaxFileSize = 1 << 20
+
+func init() {
+	backend.Register("files", func() backend.ServerBackend {
+		return New()
+	})
+}
+
+// Backend serves the files under a root directory, read-only
+type Backend struct {
+	*backend.BaseBackend
+
+	security    *security.Manager
+	maxFileSize int64
+}
+
+// New creates a files backend; Initialize configures it
+func New() *Backend {
+	b := &Backend{
+		BaseBackend: backend.NewBaseBackend("files"),
+		maxFileSize: DefaultMaxFileSize,
+	}
+	b.registerTools()
+	return b
+}
+
+// Initialize applies the config
+//
+// Config entries:
+//
+//	root           directory served (required)
+//	max_file_size  bytes read_file returns at most (default 1MB)
+//	deny_paths     glob patterns that may not be read
+func (b *Backend) Initialize(ctx context.Context, config map[string]interface{}) error {
+	root, _ := config["root"].(string)
+	if root == "" {
+		return errors.New("files: root is required")
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return fmt.Errorf("files: root %q is not a directory", root)
+	}
+
+	switch v := config["max_file_size"].(type) {
+	case int:
+		b.maxFileSize = int64(v)
+	case float64:
+		b.maxFileSize = int64(v)
+	}
+	if b.maxFileSize <= 0 {
+		return errors.New("files: max_file_size must be positive")
+	}
+
+	var deny []string
+	if list, ok := config["deny_paths"].([]interface{}); ok {
+		for _, p := range list {
+			if s, ok := p.(string); ok {
+				deny = append(deny, s)
+			}
+		}
+	}
+
+	manager, err := security.NewManager(security.Config{
+		Root:        root,
+		ReadOnly:    true,
+		MaxFileSize: b.maxFileSize,
+		DenyPaths:   deny,
+	})
+	if err != nil {
+		return err
+	}
+	b.security = manager
+	return nil
+}
+
+// ============================================================
+// Tools
+// ============================================================
+
+type pathArgs struct {
+	Path string `json:"path" description:"Path relative to the served root"`
+}
+
+type readResult struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Size      int64  `json:"size"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// Entry describes a directory entry or a path
+type Entry struct {
+	Name     string    `json:"name"`
+	Dir      bool      `json:"dir,omitempty"`
+	Size     int64     `json:"size"`
+	Mode     string    `json:"mode"`
+	Modified time.Time `json:"modified"`
+}
+
+type listResult struct {
+	Path    string  `json:"path"`
+	Entries []Entry `json:"entries"`
+}
+
+func (b *Backend) registerTools() {
+	backend.RegisterTypedTool(b, backend.NewTool("read_file").
+		Description("Read a text file under the served root. Content beyond the size limit is cut off.").
+		ParamsFromStruct(pathArgs{}).
+		WithCache(true, time.Minute).
+		Build(), b.readFile)
+
+	backend.RegisterTypedTool(b, backend.NewTool("list_directory").
+		Description("List the files and directories in a directory under the served root (\".\" for the root).").
+		ParamsFromStruct(pathArgs{}).
+		WithCache(true, 30*time.Second).
+		Build(), b.listDirectory)
+
+	backend.RegisterTypedTool(b, backend.NewTool("file_info").
+		Description("Show the size, mode and modification time of a file or directory.").
+		ParamsFromStruct(pathArgs{}).
+		NonCacheable().
+		Build(), b.fileInfo)
+}
+
+// readFile handles read_file
+func (b *Backend) readFile(ctx context.Context, in pathArgs) (readResult, error) {
+	path, err := b.authorize(ctx, in.Path)
+	if err != nil {
+		return readResult{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return readResult{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return readResult{}, err
+	}
+	if info.IsDir() {
+		return readResult{}, backend.NewArgumentError("read_file", "path", "is a directory")
+	}
+
+	buf := make([]byte, min(info.Size(), b.maxFileSize))
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return readResult{}, err
+	}
+	content := buf[:n]
+	truncated := int64(n) < info.Size()
+	if truncated {
+		// The cut may split the last rune
+		for i := 1; i < utf8.UTFMax && !utf8.Valid(content); i++ {
+			content = content[:len(content)-1]
+		}
+	}
+	if !utf8.Valid(content) {
+		return readResult{}, backend.NewArgumentError("read_file", "path", "is not a text file")
+	}
+	return readResult{Path: in.Path, Content: string(content), Size: info.Size(), Truncated: truncated}, nil
+}
+
+// listDirectory handles list_directory
+func (b *Backend) listDirectory(ctx context.Context, in pathArgs) (listResult, error) {
+	path, err := b.authorize(ctx, in.Path)
+	if err != nil {
+		return listResult{}, err
+	}
+	if err := b.security.ValidateDirectorySize(path); err != nil {
+		return listResult{}, err
+	}
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return listResult{}, err
+	}
+
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, d := range dirEntries {
+		info, err := d.Info()
+		if err != nil {
+			continue // removed since ReadDir
+		}
+		entries = append(entries, entry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return listResult{Path: in.Path, Entries: entries}, nil
+}
+
+// fileInfo handles file_info
+func (b *Backend) fileInfo(ctx context.Context, in pathArgs) (Entry, error) {
+	path, err := b.authorize(ctx, in.Path)
+	if err != nil {
+		return Entry{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	return entry(info), nil
+}
+
+// authorize resolves a tool's path for reading
+func (b *Backend) authorize(ctx context.Context, path string) (string, error) {
+	if b.security == nil {
+		return "", backend.ErrNotInitialized
+	}
+	if path == "" {
+		path = "."
+	}
+	return b.security.Authorize(ctx, security.OpRead, path)
+}
+
+func entry(info fs.FileInfo) Entry {
+	return Entry{
+		Name:     info.Name(),
+		Dir:      info.IsDir(),
+		Size:     info.Size(),
+		Mode:     info.Mode().String(),
+		Modified: info.ModTime().UTC(),
+	}
+}
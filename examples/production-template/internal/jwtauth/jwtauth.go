@@ -0,0 +1,225 @@
+// Package jwtauth authenticates inbound requests carrying JWT bearer tokens
+//
+// Tokens are verified locally, without a call to the issuer: HS256 with a
+// shared secret or RS256 with the issuer's public key. The subject becomes
+// the principal's ID and the "scope" (space-separated) or "scp" (list)
+// claim its scopes, so tool scopes and the admin API scope apply to JWT
+// callers like to any other.
+//
+// Bearer tokens that are not JWTs are left to the next authenticator, so
+// static ops tokens keep working next to it.
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+)
+
+// DefaultLeeway is the clock skew allowed when checking exp and nbf
+const DefaultLeeway = 30 * time.Second
+
+// Config configures an Authenticator
+// Exactly one of Secret and PublicKey is set.
+type Config struct {
+	// Issuer, if set, must equal the token's "iss"
+	Issuer string
+
+	// Audience, if set, must be one of the token's "aud"
+	Audience string
+
+	// Secret verifies HS256 tokens
+	Secret []byte
+
+	// PublicKey verifies RS256 tokens
+	PublicKey *rsa.PublicKey
+
+	// Leeway is the clock skew allowed (default DefaultLeeway)
+	Leeway time.Duration
+}
+
+// Authenticator verifies JWT bearer tokens
+type Authenticator struct {
+	config Config
+	alg    string
+	now    func() time.Time
+}
+
+// New creates an Authenticator
+func New(config Config) (*Authenticator, error) {
+	a := &Authenticator{config: config, now: time.Now}
+	switch {
+	case len(config.Secret) > 0 && config.PublicKey != nil:
+		return nil, errors.New("jwtauth: set either a secret or a public key, not both")
+	case len(config.Secret) > 0:
+		if len(config.Secret) < 32 {
+			return nil, errors.New("jwtauth: HS256 secret must be at least 32 bytes")
+		}
+		a.alg = "HS256"
+	case config.PublicKey != nil:
+		a.alg = "RS256"
+	default:
+		return nil, errors.New("jwtauth: a secret or a public key is required")
+	}
+	if a.config.Leeway == 0 {
+		a.config.Leeway = DefaultLeeway
+	}
+	return a, nil
+}
+
+// LoadPublicKey reads an RSA public key from a PEM file ("PUBLIC KEY" or
+// "RSA PUBLIC KEY")
+func LoadPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("jwtauth: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("jwtauth: %s holds no PEM block", path)
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("jwtauth: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("jwtauth: %s is not an RSA public key", path)
+	}
+	return rsaKey, nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+}
+
+type claims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scp       []string        `json:"scp"`
+}
+
+// Authenticate implements auth.Authenticator
+func (a *Authenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	token, ok := auth.BearerToken(r)
+	if !ok {
+		return nil, auth.ErrNoCredentials
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, auth.ErrNoCredentials
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, auth.ErrNoCredentials
+	}
+	// The algorithm is fixed by the configuration, never by the token
+	if h.Alg != a.alg {
+		return nil, invalid("unexpected algorithm %q", h.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed signature")
+	}
+	if err := a.verify(parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var c claims
+	var raw map[string]interface{}
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, invalid("malformed claims")
+	}
+	decodeSegment(parts[1], &raw)
+
+	now := a.now()
+	switch {
+	case c.Subject == "":
+		return nil, invalid("no subject")
+	case c.ExpiresAt == nil:
+		return nil, invalid("no expiry")
+	case now.After(time.Unix(*c.ExpiresAt, 0).Add(a.config.Leeway)):
+		return nil, fmt.Errorf("%w: %w", auth.ErrInvalidCredentials, auth.ErrTokenExpired)
+	case c.NotBefore != nil && now.Add(a.config.Leeway).Before(time.Unix(*c.NotBefore, 0)):
+		return nil, invalid("not valid yet")
+	case a.config.Issuer != "" && c.Issuer != a.config.Issuer:
+		return nil, invalid("issuer %q not accepted", c.Issuer)
+	case a.config.Audience != "" && !hasAudience(c.Audience, a.config.Audience):
+		return nil, invalid("audience not accepted")
+	}
+
+	scopes := c.Scp
+	if c.Scope != "" {
+		scopes = strings.Fields(c.Scope)
+	}
+	return &auth.Principal{ID: c.Subject, Method: "jwt", Scopes: scopes, Claims: raw}, nil
+}
+
+// verify checks a token's signature over its signing input
+func (a *Authenticator) verify(input string, signature []byte) error {
+	switch a.alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, a.config.Secret)
+		mac.Write([]byte(input))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return invalid("bad signature")
+		}
+	case "RS256":
+		digest := sha256.Sum256([]byte(input))
+		if err := rsa.VerifyPKCS1v15(a.config.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return invalid("bad signature")
+		}
+	}
+	return nil
+}
+
+// hasAudience reports whether aud, a string or a list, includes want
+func hasAudience(aud json.RawMessage, want string) bool {
+	var one string
+	if json.Unmarshal(aud, &one) == nil {
+		return one == want
+	}
+	var many []string
+	if json.Unmarshal(aud, &many) == nil {
+		for _, a := range many {
+			if a == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// invalid returns an auth.ErrInvalidCredentials explaining why
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: jwt %s", auth.ErrInvalidCredentials, fmt.Sprintf(format, args...))
+}
@@ -4,23 +4,32 @@ package weather
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/SaherElMasry/go-mcp-framework/auth"
 	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
 )
 
+// bulkConcurrency is the most locations bulk_weather_check looks up at
+// once
+const bulkConcurrency = 5
+
 // WeatherBackend implements MCP server for weather data
 type WeatherBackend struct {
 	*backend.BaseBackend
 	apiKey  string
 	baseURL string
 	timeout time.Duration
+
+	// cacheMu guards cache, which bulk checks fill concurrently
+	cacheMu sync.Mutex
 	cache   map[string]*CachedWeather
 }
 
@@ -70,20 +79,14 @@ func (b *WeatherBackend) Initialize(ctx context.Context, config map[string]inter
 		b.apiKey = apiKey
 	}
 
-	// If not in config, try to get from auth provider (set by framework)
-	if b.apiKey == "" {
-		if provider := b.GetAuthProvider(); provider != nil {
-			// Auth provider was set by framework - extract the API key
-			if apiKeyProvider, ok := provider.(*auth.APIKeyProvider); ok {
-				// The APIKeyProvider doesn't expose the key directly for security,
-				// but we can validate it which ensures it's set
-				if err := apiKeyProvider.Validate(ctx); err == nil {
-					// Key is valid, but we need to get it somehow
-					// For now, we'll use a workaround - the framework should pass it in config
-					b.apiKey = "from-auth-provider"
-				}
-			}
+	// If not in config, the auth provider set by the framework supplies
+	// it on every request (see apiKeyFor), following key rotations
+	if b.apiKey == "" && b.GetAuthProvider() != nil {
+		if _, err := b.GetAuthSecret(ctx, ""); err != nil {
+			return fmt.Errorf("API key from auth provider: %w", err)
 		}
+	} else if b.apiKey == "" {
+		return fmt.Errorf("missing API key in configuration - set WEATHER_API_KEY environment variable")
 	}
 
 	// Parse other configuration
@@ -94,11 +97,6 @@ func (b *WeatherBackend) Initialize(ctx context.Context, config map[string]inter
 		b.timeout = timeout
 	}
 
-	// Final validation - we MUST have an API key at this point
-	if b.apiKey == "" {
-		return fmt.Errorf("missing API key in configuration - set WEATHER_API_KEY environment variable")
-	}
-
 	return nil
 }
 
@@ -166,15 +164,21 @@ func (b *WeatherBackend) handleGetCurrentWeather(ctx context.Context, args map[s
 
 	// Check cache first
 	cacheKey := fmt.Sprintf("current:%s", location)
-	if cached, exists := b.cache[cacheKey]; exists && time.Now().Before(cached.ExpiresAt) {
+	b.cacheMu.Lock()
+	cached, exists := b.cache[cacheKey]
+	b.cacheMu.Unlock()
+	if exists && time.Now().Before(cached.ExpiresAt) {
 		return cached.Data, nil
 	}
 
 	// Build API URL
-	apiURL := b.buildURL("/current.json", map[string]string{
+	apiURL, err := b.buildURL(ctx, "/current.json", map[string]string{
 		"q":   location,
 		"aqi": "no",
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Make API request
 	resp, err := b.makeRequest(ctx, apiURL)
@@ -220,10 +224,12 @@ func (b *WeatherBackend) handleGetCurrentWeather(ctx context.Context, args map[s
 	}
 
 	// Cache the output
+	b.cacheMu.Lock()
 	b.cache[cacheKey] = &CachedWeather{
 		Data:      finalOutput,
 		ExpiresAt: time.Now().Add(5 * time.Minute),
 	}
+	b.cacheMu.Unlock()
 
 	return finalOutput, nil
 }
@@ -236,11 +242,14 @@ func (b *WeatherBackend) handleGetForecast(ctx context.Context, args map[string]
 		days = int(d)
 	}
 
-	apiURL := b.buildURL("/forecast.json", map[string]string{
+	apiURL, err := b.buildURL(ctx, "/forecast.json", map[string]string{
 		"q":    location,
 		"days": fmt.Sprintf("%d", days),
 		"aqi":  "no",
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := b.makeRequest(ctx, apiURL)
 	if err != nil {
@@ -292,9 +301,12 @@ func (b *WeatherBackend) handleSearchLocations(
 
 	emit.EmitProgress(0, 100, "Starting location search...")
 
-	apiURL := b.buildURL("/search.json", map[string]string{
+	apiURL, err := b.buildURL(ctx, "/search.json", map[string]string{
 		"q": query,
 	})
+	if err != nil {
+		return err
+	}
 
 	emit.EmitProgress(30, 100, "Querying WeatherAPI...")
 
@@ -339,10 +351,13 @@ func (b *WeatherBackend) handleGetAstronomy(ctx context.Context, args map[string
 		date = d
 	}
 
-	apiURL := b.buildURL("/astronomy.json", map[string]string{
+	apiURL, err := b.buildURL(ctx, "/astronomy.json", map[string]string{
 		"q":  location,
 		"dt": date,
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := b.makeRequest(ctx, apiURL)
 	if err != nil {
@@ -380,51 +395,58 @@ func (b *WeatherBackend) handleBulkWeatherCheck(
 
 	emit.EmitProgress(0, int64(total), fmt.Sprintf("Starting bulk check for %d locations", total))
 
-	for i, location := range locations {
-		select {
-		case <-emit.Context().Done():
-			return ctx.Err()
-		default:
-		}
-
+	// Locations are looked up concurrently; each result carries its
+	// location's index, and failed lookups their error
+	err := engine.FanOut(ctx, locations, bulkConcurrency, func(ctx context.Context, location string) (interface{}, error) {
 		weather, err := b.getCurrentWeatherData(ctx, location)
-
-		result := map[string]interface{}{
-			"index":    i + 1,
-			"location": location,
-		}
-
 		if err != nil {
-			result["error"] = err.Error()
-			result["success"] = false
-		} else {
-			result["weather"] = weather
-			result["success"] = true
+			return nil, fmt.Errorf("%s: %w", location, err)
 		}
+		return map[string]interface{}{
+			"location": location,
+			"weather":  weather,
+		}, nil
+	}, emit)
 
-		emit.EmitData(result)
-		emit.EmitProgress(int64(i+1), int64(total),
-			fmt.Sprintf("Processed %d/%d locations", i+1, total))
-
-		time.Sleep(100 * time.Millisecond)
+	// Failed locations are reported in their results; only a stream that
+	// could not finish fails the call
+	var itemErr *engine.ItemError
+	if err != nil && !errors.As(err, &itemErr) {
+		return err
 	}
-
 	return nil
 }
 
+// apiKeyFor returns the configured API key, or the auth provider's
+func (b *WeatherBackend) apiKeyFor(ctx context.Context) (string, error) {
+	if b.apiKey != "" {
+		return b.apiKey, nil
+	}
+	secret, err := b.GetAuthSecret(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("API key from auth provider: %w", err)
+	}
+	return secret.Reveal(), nil
+}
+
 // buildURL builds API URL with authentication
-func (b *WeatherBackend) buildURL(endpoint string, params map[string]string) string {
+func (b *WeatherBackend) buildURL(ctx context.Context, endpoint string, params map[string]string) (string, error) {
+	apiKey, err := b.apiKeyFor(ctx)
+	if err != nil {
+		return "", err
+	}
+
 	u, _ := url.Parse(b.baseURL)
 	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(endpoint, "/")
 
 	q := u.Query()
-	q.Set("key", b.apiKey)
+	q.Set("key", apiKey)
 	for k, v := range params {
 		q.Set(k, v)
 	}
 	u.RawQuery = q.Encode()
 
-	return u.String()
+	return u.String(), nil
 }
 
 // makeRequest makes an HTTP request with timeout
@@ -483,7 +505,9 @@ func parseLocations(str string) []string {
 
 // Close cleans up resources
 func (b *WeatherBackend) Close() error {
+	b.cacheMu.Lock()
 	b.cache = make(map[string]*CachedWeather)
+	b.cacheMu.Unlock()
 
 	// Close auth provider using framework's method
 	if provider := b.GetAuthProvider(); provider != nil {
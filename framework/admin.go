@@ -0,0 +1,237 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+)
+
+// DefaultAdminScope is the scope principals need to call the admin API
+const DefaultAdminScope = "admin"
+
+// adminAuthTimeout bounds each auth provider's validation
+const adminAuthTimeout = 5 * time.Second
+
+// AdminConfig configures the admin API, served by the HTTP transport
+// under /admin/ to authenticated principals with the admin scope
+//
+//	GET    /admin/tools                tools with their streaming, cache and disabled flags
+//	POST   /admin/tools/{name}/disable hide a tool and refuse its calls
+//	POST   /admin/tools/{name}/enable  serve it again
+//	GET    /admin/cache                cache statistics
+//	DELETE /admin/cache                purge cached results
+//	GET    /admin/executor             streaming executor statistics
+//	GET    /admin/sessions             MCP sessions and streams in progress
+//	DELETE /admin/sessions/{id}        end an MCP session
+//	GET    /admin/auth                 auth providers and whether they validate
+//
+// Tools switched at runtime stay so until the next config reload, which
+// applies the file's tools.disabled again.
+type AdminConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Scope principals need (default DefaultAdminScope)
+	Scope string `yaml:"scope"`
+}
+
+// adminTool describes a tool in GET /admin/tools
+type adminTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Streaming   bool   `json:"streaming"`
+	Cacheable   bool   `json:"cacheable"`
+	CacheTTL    string `json:"cache_ttl,omitempty"`
+	Disabled    bool   `json:"disabled"`
+}
+
+// adminProvider describes an auth provider in GET /admin/auth
+type adminProvider struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// adminHandler returns the admin API
+func (s *Server) adminHandler(ht *httpTransport.HTTPTransport) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /admin/tools", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"tools": s.adminTools()})
+	})
+	mux.HandleFunc("POST /admin/tools/{name}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		name, action := r.PathValue("name"), r.PathValue("action")
+		if action != "enable" && action != "disable" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := s.setToolDisabled(name, action == "disable"); err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		s.logger.InfoContext(r.Context(), "tool switched by admin", "tool", name, "action", action)
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"tool": name, "disabled": action == "disable"})
+	})
+
+	mux.HandleFunc("GET /admin/cache", func(w http.ResponseWriter, r *http.Request) {
+		if s.cache == nil {
+			writeAdminJSON(w, http.StatusOK, map[string]interface{}{"enabled": false})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"enabled": true, "stats": s.cache.Stats()})
+	})
+	mux.HandleFunc("DELETE /admin/cache", func(w http.ResponseWriter, r *http.Request) {
+		if s.cache == nil {
+			writeAdminJSON(w, http.StatusOK, map[string]interface{}{"purged": 0})
+			return
+		}
+		purged := s.cache.Stats().Size
+		if err := s.cache.Clear(r.Context()); err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+		s.logger.InfoContext(r.Context(), "cache purged by admin", "entries", purged)
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"purged": purged})
+	})
+
+	mux.HandleFunc("GET /admin/executor", func(w http.ResponseWriter, r *http.Request) {
+		if s.executor == nil {
+			writeAdminJSON(w, http.StatusOK, map[string]interface{}{"enabled": false})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"enabled": true,
+			"state":   s.executor.State(),
+			"stats":   s.executor.Stats(),
+		})
+	})
+
+	mux.HandleFunc("GET /admin/sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions := []session.Info{}
+		if s.sessions != nil {
+			sessions = s.sessions.List()
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{
+			"sessions":             sessions,
+			"streams":              ht.Sessions(),
+			"notification_streams": s.broadcaster.Subscribers(),
+		})
+	})
+	mux.HandleFunc("DELETE /admin/sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if s.sessions == nil || !s.sessions.Terminate(id) {
+			writeAdminError(w, http.StatusNotFound, fmt.Errorf("session not found: %s", id))
+			return
+		}
+		s.logger.InfoContext(r.Context(), "session terminated by admin", "session", id)
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"terminated": id})
+	})
+
+	mux.HandleFunc("GET /admin/auth", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"providers": s.adminProviders(r.Context())})
+	})
+
+	return mux
+}
+
+// adminTools lists the enabled and disabled tools, sorted by name
+func (s *Server) adminTools() []adminTool {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	var tools []adminTool
+	for _, tool := range s.backend.ListTools() {
+		entry := adminTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Streaming:   tool.Streaming,
+			Cacheable:   tool.IsCacheable(),
+		}
+		if ttl := s.toolCacheTTL(tool); entry.Cacheable && ttl > 0 {
+			entry.CacheTTL = ttl.String()
+		}
+		tools = append(tools, entry)
+	}
+
+	for _, name := range s.config.Tools.Disabled {
+		tools = append(tools, adminTool{Name: name, Disabled: true})
+	}
+
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// toolCacheTTL returns the TTL of a tool's cached results, as the
+// protocol handler picks it
+func (s *Server) toolCacheTTL(tool backend.ToolDefinition) time.Duration {
+	if s.cache == nil || s.cacheConfig == nil {
+		return 0
+	}
+	if ttl, ok := s.cacheConfig.ToolTTL[tool.Name]; ok {
+		return ttl
+	}
+	return tool.GetCacheTTL(s.cacheConfig.GetTTLDuration())
+}
+
+// setToolDisabled disables or re-enables a tool, recording the change in
+// the running config's tools.disabled
+func (s *Server) setToolDisabled(name string, disabled bool) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	names := slices.DeleteFunc(slices.Clone(s.config.Tools.Disabled), func(n string) bool { return n == name })
+	if disabled {
+		if _, ok := s.backend.GetTool(name); !ok && len(names) == len(s.config.Tools.Disabled) {
+			return fmt.Errorf("tool not found: %s", name)
+		}
+		names = append(names, name)
+	} else if len(names) == len(s.config.Tools.Disabled) {
+		return fmt.Errorf("tool is not disabled: %s", name)
+	}
+
+	if err := s.applyDisabledTools(names); err != nil {
+		return err
+	}
+	s.config.Tools.Disabled = names
+	return nil
+}
+
+// adminProviders validates every auth provider
+func (s *Server) adminProviders(ctx context.Context) []adminProvider {
+	providers := []adminProvider{}
+	if s.authManager == nil {
+		return providers
+	}
+	for _, name := range s.authManager.List() {
+		entry := adminProvider{Name: name, Status: "ok"}
+		provider, err := s.authManager.Get(name)
+		if err == nil {
+			validateCtx, cancel := context.WithTimeout(ctx, adminAuthTimeout)
+			err = provider.Validate(validateCtx)
+			cancel()
+		}
+		if err != nil {
+			entry.Status, entry.Error = "failing", err.Error()
+		}
+		providers = append(providers, entry)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name < providers[j].Name })
+	return providers
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,130 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+func TestServer_AdminAPI(t *testing.T) {
+	b := backend.NewBaseBackend("test")
+	b.RegisterTool(backend.NewTool("echo").WithCache(true, 0).Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return args, nil
+	})
+	b.RegisterTool(backend.NewTool("ping").Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return "pong", nil
+	})
+
+	s := NewServer(
+		WithBackend(b),
+		WithTransport("http"),
+		WithObservability(false),
+		WithCache("short", 60),
+		WithInboundBearerToken("ops", "ops-token", "admin"),
+		WithInboundBearerToken("client", "client-token"),
+		WithAdminAPI(""),
+		WithSessions(0, 0),
+	)
+	if err := s.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	handler, err := s.HTTPHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(method, path, token string) (int, map[string]interface{}) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var body map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	if code, _ := call(http.MethodGet, "/admin/tools", ""); code != http.StatusUnauthorized {
+		t.Errorf("anonymous = %d, want 401", code)
+	}
+	if code, _ := call(http.MethodGet, "/admin/tools", "client-token"); code != http.StatusForbidden {
+		t.Errorf("without admin scope = %d, want 403", code)
+	}
+
+	listTools := func() []adminTool {
+		t.Helper()
+		code, body := call(http.MethodGet, "/admin/tools", "ops-token")
+		data, _ := json.Marshal(body["tools"])
+		var tools []adminTool
+		if err := json.Unmarshal(data, &tools); code != http.StatusOK || err != nil {
+			t.Fatalf("GET /admin/tools = %d %s", code, data)
+		}
+		return tools
+	}
+	want := []adminTool{{Name: "echo", Cacheable: true, CacheTTL: "1m0s"}, {Name: "ping"}}
+	if tools := listTools(); !reflect.DeepEqual(tools, want) {
+		t.Fatalf("tools = %+v, want %+v", tools, want)
+	}
+
+	if code, _ := call(http.MethodPost, "/admin/tools/ping/disable", "ops-token"); code != http.StatusOK {
+		t.Fatalf("disable = %d", code)
+	}
+	if _, ok := b.GetTool("ping"); ok {
+		t.Error("ping still served after disable")
+	}
+	if tools := listTools(); len(tools) != 2 || tools[1] != (adminTool{Name: "ping", Disabled: true}) {
+		t.Errorf("tools = %+v, want ping disabled", tools)
+	}
+	if code, _ := call(http.MethodPost, "/admin/tools/ping/enable", "ops-token"); code != http.StatusOK {
+		t.Fatalf("enable = %d", code)
+	}
+	if _, ok := b.GetTool("ping"); !ok {
+		t.Error("ping not served after enable")
+	}
+	if code, _ := call(http.MethodPost, "/admin/tools/missing/disable", "ops-token"); code != http.StatusBadRequest {
+		t.Errorf("disabling a missing tool = %d, want 400", code)
+	}
+
+	s.cache.Set(context.Background(), "k", json.RawMessage(`1`), 0)
+	if code, body := call(http.MethodDelete, "/admin/cache", "ops-token"); code != http.StatusOK || body["purged"] != float64(1) {
+		t.Errorf("DELETE /admin/cache = %d %v", code, body)
+	}
+	if _, body := call(http.MethodGet, "/admin/cache", "ops-token"); body["enabled"] != true {
+		t.Errorf("GET /admin/cache = %v", body)
+	}
+	if _, body := call(http.MethodGet, "/admin/executor", "ops-token"); body["enabled"] != true {
+		t.Errorf("GET /admin/executor = %v", body)
+	}
+	if code, body := call(http.MethodGet, "/admin/sessions", "ops-token"); code != http.StatusOK || body["notification_streams"] != float64(0) {
+		t.Errorf("GET /admin/sessions = %d %v", code, body)
+	}
+	opened, _ := s.sessions.Create("client")
+	if _, body := call(http.MethodGet, "/admin/sessions", "ops-token"); len(body["sessions"].([]interface{})) != 1 {
+		t.Errorf("GET /admin/sessions = %v, want the open session", body)
+	}
+	if code, _ := call(http.MethodDelete, "/admin/sessions/"+opened.ID(), "ops-token"); code != http.StatusOK {
+		t.Errorf("DELETE /admin/sessions/{id} = %d", code)
+	}
+	if code, _ := call(http.MethodDelete, "/admin/sessions/"+opened.ID(), "ops-token"); code != http.StatusNotFound {
+		t.Errorf("DELETE of an ended session = %d, want 404", code)
+	}
+	if code, _ := call(http.MethodGet, "/admin/auth", "ops-token"); code != http.StatusOK {
+		t.Errorf("GET /admin/auth = %d", code)
+	}
+}
+
+func TestConfig_AdminRequiresAuth(t *testing.T) {
+	config := DefaultConfig()
+	config.Backend.Type = "test"
+	config.Transport.HTTP.Admin.Enabled = true
+	if err := config.Validate(); err == nil {
+		t.Error("admin API without inbound auth accepted")
+	}
+}
@@ -0,0 +1,84 @@
+package framework
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// headerAuthenticator accepts requests carrying X-User
+type headerAuthenticator struct{}
+
+func (headerAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	user := r.Header.Get("X-User")
+	if user == "" {
+		return nil, auth.ErrNoCredentials
+	}
+	return &auth.Principal{ID: user, Method: "header"}, nil
+}
+
+func TestServer_WithAuthenticator(t *testing.T) {
+	s := NewServer(
+		WithBackend(backend.NewBaseBackend("test")),
+		WithTransport("http"),
+		WithObservability(false),
+		WithAuthenticator(headerAuthenticator{}),
+		WithInboundAPIKey("ci", "ci-key"),
+	)
+	if err := s.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+	handler, err := s.HTTPHandler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := call("X-User", "alice"); code != http.StatusOK {
+		t.Errorf("custom authenticator = %d, want 200", code)
+	}
+	if code := call("X-API-Key", "ci-key"); code != http.StatusOK {
+		t.Errorf("configured API key = %d, want 200", code)
+	}
+	if code := call("", ""); code != http.StatusUnauthorized {
+		t.Errorf("anonymous = %d, want 401", code)
+	}
+}
+
+func TestServer_TokenStoreFromConfig(t *testing.T) {
+	s := NewServer()
+	s.config.Auth.TokenStore = auth.TokenStoreConfig{Type: auth.TokenStoreMemory}
+
+	store, err := s.newTokenStore()
+	if err != nil {
+		t.Fatalf("newTokenStore failed: %v", err)
+	}
+	if _, ok := store.(*auth.MemoryTokenStore); !ok {
+		t.Errorf("store is %T, want *auth.MemoryTokenStore", store)
+	}
+
+	// OAuth providers share one store
+	if again, _ := s.newTokenStore(); again != store {
+		t.Error("newTokenStore created a second store")
+	}
+
+	custom := auth.NewMemoryTokenStore()
+	s = NewServer(WithTokenStore(custom))
+	if store, _ := s.newTokenStore(); store != custom {
+		t.Error("WithTokenStore ignored")
+	}
+}
@@ -1,9 +1,11 @@
 package framework_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/SaherElMasry/go-mcp-framework/backend"
 	"github.com/SaherElMasry/go-mcp-framework/cache"
 	"github.com/SaherElMasry/go-mcp-framework/framework"
 )
@@ -102,3 +104,24 @@ func TestServer_NoCacheByDefault(t *testing.T) {
 		t.Error("cache config should be nil by default")
 	}
 }
+
+// Test: Server with a cache store of its own
+func TestServer_WithCacheStore(t *testing.T) {
+	store := cache.NewMemoryCache(10, time.Minute)
+	server := framework.NewServer(
+		framework.WithBackend(backend.NewBaseBackend("test")),
+		framework.WithTransport("stdio"),
+		framework.WithObservability(false),
+		framework.WithCacheStore(store),
+	)
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	if server.GetCache() != store {
+		t.Error("server should cache in the store")
+	}
+	if cacheConfig := server.GetCacheConfig(); cacheConfig == nil || !cacheConfig.Enabled {
+		t.Errorf("cache config = %+v, want enabled defaults", cacheConfig)
+	}
+}
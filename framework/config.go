@@ -1,10 +1,23 @@
 package framework
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 
+	"github.com/SaherElMasry/go-mcp-framework/audit"
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/canary"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/paths"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+	stdioTransport "github.com/SaherElMasry/go-mcp-framework/transport/stdio"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,34 +28,160 @@ type Config struct {
 	Observability ObservabilityConfig `yaml:"observability"`
 	Logging       LoggingConfig       `yaml:"logging"`
 	Streaming     StreamingConfig     `yaml:"streaming"` // NEW
+	RateLimit     ratelimit.Config    `yaml:"rate_limit"`
+	Provenance    ProvenanceConfig    `yaml:"provenance"`
+
+	// Cache configures response caching; options (WithCache, ...) take
+	// precedence
+	Cache *cache.Config `yaml:"cache"`
+
+	// Tools enables and disables tools without a rebuild
+	Tools ToolsConfig `yaml:"tools"`
+
+	// Results bounds the size of tools/call results and says what to do
+	// with larger ones: fail, truncate or spill to a resource
+	Results protocol.ResultLimits `yaml:"results"`
+
+	// ListPageSize returns tools/list and resources/list in pages of this
+	// many items, for servers with many of them (0 = whole lists)
+	ListPageSize int `yaml:"list_page_size"`
+
+	// Paths overrides the per-user config, cache and state directories
+	Paths paths.Dirs `yaml:"paths"`
+
+	// Auth declares resources served by registered auth providers
+	Auth AuthConfig `yaml:"auth"`
+
+	// Audit records every tool call, with redacted arguments, to a sink
+	Audit audit.Config `yaml:"audit"`
+
+	// Canaries call tools periodically with fixed arguments, reporting
+	// failures as metrics and readiness checks
+	Canaries []canary.Check `yaml:"canaries"`
+
+	// secrets are values resolved from secret references, redacted from
+	// logs and dumps
+	secrets []string
+}
+
+// AuthConfig configures outbound auth resources
+type AuthConfig struct {
+	// Resources are registered with their providers at startup
+	Resources []auth.ResourceDeclaration `yaml:"resources"`
+
+	// OAuthEndpoints registers custom authorization servers by name
+	// (Okta, Keycloak, Azure AD tenants, ...) for use by OAuth providers
+	OAuthEndpoints map[string]auth.OAuthEndpoint `yaml:"oauth_endpoints"`
+
+	// OAuth declares OAuth2 providers, keyed by provider name
+	// A provider's endpoint defaults to the endpoint of the same name.
+	OAuth map[string]auth.OAuth2Config `yaml:"oauth"`
+
+	// TokenStore selects where OAuth2 tokens are kept (default: encrypted
+	// files under paths.tokens)
+	TokenStore auth.TokenStoreConfig `yaml:"token_store"`
+
+	// Tools binds tools to the auth resources their calls use, keyed by
+	// tool name, overriding the bindings set with ToolBuilder.AuthResource
+	Tools map[string]auth.ToolResource `yaml:"tools"`
 }
 
 // BackendConfig configures the backend
 type BackendConfig struct {
 	Type   string                 `yaml:"type"`
 	Config map[string]interface{} `yaml:"config"`
+
+	// Mounts serves several registered backends from one server (see
+	// backend.CompositeBackend); Type then names the composite
+	Mounts []MountConfig `yaml:"mounts"`
+
+	// InitTimeout bounds Initialize, e.g. an upstream credentials check
+	// (default backend.DefaultInitializeTimeout; negative: no limit)
+	InitTimeout time.Duration `yaml:"init_timeout"`
+
+	// CloseTimeout bounds Close at shutdown (default
+	// backend.DefaultCloseTimeout; negative: no limit)
+	CloseTimeout time.Duration `yaml:"close_timeout"`
+}
+
+// MountConfig mounts a registered backend in a composite backend
+type MountConfig struct {
+	Type   string                 `yaml:"type"`
+	Prefix string                 `yaml:"prefix"`
+	Config map[string]interface{} `yaml:"config"`
 }
 
 // TransportConfig configures the transport layer
 type TransportConfig struct {
-	Type  string     `yaml:"type"`
-	HTTP  HTTPConfig `yaml:"http"`
-	Stdio struct{}   `yaml:"stdio"`
+	Type string     `yaml:"type"`
+	HTTP HTTPConfig `yaml:"http"`
+
+	// Stdio sets the framing, concurrency and message size limit of the
+	// stdio transport
+	Stdio stdioTransport.Config `yaml:"stdio"`
 }
 
 // HTTPConfig configures HTTP transport
 type HTTPConfig struct {
-	Address        string        `yaml:"address"`
-	ReadTimeout    time.Duration `yaml:"read_timeout"`
-	WriteTimeout   time.Duration `yaml:"write_timeout"`
-	MaxRequestSize int64         `yaml:"max_request_size"`
-	AllowedOrigins []string      `yaml:"allowed_origins"`
+	Address      string        `yaml:"address"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+
+	// MaxRequestSize bounds request bodies, after decompression; larger
+	// ones are refused with 413
+	MaxRequestSize int64 `yaml:"max_request_size"`
+
+	// AllowedOrigins is shorthand for CORS.AllowedOrigins
+	AllowedOrigins []string `yaml:"allowed_origins"`
+
+	// CORS is the cross-origin policy of /rpc, /stream and the health
+	// endpoints: origins (with wildcards), methods, headers, credentials
+	// and preflight max age
+	CORS httpTransport.CORSConfig `yaml:"cors"`
+
+	// Compression gzips responses to clients that accept it; gzip request
+	// bodies are always accepted
+	Compression httpTransport.CompressionConfig `yaml:"compression"`
+
+	// Auth enforces authentication of inbound /rpc and /stream requests
+	Auth auth.InboundConfig `yaml:"auth"`
+
+	// AccessLog logs every request with its status and duration
+	AccessLog httpTransport.AccessLogConfig `yaml:"access_log"`
+
+	// Admin serves the admin API under /admin/; it requires Auth
+	Admin AdminConfig `yaml:"admin"`
+
+	// Sessions issues MCP sessions (Mcp-Session-Id) to clients that
+	// initialize, with per-session state for tool handlers
+	Sessions session.Config `yaml:"sessions"`
+
+	// TLS serves HTTPS and HTTP/2 from certificate files or ACME, with
+	// optional client certificates (mTLS)
+	TLS httpTransport.TLSConfig `yaml:"tls"`
+
+	// TrustedProxies are the addresses and CIDR ranges of the proxies
+	// whose X-Client-ID headers name the caller for per-client rate
+	// limiting; other callers' are ignored
+	TrustedProxies httpTransport.TrustedProxies `yaml:"trusted_proxies"`
 }
 
 // ObservabilityConfig configures observability features
 type ObservabilityConfig struct {
 	Enabled        bool   `yaml:"enabled"`
 	MetricsAddress string `yaml:"metrics_address"`
+
+	// Tracing exports OpenTelemetry spans of transports, dispatch, cache,
+	// tool execution and upstream HTTP calls; it works without Enabled
+	Tracing observability.TracingConfig `yaml:"tracing"`
+
+	// Debug serves pprof profiles, expvar variables and the redacted
+	// config on the metrics server to allowed addresses
+	Debug observability.DebugConfig `yaml:"debug"`
+
+	// RequestIDs is the format of generated request IDs: uuidv7
+	// (default), ulid or random (no embedded timestamp)
+	RequestIDs string `yaml:"request_ids"`
 }
 
 // LoggingConfig configures logging
@@ -52,13 +191,124 @@ type LoggingConfig struct {
 	AddSource bool   `yaml:"add_source"`
 }
 
+// ToolsConfig configures which tools are served
+type ToolsConfig struct {
+	// Disabled tools are hidden from tools/list and cannot be called
+	Disabled []string `yaml:"disabled"`
+}
+
+// ProvenanceConfig configures the provenance metadata attached to tool
+// results (_meta.provenance)
+type ProvenanceConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Server and Version identify this server in the metadata
+	Server  string `yaml:"server"`
+	Version string `yaml:"version"`
+}
+
 // StreamingConfig configures streaming execution (NEW - v2 feature)
 type StreamingConfig struct {
 	Enabled       bool          `yaml:"enabled"`
 	BufferSize    int           `yaml:"buffer_size"`
 	Timeout       time.Duration `yaml:"timeout"`
 	MaxEvents     int64         `yaml:"max_events"`
-	MaxConcurrent int           `yaml:"max_concurrent"` // NEW: v2 semaphore
+	MaxConcurrent int           `yaml:"max_concurrent"` // Number of executor workers
+
+	// QueueSize bounds calls waiting for a worker (negative disables queueing)
+	QueueSize int `yaml:"queue_size"`
+
+	// QueueTimeout bounds how long a call waits for a worker (0 = no limit)
+	QueueTimeout time.Duration `yaml:"queue_timeout"`
+
+	// RejectPolicy is "reject" (fail fast when the queue is full) or "block"
+	RejectPolicy string `yaml:"reject_policy"`
+
+	// Record keeps the event streams of executions for replay (debugging)
+	Record RecordConfig `yaml:"record"`
+
+	// ResumeBuffer keeps each stream's last events so clients that
+	// reconnect to /stream with Last-Event-ID resume it (0 disables)
+	ResumeBuffer int `yaml:"resume_buffer"`
+
+	// ResumeWindow is how long a stream waits for its client to come back
+	// (default engine.DefaultResumeWindow)
+	ResumeWindow time.Duration `yaml:"resume_window"`
+
+	// MaxEventSize bounds the JSON data of each /stream event in bytes
+	// (0 = no limit)
+	MaxEventSize int `yaml:"max_event_size"`
+
+	// OversizedEvents is "split" (send oversized events as fragments,
+	// the default) or "truncate"
+	OversizedEvents string `yaml:"oversized_events"`
+
+	// Heartbeat is the interval of ": ping" comments on idle /stream
+	// responses, keeping proxies from closing them (0: 15s, negative: none)
+	Heartbeat time.Duration `yaml:"heartbeat"`
+
+	// ChunkThreshold serves non-streaming tools on /stream, sending
+	// results over this many bytes as content_chunk events (0 disables)
+	ChunkThreshold int `yaml:"chunk_threshold"`
+
+	// ChunkSize is the size of each chunk (default 64KB)
+	ChunkSize int `yaml:"chunk_size"`
+
+	// OverflowPolicy is what emits do when a stream's buffer is full:
+	// "block" (the default), "drop_oldest", "drop_newest" or "coalesce"
+	OverflowPolicy string `yaml:"overflow_policy"`
+
+	// OverflowTimeout bounds how long a blocked emit waits (0: 30s,
+	// negative: until the call ends)
+	OverflowTimeout time.Duration `yaml:"overflow_timeout"`
+
+	// BatchSize groups up to this many data events into one array event
+	// (0 or 1: no batching), sent at the latest FlushInterval after its
+	// first (0: 100ms)
+	BatchSize     int           `yaml:"batch_size"`
+	FlushInterval time.Duration `yaml:"flush_interval"`
+
+	// LogLevel is the least severe level of the log events sent: debug,
+	// info (the default), notice, warning, error, critical, alert or
+	// emergency; clients may set their own with X-Log-Level
+	LogLevel string `yaml:"log_level"`
+}
+
+// batching returns the executor's data event batching
+func (c StreamingConfig) batching() engine.Batching {
+	return engine.Batching{Size: c.BatchSize, FlushInterval: c.FlushInterval}
+}
+
+// overflow returns the executor's overflow policy
+func (c StreamingConfig) overflow() engine.Overflow {
+	return engine.Overflow{Policy: engine.OverflowPolicy(c.OverflowPolicy), Timeout: c.OverflowTimeout}
+}
+
+// eventLimit returns the /stream event size limit
+func (c StreamingConfig) eventLimit() httpTransport.EventLimit {
+	return httpTransport.EventLimit{MaxSize: c.MaxEventSize, Policy: c.OversizedEvents}
+}
+
+// chunkedResults returns the /stream chunking of non-streaming tools
+func (c StreamingConfig) chunkedResults() httpTransport.ChunkedResults {
+	return httpTransport.ChunkedResults{Threshold: c.ChunkThreshold, ChunkSize: c.ChunkSize}
+}
+
+// RecordConfig configures event stream recording, served for replay on
+// /debug/recordings
+type RecordConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Tools selects the recorded tools (empty = all); X-Record: true
+	// records any single call
+	Tools []string `yaml:"tools"`
+
+	// Directory persists recordings to disk; without one the most recent
+	// are kept in memory
+	Directory string `yaml:"directory"`
+
+	// Capacity bounds the recordings kept (default: 100)
+	Capacity int `yaml:"capacity"`
 }
 
 // DefaultConfig returns the default configuration
@@ -81,6 +331,7 @@ func DefaultConfig() *Config {
 				MaxRequestSize: 10485760,
 				AllowedOrigins: []string{"*"},
 			},
+			Stdio: stdioTransport.DefaultConfig(),
 		},
 		Observability: ObservabilityConfig{
 			Enabled:        true,
@@ -97,24 +348,44 @@ func DefaultConfig() *Config {
 			Timeout:       5 * time.Minute,
 			MaxEvents:     10000,
 			MaxConcurrent: 16, // NEW: v2 concurrency control
+			QueueSize:     engine.DefaultQueueSize,
+			QueueTimeout:  30 * time.Second,
+			RejectPolicy:  string(engine.RejectWhenFull),
 		},
 	}
 }
 
 // LoadConfig loads configuration from a YAML file
+// Values may reference environment variables ($VAR, ${VAR},
+// ${VAR:-default}) and secrets (${env:VAR}, ${file:/path} or any scheme
+// registered with RegisterSecretResolver). References are expanded per
+// value, so an expanded value can't inject YAML.
 func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Expand environment variables
-	expanded := os.ExpandEnv(string(data))
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
 
 	config := DefaultConfig()
-	if err := yaml.Unmarshal([]byte(expanded), config); err != nil {
+	if root.Kind == 0 {
+		// Empty file
+		return config, nil
+	}
+
+	secrets, err := expandNode(context.Background(), &root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand config: %w", err)
+	}
+
+	if err := root.Decode(config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	config.secrets = secrets
 
 	return config, nil
 }
@@ -125,6 +396,12 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("backend type is required")
 	}
 
+	for i, m := range c.Backend.Mounts {
+		if m.Type == "" {
+			return fmt.Errorf("backend mount %d: type is required", i)
+		}
+	}
+
 	if c.Transport.Type == "" {
 		return fmt.Errorf("transport type is required")
 	}
@@ -133,6 +410,49 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("HTTP address is required when using HTTP transport")
 	}
 
+	if err := c.Transport.Stdio.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Transport.HTTP.Auth.Validate(); err != nil {
+		return fmt.Errorf("invalid HTTP auth configuration: %w", err)
+	}
+
+	if c.Transport.HTTP.Admin.Enabled && !c.Transport.HTTP.Auth.Enabled {
+		return fmt.Errorf("the admin API requires inbound authentication (transport.http.auth)")
+	}
+
+	if err := c.Transport.HTTP.Sessions.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Transport.HTTP.TLS.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Transport.HTTP.CORS.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Transport.HTTP.Compression.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Transport.HTTP.TrustedProxies.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := observability.IDGeneratorByName(c.Observability.RequestIDs); err != nil {
+		return err
+	}
+	if err := c.Observability.Debug.Validate(); err != nil {
+		return err
+	}
+
+	if rate := c.Transport.HTTP.AccessLog.SampleRate; rate < 0 || rate > 1 {
+		return fmt.Errorf("access log sample rate must be between 0 and 1, got %v", rate)
+	}
+
 	// NEW: Validate streaming config
 	if c.Streaming.Enabled {
 		if c.Streaming.BufferSize <= 0 {
@@ -141,6 +461,80 @@ func (c *Config) Validate() error {
 		if c.Streaming.MaxConcurrent <= 0 {
 			return fmt.Errorf("max concurrent executions must be positive")
 		}
+		switch engine.RejectPolicy(c.Streaming.RejectPolicy) {
+		case "", engine.RejectWhenFull, engine.BlockWhenFull:
+		default:
+			return fmt.Errorf("unknown streaming reject policy %q", c.Streaming.RejectPolicy)
+		}
+		if c.Streaming.QueueTimeout < 0 {
+			return fmt.Errorf("streaming queue timeout must not be negative")
+		}
+		if c.Streaming.ResumeBuffer < 0 || c.Streaming.ResumeWindow < 0 {
+			return fmt.Errorf("streaming resume buffer and window must not be negative")
+		}
+		if err := c.Streaming.overflow().Validate(); err != nil {
+			return fmt.Errorf("invalid streaming config: %w", err)
+		}
+		if err := c.Streaming.batching().Validate(); err != nil {
+			return fmt.Errorf("invalid streaming config: %w", err)
+		}
+		if c.Streaming.LogLevel != "" {
+			if err := engine.ValidateLogLevel(c.Streaming.LogLevel); err != nil {
+				return fmt.Errorf("invalid streaming config: %w", err)
+			}
+		}
+		if err := c.Streaming.eventLimit().Validate(); err != nil {
+			return fmt.Errorf("invalid streaming config: %w", err)
+		}
+		if err := c.Streaming.chunkedResults().Validate(); err != nil {
+			return fmt.Errorf("invalid streaming config: %w", err)
+		}
+	}
+
+	for name, endpoint := range c.Auth.OAuthEndpoints {
+		if err := endpoint.Validate(); err != nil {
+			return fmt.Errorf("invalid oauth endpoint %q: %w", name, err)
+		}
+	}
+
+	if err := c.Auth.TokenStore.Validate(); err != nil {
+		return fmt.Errorf("invalid auth token store: %w", err)
+	}
+
+	if err := auth.ValidateResourceDeclarations(c.Auth.Resources); err != nil {
+		return fmt.Errorf("invalid auth resource: %w", err)
+	}
+
+	for tool, resource := range c.Auth.Tools {
+		if err := resource.Validate(); err != nil {
+			return fmt.Errorf("invalid auth binding of tool %q: %w", tool, err)
+		}
+	}
+
+	if err := c.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit configuration: %w", err)
+	}
+
+	if err := c.Results.Validate(); err != nil {
+		return fmt.Errorf("invalid results configuration: %w", err)
+	}
+
+	if c.ListPageSize < 0 {
+		return fmt.Errorf("list_page_size must not be negative, got %d", c.ListPageSize)
+	}
+
+	if err := c.Audit.Validate(); err != nil {
+		return fmt.Errorf("invalid audit configuration: %w", err)
+	}
+	// The stdio transport owns stdout
+	if c.Audit.Enabled && c.Transport.Type == "stdio" && (c.Audit.Sink == "" || c.Audit.Sink == audit.SinkStdout) {
+		return fmt.Errorf("audit sink stdout cannot be used with the stdio transport")
+	}
+
+	if c.Cache != nil {
+		if err := c.Cache.Validate(); err != nil {
+			return fmt.Errorf("invalid cache configuration: %w", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,87 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultListenPort is used when $PORT is not set
+const DefaultListenPort = 8080
+
+// ListenPortFromEnv returns the HTTP listen address from the environment
+// Follows 12-factor conventions: $PORT selects the port and the optional
+// $HOST the interface (all interfaces by default). Invalid values fall back
+// to DefaultListenPort.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithHTTPAddress(framework.ListenPortFromEnv()),
+//	)
+func ListenPortFromEnv() string {
+	return net.JoinHostPort(os.Getenv("HOST"), strconv.Itoa(portFromEnv()))
+}
+
+// portFromEnv returns $PORT, or DefaultListenPort when unset or invalid
+func portFromEnv() int {
+	if v := os.Getenv("PORT"); v != "" {
+		if p, err := strconv.Atoi(v); err == nil && p > 0 && p < 65536 {
+			return p
+		}
+	}
+	return DefaultListenPort
+}
+
+// HandleHealthcheckFlag implements the "-healthcheck <url>" self-probe used
+// by container health checks on images without a shell or curl
+// Call it first thing in main; it exits the process when the flag is present.
+//
+// Example:
+//
+//	func main() {
+//	    framework.HandleHealthcheckFlag()
+//	    ...
+//	}
+func HandleHealthcheckFlag() {
+	if len(os.Args) < 2 || os.Args[1] != "-healthcheck" {
+		return
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/health", portFromEnv())
+	if len(os.Args) > 2 {
+		url = os.Args[2]
+	}
+
+	if err := CheckHealth(context.Background(), url); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// CheckHealth probes a health endpoint, returning an error unless it answers 200
+func CheckHealth(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("healthcheck: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("healthcheck: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("healthcheck: %s returned %d", url, resp.StatusCode)
+	}
+	return nil
+}
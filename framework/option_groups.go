@@ -0,0 +1,524 @@
+package framework
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+)
+
+// ============================================================
+// Option Errors
+// ============================================================
+
+// New creates a server like NewServer, but reports invalid options and
+// conflicting option groups right away
+//
+// Example:
+//
+//	server, err := framework.New(
+//	    framework.WithTransportOptions(
+//	        framework.TransportHTTP(":8080"),
+//	        framework.TransportSessions(30*time.Minute, 0),
+//	    ),
+//	    framework.WithCacheOptions(
+//	        framework.CacheType(cache.TypeShort, 60),
+//	        framework.CacheToolTTL("search", 10*time.Second),
+//	    ),
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+func New(opts ...Option) (*Server, error) {
+	s := NewServer(opts...)
+	if err := s.optionsErr(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// optionError records an option that could not be applied
+// NewServer keeps going; New and Initialize report the errors.
+func (s *Server) optionError(err error) {
+	s.optionErrs = append(s.optionErrs, err)
+}
+
+// optionsErr returns the errors of the options, nil if all applied
+func (s *Server) optionsErr() error {
+	if len(s.optionErrs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid options: %w", errors.Join(s.optionErrs...))
+}
+
+// ensureConfig creates the default config when WithConfig(nil) dropped it,
+// so options work in any order
+func (s *Server) ensureConfig() {
+	if s.config == nil {
+		s.config = DefaultConfig()
+	}
+}
+
+// applyOptionGroups validates the option groups and applies them
+// Groups are applied after the plain options, whatever their order, and
+// again over a loaded config file.
+func (s *Server) applyOptionGroups() {
+	if t := s.transportOptions; t != nil {
+		if err := t.validate(); err != nil {
+			s.optionError(err)
+			s.transportOptions = nil
+		} else if t.listener != nil {
+			s.listener = t.listener
+		}
+	}
+	if c := s.cacheOptions; c != nil {
+		if err := c.validate(); err != nil {
+			s.optionError(err)
+			s.cacheOptions = nil
+		}
+	}
+	s.reapplyOptionGroups()
+}
+
+// reapplyOptionGroups writes the validated groups into the config
+func (s *Server) reapplyOptionGroups() {
+	s.ensureConfig()
+	if t := s.transportOptions; t != nil {
+		t.apply(&s.config.Transport)
+	}
+	if c := s.cacheOptions; c != nil {
+		c.apply(s)
+	}
+}
+
+// conflictError reports options of one group that can't be combined
+func conflictError(group, reason string, options []string) error {
+	return fmt.Errorf("%s options: %s (%s)", group, reason, strings.Join(options, ", "))
+}
+
+// ============================================================
+// Transport Options
+// ============================================================
+
+// TransportOption configures the transport within WithTransportOptions
+type TransportOption func(*transportSettings)
+
+// transportSettings collects the transport options of every
+// WithTransportOptions, so conflicts between them are caught too
+type transportSettings struct {
+	// kinds are the transport types chosen, in order
+	kinds []string
+
+	// http are the HTTP settings; httpOpts names the HTTP-only options
+	http     []func(*HTTPConfig)
+	httpOpts []string
+
+	listener net.Listener
+	errs     []error
+}
+
+// WithTransportOptions configures the transport as one checked group
+// Conflicting options, e.g. TransportStdio with TransportSessions, make
+// New and Initialize fail. The group takes precedence over WithConfig and
+// the config file, whatever the order of the options.
+func WithTransportOptions(opts ...TransportOption) Option {
+	return func(s *Server) {
+		if s.transportOptions == nil {
+			s.transportOptions = &transportSettings{}
+		}
+		for _, opt := range opts {
+			opt(s.transportOptions)
+		}
+	}
+}
+
+// TransportHTTP serves JSON-RPC and streams over HTTP on address
+func TransportHTTP(address string) TransportOption {
+	return func(t *transportSettings) {
+		t.kinds = append(t.kinds, "http")
+		if address == "" {
+			t.errs = append(t.errs, fmt.Errorf("TransportHTTP: address is required"))
+			return
+		}
+		t.http = append(t.http, func(c *HTTPConfig) { c.Address = address })
+	}
+}
+
+// TransportStdio serves JSON-RPC over stdin and stdout
+func TransportStdio() TransportOption {
+	return func(t *transportSettings) {
+		t.kinds = append(t.kinds, "stdio")
+	}
+}
+
+// TransportTimeouts bounds reading requests and writing responses
+// (0 keeps the default; /stream responses need a long write timeout)
+func TransportTimeouts(read, write time.Duration) TransportOption {
+	return func(t *transportSettings) {
+		if read < 0 || write < 0 {
+			t.errs = append(t.errs, fmt.Errorf("TransportTimeouts: timeouts must not be negative"))
+			return
+		}
+		t.setHTTP("TransportTimeouts", func(c *HTTPConfig) {
+			if read > 0 {
+				c.ReadTimeout = read
+			}
+			if write > 0 {
+				c.WriteTimeout = write
+			}
+		})
+	}
+}
+
+// TransportMaxRequestSize bounds request bodies to size bytes
+func TransportMaxRequestSize(size int64) TransportOption {
+	return func(t *transportSettings) {
+		if size <= 0 {
+			t.errs = append(t.errs, fmt.Errorf("TransportMaxRequestSize: size must be positive, got %d", size))
+			return
+		}
+		t.setHTTP("TransportMaxRequestSize", func(c *HTTPConfig) { c.MaxRequestSize = size })
+	}
+}
+
+// TransportAllowedOrigins sets the origins browsers may call from
+func TransportAllowedOrigins(origins ...string) TransportOption {
+	return func(t *transportSettings) {
+		t.setHTTP("TransportAllowedOrigins", func(c *HTTPConfig) { c.AllowedOrigins = origins })
+	}
+}
+
+// TransportTrustedProxies sets the proxies, by address or CIDR range,
+// whose X-Client-ID headers name the caller for per-client rate limiting
+func TransportTrustedProxies(proxies ...string) TransportOption {
+	return func(t *transportSettings) {
+		if err := httpTransport.TrustedProxies(proxies).Validate(); err != nil {
+			t.errs = append(t.errs, fmt.Errorf("TransportTrustedProxies: %w", err))
+			return
+		}
+		t.setHTTP("TransportTrustedProxies", func(c *HTTPConfig) { c.TrustedProxies = proxies })
+	}
+}
+
+// TransportCORS sets the cross-origin policy (see WithCORS)
+func TransportCORS(config httpTransport.CORSConfig) TransportOption {
+	return func(t *transportSettings) {
+		if err := config.Validate(); err != nil {
+			t.errs = append(t.errs, fmt.Errorf("TransportCORS: %w", err))
+			return
+		}
+		t.setHTTP("TransportCORS", func(c *HTTPConfig) { c.CORS = config })
+	}
+}
+
+// TransportCompression gzips responses at the level (0: default) to
+// clients that accept it
+func TransportCompression(level int) TransportOption {
+	return func(t *transportSettings) {
+		compression := httpTransport.CompressionConfig{Enabled: true, Level: level}
+		if err := compression.Validate(); err != nil {
+			t.errs = append(t.errs, fmt.Errorf("TransportCompression: %w", err))
+			return
+		}
+		t.setHTTP("TransportCompression", func(c *HTTPConfig) { c.Compression = compression })
+	}
+}
+
+// TransportAccessLog logs requests (see WithAccessLog)
+func TransportAccessLog(sampleRate float64, skipPaths ...string) TransportOption {
+	return func(t *transportSettings) {
+		if sampleRate < 0 || sampleRate > 1 {
+			t.errs = append(t.errs, fmt.Errorf("TransportAccessLog: sample rate must be between 0 and 1, got %v", sampleRate))
+			return
+		}
+		t.setHTTP("TransportAccessLog", func(c *HTTPConfig) {
+			c.AccessLog = httpTransport.AccessLogConfig{Enabled: true, SampleRate: sampleRate, SkipPaths: skipPaths}
+		})
+	}
+}
+
+// TransportSessions issues MCP sessions to clients (see WithSessions)
+func TransportSessions(idleTimeout time.Duration, maxSessions int) TransportOption {
+	return func(t *transportSettings) {
+		sessions := session.Config{Enabled: true, IdleTimeout: idleTimeout, MaxSessions: maxSessions}
+		if err := sessions.Validate(); err != nil {
+			t.errs = append(t.errs, fmt.Errorf("TransportSessions: %w", err))
+			return
+		}
+		t.setHTTP("TransportSessions", func(c *HTTPConfig) { c.Sessions = sessions })
+	}
+}
+
+// TransportTLS serves HTTPS (see WithTLS)
+func TransportTLS(config httpTransport.TLSConfig) TransportOption {
+	return func(t *transportSettings) {
+		config.Enabled = true
+		if err := config.Validate(); err != nil {
+			t.errs = append(t.errs, fmt.Errorf("TransportTLS: %w", err))
+			return
+		}
+		t.setHTTP("TransportTLS", func(c *HTTPConfig) { c.TLS = config })
+	}
+}
+
+// TransportListener serves HTTP on l instead of binding the address
+func TransportListener(l net.Listener) TransportOption {
+	return func(t *transportSettings) {
+		if l == nil {
+			t.errs = append(t.errs, fmt.Errorf("TransportListener: listener is nil"))
+			return
+		}
+		t.listener = l
+		t.httpOpts = append(t.httpOpts, "TransportListener")
+	}
+}
+
+// setHTTP records an HTTP-only setting
+func (t *transportSettings) setHTTP(option string, set func(*HTTPConfig)) {
+	t.http = append(t.http, set)
+	t.httpOpts = append(t.httpOpts, option)
+}
+
+// kind returns the transport type chosen ("" keeps the configured one)
+func (t *transportSettings) kind() string {
+	if len(t.kinds) == 0 {
+		return ""
+	}
+	return t.kinds[0]
+}
+
+// validate checks the options alone and against each other
+func (t *transportSettings) validate() error {
+	errs := t.errs
+	for _, kind := range t.kinds {
+		if kind != t.kind() {
+			errs = append(errs, conflictError("transport", "more than one transport", []string{"TransportHTTP", "TransportStdio"}))
+			break
+		}
+	}
+	if t.kind() == "stdio" && len(t.httpOpts) > 0 {
+		errs = append(errs, conflictError("transport", "HTTP settings with TransportStdio", t.httpOpts))
+	}
+	return errors.Join(errs...)
+}
+
+// apply writes the settings into the transport config
+func (t *transportSettings) apply(c *TransportConfig) {
+	if kind := t.kind(); kind != "" {
+		c.Type = kind
+	}
+	for _, set := range t.http {
+		set(&c.HTTP)
+	}
+}
+
+// ============================================================
+// Cache Options
+// ============================================================
+
+// CacheOption configures response caching within WithCacheOptions
+type CacheOption func(*cacheSettings)
+
+// cacheSettings collects the cache options of every WithCacheOptions
+type cacheSettings struct {
+	config   cache.Config
+	store    cache.Cache
+	identity cache.IdentityFunc
+	disabled bool
+
+	// set are the options given, by name, for conflict errors
+	set  []string
+	errs []error
+}
+
+// WithCacheOptions configures response caching as one checked group
+// Caching is enabled with cache.DefaultConfig's settings unless
+// CacheDisabled is given; conflicting options, e.g. CacheDisabled with
+// CacheMaxSize, make New and Initialize fail. The group takes precedence
+// over the plain cache options and the config file.
+func WithCacheOptions(opts ...CacheOption) Option {
+	return func(s *Server) {
+		if s.cacheOptions == nil {
+			s.cacheOptions = &cacheSettings{config: *cache.DefaultConfig()}
+			s.cacheOptions.config.Enabled = true
+			s.cacheOptions.config.Directory = ""
+		}
+		for _, opt := range opts {
+			opt(s.cacheOptions)
+		}
+	}
+}
+
+// CacheType selects the built-in cache and its default TTL: seconds for
+// cache.TypeShort (memory), minutes for cache.TypeLong (files)
+func CacheType(cacheType cache.Type, ttl int) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheType")
+		c.config.Type = cacheType
+		c.config.TTL = ttl
+	}
+}
+
+// CacheMaxSize bounds the entries of the memory cache
+func CacheMaxSize(maxSize int) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheMaxSize")
+		c.config.MaxSize = maxSize
+	}
+}
+
+// CacheMaxBytes bounds the approximate memory of the memory cache's
+// entries
+func CacheMaxBytes(maxBytes int64) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheMaxBytes")
+		c.config.MaxBytes = maxBytes
+	}
+}
+
+// CacheCompression compresses the memory cache's values of threshold
+// bytes or more (0: cache.DefaultCompressionThreshold) with algorithm,
+// "gzip" or one registered with cache.RegisterCompressor
+func CacheCompression(algorithm string, threshold int) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheCompression")
+		c.config.Compression = cache.Compression{Algorithm: algorithm, Threshold: threshold}
+	}
+}
+
+// CacheCleanupInterval sets how often the memory cache removes expired
+// entries (0: cache.DefaultCleanupInterval, negative: never)
+func CacheCleanupInterval(interval time.Duration) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheCleanupInterval")
+		c.config.CleanupInterval = interval
+	}
+}
+
+// CacheDirectory sets the directory of the file cache
+func CacheDirectory(dir string) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheDirectory")
+		c.config.Directory = dir
+	}
+}
+
+// CacheToolTTL overrides the TTL of one tool's results
+func CacheToolTTL(toolName string, ttl time.Duration) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheToolTTL")
+		if ttl < 0 {
+			c.errs = append(c.errs, fmt.Errorf("CacheToolTTL: TTL of %q must not be negative", toolName))
+			return
+		}
+		if c.config.ToolTTL == nil {
+			c.config.ToolTTL = make(map[string]time.Duration)
+		}
+		c.config.ToolTTL[toolName] = ttl
+	}
+}
+
+// CacheKeyPrefix namespaces the cache keys (see WithCacheKeyPrefix)
+func CacheKeyPrefix(prefix string) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheKeyPrefix")
+		c.config.KeyPrefix = prefix
+	}
+}
+
+// CacheIdentity sets how callers are told apart for the tools cached per
+// principal (see WithCacheIdentity)
+func CacheIdentity(identity cache.IdentityFunc) CacheOption {
+	return func(c *cacheSettings) {
+		c.set = append(c.set, "CacheIdentity")
+		if identity == nil {
+			c.errs = append(c.errs, fmt.Errorf("CacheIdentity: identity is nil"))
+			return
+		}
+		c.identity = identity
+	}
+}
+
+// CacheStore caches results in store instead of a built-in cache (see
+// WithCacheStore)
+func CacheStore(store cache.Cache) CacheOption {
+	return func(c *cacheSettings) {
+		if store == nil {
+			c.errs = append(c.errs, fmt.Errorf("CacheStore: store is nil"))
+			return
+		}
+		c.store = store
+	}
+}
+
+// CacheDisabled turns caching off, whatever the config file says
+func CacheDisabled() CacheOption {
+	return func(c *cacheSettings) {
+		c.disabled = true
+	}
+}
+
+// validate checks the options alone and against each other
+func (c *cacheSettings) validate() error {
+	errs := c.errs
+	switch {
+	case c.disabled && (len(c.set) > 0 || c.store != nil):
+		errs = append(errs, conflictError("cache", "settings with CacheDisabled", c.withStore()))
+	case c.store != nil && c.has("CacheMaxSize", "CacheMaxBytes", "CacheCompression", "CacheCleanupInterval", "CacheDirectory"):
+		errs = append(errs, conflictError("cache", "built-in cache settings with CacheStore", c.withStore()))
+	case c.config.Type == cache.TypeShort && c.has("CacheDirectory"):
+		errs = append(errs, conflictError("cache", "a directory for the memory cache", c.set))
+	case c.config.Type == cache.TypeLong && c.has("CacheMaxSize", "CacheMaxBytes"):
+		errs = append(errs, conflictError("cache", "a maximum size for the file cache", c.set))
+	case c.config.Type == cache.TypeLong && c.has("CacheCompression"):
+		errs = append(errs, conflictError("cache", "compression for the file cache", c.set))
+	}
+	if !c.disabled {
+		// Initialize defaults the directory to the configured paths
+		config := c.config
+		config.Enabled = true
+		if config.Directory == "" {
+			config.Directory = cache.DefaultDirectory()
+		}
+		if err := config.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("cache options: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// has reports whether any of options was given
+func (c *cacheSettings) has(options ...string) bool {
+	for _, set := range c.set {
+		for _, option := range options {
+			if set == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withStore returns the options given, CacheStore included
+func (c *cacheSettings) withStore() []string {
+	if c.store == nil {
+		return c.set
+	}
+	return append(append([]string(nil), c.set...), "CacheStore")
+}
+
+// apply sets the server's cache config and store
+func (c *cacheSettings) apply(s *Server) {
+	config := c.config
+	config.Enabled = !c.disabled
+	s.cacheConfig = &config
+	s.cacheStore = c.store
+	if c.identity != nil {
+		s.cacheIdentity = c.identity
+	}
+}
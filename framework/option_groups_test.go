@@ -0,0 +1,93 @@
+package framework
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+)
+
+func TestNew_OptionGroups(t *testing.T) {
+	// Groups win over WithConfig, whichever comes first
+	s, err := New(
+		WithTransportOptions(
+			TransportHTTP(":9999"),
+			TransportSessions(time.Minute, 10),
+		),
+		WithConfig(DefaultConfig()),
+		WithCacheOptions(
+			CacheType(cache.TypeShort, 30),
+			CacheToolTTL("search", 5*time.Second),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.config.Transport.Type != "http" || s.config.Transport.HTTP.Address != ":9999" || !s.config.Transport.HTTP.Sessions.Enabled {
+		t.Errorf("transport = %+v", s.config.Transport)
+	}
+	if !s.cacheConfig.Enabled || s.cacheConfig.TTL != 30 || s.cacheConfig.ToolTTL["search"] != 5*time.Second {
+		t.Errorf("cache = %+v", s.cacheConfig)
+	}
+}
+
+func TestNew_OptionConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			"stdio with HTTP settings",
+			[]Option{WithTransportOptions(TransportStdio(), TransportSessions(0, 0))},
+			"HTTP settings with TransportStdio (TransportSessions)",
+		},
+		{
+			"two transports across groups",
+			[]Option{WithTransportOptions(TransportHTTP(":8080")), WithTransportOptions(TransportStdio())},
+			"more than one transport",
+		},
+		{
+			"disabled cache with settings",
+			[]Option{WithCacheOptions(CacheDisabled(), CacheMaxSize(10))},
+			"settings with CacheDisabled (CacheMaxSize)",
+		},
+		{
+			"directory for the memory cache",
+			[]Option{WithCacheOptions(CacheType(cache.TypeShort, 60), CacheDirectory("/tmp/c"))},
+			"a directory for the memory cache",
+		},
+		{
+			"invalid cache settings",
+			[]Option{WithCacheOptions(CacheType(cache.TypeShort, 0))},
+			"TTL must be positive",
+		},
+		{
+			"mistyped auth config",
+			[]Option{WithAuth("api-key", "secret")},
+			"auth type api-key: config is string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts...); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("New error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_InitializeReportsOptionErrors(t *testing.T) {
+	s := NewServer(
+		WithBackend(backend.NewBaseBackend("test")),
+		WithObservability(false),
+		WithAuth("unknown", nil),
+	)
+	err := s.Initialize(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `unsupported auth type "unknown"`) {
+		t.Errorf("Initialize error = %v", err)
+	}
+}
@@ -2,12 +2,23 @@ package framework
 
 import (
 	"context"
-	"os"
+	"fmt"
+	"net"
 	"time"
 
 	"github.com/SaherElMasry/go-mcp-framework/auth"
 	"github.com/SaherElMasry/go-mcp-framework/backend"
 	"github.com/SaherElMasry/go-mcp-framework/cache" // ADD THIS LINE
+	"github.com/SaherElMasry/go-mcp-framework/canary"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/paths"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
+	stdioTransport "github.com/SaherElMasry/go-mcp-framework/transport/stdio"
 )
 
 // Option configures the server
@@ -24,6 +35,21 @@ func WithBackend(b backend.ServerBackend) Option {
 	}
 }
 
+// WithBackends serves several backends from one server, with optional
+// tool name prefixes (see backend.CompositeBackend)
+//
+// Example:
+//
+//	framework.WithBackends(
+//	    backend.Mount{Backend: fs, Prefix: "fs"},
+//	    backend.Mount{Backend: gh, Prefix: "gh"},
+//	)
+func WithBackends(mounts ...backend.Mount) Option {
+	return func(s *Server) {
+		s.backend = backend.NewCompositeBackend("composite", mounts...)
+	}
+}
+
 // WithConfigFile sets the config file path
 func WithConfigFile(path string) Option {
 	return func(s *Server) {
@@ -31,6 +57,15 @@ func WithConfigFile(path string) Option {
 	}
 }
 
+// WithConfigReload sets how often the config file is checked for changes
+// (default: DefaultReloadInterval). Zero only reloads on SIGHUP; a negative
+// interval disables reloading.
+func WithConfigReload(interval time.Duration) Option {
+	return func(s *Server) {
+		s.reloadInterval = interval
+	}
+}
+
 // WithConfig sets the complete configuration
 func WithConfig(config *Config) Option {
 	return func(s *Server) {
@@ -41,13 +76,21 @@ func WithConfig(config *Config) Option {
 // WithBackendType sets the backend type
 func WithBackendType(backendType string) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Backend.Type = backendType
 	}
 }
 
+// WithBackendTimeouts bounds backend Initialize and Close (0 keeps the
+// defaults; negative means no limit)
+func WithBackendTimeouts(initialize, close time.Duration) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Backend.InitTimeout = initialize
+		s.config.Backend.CloseTimeout = close
+	}
+}
+
 // ============================================================
 // Transport Options
 // ============================================================
@@ -55,9 +98,7 @@ func WithBackendType(backendType string) Option {
 // WithTransport sets the transport type
 func WithTransport(transport string) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Transport.Type = transport
 	}
 }
@@ -65,13 +106,125 @@ func WithTransport(transport string) Option {
 // WithHTTPAddress sets the HTTP server address
 func WithHTTPAddress(addr string) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Transport.HTTP.Address = addr
 	}
 }
 
+// WithStdio serves over stdin and stdout with the given framing,
+// concurrency and message size limit (see stdioTransport.Config)
+func WithStdio(config stdioTransport.Config) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Transport.Type = "stdio"
+		s.config.Transport.Stdio = config
+	}
+}
+
+// WithAccessLog logs every HTTP request with its status, size and duration
+// sampleRate is the share of successful requests logged (1 logs all);
+// failed requests are always logged.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithAccessLog(0.1, "/health/live", "/health/ready"),
+//	)
+func WithAccessLog(sampleRate float64, skipPaths ...string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Transport.HTTP.AccessLog = httpTransport.AccessLogConfig{
+			Enabled:    true,
+			SampleRate: sampleRate,
+			SkipPaths:  skipPaths,
+		}
+	}
+}
+
+// WithSessions issues MCP sessions to HTTP clients that initialize
+// (0 keeps the session.Config defaults)
+func WithSessions(idleTimeout time.Duration, maxSessions int) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Transport.HTTP.Sessions = session.Config{
+			Enabled:     true,
+			IdleTimeout: idleTimeout,
+			MaxSessions: maxSessions,
+		}
+	}
+}
+
+// WithCORS sets the cross-origin policy of every HTTP endpoint
+//
+// Example:
+//
+//	framework.WithCORS(httpTransport.CORSConfig{
+//	    AllowedOrigins:   []string{"https://*.example.com"},
+//	    AllowCredentials: true,
+//	    MaxAge:           10 * time.Minute,
+//	})
+func WithCORS(config httpTransport.CORSConfig) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		if err := config.Validate(); err != nil {
+			s.optionError(fmt.Errorf("WithCORS: %w", err))
+			return
+		}
+		s.config.Transport.HTTP.CORS = config
+	}
+}
+
+// WithCompression gzips HTTP responses to clients that accept it
+func WithCompression(config httpTransport.CompressionConfig) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		config.Enabled = true
+		if err := config.Validate(); err != nil {
+			s.optionError(fmt.Errorf("WithCompression: %w", err))
+			return
+		}
+		s.config.Transport.HTTP.Compression = config
+	}
+}
+
+// WithTLS serves HTTPS and HTTP/2 with the certificate and key files
+// For mTLS, ACME or cipher settings use TransportTLS or the config file.
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Transport.HTTP.TLS.Enabled = true
+		s.config.Transport.HTTP.TLS.CertFile = certFile
+		s.config.Transport.HTTP.TLS.KeyFile = keyFile
+	}
+}
+
+// ============================================================
+// Directory Options
+// ============================================================
+
+// WithAppName sets the subdirectory used for per-user config, cache and state
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithAppName("mcp-github"), // ~/.local/state/mcp-github/tokens
+//	)
+func WithAppName(name string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Paths.AppName = name
+	}
+}
+
+// WithPaths overrides the config, cache and state directories
+// Must precede options that use them (WithCache, WithOAuth)
+func WithPaths(dirs paths.Dirs) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Paths = dirs
+	}
+}
+
 // ============================================================
 // Observability Options
 // ============================================================
@@ -79,9 +232,7 @@ func WithHTTPAddress(addr string) Option {
 // WithObservability enables/disables observability
 func WithObservability(enabled bool) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = DefaultConfig()
-		}
+		s.ensureConfig()
 		s.config.Observability.Enabled = enabled
 	}
 }
@@ -89,9 +240,7 @@ func WithObservability(enabled bool) Option {
 // WithLogLevel sets the log level
 func WithLogLevel(level string) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Logging.Level = level
 	}
 }
@@ -99,13 +248,47 @@ func WithLogLevel(level string) Option {
 // WithMetricsAddress sets the metrics server address
 func WithMetricsAddress(addr string) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Observability.MetricsAddress = addr
 	}
 }
 
+// WithTracing enables OpenTelemetry tracing with an OTLP/HTTP exporter
+//
+// Example:
+//
+//	framework.WithTracing(observability.TracingConfig{
+//	    Endpoint: "http://otel-collector:4318",
+//	    SampleRatio: 0.1,
+//	})
+func WithTracing(config observability.TracingConfig) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		config.Enabled = true
+		s.config.Observability.Tracing = config
+	}
+}
+
+// WithIDGenerator sets how request IDs are generated, overriding the
+// config's observability.request_ids
+// The generator is process-wide (see observability.SetIDGenerator); IDs
+// must be unique across replicas and printable ASCII.
+func WithIDGenerator(gen observability.IDGenerator) Option {
+	return func(s *Server) {
+		s.idGenerator = gen
+	}
+}
+
+// WithDebugEndpoints serves /debug/pprof/, /debug/vars and /debug/config
+// on the metrics server to the given addresses or CIDR ranges (default:
+// loopback only)
+func WithDebugEndpoints(allowedIPs ...string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Observability.Debug = observability.DebugConfig{Enabled: true, AllowedIPs: allowedIPs}
+	}
+}
+
 // ============================================================
 // Streaming Options
 // ============================================================
@@ -113,9 +296,7 @@ func WithMetricsAddress(addr string) Option {
 // WithStreaming enables/disables streaming
 func WithStreaming(enabled bool) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Streaming.Enabled = enabled
 	}
 }
@@ -123,9 +304,7 @@ func WithStreaming(enabled bool) Option {
 // WithStreamingBufferSize sets the event buffer size
 func WithStreamingBufferSize(size int) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Streaming.BufferSize = size
 	}
 }
@@ -133,9 +312,7 @@ func WithStreamingBufferSize(size int) Option {
 // WithStreamingTimeout sets the execution timeout
 func WithStreamingTimeout(timeout time.Duration) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Streaming.Timeout = timeout
 	}
 }
@@ -143,19 +320,120 @@ func WithStreamingTimeout(timeout time.Duration) Option {
 // WithMaxConcurrent sets maximum concurrent executions
 func WithMaxConcurrent(max int) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Streaming.MaxConcurrent = max
 	}
 }
 
+// WithExecutorQueue sets the executor queue depth, wait timeout and reject policy
+func WithExecutorQueue(size int, timeout time.Duration, policy engine.RejectPolicy) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Streaming.QueueSize = size
+		s.config.Streaming.QueueTimeout = timeout
+		s.config.Streaming.RejectPolicy = string(policy)
+	}
+}
+
+// WithEventOverflow sets what emits do when a stream's event buffer is
+// full; tools may set their own (backend.ToolBuilder.EventOverflow)
+func WithEventOverflow(policy engine.OverflowPolicy, timeout time.Duration) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Streaming.OverflowPolicy = string(policy)
+		s.config.Streaming.OverflowTimeout = timeout
+	}
+}
+
+// WithEventBatching groups up to size data events of every stream into
+// one array event, sent at the latest flushInterval after its first;
+// tools may set their own (backend.ToolBuilder.BatchEvents)
+func WithEventBatching(size int, flushInterval time.Duration) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Streaming.BatchSize = size
+		s.config.Streaming.FlushInterval = flushInterval
+	}
+}
+
+// WithStreamLogLevel sets the least severe level of the log events
+// streaming tools send (default engine.DefaultLogLevel)
+func WithStreamLogLevel(level string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Streaming.LogLevel = level
+	}
+}
+
+// WithResumableStreams keeps the last buffer events of every stream, so
+// clients that lose their /stream connection resume it with Last-Event-ID
+// within window (0: engine.DefaultResumeWindow)
+func WithResumableStreams(buffer int, window time.Duration) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Streaming.ResumeBuffer = buffer
+		s.config.Streaming.ResumeWindow = window
+	}
+}
+
+// WithMaxEventSize bounds the JSON data of each /stream event to size
+// bytes, sending larger events split into fragments or truncated
+// (policy httpTransport.OversizedSplit or httpTransport.OversizedTruncate)
+func WithMaxEventSize(size int, policy string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Streaming.MaxEventSize = size
+		s.config.Streaming.OversizedEvents = policy
+	}
+}
+
+// WithSSEHeartbeat sets the interval of the ": ping" comments sent on idle
+// /stream responses (0: httpTransport.DefaultHeartbeat, negative: none)
+func WithSSEHeartbeat(interval time.Duration) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Streaming.Heartbeat = interval
+	}
+}
+
+// WithChunkedResults serves non-streaming tools on /stream, sending
+// results over threshold bytes as content_chunk events of chunkSize bytes
+// (0: engine.DefaultChunkSize) and a content_complete marker
+func WithChunkedResults(threshold, chunkSize int) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Streaming.ChunkThreshold = threshold
+		s.config.Streaming.ChunkSize = chunkSize
+	}
+}
+
+// WithEventRecording records the event streams of streaming executions
+// into store, for replay on /debug/recordings; with no tools, every
+// execution is recorded
+// Meant for debugging client rendering: recordings hold tool arguments
+// and output verbatim.
+func WithEventRecording(store engine.RecordingStore, tools ...string) Option {
+	return func(s *Server) {
+		s.recorder = engine.NewRecorder(store, tools...)
+	}
+}
+
+// WithCanary calls a tool periodically with fixed arguments, recording
+// failures and latency as metrics and a "canary:<name>" readiness check
+// Pick arguments that are safe to send forever (a fixed city for a
+// weather tool, a read-only path for a file tool). Set check.Cron to
+// run on a cron schedule instead of an interval; with observability
+// enabled, the metrics server lists schedules on canary.PathSchedules.
+func WithCanary(check canary.Check) Option {
+	return func(s *Server) {
+		s.canaryChecks = append(s.canaryChecks, check)
+	}
+}
+
 // WithMaxEvents sets maximum events per execution
 func WithMaxEvents(max int64) Option {
 	return func(s *Server) {
-		if s.config == nil {
-			s.config = &Config{}
-		}
+		s.ensureConfig()
 		s.config.Streaming.MaxEvents = max
 	}
 }
@@ -174,27 +452,58 @@ func WithAuth(authType string, config interface{}) Option {
 			if cfg, ok := config.(auth.APIKeyConfig); ok {
 				provider = auth.NewAPIKeyProvider("default", cfg)
 			} else {
-				s.logger.Error("invalid config type for api-key provider")
+				s.optionError(fmt.Errorf("auth type api-key: config is %T, want auth.APIKeyConfig", config))
+				return
+			}
+
+		case "aws-sigv4":
+			if cfg, ok := config.(auth.AWSSigV4Config); ok {
+				provider = auth.NewAWSSigV4Provider("default", cfg)
+			} else {
+				s.optionError(fmt.Errorf("auth type aws-sigv4: config is %T, want auth.AWSSigV4Config", config))
+				return
+			}
+
+		case "gcp":
+			if cfg, ok := config.(auth.GCPConfig); ok {
+				provider = auth.NewGCPProvider("default", cfg)
+			} else {
+				s.optionError(fmt.Errorf("auth type gcp: config is %T, want auth.GCPConfig", config))
+				return
+			}
+
+		case "static-header":
+			if cfg, ok := config.(auth.StaticHeaderConfig); ok {
+				provider = auth.NewStaticHeaderProvider("default", cfg)
+			} else {
+				s.optionError(fmt.Errorf("auth type static-header: config is %T, want auth.StaticHeaderConfig", config))
 				return
 			}
 
 		case "database":
-			if _, ok := config.(auth.DatabaseConfig); ok {
-				provider = auth.NewDatabaseProvider("default")
-				// Additional database setup would go here
+			if cfg, ok := config.(auth.DatabaseConfig); ok {
+				db := auth.NewDatabaseProvider("default")
+				// The config is the provider's "default" database
+				if cfg.Driver != "" {
+					if err := db.RegisterDatabase("default", cfg); err != nil {
+						s.optionError(err)
+						return
+					}
+				}
+				provider = db
 			} else {
-				s.logger.Error("invalid config type for database provider")
+				s.optionError(fmt.Errorf("auth type database: config is %T, want auth.DatabaseConfig", config))
 				return
 			}
 
 		default:
-			s.logger.Error("unsupported auth type", "type", authType)
+			s.optionError(fmt.Errorf("unsupported auth type %q", authType))
 			return
 		}
 
 		// Register provider
 		if err := s.authManager.Register("default", provider); err != nil {
-			s.logger.Error("failed to register auth provider", "error", err)
+			s.optionError(fmt.Errorf("failed to register auth provider: %w", err))
 			return
 		}
 
@@ -209,9 +518,7 @@ func WithAuth(authType string, config interface{}) Option {
 func WithAuthProvider(name string, provider auth.AuthProvider) Option {
 	return func(s *Server) {
 		if err := s.authManager.Register(name, provider); err != nil {
-			s.logger.Error("failed to register auth provider",
-				"name", name,
-				"error", err)
+			s.optionError(fmt.Errorf("failed to register auth provider %q: %w", name, err))
 			return
 		}
 
@@ -227,45 +534,42 @@ func WithAuthResource(providerName string, resource auth.ResourceConfig) Option
 	return func(s *Server) {
 		provider, err := s.authManager.Get(providerName)
 		if err != nil {
-			s.logger.Error("auth provider not found",
-				"name", providerName,
-				"error", err)
+			s.optionError(fmt.Errorf("auth resource %q: %w", resource.ID, err))
 			return
 		}
 
 		// Check if provider supports RegisterResource
-		if apiProvider, ok := provider.(*auth.APIKeyProvider); ok {
-			apiProvider.RegisterResource(resource)
-		} else if dbProvider, ok := provider.(*auth.DatabaseProvider); ok {
-			dbProvider.RegisterResource(resource)
-		} else if oauth2Provider, ok := provider.(*auth.OAuth2Provider); ok {
-			oauth2Provider.RegisterResource(resource)
+		if registrar, ok := provider.(auth.ResourceRegistrar); ok {
+			registrar.RegisterResource(resource)
 		} else {
-			s.logger.Error("provider does not support resource registration",
-				"provider", providerName)
+			s.optionError(fmt.Errorf("auth provider %q does not support resource registration", providerName))
 		}
 	}
 }
 
-// WithOAuth configures OAuth2 authentication for popular providers
-func WithOAuth(providerName, clientID, clientSecret, redirectURL string, scopes []string) Option {
+// WithToolAuthResource binds a tool to the auth resource its calls use
+// It overrides the tool's own binding (ToolBuilder.AuthResource); the
+// handler reads the resource with auth.ResourceFromContext.
+//
+// Example:
+//
+//	framework.WithToolAuthResource("create_repo", auth.ToolResource{
+//	    Provider: "github", Resource: "github-write",
+//	})
+func WithToolAuthResource(tool string, resource auth.ToolResource) Option {
 	return func(s *Server) {
-		// Create token store with encryption
-		encryptionKey := os.Getenv("OAUTH_ENCRYPTION_KEY")
-		if encryptionKey == "" {
-			// Generate a key if not provided (for development)
-			var err error
-			encryptionKey, err = auth.GenerateKey()
-			if err != nil {
-				s.logger.Error("failed to generate encryption key", "error", err)
-				return
-			}
-			s.logger.Warn("using generated encryption key - set OAUTH_ENCRYPTION_KEY for production")
+		if err := s.authManager.BindTool(tool, resource); err != nil {
+			s.optionError(fmt.Errorf("auth binding: %w", err))
 		}
+	}
+}
 
-		tokenStore, err := auth.NewFileTokenStore(".tokens", encryptionKey)
+// WithOAuth configures OAuth2 authentication for popular providers
+func WithOAuth(providerName, clientID, clientSecret, redirectURL string, scopes []string) Option {
+	return func(s *Server) {
+		tokenStore, err := s.newTokenStore()
 		if err != nil {
-			s.logger.Error("failed to create token store", "error", err)
+			s.optionError(fmt.Errorf("failed to create token store: %w", err))
 			return
 		}
 
@@ -275,15 +579,13 @@ func WithOAuth(providerName, clientID, clientSecret, redirectURL string, scopes
 		// Create OAuth2 provider
 		provider, err := factory.Create(providerName, clientID, clientSecret, redirectURL, scopes)
 		if err != nil {
-			s.logger.Error("failed to create OAuth2 provider",
-				"provider", providerName,
-				"error", err)
+			s.optionError(fmt.Errorf("failed to create OAuth2 provider %q: %w", providerName, err))
 			return
 		}
 
 		// Register provider
 		if err := s.authManager.Register("default", provider); err != nil {
-			s.logger.Error("failed to register OAuth2 provider", "error", err)
+			s.optionError(fmt.Errorf("failed to register OAuth2 provider: %w", err))
 			return
 		}
 
@@ -298,27 +600,57 @@ func WithOAuth(providerName, clientID, clientSecret, redirectURL string, scopes
 	}
 }
 
+// WithTokenStore keeps OAuth2 tokens in store instead of the one the
+// config's auth.token_store selects
+// Must precede the WithOAuth options that use it.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithTokenStore(auth.NewKeyringTokenStore("my-cli")),
+//	    framework.WithGitHub(clientID, "", redirectURL, nil),
+//	)
+func WithTokenStore(store auth.TokenStore) Option {
+	return func(s *Server) {
+		s.tokenStore = store
+	}
+}
+
+// WithOAuthEndpoint registers a custom authorization server for WithOAuth
+// Must precede the WithOAuth option that uses it.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithOAuthEndpoint("keycloak", auth.KeycloakEndpoint("https://sso.example.com", "eng")),
+//	    framework.WithOAuth("keycloak", clientID, clientSecret, redirectURL, nil),
+//	)
+func WithOAuthEndpoint(name string, endpoint auth.OAuthEndpoint) Option {
+	return func(s *Server) {
+		if err := auth.RegisterOAuthEndpoint(name, endpoint); err != nil {
+			s.optionError(fmt.Errorf("failed to register OAuth endpoint %q: %w", name, err))
+		}
+	}
+}
+
 // WithOAuth2Token sets a pre-configured OAuth2 token
 func WithOAuth2Token(providerName string, token *auth.OAuth2Token) Option {
 	return func(s *Server) {
 		provider, err := s.authManager.Get(providerName)
 		if err != nil {
-			s.logger.Error("provider not found",
-				"name", providerName,
-				"error", err)
+			s.optionError(fmt.Errorf("OAuth2 token: %w", err))
 			return
 		}
 
 		oauth2Provider, ok := provider.(*auth.OAuth2Provider)
 		if !ok {
-			s.logger.Error("provider is not an OAuth2 provider",
-				"name", providerName)
+			s.optionError(fmt.Errorf("OAuth2 token: provider %q is not an OAuth2 provider", providerName))
 			return
 		}
 
 		ctx := context.Background()
 		if err := oauth2Provider.SetToken(ctx, token); err != nil {
-			s.logger.Error("failed to set token", "error", err)
+			s.optionError(fmt.Errorf("failed to set OAuth2 token: %w", err))
 			return
 		}
 
@@ -355,6 +687,43 @@ func WithSlack(clientID, clientSecret, redirectURL string, scopes []string) Opti
 	return WithOAuth("slack", clientID, clientSecret, redirectURL, scopes)
 }
 
+// WithGitHubApp authenticates as a GitHub App installation
+// Installation tokens are minted from the app's private key and renewed
+// automatically; resources without a base_url target api.github.com.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithGitHubApp(123456, "/etc/mcp/github-app.pem", 7890123),
+//	    framework.WithAuthResource("default", auth.ResourceConfig{ID: "github-api", Type: "api"}),
+//	)
+func WithGitHubApp(appID int64, keyPath string, installationID int64) Option {
+	return func(s *Server) {
+		provider, err := auth.NewGitHubAppProvider("default", auth.GitHubAppConfig{
+			AppID:          appID,
+			InstallationID: installationID,
+			PrivateKeyPath: keyPath,
+		})
+		if err != nil {
+			s.optionError(fmt.Errorf("failed to create GitHub App provider: %w", err))
+			return
+		}
+
+		if err := s.authManager.Register("default", provider); err != nil {
+			s.optionError(fmt.Errorf("failed to register GitHub App provider: %w", err))
+			return
+		}
+
+		if s.backend != nil {
+			s.backend.SetAuthProvider(provider)
+		}
+
+		s.logger.Info("GitHub App provider configured",
+			"app_id", appID,
+			"installation_id", installationID)
+	}
+}
+
 // ============================================================
 // CACHE OPTIONS (NEW - v0.4.0)
 // ============================================================
@@ -372,7 +741,7 @@ func WithCache(cacheType string, ttl int) Option {
 			Type:      cache.Type(cacheType),
 			TTL:       ttl,
 			MaxSize:   1000,
-			Directory: ".mcp-cache",
+			Directory: s.cacheDirectory(),
 			Enabled:   true,
 			ToolTTL:   make(map[string]time.Duration),
 		}
@@ -418,6 +787,80 @@ func WithCacheSize(maxSize int) Option {
 	}
 }
 
+// WithCacheMaxBytes bounds the approximate memory of the memory cache's
+// entries; least recently used entries are evicted to stay under it
+func WithCacheMaxBytes(maxBytes int64) Option {
+	return func(s *Server) {
+		if s.cacheConfig == nil {
+			s.cacheConfig = cache.DefaultConfig()
+			s.cacheConfig.Enabled = true
+		}
+		s.cacheConfig.MaxBytes = maxBytes
+	}
+}
+
+// WithCacheCompression compresses the memory cache's values of threshold
+// bytes or more (0: cache.DefaultCompressionThreshold) with algorithm,
+// "gzip" or one registered with cache.RegisterCompressor
+func WithCacheCompression(algorithm string, threshold int) Option {
+	return func(s *Server) {
+		if s.cacheConfig == nil {
+			s.cacheConfig = cache.DefaultConfig()
+			s.cacheConfig.Enabled = true
+		}
+		s.cacheConfig.Compression = cache.Compression{Algorithm: algorithm, Threshold: threshold}
+	}
+}
+
+// WithCacheKeyPrefix namespaces the cache keys, e.g. per server or
+// environment when several share a store
+func WithCacheKeyPrefix(prefix string) Option {
+	return func(s *Server) {
+		if s.cacheConfig == nil {
+			s.cacheConfig = cache.DefaultConfig()
+			s.cacheConfig.Enabled = true
+		}
+		s.cacheConfig.KeyPrefix = prefix
+	}
+}
+
+// WithCacheIdentity sets how callers are told apart for the tools cached
+// per principal (see backend.ToolBuilder.CachePerPrincipal), e.g. by
+// tenant rather than by user; the default is the authenticated principal
+//
+// Example:
+//
+//	framework.WithCacheIdentity(func(ctx context.Context) string {
+//	    p, _ := auth.PrincipalFromContext(ctx)
+//	    tenant, _ := p.Claims["tenant"].(string)
+//	    return tenant
+//	})
+func WithCacheIdentity(identity cache.IdentityFunc) Option {
+	return func(s *Server) {
+		s.cacheIdentity = identity
+	}
+}
+
+// principalIdentity identifies a caller by its authentication method and
+// principal ID, "" if it is anonymous
+func principalIdentity(ctx context.Context) string {
+	p, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return p.Method + ":" + p.ID
+}
+
+// WithCacheStore caches tool results in store, e.g. one shared by
+// several replicas, instead of the built-in memory cache
+// Caching is enabled with the default TTLs unless the cache is
+// configured; the server closes store on shutdown.
+func WithCacheStore(store cache.Cache) Option {
+	return func(s *Server) {
+		s.cacheStore = store
+	}
+}
+
 // WithCacheDisabled explicitly disables caching
 func WithCacheDisabled() Option {
 	return func(s *Server) {
@@ -425,3 +868,272 @@ func WithCacheDisabled() Option {
 		s.cacheConfig.Enabled = false
 	}
 }
+
+// ============================================================
+// RATE LIMIT OPTIONS
+// ============================================================
+
+// WithRateLimit enables a global rate limit on tool calls
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithRateLimit(50, 100), // 50 req/s, bursts of 100
+//	)
+func WithRateLimit(rate float64, burst int) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.RateLimit.Enabled = true
+		s.config.RateLimit.Global = ratelimit.Rule{Rate: rate, Burst: burst}
+	}
+}
+
+// WithClientRateLimit enables a per-client rate limit
+// Clients are identified by principal, by X-Client-ID when a trusted
+// proxy sends it (see TransportTrustedProxies), or by remote address
+func WithClientRateLimit(rate float64, burst int) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.RateLimit.Enabled = true
+		s.config.RateLimit.PerClient = ratelimit.Rule{Rate: rate, Burst: burst}
+	}
+}
+
+// WithToolRateLimit sets a rate limit for a single tool
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithToolRateLimit("search", 2, 5),
+//	)
+func WithToolRateLimit(toolName string, rate float64, burst int) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.RateLimit.Enabled = true
+		if s.config.RateLimit.PerTool == nil {
+			s.config.RateLimit.PerTool = make(map[string]ratelimit.Rule)
+		}
+		s.config.RateLimit.PerTool[toolName] = ratelimit.Rule{Rate: rate, Burst: burst}
+	}
+}
+
+// ============================================================
+// PROVENANCE OPTIONS
+// ============================================================
+
+// WithProvenance attaches provenance metadata (_meta.provenance) to every
+// tool result, identifying this server by name and version
+func WithProvenance(server, version string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Provenance = ProvenanceConfig{
+			Enabled: true,
+			Server:  server,
+			Version: version,
+		}
+	}
+}
+
+// ============================================================
+// OUTPUT FILTER OPTIONS
+// ============================================================
+
+// WithOutputFilter adds a filter that inspects or rewrites tool results
+// before they are returned; filters run in the order they were added.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithOutputFilter(safety.RedactSecrets()),
+//	)
+func WithOutputFilter(filter protocol.OutputFilter) Option {
+	return func(s *Server) {
+		s.outputFilters = append(s.outputFilters, filter)
+	}
+}
+
+// ============================================================
+// RESULT SIZE OPTIONS
+// ============================================================
+
+// WithResultLimit bounds the encoded size of tools/call results in bytes;
+// policy (protocol.OversizedError, OversizedTruncate or OversizedSpill)
+// says what happens to larger ones
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithResultLimit(1<<20, protocol.OversizedSpill),
+//	)
+func WithResultLimit(maxSize int, policy string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Results.MaxSize = maxSize
+		s.config.Results.Policy = policy
+	}
+}
+
+// WithToolResultLimit overrides the result size limit and policy of one
+// tool; a zero value keeps the server-wide one
+func WithToolResultLimit(tool string, maxSize int, policy string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		if s.config.Results.Tools == nil {
+			s.config.Results.Tools = make(map[string]protocol.ResultLimit)
+		}
+		s.config.Results.Tools[tool] = protocol.ResultLimit{MaxSize: maxSize, Policy: policy}
+	}
+}
+
+// ============================================================
+// LIST OPTIONS
+// ============================================================
+
+// WithListPageSize returns tools/list and resources/list in pages of at
+// most n items; clients follow nextCursor for the rest
+func WithListPageSize(n int) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.ListPageSize = n
+	}
+}
+
+// ============================================================
+// HEALTH OPTIONS
+// ============================================================
+
+// WithHealthCheck registers a liveness or readiness check
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithHealthCheck(health.Readiness, "database", db.PingContext),
+//	)
+func WithHealthCheck(kind health.Kind, name string, check health.Check) Option {
+	return func(s *Server) {
+		if s.health == nil {
+			s.health = health.NewRegistry()
+		}
+		s.health.Register(kind, name, check)
+	}
+}
+
+// ============================================================
+// INBOUND AUTH OPTIONS
+// ============================================================
+
+// WithInboundAuth configures authentication of inbound HTTP requests
+func WithInboundAuth(config auth.InboundConfig) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Transport.HTTP.Auth = config
+	}
+}
+
+// WithInboundAPIKey accepts an API key on the HTTP transport
+// Enables inbound authentication; may be repeated for multiple keys
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithInboundAPIKey("ci", os.Getenv("MCP_CI_KEY")),
+//	)
+func WithInboundAPIKey(id, key string, scopes ...string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		a := &s.config.Transport.HTTP.Auth
+		a.Enabled = true
+		a.APIKeys.Keys = append(a.APIKeys.Keys, auth.InboundCredential{ID: id, Secret: key, Scopes: scopes})
+	}
+}
+
+// WithInboundBearerToken accepts a static bearer token on the HTTP transport
+// Enables inbound authentication; may be repeated for multiple tokens
+func WithInboundBearerToken(id, token string, scopes ...string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		a := &s.config.Transport.HTTP.Auth
+		a.Enabled = true
+		a.Bearer.Tokens = append(a.Bearer.Tokens, auth.InboundCredential{ID: id, Secret: token, Scopes: scopes})
+	}
+}
+
+// WithInboundJWT accepts bearer JWTs from issuer on the HTTP transport
+// Signatures are checked against the keys published at jwksURL, and the
+// aud claim must name one of audiences. Enables inbound authentication;
+// may be repeated for multiple issuers.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithInboundJWT("https://acme.okta.com/oauth2/default",
+//	        "https://acme.okta.com/oauth2/default/v1/keys", "api://mcp"),
+//	)
+func WithInboundJWT(issuer, jwksURL string, audiences ...string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		a := &s.config.Transport.HTTP.Auth
+		a.Enabled = true
+		a.JWT.Issuers = append(a.JWT.Issuers, auth.JWTIssuerConfig{Issuer: issuer, JWKSURL: jwksURL, Audiences: audiences})
+	}
+}
+
+// WithAuthenticator authenticates inbound HTTP requests with a, e.g. a
+// JWT validator, before any configured API keys, bearer tokens or
+// introspection; may be repeated
+// a returns auth.ErrNoCredentials for requests without its credentials,
+// so the next authenticator is tried.
+func WithAuthenticator(a auth.Authenticator) Option {
+	return func(s *Server) {
+		s.authenticators = append(s.authenticators, a)
+	}
+}
+
+// WithAdminAPI serves the admin API (see AdminConfig) under /admin/ to
+// principals granted scope (default DefaultAdminScope)
+// Inbound authentication must be enabled too.
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithInboundBearerToken("ops", os.Getenv("MCP_OPS_TOKEN"), "admin"),
+//	    framework.WithAdminAPI(""),
+//	)
+func WithAdminAPI(scope string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		s.config.Transport.HTTP.Admin = AdminConfig{Enabled: true, Scope: scope}
+	}
+}
+
+// WithTokenIntrospection validates bearer tokens with an RFC 7662 introspection endpoint
+func WithTokenIntrospection(endpoint, clientID, clientSecret string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		a := &s.config.Transport.HTTP.Auth
+		a.Enabled = true
+		a.Introspection.Endpoint = endpoint
+		a.Introspection.ClientID = clientID
+		a.Introspection.ClientSecret = clientSecret
+	}
+}
+
+// WithToolAccess restricts a tool to the given principal IDs
+func WithToolAccess(toolName string, principals ...string) Option {
+	return func(s *Server) {
+		s.ensureConfig()
+		a := &s.config.Transport.HTTP.Auth
+		if a.Tools == nil {
+			a.Tools = make(map[string]auth.ToolAccessPolicy)
+		}
+		a.Tools[toolName] = auth.ToolAccessPolicy{AllowedPrincipals: principals}
+	}
+}
+
+// WithListener serves the HTTP transport on an existing listener
+// Useful for socket activation and tests (see the service package)
+func WithListener(l net.Listener) Option {
+	return func(s *Server) {
+		s.listener = l
+	}
+}
@@ -0,0 +1,219 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// DefaultReloadInterval is how often the config file is checked for changes
+const DefaultReloadInterval = 5 * time.Second
+
+// toolSwitcher is implemented by backends that can disable tools at
+// runtime (BaseBackend does)
+type toolSwitcher interface {
+	SetDisabledTools(names []string)
+}
+
+// Reload re-reads the config file and applies its dynamic settings:
+// logging.level, rate_limit, cache.ttl and cache.tool_ttl, and
+// tools.disabled. Connections are not dropped.
+//
+// A file changing anything else (transport, backend, auth, ...) is rejected
+// as a whole, listing the settings that need a restart.
+func (s *Server) Reload() error {
+	if s.configFile == "" {
+		return fmt.Errorf("no config file to reload")
+	}
+
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	next, err := LoadConfig(s.configFile)
+	if err != nil {
+		return err
+	}
+	if err := next.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	// Secrets of the running and the new config are both masked
+	s.addSecrets(next.secrets)
+
+	if fields := s.restartRequired(next); len(fields) > 0 {
+		return fmt.Errorf("changes to %s require a restart", strings.Join(fields, ", "))
+	}
+
+	var applied []string
+
+	if next.Logging.Level != s.config.Logging.Level {
+		s.logLevel.Set(observability.ParseLevel(next.Logging.Level))
+		s.config.Logging.Level = next.Logging.Level
+		applied = append(applied, "logging.level")
+	}
+
+	if !reflect.DeepEqual(next.RateLimit, s.config.RateLimit) && s.limiter != nil {
+		if err := s.limiter.Update(&next.RateLimit); err != nil {
+			return err
+		}
+		s.config.RateLimit = next.RateLimit
+		applied = append(applied, "rate_limit")
+	}
+
+	if s.cacheFromConfig && next.Cache != nil && !reflect.DeepEqual(next.Cache, s.config.Cache) {
+		updated := *s.cacheConfig
+		updated.TTL = next.Cache.TTL
+		updated.ToolTTL = next.Cache.ToolTTL
+		s.updateCacheConfig(&updated)
+		s.cacheConfig = &updated
+		s.config.Cache = next.Cache
+		applied = append(applied, "cache")
+	}
+
+	if !reflect.DeepEqual(next.Tools, s.config.Tools) {
+		if err := s.applyDisabledTools(next.Tools.Disabled); err != nil {
+			return err
+		}
+		s.config.Tools = next.Tools
+		applied = append(applied, "tools")
+	}
+
+	if len(applied) == 0 {
+		s.logger.Info("config reloaded, nothing changed", "file", s.configFile)
+		return nil
+	}
+	s.logger.Info("config reloaded", "file", s.configFile, "applied", applied)
+	return nil
+}
+
+// restartRequired lists the settings next changes that can't be applied
+// to a running server
+func (s *Server) restartRequired(next *Config) []string {
+	current := s.config
+	var fields []string
+	check := func(name string, a, b interface{}) {
+		if !reflect.DeepEqual(a, b) {
+			fields = append(fields, name)
+		}
+	}
+
+	check("backend", current.Backend, next.Backend)
+	check("transport.type", current.Transport.Type, next.Transport.Type)
+	check("transport.http.address", current.Transport.HTTP.Address, next.Transport.HTTP.Address)
+
+	// Compare the rest of the HTTP settings without the address
+	currentHTTP, nextHTTP := current.Transport.HTTP, next.Transport.HTTP
+	currentHTTP.Address, nextHTTP.Address = "", ""
+	check("transport.http", currentHTTP, nextHTTP)
+
+	check("observability", current.Observability, next.Observability)
+	check("logging.format", current.Logging.Format, next.Logging.Format)
+	check("logging.add_source", current.Logging.AddSource, next.Logging.AddSource)
+	check("streaming", current.Streaming, next.Streaming)
+	check("paths", current.Paths, next.Paths)
+	check("auth", current.Auth, next.Auth)
+	check("provenance", current.Provenance, next.Provenance)
+	check("canaries", current.Canaries, next.Canaries)
+	check("audit", current.Audit, next.Audit)
+
+	if s.limiter == nil && next.RateLimit.Enabled {
+		fields = append(fields, "rate_limit.enabled")
+	}
+
+	// The file's cache section is ignored when options configured the cache
+	if s.cacheFromConfig || s.cacheConfig == nil {
+		check("cache", cacheStructure(current.Cache), cacheStructure(next.Cache))
+	}
+
+	return fields
+}
+
+// cacheStructure returns the cache settings that need a restart
+func cacheStructure(config *cache.Config) *cache.Config {
+	if config == nil {
+		return nil
+	}
+	return &cache.Config{
+		Type:      config.Type,
+		MaxSize:   config.MaxSize,
+		Directory: config.Directory,
+		Enabled:   config.Enabled,
+	}
+}
+
+// updateCacheConfig hands new cache TTLs to the protocol handler
+func (s *Server) updateCacheConfig(config *cache.Config) {
+	if h, ok := s.handler.(*protocol.InstrumentedHandler); ok {
+		h.UpdateCacheConfig(config)
+	} else if h, ok := s.handler.(*protocol.Handler); ok {
+		h.UpdateCacheConfig(config)
+	}
+}
+
+// applyDisabledTools disables tools on the backend
+func (s *Server) applyDisabledTools(names []string) error {
+	switcher, ok := s.backend.(toolSwitcher)
+	if !ok {
+		if len(names) == 0 {
+			return nil
+		}
+		return fmt.Errorf("backend %s does not support disabling tools", s.backend.Name())
+	}
+	switcher.SetDisabledTools(names)
+	return nil
+}
+
+// watchConfig reloads the config file on SIGHUP and when it changes
+func (s *Server) watchConfig(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if s.reloadInterval > 0 {
+		ticker := time.NewTicker(s.reloadInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	modTime := s.configModTime()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			s.logger.Info("SIGHUP received, reloading config", "file", s.configFile)
+			modTime = s.configModTime()
+		case <-tick:
+			current := s.configModTime()
+			if current.Equal(modTime) {
+				continue
+			}
+			modTime = current
+			s.logger.Info("config file changed, reloading", "file", s.configFile)
+		}
+
+		if err := s.Reload(); err != nil {
+			s.logger.Error("config reload rejected, keeping the running configuration",
+				"file", s.configFile,
+				"error", err)
+		}
+	}
+}
+
+// configModTime returns the config file's modification time
+func (s *Server) configModTime() time.Time {
+	info, err := os.Stat(s.configFile)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,87 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+const reloadBaseConfig = `
+backend:
+  type: test
+transport:
+  type: stdio
+observability:
+  enabled: false
+streaming:
+  enabled: false
+`
+
+func TestServer_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(rate int, extra string) {
+		t.Helper()
+		limits := fmt.Sprintf("rate_limit:\n  enabled: true\n  global:\n    rate: %d\n", rate)
+		if err := os.WriteFile(path, []byte(reloadBaseConfig+limits+extra), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(100, "")
+
+	b := backend.NewBaseBackend("test")
+	b.RegisterTool(backend.NewTool("echo").Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return args, nil
+	})
+
+	s := NewServer(WithBackend(b), WithConfigFile(path))
+	if err := s.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	// Dynamic settings are applied
+	write(100, `
+logging:
+  level: debug
+tools:
+  disabled: [echo]
+`)
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if s.logLevel.Level() != slog.LevelDebug {
+		t.Errorf("log level = %v, want debug", s.logLevel.Level())
+	}
+	if _, ok := b.GetTool("echo"); ok {
+		t.Error("echo is still enabled")
+	}
+
+	write(5, "")
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if rate := s.GetRateLimiter().Config().Global.Rate; rate != 5 {
+		t.Errorf("global rate = %v, want 5", rate)
+	}
+	if _, ok := b.GetTool("echo"); !ok {
+		t.Error("echo was not re-enabled")
+	}
+
+	// Changes needing a restart are rejected as a whole
+	restart := strings.Replace(reloadBaseConfig, "type: stdio", "type: http\n  http:\n    address: \":9999\"", 1)
+	if err := os.WriteFile(path, []byte(restart+"logging:\n  level: error\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := s.Reload()
+	if err == nil || !strings.Contains(err.Error(), "transport.type") || !strings.Contains(err.Error(), "transport.http.address") {
+		t.Fatalf("Reload error = %v, want restart required", err)
+	}
+	if s.logLevel.Level() != slog.LevelInfo {
+		t.Errorf("log level = %v, want the previous one kept", s.logLevel.Level())
+	}
+}
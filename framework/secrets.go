@@ -0,0 +1,362 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================================
+// Secret resolvers
+// ============================================================
+
+// SecretResolver looks up secrets referenced from config files
+// A value "${scheme:ref}" is replaced by the secret the resolver
+// registered for scheme returns for ref. Built-in schemes are "env" and
+// "file"; register a VaultResolver (or your own) for others.
+//
+// Example:
+//
+//	framework.RegisterSecretResolver("vault", framework.NewVaultResolver(framework.VaultConfig{}))
+//
+//	# config.yaml
+//	backend:
+//	  config:
+//	    api_key: ${vault:weather#api_key}
+//	    region: ${REGION:-eu-west-1}
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to SecretResolver
+type SecretResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve implements SecretResolver
+func (f SecretResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	secretResolvers = map[string]SecretResolver{
+		"env":  EnvResolver(),
+		"file": FileResolver(),
+	}
+	secretResolversMu sync.RWMutex
+)
+
+// RegisterSecretResolver registers the resolver of a reference scheme
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[scheme] = resolver
+}
+
+// secretResolver returns the resolver of a scheme
+func secretResolver(scheme string) (SecretResolver, bool) {
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	r, ok := secretResolvers[scheme]
+	return r, ok
+}
+
+// EnvResolver resolves "${env:NAME}" to a required environment variable
+// Unlike "${NAME}", the value is treated as a secret and must be set.
+func EnvResolver() SecretResolver {
+	return SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		value, ok := os.LookupEnv(ref)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", ref)
+		}
+		return value, nil
+	})
+}
+
+// FileResolver resolves "${file:/path}" to the file's contents, without a
+// trailing newline (Docker and Kubernetes secrets are mounted as files)
+func FileResolver() SecretResolver {
+	return SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		data, err := os.ReadFile(ref)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	})
+}
+
+// VaultConfig configures a VaultResolver
+type VaultConfig struct {
+	// Address of the Vault server (default: $VAULT_ADDR)
+	Address string
+
+	// Token authenticates to Vault (default: $VAULT_TOKEN)
+	Token string
+
+	// Namespace is the Vault Enterprise namespace (default: $VAULT_NAMESPACE)
+	Namespace string
+
+	// Mount is the KV version 2 secrets engine mount (default: "secret")
+	Mount string
+
+	// Client makes the requests (default: a client with a 10s timeout)
+	Client *http.Client
+}
+
+// VaultResolver resolves "${vault:path#field}" from a KV version 2 engine
+type VaultResolver struct {
+	config VaultConfig
+}
+
+// NewVaultResolver creates a Vault resolver
+func NewVaultResolver(config VaultConfig) *VaultResolver {
+	if config.Address == "" {
+		config.Address = os.Getenv("VAULT_ADDR")
+	}
+	if config.Token == "" {
+		config.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if config.Namespace == "" {
+		config.Namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	if config.Mount == "" {
+		config.Mount = "secret"
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &VaultResolver{config: config}
+}
+
+// Resolve implements SecretResolver
+func (v *VaultResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault reference %q must be path#field", ref)
+	}
+	if v.config.Address == "" {
+		return "", fmt.Errorf("vault address is not configured (set VAULT_ADDR)")
+	}
+
+	endpoint := strings.TrimRight(v.config.Address, "/") + "/v1/" +
+		url.PathEscape(v.config.Mount) + "/data/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.config.Token)
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+
+	resp, err := v.config.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %d for %s", resp.StatusCode, path)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	value, ok := body.Data.Data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %s", path, field)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// ============================================================
+// Expansion
+// ============================================================
+
+// expandNode expands references in every scalar value of a YAML document
+// and returns the secret values it resolved
+func expandNode(ctx context.Context, node *yaml.Node) ([]string, error) {
+	var secrets []string
+	var firstErr error
+
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		switch n.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, child := range n.Content {
+				walk(child)
+			}
+		case yaml.MappingNode:
+			// Expand values only; keys are left alone
+			for i := 1; i < len(n.Content); i += 2 {
+				walk(n.Content[i])
+			}
+		case yaml.ScalarNode:
+			if !strings.Contains(n.Value, "$") {
+				return
+			}
+			value, found, err := expand(ctx, n.Value)
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("line %d: %w", n.Line, err)
+			}
+			secrets = append(secrets, found...)
+			n.Value = value
+			// Let plain scalars be re-resolved, so "${PORT}" can decode as an int
+			if n.Style == 0 {
+				n.Tag = ""
+			}
+		}
+	}
+	walk(node)
+
+	return secrets, firstErr
+}
+
+// expand replaces $VAR, ${VAR}, ${VAR:-default} and ${scheme:ref} in s
+func expand(ctx context.Context, s string) (string, []string, error) {
+	var secrets []string
+	var firstErr error
+
+	result := os.Expand(s, func(name string) string {
+		name, fallback, hasDefault := strings.Cut(name, ":-")
+
+		if scheme, ref, ok := strings.Cut(name, ":"); ok {
+			resolver, registered := secretResolver(scheme)
+			if !registered {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("unknown secret scheme %q in ${%s}", scheme, name)
+				}
+				return ""
+			}
+			value, err := resolver.Resolve(ctx, ref)
+			if err != nil {
+				if hasDefault {
+					return fallback
+				}
+				if firstErr == nil {
+					firstErr = fmt.Errorf("resolve ${%s}: %w", name, err)
+				}
+				return ""
+			}
+			if value != "" {
+				secrets = append(secrets, value)
+			}
+			return value
+		}
+
+		if value, ok := os.LookupEnv(name); ok && (value != "" || !hasDefault) {
+			return value
+		}
+		return fallback
+	})
+
+	return result, secrets, firstErr
+}
+
+// ============================================================
+// Redaction
+// ============================================================
+
+// redacted replaces secret values in config dumps
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are config keys whose values are always redacted
+var sensitiveKeys = map[string]bool{
+	"secret":         true,
+	"client_secret":  true,
+	"password":       true,
+	"token":          true,
+	"api_key":        true,
+	"apikey":         true,
+	"private_key":    true,
+	"access_token":   true,
+	"refresh_token":  true,
+	"encryption_key": true,
+	"dsn":            true,
+}
+
+// Secrets returns the secret values resolved while loading the config
+func (c *Config) Secrets() []string {
+	return c.secrets
+}
+
+// addSecrets adds values to mask in the server's logs
+func (s *Server) addSecrets(values []string) {
+	s.secretsMu.Lock()
+	defer s.secretsMu.Unlock()
+	for _, v := range values {
+		if !slices.Contains(s.secrets, v) {
+			s.secrets = append(s.secrets, v)
+		}
+	}
+}
+
+// secretValues returns the values masked in the server's logs
+func (s *Server) secretValues() []string {
+	s.secretsMu.RLock()
+	defer s.secretsMu.RUnlock()
+	return s.secrets
+}
+
+// dumpConfig dumps the running configuration, serving /debug/config
+func (s *Server) dumpConfig() ([]byte, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	return s.config.Dump()
+}
+
+// Dump returns the configuration as YAML with secrets redacted: values
+// resolved from secret references, wherever they appear, and values of
+// sensitive keys (password, client_secret, api_key, ...)
+func (c *Config) Dump() ([]byte, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		switch n.Kind {
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, child := range n.Content {
+				walk(child)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, value := n.Content[i], n.Content[i+1]
+				if value.Kind == yaml.ScalarNode && value.Value != "" && sensitiveKeys[strings.ToLower(key.Value)] {
+					value.Value, value.Tag, value.Style = redacted, "!!str", 0
+					continue
+				}
+				walk(value)
+			}
+		case yaml.ScalarNode:
+			value := n.Value
+			for _, secret := range c.secrets {
+				value = strings.ReplaceAll(value, secret, redacted)
+			}
+			if value != n.Value {
+				n.Value, n.Tag, n.Style = value, "!!str", 0
+			}
+		}
+	}
+	walk(&root)
+
+	return yaml.Marshal(&root)
+}
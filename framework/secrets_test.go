@@ -0,0 +1,269 @@
+package framework
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_Expansion(t *testing.T) {
+	t.Setenv("MCP_TEST_BACKEND", "weather")
+	t.Setenv("MCP_TEST_PORT", "9100")
+	t.Setenv("MCP_TEST_EMPTY", "")
+
+	secretFile := filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(secretFile, []byte("s3cr3t-from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(writeConfig(t, fmt.Sprintf(`
+backend:
+  type: ${MCP_TEST_BACKEND}
+  config:
+    api_key: ${file:%s}
+    region: ${MCP_TEST_UNSET:-eu-west-1}
+    zone: ${MCP_TEST_EMPTY:-a}
+    label: "$MCP_TEST_PORT"
+transport:
+  type: stdio
+rate_limit:
+  global:
+    burst: ${MCP_TEST_PORT}
+`, secretFile)))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	if config.Backend.Type != "weather" {
+		t.Errorf("backend type = %q", config.Backend.Type)
+	}
+	if got := config.Backend.Config["api_key"]; got != "s3cr3t-from-file" {
+		t.Errorf("api_key = %v", got)
+	}
+	if got := config.Backend.Config["region"]; got != "eu-west-1" {
+		t.Errorf("region = %v, want the default", got)
+	}
+	if got := config.Backend.Config["zone"]; got != "a" {
+		t.Errorf("zone = %v, want the default for an empty variable", got)
+	}
+	if got := config.Backend.Config["label"]; got != "9100" {
+		t.Errorf("quoted label = %#v, want a string", got)
+	}
+	if config.RateLimit.Global.Burst != 9100 {
+		t.Errorf("burst = %d", config.RateLimit.Global.Burst)
+	}
+	if secrets := config.Secrets(); len(secrets) != 1 || secrets[0] != "s3cr3t-from-file" {
+		t.Errorf("secrets = %v", secrets)
+	}
+}
+
+func TestLoadConfig_SecretErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"unknown scheme", "${nope:x}", "unknown secret scheme"},
+		{"missing env", "${env:MCP_TEST_DEFINITELY_UNSET}", "is not set"},
+		{"missing file", "${file:/does/not/exist}", "resolve"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeConfig(t, "backend:\n  type: "+tt.value+"\n"))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// A default covers a failed lookup
+	config, err := LoadConfig(writeConfig(t, "backend:\n  type: ${env:MCP_TEST_DEFINITELY_UNSET:-fallback}\n"))
+	if err != nil || config.Backend.Type != "fallback" {
+		t.Errorf("type = %v, err = %v", config, err)
+	}
+}
+
+func TestVaultResolver(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/weather" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"data":{"api_key":"from-vault"},"metadata":{"version":3}}}`)
+	}))
+	defer vault.Close()
+
+	RegisterSecretResolver("vault-test", NewVaultResolver(VaultConfig{
+		Address: vault.URL,
+		Token:   "root",
+		Mount:   "kv",
+	}))
+
+	config, err := LoadConfig(writeConfig(t, `
+backend:
+  type: weather
+  config:
+    api_key: ${vault-test:weather#api_key}
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got := config.Backend.Config["api_key"]; got != "from-vault" {
+		t.Errorf("api_key = %v", got)
+	}
+
+	_, err = LoadConfig(writeConfig(t, "backend:\n  type: ${vault-test:weather#missing}\n"))
+	if err == nil || !strings.Contains(err.Error(), "has no field missing") {
+		t.Errorf("missing field error = %v", err)
+	}
+}
+
+func TestConfig_Dump(t *testing.T) {
+	RegisterSecretResolver("static-test", SecretResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		return "token-" + ref, nil
+	}))
+
+	config, err := LoadConfig(writeConfig(t, `
+backend:
+  type: weather
+  config:
+    endpoint: https://api.example.com?key=${static-test:abc}
+    upstream: ${static-test:abc}
+    password: hunter2
+auth:
+  oauth:
+    github:
+      client_id: my-client
+      client_secret: shh
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	data, err := config.Dump()
+	if err != nil {
+		t.Fatalf("Dump: %v", err)
+	}
+	dump := string(data)
+
+	for _, leaked := range []string{"token-abc", "hunter2", "shh"} {
+		if strings.Contains(dump, leaked) {
+			t.Errorf("dump leaks %q:\n%s", leaked, dump)
+		}
+	}
+	if !strings.Contains(dump, "upstream: '[REDACTED]'") {
+		t.Errorf("resolved secret not redacted:\n%s", dump)
+	}
+	if !strings.Contains(dump, "client_id: my-client") {
+		t.Errorf("non-secret value redacted:\n%s", dump)
+	}
+
+	// Logs mask secrets anywhere in a value
+	var buf bytes.Buffer
+	logger := slog.New(observability.NewRedactingHandler(
+		slog.NewTextHandler(&buf, nil), config.Secrets))
+	logger.With("key", "token-abc").Info("calling upstream", "url", config.Backend.Config["endpoint"],
+		"error", fmt.Errorf("401 for token-abc"))
+	if strings.Contains(buf.String(), "token-abc") {
+		t.Errorf("log leaks the secret: %s", buf.String())
+	}
+}
+
+func TestDebugEndpoints(t *testing.T) {
+	config, err := LoadConfig(writeConfig(t, `
+backend:
+  type: weather
+  config:
+    password: hunter2
+observability:
+  debug:
+    enabled: true
+    allowed_ips: ["127.0.0.1", "10.0.0.0/8"]
+`))
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	serve := func(debug observability.DebugConfig) string {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		address := ln.Addr().String()
+		ln.Close()
+
+		m := observability.NewMetricsServer(address, nil)
+		if err := m.EnableDebug(debug, config.Dump); err != nil {
+			t.Fatalf("EnableDebug: %v", err)
+		}
+		go m.Start()
+		t.Cleanup(func() { m.Stop() })
+		for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+			if conn, err := net.Dial("tcp", address); err == nil {
+				conn.Close()
+				return "http://" + address
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("metrics server did not start")
+			}
+		}
+	}
+	get := func(url string) (int, string) {
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	base := serve(config.Observability.Debug)
+	if status, body := get(base + observability.PathConfig); status != http.StatusOK || strings.Contains(body, "hunter2") {
+		t.Errorf("GET /debug/config = %d:\n%s", status, body)
+	}
+	if status, body := get(base + observability.PathVars); status != http.StatusOK || !strings.Contains(body, "memstats") {
+		t.Errorf("GET /debug/vars = %d", status)
+	}
+	if status, _ := get(base + observability.PathPprof + "goroutine?debug=1"); status != http.StatusOK {
+		t.Errorf("GET /debug/pprof/goroutine = %d", status)
+	}
+
+	remote := serve(observability.DebugConfig{Enabled: true, AllowedIPs: []string{"10.0.0.0/8"}})
+	if status, _ := get(remote + observability.PathPprof); status != http.StatusForbidden {
+		t.Errorf("GET from a disallowed address = %d, want 403", status)
+	}
+
+	config.Observability.Debug.AllowedIPs = []string{"10.0.0.300"}
+	if err := config.Validate(); err == nil {
+		t.Error("invalid allowed IP accepted")
+	}
+}
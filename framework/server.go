@@ -4,21 +4,37 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
 	"time" // ADD THIS IMPORT
 
+	"github.com/SaherElMasry/go-mcp-framework/audit"
 	"github.com/SaherElMasry/go-mcp-framework/auth"
 	"github.com/SaherElMasry/go-mcp-framework/backend"
 	"github.com/SaherElMasry/go-mcp-framework/cache" // ADD THIS IMPORT
+	"github.com/SaherElMasry/go-mcp-framework/canary"
 	"github.com/SaherElMasry/go-mcp-framework/color"
 	"github.com/SaherElMasry/go-mcp-framework/engine"
+	"github.com/SaherElMasry/go-mcp-framework/health"
 	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/paths"
 	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
+	"github.com/SaherElMasry/go-mcp-framework/session"
 	"github.com/SaherElMasry/go-mcp-framework/transport"
 	httpTransport "github.com/SaherElMasry/go-mcp-framework/transport/http"
 	stdioTransport "github.com/SaherElMasry/go-mcp-framework/transport/stdio"
+
+	// Built-in backends
+	_ "github.com/SaherElMasry/go-mcp-framework/backend/filesystem"
+	_ "github.com/SaherElMasry/go-mcp-framework/backend/proxy"
+	_ "github.com/SaherElMasry/go-mcp-framework/backend/rest"
+	_ "github.com/SaherElMasry/go-mcp-framework/backend/sql"
 )
 
 // Server is the main MCP server
@@ -29,16 +45,85 @@ type Server struct {
 	transport  transport.Transport
 	logger     *slog.Logger
 	executor   *engine.Executor
+	handler    transport.Handler
 
 	// Observability
 	metricsServer *observability.MetricsServer
+	health        *health.Registry
+
+	// stopTracing flushes and stops the span exporter
+	stopTracing func(context.Context) error
 
 	authManager *auth.Manager
 
+	// tokenStore keeps OAuth2 tokens; created on first use unless set by
+	// WithTokenStore
+	tokenStore auth.TokenStore
+
+	// authenticators, added by WithAuthenticator, are tried before the
+	// configured inbound credentials
+	authenticators []auth.Authenticator
+
 	// === NEW: Cache support ===
 	cache       cache.Cache         // Cache instance
 	cacheConfig *cache.Config       // Cache configuration
 	keyGen      *cache.KeyGenerator // Key generator
+
+	// cacheFromConfig is set when the config file's cache section is used
+	cacheFromConfig bool
+
+	// cacheStore, set by WithCacheStore, replaces the built-in caches
+	cacheStore cache.Cache
+
+	// cacheIdentity, set by WithCacheIdentity, identifies the callers of
+	// tools cached per principal (default: principalIdentity)
+	cacheIdentity cache.IdentityFunc
+
+	limiter *ratelimit.Limiter
+
+	// auditLog records tool calls when the config enables auditing
+	auditLog *audit.Logger
+
+	// Output filters run on every tool result
+	outputFilters []protocol.OutputFilter
+
+	// recorder records executor event streams for replay
+	recorder *engine.Recorder
+
+	// canaryChecks are added by options; canaries runs them with the
+	// config file's
+	canaryChecks []canary.Check
+	canaries     *canary.Scheduler
+
+	// broadcaster sends notifications to every connected client
+	broadcaster *transport.Broadcaster
+
+	// sessions tracks HTTP clients' MCP sessions, when enabled
+	sessions *session.Manager
+
+	// Service integration
+	listener net.Listener
+	onReady  []func()
+
+	// Config reload
+	logLevel       *slog.LevelVar
+	reloadInterval time.Duration
+	reloadMu       sync.Mutex
+
+	// idGenerator, set by WithIDGenerator, overrides the config's
+	// request ID format
+	idGenerator observability.IDGenerator
+
+	// Secret values resolved from the config file, masked in logs
+	secrets   []string
+	secretsMu sync.RWMutex
+
+	// Option groups, applied over the plain options and the config file
+	transportOptions *transportSettings
+	cacheOptions     *cacheSettings
+
+	// optionErrs are the options that could not be applied
+	optionErrs []error
 }
 
 // NewServer creates a new MCP server
@@ -49,7 +134,12 @@ func NewServer(opts ...Option) *Server {
 	s := &Server{
 		config:      DefaultConfig(),
 		authManager: auth.NewManager(),
+		health:      health.NewRegistry(),
 		logger:      slog.Default(),
+		logLevel:    new(slog.LevelVar),
+		broadcaster: transport.NewBroadcaster(),
+
+		reloadInterval: DefaultReloadInterval,
 		// Cache will be initialized in Initialize() if configured
 	}
 
@@ -57,12 +147,17 @@ func NewServer(opts ...Option) *Server {
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.applyOptionGroups()
 
 	return s
 }
 
 // Initialize initializes the server
 func (s *Server) Initialize(ctx context.Context) error {
+	if err := s.optionsErr(); err != nil {
+		return err
+	}
+
 	// Load config file if specified
 	if s.configFile != "" {
 		config, err := LoadConfig(s.configFile)
@@ -70,6 +165,8 @@ func (s *Server) Initialize(ctx context.Context) error {
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 		s.config = config
+		s.addSecrets(config.secrets)
+		s.reapplyOptionGroups()
 	}
 
 	// Validate configuration
@@ -78,31 +175,88 @@ func (s *Server) Initialize(ctx context.Context) error {
 	}
 
 	// Setup logging
-	s.logger = observability.SetupLogging(s.config.Logging)
+	s.logger = observability.SetupLogging(observability.LoggingConfig{
+		Level:     s.config.Logging.Level,
+		Format:    s.config.Logging.Format,
+		AddSource: s.config.Logging.AddSource,
+		LevelVar:  s.logLevel,
+		Secrets:   s.secretValues,
+	})
+
+	idGenerator := s.idGenerator
+	if idGenerator == nil {
+		idGenerator, _ = observability.IDGeneratorByName(s.config.Observability.RequestIDs)
+	}
+	observability.SetIDGenerator(idGenerator)
 
 	s.logger.Info("initializing server",
 		"backend", s.config.Backend.Type,
 		"transport", s.config.Transport.Type)
 
+	if tracing := s.config.Observability.Tracing; tracing.Enabled {
+		if tracing.ServiceName == "" {
+			tracing.ServiceName = s.config.Paths.AppName
+		}
+		stop, err := observability.SetupTracing(ctx, tracing)
+		if err != nil {
+			return fmt.Errorf("failed to set up tracing: %w", err)
+		}
+		s.stopTracing = stop
+		s.logger.Info("tracing enabled",
+			"endpoint", tracing.Endpoint,
+			"service", tracing.ServiceName)
+	}
+
+	// Options take precedence over the config file's cache section
+	if s.cacheConfig == nil && s.config.Cache != nil {
+		cacheConfig := *s.config.Cache
+		s.cacheConfig = &cacheConfig
+		s.cacheFromConfig = true
+	}
+	if s.cacheConfig == nil && s.cacheStore != nil {
+		s.cacheConfig = cache.DefaultConfig()
+		s.cacheConfig.Enabled = true
+	}
+
 	// === NEW: Initialize cache BEFORE backend ===
 	if s.cacheConfig != nil && s.cacheConfig.Enabled {
-		var err error
-		s.cache, err = cache.New(s.cacheConfig)
-		if err != nil {
-			return fmt.Errorf("failed to create cache: %w", err)
+		if s.cacheConfig.Directory == "" {
+			s.cacheConfig.Directory = s.cacheDirectory()
 		}
 
-		s.keyGen = cache.NewKeyGenerator()
+		if s.cacheStore != nil {
+			if err := s.cacheConfig.Validate(); err != nil {
+				return fmt.Errorf("invalid cache config: %w", err)
+			}
+			s.cache = s.cacheStore
+		} else {
+			// The server closes its cache, so it sweeps by default
+			config := *s.cacheConfig
+			if config.CleanupInterval == 0 {
+				config.CleanupInterval = cache.DefaultCleanupInterval
+			}
+			var err error
+			s.cache, err = cache.New(&config)
+			if err != nil {
+				return fmt.Errorf("failed to create cache: %w", err)
+			}
+		}
+
+		identity := s.cacheIdentity
+		if identity == nil {
+			identity = principalIdentity
+		}
+		s.keyGen = cache.NewKeyGenerator(
+			cache.WithKeyPrefix(s.cacheConfig.KeyPrefix),
+			cache.WithKeyIdentity(identity),
+		)
 
 		s.logger.Info("cache initialized",
 			"type", s.cacheConfig.Type,
 			"ttl", s.cacheConfig.GetTTLDuration(),
-			"max_size", s.cacheConfig.MaxSize)
-
-		// Start background cleanup for memory cache
-		if s.cacheConfig.Type == cache.TypeShort {
-			go s.startCacheCleanup(ctx)
-		}
+			"max_size", s.cacheConfig.MaxSize,
+			"max_bytes", s.cacheConfig.MaxBytes,
+			"key_prefix", s.cacheConfig.KeyPrefix)
 	} else {
 		// No cache configured - use NoOp
 		s.cache = cache.NewNoOpCache()
@@ -112,12 +266,40 @@ func (s *Server) Initialize(ctx context.Context) error {
 	// Initialize backend if not provided
 	if s.backend == nil {
 		var err error
-		s.backend, err = backend.Create(s.config.Backend.Type)
+		if len(s.config.Backend.Mounts) > 0 {
+			s.backend, err = newCompositeBackend(s.config.Backend)
+		} else {
+			s.backend, err = backend.Create(s.config.Backend.Type)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to create backend: %w", err)
 		}
 	}
 
+	if err := s.applyDisabledTools(s.config.Tools.Disabled); err != nil {
+		return err
+	}
+	s.watchToolChanges()
+
+	// Register OAuth endpoints and providers declared in configuration
+	if err := s.configureOAuth(); err != nil {
+		return fmt.Errorf("failed to configure OAuth: %w", err)
+	}
+
+	// Register auth resources declared in configuration
+	if len(s.config.Auth.Resources) > 0 {
+		if err := s.authManager.RegisterResources(s.config.Auth.Resources); err != nil {
+			return fmt.Errorf("failed to register auth resources: %w", err)
+		}
+		s.logger.Info("auth resources registered from config",
+			"count", len(s.config.Auth.Resources))
+	}
+	if len(s.config.Auth.Tools) > 0 {
+		if err := s.authManager.BindTools(s.config.Auth.Tools); err != nil {
+			return fmt.Errorf("failed to bind tool auth resources: %w", err)
+		}
+	}
+
 	// Set auth manager on backend
 	if s.authManager != nil {
 		s.backend.SetAuthManager(s.authManager)
@@ -132,8 +314,9 @@ func (s *Server) Initialize(ctx context.Context) error {
 	}
 
 	// Initialize backend
-	if err := s.backend.Initialize(ctx, s.config.Backend.Config); err != nil {
-		return fmt.Errorf("failed to initialize backend: %w", err)
+	initTimeout := lifecycleTimeout(s.config.Backend.InitTimeout, backend.DefaultInitializeTimeout)
+	if err := backend.InitializeWithTimeout(ctx, s.backend, s.config.Backend.Config, initTimeout); err != nil {
+		return fmt.Errorf("failed to initialize %w", err)
 	}
 
 	// Validate all auth providers
@@ -143,6 +326,12 @@ func (s *Server) Initialize(ctx context.Context) error {
 		}
 	}
 
+	if err := s.configureCanaries(); err != nil {
+		return err
+	}
+
+	s.registerHealthChecks()
+
 	// Initialize streaming executor
 	if s.config.Streaming.Enabled {
 		executorConfig := engine.ExecutorConfig{
@@ -150,13 +339,27 @@ func (s *Server) Initialize(ctx context.Context) error {
 			Timeout:       s.config.Streaming.Timeout,
 			MaxEvents:     s.config.Streaming.MaxEvents,
 			MaxConcurrent: s.config.Streaming.MaxConcurrent,
+			QueueSize:     s.config.Streaming.QueueSize,
+			QueueTimeout:  s.config.Streaming.QueueTimeout,
+			RejectPolicy:  engine.RejectPolicy(s.config.Streaming.RejectPolicy),
+			ResumeBuffer:  s.config.Streaming.ResumeBuffer,
+			ResumeWindow:  s.config.Streaming.ResumeWindow,
+			Overflow:      s.config.Streaming.overflow(),
+			Batching:      s.config.Streaming.batching(),
+			LogLevel:      s.config.Streaming.LogLevel,
 		}
 		s.executor = engine.NewExecutor(executorConfig, s.logger)
 
+		if err := s.configureRecording(); err != nil {
+			return err
+		}
+
 		s.logger.Info("streaming enabled",
 			"buffer_size", executorConfig.BufferSize,
 			"timeout", executorConfig.Timeout,
-			"max_concurrent", executorConfig.MaxConcurrent)
+			"max_concurrent", executorConfig.MaxConcurrent,
+			"queue_size", executorConfig.QueueSize,
+			"reject_policy", executorConfig.RejectPolicy)
 	}
 
 	// Setup observability
@@ -165,6 +368,19 @@ func (s *Server) Initialize(ctx context.Context) error {
 			s.config.Observability.MetricsAddress,
 			s.logger,
 		)
+		s.metricsServer.Handle(health.PathLive, s.health.Handler(health.Liveness))
+		s.metricsServer.Handle(health.PathReady, s.health.Handler(health.Readiness))
+		if s.canaries != nil {
+			s.metricsServer.Handle(canary.PathSchedules, s.canaries.Handler())
+		}
+		if debug := s.config.Observability.Debug; debug.Enabled {
+			if err := s.metricsServer.EnableDebug(debug, s.dumpConfig); err != nil {
+				return err
+			}
+		}
+		if s.cache != nil {
+			observability.SetCacheStats(s.cache.Stats)
+		}
 
 		go func() {
 			if err := s.metricsServer.Start(); err != nil {
@@ -181,6 +397,13 @@ func (s *Server) Initialize(ctx context.Context) error {
 		handler = protocol.NewHandler(s.backend, s.logger)
 	}
 
+	// Send notifications/resources/updated to resources/subscribe clients
+	if h, ok := handler.(*protocol.InstrumentedHandler); ok {
+		h.SetBroadcaster(s.broadcaster)
+	} else if h, ok := handler.(*protocol.Handler); ok {
+		h.SetBroadcaster(s.broadcaster)
+	}
+
 	// === NEW: Configure cache in handler ===
 	if s.cache != nil && s.keyGen != nil {
 		// Type assertion to access SetCache
@@ -193,6 +416,90 @@ func (s *Server) Initialize(ctx context.Context) error {
 			"enabled", s.cacheConfig.Enabled,
 			"type", s.cacheConfig.Type)
 	}
+
+	// Attach provenance metadata to tool results
+	if s.config.Provenance.Enabled {
+		provenance := &protocol.ProvenanceConfig{
+			Server:        s.config.Provenance.Server,
+			ServerVersion: s.config.Provenance.Version,
+		}
+		if h, ok := handler.(*protocol.InstrumentedHandler); ok {
+			h.SetProvenance(provenance)
+		} else if h, ok := handler.(*protocol.Handler); ok {
+			h.SetProvenance(provenance)
+		}
+	}
+
+	// Bound result sizes; tools may declare their own limits even when no
+	// server-wide one is configured
+	if h, ok := handler.(*protocol.InstrumentedHandler); ok {
+		h.SetResultLimits(&s.config.Results)
+	} else if h, ok := handler.(*protocol.Handler); ok {
+		h.SetResultLimits(&s.config.Results)
+	}
+
+	// Paginate list methods
+	if h, ok := handler.(*protocol.InstrumentedHandler); ok {
+		h.SetListPageSize(s.config.ListPageSize)
+	} else if h, ok := handler.(*protocol.Handler); ok {
+		h.SetListPageSize(s.config.ListPageSize)
+	}
+
+	// Install output filters
+	for _, filter := range s.outputFilters {
+		if h, ok := handler.(*protocol.InstrumentedHandler); ok {
+			h.AddOutputFilter(filter)
+		} else if h, ok := handler.(*protocol.Handler); ok {
+			h.AddOutputFilter(filter)
+		}
+	}
+	if len(s.outputFilters) > 0 {
+		s.logger.Info("output filters configured", "count", len(s.outputFilters))
+	}
+
+	// Configure rate limiting
+	if s.config.RateLimit.Enabled {
+		limiter, err := ratelimit.New(&s.config.RateLimit)
+		if err != nil {
+			return fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+		s.limiter = limiter
+
+		if h, ok := handler.(*protocol.InstrumentedHandler); ok {
+			h.SetRateLimiter(limiter)
+		} else if h, ok := handler.(*protocol.Handler); ok {
+			h.SetRateLimiter(limiter)
+		}
+		s.logger.Info("rate limiting enabled",
+			"global_rate", s.config.RateLimit.Global.Rate,
+			"per_client_rate", s.config.RateLimit.PerClient.Rate,
+			"tool_limits", len(s.config.RateLimit.PerTool))
+	}
+
+	// Record tool calls to the audit log
+	if s.config.Audit.Enabled {
+		auditLog, err := audit.New(&s.config.Audit, s.logger)
+		if err != nil {
+			return fmt.Errorf("failed to create audit log: %w", err)
+		}
+		s.auditLog = auditLog
+
+		if h, ok := handler.(*protocol.InstrumentedHandler); ok {
+			h.SetAuditLogger(auditLog)
+		} else if h, ok := handler.(*protocol.Handler); ok {
+			h.SetAuditLogger(auditLog)
+		}
+		sink := s.config.Audit.Sink
+		if sink == "" {
+			sink = audit.SinkStdout
+		}
+		s.logger.Info("audit logging enabled",
+			"sink", sink,
+			"redacted_tools", len(s.config.Audit.Redact))
+	}
+
+	s.handler = handler
+
 	// Setup transport
 	switch s.config.Transport.Type {
 	case "http":
@@ -202,18 +509,53 @@ func (s *Server) Initialize(ctx context.Context) error {
 			WriteTimeout:   s.config.Transport.HTTP.WriteTimeout,
 			MaxRequestSize: s.config.Transport.HTTP.MaxRequestSize,
 			AllowedOrigins: s.config.Transport.HTTP.AllowedOrigins,
+			CORS:           s.config.Transport.HTTP.CORS,
+			Compression:    s.config.Transport.HTTP.Compression,
+			AccessLog:      s.config.Transport.HTTP.AccessLog,
+			EventLimit:     s.config.Streaming.eventLimit(),
+			Heartbeat:      s.config.Streaming.Heartbeat,
+			ChunkedResults: s.config.Streaming.chunkedResults(),
+			TLS:            s.tlsConfig(),
+			TrustedProxies: s.config.Transport.HTTP.TrustedProxies,
 		}
 
-		s.transport = httpTransport.NewHTTPTransport(
+		ht := httpTransport.NewHTTPTransport(
 			handler,
 			httpConfig,
 			s.logger,
 			s.backend,
 			s.executor,
 		)
+		ht.SetRateLimiter(s.limiter)
+		ht.SetAuditLogger(s.auditLog)
+		ht.SetHealth(s.health)
+		ht.SetBroadcaster(s.broadcaster)
+		if sessions := s.config.Transport.HTTP.Sessions; sessions.Enabled {
+			s.sessions = session.NewManager(sessions, s.logger)
+			ht.SetSessions(s.sessions)
+		}
+		if s.listener != nil {
+			ht.SetListener(s.listener)
+		}
+		ht.OnListen(s.notifyReady)
+
+		if err := s.configureInboundAuth(handler, ht); err != nil {
+			return err
+		}
+		if admin := s.config.Transport.HTTP.Admin; admin.Enabled {
+			scope := admin.Scope
+			if scope == "" {
+				scope = DefaultAdminScope
+			}
+			ht.SetAdmin(s.adminHandler(ht), scope)
+		}
+		s.transport = ht
 
 	case "stdio":
-		s.transport = stdioTransport.NewStdioTransport(handler, s.logger)
+		st := stdioTransport.NewStdioTransport(handler, s.logger)
+		st.SetConfig(s.config.Transport.Stdio)
+		st.SetBroadcaster(s.broadcaster)
+		s.transport = st
 
 	default:
 		return fmt.Errorf("unknown transport type: %s", s.config.Transport.Type)
@@ -222,24 +564,35 @@ func (s *Server) Initialize(ctx context.Context) error {
 	return nil
 }
 
-// === NEW: Background cache cleanup ===
-func (s *Server) startCacheCleanup(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if mc, ok := s.cache.(*cache.MemoryCache); ok {
-				removed := mc.CleanExpired()
-				if removed > 0 {
-					s.logger.Debug("cleaned expired cache entries",
-						"count", removed)
-				}
-			}
+// registerHealthChecks registers the server's, backend's and auth
+// providers' health checks
+func (s *Server) registerHealthChecks() {
+	// Answering the probe at all shows the process isn't wedged
+	s.health.RegisterLiveness("server", func(ctx context.Context) error {
+		return nil
+	})
+
+	if reporter, ok := s.backend.(health.Reporter); ok {
+		reporter.RegisterHealthChecks(s.health)
+	}
+
+	if s.canaries != nil {
+		s.canaries.RegisterHealthChecks(s.health)
+	}
+
+	if s.authManager == nil {
+		return
+	}
+	for _, name := range s.authManager.List() {
+		provider, err := s.authManager.Get(name)
+		if err != nil {
+			continue
 		}
+		if reporter, ok := provider.(health.Reporter); ok {
+			reporter.RegisterHealthChecks(s.health)
+			continue
+		}
+		s.health.RegisterReadiness("auth:"+name, provider.Validate)
 	}
 }
 
@@ -272,11 +625,29 @@ func (s *Server) Run(ctx context.Context) error {
 		cancel()
 	}()
 
+	// Apply config file changes without restarting
+	if s.configFile != "" && s.reloadInterval >= 0 {
+		go s.watchConfig(ctx)
+	}
+
+	if s.canaries != nil {
+		go s.canaries.Run(ctx)
+	}
+
+	if s.sessions != nil {
+		go s.sessions.Run(ctx)
+	}
+
 	// Run transport
 	s.logger.Info("server starting",
 		"transport", s.config.Transport.Type,
 		"address", s.getAddress())
 
+	// The HTTP transport reports readiness once it is listening
+	if _, ok := s.transport.(*httpTransport.HTTPTransport); !ok {
+		s.notifyReady()
+	}
+
 	if err := s.transport.Run(ctx); err != nil {
 		return fmt.Errorf("transport error: %w", err)
 	}
@@ -284,6 +655,11 @@ func (s *Server) Run(ctx context.Context) error {
 	// Cleanup
 	s.logger.Info("server shutting down")
 
+	// Fail streaming calls still waiting for a worker
+	if s.executor != nil {
+		s.executor.Close()
+	}
+
 	// === NEW: Close cache ===
 	if s.cache != nil {
 		if err := s.cache.Close(); err != nil {
@@ -298,17 +674,52 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}
 
-	if err := s.backend.Close(); err != nil {
+	closeTimeout := lifecycleTimeout(s.config.Backend.CloseTimeout, backend.DefaultCloseTimeout)
+	if err := backend.CloseWithTimeout(s.backend, closeTimeout); err != nil {
 		s.logger.Error("backend close error", "error", err)
 	}
 
+	// Write the last audit events
+	if err := s.auditLog.Close(); err != nil {
+		s.logger.Error("audit log close error", "error", err)
+	}
+
 	if s.metricsServer != nil {
 		s.metricsServer.Stop()
 	}
 
+	// Export the spans of the last requests
+	if s.stopTracing != nil {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := s.stopTracing(flushCtx); err != nil {
+			s.logger.Error("tracing shutdown error", "error", err)
+		}
+		cancel()
+	}
+
 	return nil
 }
 
+// HTTPHandler returns the MCP HTTP endpoints as an http.Handler for embedding
+// into an existing web server. Initialize must be called first, and the
+// transport type must be "http".
+func (s *Server) HTTPHandler() (http.Handler, error) {
+	ht, ok := s.transport.(*httpTransport.HTTPTransport)
+	if !ok {
+		return nil, fmt.Errorf("HTTP handler unavailable: transport is %q (call Initialize with transport \"http\")", s.config.Transport.Type)
+	}
+	return ht.Handler(), nil
+}
+
+// HTTPRoutes returns the endpoints served by HTTPHandler
+func (s *Server) HTTPRoutes() []httpTransport.Route {
+	ht, ok := s.transport.(*httpTransport.HTTPTransport)
+	if !ok {
+		return nil
+	}
+	return ht.Routes()
+}
+
 // getAddress returns the server address for logging
 func (s *Server) getAddress() string {
 	switch s.config.Transport.Type {
@@ -342,6 +753,82 @@ func (s *Server) RegisterBackend(b backend.ServerBackend) {
 		"style", "v0.2.0-full")
 }
 
+// configureRecording sets up event stream recording on the executor
+func (s *Server) configureRecording() error {
+	if s.recorder == nil && s.config.Streaming.Record.Enabled {
+		record := s.config.Streaming.Record
+		capacity := record.Capacity
+		if capacity <= 0 {
+			capacity = 100
+		}
+
+		var store engine.RecordingStore = engine.NewMemoryRecordingStore(capacity)
+		if record.Directory != "" {
+			disk, err := engine.NewDiskRecordingStore(record.Directory, capacity)
+			if err != nil {
+				return err
+			}
+			store = disk
+		}
+		s.recorder = engine.NewRecorder(store, record.Tools...)
+	}
+	if s.recorder == nil {
+		return nil
+	}
+
+	s.executor.SetRecorder(s.recorder)
+	s.logger.Warn("event stream recording enabled; recorded events may contain sensitive data",
+		"path", httpTransport.PathRecordings)
+	return nil
+}
+
+// configureCanaries creates the canary scheduler when canaries are
+// configured
+func (s *Server) configureCanaries() error {
+	checks := append(append([]canary.Check(nil), s.config.Canaries...), s.canaryChecks...)
+	if len(checks) == 0 {
+		return nil
+	}
+
+	s.canaries = canary.NewScheduler(s.backend, s.logger)
+	for _, check := range checks {
+		if _, ok := s.backend.GetTool(check.Tool); !ok {
+			return fmt.Errorf("canary %q: tool not found: %s", check.Name, check.Tool)
+		}
+		if err := s.canaries.Add(check); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("canaries configured", "count", len(checks))
+	return nil
+}
+
+// lifecycleTimeout resolves a configured backend timeout: 0 means def,
+// negative means no limit
+func lifecycleTimeout(configured, def time.Duration) time.Duration {
+	switch {
+	case configured < 0:
+		return 0
+	case configured == 0:
+		return def
+	}
+	return configured
+}
+
+// newCompositeBackend creates the registered backends a config mounts
+func newCompositeBackend(config BackendConfig) (backend.ServerBackend, error) {
+	mounts := make([]backend.Mount, 0, len(config.Mounts))
+	for _, m := range config.Mounts {
+		b, err := backend.Create(m.Type)
+		if err != nil {
+			return nil, err
+		}
+		mounts = append(mounts, backend.Mount{Backend: b, Prefix: m.Prefix, Config: m.Config})
+	}
+	return backend.NewCompositeBackend(config.Type, mounts...), nil
+}
+
 // === NEW: Public Getters ===
 
 // GetBackend returns the current backend
@@ -350,6 +837,7 @@ func (s *Server) GetBackend() backend.ServerBackend {
 }
 
 // GetExecutor returns the streaming executor
+// Use its Stats method to inspect worker and queue usage.
 func (s *Server) GetExecutor() *engine.Executor {
 	return s.executor
 }
@@ -359,6 +847,67 @@ func (s *Server) GetAuthManager() *auth.Manager {
 	return s.authManager
 }
 
+// GetHealth returns the health check registry
+// Checks registered before Run are served on /health/live and /health/ready.
+func (s *Server) GetHealth() *health.Registry {
+	return s.health
+}
+
+// RevokeTokens revokes the OAuth tokens held by the named auth providers,
+// or by every provider when none are named. Use it as an admin action
+// when credentials may have leaked.
+func (s *Server) RevokeTokens(ctx context.Context, providers ...string) error {
+	if len(providers) == 0 {
+		return s.authManager.RevokeAll(ctx)
+	}
+
+	for _, name := range providers {
+		if err := s.authManager.RevokeToken(ctx, name); err != nil {
+			return err
+		}
+		s.logger.Info("revoked tokens", "provider", name)
+	}
+	return nil
+}
+
+// RotateTokenKey re-encrypts the stored OAuth tokens with newKey
+// Set OAUTH_ENCRYPTION_KEY (or auth.token_store.encryption_key) to newKey
+// before the next start.
+func (s *Server) RotateTokenKey(ctx context.Context, oldKey, newKey string) error {
+	store, err := s.newTokenStore()
+	if err != nil {
+		return err
+	}
+	rotator, ok := store.(auth.KeyRotator)
+	if !ok {
+		return fmt.Errorf("token store %T does not encrypt tokens with a key", store)
+	}
+	if err := rotator.RotateKey(ctx, oldKey, newKey); err != nil {
+		return fmt.Errorf("rotate token key: %w", err)
+	}
+	s.logger.Info("rotated OAuth token encryption key")
+	return nil
+}
+
+// DeviceLogin authenticates the named OAuth provider with the device
+// authorization grant, for headless deployments without a browser
+// redirect. prompt shows the user where to approve the login.
+func (s *Server) DeviceLogin(ctx context.Context, providerName string, prompt func(auth.DeviceAuthorization)) error {
+	provider, err := s.authManager.Get(providerName)
+	if err != nil {
+		return err
+	}
+	oauthProvider, ok := provider.(*auth.OAuth2Provider)
+	if !ok {
+		return fmt.Errorf("provider %q is not an OAuth2 provider", providerName)
+	}
+	if err := oauthProvider.DeviceLogin(ctx, prompt); err != nil {
+		return err
+	}
+	s.logger.Info("device login complete", "provider", providerName)
+	return nil
+}
+
 // GetLogger returns the logger
 func (s *Server) GetLogger() *slog.Logger {
 	return s.logger
@@ -380,3 +929,201 @@ func (s *Server) GetKeyGenerator() *cache.KeyGenerator {
 func (s *Server) GetCacheConfig() *cache.Config {
 	return s.cacheConfig
 }
+
+// configureInboundAuth wires request authentication and per-tool access policies
+func (s *Server) configureInboundAuth(handler transport.Handler, ht *httpTransport.HTTPTransport) error {
+	cfg := s.config.Transport.HTTP.Auth
+
+	if len(cfg.Tools) > 0 {
+		policy := auth.NewAccessPolicy(cfg.Tools)
+		if h, ok := handler.(*protocol.InstrumentedHandler); ok {
+			h.SetAccessPolicy(policy)
+		} else if h, ok := handler.(*protocol.Handler); ok {
+			h.SetAccessPolicy(policy)
+		}
+		ht.SetAccessPolicy(policy)
+	}
+
+	if !cfg.Enabled && len(s.authenticators) == 0 {
+		return nil
+	}
+
+	chain := auth.ChainAuthenticator(append([]auth.Authenticator(nil), s.authenticators...))
+	if cfg.Enabled {
+		authn, err := auth.NewInboundAuthenticator(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create inbound authenticator: %w", err)
+		}
+		chain = append(chain, authn)
+	}
+	ht.SetAuthenticator(chain)
+
+	s.logger.Info("inbound authentication enabled",
+		"authenticators", len(s.authenticators),
+		"api_keys", len(cfg.APIKeys.Keys),
+		"bearer_tokens", len(cfg.Bearer.Tokens),
+		"introspection", cfg.Introspection.Endpoint != "",
+		"jwt_issuers", len(cfg.JWT.Issuers),
+		"tool_policies", len(cfg.Tools))
+
+	return nil
+}
+
+// newTokenStore returns the OAuth2 token store auth.token_store selects
+// OAuth providers share it. The key comes from encryption_key or
+// $OAUTH_ENCRYPTION_KEY; a random key is generated (and tokens will not
+// survive restarts) when both are unset. Tokens left in the file store
+// are moved to any other persistent store.
+func (s *Server) newTokenStore() (auth.TokenStore, error) {
+	if s.tokenStore != nil {
+		return s.tokenStore, nil
+	}
+
+	cfg := s.config.Auth.TokenStore
+	if cfg.EncryptionKey == "" {
+		cfg.EncryptionKey = os.Getenv("OAUTH_ENCRYPTION_KEY")
+	}
+	generatedKey := false
+	if cfg.EncryptionKey == "" && cfg.Encrypted() {
+		// Generate a key if not provided (for development)
+		var err error
+		cfg.EncryptionKey, err = auth.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("generate encryption key: %w", err)
+		}
+		generatedKey = true
+		s.logger.Warn("using generated encryption key - set OAUTH_ENCRYPTION_KEY for production")
+	}
+
+	tokenDir, err := s.tokenDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("resolve token directory: %w", err)
+	}
+	if cfg.Directory == "" {
+		cfg.Directory = tokenDir
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	store, err := auth.NewTokenStore(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create token store: %w", err)
+	}
+
+	switch cfg.Type {
+	case "", auth.TokenStoreFile, auth.TokenStoreMemory:
+	default:
+		// A generated key can't decrypt files written before
+		if !generatedKey {
+			n, err := auth.MigrateFileTokens(ctx, tokenDir, cfg.EncryptionKey, store)
+			if n > 0 {
+				s.logger.Info("migrated OAuth tokens from the file store", "store", cfg.Type, "tokens", n)
+			}
+			if err != nil {
+				s.logger.Warn("some OAuth tokens could not be migrated", "store", cfg.Type, "error", err)
+			}
+		}
+	}
+
+	s.tokenStore = store
+	return store, nil
+}
+
+// configureOAuth registers OAuth endpoints and providers declared in configuration
+func (s *Server) configureOAuth() error {
+	cfg := s.config.Auth
+
+	for name, endpoint := range cfg.OAuthEndpoints {
+		if err := auth.RegisterOAuthEndpoint(name, endpoint); err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.OAuth) == 0 {
+		return nil
+	}
+
+	tokenStore, err := s.newTokenStore()
+	if err != nil {
+		return err
+	}
+
+	for name, oc := range cfg.OAuth {
+		if oc.Endpoint == "" && oc.AuthURL == "" && oc.DeviceAuthURL == "" {
+			oc.Endpoint = name
+		}
+		resolved, err := oc.ResolveEndpoint()
+		if err != nil {
+			return fmt.Errorf("oauth provider %q: %w", name, err)
+		}
+
+		provider := auth.NewOAuth2Provider(name, resolved, tokenStore)
+		if err := s.authManager.Register(name, provider); err != nil {
+			return fmt.Errorf("oauth provider %q: %w", name, err)
+		}
+
+		s.logger.Info("OAuth2 provider configured from config",
+			"provider", name,
+			"auth_url", resolved.AuthURL,
+			"scopes", resolved.Scopes)
+	}
+
+	return nil
+}
+
+// cacheDirectory returns the file cache directory for the configured paths
+func (s *Server) cacheDirectory() string {
+	if s.config == nil {
+		return cache.DefaultDirectory()
+	}
+	dir, err := s.config.Paths.Cache()
+	if err != nil {
+		return cache.DefaultDirectory()
+	}
+	return filepath.Join(dir, "responses")
+}
+
+// tlsConfig returns the HTTPS configuration, keeping ACME certificates
+// in the state directory unless configured otherwise
+func (s *Server) tlsConfig() httpTransport.TLSConfig {
+	config := s.config.Transport.HTTP.TLS
+	if config.ACME.Enabled && config.ACME.CacheDir == "" {
+		if dir, err := s.config.Paths.State(); err == nil {
+			config.ACME.CacheDir = filepath.Join(dir, "acme")
+		}
+	}
+	return config
+}
+
+// tokenDirectory returns the OAuth2 token directory for the configured paths
+func (s *Server) tokenDirectory() (string, error) {
+	if s.config == nil {
+		return paths.TokenDir("")
+	}
+	return s.config.Paths.Tokens()
+}
+
+// UseListener makes the HTTP transport serve on l instead of binding its address
+// Must be called before Initialize/Run
+func (s *Server) UseListener(l net.Listener) {
+	s.listener = l
+}
+
+// OnReady registers a callback invoked once the transport is accepting requests
+// Must be called before Run
+func (s *Server) OnReady(fn func()) {
+	s.onReady = append(s.onReady, fn)
+}
+
+// notifyReady invokes the registered readiness callbacks
+func (s *Server) notifyReady() {
+	for _, fn := range s.onReady {
+		fn()
+	}
+}
+
+// GetRateLimiter returns the rate limiter (nil if rate limiting is disabled)
+func (s *Server) GetRateLimiter() *ratelimit.Limiter {
+	return s.limiter
+}
@@ -0,0 +1,100 @@
+package framework
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// toolRegistry is implemented by backends whose tools can be registered
+// while the server runs (backends embedding BaseBackend)
+type toolRegistry interface {
+	AddTool(tool backend.ToolDefinition, handler backend.ToolHandler) error
+	ReplaceTool(tool backend.ToolDefinition, handler backend.ToolHandler) error
+	RemoveTool(name string) bool
+}
+
+// AddTool registers a tool while the server runs
+// tools/list reflects it immediately and connected clients are sent
+// notifications/tools/list_changed.
+func (s *Server) AddTool(tool backend.ToolDefinition, handler backend.ToolHandler) error {
+	registry, err := s.toolRegistry()
+	if err != nil {
+		return err
+	}
+	return registry.AddTool(tool, handler)
+}
+
+// ReplaceTool swaps the definition and handler of a registered tool
+// Cached results are dropped, since they came from the old handler.
+func (s *Server) ReplaceTool(tool backend.ToolDefinition, handler backend.ToolHandler) error {
+	registry, err := s.toolRegistry()
+	if err != nil {
+		return err
+	}
+	if err := registry.ReplaceTool(tool, handler); err != nil {
+		return err
+	}
+	s.clearCache()
+	return nil
+}
+
+// RemoveTool unregisters a tool
+func (s *Server) RemoveTool(name string) error {
+	registry, err := s.toolRegistry()
+	if err != nil {
+		return err
+	}
+	if !registry.RemoveTool(name) {
+		return fmt.Errorf("tool not found: %s", name)
+	}
+	s.clearCache()
+	return nil
+}
+
+// toolRegistry returns the backend as a toolRegistry
+func (s *Server) toolRegistry() (toolRegistry, error) {
+	if s.backend == nil {
+		return nil, fmt.Errorf("no backend configured")
+	}
+	registry, ok := s.backend.(toolRegistry)
+	if !ok {
+		return nil, fmt.Errorf("backend %s does not support registering tools at runtime", s.backend.Name())
+	}
+	return registry, nil
+}
+
+// clearCache drops cached tool results
+func (s *Server) clearCache() {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Clear(context.Background()); err != nil {
+		s.logger.Warn("failed to clear cache", "error", err)
+	}
+}
+
+// watchToolChanges sends notifications/tools/list_changed to connected
+// clients whenever the backend's tool set changes
+func (s *Server) watchToolChanges() {
+	notifier, ok := s.backend.(backend.ToolChangeNotifier)
+	if !ok {
+		return
+	}
+
+	message, err := json.Marshal(protocol.Notification{
+		JSONRPC: "2.0",
+		Method:  protocol.NotificationToolsListChanged,
+	})
+	if err != nil {
+		return
+	}
+
+	notifier.OnToolsChanged(func() {
+		delivered := s.broadcaster.Broadcast(message)
+		s.logger.Debug("tool list changed", "clients_notified", delivered)
+	})
+}
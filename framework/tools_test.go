@@ -0,0 +1,53 @@
+package framework
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
+)
+
+func TestServer_DynamicTools(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(reloadBaseConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(WithBackend(backend.NewBaseBackend("test")), WithConfigFile(path))
+	if err := s.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize: %v", err)
+	}
+
+	var notifications []string
+	s.broadcaster.Subscribe(transport.NotifierFunc(func(message []byte) error {
+		notifications = append(notifications, string(message))
+		return nil
+	}))
+
+	handler := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return "pong", nil
+	}
+	if err := s.AddTool(backend.NewTool("ping").Build(), handler); err != nil {
+		t.Fatalf("AddTool: %v", err)
+	}
+
+	resp, err := s.handler.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`), "stdio")
+	if err != nil || !strings.Contains(string(resp), `"name":"ping"`) {
+		t.Errorf("tools/list = %s, %v", resp, err)
+	}
+
+	if err := s.RemoveTool("ping"); err != nil {
+		t.Fatalf("RemoveTool: %v", err)
+	}
+	if err := s.RemoveTool("ping"); err == nil {
+		t.Error("removing a missing tool succeeded")
+	}
+
+	if len(notifications) != 2 || !strings.Contains(notifications[0], `"method":"notifications/tools/list_changed"`) {
+		t.Errorf("notifications = %v", notifications)
+	}
+}
@@ -0,0 +1,241 @@
+// Package health runs liveness and readiness checks
+//
+// Backends, auth providers and the server register checks in a Registry;
+// the HTTP transport serves them on /health/live and /health/ready, and the
+// metrics server does too, so stdio servers can be probed as well.
+//
+// Liveness checks answer "should this process be restarted?" and should
+// only fail when it is wedged. Readiness checks answer "can this process
+// serve traffic?": upstream APIs reachable, database pinged, tokens valid.
+// A check returning an error marked with Degraded reports a problem without
+// failing the probe.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// Kind selects the probe a check belongs to
+type Kind string
+
+const (
+	Liveness  Kind = "liveness"
+	Readiness Kind = "readiness"
+)
+
+// Status is the outcome of a check or probe
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusDegraded Status = "degraded"
+	StatusFailing  Status = "failing"
+)
+
+// DefaultTimeout bounds each check
+const DefaultTimeout = 5 * time.Second
+
+// Check reports a component's health: nil is healthy
+type Check func(ctx context.Context) error
+
+// degradedError marks an error as degraded rather than failing
+type degradedError struct {
+	err error
+}
+
+func (e *degradedError) Error() string { return e.err.Error() }
+func (e *degradedError) Unwrap() error { return e.err }
+
+// Degraded marks err as a degradation: reported, but not failing the probe
+func Degraded(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &degradedError{err: err}
+}
+
+// IsDegraded reports whether err was marked with Degraded
+func IsDegraded(err error) bool {
+	var d *degradedError
+	return errors.As(err, &d)
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name      string        `json:"name"`
+	Status    Status        `json:"status"`
+	Latency   time.Duration `json:"-"`
+	LatencyMS float64       `json:"latency_ms"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Report is the outcome of a probe
+type Report struct {
+	Status    Status    `json:"status"`
+	Checks    []Result  `json:"checks"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Reporter is implemented by components that provide their own checks
+// BaseBackend implements it, so the server picks up backend checks
+// automatically.
+type Reporter interface {
+	RegisterHealthChecks(r *Registry)
+}
+
+// Registry holds registered checks
+type Registry struct {
+	mu      sync.RWMutex
+	checks  map[Kind]map[string]Check
+	timeout time.Duration
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		checks: map[Kind]map[string]Check{
+			Liveness:  {},
+			Readiness: {},
+		},
+		timeout: DefaultTimeout,
+	}
+}
+
+// SetTimeout changes how long each check may run
+func (r *Registry) SetTimeout(timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeout = timeout
+}
+
+// Register adds a check; registering an existing name replaces it
+func (r *Registry) Register(kind Kind, name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.checks[kind] == nil {
+		r.checks[kind] = make(map[string]Check)
+	}
+	r.checks[kind][name] = check
+}
+
+// RegisterLiveness adds a liveness check
+func (r *Registry) RegisterLiveness(name string, check Check) {
+	r.Register(Liveness, name, check)
+}
+
+// RegisterReadiness adds a readiness check
+func (r *Registry) RegisterReadiness(name string, check Check) {
+	r.Register(Readiness, name, check)
+}
+
+// Unregister removes a check
+func (r *Registry) Unregister(kind Kind, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks[kind], name)
+}
+
+// Run runs every check of kind concurrently
+// The probe fails if any check fails, and is degraded if any is degraded.
+func (r *Registry) Run(ctx context.Context, kind Kind) Report {
+	r.mu.RLock()
+	checks := make(map[string]Check, len(r.checks[kind]))
+	for name, check := range r.checks[kind] {
+		checks[name] = check
+	}
+	timeout := r.timeout
+	r.mu.RUnlock()
+
+	results := make([]Result, 0, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := runCheck(ctx, name, check, timeout)
+			observability.RecordHealthCheck(name, string(kind), statusValue(result.Status), result.Latency)
+
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := Report{Status: StatusOK, Checks: results, Timestamp: time.Now()}
+	for _, result := range results {
+		switch result.Status {
+		case StatusFailing:
+			report.Status = StatusFailing
+		case StatusDegraded:
+			if report.Status == StatusOK {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+	return report
+}
+
+// runCheck runs one check with a timeout, recovering panics
+func runCheck(ctx context.Context, name string, check Check, timeout time.Duration) Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("check panicked: %v", p)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", timeout)
+	}
+
+	latency := time.Since(start)
+	result := Result{
+		Name:      name,
+		Status:    StatusOK,
+		Latency:   latency,
+		LatencyMS: float64(latency.Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Error = err.Error()
+		result.Status = StatusFailing
+		if IsDegraded(err) {
+			result.Status = StatusDegraded
+		}
+	}
+	return result
+}
+
+// statusValue maps a status to the health check gauge value
+func statusValue(s Status) float64 {
+	switch s {
+	case StatusOK:
+		return 1
+	case StatusDegraded:
+		return 0.5
+	default:
+		return 0
+	}
+}
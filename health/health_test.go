@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	degraded := func(ctx context.Context) error { return Degraded(errors.New("slow")) }
+	failing := func(ctx context.Context) error { return errors.New("down") }
+
+	tests := []struct {
+		name   string
+		checks map[string]Check
+		want   Status
+	}{
+		{"empty", nil, StatusOK},
+		{"all ok", map[string]Check{"a": ok, "b": ok}, StatusOK},
+		{"degraded", map[string]Check{"a": ok, "b": degraded}, StatusDegraded},
+		{"failing wins", map[string]Check{"a": degraded, "b": failing}, StatusFailing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			for name, check := range tt.checks {
+				r.RegisterReadiness(name, check)
+			}
+
+			report := r.Run(context.Background(), Readiness)
+			if report.Status != tt.want {
+				t.Errorf("status = %s, want %s", report.Status, tt.want)
+			}
+			if len(report.Checks) != len(tt.checks) {
+				t.Errorf("checks = %d, want %d", len(report.Checks), len(tt.checks))
+			}
+		})
+	}
+}
+
+func TestRegistry_RunKinds(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterLiveness("live", func(ctx context.Context) error { return nil })
+	r.RegisterReadiness("ready", func(ctx context.Context) error { return errors.New("down") })
+
+	if report := r.Run(context.Background(), Liveness); report.Status != StatusOK {
+		t.Errorf("liveness = %s, want ok", report.Status)
+	}
+	if report := r.Run(context.Background(), Readiness); report.Status != StatusFailing {
+		t.Errorf("readiness = %s, want failing", report.Status)
+	}
+
+	r.Unregister(Readiness, "ready")
+	if report := r.Run(context.Background(), Readiness); report.Status != StatusOK {
+		t.Errorf("readiness after unregister = %s, want ok", report.Status)
+	}
+}
+
+func TestRegistry_TimeoutAndPanic(t *testing.T) {
+	r := NewRegistry()
+	r.SetTimeout(20 * time.Millisecond)
+	r.RegisterReadiness("hang", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	r.RegisterReadiness("panic", func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	start := time.Now()
+	report := r.Run(context.Background(), Readiness)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Run took %s, want the check timeout", elapsed)
+	}
+
+	if report.Status != StatusFailing {
+		t.Errorf("status = %s, want failing", report.Status)
+	}
+	for _, result := range report.Checks {
+		if result.Status != StatusFailing || result.Error == "" {
+			t.Errorf("%s: status = %s, error = %q", result.Name, result.Status, result.Error)
+		}
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterLiveness("live", func(ctx context.Context) error { return nil })
+	r.RegisterReadiness("db", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	mux := http.NewServeMux()
+	r.Mount(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathLive, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("live status = %d, want 200", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathReady, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("ready status = %d, want 503", w.Code)
+	}
+
+	var report Report
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(report.Checks) != 1 || report.Checks[0].Name != "db" || report.Checks[0].Error != "connection refused" {
+		t.Errorf("checks = %+v", report.Checks)
+	}
+}
@@ -0,0 +1,36 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Endpoint paths of the probes
+const (
+	PathLive  = "/health/live"
+	PathReady = "/health/ready"
+)
+
+// Handler serves the report of a probe as JSON
+// It responds 200 when the probe is ok or degraded and 503 when failing.
+func (r *Registry) Handler(kind Kind) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Run(req.Context(), kind)
+
+		status := http.StatusOK
+		if report.Status == StatusFailing {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// Mount registers the liveness and readiness endpoints on mux
+func (r *Registry) Mount(mux *http.ServeMux) {
+	mux.Handle(PathLive, r.Handler(Liveness))
+	mux.Handle(PathReady, r.Handler(Readiness))
+}
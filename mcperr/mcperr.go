@@ -0,0 +1,199 @@
+// Package mcperr defines typed errors that tools return to signal why they
+// failed, with machine-readable codes and retry hints
+//
+// The protocol handler maps them to JSON-RPC errors whose data payload
+// carries the code, whether the call is worth retrying and, for rate
+// limits, how long to wait:
+//
+//	{"code": -32029, "message": "Rate limit exceeded",
+//	 "data": {"code": "rate_limited", "message": "...", "retryable": true, "retry_after": 30}}
+package mcperr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Code is a machine-readable error category
+type Code string
+
+const (
+	// CodeNotFound means the requested entity does not exist
+	CodeNotFound Code = "not_found"
+
+	// CodePermissionDenied means the caller may not perform the operation
+	CodePermissionDenied Code = "permission_denied"
+
+	// CodeRateLimited means the caller (or the server, upstream) is throttled
+	CodeRateLimited Code = "rate_limited"
+
+	// CodeTimeout means the operation did not complete in time
+	CodeTimeout Code = "timeout"
+
+	// CodeUpstream means a dependency (API, database) failed
+	CodeUpstream Code = "upstream_error"
+
+	// CodeInternal is any other failure
+	CodeInternal Code = "internal"
+)
+
+// Retryable reports whether a call failing with this code may succeed if retried
+func (c Code) Retryable() bool {
+	switch c {
+	case CodeRateLimited, CodeTimeout, CodeUpstream:
+		return true
+	default:
+		return false
+	}
+}
+
+// Error is a categorized error
+type Error struct {
+	Code    Code
+	Message string
+
+	// RetryAfter is how long the client should wait before retrying (0 = unknown)
+	RetryAfter time.Duration
+
+	// Details are extra machine-readable fields sent to the client
+	Details map[string]interface{}
+
+	// Err is the underlying cause (not sent to the client)
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors with the same code, so errors.Is(err, mcperr.ErrNotFound) works
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Message == "" && t.Code == e.Code
+}
+
+// WithDetail adds a machine-readable field to the error
+func (e *Error) WithDetail(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Sentinels for errors.Is
+var (
+	ErrNotFound         = &Error{Code: CodeNotFound}
+	ErrPermissionDenied = &Error{Code: CodePermissionDenied}
+	ErrRateLimited      = &Error{Code: CodeRateLimited}
+	ErrTimeout          = &Error{Code: CodeTimeout}
+	ErrUpstream         = &Error{Code: CodeUpstream}
+)
+
+// ============================================================
+// Constructors
+// ============================================================
+
+// New creates an error with the given code
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap creates an error with the given code and cause
+func Wrap(err error, code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// Recovered creates an internal error from a value returned by recover
+// The panic and its stack are kept in a *PanicError cause, for logs; the
+// client sees the message and a "panic" detail.
+func Recovered(value interface{}, format string, args ...interface{}) *Error {
+	cause := &PanicError{Value: value, Stack: debug.Stack()}
+	return Wrap(cause, CodeInternal, format, args...).WithDetail("panic", true)
+}
+
+// PanicError is a recovered panic
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// NotFound creates a not_found error
+func NotFound(format string, args ...interface{}) *Error {
+	return New(CodeNotFound, format, args...)
+}
+
+// PermissionDenied creates a permission_denied error
+func PermissionDenied(format string, args ...interface{}) *Error {
+	return New(CodePermissionDenied, format, args...)
+}
+
+// RateLimited creates a rate_limited error with a retry hint
+func RateLimited(retryAfter time.Duration, format string, args ...interface{}) *Error {
+	e := New(CodeRateLimited, format, args...)
+	e.RetryAfter = retryAfter
+	return e
+}
+
+// Timeout creates a timeout error
+func Timeout(format string, args ...interface{}) *Error {
+	return New(CodeTimeout, format, args...)
+}
+
+// Upstream creates an upstream_error wrapping the dependency's error
+func Upstream(err error, format string, args ...interface{}) *Error {
+	return Wrap(err, CodeUpstream, format, args...)
+}
+
+// ============================================================
+// Inspection
+// ============================================================
+
+// As returns the categorized error in err's chain
+// context.DeadlineExceeded is reported as a timeout.
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Code: CodeTimeout, Message: "operation timed out", Err: err}, true
+	}
+	return nil, false
+}
+
+// CodeOf returns the code of err, or CodeInternal for uncategorized errors
+func CodeOf(err error) Code {
+	if e, ok := As(err); ok {
+		return e.Code
+	}
+	return CodeInternal
+}
+
+// IsRetryable reports whether the operation that returned err may be retried
+func IsRetryable(err error) bool {
+	return CodeOf(err).Retryable()
+}
+
+// RetryAfter returns the retry hint carried by err, if any
+func RetryAfter(err error) (time.Duration, bool) {
+	if e, ok := As(err); ok && e.RetryAfter > 0 {
+		return e.RetryAfter, true
+	}
+	return 0, false
+}
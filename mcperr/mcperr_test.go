@@ -0,0 +1,71 @@
+package mcperr_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+func TestErrorsIs(t *testing.T) {
+	err := fmt.Errorf("lookup: %w", mcperr.NotFound("user %d", 42))
+
+	if !errors.Is(err, mcperr.ErrNotFound) {
+		t.Error("expected errors.Is(err, ErrNotFound)")
+	}
+	if errors.Is(err, mcperr.ErrTimeout) {
+		t.Error("not_found error matched ErrTimeout")
+	}
+	if mcperr.IsRetryable(err) {
+		t.Error("not_found should not be retryable")
+	}
+}
+
+func TestRetryHints(t *testing.T) {
+	err := mcperr.RateLimited(30*time.Second, "GitHub API quota exhausted")
+
+	if !mcperr.IsRetryable(err) {
+		t.Error("rate_limited should be retryable")
+	}
+	if d, ok := mcperr.RetryAfter(err); !ok || d != 30*time.Second {
+		t.Errorf("RetryAfter = %v, %v", d, ok)
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	tests := []struct {
+		err  error
+		want mcperr.Code
+	}{
+		{mcperr.Upstream(cause, "query failed"), mcperr.CodeUpstream},
+		{context.DeadlineExceeded, mcperr.CodeTimeout},
+		{cause, mcperr.CodeInternal},
+	}
+	for _, tt := range tests {
+		if got := mcperr.CodeOf(tt.err); got != tt.want {
+			t.Errorf("CodeOf(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+
+	if !errors.Is(mcperr.Upstream(cause, "query failed"), cause) {
+		t.Error("Upstream error should wrap its cause")
+	}
+}
+
+func TestRecovered(t *testing.T) {
+	var err error = mcperr.Recovered("boom", "tool %s panicked", "divide")
+
+	var panicErr *mcperr.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Fatalf("cause = %+v", panicErr)
+	}
+	e, _ := mcperr.As(err)
+	if e.Code != mcperr.CodeInternal || e.Message != "tool divide panicked" || e.Details["panic"] != true || mcperr.IsRetryable(err) {
+		t.Errorf("error = %+v", e)
+	}
+}
@@ -0,0 +1,91 @@
+package observability
+
+import (
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cacheCollector exports the statistics of the server's cache at scrape
+// time, so evictions the cache makes on its own are counted too
+type cacheCollector struct {
+	mu    sync.RWMutex
+	stats func() cache.CacheStats
+
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	sets      *prometheus.Desc
+	evictions *prometheus.Desc
+	entries   *prometheus.Desc
+	capacity  *prometheus.Desc
+	hitRatio  *prometheus.Desc
+	bytes     *prometheus.Desc
+	maxBytes  *prometheus.Desc
+	ratio     *prometheus.Desc
+	sweeps    *prometheus.Desc
+	swept     *prometheus.Desc
+	lastSweep *prometheus.Desc
+}
+
+var cacheStats = func() *cacheCollector {
+	c := &cacheCollector{
+		hits:      prometheus.NewDesc("mcp_cache_hits_total", "Total number of cache hits", nil, nil),
+		misses:    prometheus.NewDesc("mcp_cache_misses_total", "Total number of cache misses, expired entries included", nil, nil),
+		sets:      prometheus.NewDesc("mcp_cache_sets_total", "Total number of entries stored in the cache", nil, nil),
+		evictions: prometheus.NewDesc("mcp_cache_evictions_total", "Total number of entries evicted for capacity or expiry", nil, nil),
+		entries:   prometheus.NewDesc("mcp_cache_entries", "Number of entries in the cache", nil, nil),
+		capacity:  prometheus.NewDesc("mcp_cache_capacity", "Most entries the cache holds", nil, nil),
+		hitRatio:  prometheus.NewDesc("mcp_cache_hit_ratio", "Share of cache lookups that hit, 0 to 1", nil, nil),
+		bytes:     prometheus.NewDesc("mcp_cache_bytes", "Approximate memory of the cache's entries in bytes", nil, nil),
+		maxBytes:  prometheus.NewDesc("mcp_cache_max_bytes", "Most bytes the cache's entries take (0 = no limit)", nil, nil),
+		ratio:     prometheus.NewDesc("mcp_cache_compression_ratio", "Original over stored size of the compressed cache values (0 = none)", nil, nil),
+		sweeps:    prometheus.NewDesc("mcp_cache_sweeps_total", "Total number of background sweeps for expired cache entries", nil, nil),
+		swept:     prometheus.NewDesc("mcp_cache_swept_entries_total", "Total number of expired entries the sweeps removed", nil, nil),
+		lastSweep: prometheus.NewDesc("mcp_cache_last_sweep_timestamp_seconds", "Unix time of the latest sweep", nil, nil),
+	}
+	prometheus.MustRegister(c)
+	return c
+}()
+
+// SetCacheStats exports the statistics stats returns as the cache metrics
+// A server calls it with its cache's Stats method; nil stops the export.
+func SetCacheStats(stats func() cache.CacheStats) {
+	cacheStats.mu.Lock()
+	defer cacheStats.mu.Unlock()
+	cacheStats.stats = stats
+}
+
+// Describe implements prometheus.Collector
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.hits, c.misses, c.sets, c.evictions, c.entries, c.capacity, c.hitRatio, c.bytes, c.maxBytes, c.ratio, c.sweeps, c.swept, c.lastSweep} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	stats := c.stats
+	c.mu.RUnlock()
+	if stats == nil {
+		return
+	}
+
+	s := stats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses))
+	ch <- prometheus.MustNewConstMetric(c.sets, prometheus.CounterValue, float64(s.Sets))
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions))
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Size))
+	ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(s.MaxSize))
+	ch <- prometheus.MustNewConstMetric(c.hitRatio, prometheus.GaugeValue, s.HitRate)
+	ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(s.Bytes))
+	ch <- prometheus.MustNewConstMetric(c.maxBytes, prometheus.GaugeValue, float64(s.MaxBytes))
+	ch <- prometheus.MustNewConstMetric(c.ratio, prometheus.GaugeValue, s.CompressionRatio)
+	ch <- prometheus.MustNewConstMetric(c.sweeps, prometheus.CounterValue, float64(s.Sweeps))
+	ch <- prometheus.MustNewConstMetric(c.swept, prometheus.CounterValue, float64(s.SweptEntries))
+	if !s.LastSweep.IsZero() {
+		ch <- prometheus.MustNewConstMetric(c.lastSweep, prometheus.GaugeValue, float64(s.LastSweep.UnixNano())/1e9)
+	}
+}
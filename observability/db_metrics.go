@@ -0,0 +1,88 @@
+package observability
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dbPoolKey identifies a connection pool
+type dbPoolKey struct {
+	provider string
+	resource string
+}
+
+// dbPoolCollector exports the statistics of the database connection
+// pools of auth providers at scrape time
+type dbPoolCollector struct {
+	mu    sync.RWMutex
+	pools map[dbPoolKey]func() sql.DBStats
+
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	maxOpen      *prometheus.Desc
+	waits        *prometheus.Desc
+	waitDuration *prometheus.Desc
+	closed       *prometheus.Desc
+}
+
+var dbPools = func() *dbPoolCollector {
+	labels := []string{"provider", "resource"}
+	c := &dbPoolCollector{
+		pools:        make(map[dbPoolKey]func() sql.DBStats),
+		open:         prometheus.NewDesc("mcp_db_connections_open", "Number of open database connections", labels, nil),
+		inUse:        prometheus.NewDesc("mcp_db_connections_in_use", "Number of database connections in use", labels, nil),
+		idle:         prometheus.NewDesc("mcp_db_connections_idle", "Number of idle database connections", labels, nil),
+		maxOpen:      prometheus.NewDesc("mcp_db_connections_max_open", "Most database connections the pool opens, 0 for no limit", labels, nil),
+		waits:        prometheus.NewDesc("mcp_db_connection_waits_total", "Total number of waits for a database connection", labels, nil),
+		waitDuration: prometheus.NewDesc("mcp_db_connection_wait_seconds_total", "Total time spent waiting for a database connection", labels, nil),
+		closed:       prometheus.NewDesc("mcp_db_connections_closed_total", "Total number of database connections closed for idleness or lifetime", append(labels, "reason"), nil),
+	}
+	prometheus.MustRegister(c)
+	return c
+}()
+
+// RegisterDBPool exports the pool statistics stats returns
+// A database auth provider calls it with the Stats method of each
+// resource's sql.DB; registering a resource again replaces it.
+func RegisterDBPool(provider, resource string, stats func() sql.DBStats) {
+	dbPools.mu.Lock()
+	defer dbPools.mu.Unlock()
+	dbPools.pools[dbPoolKey{provider, resource}] = stats
+}
+
+// UnregisterDBPool stops exporting a pool's statistics
+func UnregisterDBPool(provider, resource string) {
+	dbPools.mu.Lock()
+	defer dbPools.mu.Unlock()
+	delete(dbPools.pools, dbPoolKey{provider, resource})
+}
+
+// Describe implements prometheus.Collector
+func (c *dbPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.open, c.inUse, c.idle, c.maxOpen, c.waits, c.waitDuration, c.closed} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *dbPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for key, stats := range c.pools {
+		s := stats()
+		labels := []string{key.provider, key.resource}
+		ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(s.OpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(s.InUse), labels...)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.Idle), labels...)
+		ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(s.MaxOpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(c.waits, prometheus.CounterValue, float64(s.WaitCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, s.WaitDuration.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(s.MaxIdleClosed), append(labels, "max_idle")...)
+		ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(s.MaxIdleTimeClosed), append(labels, "idle_time")...)
+		ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(s.MaxLifetimeClosed), append(labels, "lifetime")...)
+	}
+}
@@ -0,0 +1,142 @@
+package observability
+
+import (
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// ============================================================
+// Debug endpoints
+// ============================================================
+
+// Debug endpoint paths served by the metrics server
+const (
+	PathPprof  = "/debug/pprof/"
+	PathVars   = "/debug/vars"
+	PathConfig = "/debug/config"
+)
+
+// DebugConfig configures the runtime debug endpoints of the metrics
+// server: pprof profiles, expvar variables and the redacted config
+// Profiles expose internals and cost CPU, so the endpoints answer only
+// the allowed source addresses.
+type DebugConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// AllowedIPs are addresses or CIDR ranges allowed to call the
+	// endpoints (default: loopback only). Forwarding headers are ignored:
+	// behind a proxy, allow the proxy's address.
+	AllowedIPs []string `yaml:"allowed_ips"`
+}
+
+// defaultDebugIPs are allowed when DebugConfig.AllowedIPs is empty
+var defaultDebugIPs = []string{"127.0.0.0/8", "::1/128"}
+
+// prefixes parses AllowedIPs
+func (c DebugConfig) prefixes() ([]netip.Prefix, error) {
+	allowed := c.AllowedIPs
+	if len(allowed) == 0 {
+		allowed = defaultDebugIPs
+	}
+	prefixes := make([]netip.Prefix, 0, len(allowed))
+	for _, s := range allowed {
+		if strings.Contains(s, "/") {
+			prefix, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid debug allowed IP %q: %w", s, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid debug allowed IP %q: %w", s, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Validate checks the allowed addresses
+func (c DebugConfig) Validate() error {
+	_, err := c.prefixes()
+	return err
+}
+
+// EnableDebug serves the debug endpoints next to /metrics:
+//
+//	/debug/pprof/  CPU, heap, goroutine, ... profiles (net/http/pprof)
+//	/debug/vars    expvar variables, including memstats and cmdline
+//	/debug/config  the configuration dump returned by config, if set
+//
+// Must be called before Start. (Like any import of net/http/pprof and
+// expvar, this package also registers them on http.DefaultServeMux, which
+// the framework never serves; don't serve it on a public address.)
+func (m *MetricsServer) EnableDebug(debug DebugConfig, config func() ([]byte, error)) error {
+	prefixes, err := debug.prefixes()
+	if err != nil {
+		return err
+	}
+	allow := func(h http.Handler) http.Handler {
+		return allowIPs(prefixes, debugTimeout(h))
+	}
+
+	m.Handle(PathPprof, allow(http.HandlerFunc(pprof.Index)))
+	m.Handle(PathPprof+"cmdline", allow(http.HandlerFunc(pprof.Cmdline)))
+	m.Handle(PathPprof+"profile", allow(http.HandlerFunc(pprof.Profile)))
+	m.Handle(PathPprof+"symbol", allow(http.HandlerFunc(pprof.Symbol)))
+	m.Handle(PathPprof+"trace", allow(http.HandlerFunc(pprof.Trace)))
+	m.Handle(PathVars, allow(expvar.Handler()))
+	if config != nil {
+		m.Handle(PathConfig, allow(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, err := config()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/yaml")
+			w.Write(data)
+		})))
+	}
+
+	m.logger.Warn("debug endpoints enabled on the metrics server",
+		"address", m.address,
+		"allowed_ips", prefixes)
+	return nil
+}
+
+// allowIPs answers 403 to requests from addresses outside prefixes
+func allowIPs(prefixes []netip.Prefix, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		addr, err := netip.ParseAddr(host)
+		if err == nil {
+			addr = addr.Unmap()
+			for _, prefix := range prefixes {
+				if prefix.Contains(addr) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	})
+}
+
+// debugTimeout lifts the metrics server's write timeout, which would cut
+// off CPU profiles and traces (a profile samples for 30 seconds by default)
+func debugTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		next.ServeHTTP(w, r)
+	})
+}
@@ -25,6 +25,9 @@ type HealthCheck struct {
 }
 
 // HealthChecker performs health checks
+//
+// Deprecated: use the health package, whose checks the server serves on
+// /health/live and /health/ready.
 type HealthChecker struct {
 	checks map[string]CheckFunc
 	mu     sync.RWMutex
@@ -0,0 +1,100 @@
+package observability
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ============================================================
+// ID generation
+// ============================================================
+
+// IDGenerator returns a new ID for a request
+// IDs end up in headers, SSE event IDs, log lines, recordings and audit
+// events, so they must be unique across replicas and printable ASCII of
+// at most MaxRequestIDLength bytes; NewRequestID replaces any other
+// result with a UUIDv7.
+type IDGenerator func() string
+
+// Names of the built-in generators, for IDGeneratorByName
+const (
+	IDFormatUUIDv7 = "uuidv7"
+	IDFormatULID   = "ulid"
+	IDFormatRandom = "random"
+)
+
+var idGenerator atomic.Value // IDGenerator
+
+// SetIDGenerator sets the generator of NewRequestID, used by every
+// transport, the executor, audit events and spans; nil restores the
+// default, NewUUIDv7
+// The generator is process-wide and must be safe for concurrent use.
+func SetIDGenerator(gen IDGenerator) {
+	if gen == nil {
+		gen = NewUUIDv7
+	}
+	idGenerator.Store(gen)
+}
+
+// IDGeneratorByName returns a built-in generator: "uuidv7" (or ""),
+// "ulid" or "random"
+func IDGeneratorByName(name string) (IDGenerator, error) {
+	switch name {
+	case "", IDFormatUUIDv7:
+		return NewUUIDv7, nil
+	case IDFormatULID:
+		return NewULID, nil
+	case IDFormatRandom:
+		return NewRandomID, nil
+	}
+	return nil, fmt.Errorf("unknown request ID format %q (want uuidv7, ulid or random)", name)
+}
+
+// NewUUIDv7 returns a UUIDv7: a millisecond timestamp followed by 74
+// random bits, so IDs sort by creation and don't collide across replicas
+func NewUUIDv7() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return NewRandomID()
+	}
+	return id.String()
+}
+
+// crockford is the ULID alphabet
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a ULID: 26 characters encoding a millisecond timestamp
+// and 80 random bits, sortable like a UUIDv7 but shorter
+func NewULID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	if _, err := rand.Read(b[6:]); err != nil {
+		return NewRandomID()
+	}
+
+	// 128 bits as 26 base-32 digits, the first holding the top 3 bits
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// NewRandomID returns a random 16-character hex ID
+// Unlike UUIDv7s and ULIDs it doesn't reveal when it was made.
+func NewRandomID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().Format("150405.000000000")
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -15,6 +15,13 @@ type LoggingConfig struct {
 	Format    string
 	AddSource bool
 	Output    io.Writer
+
+	// LevelVar, if set, is set to Level and controls the logger's level,
+	// so it can be changed at runtime
+	LevelVar *slog.LevelVar
+
+	// Secrets, if set, returns values masked in every log record
+	Secrets func() []string
 }
 
 // SetupLogging configures structured logging based on config
@@ -60,17 +67,23 @@ func SetupLogging(config interface{}) *slog.Logger {
 		cfg.Output = os.Stdout
 	}
 
+	var level slog.Leveler = ParseLevel(cfg.Level)
+	if cfg.LevelVar != nil {
+		cfg.LevelVar.Set(ParseLevel(cfg.Level))
+		level = cfg.LevelVar
+	}
+
 	// Use colored handler for text format
 	if cfg.Format == "text" && color.IsEnabled() {
 		handler = color.NewColoredHandler(cfg.Output, &color.ColoredHandlerOptions{
-			Level:      parseLevel(cfg.Level),
+			Leveler:    level,
 			TimeFormat: "15:04:05",
 			Writer:     cfg.Output,
 		})
 	} else {
 		// Create handler options
 		opts := &slog.HandlerOptions{
-			Level:     parseLevel(cfg.Level),
+			Level:     level,
 			AddSource: cfg.AddSource,
 		}
 
@@ -85,11 +98,16 @@ func SetupLogging(config interface{}) *slog.Logger {
 		}
 	}
 
+	if cfg.Secrets != nil {
+		handler = NewRedactingHandler(handler, cfg.Secrets)
+	}
+	handler = NewContextHandler(handler)
+
 	return slog.New(handler)
 }
 
-// parseLevel converts string to slog.Level
-func parseLevel(levelStr string) slog.Level {
+// ParseLevel converts a level name to slog.Level (default: info)
+func ParseLevel(levelStr string) slog.Level {
 	switch levelStr {
 	case "debug":
 		return slog.LevelDebug
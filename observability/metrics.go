@@ -50,18 +50,43 @@ var (
 			Name: "mcp_tool_calls_total",
 			Help: "Total number of tool calls",
 		},
-		[]string{"tool", "status"},
+		[]string{"tool", "status", "transport"},
 	)
 
 	toolDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "mcp_tool_duration_seconds",
-			Help:    "Tool execution duration in seconds",
-			Buckets: prometheus.DefBuckets,
+			Help:    "Tool call duration in seconds, cache hits included",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+		},
+		[]string{"tool", "transport"},
+	)
+
+	toolErrorsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_tool_errors_total",
+			Help: "Total number of failed tool calls by error code",
+		},
+		[]string{"tool", "transport", "code"},
+	)
+
+	toolPanicsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_tool_panics_total",
+			Help: "Total number of panics recovered from tool handlers",
 		},
 		[]string{"tool"},
 	)
 
+	// Cache metrics; totals come from the cache's own statistics
+	cacheLookupsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_cache_lookups_total",
+			Help: "Total number of cache lookups of tool results",
+		},
+		[]string{"tool", "result"},
+	)
+
 	// Backend metrics
 	backendInitialized = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -77,7 +102,23 @@ var (
 			Name: "mcp_streaming_events_total",
 			Help: "Total number of streaming events emitted",
 		},
-		[]string{"tool", "event_type"},
+		[]string{"tool", "event_type", "transport"},
+	)
+
+	oversizedEventsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_streaming_oversized_events_total",
+			Help: "Streaming events over the size limit, by tool and how they were sent (split or truncate)",
+		},
+		[]string{"tool", "policy"},
+	)
+
+	oversizedResultsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_oversized_results_total",
+			Help: "tools/call results over the size limit, by tool and what was done with them (error, truncate or spill)",
+		},
+		[]string{"tool", "policy"},
 	)
 
 	activeStreams = promauto.NewGauge(
@@ -93,6 +134,175 @@ var (
 			Help: "Number of concurrent tool executions",
 		},
 	)
+
+	// Executor metrics
+	executorWorkers = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mcp_executor_workers",
+			Help: "Number of executor workers, the most streaming tool calls run at once",
+		},
+	)
+
+	executorQueueLength = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mcp_executor_queue_length",
+			Help: "Number of streaming tool calls waiting for an executor worker",
+		},
+	)
+
+	executorQueueWait = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "mcp_executor_queue_wait_seconds",
+			Help:    "Time streaming tool calls waited for an executor worker",
+			Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+		},
+	)
+
+	executorRejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_executor_rejections_total",
+			Help: "Total number of streaming tool calls that never reached a worker",
+		},
+		[]string{"reason"},
+	)
+
+	// Circuit breaker metrics
+	circuitState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_circuit_breaker_state",
+			Help: "Circuit breaker state (0 = closed, 1 = open, 2 = half-open)",
+		},
+		[]string{"breaker"},
+	)
+
+	circuitRejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_circuit_breaker_rejections_total",
+			Help: "Total number of calls rejected by an open circuit breaker",
+		},
+		[]string{"breaker"},
+	)
+
+	// Health check metrics
+	healthCheckStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_health_check_status",
+			Help: "Last health check result (1 = ok, 0.5 = degraded, 0 = failing)",
+		},
+		[]string{"check", "kind"},
+	)
+
+	healthCheckDuration = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_health_check_duration_seconds",
+			Help: "Duration of the last health check run",
+		},
+		[]string{"check", "kind"},
+	)
+
+	// Output filter metrics
+	outputRedactionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_output_redactions_total",
+			Help: "Total number of matches redacted from tool results by output filters",
+		},
+		[]string{"pattern", "tool"},
+	)
+
+	// Audit metrics
+	auditEventsLostTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_audit_events_lost_total",
+			Help: "Total number of audit events not written, by reason",
+		},
+		[]string{"reason"},
+	)
+
+	// Canary metrics
+	canaryRunsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_canary_runs_total",
+			Help: "Total number of canary tool calls",
+		},
+		[]string{"canary", "tool", "status"},
+	)
+
+	canaryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mcp_canary_duration_seconds",
+			Help:    "Latency of canary tool calls",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"canary", "tool"},
+	)
+
+	canaryUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_canary_up",
+			Help: "Whether the last canary call succeeded (1) or failed (0)",
+		},
+		[]string{"canary", "tool"},
+	)
+
+	// Backend lifecycle metrics
+	backendInitDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mcp_backend_initialize_duration_seconds",
+			Help:    "Duration of backend initialization, by outcome",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"backend", "status"},
+	)
+
+	// Session metrics
+	activeSessions = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mcp_sessions_active",
+			Help: "Number of open client sessions",
+		},
+	)
+
+	sessionsEndedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_sessions_ended_total",
+			Help: "Client sessions ended, by reason",
+		},
+		[]string{"reason"},
+	)
+
+	// Rate limiting metrics
+	rateLimitRejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_ratelimit_rejections_total",
+			Help: "Total number of requests rejected by rate limiting",
+		},
+		[]string{"scope", "tool"},
+	)
+
+	authFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_auth_failures_total",
+			Help: "Total number of inbound requests rejected by authentication or authorization",
+		},
+		[]string{"reason", "transport"},
+	)
+
+	// Outbound API key metrics
+	apiKeyInUse = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "mcp_apikey_in_use",
+			Help: "API key an outbound auth provider currently sends (1 for the key in use)",
+		},
+		[]string{"provider", "key"},
+	)
+
+	apiKeyRotationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mcp_apikey_rotations_total",
+			Help: "Total number of switches to another API key, by the key left and why",
+		},
+		[]string{"provider", "key", "reason"},
+	)
 )
 
 // RecordRequest records a request metric
@@ -116,13 +326,35 @@ func RecordResponseSize(method, transport string, size int64) {
 }
 
 // RecordToolCall records a tool call
-func RecordToolCall(tool, status string) {
-	toolCallsTotal.WithLabelValues(tool, status).Inc()
+// status is "success" or "error"; error results of tools count as errors.
+func RecordToolCall(tool, status, transport string) {
+	toolCallsTotal.WithLabelValues(tool, status, transport).Inc()
 }
 
-// RecordToolDuration records tool execution duration
-func RecordToolDuration(tool string, duration time.Duration) {
-	toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+// RecordToolDuration records a tool call's duration
+func RecordToolDuration(tool, transport string, duration time.Duration) {
+	toolDuration.WithLabelValues(tool, transport).Observe(duration.Seconds())
+}
+
+// RecordToolError records why a tool call failed
+// code is an mcperr code, "invalid_params", "tool_error" for error
+// results, or "internal".
+func RecordToolError(tool, transport, code string) {
+	toolErrorsTotal.WithLabelValues(tool, transport, code).Inc()
+}
+
+// RecordToolPanic records a panic recovered from a tool's handler
+func RecordToolPanic(tool string) {
+	toolPanicsTotal.WithLabelValues(tool).Inc()
+}
+
+// RecordCacheLookup records a cache lookup of a tool's result
+func RecordCacheLookup(tool string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookupsTotal.WithLabelValues(tool, result).Inc()
 }
 
 // RecordBackendInitialized records backend initialization
@@ -135,8 +367,18 @@ func RecordBackendInitialized(backend string, initialized bool) {
 }
 
 // RecordStreamingEvent records a streaming event (NEW for v0.2.0)
-func RecordStreamingEvent(tool, eventType string) {
-	streamingEventsTotal.WithLabelValues(tool, eventType).Inc()
+func RecordStreamingEvent(tool, eventType, transport string) {
+	streamingEventsTotal.WithLabelValues(tool, eventType, transport).Inc()
+}
+
+// RecordOversizedEvent records a streaming event over the size limit
+func RecordOversizedEvent(tool, policy string) {
+	oversizedEventsTotal.WithLabelValues(tool, policy).Inc()
+}
+
+// RecordOversizedResult records a tools/call result over the size limit
+func RecordOversizedResult(tool, policy string) {
+	oversizedResultsTotal.WithLabelValues(tool, policy).Inc()
 }
 
 // IncActiveStreams increments active streams counter (NEW for v0.2.0)
@@ -158,3 +400,110 @@ func IncConcurrentExecutions() {
 func DecConcurrentExecutions() {
 	concurrentExecutions.Dec()
 }
+
+// SetExecutorWorkers records the size of the executor's worker pool
+func SetExecutorWorkers(n int) {
+	executorWorkers.Set(float64(n))
+}
+
+// SetExecutorQueueLength records the number of calls waiting for a worker
+func SetExecutorQueueLength(n int64) {
+	executorQueueLength.Set(float64(n))
+}
+
+// RecordExecutorQueueWait records how long a call waited for a worker
+func RecordExecutorQueueWait(wait time.Duration) {
+	executorQueueWait.Observe(wait.Seconds())
+}
+
+// RecordExecutorRejection records a call that never reached a worker
+// reason is one of "queue_full", "queue_timeout", "canceled" or "closed"
+func RecordExecutorRejection(reason string) {
+	executorRejectionsTotal.WithLabelValues(reason).Inc()
+}
+
+// SetCircuitState records a circuit breaker's state
+func SetCircuitState(breaker string, state int) {
+	circuitState.WithLabelValues(breaker).Set(float64(state))
+}
+
+// RecordCircuitRejection records a call rejected by an open circuit breaker
+func RecordCircuitRejection(breaker string) {
+	circuitRejectionsTotal.WithLabelValues(breaker).Inc()
+}
+
+// RecordHealthCheck records the result of a health check
+func RecordHealthCheck(check, kind string, status float64, latency time.Duration) {
+	healthCheckStatus.WithLabelValues(check, kind).Set(status)
+	healthCheckDuration.WithLabelValues(check, kind).Set(latency.Seconds())
+}
+
+// RecordOutputRedactions records matches of a pattern redacted from a tool result
+func RecordOutputRedactions(pattern, tool string, count int) {
+	outputRedactionsTotal.WithLabelValues(pattern, tool).Add(float64(count))
+}
+
+// RecordAuditDropped records an audit event dropped because the sink
+// fell behind
+func RecordAuditDropped() {
+	auditEventsLostTotal.WithLabelValues("dropped").Inc()
+}
+
+// RecordAuditSinkError records an audit event the sink failed to write
+func RecordAuditSinkError() {
+	auditEventsLostTotal.WithLabelValues("sink_error").Inc()
+}
+
+// RecordCanary records the outcome of a canary tool call
+func RecordCanary(canary, tool string, err error, latency time.Duration) {
+	status, up := "success", 1.0
+	if err != nil {
+		status, up = "error", 0
+	}
+	canaryRunsTotal.WithLabelValues(canary, tool, status).Inc()
+	canaryDuration.WithLabelValues(canary, tool).Observe(latency.Seconds())
+	canaryUp.WithLabelValues(canary, tool).Set(up)
+}
+
+// RecordBackendInitialize records how long a backend took to initialize
+// status is "success", "error" or "timeout"
+func RecordBackendInitialize(backend, status string, duration time.Duration) {
+	backendInitDuration.WithLabelValues(backend, status).Observe(duration.Seconds())
+}
+
+// RecordSessionStarted records a client session being opened
+func RecordSessionStarted() {
+	activeSessions.Inc()
+}
+
+// RecordSessionEnded records a client session ending
+// reason is "terminated", "expired" or "shutdown"
+func RecordSessionEnded(reason string) {
+	activeSessions.Dec()
+	sessionsEndedTotal.WithLabelValues(reason).Inc()
+}
+
+// RecordRateLimitRejection records a request rejected by a rate limit
+func RecordRateLimitRejection(scope, tool string) {
+	rateLimitRejectionsTotal.WithLabelValues(scope, tool).Inc()
+}
+
+// RecordAuthFailure records an inbound request rejected by authentication or authorization
+// reason is one of "missing", "invalid" or "forbidden"
+func RecordAuthFailure(reason, transport string) {
+	authFailuresTotal.WithLabelValues(reason, transport).Inc()
+}
+
+// RecordAPIKeyInUse records the API key a provider now sends
+func RecordAPIKeyInUse(provider, key string) {
+	apiKeyInUse.DeletePartialMatch(prometheus.Labels{"provider": provider})
+	if key != "" {
+		apiKeyInUse.WithLabelValues(provider, key).Set(1)
+	}
+}
+
+// RecordAPIKeyRotation records a provider leaving an API key
+// reason is one of "rejected", "expired" or "reloaded"
+func RecordAPIKeyRotation(provider, key, reason string) {
+	apiKeyRotationsTotal.WithLabelValues(provider, key, reason).Inc()
+}
@@ -12,9 +12,10 @@ import (
 
 // MetricsServer serves Prometheus metrics
 type MetricsServer struct {
-	address string
-	server  *http.Server
-	logger  *slog.Logger
+	address  string
+	server   *http.Server
+	logger   *slog.Logger
+	handlers map[string]http.Handler
 }
 
 // NewMetricsServer creates a new metrics server
@@ -29,10 +30,22 @@ func NewMetricsServer(address string, logger *slog.Logger) *MetricsServer {
 	}
 }
 
+// Handle serves an extra endpoint (e.g. health probes) next to /metrics
+// Must be called before Start.
+func (m *MetricsServer) Handle(path string, handler http.Handler) {
+	if m.handlers == nil {
+		m.handlers = make(map[string]http.Handler)
+	}
+	m.handlers[path] = handler
+}
+
 // Start starts the metrics server
 func (m *MetricsServer) Start() error {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
+	for path, handler := range m.handlers {
+		mux.Handle(path, handler)
+	}
 
 	m.server = &http.Server{
 		Addr:         m.address,
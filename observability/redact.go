@@ -0,0 +1,92 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// redactedValue replaces secret values in log records
+const redactedValue = "[REDACTED]"
+
+// RedactingHandler masks secret values in log messages and string
+// attributes before passing records on
+type RedactingHandler struct {
+	next    slog.Handler
+	secrets func() []string
+}
+
+// NewRedactingHandler wraps next, masking the values secrets returns
+// secrets is called per record, so the set may change at runtime (e.g. on
+// config reload).
+func NewRedactingHandler(next slog.Handler, secrets func() []string) *RedactingHandler {
+	return &RedactingHandler{next: next, secrets: secrets}
+}
+
+// Enabled implements slog.Handler
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	secrets := h.secrets()
+	if len(secrets) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+
+	out := slog.NewRecord(r.Time, r.Level, redactString(r.Message, secrets), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a, secrets))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs implements slog.Handler
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if secrets := h.secrets(); len(secrets) > 0 {
+		redacted := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			redacted[i] = redactAttr(a, secrets)
+		}
+		attrs = redacted
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(attrs), secrets: h.secrets}
+}
+
+// WithGroup implements slog.Handler
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), secrets: h.secrets}
+}
+
+// redactAttr masks secrets in an attribute, descending into groups
+func redactAttr(a slog.Attr, secrets []string) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redactString(v.String(), secrets))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, g := range group {
+			redacted[i] = redactAttr(g, secrets)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, redactString(err.Error(), secrets))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// redactString replaces every secret in s
+func redactString(s string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" && strings.Contains(s, secret) {
+			s = strings.ReplaceAll(s, secret, redactedValue)
+		}
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ============================================================
+// Request IDs
+// ============================================================
+
+// RequestIDKey is the log attribute carrying a request's ID
+const RequestIDKey = "request_id"
+
+// MaxRequestIDLength bounds IDs accepted from clients
+const MaxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying a request's ID
+// The transports set it for every JSON-RPC call, from the client's
+// X-Request-ID or a new one, so logs, traces, SSE events and responses
+// of one call share it.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the ID of the request ctx belongs to
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// EnsureRequestID returns ctx's request ID, adding a new one if it has none
+func EnsureRequestID(ctx context.Context) (context.Context, string) {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return ctx, id
+	}
+	id := NewRequestID()
+	return WithRequestID(ctx, id), id
+}
+
+// NewRequestID returns a new ID from the configured generator (see
+// SetIDGenerator)
+func NewRequestID() string {
+	if gen, ok := idGenerator.Load().(IDGenerator); ok {
+		// A generator's ID ends up in headers and logs like a client's
+		if id := gen(); ValidRequestID(id) {
+			return id
+		}
+	}
+	return NewUUIDv7()
+}
+
+// ValidRequestID reports whether a client-supplied ID can be kept: not
+// empty, not too long and printable ASCII, so it is safe in headers, SSE
+// event IDs and log lines
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > MaxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// ContextHandler adds the request ID of a record's context to the record
+// Log calls given a context (InfoContext, LogAttrs, ...) inside a request
+// are thereby correlated without passing the ID along.
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler wraps next, adding request_id attributes
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled implements slog.Handler
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := RequestIDFromContext(ctx); ok && !hasAttr(r, RequestIDKey) {
+		r = r.Clone()
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
+
+// hasAttr reports whether a record has a top-level attribute
+func hasAttr(r slog.Record, key string) bool {
+	found := false
+	r.Attrs(func(a slog.Attr) bool {
+		found = a.Key == key
+		return !found
+	})
+	return found
+}
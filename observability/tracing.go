@@ -0,0 +1,237 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation scope of the framework's spans
+const TracerName = "github.com/SaherElMasry/go-mcp-framework"
+
+// TracingConfig configures OpenTelemetry tracing
+// Spans are exported over OTLP/HTTP. Unset fields fall back to the
+// standard OTEL_EXPORTER_OTLP_* environment variables.
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Endpoint is the collector, as a URL (http://collector:4318) or
+	// host:port (TLS unless Insecure)
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+
+	// Headers are sent with every export, e.g. an API key
+	Headers map[string]string `yaml:"headers"`
+
+	// ServiceName identifies this server (default "mcp-server")
+	ServiceName    string `yaml:"service_name"`
+	ServiceVersion string `yaml:"service_version"`
+
+	// SampleRatio is the share of new traces recorded, 0 to 1; calls
+	// continuing a caller's trace follow the caller's decision
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// SetupTracing installs a global tracer provider exporting over OTLP
+// It returns a function flushing and stopping the exporter, to be called
+// on shutdown. Without it, the framework's spans are no-ops.
+func SetupTracing(ctx context.Context, config TracingConfig) (func(context.Context) error, error) {
+	var options []otlptracehttp.Option
+	switch endpoint := config.Endpoint; {
+	case strings.Contains(endpoint, "://"):
+		options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+	case endpoint != "":
+		options = append(options, otlptracehttp.WithEndpoint(endpoint))
+	}
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(config.Headers))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("otlp exporter: %w", err)
+	}
+
+	name := config.ServiceName
+	if name == "" {
+		name = "mcp-server"
+	}
+	attrs := []attribute.KeyValue{attribute.String("service.name", name)}
+	if config.ServiceVersion != "" {
+		attrs = append(attrs, attribute.String("service.version", config.ServiceVersion))
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("trace resource: %w", err)
+	}
+
+	ratio := config.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the framework's tracer from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// StartSpan starts a span of the framework's tracer
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// EndSpan ends a span, marking it failed when err is set
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ============================================================
+// Propagation
+// ============================================================
+
+// ExtractHTTP returns ctx continuing the trace of incoming headers
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// InjectHTTP adds the trace of ctx to outgoing headers
+func InjectHTTP(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractMeta returns ctx continuing the trace of a JSON-RPC request's
+// params._meta (traceparent, tracestate), as clients on stdio send it
+func ExtractMeta(ctx context.Context, meta map[string]interface{}) context.Context {
+	carrier := propagation.MapCarrier{}
+	for _, key := range otel.GetTextMapPropagator().Fields() {
+		if v, ok := meta[key].(string); ok {
+			carrier[key] = v
+		}
+	}
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// ============================================================
+// HTTP
+// ============================================================
+
+// TraceHandler wraps an HTTP handler with a server span per request,
+// continuing the caller's trace from the request headers
+func TraceHandler(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := StartSpan(ExtractHTTP(r.Context(), r.Header), name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("mcp.transport", "http"),
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			))
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", rec.status))
+		if rec.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	})
+}
+
+// statusRecorder captures a response's status code
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Flush keeps event streams working through the recorder
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the wrapped writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// TraceTransport wraps a round tripper with a client span per request,
+// propagating the trace to the server; nil means http.DefaultTransport
+// URLs are recorded without their query, which often carries keys.
+func TraceTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if _, ok := base.(*tracingTransport); ok {
+		return base
+	}
+	return &tracingTransport{base: base}
+}
+
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	u.RawQuery, u.User = "", nil
+	ctx, span := StartSpan(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.full", u.String()),
+		))
+
+	req = req.Clone(ctx)
+	InjectHTTP(ctx, req.Header)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		EndSpan(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	span.End()
+	return resp, nil
+}
@@ -0,0 +1,182 @@
+// Package paths resolves per-user config, cache and state directories
+//
+// Directories follow each platform's conventions:
+//
+//	Linux/BSD  $XDG_CONFIG_HOME, $XDG_CACHE_HOME, $XDG_STATE_HOME
+//	           (default ~/.config, ~/.cache, ~/.local/state)
+//	macOS      ~/Library/Application Support, ~/Library/Caches
+//	Windows    %AppData% (config), %LocalAppData% (cache and state)
+//
+// Every directory can be overridden with MCP_CONFIG_DIR, MCP_CACHE_DIR and
+// MCP_STATE_DIR, or through Dirs in the server configuration.
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// DefaultAppName is the subdirectory used when no application name is given
+const DefaultAppName = "go-mcp"
+
+// Environment variables overriding the resolved directories
+const (
+	EnvConfigDir = "MCP_CONFIG_DIR"
+	EnvCacheDir  = "MCP_CACHE_DIR"
+	EnvStateDir  = "MCP_STATE_DIR"
+)
+
+// Kind identifies a class of directory
+type Kind string
+
+const (
+	// KindConfig holds user-edited configuration files
+	KindConfig Kind = "config"
+
+	// KindCache holds data that can be regenerated (e.g. the file cache)
+	KindCache Kind = "cache"
+
+	// KindState holds data that must persist across restarts (e.g. OAuth tokens)
+	KindState Kind = "state"
+)
+
+// Dirs overrides the resolved directories
+// Empty fields fall back to the environment, then to platform defaults.
+type Dirs struct {
+	// AppName is the per-application subdirectory (default: DefaultAppName)
+	AppName string `yaml:"app_name" json:"app_name"`
+
+	ConfigDir string `yaml:"config_dir" json:"config_dir"`
+	CacheDir  string `yaml:"cache_dir" json:"cache_dir"`
+	StateDir  string `yaml:"state_dir" json:"state_dir"`
+}
+
+// Overridable for tests
+var (
+	goos    = runtime.GOOS
+	getenv  = os.Getenv
+	homeDir = os.UserHomeDir
+)
+
+// Config returns the configuration directory
+func (d Dirs) Config() (string, error) {
+	return d.resolve(KindConfig, d.ConfigDir, EnvConfigDir)
+}
+
+// Cache returns the cache directory
+func (d Dirs) Cache() (string, error) {
+	return d.resolve(KindCache, d.CacheDir, EnvCacheDir)
+}
+
+// State returns the state directory
+func (d Dirs) State() (string, error) {
+	return d.resolve(KindState, d.StateDir, EnvStateDir)
+}
+
+// Tokens returns the directory for persisted OAuth2 tokens
+func (d Dirs) Tokens() (string, error) {
+	state, err := d.State()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(state, "tokens"), nil
+}
+
+// resolve applies the override, environment and platform default in that order
+func (d Dirs) resolve(kind Kind, override, env string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	if v := getenv(env); v != "" {
+		return v, nil
+	}
+
+	base, err := baseDir(kind)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s directory: %w", kind, err)
+	}
+
+	app := d.AppName
+	if app == "" {
+		app = DefaultAppName
+	}
+	return filepath.Join(base, app), nil
+}
+
+// baseDir returns the platform's base directory for kind
+func baseDir(kind Kind) (string, error) {
+	switch goos {
+	case "windows":
+		name := "LocalAppData"
+		if kind == KindConfig {
+			name = "AppData"
+		}
+		if v := getenv(name); v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("%%%s%% is not set", name)
+
+	case "darwin", "ios":
+		home, err := home()
+		if err != nil {
+			return "", err
+		}
+		if kind == KindCache {
+			return filepath.Join(home, "Library", "Caches"), nil
+		}
+		return filepath.Join(home, "Library", "Application Support"), nil
+
+	default:
+		env, fallback := "XDG_CONFIG_HOME", ".config"
+		switch kind {
+		case KindCache:
+			env, fallback = "XDG_CACHE_HOME", ".cache"
+		case KindState:
+			env, fallback = "XDG_STATE_HOME", filepath.Join(".local", "state")
+		}
+		// The XDG spec requires absolute paths; ignore relative values
+		if v := getenv(env); v != "" && filepath.IsAbs(v) {
+			return v, nil
+		}
+		home, err := home()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, fallback), nil
+	}
+}
+
+// home returns the user's home directory
+func home() (string, error) {
+	h, err := homeDir()
+	if err != nil {
+		return "", err
+	}
+	if h == "" {
+		return "", errors.New("home directory is not set")
+	}
+	return h, nil
+}
+
+// ConfigDir returns the default configuration directory for app
+func ConfigDir(app string) (string, error) {
+	return Dirs{AppName: app}.Config()
+}
+
+// CacheDir returns the default cache directory for app
+func CacheDir(app string) (string, error) {
+	return Dirs{AppName: app}.Cache()
+}
+
+// StateDir returns the default state directory for app
+func StateDir(app string) (string, error) {
+	return Dirs{AppName: app}.State()
+}
+
+// TokenDir returns the default OAuth2 token directory for app
+func TokenDir(app string) (string, error) {
+	return Dirs{AppName: app}.Tokens()
+}
@@ -0,0 +1,102 @@
+package paths
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// setPlatform fakes the OS, environment and home directory for a test
+func setPlatform(t *testing.T, os string, env map[string]string, home string) {
+	t.Helper()
+	oldGOOS, oldGetenv, oldHome := goos, getenv, homeDir
+	t.Cleanup(func() { goos, getenv, homeDir = oldGOOS, oldGetenv, oldHome })
+
+	goos = os
+	getenv = func(k string) string { return env[k] }
+	homeDir = func() (string, error) { return home, nil }
+}
+
+func TestDirs_Linux(t *testing.T) {
+	setPlatform(t, "linux", map[string]string{}, "/home/u")
+
+	tests := []struct {
+		name string
+		get  func(Dirs) (string, error)
+		want string
+	}{
+		{"config", Dirs.Config, "/home/u/.config/go-mcp"},
+		{"cache", Dirs.Cache, "/home/u/.cache/go-mcp"},
+		{"state", Dirs.State, "/home/u/.local/state/go-mcp"},
+		{"tokens", Dirs.Tokens, "/home/u/.local/state/go-mcp/tokens"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.get(Dirs{})
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirs_XDGOverrides(t *testing.T) {
+	setPlatform(t, "linux", map[string]string{
+		"XDG_CACHE_HOME":  "/xdg/cache",
+		"XDG_CONFIG_HOME": "relative/ignored",
+	}, "/home/u")
+
+	d := Dirs{AppName: "mcp-fs"}
+	if got, _ := d.Cache(); got != filepath.FromSlash("/xdg/cache/mcp-fs") {
+		t.Errorf("Cache() = %q", got)
+	}
+	if got, _ := d.Config(); got != filepath.FromSlash("/home/u/.config/mcp-fs") {
+		t.Errorf("Config() = %q, relative XDG paths must be ignored", got)
+	}
+}
+
+func TestDirs_Darwin(t *testing.T) {
+	setPlatform(t, "darwin", map[string]string{}, "/Users/u")
+
+	if got, _ := (Dirs{}).Cache(); got != filepath.FromSlash("/Users/u/Library/Caches/go-mcp") {
+		t.Errorf("Cache() = %q", got)
+	}
+	if got, _ := (Dirs{}).Config(); got != filepath.FromSlash("/Users/u/Library/Application Support/go-mcp") {
+		t.Errorf("Config() = %q", got)
+	}
+}
+
+func TestDirs_Windows(t *testing.T) {
+	setPlatform(t, "windows", map[string]string{
+		"AppData":      `C:\Users\u\AppData\Roaming`,
+		"LocalAppData": `C:\Users\u\AppData\Local`,
+	}, `C:\Users\u`)
+
+	if got, _ := (Dirs{}).Config(); got != filepath.Join(`C:\Users\u\AppData\Roaming`, "go-mcp") {
+		t.Errorf("Config() = %q", got)
+	}
+	if got, _ := (Dirs{}).State(); got != filepath.Join(`C:\Users\u\AppData\Local`, "go-mcp") {
+		t.Errorf("State() = %q", got)
+	}
+}
+
+func TestDirs_WindowsMissingEnv(t *testing.T) {
+	setPlatform(t, "windows", map[string]string{}, `C:\Users\u`)
+
+	if _, err := (Dirs{}).Config(); err == nil {
+		t.Error("expected error when %AppData% is unset")
+	}
+}
+
+func TestDirs_Precedence(t *testing.T) {
+	setPlatform(t, "linux", map[string]string{EnvStateDir: "/env/state"}, "/home/u")
+
+	if got, _ := (Dirs{}).State(); got != "/env/state" {
+		t.Errorf("State() = %q, want env override", got)
+	}
+	if got, _ := (Dirs{StateDir: "/cfg/state"}).State(); got != "/cfg/state" {
+		t.Errorf("State() = %q, want config override", got)
+	}
+}
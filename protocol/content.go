@@ -0,0 +1,32 @@
+package protocol
+
+import (
+	"encoding/json"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// Content types, shared with backend so handlers can return them directly
+type (
+	Content          = backend.Content
+	TextContent      = backend.TextContent
+	ImageContent     = backend.ImageContent
+	AudioContent     = backend.AudioContent
+	EmbeddedResource = backend.EmbeddedResource
+	ResourceLink     = backend.ResourceLink
+	ResourceContents = backend.ResourceContents
+)
+
+// NewContentItem converts a typed content item to its wire form
+func NewContentItem(c Content) (ContentItem, error) {
+	data, err := backend.MarshalContent(c)
+	if err != nil {
+		return ContentItem{}, err
+	}
+
+	var item ContentItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return ContentItem{}, err
+	}
+	return item, nil
+}
@@ -1,6 +1,12 @@
 package protocol
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
 
 // Standard JSON-RPC 2.0 error codes
 const (
@@ -11,6 +17,30 @@ const (
 	InternalError  = -32603
 )
 
+// Implementation-defined server error codes (-32000 to -32099)
+const (
+	// Unauthorized mirrors HTTP 401 Unauthorized
+	Unauthorized = -32001
+
+	// UpstreamError mirrors HTTP 502 Bad Gateway
+	UpstreamError = -32002
+
+	// Forbidden mirrors HTTP 403 Forbidden
+	Forbidden = -32003
+
+	// NotFound mirrors HTTP 404 Not Found
+	NotFound = -32004
+
+	// RequestTimeout mirrors HTTP 408 Request Timeout
+	RequestTimeout = -32008
+
+	// ResultTooLarge mirrors HTTP 413 Content Too Large
+	ResultTooLarge = -32013
+
+	// RateLimitExceeded mirrors HTTP 429 Too Many Requests
+	RateLimitExceeded = -32029
+)
+
 // NewError creates a new protocol error
 func NewError(code int, message string, data interface{}) *Error {
 	return &Error{
@@ -45,6 +75,69 @@ func NewInternalError(err error) *Error {
 	return NewError(InternalError, "Internal error", err.Error())
 }
 
+// NewRateLimitError creates a rate limit error
+// The data payload carries the rejecting scope and retry_after in seconds
+func NewRateLimitError(scope string, retryAfter time.Duration) *Error {
+	return NewError(RateLimitExceeded, "Rate limit exceeded", map[string]interface{}{
+		"code":        mcperr.CodeRateLimited,
+		"retryable":   true,
+		"scope":       scope,
+		"retry_after": math.Ceil(retryAfter.Seconds()),
+	})
+}
+
+// NewCategorizedError maps a typed tool error to a JSON-RPC error
+// The data payload carries the machine-readable code, whether the call
+// is retryable and retry_after in seconds when known.
+func NewCategorizedError(e *mcperr.Error) *Error {
+	data := make(map[string]interface{}, len(e.Details)+4)
+	for k, v := range e.Details {
+		data[k] = v
+	}
+	data["code"] = e.Code
+	data["message"] = e.Message
+	data["retryable"] = e.Code.Retryable()
+	if e.RetryAfter > 0 {
+		data["retry_after"] = math.Ceil(e.RetryAfter.Seconds())
+	}
+
+	switch e.Code {
+	case mcperr.CodeNotFound:
+		return NewError(NotFound, "Not found", data)
+	case mcperr.CodePermissionDenied:
+		return NewError(Forbidden, "Forbidden", data)
+	case mcperr.CodeRateLimited:
+		return NewError(RateLimitExceeded, "Rate limit exceeded", data)
+	case mcperr.CodeTimeout:
+		return NewError(RequestTimeout, "Request timeout", data)
+	case mcperr.CodeUpstream:
+		return NewError(UpstreamError, "Upstream error", data)
+	default:
+		return NewError(InternalError, "Internal error", data)
+	}
+}
+
+// NewResultTooLargeError reports a tool result over its size limit
+func NewResultTooLargeError(tool string, size, maxSize int) *Error {
+	return NewError(ResultTooLarge, "Result too large", map[string]interface{}{
+		"code":      "result_too_large",
+		"message":   fmt.Sprintf("result of %s is %d bytes, over the limit of %d", tool, size, maxSize),
+		"retryable": false,
+		"size":      size,
+		"max_size":  maxSize,
+	})
+}
+
+// NewUnauthorizedError creates an authentication error
+func NewUnauthorizedError(message string) *Error {
+	return NewError(Unauthorized, "Unauthorized", message)
+}
+
+// NewForbiddenError creates an authorization error
+func NewForbiddenError(message string) *Error {
+	return NewError(Forbidden, "Forbidden", message)
+}
+
 // Error implements the error interface
 func (e *Error) Error() string {
 	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
@@ -0,0 +1,83 @@
+package protocol
+
+import (
+	"context"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// OutputFilter inspects or rewrites a tool result before it is returned
+// Filters run in order on every tools/call result, including cache hits
+// (the cache holds unfiltered results, so changing filters takes effect
+// immediately). Returning an error withholds the result; categorized
+// errors (see mcperr) keep their code, e.g. mcperr.PermissionDenied.
+//
+// The safety package bundles filters for secrets and PII.
+type OutputFilter func(ctx context.Context, tool string, result *ToolCallResult) error
+
+// AddOutputFilter appends a filter to the output filter chain
+func (h *Handler) AddOutputFilter(filter OutputFilter) {
+	h.filters = append(h.filters, filter)
+}
+
+// filterOutput runs the output filter chain on a tools/call result
+func (h *Handler) filterOutput(ctx context.Context, tool backend.ToolDefinition, result interface{}) (interface{}, *Error) {
+	if len(h.filters) == 0 {
+		return result, nil
+	}
+
+	callResult, ok := result.(ToolCallResult)
+	if !ok {
+		return result, nil
+	}
+	for _, filter := range h.filters {
+		if err := filter(ctx, tool.Name, &callResult); err != nil {
+			h.logger.WarnContext(ctx, "tool result withheld by output filter", "tool", tool.Name, "error", err)
+			return nil, toolError(err)
+		}
+	}
+	return callResult, nil
+}
+
+// RewriteText replaces every text in the result with fn(text): text
+// content, embedded text resources and strings in structuredContent
+// Shared values are copied before being changed.
+func (r *ToolCallResult) RewriteText(fn func(string) string) {
+	for i := range r.Content {
+		item := &r.Content[i]
+		if item.Text != "" {
+			item.Text = fn(item.Text)
+		}
+		if item.Resource != nil && item.Resource.Text != "" {
+			resource := *item.Resource
+			resource.Text = fn(resource.Text)
+			item.Resource = &resource
+		}
+	}
+
+	if r.StructuredContent != nil {
+		r.StructuredContent = rewriteValue(r.StructuredContent, fn).(map[string]interface{})
+	}
+}
+
+// rewriteValue rewrites the strings of a decoded JSON value
+func rewriteValue(v interface{}, fn func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return fn(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = rewriteValue(value, fn)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = rewriteValue(value, fn)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -3,12 +3,25 @@ package protocol
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"sync/atomic"
 	"time"
 
+	"github.com/SaherElMasry/go-mcp-framework/audit"
+	"github.com/SaherElMasry/go-mcp-framework/auth"
 	"github.com/SaherElMasry/go-mcp-framework/backend"
 	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Handler handles JSON-RPC requests
@@ -19,7 +32,22 @@ type Handler struct {
 	// === NEW: Cache support ===
 	cache  cache.Cache
 	keyGen *cache.KeyGenerator
-	config *cache.Config
+	config atomic.Pointer[cache.Config]
+
+	limiter    *ratelimit.Limiter
+	access     *auth.AccessPolicy
+	provenance *ProvenanceConfig
+	filters    []OutputFilter
+	audit      *audit.Logger
+
+	broadcaster   *transport.Broadcaster
+	subscriptions subscriptions
+
+	limits *ResultLimits
+	spills spillStore
+
+	// listPageSize paginates tools/list and resources/list (0 = one page)
+	listPageSize int
 }
 
 // NewHandler creates a new protocol handler
@@ -32,6 +60,10 @@ func NewHandler(backend backend.ServerBackend, logger *slog.Logger) *Handler {
 		backend: backend,
 		logger:  logger,
 		// Cache will be set via SetCache() from framework
+		subscriptions: subscriptions{
+			watches:  make(map[string]*resourceWatch),
+			sessions: make(map[string]bool),
+		},
 	}
 }
 
@@ -39,7 +71,35 @@ func NewHandler(backend backend.ServerBackend, logger *slog.Logger) *Handler {
 func (h *Handler) SetCache(c cache.Cache, keyGen *cache.KeyGenerator, config *cache.Config) {
 	h.cache = c
 	h.keyGen = keyGen
-	h.config = config
+	h.config.Store(config)
+}
+
+// UpdateCacheConfig replaces the cache settings used for new entries
+// Only TTLs take effect; the cache itself is not rebuilt.
+func (h *Handler) UpdateCacheConfig(config *cache.Config) {
+	h.config.Store(config)
+}
+
+// SetRateLimiter configures rate limiting for tools/call
+func (h *Handler) SetRateLimiter(limiter *ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetAccessPolicy configures per-tool access control for tools/call
+// The caller's principal is read from the context (see auth.WithPrincipal)
+func (h *Handler) SetAccessPolicy(policy *auth.AccessPolicy) {
+	h.access = policy
+}
+
+// SetListPageSize returns tools/list and resources/list in pages of at
+// most n items, with a nextCursor for the next one; 0 returns whole lists
+func (h *Handler) SetListPageSize(n int) {
+	h.listPageSize = n
+}
+
+// SetAuditLogger records every tools/call to an audit log
+func (h *Handler) SetAuditLogger(l *audit.Logger) {
+	h.audit = l
 }
 
 // Handle processes a JSON-RPC request
@@ -49,18 +109,31 @@ func (h *Handler) Handle(ctx context.Context, data []byte, transportType string)
 		return h.errorResponse(nil, NewParseError(err))
 	}
 
-	h.logger.Debug("handling request",
+	// Transports assign HTTP calls their ID; others get one here
+	ctx, requestID := observability.EnsureRequestID(ctx)
+
+	h.logger.DebugContext(ctx, "handling request",
 		"method", req.Method,
 		"id", req.ID,
 		"transport", transportType)
 
+	ctx, span := observability.StartSpan(ctx, "jsonrpc "+req.Method,
+		trace.WithAttributes(
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", req.Method),
+			attribute.String("rpc.jsonrpc.request_id", fmt.Sprint(req.ID)),
+			attribute.String("mcp.request_id", requestID),
+			attribute.String("mcp.transport", transportType),
+		))
+	defer span.End()
+
 	var resp Response
 	resp.JSONRPC = "2.0"
 	resp.ID = req.ID
 
 	switch req.Method {
 	case "tools/list":
-		result, err := h.handleToolsList(ctx)
+		result, err := h.handleToolsList(ctx, req.Params)
 		if err != nil {
 			resp.Error = err
 		} else {
@@ -68,7 +141,39 @@ func (h *Handler) Handle(ctx context.Context, data []byte, transportType string)
 		}
 
 	case "tools/call":
-		result, err := h.handleToolsCall(ctx, req.Params)
+		result, err := h.handleToolsCall(ctx, req.Params, transportType)
+		if err != nil {
+			resp.Error = err
+		} else {
+			resp.Result = result
+		}
+
+	case "resources/list":
+		result, err := h.handleResourcesList(ctx, req.Params)
+		if err != nil {
+			resp.Error = err
+		} else {
+			resp.Result = result
+		}
+
+	case "resources/read":
+		result, err := h.handleResourcesRead(ctx, req.Params)
+		if err != nil {
+			resp.Error = err
+		} else {
+			resp.Result = result
+		}
+
+	case "resources/subscribe":
+		result, err := h.handleResourcesSubscribe(ctx, req.Params)
+		if err != nil {
+			resp.Error = err
+		} else {
+			resp.Result = result
+		}
+
+	case "resources/unsubscribe":
+		result, err := h.handleResourcesUnsubscribe(ctx, req.Params)
 		if err != nil {
 			resp.Error = err
 		} else {
@@ -79,29 +184,194 @@ func (h *Handler) Handle(ctx context.Context, data []byte, transportType string)
 		resp.Error = NewMethodNotFound(req.Method)
 	}
 
+	if resp.Error != nil {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", resp.Error.Code))
+		span.SetStatus(codes.Error, resp.Error.Message)
+		resp.Error.Data = withRequestIDData(resp.Error.Data, requestID)
+	} else {
+		resp.Result = withRequestIDMeta(resp.Result, requestID)
+	}
 	return json.Marshal(resp)
 }
 
+// withRequestIDMeta adds the request ID to a result's _meta
+// Results are built per call (cached ones are decoded afresh), so they
+// are changed in place.
+func withRequestIDMeta(result interface{}, requestID string) interface{} {
+	switch r := result.(type) {
+	case ToolCallResult:
+		if r.Meta == nil {
+			r.Meta = make(map[string]interface{})
+		}
+		r.Meta["requestId"] = requestID
+		return r
+	case map[string]interface{}:
+		meta, _ := r["_meta"].(map[string]interface{})
+		if meta == nil {
+			meta = make(map[string]interface{})
+		}
+		meta["requestId"] = requestID
+		r["_meta"] = meta
+	}
+	return result
+}
+
+// withRequestIDData adds the request ID to an error's data, if it is
+// empty or an object; errors with other data (e.g. the method name of
+// "Method not found") keep it, and HTTP clients still get X-Request-ID
+func withRequestIDData(data interface{}, requestID string) interface{} {
+	switch d := data.(type) {
+	case nil:
+		return map[string]interface{}{"requestId": requestID}
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(d)+1)
+		for k, v := range d {
+			copied[k] = v
+		}
+		copied["requestId"] = requestID
+		return copied
+	}
+	return data
+}
+
 // handleToolsList handles the tools/list method
-func (h *Handler) handleToolsList(ctx context.Context) (interface{}, *Error) {
+func (h *Handler) handleToolsList(ctx context.Context, params map[string]interface{}) (interface{}, *Error) {
+	// Backends list tools in no particular order; cursors need a stable one
 	tools := h.backend.ListTools()
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
 
-	toolInfos := make([]ToolInfo, len(tools))
-	for i, tool := range tools {
+	page, err := listPage(h, tools, params)
+	if err != nil {
+		return nil, err
+	}
+
+	toolInfos := make([]ToolInfo, len(page.Items))
+	for i, tool := range page.Items {
 		toolInfos[i] = ToolInfo{
-			Name:        tool.Name,
-			Description: tool.Description,
-			InputSchema: h.convertParametersToSchema(tool.Parameters),
+			Name:         tool.Name,
+			Description:  tool.Description,
+			InputSchema:  h.convertParametersToSchema(tool.Parameters),
+			OutputSchema: tool.OutputSchema,
 		}
 	}
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"tools": toolInfos,
-	}, nil
+	}
+	if page.NextCursor != "" {
+		result["nextCursor"] = page.NextCursor
+	}
+	return result, nil
 }
 
-// handleToolsCall handles the tools/call method WITH CACHING
-func (h *Handler) handleToolsCall(ctx context.Context, params map[string]interface{}) (interface{}, *Error) {
+// handleResourcesList handles the resources/list method
+func (h *Handler) handleResourcesList(ctx context.Context, params map[string]interface{}) (interface{}, *Error) {
+	page, err := listPage(h, h.backend.ListResources(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"resources": page.Items,
+	}
+	if page.NextCursor != "" {
+		result["nextCursor"] = page.NextCursor
+	}
+	return result, nil
+}
+
+// listPage returns the page of a list method's items its cursor asks for
+func listPage[T any](h *Handler, items []T, params map[string]interface{}) (backend.Page[T], *Error) {
+	cursor, _ := params["cursor"].(string)
+	limit := h.listPageSize
+	if limit <= 0 {
+		limit = max(len(items), 1)
+	}
+	page, err := backend.Paginate(items, backend.PageRequest{Cursor: cursor, Limit: limit})
+	if err != nil {
+		return page, NewInvalidParams(err.Error())
+	}
+	return page, nil
+}
+
+// handleToolsCall handles the tools/call method, recording its metrics
+// Calls of unknown tools are not recorded, keeping the tool label bounded.
+func (h *Handler) handleToolsCall(ctx context.Context, params map[string]interface{}, transportType string) (interface{}, *Error) {
+	start := time.Now()
+	record := &callRecord{}
+	result, protoErr := h.callTool(context.WithValue(ctx, callRecordKey{}, record), params, transportType)
+	duration := time.Since(start)
+
+	toolName, _ := params["name"].(string)
+	status, code := "success", callErrorCode(result, protoErr)
+	if code != "" {
+		status = "error"
+	}
+	if _, known := h.backend.GetTool(toolName); known {
+		if code != "" {
+			observability.RecordToolError(toolName, transportType, code)
+		}
+		observability.RecordToolCall(toolName, status, transportType)
+		observability.RecordToolDuration(toolName, transportType, duration)
+	}
+
+	if h.audit != nil {
+		args, _ := params["arguments"].(map[string]interface{})
+		h.audit.Record(ctx, audit.Event{
+			Transport:  transportType,
+			Tool:       toolName,
+			Arguments:  args,
+			Status:     status,
+			ErrorCode:  code,
+			DurationMS: float64(duration.Microseconds()) / 1000,
+			CacheHit:   record.cacheHit,
+		})
+	}
+	return result, protoErr
+}
+
+// callRecord collects facts about a call that its result does not carry
+type callRecord struct {
+	cacheHit bool
+}
+
+type callRecordKey struct{}
+
+// noteCacheHit notes on the call's record that the cache answered it
+func noteCacheHit(ctx context.Context) {
+	if record, ok := ctx.Value(callRecordKey{}).(*callRecord); ok {
+		record.cacheHit = true
+	}
+}
+
+// callErrorCode returns the error code metrics record for a failed call,
+// or "" for a successful one
+func callErrorCode(result interface{}, protoErr *Error) string {
+	if protoErr == nil {
+		if callResult, ok := result.(ToolCallResult); ok && callResult.IsError {
+			return "tool_error"
+		}
+		return ""
+	}
+	if data, ok := protoErr.Data.(map[string]interface{}); ok {
+		if code, ok := data["code"]; ok {
+			return fmt.Sprint(code)
+		}
+	}
+	switch protoErr.Code {
+	case InvalidParams:
+		return "invalid_params"
+	case Unauthorized:
+		return "unauthenticated"
+	case Forbidden:
+		return string(mcperr.CodePermissionDenied)
+	}
+	return string(mcperr.CodeInternal)
+}
+
+// callTool runs a tools/call request through access control, rate
+// limits, validation and the cache
+func (h *Handler) callTool(ctx context.Context, params map[string]interface{}, transportType string) (interface{}, *Error) {
 	toolName, ok := params["name"].(string)
 	if !ok {
 		return nil, NewInvalidParams("missing or invalid 'name' parameter")
@@ -111,6 +381,18 @@ func (h *Handler) handleToolsCall(ctx context.Context, params map[string]interfa
 	if !ok {
 		args = make(map[string]interface{})
 	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("mcp.tool.name", toolName))
+
+	ctx = h.withProgress(ctx, params)
+
+	ctx, protoErr := h.withLogging(ctx, toolName, params)
+	if protoErr != nil {
+		return nil, protoErr
+	}
+	ctx, protoErr = withRequestTimeout(ctx, params)
+	if protoErr != nil {
+		return nil, protoErr
+	}
 
 	// === NEW: Get tool definition to check if cacheable ===
 	tool, exists := h.backend.GetTool(toolName)
@@ -118,84 +400,306 @@ func (h *Handler) handleToolsCall(ctx context.Context, params map[string]interfa
 		return nil, NewInternalError(fmt.Errorf("tool not found: %s", toolName))
 	}
 
+	// Enforce per-tool access policies and required scopes
+	if err := h.authorize(ctx, tool, transportType); err != nil {
+		return nil, err
+	}
+
+	// Enforce rate limits before any work (including cache lookups)
+	if h.limiter != nil {
+		if d := h.limiter.Allow(toolName, ratelimit.ClientIDFromContext(ctx)); !d.Allowed {
+			observability.RecordRateLimitRejection(string(d.Scope), toolName)
+			h.logger.WarnContext(ctx, "rate limit exceeded",
+				"tool", toolName,
+				"scope", d.Scope,
+				"retry_after", d.RetryAfter)
+			return nil, NewRateLimitError(string(d.Scope), d.RetryAfter)
+		}
+	}
+
+	// Reject arguments that violate the advertised input schema
+	if err := backend.ValidateArguments(tool, args); err != nil {
+		h.logger.DebugContext(ctx, "invalid tool arguments", "tool", toolName, "error", err)
+		return nil, toolError(err)
+	}
+
+	// Enforce the tool's (or client's) deadline
+	if timeout := backend.ResolveTimeout(ctx, tool, 0); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var result interface{}
+
 	// === NEW: Cache logic ===
 	if h.cache != nil && h.keyGen != nil && tool.IsCacheable() {
-		return h.handleCachedToolCall(ctx, toolName, args, tool)
+		result, protoErr = h.handleCachedToolCall(ctx, toolName, args, tool)
+	} else {
+		// No cache or tool not cacheable - execute directly
+		result, protoErr = h.executeToolAndConvert(ctx, tool, args)
+	}
+	if protoErr != nil {
+		return nil, protoErr
+	}
+
+	result, protoErr = h.filterOutput(ctx, tool, result)
+	if protoErr != nil {
+		return nil, protoErr
+	}
+	return h.limitResult(ctx, tool, result)
+}
+
+// withProgress routes backend.EmitProgress to notifications/progress
+// when the request carries _meta.progressToken and the transport can notify
+func (h *Handler) withProgress(ctx context.Context, params map[string]interface{}) context.Context {
+	meta, _ := params["_meta"].(map[string]interface{})
+	token, ok := meta["progressToken"]
+	if !ok || token == nil {
+		return ctx
+	}
+
+	notifier, ok := transport.NotifierFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return backend.WithProgressReporter(ctx, func(current, total int64, message string) error {
+		progress := map[string]interface{}{
+			"progressToken": token,
+			"progress":      current,
+		}
+		if total > 0 {
+			progress["total"] = total
+		}
+		if message != "" {
+			progress["message"] = message
+		}
+
+		data, err := json.Marshal(Notification{
+			JSONRPC: "2.0",
+			Method:  "notifications/progress",
+			Params:  progress,
+		})
+		if err != nil {
+			return err
+		}
+		return notifier.Notify(data)
+	})
+}
+
+// withLogging routes the tool's logs (backend.EmitLog, and EmitStreamLog of
+// streaming tools run to completion) to notifications/message when the
+// transport can notify, dropping those below _meta.logLevel (default
+// engine.DefaultLogLevel)
+func (h *Handler) withLogging(ctx context.Context, toolName string, params map[string]interface{}) (context.Context, *Error) {
+	meta, _ := params["_meta"].(map[string]interface{})
+	minLevel, _ := meta["logLevel"].(string)
+	if minLevel != "" {
+		if err := engine.ValidateLogLevel(minLevel); err != nil {
+			return ctx, NewInvalidParams(err.Error())
+		}
+	}
+
+	notifier, ok := transport.NotifierFromContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	return backend.WithLogReporter(ctx, func(level, message string, fields map[string]interface{}) error {
+		if err := engine.ValidateLogLevel(level); err != nil {
+			return err
+		}
+		if !engine.LogLevelEnabled(level, minLevel) {
+			return nil
+		}
+
+		data := map[string]interface{}{"message": message}
+		for k, v := range fields {
+			if k != "message" {
+				data[k] = v
+			}
+		}
+		notification, err := json.Marshal(Notification{
+			JSONRPC: "2.0",
+			Method:  "notifications/message",
+			Params: map[string]interface{}{
+				"level":  level,
+				"logger": toolName,
+				"data":   data,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		return notifier.Notify(notification)
+	}), nil
+}
+
+// withRequestTimeout applies a client-requested _meta.timeout (seconds or duration string)
+func withRequestTimeout(ctx context.Context, params map[string]interface{}) (context.Context, *Error) {
+	meta, _ := params["_meta"].(map[string]interface{})
+	value, ok := meta["timeout"]
+	if !ok || value == nil {
+		return ctx, nil
 	}
 
-	// No cache or tool not cacheable - execute directly
-	return h.executeToolAndConvert(ctx, toolName, args)
+	timeout, err := backend.TimeoutFromValue(value)
+	if err != nil {
+		return ctx, NewInvalidParams(err.Error())
+	}
+	return backend.WithRequestTimeout(ctx, timeout), nil
+}
+
+// toolError maps a tool execution error to a protocol error
+func toolError(err error) *Error {
+	var argErr *backend.ArgumentError
+	if errors.As(err, &argErr) {
+		return NewError(InvalidParams, "Invalid params", map[string]interface{}{
+			"message": err.Error(),
+			"fields":  argErr.Fields,
+		})
+	}
+	if errors.Is(err, backend.ErrInvalidArguments) {
+		return NewInvalidParams(err.Error())
+	}
+	if categorized, ok := mcperr.As(err); ok {
+		return NewCategorizedError(categorized)
+	}
+	return NewInternalError(err)
+}
+
+// authorize checks the caller's principal against access policies and the tool's required scopes
+func (h *Handler) authorize(ctx context.Context, tool backend.ToolDefinition, transportType string) *Error {
+	principal, _ := auth.PrincipalFromContext(ctx)
+
+	err := h.access.Authorize(tool.Name, principal)
+	if err == nil {
+		err = auth.RequireScopes(principal, tool.RequiredScopes...)
+	}
+	if err == nil {
+		return nil
+	}
+
+	h.logger.WarnContext(ctx, "tool access denied", "tool", tool.Name, "error", err)
+
+	if errors.Is(err, auth.ErrUnauthenticated) {
+		observability.RecordAuthFailure("missing", transportType)
+		return NewUnauthorizedError(err.Error())
+	}
+
+	observability.RecordAuthFailure("forbidden", transportType)
+	var scopeErr *auth.ScopeError
+	if errors.As(err, &scopeErr) {
+		return NewError(Forbidden, "Forbidden", map[string]interface{}{
+			"message":        err.Error(),
+			"missing_scopes": scopeErr.Missing,
+		})
+	}
+	return NewForbiddenError(err.Error())
 }
 
 // === NEW: handleCachedToolCall implements cache-aware tool execution ===
 func (h *Handler) handleCachedToolCall(ctx context.Context, toolName string, args map[string]interface{}, tool backend.ToolDefinition) (interface{}, *Error) {
 	// Generate cache key
-	cacheKey, err := h.keyGen.Generate(toolName, args)
+	cacheKey, err := h.keyGen.GenerateScoped(ctx, toolName, args, cache.KeyScope{
+		Version:     tool.Cache.Version,
+		PerIdentity: tool.Cache.PerPrincipal,
+	})
 	if err != nil {
-		h.logger.Warn("cache key generation failed, executing without cache",
+		h.logger.WarnContext(ctx, "cache key generation failed, executing without cache",
 			"tool", toolName,
 			"error", err)
-		return h.executeToolAndConvert(ctx, toolName, args)
+		return h.executeToolAndConvert(ctx, tool, args)
 	}
 
 	// Try to get from cache
+	_, span := observability.StartSpan(ctx, "cache.get", trace.WithAttributes(attribute.String("mcp.tool.name", toolName)))
 	entry, err := h.cache.Get(ctx, cacheKey)
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil && entry != nil))
+	span.End()
+	observability.RecordCacheLookup(toolName, err == nil && entry != nil)
 	if err == nil && entry != nil {
 		// Cache hit!
-		h.logger.Debug("cache hit",
+		noteCacheHit(ctx)
+		h.logger.DebugContext(ctx, "cache hit",
 			"tool", toolName,
 			"key", cacheKey,
 			"age", entry.Age(),
 			"hits", entry.Hits)
 
+		// Decode typed results when provenance or output filters need them
+		if h.provenance != nil || len(h.filters) > 0 {
+			var callResult ToolCallResult
+			if err := entry.Unmarshal(&callResult); err == nil {
+				if h.provenance != nil {
+					h.markCacheHit(&callResult, tool)
+				}
+				return callResult, nil
+			}
+		}
+
 		// Deserialize cached result
 		var cachedResult interface{}
 		if err := entry.Unmarshal(&cachedResult); err != nil {
-			h.logger.Warn("cache deserialization failed, executing",
+			h.logger.WarnContext(ctx, "cache deserialization failed, executing",
 				"tool", toolName,
 				"error", err)
-			return h.executeToolAndConvert(ctx, toolName, args)
+			return h.executeToolAndConvert(ctx, tool, args)
 		}
 
 		return cachedResult, nil
 	}
 
 	// Cache miss - execute tool
-	h.logger.Debug("cache miss",
+	h.logger.DebugContext(ctx, "cache miss",
 		"tool", toolName,
 		"key", cacheKey)
 
-	result, protoErr := h.executeToolAndConvert(ctx, toolName, args)
+	result, protoErr := h.executeToolAndConvert(ctx, tool, args)
 	if protoErr != nil {
 		// Don't cache errors
 		return nil, protoErr
 	}
+	if callResult, ok := result.(ToolCallResult); ok && callResult.IsError {
+		return result, nil
+	}
 
 	// Store result in cache
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
-		h.logger.Warn("failed to serialize result for caching",
+		h.logger.WarnContext(ctx, "failed to serialize result for caching",
 			"tool", toolName,
 			"error", err)
 		// Still return the result, just don't cache it
 		return result, nil
 	}
 
-	// Get TTL for this tool
+	// Get TTL for this tool; configured per-tool TTLs override the tool's
 	var ttl time.Duration
-	if h.config != nil {
-		ttl = tool.GetCacheTTL(h.config.GetTTLDuration())
+	if config := h.config.Load(); config != nil {
+		ttl = tool.GetCacheTTL(config.GetTTLDuration())
+		if override, ok := config.ToolTTL[toolName]; ok {
+			ttl = override
+		}
 	} else {
 		ttl = tool.GetCacheTTL(5 * time.Minute) // Fallback default
 	}
 
-	if err := h.cache.Set(ctx, cacheKey, resultJSON, ttl); err != nil {
-		h.logger.Warn("failed to cache result",
+	_, span = observability.StartSpan(ctx, "cache.set", trace.WithAttributes(
+		attribute.String("mcp.tool.name", toolName),
+		attribute.Int("cache.entry_size", len(resultJSON)),
+		attribute.String("cache.ttl", ttl.String()),
+	))
+	err = h.cache.Set(ctx, cacheKey, resultJSON, ttl)
+	observability.EndSpan(span, err)
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to cache result",
 			"tool", toolName,
 			"error", err)
 		// Still return the result, caching is not critical
 	} else {
-		h.logger.Debug("cached result",
+		h.logger.DebugContext(ctx, "cached result",
 			"tool", toolName,
 			"key", cacheKey,
 			"ttl", ttl)
@@ -205,15 +709,33 @@ func (h *Handler) handleCachedToolCall(ctx context.Context, toolName string, arg
 }
 
 // === NEW: executeToolAndConvert is a helper to execute and convert results ===
-func (h *Handler) executeToolAndConvert(ctx context.Context, toolName string, args map[string]interface{}) (interface{}, *Error) {
+func (h *Handler) executeToolAndConvert(ctx context.Context, tool backend.ToolDefinition, args map[string]interface{}) (interface{}, *Error) {
+	sources := func() []string { return nil }
+	if h.provenance != nil {
+		ctx, sources = backend.WithSourceRecorder(ctx)
+	}
+
 	// Execute tool
-	result, err := h.backend.CallTool(ctx, toolName, args)
+	result, err := h.backend.CallTool(ctx, tool.Name, args)
 	if err != nil {
-		return nil, NewInternalError(err)
+		// Domain failures are results, not protocol errors
+		if toolErr, ok := backend.AsToolError(err); ok {
+			h.logger.DebugContext(ctx, "tool returned an error result", "tool", tool.Name, "error", err)
+			callResult := h.convertContentResult(tool, backend.NewResult(toolErr.ResultContent()...))
+			callResult.IsError = true
+			h.stampProvenance(&callResult, tool, sources())
+			return callResult, nil
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, toolError(mcperr.Wrap(err, mcperr.CodeTimeout, "tool %s timed out", tool.Name))
+		}
+		return nil, toolError(err)
 	}
 
 	// Convert result to MCP format
-	return h.convertToToolCallResult(result), nil
+	callResult := h.convertToToolCallResult(tool, result)
+	h.stampProvenance(&callResult, tool, sources())
+	return callResult, nil
 }
 
 // convertParametersToSchema converts tool parameters to JSON Schema
@@ -222,6 +744,14 @@ func (h *Handler) convertParametersToSchema(params []backend.Parameter) map[stri
 	required := make([]string, 0)
 
 	for _, param := range params {
+		if param.Schema != nil {
+			properties[param.Name] = param.Schema
+			if param.Required {
+				required = append(required, param.Name)
+			}
+			continue
+		}
+
 		prop := map[string]interface{}{
 			"type":        param.Type,
 			"description": param.Description,
@@ -263,7 +793,13 @@ func (h *Handler) convertParametersToSchema(params []backend.Parameter) map[stri
 }
 
 // convertToToolCallResult converts a result to MCP ToolCallResult format
-func (h *Handler) convertToToolCallResult(result interface{}) ToolCallResult {
+// Tools declaring an output schema also return the result as structuredContent.
+func (h *Handler) convertToToolCallResult(tool backend.ToolDefinition, result interface{}) ToolCallResult {
+	// Results made of explicit content items are sent as-is
+	if typed, ok := backend.AsToolResult(result); ok {
+		return h.convertContentResult(tool, typed)
+	}
+
 	// Convert result to JSON string
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
@@ -277,7 +813,7 @@ func (h *Handler) convertToToolCallResult(result interface{}) ToolCallResult {
 		}
 	}
 
-	return ToolCallResult{
+	callResult := ToolCallResult{
 		Content: []ContentItem{
 			{
 				Type: "text",
@@ -285,6 +821,44 @@ func (h *Handler) convertToToolCallResult(result interface{}) ToolCallResult {
 			},
 		},
 	}
+
+	if tool.OutputSchema != nil {
+		// structuredContent must be a JSON object
+		var structured map[string]interface{}
+		if err := json.Unmarshal(resultJSON, &structured); err == nil && structured != nil {
+			callResult.StructuredContent = structured
+		} else {
+			h.logger.Warn("tool declares an output schema but returned a non-object result",
+				"tool", tool.Name)
+		}
+	}
+
+	return callResult
+}
+
+// convertContentResult converts a backend.ToolResult to MCP ToolCallResult format
+func (h *Handler) convertContentResult(tool backend.ToolDefinition, result *backend.ToolResult) ToolCallResult {
+	callResult := ToolCallResult{
+		Content: make([]ContentItem, 0, len(result.Content)),
+	}
+
+	for _, c := range result.Content {
+		item, err := NewContentItem(c)
+		if err != nil {
+			h.logger.Warn("dropping unencodable content item",
+				"tool", tool.Name,
+				"type", c.ContentType(),
+				"error", err)
+			continue
+		}
+		callResult.Content = append(callResult.Content, item)
+	}
+
+	if tool.OutputSchema != nil {
+		callResult.StructuredContent = result.Structured
+	}
+
+	return callResult
 }
 
 // errorResponse creates an error response
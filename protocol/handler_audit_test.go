@@ -0,0 +1,90 @@
+package protocol_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/audit"
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+type auditSink struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (s *auditSink) Write(e audit.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+func (s *auditSink) Close() error { return nil }
+
+func TestHandler_Audit(t *testing.T) {
+	b := backend.NewBaseBackend("audited")
+	b.RegisterTool(backend.NewTool("file_write").
+		StringParam("path", "File path", true).
+		StringParam("content", "File content", true).
+		Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "written", nil
+		})
+	b.RegisterTool(backend.NewTool("lookup").WithCache(true, time.Minute).Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, mcperr.NotFound("no such record")
+		})
+	b.RegisterTool(backend.NewTool("cached").WithCache(true, time.Minute).Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+
+	handler := protocol.NewHandler(b, nil)
+	config := &cache.Config{Type: cache.TypeShort, TTL: 60, MaxSize: 10, Enabled: true}
+	c, _ := cache.New(config)
+	handler.SetCache(c, cache.NewKeyGenerator(), config)
+	sink := &auditSink{}
+	l := audit.NewWithSink(sink, map[string][]string{"file_write": {"content"}}, 0, nil)
+	handler.SetAuditLogger(l)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "alice", Method: "bearer"})
+	call := func(tool, args string) {
+		request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q,"arguments":%s}}`, tool, args)
+		if _, err := handler.Handle(ctx, []byte(request), "http"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	call("file_write", `{"path":"/a","content":"secret plans"}`)
+	call("lookup", `{}`)
+	call("cached", `{}`)
+	call("cached", `{}`)
+	call("missing", `{}`)
+	l.Close()
+
+	if len(sink.events) != 5 {
+		t.Fatalf("got %d events, want 5", len(sink.events))
+	}
+	write := sink.events[0]
+	if write.Principal != "alice" || write.Transport != "http" || write.Status != "success" {
+		t.Errorf("file_write event = %+v", write)
+	}
+	if write.Arguments["content"] != audit.Redacted || write.Arguments["path"] != "/a" {
+		t.Errorf("file_write arguments = %v", write.Arguments)
+	}
+	if e := sink.events[1]; e.Status != "error" || e.ErrorCode != "not_found" {
+		t.Errorf("lookup event = %+v", e)
+	}
+	if sink.events[2].CacheHit || !sink.events[3].CacheHit {
+		t.Errorf("cache hits = %v, %v; want false, true", sink.events[2].CacheHit, sink.events[3].CacheHit)
+	}
+	if e := sink.events[4]; e.Tool != "missing" || e.Status != "error" {
+		t.Errorf("unknown tool event = %+v", e)
+	}
+}
@@ -0,0 +1,403 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
+)
+
+// Test: tools/call enforces scopes declared with RequireScope
+func TestHandler_RequiredScopes(t *testing.T) {
+	mb := newMockBackend()
+
+	var seen *auth.Principal
+	tool := backend.NewTool("write_file").
+		Description("Writes a file").
+		StringParam("path", "File path", true).
+		RequireScope("fs:write").
+		Build()
+	mb.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		mb.callCount++
+		seen, _ = auth.PrincipalFromContext(ctx)
+		return map[string]interface{}{"status": "written"}, nil
+	})
+
+	handler := protocol.NewHandler(mb, nil)
+
+	reqJSON, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "write_file",
+			"arguments": map[string]interface{}{"path": "/a"},
+		},
+	})
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		wantCode  int
+	}{
+		{"anonymous", nil, protocol.Unauthorized},
+		{"missing scope", &auth.Principal{ID: "reader", Scopes: []string{"fs:read"}}, protocol.Forbidden},
+		{"granted", &auth.Principal{ID: "writer", Scopes: []string{"fs:read", "fs:write"}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tt.principal)
+			}
+
+			respJSON, err := handler.Handle(ctx, reqJSON, "test")
+			if err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+
+			var resp protocol.Response
+			if err := json.Unmarshal(respJSON, &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			if tt.wantCode == 0 {
+				if resp.Error != nil {
+					t.Fatalf("unexpected error: %+v", resp.Error)
+				}
+				if seen == nil || seen.ID != tt.principal.ID {
+					t.Errorf("handler saw principal %+v, want %q", seen, tt.principal.ID)
+				}
+				return
+			}
+			if resp.Error == nil || resp.Error.Code != tt.wantCode {
+				t.Errorf("error = %+v, want code %d", resp.Error, tt.wantCode)
+			}
+		})
+	}
+
+	if mb.callCount != 1 {
+		t.Errorf("callCount = %d, want 1 (rejected calls must not execute)", mb.callCount)
+	}
+}
+
+// Test: tools/call rejects arguments violating the input schema before dispatch
+func TestHandler_ValidatesArguments(t *testing.T) {
+	mb := newMockBackend()
+	handler := protocol.NewHandler(mb, nil)
+
+	reqJSON, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "create_file",
+			"arguments": map[string]interface{}{"path": 7},
+		},
+	})
+
+	respJSON, err := handler.Handle(context.Background(), reqJSON, "test")
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	var resp struct {
+		Error *struct {
+			Code int `json:"code"`
+			Data struct {
+				Fields []backend.FieldError `json:"fields"`
+			} `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if resp.Error == nil || resp.Error.Code != protocol.InvalidParams {
+		t.Fatalf("error = %+v, want InvalidParams", resp.Error)
+	}
+	if len(resp.Error.Data.Fields) != 1 || resp.Error.Data.Fields[0].Field != "path" {
+		t.Errorf("fields = %+v, want path", resp.Error.Data.Fields)
+	}
+	if mb.callCount != 0 {
+		t.Errorf("handler called %d times, want 0", mb.callCount)
+	}
+}
+
+// Test: tools with an output schema advertise it and return structuredContent
+func TestHandler_StructuredContent(t *testing.T) {
+	type forecast struct {
+		Summary string  `json:"summary"`
+		TempC   float64 `json:"temp_c"`
+	}
+
+	mb := newMockBackend()
+	tool := backend.NewTool("get_weather").
+		StringParam("city", "City", true).
+		OutputFromStruct(forecast{}).
+		Build()
+	mb.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return forecast{Summary: "sunny", TempC: 31}, nil
+	})
+	handler := protocol.NewHandler(mb, nil)
+
+	listJSON, _ := handler.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`), "test")
+	var list struct {
+		Result struct {
+			Tools []protocol.ToolInfo `json:"tools"`
+		} `json:"result"`
+	}
+	json.Unmarshal(listJSON, &list)
+	for _, info := range list.Result.Tools {
+		if info.Name == "get_weather" && info.OutputSchema["type"] != "object" {
+			t.Errorf("outputSchema = %v", info.OutputSchema)
+		}
+		if info.Name == "read_file" && info.OutputSchema != nil {
+			t.Errorf("read_file advertises an output schema")
+		}
+	}
+
+	callJSON, _ := handler.Handle(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_weather","arguments":{"city":"Cairo"}}}`), "test")
+	var call struct {
+		Result protocol.ToolCallResult `json:"result"`
+	}
+	if err := json.Unmarshal(callJSON, &call); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if call.Result.StructuredContent["summary"] != "sunny" {
+		t.Errorf("structuredContent = %v", call.Result.StructuredContent)
+	}
+	if len(call.Result.Content) != 1 || call.Result.Content[0].Type != "text" {
+		t.Errorf("content = %+v, want text fallback", call.Result.Content)
+	}
+}
+
+func TestHandler_RichContent(t *testing.T) {
+	mb := newMockBackend()
+	tool := backend.NewTool("screenshot").Build()
+	mb.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return backend.NewImageResult([]byte("png"), "image/png").
+			Add(backend.ResourceLink{URI: "file:///shot.png", Name: "shot.png"}).
+			Add(backend.NewTextResource("file:///notes.txt", "text/plain", "notes")), nil
+	})
+	handler := protocol.NewHandler(mb, nil)
+
+	callJSON, _ := handler.Handle(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"screenshot","arguments":{}}}`), "test")
+	var call struct {
+		Result protocol.ToolCallResult `json:"result"`
+	}
+	if err := json.Unmarshal(callJSON, &call); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	content := call.Result.Content
+	if len(content) != 3 {
+		t.Fatalf("content = %+v, want 3 items", content)
+	}
+	if content[0].Type != "image" || content[0].MimeType != "image/png" || content[0].Data != "cG5n" {
+		t.Errorf("image item = %+v", content[0])
+	}
+	if content[1].Type != "resource_link" || content[1].URI != "file:///shot.png" {
+		t.Errorf("resource link item = %+v", content[1])
+	}
+	if content[2].Type != "resource" || content[2].Resource == nil || content[2].Resource.Text != "notes" {
+		t.Errorf("embedded resource item = %+v", content[2])
+	}
+}
+
+func TestHandler_ToolErrorResult(t *testing.T) {
+	mb := newMockBackend()
+	tool := backend.NewTool("lookup").StringParam("id", "ID", true).Build()
+	mb.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		if args["id"] == "missing" {
+			return nil, backend.NewToolError("record %s not found", args["id"])
+		}
+		return nil, errors.New("database unavailable")
+	})
+	handler := protocol.NewHandler(mb, nil)
+
+	callJSON, _ := handler.Handle(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"lookup","arguments":{"id":"missing"}}}`), "test")
+	var call struct {
+		Result *protocol.ToolCallResult `json:"result"`
+		Error  *protocol.Error          `json:"error"`
+	}
+	if err := json.Unmarshal(callJSON, &call); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if call.Error != nil || call.Result == nil {
+		t.Fatalf("expected a result, got %s", callJSON)
+	}
+	if !call.Result.IsError || len(call.Result.Content) != 1 || call.Result.Content[0].Text != "record missing not found" {
+		t.Errorf("result = %+v", call.Result)
+	}
+
+	// Other errors remain protocol errors
+	callJSON, _ = handler.Handle(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"lookup","arguments":{"id":"x"}}}`), "test")
+	call.Result, call.Error = nil, nil
+	json.Unmarshal(callJSON, &call)
+	if call.Error == nil || call.Error.Code != protocol.InternalError {
+		t.Errorf("expected internal error, got %s", callJSON)
+	}
+}
+
+func TestHandler_CategorizedErrors(t *testing.T) {
+	mb := newMockBackend()
+	tool := backend.NewTool("fetch").Build()
+	mb.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, mcperr.RateLimited(30*time.Second, "upstream quota exhausted")
+	})
+	handler := protocol.NewHandler(mb, nil)
+
+	callJSON, _ := handler.Handle(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fetch","arguments":{}}}`), "test")
+	var resp struct {
+		Error *protocol.Error `json:"error"`
+	}
+	json.Unmarshal(callJSON, &resp)
+
+	if resp.Error == nil || resp.Error.Code != protocol.RateLimitExceeded {
+		t.Fatalf("expected rate limit error, got %s", callJSON)
+	}
+	data, _ := resp.Error.Data.(map[string]interface{})
+	if data["code"] != "rate_limited" || data["retryable"] != true || data["retry_after"] != float64(30) {
+		t.Errorf("data = %v", data)
+	}
+}
+
+func TestHandler_ProgressNotifications(t *testing.T) {
+	mb := newMockBackend()
+	tool := backend.NewTool("index").Build()
+	mb.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		for i := int64(1); i <= 2; i++ {
+			if err := backend.EmitProgress(ctx, i, 2, "indexing"); err != nil {
+				return nil, err
+			}
+		}
+		return "done", nil
+	})
+	handler := protocol.NewHandler(mb, nil)
+
+	var notifications []protocol.Notification
+	ctx := transport.WithNotifier(context.Background(), transport.NotifierFunc(func(message []byte) error {
+		var n protocol.Notification
+		json.Unmarshal(message, &n)
+		notifications = append(notifications, n)
+		return nil
+	}))
+
+	handler.Handle(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"index","arguments":{},"_meta":{"progressToken":"abc"}}}`), "stdio")
+
+	if len(notifications) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(notifications))
+	}
+	n := notifications[1]
+	if n.Method != "notifications/progress" || n.Params["progressToken"] != "abc" ||
+		n.Params["progress"] != float64(2) || n.Params["total"] != float64(2) {
+		t.Errorf("notification = %+v", n)
+	}
+
+	// No token, no notifications
+	notifications = nil
+	handler.Handle(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"index","arguments":{}}}`), "stdio")
+	if len(notifications) != 0 {
+		t.Errorf("got %d notifications without a progressToken", len(notifications))
+	}
+}
+
+func TestHandler_LogNotifications(t *testing.T) {
+	mb := newMockBackend()
+	mb.RegisterTool(backend.NewTool("scan").Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		backend.EmitLog(ctx, backend.LogDebug, "opening", nil)
+		backend.EmitLog(ctx, backend.LogWarning, "skipped unreadable file", map[string]interface{}{"path": "a.txt"})
+		return "done", nil
+	})
+	handler := protocol.NewHandler(mb, nil)
+
+	var notifications []protocol.Notification
+	ctx := transport.WithNotifier(context.Background(), transport.NotifierFunc(func(message []byte) error {
+		var n protocol.Notification
+		json.Unmarshal(message, &n)
+		notifications = append(notifications, n)
+		return nil
+	}))
+
+	// Debug logs are below the default level
+	handler.Handle(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"scan","arguments":{}}}`), "stdio")
+	if len(notifications) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(notifications))
+	}
+	n := notifications[0]
+	data, _ := n.Params["data"].(map[string]interface{})
+	if n.Method != "notifications/message" || n.Params["level"] != "warning" || n.Params["logger"] != "scan" ||
+		data["message"] != "skipped unreadable file" || data["path"] != "a.txt" {
+		t.Errorf("notification = %+v", n)
+	}
+
+	notifications = nil
+	handler.Handle(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"scan","arguments":{},"_meta":{"logLevel":"debug"}}}`), "stdio")
+	if len(notifications) != 2 {
+		t.Errorf("got %d notifications at debug level, want 2", len(notifications))
+	}
+
+	resp, _ := handler.Handle(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"scan","arguments":{},"_meta":{"logLevel":"loud"}}}`), "stdio")
+	if !strings.Contains(string(resp), `"code":-32602`) {
+		t.Errorf("unknown log level response = %s", resp)
+	}
+}
+
+func TestHandler_ToolTimeout(t *testing.T) {
+	mb := newMockBackend()
+	tool := backend.NewTool("slow").Timeout(time.Second).Build()
+
+	var deadline time.Duration
+	mb.RegisterTool(tool, func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		d, _ := ctx.Deadline()
+		deadline = time.Until(d)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	handler := protocol.NewHandler(mb, nil)
+
+	// _meta.timeout shortens the tool's one-second deadline
+	callJSON, _ := handler.Handle(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{},"_meta":{"timeout":"20ms"}}}`), "test")
+	var resp struct {
+		Error *protocol.Error `json:"error"`
+	}
+	json.Unmarshal(callJSON, &resp)
+
+	if resp.Error == nil || resp.Error.Code != protocol.RequestTimeout {
+		t.Fatalf("expected request timeout, got %s", callJSON)
+	}
+	if deadline > 20*time.Millisecond {
+		t.Errorf("deadline = %v, want <= 20ms", deadline)
+	}
+
+	// A longer request timeout cannot extend the tool's
+	handler.Handle(backend.WithRequestTimeout(context.Background(), 10*time.Millisecond),
+		[]byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"slow","arguments":{},"_meta":{"timeout":60}}}`), "test")
+	if deadline > 10*time.Millisecond {
+		t.Errorf("deadline = %v, want <= 10ms", deadline)
+	}
+
+	callJSON, _ = handler.Handle(context.Background(),
+		[]byte(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"slow","arguments":{},"_meta":{"timeout":"soon"}}}`), "test")
+	resp.Error = nil
+	json.Unmarshal(callJSON, &resp)
+	if resp.Error == nil || resp.Error.Code != protocol.InvalidParams {
+		t.Errorf("expected invalid params for bad timeout, got %s", callJSON)
+	}
+}
@@ -3,12 +3,16 @@ package protocol_test
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/SaherElMasry/go-mcp-framework/auth"
 	"github.com/SaherElMasry/go-mcp-framework/backend"
 	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
 	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
 )
 
 // mockBackend for testing
@@ -259,3 +263,190 @@ func TestHandler_WorksWithoutCache(t *testing.T) {
 
 	t.Logf("✓ Handler works correctly without cache")
 }
+
+// Test: Rate limited calls return a RateLimitExceeded error
+func TestHandler_RateLimit(t *testing.T) {
+	mb := newMockBackend()
+	handler := protocol.NewHandler(mb, nil)
+
+	limiter, err := ratelimit.New(&ratelimit.Config{
+		Enabled: true,
+		PerTool: map[string]ratelimit.Rule{"create_file": {Rate: 0.001, Burst: 1}},
+	})
+	if err != nil {
+		t.Fatalf("ratelimit.New() error = %v", err)
+	}
+	handler.SetRateLimiter(limiter)
+
+	reqJSON, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "create_file",
+			"arguments": map[string]interface{}{"path": "/a"},
+		},
+	})
+
+	ctx := context.Background()
+	if _, err := handler.Handle(ctx, reqJSON, "test"); err != nil {
+		t.Fatalf("first call error: %v", err)
+	}
+
+	respJSON, err := handler.Handle(ctx, reqJSON, "test")
+	if err != nil {
+		t.Fatalf("second call error: %v", err)
+	}
+
+	var resp protocol.Response
+	if err := json.Unmarshal(respJSON, &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != protocol.RateLimitExceeded {
+		t.Fatalf("error = %+v, want code %d", resp.Error, protocol.RateLimitExceeded)
+	}
+	if mb.callCount != 1 {
+		t.Errorf("callCount = %d, want 1 (rejected call must not execute)", mb.callCount)
+	}
+}
+
+// Test: Provenance metadata is attached and survives the cache
+func TestHandler_Provenance(t *testing.T) {
+	mb := newMockBackend()
+	mb.RegisterTool(backend.NewTool("fetch").
+		Version("1.2.0").
+		WithCache(true, time.Minute).
+		Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		backend.RecordSource(ctx, "https://api.example.com/data")
+		return map[string]interface{}{"ok": true}, nil
+	})
+
+	handler := protocol.NewHandler(mb, nil)
+	cacheConfig := &cache.Config{Type: cache.TypeShort, TTL: 60, MaxSize: 100, Enabled: true}
+	c, _ := cache.New(cacheConfig)
+	handler.SetCache(c, cache.NewKeyGenerator(), cacheConfig)
+	handler.SetProvenance(&protocol.ProvenanceConfig{Server: "test-server", ServerVersion: "0.3.0"})
+
+	reqJSON := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fetch"}}`)
+
+	call := func() protocol.Provenance {
+		t.Helper()
+		respJSON, err := handler.Handle(context.Background(), reqJSON, "test")
+		if err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+		var resp struct {
+			Result struct {
+				Meta struct {
+					Provenance protocol.Provenance `json:"provenance"`
+				} `json:"_meta"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(respJSON, &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp.Result.Meta.Provenance
+	}
+
+	first := call()
+	if first.Server != "test-server" || first.ServerVersion != "0.3.0" || first.Backend != "mock" {
+		t.Errorf("server fields = %+v", first)
+	}
+	if first.Tool != "fetch" || first.ToolVersion != "1.2.0" {
+		t.Errorf("tool fields = %+v", first)
+	}
+	if first.CacheHit || len(first.Sources) != 1 || first.Sources[0] != "https://api.example.com/data" {
+		t.Errorf("first call = %+v", first)
+	}
+	if first.Timestamp.IsZero() {
+		t.Error("missing timestamp")
+	}
+
+	second := call()
+	if !second.CacheHit {
+		t.Error("second call: cacheHit = false")
+	}
+	if !second.Timestamp.Equal(first.Timestamp) || len(second.Sources) != 1 {
+		t.Errorf("cached provenance = %+v, want the original's", second)
+	}
+}
+
+// Test: Output filters rewrite results, including cache hits, without
+// changing what is cached
+func TestHandler_OutputFilter(t *testing.T) {
+	mb := newMockBackend()
+	handler := protocol.NewHandler(mb, nil)
+	cacheConfig := &cache.Config{Type: cache.TypeShort, TTL: 60, MaxSize: 100, Enabled: true}
+	c, _ := cache.New(cacheConfig)
+	handler.SetCache(c, cache.NewKeyGenerator(), cacheConfig)
+
+	filtered := 0
+	handler.AddOutputFilter(func(ctx context.Context, tool string, result *protocol.ToolCallResult) error {
+		filtered++
+		result.RewriteText(func(s string) string {
+			return strings.ReplaceAll(s, "file contents", "[filtered]")
+		})
+		return nil
+	})
+
+	reqJSON := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_file","arguments":{"path":"/a"}}}`)
+	for i := 0; i < 2; i++ {
+		respJSON, err := handler.Handle(context.Background(), reqJSON, "test")
+		if err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+		if strings.Contains(string(respJSON), "file contents") || !strings.Contains(string(respJSON), "[filtered]") {
+			t.Errorf("call %d: response not filtered: %s", i+1, respJSON)
+		}
+	}
+	if filtered != 2 || mb.callCount != 1 {
+		t.Errorf("filtered = %d, callCount = %d, want 2 and 1", filtered, mb.callCount)
+	}
+
+	// A filter error withholds the result
+	handler.AddOutputFilter(func(ctx context.Context, tool string, result *protocol.ToolCallResult) error {
+		return mcperr.PermissionDenied("blocked")
+	})
+	respJSON, _ := handler.Handle(context.Background(), reqJSON, "test")
+	var resp protocol.Response
+	json.Unmarshal(respJSON, &resp)
+	if resp.Error == nil || resp.Error.Code != protocol.Forbidden {
+		t.Errorf("error = %+v, want Forbidden", resp.Error)
+	}
+}
+
+// Test: Results of per-principal tools are not shared across callers
+func TestHandler_CachePerPrincipal(t *testing.T) {
+	mb := newMockBackend()
+	mb.RegisterTool(backend.NewTool("whoami").
+		CachePerPrincipal().
+		WithCache(true, time.Minute).
+		Build(), func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		mb.callCount++
+		p, _ := auth.PrincipalFromContext(ctx)
+		return map[string]interface{}{"id": p.ID}, nil
+	})
+
+	handler := protocol.NewHandler(mb, nil)
+	cacheConfig := &cache.Config{Type: cache.TypeShort, TTL: 60, MaxSize: 100, Enabled: true}
+	c, _ := cache.New(cacheConfig)
+	handler.SetCache(c, cache.NewKeyGenerator(cache.WithKeyIdentity(func(ctx context.Context) string {
+		p, _ := auth.PrincipalFromContext(ctx)
+		return p.ID
+	})), cacheConfig)
+
+	reqJSON := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"whoami"}}`)
+	for _, id := range []string{"alice", "bob", "alice"} {
+		ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: id, Method: "test"})
+		respJSON, err := handler.Handle(ctx, reqJSON, "test")
+		if err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+		if !strings.Contains(string(respJSON), id) {
+			t.Errorf("%s got another caller's result: %s", id, respJSON)
+		}
+	}
+	if mb.callCount != 2 {
+		t.Errorf("callCount = %d, want 2 (one per principal)", mb.callCount)
+	}
+}
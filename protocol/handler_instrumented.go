@@ -6,9 +6,12 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/SaherElMasry/go-mcp-framework/audit"
+	"github.com/SaherElMasry/go-mcp-framework/auth"
 	"github.com/SaherElMasry/go-mcp-framework/backend"
 	"github.com/SaherElMasry/go-mcp-framework/cache"
 	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
 )
 
 // InstrumentedHandler wraps a handler with metrics
@@ -28,6 +31,46 @@ func (h *InstrumentedHandler) SetCache(c cache.Cache, keyGen *cache.KeyGenerator
 	h.Handler.SetCache(c, keyGen, config)
 }
 
+// UpdateCacheConfig forwards to underlying handler
+func (h *InstrumentedHandler) UpdateCacheConfig(config *cache.Config) {
+	h.Handler.UpdateCacheConfig(config)
+}
+
+// SetRateLimiter forwards to underlying handler
+func (h *InstrumentedHandler) SetRateLimiter(limiter *ratelimit.Limiter) {
+	h.Handler.SetRateLimiter(limiter)
+}
+
+// SetAccessPolicy forwards to underlying handler
+func (h *InstrumentedHandler) SetAccessPolicy(policy *auth.AccessPolicy) {
+	h.Handler.SetAccessPolicy(policy)
+}
+
+// SetProvenance forwards to underlying handler
+func (h *InstrumentedHandler) SetProvenance(config *ProvenanceConfig) {
+	h.Handler.SetProvenance(config)
+}
+
+// SetAuditLogger forwards to underlying handler
+func (h *InstrumentedHandler) SetAuditLogger(l *audit.Logger) {
+	h.Handler.SetAuditLogger(l)
+}
+
+// AddOutputFilter forwards to underlying handler
+func (h *InstrumentedHandler) AddOutputFilter(filter OutputFilter) {
+	h.Handler.AddOutputFilter(filter)
+}
+
+// SetResultLimits forwards to underlying handler
+func (h *InstrumentedHandler) SetResultLimits(limits *ResultLimits) {
+	h.Handler.SetResultLimits(limits)
+}
+
+// SetListPageSize forwards to underlying handler
+func (h *InstrumentedHandler) SetListPageSize(n int) {
+	h.Handler.SetListPageSize(n)
+}
+
 // Handle processes a request with metrics
 func (h *InstrumentedHandler) Handle(ctx context.Context, data []byte, transportType string) ([]byte, error) {
 	start := time.Now()
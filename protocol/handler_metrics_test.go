@@ -0,0 +1,108 @@
+package protocol_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricValue returns the value of the default registry's series with
+// the given labels, or 0 if there is none
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	series:
+		for _, m := range family.GetMetric() {
+			got := map[string]string{}
+			for _, pair := range m.GetLabel() {
+				got[pair.GetName()] = pair.GetValue()
+			}
+			for k, v := range labels {
+				if got[k] != v {
+					continue series
+				}
+			}
+			switch {
+			case m.Counter != nil:
+				return m.Counter.GetValue()
+			case m.Gauge != nil:
+				return m.Gauge.GetValue()
+			case m.Histogram != nil:
+				return float64(m.Histogram.GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func TestHandler_Metrics(t *testing.T) {
+	b := backend.NewBaseBackend("metered")
+	b.RegisterTool(backend.NewTool("metrics_ok").WithCache(true, time.Minute).Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+	b.RegisterTool(backend.NewTool("metrics_missing").Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, mcperr.NotFound("no such record")
+		})
+	b.RegisterTool(backend.NewTool("metrics_failing").Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, backend.NewToolError("bad input")
+		})
+
+	handler := protocol.NewHandler(b, nil)
+	config := &cache.Config{Type: cache.TypeShort, TTL: 60, MaxSize: 10, Enabled: true}
+	c, _ := cache.New(config)
+	handler.SetCache(c, cache.NewKeyGenerator(), config)
+	observability.SetCacheStats(c.Stats)
+	defer observability.SetCacheStats(nil)
+
+	call := func(tool string) {
+		request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q}}`, tool)
+		if _, err := handler.Handle(context.Background(), []byte(request), "stdio"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	call("metrics_ok")
+	call("metrics_ok")
+	call("metrics_missing")
+	call("metrics_failing")
+	call("metrics_unknown")
+
+	for _, tc := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"mcp_tool_calls_total", map[string]string{"tool": "metrics_ok", "status": "success", "transport": "stdio"}, 2},
+		{"mcp_tool_duration_seconds", map[string]string{"tool": "metrics_ok", "transport": "stdio"}, 2},
+		{"mcp_tool_calls_total", map[string]string{"tool": "metrics_missing", "status": "error"}, 1},
+		{"mcp_tool_errors_total", map[string]string{"tool": "metrics_missing", "code": "not_found"}, 1},
+		{"mcp_tool_errors_total", map[string]string{"tool": "metrics_failing", "code": "tool_error"}, 1},
+		{"mcp_tool_calls_total", map[string]string{"tool": "metrics_unknown"}, 0},
+		{"mcp_cache_lookups_total", map[string]string{"tool": "metrics_ok", "result": "miss"}, 1},
+		{"mcp_cache_lookups_total", map[string]string{"tool": "metrics_ok", "result": "hit"}, 1},
+		{"mcp_cache_hits_total", nil, 1},
+		{"mcp_cache_entries", nil, 1},
+		{"mcp_cache_hit_ratio", nil, 0.5},
+	} {
+		if got := metricValue(t, tc.name, tc.labels); got != tc.want {
+			t.Errorf("%s%v = %v, want %v", tc.name, tc.labels, got, tc.want)
+		}
+	}
+}
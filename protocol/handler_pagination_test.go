@@ -0,0 +1,81 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+func TestHandler_ListPagination(t *testing.T) {
+	b := backend.NewBaseBackend("many")
+	for i := 0; i < 5; i++ {
+		b.RegisterTool(backend.NewTool(fmt.Sprintf("tool_%d", i)).Build(),
+			func(ctx context.Context, args map[string]interface{}) (interface{}, error) { return "ok", nil })
+		b.RegisterResource(backend.Resource{URI: fmt.Sprintf("file:///r%d", i), Name: fmt.Sprintf("r%d", i)})
+	}
+	h := protocol.NewHandler(b, nil)
+
+	list := func(method, field, cursor string) ([]string, string, *protocol.Error) {
+		t.Helper()
+		params := "{}"
+		if cursor != "" {
+			params = fmt.Sprintf(`{"cursor":%q}`, cursor)
+		}
+		data, err := h.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":`+params+`}`), "stdio")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp struct {
+			Result map[string]json.RawMessage `json:"result"`
+			Error  *protocol.Error            `json:"error"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		var items []struct {
+			Name string `json:"name"`
+		}
+		var next string
+		json.Unmarshal(resp.Result[field], &items)
+		json.Unmarshal(resp.Result["nextCursor"], &next)
+		names := make([]string, len(items))
+		for i, item := range items {
+			names[i] = item.Name
+		}
+		return names, next, resp.Error
+	}
+
+	// Without a page size lists come whole
+	if names, next, _ := list("tools/list", "tools", ""); len(names) != 5 || next != "" {
+		t.Errorf("tools/list = %v, next %q", names, next)
+	}
+
+	h.SetListPageSize(2)
+	for _, method := range []struct{ name, field string }{{"tools/list", "tools"}, {"resources/list", "resources"}} {
+		var all []string
+		cursor := ""
+		for pages := 1; ; pages++ {
+			names, next, protoErr := list(method.name, method.field, cursor)
+			if protoErr != nil || len(names) > 2 || pages > 3 {
+				t.Fatalf("%s page %d: %v %+v", method.name, pages, names, protoErr)
+			}
+			all = append(all, names...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if len(all) != 5 || !sort.StringsAreSorted(all) {
+			t.Errorf("%s = %v", method.name, all)
+		}
+	}
+
+	if _, _, protoErr := list("tools/list", "tools", "garbage"); protoErr == nil || protoErr.Code != protocol.InvalidParams {
+		t.Errorf("invalid cursor: %+v", protoErr)
+	}
+}
@@ -0,0 +1,119 @@
+package protocol_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+func TestHandler_RequestID(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(observability.NewContextHandler(slog.NewJSONHandler(&logs, nil)))
+
+	var seen string
+	b := backend.NewBaseBackend("correlated")
+	b.RegisterTool(backend.NewTool("echo").Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			seen, _ = observability.RequestIDFromContext(ctx)
+			logger.InfoContext(ctx, "backend work")
+			return "ok", nil
+		})
+	b.RegisterTool(backend.NewTool("lookup").Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return nil, mcperr.NotFound("no such record")
+		})
+	handler := protocol.NewHandler(b, logger)
+
+	call := func(ctx context.Context, request string) protocol.Response {
+		t.Helper()
+		data, err := handler.Handle(ctx, []byte(request), "stdio")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp protocol.Response
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	ctx := observability.WithRequestID(context.Background(), "client-42")
+	resp := call(ctx, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo"}}`)
+	meta, _ := resp.Result.(map[string]interface{})["_meta"].(map[string]interface{})
+	if meta["requestId"] != "client-42" || seen != "client-42" {
+		t.Errorf("_meta = %v, backend saw %q; want client-42", meta, seen)
+	}
+	if !strings.Contains(logs.String(), `"msg":"backend work","request_id":"client-42"`) {
+		t.Errorf("backend log line lacks the request ID: %s", logs.String())
+	}
+
+	// Calls without an ID get one; errors carry it in their data
+	resp = call(context.Background(), `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"lookup"}}`)
+	data, _ := resp.Error.Data.(map[string]interface{})
+	if id, _ := data["requestId"].(string); len(id) != 36 {
+		t.Errorf("error data = %v, want a generated requestId", resp.Error.Data)
+	}
+	resp = call(context.Background(), `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	meta, _ = resp.Result.(map[string]interface{})["_meta"].(map[string]interface{})
+	if id, _ := meta["requestId"].(string); id == "" || id == data["requestId"] {
+		t.Errorf("tools/list _meta = %v, want a new requestId", meta)
+	}
+}
+
+func TestHandler_IDGenerator(t *testing.T) {
+	b := backend.NewBaseBackend("generated")
+	handler := protocol.NewHandler(b, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	requestID := func() string {
+		t.Helper()
+		data, err := handler.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`), "stdio")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp protocol.Response
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		meta, _ := resp.Result.(map[string]interface{})["_meta"].(map[string]interface{})
+		id, _ := meta["requestId"].(string)
+		return id
+	}
+	defer observability.SetIDGenerator(nil)
+
+	next := 0
+	observability.SetIDGenerator(func() string {
+		next++
+		return fmt.Sprintf("replica-a-%d", next)
+	})
+	if id := requestID(); id != "replica-a-1" {
+		t.Errorf("requestId = %q, want the generator's", id)
+	}
+
+	// Unusable IDs fall back to a UUIDv7
+	observability.SetIDGenerator(func() string { return "not\nprintable" })
+	if id := requestID(); len(id) != 36 {
+		t.Errorf("requestId = %q, want a UUIDv7", id)
+	}
+
+	ulid, err := observability.IDGeneratorByName(observability.IDFormatULID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	observability.SetIDGenerator(ulid)
+	first, second := requestID(), requestID()
+	if len(first) != 26 || first == second || strings.ToUpper(first) != first {
+		t.Errorf("ULIDs %q, %q", first, second)
+	}
+	if _, err := observability.IDGeneratorByName("sequential"); err == nil {
+		t.Error("IDGeneratorByName accepted an unknown format")
+	}
+}
@@ -0,0 +1,112 @@
+package protocol_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHandler_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	// The upstream sees the trace of the tool call
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+
+	b := backend.NewBaseBackend("traced")
+	client := b.HTTPClient(nil)
+	b.RegisterTool(backend.NewTool("fetch").WithCache(true, time.Minute).Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL+"/data?key=secret", nil)
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body.Close()
+			return "ok", nil
+		})
+
+	handler := protocol.NewHandler(b, nil)
+	config := &cache.Config{Type: cache.TypeShort, TTL: 60, MaxSize: 10, Enabled: true}
+	c, _ := cache.New(config)
+	handler.SetCache(c, cache.NewKeyGenerator(), config)
+
+	// Continue a caller's trace, as the stdio transport does from _meta
+	const callerTrace = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := observability.ExtractMeta(context.Background(), map[string]interface{}{
+		"traceparent": "00-" + callerTrace + "-00f067aa0ba902b7-01",
+	})
+	request := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"fetch","arguments":{}}}`)
+	if _, err := handler.Handle(ctx, request, "stdio"); err != nil {
+		t.Fatal(err)
+	}
+	provider.ForceFlush(context.Background())
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		spans[s.Name()] = s
+		if got := s.SpanContext().TraceID().String(); got != callerTrace {
+			t.Errorf("span %s in trace %s, want the caller's", s.Name(), got)
+		}
+	}
+	dispatch, ok := spans["jsonrpc tools/call"]
+	if !ok {
+		t.Fatalf("spans = %v, want a dispatch span", spans)
+	}
+	for _, name := range []string{"cache.get", "tool fetch", "cache.set"} {
+		s, ok := spans[name]
+		if !ok {
+			t.Errorf("no %s span", name)
+			continue
+		}
+		if s.Parent().SpanID() != dispatch.SpanContext().SpanID() {
+			t.Errorf("%s is not a child of the dispatch span", name)
+		}
+	}
+
+	httpSpan, ok := spans["HTTP GET"]
+	if !ok {
+		t.Fatal("no upstream HTTP span")
+	}
+	if httpSpan.Parent().SpanID() != spans["tool fetch"].SpanContext().SpanID() {
+		t.Error("upstream call is not a child of the tool span")
+	}
+	for _, attr := range httpSpan.Attributes() {
+		if attr.Key == "url.full" && attr.Value.AsString() != upstream.URL+"/data" {
+			t.Errorf("url.full = %s, want the URL without its query", attr.Value.AsString())
+		}
+	}
+	if want := httpSpan.SpanContext().TraceID().String(); traceparent == "" || traceparent[3:35] != want {
+		t.Errorf("traceparent = %q, want trace %s", traceparent, want)
+	}
+
+	// A cache hit skips the tool
+	recorder.Reset()
+	handler.Handle(ctx, request, "stdio")
+	for _, s := range recorder.Ended() {
+		if s.Name() == "tool fetch" {
+			t.Error("cache hit executed the tool")
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package protocol
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// What tools/call does with results over their size limit
+const (
+	// OversizedError fails the call (default)
+	OversizedError = "error"
+
+	// OversizedTruncate cuts the result's text to fit and marks it with
+	// _meta.truncated
+	OversizedTruncate = "truncate"
+
+	// OversizedSpill keeps the whole result as a temporary resource the
+	// client fetches with resources/read, returning a link to it
+	OversizedSpill = "spill"
+)
+
+// SpillScheme is the URI scheme of spilled results
+const SpillScheme = "mcp-result"
+
+// Spill defaults
+const (
+	DefaultSpillTTL      = 10 * time.Minute
+	DefaultSpillMaxBytes = 64 << 20
+)
+
+// ResultLimit is the size limit of a tool's results
+type ResultLimit struct {
+	// MaxSize is the most bytes of the encoded result (0 = no limit)
+	MaxSize int `yaml:"max_size"`
+
+	// Policy is OversizedError, OversizedTruncate or OversizedSpill
+	Policy string `yaml:"policy"`
+}
+
+// ResultLimits bounds the size of tools/call results, which some clients
+// cannot take whole
+// A tool's own limit (backend.ToolDefinition.MaxResultSize) replaces
+// MaxSize; Tools replaces both, and the policy, per tool. Limits apply to
+// the results sent, after output filters and on cache hits alike, but
+// before the request ID is stamped in _meta. Streamed results are bounded
+// by the stream's event limit instead.
+type ResultLimits struct {
+	ResultLimit `yaml:",inline"`
+
+	// Tools overrides the limit per tool; a zero field keeps the default
+	Tools map[string]ResultLimit `yaml:"tools"`
+
+	// SpillTTL is how long spilled results can be read (default 10m)
+	SpillTTL time.Duration `yaml:"spill_ttl"`
+
+	// SpillMaxBytes bounds the spilled results kept; the oldest are
+	// dropped first (default 64MB)
+	SpillMaxBytes int `yaml:"spill_max_bytes"`
+}
+
+// Validate validates the limits
+func (l ResultLimits) Validate() error {
+	if err := l.ResultLimit.validate(); err != nil {
+		return err
+	}
+	for tool, limit := range l.Tools {
+		if err := limit.validate(); err != nil {
+			return fmt.Errorf("tool %s: %w", tool, err)
+		}
+	}
+	if l.SpillTTL < 0 || l.SpillMaxBytes < 0 {
+		return fmt.Errorf("spill ttl and max bytes must not be negative")
+	}
+	return nil
+}
+
+func (l ResultLimit) validate() error {
+	if l.MaxSize < 0 {
+		return fmt.Errorf("max result size must not be negative, got %d", l.MaxSize)
+	}
+	switch l.Policy {
+	case "", OversizedError, OversizedTruncate, OversizedSpill:
+		return nil
+	}
+	return fmt.Errorf("unknown oversized result policy %q (want error, truncate or spill)", l.Policy)
+}
+
+// limitFor resolves the limit of a tool
+func (l *ResultLimits) limitFor(tool backend.ToolDefinition) ResultLimit {
+	limit := l.ResultLimit
+	if tool.MaxResultSize > 0 {
+		limit.MaxSize = tool.MaxResultSize
+	}
+	if override, ok := l.Tools[tool.Name]; ok {
+		if override.MaxSize > 0 {
+			limit.MaxSize = override.MaxSize
+		}
+		if override.Policy != "" {
+			limit.Policy = override.Policy
+		}
+	}
+	return limit
+}
+
+// SetResultLimits bounds the size of tools/call results; nil removes the
+// limits
+func (h *Handler) SetResultLimits(limits *ResultLimits) {
+	if limits != nil && limits.SpillTTL <= 0 {
+		copied := *limits
+		copied.SpillTTL = DefaultSpillTTL
+		limits = &copied
+	}
+	h.limits = limits
+}
+
+// limitResult applies the tool's size limit to a tools/call result
+func (h *Handler) limitResult(ctx context.Context, tool backend.ToolDefinition, result interface{}) (interface{}, *Error) {
+	if h.limits == nil {
+		return result, nil
+	}
+	limit := h.limits.limitFor(tool)
+	if limit.MaxSize <= 0 {
+		return result, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil || len(data) <= limit.MaxSize {
+		return result, nil
+	}
+
+	policy := limit.Policy
+	if policy == "" {
+		policy = OversizedError
+	}
+	observability.RecordOversizedResult(tool.Name, policy)
+	h.logger.WarnContext(ctx, "tool result over the size limit",
+		"tool", tool.Name,
+		"size", len(data),
+		"limit", limit.MaxSize,
+		"policy", policy)
+
+	callResult, ok := result.(ToolCallResult)
+	if !ok {
+		// Cached results decoded untyped
+		if err := json.Unmarshal(data, &callResult); err != nil {
+			policy = OversizedError
+		}
+	}
+	switch policy {
+	case OversizedTruncate:
+		if truncated, ok := truncateResult(callResult, len(data), limit.MaxSize); ok {
+			return truncated, nil
+		}
+	case OversizedSpill:
+		return h.spillResult(tool, callResult, len(data), limit.MaxSize), nil
+	}
+	return nil, NewResultTooLargeError(tool.Name, len(data), limit.MaxSize)
+}
+
+// truncateResult cuts a result's text, last items first, to fit maxSize
+// Structured content and content that cannot be cut (images, blobs) are
+// dropped. It fails if even an empty result is too large.
+func truncateResult(result ToolCallResult, size, maxSize int) (ToolCallResult, bool) {
+	result.Meta = copyMeta(result.Meta)
+	result.Meta["truncated"] = true
+	result.Meta["originalSize"] = size
+	result.StructuredContent = nil
+	result.Content = append([]ContentItem(nil), result.Content...)
+
+	for {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return result, false
+		}
+		excess := len(data) - maxSize
+		if excess <= 0 {
+			return result, true
+		}
+		last := len(result.Content) - 1
+		if last < 0 {
+			return result, false
+		}
+		item := &result.Content[last]
+		if item.Type != "text" || len(item.Text) <= excess {
+			// Each raw byte takes at least one encoded byte, so cutting
+			// excess bytes of text is enough, unless there are not as many
+			result.Content = result.Content[:last]
+			continue
+		}
+		item.Text = cutText(item.Text, len(item.Text)-excess)
+	}
+}
+
+// cutText returns the longest prefix of s within n bytes that does not
+// split a rune
+func cutText(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func copyMeta(meta map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(meta)+2)
+	for k, v := range meta {
+		copied[k] = v
+	}
+	return copied
+}
+
+// spillResult keeps a result as a resource, returning a result that
+// links to it
+func (h *Handler) spillResult(tool backend.ToolDefinition, result ToolCallResult, size, maxSize int) ToolCallResult {
+	contents := spillContents(result)
+	contents.URI = h.spills.put(contents, h.limits.SpillTTL, h.limits.SpillMaxBytes)
+
+	meta := copyMeta(result.Meta)
+	meta["truncated"] = true
+	meta["originalSize"] = size
+	meta["resource"] = contents.URI
+	return ToolCallResult{
+		Content: []ContentItem{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("The result is %d bytes, over the limit of %d; read it from %s with resources/read within %s.",
+					size, maxSize, contents.URI, h.limits.SpillTTL),
+			},
+			{
+				Type:     "resource_link",
+				URI:      contents.URI,
+				Name:     tool.Name + " result",
+				MimeType: contents.MimeType,
+			},
+		},
+		IsError: result.IsError,
+		Meta:    meta,
+	}
+}
+
+// spillContents is the resource form of a result: its text, or the JSON
+// of its content when that is not all text
+func spillContents(result ToolCallResult) ResourceContents {
+	texts := make([]string, 0, len(result.Content))
+	for _, item := range result.Content {
+		if item.Type != "text" {
+			data, _ := json.Marshal(result.Content)
+			return ResourceContents{MimeType: "application/json", Text: string(data)}
+		}
+		texts = append(texts, item.Text)
+	}
+	text := strings.Join(texts, "\n")
+	if len(texts) == 1 && json.Valid([]byte(text)) {
+		return ResourceContents{MimeType: "application/json", Text: text}
+	}
+	return ResourceContents{MimeType: "text/plain", Text: text}
+}
+
+// ============================================================
+// Spilled results
+// ============================================================
+
+// spillStore keeps spilled results until they expire
+// Their URIs are unguessable, so holding one is what grants reading it.
+type spillStore struct {
+	mu      sync.Mutex
+	entries map[string]*spilled
+	order   []string // oldest first
+	bytes   int
+}
+
+type spilled struct {
+	contents ResourceContents
+	expires  time.Time
+}
+
+// put stores contents, returning their URI
+func (s *spillStore) put(contents ResourceContents, ttl time.Duration, maxBytes int) string {
+	if maxBytes <= 0 {
+		maxBytes = DefaultSpillMaxBytes
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	contents.URI = SpillScheme + "://" + hex.EncodeToString(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[string]*spilled)
+	}
+	now := time.Now()
+	s.bytes += len(contents.Text)
+	s.entries[contents.URI] = &spilled{contents: contents, expires: now.Add(ttl)}
+	s.order = append(s.order, contents.URI)
+
+	// Drop expired entries, and the oldest while over the byte budget;
+	// the newest is kept even if it alone exceeds it
+	for len(s.order) > 1 {
+		oldest := s.entries[s.order[0]]
+		if oldest != nil && oldest.expires.After(now) && s.bytes <= maxBytes {
+			break
+		}
+		if oldest != nil {
+			s.bytes -= len(oldest.contents.Text)
+			delete(s.entries, s.order[0])
+		}
+		s.order = s.order[1:]
+	}
+	return contents.URI
+}
+
+// get returns the contents of an unexpired spilled result
+func (s *spillStore) get(uri string) (ResourceContents, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[uri]
+	if !ok || time.Now().After(entry.expires) {
+		return ResourceContents{}, false
+	}
+	return entry.contents, true
+}
+
+// handleResourcesRead serves resources/read for spilled results
+func (h *Handler) handleResourcesRead(ctx context.Context, params map[string]interface{}) (interface{}, *Error) {
+	uri, _ := params["uri"].(string)
+	if uri == "" {
+		return nil, NewInvalidParams("missing or invalid 'uri' parameter")
+	}
+	if !strings.HasPrefix(uri, SpillScheme+"://") {
+		return nil, NewCategorizedError(mcperr.NotFound("unknown resource %s", uri))
+	}
+	contents, ok := h.spills.get(uri)
+	if !ok {
+		return nil, NewCategorizedError(mcperr.NotFound("result %s has expired or does not exist", uri))
+	}
+	return map[string]interface{}{
+		"contents": []ResourceContents{contents},
+	}, nil
+}
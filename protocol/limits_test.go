@@ -0,0 +1,138 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/cache"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// bigBackend returns n-byte texts
+func bigBackend() *backend.BaseBackend {
+	b := backend.NewBaseBackend("big")
+	text := func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		n := int(args["n"].(float64))
+		return backend.NewResult(backend.TextContent{Text: strings.Repeat("é", n/2)}), nil
+	}
+	b.RegisterTool(backend.NewTool("text").IntParam("n", "Size", true, nil, nil).WithCache(true, time.Minute).Build(), text)
+	b.RegisterTool(backend.NewTool("report").IntParam("n", "Size", true, nil, nil).MaxResultSize(5000).Build(), text)
+	b.RegisterTool(backend.NewTool("rows").IntParam("n", "Size", true, nil, nil).Build(),
+		func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"rows": strings.Repeat("x", int(args["n"].(float64)))}, nil
+		})
+	return b
+}
+
+func callBig(t *testing.T, h *protocol.Handler, tool string, n int) protocol.Response {
+	t.Helper()
+	request := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":%q,"arguments":{"n":%d}}}`, tool, n)
+	data, err := h.Handle(context.Background(), []byte(request), "stdio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp protocol.Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func resultOf(t *testing.T, resp protocol.Response) protocol.ToolCallResult {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("error %+v", resp.Error)
+	}
+	data, _ := json.Marshal(resp.Result)
+	var result protocol.ToolCallResult
+	json.Unmarshal(data, &result)
+	return result
+}
+
+func TestHandler_ResultLimits(t *testing.T) {
+	h := protocol.NewHandler(bigBackend(), nil)
+	h.SetResultLimits(&protocol.ResultLimits{
+		ResultLimit: protocol.ResultLimit{MaxSize: 1000},
+		Tools:       map[string]protocol.ResultLimit{"rows": {Policy: protocol.OversizedTruncate}},
+	})
+	c, _ := cache.New(&cache.Config{Type: cache.TypeShort, TTL: 60, MaxSize: 100, Enabled: true})
+	h.SetCache(c, cache.NewKeyGenerator(), &cache.Config{TTL: 60, Enabled: true})
+
+	if result := resultOf(t, callBig(t, h, "text", 500)); len(result.Content[0].Text) != 500 {
+		t.Errorf("small result %+v", result)
+	}
+
+	// The default policy fails the call, from the cache as well
+	for i := 0; i < 2; i++ {
+		resp := callBig(t, h, "text", 2000)
+		if resp.Error == nil || resp.Error.Code != protocol.ResultTooLarge {
+			t.Fatalf("call %d: %+v", i, resp)
+		}
+		if data := resp.Error.Data.(map[string]interface{}); data["code"] != "result_too_large" || data["max_size"] != float64(1000) {
+			t.Errorf("error data %v", data)
+		}
+	}
+
+	// Truncation fits the limit (before the request ID is stamped);
+	// structured results are cut as text
+	result := resultOf(t, callBig(t, h, "rows", 3000))
+	delete(result.Meta, "requestId")
+	data, _ := json.Marshal(result)
+	if len(data) > 1000 || result.Meta["truncated"] != true || result.Meta["originalSize"] == nil || !strings.HasPrefix(result.Content[0].Text, `{"rows":"xxx`) {
+		t.Errorf("truncated to %d bytes: %+v", len(data), result)
+	}
+
+	// The tool's own limit replaces the server's
+	if resp := callBig(t, h, "report", 3000); resp.Error != nil {
+		t.Errorf("report within its limit: %+v", resp.Error)
+	}
+}
+
+func TestHandler_ResultSpill(t *testing.T) {
+	h := protocol.NewHandler(bigBackend(), nil)
+	h.SetResultLimits(&protocol.ResultLimits{ResultLimit: protocol.ResultLimit{MaxSize: 1000, Policy: protocol.OversizedSpill}})
+
+	result := resultOf(t, callBig(t, h, "text", 4000))
+	uri, _ := result.Meta["resource"].(string)
+	if !strings.HasPrefix(uri, protocol.SpillScheme+"://") || len(result.Content) != 2 || result.Content[1].Type != "resource_link" || result.Content[1].URI != uri {
+		t.Fatalf("spilled result %+v", result)
+	}
+
+	read := func(uri string) protocol.Response {
+		data, _ := h.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"`+uri+`"}}`), "stdio")
+		var resp protocol.Response
+		json.Unmarshal(data, &resp)
+		return resp
+	}
+	resp := read(uri)
+	if resp.Error != nil {
+		t.Fatalf("resources/read: %+v", resp.Error)
+	}
+	contents := resp.Result.(map[string]interface{})["contents"].([]interface{})[0].(map[string]interface{})
+	if contents["text"] != strings.Repeat("é", 2000) || contents["mimeType"] != "text/plain" || contents["uri"] != uri {
+		t.Errorf("contents %v", contents)
+	}
+
+	if resp := read(protocol.SpillScheme + "://0000"); resp.Error == nil || resp.Error.Code != protocol.NotFound {
+		t.Errorf("unknown result: %+v", resp)
+	}
+	if resp := read("file:///etc/passwd"); resp.Error == nil || resp.Error.Code != protocol.NotFound {
+		t.Errorf("other resource: %+v", resp)
+	}
+}
+
+func TestResultLimits_Validate(t *testing.T) {
+	valid := protocol.ResultLimits{ResultLimit: protocol.ResultLimit{MaxSize: 1 << 20, Policy: protocol.OversizedSpill}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid limits: %v", err)
+	}
+	invalid := protocol.ResultLimits{Tools: map[string]protocol.ResultLimit{"x": {Policy: "drop"}}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("unknown policy accepted")
+	}
+}
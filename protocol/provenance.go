@@ -0,0 +1,67 @@
+package protocol
+
+import (
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// ProvenanceConfig identifies the server in result provenance
+type ProvenanceConfig struct {
+	Server        string
+	ServerVersion string
+}
+
+// Provenance describes where a tool result came from
+// It is attached to tools/call results as _meta.provenance so downstream
+// agents and auditors can trace the data.
+type Provenance struct {
+	Server        string    `json:"server,omitempty"`
+	ServerVersion string    `json:"serverVersion,omitempty"`
+	Backend       string    `json:"backend"`
+	Tool          string    `json:"tool"`
+	ToolVersion   string    `json:"toolVersion,omitempty"`
+	CacheHit      bool      `json:"cacheHit"`
+	Sources       []string  `json:"sources,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// SetProvenance attaches provenance metadata to every tool result
+// A nil config disables it.
+func (h *Handler) SetProvenance(config *ProvenanceConfig) {
+	h.provenance = config
+}
+
+// stampProvenance attaches the provenance of a freshly executed call
+// Cached results keep it, so a cache hit still reports when and where the
+// data was fetched.
+func (h *Handler) stampProvenance(result *ToolCallResult, tool backend.ToolDefinition, sources []string) {
+	if h.provenance == nil {
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = make(map[string]interface{})
+	}
+	result.Meta["provenance"] = Provenance{
+		Server:        h.provenance.Server,
+		ServerVersion: h.provenance.ServerVersion,
+		Backend:       h.backend.Name(),
+		Tool:          tool.Name,
+		ToolVersion:   tool.Version,
+		Sources:       sources,
+		Timestamp:     time.Now().UTC(),
+	}
+}
+
+// markCacheHit flags the provenance of a result served from the cache
+// Entries cached before provenance was enabled get a fresh one.
+func (h *Handler) markCacheHit(result *ToolCallResult, tool backend.ToolDefinition) {
+	if prov, ok := result.Meta["provenance"].(map[string]interface{}); ok {
+		prov["cacheHit"] = true
+		return
+	}
+	h.stampProvenance(result, tool, nil)
+	p := result.Meta["provenance"].(Provenance)
+	p.CacheHit = true
+	result.Meta["provenance"] = p
+}
@@ -85,3 +85,32 @@ func FormatEventAsSSE(event engine.Event, requestID string) string {
 	msg := eventToSSE(event, requestID)
 	return msg.format()
 }
+
+// FormatSSE formats an SSE message with already serialized data; an
+// empty id leaves the client's last event ID unchanged
+func FormatSSE(event, id, data string) string {
+	return sseMessage{Event: event, ID: id, Data: data}.format()
+}
+
+// FormatNDJSON formats a newline-delimited JSON stream line with already
+// serialized data: {"event":...,"id":...,"data":...}; an empty id is
+// omitted like in FormatSSE
+func FormatNDJSON(event, id, data string) string {
+	var sb strings.Builder
+	sb.WriteString(`{"event":`)
+	sb.Write(quoteJSON(event))
+	if id != "" {
+		sb.WriteString(`,"id":`)
+		sb.Write(quoteJSON(id))
+	}
+	sb.WriteString(`,"data":`)
+	sb.WriteString(data)
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// quoteJSON encodes s as a JSON string
+func quoteJSON(s string) []byte {
+	b, _ := json.Marshal(s)
+	return b
+}
@@ -273,3 +273,18 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+func TestFormatNDJSON(t *testing.T) {
+	tests := []struct {
+		event, id, data string
+		expected        string
+	}{
+		{"data", "req-1:2", `{"chunk":"a\nb"}`, "{\"event\":\"data\",\"id\":\"req-1:2\",\"data\":{\"chunk\":\"a\\nb\"}}\n"},
+		{"fragment", "", `{"part":"x"}`, "{\"event\":\"fragment\",\"data\":{\"part\":\"x\"}}\n"},
+	}
+	for _, tt := range tests {
+		if got := FormatNDJSON(tt.event, tt.id, tt.data); got != tt.expected {
+			t.Errorf("FormatNDJSON(%q, %q, %q) = %q, want %q", tt.event, tt.id, tt.data, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
+)
+
+// subscriptions tracks resources/subscribe: the sessions subscribed to
+// each URI and the backend watch serving them
+// Clients without sessions share the session "".
+type subscriptions struct {
+	mu      sync.Mutex
+	watches map[string]*resourceWatch // by URI
+
+	// sessions whose end is awaited to drop their subscriptions
+	sessions map[string]bool
+}
+
+// resourceWatch is one backend watch, shared by its URI's subscribers
+type resourceWatch struct {
+	cancel   context.CancelFunc
+	sessions map[string]bool
+}
+
+// SetBroadcaster enables resources/subscribe for backends implementing
+// backend.ResourceWatcher: notifications/resources/updated go through b
+// to the subscribed clients' notification streams
+func (h *Handler) SetBroadcaster(b *transport.Broadcaster) {
+	h.broadcaster = b
+}
+
+// handleResourcesSubscribe serves resources/subscribe
+// A URI's first subscriber starts a backend watch, which its last
+// unsubscribe (or the end of its session) stops.
+func (h *Handler) handleResourcesSubscribe(ctx context.Context, params map[string]interface{}) (interface{}, *Error) {
+	uri, _ := params["uri"].(string)
+	if uri == "" {
+		return nil, NewInvalidParams("uri is required")
+	}
+	watcher, ok := h.backend.(backend.ResourceWatcher)
+	if !ok || h.broadcaster == nil {
+		return nil, NewMethodNotFound("resources/subscribe")
+	}
+
+	sessionID := ""
+	var sessionDone <-chan struct{}
+	if s, ok := session.FromContext(ctx); ok {
+		sessionID, sessionDone = s.ID(), s.Done()
+	}
+
+	h.subscriptions.mu.Lock()
+	defer h.subscriptions.mu.Unlock()
+
+	w := h.subscriptions.watches[uri]
+	if w == nil {
+		// The watch outlives the request but keeps its values (principal...)
+		watchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		err := watcher.WatchResource(watchCtx, uri, func(changed string) {
+			h.resourceUpdated(uri, changed)
+		})
+		if err != nil {
+			cancel()
+			return nil, toolError(err)
+		}
+		w = &resourceWatch{cancel: cancel, sessions: make(map[string]bool)}
+		h.subscriptions.watches[uri] = w
+	}
+	w.sessions[sessionID] = true
+
+	if sessionDone != nil && !h.subscriptions.sessions[sessionID] {
+		h.subscriptions.sessions[sessionID] = true
+		go func() {
+			<-sessionDone
+			h.unsubscribeSession(sessionID)
+		}()
+	}
+
+	h.logger.DebugContext(ctx, "resource subscribed", "uri", uri, "session", sessionID)
+	return map[string]interface{}{}, nil
+}
+
+// handleResourcesUnsubscribe serves resources/unsubscribe
+func (h *Handler) handleResourcesUnsubscribe(ctx context.Context, params map[string]interface{}) (interface{}, *Error) {
+	uri, _ := params["uri"].(string)
+	if uri == "" {
+		return nil, NewInvalidParams("uri is required")
+	}
+	sessionID := ""
+	if s, ok := session.FromContext(ctx); ok {
+		sessionID = s.ID()
+	}
+
+	h.subscriptions.mu.Lock()
+	defer h.subscriptions.mu.Unlock()
+	h.unsubscribe(uri, sessionID)
+	return map[string]interface{}{}, nil
+}
+
+// unsubscribe removes a session's subscription to uri, stopping the
+// watch after the last one; the caller holds the lock
+func (h *Handler) unsubscribe(uri, sessionID string) {
+	w := h.subscriptions.watches[uri]
+	if w == nil {
+		return
+	}
+	delete(w.sessions, sessionID)
+	if len(w.sessions) == 0 {
+		w.cancel()
+		delete(h.subscriptions.watches, uri)
+	}
+}
+
+// unsubscribeSession removes the subscriptions of an ended session
+func (h *Handler) unsubscribeSession(sessionID string) {
+	h.subscriptions.mu.Lock()
+	defer h.subscriptions.mu.Unlock()
+	for uri := range h.subscriptions.watches {
+		h.unsubscribe(uri, sessionID)
+	}
+	delete(h.subscriptions.sessions, sessionID)
+}
+
+// resourceUpdated sends notifications/resources/updated for changed to
+// the subscribers of uri
+func (h *Handler) resourceUpdated(uri, changed string) {
+	h.subscriptions.mu.Lock()
+	var sessions []string
+	if w := h.subscriptions.watches[uri]; w != nil {
+		for id := range w.sessions {
+			sessions = append(sessions, id)
+		}
+	}
+	h.subscriptions.mu.Unlock()
+	if len(sessions) == 0 {
+		return
+	}
+
+	message, err := json.Marshal(Notification{
+		JSONRPC: "2.0",
+		Method:  NotificationResourcesUpdated,
+		Params:  map[string]interface{}{"uri": changed},
+	})
+	if err != nil {
+		return
+	}
+	for _, id := range sessions {
+		h.broadcaster.Send(id, message)
+	}
+}
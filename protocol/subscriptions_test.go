@@ -0,0 +1,138 @@
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
+)
+
+// watchingBackend serves resource watches under "mem://"
+type watchingBackend struct {
+	*backend.BaseBackend
+
+	mu      sync.Mutex
+	watches map[string]func(string)
+	stopped []string
+}
+
+func (b *watchingBackend) WatchResource(ctx context.Context, uri string, updated func(uri string)) error {
+	if uri == "mem://missing" {
+		return mcperr.NotFound("no resource %s", uri)
+	}
+	b.mu.Lock()
+	b.watches[uri] = updated
+	b.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.watches, uri)
+		b.stopped = append(b.stopped, uri)
+		b.mu.Unlock()
+	}()
+	return nil
+}
+
+func (b *watchingBackend) change(uri, changed string) bool {
+	b.mu.Lock()
+	updated := b.watches[uri]
+	b.mu.Unlock()
+	if updated != nil {
+		updated(changed)
+	}
+	return updated != nil
+}
+
+func TestHandler_ResourceSubscriptions(t *testing.T) {
+	b := &watchingBackend{BaseBackend: backend.NewBaseBackend("mem"), watches: map[string]func(string){}}
+	handler := protocol.NewHandler(b, nil)
+	broadcaster := transport.NewBroadcaster()
+	handler.SetBroadcaster(broadcaster)
+
+	sessions := session.NewManager(session.Config{}, nil)
+	alice, _ := sessions.Create("")
+	bob, _ := sessions.Create("")
+	received := map[string][]string{}
+	var mu sync.Mutex
+	for _, s := range []*session.Session{alice, bob} {
+		id := s.ID()
+		broadcaster.SubscribeSession(id, transport.NotifierFunc(func(message []byte) error {
+			var n protocol.Notification
+			json.Unmarshal(message, &n)
+			mu.Lock()
+			received[id] = append(received[id], n.Method+" "+n.Params["uri"].(string))
+			mu.Unlock()
+			return nil
+		}))
+	}
+
+	call := func(s *session.Session, method, uri string) *protocol.Error {
+		t.Helper()
+		ctx := session.WithSession(context.Background(), s)
+		data, err := handler.Handle(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":{"uri":"`+uri+`"}}`), "http")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var resp protocol.Response
+		json.Unmarshal(data, &resp)
+		return resp.Error
+	}
+
+	if err := call(alice, "resources/subscribe", "mem://notes"); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := call(bob, "resources/subscribe", "mem://notes"); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := call(alice, "resources/subscribe", "mem://missing"); err == nil || err.Code != protocol.NotFound {
+		t.Errorf("missing resource: %v", err)
+	}
+
+	b.change("mem://notes", "mem://notes/1")
+	mu.Lock()
+	if len(received[alice.ID()]) != 1 || received[bob.ID()][0] != "notifications/resources/updated mem://notes/1" {
+		t.Errorf("received %v", received)
+	}
+	mu.Unlock()
+
+	// The watch stops with its last subscriber: bob unsubscribing, alice's
+	// session ending
+	call(bob, "resources/unsubscribe", "mem://notes")
+	b.change("mem://notes", "mem://notes/2")
+	sessions.Terminate(alice.ID())
+	for i := 0; ; i++ {
+		b.mu.Lock()
+		stopped := len(b.stopped)
+		b.mu.Unlock()
+		if stopped == 1 {
+			break
+		}
+		if i == 1000 {
+			t.Fatal("watch not stopped after the session ended")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	mu.Lock()
+	if len(received[alice.ID()]) != 2 || len(received[bob.ID()]) != 1 {
+		t.Errorf("after unsubscribing: %v", received)
+	}
+	mu.Unlock()
+}
+
+func TestHandler_ResourceSubscriptionsUnsupported(t *testing.T) {
+	handler := protocol.NewHandler(backend.NewBaseBackend("plain"), nil)
+	handler.SetBroadcaster(transport.NewBroadcaster())
+	data, _ := handler.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"x://y"}}`), "stdio")
+	var resp protocol.Response
+	json.Unmarshal(data, &resp)
+	if resp.Error == nil || resp.Error.Code != protocol.MethodNotFound {
+		t.Errorf("response %+v", resp)
+	}
+}
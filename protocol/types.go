@@ -32,9 +32,10 @@ type Notification struct {
 
 // ToolInfo represents tool information for MCP
 type ToolInfo struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
 }
 
 // ToolCallResult represents the result of a tool call
@@ -42,6 +43,10 @@ type ToolCallResult struct {
 	Content []ContentItem          `json:"content"`
 	IsError bool                   `json:"isError,omitempty"`
 	Meta    map[string]interface{} `json:"_meta,omitempty"`
+
+	// StructuredContent carries the result as a JSON object for tools
+	// that declare an output schema
+	StructuredContent map[string]interface{} `json:"structuredContent,omitempty"`
 }
 
 // ContentItem represents a piece of content in the result
@@ -51,4 +56,19 @@ type ContentItem struct {
 	Data        interface{}            `json:"data,omitempty"`
 	MimeType    string                 `json:"mimeType,omitempty"`
 	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Resource links
+	URI         string `json:"uri,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Embedded resources
+	Resource *ResourceContents `json:"resource,omitempty"`
 }
+
+// NotificationToolsListChanged tells clients to re-fetch tools/list
+const NotificationToolsListChanged = "notifications/tools/list_changed"
+
+// NotificationResourcesUpdated tells a subscribed client that a resource
+// changed (see resources/subscribe)
+const NotificationResourcesUpdated = "notifications/resources/updated"
@@ -0,0 +1,302 @@
+// Package ratelimit provides token-bucket rate limiting for MCP tool calls
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Scope identifies which limit rejected a request
+type Scope string
+
+const (
+	// ScopeGlobal is the server-wide limit
+	ScopeGlobal Scope = "global"
+
+	// ScopeClient is the per-client (API key / client ID) limit
+	ScopeClient Scope = "client"
+
+	// ScopeTool is the per-tool limit
+	ScopeTool Scope = "tool"
+)
+
+// Rule configures a single token bucket
+type Rule struct {
+	// Rate is the number of requests allowed per second
+	Rate float64 `json:"rate" yaml:"rate"`
+
+	// Burst is the maximum number of requests allowed at once
+	// Defaults to ceil(Rate) when zero
+	Burst int `json:"burst" yaml:"burst"`
+}
+
+// IsZero reports whether the rule is unset (no limit)
+func (r Rule) IsZero() bool {
+	return r.Rate <= 0
+}
+
+// burst returns the effective bucket capacity
+func (r Rule) burst() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return math.Max(1, math.Ceil(r.Rate))
+}
+
+// Config holds rate limiting configuration
+type Config struct {
+	// Enabled enables rate limiting
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Global limits all tool calls across all clients
+	Global Rule `json:"global" yaml:"global"`
+
+	// PerClient limits each client independently
+	PerClient Rule `json:"per_client" yaml:"per_client"`
+
+	// PerTool limits each named tool independently (across clients)
+	PerTool map[string]Rule `json:"per_tool,omitempty" yaml:"per_tool,omitempty"`
+}
+
+// DefaultConfig returns the default configuration (disabled)
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled: false,
+		PerTool: make(map[string]Rule),
+	}
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	check := func(name string, r Rule) error {
+		if r.Rate < 0 {
+			return fmt.Errorf("%s rate must not be negative, got %v", name, r.Rate)
+		}
+		if r.Burst < 0 {
+			return fmt.Errorf("%s burst must not be negative, got %d", name, r.Burst)
+		}
+		return nil
+	}
+
+	if err := check("global", c.Global); err != nil {
+		return err
+	}
+	if err := check("per_client", c.PerClient); err != nil {
+		return err
+	}
+	for tool, r := range c.PerTool {
+		if err := check("tool "+tool, r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Decision is the result of a rate limit check
+type Decision struct {
+	Allowed    bool
+	Scope      Scope
+	RetryAfter time.Duration
+}
+
+// bucket is a token bucket
+type bucket struct {
+	tokens   float64
+	capacity float64
+	rate     float64
+	last     time.Time
+}
+
+func newBucket(r Rule, now time.Time) *bucket {
+	return &bucket{
+		tokens:   r.burst(),
+		capacity: r.burst(),
+		rate:     r.Rate,
+		last:     now,
+	}
+}
+
+// refill adds tokens accumulated since the last check
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// wait returns how long until one token is available (0 if available now)
+func (b *bucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// Limiter enforces global, per-client and per-tool limits
+// Thread-safe
+type Limiter struct {
+	mu      sync.Mutex
+	config  Config
+	global  *bucket
+	clients map[string]*list.Element // client ID → element of lru
+	lru     *list.List               // clientBucket values, most recently used first
+	tools   map[string]*bucket
+	now     func() time.Time
+}
+
+// maxClients bounds the per-client buckets: when they are full, the least
+// recently used one is evicted
+const maxClients = 10000
+
+// clientBucket is a client's bucket in the Limiter's LRU list
+type clientBucket struct {
+	id string
+	*bucket
+}
+
+// New creates a new limiter from configuration
+func New(config *Config) (*Limiter, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate limit config: %w", err)
+	}
+
+	l := &Limiter{
+		clients: make(map[string]*list.Element),
+		lru:     list.New(),
+		tools:   make(map[string]*bucket),
+		now:     time.Now,
+	}
+	l.configure(*config)
+	return l, nil
+}
+
+// configure (re)builds bucket state from config
+// Caller must hold l.mu or be the constructor
+func (l *Limiter) configure(config Config) {
+	l.config = config
+	l.global = nil
+	if !config.Global.IsZero() {
+		l.global = newBucket(config.Global, l.now())
+	}
+	l.clients = make(map[string]*list.Element)
+	l.lru = list.New()
+	l.tools = make(map[string]*bucket)
+}
+
+// Update replaces the limiter configuration, resetting all buckets
+func (l *Limiter) Update(config *Config) error {
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit config: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.configure(*config)
+	return nil
+}
+
+// Config returns a copy of the current configuration
+func (l *Limiter) Config() Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.config
+}
+
+// Allow checks whether a call to tool by clientID is permitted
+// Tokens are only consumed when every applicable limit allows the call
+func (l *Limiter) Allow(tool, clientID string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.config.Enabled {
+		return Decision{Allowed: true}
+	}
+
+	now := l.now()
+
+	type check struct {
+		scope Scope
+		b     *bucket
+	}
+	checks := make([]check, 0, 3)
+
+	if l.global != nil {
+		checks = append(checks, check{ScopeGlobal, l.global})
+	}
+
+	if !l.config.PerClient.IsZero() && clientID != "" {
+		checks = append(checks, check{ScopeClient, l.client(clientID, now)})
+	}
+
+	if rule, ok := l.config.PerTool[tool]; ok && !rule.IsZero() {
+		b, ok := l.tools[tool]
+		if !ok {
+			b = newBucket(rule, now)
+			l.tools[tool] = b
+		}
+		checks = append(checks, check{ScopeTool, b})
+	}
+
+	// Check all buckets before consuming so a rejection is side-effect free
+	for _, c := range checks {
+		c.b.refill(now)
+		if wait := c.b.wait(); wait > 0 {
+			return Decision{Allowed: false, Scope: c.scope, RetryAfter: wait}
+		}
+	}
+
+	for _, c := range checks {
+		c.b.tokens--
+	}
+
+	return Decision{Allowed: true}
+}
+
+// client returns clientID's bucket, marking it most recently used; a
+// new client evicts the least recently used one when maxClients are kept
+// Caller must hold l.mu
+func (l *Limiter) client(clientID string, now time.Time) *bucket {
+	if element, ok := l.clients[clientID]; ok {
+		l.lru.MoveToFront(element)
+		return element.Value.(clientBucket).bucket
+	}
+
+	if l.lru.Len() >= maxClients {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		delete(l.clients, oldest.Value.(clientBucket).id)
+	}
+	b := newBucket(l.config.PerClient, now)
+	l.clients[clientID] = l.lru.PushFront(clientBucket{id: clientID, bucket: b})
+	return b
+}
+
+// ============================================================
+// Client identity in context
+// ============================================================
+
+type clientIDKey struct{}
+
+// WithClientID returns a context carrying the client identifier used for per-client limits
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, clientID)
+}
+
+// ClientIDFromContext returns the client identifier stored in ctx, if any
+func ClientIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(clientIDKey{}).(string)
+	return id
+}
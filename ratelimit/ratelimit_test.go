@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a controllable time source
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestLimiter(t *testing.T, cfg *Config) (*Limiter, *fakeClock) {
+	t.Helper()
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	clock := &fakeClock{t: time.Unix(1700000000, 0)}
+	l.now = clock.now
+	l.configure(*cfg)
+	return l, clock
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	l, _ := newTestLimiter(t, &Config{Enabled: false, Global: Rule{Rate: 1}})
+
+	for i := 0; i < 100; i++ {
+		if d := l.Allow("tool", "client"); !d.Allowed {
+			t.Fatalf("call %d rejected while disabled", i)
+		}
+	}
+}
+
+func TestLimiter_GlobalBurstAndRefill(t *testing.T) {
+	l, clock := newTestLimiter(t, &Config{
+		Enabled: true,
+		Global:  Rule{Rate: 2, Burst: 3},
+	})
+
+	for i := 0; i < 3; i++ {
+		if d := l.Allow("tool", ""); !d.Allowed {
+			t.Fatalf("call %d within burst rejected", i)
+		}
+	}
+
+	d := l.Allow("tool", "")
+	if d.Allowed {
+		t.Fatal("call beyond burst should be rejected")
+	}
+	if d.Scope != ScopeGlobal {
+		t.Errorf("Scope = %v, want %v", d.Scope, ScopeGlobal)
+	}
+	if d.RetryAfter <= 0 || d.RetryAfter > 500*time.Millisecond {
+		t.Errorf("RetryAfter = %v, want (0, 500ms]", d.RetryAfter)
+	}
+
+	clock.advance(500 * time.Millisecond)
+	if d := l.Allow("tool", ""); !d.Allowed {
+		t.Error("call after refill should be allowed")
+	}
+}
+
+func TestLimiter_PerClientIsolation(t *testing.T) {
+	l, _ := newTestLimiter(t, &Config{
+		Enabled:   true,
+		PerClient: Rule{Rate: 1, Burst: 1},
+	})
+
+	if d := l.Allow("tool", "alice"); !d.Allowed {
+		t.Fatal("alice first call rejected")
+	}
+	if d := l.Allow("tool", "alice"); d.Allowed || d.Scope != ScopeClient {
+		t.Errorf("alice second call = %+v, want client rejection", d)
+	}
+	if d := l.Allow("tool", "bob"); !d.Allowed {
+		t.Error("bob should have an independent bucket")
+	}
+}
+
+func TestLimiter_ClientsBounded(t *testing.T) {
+	l, clock := newTestLimiter(t, &Config{
+		Enabled:   true,
+		PerClient: Rule{Rate: 0.001, Burst: 1},
+	})
+
+	// Drained buckets never refill, yet the buckets stay bounded
+	for i := 0; i < maxClients; i++ {
+		l.Allow("tool", fmt.Sprintf("client-%d", i))
+		clock.advance(time.Millisecond)
+	}
+	l.Allow("tool", "client-0")
+	for i := maxClients; i < maxClients+10; i++ {
+		l.Allow("tool", fmt.Sprintf("client-%d", i))
+	}
+	if len(l.clients) != maxClients || l.lru.Len() != maxClients {
+		t.Errorf("clients = %d (lru %d), want %d", len(l.clients), l.lru.Len(), maxClients)
+	}
+	if _, ok := l.clients["client-1"]; ok {
+		t.Error("least recently used client was not evicted")
+	}
+	for _, id := range []string{"client-0", fmt.Sprintf("client-%d", maxClients+9)} {
+		if _, ok := l.clients[id]; !ok {
+			t.Errorf("recently used %s was evicted", id)
+		}
+	}
+}
+
+func TestLimiter_PerTool(t *testing.T) {
+	l, _ := newTestLimiter(t, &Config{
+		Enabled: true,
+		PerTool: map[string]Rule{"search": {Rate: 1, Burst: 1}},
+	})
+
+	if d := l.Allow("search", ""); !d.Allowed {
+		t.Fatal("first search rejected")
+	}
+	if d := l.Allow("search", ""); d.Allowed || d.Scope != ScopeTool {
+		t.Errorf("second search = %+v, want tool rejection", d)
+	}
+	if d := l.Allow("read_file", ""); !d.Allowed {
+		t.Error("unlimited tool should be allowed")
+	}
+}
+
+func TestLimiter_RejectionDoesNotConsume(t *testing.T) {
+	l, _ := newTestLimiter(t, &Config{
+		Enabled:   true,
+		Global:    Rule{Rate: 1, Burst: 2},
+		PerClient: Rule{Rate: 1, Burst: 1},
+	})
+
+	l.Allow("tool", "alice")
+	// alice is now client-limited; her rejection must not drain the global bucket
+	if d := l.Allow("tool", "alice"); d.Allowed {
+		t.Fatal("expected client rejection")
+	}
+	if d := l.Allow("tool", "bob"); !d.Allowed {
+		t.Error("global token was consumed by a rejected call")
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"disabled ignores values", Config{Global: Rule{Rate: -1}}, false},
+		{"valid", Config{Enabled: true, Global: Rule{Rate: 10, Burst: 20}}, false},
+		{"negative rate", Config{Enabled: true, Global: Rule{Rate: -1}}, true},
+		{"negative burst", Config{Enabled: true, PerClient: Rule{Rate: 1, Burst: -1}}, true},
+		{"negative tool rate", Config{Enabled: true, PerTool: map[string]Rule{"x": {Rate: -2}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientIDContext(t *testing.T) {
+	ctx := context.Background()
+	if got := ClientIDFromContext(ctx); got != "" {
+		t.Errorf("ClientIDFromContext(empty) = %q, want empty", got)
+	}
+
+	ctx = WithClientID(ctx, "client-1")
+	if got := ClientIDFromContext(ctx); got != "client-1" {
+		t.Errorf("ClientIDFromContext() = %q, want %q", got, "client-1")
+	}
+}
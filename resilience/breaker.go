@@ -0,0 +1,308 @@
+// Package resilience protects backends from failing upstream dependencies
+//
+// A Breaker counts consecutive failures of the calls it guards. After
+// FailureThreshold of them it opens and fails calls immediately for
+// OpenDuration, then lets a few probe calls through (half-open): if they
+// succeed it closes again, otherwise it re-opens.
+//
+// BaseBackend guards tool calls with breakers configured per tool
+// (ToolBuilder.CircuitBreaker) or per backend (BaseBackend.UseCircuitBreaker).
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// ErrCircuitOpen indicates a call was rejected without being attempted
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker
+type State int
+
+const (
+	// StateClosed lets calls through and counts failures
+	StateClosed State = iota
+
+	// StateOpen rejects calls until OpenDuration has passed
+	StateOpen
+
+	// StateHalfOpen lets a limited number of probe calls through
+	StateHalfOpen
+)
+
+// String implements fmt.Stringer
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// Config configures a breaker
+type Config struct {
+	// FailureThreshold is the consecutive failures that open the breaker (default: 5)
+	FailureThreshold int `yaml:"failure_threshold"`
+
+	// OpenDuration is how long the breaker stays open (default: 30s)
+	OpenDuration time.Duration `yaml:"open_duration"`
+
+	// HalfOpenProbes is how many probe calls must succeed to close (default: 1)
+	// No more than this many probes run at once.
+	HalfOpenProbes int `yaml:"half_open_probes"`
+
+	// IsFailure decides which errors count against the upstream
+	// Defaults to DefaultIsFailure.
+	IsFailure func(error) bool `yaml:"-"`
+}
+
+// DefaultConfig returns the default breaker configuration
+func DefaultConfig() Config {
+	return Config{
+		FailureThreshold: 5,
+		OpenDuration:     30 * time.Second,
+		HalfOpenProbes:   1,
+	}
+}
+
+// DefaultIsFailure counts every error except cancellations by the caller
+// and categorized client errors (not found, permission denied), which say
+// nothing about the upstream's health.
+func DefaultIsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch mcperr.CodeOf(err) {
+	case mcperr.CodeNotFound, mcperr.CodePermissionDenied:
+		return false
+	}
+	return true
+}
+
+// Stats is a snapshot of a breaker
+type Stats struct {
+	Name                string    `json:"name"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Rejected            int64     `json:"rejected"`
+	OpenedAt            time.Time `json:"opened_at,omitempty"`
+}
+
+// Breaker is a circuit breaker
+type Breaker struct {
+	name   string
+	config Config
+	now    func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int // consecutive failures while closed
+	successes int // successful probes while half-open
+	probes    int // probes in flight while half-open
+	openedAt  time.Time
+	rejected  int64
+}
+
+// NewBreaker creates a closed breaker
+// Zero config fields take their defaults.
+func NewBreaker(name string, config Config) *Breaker {
+	defaults := DefaultConfig()
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaults.FailureThreshold
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = defaults.OpenDuration
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = defaults.HalfOpenProbes
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = DefaultIsFailure
+	}
+
+	b := &Breaker{
+		name:   name,
+		config: config,
+		now:    time.Now,
+	}
+	observability.SetCircuitState(name, int(StateClosed))
+	return b
+}
+
+// Name returns the breaker name
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Execute runs fn unless the breaker is open, recording its outcome
+// Rejected calls return an upstream_error (wrapping ErrCircuitOpen) whose
+// retry hint is the time left until the breaker half-opens.
+func (b *Breaker) Execute(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	done(err)
+	return err
+}
+
+// Allow reserves a call, for callers that can't wrap it in a function
+// On success, done must be called exactly once with the call's error.
+func (b *Breaker) Allow() (done func(error), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.config.OpenDuration {
+		b.setState(StateHalfOpen)
+	}
+
+	switch b.state {
+	case StateOpen:
+		return nil, b.reject(b.openedAt.Add(b.config.OpenDuration).Sub(now))
+	case StateHalfOpen:
+		if b.probes >= b.config.HalfOpenProbes {
+			return nil, b.reject(0)
+		}
+		b.probes++
+	}
+
+	var once sync.Once
+	probe := b.state == StateHalfOpen
+	return func(err error) {
+		once.Do(func() { b.record(probe, err) })
+	}, nil
+}
+
+// reject counts a rejected call and builds its error
+func (b *Breaker) reject(retryAfter time.Duration) error {
+	b.rejected++
+	observability.RecordCircuitRejection(b.name)
+
+	e := mcperr.Wrap(ErrCircuitOpen, mcperr.CodeUpstream, "%s is unavailable (circuit open)", b.name)
+	e.RetryAfter = retryAfter
+	return e.WithDetail("circuit", b.name)
+}
+
+// record applies the outcome of an allowed call
+func (b *Breaker) record(probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	failed := b.config.IsFailure(err)
+
+	if probe {
+		b.probes--
+		// The breaker may have moved on (e.g. Reset) while the probe ran
+		if b.state != StateHalfOpen {
+			return
+		}
+		if failed {
+			b.open()
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.HalfOpenProbes {
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	if b.state != StateClosed {
+		return
+	}
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.config.FailureThreshold {
+		b.open()
+	}
+}
+
+// open trips the breaker
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+// setState moves to state, resetting the counters of the state left
+func (b *Breaker) setState(state State) {
+	b.state = state
+	b.failures = 0
+	b.successes = 0
+	b.probes = 0
+	observability.SetCircuitState(b.name, int(state))
+}
+
+// State returns the current state
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.OpenDuration {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Reset closes the breaker
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.setState(StateClosed)
+}
+
+// Stats returns a snapshot of the breaker
+func (b *Breaker) Stats() Stats {
+	state := b.State()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := Stats{
+		Name:                b.name,
+		State:               state.String(),
+		ConsecutiveFailures: b.failures,
+		Rejected:            b.rejected,
+	}
+	if state != StateClosed {
+		stats.OpenedAt = b.openedAt
+	}
+	return stats
+}
+
+// HealthCheck reports open breakers as a degradation
+// The server is up, but tools depending on those upstreams fail fast, so
+// the check degrades the readiness probe rather than failing it.
+func HealthCheck(breakers func() []*Breaker) health.Check {
+	return func(ctx context.Context) error {
+		var open []string
+		for _, b := range breakers() {
+			if b.State() != StateClosed {
+				open = append(open, b.Name())
+			}
+		}
+		if len(open) > 0 {
+			return health.Degraded(fmt.Errorf("open circuits: %s", strings.Join(open, ", ")))
+		}
+		return nil
+	}
+}
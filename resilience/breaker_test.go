@@ -0,0 +1,124 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+var errUpstream = errors.New("upstream returned 503")
+
+func newTestBreaker(config Config) (*Breaker, *time.Time) {
+	b := NewBreaker("test", config)
+	now := time.Now()
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 3, OpenDuration: time.Minute})
+
+	fail := func() error { return errUpstream }
+	for i := 0; i < 2; i++ {
+		b.Execute(fail)
+	}
+	// A success resets the count
+	b.Execute(func() error { return nil })
+	for i := 0; i < 2; i++ {
+		b.Execute(fail)
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("state = %v after non-consecutive failures", b.State())
+	}
+
+	b.Execute(fail)
+	if b.State() != StateOpen {
+		t.Fatalf("state = %v, want open", b.State())
+	}
+
+	called := false
+	err := b.Execute(func() error { called = true; return nil })
+	if called {
+		t.Error("open breaker ran the call")
+	}
+	if !errors.Is(err, ErrCircuitOpen) || mcperr.CodeOf(err) != mcperr.CodeUpstream {
+		t.Errorf("error = %v, want circuit open upstream error", err)
+	}
+	if retry, _ := mcperr.RetryAfter(err); retry != time.Minute {
+		t.Errorf("retry after = %v, want 1m", retry)
+	}
+	if stats := b.Stats(); stats.Rejected != 1 || stats.State != "open" {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	b, now := newTestBreaker(Config{FailureThreshold: 1, OpenDuration: time.Second, HalfOpenProbes: 2})
+
+	b.Execute(func() error { return errUpstream })
+	*now = now.Add(time.Second)
+
+	if b.State() != StateHalfOpen {
+		t.Fatalf("state = %v, want half-open", b.State())
+	}
+
+	// Only HalfOpenProbes calls may be in flight
+	done1, err1 := b.Allow()
+	done2, err2 := b.Allow()
+	if err1 != nil || err2 != nil {
+		t.Fatalf("probes rejected: %v, %v", err1, err2)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("third probe error = %v, want circuit open", err)
+	}
+
+	done1(nil)
+	if b.State() != StateHalfOpen {
+		t.Errorf("state = %v after one successful probe", b.State())
+	}
+	done2(nil)
+	if b.State() != StateClosed {
+		t.Errorf("state = %v, want closed", b.State())
+	}
+
+	// A failed probe re-opens
+	b.Execute(func() error { return errUpstream })
+	*now = now.Add(time.Second)
+	b.Execute(func() error { return errUpstream })
+	if b.State() != StateOpen {
+		t.Errorf("state = %v, want open after failed probe", b.State())
+	}
+}
+
+func TestBreaker_IgnoresClientErrors(t *testing.T) {
+	b, _ := newTestBreaker(Config{FailureThreshold: 1})
+
+	b.Execute(func() error { return mcperr.NotFound("no such city") })
+	b.Execute(func() error { return context.Canceled })
+	if b.State() != StateClosed {
+		t.Errorf("state = %v, client errors should not trip the breaker", b.State())
+	}
+
+	b.Execute(func() error { return mcperr.Timeout("upstream slow") })
+	if b.State() != StateOpen {
+		t.Errorf("state = %v, timeouts should trip the breaker", b.State())
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	a, _ := newTestBreaker(Config{FailureThreshold: 1})
+	b := NewBreaker("other", Config{})
+	check := HealthCheck(func() []*Breaker { return []*Breaker{a, b} })
+
+	if err := check(context.Background()); err != nil {
+		t.Errorf("closed breakers: %v", err)
+	}
+	a.Execute(func() error { return errUpstream })
+	if err := check(context.Background()); !health.IsDegraded(err) {
+		t.Errorf("open breaker: error = %v, want degraded", err)
+	}
+}
@@ -0,0 +1,163 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// RetryConfig configures Retry
+type RetryConfig struct {
+	// MaxAttempts is the number of calls, including the first (default: 3)
+	MaxAttempts int `yaml:"max_attempts"`
+
+	// InitialDelay is the delay before the first retry (default: 200ms)
+	InitialDelay time.Duration `yaml:"initial_delay"`
+
+	// MaxDelay caps the delay between attempts (default: 10s)
+	MaxDelay time.Duration `yaml:"max_delay"`
+
+	// Multiplier grows the delay after each retry (default: 2)
+	Multiplier float64 `yaml:"multiplier"`
+
+	// Jitter randomizes each delay by up to this fraction (default: 0.2)
+	Jitter float64 `yaml:"jitter"`
+
+	// IsRetryable decides which errors are retried
+	// Defaults to DefaultIsRetryable.
+	IsRetryable func(error) bool `yaml:"-"`
+}
+
+// DefaultRetryConfig returns the default retry configuration
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:  3,
+		InitialDelay: 200 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// DefaultIsRetryable retries categorized errors marked retryable (timeouts,
+// rate limits, upstream failures) and nothing else; open circuits are not
+// retried since they reject calls until they half-open
+func DefaultIsRetryable(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	return mcperr.IsRetryable(err)
+}
+
+// RetryEvent describes a retry about to happen
+type RetryEvent struct {
+	// Attempt is the number of the upcoming attempt (2 for the first retry)
+	Attempt     int
+	MaxAttempts int
+
+	// Delay is the wait before the attempt
+	Delay time.Duration
+
+	// Reason is the error of the failed attempt
+	Reason error
+}
+
+// RetryObserver is notified before each retry
+type RetryObserver func(RetryEvent)
+
+type retryObserverKey struct{}
+
+// WithRetryObserver attaches an observer to ctx that Retry notifies
+// The streaming executor installs one that emits retry events, so clients
+// see why a stream stalled.
+func WithRetryObserver(ctx context.Context, observer RetryObserver) context.Context {
+	return context.WithValue(ctx, retryObserverKey{}, observer)
+}
+
+// Retry calls fn until it succeeds, fails with a non-retryable error, runs
+// out of attempts or ctx is done
+// Delays grow exponentially with jitter; a retry hint carried by the error
+// (see mcperr.RetryAfter) is honored when longer. Zero config fields take
+// their defaults.
+//
+// Example:
+//
+//	err := resilience.Retry(ctx, resilience.RetryConfig{}, func(ctx context.Context) error {
+//	    resp, err = client.Fetch(ctx, url)
+//	    return err
+//	})
+func Retry(ctx context.Context, config RetryConfig, fn func(ctx context.Context) error) error {
+	config = retryDefaults(config)
+	observer, _ := ctx.Value(retryObserverKey{}).(RetryObserver)
+
+	delay := config.InitialDelay
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt >= config.MaxAttempts || !config.IsRetryable(err) {
+			return err
+		}
+
+		wait := jitter(delay, config.Jitter)
+		if hint, ok := mcperr.RetryAfter(err); ok && hint > wait {
+			wait = hint
+		}
+		if wait > config.MaxDelay {
+			wait = config.MaxDelay
+		}
+
+		if observer != nil {
+			observer(RetryEvent{
+				Attempt:     attempt + 1,
+				MaxAttempts: config.MaxAttempts,
+				Delay:       wait,
+				Reason:      err,
+			})
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		delay = time.Duration(math.Min(float64(delay)*config.Multiplier, float64(config.MaxDelay)))
+	}
+}
+
+// retryDefaults fills in zero config fields
+func retryDefaults(config RetryConfig) RetryConfig {
+	defaults := DefaultRetryConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialDelay <= 0 {
+		config.InitialDelay = defaults.InitialDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = defaults.MaxDelay
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = defaults.Multiplier
+	}
+	if config.Jitter <= 0 {
+		config.Jitter = defaults.Jitter
+	}
+	if config.IsRetryable == nil {
+		config.IsRetryable = DefaultIsRetryable
+	}
+	return config
+}
+
+// jitter spreads d by up to ±fraction
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+	return time.Duration(float64(d) * (1 + fraction*(2*rand.Float64()-1)))
+}
@@ -0,0 +1,97 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+func fastRetry(attempts int) RetryConfig {
+	return RetryConfig{MaxAttempts: attempts, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestRetry_RetriesUntilSuccess(t *testing.T) {
+	var events []RetryEvent
+	ctx := WithRetryObserver(context.Background(), func(e RetryEvent) {
+		events = append(events, e)
+	})
+
+	calls := 0
+	err := Retry(ctx, fastRetry(5), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return mcperr.Upstream(errUpstream, "fetch failed")
+		}
+		return nil
+	})
+
+	if err != nil || calls != 3 {
+		t.Fatalf("err = %v, calls = %d, want success on the third call", err, calls)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want 2", len(events))
+	}
+	if events[0].Attempt != 2 || events[1].Attempt != 3 || events[0].MaxAttempts != 5 {
+		t.Errorf("events = %+v", events)
+	}
+	if !errors.Is(events[0].Reason, errUpstream) {
+		t.Errorf("reason = %v", events[0].Reason)
+	}
+}
+
+func TestRetry_Stops(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"not retryable", mcperr.NotFound("no such city"), 1},
+		{"plain error", errUpstream, 1},
+		{"circuit open", mcperr.Wrap(ErrCircuitOpen, mcperr.CodeUpstream, "open"), 1},
+		{"out of attempts", mcperr.Timeout("slow"), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Retry(context.Background(), fastRetry(3), func(ctx context.Context) error {
+				calls++
+				return tt.err
+			})
+			if calls != tt.wantCalls || err != tt.err {
+				t.Errorf("calls = %d, err = %v; want %d, %v", calls, err, tt.wantCalls, tt.err)
+			}
+		})
+	}
+}
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	var delay time.Duration
+	ctx := WithRetryObserver(context.Background(), func(e RetryEvent) { delay = e.Delay })
+
+	calls := 0
+	Retry(ctx, RetryConfig{MaxAttempts: 2, InitialDelay: time.Millisecond}, func(ctx context.Context) error {
+		calls++
+		return mcperr.RateLimited(20*time.Millisecond, "slow down")
+	})
+	if delay != 20*time.Millisecond {
+		t.Errorf("delay = %v, want the retry hint", delay)
+	}
+}
+
+func TestRetry_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	start := time.Now()
+	err := Retry(ctx, RetryConfig{MaxAttempts: 3, InitialDelay: time.Hour}, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return mcperr.Timeout("slow")
+	})
+	if calls != 1 || mcperr.CodeOf(err) != mcperr.CodeTimeout || time.Since(start) > time.Second {
+		t.Errorf("calls = %d, err = %v", calls, err)
+	}
+}
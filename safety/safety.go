@@ -0,0 +1,178 @@
+// Package safety provides output filters that scrub tool results
+//
+// A Redactor replaces matches of regular expression patterns in every text
+// of a result (see protocol.ToolCallResult.RewriteText) and counts them in
+// the mcp_output_redactions_total metric. Bundled pattern sets cover common
+// secrets (cloud keys, tokens, private keys) and PII (emails, card numbers).
+//
+// Example:
+//
+//	framework.NewServer(
+//	    framework.WithOutputFilter(safety.RedactSecrets()),
+//	    framework.WithOutputFilter(safety.ForTools(safety.RedactPII(), "read_file")),
+//	)
+package safety
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// Pattern is a named regular expression and its replacement
+type Pattern struct {
+	Name        string
+	Regexp      *regexp.Regexp
+	Replacement string
+}
+
+// NewPattern compiles a pattern; it panics if expr is invalid
+// The replacement defaults to "[REDACTED:<name>]".
+func NewPattern(name, expr, replacement string) Pattern {
+	if replacement == "" {
+		replacement = "[REDACTED:" + name + "]"
+	}
+	return Pattern{
+		Name:        name,
+		Regexp:      regexp.MustCompile(expr),
+		Replacement: replacement,
+	}
+}
+
+// Words builds a case-insensitive pattern masking whole words
+// (e.g. a profanity list) with mask.
+func Words(name, mask string, words ...string) Pattern {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	if mask == "" {
+		mask = "****"
+	}
+	return NewPattern(name, `(?i)\b(?:`+strings.Join(quoted, "|")+`)\b`, mask)
+}
+
+// SecretPatterns returns patterns for common credentials
+func SecretPatterns() []Pattern {
+	return []Pattern{
+		NewPattern("private_key", `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`, ""),
+		NewPattern("aws_access_key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`, ""),
+		NewPattern("github_token", `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`, ""),
+		NewPattern("slack_token", `\bxox[abposr]-[A-Za-z0-9-]{10,}\b`, ""),
+		NewPattern("stripe_key", `\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}\b`, ""),
+		NewPattern("google_api_key", `\bAIza[0-9A-Za-z_-]{35}\b`, ""),
+		NewPattern("jwt", `\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\b`, ""),
+		NewPattern("bearer_token", `(?i)\bbearer\s+[A-Za-z0-9._~+/-]{20,}=*`, "Bearer [REDACTED:bearer_token]"),
+	}
+}
+
+// PIIPatterns returns patterns for personal data
+func PIIPatterns() []Pattern {
+	return []Pattern{
+		NewPattern("email", `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`, ""),
+		NewPattern("credit_card", `\b(?:\d[ -]?){12,18}\d\b`, ""),
+		NewPattern("us_ssn", `\b\d{3}-\d{2}-\d{4}\b`, ""),
+	}
+}
+
+// Redactor replaces pattern matches in text
+type Redactor struct {
+	patterns []Pattern
+}
+
+// NewRedactor creates a redactor applying patterns in order
+func NewRedactor(patterns ...Pattern) *Redactor {
+	return &Redactor{patterns: patterns}
+}
+
+// Redact returns s with every match replaced, and the matches per pattern
+func (r *Redactor) Redact(s string) (string, map[string]int) {
+	var counts map[string]int
+	for _, p := range r.patterns {
+		matches := 0
+		s = p.Regexp.ReplaceAllStringFunc(s, func(string) string {
+			matches++
+			return p.Replacement
+		})
+		if matches > 0 {
+			if counts == nil {
+				counts = make(map[string]int)
+			}
+			counts[p.Name] += matches
+		}
+	}
+	return s, counts
+}
+
+// Filter returns an output filter redacting tool results
+func (r *Redactor) Filter() protocol.OutputFilter {
+	return func(ctx context.Context, tool string, result *protocol.ToolCallResult) error {
+		total := make(map[string]int)
+		result.RewriteText(func(s string) string {
+			s, counts := r.Redact(s)
+			for name, n := range counts {
+				total[name] += n
+			}
+			return s
+		})
+		for name, n := range total {
+			observability.RecordOutputRedactions(name, tool, n)
+		}
+		return nil
+	}
+}
+
+// RedactSecrets returns a filter redacting SecretPatterns
+func RedactSecrets() protocol.OutputFilter {
+	return NewRedactor(SecretPatterns()...).Filter()
+}
+
+// RedactPII returns a filter redacting PIIPatterns
+func RedactPII() protocol.OutputFilter {
+	return NewRedactor(PIIPatterns()...).Filter()
+}
+
+// Block returns a filter withholding results matching any pattern
+// The call fails with a permission_denied error naming the pattern, e.g.
+// to refuse returning a file that contains a private key.
+func Block(patterns ...Pattern) protocol.OutputFilter {
+	return func(ctx context.Context, tool string, result *protocol.ToolCallResult) error {
+		var matched string
+		result.RewriteText(func(s string) string {
+			if matched != "" {
+				return s
+			}
+			for _, p := range patterns {
+				if p.Regexp.MatchString(s) {
+					matched = p.Name
+					break
+				}
+			}
+			return s
+		})
+		if matched == "" {
+			return nil
+		}
+		observability.RecordOutputRedactions(matched, tool, 1)
+		return mcperr.PermissionDenied("result of %s withheld: it contains a %s", tool, matched).
+			WithDetail("pattern", matched)
+	}
+}
+
+// ForTools applies filter to the named tools only
+func ForTools(filter protocol.OutputFilter, tools ...string) protocol.OutputFilter {
+	only := make(map[string]bool, len(tools))
+	for _, t := range tools {
+		only[t] = true
+	}
+	return func(ctx context.Context, tool string, result *protocol.ToolCallResult) error {
+		if !only[tool] {
+			return nil
+		}
+		return filter(ctx, tool, result)
+	}
+}
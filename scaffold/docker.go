@@ -0,0 +1,177 @@
+// Package scaffold generates deployment files for MCP servers
+//
+// The generated Dockerfile builds a static binary in a multi-stage build and
+// runs it on a distroless, non-root base image. The compose file exposes the
+// MCP and metrics ports and wires a container health check.
+package scaffold
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DockerOptions configures generated container files
+type DockerOptions struct {
+	// Name of the service and binary (e.g. "weather-server")
+	Name string
+
+	// MainPackage is the package to build (default: ".")
+	MainPackage string
+
+	// GoVersion of the build image (default: "1.25")
+	GoVersion string
+
+	// Port the MCP HTTP transport listens on (default: 8080)
+	Port int
+
+	// MetricsPort of the Prometheus endpoint (default: 9091, 0 disables)
+	MetricsPort int
+
+	// HealthPath is probed by the container health check (default: "/health")
+	HealthPath string
+}
+
+// withDefaults fills unset options
+func (o DockerOptions) withDefaults() (DockerOptions, error) {
+	if o.Name == "" {
+		return o, errors.New("scaffold: name is required")
+	}
+	if strings.ContainsAny(o.Name, " /\\:") {
+		return o, fmt.Errorf("scaffold: invalid name %q", o.Name)
+	}
+	if o.MainPackage == "" {
+		o.MainPackage = "."
+	}
+	if o.GoVersion == "" {
+		o.GoVersion = "1.25"
+	}
+	if o.Port == 0 {
+		o.Port = 8080
+	}
+	if o.MetricsPort == 0 {
+		o.MetricsPort = 9091
+	}
+	if o.MetricsPort < 0 {
+		o.MetricsPort = 0
+	}
+	if o.HealthPath == "" {
+		o.HealthPath = "/health"
+	}
+	return o, nil
+}
+
+var dockerfileTemplate = template.Must(template.New("Dockerfile").Parse(`# syntax=docker/dockerfile:1
+
+# ---- build ----
+FROM golang:{{.GoVersion}} AS build
+WORKDIR /src
+
+COPY go.mod go.sum ./
+RUN go mod download
+
+COPY . .
+RUN CGO_ENABLED=0 go build -trimpath -ldflags="-s -w" -o /out/{{.Name}} {{.MainPackage}}
+
+# ---- runtime ----
+FROM gcr.io/distroless/static-debian12:nonroot
+COPY --from=build /out/{{.Name}} /{{.Name}}
+
+ENV PORT={{.Port}}
+EXPOSE {{.Port}}{{if .MetricsPort}} {{.MetricsPort}}{{end}}
+
+USER nonroot:nonroot
+ENTRYPOINT ["/{{.Name}}"]
+`))
+
+var composeTemplate = template.Must(template.New("compose").Parse(`services:
+  {{.Name}}:
+    build: .
+    image: {{.Name}}:latest
+    restart: unless-stopped
+    environment:
+      PORT: "{{.Port}}"
+    ports:
+      - "{{.Port}}:{{.Port}}"{{if .MetricsPort}}
+      - "{{.MetricsPort}}:{{.MetricsPort}}"{{end}}
+    read_only: true
+    security_opt:
+      - no-new-privileges:true
+    # Distroless images have no shell or curl; the server binary probes itself
+    healthcheck:
+      test: ["CMD", "/{{.Name}}", "-healthcheck", "http://127.0.0.1:{{.Port}}{{.HealthPath}}"]
+      interval: 15s
+      timeout: 3s
+      retries: 3
+      start_period: 5s
+`))
+
+// Dockerfile renders a multi-stage, distroless, non-root Dockerfile
+func Dockerfile(opts DockerOptions) (string, error) {
+	return render(dockerfileTemplate, opts)
+}
+
+// Compose renders a docker-compose file with metrics and a health check
+func Compose(opts DockerOptions) (string, error) {
+	return render(composeTemplate, opts)
+}
+
+// WriteDockerFiles writes Dockerfile, compose.yaml and .dockerignore into dir
+// Existing files are never overwritten.
+func WriteDockerFiles(dir string, opts DockerOptions) error {
+	dockerfile, err := Dockerfile(opts)
+	if err != nil {
+		return err
+	}
+	compose, err := Compose(opts)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"Dockerfile", dockerfile},
+		{"compose.yaml", compose},
+		{".dockerignore", dockerignore},
+	}
+
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("scaffold: %s already exists", path)
+		}
+	}
+
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.name), []byte(f.content), 0o644); err != nil {
+			return fmt.Errorf("scaffold: write %s: %w", f.name, err)
+		}
+	}
+
+	return nil
+}
+
+const dockerignore = `.git
+*.md
+workspace/
+*.test
+`
+
+func render(t *template.Template, opts DockerOptions) (string, error) {
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, opts); err != nil {
+		return "", fmt.Errorf("scaffold: render %s: %w", t.Name(), err)
+	}
+	return buf.String(), nil
+}
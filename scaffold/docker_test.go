@@ -0,0 +1,69 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDockerfile(t *testing.T) {
+	out, err := Dockerfile(DockerOptions{Name: "weather-server", MainPackage: "./cmd/weather"})
+	if err != nil {
+		t.Fatalf("Dockerfile() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"FROM golang:1.25 AS build",
+		"go build -trimpath -ldflags=\"-s -w\" -o /out/weather-server ./cmd/weather",
+		"FROM gcr.io/distroless/static-debian12:nonroot",
+		"USER nonroot:nonroot",
+		"EXPOSE 8080 9091",
+		`ENTRYPOINT ["/weather-server"]`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Dockerfile missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	out, err := Compose(DockerOptions{Name: "fs", Port: 9000, MetricsPort: -1})
+	if err != nil {
+		t.Fatalf("Compose() error = %v", err)
+	}
+
+	if !strings.Contains(out, `"9000:9000"`) {
+		t.Errorf("compose missing port mapping\n%s", out)
+	}
+	if strings.Contains(out, "9091") {
+		t.Errorf("compose should not expose metrics when disabled\n%s", out)
+	}
+	if !strings.Contains(out, `"-healthcheck", "http://127.0.0.1:9000/health"`) {
+		t.Errorf("compose missing healthcheck\n%s", out)
+	}
+}
+
+func TestWriteDockerFiles(t *testing.T) {
+	dir := t.TempDir()
+	opts := DockerOptions{Name: "fs"}
+
+	if err := WriteDockerFiles(dir, opts); err != nil {
+		t.Fatalf("WriteDockerFiles() error = %v", err)
+	}
+	for _, name := range []string{"Dockerfile", "compose.yaml", ".dockerignore"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+
+	if err := WriteDockerFiles(dir, opts); err == nil {
+		t.Error("expected error when files already exist")
+	}
+}
+
+func TestDockerOptions_RequiresName(t *testing.T) {
+	if _, err := Dockerfile(DockerOptions{}); err == nil {
+		t.Error("expected error for missing name")
+	}
+}
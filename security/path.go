@@ -0,0 +1,81 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResolvePath returns the real path of a root-relative path
+//
+// Absolute paths, ".." components leaving the root and NUL bytes are
+// rejected. Each existing component is checked for symlinks: without
+// AllowSymlinks any symlink is rejected, and with it the link's target must
+// also lie inside the root. Components that don't exist yet (a file about to
+// be created) are appended as given.
+//
+// The check cannot stop a symlink created between validation and use; roots
+// shared with untrusted writers need OS-level isolation as well.
+func (m *Manager) ResolvePath(path string) (string, error) {
+	if strings.ContainsRune(path, 0) {
+		return "", deny("path", "path contains a NUL byte")
+	}
+	if filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", deny("path", "absolute paths not allowed: %s", path)
+	}
+
+	clean := filepath.Clean(path)
+	if escapes(clean) {
+		return "", deny("path", "path traversal attempt detected: %s", path)
+	}
+
+	root := m.root
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+	if clean == "." {
+		return root, nil
+	}
+
+	current := root
+	parts := strings.Split(clean, string(filepath.Separator))
+	for i, part := range parts {
+		next := filepath.Join(current, part)
+
+		info, err := os.Lstat(next)
+		if os.IsNotExist(err) {
+			return filepath.Join(append([]string{next}, parts[i+1:]...)...), nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !m.allowSymlinks {
+				return "", deny("symlink", "symlinks not allowed: %s", path)
+			}
+			target, err := filepath.EvalSymlinks(next)
+			if err != nil {
+				return "", deny("symlink", "cannot resolve symlink in %s", path)
+			}
+			if !within(root, target) {
+				return "", deny("symlink", "symlink escapes root: %s", path)
+			}
+			next = target
+		}
+		current = next
+	}
+
+	return current, nil
+}
+
+// within reports whether path is root or below it
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && !escapes(rel)
+}
+
+// escapes reports whether a cleaned relative path leaves its base
+func escapes(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,194 @@
+package security
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// ReadOnly denies every operation but reads
+func ReadOnly() Policy {
+	return PolicyFunc(func(ctx context.Context, req Request) error {
+		if req.Op != OpRead {
+			return deny("read_only", "read-only mode enabled, operation not allowed: %s", req.Op)
+		}
+		return nil
+	})
+}
+
+// MaxSize denies writes larger than limit bytes
+func MaxSize(limit int64) Policy {
+	return PolicyFunc(func(ctx context.Context, req Request) error {
+		if req.Op == OpWrite && req.Size > limit {
+			return deny("max_file_size", "file size exceeds limit: %d > %d bytes", req.Size, limit)
+		}
+		return nil
+	})
+}
+
+// Extensions restricts file extensions (compared case-insensitively)
+// A non-empty allowed list permits only those extensions; blocked always wins.
+func Extensions(allowed, blocked []string) Policy {
+	normalize := func(exts []string) map[string]bool {
+		set := make(map[string]bool, len(exts))
+		for _, ext := range exts {
+			ext = strings.ToLower(ext)
+			if ext != "" && !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			set[ext] = true
+		}
+		return set
+	}
+	allow, block := normalize(allowed), normalize(blocked)
+
+	return PolicyFunc(func(ctx context.Context, req Request) error {
+		if req.Path == "" {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(req.Path))
+		if block[ext] {
+			return deny("extensions", "file extension blocked: %s", ext)
+		}
+		if len(allow) > 0 && !allow[ext] {
+			return deny("extensions", "file extension not in whitelist: %s", ext)
+		}
+		return nil
+	})
+}
+
+// DenyPaths rejects paths matching any glob pattern
+// Patterns use path.Match syntax with forward slashes and are matched
+// against the whole path and each of its parent directories, so ".git"
+// also covers ".git/config".
+func DenyPaths(patterns ...string) Policy {
+	return PolicyFunc(func(ctx context.Context, req Request) error {
+		if req.Path == "" {
+			return nil
+		}
+		p := filepath.ToSlash(filepath.Clean(req.Path))
+		for {
+			for _, pattern := range patterns {
+				if ok, _ := path.Match(pattern, p); ok {
+					return deny("deny_paths", "access to %s is denied", req.Path)
+				}
+			}
+			dir := path.Dir(p)
+			if dir == p || dir == "." || dir == "/" {
+				return nil
+			}
+			p = dir
+		}
+	})
+}
+
+// DestructiveRate allows at most max destructive operations per window
+// Excess operations are denied as rate limited, with a retry hint.
+func DestructiveRate(max int, window time.Duration) Policy {
+	return &destructiveRate{slidingWindow: newSlidingWindow(max, window)}
+}
+
+type destructiveRate struct {
+	slidingWindow
+}
+
+// Check implements Policy
+func (r *destructiveRate) Check(ctx context.Context, req Request) error {
+	if !req.Op.Destructive() {
+		return nil
+	}
+	if retryAfter, ok := r.take(!IsDryRun(ctx)); !ok {
+		return mcperr.RateLimited(retryAfter, "too many destructive operations: %d per %s", r.max, r.length).
+			WithDetail("policy", "destructive_rate")
+	}
+	return nil
+}
+
+// slidingWindow counts events over the last length of time
+type slidingWindow struct {
+	max    int
+	length time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	recent []time.Time
+}
+
+func newSlidingWindow(max int, length time.Duration) slidingWindow {
+	return slidingWindow{max: max, length: length, now: time.Now}
+}
+
+// take admits one more event, recording it if record is set; when the
+// window is full it returns how long until an event leaves it
+func (w *slidingWindow) take(record bool) (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.expire()
+	if len(w.recent) >= w.max {
+		return w.recent[0].Add(w.length).Sub(now), false
+	}
+	if record {
+		w.recent = append(w.recent, now)
+	}
+	return 0, true
+}
+
+// count returns the number of events in the window
+func (w *slidingWindow) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.expire()
+	return len(w.recent)
+}
+
+// expire drops the events older than the window, returning the time
+// Callers hold mu.
+func (w *slidingWindow) expire() time.Time {
+	now := w.now()
+	cutoff := now.Add(-w.length)
+	kept := w.recent[:0]
+	for _, t := range w.recent {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	w.recent = kept
+	return now
+}
+
+// shellMetacharacters are rejected in commands, which are never run through a shell
+const shellMetacharacters = ";&|<>`$\\\n\r*?~(){}[]!#\"'"
+
+// Commands restricts OpExecute to the named programs
+// With no programs, execution is denied outright. Command lines may not
+// contain shell metacharacters, so they are safe to split on whitespace
+// and pass to exec without a shell.
+func Commands(allowed ...string) Policy {
+	set := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		set[name] = true
+	}
+
+	return PolicyFunc(func(ctx context.Context, req Request) error {
+		if req.Op != OpExecute {
+			return nil
+		}
+		if i := strings.IndexAny(req.Command, shellMetacharacters); i >= 0 {
+			return deny("commands", "shell metacharacter %q not allowed", req.Command[i])
+		}
+		fields := strings.Fields(req.Command)
+		if len(fields) == 0 {
+			return deny("commands", "empty command")
+		}
+		if !set[fields[0]] {
+			return deny("commands", "command not allowed: %s", fields[0])
+		}
+		return nil
+	})
+}
@@ -0,0 +1,143 @@
+package security
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// QuotaRescan is how long counted usage is trusted
+// Writes checked in between add their sizes to it; deletes and writes
+// made outside the manager show at the next count.
+var QuotaRescan = 5 * time.Second
+
+// Usage is a workspace's consumption of its quotas
+// A zero limit is unlimited.
+type Usage struct {
+	Bytes    int64 `json:"bytes"`
+	Files    int   `json:"files"`
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+	MaxFiles int   `json:"max_files,omitempty"`
+
+	// Ops is the number of operations authorized in the last minute
+	Ops             int `json:"ops_last_minute"`
+	MaxOpsPerMinute int `json:"max_ops_per_minute,omitempty"`
+}
+
+// quota tracks the bytes and files below the root
+type quota struct {
+	root     string
+	maxBytes int64
+	maxFiles int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	bytes   int64
+	files   int
+	counted time.Time
+}
+
+// Check implements Policy, denying writes that would take the workspace
+// over its byte or file limit
+func (q *quota) Check(ctx context.Context, req Request) error {
+	if req.Op != OpWrite || req.Path == "" {
+		return nil
+	}
+
+	var existing int64
+	files := req.Files
+	if files == 0 && req.Size > 0 {
+		files = 1
+	}
+	if info, err := os.Lstat(filepath.Join(q.root, filepath.Clean(req.Path))); err == nil && !info.IsDir() {
+		existing, files = info.Size(), 0
+	}
+	grow := max(req.Size-existing, 0)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.refresh(false)
+
+	if q.maxBytes > 0 && grow > 0 && q.bytes+grow > q.maxBytes {
+		return mcperr.PermissionDenied("workspace quota exceeded: %d bytes used, %d more requested, limit %d",
+			q.bytes, grow, q.maxBytes).
+			WithDetail("policy", "max_total_bytes").
+			WithDetail("used_bytes", q.bytes).
+			WithDetail("requested_bytes", grow).
+			WithDetail("max_total_bytes", q.maxBytes)
+	}
+	if q.maxFiles > 0 && files > 0 && q.files+files > q.maxFiles {
+		return mcperr.PermissionDenied("workspace file limit reached: %d files, %d more requested, limit %d",
+			q.files, files, q.maxFiles).
+			WithDetail("policy", "max_files").
+			WithDetail("used_files", q.files).
+			WithDetail("requested_files", files).
+			WithDetail("max_files", q.maxFiles)
+	}
+	if !IsDryRun(ctx) {
+		q.bytes += grow
+		q.files += files
+	}
+	return nil
+}
+
+// usage returns the bytes and files below the root, counting them again
+// if force is set or the last count is stale
+func (q *quota) usage(force bool) (int64, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.refresh(force)
+	return q.bytes, q.files
+}
+
+// refresh recounts the usage when due; callers hold mu
+func (q *quota) refresh(force bool) {
+	now := q.now()
+	if !force && !q.counted.IsZero() && now.Sub(q.counted) < QuotaRescan {
+		return
+	}
+	var bytes int64
+	files := 0
+	filepath.WalkDir(q.root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			bytes += info.Size()
+			files++
+		}
+		return nil
+	})
+	q.bytes, q.files, q.counted = bytes, files, now
+}
+
+// spend charges an operation against the operations budget
+func (m *Manager) spend(ctx context.Context) error {
+	if m.ops == nil {
+		return nil
+	}
+	if retryAfter, ok := m.ops.take(!IsDryRun(ctx)); !ok {
+		return mcperr.RateLimited(retryAfter, "operation budget exhausted: %d per minute", m.ops.max).
+			WithDetail("policy", "max_ops_per_minute").
+			WithDetail("ops_last_minute", m.ops.count()).
+			WithDetail("max_ops_per_minute", m.ops.max)
+	}
+	return nil
+}
+
+// Usage counts the workspace's bytes and files and reports them with the
+// operations of the last minute, against the configured limits
+func (m *Manager) Usage() Usage {
+	u := Usage{MaxBytes: m.quota.maxBytes, MaxFiles: m.quota.maxFiles}
+	u.Bytes, u.Files = m.quota.usage(true)
+	if m.ops != nil {
+		u.Ops = m.ops.count()
+		u.MaxOpsPerMinute = m.ops.max
+	}
+	return u
+}
@@ -0,0 +1,316 @@
+// Package security sandboxes tool inputs that name files or commands
+//
+// A Manager confines paths to a root directory (rejecting traversal and
+// symlink escapes) and runs every operation through a chain of policies:
+// read-only mode, size limits, extension and path rules, workspace quotas,
+// the rate of destructive operations and shell input rules. Backends that take paths
+// or command lines from tool arguments call it before touching anything.
+//
+// Denials are *mcperr.Error values, so the protocol handler reports them
+// as Forbidden (or RateLimitExceeded) without further mapping.
+package security
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+// Operation is the kind of access a request makes
+type Operation string
+
+const (
+	OpRead    Operation = "read"
+	OpWrite   Operation = "write"
+	OpDelete  Operation = "delete"
+	OpExecute Operation = "execute"
+)
+
+// Destructive reports whether the operation destroys data
+func (o Operation) Destructive() bool {
+	return o == OpDelete
+}
+
+// Request describes one operation to check
+type Request struct {
+	Op Operation
+
+	// Path is the path as given by the caller, relative to the root
+	Path string
+
+	// Size is the number of bytes to be written (0 if unknown or not a write)
+	Size int64
+
+	// Files is the number of files a write creates, such as a directory
+	// copy's; 0 means one for a sized write. Writes over an existing file
+	// create none.
+	Files int
+
+	// Command is the command line of an OpExecute request
+	Command string
+
+	// Resource names the object of a request that is not on a file,
+	// such as a container
+	Resource string
+}
+
+// Policy decides whether a request is allowed
+type Policy interface {
+	Check(ctx context.Context, req Request) error
+}
+
+// PolicyFunc adapts a function to Policy
+type PolicyFunc func(ctx context.Context, req Request) error
+
+// Check implements Policy
+func (f PolicyFunc) Check(ctx context.Context, req Request) error {
+	return f(ctx, req)
+}
+
+// Config configures a Manager
+// Zero values disable the corresponding policy, except the size limits,
+// which default to 10MB and 1000 entries.
+type Config struct {
+	// Root is the directory paths are confined to
+	Root string `yaml:"root"`
+
+	// AllowSymlinks follows symlinks that resolve inside Root
+	// When false, paths through any symlink are rejected.
+	AllowSymlinks bool `yaml:"allow_symlinks"`
+
+	// ReadOnly rejects every operation but reads
+	ReadOnly bool `yaml:"read_only"`
+
+	MaxFileSize    int64 `yaml:"max_file_size"`
+	MaxFilesPerDir int   `yaml:"max_files_per_dir"`
+
+	// AllowedExtensions, when set, is the only extensions permitted
+	AllowedExtensions []string `yaml:"allowed_extensions"`
+	BlockedExtensions []string `yaml:"blocked_extensions"`
+
+	// DenyPaths are glob patterns (e.g. ".git", "secrets/*") that may not be touched
+	DenyPaths []string `yaml:"deny_paths"`
+
+	// MaxDestructiveOps limits deletes per DestructiveWindow (default window: 1m)
+	MaxDestructiveOps int           `yaml:"max_destructive_ops"`
+	DestructiveWindow time.Duration `yaml:"destructive_window"`
+
+	// AllowedCommands, when set, enables OpExecute for these programs only
+	AllowedCommands []string `yaml:"allowed_commands"`
+
+	// MaxTotalBytes and MaxFiles are quotas on everything below Root
+	MaxTotalBytes int64 `yaml:"max_total_bytes"`
+	MaxFiles      int   `yaml:"max_files"`
+
+	// MaxOpsPerMinute is the operations budget: the paths authorized per
+	// minute, whatever the operation
+	MaxOpsPerMinute int `yaml:"max_ops_per_minute"`
+}
+
+// Manager confines paths to a root and enforces policies
+type Manager struct {
+	root           string
+	allowSymlinks  bool
+	maxFilesPerDir int
+	policies       []Policy
+	quota          *quota
+
+	// ops is the operations budget; nil is unlimited
+	ops *slidingWindow
+}
+
+// NewManager creates a manager with the policies described by config
+func NewManager(config Config) (*Manager, error) {
+	if config.Root == "" {
+		return nil, fmt.Errorf("security: root is required")
+	}
+	root, err := filepath.Abs(config.Root)
+	if err != nil {
+		return nil, fmt.Errorf("security: resolve root: %w", err)
+	}
+
+	if config.MaxFileSize == 0 {
+		config.MaxFileSize = 10 * 1024 * 1024 // 10MB
+	}
+	if config.MaxFilesPerDir == 0 {
+		config.MaxFilesPerDir = 1000
+	}
+
+	m := &Manager{
+		root:           root,
+		allowSymlinks:  config.AllowSymlinks,
+		maxFilesPerDir: config.MaxFilesPerDir,
+		quota:          &quota{root: root, maxBytes: config.MaxTotalBytes, maxFiles: config.MaxFiles, now: time.Now},
+	}
+	if config.MaxOpsPerMinute > 0 {
+		ops := newSlidingWindow(config.MaxOpsPerMinute, time.Minute)
+		m.ops = &ops
+	}
+
+	if config.ReadOnly {
+		m.Use(ReadOnly())
+	}
+	m.Use(MaxSize(config.MaxFileSize))
+	if len(config.AllowedExtensions) > 0 || len(config.BlockedExtensions) > 0 {
+		m.Use(Extensions(config.AllowedExtensions, config.BlockedExtensions))
+	}
+	if len(config.DenyPaths) > 0 {
+		m.Use(DenyPaths(config.DenyPaths...))
+	}
+	if config.MaxDestructiveOps > 0 {
+		window := config.DestructiveWindow
+		if window <= 0 {
+			window = time.Minute
+		}
+		m.Use(DestructiveRate(config.MaxDestructiveOps, window))
+	}
+	m.Use(Commands(config.AllowedCommands...))
+	if config.MaxTotalBytes > 0 || config.MaxFiles > 0 {
+		m.Use(m.quota)
+	}
+
+	return m, nil
+}
+
+// Use appends policies to the chain
+func (m *Manager) Use(policies ...Policy) {
+	m.policies = append(m.policies, policies...)
+}
+
+// Root returns the absolute root directory
+func (m *Manager) Root() string {
+	return m.root
+}
+
+// Check runs req through every policy, returning the first denial
+// Requests with a Path must also resolve inside the root.
+func (m *Manager) Check(ctx context.Context, req Request) error {
+	if req.Path != "" {
+		resolved, err := m.ResolvePath(req.Path)
+		if err != nil {
+			return err
+		}
+		if err := m.checkTarget(ctx, req, resolved); err != nil {
+			return err
+		}
+	}
+	return m.checkPolicies(ctx, req)
+}
+
+// Authorize resolves path and checks op on it, returning the resolved path
+// It is the start of an operation, counted against the operations budget;
+// Check is not.
+func (m *Manager) Authorize(ctx context.Context, op Operation, path string) (string, error) {
+	resolved, err := m.ResolvePath(path)
+	if err != nil {
+		return "", err
+	}
+	req := Request{Op: op, Path: path}
+	if err := m.checkTarget(ctx, req, resolved); err != nil {
+		return "", err
+	}
+	if err := m.checkPolicies(ctx, req); err != nil {
+		return "", err
+	}
+	if err := m.spend(ctx); err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
+
+// checkTarget checks req against the policies on the path it resolved
+// to, when symlinks lead elsewhere, so a link cannot reach what its
+// target's path would be denied; the check is a dry run, as req itself is
+// checked next
+func (m *Manager) checkTarget(ctx context.Context, req Request, resolved string) error {
+	target, err := m.GetRelativePath(resolved)
+	if err != nil || target == filepath.Clean(req.Path) {
+		return nil
+	}
+	req.Path = target
+	return m.checkPolicies(DryRun(ctx), req)
+}
+
+func (m *Manager) checkPolicies(ctx context.Context, req Request) error {
+	for _, p := range m.policies {
+		if err := p.Check(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidatePath resolves path inside the root (see ResolvePath)
+func (m *Manager) ValidatePath(path string) (string, error) {
+	return m.ResolvePath(path)
+}
+
+// ValidateFileOperation checks op on path against the policies, counting
+// it against the operations budget as Authorize does
+func (m *Manager) ValidateFileOperation(path string, op Operation) error {
+	if err := m.Check(context.Background(), Request{Op: op, Path: path}); err != nil {
+		return err
+	}
+	return m.spend(context.Background())
+}
+
+// ValidateFileSize checks a write of size bytes against the size limit
+func (m *Manager) ValidateFileSize(size int64) error {
+	return m.Check(context.Background(), Request{Op: OpWrite, Size: size})
+}
+
+// ValidateDirectorySize checks the number of entries in dirPath
+func (m *Manager) ValidateDirectorySize(dirPath string) error {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+	if len(entries) > m.maxFilesPerDir {
+		return deny("max_files_per_dir", "directory contains too many files: %d > %d",
+			len(entries), m.maxFilesPerDir)
+	}
+	return nil
+}
+
+// GetRelativePath returns a resolved path relative to the root
+func (m *Manager) GetRelativePath(fullPath string) (string, error) {
+	if real, err := filepath.EvalSymlinks(m.root); err == nil {
+		if rel, err := filepath.Rel(real, fullPath); err == nil && !escapes(rel) {
+			return rel, nil
+		}
+	}
+	return filepath.Rel(m.root, fullPath)
+}
+
+// EnsureRoot creates the root directory if it doesn't exist
+func (m *Manager) EnsureRoot() error {
+	return os.MkdirAll(m.root, 0755)
+}
+
+// ============================================================
+// Dry runs
+// ============================================================
+
+type dryRunKey struct{}
+
+// DryRun returns a context for checking operations that will not be
+// performed: policies decide as usual but record nothing, so a dry run
+// does not count against the destructive rate
+func DryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx comes from DryRun
+func IsDryRun(ctx context.Context) bool {
+	dry, _ := ctx.Value(dryRunKey{}).(bool)
+	return dry
+}
+
+// deny returns a permission-denied error naming the policy
+func deny(policy, format string, args ...interface{}) error {
+	return mcperr.PermissionDenied(format, args...).WithDetail("policy", policy)
+}
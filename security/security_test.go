@@ -0,0 +1,291 @@
+package security
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+)
+
+func newTestManager(t *testing.T, config Config) *Manager {
+	t.Helper()
+	if config.Root == "" {
+		config.Root = t.TempDir()
+	}
+	m, err := NewManager(config)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return m
+}
+
+func TestResolvePath_Traversal(t *testing.T) {
+	m := newTestManager(t, Config{})
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"notes.txt", true},
+		{"a/b/../c.txt", true},
+		{"a..b.txt", true},
+		{".", true},
+		{"..", false},
+		{"../etc/passwd", false},
+		{"a/../../etc/passwd", false},
+		{"/etc/passwd", false},
+		{"bad\x00name", false},
+	}
+
+	for _, tt := range tests {
+		resolved, err := m.ResolvePath(tt.path)
+		if tt.allowed {
+			if err != nil {
+				t.Errorf("ResolvePath(%q) error = %v", tt.path, err)
+			} else if !within(m.Root(), resolved) {
+				if real, _ := filepath.EvalSymlinks(m.Root()); !within(real, resolved) {
+					t.Errorf("ResolvePath(%q) = %q, outside root", tt.path, resolved)
+				}
+			}
+			continue
+		}
+		if mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+			t.Errorf("ResolvePath(%q) error = %v, want permission denied", tt.path, err)
+		}
+	}
+}
+
+func TestResolvePath_Symlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	os.Mkdir(filepath.Join(root, "docs"), 0755)
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644)
+
+	if err := os.Symlink(filepath.Join(root, "docs"), filepath.Join(root, "inside")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	os.Symlink(outside, filepath.Join(root, "escape"))
+	os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling"))
+
+	strict := newTestManager(t, Config{Root: root})
+	if _, err := strict.ResolvePath("inside/readme.md"); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("symlinks disabled: error = %v", err)
+	}
+
+	lenient := newTestManager(t, Config{Root: root, AllowSymlinks: true})
+
+	resolved, err := lenient.ResolvePath("inside/readme.md")
+	if err != nil {
+		t.Fatalf("symlink inside root: %v", err)
+	}
+	realRoot, _ := filepath.EvalSymlinks(root)
+	if want := filepath.Join(realRoot, "docs", "readme.md"); resolved != want {
+		t.Errorf("resolved = %q, want %q", resolved, want)
+	}
+
+	for _, path := range []string{"escape/secret.txt", "escape", "dangling", "dangling/new.txt"} {
+		if _, err := lenient.ResolvePath(path); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+			t.Errorf("ResolvePath(%q) error = %v, want permission denied", path, err)
+		}
+	}
+}
+
+func TestManager_PoliciesOnSymlinkTarget(t *testing.T) {
+	root := t.TempDir()
+	os.Mkdir(filepath.Join(root, "secrets"), 0755)
+	os.WriteFile(filepath.Join(root, "secrets", "key.pem"), []byte("key"), 0644)
+	if err := os.Symlink(filepath.Join(root, "secrets", "key.pem"), filepath.Join(root, "ok.txt")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	os.Symlink(filepath.Join(root, "secrets", "key.pem"), filepath.Join(root, "ok.pem"))
+
+	m := newTestManager(t, Config{
+		Root:              root,
+		AllowSymlinks:     true,
+		AllowedExtensions: []string{".txt", ".pem"},
+		BlockedExtensions: []string{".key"},
+		DenyPaths:         []string{"secrets/*"},
+	})
+	ctx := context.Background()
+
+	for _, path := range []string{"ok.txt", "ok.pem"} {
+		if _, err := m.Authorize(ctx, OpRead, path); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+			t.Errorf("Authorize(%q) error = %v, want permission denied", path, err)
+		}
+		if err := m.Check(ctx, Request{Op: OpRead, Path: path}); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+			t.Errorf("Check(%q) error = %v, want permission denied", path, err)
+		}
+	}
+
+	// Extensions apply to the target too
+	os.WriteFile(filepath.Join(root, "notes.key"), []byte("k"), 0644)
+	os.Symlink(filepath.Join(root, "notes.key"), filepath.Join(root, "notes.txt"))
+	if _, err := m.Authorize(ctx, OpRead, "notes.txt"); mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+		t.Errorf("Authorize(notes.txt) error = %v, want permission denied", err)
+	}
+}
+
+func TestManager_Policies(t *testing.T) {
+	m := newTestManager(t, Config{
+		ReadOnly:          false,
+		MaxFileSize:       10,
+		AllowedExtensions: []string{".txt", "md"},
+		BlockedExtensions: []string{".exe"},
+		DenyPaths:         []string{".git", "secrets/*"},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		req     Request
+		allowed bool
+	}{
+		{"allowed write", Request{Op: OpWrite, Path: "a.txt", Size: 5}, true},
+		{"extension without dot", Request{Op: OpRead, Path: "README.MD"}, true},
+		{"too large", Request{Op: OpWrite, Path: "a.txt", Size: 11}, false},
+		{"not whitelisted", Request{Op: OpRead, Path: "a.go"}, false},
+		{"blocked", Request{Op: OpRead, Path: "a.exe"}, false},
+		{"denied directory", Request{Op: OpRead, Path: ".git/notes.txt"}, false},
+		{"denied glob", Request{Op: OpRead, Path: "secrets/key.txt"}, false},
+		{"execute disabled", Request{Op: OpExecute, Command: "ls"}, false},
+	}
+
+	for _, tt := range tests {
+		err := m.Check(ctx, tt.req)
+		if tt.allowed && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.allowed && mcperr.CodeOf(err) != mcperr.CodePermissionDenied {
+			t.Errorf("%s: error = %v, want permission denied", tt.name, err)
+		}
+	}
+
+	readOnly := newTestManager(t, Config{ReadOnly: true})
+	if err := readOnly.ValidateFileOperation("a.txt", OpRead); err != nil {
+		t.Errorf("read-only read: %v", err)
+	}
+	if err := readOnly.ValidateFileOperation("a.txt", OpDelete); err == nil {
+		t.Error("read-only delete allowed")
+	}
+}
+
+func TestDestructiveRate(t *testing.T) {
+	policy := DestructiveRate(2, time.Minute).(*destructiveRate)
+	now := time.Now()
+	policy.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Dry runs are checked but not counted
+	for i := 0; i < 3; i++ {
+		if err := policy.Check(DryRun(ctx), Request{Op: OpDelete, Path: "a.txt"}); err != nil {
+			t.Fatalf("dry run %d: %v", i, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := policy.Check(ctx, Request{Op: OpDelete, Path: "a.txt"}); err != nil {
+			t.Fatalf("delete %d: %v", i, err)
+		}
+	}
+	if err := policy.Check(ctx, Request{Op: OpWrite, Path: "a.txt"}); err != nil {
+		t.Errorf("writes are not destructive: %v", err)
+	}
+
+	err := policy.Check(ctx, Request{Op: OpDelete, Path: "a.txt"})
+	if mcperr.CodeOf(err) != mcperr.CodeRateLimited {
+		t.Fatalf("error = %v, want rate limited", err)
+	}
+	if retry, _ := mcperr.RetryAfter(err); retry != time.Minute {
+		t.Errorf("retry after = %v", retry)
+	}
+
+	now = now.Add(time.Minute + time.Second)
+	if err := policy.Check(ctx, Request{Op: OpDelete, Path: "a.txt"}); err != nil {
+		t.Errorf("after window: %v", err)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	m := newTestManager(t, Config{MaxTotalBytes: 100, MaxFiles: 2})
+	ctx := context.Background()
+	os.WriteFile(filepath.Join(m.Root(), "a.txt"), make([]byte, 60), 0644)
+
+	// Overwriting a file counts the growth only
+	if err := m.Check(ctx, Request{Op: OpWrite, Path: "a.txt", Size: 90}); err != nil {
+		t.Fatalf("growing a.txt: %v", err)
+	}
+	err := m.Check(ctx, Request{Op: OpWrite, Path: "b.txt", Size: 20})
+	e, _ := mcperr.As(err)
+	if e == nil || e.Code != mcperr.CodePermissionDenied || e.Details["used_bytes"] != int64(90) || e.Details["policy"] != "max_total_bytes" {
+		t.Fatalf("over the byte quota: %v", err)
+	}
+	if err := m.Check(DryRun(ctx), Request{Op: OpWrite, Path: "b.txt", Size: 10}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if err := m.Check(ctx, Request{Op: OpWrite, Path: "b.txt", Size: 10}); err != nil {
+		t.Fatalf("b.txt: %v", err)
+	}
+	err = m.Check(ctx, Request{Op: OpWrite, Path: "c.txt", Files: 1})
+	if e, _ := mcperr.As(err); e == nil || e.Details["used_files"] != 2 {
+		t.Errorf("over the file quota: %v", err)
+	}
+
+	// A recount sees what was actually written
+	if u := m.Usage(); u.Bytes != 60 || u.Files != 1 || u.MaxBytes != 100 || u.MaxFiles != 2 {
+		t.Errorf("usage %+v", u)
+	}
+}
+
+func TestOperationBudget(t *testing.T) {
+	m := newTestManager(t, Config{MaxOpsPerMinute: 2})
+	ctx := context.Background()
+
+	if _, err := m.Authorize(DryRun(ctx), OpDelete, "a.txt"); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := m.Authorize(ctx, OpRead, "a.txt"); err != nil {
+			t.Fatalf("operation %d: %v", i, err)
+		}
+		// Checks within an operation are free
+		if err := m.Check(ctx, Request{Op: OpRead, Path: "a.txt"}); err != nil {
+			t.Fatalf("check %d: %v", i, err)
+		}
+	}
+	err := m.ValidateFileOperation("a.txt", OpWrite)
+	if mcperr.CodeOf(err) != mcperr.CodeRateLimited {
+		t.Fatalf("over budget: %v", err)
+	}
+	if retry, _ := mcperr.RetryAfter(err); retry <= 0 || retry > time.Minute {
+		t.Errorf("retry after %v", retry)
+	}
+	if u := m.Usage(); u.Ops != 2 || u.MaxOpsPerMinute != 2 {
+		t.Errorf("usage %+v", u)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	policy := Commands("git", "ls")
+	ctx := context.Background()
+
+	tests := map[string]bool{
+		"git status":         true,
+		"ls -la docs":        true,
+		"rm -rf /":           false,
+		"ls; rm -rf /":       false,
+		"ls $(whoami)":       false,
+		"git log | head":     false,
+		"ls `id`":            false,
+		"git status\nrm -rf": false,
+		"":                   false,
+	}
+	for command, allowed := range tests {
+		err := policy.Check(ctx, Request{Op: OpExecute, Command: command})
+		if allowed != (err == nil) {
+			t.Errorf("Check(%q) error = %v, want allowed=%v", command, err, allowed)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package service
+
+import "net"
+
+// Listeners returns nil; socket activation is a systemd feature
+func Listeners() ([]net.Listener, error) {
+	return nil, nil
+}
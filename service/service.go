@@ -0,0 +1,107 @@
+// Package service runs MCP servers as background daemons
+//
+// It integrates with systemd (readiness notification, watchdog and socket
+// activation) and with the Windows service control manager, so the same
+// binary can be installed as a unit file or a Windows service:
+//
+//	server := framework.NewServer(...)
+//	if err := service.Run(ctx, server, service.Options{Name: "mcp-fs"}); err != nil {
+//	    log.Fatal(err)
+//	}
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/framework"
+)
+
+// Options configures how the server runs as a service
+type Options struct {
+	// Name is the Windows service name (ignored elsewhere)
+	Name string
+
+	// Logger for service lifecycle messages (default: slog.Default())
+	Logger *slog.Logger
+}
+
+// Run runs server under the current service manager
+//
+// Under the Windows service control manager, stop and shutdown requests
+// cancel the server context. Under systemd, an activated socket (if any) is
+// handed to the HTTP transport, READY=1 is sent once requests are accepted,
+// the watchdog is fed, and STOPPING=1 is sent on shutdown. Otherwise the
+// server simply runs in the foreground.
+func Run(ctx context.Context, server *framework.Server, opts Options) error {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	isService, err := IsWindowsService()
+	if err != nil {
+		return fmt.Errorf("detect windows service: %w", err)
+	}
+	if isService {
+		return RunWindowsService(opts.Name, server.Run)
+	}
+
+	return runSystemd(ctx, server, opts.Logger)
+}
+
+// runSystemd runs server with systemd integration when available
+func runSystemd(ctx context.Context, server *framework.Server, logger *slog.Logger) error {
+	listeners, err := Listeners()
+	if err != nil {
+		return fmt.Errorf("socket activation: %w", err)
+	}
+	if len(listeners) > 0 {
+		if len(listeners) > 1 {
+			logger.Warn("multiple activated sockets, serving the first", "count", len(listeners))
+			for _, l := range listeners[1:] {
+				l.Close()
+			}
+		}
+		logger.Info("using socket-activated listener", "address", listeners[0].Addr().String())
+		server.UseListener(listeners[0])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	server.OnReady(func() {
+		if _, err := Notify(NotifyReady); err != nil {
+			logger.Warn("sd_notify failed", "error", err)
+		}
+		if interval, ok := WatchdogInterval(); ok {
+			go feedWatchdog(ctx, interval/2, logger)
+		}
+	})
+
+	err = server.Run(ctx)
+
+	if _, nerr := Notify(NotifyStopping); nerr != nil {
+		logger.Warn("sd_notify failed", "error", nerr)
+	}
+
+	return err
+}
+
+// feedWatchdog pings the systemd watchdog until ctx is done
+func feedWatchdog(ctx context.Context, every time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := Notify(NotifyWatchdog); err != nil {
+				logger.Warn("watchdog notify failed", "error", err)
+			}
+		}
+	}
+}
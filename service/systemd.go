@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// sd_notify states
+const (
+	NotifyReady     = "READY=1"
+	NotifyStopping  = "STOPPING=1"
+	NotifyReloading = "RELOADING=1"
+	NotifyWatchdog  = "WATCHDOG=1"
+)
+
+// NotifyStatus returns a STATUS= message for Notify
+func NotifyStatus(status string) string {
+	return "STATUS=" + status
+}
+
+// Notify sends state to the systemd notification socket ($NOTIFY_SOCKET)
+// Returns false without error when not running under systemd
+func Notify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+
+	// Abstract namespace sockets are written with a leading '@'
+	addr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	if socket[0] == '@' {
+		addr.Name = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		return false, fmt.Errorf("dial notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("write notify socket: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the systemd watchdog timeout for this process
+// Returns false when the watchdog is not enabled
+func WatchdogInterval() (time.Duration, bool) {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" {
+		if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+			return 0, false
+		}
+	}
+
+	return time.Duration(usec) * time.Microsecond, true
+}
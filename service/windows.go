@@ -0,0 +1,72 @@
+//go:build windows
+
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// IsWindowsService reports whether the process is run by the service control manager
+func IsWindowsService() (bool, error) {
+	return svc.IsWindowsService()
+}
+
+// RunWindowsService runs fn as the Windows service name
+// Stop and shutdown requests cancel the context passed to fn.
+func RunWindowsService(name string, fn func(ctx context.Context) error) error {
+	h := &windowsHandler{run: fn}
+	if err := svc.Run(name, h); err != nil {
+		return fmt.Errorf("run windows service %q: %w", name, err)
+	}
+	return h.err
+}
+
+// windowsHandler adapts a run function to svc.Handler
+type windowsHandler struct {
+	run func(ctx context.Context) error
+	err error
+}
+
+// Execute implements svc.Handler
+func (h *windowsHandler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown
+
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- h.run(ctx) }()
+
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for {
+		select {
+		case err := <-done:
+			h.err = err
+			changes <- svc.Status{State: svc.StopPending}
+			if err != nil {
+				return true, 1
+			}
+			return false, 0
+
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				h.err = <-done
+				if h.err != nil {
+					return true, 1
+				}
+				return false, 0
+			}
+		}
+	}
+}
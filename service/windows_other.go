@@ -0,0 +1,21 @@
+//go:build !windows
+
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotSupported is returned by Windows service helpers on other platforms
+var ErrNotSupported = errors.New("windows services are not supported on this platform")
+
+// IsWindowsService always reports false on non-Windows platforms
+func IsWindowsService() (bool, error) {
+	return false, nil
+}
+
+// RunWindowsService is only supported on Windows
+func RunWindowsService(name string, fn func(ctx context.Context) error) error {
+	return ErrNotSupported
+}
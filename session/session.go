@@ -0,0 +1,344 @@
+// Package session tracks MCP client sessions across requests
+//
+// A transport opens a session when a client initializes, hands the client
+// its ID and attaches the session to the context of every later request
+// carrying that ID. Tool handlers keep per-client state in it:
+//
+//	func (b *MyBackend) handleNext(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+//	    s, ok := session.FromContext(ctx)
+//	    if !ok {
+//	        return nil, errors.New("paging requires a session")
+//	    }
+//	    cursor, _ := s.Get("cursor")
+//	    ...
+//	    s.Set("cursor", next)
+//	}
+//
+// Sessions expire after sitting idle and end when the client or an
+// operator terminates them.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// Defaults for Config
+const (
+	DefaultIdleTimeout = 30 * time.Minute
+	DefaultMaxSessions = 1000
+)
+
+// ErrTooManySessions is returned by Create when MaxSessions are open
+var ErrTooManySessions = errors.New("too many sessions")
+
+// Config configures sessions
+type Config struct {
+	Enabled bool `yaml:"enabled"`
+
+	// IdleTimeout ends sessions without requests for this long
+	// (default DefaultIdleTimeout)
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+
+	// MaxSessions bounds the open sessions (default DefaultMaxSessions)
+	MaxSessions int `yaml:"max_sessions"`
+}
+
+// Validate validates the configuration
+func (c Config) Validate() error {
+	if c.IdleTimeout < 0 {
+		return fmt.Errorf("session idle_timeout must not be negative, got %s", c.IdleTimeout)
+	}
+	if c.MaxSessions < 0 {
+		return fmt.Errorf("session max_sessions must not be negative, got %d", c.MaxSessions)
+	}
+	return nil
+}
+
+// ============================================================
+// Sessions
+// ============================================================
+
+// Session is one client's state across requests
+// Its methods are safe for concurrent use by the client's requests.
+type Session struct {
+	id        string
+	principal string
+	created   time.Time
+
+	mu         sync.Mutex
+	lastActive time.Time
+	values     map[string]interface{}
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// ID returns the session ID, which the client presents on every request
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Principal returns the ID of the principal that opened the session, if
+// the transport authenticates
+func (s *Session) Principal() string {
+	return s.principal
+}
+
+// Get returns a value stored in the session
+func (s *Session) Get(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set stores a value in the session
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Delete removes a value from the session
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// Done is closed when the session ends, so streams serving it can stop
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// touch records activity at now
+func (s *Session) touch(now time.Time) {
+	s.mu.Lock()
+	s.lastActive = now
+	s.mu.Unlock()
+}
+
+// idleSince returns when the session was last used
+func (s *Session) idleSince() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastActive
+}
+
+// end closes Done
+func (s *Session) end() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// Info describes a session, for listings
+type Info struct {
+	ID         string    `json:"id"`
+	Principal  string    `json:"principal,omitempty"`
+	Created    time.Time `json:"created"`
+	LastActive time.Time `json:"last_active"`
+	Values     int       `json:"values"`
+}
+
+// info describes s
+func (s *Session) info() Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Info{
+		ID:         s.id,
+		Principal:  s.principal,
+		Created:    s.created,
+		LastActive: s.lastActive,
+		Values:     len(s.values),
+	}
+}
+
+// ============================================================
+// Manager
+// ============================================================
+
+// Manager issues, looks up and expires sessions
+type Manager struct {
+	config Config
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewManager creates a manager; Run expires idle sessions
+func NewManager(config Config, logger *slog.Logger) *Manager {
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+	if config.MaxSessions == 0 {
+		config.MaxSessions = DefaultMaxSessions
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Manager{
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+		sessions: make(map[string]*Session),
+	}
+}
+
+// Create opens a session for principal ("" when unauthenticated)
+// Expired sessions are swept first; if MaxSessions are still open it
+// returns ErrTooManySessions.
+func (m *Manager) Create(principal string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if len(m.sessions) >= m.config.MaxSessions {
+		m.sweep(now)
+		if len(m.sessions) >= m.config.MaxSessions {
+			return nil, ErrTooManySessions
+		}
+	}
+
+	s := &Session{
+		// 130 random bits: IDs are bearer credentials for the session
+		id:         rand.Text(),
+		principal:  principal,
+		created:    now,
+		lastActive: now,
+		values:     make(map[string]interface{}),
+		done:       make(chan struct{}),
+	}
+	m.sessions[s.id] = s
+	observability.RecordSessionStarted()
+	m.logger.Debug("session opened", "session", s.id, "principal", principal)
+	return s, nil
+}
+
+// Get returns an open session and records activity on it
+func (m *Manager) Get(id string) (*Session, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	now := m.now()
+	if m.expired(s, now) {
+		m.remove(s, "expired")
+		return nil, false
+	}
+	s.touch(now)
+	return s, true
+}
+
+// Terminate ends a session, reporting whether it was open
+func (m *Manager) Terminate(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if ok {
+		m.remove(s, "terminated")
+	}
+	return ok
+}
+
+// List describes the open sessions, oldest first
+func (m *Manager) List() []Info {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(m.now())
+	infos := make([]Info, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		infos = append(infos, s.info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Created.Before(infos[j].Created) })
+	return infos
+}
+
+// Len returns the number of open sessions, including expired ones not
+// swept yet
+func (m *Manager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// Run expires idle sessions until ctx is done, then ends the rest
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(max(m.config.IdleTimeout/4, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.Close()
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			m.sweep(m.now())
+			m.mu.Unlock()
+		}
+	}
+}
+
+// Close ends every session
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.sessions {
+		m.remove(s, "shutdown")
+	}
+}
+
+// sweep removes expired sessions; m.mu must be held
+func (m *Manager) sweep(now time.Time) {
+	for _, s := range m.sessions {
+		if m.expired(s, now) {
+			m.remove(s, "expired")
+		}
+	}
+}
+
+// expired reports whether s has been idle too long
+func (m *Manager) expired(s *Session, now time.Time) bool {
+	return now.Sub(s.idleSince()) > m.config.IdleTimeout
+}
+
+// remove ends s; m.mu must be held
+func (m *Manager) remove(s *Session, reason string) {
+	delete(m.sessions, s.id)
+	s.end()
+	observability.RecordSessionEnded(reason)
+	m.logger.Debug("session ended", "session", s.id, "reason", reason)
+}
+
+// ============================================================
+// Session in context
+// ============================================================
+
+type sessionKey struct{}
+
+// WithSession returns a context carrying the session of a request
+// Transports call it before passing the request to the handler.
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// FromContext returns the session of the request ctx belongs to
+func FromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(sessionKey{}).(*Session)
+	return s, ok && s != nil
+}
@@ -0,0 +1,106 @@
+package session_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/session"
+)
+
+func TestManager(t *testing.T) {
+	m := session.NewManager(session.Config{MaxSessions: 2}, nil)
+
+	a, err := m.Create("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := m.Create("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID() == b.ID() || len(a.ID()) < 26 {
+		t.Errorf("session IDs %q, %q", a.ID(), b.ID())
+	}
+	if _, err := m.Create("carol"); !errors.Is(err, session.ErrTooManySessions) {
+		t.Errorf("Create beyond MaxSessions: %v", err)
+	}
+
+	got, ok := m.Get(a.ID())
+	if !ok || got != a || got.Principal() != "alice" {
+		t.Fatalf("Get = %v, %v", got, ok)
+	}
+	a.Set("cursor", 42)
+	if v, ok := got.Get("cursor"); !ok || v != 42 {
+		t.Errorf("cursor = %v, %v", v, ok)
+	}
+	a.Delete("cursor")
+	if _, ok := a.Get("cursor"); ok {
+		t.Error("cursor not deleted")
+	}
+
+	if !m.Terminate(a.ID()) || m.Terminate(a.ID()) {
+		t.Error("Terminate should report the session open once")
+	}
+	select {
+	case <-a.Done():
+	default:
+		t.Error("Done not closed by Terminate")
+	}
+	if _, ok := m.Get(a.ID()); ok {
+		t.Error("terminated session still found")
+	}
+	if infos := m.List(); len(infos) != 1 || infos[0].ID != b.ID() {
+		t.Errorf("List = %+v", infos)
+	}
+}
+
+func TestManager_IdleExpiry(t *testing.T) {
+	m := session.NewManager(session.Config{IdleTimeout: 50 * time.Millisecond, MaxSessions: 1}, nil)
+	idle, err := m.Create("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(80 * time.Millisecond)
+
+	if _, ok := m.Get(idle.ID()); ok {
+		t.Error("idle session still found")
+	}
+	select {
+	case <-idle.Done():
+	default:
+		t.Error("Done not closed on expiry")
+	}
+
+	// Expired sessions make room for new ones
+	m.Create("")
+	time.Sleep(80 * time.Millisecond)
+	if _, err := m.Create(""); err != nil {
+		t.Errorf("Create after expiry: %v", err)
+	}
+
+	// Run ends the remaining sessions when its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	<-done
+	if m.Len() != 0 {
+		t.Errorf("%d sessions open after Run", m.Len())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if _, ok := session.FromContext(context.Background()); ok {
+		t.Error("FromContext found a session in an empty context")
+	}
+	m := session.NewManager(session.Config{}, nil)
+	s, _ := m.Create("")
+	if got, ok := session.FromContext(session.WithSession(context.Background(), s)); !ok || got != s {
+		t.Errorf("FromContext = %v, %v", got, ok)
+	}
+}
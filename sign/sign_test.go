@@ -0,0 +1,144 @@
+package sign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSignVerify(t *testing.T) {
+	signer, err := NewSigner(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewVerifier(VerifierConfig{
+		Secrets: [][]byte{[]byte("old-secret-0123456789"), testSecret},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := []byte(`{"event":"tool.completed"}`)
+	req, err := signer.NewRequest("https://hooks.example.com/mcp", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := verifier.Verify(req)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if string(got) != string(body) {
+		t.Errorf("body = %q", got)
+	}
+	if again, _ := io.ReadAll(req.Body); string(again) != string(body) {
+		t.Errorf("body not restored: %q", again)
+	}
+
+	// Same request again is a replay
+	req.Body = io.NopCloser(strings.NewReader(string(body)))
+	if _, err := verifier.Verify(req); !errors.Is(err, ErrReplayed) {
+		t.Errorf("replay error = %v, want ErrReplayed", err)
+	}
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	signer, _ := NewSigner(testSecret)
+	verifier, _ := NewVerifier(VerifierConfig{Secrets: [][]byte{testSecret}})
+	body := []byte(`{"amount":10}`)
+
+	tampered, _ := signer.NewRequest("https://hooks.example.com", body)
+	tampered.Body = io.NopCloser(strings.NewReader(`{"amount":1000}`))
+	if _, err := verifier.Verify(tampered); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("tampered body: error = %v", err)
+	}
+
+	unsigned, _ := http.NewRequest(http.MethodPost, "https://hooks.example.com", strings.NewReader("{}"))
+	if _, err := verifier.Verify(unsigned); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("unsigned: error = %v", err)
+	}
+
+	signer.now = func() time.Time { return time.Now().Add(-10 * time.Minute) }
+	stale, _ := signer.NewRequest("https://hooks.example.com", body)
+	if _, err := verifier.Verify(stale); !errors.Is(err, ErrExpired) {
+		t.Errorf("stale: error = %v", err)
+	}
+
+	other, _ := NewSigner([]byte("another-secret-0123456789"))
+	wrongKey, _ := other.NewRequest("https://hooks.example.com", body)
+	if _, err := verifier.Verify(wrongKey); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("wrong key: error = %v", err)
+	}
+}
+
+func TestTransportAndMiddleware(t *testing.T) {
+	signer, _ := NewSigner(testSecret)
+	verifier, _ := NewVerifier(VerifierConfig{Secrets: [][]byte{testSecret}})
+
+	var received string
+	server := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received = string(b)
+	})))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{Signer: signer}}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"ok":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || received != `{"ok":true}` {
+		t.Errorf("status = %d, received = %q", resp.StatusCode, received)
+	}
+
+	resp, err = http.Post(server.URL, "application/json", strings.NewReader(`{"ok":true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unsigned status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestGitHubVerifier(t *testing.T) {
+	secret := []byte("It's a Secret to Everybody")
+	verifier, _ := NewGitHubVerifier(secret)
+
+	// Example from GitHub's webhook documentation
+	body := "Hello, World!"
+	const want = "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(body))
+	if got := "sha256=" + hex.EncodeToString(h.Sum(nil)); got != want {
+		t.Fatalf("test vector mismatch: %s", got)
+	}
+
+	newDelivery := func(signature string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(body))
+		req.Header.Set(HeaderGitHubSignature, signature)
+		req.Header.Set(HeaderGitHubDelivery, "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+		return req
+	}
+
+	if _, err := verifier.Verify(newDelivery(want)); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if _, err := verifier.Verify(newDelivery(want)); !errors.Is(err, ErrReplayed) {
+		t.Errorf("redelivery: error = %v, want ErrReplayed", err)
+	}
+	if _, err := verifier.Verify(newDelivery("sha256=00")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("bad signature: error = %v", err)
+	}
+}
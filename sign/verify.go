@@ -0,0 +1,213 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTolerance is the clock skew accepted between signer and verifier
+const DefaultTolerance = 5 * time.Minute
+
+// DefaultMaxBodySize bounds the bodies read for verification
+const DefaultMaxBodySize = 10 << 20 // 10MB
+
+// VerifierConfig configures a Verifier
+type VerifierConfig struct {
+	// Secrets are tried in order, so a secret can be rotated by
+	// accepting the old and new one for a while
+	Secrets [][]byte
+
+	// Tolerance is the accepted clock skew (default: DefaultTolerance)
+	Tolerance time.Duration
+
+	// Nonces records used nonces (default: a MemoryNonceStore)
+	Nonces NonceStore
+
+	// MaxBodySize bounds the body read (default: DefaultMaxBodySize)
+	MaxBodySize int64
+}
+
+// Verifier checks signed requests
+type Verifier struct {
+	secrets     [][]byte
+	tolerance   time.Duration
+	nonces      NonceStore
+	maxBodySize int64
+	now         func() time.Time
+}
+
+// NewVerifier creates a verifier
+func NewVerifier(config VerifierConfig) (*Verifier, error) {
+	if len(config.Secrets) == 0 {
+		return nil, fmt.Errorf("sign: at least one secret is required")
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = DefaultTolerance
+	}
+	if config.Nonces == nil {
+		config.Nonces = NewMemoryNonceStore()
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+
+	return &Verifier{
+		secrets:     config.Secrets,
+		tolerance:   config.Tolerance,
+		nonces:      config.Nonces,
+		maxBodySize: config.MaxBodySize,
+		now:         time.Now,
+	}, nil
+}
+
+// Verify checks req's signature and returns its body
+// The request body is consumed and replaced, so handlers can read it again.
+func (v *Verifier) Verify(req *http.Request) ([]byte, error) {
+	timestamp := req.Header.Get(HeaderTimestamp)
+	nonce := req.Header.Get(HeaderNonce)
+	signature := req.Header.Get(HeaderSignature)
+	if timestamp == "" || nonce == "" || signature == "" {
+		return nil, ErrMissingSignature
+	}
+
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed timestamp", ErrInvalidSignature)
+	}
+	signedAt := time.Unix(sent, 0)
+	if skew := v.now().Sub(signedAt); skew > v.tolerance || skew < -v.tolerance {
+		return nil, ErrExpired
+	}
+
+	body, err := readLimitedBody(req, v.maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.matches(signature, timestamp, nonce, body) {
+		return nil, ErrInvalidSignature
+	}
+
+	// Record the nonce only for authentic requests, and for as long as
+	// its timestamp would still be accepted
+	if v.nonces.Seen(nonce, signedAt.Add(v.tolerance)) {
+		return nil, ErrReplayed
+	}
+	return body, nil
+}
+
+// matches reports whether signature is valid under any secret
+func (v *Verifier) matches(signature, timestamp, nonce string, body []byte) bool {
+	version, encoded, ok := strings.Cut(signature, "=")
+	if !ok || version != signatureVersion {
+		return false
+	}
+	got, err := hex.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+	for _, secret := range v.secrets {
+		if hmac.Equal(got, mac(secret, timestamp, nonce, body)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware rejects requests without a valid signature with 401
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return verifyMiddleware(v.Verify, next)
+}
+
+// verifyMiddleware adapts a verify function to HTTP middleware
+func verifyMiddleware(verify func(*http.Request) ([]byte, error), next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := verify(r); err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, errBodyTooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// readLimitedBody reads at most limit bytes of body, leaving it readable
+func readLimitedBody(req *http.Request, limit int64) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, limit+1))
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, errBodyTooLarge
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// ============================================================
+// Nonce store
+// ============================================================
+
+// NonceStore remembers nonces to reject replays
+// Servers running several replicas should share one (e.g. in Redis).
+type NonceStore interface {
+	// Seen records nonce until expires and reports whether it was
+	// already recorded
+	Seen(nonce string, expires time.Time) bool
+}
+
+// MemoryNonceStore is an in-process NonceStore
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryNonceStore creates an empty in-memory nonce store
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Seen implements NonceStore
+func (s *MemoryNonceStore) Seen(nonce string, expires time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for n, exp := range s.nonces {
+			if now.After(exp) {
+				delete(s.nonces, n)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if exp, ok := s.nonces[nonce]; ok && !now.After(exp) {
+		return true
+	}
+	s.nonces[nonce] = expires
+	return false
+}
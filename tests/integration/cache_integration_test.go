@@ -75,6 +75,12 @@ func TestCache_EndToEndIntegration(t *testing.T) {
 	json.Unmarshal(resp1, &result1)
 	json.Unmarshal(resp2, &result2)
 
+	// Each call has its own request ID; the cached data must not
+	id1, id2 := popRequestID(result1.Result), popRequestID(result2.Result)
+	if id1 == "" || id1 == id2 {
+		t.Errorf("request IDs = %q, %q; want two distinct IDs", id1, id2)
+	}
+
 	// Compare the actual result data
 	r1JSON, _ := json.Marshal(result1.Result)
 	r2JSON, _ := json.Marshal(result2.Result)
@@ -137,3 +143,16 @@ func createTestBackend(t *testing.T) backend.ServerBackend {
 
 	return base
 }
+
+// popRequestID removes _meta.requestId from a decoded result, and _meta
+// if nothing else is left in it
+func popRequestID(result interface{}) string {
+	m, _ := result.(map[string]interface{})
+	meta, _ := m["_meta"].(map[string]interface{})
+	id, _ := meta["requestId"].(string)
+	delete(meta, "requestId")
+	if meta != nil && len(meta) == 0 {
+		delete(m, "_meta")
+	}
+	return id
+}
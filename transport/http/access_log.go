@@ -0,0 +1,135 @@
+package http
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// HeaderRequestID carries a request's ID; a client's value is kept,
+// otherwise one is generated, and the response echoes it. JSON-RPC
+// responses also carry it as _meta.requestId.
+const HeaderRequestID = "X-Request-ID"
+
+// AccessLogConfig configures the access log of the HTTP transport
+type AccessLogConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// SampleRate is the share of successful requests logged, 0 to 1
+	// (default 1). Failed (4xx, 5xx) and slow requests are always logged.
+	SampleRate float64 `yaml:"sample_rate"`
+
+	// SlowThreshold marks requests that take longer as slow (0: none)
+	SlowThreshold time.Duration `yaml:"slow_threshold"`
+
+	// SkipPaths are not logged unless they fail or are slow, e.g. probe
+	// endpoints
+	SkipPaths []string `yaml:"skip_paths"`
+}
+
+// sampled reports whether a successful request is logged
+func (c AccessLogConfig) sampled() bool {
+	if c.SampleRate <= 0 || c.SampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < c.SampleRate
+}
+
+// withRequestID gives a request its ID: one assigned earlier (by the
+// access log), the client's X-Request-ID if usable, or a new one
+// The response echoes the ID, and the request's context carries it
+// (see observability.RequestIDFromContext).
+func withRequestID(w http.ResponseWriter, r *http.Request) (*http.Request, string) {
+	if id, ok := observability.RequestIDFromContext(r.Context()); ok {
+		return r, id
+	}
+	id := strings.TrimSpace(r.Header.Get(HeaderRequestID))
+	if !observability.ValidRequestID(id) {
+		id = observability.NewRequestID()
+	}
+	w.Header().Set(HeaderRequestID, id)
+	return r.WithContext(observability.WithRequestID(r.Context(), id)), id
+}
+
+// accessLog logs every request with its outcome, level by status: 5xx
+// as errors and 4xx as warnings, which the colored handler highlights
+func (t *HTTPTransport) accessLog(next http.Handler) http.Handler {
+	config := t.config.AccessLog
+	if !config.Enabled {
+		return next
+	}
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, p := range config.SkipPaths {
+		skip[p] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, id := withRequestID(w, r)
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		duration := time.Since(start)
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelError
+		case rec.status >= 400:
+			level = slog.LevelWarn
+		case config.SlowThreshold > 0 && duration > config.SlowThreshold:
+			level = slog.LevelWarn
+		case skip[r.URL.Path] || !config.sampled():
+			return
+		}
+
+		t.logger.LogAttrs(r.Context(), level, "http request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Int64("bytes", rec.bytes),
+			slog.Duration("duration", duration),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("request_id", id),
+		)
+	})
+}
+
+// responseRecorder captures the status and size of a response
+// It passes Flush through, so event streams keep working.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
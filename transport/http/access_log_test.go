@@ -0,0 +1,152 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// accessLogRecords returns the "http request" records a JSON log holds
+func accessLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			continue
+		}
+		if rec["msg"] == "http request" {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+func TestHTTPTransport_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := &mockHandler{HandleResult: []byte(`{"jsonrpc":"2.0","result":"ok","id":1}`)}
+	tr := NewHTTPTransport(handler, HTTPConfig{
+		MaxRequestSize: 1024,
+		AccessLog:      AccessLogConfig{Enabled: true, SkipPaths: []string{PathHealthLive}},
+	}, logger, nil, nil)
+	h := tr.Handler()
+
+	req := httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(`{"jsonrpc":"2.0","method":"ping","id":1}`))
+	req.Header.Set(HeaderRequestID, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if got := w.Header().Get(HeaderRequestID); got != "abc-123" {
+		t.Errorf("response %s = %q, want the client's", HeaderRequestID, got)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, PathRPC, nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, PathHealthLive, nil))
+
+	records := accessLogRecords(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("got %d access log records, want 2 (probe skipped): %s", len(records), buf.String())
+	}
+	rpc := records[0]
+	if rpc["method"] != "POST" || rpc["path"] != PathRPC || rpc["status"] != float64(200) ||
+		rpc["request_id"] != "abc-123" || rpc["level"] != "INFO" {
+		t.Errorf("rpc record = %v", rpc)
+	}
+	if rpc["bytes"] != float64(len(handler.HandleResult)) {
+		t.Errorf("bytes = %v, want %d", rpc["bytes"], len(handler.HandleResult))
+	}
+	if _, ok := rpc["duration"]; !ok || rpc["remote_addr"] == "" {
+		t.Errorf("rpc record lacks duration or remote_addr: %v", rpc)
+	}
+
+	rejected := records[1]
+	if rejected["status"] != float64(http.StatusMethodNotAllowed) || rejected["level"] != "WARN" {
+		t.Errorf("rejected record = %v", rejected)
+	}
+	if id, _ := rejected["request_id"].(string); len(id) != 36 {
+		t.Errorf("generated request_id = %q", id)
+	}
+}
+
+func TestHTTPTransport_AccessLogSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	tr := NewHTTPTransport(&mockHandler{}, HTTPConfig{
+		AccessLog: AccessLogConfig{Enabled: true, SampleRate: 1e-12},
+	}, logger, nil, nil)
+	h := tr.Handler()
+
+	for i := 0; i < 10; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, PathHealth, nil))
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, PathRPC, nil))
+
+	records := accessLogRecords(t, &buf)
+	if len(records) != 1 || records[0]["status"] != float64(http.StatusMethodNotAllowed) {
+		t.Errorf("records = %v, want only the failed request", records)
+	}
+}
+
+func TestHTTPTransport_AccessLogDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	tr := NewHTTPTransport(&mockHandler{}, HTTPConfig{}, slog.New(slog.NewJSONHandler(&buf, nil)), nil, nil)
+	w := httptest.NewRecorder()
+	tr.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathHealth, nil))
+
+	if len(accessLogRecords(t, &buf)) != 0 || w.Header().Get(HeaderRequestID) != "" {
+		t.Error("access log wrote records while disabled")
+	}
+}
+
+func TestResponseRecorder_Flush(t *testing.T) {
+	w := httptest.NewRecorder()
+	var rw http.ResponseWriter = &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		t.Fatal("responseRecorder does not implement http.Flusher")
+	}
+	rw.Write([]byte("data: x\n\n"))
+	flusher.Flush()
+	if !w.Flushed {
+		t.Error("Flush did not reach the underlying writer")
+	}
+}
+
+func TestHTTPTransport_RequestIDWithoutAccessLog(t *testing.T) {
+	var seen string
+	handler := handlerFunc(func(ctx context.Context, body []byte, transport string) ([]byte, error) {
+		seen, _ = observability.RequestIDFromContext(ctx)
+		return []byte(`{}`), nil
+	})
+	h := NewHTTPTransport(handler, HTTPConfig{MaxRequestSize: 1024}, nil, nil, nil).Handler()
+
+	req := httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(`{}`))
+	req.Header.Set(HeaderRequestID, "abc-123")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if seen != "abc-123" || w.Header().Get(HeaderRequestID) != "abc-123" {
+		t.Errorf("handler saw %q, response header %q; want the client's ID", seen, w.Header().Get(HeaderRequestID))
+	}
+
+	// Unusable client IDs are replaced
+	req = httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(`{}`))
+	req.Header.Set(HeaderRequestID, "line\nbreak")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if len(seen) != 36 || w.Header().Get(HeaderRequestID) != seen {
+		t.Errorf("handler saw %q, response header %q; want a generated ID", seen, w.Header().Get(HeaderRequestID))
+	}
+}
+
+type handlerFunc func(ctx context.Context, body []byte, transport string) ([]byte, error)
+
+func (f handlerFunc) Handle(ctx context.Context, body []byte, transport string) ([]byte, error) {
+	return f(ctx, body, transport)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// DefaultMaxRequestSize bounds request bodies when HTTPConfig sets no limit
+const DefaultMaxRequestSize = 10 * 1024 * 1024
+
+// maxRequestSize returns the request body limit
+func (c HTTPConfig) maxRequestSize() int64 {
+	if c.MaxRequestSize <= 0 {
+		return DefaultMaxRequestSize
+	}
+	return c.MaxRequestSize
+}
+
+// limitBody decodes gzip request bodies and bounds bodies, decoded, to
+// MaxRequestSize; reading past it fails with *http.MaxBytesError, which
+// handlers answer with 413 (see writeTooLarge)
+func (t *HTTPTransport) limitBody(next http.Handler) http.Handler {
+	limit := t.config.maxRequestSize()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			writeTooLarge(w, limit)
+			return
+		}
+		if r.Body != nil && r.Body != http.NoBody {
+			switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+			case "", "identity":
+			case "gzip", "x-gzip":
+				zr, err := gzip.NewReader(r.Body)
+				if err != nil {
+					http.Error(w, "Invalid gzip request body", http.StatusBadRequest)
+					return
+				}
+				r = r.Clone(r.Context())
+				r.Body = &gzipBody{Reader: zr, body: r.Body}
+				r.Header.Del("Content-Encoding")
+				r.ContentLength = -1
+			default:
+				http.Error(w, fmt.Sprintf("Unsupported Content-Encoding %q", encoding), http.StatusUnsupportedMediaType)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// gzipBody is a decoded request body
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// isTooLarge reports whether err is a body read past the limit
+func isTooLarge(err error) bool {
+	var maxBytes *http.MaxBytesError
+	return errors.As(err, &maxBytes)
+}
+
+// writeTooLarge answers a body over the limit with 413 and a JSON-RPC
+// error; its ID is unknown, since the request wasn't read
+func writeTooLarge(w http.ResponseWriter, limit int64) {
+	response, _ := json.Marshal(protocol.Response{
+		JSONRPC: "2.0",
+		Error:   protocol.NewInvalidRequest(fmt.Sprintf("request body exceeds %d bytes", limit)),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	w.Write(response)
+}
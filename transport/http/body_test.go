@@ -0,0 +1,104 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+)
+
+func gzipped(t *testing.T, s string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestHTTPTransport_BodyTooLarge(t *testing.T) {
+	executor := engine.NewExecutor(engine.DefaultExecutorConfig(), nil)
+	mb := &mockBackend{Tools: map[string]backend.ToolDefinition{"tool1": {Name: "tool1", Streaming: true}}}
+	mh := &mockHandler{HandleResult: []byte(`{}`)}
+	handler := NewHTTPTransport(mh, HTTPConfig{MaxRequestSize: 64}, nil, mb, executor).Handler()
+
+	large := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"padding":"` + strings.Repeat("x", 100) + `"}}`
+
+	tests := []struct {
+		name string
+		req  func() *http.Request
+	}{
+		{"rpc with length", func() *http.Request {
+			return httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(large))
+		}},
+		{"rpc chunked", func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(large))
+			r.ContentLength = -1
+			return r
+		}},
+		{"rpc gzip", func() *http.Request {
+			// Small compressed, too large decoded
+			r := httptest.NewRequest(http.MethodPost, PathRPC, gzipped(t, large))
+			r.Header.Set("Content-Encoding", "gzip")
+			return r
+		}},
+		{"stream", func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, PathStream+"?tool=tool1", strings.NewReader(large))
+			r.ContentLength = -1
+			return r
+		}},
+	}
+	for _, tt := range tests {
+		mh.ReceivedBody = nil
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, tt.req())
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: status = %d, want 413", tt.name, w.Code)
+			continue
+		}
+		var resp struct {
+			ID    any `json:"id"`
+			Error struct {
+				Code int `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Error.Code != -32600 || resp.ID != nil {
+			t.Errorf("%s: body = %s", tt.name, w.Body.String())
+		}
+		if mh.ReceivedBody != nil {
+			t.Errorf("%s: truncated body reached the handler", tt.name)
+		}
+	}
+}
+
+func TestHTTPTransport_GzipRequest(t *testing.T) {
+	mh := &mockHandler{HandleResult: []byte(`{}`)}
+	handler := NewHTTPTransport(mh, HTTPConfig{}, nil, nil, nil).Handler()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	req := httptest.NewRequest(http.MethodPost, PathRPC, gzipped(t, body))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || string(mh.ReceivedBody) != body {
+		t.Errorf("status %d, handler received %q", w.Code, mh.ReceivedBody)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(body))
+	req.Header.Set("Content-Encoding", "br")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("unsupported encoding: status = %d, want 415", w.Code)
+	}
+}
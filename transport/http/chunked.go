@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+)
+
+// ChunkedResults serves non-streaming tools on /stream, sending results
+// larger than Threshold as content_chunk events and a content_complete
+// marker instead of one huge event
+type ChunkedResults struct {
+	// Threshold is the serialized result size, in bytes, above which
+	// results are chunked (0 disables; non-streaming tools are then
+	// refused on /stream)
+	Threshold int
+
+	// ChunkSize is the size of each chunk (default engine.DefaultChunkSize)
+	ChunkSize int
+}
+
+// enabled reports whether non-streaming tools are served on /stream
+func (c ChunkedResults) enabled() bool {
+	return c.Threshold > 0
+}
+
+// Validate validates the settings
+func (c ChunkedResults) Validate() error {
+	if c.Threshold < 0 || c.ChunkSize < 0 {
+		return fmt.Errorf("chunk threshold and size must not be negative")
+	}
+	return nil
+}
+
+// SetChunkedResults serves non-streaming tools on /stream, chunking their
+// large results
+func (h *SSEHandler) SetChunkedResults(c ChunkedResults) {
+	h.chunking = c
+}
+
+// chunkedHandler runs a non-streaming tool as a stream: a result up to
+// the threshold is sent as one data event, a larger one in chunks
+func (h *SSEHandler) chunkedHandler(toolName string) engine.StreamingToolHandler {
+	return func(ctx context.Context, args map[string]interface{}, emit engine.Emitter) error {
+		result, err := h.backend.CallTool(ctx, toolName, args)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to serialize result: %w", err)
+		}
+		if len(data) <= h.chunking.Threshold {
+			return emit.EmitData(json.RawMessage(data))
+		}
+		h.logger.DebugContext(ctx, "chunking tool result",
+			"tool", toolName,
+			"size", len(data))
+		return engine.EmitChunked(emit, data, h.chunking.ChunkSize)
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+)
+
+// resultBackend's non-streaming tool returns a fixed result
+type resultBackend struct {
+	mockBackend
+	result interface{}
+}
+
+func (b *resultBackend) CallTool(ctx context.Context, name string, args map[string]interface{}) (interface{}, error) {
+	return b.result, nil
+}
+
+func TestSSEHandler_ChunkedResults(t *testing.T) {
+	executor := engine.NewExecutor(engine.DefaultExecutorConfig(), nil)
+	b := &resultBackend{
+		mockBackend: mockBackend{Tools: map[string]backend.ToolDefinition{"read": {Name: "read"}}},
+		result:      map[string]string{"content": strings.Repeat("x", 10000)},
+	}
+	h := NewSSEHandler(executor, b, nil, time.Second)
+
+	serve := func() string {
+		req := httptest.NewRequest(http.MethodPost, "/stream?tool=read", strings.NewReader("{}"))
+		req.Header.Set("Accept", ContentTypeNDJSON)
+		w := &flushingRecorder{ResponseRecorder: httptest.NewRecorder()}
+		h.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// Non-streaming tools are refused unless chunking is enabled
+	if body := serve(); !strings.Contains(body, "not_streaming") {
+		t.Fatalf("stream without chunking:\n%s", body)
+	}
+
+	h.SetChunkedResults(ChunkedResults{Threshold: 1024, ChunkSize: 4096})
+	var joined strings.Builder
+	var types []string
+	scanner := bufio.NewScanner(strings.NewReader(serve()))
+	for scanner.Scan() {
+		var line struct {
+			Event string          `json:"event"`
+			Data  json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		types = append(types, line.Event)
+		if line.Event == "content_chunk" {
+			var chunk engine.ContentChunkPayload
+			json.Unmarshal(line.Data, &chunk)
+			joined.WriteString(chunk.Data)
+		}
+	}
+	if got := strings.Join(types, ","); got != "start,content_chunk,content_chunk,content_chunk,content_complete,end" {
+		t.Errorf("events = %s", got)
+	}
+	var result map[string]string
+	if err := json.Unmarshal([]byte(joined.String()), &result); err != nil || len(result["content"]) != 10000 {
+		t.Errorf("assembled result: %v", err)
+	}
+
+	// Small results stay one data event
+	b.result = "small"
+	if body := serve(); !strings.Contains(body, `"event":"data"`) || strings.Contains(body, "content_chunk") {
+		t.Errorf("small result:\n%s", body)
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+)
+
+// HeaderClientID names the caller for per-client rate limiting; it is
+// only honored on requests from a trusted proxy
+const HeaderClientID = "X-Client-ID"
+
+// TrustedProxies are the addresses ("10.0.0.1") and CIDR ranges
+// ("10.0.0.0/8") of the proxies whose X-Client-ID headers are honored
+// Other callers could pick a fresh ID per request to get a fresh rate
+// limit, so theirs is ignored.
+type TrustedProxies []string
+
+// Validate checks every entry is an address or a CIDR range
+func (p TrustedProxies) Validate() error {
+	for _, proxy := range p {
+		if _, err := parseProxy(proxy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseProxy parses an address or CIDR range
+func parseProxy(proxy string) (netip.Prefix, error) {
+	proxy = strings.TrimSpace(proxy)
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), nil
+}
+
+// clientIdentifier identifies callers for per-client rate limiting
+type clientIdentifier struct {
+	trusted []netip.Prefix
+}
+
+// newClientIdentifier creates a client identifier trusting proxies;
+// invalid entries (see TrustedProxies.Validate) are skipped
+func newClientIdentifier(proxies TrustedProxies) clientIdentifier {
+	var c clientIdentifier
+	for _, proxy := range proxies {
+		if prefix, err := parseProxy(proxy); err == nil {
+			c.trusted = append(c.trusted, prefix)
+		}
+	}
+	return c
+}
+
+// id identifies the caller of r
+// Prefers the authenticated principal, then the X-Client-ID of a trusted
+// proxy, then the remote host. An API key names the caller only once
+// authentication has checked it (as its principal): an unchecked one, like
+// an untrusted X-Client-ID, could change on every request.
+func (c clientIdentifier) id(r *http.Request) string {
+	if p, ok := auth.PrincipalFromContext(r.Context()); ok && p.ID != "" {
+		return "principal:" + p.ID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if id := r.Header.Get(HeaderClientID); id != "" && c.trusts(host) {
+		return "client:" + id
+	}
+
+	return host
+}
+
+// trusts reports whether host is a trusted proxy
+func (c clientIdentifier) trusts(host string) bool {
+	if len(c.trusted) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range c.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
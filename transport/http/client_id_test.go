@@ -0,0 +1,68 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+)
+
+func TestClientIdentifier(t *testing.T) {
+	c := newClientIdentifier(TrustedProxies{"10.0.0.0/8", "192.0.2.7"})
+
+	request := func(remote, clientID, apiKey string) string {
+		r := httptest.NewRequest("POST", "/rpc", nil)
+		r.RemoteAddr = remote
+		if clientID != "" {
+			r.Header.Set(HeaderClientID, clientID)
+		}
+		if apiKey != "" {
+			r.Header.Set("X-API-Key", apiKey)
+		}
+		return c.id(r)
+	}
+
+	tests := []struct {
+		name     string
+		remote   string
+		clientID string
+		apiKey   string
+		want     string
+	}{
+		{"trusted range", "10.1.2.3:4000", "tenant-a", "", "client:tenant-a"},
+		{"trusted address", "192.0.2.7:4000", "tenant-a", "", "client:tenant-a"},
+		{"untrusted caller", "203.0.113.9:4000", "fresh-id", "", "203.0.113.9"},
+		{"trusted without header", "10.1.2.3:4000", "", "", "10.1.2.3"},
+		{"unauthenticated API key", "203.0.113.9:4000", "", "fresh-key", "203.0.113.9"},
+	}
+	for _, tt := range tests {
+		if got := request(tt.remote, tt.clientID, tt.apiKey); got != tt.want {
+			t.Errorf("%s: id = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Without trusted proxies the header is always ignored
+	r := httptest.NewRequest("POST", "/rpc", nil)
+	r.RemoteAddr = "10.1.2.3:4000"
+	r.Header.Set(HeaderClientID, "tenant-a")
+	if got := newClientIdentifier(nil).id(r); got != "10.1.2.3" {
+		t.Errorf("untrusted id = %q, want the remote host", got)
+	}
+
+	// The principal wins over everything
+	r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{ID: "alice"}))
+	if got := c.id(r); got != "principal:alice" {
+		t.Errorf("authenticated id = %q", got)
+	}
+}
+
+func TestTrustedProxies_Validate(t *testing.T) {
+	if err := (TrustedProxies{"10.0.0.0/8", "::1", "192.0.2.7"}).Validate(); err != nil {
+		t.Errorf("valid proxies: %v", err)
+	}
+	for _, proxy := range []string{"10.0.0.0/33", "proxy.internal", ""} {
+		if err := (TrustedProxies{proxy}).Validate(); err == nil {
+			t.Errorf("Validate(%q) = nil, want error", proxy)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"compress/gzip"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultCompressionMinSize is the smallest response compressed
+const DefaultCompressionMinSize = 1024
+
+// CompressionConfig configures gzip compression of responses to clients
+// that accept it; gzip request bodies are always accepted
+type CompressionConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Level is the gzip level, 1 (fastest) to 9 (smallest); 0 is the
+	// default level
+	Level int `yaml:"level"`
+
+	// MinSize is the smallest response compressed, in bytes (default
+	// DefaultCompressionMinSize)
+	MinSize int `yaml:"min_size"`
+}
+
+// Validate validates the configuration
+func (c CompressionConfig) Validate() error {
+	if c.Level < 0 || c.Level > gzip.BestCompression {
+		return fmt.Errorf("compression level must be between 1 and 9, got %d", c.Level)
+	}
+	if c.MinSize < 0 {
+		return fmt.Errorf("compression min size must not be negative")
+	}
+	return nil
+}
+
+func (c CompressionConfig) level() int {
+	if c.Level == 0 {
+		return gzip.DefaultCompression
+	}
+	return c.Level
+}
+
+func (c CompressionConfig) minSize() int {
+	if c.MinSize == 0 {
+		return DefaultCompressionMinSize
+	}
+	return c.MinSize
+}
+
+// compress gzips responses for clients that accept it
+// Event streams are never compressed: compression would hold events back.
+func (t *HTTPTransport) compress(next http.Handler) http.Handler {
+	config := t.config.Compression
+	if !config.Enabled {
+		return next
+	}
+	writers := &sync.Pool{New: func() any {
+		zw, _ := gzip.NewWriterLevel(nil, config.level())
+		return zw
+	}}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r) || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, writers: writers, minSize: config.minSize()}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptsGzip reports whether the client's Accept-Encoding allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept-Encoding") {
+		for _, part := range strings.Split(accept, ",") {
+			coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			if strings.EqualFold(coding, "gzip") || coding == "*" {
+				return strings.ReplaceAll(params, " ", "") != "q=0"
+			}
+		}
+	}
+	return false
+}
+
+// compressible reports whether a response of the content type is worth
+// compressing
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch mediaType {
+	case "text/event-stream", ContentTypeNDJSON:
+		return false
+	}
+	return mediaType == "application/json" || strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json")
+}
+
+// compressWriter gzips a response once it reaches the minimum size; until
+// then it buffers it, and smaller responses go out as they are
+type compressWriter struct {
+	http.ResponseWriter
+	writers *sync.Pool
+	minSize int
+
+	status  int
+	buf     []byte
+	decided bool
+	zw      *gzip.Writer
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	w.status = status
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		!w.eligible() {
+		w.passThrough()
+	}
+}
+
+// eligible reports whether the response, by its headers, may be compressed
+func (w *compressWriter) eligible() bool {
+	h := w.Header()
+	return h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type"))
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.status == 0 {
+			w.WriteHeader(http.StatusOK)
+		}
+		if !w.decided {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= w.minSize {
+				if err := w.startGzip(); err != nil {
+					return 0, err
+				}
+			}
+			return len(p), nil
+		}
+	}
+	if w.zw != nil {
+		return w.zw.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// passThrough sends the response uncompressed, with what was buffered
+func (w *compressWriter) passThrough() error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// startGzip compresses the rest of the response, starting with what was
+// buffered
+func (w *compressWriter) startGzip() error {
+	w.decided = true
+	h := w.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+
+	w.zw = w.writers.Get().(*gzip.Writer)
+	w.zw.Reset(w.ResponseWriter)
+	_, err := w.zw.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// Flush implements http.Flusher; a response flushed before reaching the
+// minimum size goes out uncompressed
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.passThrough()
+	}
+	if w.zw != nil {
+		w.zw.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// close finishes the response
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 && len(w.buf) == 0 {
+			return // nothing written: net/http sends the default response
+		}
+		w.passThrough()
+	}
+	if w.zw != nil {
+		w.zw.Close()
+		w.writers.Put(w.zw)
+		w.zw = nil
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
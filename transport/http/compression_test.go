@@ -0,0 +1,91 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                    false,
+		"gzip":                true,
+		"deflate, gzip;q=1.0": true,
+		"br, GZIP":            true,
+		"gzip;q=0":            false,
+		"*":                   true,
+		"identity":            false,
+	}
+	for accept, want := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if accept != "" {
+			r.Header.Set("Accept-Encoding", accept)
+		}
+		if got := acceptsGzip(r); got != want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
+
+func TestHTTPTransport_CompressResponse(t *testing.T) {
+	large := `{"jsonrpc":"2.0","id":1,"result":"` + strings.Repeat("x", 4096) + `"}`
+	mh := &mockHandler{}
+	config := HTTPConfig{Compression: CompressionConfig{Enabled: true}}
+	handler := NewHTTPTransport(mh, config, nil, nil, nil).Handler()
+
+	call := func(acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(`{}`))
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	mh.HandleResult = []byte(large)
+	w := call("gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("large response not compressed: %v", w.Header())
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != large {
+		t.Errorf("decompressed body differs (%d bytes)", len(body))
+	}
+	if !strings.Contains(strings.Join(w.Header().Values("Vary"), ","), "Accept-Encoding") {
+		t.Errorf("Vary = %v", w.Header().Values("Vary"))
+	}
+
+	if w := call(""); w.Header().Get("Content-Encoding") != "" || w.Body.String() != large {
+		t.Error("response compressed for a client without Accept-Encoding")
+	}
+
+	mh.HandleResult = []byte(`{"jsonrpc":"2.0","id":1,"result":"ok"}`)
+	if w := call("gzip"); w.Header().Get("Content-Encoding") != "" || w.Body.String() != string(mh.HandleResult) {
+		t.Errorf("small response: encoding %q, body %q", w.Header().Get("Content-Encoding"), w.Body.String())
+	}
+}
+
+func TestCompressWriter_EventStream(t *testing.T) {
+	tr := NewHTTPTransport(&mockHandler{}, HTTPConfig{Compression: CompressionConfig{Enabled: true, MinSize: 1}}, nil, nil, nil)
+	handler := tr.compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: data\ndata: {}\n\n"))
+		w.(http.Flusher).Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, PathStream, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Header().Get("Content-Encoding") != "" || !strings.HasPrefix(w.Body.String(), "event: data") {
+		t.Errorf("event stream compressed: %v %q", w.Header(), w.Body.String())
+	}
+}
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of CORSConfig
+var (
+	DefaultCORSMethods = []string{
+		http.MethodGet, http.MethodPost, http.MethodDelete, http.MethodOptions,
+	}
+	DefaultCORSHeaders = []string{
+		"Content-Type", "Authorization", "X-API-Key", "X-Timeout", "X-Record",
+		HeaderLastEventID, HeaderSessionID, HeaderRequestID,
+	}
+	DefaultCORSExposedHeaders = []string{HeaderSessionID, HeaderRequestID}
+)
+
+// CORSConfig is the cross-origin policy of every endpoint
+type CORSConfig struct {
+	// AllowedOrigins are the origins browsers may call from: "*" for
+	// any, exact origins ("https://app.example.com") or origins with one
+	// wildcard ("https://*.example.com"). Empty allows none.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+
+	// AllowedMethods may be used cross-origin (default DefaultCORSMethods)
+	AllowedMethods []string `yaml:"allowed_methods"`
+
+	// AllowedHeaders may be sent cross-origin (default DefaultCORSHeaders;
+	// "*" allows any)
+	AllowedHeaders []string `yaml:"allowed_headers"`
+
+	// ExposedHeaders may be read by scripts (default
+	// DefaultCORSExposedHeaders)
+	ExposedHeaders []string `yaml:"exposed_headers"`
+
+	// AllowCredentials lets browsers send cookies and client certificates;
+	// it cannot be combined with the "*" origin
+	AllowCredentials bool `yaml:"allow_credentials"`
+
+	// MaxAge is how long browsers may cache preflight results (0: the
+	// browser default)
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// Validate validates the configuration
+func (c CORSConfig) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.AllowCredentials {
+				return fmt.Errorf("cors: allow_credentials cannot be combined with the \"*\" origin")
+			}
+			continue
+		}
+		if strings.Count(origin, "*") > 1 {
+			return fmt.Errorf("cors: origin %q has more than one wildcard", origin)
+		}
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("cors: max_age must not be negative")
+	}
+	return nil
+}
+
+// corsPolicy applies a CORSConfig to requests
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	patterns    [][2]string // prefix and suffix around the wildcard
+	methods     []string
+	headers     map[string]bool
+	anyHeader   bool
+	credentials bool
+
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// newCORSPolicy compiles the configuration, filling in defaults
+func newCORSPolicy(c CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		origins:     make(map[string]bool),
+		headers:     make(map[string]bool),
+		methods:     orDefault(c.AllowedMethods, DefaultCORSMethods),
+		credentials: c.AllowCredentials,
+	}
+	for _, origin := range c.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			p.patterns = append(p.patterns, [2]string{prefix, suffix})
+		default:
+			p.origins[origin] = true
+		}
+	}
+	headers := orDefault(c.AllowedHeaders, DefaultCORSHeaders)
+	for _, header := range headers {
+		if header == "*" {
+			p.anyHeader = true
+		}
+		p.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	p.allowMethods = strings.Join(p.methods, ", ")
+	p.allowHeaders = strings.Join(headers, ", ")
+	p.exposeHeaders = strings.Join(orDefault(c.ExposedHeaders, DefaultCORSExposedHeaders), ", ")
+	if c.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(c.MaxAge / time.Second))
+	}
+	return p
+}
+
+// orDefault returns values, or defaults if there are none
+func orDefault(values, defaults []string) []string {
+	if len(values) == 0 {
+		return defaults
+	}
+	return values
+}
+
+// allowOrigin reports whether origin may make cross-origin requests
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if len(origin) > len(pattern[0])+len(pattern[1]) &&
+			strings.HasPrefix(origin, pattern[0]) && strings.HasSuffix(origin, pattern[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowRequestHeaders reports whether a preflight's requested headers are allowed
+func (p *corsPolicy) allowRequestHeaders(requested string) bool {
+	if p.anyHeader {
+		return true
+	}
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !p.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
+
+// setOrigin answers an allowed origin
+func (p *corsPolicy) setOrigin(h http.Header, origin string) {
+	if p.anyOrigin && !p.credentials {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// handle sets the CORS headers of a request and reports whether it was a
+// preflight, which is answered in full
+func (p *corsPolicy) handle(w http.ResponseWriter, r *http.Request) bool {
+	h := w.Header()
+	if !p.anyOrigin || p.credentials {
+		h.Add("Vary", "Origin")
+	}
+	origin := r.Header.Get("Origin")
+
+	if r.Method == http.MethodOptions {
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		method := r.Header.Get("Access-Control-Request-Method")
+		requested := r.Header.Get("Access-Control-Request-Headers")
+		if origin != "" && p.allowOrigin(origin) &&
+			(method == "" || slices.Contains(p.methods, method)) &&
+			p.allowRequestHeaders(requested) {
+			p.setOrigin(h, origin)
+			h.Set("Access-Control-Allow-Methods", p.allowMethods)
+			if p.anyHeader && requested != "" {
+				h.Set("Access-Control-Allow-Headers", requested)
+			} else {
+				h.Set("Access-Control-Allow-Headers", p.allowHeaders)
+			}
+			if p.maxAge != "" {
+				h.Set("Access-Control-Max-Age", p.maxAge)
+			}
+		}
+		// Disallowed preflights get no CORS headers, so browsers refuse them
+		w.WriteHeader(http.StatusOK)
+		return true
+	}
+
+	if origin != "" && p.allowOrigin(origin) {
+		p.setOrigin(h, origin)
+		h.Set("Access-Control-Expose-Headers", p.exposeHeaders)
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+)
+
+func TestCORSPolicy_Origins(t *testing.T) {
+	p := newCORSPolicy(CORSConfig{AllowedOrigins: []string{
+		"https://app.example.com",
+		"https://*.example.org",
+	}})
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"HTTPS://APP.EXAMPLE.COM", true},
+		{"https://evil.example.com", false},
+		{"https://a.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://.example.org", false},
+		{"https://example.org", false},
+		{"http://a.example.org", false},
+	}
+	for _, tt := range tests {
+		if got := p.allowOrigin(tt.origin); got != tt.want {
+			t.Errorf("allowOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestCORSPolicy_Preflight(t *testing.T) {
+	p := newCORSPolicy(CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{http.MethodPost},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	preflight := func(origin, method, headers string) http.Header {
+		r := httptest.NewRequest(http.MethodOptions, PathRPC, nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			r.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		w := httptest.NewRecorder()
+		if !p.handle(w, r) {
+			t.Fatal("preflight not answered")
+		}
+		return w.Header()
+	}
+
+	h := preflight("https://app.example.com", http.MethodPost, "content-type, x-api-key")
+	if h.Get("Access-Control-Allow-Origin") != "https://app.example.com" ||
+		h.Get("Access-Control-Allow-Credentials") != "true" ||
+		h.Get("Access-Control-Allow-Methods") != "POST" ||
+		h.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("allowed preflight headers = %v", h)
+	}
+
+	for _, h := range []http.Header{
+		preflight("https://evil.example.com", http.MethodPost, ""),
+		preflight("https://app.example.com", http.MethodDelete, ""),
+		preflight("https://app.example.com", http.MethodPost, "X-Unknown"),
+	} {
+		if h.Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("refused preflight headers = %v", h)
+		}
+	}
+}
+
+func TestCORSConfig_Validate(t *testing.T) {
+	if err := (CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}).Validate(); err == nil {
+		t.Error("credentials with any origin accepted")
+	}
+	if err := (CORSConfig{AllowedOrigins: []string{"https://*.*.example.com"}}).Validate(); err == nil {
+		t.Error("origin with two wildcards accepted")
+	}
+	if err := (CORSConfig{AllowedOrigins: []string{"*", "https://*.example.com"}}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHTTPTransport_CORSEndpoints(t *testing.T) {
+	executor := engine.NewExecutor(engine.DefaultExecutorConfig(), nil)
+	mb := &mockBackend{Tools: map[string]backend.ToolDefinition{"tool1": {Name: "tool1", Streaming: true}}}
+	config := HTTPConfig{
+		MaxRequestSize: 1024,
+		CORS:           CORSConfig{AllowedOrigins: []string{"https://app.example.com"}},
+	}
+	handler := NewHTTPTransport(&mockHandler{HandleResult: []byte(`{}`)}, config, nil, mb, executor).Handler()
+
+	for _, path := range []string{PathRPC, PathStream + "?tool=tool1", PathHealth} {
+		method := http.MethodPost
+		if path == PathHealth {
+			method = http.MethodGet
+		}
+		for origin, want := range map[string]string{
+			"https://app.example.com":  "https://app.example.com",
+			"https://evil.example.com": "",
+		} {
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set("Origin", origin)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != want {
+				t.Errorf("%s from %s: Access-Control-Allow-Origin = %q, want %q", path, origin, got, want)
+			}
+		}
+	}
+}
@@ -2,14 +2,25 @@ package http
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/SaherElMasry/go-mcp-framework/audit"
+	"github.com/SaherElMasry/go-mcp-framework/auth"
 	"github.com/SaherElMasry/go-mcp-framework/backend"
 	"github.com/SaherElMasry/go-mcp-framework/engine"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
+	"github.com/SaherElMasry/go-mcp-framework/session"
 	"github.com/SaherElMasry/go-mcp-framework/transport"
 )
 
@@ -19,7 +30,37 @@ type HTTPConfig struct {
 	ReadTimeout    time.Duration
 	WriteTimeout   time.Duration
 	MaxRequestSize int64
+
+	// AllowedOrigins is shorthand for CORS.AllowedOrigins, used when
+	// CORS sets none
 	AllowedOrigins []string
+
+	// CORS is the cross-origin policy of every endpoint
+	CORS CORSConfig
+
+	// Compression gzips responses to clients that accept it
+	Compression CompressionConfig
+
+	// AccessLog logs each request's method, path, status, size and duration
+	AccessLog AccessLogConfig
+
+	// EventLimit bounds the size of /stream events
+	EventLimit EventLimit
+
+	// Heartbeat is the interval of comments on idle /stream responses
+	// (0: DefaultHeartbeat, negative: none)
+	Heartbeat time.Duration
+
+	// ChunkedResults serves non-streaming tools on /stream, chunking
+	// large results
+	ChunkedResults ChunkedResults
+
+	// TLS serves HTTPS (and HTTP/2) instead of plain HTTP
+	TLS TLSConfig
+
+	// TrustedProxies are the proxies whose X-Client-ID headers name the
+	// caller for per-client rate limiting
+	TrustedProxies TrustedProxies
 }
 
 // HTTPTransport implements HTTP-based transport
@@ -30,6 +71,27 @@ type HTTPTransport struct {
 	server   *http.Server
 	backend  backend.ServerBackend // NEW: For SSE streaming
 	executor *engine.Executor      // NEW: For streaming execution
+	limiter  *ratelimit.Limiter
+	authn    auth.Authenticator
+	access   *auth.AccessPolicy
+	audit    *audit.Logger
+	listener net.Listener
+	onListen func()
+	cors     *corsPolicy
+	health   *health.Registry
+
+	// clients identifies callers for per-client rate limiting
+	clients clientIdentifier
+
+	broadcaster *transport.Broadcaster
+	sessions    *sessionRegistry
+
+	// sessionManager issues MCP sessions, if set (see SetSessions)
+	sessionManager *session.Manager
+
+	// admin is served under PathAdmin to principals with adminScope
+	admin      http.Handler
+	adminScope string
 }
 
 // NewHTTPTransport creates a new HTTP transport
@@ -44,39 +106,235 @@ func NewHTTPTransport(
 		logger = slog.Default()
 	}
 
+	cors := config.CORS
+	if len(cors.AllowedOrigins) == 0 {
+		cors.AllowedOrigins = config.AllowedOrigins
+	}
+
 	return &HTTPTransport{
 		handler:  handler,
 		config:   config,
 		logger:   logger,
 		backend:  backend,
 		executor: executor,
+		cors:     newCORSPolicy(cors),
+		sessions: newSessionRegistry(),
+		clients:  newClientIdentifier(config.TrustedProxies),
 	}
 }
 
-// Run starts the HTTP server
-func (t *HTTPTransport) Run(ctx context.Context) error {
+// SetRateLimiter configures rate limiting for the streaming endpoint
+// (JSON-RPC calls are limited by the protocol handler)
+func (t *HTTPTransport) SetRateLimiter(limiter *ratelimit.Limiter) {
+	t.limiter = limiter
+}
+
+// SetAuthenticator requires every /rpc and /stream request to authenticate
+// The authenticated principal is stored in the request context (see auth.PrincipalFromContext)
+func (t *HTTPTransport) SetAuthenticator(authn auth.Authenticator) {
+	t.authn = authn
+}
+
+// SetAccessPolicy configures per-tool access control for the streaming endpoint
+// (JSON-RPC calls are checked by the protocol handler)
+func (t *HTTPTransport) SetAccessPolicy(policy *auth.AccessPolicy) {
+	t.access = policy
+}
+
+// SetAuditLogger records every streamed tool call to an audit log
+// (JSON-RPC calls are recorded by the protocol handler)
+func (t *HTTPTransport) SetAuditLogger(l *audit.Logger) {
+	t.audit = l
+}
+
+// SetBroadcaster serves server-initiated notifications to clients holding
+// a GET /rpc event stream open
+func (t *HTTPTransport) SetBroadcaster(b *transport.Broadcaster) {
+	t.broadcaster = b
+}
+
+// SetHealth serves the registry's probes on /health/live and /health/ready
+func (t *HTTPTransport) SetHealth(registry *health.Registry) {
+	t.health = registry
+}
+
+// SetAdmin serves an admin API under PathAdmin to authenticated
+// principals granted scope
+// It is only mounted when an authenticator is set.
+func (t *HTTPTransport) SetAdmin(handler http.Handler, scope string) {
+	t.admin = handler
+	t.adminScope = scope
+}
+
+// SetListener serves on an existing listener instead of binding config.Address
+// Used for socket activation, where the service manager owns the socket
+func (t *HTTPTransport) SetListener(l net.Listener) {
+	t.listener = l
+}
+
+// OnListen registers a callback invoked once the transport accepts connections
+func (t *HTTPTransport) OnListen(fn func()) {
+	t.onListen = fn
+}
+
+// Endpoint paths served by the HTTP transport
+const (
+	PathRPC    = "/rpc"
+	PathStream = "/stream"
+	PathHealth = "/health"
+	PathAdmin  = "/admin/"
+
+	// PathStreamNDJSON serves /stream as newline-delimited JSON, for
+	// clients that can't send Accept: application/x-ndjson
+	PathStreamNDJSON = "/stream-ndjson"
+
+	// Probe endpoints (see the health package)
+	PathHealthLive  = health.PathLive
+	PathHealthReady = health.PathReady
+)
+
+// Route describes an endpoint served by the transport
+// Used by router adapters to mount MCP endpoints in other web frameworks
+type Route struct {
+	Method string
+	Path   string
+
+	// Prefix marks a subtree: the route serves Path, which ends in a
+	// slash, and every path below it (e.g. /admin/tools/{name})
+	Prefix bool
+}
+
+// Pattern returns the route's path for a router whose subtree routes end
+// in wildcard, e.g. "*path" for gin or "*" for echo and fiber
+func (r Route) Pattern(wildcard string) string {
+	if r.Prefix {
+		return r.Path + wildcard
+	}
+	return r.Path
+}
+
+// Routes returns the endpoints served by Handler
+func (t *HTTPTransport) Routes() []Route {
+	routes := []Route{
+		{Method: http.MethodPost, Path: PathRPC},
+	}
+	if t.broadcaster != nil {
+		routes = append(routes, Route{Method: http.MethodGet, Path: PathRPC})
+	}
+	if t.sessionManager != nil {
+		routes = append(routes, Route{Method: http.MethodDelete, Path: PathRPC})
+	}
+	if t.executor != nil {
+		routes = append(routes,
+			Route{Method: http.MethodPost, Path: PathStream},
+			Route{Method: http.MethodPost, Path: PathStreamNDJSON},
+		)
+	}
+	if t.recordings() != nil {
+		routes = append(routes,
+			Route{Method: http.MethodGet, Path: PathRecordings},
+			Route{Method: http.MethodGet, Path: PathRecordings + "/", Prefix: true},
+		)
+	}
+	if t.admin != nil && t.authn != nil {
+		routes = append(routes,
+			Route{Method: http.MethodGet, Path: PathAdmin, Prefix: true},
+			Route{Method: http.MethodPost, Path: PathAdmin, Prefix: true},
+			Route{Method: http.MethodDelete, Path: PathAdmin, Prefix: true},
+		)
+	}
+	routes = append(routes,
+		Route{Method: http.MethodGet, Path: PathHealth},
+		Route{Method: http.MethodGet, Path: PathHealthLive},
+		Route{Method: http.MethodGet, Path: PathHealthReady},
+	)
+	return routes
+}
+
+// Handler returns the transport's endpoints as an http.Handler
+// This allows MCP to be embedded into an existing HTTP server instead of calling Run
+func (t *HTTPTransport) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	// Regular JSON-RPC endpoint
-	mux.HandleFunc("/rpc", t.handleRPC)
+	mux.Handle(PathRPC, observability.TraceHandler("transport.receive", t.requireAuth(http.HandlerFunc(t.handleRPC))))
 
 	// NEW: SSE streaming endpoint
 	if t.executor != nil {
 		sseHandler := NewSSEHandler(t.executor, t.backend, t.logger, 5*time.Minute)
-		mux.Handle("/stream", sseHandler)
-		t.logger.Info("SSE streaming endpoint enabled", "path", "/stream")
+		sseHandler.SetRateLimiter(t.limiter)
+		sseHandler.SetAccessPolicy(t.access)
+		sseHandler.SetAuditLogger(t.audit)
+		sseHandler.SetEventLimit(t.config.EventLimit)
+		if t.config.Heartbeat != 0 {
+			sseHandler.SetHeartbeat(t.config.Heartbeat)
+		}
+		sseHandler.SetChunkedResults(t.config.ChunkedResults)
+		sseHandler.sessions = t.sessions
+		sseHandler.clients = t.clients
+		mux.Handle(PathStream, observability.TraceHandler("transport.receive", t.requireAuth(t.withOptionalSession(sseHandler))))
+		mux.Handle(PathStreamNDJSON, observability.TraceHandler("transport.receive", t.requireAuth(t.withOptionalSession(ndjsonOnly(sseHandler)))))
+		t.logger.Info("SSE streaming endpoint enabled", "path", PathStream)
 	}
 
-	// Health check endpoint
-	mux.HandleFunc("/health", t.handleHealth)
+	// Developer endpoint replaying recorded event streams
+	if store := t.recordings(); store != nil {
+		recordings := t.requireAuth(NewRecordingsHandler(store))
+		mux.Handle(PathRecordings, recordings)
+		mux.Handle(PathRecordings+"/", recordings)
+		t.logger.Info("event recordings endpoint enabled", "path", PathRecordings)
+	}
+
+	// Admin API, never without authentication
+	if t.admin != nil {
+		if t.authn == nil {
+			t.logger.Warn("admin API not served: it requires inbound authentication")
+		} else {
+			mux.Handle(PathAdmin, t.requireAuth(requireScope(t.adminScope, t.admin)))
+			t.logger.Info("admin API enabled", "path", PathAdmin, "scope", t.adminScope)
+		}
+	}
+
+	// Health check endpoints
+	mux.HandleFunc(PathHealth, t.handleHealth)
+	registry := t.health
+	if registry == nil {
+		registry = health.NewRegistry()
+	}
+	registry.Mount(mux)
 
+	return t.accessLog(t.applyCORS(t.compress(t.limitBody(mux))))
+}
+
+// recordings returns the executor's recording store, if it records
+func (t *HTTPTransport) recordings() engine.RecordingStore {
+	if t.executor == nil {
+		return nil
+	}
+	if r := t.executor.Recorder(); r != nil {
+		return r.Store()
+	}
+	return nil
+}
+
+// Run starts the HTTP server
+func (t *HTTPTransport) Run(ctx context.Context) error {
 	t.server = &http.Server{
 		Addr:         t.config.Address,
-		Handler:      t.applyCORS(mux),
+		Handler:      t.Handler(),
 		ReadTimeout:  t.config.ReadTimeout,
 		WriteTimeout: t.config.WriteTimeout,
 	}
 
+	var serverTLS *serverTLS
+	if t.config.TLS.Enabled {
+		var err error
+		if serverTLS, err = newServerTLS(t.config.TLS, t.logger); err != nil {
+			return err
+		}
+		t.server.TLSConfig = serverTLS.config
+	}
+
 	// Graceful shutdown
 	go func() {
 		<-ctx.Done()
@@ -87,10 +345,38 @@ func (t *HTTPTransport) Run(ctx context.Context) error {
 		}
 	}()
 
-	t.logger.Info("http transport started", "address", t.config.Address)
+	ln := t.listener
+	if ln == nil {
+		var err error
+		ln, err = net.Listen("tcp", t.config.Address)
+		if err != nil {
+			return fmt.Errorf("http listen error: %w", err)
+		}
+	}
 
-	if err := t.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("http server error: %w", err)
+	t.logger.Info("http transport started",
+		"address", ln.Addr().String(),
+		"tls", serverTLS != nil)
+
+	if t.onListen != nil {
+		t.onListen()
+	}
+
+	if serverTLS == nil {
+		err := t.server.Serve(ln)
+		if err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("http server error: %w", err)
+		}
+		return nil
+	}
+
+	if serverTLS.acme != nil && t.config.TLS.ACME.HTTPAddress != "" {
+		go serverTLS.serveChallenges(ctx, t.config.TLS.ACME.HTTPAddress, t.logger)
+	}
+	// The certificate comes from TLSConfig.GetCertificate; ServeTLS
+	// negotiates HTTP/2 over ALPN
+	if err := t.server.ServeTLS(ln, "", ""); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("https server error: %w", err)
 	}
 
 	return nil
@@ -98,62 +384,290 @@ func (t *HTTPTransport) Run(ctx context.Context) error {
 
 // handleRPC handles regular JSON-RPC requests
 func (t *HTTPTransport) handleRPC(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && t.broadcaster != nil && acceptsEventStream(r) {
+		if t.sessionManager != nil {
+			var ok bool
+			if r, ok = t.requireSession(w, r); !ok {
+				return
+			}
+		}
+		t.handleNotifications(w, r)
+		return
+	}
+	if r.Method == http.MethodDelete && t.sessionManager != nil {
+		t.handleDeleteSession(w, r)
+		return
+	}
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	r, _ = withRequestID(w, r)
 
-	// Read request body
-	body, err := io.ReadAll(io.LimitReader(r.Body, t.config.MaxRequestSize))
+	// Read request body, bounded by limitBody
+	body, err := io.ReadAll(r.Body)
+	if isTooLarge(err) {
+		t.logger.WarnContext(r.Context(), "request body too large", "max_size", t.config.maxRequestSize())
+		writeTooLarge(w, t.config.maxRequestSize())
+		return
+	}
 	if err != nil {
-		t.logger.Error("read error", "error", err)
+		t.logger.ErrorContext(r.Context(), "read error", "error", err)
 		http.Error(w, "Failed to read request", http.StatusBadRequest)
 		return
 	}
 	defer r.Body.Close()
 
+	r, ok := t.rpcSession(w, r, body)
+	if !ok {
+		return
+	}
+
 	// Handle request
-	resp, err := t.handler.Handle(r.Context(), body, "http")
+	ctx, err := withTimeoutHeader(ratelimit.WithClientID(r.Context(), t.clients.id(r)), r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Clients accepting event streams get notifications (e.g. progress)
+	// before the response, as in MCP Streamable HTTP
+	var stream *rpcEventStream
+	if acceptsEventStream(r) {
+		if flusher, ok := w.(http.Flusher); ok {
+			stream = &rpcEventStream{w: w, flusher: flusher}
+			ctx = transport.WithNotifier(ctx, stream)
+		}
+	}
+
+	resp, err := t.handler.Handle(ctx, body, "http")
 	if err != nil {
-		t.logger.Error("handler error", "error", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		t.logger.ErrorContext(ctx, "handler error", "error", err)
+		if stream == nil || !stream.started() {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if stream != nil && stream.started() {
+		if err := stream.Notify(resp); err != nil {
+			t.logger.ErrorContext(ctx, "write error", "error", err)
+		}
 		return
 	}
 
 	// Write response
 	w.Header().Set("Content-Type", "application/json")
 	if _, err := w.Write(resp); err != nil {
-		t.logger.Error("write error", "error", err)
+		t.logger.ErrorContext(ctx, "write error", "error", err)
+	}
+}
+
+// notificationPing is the keep-alive interval of notification streams
+const notificationPing = 30 * time.Second
+
+// handleNotifications serves GET /rpc: an event stream of server-initiated
+// notifications (e.g. notifications/tools/list_changed), as in MCP
+// Streamable HTTP, open until the client disconnects
+func (t *HTTPTransport) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	stream := &rpcEventStream{w: w, flusher: flusher}
+	stream.mu.Lock()
+	stream.start()
+	stream.mu.Unlock()
+
+	sessionID := ""
+	if s, ok := session.FromContext(r.Context()); ok {
+		sessionID = s.ID()
+	}
+	unsubscribe := t.broadcaster.SubscribeSession(sessionID, stream)
+	defer func() {
+		unsubscribe()
+		// A broadcast in flight must not write after the handler returns
+		stream.mu.Lock()
+		stream.closed = true
+		stream.mu.Unlock()
+	}()
+
+	t.logger.Debug("notification stream opened", "remote_addr", r.RemoteAddr)
+
+	// The stream ends with the client's session, if it has one
+	var sessionDone <-chan struct{}
+	if s, ok := session.FromContext(r.Context()); ok {
+		sessionDone = s.Done()
+	}
+
+	ticker := time.NewTicker(notificationPing)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			t.logger.Debug("notification stream closed", "remote_addr", r.RemoteAddr)
+			return
+		case <-sessionDone:
+			t.logger.Debug("notification stream closed: session ended", "remote_addr", r.RemoteAddr)
+			return
+		case <-ticker.C:
+			if err := stream.ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// withTimeoutHeader applies a client-requested X-Timeout to the call context
+func withTimeoutHeader(ctx context.Context, r *http.Request) (context.Context, error) {
+	value := r.Header.Get("X-Timeout")
+	if value == "" {
+		return ctx, nil
+	}
+	timeout, err := backend.ParseTimeout(value)
+	if err != nil {
+		return ctx, fmt.Errorf("X-Timeout: %w", err)
+	}
+	return backend.WithRequestTimeout(ctx, timeout), nil
+}
+
+// acceptsEventStream reports whether the client accepts text/event-stream responses
+func acceptsEventStream(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		if strings.Contains(accept, "text/event-stream") {
+			return true
+		}
 	}
+	return false
+}
+
+// errStreamClosed is returned by notifications to a disconnected client
+var errStreamClosed = errors.New("event stream closed")
+
+// rpcEventStream upgrades a /rpc response to an event stream on the first notification
+// Requests that send no notifications still get a plain JSON response.
+type rpcEventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+
+	mu     sync.Mutex
+	open   bool
+	closed bool
+}
+
+// Notify writes message as an SSE "message" event
+func (s *rpcEventStream) Notify(message []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errStreamClosed
+	}
+	s.start()
+	if _, err := fmt.Fprintf(s.w, "event: message\ndata: %s\n\n", message); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// start opens the event stream; s.mu must be held
+func (s *rpcEventStream) start() {
+	if s.open {
+		return
+	}
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.WriteHeader(http.StatusOK)
+	s.flusher.Flush()
+	s.open = true
+}
+
+// ping writes an SSE comment, keeping idle connections open through proxies
+func (s *rpcEventStream) ping() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// started reports whether the event stream has been opened
+func (s *rpcEventStream) started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.open
+}
+
+// requireAuth authenticates requests before passing them to next
+// Requests without valid credentials are rejected with 401
+func (t *HTTPTransport) requireAuth(next http.Handler) http.Handler {
+	if t.authn == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := t.authn.Authenticate(r)
+		if err != nil {
+			reason := "invalid"
+			message := "invalid credentials"
+			if errors.Is(err, auth.ErrNoCredentials) {
+				reason = "missing"
+				message = "authentication required"
+			}
+			observability.RecordAuthFailure(reason, "http")
+			t.logger.Warn("authentication failed",
+				"path", r.URL.Path,
+				"remote_addr", r.RemoteAddr,
+				"error", err)
+			writeUnauthorized(w, message)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// requireScope answers 403 to principals without scope
+func requireScope(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := auth.PrincipalFromContext(r.Context())
+		if !principal.HasScope(scope) {
+			observability.RecordAuthFailure("forbidden", "http")
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeUnauthorized writes a 401 response with a bearer challenge
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized", "message": message})
 }
 
 // handleHealth handles health check requests
+// It only reports that the process is serving; /health/live and
+// /health/ready run the registered checks.
 func (t *HTTPTransport) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(`{"status":"ok"}`))
 }
 
-// applyCORS applies CORS headers
+// applyCORS applies the CORS policy, answering preflight requests
 func (t *HTTPTransport) applyCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Handle preflight requests
-		if r.Method == http.MethodOptions {
-			t.setCORSHeaders(w)
-			w.WriteHeader(http.StatusOK)
+		if t.cors.handle(w, r) {
 			return
 		}
-
-		t.setCORSHeaders(w)
 		next.ServeHTTP(w, r)
 	})
 }
-
-// setCORSHeaders sets CORS headers
-func (t *HTTPTransport) setCORSHeaders(w http.ResponseWriter) {
-	if len(t.config.AllowedOrigins) > 0 {
-		w.Header().Set("Access-Control-Allow-Origin", t.config.AllowedOrigins[0])
-	}
-	w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-}
@@ -1,12 +1,20 @@
 package http
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/health"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
 )
 
 // mockHandler implements transport.Handler for testing
@@ -143,20 +151,13 @@ func TestHTTPTransport_CORS(t *testing.T) {
 	}
 	tr := NewHTTPTransport(&mockHandler{}, config, nil, nil, nil)
 
-	t.Run("setCORSHeaders", func(t *testing.T) {
-		w := httptest.NewRecorder()
-		tr.setCORSHeaders(w)
-
-		if w.Header().Get("Access-Control-Allow-Origin") != "http://example.com" {
-			t.Errorf("Expected CORS origin header, got %s", w.Header().Get("Access-Control-Allow-Origin"))
-		}
-	})
-
 	t.Run("applyCORS_Preflight", func(t *testing.T) {
 		mux := http.NewServeMux()
 		handler := tr.applyCORS(mux)
 
 		req := httptest.NewRequest(http.MethodOptions, "/rpc", nil)
+		req.Header.Set("Origin", "http://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
 		w := httptest.NewRecorder()
 
 		handler.ServeHTTP(w, req)
@@ -177,6 +178,7 @@ func TestHTTPTransport_CORS(t *testing.T) {
 		handler := tr.applyCORS(mux)
 
 		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Origin", "http://example.com")
 		w := httptest.NewRecorder()
 
 		handler.ServeHTTP(w, req)
@@ -189,3 +191,165 @@ func TestHTTPTransport_CORS(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTPTransport_RequireAuth(t *testing.T) {
+	handler := &mockHandler{HandleResult: []byte(`{"jsonrpc":"2.0","result":"ok","id":1}`)}
+	tr := NewHTTPTransport(handler, HTTPConfig{MaxRequestSize: 1024}, nil, nil, nil)
+
+	authn, err := auth.NewInboundAuthenticator(auth.InboundConfig{
+		Enabled: true,
+		APIKeys: auth.InboundAPIKeyConfig{Keys: []auth.InboundCredential{{ID: "ci", Secret: "secret"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewInboundAuthenticator() error = %v", err)
+	}
+	tr.SetAuthenticator(authn)
+	h := tr.Handler()
+
+	tests := []struct {
+		name     string
+		key      string
+		wantCode int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"invalid", "wrong", http.StatusUnauthorized},
+		{"valid", "secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, PathRPC, bytes.NewBufferString(`{}`))
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate challenge")
+			}
+		})
+	}
+
+	// Health checks stay open
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathHealth, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("health status = %d, want 200", w.Code)
+	}
+}
+
+// notifyingHandler sends a notification before responding
+type notifyingHandler struct{}
+
+func (notifyingHandler) Handle(ctx context.Context, requestBytes []byte, transportType string) ([]byte, error) {
+	if n, ok := transport.NotifierFromContext(ctx); ok {
+		n.Notify([]byte(`{"jsonrpc":"2.0","method":"notifications/progress"}`))
+	}
+	return []byte(`{"jsonrpc":"2.0","result":"ok","id":1}`), nil
+}
+
+func TestHTTPTransport_handleRPC_EventStream(t *testing.T) {
+	tr := NewHTTPTransport(notifyingHandler{}, HTTPConfig{MaxRequestSize: 1024}, nil, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewBufferString(`{}`))
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	w := httptest.NewRecorder()
+	tr.handleRPC(w, req)
+
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+	want := "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n" +
+		"event: message\ndata: {\"jsonrpc\":\"2.0\",\"result\":\"ok\",\"id\":1}\n\n"
+	if w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+
+	// Without the Accept header, notifications are dropped and the response is JSON
+	req = httptest.NewRequest(http.MethodPost, "/rpc", bytes.NewBufferString(`{}`))
+	w = httptest.NewRecorder()
+	tr.handleRPC(w, req)
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+}
+
+func TestHTTPTransport_HealthProbes(t *testing.T) {
+	tr := NewHTTPTransport(&mockHandler{}, HTTPConfig{}, nil, nil, nil)
+	registry := health.NewRegistry()
+	registry.RegisterReadiness("upstream", func(ctx context.Context) error {
+		return errors.New("unreachable")
+	})
+	tr.SetHealth(registry)
+	h := tr.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathHealthLive, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("live status = %d, want 200", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathHealthReady, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("ready status = %d, want 503", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "unreachable") {
+		t.Errorf("ready body = %s", w.Body.String())
+	}
+}
+
+func TestHTTPTransport_NotificationStream(t *testing.T) {
+	broadcaster := transport.NewBroadcaster()
+	tr := NewHTTPTransport(&mockHandler{}, HTTPConfig{}, nil, nil, nil)
+	tr.SetBroadcaster(broadcaster)
+	server := httptest.NewServer(tr.Handler())
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+PathRPC, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Content-Type = %s", resp.Header.Get("Content-Type"))
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for broadcaster.Subscribers() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	message := `{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`
+	if n := broadcaster.Broadcast([]byte(message)); n != 1 {
+		t.Fatalf("delivered to %d clients, want 1", n)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	if lines[0] != "event: message" || lines[1] != "data: "+message {
+		t.Errorf("stream = %q", lines)
+	}
+
+	// Without a broadcaster, GET is not allowed
+	plain := NewHTTPTransport(&mockHandler{}, HTTPConfig{}, nil, nil, nil)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, PathRPC, nil)
+	r.Header.Set("Accept", "text/event-stream")
+	plain.Handler().ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", w.Code)
+	}
+}
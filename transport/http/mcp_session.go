@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+)
+
+// HeaderSessionID carries the MCP session ID, as in Streamable HTTP: the
+// server sets it on the response to initialize, clients send it on every
+// later request
+const HeaderSessionID = "Mcp-Session-Id"
+
+// SetSessions issues sessions to clients that initialize over /rpc
+// Later /rpc requests must present the session ID; DELETE /rpc ends the
+// session. Handlers find it with session.FromContext.
+func (t *HTTPTransport) SetSessions(m *session.Manager) {
+	t.sessionManager = m
+}
+
+// rpcSession attaches the request's session to its context
+// An initialize request without a session ID gets a new one. Otherwise
+// the ID is required and must name an open session of the same principal;
+// when it doesn't, the response is written and ok is false.
+func (t *HTTPTransport) rpcSession(w http.ResponseWriter, r *http.Request, body []byte) (_ *http.Request, ok bool) {
+	if t.sessionManager == nil {
+		return r, true
+	}
+	if r.Header.Get(HeaderSessionID) == "" && isInitialize(body) {
+		s, err := t.sessionManager.Create(principalID(r))
+		if errors.Is(err, session.ErrTooManySessions) {
+			http.Error(w, "Too many sessions", http.StatusServiceUnavailable)
+			return r, false
+		}
+		w.Header().Set(HeaderSessionID, s.ID())
+		return r.WithContext(session.WithSession(r.Context(), s)), true
+	}
+	return t.requireSession(w, r)
+}
+
+// requireSession attaches the session named by the request's
+// Mcp-Session-Id header, answering 400 without one and 404 (so clients
+// initialize again) for an unknown or expired one
+func (t *HTTPTransport) requireSession(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	id := r.Header.Get(HeaderSessionID)
+	if id == "" {
+		http.Error(w, "Missing "+HeaderSessionID+" header", http.StatusBadRequest)
+		return r, false
+	}
+	s, ok := t.sessionManager.Get(id)
+	// Another principal's session is reported like an unknown one
+	if !ok || s.Principal() != principalID(r) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return r, false
+	}
+	return r.WithContext(session.WithSession(r.Context(), s)), true
+}
+
+// optionalSession attaches the session named by the request's
+// Mcp-Session-Id header, if it has one
+func (t *HTTPTransport) optionalSession(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	if t.sessionManager == nil || r.Header.Get(HeaderSessionID) == "" {
+		return r, true
+	}
+	return t.requireSession(w, r)
+}
+
+// withOptionalSession applies optionalSession to next's requests
+func (t *HTTPTransport) withOptionalSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r, ok := t.optionalSession(w, r); ok {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// handleDeleteSession serves DELETE /rpc: the client ending its session
+func (t *HTTPTransport) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	r, ok := t.requireSession(w, r)
+	if !ok {
+		return
+	}
+	s, _ := session.FromContext(r.Context())
+	t.sessionManager.Terminate(s.ID())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// isInitialize reports whether a JSON-RPC request body is an initialize call
+func isInitialize(body []byte) bool {
+	var req struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(body, &req) == nil && req.Method == "initialize"
+}
+
+// principalID returns the authenticated principal's ID, "" without one
+func principalID(r *http.Request) string {
+	if p, ok := auth.PrincipalFromContext(r.Context()); ok && p != nil {
+		return p.ID
+	}
+	return ""
+}
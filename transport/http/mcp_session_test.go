@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/auth"
+	"github.com/SaherElMasry/go-mcp-framework/session"
+	"github.com/SaherElMasry/go-mcp-framework/transport"
+)
+
+func TestHTTPTransport_Sessions(t *testing.T) {
+	// The handler counts calls in the session's state
+	handler := handlerFunc(func(ctx context.Context, body []byte, transport string) ([]byte, error) {
+		s, ok := session.FromContext(ctx)
+		if !ok {
+			return []byte(`{"calls":0}`), nil
+		}
+		calls, _ := s.Get("calls")
+		n, _ := calls.(int)
+		s.Set("calls", n+1)
+		return []byte(fmt.Sprintf(`{"calls":%d}`, n+1)), nil
+	})
+	manager := session.NewManager(session.Config{MaxSessions: 1}, nil)
+	tr := NewHTTPTransport(handler, HTTPConfig{MaxRequestSize: 1 << 20}, nil, nil, nil)
+	tr.SetSessions(manager)
+	tr.SetBroadcaster(transport.NewBroadcaster())
+	h := tr.Handler()
+
+	post := func(body, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set(HeaderSessionID, sessionID)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize"}`
+	call := `{"jsonrpc":"2.0","id":2,"method":"tools/call"}`
+
+	w := post(initialize, "")
+	id := w.Header().Get(HeaderSessionID)
+	if w.Code != http.StatusOK || id == "" {
+		t.Fatalf("initialize: status %d, session %q", w.Code, id)
+	}
+	if w := post(call, id); w.Body.String() != `{"calls":2}` {
+		t.Errorf("call in session = %s, want the session's state", w.Body.String())
+	}
+
+	// Requests outside a session are refused
+	if w := post(call, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("call without session: status %d, want 400", w.Code)
+	}
+	if w := post(call, "unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("call with unknown session: status %d, want 404", w.Code)
+	}
+	if w := post(initialize, ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("initialize beyond max sessions: status %d, want 503", w.Code)
+	}
+
+	// Ending the session closes its notification stream
+	server := httptest.NewServer(h)
+	defer server.Close()
+	req, _ := http.NewRequest(http.MethodGet, server.URL+PathRPC, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(HeaderSessionID, id)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	del := httptest.NewRequest(http.MethodDelete, PathRPC, nil)
+	del.Header.Set(HeaderSessionID, id)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, del)
+	if w.Code != http.StatusNoContent || manager.Len() != 0 {
+		t.Errorf("DELETE: status %d, %d sessions open", w.Code, manager.Len())
+	}
+
+	ended := make(chan struct{})
+	go func() {
+		defer close(ended)
+		buf := make([]byte, 512)
+		for {
+			if _, err := resp.Body.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+	select {
+	case <-ended:
+	case <-time.After(2 * time.Second):
+		t.Error("notification stream still open after the session ended")
+	}
+	if w := post(call, id); w.Code != http.StatusNotFound {
+		t.Errorf("call in ended session: status %d, want 404", w.Code)
+	}
+}
+
+func TestHTTPTransport_SessionPrincipal(t *testing.T) {
+	manager := session.NewManager(session.Config{}, nil)
+	tr := NewHTTPTransport(&mockHandler{HandleResult: []byte(`{}`)}, HTTPConfig{MaxRequestSize: 1 << 20}, nil, nil, nil)
+	tr.SetSessions(manager)
+	authn, err := auth.NewInboundAuthenticator(auth.InboundConfig{
+		Enabled: true,
+		APIKeys: auth.InboundAPIKeyConfig{Keys: []auth.InboundCredential{
+			{ID: "alice", Secret: "alice-key"},
+			{ID: "bob", Secret: "bob-key"},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.SetAuthenticator(authn)
+	h := tr.Handler()
+
+	post := func(body, key, sessionID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, PathRPC, strings.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		if sessionID != "" {
+			req.Header.Set(HeaderSessionID, sessionID)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	id := post(`{"method":"initialize"}`, "alice-key", "").Header().Get(HeaderSessionID)
+	if sessions := manager.List(); len(sessions) != 1 || sessions[0].Principal != "alice" {
+		t.Fatalf("sessions = %+v", sessions)
+	}
+	if w := post(`{"method":"tools/list"}`, "alice-key", id); w.Code != http.StatusOK {
+		t.Errorf("owner: status %d", w.Code)
+	}
+	// Another principal can't use the session
+	if w := post(`{"method":"tools/list"}`, "bob-key", id); w.Code != http.StatusNotFound {
+		t.Errorf("other principal: status %d, want 404", w.Code)
+	}
+}
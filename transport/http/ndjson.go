@@ -0,0 +1,73 @@
+package http
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// ContentTypeNDJSON is the media type of newline-delimited JSON streams
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// streamFormat is the wire format of a /stream response
+type streamFormat int
+
+const (
+	// formatSSE sends Server-Sent Events (default)
+	formatSSE streamFormat = iota
+
+	// formatNDJSON sends one JSON object per line:
+	// {"event":"data","id":"<event ID>","data":{...}}
+	formatNDJSON
+)
+
+// negotiateFormat picks the stream format the client accepts
+// Clients that list application/x-ndjson in Accept get NDJSON; everyone
+// else, EventSource included, gets SSE.
+func negotiateFormat(r *http.Request) streamFormat {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err == nil && mediaType == ContentTypeNDJSON && params["q"] != "0" {
+				return formatNDJSON
+			}
+		}
+	}
+	return formatSSE
+}
+
+// setHeaders starts a stream response in the format
+func (f streamFormat) setHeaders(w http.ResponseWriter) {
+	setSSEHeaders(w)
+	if f == formatNDJSON {
+		w.Header().Set("Content-Type", ContentTypeNDJSON)
+	}
+}
+
+// message formats one event with already serialized data
+func (f streamFormat) message(event, id, data string) string {
+	if f == formatNDJSON {
+		return protocol.FormatNDJSON(event, id, data)
+	}
+	return protocol.FormatSSE(event, id, data)
+}
+
+// heartbeat keeps an idle stream alive; NDJSON clients skip blank lines
+func (f streamFormat) heartbeat() string {
+	if f == formatNDJSON {
+		return "\n"
+	}
+	return ": ping\n\n"
+}
+
+// ndjsonOnly serves /stream to clients that can't set Accept, as if they
+// asked for NDJSON
+func ndjsonOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+		r.Header.Set("Accept", ContentTypeNDJSON)
+		next.ServeHTTP(w, r)
+	})
+}
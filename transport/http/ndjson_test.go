@@ -0,0 +1,85 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   streamFormat
+	}{
+		{"", formatSSE},
+		{"text/event-stream", formatSSE},
+		{"application/x-ndjson", formatNDJSON},
+		{"text/event-stream;q=0.5, application/x-ndjson", formatNDJSON},
+		{"application/x-ndjson;q=0", formatSSE},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/stream", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		if got := negotiateFormat(r); got != tt.want {
+			t.Errorf("Accept %q: format %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPTransport_StreamNDJSON(t *testing.T) {
+	executor := engine.NewExecutor(engine.DefaultExecutorConfig(), nil)
+	mb := &mockBackend{Tools: map[string]backend.ToolDefinition{"tool1": {Name: "tool1", Streaming: true}}}
+	handler := NewHTTPTransport(&mockHandler{}, HTTPConfig{}, nil, mb, executor).Handler()
+
+	serve := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path+"?tool=tool1", strings.NewReader("{}"))
+		req.Header.Set(HeaderRequestID, "req-1")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, w := range []*httptest.ResponseRecorder{
+		serve(PathStream, ContentTypeNDJSON),
+		serve(PathStreamNDJSON, ""),
+	} {
+		if ct := w.Header().Get("Content-Type"); ct != ContentTypeNDJSON {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		var events []string
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var line struct {
+				Event string          `json:"event"`
+				ID    string          `json:"id"`
+				Data  json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				t.Fatalf("line %q: %v", scanner.Text(), err)
+			}
+			if line.ID != "req-1" || len(line.Data) == 0 {
+				t.Errorf("line = %s", scanner.Text())
+			}
+			events = append(events, line.Event)
+		}
+		if got := strings.Join(events, ","); got != "start,data,end" {
+			t.Errorf("events = %s", got)
+		}
+	}
+
+	// SSE stays the default
+	if w := serve(PathStream, ""); !strings.Contains(w.Body.String(), "event: data\n") {
+		t.Errorf("SSE stream:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+)
+
+// PathRecordings lists recorded event streams; PathRecordings/{request_id}
+// replays one through the SSE mapper
+// Served when the executor has a recorder (see engine.Recorder).
+const PathRecordings = "/debug/recordings"
+
+// HeaderRecord requests recording of a /stream call ("X-Record: true")
+// whose tool the recorder doesn't select
+const HeaderRecord = "X-Record"
+
+// maxReplayDelay caps the pause between events replayed in real time
+const maxReplayDelay = 5 * time.Second
+
+// RecordingsHandler serves recorded executions
+//
+//	GET /debug/recordings                  list recordings, newest first
+//	GET /debug/recordings/{id}             replay as text/event-stream
+//	GET /debug/recordings/{id}?timing=real replay with the original pacing
+//	GET /debug/recordings/{id}?format=json the raw recording
+type RecordingsHandler struct {
+	store engine.RecordingStore
+}
+
+// NewRecordingsHandler creates a handler serving store
+func NewRecordingsHandler(store engine.RecordingStore) *RecordingsHandler {
+	return &RecordingsHandler{store: store}
+}
+
+// ServeHTTP implements http.Handler
+func (h *RecordingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, PathRecordings), "/")
+	if id == "" {
+		infos, err := h.store.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"recordings": infos})
+		return
+	}
+
+	rec, err := h.store.Load(id)
+	if errors.Is(err, engine.ErrRecordingNotFound) {
+		http.Error(w, "Recording not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rec)
+		return
+	}
+
+	h.replay(w, r, rec, r.URL.Query().Get("timing") == "real")
+}
+
+// replay writes a recording as the SSE stream /stream produced
+func (h *RecordingsHandler) replay(w http.ResponseWriter, r *http.Request, rec *engine.Recording, realTime bool) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher, _ := w.(http.Flusher)
+
+	var previous time.Time
+	for _, recorded := range rec.Events {
+		if realTime && !previous.IsZero() {
+			delay := min(recorded.Timestamp.Sub(previous), maxReplayDelay)
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+		previous = recorded.Timestamp
+
+		if _, err := w.Write([]byte(protocol.FormatEventAsSSE(recorded.Event(), rec.RequestID))); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
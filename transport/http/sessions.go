@@ -0,0 +1,59 @@
+package http
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Session is a /stream call in progress
+type Session struct {
+	RequestID  string    `json:"request_id"`
+	Tool       string    `json:"tool"`
+	Principal  string    `json:"principal,omitempty"`
+	RemoteAddr string    `json:"remote_addr"`
+	Started    time.Time `json:"started"`
+}
+
+// sessionRegistry tracks the streams the SSE handler is serving
+type sessionRegistry struct {
+	mu     sync.Mutex
+	next   uint64
+	active map[uint64]Session
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{active: make(map[uint64]Session)}
+}
+
+// add records a session until the returned function is called
+// Sessions are keyed by registration, since clients choose request IDs.
+func (r *sessionRegistry) add(s Session) (remove func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	id := r.next
+	r.active[id] = s
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.active, id)
+	}
+}
+
+// list returns the active sessions, oldest first
+func (r *sessionRegistry) list() []Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]Session, 0, len(r.active))
+	for _, s := range r.active {
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Started.Before(sessions[j].Started) })
+	return sessions
+}
+
+// Sessions returns the /stream calls in progress, oldest first
+func (t *HTTPTransport) Sessions() []Session {
+	return t.sessions.list()
+}
@@ -2,24 +2,60 @@ package http
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/SaherElMasry/go-mcp-framework/audit"
+	"github.com/SaherElMasry/go-mcp-framework/auth"
 	"github.com/SaherElMasry/go-mcp-framework/backend"
 	"github.com/SaherElMasry/go-mcp-framework/engine"
-	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/mcperr"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
 )
 
+// DefaultHeartbeat is the interval of the ": ping" comments an idle stream
+// sends, so proxies don't close it
+const DefaultHeartbeat = 15 * time.Second
+
+// ErrClientDisconnected is the cause of a streaming call's cancellation
+// when its client went away (see context.Cause)
+var ErrClientDisconnected = errors.New("client disconnected")
+
 // SSEHandler handles Server-Sent Events streaming requests
 type SSEHandler struct {
 	executor *engine.Executor
 	backend  backend.ServerBackend
 	logger   *slog.Logger
 	timeout  time.Duration
+	limiter  *ratelimit.Limiter
+	access   *auth.AccessPolicy
+	audit    *audit.Logger
+
+	// sessions, if set, tracks the streams in progress
+	sessions *sessionRegistry
+
+	// clients identifies callers for per-client rate limiting
+	clients clientIdentifier
+
+	// eventLimit bounds the size of each event (see SetEventLimit)
+	eventLimit EventLimit
+
+	// heartbeat is the interval of comments on idle streams; 0 disables them
+	heartbeat time.Duration
+
+	// chunking serves non-streaming tools (see SetChunkedResults)
+	chunking ChunkedResults
 }
 
 // NewSSEHandler creates a new SSE handler
@@ -37,28 +73,137 @@ func NewSSEHandler(
 	}
 
 	return &SSEHandler{
-		executor: executor,
-		backend:  backend,
-		logger:   logger,
-		timeout:  timeout,
+		executor:  executor,
+		backend:   backend,
+		logger:    logger,
+		timeout:   timeout,
+		heartbeat: DefaultHeartbeat,
+	}
+}
+
+// SetHeartbeat sets the interval of the ": ping" comments sent on idle
+// streams (default DefaultHeartbeat); d <= 0 disables them
+func (h *SSEHandler) SetHeartbeat(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.heartbeat = d
+}
+
+// SetRateLimiter configures rate limiting for stream requests
+func (h *SSEHandler) SetRateLimiter(limiter *ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetAccessPolicy configures per-tool access control for stream requests
+func (h *SSEHandler) SetAccessPolicy(policy *auth.AccessPolicy) {
+	h.access = policy
+}
+
+// SetAuditLogger records every stream request to an audit log
+func (h *SSEHandler) SetAuditLogger(l *audit.Logger) {
+	h.audit = l
+}
+
+// record writes a stream request to the audit log; code is "" on success
+func (h *SSEHandler) record(r *http.Request, toolName string, args map[string]interface{}, start time.Time, code string) {
+	if h.audit == nil {
+		return
+	}
+	status := "success"
+	if code != "" {
+		status = "error"
+	}
+	h.audit.Record(r.Context(), audit.Event{
+		Transport:  "sse",
+		Tool:       toolName,
+		Arguments:  args,
+		Status:     status,
+		ErrorCode:  code,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	})
+}
+
+// authorize checks the request principal against access policies and the tool's required scopes
+// Unknown tools are left to the regular tool_not_found handling
+func (h *SSEHandler) authorize(r *http.Request) error {
+	toolName := r.URL.Query().Get("tool")
+	principal, _ := auth.PrincipalFromContext(r.Context())
+
+	if err := h.access.Authorize(toolName, principal); err != nil {
+		return err
+	}
+
+	if h.backend == nil {
+		return nil
+	}
+	tool, ok := h.backend.GetTool(toolName)
+	if !ok {
+		return nil
 	}
+	return auth.RequireScopes(principal, tool.RequiredScopes...)
 }
 
 // ServeHTTP handles SSE streaming requests
 // POST /stream?tool=<tool_name> with JSON body containing arguments
+//
+// When the executor buffers streams, a client that lost its connection
+// reconnects with the Last-Event-ID header (GET or POST, as EventSource
+// does) and receives the events it missed, instead of running the tool
+// again. Only the principal that made the call can resume it; an
+// anonymous call's request ID is replaced by a random one, so only
+// clients that saw its events can.
+//
+// Clients that send Accept: application/x-ndjson get the same events as
+// newline-delimited JSON, one object per line.
 func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if lastEventID := r.Header.Get(HeaderLastEventID); lastEventID != "" && h.executor.Resumable() {
+		h.resume(w, r, lastEventID)
+		return
+	}
+
 	// Only accept POST requests
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Set SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*") // Configure properly in production
-	w.Header().Set("X-Accel-Buffering", "no")          // Disable nginx buffering
+	start := time.Now()
+	r, requestID := withRequestID(w, r)
+	if h.executor.Resumable() && principalID(r) == "" {
+		r, requestID = withStreamID(w, r)
+	}
+
+	// Enforce per-tool access policies and required scopes before committing to an event stream
+	if err := h.authorize(r); err != nil {
+		h.logger.WarnContext(r.Context(), "tool access denied", "tool", r.URL.Query().Get("tool"), "error", err)
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			observability.RecordAuthFailure("missing", "sse")
+			h.record(r, r.URL.Query().Get("tool"), nil, start, "unauthenticated")
+			writeUnauthorized(w, err.Error())
+			return
+		}
+		observability.RecordAuthFailure("forbidden", "sse")
+		h.record(r, r.URL.Query().Get("tool"), nil, start, string(mcperr.CodePermissionDenied))
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	// Enforce rate limits before committing to an event stream
+	if h.limiter != nil {
+		toolName := r.URL.Query().Get("tool")
+		if d := h.limiter.Allow(toolName, h.clients.id(r)); !d.Allowed {
+			observability.RecordRateLimitRejection(string(d.Scope), toolName)
+			h.record(r, toolName, nil, start, string(mcperr.CodeRateLimited))
+			h.logger.WarnContext(r.Context(), "rate limit exceeded",
+				"tool", toolName,
+				"scope", d.Scope,
+				"retry_after", d.RetryAfter)
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(d.RetryAfter.Seconds()))))
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+	}
 
 	// Get flusher for streaming
 	flusher, ok := w.(http.Flusher)
@@ -66,102 +211,337 @@ func (h *SSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
 		return
 	}
+	format := negotiateFormat(r)
 
 	// Parse request body (tool arguments)
 	var args map[string]interface{}
 	if r.Body != nil {
 		if err := json.NewDecoder(r.Body).Decode(&args); err != nil && err != io.EOF {
-			h.sendErrorEvent(w, flusher, "invalid_request", fmt.Sprintf("Failed to parse arguments: %v", err))
+			var maxBytes *http.MaxBytesError
+			if errors.As(err, &maxBytes) {
+				// Before committing to an event stream
+				writeTooLarge(w, maxBytes.Limit)
+				return
+			}
+			format.setHeaders(w)
+			h.sendErrorEvent(w, flusher, format, "invalid_request", fmt.Sprintf("Failed to parse arguments: %v", err))
 			return
 		}
 	}
+	format.setHeaders(w)
 
 	// Get tool name from query parameter
 	toolName := r.URL.Query().Get("tool")
 	if toolName == "" {
-		h.sendErrorEvent(w, flusher, "missing_tool", "Tool name required in query parameter 'tool'")
+		h.sendErrorEvent(w, flusher, format, "missing_tool", "Tool name required in query parameter 'tool'")
 		return
 	}
 
 	// Verify tool exists
-	_, ok = h.backend.GetTool(toolName)
+	tool, ok := h.backend.GetTool(toolName)
 	if !ok {
-		h.sendErrorEvent(w, flusher, "tool_not_found", fmt.Sprintf("Tool not found: %s", toolName))
+		h.sendErrorEvent(w, flusher, format, "tool_not_found", fmt.Sprintf("Tool not found: %s", toolName))
 		return
 	}
 
-	// Check if tool supports streaming
-	if !h.backend.IsStreamingTool(toolName) {
-		h.sendErrorEvent(w, flusher, "not_streaming", fmt.Sprintf("Tool %s does not support streaming", toolName))
+	// Reject arguments that violate the advertised input schema
+	if err := backend.ValidateArguments(tool, args); err != nil {
+		h.record(r, toolName, args, start, "invalid_params")
+		h.sendErrorEvent(w, flusher, format, "invalid_arguments", err.Error())
 		return
 	}
 
-	// Generate request ID
-	requestID := fmt.Sprintf("req-%d", time.Now().UnixNano())
+	// Check if tool supports streaming
+	streaming := h.backend.IsStreamingTool(toolName)
+	if !streaming && !h.chunking.enabled() {
+		h.sendErrorEvent(w, flusher, format, "not_streaming", fmt.Sprintf("Tool %s does not support streaming", toolName))
+		return
+	}
 
 	h.logger.Info("starting SSE stream",
 		"tool", toolName,
 		"request_id", requestID,
 		"remote_addr", r.RemoteAddr)
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	// A client that goes away cancels the call with ErrClientDisconnected
+	// as its cause, so tools stop working for nobody and can tell why
+	ctx, disconnect := context.WithCancelCause(context.WithoutCancel(r.Context()))
+	defer disconnect(nil)
+	defer context.AfterFunc(r.Context(), func() { disconnect(ErrClientDisconnected) })()
+
+	// The tool's timeout replaces the server default; X-Timeout may shorten it
+	ctx, err := withTimeoutHeader(ctx, r)
+	if err != nil {
+		h.sendErrorEvent(w, flusher, format, "invalid_timeout", err.Error())
+		return
+	}
+	if record, _ := strconv.ParseBool(r.Header.Get(HeaderRecord)); record {
+		ctx = engine.WithRecording(ctx)
+	}
+	if level := r.Header.Get(HeaderLogLevel); level != "" {
+		if err := engine.ValidateLogLevel(level); err != nil {
+			h.sendErrorEvent(w, flusher, format, "invalid_log_level", err.Error())
+			return
+		}
+		ctx = engine.WithLogLevel(ctx, level)
+	}
+	ctx = engine.WithOwner(ctx, principalID(r))
+
+	timeout := backend.ResolveTimeout(ctx, tool, h.timeout)
+	ctx = engine.WithTimeout(ctx, timeout)
+	if tool.EventOverflow != "" || tool.EventOverflowTimeout != 0 {
+		ctx = engine.WithOverflow(ctx, engine.Overflow{
+			Policy:  engine.OverflowPolicy(tool.EventOverflow),
+			Timeout: tool.EventOverflowTimeout,
+		})
+	}
+	if tool.EventBatchSize != 0 || tool.EventFlushInterval != 0 {
+		ctx = engine.WithBatching(ctx, engine.Batching{
+			Size:          tool.EventBatchSize,
+			FlushInterval: tool.EventFlushInterval,
+		})
+	}
+
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
 	defer cancel()
 
 	// Create streaming handler that calls the backend
 	handler := func(ctx context.Context, args map[string]interface{}, emit engine.Emitter) error {
 		return h.backend.CallStreamingTool(ctx, toolName, args, emit)
 	}
+	if !streaming {
+		handler = h.chunkedHandler(toolName)
+	}
 
 	// Execute tool and get event stream
+	execStart := time.Now()
+	observability.IncActiveStreams()
+	defer observability.DecActiveStreams()
+	if h.sessions != nil {
+		session := Session{RequestID: requestID, Tool: toolName, RemoteAddr: r.RemoteAddr, Started: execStart}
+		if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+			session.Principal = principal.ID
+		}
+		defer h.sessions.add(session)()
+	}
 	events := h.executor.Execute(ctx, toolName, requestID, args, handler)
 
 	// Stream events as SSE messages
-	h.streamEvents(w, flusher, events, requestID)
+	status := "success"
+	code := h.streamEvents(r.Context(), w, flusher, format, events, toolName, requestID, 0)
+	if code == "client_gone" {
+		disconnect(ErrClientDisconnected)
+	}
+	if code != "" {
+		status = "error"
+		observability.RecordToolError(toolName, "sse", code)
+	}
+	observability.RecordToolCall(toolName, status, "sse")
+	observability.RecordToolDuration(toolName, "sse", time.Since(execStart))
+	h.record(r, toolName, args, start, code)
 
 	h.logger.Info("SSE stream completed",
 		"tool", toolName,
 		"request_id", requestID)
 }
 
-// streamEvents converts engine events to SSE format and sends them
+// withStreamID gives an anonymous resumable call a new random request ID
+// in place of the client's X-Request-ID (or a generated, possibly
+// predictable one): without a principal to own the stream, knowing the
+// ID is what lets a client resume it.
+func withStreamID(w http.ResponseWriter, r *http.Request) (*http.Request, string) {
+	b := make([]byte, 16)
+	rand.Read(b)
+	id := hex.EncodeToString(b)
+	w.Header().Set(HeaderRequestID, id)
+	return r.WithContext(observability.WithRequestID(r.Context(), id)), id
+}
+
+// resume serves a reconnecting client the events after its Last-Event-ID
+func (h *SSEHandler) resume(w http.ResponseWriter, r *http.Request, lastEventID string) {
+	requestID, after, ok := parseEventID(lastEventID)
+	if !ok {
+		http.Error(w, "Invalid "+HeaderLastEventID, http.StatusBadRequest)
+		return
+	}
+	stream, err := h.executor.Resume(r.Context(), requestID, principalID(r), after)
+	switch {
+	case errors.Is(err, engine.ErrStreamNotFound):
+		http.Error(w, "Stream not found", http.StatusNotFound)
+		return
+	case errors.Is(err, engine.ErrStreamGap):
+		// The client has to call the tool again
+		http.Error(w, "Stream events no longer available", http.StatusGone)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	r = r.WithContext(observability.WithRequestID(r.Context(), requestID))
+	w.Header().Set(HeaderRequestID, requestID)
+	format := negotiateFormat(r)
+	format.setHeaders(w)
+
+	h.logger.InfoContext(r.Context(), "resuming SSE stream",
+		"tool", stream.ToolName,
+		"from_event", stream.From,
+		"remote_addr", r.RemoteAddr)
+
+	observability.IncActiveStreams()
+	defer observability.DecActiveStreams()
+	if h.sessions != nil {
+		session := Session{RequestID: requestID, Tool: stream.ToolName, Principal: principalID(r), RemoteAddr: r.RemoteAddr, Started: time.Now()}
+		defer h.sessions.add(session)()
+	}
+	// The call outlives this client, which only stops receiving its events
+	h.streamEvents(r.Context(), w, flusher, format, stream.Events, stream.ToolName, requestID, stream.From)
+
+	h.logger.InfoContext(r.Context(), "resumed SSE stream completed", "tool", stream.ToolName)
+}
+
+// setSSEHeaders starts an event stream response
+func setSSEHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
+}
+
+// HeaderLastEventID carries the ID of the last event a reconnecting
+// client received
+const HeaderLastEventID = "Last-Event-ID"
+
+// HeaderLogLevel sets the least severe level of the log events of a
+// /stream call ("X-Log-Level: warning"; default: the executor's)
+const HeaderLogLevel = "X-Log-Level"
+
+// eventID returns the SSE ID of a stream's event n
+// Resumable streams number their events, "<request ID>:<n>", so a
+// reconnecting client's Last-Event-ID says where to resume.
+func (h *SSEHandler) eventID(requestID string, n int) string {
+	if !h.executor.Resumable() {
+		return requestID
+	}
+	return requestID + ":" + strconv.Itoa(n)
+}
+
+// parseEventID splits an ID made by eventID
+func parseEventID(id string) (requestID string, n int, ok bool) {
+	i := strings.LastIndex(id, ":")
+	if i <= 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(id[i+1:])
+	if err != nil || n < 0 {
+		return "", 0, false
+	}
+	return id[:i], n, true
+}
+
+// streamEvents converts engine events to the stream format and sends
+// them, numbering them from first
+// While no event is due it sends heartbeat comments. It returns the error
+// code of a failed call, "client_gone" if the client went away (ctx, the
+// request's context, is done or a write failed), or "" if the call
+// succeeded.
 func (h *SSEHandler) streamEvents(
+	ctx context.Context,
 	w http.ResponseWriter,
 	flusher http.Flusher,
+	format streamFormat,
 	events <-chan engine.Event,
+	toolName string,
 	requestID string,
-) {
-	for evt := range events {
-		// Convert event to SSE using the public protocol function
-		sseData := protocol.FormatEventAsSSE(evt, requestID)
+	first int,
+) (code string) {
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	n := first
+	for {
+		var evt engine.Event
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return code
+			}
+			evt = e
+		case <-heartbeat:
+			if _, err := io.WriteString(w, format.heartbeat()); err != nil {
+				h.clientGone(ctx, toolName, requestID, "heartbeat failed", err)
+				return "client_gone"
+			}
+			flusher.Flush()
+			continue
+		case <-ctx.Done():
+			h.clientGone(ctx, toolName, requestID, "connection closed", context.Cause(ctx))
+			return "client_gone"
+		}
+
+		observability.RecordStreamingEvent(toolName, evt.Type.String(), "sse")
+		if payload, ok := evt.Data.(engine.ErrorPayload); ok && evt.Type == engine.EventError {
+			code = string(mcperr.CodeOf(payload.Error))
+		}
+
+		// Convert event to the stream format, splitting or truncating it if oversized
+		sseData := h.formatEvent(format, evt, toolName, h.eventID(requestID, n))
+		n++
 
 		// Write SSE message
 		if _, err := w.Write([]byte(sseData)); err != nil {
-			h.logger.Error("failed to write SSE message",
-				"error", err,
-				"request_id", requestID)
-			return
+			h.clientGone(ctx, toolName, requestID, "write failed", err)
+			return "client_gone"
 		}
 
 		// Flush immediately for streaming
 		flusher.Flush()
 
-		// Log progress events
-		if evt.Type == engine.EventProgress {
+		// Log progress and retry events
+		switch evt.Type {
+		case engine.EventProgress:
 			if payload, ok := evt.Data.(engine.ProgressPayload); ok {
 				h.logger.Debug("progress",
 					"request_id", requestID,
 					"percentage", payload.Percentage,
 					"message", payload.Message)
 			}
+		case engine.EventRetry:
+			if payload, ok := evt.Data.(engine.RetryPayload); ok {
+				h.logger.Info("retrying upstream call",
+					"request_id", requestID,
+					"attempt", payload.Attempt,
+					"delay_ms", payload.DelayMS,
+					"reason", payload.Reason)
+			}
 		}
 	}
 }
 
-// sendErrorEvent sends an error event in SSE format
-func (h *SSEHandler) sendErrorEvent(w http.ResponseWriter, flusher http.Flusher, code, message string) {
+// clientGone logs why a stream's client went away
+func (h *SSEHandler) clientGone(ctx context.Context, toolName, requestID, reason string, err error) {
+	h.logger.InfoContext(ctx, "SSE client disconnected",
+		"tool", toolName,
+		"request_id", requestID,
+		"reason", reason,
+		"error", err)
+}
+
+// sendErrorEvent sends an error event in the stream format
+func (h *SSEHandler) sendErrorEvent(w http.ResponseWriter, flusher http.Flusher, format streamFormat, code, message string) {
 	errorEvt := engine.NewErrorEvent(nil, message, false)
-	sseData := protocol.FormatEventAsSSE(errorEvt, code)
-	w.Write([]byte(sseData))
+	data, _ := json.Marshal(errorEvt.Data)
+	w.Write([]byte(format.message(errorEvt.Type.String(), code, string(data))))
 	flusher.Flush()
 }
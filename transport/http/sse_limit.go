@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+)
+
+// How /stream sends events over EventLimit.MaxSize
+const (
+	// OversizedSplit sends the event as "fragment" events (default)
+	OversizedSplit = "split"
+
+	// OversizedTruncate cuts the event's payload to fit
+	OversizedTruncate = "truncate"
+)
+
+// MinEventSize is the smallest allowed EventLimit.MaxSize
+const MinEventSize = 1024
+
+// fragmentOverhead is room left in each fragment for its envelope
+const fragmentOverhead = 256
+
+// EventLimit bounds the data of each /stream event, for clients and
+// proxies that break on multi-megabyte events
+type EventLimit struct {
+	// MaxSize is the most bytes of JSON data per event (0 = no limit)
+	MaxSize int
+
+	// Policy is OversizedSplit or OversizedTruncate
+	Policy string
+}
+
+// Validate validates the limit
+func (l EventLimit) Validate() error {
+	if l.MaxSize != 0 && l.MaxSize < MinEventSize {
+		return fmt.Errorf("max event size must be 0 (no limit) or at least %d bytes, got %d", MinEventSize, l.MaxSize)
+	}
+	switch l.Policy {
+	case "", OversizedSplit, OversizedTruncate:
+		return nil
+	}
+	return fmt.Errorf("unknown oversized event policy %q (want split or truncate)", l.Policy)
+}
+
+// FragmentPayload is one piece of an event too large to send whole
+// Clients join the parts of fragments 0..Fragments-1 and decode the
+// result as the data of an Event event. Only the last fragment carries
+// the SSE event ID, so a client reconnecting midway is sent every
+// fragment again.
+type FragmentPayload struct {
+	Event     string `json:"event"`
+	Sequence  int64  `json:"sequence,omitempty"`
+	Fragment  int    `json:"fragment"`
+	Fragments int    `json:"fragments"`
+	Part      string `json:"part"`
+}
+
+// TruncatedPayload replaces the data of an event cut to fit
+// Data is the start of the event's JSON data, no longer valid JSON.
+type TruncatedPayload struct {
+	Sequence  int64  `json:"sequence,omitempty"`
+	Data      string `json:"data"`
+	Truncated bool   `json:"truncated"`
+	Size      int    `json:"size"`
+}
+
+// SetEventLimit bounds the size of stream events
+func (h *SSEHandler) SetEventLimit(limit EventLimit) {
+	h.eventLimit = limit
+}
+
+// formatEvent returns an event in the stream format, split or truncated
+// to the limit
+func (h *SSEHandler) formatEvent(format streamFormat, evt engine.Event, toolName, id string) string {
+	data, err := json.Marshal(evt.Data)
+	if err != nil {
+		data = []byte(`{"error":"failed to serialize event data"}`)
+	}
+	if h.eventLimit.MaxSize <= 0 || len(data) <= h.eventLimit.MaxSize {
+		return format.message(evt.Type.String(), id, string(data))
+	}
+
+	var sequence int64
+	if payload, ok := evt.Data.(engine.DataPayload); ok {
+		sequence = payload.Sequence
+	}
+	budget := h.eventLimit.MaxSize - fragmentOverhead
+
+	if h.eventLimit.Policy == OversizedTruncate {
+		observability.RecordOversizedEvent(toolName, OversizedTruncate)
+		prefix, _ := cutJSONString(string(data), budget)
+		return format.message(evt.Type.String(), id, marshalUnescaped(TruncatedPayload{
+			Sequence:  sequence,
+			Data:      prefix,
+			Truncated: true,
+			Size:      len(data),
+		}))
+	}
+
+	observability.RecordOversizedEvent(toolName, OversizedSplit)
+	var parts []string
+	for rest := string(data); rest != ""; {
+		var part string
+		part, rest = cutJSONString(rest, budget)
+		parts = append(parts, part)
+	}
+	var sb strings.Builder
+	for i, part := range parts {
+		fragmentID := ""
+		if i == len(parts)-1 {
+			fragmentID = id
+		}
+		sb.WriteString(format.message("fragment", fragmentID, marshalUnescaped(FragmentPayload{
+			Event:     evt.Type.String(),
+			Sequence:  sequence,
+			Fragment:  i,
+			Fragments: len(parts),
+			Part:      part,
+		})))
+	}
+	return sb.String()
+}
+
+// cutJSONString splits s after the longest prefix whose JSON string
+// encoding (see marshalUnescaped) fits in budget bytes, keeping runes
+// whole; the prefix holds at least one rune
+func cutJSONString(s string, budget int) (prefix, rest string) {
+	size := 0
+	for i, r := range s {
+		n := escapedLen(r)
+		if size+n > budget && i > 0 {
+			return s[:i], s[i:]
+		}
+		size += n
+	}
+	return s, ""
+}
+
+// escapedLen is the length of r in a JSON string without HTML escaping
+func escapedLen(r rune) int {
+	switch {
+	case r == '"' || r == '\\' || r == '\n' || r == '\r' || r == '\t' || r == '\b' || r == '\f':
+		return 2
+	case r < 0x20 || r == '\u2028' || r == '\u2029':
+		return 6
+	}
+	return utf8.RuneLen(r)
+}
+
+// marshalUnescaped encodes v as JSON without HTML escaping, so string
+// sizes match escapedLen
+func marshalUnescaped(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
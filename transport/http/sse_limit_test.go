@@ -0,0 +1,118 @@
+package http
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/SaherElMasry/go-mcp-framework/engine"
+)
+
+// parseSSE returns the event types, IDs and data of formatted SSE messages
+func parseSSE(t *testing.T, s string) (types, ids, data []string) {
+	t.Helper()
+	for _, msg := range strings.Split(strings.TrimSuffix(s, "\n\n"), "\n\n") {
+		var typ, id string
+		var lines []string
+		for _, line := range strings.Split(msg, "\n") {
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				typ = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				lines = append(lines, strings.TrimPrefix(line, "data: "))
+			}
+		}
+		types, ids, data = append(types, typ), append(ids, id), append(data, strings.Join(lines, "\n"))
+	}
+	return types, ids, data
+}
+
+func TestEventLimit_Validate(t *testing.T) {
+	tests := []struct {
+		limit   EventLimit
+		wantErr bool
+	}{
+		{EventLimit{}, false},
+		{EventLimit{MaxSize: 4096, Policy: OversizedTruncate}, false},
+		{EventLimit{MaxSize: 100}, true},
+		{EventLimit{MaxSize: 4096, Policy: "drop"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.limit.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) = %v, wantErr %v", tt.limit, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSSEHandler_SplitOversizedEvent(t *testing.T) {
+	h := NewSSEHandler(nil, nil, nil, 0)
+	h.SetEventLimit(EventLimit{MaxSize: MinEventSize})
+
+	// Quotes, newlines and multi-byte runes all grow or span bytes when encoded
+	chunk := strings.Repeat(`line "é" <b>日本</b>`+"\n", 300)
+	evt := engine.NewDataEvent(chunk, 7)
+	want, _ := json.Marshal(evt.Data)
+
+	types, ids, data := parseSSE(t, h.formatEvent(formatSSE, evt, "tool1", "req-1:3"))
+	if len(types) < 2 {
+		t.Fatalf("got %d events, want fragments", len(types))
+	}
+	var joined strings.Builder
+	for i := range types {
+		if len(data[i]) > MinEventSize {
+			t.Errorf("fragment %d has %d bytes", i, len(data[i]))
+		}
+		var frag FragmentPayload
+		if err := json.Unmarshal([]byte(data[i]), &frag); err != nil {
+			t.Fatalf("fragment %d: %v", i, err)
+		}
+		if types[i] != "fragment" || frag.Event != "data" || frag.Sequence != 7 ||
+			frag.Fragment != i || frag.Fragments != len(types) {
+			t.Errorf("fragment %d = %s %+v", i, types[i], frag)
+		}
+		// Only the last fragment advances the client's Last-Event-ID
+		wantID := ""
+		if i == len(types)-1 {
+			wantID = "req-1:3"
+		}
+		if ids[i] != wantID {
+			t.Errorf("fragment %d has id %q", i, ids[i])
+		}
+		joined.WriteString(frag.Part)
+	}
+	if joined.String() != string(want) {
+		t.Errorf("joined fragments differ from the event data")
+	}
+}
+
+func TestSSEHandler_TruncateOversizedEvent(t *testing.T) {
+	h := NewSSEHandler(nil, nil, nil, 0)
+	h.SetEventLimit(EventLimit{MaxSize: MinEventSize, Policy: OversizedTruncate})
+
+	evt := engine.NewDataEvent(strings.Repeat("x", 5000), 2)
+	types, ids, data := parseSSE(t, h.formatEvent(formatSSE, evt, "tool1", "req-1:1"))
+	if len(types) != 1 || types[0] != "data" || ids[0] != "req-1:1" || len(data[0]) > MinEventSize {
+		t.Fatalf("truncated event = %v %v (%d bytes)", types, ids, len(data[0]))
+	}
+	var payload TruncatedPayload
+	if err := json.Unmarshal([]byte(data[0]), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if !payload.Truncated || payload.Sequence != 2 || payload.Size <= 5000 ||
+		!strings.HasPrefix(payload.Data, `{"chunk":"xxx`) {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestSSEHandler_EventUnderLimit(t *testing.T) {
+	h := NewSSEHandler(nil, nil, nil, 0)
+	h.SetEventLimit(EventLimit{MaxSize: MinEventSize})
+
+	evt := engine.NewDataEvent("small", 1)
+	want := "event: data\nid: req-1:1\ndata: {\"chunk\":\"small\",\"sequence\":1}\n\n"
+	if got := h.formatEvent(formatSSE, evt, "tool1", "req-1:1"); got != want {
+		t.Errorf("formatEvent = %q, want %q", got, want)
+	}
+}
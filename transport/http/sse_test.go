@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -161,7 +162,7 @@ func TestSSEHandler_sendErrorEvent(t *testing.T) {
 	h := NewSSEHandler(nil, nil, nil, 0)
 	w := &flushingRecorder{ResponseRecorder: httptest.NewRecorder()}
 
-	h.sendErrorEvent(w, w, "test_code", "test message")
+	h.sendErrorEvent(w, w, formatSSE, "test_code", "test message")
 
 	body := w.Body.String()
 	if !strings.Contains(body, "event: error") {
@@ -174,3 +175,235 @@ func TestSSEHandler_sendErrorEvent(t *testing.T) {
 		t.Error("Expected flusher to be called")
 	}
 }
+
+func TestHTTPTransport_Recordings(t *testing.T) {
+	executor := engine.NewExecutor(engine.DefaultExecutorConfig(), nil)
+	store := engine.NewMemoryRecordingStore(10)
+	executor.SetRecorder(engine.NewRecorder(store, "tool1"))
+	mb := &mockBackend{
+		Tools: map[string]backend.ToolDefinition{
+			"tool1": {Name: "tool1", Streaming: true},
+		},
+	}
+	handler := NewHTTPTransport(&mockHandler{}, HTTPConfig{}, nil, mb, executor).Handler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/stream?tool=tool1", strings.NewReader(`{"input":"x"}`)))
+	original := w.Body.String()
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathRecordings, nil))
+	var list struct {
+		Recordings []engine.RecordingInfo `json:"recordings"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list.Recordings) != 1 {
+		t.Fatalf("list = %s, %v", w.Body.String(), err)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathRecordings+"/"+list.Recordings[0].RequestID, nil))
+	if w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Content-Type = %s", w.Header().Get("Content-Type"))
+	}
+	if w.Body.String() != original {
+		t.Errorf("replay differs from the original stream:\n%s\nwant:\n%s", w.Body.String(), original)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, PathRecordings+"/req-unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown recording: status %d", w.Code)
+	}
+}
+
+// blockingBackend streams until release is closed
+type blockingBackend struct {
+	mockBackend
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingBackend) CallStreamingTool(ctx context.Context, name string, args map[string]interface{}, emit backend.StreamingEmitter) error {
+	close(b.started)
+	<-b.release
+	return nil
+}
+
+func TestSSEHandler_Sessions(t *testing.T) {
+	executor := engine.NewExecutor(engine.DefaultExecutorConfig(), nil)
+	b := &blockingBackend{
+		mockBackend: mockBackend{Tools: map[string]backend.ToolDefinition{"tail": {Name: "tail", Streaming: true}}},
+		started:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	ht := NewHTTPTransport(&mockHandler{}, HTTPConfig{}, nil, b, executor)
+	handler := ht.Handler()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest(http.MethodPost, "/stream?tool=tail", strings.NewReader("{}"))
+		req.Header.Set(HeaderRequestID, "req-1")
+		handler.ServeHTTP(&flushingRecorder{ResponseRecorder: httptest.NewRecorder()}, req)
+	}()
+
+	<-b.started
+	sessions := ht.Sessions()
+	if len(sessions) != 1 || sessions[0].Tool != "tail" || sessions[0].RequestID != "req-1" {
+		t.Errorf("sessions during the stream = %+v", sessions)
+	}
+	close(b.release)
+	<-done
+	if sessions := ht.Sessions(); len(sessions) != 0 {
+		t.Errorf("sessions after the stream = %+v", sessions)
+	}
+}
+
+func TestSSEHandler_Resume(t *testing.T) {
+	config := engine.DefaultExecutorConfig()
+	config.ResumeBuffer = 3
+	executor := engine.NewExecutor(config, nil)
+	mb := &mockBackend{Tools: map[string]backend.ToolDefinition{"tool1": {Name: "tool1", Streaming: true}}}
+	h := NewSSEHandler(executor, mb, nil, time.Second)
+
+	serve := func(method, lastEventID string) *flushingRecorder {
+		req := httptest.NewRequest(method, "/stream?tool=tool1", strings.NewReader("{}"))
+		req.Header.Set(HeaderRequestID, "req-1")
+		if lastEventID != "" {
+			req.Header.Set(HeaderLastEventID, lastEventID)
+		}
+		w := &flushingRecorder{ResponseRecorder: httptest.NewRecorder()}
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// Events are numbered so clients can say where they left off; an
+	// anonymous stream's ID is the server's, not the client's req-1
+	first := serve(http.MethodPost, "")
+	id := first.Header().Get(HeaderRequestID)
+	if id == "" || id == "req-1" {
+		t.Fatalf("X-Request-ID of an anonymous stream = %q", id)
+	}
+	body := first.Body.String()
+	for _, n := range []string{"0", "1", "2"} {
+		if !strings.Contains(body, "id: "+id+":"+n+"\n") {
+			t.Errorf("stream lacks event %s of %q:\n%s", n, id, body)
+		}
+	}
+
+	// Another anonymous client reusing req-1 gets a stream of its own
+	if other := serve(http.MethodPost, "").Header().Get(HeaderRequestID); other == id || other == "req-1" {
+		t.Errorf("X-Request-ID of the second stream = %q", other)
+	}
+
+	// A reconnecting EventSource gets the events after its last one
+	w := serve(http.MethodGet, id+":0")
+	body = w.Body.String()
+	if w.Code != http.StatusOK || strings.Contains(body, "event: start") ||
+		!strings.Contains(body, "event: data\nid: "+id+":1\n") || !strings.Contains(body, "event: end\nid: "+id+":2\n") {
+		t.Errorf("resumed stream = %d:\n%s", w.Code, body)
+	}
+	if w.Header().Get(HeaderRequestID) != id {
+		t.Errorf("X-Request-ID = %q", w.Header().Get(HeaderRequestID))
+	}
+
+	tests := []struct {
+		lastEventID string
+		want        int
+	}{
+		{"req-1:0", http.StatusNotFound},
+		{"req-2:0", http.StatusNotFound},
+		{id, http.StatusBadRequest},
+		{id + ":7", http.StatusGone},
+	}
+	for _, tt := range tests {
+		if w := serve(http.MethodPost, tt.lastEventID); w.Code != tt.want {
+			t.Errorf("Last-Event-ID %q: status %d, want %d", tt.lastEventID, w.Code, tt.want)
+		}
+	}
+}
+
+// waitingBackend's streaming tool runs until its context is done and
+// reports the cause
+type waitingBackend struct {
+	mockBackend
+	started chan struct{}
+	cause   chan error
+}
+
+func (b *waitingBackend) CallStreamingTool(ctx context.Context, name string, args map[string]interface{}, emit backend.StreamingEmitter) error {
+	close(b.started)
+	<-ctx.Done()
+	b.cause <- context.Cause(ctx)
+	return ctx.Err()
+}
+
+func TestSSEHandler_Heartbeat(t *testing.T) {
+	executor := engine.NewExecutor(engine.DefaultExecutorConfig(), nil)
+	mb := &mockBackend{Tools: map[string]backend.ToolDefinition{"tool1": {Name: "tool1", Streaming: true}}}
+	slow := &slowBackend{mockBackend: *mb, delay: 60 * time.Millisecond}
+	h := NewSSEHandler(executor, slow, nil, time.Second)
+	h.SetHeartbeat(10 * time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodPost, "/stream?tool=tool1", strings.NewReader("{}"))
+	w := &flushingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, ": ping\n\n") || !strings.Contains(body, "event: end") {
+		t.Errorf("idle stream without heartbeats:\n%s", body)
+	}
+
+	h.SetHeartbeat(-1)
+	w = &flushingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/stream?tool=tool1", strings.NewReader("{}")))
+	if strings.Contains(w.Body.String(), ": ping") {
+		t.Errorf("heartbeats sent while disabled:\n%s", w.Body.String())
+	}
+}
+
+// slowBackend's streaming tool emits one event after a delay
+type slowBackend struct {
+	mockBackend
+	delay time.Duration
+}
+
+func (b *slowBackend) CallStreamingTool(ctx context.Context, name string, args map[string]interface{}, emit backend.StreamingEmitter) error {
+	time.Sleep(b.delay)
+	emit.EmitData("done")
+	return nil
+}
+
+func TestSSEHandler_ClientDisconnect(t *testing.T) {
+	executor := engine.NewExecutor(engine.DefaultExecutorConfig(), nil)
+	b := &waitingBackend{
+		mockBackend: mockBackend{Tools: map[string]backend.ToolDefinition{"tool1": {Name: "tool1", Streaming: true}}},
+		started:     make(chan struct{}),
+		cause:       make(chan error, 1),
+	}
+	h := NewSSEHandler(executor, b, nil, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodPost, "/stream?tool=tool1", strings.NewReader("{}")).WithContext(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(&flushingRecorder{ResponseRecorder: httptest.NewRecorder()}, req)
+	}()
+
+	<-b.started
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler still streaming after the client left")
+	}
+	select {
+	case cause := <-b.cause:
+		if !errors.Is(cause, ErrClientDisconnected) {
+			t.Errorf("tool context cause = %v", cause)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tool still running after the client left")
+	}
+}
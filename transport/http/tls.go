@@ -0,0 +1,284 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Client certificate policies of TLSConfig.ClientAuth
+const (
+	// ClientAuthRequire rejects clients without a certificate signed by
+	// the client CA (default with a client CA)
+	ClientAuthRequire = "require"
+
+	// ClientAuthVerifyIfGiven verifies certificates clients present but
+	// lets clients without one through, to authenticate otherwise
+	ClientAuthVerifyIfGiven = "verify_if_given"
+)
+
+// certCheckInterval is how often the certificate files are checked for
+// renewal
+const certCheckInterval = 30 * time.Second
+
+// TLSConfig serves the HTTP transport over HTTPS, with HTTP/2
+type TLSConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// CertFile and KeyFile hold the PEM certificate chain and key; they
+	// are reloaded when renewed on disk
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+
+	// ClientCAFile enables mTLS: the PEM CAs client certificates must be
+	// signed by
+	ClientCAFile string `yaml:"client_ca_file"`
+
+	// ClientAuth is ClientAuthRequire or ClientAuthVerifyIfGiven
+	ClientAuth string `yaml:"client_auth"`
+
+	// MinVersion is "1.2" (default) or "1.3"
+	MinVersion string `yaml:"min_version"`
+
+	// CipherSuites restricts the TLS 1.2 cipher suites, by Go name
+	// (e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256); TLS 1.3 suites are
+	// not configurable
+	CipherSuites []string `yaml:"cipher_suites"`
+
+	// ACME obtains and renews certificates automatically (Let's Encrypt)
+	// instead of CertFile and KeyFile
+	ACME ACMEConfig `yaml:"acme"`
+}
+
+// ACMEConfig obtains certificates from an ACME certificate authority
+type ACMEConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Domains are the host names certificates are requested for
+	Domains []string `yaml:"domains"`
+
+	// Email is the account contact for expiry notices
+	Email string `yaml:"email"`
+
+	// CacheDir keeps the account key and certificates across restarts
+	// (the framework defaults it to "acme" in the state directory)
+	CacheDir string `yaml:"cache_dir"`
+
+	// DirectoryURL is the CA's directory (default: Let's Encrypt
+	// production; use the staging directory while testing)
+	DirectoryURL string `yaml:"directory_url"`
+
+	// HTTPAddress, e.g. ":80", answers HTTP-01 challenges and redirects
+	// other requests to HTTPS; without it only TLS-ALPN-01 challenges
+	// on the HTTPS port are answered
+	HTTPAddress string `yaml:"http_address"`
+}
+
+// Validate validates the configuration
+func (c TLSConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	hasFiles := c.CertFile != "" || c.KeyFile != ""
+	switch {
+	case c.ACME.Enabled && hasFiles:
+		return fmt.Errorf("tls: set either cert_file and key_file or acme, not both")
+	case c.ACME.Enabled:
+		if len(c.ACME.Domains) == 0 {
+			return fmt.Errorf("tls: acme requires domains")
+		}
+	case c.CertFile == "" || c.KeyFile == "":
+		return fmt.Errorf("tls: cert_file and key_file are required")
+	}
+	switch c.ClientAuth {
+	case "", ClientAuthRequire, ClientAuthVerifyIfGiven:
+	default:
+		return fmt.Errorf("tls: unknown client_auth %q (want require or verify_if_given)", c.ClientAuth)
+	}
+	if c.ClientAuth != "" && c.ClientCAFile == "" {
+		return fmt.Errorf("tls: client_auth requires a client_ca_file")
+	}
+	if _, err := tlsVersion(c.MinVersion); err != nil {
+		return err
+	}
+	if _, err := cipherSuites(c.CipherSuites); err != nil {
+		return err
+	}
+	return nil
+}
+
+// serverTLS is the TLS setup of a running transport
+type serverTLS struct {
+	config *tls.Config
+
+	// acme answers HTTP-01 challenges, when enabled
+	acme *autocert.Manager
+}
+
+// newServerTLS loads the certificates and builds the server's TLS config
+func newServerTLS(c TLSConfig, logger *slog.Logger) (*serverTLS, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	minVersion, _ := tlsVersion(c.MinVersion)
+	suites, _ := cipherSuites(c.CipherSuites)
+	config := &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: suites,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	s := &serverTLS{config: config}
+
+	if c.ACME.Enabled {
+		if c.ACME.CacheDir == "" {
+			return nil, fmt.Errorf("tls: acme requires a cache_dir")
+		}
+		s.acme = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(c.ACME.Domains...),
+			Cache:      autocert.DirCache(c.ACME.CacheDir),
+			Email:      c.ACME.Email,
+		}
+		if c.ACME.DirectoryURL != "" {
+			s.acme.Client = &acme.Client{DirectoryURL: c.ACME.DirectoryURL}
+		}
+		config.GetCertificate = s.acme.GetCertificate
+		config.NextProtos = append(config.NextProtos, acme.ALPNProto)
+	} else {
+		certs, err := newCertReloader(c.CertFile, c.KeyFile, logger)
+		if err != nil {
+			return nil, err
+		}
+		config.GetCertificate = certs.getCertificate
+	}
+
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: %s holds no PEM certificates", c.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		if c.ClientAuth == ClientAuthVerifyIfGiven {
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+	return s, nil
+}
+
+// serveChallenges answers ACME HTTP-01 challenges on address until ctx
+// is done, redirecting other requests to HTTPS
+func (s *serverTLS) serveChallenges(ctx context.Context, address string, logger *slog.Logger) {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           s.acme.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	logger.Info("ACME challenge listener started", "address", address)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("ACME challenge listener failed", "error", err)
+	}
+}
+
+// certReloader serves a certificate from files, reloading it when the
+// files change, so renewed certificates apply without a restart
+type certReloader struct {
+	certFile, keyFile string
+	logger            *slog.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(certFile, keyFile string, logger *slog.Logger) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// load reads the key pair
+func (r *certReloader) load() error {
+	info, err := os.Stat(r.certFile)
+	if err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+	r.cert, r.modTime = &cert, info.ModTime()
+	return nil
+}
+
+// getCertificate implements tls.Config.GetCertificate
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := time.Now(); now.Sub(r.checkedAt) >= certCheckInterval {
+		r.checkedAt = now
+		if info, err := os.Stat(r.certFile); err == nil && !info.ModTime().Equal(r.modTime) {
+			// Keep serving the old certificate if the new one is incomplete
+			if err := r.load(); err != nil {
+				r.logger.Error("failed to reload TLS certificate", "error", err)
+			} else {
+				r.logger.Info("TLS certificate reloaded", "cert_file", r.certFile)
+			}
+		}
+	}
+	return r.cert, nil
+}
+
+// tlsVersion parses a MinVersion
+func tlsVersion(v string) (uint16, error) {
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("tls: unsupported min_version %q (want 1.2 or 1.3)", v)
+}
+
+// cipherSuites resolves cipher suite names; insecure suites are refused
+func cipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("tls: unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
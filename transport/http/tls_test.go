@@ -0,0 +1,179 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCert is a certificate issued by a test CA, or the CA itself
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// issueCert creates a certificate signed by parent (a CA, if nil) and
+// writes it and its key as PEM files in dir
+func issueCert(t *testing.T, dir, name string, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.ExtKeyUsage = nil
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	os.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return c
+}
+
+// runTLS serves the transport over TLS on a local port until the test ends
+func runTLS(t *testing.T, config TLSConfig) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht := NewHTTPTransport(&mockHandler{}, HTTPConfig{TLS: config}, nil, &mockBackend{}, nil)
+	ht.SetListener(ln)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ht.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return "https://" + ln.Addr().String()
+}
+
+func tlsClient(ca *testCert, client *testCert) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	config := &tls.Config{RootCAs: roots}
+	if client != nil {
+		pair, _ := tls.LoadX509KeyPair(client.certFile, client.keyFile)
+		config.Certificates = []tls.Certificate{pair}
+	}
+	return &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: config, ForceAttemptHTTP2: true},
+	}
+}
+
+func TestHTTPTransport_TLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issueCert(t, dir, "ca", nil, x509.ExtKeyUsageServerAuth)
+	server := issueCert(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+
+	url := runTLS(t, TLSConfig{Enabled: true, CertFile: server.certFile, KeyFile: server.keyFile})
+
+	resp, err := tlsClient(ca, nil).Get(url + PathHealth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+	if resp.ProtoMajor != 2 {
+		t.Errorf("protocol = %s, want HTTP/2", resp.Proto)
+	}
+}
+
+func TestHTTPTransport_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issueCert(t, dir, "ca", nil, x509.ExtKeyUsageServerAuth)
+	server := issueCert(t, dir, "server", ca, x509.ExtKeyUsageServerAuth)
+	client := issueCert(t, dir, "client", ca, x509.ExtKeyUsageClientAuth)
+
+	url := runTLS(t, TLSConfig{
+		Enabled:      true,
+		CertFile:     server.certFile,
+		KeyFile:      server.keyFile,
+		ClientCAFile: ca.certFile,
+		MinVersion:   "1.3",
+	})
+
+	if resp, err := tlsClient(ca, nil).Get(url + PathHealth); err == nil {
+		resp.Body.Close()
+		t.Fatal("request without a client certificate succeeded")
+	}
+
+	resp, err := tlsClient(ca, client).Get(url + PathHealth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d", resp.StatusCode)
+	}
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TLSConfig
+		wantErr bool
+	}{
+		{"disabled", TLSConfig{}, false},
+		{"files", TLSConfig{Enabled: true, CertFile: "c", KeyFile: "k"}, false},
+		{"missing key", TLSConfig{Enabled: true, CertFile: "c"}, true},
+		{"acme", TLSConfig{Enabled: true, ACME: ACMEConfig{Enabled: true, Domains: []string{"a.example"}}}, false},
+		{"acme without domains", TLSConfig{Enabled: true, ACME: ACMEConfig{Enabled: true}}, true},
+		{"files and acme", TLSConfig{Enabled: true, CertFile: "c", KeyFile: "k", ACME: ACMEConfig{Enabled: true, Domains: []string{"a"}}}, true},
+		{"min version", TLSConfig{Enabled: true, CertFile: "c", KeyFile: "k", MinVersion: "1.1"}, true},
+		{"cipher suite", TLSConfig{Enabled: true, CertFile: "c", KeyFile: "k", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}}, false},
+		{"insecure cipher suite", TLSConfig{Enabled: true, CertFile: "c", KeyFile: "k", CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}, true},
+		{"client auth without CA", TLSConfig{Enabled: true, CertFile: "c", KeyFile: "k", ClientAuth: ClientAuthRequire}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package stdio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Message framings
+const (
+	// FramingAuto detects the framing from the client's first message
+	// and answers in kind (default)
+	FramingAuto = "auto"
+
+	// FramingNewline sends one JSON message per line
+	FramingNewline = "newline"
+
+	// FramingContentLength precedes each message with LSP-style headers:
+	// "Content-Length: <n>\r\n\r\n"
+	FramingContentLength = "content-length"
+)
+
+// Defaults of Config
+const (
+	DefaultMaxConcurrent  = 8
+	DefaultMaxMessageSize = 10 * 1024 * 1024
+)
+
+// Config configures the stdio transport
+type Config struct {
+	// Framing is FramingAuto, FramingNewline or FramingContentLength
+	Framing string `yaml:"framing"`
+
+	// MaxConcurrent is how many requests are handled at a time (1: one
+	// after another)
+	MaxConcurrent int `yaml:"max_concurrent"`
+
+	// MaxMessageSize bounds each incoming message in bytes; larger ones
+	// are discarded and answered with an Invalid Request error
+	MaxMessageSize int `yaml:"max_message_size"`
+}
+
+// DefaultConfig returns the default stdio configuration
+func DefaultConfig() Config {
+	return Config{
+		Framing:        FramingAuto,
+		MaxConcurrent:  DefaultMaxConcurrent,
+		MaxMessageSize: DefaultMaxMessageSize,
+	}
+}
+
+// Validate validates the configuration
+func (c Config) Validate() error {
+	switch c.Framing {
+	case "", FramingAuto, FramingNewline, FramingContentLength:
+	default:
+		return fmt.Errorf("unknown stdio framing %q (want auto, newline or content-length)", c.Framing)
+	}
+	if c.MaxConcurrent < 0 || c.MaxMessageSize < 0 {
+		return fmt.Errorf("stdio max concurrent and max message size must not be negative")
+	}
+	return nil
+}
+
+func (c Config) framing() string {
+	if c.Framing == "" {
+		return FramingAuto
+	}
+	return c.Framing
+}
+
+func (c Config) maxConcurrent() int {
+	return max(c.MaxConcurrent, 1)
+}
+
+func (c Config) maxMessageSize() int {
+	if c.MaxMessageSize <= 0 {
+		return DefaultMaxMessageSize
+	}
+	return c.MaxMessageSize
+}
+
+// errMessageTooLarge is returned by readMessage after discarding a
+// message over the size limit
+var errMessageTooLarge = errors.New("message too large")
+
+// contentLengthPrefix starts the headers of a Content-Length framed message
+const contentLengthPrefix = "content-length:"
+
+// maxHeaderLine bounds each header line of a Content-Length framed
+// message; MaxMessageSize only bounds the body
+const maxHeaderLine = 4096
+
+// readMessage reads the next message in the transport's framing,
+// detecting it first under FramingAuto
+func (t *StdioTransport) readMessage() ([]byte, error) {
+	if t.framing == "" {
+		framing, err := t.detectFraming()
+		if err != nil {
+			return nil, err
+		}
+		t.setFraming(framing)
+		t.logger.Debug("stdio framing detected", "framing", framing)
+	}
+	if t.framing == FramingContentLength {
+		return t.readContentLength()
+	}
+	return t.readLine()
+}
+
+// setFraming sets the framing responses and notifications are written in
+func (t *StdioTransport) setFraming(framing string) {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	t.framing = framing
+}
+
+// detectFraming peeks at the first bytes the client sends: LSP-style
+// headers mean Content-Length framing, anything else newlines
+func (t *StdioTransport) detectFraming() (string, error) {
+	for {
+		b, err := t.reader.Peek(1)
+		if err != nil {
+			return "", err
+		}
+		if b[0] != '\r' && b[0] != '\n' && b[0] != ' ' && b[0] != '\t' {
+			break
+		}
+		t.reader.ReadByte()
+	}
+	head, err := t.reader.Peek(len(contentLengthPrefix))
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if strings.EqualFold(string(head), contentLengthPrefix) {
+		return FramingContentLength, nil
+	}
+	return FramingNewline, nil
+}
+
+// readLine reads a newline-delimited message, discarding it whole if it
+// is over the size limit
+func (t *StdioTransport) readLine() ([]byte, error) {
+	limit := t.config.maxMessageSize()
+	var line []byte
+	tooLarge := false
+	for {
+		chunk, err := t.reader.ReadSlice('\n')
+		if !tooLarge {
+			if len(line)+len(chunk) > limit+1 { // +1: the newline
+				tooLarge, line = true, nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		switch {
+		case err == nil:
+			if tooLarge {
+				return nil, errMessageTooLarge
+			}
+			return line, nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err == io.EOF && len(line) > 0 && !tooLarge:
+			// A last message without a newline
+			return line, nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+// readContentLength reads a message framed by LSP-style headers
+func (t *StdioTransport) readContentLength() ([]byte, error) {
+	length := -1
+	for {
+		header, err := t.readHeaderLine()
+		if err != nil {
+			return nil, err
+		}
+		if header == "" {
+			if length >= 0 {
+				break
+			}
+			continue // blank lines between messages
+		}
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return nil, fmt.Errorf("malformed header %q", header)
+		}
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || length < 0 {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+
+	if length > t.config.maxMessageSize() {
+		if _, err := t.reader.Discard(length); err != nil {
+			return nil, err
+		}
+		return nil, errMessageTooLarge
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(t.reader, message); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	return message, nil
+}
+
+// readHeaderLine reads one header line without its line ending, failing
+// once it is longer than maxHeaderLine
+func (t *StdioTransport) readHeaderLine() (string, error) {
+	var line []byte
+	for {
+		chunk, err := t.reader.ReadSlice('\n')
+		if len(line)+len(chunk) > maxHeaderLine+2 { // +2: the "\r\n"
+			return "", fmt.Errorf("header line longer than %d bytes", maxHeaderLine)
+		}
+		line = append(line, chunk...)
+		switch {
+		case err == nil:
+			return strings.TrimRight(string(line), "\r\n"), nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		default:
+			return "", err
+		}
+	}
+}
+
+// writeMessage writes one message to stdout in the transport's framing
+func (t *StdioTransport) writeMessage(message []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	message = bytes.TrimRight(message, "\r\n")
+	if t.framing == FramingContentLength {
+		if _, err := fmt.Fprintf(t.writer, "Content-Length: %d\r\n\r\n", len(message)); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+		if _, err := t.writer.Write(message); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+	} else {
+		if _, err := t.writer.Write(message); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+		if err := t.writer.WriteByte('\n'); err != nil {
+			return fmt.Errorf("write error: %w", err)
+		}
+	}
+
+	if err := t.writer.Flush(); err != nil {
+		return fmt.Errorf("flush error: %w", err)
+	}
+
+	return nil
+}
@@ -2,13 +2,22 @@ package stdio
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"sync"
 
+	"github.com/SaherElMasry/go-mcp-framework/observability"
+	"github.com/SaherElMasry/go-mcp-framework/protocol"
+	"github.com/SaherElMasry/go-mcp-framework/ratelimit"
 	"github.com/SaherElMasry/go-mcp-framework/transport"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // StdioTransport implements MCP over standard input/output
@@ -17,6 +26,16 @@ type StdioTransport struct {
 	logger  *slog.Logger
 	reader  *bufio.Reader
 	writer  *bufio.Writer
+	config  Config
+
+	// writeMu serializes responses and notifications on stdout
+	writeMu sync.Mutex
+
+	// framing is the framing in use, detected from the first message
+	// under FramingAuto; set under writeMu
+	framing string
+
+	broadcaster *transport.Broadcaster
 }
 
 // NewStdioTransport creates a new stdio transport
@@ -30,12 +49,46 @@ func NewStdioTransport(handler transport.Handler, logger *slog.Logger) *StdioTra
 		logger:  logger,
 		reader:  bufio.NewReader(os.Stdin),
 		writer:  bufio.NewWriter(os.Stdout),
+		config:  DefaultConfig(),
 	}
 }
 
+// SetConfig sets the framing, concurrency and message size limit
+// Must be called before Run.
+func (t *StdioTransport) SetConfig(config Config) {
+	t.config = config
+}
+
+// SetBroadcaster delivers server-initiated notifications to the client
+// while Run serves it
+func (t *StdioTransport) SetBroadcaster(b *transport.Broadcaster) {
+	t.broadcaster = b
+}
+
 // Run starts the stdio transport loop
+// Requests are handled up to Config.MaxConcurrent at a time; responses
+// are written in the order the requests arrived. Notifications are
+// written as soon as they are sent.
 func (t *StdioTransport) Run(ctx context.Context) error {
-	t.logger.Info("stdio transport started")
+	t.logger.Info("stdio transport started",
+		"framing", t.config.framing(),
+		"max_concurrent", t.config.maxConcurrent())
+
+	// A stdio transport serves exactly one client
+	ctx = ratelimit.WithClientID(ctx, "stdio")
+	ctx = transport.WithNotifier(ctx, transport.NotifierFunc(t.writeMessage))
+
+	if t.broadcaster != nil {
+		unsubscribe := t.broadcaster.Subscribe(transport.NotifierFunc(t.writeMessage))
+		defer unsubscribe()
+	}
+	if t.config.framing() != FramingAuto {
+		t.setFraming(t.config.framing())
+	}
+
+	responses := newResponseQueue(t.writeMessage)
+	defer responses.close()
+	slots := make(chan struct{}, t.config.maxConcurrent())
 
 	for {
 		select {
@@ -44,42 +97,160 @@ func (t *StdioTransport) Run(ctx context.Context) error {
 			return ctx.Err()
 		default:
 		}
+		if err := responses.err(); err != nil {
+			return err
+		}
 
-		line, err := t.reader.ReadBytes('\n')
+		message, err := t.readMessage()
+		if errors.Is(err, errMessageTooLarge) {
+			t.logger.Warn("message too large", "max_size", t.config.maxMessageSize())
+			responses.push(func() []byte { return tooLargeResponse(t.config.maxMessageSize()) })
+			continue
+		}
 		if err != nil {
 			if err == io.EOF {
 				t.logger.Info("client disconnected")
-				return nil
+				return responses.drain()
 			}
 			t.logger.Error("read error", "error", err)
 			return fmt.Errorf("read error: %w", err)
 		}
+		if len(bytes.TrimSpace(message)) == 0 {
+			continue
+		}
 
-		if len(line) == 0 || (len(line) == 1 && line[0] == '\n') {
+		t.logger.Debug("received message", "size", len(message))
+
+		if cap(slots) == 1 {
+			// One at a time: handle inline, as the client sent them
+			response := t.receive(ctx, message)
+			responses.push(func() []byte { return response })
+			if err := responses.drain(); err != nil {
+				return err
+			}
 			continue
 		}
 
-		t.logger.Debug("received message", "size", len(line))
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			t.logger.Info("stdio transport shutting down")
+			return ctx.Err()
+		}
+		result := make(chan []byte, 1)
+		responses.push(func() []byte { return <-result })
+		go func() {
+			defer func() { <-slots }()
+			result <- t.receive(ctx, message)
+		}()
+	}
+}
 
-		response, err := t.handler.Handle(ctx, line, "stdio")
-		if err != nil {
-			t.logger.Error("handler error", "error", err)
+// receive handles one message inside a transport span, continuing the
+// client's trace from params._meta, and returns the response (nil for
+// notifications)
+func (t *StdioTransport) receive(ctx context.Context, line []byte) []byte {
+	if bytes.Contains(line, []byte("traceparent")) {
+		var msg struct {
+			Params struct {
+				Meta map[string]interface{} `json:"_meta"`
+			} `json:"params"`
+		}
+		if json.Unmarshal(line, &msg) == nil {
+			ctx = observability.ExtractMeta(ctx, msg.Params.Meta)
 		}
+	}
+	ctx, span := observability.StartSpan(ctx, "transport.receive",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("mcp.transport", "stdio"),
+			attribute.Int("mcp.message_size", len(line)),
+		))
+	defer span.End()
 
-		if len(response) > 0 {
-			if _, err := t.writer.Write(response); err != nil {
-				return fmt.Errorf("write error: %w", err)
-			}
+	response, err := t.handler.Handle(ctx, line, "stdio")
+	if err != nil {
+		t.logger.Error("handler error", "error", err)
+	}
+	return response
+}
 
-			if err := t.writer.WriteByte('\n'); err != nil {
-				return fmt.Errorf("write error: %w", err)
-			}
+// tooLargeResponse is the error sent for a message over the size limit;
+// its ID is unknown, since the message wasn't read
+func tooLargeResponse(maxSize int) []byte {
+	response, _ := json.Marshal(protocol.Response{
+		JSONRPC: "2.0",
+		Error:   protocol.NewInvalidRequest(fmt.Sprintf("message exceeds %d bytes", maxSize)),
+	})
+	return response
+}
 
-			if err := t.writer.Flush(); err != nil {
-				return fmt.Errorf("flush error: %w", err)
-			}
+// ============================================================
+// Ordered Responses
+// ============================================================
+
+// responseQueue writes responses in the order their requests arrived,
+// whenever each becomes available
+type responseQueue struct {
+	pending chan func() []byte
+	done    chan struct{}
+	write   func([]byte) error
 
-			t.logger.Debug("sent response", "size", len(response))
+	mu       sync.Mutex
+	writeErr error
+}
+
+// newResponseQueue starts writing queued responses with write
+func newResponseQueue(write func([]byte) error) *responseQueue {
+	q := &responseQueue{
+		pending: make(chan func() []byte, 1024),
+		done:    make(chan struct{}),
+		write:   write,
+	}
+	go q.run()
+	return q
+}
+
+// push queues a response, which is written once the earlier ones are
+func (q *responseQueue) push(response func() []byte) {
+	q.pending <- response
+}
+
+func (q *responseQueue) run() {
+	defer close(q.done)
+	for response := range q.pending {
+		message := response()
+		if len(message) == 0 || q.err() != nil {
+			continue
+		}
+		if err := q.write(message); err != nil {
+			q.mu.Lock()
+			q.writeErr = err
+			q.mu.Unlock()
 		}
 	}
 }
+
+// err returns the first write error
+func (q *responseQueue) err() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.writeErr
+}
+
+// drain waits for every queued response to be written
+func (q *responseQueue) drain() error {
+	wait := make(chan struct{})
+	q.push(func() []byte {
+		close(wait)
+		return nil
+	})
+	<-wait
+	return q.err()
+}
+
+// close stops the queue once the queued responses are written
+func (q *responseQueue) close() {
+	close(q.pending)
+	<-q.done
+}
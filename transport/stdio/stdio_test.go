@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"strings"
 	"testing"
@@ -146,3 +148,101 @@ func TestStdioTransport_Run_EmptyLines(t *testing.T) {
 		t.Error("Handler should not have been called for empty lines")
 	}
 }
+
+// echoHandler answers each request with its ID after a delay set by the
+// request, so later requests can finish first
+type echoHandler struct{}
+
+func (echoHandler) Handle(ctx context.Context, requestBytes []byte, transport string) ([]byte, error) {
+	var req struct {
+		ID    int `json:"id"`
+		Delay int `json:"delay"`
+	}
+	if err := json.Unmarshal(requestBytes, &req); err != nil {
+		return nil, err
+	}
+	time.Sleep(time.Duration(req.Delay) * time.Millisecond)
+	return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"ok"}`, req.ID)), nil
+}
+
+func newTestTransport(input string, config Config) (*StdioTransport, *bytes.Buffer) {
+	output := &bytes.Buffer{}
+	return &StdioTransport{
+		handler: echoHandler{},
+		logger:  slog.Default(),
+		reader:  bufio.NewReader(strings.NewReader(input)),
+		writer:  bufio.NewWriter(output),
+		config:  config,
+	}, output
+}
+
+func TestStdioTransport_OrderedConcurrentResponses(t *testing.T) {
+	input := `{"id":1,"delay":60}` + "\n" + `{"id":2,"delay":0}` + "\n" + `{"id":3,"delay":20}` + "\n"
+	tr, output := newTestTransport(input, DefaultConfig())
+
+	start := time.Now()
+	if err := tr.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Handled at the same time, answered in order
+	if elapsed := time.Since(start); elapsed > 75*time.Millisecond {
+		t.Errorf("requests handled one after another (%v)", elapsed)
+	}
+	want := `{"jsonrpc":"2.0","id":1,"result":"ok"}` + "\n" +
+		`{"jsonrpc":"2.0","id":2,"result":"ok"}` + "\n" +
+		`{"jsonrpc":"2.0","id":3,"result":"ok"}` + "\n"
+	if output.String() != want {
+		t.Errorf("output = %q, want %q", output.String(), want)
+	}
+}
+
+func TestStdioTransport_ContentLengthFraming(t *testing.T) {
+	frame := func(body string) string {
+		return fmt.Sprintf("Content-Length: %d\r\nContent-Type: application/json\r\n\r\n%s", len(body), body)
+	}
+	tr, output := newTestTransport(frame(`{"id":1}`)+frame(`{"id":2}`), DefaultConfig())
+	if err := tr.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Content-Length: 38\r\n\r\n" + `{"jsonrpc":"2.0","id":1,"result":"ok"}` +
+		"Content-Length: 38\r\n\r\n" + `{"jsonrpc":"2.0","id":2,"result":"ok"}`
+	if output.String() != want {
+		t.Errorf("output = %q, want %q", output.String(), want)
+	}
+}
+
+func TestStdioTransport_ContentLengthHeaderTooLong(t *testing.T) {
+	// An unterminated header line must not be buffered without bound
+	input := "Content-Length: 8\r\nX-Padding: " + strings.Repeat("x", 1<<20)
+	tr, _ := newTestTransport(input, DefaultConfig())
+	err := tr.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "header line longer than") {
+		t.Errorf("Run() error = %v, want a header line error", err)
+	}
+}
+
+func TestStdioTransport_MaxMessageSize(t *testing.T) {
+	config := DefaultConfig()
+	config.MaxMessageSize = 32
+	input := `{"id":1,"padding":"` + strings.Repeat("x", 64) + `"}` + "\n" + `{"id":2}` + "\n"
+	tr, output := newTestTransport(input, config)
+	if err := tr.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"code":-32600`) || !strings.Contains(lines[0], `"id":null`) ||
+		lines[1] != `{"jsonrpc":"2.0","id":2,"result":"ok"}` {
+		t.Errorf("output = %q", output.String())
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Error(err)
+	}
+	if err := (Config{Framing: "xml"}).Validate(); err == nil {
+		t.Error("unknown framing accepted")
+	}
+}
@@ -1,6 +1,9 @@
 package transport
 
-import "context"
+import (
+	"context"
+	"sync"
+)
 
 // Transport represents a network transport for MCP
 type Transport interface {
@@ -11,3 +14,116 @@ type Transport interface {
 type Handler interface {
 	Handle(ctx context.Context, requestBytes []byte, transport string) ([]byte, error)
 }
+
+// Notifier sends server-initiated JSON-RPC messages to the client of the
+// request being handled (e.g. notifications/progress)
+type Notifier interface {
+	Notify(message []byte) error
+}
+
+// NotifierFunc adapts a function to Notifier
+type NotifierFunc func(message []byte) error
+
+// Notify implements Notifier
+func (f NotifierFunc) Notify(message []byte) error {
+	return f(message)
+}
+
+type notifierKey struct{}
+
+// WithNotifier attaches the request's notification channel to ctx
+// Transports call it before passing a request to the Handler.
+func WithNotifier(ctx context.Context, n Notifier) context.Context {
+	return context.WithValue(ctx, notifierKey{}, n)
+}
+
+// NotifierFromContext returns the notification channel of the current request
+func NotifierFromContext(ctx context.Context) (Notifier, bool) {
+	n, ok := ctx.Value(notifierKey{}).(Notifier)
+	return n, ok && n != nil
+}
+
+// Broadcaster delivers server-initiated notifications (e.g.
+// notifications/tools/list_changed) to every connected client
+// Transports subscribe each client connection able to receive them,
+// under the client's session when it has one, so that notifications
+// meant for one client (e.g. notifications/resources/updated) can be sent
+// to it alone.
+type Broadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[int]subscriber
+	next        int
+}
+
+// subscriber is a client connection and its session ID ("" without one)
+type subscriber struct {
+	notifier Notifier
+	session  string
+}
+
+// NewBroadcaster creates a broadcaster with no subscribers
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subscribers: make(map[int]subscriber)}
+}
+
+// Subscribe adds a client; call the returned function when it disconnects
+func (b *Broadcaster) Subscribe(n Notifier) (unsubscribe func()) {
+	return b.SubscribeSession("", n)
+}
+
+// SubscribeSession adds a client of a session (see Send); call the
+// returned function when it disconnects
+func (b *Broadcaster) SubscribeSession(sessionID string, n Notifier) (unsubscribe func()) {
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.subscribers[id] = subscriber{notifier: n, session: sessionID}
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		delete(b.subscribers, id)
+		b.mu.Unlock()
+	}
+}
+
+// Broadcast sends message to every subscriber and returns how many
+// received it
+func (b *Broadcaster) Broadcast(message []byte) int {
+	return b.deliver(message, func(subscriber) bool { return true })
+}
+
+// Send sends message to the subscribers of a session and returns how
+// many received it
+// Clients without sessions (stdio, HTTP without session management)
+// share the session "", and so receive each other's messages.
+func (b *Broadcaster) Send(sessionID string, message []byte) int {
+	return b.deliver(message, func(s subscriber) bool { return s.session == sessionID })
+}
+
+// deliver sends message to the subscribers selected by match
+func (b *Broadcaster) deliver(message []byte, match func(subscriber) bool) int {
+	b.mu.RLock()
+	notifiers := make([]Notifier, 0, len(b.subscribers))
+	for _, s := range b.subscribers {
+		if match(s) {
+			notifiers = append(notifiers, s.notifier)
+		}
+	}
+	b.mu.RUnlock()
+
+	delivered := 0
+	for _, n := range notifiers {
+		if n.Notify(message) == nil {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// Subscribers returns the number of connected clients
+func (b *Broadcaster) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
@@ -0,0 +1,167 @@
+// Package txn undoes the partial effects of multi-step tool handlers
+//
+// A handler registers a compensating action as it makes each change; if it
+// fails, panics or its context is cancelled midway, the compensations run
+// in reverse order, so a copy-then-delete sequence doesn't leave
+// half-applied state behind.
+//
+// Example:
+//
+//	b.RegisterTool(def, txn.Wrap(func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+//	    if err := os.Mkdir(dst, 0o755); err != nil {
+//	        return nil, err
+//	    }
+//	    txn.OnRollback(ctx, func(ctx context.Context) error { return os.RemoveAll(dst) })
+//
+//	    if err := copyTree(ctx, src, dst); err != nil {
+//	        return nil, err // dst is removed
+//	    }
+//	    return nil, os.RemoveAll(src)
+//	}))
+package txn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SaherElMasry/go-mcp-framework/backend"
+)
+
+// RollbackTimeout bounds the compensations of a failed transaction
+// They run on a context detached from the cancelled call's.
+var RollbackTimeout = 30 * time.Second
+
+// Compensation undoes one change
+type Compensation func(ctx context.Context) error
+
+// Txn collects compensations in the order changes are made
+type Txn struct {
+	mu            sync.Mutex
+	compensations []Compensation
+}
+
+// New creates an empty transaction
+func New() *Txn {
+	return &Txn{}
+}
+
+// OnRollback registers a compensation for a change just made
+func (t *Txn) OnRollback(fn Compensation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.compensations = append(t.compensations, fn)
+}
+
+// Commit discards the compensations; the changes are kept
+func (t *Txn) Commit() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.compensations = nil
+}
+
+// Rollback runs the compensations in reverse order
+// Every compensation runs even if an earlier one fails; their errors are
+// joined. A transaction rolls back at most once.
+func (t *Txn) Rollback(ctx context.Context) error {
+	t.mu.Lock()
+	compensations := t.compensations
+	t.compensations = nil
+	t.mu.Unlock()
+
+	var errs []error
+	for i := len(compensations) - 1; i >= 0; i-- {
+		if err := runCompensation(ctx, compensations[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runCompensation runs fn, turning a panic into an error
+func runCompensation(ctx context.Context, fn Compensation) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("compensation panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+type txnKey struct{}
+
+// WithTxn attaches t to ctx
+func WithTxn(ctx context.Context, t *Txn) context.Context {
+	return context.WithValue(ctx, txnKey{}, t)
+}
+
+// FromContext returns the transaction attached to ctx, if any
+func FromContext(ctx context.Context) (*Txn, bool) {
+	t, ok := ctx.Value(txnKey{}).(*Txn)
+	return t, ok
+}
+
+// OnRollback registers a compensation with the transaction in ctx
+// It reports false, registering nothing, when ctx has no transaction.
+func OnRollback(ctx context.Context, fn Compensation) bool {
+	t, ok := FromContext(ctx)
+	if !ok {
+		return false
+	}
+	t.OnRollback(fn)
+	return true
+}
+
+// Run calls fn in a new transaction
+// If fn returns an error, panics or ctx is done when it returns, the
+// compensations fn registered run and their errors are joined to the
+// returned error; a panic is re-raised after the rollback.
+func Run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	t := New()
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.rollback(ctx)
+			panic(r)
+		}
+	}()
+
+	err = fn(WithTxn(ctx, t))
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		t.Commit()
+		return nil
+	}
+
+	if rollbackErr := t.rollback(ctx); rollbackErr != nil {
+		return errors.Join(err, fmt.Errorf("rollback: %w", rollbackErr))
+	}
+	return err
+}
+
+// rollback rolls back on a context that outlives the call's cancellation
+func (t *Txn) rollback(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), RollbackTimeout)
+	defer cancel()
+	return t.Rollback(ctx)
+}
+
+// Wrap runs each call of handler in a transaction (see Run)
+func Wrap(handler backend.ToolHandler) backend.ToolHandler {
+	return func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		var result interface{}
+		err := Run(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = handler(ctx, args)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,124 @@
+package txn
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRun_RollsBackInReverse(t *testing.T) {
+	var undone []string
+	undo := func(name string) Compensation {
+		return func(ctx context.Context) error {
+			undone = append(undone, name)
+			return nil
+		}
+	}
+
+	boom := errors.New("delete failed")
+	err := Run(context.Background(), func(ctx context.Context) error {
+		OnRollback(ctx, undo("mkdir"))
+		OnRollback(ctx, undo("copy"))
+		return boom
+	})
+
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+	if want := []string{"copy", "mkdir"}; !reflect.DeepEqual(undone, want) {
+		t.Errorf("undone = %v, want %v", undone, want)
+	}
+}
+
+func TestRun_CommitsOnSuccess(t *testing.T) {
+	called := false
+	err := Run(context.Background(), func(ctx context.Context) error {
+		OnRollback(ctx, func(ctx context.Context) error { called = true; return nil })
+		return nil
+	})
+	if err != nil || called {
+		t.Errorf("err = %v, compensation called = %v", err, called)
+	}
+}
+
+func TestRun_CancelledMidway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var rollbackCtxErr error
+	err := Run(ctx, func(ctx context.Context) error {
+		OnRollback(ctx, func(ctx context.Context) error {
+			rollbackCtxErr = ctx.Err()
+			return nil
+		})
+		cancel()
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want canceled", err)
+	}
+	if rollbackCtxErr != nil {
+		t.Errorf("compensation ran on a done context: %v", rollbackCtxErr)
+	}
+}
+
+func TestRun_CompensationErrors(t *testing.T) {
+	var ran int
+	err := Run(context.Background(), func(ctx context.Context) error {
+		OnRollback(ctx, func(ctx context.Context) error { ran++; return nil })
+		OnRollback(ctx, func(ctx context.Context) error { ran++; panic("oops") })
+		OnRollback(ctx, func(ctx context.Context) error { ran++; return errors.New("restore failed") })
+		return errors.New("step 3 failed")
+	})
+
+	if ran != 3 {
+		t.Errorf("ran %d compensations, want all 3", ran)
+	}
+	for _, want := range []string{"step 3 failed", "rollback: restore failed", "compensation panicked: oops"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %v, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestRun_Panic(t *testing.T) {
+	undone := false
+	func() {
+		defer func() {
+			if r := recover(); r != "handler bug" {
+				t.Errorf("recovered %v, want the panic re-raised", r)
+			}
+		}()
+		Run(context.Background(), func(ctx context.Context) error {
+			OnRollback(ctx, func(ctx context.Context) error { undone = true; return nil })
+			panic("handler bug")
+		})
+	}()
+	if !undone {
+		t.Error("panic did not roll back")
+	}
+}
+
+func TestWrap(t *testing.T) {
+	undone := false
+	handler := Wrap(func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		OnRollback(ctx, func(ctx context.Context) error { undone = true; return nil })
+		if args["fail"] == true {
+			return "partial", errors.New("failed")
+		}
+		return "ok", nil
+	})
+
+	if result, err := handler(context.Background(), nil); result != "ok" || err != nil || undone {
+		t.Errorf("success: result = %v, err = %v, undone = %v", result, err, undone)
+	}
+	if result, err := handler(context.Background(), map[string]interface{}{"fail": true}); result != nil || err == nil || !undone {
+		t.Errorf("failure: result = %v, err = %v, undone = %v", result, err, undone)
+	}
+
+	if OnRollback(context.Background(), func(ctx context.Context) error { return nil }) {
+		t.Error("OnRollback without a transaction reported true")
+	}
+}